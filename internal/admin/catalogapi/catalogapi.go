@@ -164,6 +164,8 @@ type Config struct {
 // handler binds the routes to their dependencies.
 type handler struct {
 	cfg Config
+	// drift is cfg.Catalogs when it keeps the contract drift report, else nil.
+	drift apicatalog.DriftStore
 }
 
 // Register mounts the API-catalog routes on mux. Reads need only the store;
@@ -177,6 +179,11 @@ func Register(mux *http.ServeMux, cfg Config) {
 	mux.HandleFunc("GET /api/v1/admin/api-catalogs", h.listCatalogs)
 	mux.HandleFunc("GET /api/v1/admin/api-catalogs/{id}", h.getCatalog)
 	mux.HandleFunc("GET /api/v1/admin/api-catalogs/{id}/specs", h.listCatalogSpecs)
+	// The contract drift report is kept only by a database-backed store.
+	if drift, ok := cfg.Catalogs.(apicatalog.DriftStore); ok {
+		h.drift = drift
+		mux.HandleFunc("GET /api/v1/admin/api-catalogs/{id}/drift", h.listCatalogDrift)
+	}
 	if !cfg.Mutable {
 		return
 	}
@@ -658,6 +665,37 @@ func (h *handler) listCatalogSpecs(w http.ResponseWriter, r *http.Request) {
 	httpjson.WriteJSON(w, http.StatusOK, out)
 }
 
+// listCatalogDrift handles GET /api/v1/admin/api-catalogs/{id}/drift.
+//
+// @Summary      List catalog contract drift
+// @Description  Returns, per operation and connection, the live responses that did not match the catalog's specs, most recently seen first. Drift last seen before its spec was last edited is omitted.
+// @Tags         API Catalogs
+// @Produce      json
+// @Param        id  path  string  true  "Catalog ID"
+// @Success      200  {array}   apicatalog.DriftEntry
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/api-catalogs/{id}/drift [get]
+func (h *handler) listCatalogDrift(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue(catalogPathID)
+	if _, err := h.cfg.Catalogs.GetCatalog(r.Context(), id); errors.Is(err, apicatalog.ErrNotFound) {
+		httpjson.WriteError(w, http.StatusNotFound, "catalog not found")
+		return
+	} else if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to get catalog")
+		return
+	}
+	entries, err := h.drift.ListDrift(r.Context(), id)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to list drift")
+		slog.Warn("listCatalogDrift", logKeyError, err)
+		return
+	}
+	httpjson.WriteJSON(w, http.StatusOK, entries)
+}
+
 // getCatalogSpec handles GET /api/v1/admin/api-catalogs/{id}/specs/{spec}.
 //
 // @Summary      Get catalog spec
@@ -662,3 +662,60 @@ func TestCatalog_CloneCopiesTitleDescription(t *testing.T) {
 		t.Errorf("clone did not preserve title/description; got %+v", specs[0])
 	}
 }
+
+// driftCatalogStore is a memory catalog that also keeps a drift report, as
+// the database-backed store does.
+type driftCatalogStore struct {
+	*apicatalog.MemoryStore
+	entries []apicatalog.DriftEntry
+}
+
+func (*driftCatalogStore) RecordDrift(context.Context, apicatalog.DriftObservation) error { return nil }
+
+func (s *driftCatalogStore) ListDrift(context.Context, string) ([]apicatalog.DriftEntry, error) {
+	return s.entries, nil
+}
+
+func TestCatalog_DriftReport(t *testing.T) {
+	t.Parallel()
+	store := &driftCatalogStore{
+		MemoryStore: apicatalog.NewMemoryStore(),
+		entries: []apicatalog.DriftEntry{{
+			Connection: "orders-prod", SpecName: "orders", OperationID: "getOrder",
+			Method: http.MethodGet, Path: "/orders/{id}", Violations: 3, LastStatus: http.StatusOK,
+			LastViolation: "status 200: /total: value must be a number",
+		}},
+	}
+	if err := store.CreateCatalog(context.Background(), apicatalog.Catalog{
+		ID: "orders-v1", Name: "orders", DisplayName: "Orders",
+	}); err != nil {
+		t.Fatalf("CreateCatalog: %v", err)
+	}
+	h := testMux(Config{Catalogs: store})
+
+	res := doJSON(t, h, http.MethodGet, "/api/v1/admin/api-catalogs/orders-v1/drift", nil)
+	if res.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", res.Code, res.Body.String())
+	}
+	var got []apicatalog.DriftEntry
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != 1 || got[0].OperationID != "getOrder" || got[0].Violations != 3 {
+		t.Errorf("drift = %+v", got)
+	}
+
+	res = doJSON(t, h, http.MethodGet, "/api/v1/admin/api-catalogs/ghost/drift", nil)
+	if res.Code != http.StatusNotFound {
+		t.Errorf("unknown catalog: status = %d; want 404", res.Code)
+	}
+}
+
+func TestCatalog_DriftReportNeedsADriftStore(t *testing.T) {
+	t.Parallel()
+	h, _ := newCatalogTestHandler(t)
+	res := doJSON(t, h, http.MethodGet, "/api/v1/admin/api-catalogs/any/drift", nil)
+	if res.Code != http.StatusNotFound && res.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("a store without a drift report must not serve the route: %d", res.Code)
+	}
+}
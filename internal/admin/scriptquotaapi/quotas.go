@@ -0,0 +1,168 @@
+package scriptquotaapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/internal/httpjson"
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptquota"
+)
+
+// Usage windows, in days.
+const (
+	defaultUsageDays = 1
+	maxUsageDays     = 90
+)
+
+// usageResponse is one usage rollup: a row per script that finished a run in
+// the window.
+type usageResponse struct {
+	Data  []scriptquota.Usage `json:"data"`
+	Since time.Time           `json:"since"`
+	Days  int                 `json:"days" example:"1"`
+	Sort  string              `json:"sort" example:"rows_scanned"`
+}
+
+// quotaListResponse wraps every quota set.
+type quotaListResponse struct {
+	Data []scriptquota.Quota `json:"data"`
+}
+
+// usage handles GET /api/v1/admin/scripts/usage.
+//
+// @Summary      Roll up script usage
+// @Description  Returns what each managed script spent over the last days (default 1, the window a quota is enforced over; at most 90), summed from its finished runs: runs, runs its quota stopped or refused, wall time, rows scanned as the query tool reported them, tool calls, and output bytes. Heaviest first by sort (rows_scanned, wall_time, tool_calls, output_bytes, or runs; default rows_scanned).
+// @Tags         Scripts
+// @Produce      json
+// @Param        days      query  integer  false  "Window in days (default: 1, max: 90)"
+// @Param        owner     query  string   false  "Filter to one owner's scripts"
+// @Param        sort      query  string   false  "Order by rows_scanned, wall_time, tool_calls, output_bytes, or runs"
+// @Param        per_page  query  integer  false  "Scripts to return (default: 50, max: 500)"
+// @Success      200  {object}  usageResponse
+// @Failure      400  {object}  httpjson.ProblemDetail
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/scripts/usage [get]
+func (h *handler) usage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	days := defaultUsageDays
+	if v := q.Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxUsageDays {
+			httpjson.WriteError(w, http.StatusBadRequest, "days must be a whole number from 1 to 90")
+			return
+		}
+		days = n
+	}
+	sort := q.Get("sort")
+	if sort == "" {
+		sort = scriptquota.DefaultRollupSort
+	}
+	filter := scriptquota.RollupFilter{
+		Since: time.Now().UTC().Add(-time.Duration(days) * scriptquota.Window),
+		Owner: q.Get("owner"), Sort: sort, Limit: httpjson.ParseLimit(q),
+	}
+	usage, err := h.cfg.Quotas.Rollup(r.Context(), filter)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to roll up script usage")
+		return
+	}
+	httpjson.WriteJSON(w, http.StatusOK, usageResponse{
+		Data: usage, Since: filter.Since, Days: days, Sort: sort,
+	})
+}
+
+// listQuotas handles GET /api/v1/admin/scripts/quotas.
+//
+// @Summary      List script quotas
+// @Description  Returns every quota set: script quotas first, then owner quotas. A limit of 0 leaves that axis unbounded.
+// @Tags         Scripts
+// @Produce      json
+// @Success      200  {object}  quotaListResponse
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/scripts/quotas [get]
+func (h *handler) listQuotas(w http.ResponseWriter, r *http.Request) {
+	quotas, err := h.cfg.Quotas.List(r.Context())
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to list script quotas")
+		return
+	}
+	if quotas == nil {
+		quotas = []scriptquota.Quota{}
+	}
+	httpjson.WriteJSON(w, http.StatusOK, quotaListResponse{Data: quotas})
+}
+
+// setQuota handles PUT /api/v1/admin/scripts/quotas.
+//
+// @Summary      Set a script quota
+// @Description  Sets the daily allowance on one script (script_id) or on every script one person owns (owner), replacing what it had. Each limit is over the rolling day before a run: wall_seconds, rows_scanned, tool_calls, output_bytes; 0 leaves the axis unbounded. A run is bounded by the least of its script's and its owner's remaining allowance.
+// @Tags         Scripts
+// @Accept       json
+// @Produce      json
+// @Param        request  body  scriptquota.Quota  true  "The quota"
+// @Success      200  {object}  scriptquota.Quota
+// @Failure      400  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/scripts/quotas [put]
+func (h *handler) setQuota(w http.ResponseWriter, r *http.Request) {
+	var q scriptquota.Quota
+	if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := q.Validate(); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	q.UpdatedBy = h.actor(r)
+	err := h.cfg.Quotas.Set(r.Context(), &q)
+	switch {
+	case errors.Is(err, scriptquota.ErrNoScript):
+		httpjson.WriteError(w, http.StatusNotFound, "script not found")
+	case err != nil:
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to set script quota")
+	default:
+		httpjson.WriteJSON(w, http.StatusOK, q)
+	}
+}
+
+// deleteQuota handles DELETE /api/v1/admin/scripts/quotas.
+//
+// @Summary      Remove a script quota
+// @Description  Removes the quota on one script or one owner, leaving it bounded only by the per-run limits.
+// @Tags         Scripts
+// @Param        script_id  query  string  false  "The script whose quota to remove"
+// @Param        owner      query  string  false  "The owner whose quota to remove"
+// @Success      204
+// @Failure      400  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/scripts/quotas [delete]
+func (h *handler) deleteQuota(w http.ResponseWriter, r *http.Request) {
+	scriptID, owner := r.URL.Query().Get("script_id"), r.URL.Query().Get("owner")
+	if (scriptID == "") == (owner == "") {
+		httpjson.WriteError(w, http.StatusBadRequest, "name exactly one of script_id and owner")
+		return
+	}
+	err := h.cfg.Quotas.Delete(r.Context(), scriptID, owner)
+	switch {
+	case errors.Is(err, scriptquota.ErrNotFound):
+		httpjson.WriteError(w, http.StatusNotFound, "script quota not found")
+	case err != nil:
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to remove script quota")
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,127 @@
+package scriptquotaapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptquota"
+)
+
+// fakeStore is a quota store with canned answers, recording what the handlers
+// asked of it.
+type fakeStore struct {
+	usage  []scriptquota.Usage
+	quotas []scriptquota.Quota
+	err    error
+
+	gotFilter  scriptquota.RollupFilter
+	gotSet     *scriptquota.Quota
+	gotDeleted [2]string
+}
+
+func (f *fakeStore) Rollup(_ context.Context, filter scriptquota.RollupFilter) ([]scriptquota.Usage, error) {
+	f.gotFilter = filter
+	return f.usage, f.err
+}
+
+func (f *fakeStore) List(context.Context) ([]scriptquota.Quota, error) { return f.quotas, f.err }
+
+func (f *fakeStore) Set(_ context.Context, q *scriptquota.Quota) error {
+	f.gotSet = q
+	return f.err
+}
+
+func (f *fakeStore) Delete(_ context.Context, scriptID, owner string) error {
+	f.gotDeleted = [2]string{scriptID, owner}
+	return f.err
+}
+
+func serve(t *testing.T, store *fakeStore, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := http.NewServeMux()
+	Register(mux, Config{Quotas: store, Actor: func(*http.Request) string { return "admin@example.com" }})
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return w
+}
+
+func TestRegister_NoStoreNoRoutes(t *testing.T) {
+	mux := http.NewServeMux()
+	Register(mux, Config{})
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/scripts/usage", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUsage_RollsUpTheWindowHeaviestFirst(t *testing.T) {
+	store := &fakeStore{usage: []scriptquota.Usage{{ScriptID: "s1", Name: "hourly-scan", RowsScanned: 9_000_000_000}}}
+	w := serve(t, store, http.MethodGet, "/api/v1/admin/scripts/usage?days=7&owner=jane@example.com&per_page=10", "")
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var got usageResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, "hourly-scan", got.Data[0].Name)
+	assert.Equal(t, 7, got.Days)
+	assert.Equal(t, scriptquota.DefaultRollupSort, got.Sort)
+	assert.Equal(t, "jane@example.com", store.gotFilter.Owner)
+	assert.Equal(t, 10, store.gotFilter.Limit)
+	assert.WithinDuration(t, time.Now().Add(-7*24*time.Hour), store.gotFilter.Since, time.Minute)
+}
+
+func TestUsage_RefusesAWindowOutOfRange(t *testing.T) {
+	for _, days := range []string{"0", "91", "week"} {
+		w := serve(t, &fakeStore{}, http.MethodGet, "/api/v1/admin/scripts/usage?days="+days, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code, days)
+	}
+}
+
+func TestSetQuota_RecordsTheOperator(t *testing.T) {
+	store := &fakeStore{}
+	w := serve(t, store, http.MethodPut, "/api/v1/admin/scripts/quotas",
+		`{"owner":"Jane@Example.com","rows_scanned":5000000000,"tool_calls":2000}`)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NotNil(t, store.gotSet)
+	assert.Equal(t, "jane@example.com", store.gotSet.Owner)
+	assert.Equal(t, int64(5_000_000_000), store.gotSet.RowsScanned)
+	assert.Equal(t, "admin@example.com", store.gotSet.UpdatedBy)
+}
+
+func TestSetQuota_Refusals(t *testing.T) {
+	w := serve(t, &fakeStore{}, http.MethodPut, "/api/v1/admin/scripts/quotas", `{"tool_calls":10}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "a quota on nobody")
+
+	w = serve(t, &fakeStore{err: scriptquota.ErrNoScript}, http.MethodPut, "/api/v1/admin/scripts/quotas", `{"script_id":"nope"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve(t, &fakeStore{err: errors.New("boom")}, http.MethodPut, "/api/v1/admin/scripts/quotas", `{"script_id":"s1"}`)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestDeleteQuota(t *testing.T) {
+	store := &fakeStore{}
+	w := serve(t, store, http.MethodDelete, "/api/v1/admin/scripts/quotas?script_id=s1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, [2]string{"s1", ""}, store.gotDeleted)
+
+	w = serve(t, &fakeStore{}, http.MethodDelete, "/api/v1/admin/scripts/quotas", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serve(t, &fakeStore{err: scriptquota.ErrNotFound}, http.MethodDelete, "/api/v1/admin/scripts/quotas?owner=jane@example.com", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestListQuotas_IsNeverNull(t *testing.T) {
+	w := serve(t, &fakeStore{}, http.MethodGet, "/api/v1/admin/scripts/quotas", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":[]}`, w.Body.String())
+}
@@ -0,0 +1,58 @@
+// Package scriptquotaapi serves the /api/v1/admin/scripts usage and quota
+// surface: what every managed script spent over a window, heaviest first, and
+// the daily allowances that bound what they may spend.
+//
+// It is the operator's view of internal/platform/scriptquota. Usage is read
+// across every owner, and only an operator sets a quota: an allowance its own
+// owner could raise would bound nothing.
+package scriptquotaapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptquota"
+)
+
+// Store reads usage and keeps quotas. *scriptquota.Store satisfies it.
+type Store interface {
+	Rollup(ctx context.Context, f scriptquota.RollupFilter) ([]scriptquota.Usage, error)
+	List(ctx context.Context) ([]scriptquota.Quota, error)
+	Set(ctx context.Context, q *scriptquota.Quota) error
+	Delete(ctx context.Context, scriptID, owner string) error
+}
+
+// Config carries what the routes need.
+type Config struct {
+	// Quotas is the quota store. Nil leaves the routes unregistered.
+	Quotas Store
+	// Actor names the operator setting a quota. Supplied by the admin
+	// handler, which owns the authenticated identity.
+	Actor func(*http.Request) string
+}
+
+// handler binds the routes to their dependencies.
+type handler struct {
+	cfg Config
+}
+
+// Register mounts the script usage and quota routes on mux.
+func Register(mux *http.ServeMux, cfg Config) {
+	if cfg.Quotas == nil {
+		return
+	}
+	h := &handler{cfg: cfg}
+	mux.HandleFunc("GET /api/v1/admin/scripts/usage", h.usage)
+	mux.HandleFunc("GET /api/v1/admin/scripts/quotas", h.listQuotas)
+	mux.HandleFunc("PUT /api/v1/admin/scripts/quotas", h.setQuota)
+	mux.HandleFunc("DELETE /api/v1/admin/scripts/quotas", h.deleteQuota)
+}
+
+// actor names the operator taking an action, or "" when the handler was wired
+// without an identity source.
+func (h *handler) actor(r *http.Request) string {
+	if h.cfg.Actor == nil {
+		return ""
+	}
+	return h.cfg.Actor(r)
+}
@@ -0,0 +1,60 @@
+// Package scriptsecretapi serves the /api/v1/admin/script-secrets surface: the
+// named secrets an operator stores for managed scripts and the script versions
+// granted each.
+//
+// It is the operator's view of internal/platform/scriptsecret. A value goes in
+// and never comes back out: every route answers with names, descriptions and
+// grants, so a leaked admin response names a secret and never discloses it.
+package scriptsecretapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptsecret"
+)
+
+// Store keeps secrets and their grants. *scriptsecret.Store satisfies it.
+type Store interface {
+	List(ctx context.Context) ([]scriptsecret.Secret, error)
+	Set(ctx context.Context, name, value, description, actor string) (*scriptsecret.Secret, error)
+	Delete(ctx context.Context, name string) error
+	Grant(ctx context.Context, name, scriptID, actor string) (*scriptsecret.Grant, error)
+	Revoke(ctx context.Context, name, scriptID string) error
+}
+
+// Config carries what the routes need.
+type Config struct {
+	// Secrets is the secret store. Nil leaves the routes unregistered.
+	Secrets Store
+	// Actor names the operator setting or granting a secret. Supplied by the
+	// admin handler, which owns the authenticated identity.
+	Actor func(*http.Request) string
+}
+
+// handler binds the routes to their dependencies.
+type handler struct {
+	cfg Config
+}
+
+// Register mounts the script secret routes on mux.
+func Register(mux *http.ServeMux, cfg Config) {
+	if cfg.Secrets == nil {
+		return
+	}
+	h := &handler{cfg: cfg}
+	mux.HandleFunc("GET /api/v1/admin/script-secrets", h.list)
+	mux.HandleFunc("PUT /api/v1/admin/script-secrets/{name}", h.set)
+	mux.HandleFunc("DELETE /api/v1/admin/script-secrets/{name}", h.remove)
+	mux.HandleFunc("PUT /api/v1/admin/script-secrets/{name}/grants/{scriptID}", h.grant)
+	mux.HandleFunc("DELETE /api/v1/admin/script-secrets/{name}/grants/{scriptID}", h.revoke)
+}
+
+// actor names the operator taking an action, or "" when the handler was wired
+// without an identity source.
+func (h *handler) actor(r *http.Request) string {
+	if h.cfg.Actor == nil {
+		return ""
+	}
+	return h.cfg.Actor(r)
+}
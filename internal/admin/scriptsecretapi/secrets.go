@@ -0,0 +1,167 @@
+package scriptsecretapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/txn2/mcp-data-platform/internal/httpjson"
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptsecret"
+)
+
+// maxSetBodyBytes bounds a set request: the largest value, its description,
+// and the JSON around them.
+const maxSetBodyBytes = scriptsecret.MaxValueBytes * 2
+
+// secretListResponse wraps every secret, without values.
+type secretListResponse struct {
+	Data []scriptsecret.Secret `json:"data"`
+}
+
+// setSecretRequest is a secret's value and what it is for.
+type setSecretRequest struct {
+	Value       string `json:"value" example:"sk_live_..."`
+	Description string `json:"description,omitempty" example:"Signing key for the Acme webhook"`
+}
+
+// list handles GET /api/v1/admin/script-secrets.
+//
+// @Summary      List script secrets
+// @Description  Returns every secret stored for managed scripts by name, with the script versions granted each. A grant whose script was edited since is reported with current false; a run of the edited version is refused the secret until it is granted again. No value is ever returned.
+// @Tags         Scripts
+// @Produce      json
+// @Success      200  {object}  secretListResponse
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/script-secrets [get]
+func (h *handler) list(w http.ResponseWriter, r *http.Request) {
+	secrets, err := h.cfg.Secrets.List(r.Context())
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to list script secrets")
+		return
+	}
+	if secrets == nil {
+		secrets = []scriptsecret.Secret{}
+	}
+	httpjson.WriteJSON(w, http.StatusOK, secretListResponse{Data: secrets})
+}
+
+// set handles PUT /api/v1/admin/script-secrets/{name}.
+//
+// @Summary      Set a script secret
+// @Description  Stores a secret's value, encrypted with the platform's field key, creating the secret or rotating it in place. A rotation keeps its grants. Refused when the deployment has no encryption key. The response describes the secret and does not include the value.
+// @Tags         Scripts
+// @Accept       json
+// @Produce      json
+// @Param        name     path  string            true  "Secret name: lowercase letters, digits, '-' or '_'"
+// @Param        request  body  setSecretRequest  true  "The value and its description"
+// @Success      200  {object}  scriptsecret.Secret
+// @Failure      400  {object}  httpjson.ProblemDetail
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Failure      503  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/script-secrets/{name} [put]
+func (h *handler) set(w http.ResponseWriter, r *http.Request) {
+	var req setSecretRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSetBodyBytes)).Decode(&req); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	name := r.PathValue("name")
+	if err := scriptsecret.ValidateName(name); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Value == "" || len(req.Value) > scriptsecret.MaxValueBytes {
+		httpjson.WriteError(w, http.StatusBadRequest, "value must be 1 to 8192 bytes")
+		return
+	}
+	sec, err := h.cfg.Secrets.Set(r.Context(), name, req.Value, req.Description, h.actor(r))
+	switch {
+	case errors.Is(err, scriptsecret.ErrUnencrypted):
+		httpjson.WriteError(w, http.StatusServiceUnavailable, err.Error())
+	case err != nil:
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to set script secret")
+	default:
+		httpjson.WriteJSON(w, http.StatusOK, sec)
+	}
+}
+
+// remove handles DELETE /api/v1/admin/script-secrets/{name}.
+//
+// @Summary      Delete a script secret
+// @Description  Deletes a secret and every grant of it. A run that asks for it afterwards is refused.
+// @Tags         Scripts
+// @Param        name  path  string  true  "Secret name"
+// @Success      204
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/script-secrets/{name} [delete]
+func (h *handler) remove(w http.ResponseWriter, r *http.Request) {
+	err := h.cfg.Secrets.Delete(r.Context(), r.PathValue("name"))
+	switch {
+	case errors.Is(err, scriptsecret.ErrNotFound):
+		httpjson.WriteError(w, http.StatusNotFound, "script secret not found")
+	case err != nil:
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to delete script secret")
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// grant handles PUT /api/v1/admin/script-secrets/{name}/grants/{scriptID}.
+//
+// @Summary      Grant a script secret
+// @Description  Allows the script's current version to read the secret, replacing an earlier grant to the same script. An edit to the script makes a new version the grant does not cover: grant it again once the new code has been read.
+// @Tags         Scripts
+// @Produce      json
+// @Param        name      path  string  true  "Secret name"
+// @Param        scriptID  path  string  true  "Script ID"
+// @Success      200  {object}  scriptsecret.Grant
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/script-secrets/{name}/grants/{scriptID} [put]
+func (h *handler) grant(w http.ResponseWriter, r *http.Request) {
+	g, err := h.cfg.Secrets.Grant(r.Context(), r.PathValue("name"), r.PathValue("scriptID"), h.actor(r))
+	switch {
+	case errors.Is(err, scriptsecret.ErrNotFound):
+		httpjson.WriteError(w, http.StatusNotFound, "script secret not found")
+	case errors.Is(err, scriptsecret.ErrNoScript):
+		httpjson.WriteError(w, http.StatusNotFound, "script not found")
+	case err != nil:
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to grant script secret")
+	default:
+		httpjson.WriteJSON(w, http.StatusOK, g)
+	}
+}
+
+// revoke handles DELETE /api/v1/admin/script-secrets/{name}/grants/{scriptID}.
+//
+// @Summary      Revoke a script secret
+// @Description  Ends a script's grant of the secret. Its next run that asks for it is refused.
+// @Tags         Scripts
+// @Param        name      path  string  true  "Secret name"
+// @Param        scriptID  path  string  true  "Script ID"
+// @Success      204
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/script-secrets/{name}/grants/{scriptID} [delete]
+func (h *handler) revoke(w http.ResponseWriter, r *http.Request) {
+	err := h.cfg.Secrets.Revoke(r.Context(), r.PathValue("name"), r.PathValue("scriptID"))
+	switch {
+	case errors.Is(err, scriptsecret.ErrNotFound):
+		httpjson.WriteError(w, http.StatusNotFound, "script secret grant not found")
+	case err != nil:
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to revoke script secret")
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,135 @@
+package scriptsecretapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptsecret"
+)
+
+// fakeStore is a secret store with canned answers, recording what the
+// handlers asked of it.
+type fakeStore struct {
+	secrets []scriptsecret.Secret
+	err     error
+
+	gotSet   [4]string
+	gotGrant [3]string
+	gotName  string
+}
+
+func (f *fakeStore) List(context.Context) ([]scriptsecret.Secret, error) { return f.secrets, f.err }
+
+func (f *fakeStore) Set(_ context.Context, name, value, description, actor string) (*scriptsecret.Secret, error) {
+	f.gotSet = [4]string{name, value, description, actor}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &scriptsecret.Secret{Name: name, Description: description, Grants: []scriptsecret.Grant{}, UpdatedBy: actor}, nil
+}
+
+func (f *fakeStore) Delete(_ context.Context, name string) error {
+	f.gotName = name
+	return f.err
+}
+
+func (f *fakeStore) Grant(_ context.Context, name, scriptID, actor string) (*scriptsecret.Grant, error) {
+	f.gotGrant = [3]string{name, scriptID, actor}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &scriptsecret.Grant{ScriptID: scriptID, Version: 3, Current: true, GrantedBy: actor}, nil
+}
+
+func (f *fakeStore) Revoke(_ context.Context, name, scriptID string) error {
+	f.gotGrant = [3]string{name, scriptID, ""}
+	return f.err
+}
+
+func serve(t *testing.T, store *fakeStore, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := http.NewServeMux()
+	Register(mux, Config{Secrets: store, Actor: func(*http.Request) string { return "admin@example.com" }})
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return w
+}
+
+func TestRegister_NoStoreNoRoutes(t *testing.T) {
+	mux := http.NewServeMux()
+	Register(mux, Config{})
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/script-secrets", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestSet_NeverEchoesTheValue stores a value and checks the response names the
+// secret and carries nothing of what it is.
+func TestSet_NeverEchoesTheValue(t *testing.T) {
+	store := &fakeStore{}
+	w := serve(t, store, http.MethodPut, "/api/v1/admin/script-secrets/partner-key",
+		`{"value": "hunter2", "description": "Acme webhook"}`)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, [4]string{"partner-key", "hunter2", "Acme webhook", "admin@example.com"}, store.gotSet)
+	assert.NotContains(t, w.Body.String(), "hunter2")
+	var got scriptsecret.Secret
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, "partner-key", got.Name)
+}
+
+func TestSet_Refusals(t *testing.T) {
+	for _, tc := range []struct {
+		name, target, body string
+		err                error
+		want               int
+	}{
+		{"bad name", "/api/v1/admin/script-secrets/Partner", `{"value": "v"}`, nil, http.StatusBadRequest},
+		{"empty value", "/api/v1/admin/script-secrets/k", `{"value": ""}`, nil, http.StatusBadRequest},
+		{"not JSON", "/api/v1/admin/script-secrets/k", `value=v`, nil, http.StatusBadRequest},
+		{"no key", "/api/v1/admin/script-secrets/k", `{"value": "v"}`, scriptsecret.ErrUnencrypted, http.StatusServiceUnavailable},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := serve(t, &fakeStore{err: tc.err}, http.MethodPut, tc.target, tc.body)
+			assert.Equal(t, tc.want, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestList_ReturnsAnEmptyListNotNull(t *testing.T) {
+	w := serve(t, &fakeStore{}, http.MethodGet, "/api/v1/admin/script-secrets", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data": []}`, w.Body.String())
+}
+
+func TestGrantAndRevoke(t *testing.T) {
+	store := &fakeStore{}
+	w := serve(t, store, http.MethodPut, "/api/v1/admin/script-secrets/partner-key/grants/s1", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, [3]string{"partner-key", "s1", "admin@example.com"}, store.gotGrant)
+	assert.Contains(t, w.Body.String(), `"version":3`)
+
+	w = serve(t, store, http.MethodDelete, "/api/v1/admin/script-secrets/partner-key/grants/s1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	for err, want := range map[error]string{
+		scriptsecret.ErrNotFound: "script secret not found",
+		scriptsecret.ErrNoScript: "script not found",
+	} {
+		w := serve(t, &fakeStore{err: err}, http.MethodPut, "/api/v1/admin/script-secrets/k/grants/s9", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), want)
+	}
+}
+
+func TestDelete_MissingIsNotFound(t *testing.T) {
+	w := serve(t, &fakeStore{err: scriptsecret.ErrNotFound}, http.MethodDelete, "/api/v1/admin/script-secrets/k", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
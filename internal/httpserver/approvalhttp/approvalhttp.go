@@ -0,0 +1,238 @@
+// Package approvalhttp serves the approver's side of the write-operation
+// approval workflow on the portal API: the queue of requests a person may
+// decide, their own requests, one request in full, and the approve and
+// reject decisions.
+//
+// The gate that parks a call and the approval_status tool the agent polls
+// live in internal/platform/approvalgate; this package is only the REST
+// surface over its Decider. Like mentionhttp, the composition root mounts
+// these routes wrapped in the portal's authentication middleware and
+// injects the identity accessor, so this package never imports pkg/portal.
+package approvalhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/txn2/mcp-data-platform/internal/httpjson"
+	"github.com/txn2/mcp-data-platform/internal/platform/approvalgate"
+)
+
+// Decisions is the approval workflow's decision surface.
+// *approvalgate.Decider implements it.
+type Decisions interface {
+	List(ctx context.Context, c approvalgate.Caller, scope, status string) ([]approvalgate.Approval, error)
+	Get(ctx context.Context, c approvalgate.Caller, id string) (*approvalgate.Approval, error)
+	Decide(ctx context.Context, c approvalgate.Caller, id string, approve bool, reason string) (*approvalgate.Approval, error)
+}
+
+// Deps carries the collaborators the handlers need.
+type Deps struct {
+	// Decisions lists, reads, and decides approvals. Nil leaves the routes
+	// unregistered.
+	Decisions Decisions
+	// Caller resolves the authenticated portal user, returning nil when the
+	// request carries none.
+	Caller func(*http.Request) *approvalgate.Caller
+}
+
+// Handler serves the approval routes.
+type Handler struct {
+	deps Deps
+}
+
+// New builds the handler.
+func New(deps Deps) *Handler {
+	return &Handler{deps: deps}
+}
+
+// Register mounts the routes, wrapping each in the portal's authentication
+// middleware. A deployment without approvals registers nothing.
+func (h *Handler) Register(mux *http.ServeMux, wrap func(http.Handler) http.Handler) {
+	if h.deps.Decisions == nil || h.deps.Caller == nil {
+		return
+	}
+	mux.Handle("GET /api/v1/portal/approvals", wrap(http.HandlerFunc(h.list)))
+	mux.Handle("GET /api/v1/portal/approvals/{id}", wrap(http.HandlerFunc(h.get)))
+	mux.Handle("POST /api/v1/portal/approvals/{id}/approve", wrap(http.HandlerFunc(h.approve)))
+	mux.Handle("POST /api/v1/portal/approvals/{id}/reject", wrap(http.HandlerFunc(h.reject)))
+}
+
+// approvalsResponse wraps a list of approvals.
+type approvalsResponse struct {
+	Data []approvalgate.Approval `json:"data"`
+}
+
+// decisionRequest is the body of an approve or reject.
+type decisionRequest struct {
+	// Reason is shown to the requester and carried on the audit event.
+	Reason string `json:"reason,omitempty" example:"Customer confirmed by phone"`
+}
+
+// list handles GET /api/v1/portal/approvals.
+//
+// @Summary      List approval requests
+// @Description  Returns write operations held for approval, newest first. scope=decide (the default) lists the requests the caller may decide: those naming them or one of their personas as an approver, or every request for an admin, never their own. scope=mine lists the requests the caller's agents made.
+// @Tags         Approvals
+// @Produce      json
+// @Param        scope   query  string  false  "decide (default) or mine"
+// @Param        status  query  string  false  "pending, approved, rejected, or expired"
+// @Success      200  {object}  approvalsResponse
+// @Failure      400  {object}  httpjson.ProblemDetail
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/approvals [get]
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	caller := h.caller(w, r)
+	if caller == nil {
+		return
+	}
+	scope := r.URL.Query().Get("scope")
+	switch scope {
+	case "":
+		scope = approvalgate.ScopeDecide
+	case approvalgate.ScopeDecide, approvalgate.ScopeMine:
+	default:
+		httpjson.WriteError(w, http.StatusBadRequest, "scope must be decide or mine")
+		return
+	}
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", approvalgate.StatusPending, approvalgate.StatusApproved, approvalgate.StatusRejected, approvalgate.StatusExpired:
+	default:
+		httpjson.WriteError(w, http.StatusBadRequest, "status must be pending, approved, rejected, or expired")
+		return
+	}
+	rows, err := h.deps.Decisions.List(r.Context(), *caller, scope, status)
+	if err != nil {
+		slog.Error("approvals: listing failed", "error", err)
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to list approvals")
+		return
+	}
+	httpjson.WriteJSON(w, http.StatusOK, approvalsResponse{Data: rows})
+}
+
+// get handles GET /api/v1/portal/approvals/{id}.
+//
+// @Summary      Get an approval request
+// @Description  Returns one held write operation in full: the request the agent made, the rule that held it, its approvers, and its decision. Readable by its requester, its approvers, and admins; anyone else gets 404.
+// @Tags         Approvals
+// @Produce      json
+// @Param        id   path  string  true  "Approval ID"
+// @Success      200  {object}  approvalgate.Approval
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/approvals/{id} [get]
+func (h *Handler) get(w http.ResponseWriter, r *http.Request) {
+	caller := h.caller(w, r)
+	if caller == nil {
+		return
+	}
+	a, err := h.deps.Decisions.Get(r.Context(), *caller, r.PathValue("id"))
+	if err != nil {
+		writeDecisionError(w, err)
+		return
+	}
+	httpjson.WriteJSON(w, http.StatusOK, a)
+}
+
+// approve handles POST /api/v1/portal/approvals/{id}/approve.
+//
+// @Summary      Approve a request
+// @Description  Approves a pending write operation. Nothing runs now: the agent repeats the identical call, which then executes once, within the configured window. Open to the request's approvers and admins, never to its requester.
+// @Tags         Approvals
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string           true   "Approval ID"
+// @Param        body  body  decisionRequest  false  "Optional reason"
+// @Success      200  {object}  approvalgate.Approval
+// @Failure      400  {object}  httpjson.ProblemDetail
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      403  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Failure      409  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/approvals/{id}/approve [post]
+func (h *Handler) approve(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, true)
+}
+
+// reject handles POST /api/v1/portal/approvals/{id}/reject.
+//
+// @Summary      Reject a request
+// @Description  Rejects a pending write operation. The agent's next repeat of the call is told it was rejected, with the reason, and is not executed. Open to the request's approvers and admins, never to its requester.
+// @Tags         Approvals
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string           true   "Approval ID"
+// @Param        body  body  decisionRequest  false  "Optional reason"
+// @Success      200  {object}  approvalgate.Approval
+// @Failure      400  {object}  httpjson.ProblemDetail
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      403  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Failure      409  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/approvals/{id}/reject [post]
+func (h *Handler) reject(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, false)
+}
+
+// maxDecisionBody bounds a decision's body; it carries one short reason.
+const maxDecisionBody = 16 << 10
+
+// decide records one decision.
+func (h *Handler) decide(w http.ResponseWriter, r *http.Request, approve bool) {
+	caller := h.caller(w, r)
+	if caller == nil {
+		return
+	}
+	var body decisionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDecisionBody)).Decode(&body); err != nil {
+			httpjson.WriteError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+	a, err := h.deps.Decisions.Decide(r.Context(), *caller, r.PathValue("id"), approve, body.Reason)
+	if err != nil {
+		writeDecisionError(w, err)
+		return
+	}
+	httpjson.WriteJSON(w, http.StatusOK, a)
+}
+
+// writeDecisionError maps a decision-surface error to a status.
+func writeDecisionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, approvalgate.ErrNotFound):
+		httpjson.WriteError(w, http.StatusNotFound, "approval not found")
+	case errors.Is(err, approvalgate.ErrOwnRequest), errors.Is(err, approvalgate.ErrNotApprover):
+		httpjson.WriteError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, approvalgate.ErrNotPending):
+		httpjson.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		slog.Error("approvals: request failed", "error", err)
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to process the approval")
+	}
+}
+
+// caller resolves the authenticated user, writing a 401 when there is none.
+func (h *Handler) caller(w http.ResponseWriter, r *http.Request) *approvalgate.Caller {
+	c := h.deps.Caller(r)
+	if c == nil || c.UserID == "" {
+		httpjson.WriteError(w, http.StatusUnauthorized, "authentication required")
+		return nil
+	}
+	return c
+}
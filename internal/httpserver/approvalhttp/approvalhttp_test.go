@@ -0,0 +1,114 @@
+package approvalhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/internal/platform/approvalgate"
+)
+
+// fakeDecisions records what the handler asked for and answers canned
+// results.
+type fakeDecisions struct {
+	scope, status string
+	approve       bool
+	reason        string
+	err           error
+}
+
+func (f *fakeDecisions) List(_ context.Context, _ approvalgate.Caller, scope, status string) ([]approvalgate.Approval, error) {
+	f.scope, f.status = scope, status
+	return []approvalgate.Approval{{ID: "apr_1"}}, f.err
+}
+
+func (f *fakeDecisions) Get(_ context.Context, _ approvalgate.Caller, id string) (*approvalgate.Approval, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &approvalgate.Approval{ID: id}, nil
+}
+
+func (f *fakeDecisions) Decide(_ context.Context, _ approvalgate.Caller, id string, approve bool, reason string) (*approvalgate.Approval, error) {
+	f.approve, f.reason = approve, reason
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &approvalgate.Approval{ID: id, Status: approvalgate.StatusApproved}, nil
+}
+
+// serve runs one request as lead, or unauthenticated when anonymous.
+func serve(d Decisions, anonymous bool, method, target, body string) *httptest.ResponseRecorder {
+	h := New(Deps{
+		Decisions: d,
+		Caller: func(*http.Request) *approvalgate.Caller {
+			if anonymous {
+				return nil
+			}
+			return &approvalgate.Caller{UserID: "lead", Email: "lead@example.com"}
+		},
+	})
+	mux := http.NewServeMux()
+	h.Register(mux, func(next http.Handler) http.Handler { return next })
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequestWithContext(context.Background(), method, target, strings.NewReader(body)))
+	return w
+}
+
+func TestList_DefaultsToTheDecideScope(t *testing.T) {
+	d := &fakeDecisions{}
+	w := serve(d, false, http.MethodGet, "/api/v1/portal/approvals?status=pending", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, approvalgate.ScopeDecide, d.scope)
+	assert.Equal(t, approvalgate.StatusPending, d.status)
+	assert.Contains(t, w.Body.String(), "apr_1")
+}
+
+func TestList_RejectsUnknownFilters(t *testing.T) {
+	assert.Equal(t, http.StatusBadRequest, serve(&fakeDecisions{}, false, http.MethodGet, "/api/v1/portal/approvals?scope=everyone", "").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(&fakeDecisions{}, false, http.MethodGet, "/api/v1/portal/approvals?status=done", "").Code)
+}
+
+func TestRoutesRequireAuthentication(t *testing.T) {
+	w := serve(&fakeDecisions{}, true, http.MethodGet, "/api/v1/portal/approvals", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestDecide_PassesTheDecisionThrough(t *testing.T) {
+	d := &fakeDecisions{}
+	w := serve(d, false, http.MethodPost, "/api/v1/portal/approvals/apr_1/reject", `{"reason":"wrong customer"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, d.approve)
+	assert.Equal(t, "wrong customer", d.reason)
+
+	w = serve(d, false, http.MethodPost, "/api/v1/portal/approvals/apr_1/approve", "")
+	require.Equal(t, http.StatusOK, w.Code, "the reason is optional")
+	assert.True(t, d.approve)
+}
+
+func TestDecide_MapsErrorsToStatuses(t *testing.T) {
+	cases := map[error]int{
+		approvalgate.ErrNotFound:    http.StatusNotFound,
+		approvalgate.ErrOwnRequest:  http.StatusForbidden,
+		approvalgate.ErrNotApprover: http.StatusForbidden,
+		approvalgate.ErrNotPending:  http.StatusConflict,
+		assert.AnError:              http.StatusInternalServerError,
+	}
+	for err, want := range cases {
+		w := serve(&fakeDecisions{err: err}, false, http.MethodPost, "/api/v1/portal/approvals/apr_1/approve", "")
+		assert.Equal(t, want, w.Code, err.Error())
+	}
+}
+
+func TestRegister_NothingWithoutApprovals(t *testing.T) {
+	mux := http.NewServeMux()
+	New(Deps{}).Register(mux, func(next http.Handler) http.Handler { return next })
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/api/v1/portal/approvals", http.NoBody))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
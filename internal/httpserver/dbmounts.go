@@ -20,20 +20,27 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/txn2/mcp-data-platform/internal/httpserver/approvalhttp"
 	"github.com/txn2/mcp-data-platform/internal/httpserver/attachhttp"
 	"github.com/txn2/mcp-data-platform/internal/httpserver/mentionhttp"
+	"github.com/txn2/mcp-data-platform/internal/httpserver/scripthistoryhttp"
 	"github.com/txn2/mcp-data-platform/internal/httpserver/scripthttp"
 	"github.com/txn2/mcp-data-platform/internal/httpserver/versionhttp"
+	"github.com/txn2/mcp-data-platform/internal/platform/approvalgate"
 	"github.com/txn2/mcp-data-platform/internal/platform/connreach"
 	"github.com/txn2/mcp-data-platform/internal/platform/knowledgebuiltin"
 	"github.com/txn2/mcp-data-platform/internal/platform/notifydelivery"
 	"github.com/txn2/mcp-data-platform/internal/platform/resourceaudit"
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptdiff"
 	"github.com/txn2/mcp-data-platform/internal/platform/scriptdraft"
 	"github.com/txn2/mcp-data-platform/internal/platform/scriptstore"
 	"github.com/txn2/mcp-data-platform/pkg/browsersession"
 	"github.com/txn2/mcp-data-platform/pkg/middleware"
 	"github.com/txn2/mcp-data-platform/pkg/platform"
 	"github.com/txn2/mcp-data-platform/pkg/portal"
+	"github.com/txn2/mcp-data-platform/pkg/portal/embedtoken"
+	"github.com/txn2/mcp-data-platform/pkg/portal/signoff"
+	"github.com/txn2/mcp-data-platform/pkg/portal/spaces"
 	"github.com/txn2/mcp-data-platform/pkg/prompt"
 	"github.com/txn2/mcp-data-platform/pkg/resource"
 )
@@ -78,6 +85,8 @@ func mountPortalAPI(mux *http.ServeMux, p *platform.Platform, notify *notifydeli
 		CollectionStore:             p.PortalCollectionStore(),
 		ThreadStore:                 p.PortalThreadStore(),
 		KnowledgePageStore:          p.PortalKnowledgePageStore(),
+		SignoffStore:                signoff.NewPostgresStore(p.DB()),
+		SpaceStore:                  spaces.NewPostgresStore(p.DB()),
 		KnowledgePageDedupThreshold: p.Config().Knowledge.Pages.Resolve().DedupThreshold,
 		// The way back from hiding a built-in page (#1390); the seam no-ops on
 		// a store without the capability.
@@ -117,6 +126,17 @@ func mountPortalAPI(mux *http.ServeMux, p *platform.Platform, notify *notifydeli
 	// is present on this path (checked at the top of this function).
 	deps.ShareGuest = newShareGuestService(p, notify, p.PortalShareStore(), p.DB())
 
+	// Signed iframe embeds of one asset version; nil when portal.embed is off.
+	embed, err := embedtoken.New(p.Config().Portal.Embed)
+	if err != nil {
+		return err
+	}
+	if embed != nil {
+		deps.Embed = embed
+		deps.EmbedAudit = p.Audit().Logger()
+		log.Println("Portal embeds enabled")
+	}
+
 	// Authentication proves identity; the gate decides access. Every
 	// authenticated portal route runs through both, so an account the IdP will
 	// issue a token for but no persona claims reaches nothing. The public
@@ -127,9 +147,12 @@ func mountPortalAPI(mux *http.ServeMux, p *platform.Platform, notify *notifydeli
 	handler := portal.NewHandler(deps, wrap)
 	mux.Handle("/api/v1/portal/", handler)
 	mux.Handle("/portal/view/", handler)
+	mux.Handle("/portal/embed/", handler)
 	mountPromptVersionPortalAPI(mux, p, wrap, adminRoles)
 	mountScriptPortalAPI(mux, p, wrap, adminRoles)
+	mountScriptHistoryAPI(mux, p, wrap, adminRoles)
 	mountMentionAPI(mux, p, wrap, adminRoles)
+	mountApprovalAPI(mux, p, wrap, adminRoles, notify)
 	// Table registration serves both the portal's assets and the managed
 	// resources API, so it is mounted once here rather than beside each.
 	mountTableAPI(mux, p, wrap, adminRoles)
@@ -202,6 +225,31 @@ func mountScriptPortalAPI(mux *http.ServeMux, p *platform.Platform, wrap func(ht
 	scripthttp.New(deps).RegisterPortal(mux, wrap)
 }
 
+// mountScriptHistoryAPI registers the run comparison and retention routes
+// beside the script routes, over the same store, and reads compared output
+// versions from the portal's own storage.
+func mountScriptHistoryAPI(mux *http.ServeMux, p *platform.Platform, wrap func(http.Handler) http.Handler, adminRoles []string) {
+	if p.DB() == nil {
+		return
+	}
+	store := scriptstore.New(p.DB())
+	scripthistoryhttp.New(scripthistoryhttp.Deps{
+		Scripts: store, Runs: store, Retention: store,
+		Content: scriptdiff.PortalContent{Versions: p.PortalVersionStore(), Objects: p.PortalS3Client()},
+		Caller: func(r *http.Request) *scripthistoryhttp.Caller {
+			user := portal.GetUser(r.Context())
+			if user == nil {
+				return nil
+			}
+			owner := user.Email
+			if owner == "" {
+				owner = user.UserID
+			}
+			return &scripthistoryhttp.Caller{Owner: owner, IsAdmin: rolesIntersect(user.Roles, adminRoles)}
+		},
+	}).Register(mux, wrap)
+}
+
 // scriptDeps assembles the surface-independent script handler dependencies,
 // reporting ok=false when the deployment has nowhere to keep scripts.
 //
@@ -396,3 +444,30 @@ func mountMentionAPI(mux *http.ServeMux, p *platform.Platform, wrap func(http.Ha
 	}
 	mentionhttp.New(deps).Register(mux, wrap)
 }
+
+// mountApprovalAPI registers the approver's routes for the write-operation
+// approval workflow. Called from mountPortalAPI; a deployment with approvals
+// off, or without a database to keep them in, registers nothing. The decision
+// surface builds its own store over the pool, as the gate in the middleware
+// chain does: both are stateless over it.
+func mountApprovalAPI(mux *http.ServeMux, p *platform.Platform, wrap func(http.Handler) http.Handler, adminRoles []string, notify *notifydelivery.Handle) {
+	cfg := p.Config().Approvals
+	if !cfg.Enabled || p.DB() == nil {
+		return
+	}
+	deps := approvalgate.DeciderDeps{
+		Store:   approvalgate.NewPostgresStore(p.DB()),
+		Audit:   p.Audit().Logger(),
+		TTL:     cfg.EffectiveTTL(),
+		BaseURL: p.Config().Portal.PublicBaseURL,
+	}
+	// Assign only a live enqueuer: a typed nil would read as wired.
+	if enq := notify.Enqueuer(); enq != nil {
+		deps.Notifier = enq
+	}
+	approvalhttp.New(approvalhttp.Deps{
+		Decisions: approvalgate.NewDecider(deps),
+		Caller:    approvalCaller(p.PersonaRegistry(), adminRoles),
+	}).Register(mux, wrap)
+	log.Println("Approval workflow enabled on /api/v1/portal/approvals")
+}
@@ -15,10 +15,13 @@ import (
 	"github.com/txn2/mcp-data-platform/internal/httpserver/gatewayhttp"
 	"github.com/txn2/mcp-data-platform/internal/httpserver/httpauth"
 	"github.com/txn2/mcp-data-platform/internal/httpserver/scripthttp"
+	"github.com/txn2/mcp-data-platform/internal/platform/approvalgate"
 	"github.com/txn2/mcp-data-platform/internal/platform/callrecord"
 	"github.com/txn2/mcp-data-platform/internal/platform/connreach"
 	"github.com/txn2/mcp-data-platform/internal/platform/notifydelivery"
 	"github.com/txn2/mcp-data-platform/internal/platform/reviewalert"
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptquota"
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptsecret"
 	"github.com/txn2/mcp-data-platform/internal/platform/sessionview"
 	"github.com/txn2/mcp-data-platform/internal/ui"
 	"github.com/txn2/mcp-data-platform/pkg/admin"
@@ -287,6 +290,31 @@ func buildResourceClaims(user *portal.User, pr *persona.Registry, adminPersona s
 	return claims, nil
 }
 
+// approvalCaller adapts the portal auth context to the approval workflow's
+// caller: the personas the user's roles admit them to, which is what a rule's
+// persona approvers are matched against, and whether they are an admin.
+func approvalCaller(pr *persona.Registry, adminRoles []string) func(*http.Request) *approvalgate.Caller {
+	return func(r *http.Request) *approvalgate.Caller {
+		user := portal.GetUser(r.Context())
+		if user == nil {
+			return nil
+		}
+		c := &approvalgate.Caller{
+			UserID:  user.UserID,
+			Email:   user.Email,
+			IsAdmin: rolesIntersect(user.Roles, adminRoles),
+		}
+		if pr != nil {
+			for _, per := range pr.All() {
+				if matchesAnyRole(per.Roles, user.Roles) {
+					c.Personas = append(c.Personas, per.Name)
+				}
+			}
+		}
+		return c
+	}
+}
+
 // personaAdminInfix is the role substring that marks a persona-admin grant.
 // Roles may carry an arbitrary prefix (e.g., "dp_persona-admin:finance").
 const personaAdminInfix = "persona-admin:"
@@ -509,7 +537,12 @@ func scriptConnectionEnumerator(lister *connreach.Lister) scripthttp.ConnectionE
 		return nil
 	}
 	return func(ctx context.Context, caller scripthttp.ConnectionScope) []scripthttp.ConnectionChoice {
-		conns := lister.ForPersona(ctx, caller.Persona, caller.Unrestricted)
+		var conns []connreach.Connection
+		if caller.Roles != nil {
+			conns = lister.ForRoles(ctx, caller.Roles)
+		} else {
+			conns = lister.ForPersona(ctx, caller.Persona, caller.Unrestricted)
+		}
 		choices := make([]scripthttp.ConnectionChoice, 0, len(conns))
 		for _, c := range conns {
 			choices = append(choices, scripthttp.ConnectionChoice{
@@ -658,6 +691,11 @@ func buildAdminHandler(p *platform.Platform, notify *notifydelivery.Handle) http
 	// persona. No database, no history to derive a session from, no routes.
 	if db := p.DB(); db != nil {
 		deps.SessionViewer = sessionview.NewPostgresStore(db)
+		// Script usage is summed from the run history, so it needs the same
+		// database the runs are in, and nothing else.
+		deps.ScriptQuotas = scriptquota.New(db)
+		// Secrets are sealed with the key connection credentials use.
+		deps.ScriptSecrets = scriptsecret.New(db, p.RestEncryptor())
 	}
 	deps.CallCatalog, deps.CallPromoter = callCatalog(p)
 
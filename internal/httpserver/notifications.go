@@ -10,6 +10,7 @@ import (
 	"github.com/txn2/mcp-data-platform/internal/platform/branding"
 	"github.com/txn2/mcp-data-platform/internal/platform/notifydelivery"
 	"github.com/txn2/mcp-data-platform/internal/platform/reviewalert"
+	"github.com/txn2/mcp-data-platform/internal/platform/watchalert"
 	"github.com/txn2/mcp-data-platform/pkg/platform"
 	"github.com/txn2/mcp-data-platform/pkg/portal"
 	"github.com/txn2/mcp-data-platform/pkg/portal/mention"
@@ -71,6 +72,28 @@ func buildReviewAlert(p *platform.Platform, notify *notifydelivery.Handle) *revi
 	return checker
 }
 
+// buildWatchAlert assembles the sweep that tells watchers about changes to
+// what they watch. Returns nil (a no-op checker) under the same conditions as
+// the review alert: no database or notifications off. Schema changes are only
+// detected when the semantic provider can resolve a dataset URN.
+func buildWatchAlert(p *platform.Platform, notify *notifydelivery.Handle) *watchalert.Checker {
+	if p == nil || p.DB() == nil || !p.Config().Notifications.IsEnabled() {
+		return nil
+	}
+	checker := watchalert.New(watchalert.Config{
+		Store:    watchalert.NewPostgresStore(p.DB()),
+		Watches:  notify.Watches(),
+		Audience: mentionAudience(p),
+		Schema:   watchalert.NewSemanticSchema(p.SemanticProvider()),
+		Enqueuer: notify.Enqueuer(),
+		BaseURL:  p.Config().Portal.PublicBaseURL,
+	})
+	if checker != nil {
+		log.Println("Watch change notifications enabled")
+	}
+	return checker
+}
+
 // buildNotifications assembles the email-notification substrate from the
 // platform's database, encryptor, and branding. Returns nil when the
 // feature is unavailable (no platform, no database) or disabled by config;
@@ -176,7 +199,8 @@ const portalToolkitKind = "portal"
 
 // wirePortalNotifications attaches the notification substrate to the portal
 // dependency set: the share/thread trigger bridge and the self-scoped
-// preference routes. A nil handle leaves both unset (feature unavailable).
+// preference, channel, history, and inbox routes. A nil handle leaves both
+// unset (feature unavailable).
 func wirePortalNotifications(deps *portal.Deps, p *platform.Platform, notify *notifydelivery.Handle) {
 	if notify == nil {
 		return
@@ -208,6 +232,11 @@ func wirePortalNotifications(deps *portal.Deps, p *platform.Platform, notify *no
 		// SMTP path exists rather than offering live controls over a
 		// preference nothing can act on (#1099).
 		Settings:  notify.Settings(),
+		Channels:  notify.Channels(),
+		UserEmail: callerEmail,
+	}
+	channelsAPI := &notifyhttp.ChannelsAPI{
+		Store:     notify.Channels(),
 		UserEmail: callerEmail,
 	}
 	historyAPI := &notifyhttp.HistoryAPI{
@@ -215,10 +244,23 @@ func wirePortalNotifications(deps *portal.Deps, p *platform.Platform, notify *no
 		UserEmail: callerEmail,
 		Retention: notifydelivery.HistoryRetention,
 	}
-	// Both surfaces are self-scoped to the same caller identity, so they
-	// resolve it through one function rather than two spellings of it.
+	watchesAPI := &notifyhttp.WatchesAPI{
+		Store:     notify.Watches(),
+		UserEmail: callerEmail,
+	}
+	inboxAPI := &notifyhttp.InboxAPI{
+		Store:     notify.Inbox(),
+		Wakeups:   notify.InboxWakeups(),
+		UserEmail: callerEmail,
+		Retention: notifydelivery.HistoryRetention,
+	}
+	// The surfaces are self-scoped to the same caller identity, so they
+	// resolve it through one function rather than five spellings of it.
 	deps.NotificationRegistrar = func(mux *http.ServeMux) {
 		prefsAPI.Register(mux)
+		channelsAPI.Register(mux)
 		historyAPI.Register(mux)
+		watchesAPI.Register(mux)
+		inboxAPI.Register(mux)
 	}
 }
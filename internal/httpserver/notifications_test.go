@@ -73,6 +73,21 @@ func TestBuildReviewAlert_NoDatabase(t *testing.T) {
 	buildReviewAlert(p, nil).Stop()
 }
 
+// TestBuildWatchAlert_NoDatabase covers the watch sweep's degraded path; like
+// the review alert, its database-present path needs a live Postgres.
+func TestBuildWatchAlert_NoDatabase(t *testing.T) {
+	if got := buildWatchAlert(nil, nil); got != nil {
+		t.Error("nil platform must yield no checker")
+	}
+	p := newTestPlatform(t, &platform.Config{})
+	defer func() { _ = p.Close() }()
+	if got := buildWatchAlert(p, nil); got != nil {
+		t.Error("no database must yield no checker")
+	}
+	buildWatchAlert(p, nil).Start(context.Background())
+	buildWatchAlert(p, nil).Stop()
+}
+
 // TestReviewAlertSettings_NotificationsDisabled: with notifications off in
 // YAML the admin routes must not mount, so an operator cannot configure an
 // alert that nothing will ever send. This mirrors the SMTP section, whose
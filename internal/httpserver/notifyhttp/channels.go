@@ -0,0 +1,196 @@
+package notifyhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// ChannelsAPI serves the caller's own chat and webhook channels: the Slack,
+// Teams, or signed-webhook destinations a category can be routed to.
+//
+// It is self-scoped the way PrefsAPI is: the authenticated caller's address is
+// the only owner it lists, creates, or deletes under. A channel's URL is a
+// credential, so it is accepted and never returned; a listing shows the host
+// it points at.
+type ChannelsAPI struct {
+	Store notification.ChannelStore
+	// UserEmail resolves the authenticated user's email from the request,
+	// returning "" when unauthenticated.
+	UserEmail func(*http.Request) string
+}
+
+// ChannelResponse is one of the caller's channels.
+type ChannelResponse struct {
+	Name    string `json:"name" example:"team-alerts"`
+	Kind    string `json:"kind" example:"slack"`
+	URLHint string `json:"url_hint" example:"hooks.slack.com"`
+	// SigningSecret is returned once, by the request that creates a webhook
+	// channel; the receiver verifies each post with it.
+	SigningSecret string    `json:"signing_secret,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ChannelListResponse is the caller's channels, by name.
+type ChannelListResponse struct {
+	Data []ChannelResponse `json:"data"`
+}
+
+// ChannelRequest is the body for registering a channel.
+type ChannelRequest struct {
+	Name string `json:"name" example:"team-alerts"`
+	// Kind is webhook, slack, or teams.
+	Kind string `json:"kind" example:"slack"`
+	// URL is the incoming-webhook address, https only.
+	URL string `json:"url" example:"https://hooks.slack.com/services/T000/B000/XXXX"`
+}
+
+// Register mounts the channel endpoints on mux.
+func (a *ChannelsAPI) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/portal/notification-channels", a.list)
+	mux.HandleFunc("POST /api/v1/portal/notification-channels", a.create)
+	mux.HandleFunc("DELETE /api/v1/portal/notification-channels/{name}", a.remove)
+}
+
+// list handles GET /api/v1/portal/notification-channels.
+//
+// @Summary      List my notification channels
+// @Description  Returns the calling user's chat and webhook notification channels by name. URLs and signing secrets are never returned; url_hint names the host a channel posts to.
+// @Tags         Notifications
+// @Produce      json
+// @Success      200  {object}  ChannelListResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/notification-channels [get]
+func (a *ChannelsAPI) list(w http.ResponseWriter, r *http.Request) {
+	email := a.callerEmail(w, r)
+	if email == "" {
+		return
+	}
+	channels, err := a.Store.List(r.Context(), email)
+	if err != nil {
+		writePrefsError(w, http.StatusInternalServerError, "reading notification channels failed")
+		return
+	}
+	data := make([]ChannelResponse, 0, len(channels))
+	for _, c := range channels {
+		data = append(data, channelResponse(c))
+	}
+	writePrefsJSON(w, ChannelListResponse{Data: data})
+}
+
+// create handles POST /api/v1/portal/notification-channels.
+//
+// @Summary      Register a notification channel
+// @Description  Registers a channel a notification category can be routed to: a Slack-compatible incoming webhook, a Microsoft Teams webhook, or a generic https webhook. A webhook channel's response carries its signing secret, once; each post is signed with HMAC-SHA256 over the timestamp header, a period, and the body.
+// @Tags         Notifications
+// @Accept       json
+// @Produce      json
+// @Param        request  body  ChannelRequest  true  "Channel to register"
+// @Success      201  {object}  ChannelResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/notification-channels [post]
+func (a *ChannelsAPI) create(w http.ResponseWriter, r *http.Request) {
+	email := a.callerEmail(w, r)
+	if email == "" {
+		return
+	}
+	var req ChannelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writePrefsError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := notification.ValidateChannelName(req.Name); err != nil {
+		writePrefsError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !notification.ValidChannelKind(req.Kind) {
+		writePrefsError(w, http.StatusBadRequest, "kind must be webhook, slack, or teams")
+		return
+	}
+	if _, err := notification.ValidateChannelURL(req.URL); err != nil {
+		writePrefsError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	created, err := a.Store.Create(r.Context(), notification.Channel{
+		Email: email, Name: req.Name, Kind: req.Kind, URL: req.URL,
+	})
+	switch {
+	case errors.Is(err, notification.ErrChannelNameTaken):
+		writePrefsError(w, http.StatusConflict, "you already have a channel by that name")
+		return
+	case errors.Is(err, notification.ErrChannelLimit):
+		writePrefsError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		slog.Warn("notification: storing a channel failed", logKeyError, err)
+		writePrefsError(w, http.StatusInternalServerError, "storing notification channel failed")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(channelResponse(*created))
+}
+
+// remove handles DELETE /api/v1/portal/notification-channels/{name}.
+//
+// @Summary      Delete a notification channel
+// @Description  Deletes one of the calling user's channels and drops whatever is queued for it. A category still routed to it falls back to email.
+// @Tags         Notifications
+// @Param        name  path  string  true  "Channel name"
+// @Success      204
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/notification-channels/{name} [delete]
+func (a *ChannelsAPI) remove(w http.ResponseWriter, r *http.Request) {
+	email := a.callerEmail(w, r)
+	if email == "" {
+		return
+	}
+	err := a.Store.Delete(r.Context(), email, r.PathValue("name"))
+	if errors.Is(err, notification.ErrChannelNotFound) {
+		writePrefsError(w, http.StatusNotFound, "no channel by that name")
+		return
+	}
+	if err != nil {
+		writePrefsError(w, http.StatusInternalServerError, "deleting notification channel failed")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// callerEmail resolves the authenticated caller, writing a 401 when absent.
+func (a *ChannelsAPI) callerEmail(w http.ResponseWriter, r *http.Request) string {
+	email := ""
+	if a.UserEmail != nil {
+		// Channels are keyed by the address the queue keys rows by.
+		email = notification.NormalizeAddress(a.UserEmail(r))
+	}
+	if email == "" {
+		writePrefsError(w, http.StatusUnauthorized, "authentication required")
+	}
+	return email
+}
+
+// channelResponse maps a stored channel to the API shape.
+func channelResponse(c notification.Channel) ChannelResponse {
+	return ChannelResponse{
+		Name:          c.Name,
+		Kind:          c.Kind,
+		URLHint:       c.URLHint,
+		SigningSecret: c.SigningSecret,
+		CreatedAt:     c.CreatedAt,
+	}
+}
@@ -0,0 +1,158 @@
+package notifyhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// fakeChannelHTTPStore keeps channels in memory, keyed by owner and name.
+type fakeChannelHTTPStore struct {
+	byOwner map[string]map[string]notification.Channel
+}
+
+func newFakeChannelHTTPStore() *fakeChannelHTTPStore {
+	return &fakeChannelHTTPStore{byOwner: map[string]map[string]notification.Channel{}}
+}
+
+func (f *fakeChannelHTTPStore) List(_ context.Context, email string) ([]notification.Channel, error) {
+	out := []notification.Channel{}
+	for _, c := range f.byOwner[email] {
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func (f *fakeChannelHTTPStore) Create(_ context.Context, c notification.Channel) (*notification.Channel, error) {
+	if _, taken := f.byOwner[c.Email][c.Name]; taken {
+		return nil, notification.ErrChannelNameTaken
+	}
+	if f.byOwner[c.Email] == nil {
+		f.byOwner[c.Email] = map[string]notification.Channel{}
+	}
+	c.URLHint = "hooks.example.com"
+	f.byOwner[c.Email][c.Name] = c
+	if c.Kind == notification.ChannelWebhook {
+		c.SigningSecret = "whsec_k"
+	}
+	return &c, nil
+}
+
+func (f *fakeChannelHTTPStore) Delete(_ context.Context, email, name string) error {
+	if _, ok := f.byOwner[email][name]; !ok {
+		return notification.ErrChannelNotFound
+	}
+	delete(f.byOwner[email], name)
+	return nil
+}
+
+func (f *fakeChannelHTTPStore) Lookup(_ context.Context, email, name string) (int64, error) {
+	if _, ok := f.byOwner[email][name]; !ok {
+		return 0, notification.ErrChannelNotFound
+	}
+	return 1, nil
+}
+
+func (*fakeChannelHTTPStore) Get(context.Context, int64) (*notification.Channel, error) {
+	return nil, notification.ErrChannelNotFound
+}
+
+func doChannelReq(t *testing.T, mux *http.ServeMux, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	b, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequestWithContext(context.Background(), method, "/api/v1/portal/notification-channels"+path, bytes.NewReader(b))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+// TestChannelsAPI_Lifecycle registers, lists, and deletes a channel, and
+// checks the URL never comes back while the signing secret comes back once.
+func TestChannelsAPI_Lifecycle(t *testing.T) {
+	store := newFakeChannelHTTPStore()
+	api := &ChannelsAPI{Store: store, UserEmail: func(*http.Request) string { return "A@B.io" }}
+	mux := http.NewServeMux()
+	api.Register(mux)
+
+	res := doChannelReq(t, mux, http.MethodPost, "", ChannelRequest{
+		Name: "oncall", Kind: notification.ChannelWebhook, URL: "https://hooks.example.com/secret-token",
+	})
+	if res.Code != http.StatusCreated || !strings.Contains(res.Body.String(), "whsec_k") {
+		t.Fatalf("create: %d %s", res.Code, res.Body)
+	}
+	if _, ok := store.byOwner["a@b.io"]["oncall"]; !ok {
+		t.Error("the channel must be keyed by the normalized caller")
+	}
+	if res := doChannelReq(t, mux, http.MethodPost, "", ChannelRequest{
+		Name: "oncall", Kind: notification.ChannelSlack, URL: "https://hooks.slack.com/x",
+	}); res.Code != http.StatusConflict {
+		t.Errorf("duplicate: status = %d; want 409", res.Code)
+	}
+
+	res = doChannelReq(t, mux, http.MethodGet, "", nil)
+	if res.Code != http.StatusOK || strings.Contains(res.Body.String(), "secret-token") || strings.Contains(res.Body.String(), "whsec_k") {
+		t.Errorf("list leaks a credential or failed: %d %s", res.Code, res.Body)
+	}
+
+	if res := doChannelReq(t, mux, http.MethodDelete, "/oncall", nil); res.Code != http.StatusNoContent {
+		t.Errorf("delete: status = %d", res.Code)
+	}
+	if res := doChannelReq(t, mux, http.MethodDelete, "/oncall", nil); res.Code != http.StatusNotFound {
+		t.Errorf("second delete: status = %d; want 404", res.Code)
+	}
+}
+
+func TestChannelsAPI_CreateRefusals(t *testing.T) {
+	api := &ChannelsAPI{Store: newFakeChannelHTTPStore(), UserEmail: func(*http.Request) string { return "a@b.io" }}
+	mux := http.NewServeMux()
+	api.Register(mux)
+	for name, req := range map[string]ChannelRequest{
+		"name email": {Name: notification.RouteEmail, Kind: notification.ChannelSlack, URL: "https://hooks.slack.com/x"},
+		"bad kind":   {Name: "team", Kind: "irc", URL: "https://hooks.slack.com/x"},
+		"plain http": {Name: "team", Kind: notification.ChannelTeams, URL: "http://example.webhook.office.com/x"},
+	} {
+		if res := doChannelReq(t, mux, http.MethodPost, "", req); res.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d; want 400", name, res.Code)
+		}
+	}
+
+	anonymous := &ChannelsAPI{Store: newFakeChannelHTTPStore(), UserEmail: func(*http.Request) string { return "" }}
+	anonMux := http.NewServeMux()
+	anonymous.Register(anonMux)
+	if res := doChannelReq(t, anonMux, http.MethodGet, "", nil); res.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous: status = %d; want 401", res.Code)
+	}
+}
+
+// TestPrefsAPI_Put_Routes accepts a route to one of the caller's channels and
+// refuses one naming a channel the caller does not have.
+func TestPrefsAPI_Put_Routes(t *testing.T) {
+	channels := newFakeChannelHTTPStore()
+	channels.byOwner["a@b.io"] = map[string]notification.Channel{"team": {Name: "team"}}
+	store := &fakePrefsHTTPStore{}
+	api := &PrefsAPI{Store: store, Channels: channels, UserEmail: func(*http.Request) string { return "a@b.io" }}
+	mux := http.NewServeMux()
+	api.Register(mux)
+
+	routes := map[string][]string{notification.CategoryScriptRun: {"team", notification.RouteEmail}}
+	if res := doPrefsReq(t, mux, http.MethodPut, PrefsRequest{Routes: &routes}); res.Code != http.StatusOK {
+		t.Fatalf("status = %d; want 200: %s", res.Code, res.Body)
+	}
+	if store.last == nil || store.last.Routes == nil || len((*store.last.Routes)[notification.CategoryScriptRun]) != 2 {
+		t.Errorf("routes not passed to the store: %+v", store.last)
+	}
+
+	foreign := map[string][]string{notification.CategoryMention: {"someone-elses"}}
+	if res := doPrefsReq(t, mux, http.MethodPut, PrefsRequest{Routes: &foreign}); res.Code != http.StatusBadRequest {
+		t.Errorf("foreign channel: status = %d; want 400", res.Code)
+	}
+}
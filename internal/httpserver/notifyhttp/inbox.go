@@ -0,0 +1,378 @@
+package notifyhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/internal/httpjson"
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// Inbox stream pacing.
+const (
+	// DefaultInboxPoll is how often an open stream recounts without a wakeup.
+	// It bounds how stale the badge can be when LISTEN is unavailable, and it
+	// doubles as the keepalive that stops proxies closing an idle stream.
+	DefaultInboxPoll = 25 * time.Second
+	// inboxRecountGap is the least time between two recounts of one stream.
+	// A wakeup reaches only the streams of the person it was for, but a burst
+	// of notifications to one person would otherwise cost each of their
+	// streams a count per row.
+	inboxRecountGap = time.Second
+	// maxInboxStateIDs caps one state change, the same as one page.
+	maxInboxStateIDs = notification.MaxHistoryLimit
+)
+
+// Wakeups delivers a signal whenever one person's inbox may have changed.
+// notifyqueue.Fanout implements it over the inbox channel's LISTEN wakeup.
+type Wakeups interface {
+	// Subscribe returns a channel that receives a value after each change to
+	// recipient's inbox, and the func that ends the subscription.
+	Subscribe(recipient string) (wake <-chan struct{}, cancel func())
+}
+
+// InboxAPI serves a user's in-app notification inbox: each event the platform
+// queued for them, once, with the read and archived state they gave it, and a
+// live unread count for the portal's badge.
+//
+// It is self-scoped like HistoryAPI: the caller's address is the only
+// recipient queried or written, so an id naming someone else's notification
+// changes nothing.
+type InboxAPI struct {
+	// Store reads and writes the inbox.
+	Store notification.InboxStore
+	// Wakeups prompts open streams to recount. Nil leaves them on the poll.
+	Wakeups Wakeups
+	// UserEmail resolves the authenticated user's email from the request,
+	// returning "" when unauthenticated.
+	UserEmail func(*http.Request) string
+	// Retention is the window the inbox covers, as HistoryAPI reports it.
+	Retention time.Duration
+	// Poll overrides DefaultInboxPoll; zero uses the default.
+	Poll time.Duration
+}
+
+// InboxItem is one inbox entry: the history shape plus the reader's state.
+type InboxItem struct {
+	HistoryItem
+	State string `json:"state"`
+}
+
+// InboxResponse is one page of the caller's inbox.
+type InboxResponse struct {
+	Data    []InboxItem `json:"data"`
+	Total   int         `json:"total"`
+	Unread  int         `json:"unread"`
+	Page    int         `json:"page"`
+	PerPage int         `json:"per_page"`
+	// RetentionDays is the window the inbox covers. Zero means the
+	// deployment did not report one.
+	RetentionDays int `json:"retention_days"`
+}
+
+// InboxStateRequest moves entries to a state.
+type InboxStateRequest struct {
+	IDs   []int64 `json:"ids"`
+	State string  `json:"state"`
+}
+
+// InboxCountResponse reports a change and the unread count after it.
+type InboxCountResponse struct {
+	Updated int64 `json:"updated,omitempty"`
+	Unread  int   `json:"unread"`
+}
+
+// Register mounts the inbox endpoints on mux.
+func (a *InboxAPI) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/portal/notifications/inbox", a.list)
+	mux.HandleFunc("GET /api/v1/portal/notifications/inbox/unread", a.unread)
+	mux.HandleFunc("GET /api/v1/portal/notifications/inbox/stream", a.stream)
+	mux.HandleFunc("POST /api/v1/portal/notifications/inbox/state", a.setState)
+	mux.HandleFunc("POST /api/v1/portal/notifications/inbox/read-all", a.readAll)
+}
+
+// list handles GET /api/v1/portal/notifications/inbox.
+//
+// @Summary      List my inbox
+// @Description  Returns the calling user's in-app inbox, newest first: one entry per event however many channels it was delivered to, each with its read state. Without a state filter archived entries are left out. Server-side self-scoped and bounded by the queue's retention window.
+// @Tags         Notifications
+// @Produce      json
+// @Param        state     query  string   false  "Filter by state (unread, read, archived)"
+// @Param        page      query  integer  false  "Page number, 1-based (default: 1)"
+// @Param        per_page  query  integer  false  "Results per page (default: 50, max: 200)"
+// @Success      200  {object}  InboxResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/notifications/inbox [get]
+func (a *InboxAPI) list(w http.ResponseWriter, r *http.Request) {
+	email := a.callerEmail(w, r)
+	if email == "" {
+		return
+	}
+	q := r.URL.Query()
+	filter := notification.InboxFilter{State: q.Get("state"), Limit: httpjson.ParseLimit(q)}
+	if filter.State != "" && !notification.ValidInboxState(filter.State) {
+		writePrefsError(w, http.StatusBadRequest, "state must be unread, read, or archived")
+		return
+	}
+	filter.Offset = httpjson.ParsePageOffset(q, filter.EffectiveLimit())
+	filter.Recipient = email
+
+	items, err := a.Store.Inbox(r.Context(), filter)
+	if err != nil {
+		writePrefsError(w, http.StatusInternalServerError, "reading notification inbox failed")
+		return
+	}
+	countFilter := filter
+	countFilter.Limit, countFilter.Offset = 0, 0
+	total, err := a.Store.InboxCount(r.Context(), countFilter)
+	if err != nil {
+		writePrefsError(w, http.StatusInternalServerError, "reading notification inbox failed")
+		return
+	}
+	unread, err := a.Store.Unread(r.Context(), email)
+	if err != nil {
+		writePrefsError(w, http.StatusInternalServerError, "reading notification inbox failed")
+		return
+	}
+
+	limit := filter.EffectiveLimit()
+	data := make([]InboxItem, 0, len(items))
+	for _, it := range items {
+		data = append(data, InboxItem{HistoryItem: historyItem(it.Notification), State: it.State})
+	}
+	writePrefsJSON(w, InboxResponse{
+		Data:          data,
+		Total:         total,
+		Unread:        unread,
+		Page:          filter.Offset/limit + 1,
+		PerPage:       limit,
+		RetentionDays: int(a.Retention / (24 * time.Hour)),
+	})
+}
+
+// unread handles GET /api/v1/portal/notifications/inbox/unread.
+//
+// @Summary      Count my unread notifications
+// @Description  Returns how many of the calling user's inbox entries are unread.
+// @Tags         Notifications
+// @Produce      json
+// @Success      200  {object}  InboxCountResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/notifications/inbox/unread [get]
+func (a *InboxAPI) unread(w http.ResponseWriter, r *http.Request) {
+	email := a.callerEmail(w, r)
+	if email == "" {
+		return
+	}
+	a.writeUnread(w, r, email, 0)
+}
+
+// setState handles POST /api/v1/portal/notifications/inbox/state.
+//
+// @Summary      Mark inbox entries
+// @Description  Moves the named entries of the calling user's inbox to unread, read, or archived, and returns the unread count after the change. An id that is not one of the caller's entries is ignored.
+// @Tags         Notifications
+// @Accept       json
+// @Produce      json
+// @Param        request  body  InboxStateRequest  true  "Entries and their new state"
+// @Success      200  {object}  InboxCountResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/notifications/inbox/state [post]
+func (a *InboxAPI) setState(w http.ResponseWriter, r *http.Request) {
+	email := a.callerEmail(w, r)
+	if email == "" {
+		return
+	}
+	var req InboxStateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writePrefsError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if !notification.ValidInboxState(req.State) {
+		writePrefsError(w, http.StatusBadRequest, "state must be unread, read, or archived")
+		return
+	}
+	if len(req.IDs) == 0 || len(req.IDs) > maxInboxStateIDs {
+		writePrefsError(w, http.StatusBadRequest, fmt.Sprintf("ids must name 1 to %d entries", maxInboxStateIDs))
+		return
+	}
+	updated, err := a.Store.SetInboxState(r.Context(), email, req.IDs, req.State)
+	if err != nil {
+		writePrefsError(w, http.StatusInternalServerError, "updating notification inbox failed")
+		return
+	}
+	a.writeUnread(w, r, email, updated)
+}
+
+// readAll handles POST /api/v1/portal/notifications/inbox/read-all.
+//
+// @Summary      Mark my inbox read
+// @Description  Marks every unread entry of the calling user's inbox read. Archived entries keep their state.
+// @Tags         Notifications
+// @Produce      json
+// @Success      200  {object}  InboxCountResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/notifications/inbox/read-all [post]
+func (a *InboxAPI) readAll(w http.ResponseWriter, r *http.Request) {
+	email := a.callerEmail(w, r)
+	if email == "" {
+		return
+	}
+	updated, err := a.Store.MarkAllRead(r.Context(), email)
+	if err != nil {
+		writePrefsError(w, http.StatusInternalServerError, "updating notification inbox failed")
+		return
+	}
+	a.writeUnread(w, r, email, updated)
+}
+
+// writeUnread answers with the caller's unread count, so a client that just
+// changed state can set its badge without a second request.
+func (a *InboxAPI) writeUnread(w http.ResponseWriter, r *http.Request, email string, updated int64) {
+	unread, err := a.Store.Unread(r.Context(), email)
+	if err != nil {
+		writePrefsError(w, http.StatusInternalServerError, "reading notification inbox failed")
+		return
+	}
+	writePrefsJSON(w, InboxCountResponse{Updated: updated, Unread: unread})
+}
+
+// stream handles GET /api/v1/portal/notifications/inbox/stream.
+//
+// @Summary      Stream my unread count
+// @Description  A server-sent event stream of the calling user's unread count. An "unread" event carrying {"unread": n} is sent on connect and again whenever the count changes: on a new notification, or on a state change made from another tab. The count is re-read on each wakeup for the caller's own inbox and at least every 25 seconds, and an unchanged poll sends a comment line as a keepalive.
+// @Tags         Notifications
+// @Produce      text/event-stream
+// @Success      200
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/notifications/inbox/stream [get]
+func (a *InboxAPI) stream(w http.ResponseWriter, r *http.Request) {
+	email := a.callerEmail(w, r)
+	if email == "" {
+		return
+	}
+	ctx := r.Context()
+	// The first count is read before anything is committed, so a broken store
+	// is answered with an ordinary error rather than an empty stream.
+	last, err := a.Store.Unread(ctx, email)
+	if err != nil {
+		writePrefsError(w, http.StatusInternalServerError, "reading notification inbox failed")
+		return
+	}
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Proxies that buffer responses would hold every update back.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if !writeUnreadEvent(w, rc, last) {
+		return
+	}
+
+	var wake <-chan struct{}
+	if a.Wakeups != nil {
+		var cancel func()
+		wake, cancel = a.Wakeups.Subscribe(email)
+		defer cancel()
+	}
+	poll := time.NewTicker(a.pollInterval())
+	defer poll.Stop()
+	for {
+		polled := false
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+		case <-poll.C:
+			polled = true
+		}
+		n, err := a.Store.Unread(ctx, email)
+		switch {
+		case err == nil && n != last:
+			last = n
+			if !writeUnreadEvent(w, rc, n) {
+				return
+			}
+		case polled:
+			// A failed recount is retried on the next wakeup; the keepalive
+			// goes out either way.
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			_ = rc.Flush()
+		}
+		if !pause(ctx, inboxRecountGap) {
+			return
+		}
+	}
+}
+
+// pollInterval resolves the stream's poll period.
+func (a *InboxAPI) pollInterval() time.Duration {
+	if a.Poll > 0 {
+		return a.Poll
+	}
+	return DefaultInboxPoll
+}
+
+// writeUnreadEvent writes one unread event and flushes it, reporting whether
+// the reader is still there.
+func writeUnreadEvent(w http.ResponseWriter, rc *http.ResponseController, n int) bool {
+	body, err := json.Marshal(InboxCountResponse{Unread: n})
+	if err != nil {
+		return false
+	}
+	if _, err := fmt.Fprintf(w, "event: unread\ndata: %s\n\n", body); err != nil {
+		return false
+	}
+	_ = rc.Flush()
+	return true
+}
+
+// pause waits d, reporting false when ctx ends first. Wakeups that arrive
+// meanwhile are held by the subscription's one-slot buffer, so the next
+// recount still sees them.
+func pause(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// callerEmail resolves the authenticated caller, writing a 401 when absent.
+func (a *InboxAPI) callerEmail(w http.ResponseWriter, r *http.Request) string {
+	email := ""
+	if a.UserEmail != nil {
+		// The inbox is keyed by the address the queue keys rows by.
+		email = notification.NormalizeAddress(a.UserEmail(r))
+	}
+	if email == "" {
+		writePrefsError(w, http.StatusUnauthorized, "authentication required")
+	}
+	return email
+}
@@ -0,0 +1,221 @@
+package notifyhttp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// fakeInboxStore records who it was asked about and returns canned answers.
+type fakeInboxStore struct {
+	mu         sync.Mutex
+	items      []notification.InboxItem
+	unread     int
+	err        error
+	lastFilter notification.InboxFilter
+	lastOwner  string
+	lastIDs    []int64
+	lastState  string
+}
+
+func (f *fakeInboxStore) Inbox(_ context.Context, filter notification.InboxFilter) ([]notification.InboxItem, error) {
+	f.lastFilter = filter
+	return f.items, f.err
+}
+
+func (f *fakeInboxStore) InboxCount(context.Context, notification.InboxFilter) (int, error) {
+	return len(f.items), f.err
+}
+
+func (f *fakeInboxStore) Unread(_ context.Context, recipient string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastOwner = recipient
+	return f.unread, f.err
+}
+
+func (f *fakeInboxStore) SetInboxState(_ context.Context, recipient string, ids []int64, state string) (int64, error) {
+	f.lastOwner, f.lastIDs, f.lastState = recipient, ids, state
+	return int64(len(ids)), f.err
+}
+
+func (f *fakeInboxStore) MarkAllRead(_ context.Context, recipient string) (int64, error) {
+	f.lastOwner = recipient
+	return 1, f.err
+}
+
+func (f *fakeInboxStore) setUnread(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unread = n
+}
+
+var _ notification.InboxStore = (*fakeInboxStore)(nil)
+
+// fakeWakeups hands out one channel the test fires by hand, and records whose
+// inbox the stream subscribed to.
+type fakeWakeups struct {
+	ch         chan struct{}
+	subscribed chan string
+}
+
+func (f *fakeWakeups) Subscribe(recipient string) (<-chan struct{}, func()) {
+	select {
+	case f.subscribed <- recipient:
+	default:
+	}
+	return f.ch, func() {}
+}
+
+func newInboxMux(store notification.InboxStore, wake Wakeups, caller string) *http.ServeMux {
+	api := &InboxAPI{
+		Store:     store,
+		Wakeups:   wake,
+		UserEmail: func(*http.Request) string { return caller },
+		Retention: 30 * 24 * time.Hour,
+	}
+	mux := http.NewServeMux()
+	api.Register(mux)
+	return mux
+}
+
+func serveInbox(store notification.InboxStore, caller, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	newInboxMux(store, nil, caller).ServeHTTP(w,
+		httptest.NewRequestWithContext(context.Background(), method, target, strings.NewReader(body)))
+	return w
+}
+
+func TestInboxAPI_ListIsSelfScoped(t *testing.T) {
+	store := &fakeInboxStore{
+		unread: 1,
+		items: []notification.InboxItem{{
+			Notification: notification.Notification{
+				ID: 9, Category: notification.CategoryShare,
+				Payload: notification.Payload{Kind: notification.KindAsset, ItemTitle: "Report"},
+			},
+			State: notification.InboxUnread,
+		}},
+	}
+	w := serveInbox(store, "Ann <A@B.io>", http.MethodGet, "/api/v1/portal/notifications/inbox?recipient=x@y.z", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	if store.lastFilter.Recipient != "a@b.io" {
+		t.Errorf("listing scoped to %q, want the caller", store.lastFilter.Recipient)
+	}
+	var resp InboxResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Unread != 1 || resp.RetentionDays != 30 || len(resp.Data) != 1 ||
+		resp.Data[0].State != notification.InboxUnread || resp.Data[0].Subject == "" {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestInboxAPI_Rejections(t *testing.T) {
+	tests := map[string]struct {
+		caller, method, target, body string
+		want                         int
+	}{
+		"unauthenticated":  {"", http.MethodGet, "/api/v1/portal/notifications/inbox", "", http.StatusUnauthorized},
+		"unknown state":    {"a@b.io", http.MethodGet, "/api/v1/portal/notifications/inbox?state=pinned", "", http.StatusBadRequest},
+		"bad body":         {"a@b.io", http.MethodPost, "/api/v1/portal/notifications/inbox/state", "{", http.StatusBadRequest},
+		"bad target state": {"a@b.io", http.MethodPost, "/api/v1/portal/notifications/inbox/state", `{"ids":[1],"state":"x"}`, http.StatusBadRequest},
+		"no ids":           {"a@b.io", http.MethodPost, "/api/v1/portal/notifications/inbox/state", `{"state":"read"}`, http.StatusBadRequest},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if w := serveInbox(&fakeInboxStore{}, tc.caller, tc.method, tc.target, tc.body); w.Code != tc.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tc.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestInboxAPI_SetStateAnswersWithTheUnreadCount(t *testing.T) {
+	store := &fakeInboxStore{unread: 4}
+	w := serveInbox(store, "a@b.io", http.MethodPost, "/api/v1/portal/notifications/inbox/state",
+		`{"ids":[3,5],"state":"archived"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	if store.lastOwner != "a@b.io" || store.lastState != notification.InboxArchived || len(store.lastIDs) != 2 {
+		t.Errorf("store asked for %q %v %q", store.lastOwner, store.lastIDs, store.lastState)
+	}
+	var resp InboxCountResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Updated != 2 || resp.Unread != 4 {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestInboxAPI_StoreErrors(t *testing.T) {
+	store := &fakeInboxStore{err: errors.New("down")}
+	for _, target := range []string{"/api/v1/portal/notifications/inbox", "/api/v1/portal/notifications/inbox/unread", "/api/v1/portal/notifications/inbox/stream"} {
+		if w := serveInbox(store, "a@b.io", http.MethodGet, target, ""); w.Code != http.StatusInternalServerError {
+			t.Errorf("%s status = %d, want 500", target, w.Code)
+		}
+	}
+	if w := serveInbox(store, "a@b.io", http.MethodPost, "/api/v1/portal/notifications/inbox/read-all", ""); w.Code != http.StatusInternalServerError {
+		t.Errorf("read-all status = %d, want 500", w.Code)
+	}
+}
+
+// TestInboxAPI_StreamPushesOnWakeup opens a real stream and checks the badge
+// count arrives on connect and again after a wakeup that changed it.
+func TestInboxAPI_StreamPushesOnWakeup(t *testing.T) {
+	store := &fakeInboxStore{unread: 2}
+	wake := &fakeWakeups{ch: make(chan struct{}, 1), subscribed: make(chan string, 1)}
+	srv := httptest.NewServer(newInboxMux(store, wake, "a@b.io"))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/v1/portal/notifications/inbox/stream", http.NoBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type = %q", ct)
+	}
+
+	lines := bufio.NewScanner(resp.Body)
+	nextData := func() string {
+		for lines.Scan() {
+			if data, ok := strings.CutPrefix(lines.Text(), "data: "); ok {
+				return data
+			}
+		}
+		t.Fatalf("stream ended: %v", lines.Err())
+		return ""
+	}
+	if got := nextData(); got != `{"unread":2}` {
+		t.Fatalf("first event = %s", got)
+	}
+	if got := <-wake.subscribed; got != "a@b.io" {
+		t.Fatalf("stream subscribed to %q, want the caller's own inbox", got)
+	}
+	store.setUnread(5)
+	wake.ch <- struct{}{}
+	if got := nextData(); got != `{"unread":5}` {
+		t.Fatalf("second event = %s", got)
+	}
+}
@@ -1,5 +1,5 @@
-// Package notifyhttp serves the self-scoped notification-preference REST
-// endpoints the portal settings page calls.
+// Package notifyhttp serves the self-scoped notification-preference, channel,
+// and history REST endpoints the portal settings page calls.
 //
 // It registers onto the portal's authenticated mux through a registrar hook
 // (the datahubapi pattern) rather than owning a server of its own, and it is
@@ -11,6 +11,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 
@@ -30,6 +31,9 @@ type PrefsAPI struct {
 	// is unavailable. Only the derived boolean is exposed -- no SMTP host,
 	// credential, or sender value reaches this non-admin response.
 	Settings smtp.SettingsStore
+	// Channels checks that every channel a route names is one of the
+	// caller's. Optional: when unset, a route may name only email.
+	Channels notification.ChannelResolver
 	// UserEmail resolves the authenticated user's email from the request,
 	// returning "" when unauthenticated.
 	UserEmail func(*http.Request) string
@@ -41,6 +45,9 @@ type PrefsResponse struct {
 	SharesEnabled   bool   `json:"shares_enabled"`
 	CommentsEnabled bool   `json:"comments_enabled"`
 	MentionsEnabled bool   `json:"mentions_enabled"`
+	// Routes sends a category to email and/or the caller's named channels.
+	// A category it omits goes to email.
+	Routes map[string][]string `json:"routes"`
 	// DeliveryAvailable reports whether the platform currently has an SMTP
 	// path that could deliver these notifications. False means stored
 	// preferences describe an intent nothing can act on: triggers keep
@@ -55,6 +62,9 @@ type PrefsRequest struct {
 	SharesEnabled   *bool   `json:"shares_enabled,omitempty"`
 	CommentsEnabled *bool   `json:"comments_enabled,omitempty"`
 	MentionsEnabled *bool   `json:"mentions_enabled,omitempty"`
+	// Routes replaces the whole routing map when present. Each target is
+	// "email" or the name of one of the caller's channels.
+	Routes *map[string][]string `json:"routes,omitempty"`
 }
 
 // Register mounts the preference endpoints on mux.
@@ -90,7 +100,7 @@ func (a *PrefsAPI) getPrefs(w http.ResponseWriter, r *http.Request) {
 // putPrefs handles PUT /api/v1/portal/notification-prefs.
 //
 // @Summary      Update my notification preferences
-// @Description  Updates the calling user's email notification preferences. Omitted fields are left unchanged. Server-side self-scope: only the caller's own preferences are ever written.
+// @Description  Updates the calling user's notification preferences. Omitted fields are left unchanged; routes, when present, replaces the whole category routing map, and every channel it names must be one of the caller's. Server-side self-scope: only the caller's own preferences are ever written.
 // @Tags         Notifications
 // @Accept       json
 // @Produce      json
@@ -115,6 +125,12 @@ func (a *PrefsAPI) putPrefs(w http.ResponseWriter, r *http.Request) {
 		writePrefsError(w, http.StatusBadRequest, "mode must be off, immediate, or daily")
 		return
 	}
+	if req.Routes != nil {
+		if status, msg := a.checkRoutes(r.Context(), email, *req.Routes); status != 0 {
+			writePrefsError(w, status, msg)
+			return
+		}
+	}
 	prefs, err := a.Store.Set(r.Context(), email, notification.PrefsUpdate(req))
 	if err != nil {
 		writePrefsError(w, http.StatusInternalServerError, "storing notification preferences failed")
@@ -123,6 +139,34 @@ func (a *PrefsAPI) putPrefs(w http.ResponseWriter, r *http.Request) {
 	writePrefsJSON(w, prefsResponse(prefs, a.deliveryAvailable(r.Context())))
 }
 
+// checkRoutes validates a routing map against the caller's own channels,
+// returning the status and message to refuse it with, or 0 when every route
+// holds.
+func (a *PrefsAPI) checkRoutes(ctx context.Context, email string, routes map[string][]string) (int, string) {
+	if err := notification.ValidateRoutes(routes); err != nil {
+		return http.StatusBadRequest, err.Error()
+	}
+	for category, targets := range routes {
+		for _, target := range targets {
+			if target == notification.RouteEmail {
+				continue
+			}
+			if a.Channels == nil {
+				return http.StatusBadRequest, "notification channels are not available"
+			}
+			if _, err := a.Channels.Lookup(ctx, email, target); err != nil {
+				if errors.Is(err, notification.ErrChannelNotFound) {
+					return http.StatusBadRequest,
+						fmt.Sprintf("category %q routes to %q, which is not one of your channels", category, target)
+				}
+				slog.Warn("notification: resolving a route's channel failed", logKeyError, err)
+				return http.StatusInternalServerError, "checking notification routes failed"
+			}
+		}
+	}
+	return 0, ""
+}
+
 // deliveryAvailable reports whether a queued notification currently has a
 // path to a mailbox: SMTP configured, enabled, and pointed at a host. A read
 // failure reports available -- a transient store error must not tell users a
@@ -164,10 +208,19 @@ func prefsResponse(p notification.Prefs, deliveryAvailable bool) PrefsResponse {
 		SharesEnabled:     p.SharesEnabled,
 		CommentsEnabled:   p.CommentsEnabled,
 		MentionsEnabled:   p.MentionsEnabled,
+		Routes:            routesOrEmpty(p.Routes),
 		DeliveryAvailable: deliveryAvailable,
 	}
 }
 
+// routesOrEmpty renders an absent routing map as {} rather than null.
+func routesOrEmpty(routes map[string][]string) map[string][]string {
+	if routes == nil {
+		return map[string][]string{}
+	}
+	return routes
+}
+
 func writePrefsJSON(w http.ResponseWriter, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(v)
@@ -279,7 +279,7 @@ func TestPrefsAPI_ExposesNoSMTPDetail(t *testing.T) {
 	if err := json.Unmarshal(res.Body.Bytes(), &fields); err != nil {
 		t.Fatal(err)
 	}
-	allowed := []string{"mode", "shares_enabled", "comments_enabled", "mentions_enabled", "delivery_available"}
+	allowed := []string{"mode", "shares_enabled", "comments_enabled", "mentions_enabled", "routes", "delivery_available"}
 	for key := range fields {
 		if !slices.Contains(allowed, key) {
 			t.Errorf("unexpected field %q in the non-admin preferences response", key)
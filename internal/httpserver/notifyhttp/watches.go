@@ -0,0 +1,162 @@
+package notifyhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// WatchesAPI serves the caller's own watches: the portal items and datasets
+// they are told about when a new version, a thread, a knowledge change, or a
+// schema change lands.
+//
+// It is self-scoped the way ChannelsAPI is. A watch is only a subscription;
+// whether the caller may see the target is checked when there is news about
+// it, so watching something invisible reveals nothing.
+type WatchesAPI struct {
+	Store notification.WatchStore
+	// UserEmail resolves the authenticated user's email from the request,
+	// returning "" when unauthenticated.
+	UserEmail func(*http.Request) string
+}
+
+// WatchListResponse is the caller's watches, newest first.
+type WatchListResponse struct {
+	Data []notification.Watch `json:"data"`
+}
+
+// WatchRequest is the body for watching a target.
+type WatchRequest struct {
+	// TargetType is asset, collection, prompt, knowledge_page, or dataset.
+	TargetType string `json:"target_type" example:"dataset"`
+	// TargetID is the item's id, or a dataset's DataHub URN.
+	TargetID string `json:"target_id" example:"urn:li:dataset:(urn:li:dataPlatform:trino,hive.sales.orders,PROD)"`
+}
+
+// Register mounts the watch endpoints on mux.
+func (a *WatchesAPI) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/portal/notification-watches", a.list)
+	mux.HandleFunc("POST /api/v1/portal/notification-watches", a.add)
+	mux.HandleFunc("DELETE /api/v1/portal/notification-watches", a.remove)
+}
+
+// list handles GET /api/v1/portal/notification-watches.
+//
+// @Summary      List my watches
+// @Description  Returns the portal items and datasets the calling user watches, newest first.
+// @Tags         Notifications
+// @Produce      json
+// @Success      200  {object}  WatchListResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/notification-watches [get]
+func (a *WatchesAPI) list(w http.ResponseWriter, r *http.Request) {
+	email := a.callerEmail(w, r)
+	if email == "" {
+		return
+	}
+	watches, err := a.Store.List(r.Context(), email)
+	if err != nil {
+		writePrefsError(w, http.StatusInternalServerError, "reading notification watches failed")
+		return
+	}
+	writePrefsJSON(w, WatchListResponse{Data: watches})
+}
+
+// add handles POST /api/v1/portal/notification-watches.
+//
+// @Summary      Watch an item or dataset
+// @Description  Watches an asset, collection, prompt, knowledge page, or DataHub dataset. The caller is notified, under the watch category, of new versions, threads opened on it, applied knowledge changes, and (for a dataset) column changes. Watching something already watched succeeds with 200.
+// @Tags         Notifications
+// @Accept       json
+// @Produce      json
+// @Param        request  body  WatchRequest  true  "Target to watch"
+// @Success      200  {object}  notification.Watch
+// @Success      201  {object}  notification.Watch
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/notification-watches [post]
+func (a *WatchesAPI) add(w http.ResponseWriter, r *http.Request) {
+	email := a.callerEmail(w, r)
+	if email == "" {
+		return
+	}
+	var req WatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writePrefsError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := notification.ValidateWatchTarget(req.TargetType, req.TargetID); err != nil {
+		writePrefsError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	watch := notification.Watch{Email: email, TargetType: req.TargetType, TargetID: req.TargetID}
+	created, err := a.Store.Add(r.Context(), watch)
+	switch {
+	case errors.Is(err, notification.ErrWatchLimit):
+		writePrefsError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		slog.Warn("notification: storing a watch failed", logKeyError, err)
+		writePrefsError(w, http.StatusInternalServerError, "storing notification watch failed")
+		return
+	}
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(watch)
+}
+
+// remove handles DELETE /api/v1/portal/notification-watches.
+//
+// @Summary      Stop watching an item or dataset
+// @Description  Drops one of the calling user's watches. The target is named by query parameters, since a dataset URN does not fit a path segment.
+// @Tags         Notifications
+// @Param        target_type  query  string  true  "Target type"
+// @Param        target_id    query  string  true  "Target id or dataset URN"
+// @Success      204
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/notification-watches [delete]
+func (a *WatchesAPI) remove(w http.ResponseWriter, r *http.Request) {
+	email := a.callerEmail(w, r)
+	if email == "" {
+		return
+	}
+	q := r.URL.Query()
+	err := a.Store.Remove(r.Context(), email, q.Get("target_type"), q.Get("target_id"))
+	if errors.Is(err, notification.ErrWatchNotFound) {
+		writePrefsError(w, http.StatusNotFound, "you do not watch that")
+		return
+	}
+	if err != nil {
+		writePrefsError(w, http.StatusInternalServerError, "deleting notification watch failed")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// callerEmail resolves the authenticated caller, writing a 401 when absent.
+func (a *WatchesAPI) callerEmail(w http.ResponseWriter, r *http.Request) string {
+	email := ""
+	if a.UserEmail != nil {
+		email = notification.NormalizeAddress(a.UserEmail(r))
+	}
+	if email == "" {
+		writePrefsError(w, http.StatusUnauthorized, "authentication required")
+	}
+	return email
+}
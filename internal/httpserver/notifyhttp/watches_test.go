@@ -0,0 +1,130 @@
+package notifyhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// fakeWatchHTTPStore keeps watches in memory, keyed by owner then target.
+type fakeWatchHTTPStore struct {
+	byOwner map[string]map[string]notification.Watch
+	limit   int
+}
+
+func (f *fakeWatchHTTPStore) List(_ context.Context, email string) ([]notification.Watch, error) {
+	out := []notification.Watch{}
+	for _, w := range f.byOwner[email] {
+		out = append(out, w)
+	}
+	return out, nil
+}
+
+func (f *fakeWatchHTTPStore) Add(_ context.Context, w notification.Watch) (bool, error) {
+	key := w.TargetType + "/" + w.TargetID
+	if _, ok := f.byOwner[w.Email][key]; ok {
+		return false, nil
+	}
+	if len(f.byOwner[w.Email]) >= f.limit {
+		return false, notification.ErrWatchLimit
+	}
+	if f.byOwner[w.Email] == nil {
+		f.byOwner[w.Email] = map[string]notification.Watch{}
+	}
+	f.byOwner[w.Email][key] = w
+	return true, nil
+}
+
+func (f *fakeWatchHTTPStore) Remove(_ context.Context, email, targetType, targetID string) error {
+	key := targetType + "/" + targetID
+	if _, ok := f.byOwner[email][key]; !ok {
+		return notification.ErrWatchNotFound
+	}
+	delete(f.byOwner[email], key)
+	return nil
+}
+
+func (*fakeWatchHTTPStore) Watchers(context.Context, string, string) ([]string, error) {
+	return nil, nil
+}
+
+func doWatchReq(t *testing.T, mux *http.ServeMux, method, query string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	b, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequestWithContext(context.Background(), method, "/api/v1/portal/notification-watches"+query, bytes.NewReader(b))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+// TestWatchesAPI_Lifecycle watches a dataset, watches it again, lists it, and
+// unwatches it by query parameters.
+func TestWatchesAPI_Lifecycle(t *testing.T) {
+	store := &fakeWatchHTTPStore{byOwner: map[string]map[string]notification.Watch{}, limit: 2}
+	api := &WatchesAPI{Store: store, UserEmail: func(*http.Request) string { return "A@B.io" }}
+	mux := http.NewServeMux()
+	api.Register(mux)
+	urn := "urn:li:dataset:(urn:li:dataPlatform:trino,hive.sales.orders,PROD)"
+	req := WatchRequest{TargetType: notification.WatchDataset, TargetID: urn}
+
+	if res := doWatchReq(t, mux, http.MethodPost, "", req); res.Code != http.StatusCreated {
+		t.Fatalf("watch: %d %s", res.Code, res.Body)
+	}
+	if _, ok := store.byOwner["a@b.io"]["dataset/"+urn]; !ok {
+		t.Error("the watch must be keyed by the normalized caller")
+	}
+	if res := doWatchReq(t, mux, http.MethodPost, "", req); res.Code != http.StatusOK {
+		t.Errorf("watching again: status = %d; want 200", res.Code)
+	}
+
+	var list WatchListResponse
+	res := doWatchReq(t, mux, http.MethodGet, "", nil)
+	if err := json.Unmarshal(res.Body.Bytes(), &list); err != nil || len(list.Data) != 1 {
+		t.Fatalf("list: %d %s", res.Code, res.Body)
+	}
+
+	query := "?" + url.Values{"target_type": {notification.WatchDataset}, "target_id": {urn}}.Encode()
+	if res := doWatchReq(t, mux, http.MethodDelete, query, nil); res.Code != http.StatusNoContent {
+		t.Errorf("unwatch: status = %d", res.Code)
+	}
+	if res := doWatchReq(t, mux, http.MethodDelete, query, nil); res.Code != http.StatusNotFound {
+		t.Errorf("second unwatch: status = %d; want 404", res.Code)
+	}
+}
+
+func TestWatchesAPI_AddRefusals(t *testing.T) {
+	store := &fakeWatchHTTPStore{byOwner: map[string]map[string]notification.Watch{}, limit: 1}
+	api := &WatchesAPI{Store: store, UserEmail: func(*http.Request) string { return "a@b.io" }}
+	mux := http.NewServeMux()
+	api.Register(mux)
+	for name, req := range map[string]WatchRequest{
+		"bad type":    {TargetType: "folder", TargetID: "f1"},
+		"missing id":  {TargetType: notification.WatchAsset},
+		"dataset URN": {TargetType: notification.WatchDataset, TargetID: "hive.sales.orders"},
+	} {
+		if res := doWatchReq(t, mux, http.MethodPost, "", req); res.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d; want 400", name, res.Code)
+		}
+	}
+
+	doWatchReq(t, mux, http.MethodPost, "", WatchRequest{TargetType: notification.WatchAsset, TargetID: "ast_1"})
+	if res := doWatchReq(t, mux, http.MethodPost, "", WatchRequest{TargetType: notification.WatchAsset, TargetID: "ast_2"}); res.Code != http.StatusConflict {
+		t.Errorf("past the cap: status = %d; want 409", res.Code)
+	}
+
+	anonymous := &WatchesAPI{Store: store, UserEmail: func(*http.Request) string { return "" }}
+	anonMux := http.NewServeMux()
+	anonymous.Register(anonMux)
+	if res := doWatchReq(t, anonMux, http.MethodGet, "", nil); res.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous: status = %d; want 401", res.Code)
+	}
+}
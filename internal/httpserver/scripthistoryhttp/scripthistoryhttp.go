@@ -0,0 +1,340 @@
+// Package scripthistoryhttp serves a managed script's history on the portal
+// API: how its runs' outputs changed from one run to the next, and how much of
+// that history the platform keeps.
+//
+// The comparison itself lives in internal/platform/scriptdiff; this package is
+// only the REST surface over it and over the script's retention. Like
+// approvalhttp, the composition root mounts these routes wrapped in the
+// portal's authentication middleware and injects the identity accessor, so
+// this package never imports pkg/portal. The routes sit beside scripthttp's
+// under /api/v1/portal/scripts/{id} and follow its rule: a script's history is
+// its owner's and the administrators' to read, and anyone else gets 404.
+package scripthistoryhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/internal/httpjson"
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptdiff"
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+// Listing bounds for the changes view.
+const (
+	defaultChangesLimit = 25
+	maxChangesLimit     = 100
+)
+
+// maxRetentionBody bounds a retention write; it carries two numbers.
+const maxRetentionBody = 4 << 10
+
+const errScriptNot = "script not found"
+
+// Scripts reads the script a route names.
+type Scripts interface {
+	GetByID(ctx context.Context, id string) (*script.Script, error)
+}
+
+// Runs reads a script's run history.
+type Runs interface {
+	GetRun(ctx context.Context, id string) (*script.Run, error)
+	ListRuns(ctx context.Context, f script.RunFilter) ([]script.Run, error)
+}
+
+// Caller is the authenticated portal user. Owner is the identity a script's
+// owner_email is compared with: their email, or their user id when they have
+// none, as the script routes compare it.
+type Caller struct {
+	Owner   string
+	IsAdmin bool
+}
+
+// Deps carries the collaborators the handlers need.
+type Deps struct {
+	// Scripts, Runs and Retention are the script store. A nil one leaves the
+	// routes unregistered.
+	Scripts   Scripts
+	Runs      Runs
+	Retention script.RetentionStore
+	// Content reads output versions for a comparison.
+	Content scriptdiff.Content
+	// Caller resolves the authenticated portal user, returning nil when the
+	// request carries none.
+	Caller func(*http.Request) *Caller
+}
+
+// Handler serves the script history routes.
+type Handler struct {
+	deps Deps
+}
+
+// New builds the handler.
+func New(deps Deps) *Handler {
+	return &Handler{deps: deps}
+}
+
+// Register mounts the routes, wrapping each in the portal's authentication
+// middleware. A deployment without a script store registers nothing.
+//
+// The runs/compare and runs/changes paths are literal segments where
+// scripthttp's run detail has a {runID} wildcard; the mux prefers the more
+// specific pattern, so a run can never be shadowed by, or shadow, these.
+func (h *Handler) Register(mux *http.ServeMux, wrap func(http.Handler) http.Handler) {
+	if h.deps.Scripts == nil || h.deps.Runs == nil || h.deps.Retention == nil || h.deps.Caller == nil {
+		return
+	}
+	mux.Handle("GET /api/v1/portal/scripts/{id}/runs/compare", wrap(http.HandlerFunc(h.compare)))
+	mux.Handle("GET /api/v1/portal/scripts/{id}/runs/changes", wrap(http.HandlerFunc(h.changes)))
+	mux.Handle("GET /api/v1/portal/scripts/{id}/retention", wrap(http.HandlerFunc(h.getRetention)))
+	mux.Handle("PUT /api/v1/portal/scripts/{id}/retention", wrap(http.HandlerFunc(h.putRetention)))
+}
+
+// compare handles GET /api/v1/portal/scripts/{id}/runs/compare.
+//
+// @Summary      Compare two runs of a script
+// @Description  Compares what two runs of one script wrote, output by output. Outputs are paired by name and destination. A CSV or JSON export is compared row by row over the columns both versions share, with columns added or removed reported once; key names the columns that identify a row, so a row whose other values moved is reported as changed rather than as one removed and one added. A document is compared as a unified line diff. An output delivered out of the platform, a version retention has pruned, or one larger than the comparison reads is reported by the digest each run recorded. Restricted to the script's owner and administrators.
+// @Tags         Scripts
+// @Produce      json
+// @Param        id    path   string    true   "Script ID"
+// @Param        base  query  string    true   "The earlier run's ID"
+// @Param        head  query  string    true   "The later run's ID"
+// @Param        key   query  []string  false  "Columns that identify a row; repeat, or separate with commas"  collectionFormat(multi)
+// @Success      200  {object}  scriptdiff.Comparison
+// @Failure      400  {object}  httpjson.ProblemDetail
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/scripts/{id}/runs/compare [get]
+func (h *Handler) compare(w http.ResponseWriter, r *http.Request) {
+	sc, ok := h.ownedScript(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	if q.Get("base") == "" || q.Get("head") == "" {
+		httpjson.WriteError(w, http.StatusBadRequest, "base and head run ids are required")
+		return
+	}
+	base, ok := h.scriptRun(w, r, sc, q.Get("base"))
+	if !ok {
+		return
+	}
+	head, ok := h.scriptRun(w, r, sc, q.Get("head"))
+	if !ok {
+		return
+	}
+	httpjson.WriteJSON(w, http.StatusOK, scriptdiff.Compare(r.Context(), h.deps.Content, base, head,
+		scriptdiff.Options{Key: splitList(q["key"])}))
+}
+
+// changedRun is one run in the changes view: the run as a listing reports it,
+// and how its outputs differ from the run before it.
+type changedRun struct {
+	ID          string     `json:"id" example:"run_a1b2c3d4"`
+	Status      string     `json:"status" example:"succeeded"`
+	Trigger     string     `json:"trigger" example:"schedule"`
+	Version     int        `json:"version" example:"3"`
+	FireTime    time.Time  `json:"fire_time"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	OutputCount int        `json:"output_count" example:"1"`
+	// PreviousRunID is the run Changes compares against: the next older run
+	// in the listing. Both are absent on the oldest run the platform keeps.
+	PreviousRunID string              `json:"previous_run_id,omitempty" example:"run_9f8e7d6c"`
+	Changes       *scriptdiff.Summary `json:"changes,omitempty"`
+}
+
+// changesResponse is the changes view payload.
+type changesResponse struct {
+	Data  []changedRun `json:"data"`
+	Total int          `json:"total" example:"25"`
+}
+
+// changes handles GET /api/v1/portal/scripts/{id}/runs/changes.
+//
+// @Summary      List a script's runs with what each changed
+// @Description  Returns a script's runs newest first, each with a summary of how its outputs differ from the run before it: how many outputs changed, stayed the same, appeared, disappeared, or cannot be told apart, and the net change in rows. The summary is decided from the digests each run recorded, without reading any output; compare two runs for the rows and lines themselves. status narrows the listing, and defaults to succeeded, because a failed run's partial outputs are a poor baseline. Restricted to the script's owner and administrators.
+// @Tags         Scripts
+// @Produce      json
+// @Param        id        path   string  true   "Script ID"
+// @Param        status    query  string  false  "Run status; succeeded by default"
+// @Param        per_page  query  int     false  "Runs to return (default 25, max 100)"
+// @Success      200  {object}  changesResponse
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/scripts/{id}/runs/changes [get]
+func (h *Handler) changes(w http.ResponseWriter, r *http.Request) {
+	sc, ok := h.ownedScript(w, r)
+	if !ok {
+		return
+	}
+	limit := httpjson.ParseLimit(r.URL.Query())
+	if limit <= 0 {
+		limit = defaultChangesLimit
+	}
+	limit = min(limit, maxChangesLimit)
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = script.RunStatusSucceeded
+	}
+	// One run past the page, so the oldest run on it has a predecessor too.
+	runs, err := h.deps.Runs.ListRuns(r.Context(), script.RunFilter{ScriptID: sc.ID, Status: status, Limit: limit + 1})
+	if err != nil {
+		slog.Error("script history: listing runs failed", "script_id", sc.ID, "error", err)
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to list runs")
+		return
+	}
+	out := make([]changedRun, 0, min(len(runs), limit))
+	for i := 0; i < len(runs) && i < limit; i++ {
+		row := changedRun{
+			ID: runs[i].ID, Status: runs[i].Status, Trigger: runs[i].Trigger, Version: runs[i].Version,
+			FireTime: runs[i].FireTime, FinishedAt: runs[i].FinishedAt, OutputCount: len(runs[i].Outputs),
+		}
+		if i+1 < len(runs) {
+			summary := scriptdiff.Changes(&runs[i+1], &runs[i])
+			row.PreviousRunID, row.Changes = runs[i+1].ID, &summary
+		}
+		out = append(out, row)
+	}
+	httpjson.WriteJSON(w, http.StatusOK, changesResponse{Data: out, Total: len(out)})
+}
+
+// getRetention handles GET /api/v1/portal/scripts/{id}/retention.
+//
+// @Summary      Get a script's retention
+// @Description  Returns how long this script's finished runs are kept and how many versions each portal asset it writes keeps. An absent field means the deployment's setting applies. Restricted to the script's owner and administrators.
+// @Tags         Scripts
+// @Produce      json
+// @Param        id  path  string  true  "Script ID"
+// @Success      200  {object}  script.Retention
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/scripts/{id}/retention [get]
+func (h *Handler) getRetention(w http.ResponseWriter, r *http.Request) {
+	sc, ok := h.ownedScript(w, r)
+	if !ok {
+		return
+	}
+	ret, err := h.deps.Retention.GetRetention(r.Context(), sc.ID)
+	if err != nil {
+		slog.Error("script history: reading retention failed", "script_id", sc.ID, "error", err)
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to get retention")
+		return
+	}
+	httpjson.WriteJSON(w, http.StatusOK, ret)
+}
+
+// retentionRequest is the body of a retention write. An absent field returns
+// the script to the deployment's setting.
+type retentionRequest struct {
+	RunDays        *int `json:"run_days,omitempty" example:"30"`
+	OutputVersions *int `json:"output_versions,omitempty" example:"14"`
+}
+
+// putRetention handles PUT /api/v1/portal/scripts/{id}/retention.
+//
+// @Summary      Set a script's retention
+// @Description  Replaces how much of this script's history the platform keeps. run_days (1 to 3650) is how long a finished run is kept; the sweep removes older ones. output_versions is how many versions each portal asset the script writes keeps, 0 keeping every version; it is applied to an asset the next time a run writes it. An absent field returns the script to the deployment's setting. Restricted to the script's owner and administrators.
+// @Tags         Scripts
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string            true  "Script ID"
+// @Param        body  body  retentionRequest  true  "Retention"
+// @Success      200  {object}  script.Retention
+// @Failure      400  {object}  httpjson.ProblemDetail
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/scripts/{id}/retention [put]
+func (h *Handler) putRetention(w http.ResponseWriter, r *http.Request) {
+	sc, ok := h.ownedScript(w, r)
+	if !ok {
+		return
+	}
+	var body retentionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRetentionBody)).Decode(&body); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	ret := &script.Retention{
+		ScriptID: sc.ID, RunDays: body.RunDays, OutputVersions: body.OutputVersions,
+		UpdatedBy: h.deps.Caller(r).Owner,
+	}
+	if err := ret.Validate(); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.deps.Retention.SetRetention(r.Context(), ret); err != nil {
+		slog.Error("script history: writing retention failed", "script_id", sc.ID, "error", err)
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to set retention")
+		return
+	}
+	httpjson.WriteJSON(w, http.StatusOK, ret)
+}
+
+// ownedScript resolves the authenticated caller and the script the path
+// names, refusing a caller who does not own it, and writes the error response
+// either way. A script the caller may not read is as absent as one that does
+// not exist.
+func (h *Handler) ownedScript(w http.ResponseWriter, r *http.Request) (*script.Script, bool) {
+	caller := h.deps.Caller(r)
+	if caller == nil {
+		httpjson.WriteError(w, http.StatusUnauthorized, "authentication required")
+		return nil, false
+	}
+	sc, err := h.deps.Scripts.GetByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to get script")
+		return nil, false
+	}
+	// An owner is compared only when both sides are identified, so a script
+	// whose owner is unknown is not owned by every caller who is unknown too.
+	if sc == nil || (!caller.IsAdmin && (sc.OwnerEmail == "" || sc.OwnerEmail != caller.Owner)) {
+		httpjson.WriteError(w, http.StatusNotFound, errScriptNot)
+		return nil, false
+	}
+	return sc, true
+}
+
+// scriptRun reads one run of the script, writing a 404 for a run that does
+// not exist or belongs to another script.
+func (h *Handler) scriptRun(w http.ResponseWriter, r *http.Request, sc *script.Script, id string) (*script.Run, bool) {
+	run, err := h.deps.Runs.GetRun(r.Context(), id)
+	if errors.Is(err, script.ErrRunNotFound) || (err == nil && run.ScriptID != sc.ID) {
+		httpjson.WriteError(w, http.StatusNotFound, "run not found")
+		return nil, false
+	}
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to get run")
+		return nil, false
+	}
+	return run, true
+}
+
+// splitList reads a repeatable, comma-separated query parameter.
+func splitList(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
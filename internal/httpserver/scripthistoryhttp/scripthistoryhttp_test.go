@@ -0,0 +1,182 @@
+package scripthistoryhttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptdiff"
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+// fakeStore is an in-memory script, run and retention store.
+type fakeStore struct {
+	scripts   map[string]*script.Script
+	runs      []script.Run
+	filter    script.RunFilter
+	retention *script.Retention
+}
+
+func (f *fakeStore) GetByID(_ context.Context, id string) (*script.Script, error) {
+	return f.scripts[id], nil
+}
+
+func (f *fakeStore) GetRun(_ context.Context, id string) (*script.Run, error) {
+	for i := range f.runs {
+		if f.runs[i].ID == id {
+			return &f.runs[i], nil
+		}
+	}
+	return nil, script.ErrRunNotFound
+}
+
+func (f *fakeStore) ListRuns(_ context.Context, filter script.RunFilter) ([]script.Run, error) {
+	f.filter = filter
+	var out []script.Run
+	for _, r := range f.runs {
+		if r.ScriptID == filter.ScriptID && len(out) < filter.Limit {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeStore) GetRetention(_ context.Context, id string) (*script.Retention, error) {
+	if f.retention == nil {
+		return &script.Retention{ScriptID: id}, nil
+	}
+	return f.retention, nil
+}
+
+func (f *fakeStore) SetRetention(_ context.Context, r *script.Retention) error {
+	f.retention = r
+	return nil
+}
+
+// content serves every version as the same CSV, so a comparison that reads
+// finds nothing changed.
+type content struct{}
+
+func (content) Read(context.Context, string, int) ([]byte, error) { return []byte("a\n1\n"), nil }
+
+func output(digest string, version int) script.RunOutput {
+	return script.RunOutput{Name: "daily", AssetID: "asset-1", AssetVersion: version, Format: "csv", RowCount: 1, SHA256: digest}
+}
+
+func newStore() *fakeStore {
+	return &fakeStore{
+		scripts: map[string]*script.Script{
+			"s1": {ID: "s1", OwnerEmail: "owner@example.com"},
+			"s2": {ID: "s2", OwnerEmail: "other@example.com"},
+		},
+		runs: []script.Run{
+			{ID: "r3", ScriptID: "s1", Status: script.RunStatusSucceeded, Outputs: []script.RunOutput{output("b", 3)}},
+			{ID: "r2", ScriptID: "s1", Status: script.RunStatusSucceeded, Outputs: []script.RunOutput{output("a", 2)}},
+			{ID: "r1", ScriptID: "s1", Status: script.RunStatusSucceeded, Outputs: []script.RunOutput{output("a", 1)}},
+			{ID: "x1", ScriptID: "s2", Status: script.RunStatusSucceeded},
+		},
+	}
+}
+
+// serve runs one request as caller, or unauthenticated when caller is nil.
+func serve(store *fakeStore, caller *Caller, method, target, body string) *httptest.ResponseRecorder {
+	h := New(Deps{
+		Scripts: store, Runs: store, Retention: store, Content: content{},
+		Caller: func(*http.Request) *Caller { return caller },
+	})
+	mux := http.NewServeMux()
+	h.Register(mux, func(next http.Handler) http.Handler { return next })
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequestWithContext(context.Background(), method, target, strings.NewReader(body)))
+	return w
+}
+
+var owner = &Caller{Owner: "owner@example.com"}
+
+func TestCompare(t *testing.T) {
+	w := serve(newStore(), owner, http.MethodGet, "/api/v1/portal/scripts/s1/runs/compare?base=r2&head=r3&key=a", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var got scriptdiff.Comparison
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, "r2", got.Base.ID)
+	require.Len(t, got.Outputs, 1)
+	assert.Equal(t, scriptdiff.StatusUnchanged, got.Outputs[0].Status, "the content decides once it is read")
+	assert.Equal(t, []string{"a"}, got.Outputs[0].Rows.Key)
+}
+
+func TestCompare_Refusals(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller *Caller
+		target string
+		want   int
+	}{
+		{"unauthenticated", nil, "/api/v1/portal/scripts/s1/runs/compare?base=r1&head=r2", http.StatusUnauthorized},
+		{"not the owner", &Caller{Owner: "someone@example.com"}, "/api/v1/portal/scripts/s1/runs/compare?base=r1&head=r2", http.StatusNotFound},
+		{"missing head", owner, "/api/v1/portal/scripts/s1/runs/compare?base=r1", http.StatusBadRequest},
+		{"unknown run", owner, "/api/v1/portal/scripts/s1/runs/compare?base=r1&head=nope", http.StatusNotFound},
+		{"another script's run", owner, "/api/v1/portal/scripts/s1/runs/compare?base=r1&head=x1", http.StatusNotFound},
+		{"an admin reads any script", &Caller{Owner: "admin@example.com", IsAdmin: true}, "/api/v1/portal/scripts/s1/runs/compare?base=r1&head=r2", http.StatusOK},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := serve(newStore(), tc.caller, http.MethodGet, tc.target, "")
+			assert.Equal(t, tc.want, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestChanges_SummarizesEachRunAgainstItsPredecessor(t *testing.T) {
+	store := newStore()
+	w := serve(store, owner, http.MethodGet, "/api/v1/portal/scripts/s1/runs/changes?per_page=2", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, script.RunFilter{ScriptID: "s1", Status: script.RunStatusSucceeded, Limit: 3}, store.filter,
+		"one run past the page gives the oldest row a predecessor")
+
+	var got changesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	require.Len(t, got.Data, 2)
+	assert.Equal(t, "r2", got.Data[0].PreviousRunID)
+	assert.Equal(t, 1, got.Data[0].Changes.Changed)
+	assert.Equal(t, "r1", got.Data[1].PreviousRunID)
+	assert.Equal(t, 1, got.Data[1].Changes.Unchanged)
+}
+
+func TestRetention_RoundTrips(t *testing.T) {
+	store := newStore()
+	w := serve(store, owner, http.MethodPut, "/api/v1/portal/scripts/s1/retention", `{"run_days":30,"output_versions":5}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NotNil(t, store.retention)
+	assert.Equal(t, 30, *store.retention.RunDays)
+	assert.Equal(t, "owner@example.com", store.retention.UpdatedBy)
+
+	w = serve(store, owner, http.MethodGet, "/api/v1/portal/scripts/s1/retention", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"script_id":"s1","run_days":30,"output_versions":5,"updated_by":"owner@example.com"}`, w.Body.String())
+}
+
+func TestRetention_RejectsAnOutOfRangeValue(t *testing.T) {
+	store := newStore()
+	w := serve(store, owner, http.MethodPut, "/api/v1/portal/scripts/s1/retention", `{"run_days":0}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "run_days")
+	assert.Nil(t, store.retention)
+
+	w = serve(store, &Caller{Owner: "someone@example.com"}, http.MethodPut, "/api/v1/portal/scripts/s1/retention", `{"run_days":7}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Nil(t, store.retention)
+}
+
+func TestRegister_NeedsAStore(t *testing.T) {
+	mux := http.NewServeMux()
+	New(Deps{}).Register(mux, func(next http.Handler) http.Handler { return next })
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/api/v1/portal/scripts/s1/retention", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
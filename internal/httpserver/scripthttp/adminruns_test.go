@@ -40,6 +40,7 @@ func (*adminRunStore) Claim(context.Context, string, time.Duration) (*script.Run
 func (*adminRunStore) RecordOutput(context.Context, script.RunLease, script.RunOutput) error {
 	return nil
 }
+func (*adminRunStore) RecordLog(context.Context, script.RunLease, string, bool) error  { return nil }
 func (*adminRunStore) Finish(context.Context, script.RunLease, script.RunResult) error { return nil }
 func (*adminRunStore) Retry(context.Context, script.RunLease, string, time.Duration) error {
 	return nil
@@ -109,6 +109,9 @@ func (h *Handler) RegisterPortal(mux *http.ServeMux, wrap func(http.Handler) htt
 	if h.deps.Drafts != nil {
 		mux.Handle("POST /api/v1/portal/scripts/{id}/dry-run", wrap(h.portalHandler(h.portalDryRunSource)))
 	}
+	// The run form is the contract, readable whether or not anything can run
+	// it.
+	mux.Handle("GET /api/v1/portal/scripts/{id}/run-form", wrap(h.portalHandler(h.portalRunForm)))
 	if h.deps.Connections != nil {
 		mux.Handle("GET /api/v1/portal/scripts/{id}/connections", wrap(h.portalHandler(h.portalScriptConnections)))
 	}
@@ -126,6 +129,7 @@ func (h *Handler) RegisterPortal(mux *http.ServeMux, wrap func(http.Handler) htt
 	// Running one now (#1363). It is mounted with the history because it is the
 	// same store: what a run IS and what a run DID are one record.
 	mux.Handle("POST /api/v1/portal/scripts/{id}/runs", wrap(h.portalHandler(h.portalRunScript)))
+	mux.Handle("POST /api/v1/portal/scripts/{id}/runs/stream", wrap(h.portalHandler(h.portalStreamRun)))
 }
 
 // portalHandler adapts a portal handler by resolving the caller first,
@@ -66,6 +66,7 @@ func (*stubRuns) Claim(context.Context, string, time.Duration) (*script.Run, err
 	return nil, script.ErrNoWork
 }
 func (*stubRuns) RecordOutput(context.Context, script.RunLease, script.RunOutput) error { return nil }
+func (*stubRuns) RecordLog(context.Context, script.RunLease, string, bool) error        { return nil }
 func (*stubRuns) Finish(context.Context, script.RunLease, script.RunResult) error       { return nil }
 func (*stubRuns) Retry(context.Context, script.RunLease, string, time.Duration) error   { return nil }
 func (*stubRuns) PurgeRuns(context.Context, time.Duration) (int64, error)               { return 0, nil }
@@ -36,6 +36,11 @@ type ConnectionScope struct {
 	// Unrestricted lifts the persona boundary for an administrator, whose reach
 	// over this surface is unrestricted by design.
 	Unrestricted bool
+	// Roles, when set, narrows the enumeration to a script version's captured
+	// roles instead of the caller: the persona they resolve to is the one a
+	// run of that version is authorized against. Persona and Unrestricted are
+	// then ignored.
+	Roles []string
 }
 
 // ConnectionEnumerator lists the connections one caller may reach, in the
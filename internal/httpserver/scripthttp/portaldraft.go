@@ -10,6 +10,7 @@ import (
 
 	"github.com/txn2/mcp-data-platform/internal/httpjson"
 	"github.com/txn2/mcp-data-platform/internal/platform/scriptdraft"
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptlib"
 	"github.com/txn2/mcp-data-platform/internal/platform/scriptrun"
 	"github.com/txn2/mcp-data-platform/pkg/script"
 )
@@ -186,8 +187,9 @@ func (h *Handler) portalDryRunSource(w http.ResponseWriter, r *http.Request, use
 		httpjson.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	libs, _ := h.deps.Scripts.(scriptlib.Resolver)
 	outcome, err := h.deps.Drafts.Run(r.Context(), scriptdraft.Request{
-		Source: source, Name: sc.Name, Params: params,
+		Source: source, Name: sc.Name, Params: params, Libraries: libs,
 		Identity: scriptdraft.Identity{
 			UserID: user.UserID, Email: user.Email, Roles: user.Roles,
 			AuthType: user.AuthType,
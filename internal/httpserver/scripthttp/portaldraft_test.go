@@ -512,7 +512,7 @@ func TestPortalDryRunSource_RefusesAnUndeclaredDestination(t *testing.T) {
 // script, so refusing the SAVE would take away the edit that fixes it. Only the
 // surfaces answering "would this run" check it.
 func TestPortalEditSource_AcceptsAnUndeclaredDestination(t *testing.T) {
-	assert.Empty(t, refuseSource(bucketExportSource),
+	assert.Empty(t, refuseSource(&script.Script{Source: bucketExportSource}),
 		"a save reads the source, not the deployment's destination configuration")
 	assert.NotEmpty(t, refuseDraftSource(bucketExportSource, nil))
 }
@@ -67,13 +67,13 @@ func (h *Handler) portalSetSource(w http.ResponseWriter, r *http.Request, user *
 		httpjson.WriteError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
-	if detail := refuseSource(req.Source); detail != "" {
-		httpjson.WriteError(w, http.StatusBadRequest, detail)
-		return
-	}
 	before := *sc
 	after := *sc
 	after.Source = req.Source
+	if detail := refuseSource(&after); detail != "" {
+		httpjson.WriteError(w, http.StatusBadRequest, detail)
+		return
+	}
 	if err := after.Validate(); err != nil {
 		httpjson.WriteError(w, http.StatusBadRequest, err.Error())
 		return
@@ -84,15 +84,16 @@ func (h *Handler) portalSetSource(w http.ResponseWriter, r *http.Request, user *
 // refuseSource applies the same static read the tool applies before storing an
 // edit: source that does not parse is refused here rather than at the next run,
 // where nobody is watching. The detail names the first finding, which is what a
-// person needs to fix it.
+// person needs to fix it. It records on sc the libraries the source loads,
+// which the save then checks (scriptrun.ValidateScript).
 //
 // A save is deliberately NOT checked against the deployment's declared
 // destinations: the declared set is configuration that changes under a stored
 // script, and refusing the save would take away the edit that fixes it. The
 // dry-run path checks it (refuseDraftSource), because that is the surface
 // answering "would this run".
-func refuseSource(source string) string {
-	if report := scriptrun.Validate(source); !report.OK {
+func refuseSource(sc *script.Script) string {
+	if report := scriptrun.ValidateScript(sc); !report.OK {
 		detail := "the source does not parse, so it was not saved"
 		if len(report.Findings) > 0 {
 			return detail + ": " + report.Findings[0].Message
@@ -177,14 +178,19 @@ func editAuthor(user *PortalIdentity) script.Author {
 	return script.Author{Email: user.owner(), Roles: roles}
 }
 
-// writeEditError maps an edit failure to a status. A version conflict is the
-// one a caller can act on, matched by sentinel; anything else is the
-// platform's own failure and its detail stays in the log.
+// writeEditError maps an edit failure to a status. A version conflict and a
+// load the store could not bind to a library version are the ones a caller
+// can act on, matched by sentinel; anything else is the platform's own failure
+// and its detail stays in the log.
 func writeEditError(w http.ResponseWriter, err error) {
 	if errors.Is(err, script.ErrVersionConflict) {
 		httpjson.WriteError(w, http.StatusConflict, err.Error())
 		return
 	}
+	if errors.Is(err, script.ErrDependency) {
+		httpjson.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	httpjson.WriteError(w, http.StatusInternalServerError, "failed to save the source")
 }
 
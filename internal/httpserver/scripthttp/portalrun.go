@@ -1,8 +1,13 @@
 package scripthttp
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/txn2/mcp-data-platform/internal/httpjson"
 	"github.com/txn2/mcp-data-platform/pkg/script"
@@ -167,3 +172,334 @@ func (h *Handler) enqueueRun(
 	}
 	return run, true
 }
+
+// Running a script from a generated form, and watching it run.
+//
+// A queued run answers with an id and nothing else, which is right for a run
+// that may take ten minutes and wrong for a person filling in a date and
+// wanting to see what comes back. These two routes are the other shape:
+//
+//   - run-form renders the parameter contract as a JSON Schema object the page
+//     draws a form from, so nobody has to read a contract to fill one in.
+//   - runs/stream queues the same run the route above queues — persisted,
+//     executed by a worker under the script principal, requested by the caller
+//     — and holds the request open to follow it, sending its status as it
+//     moves and its log lines as they are written, and its outputs and final
+//     account when it finishes.
+//
+// The stream is a view of a run, not a way of running one. What executes, and
+// under whose authority, is exactly what a queued run gets; the only thing
+// this route adds is that the person who asked does not have to poll for it.
+
+// streamPollEvery is how often a followed run is re-read. It is the cadence
+// run_script waits on a run at, for the same reason: the run is a row, and a
+// row is only as live as its last read.
+var streamPollEvery = 300 * time.Millisecond
+
+// runFormResponse is a script's parameter contract as a form.
+type runFormResponse struct {
+	ScriptID string `json:"script_id" example:"scr_a1b2c3d4"`
+	Name     string `json:"name" example:"daily-revenue"`
+	Version  int    `json:"version" example:"3"`
+	// Refusal is the run gate's reason the script cannot run now, and empty
+	// when it can. The form is served either way: a disabled script's
+	// parameters are still worth reading.
+	Refusal string     `json:"refusal,omitempty"`
+	Schema  formSchema `json:"schema"`
+}
+
+// formSchema is a JSON Schema object describing one run's parameters.
+type formSchema struct {
+	Type       string               `json:"type" example:"object"`
+	Properties map[string]formField `json:"properties"`
+	Required   []string             `json:"required"`
+	// Order is the contract's declared order, which a map cannot carry and a
+	// form should keep.
+	Order []string `json:"x-order"`
+}
+
+// formField is one parameter as a schema property.
+type formField struct {
+	Type        string       `json:"type" example:"string"`
+	Format      string       `json:"format,omitempty" example:"date"`
+	Description string       `json:"description,omitempty"`
+	Default     any          `json:"default,omitempty"`
+	Enum        []string     `json:"enum,omitempty"`
+	OneOf       []formChoice `json:"oneOf,omitempty"`
+	// ParamType is the contract's own type name, for a form that renders a
+	// connection or a date with a control of its own.
+	ParamType string `json:"x-param-type" example:"date"`
+}
+
+// formChoice is one allowed value of a connection parameter.
+type formChoice struct {
+	Const string `json:"const" example:"warehouse"`
+	Title string `json:"title" example:"Production Trino cluster"`
+}
+
+// portalRunForm renders a script's parameter contract as a form schema.
+//
+// @Summary      Describe a script's run form
+// @Description  Returns the parameter contract of a script the caller owns as a JSON Schema object, with a connection parameter's choices drawn from the roles a run of its current version presents. Refusal carries the run gate's reason when the script cannot run now.
+// @Tags         Scripts
+// @Produce      json
+// @Param        id  path  string  true  "Script ID"
+// @Success      200  {object}  runFormResponse
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/scripts/{id}/run-form [get]
+func (h *Handler) portalRunForm(w http.ResponseWriter, r *http.Request, user *PortalIdentity) {
+	sc, ok := h.ownedScript(w, r, user)
+	if !ok {
+		return
+	}
+	out := runFormResponse{
+		ScriptID: sc.ID, Name: sc.Name, Version: sc.Version,
+		Schema: h.formSchema(r, sc),
+	}
+	if err := script.RefuseRun(sc); err != nil {
+		out.Refusal = err.Error()
+	}
+	httpjson.WriteJSON(w, http.StatusOK, out)
+}
+
+// formSchema maps a script's parameter contract onto JSON Schema. The
+// connection choices are enumerated once, and only when a parameter needs them.
+func (h *Handler) formSchema(r *http.Request, sc *script.Script) formSchema {
+	params := sc.Params
+	schema := formSchema{
+		Type: "object", Properties: make(map[string]formField, len(params)),
+		Required: []string{}, Order: make([]string, 0, len(params)),
+	}
+	var choices []formChoice
+	for _, p := range params {
+		if p.Type == script.ParamTypeConnection && choices == nil {
+			choices = h.runChoices(r, sc)
+		}
+		schema.Properties[p.Name] = formFieldFor(p, choices)
+		schema.Order = append(schema.Order, p.Name)
+		if p.Required {
+			schema.Required = append(schema.Required, p.Name)
+		}
+	}
+	return schema
+}
+
+// runChoices is the connections a run of the script's current version may
+// name. The run presents the roles its author held at the save, not the
+// caller's, so the choices are drawn from those: a choice from the caller's
+// own persona could be one the run's query is refused. A version that cannot
+// be read offers none, and the field falls back to a plain string.
+func (h *Handler) runChoices(r *http.Request, sc *script.Script) []formChoice {
+	if h.deps.Connections == nil {
+		return nil
+	}
+	v, err := h.deps.Versions.GetVersion(r.Context(), sc.ID, sc.Version)
+	if err != nil || v == nil {
+		return nil
+	}
+	roles := v.AuthorRoles
+	if roles == nil {
+		roles = []string{} // no captured roles reach nothing, never the caller's
+	}
+	return formChoices(bindableChoices(h.deps.Connections(r.Context(), ConnectionScope{Roles: roles})))
+}
+
+// formFieldFor is one parameter's property. A connection parameter offers the
+// choices when there are any, and is a plain string when the deployment cannot
+// enumerate them: the run refuses a wrong name either way.
+func formFieldFor(p script.Param, choices []formChoice) formField {
+	f := formField{Type: "string", Description: p.Description, Default: p.Default, ParamType: p.Type}
+	switch p.Type {
+	case script.ParamTypeInt:
+		f.Type = "integer"
+	case script.ParamTypeFloat:
+		f.Type = "number"
+	case script.ParamTypeBool:
+		f.Type = "boolean"
+	case script.ParamTypeDate:
+		f.Format = "date"
+	case script.ParamTypeEnum:
+		f.Enum = p.Values
+	case script.ParamTypeConnection:
+		f.OneOf = choices
+	}
+	return f
+}
+
+// formChoices renders connection choices as schema constants.
+func formChoices(reachable []ConnectionChoice) []formChoice {
+	out := make([]formChoice, 0, len(reachable))
+	for _, c := range reachable {
+		title := c.Description
+		if title == "" {
+			title = c.Name
+		}
+		out = append(out, formChoice{Const: c.Name, Title: title})
+	}
+	return out
+}
+
+// portalStreamRun queues one run of a script's latest saved version and
+// streams it to the end.
+//
+// @Summary      Run a script and stream it
+// @Description  Queues one run of the latest saved version of a script the caller owns, exactly as the run route does — persisted, executed by a worker under the script's own identity, recorded in its run history with the caller as its requester — and follows it. The response is a text/event-stream of `status` events (the run as it moves from pending to running to finished), `log` events (one print line each, as the run writes them), then `output` events (each recorded output), and one final `result` event carrying the run in full. A refusal before the run is queued is an ordinary problem response. Closing the stream stops the following, not the run.
+// @Tags         Scripts
+// @Accept       json
+// @Produce      text/event-stream
+// @Param        id   path  string      true   "Script ID"
+// @Param        run  body  runRequest  false  "Parameter values"
+// @Success      200  {object}  portalRunDetail  "The final result event"
+// @Failure      400  {object}  httpjson.ProblemDetail
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/scripts/{id}/runs/stream [post]
+func (h *Handler) portalStreamRun(w http.ResponseWriter, r *http.Request, user *PortalIdentity) {
+	sc, ok := h.ownedScript(w, r, user)
+	if !ok {
+		return
+	}
+	req, ok := decodeRunRequest(w, r)
+	if !ok {
+		return
+	}
+	version, ok := h.admittedVersion(w, r, sc)
+	if !ok {
+		return
+	}
+	params, err := script.BindParams(version.Params, req.Params)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	run, ok := h.enqueueRun(w, r, queuedRun{
+		script: sc, version: version, params: params, user: user,
+	})
+	if !ok {
+		return
+	}
+	stream := newEventStream(w)
+	stream.send("status", summarizeRun(run))
+	h.followRun(r.Context(), stream, run)
+}
+
+// followRun re-reads a queued run until it finishes, sending each status it
+// passes through and each log line as the worker writes it, then what it
+// wrote. A reader who leaves ends the following; the run is the worker's and
+// carries on, and the run history has it.
+func (h *Handler) followRun(ctx context.Context, stream *eventStream, run *script.Run) {
+	last := run.Status
+	sent := 0
+	ticker := time.NewTicker(streamPollEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		current, err := h.deps.Runs.GetRun(ctx, run.ID)
+		if err != nil {
+			stream.fail(errors.New("failed to read the run"))
+			return
+		}
+		if current.Status != last {
+			last = current.Status
+			stream.send("status", summarizeRun(current))
+		}
+		sent = sendLogLines(stream, current.Log, sent, current.Terminal())
+		if current.Terminal() {
+			sendFinishedRun(stream, current)
+			return
+		}
+	}
+}
+
+// sendLogLines sends the lines of log past the sent bytes already streamed and
+// returns how much is now sent. A running run's log is sent a whole line at a
+// time, since the worker may be mid-line; a finished run's is sent to its end.
+// A log shorter than what was sent is a retried attempt's, and is sent afresh.
+func sendLogLines(stream *eventStream, log string, sent int, final bool) int {
+	if len(log) < sent {
+		sent = 0
+	}
+	pending := log[sent:]
+	if !final {
+		pending = pending[:strings.LastIndex(pending, "\n")+1]
+	}
+	if pending == "" {
+		return sent
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(pending, "\n"), "\n") {
+		stream.send("log", map[string]string{"line": line})
+	}
+	return sent + len(pending)
+}
+
+// sendFinishedRun sends a finished run's recorded outputs and the run in full.
+func sendFinishedRun(stream *eventStream, run *script.Run) {
+	for _, out := range run.Outputs {
+		stream.send("output", out)
+	}
+	stream.send("result", detailRun(run))
+}
+
+// eventStream writes server-sent events to one response. The headers are
+// committed by the first event, so a run that never starts — the runner busy,
+// the platform broken — is still answered with an ordinary problem response.
+type eventStream struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	started bool
+}
+
+// newEventStream prepares a stream over w without committing anything.
+func newEventStream(w http.ResponseWriter) *eventStream {
+	return &eventStream{w: w, rc: http.NewResponseController(w)}
+}
+
+// start commits the stream's headers. A writer that cannot flush still
+// receives every event — they arrive together when the run ends, which is the
+// run's answer in another shape rather than no answer.
+func (s *eventStream) start() {
+	s.started = true
+	s.w.Header().Set("Content-Type", "text/event-stream")
+	s.w.Header().Set("Cache-Control", "no-cache")
+	s.w.Header().Set("Connection", "keep-alive")
+	// Proxies that buffer responses would hold the whole run back.
+	s.w.Header().Set("X-Accel-Buffering", "no")
+	s.w.WriteHeader(http.StatusOK)
+}
+
+// send writes one event. A failed write means the reader has gone; the run is
+// left to finish and its history still records it.
+func (s *eventStream) send(event string, payload any) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	if !s.started {
+		s.start()
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, body); err != nil {
+		return
+	}
+	_ = s.rc.Flush()
+}
+
+// fail reports a run the stream lost sight of: as a problem response while
+// nothing is committed, and as an error event once the stream is open.
+func (s *eventStream) fail(err error) {
+	status := http.StatusInternalServerError
+	if !s.started {
+		httpjson.WriteError(s.w, status, err.Error())
+		return
+	}
+	s.send("error", map[string]any{"error": err.Error(), "status": status})
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -223,3 +225,220 @@ func TestPortalRunScript_IsUnmountedWithoutARunStore(t *testing.T) {
 	assert.NotContains(t, rec.Body.String(), "about:blank",
 		"the route must be absent from the mux, not answering from the handler")
 }
+
+// The run form and the streamed run. The form is the contract in JSON Schema
+// terms; the stream is an ordinary queued run followed to its end, so the
+// assertions are about what got queued and what came back on the wire, in
+// order.
+
+const (
+	formPath   = "/api/v1/portal/scripts/script_2/run-form"
+	streamPath = "/api/v1/portal/scripts/script_2/runs/stream"
+)
+
+// TestPortalRunForm_RendersTheContract maps every parameter type, keeps the
+// declared order, and offers a connection parameter the caller's own bindable
+// reach.
+func TestPortalRunForm_RendersTheContract(t *testing.T) {
+	store := runnableStore()
+	store.scripts[1].Params = []script.Param{
+		{Name: "report_date", Type: script.ParamTypeDate, Required: true, Description: "Business date"},
+		{Name: "limit", Type: script.ParamTypeInt, Default: float64(10)},
+		{Name: "ratio", Type: script.ParamTypeFloat},
+		{Name: "dry", Type: script.ParamTypeBool},
+		{Name: "cadence", Type: script.ParamTypeEnum, Values: []string{"daily", "weekly"}},
+		{Name: "source", Type: script.ParamTypeConnection, Required: true},
+	}
+	deps, asked := connectionDeps(store, carol, reachable())
+	rec := servePortalRequest(t, deps, http.MethodGet, formPath, "")
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var body runFormResponse
+	decodeInto(t, rec, &body)
+	assert.Equal(t, 3, body.Version)
+	assert.Empty(t, body.Refusal)
+	assert.Equal(t, "object", body.Schema.Type)
+	assert.Equal(t, []string{"report_date", "limit", "ratio", "dry", "cadence", "source"}, body.Schema.Order)
+	assert.Equal(t, []string{"report_date", "source"}, body.Schema.Required)
+
+	props := body.Schema.Properties
+	assert.Equal(t, formField{Type: "string", Format: "date", Description: "Business date", ParamType: "date"}, props["report_date"])
+	assert.Equal(t, "integer", props["limit"].Type)
+	assert.InDelta(t, 10, props["limit"].Default, 0)
+	assert.Equal(t, "number", props["ratio"].Type)
+	assert.Equal(t, "boolean", props["dry"].Type)
+	assert.Equal(t, []string{"daily", "weekly"}, props["cadence"].Enum)
+	assert.Equal(t, []formChoice{
+		{Const: "warehouse", Title: "Production warehouse"},
+		{Const: "reporting", Title: "Reporting cluster"},
+	}, props["source"].OneOf, "only the bindable kind is offered")
+	assert.Equal(t, []string{"dp_analyst"}, asked.Roles,
+		"the choices are the reach of the roles the run presents, not the caller's")
+}
+
+// TestPortalRunForm_OffersNoChoicesWithoutTheVersion keeps a form whose run
+// authority cannot be read from offering the caller's reach in its place.
+func TestPortalRunForm_OffersNoChoicesWithoutTheVersion(t *testing.T) {
+	store := runnableStore()
+	store.scripts[1].Params = []script.Param{{Name: "source", Type: script.ParamTypeConnection}}
+	store.version = nil
+	deps, asked := connectionDeps(store, carol, reachable())
+	rec := servePortalRequest(t, deps, http.MethodGet, formPath, "")
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var body runFormResponse
+	decodeInto(t, rec, &body)
+	assert.Empty(t, body.Schema.Properties["source"].OneOf)
+	assert.Nil(t, asked.Roles, "the enumerator is not asked")
+}
+
+// TestPortalRunForm_CarriesTheRunGatesRefusal serves a disabled script's form
+// with the gate's reason, so the page explains the missing button.
+func TestPortalRunForm_CarriesTheRunGatesRefusal(t *testing.T) {
+	store := runnableStore()
+	store.scripts[1].Enabled = false
+	deps := portalDeps(store, nil, nil, carol)
+	rec := servePortalRequest(t, deps, http.MethodGet, formPath, "")
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var body runFormResponse
+	decodeInto(t, rec, &body)
+	assert.Equal(t, script.RefuseRun(&store.scripts[1]).Error(), body.Refusal)
+	assert.Equal(t, "string", body.Schema.Properties["source"].Type,
+		"without an enumerator a connection is a plain string")
+	assert.Empty(t, body.Schema.Properties["source"].OneOf)
+}
+
+// followedRuns queues like queueingRuns and then plays the queued run through
+// the states a worker moves it through, one per read, so the stream is
+// asserted against a run that actually changes under it.
+type followedRuns struct {
+	*queueingRuns
+	states []script.Run
+	reads  int
+}
+
+func (f *followedRuns) GetRun(_ context.Context, id string) (*script.Run, error) {
+	if f.queued == nil || f.queued.ID != id {
+		return nil, script.ErrRunNotFound
+	}
+	state := f.states[min(f.reads, len(f.states)-1)]
+	f.reads++
+	run := *f.queued
+	run.Status, run.Log, run.Outputs = state.Status, state.Log, state.Outputs
+	return &run, nil
+}
+
+// streamDeps assembles the portal deps with a run store that plays a run from
+// running to succeeded, its log growing a line at a time (the first read
+// catching the worker mid-line), and writing one output.
+func streamDeps(store *stubStore, user *PortalIdentity) (Deps, *followedRuns) {
+	deps, queue := runDeps(store, user)
+	runs := &followedRuns{queueingRuns: queue, states: []script.Run{
+		{Status: script.RunStatusRunning, Log: "starting\nwork"},
+		{Status: script.RunStatusRunning, Log: "starting\nworking\n"},
+		{
+			Status: script.RunStatusSucceeded, Log: "starting\nworking\ndone\n",
+			Outputs: []script.RunOutput{{Name: "daily", Destination: "portal", Format: "csv", RowCount: 1, Bytes: 8}},
+		},
+	}}
+	deps.Runs = runs
+	return deps, runs
+}
+
+// fastPolling shortens the follow cadence for one test.
+func fastPolling(t *testing.T) {
+	t.Helper()
+	prev := streamPollEvery
+	streamPollEvery = time.Millisecond
+	t.Cleanup(func() { streamPollEvery = prev })
+}
+
+// TestPortalStreamRun_FollowsAPersistedRun is the route's whole job: the
+// latest saved version queued as an ordinary run the caller requested, then
+// followed, with its status changes, log, outputs and result arriving as
+// events in the order they happened.
+func TestPortalStreamRun_FollowsAPersistedRun(t *testing.T) {
+	fastPolling(t)
+	deps, runs := streamDeps(runnableStore(), carol)
+	rec := servePortalRequest(t, deps, http.MethodPost, streamPath,
+		`{"params":{"source":"warehouse"}}`)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+
+	require.NotNil(t, runs.queued, "the run must be queued, not executed in the request")
+	assert.Equal(t, "sver_3", runs.queued.VersionID)
+	assert.Equal(t, "warehouse", runs.queued.Params["source"])
+	assert.Equal(t, script.TriggerPortal, runs.queued.Trigger)
+	assert.Equal(t, "carol@example.com", runs.queued.RequestedBy)
+
+	events := strings.Split(strings.TrimSpace(rec.Body.String()), "\n\n")
+	require.Len(t, events, 8, rec.Body.String())
+	assert.True(t, strings.HasPrefix(events[0], "event: status\n"), events[0])
+	assert.Contains(t, events[0], `"status":"pending"`)
+	assert.Contains(t, events[1], `"status":"running"`, "a status is sent once, when it changes")
+	assert.Equal(t, "event: log\ndata: {\"line\":\"starting\"}", events[2],
+		"a line is streamed while the run is still going, and a partial line waits")
+	assert.Equal(t, "event: log\ndata: {\"line\":\"working\"}", events[3])
+	assert.Contains(t, events[4], `"status":"succeeded"`)
+	assert.Equal(t, "event: log\ndata: {\"line\":\"done\"}", events[5], "no line is sent twice")
+	assert.True(t, strings.HasPrefix(events[6], "event: output\n"), events[6])
+	assert.Contains(t, events[6], `"name":"daily"`)
+	assert.True(t, strings.HasPrefix(events[7], "event: result\n"), events[7])
+	assert.Contains(t, events[7], `"id":"`+runs.queued.ID+`"`)
+}
+
+// TestPortalStreamRun_StopsFollowingALostRun answers a run the store can no
+// longer read with an error event on the open stream.
+func TestPortalStreamRun_StopsFollowingALostRun(t *testing.T) {
+	fastPolling(t)
+	deps, runs := streamDeps(runnableStore(), carol)
+	runs.getErr = errors.New("connection reset")
+	deps.Runs = lostRuns{runs}
+	rec := servePortalRequest(t, deps, http.MethodPost, streamPath, `{"params":{"source":"warehouse"}}`)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	events := strings.Split(strings.TrimSpace(rec.Body.String()), "\n\n")
+	require.Len(t, events, 2, rec.Body.String())
+	assert.True(t, strings.HasPrefix(events[1], "event: error\n"), events[1])
+	assert.NotContains(t, events[1], "connection reset", "a store error is not the reader's to see")
+}
+
+// lostRuns fails every read of a run it queued.
+type lostRuns struct{ *followedRuns }
+
+func (l lostRuns) GetRun(context.Context, string) (*script.Run, error) { return nil, l.getErr }
+
+// TestPortalStreamRun_RefusesBeforeStreaming keeps every refusal that can be
+// made before the run an ordinary problem response, with nothing queued.
+func TestPortalStreamRun_RefusesBeforeStreaming(t *testing.T) {
+	t.Run("a value the contract rejects", func(t *testing.T) {
+		deps, runs := streamDeps(runnableStore(), carol)
+		rec := servePortalRequest(t, deps, http.MethodPost, streamPath, `{"params":{}}`)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "source")
+		assert.Nil(t, runs.queued)
+	})
+	t.Run("a script the gate refuses", func(t *testing.T) {
+		store := runnableStore()
+		store.scripts[1].Enabled = false
+		deps, runs := streamDeps(store, carol)
+		rec := servePortalRequest(t, deps, http.MethodPost, streamPath, `{"params":{"source":"warehouse"}}`)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Nil(t, runs.queued)
+	})
+	t.Run("somebody else's script", func(t *testing.T) {
+		deps, runs := streamDeps(runnableStore(), stranger)
+		rec := servePortalRequest(t, deps, http.MethodPost, streamPath, `{"params":{"source":"warehouse"}}`)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Nil(t, runs.queued)
+	})
+	t.Run("a queue that refuses the run", func(t *testing.T) {
+		deps, runs := streamDeps(runnableStore(), carol)
+		runs.enqueueErr = errors.New("database unavailable")
+		rec := servePortalRequest(t, deps, http.MethodPost, streamPath, `{"params":{"source":"warehouse"}}`)
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.NotEqual(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	})
+}
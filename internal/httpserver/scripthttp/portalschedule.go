@@ -79,7 +79,7 @@ func (h *Handler) portalGetSchedule(w http.ResponseWriter, r *http.Request, user
 // portalSetSchedule creates or replaces an owned script's cadence.
 //
 // @Summary      Set a script's schedule
-// @Description  Creates or replaces the cadence a script the caller owns runs on, with the parameters every fire binds. A schedule grants no authority: every fire executes the latest saved version, authorized against the roles captured at that save. Restricted to the script's owner and to administrators.
+// @Description  Creates or replaces the cadence a script the caller owns runs on — a cron expression or an event trigger — with the parameters every fire binds. A script_run trigger may follow only a script the caller owns. A schedule grants no authority: every fire executes the latest saved version, authorized against the roles captured at that save. Restricted to the script's owner and to administrators.
 // @Tags         Scripts
 // @Accept       json
 // @Produce      json
@@ -98,7 +98,7 @@ func (h *Handler) portalSetSchedule(w http.ResponseWriter, r *http.Request, user
 	if !ok {
 		return
 	}
-	h.writeSchedule(w, r, sc, user.owner())
+	h.writeSchedule(w, r, sc, user.owner(), func(followed *script.Script) bool { return ownsScript(followed, user) })
 }
 
 // portalEnableSchedule resumes an owned script's paused schedule.
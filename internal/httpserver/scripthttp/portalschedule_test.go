@@ -81,6 +81,19 @@ func TestPortalSetSchedule_AScriptWithNoParamsTakesABareCadence(t *testing.T) {
 	assert.Equal(t, "@daily", store.schedule.CronSpec)
 }
 
+// TestPortalSetSchedule_ATriggerFollowsOnlyWhatTheCallerOwns pins the read
+// rule on a script_run trigger: following a script reveals when it succeeds.
+func TestPortalSetSchedule_ATriggerFollowsOnlyWhatTheCallerOwns(t *testing.T) {
+	rec := servePortalRequest(t, portalDeps(portalStore(), nil, nil, carol), http.MethodPut, portalSchedulePath,
+		`{"trigger":{"kind":"script_run","script_id":"script_1"}}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), "was not found")
+
+	rec = servePortalRequest(t, portalDeps(portalStore(), nil, nil, admin), http.MethodPut, portalSchedulePath,
+		`{"trigger":{"kind":"script_run","script_id":"script_1"}}`)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
 // TestPortalSetSchedule_RefusedForANonOwner pins that the caller who may READ a
 // global script still may not re-time it, and is answered exactly as a caller
 // who named a script that does not exist.
@@ -79,6 +79,9 @@ func (h *Handler) getSchedule(w http.ResponseWriter, r *http.Request) {
 type scheduleRequest struct {
 	// Cron is a standard five-field expression or a descriptor (@daily).
 	Cron string `json:"cron" example:"0 7 * * 1-5"`
+	// Trigger, sent in place of Cron, fires the schedule when its source
+	// changes. A script_run trigger names the followed script by id.
+	Trigger *script.Trigger `json:"trigger,omitempty"`
 	// Timezone is the IANA zone the expression is read in; empty means UTC.
 	Timezone string `json:"timezone" example:"America/Los_Angeles"`
 	// Params are the values every fire binds, with ${fire_date} left as
@@ -92,7 +95,7 @@ type scheduleRequest struct {
 // setSchedule creates or replaces a script's schedule.
 //
 // @Summary      Set a script's schedule
-// @Description  Creates or replaces the cadence a script runs on. The parameters are validated against the script's contract. A schedule grants no authority: every fire executes the latest saved version, authorized against the roles captured at that save.
+// @Description  Creates or replaces the cadence a script runs on: a cron expression, or a trigger that fires on a change in an S3 prefix, a Trino table's latest partition, a DataHub entity, or another script's successful runs. The parameters are validated against the script's contract. A schedule grants no authority: every fire executes the latest saved version, authorized against the roles captured at that save.
 // @Tags         Scripts
 // @Accept       json
 // @Produce      json
@@ -110,7 +113,7 @@ func (h *Handler) setSchedule(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
-	h.writeSchedule(w, r, sc, h.deps.AdminEmail(r))
+	h.writeSchedule(w, r, sc, h.deps.AdminEmail(r), func(*script.Script) bool { return true })
 }
 
 // writeSchedule applies a set request to sc, recording actor as the change's
@@ -118,7 +121,11 @@ func (h *Handler) setSchedule(w http.ResponseWriter, r *http.Request) {
 // admin route resolves its script by id alone, the portal route resolves it
 // through ownership, and from there setting a cadence is one behavior rather
 // than two implementations to keep in step.
-func (h *Handler) writeSchedule(w http.ResponseWriter, r *http.Request, sc *script.Script, actor string) {
+//
+// mayFollow is the surface's read rule, applied to the script a script_run
+// trigger follows: following a script reveals when it succeeds, so a caller
+// may follow only a script they could read.
+func (h *Handler) writeSchedule(w http.ResponseWriter, r *http.Request, sc *script.Script, actor string, mayFollow func(*script.Script) bool) {
 	var req scheduleRequest
 	// The bindings are scalars against a declared parameter contract, so the
 	// bound is generous for anything a schedule legitimately carries. It is
@@ -129,12 +136,23 @@ func (h *Handler) writeSchedule(w http.ResponseWriter, r *http.Request, sc *scri
 		httpjson.WriteError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
+	if req.Trigger != nil && req.Trigger.ScriptID != "" && req.Trigger.ScriptID != sc.ID {
+		followed, err := h.deps.Scripts.GetByID(r.Context(), req.Trigger.ScriptID)
+		if err != nil {
+			httpjson.WriteError(w, http.StatusInternalServerError, "failed to get script")
+			return
+		}
+		if followed == nil || !mayFollow(followed) {
+			httpjson.WriteError(w, http.StatusBadRequest, "the script the trigger follows was not found")
+			return
+		}
+	}
 	prev, ok := h.currentSchedule(w, r, sc.ID)
 	if !ok {
 		return
 	}
 	sched, err := script.BuildSchedule(sc, prev, script.ScheduleRequest{
-		CronSpec: req.Cron, Timezone: req.Timezone, Params: req.Params,
+		CronSpec: req.Cron, Trigger: req.Trigger, Timezone: req.Timezone, Params: req.Params,
 		Enabled: req.Enabled, Actor: actor,
 	}, time.Now())
 	if err != nil {
@@ -143,6 +161,14 @@ func (h *Handler) writeSchedule(w http.ResponseWriter, r *http.Request, sc *scri
 		httpjson.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if err := script.RefuseTriggerCycle(r.Context(), h.deps.Schedules.GetSchedule, sched); err != nil {
+		if errors.Is(err, script.ErrTriggerCycle) {
+			httpjson.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to read the schedules the trigger follows")
+		return
+	}
 	if err := h.deps.Schedules.SetSchedule(r.Context(), sched); err != nil {
 		httpjson.WriteError(w, http.StatusInternalServerError, "failed to set the schedule")
 		return
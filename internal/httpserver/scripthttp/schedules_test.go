@@ -153,6 +153,37 @@ func TestSetSchedule_Failures(t *testing.T) {
 	})
 }
 
+// TestSetSchedule_ATrigger covers the event arm: the trigger is stored, the
+// schedule carries no cron expression, and a followed script must exist.
+func TestSetSchedule_ATrigger(t *testing.T) {
+	store := portalStore()
+	rec := serve(t, store, http.MethodPut, schedulePath,
+		`{"trigger":{"kind":"script_run","script_id":"script_2","every":"10m"}}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NotNil(t, store.schedule)
+	require.NotNil(t, store.schedule.Trigger)
+	assert.Equal(t, "script_2", store.schedule.Trigger.ScriptID)
+	assert.Empty(t, store.schedule.CronSpec)
+
+	rec = serve(t, portalStore(), http.MethodPut, schedulePath,
+		`{"trigger":{"kind":"script_run","script_id":"nope"}}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "trigger follows was not found")
+
+	rec = serve(t, newStore(), http.MethodPut, schedulePath,
+		`{"cron":"@daily","trigger":{"kind":"s3_object","bucket":"landing"}}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "not both")
+
+	looped := portalStore()
+	looped.schedule = &script.Schedule{ScriptID: "script_2",
+		Trigger: &script.Trigger{Kind: script.TriggerScriptRun, ScriptID: "script_1"}}
+	rec = serve(t, looped, http.MethodPut, schedulePath,
+		`{"trigger":{"kind":"script_run","script_id":"script_2"}}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "loop", "following a script that follows this one is refused")
+}
+
 // TestSetSchedule_AScriptWithNoParamsTakesABareCadence pins the simplest
 // request: a script declaring no parameters is scheduled with a cadence alone.
 func TestSetSchedule_AScriptWithNoParamsTakesABareCadence(t *testing.T) {
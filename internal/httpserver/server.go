@@ -159,6 +159,10 @@ func Serve(ctx context.Context, mcpServer *mcp.Server, p *platform.Platform, add
 	reviewAlert := buildReviewAlert(p, notify)
 	reviewAlert.Start(ctx)
 	defer reviewAlert.Stop()
+	// The watch sweep enqueues through the same substrate, on the same terms.
+	watchAlert := buildWatchAlert(p, notify)
+	watchAlert.Start(ctx)
+	defer watchAlert.Stop()
 
 	mux := http.NewServeMux()
 	hcfg := extractHTTPConfig(p)
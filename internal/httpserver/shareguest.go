@@ -58,6 +58,8 @@ type shareTokenReader interface {
 // one-time-link flow additionally needs the database (link store), the
 // notification substrate for the transactional email, the browser-session
 // signing key, and a public base URL, and disables itself when any is absent.
+// Public-link guards need the database and the signing key; their email
+// check additionally needs the notification substrate.
 func newShareGuestService(p *platform.Platform, notify *notifydelivery.Handle, store shareTokenReader, db *sql.DB) *shareguest.Service {
 	cfg := shareguest.Config{
 		Resolve:      shareGuestResolver(store),
@@ -75,9 +77,11 @@ func newShareGuestService(p *platform.Platform, notify *notifydelivery.Handle, s
 	}
 	if db != nil {
 		cfg.Links = shareguest.NewPostgresLinkStore(db)
+		cfg.Guards = shareguest.NewPostgresGuardStore(db)
 	}
 	if notify != nil {
 		cfg.SendLink = notify.SendGuestLink
+		cfg.SendCode = notify.SendShareCode
 	}
 	if prefs := notify.Prefs(); prefs != nil {
 		cfg.OptOutStatus = optOutStatusFn(prefs)
@@ -87,6 +91,9 @@ func newShareGuestService(p *platform.Platform, notify *notifydelivery.Handle, s
 	if svc.LinksAvailable() {
 		log.Println("Portal share guest links enabled (one-time email links)")
 	}
+	if svc.GuardsAvailable() {
+		log.Println("Portal public link guards enabled (passphrase, view limit, email check)")
+	}
 	return svc
 }
 
@@ -1,4 +1,12 @@
-package enrichment
+// Package jsonpath implements the small JSONPath subset the platform uses
+// to address values inside decoded JSON: gateway enrichment predicates and
+// bindings resolve single values with Resolve, and response projections
+// prune a document down to a set of paths with CompileProjection.
+//
+// The language is deliberately limited to field access, numeric indexes,
+// and (in projections only) the [*] array wildcard, so every expression
+// stays machine-inspectable.
+package jsonpath
 
 import (
 	"errors"
@@ -7,7 +15,7 @@ import (
 	"strings"
 )
 
-// resolveJSONPath walks a simple JSONPath expression against root and returns
+// Resolve walks a simple JSONPath expression against root and returns
 // the value at that path. Supported syntax:
 //
 //	$              the root
@@ -19,18 +27,8 @@ import (
 // Anything more elaborate (filters, recursion, wildcards) is rejected with an
 // error. We deliberately keep the language small so rules stay
 // machine-inspectable for the dry-run UI.
-func resolveJSONPath(expr string, root any) (any, error) {
-	if expr == "" {
-		return nil, errors.New("jsonpath: empty expression")
-	}
-	if expr == "$" {
-		return root, nil
-	}
-	if !strings.HasPrefix(expr, "$") {
-		return nil, fmt.Errorf("jsonpath: expression %q must start with '$'", expr)
-	}
-
-	tokens, err := tokenizeJSONPath(expr[1:])
+func Resolve(expr string, root any) (any, error) {
+	tokens, err := parse(expr)
 	if err != nil {
 		return nil, err
 	}
@@ -47,10 +45,24 @@ func resolveJSONPath(expr string, root any) (any, error) {
 
 // jsonPathToken describes a single navigation step. For map access, key is
 // non-empty. For index access, isIndex is true and idx holds the offset.
+// wildcard marks a [*] step, which only projections accept.
 type jsonPathToken struct {
-	key     string
-	idx     int
-	isIndex bool
+	key      string
+	idx      int
+	isIndex  bool
+	wildcard bool
+}
+
+// parse validates expr and splits it into navigation steps. "$" alone
+// yields no steps.
+func parse(expr string) ([]jsonPathToken, error) {
+	if expr == "" {
+		return nil, errors.New("jsonpath: empty expression")
+	}
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("jsonpath: expression %q must start with '$'", expr)
+	}
+	return tokenizeJSONPath(expr[1:])
 }
 
 func tokenizeJSONPath(rest string) ([]jsonPathToken, error) {
@@ -99,14 +111,20 @@ func consumeIndex(rest string) (jsonPathToken, string, error) {
 		return jsonPathToken{}, "", errors.New("jsonpath: missing ']'")
 	}
 	body := rest[1:closing]
+	if body == "*" {
+		return jsonPathToken{wildcard: true}, rest[closing+1:], nil
+	}
 	n, err := strconv.Atoi(body)
 	if err != nil {
-		return jsonPathToken{}, "", fmt.Errorf("jsonpath: bracketed token %q is not a numeric index", body)
+		return jsonPathToken{}, "", fmt.Errorf("jsonpath: bracketed token %q is not a numeric index or '*'", body)
 	}
 	return jsonPathToken{idx: n, isIndex: true}, rest[closing+1:], nil
 }
 
 func traverseToken(cur any, tok jsonPathToken) (any, error) {
+	if tok.wildcard {
+		return nil, errors.New("jsonpath: [*] is only supported in projections")
+	}
 	if tok.isIndex {
 		return traverseIndex(cur, tok.idx)
 	}
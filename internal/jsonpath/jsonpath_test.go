@@ -1,4 +1,4 @@
-package enrichment
+package jsonpath
 
 import (
 	"reflect"
@@ -6,7 +6,7 @@ import (
 	"testing"
 )
 
-func TestResolveJSONPath_RootAndFields(t *testing.T) {
+func TestResolve_RootAndFields(t *testing.T) {
 	root := map[string]any{
 		"foo": "hello",
 		"bar": map[string]any{"baz": 42},
@@ -27,7 +27,7 @@ func TestResolveJSONPath_RootAndFields(t *testing.T) {
 	}
 	for _, tc := range cases {
 		t.Run(tc.expr, func(t *testing.T) {
-			got, err := resolveJSONPath(tc.expr, root)
+			got, err := Resolve(tc.expr, root)
 			if err != nil {
 				t.Fatalf("err: %v", err)
 			}
@@ -38,7 +38,7 @@ func TestResolveJSONPath_RootAndFields(t *testing.T) {
 	}
 }
 
-func TestResolveJSONPath_Errors(t *testing.T) {
+func TestResolve_Errors(t *testing.T) {
 	root := map[string]any{
 		"foo": "hello",
 		"arr": []any{1, 2},
@@ -61,7 +61,7 @@ func TestResolveJSONPath_Errors(t *testing.T) {
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			_, err := resolveJSONPath(tc.expr, root)
+			_, err := Resolve(tc.expr, root)
 			if err == nil {
 				t.Fatalf("expected error containing %q", tc.want)
 			}
@@ -72,10 +72,10 @@ func TestResolveJSONPath_Errors(t *testing.T) {
 	}
 }
 
-func TestResolveJSONPath_TrailingKeyWithoutDot(t *testing.T) {
+func TestResolve_TrailingKeyWithoutDot(t *testing.T) {
 	// "$.a" tokenizes to one key "a" with no following separator.
 	root := map[string]any{"a": "ok"}
-	got, err := resolveJSONPath("$.a", root)
+	got, err := Resolve("$.a", root)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -84,9 +84,9 @@ func TestResolveJSONPath_TrailingKeyWithoutDot(t *testing.T) {
 	}
 }
 
-func TestResolveJSONPath_NegativeIndex(t *testing.T) {
+func TestResolve_NegativeIndex(t *testing.T) {
 	root := map[string]any{"arr": []any{1, 2, 3}}
-	_, err := resolveJSONPath("$.arr[-1]", root)
+	_, err := Resolve("$.arr[-1]", root)
 	if err == nil {
 		t.Fatal("negative index should error")
 	}
@@ -0,0 +1,183 @@
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// MaxProjectionPaths bounds how many expressions one projection may carry.
+const MaxProjectionPaths = 32
+
+// Projection is a compiled set of paths that prunes a decoded JSON
+// document down to the values they select, keeping the document's
+// shape: a selected field stays under its parent keys, and a selected
+// array element stays inside its array.
+type Projection struct {
+	paths [][]jsonPathToken
+}
+
+// CompileProjection validates exprs and returns the projection they
+// describe. Each expression uses the Resolve syntax plus the [*]
+// wildcard, which selects every element of an array.
+func CompileProjection(exprs []string) (Projection, error) {
+	if len(exprs) == 0 {
+		return Projection{}, errors.New("jsonpath: projection needs at least one path")
+	}
+	if len(exprs) > MaxProjectionPaths {
+		return Projection{}, fmt.Errorf("jsonpath: projection has %d paths, limit is %d", len(exprs), MaxProjectionPaths)
+	}
+	p := Projection{paths: make([][]jsonPathToken, 0, len(exprs))}
+	for _, expr := range exprs {
+		tokens, err := parse(expr)
+		if err != nil {
+			return Projection{}, fmt.Errorf("projection path %q: %w", expr, err)
+		}
+		p.paths = append(p.paths, tokens)
+	}
+	return p, nil
+}
+
+// Apply returns the pruned copy of root and whether any path matched.
+// Paths that do not resolve are skipped rather than reported, so one
+// projection works across responses whose optional fields vary. Under a
+// wildcard, an element missing the rest of the path becomes null so
+// positions line up across paths. root is never modified.
+func (p Projection) Apply(root any) (any, bool) {
+	var acc any
+	matched := false
+	for _, tokens := range p.paths {
+		if v, ok := pick(root, tokens); ok {
+			acc = merge(acc, v)
+			matched = true
+		}
+	}
+	return finalize(acc), matched
+}
+
+// prunedObject and prunedArray are the partial containers a projection
+// builds. Arrays are keyed by original index so two paths selecting
+// different elements merge without renumbering.
+type (
+	prunedObject map[string]any
+	prunedArray  map[int]any
+)
+
+// pick returns the part of v that tokens select, wrapped in pruned
+// containers for every step taken.
+func pick(v any, tokens []jsonPathToken) (any, bool) {
+	if len(tokens) == 0 {
+		return v, true
+	}
+	tok, rest := tokens[0], tokens[1:]
+	switch {
+	case tok.wildcard:
+		arr, ok := v.([]any)
+		if !ok {
+			return nil, false
+		}
+		out, found := make(prunedArray, len(arr)), false
+		for i, el := range arr {
+			sub, ok := pick(el, rest)
+			out[i] = sub
+			found = found || ok
+		}
+		return out, found
+	case tok.isIndex:
+		arr, ok := v.([]any)
+		if !ok || tok.idx < 0 || tok.idx >= len(arr) {
+			return nil, false
+		}
+		sub, ok := pick(arr[tok.idx], rest)
+		if !ok {
+			return nil, false
+		}
+		return prunedArray{tok.idx: sub}, true
+	default:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		child, ok := m[tok.key]
+		if !ok {
+			return nil, false
+		}
+		sub, ok := pick(child, rest)
+		if !ok {
+			return nil, false
+		}
+		return prunedObject{tok.key: sub}, true
+	}
+}
+
+// merge combines two selections taken at the same position. Both come
+// from the same original value, so an unpruned side already contains
+// everything the other selected and wins outright.
+func merge(a, b any) any {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	switch av := a.(type) {
+	case prunedObject:
+		bv, ok := b.(prunedObject)
+		if !ok {
+			return b
+		}
+		out := make(prunedObject, len(av)+len(bv))
+		for k, v := range av {
+			out[k] = merge(v, bv[k])
+		}
+		for k, v := range bv {
+			if _, done := out[k]; !done {
+				out[k] = v
+			}
+		}
+		return out
+	case prunedArray:
+		bv, ok := b.(prunedArray)
+		if !ok {
+			return b
+		}
+		out := make(prunedArray, len(av)+len(bv))
+		for i, v := range av {
+			out[i] = merge(v, bv[i])
+		}
+		for i, v := range bv {
+			if _, done := out[i]; !done {
+				out[i] = v
+			}
+		}
+		return out
+	default:
+		return a
+	}
+}
+
+// finalize converts pruned containers back into plain JSON values,
+// emitting array elements in their original order.
+func finalize(v any) any {
+	switch tv := v.(type) {
+	case prunedObject:
+		out := make(map[string]any, len(tv))
+		for k, sub := range tv {
+			out[k] = finalize(sub)
+		}
+		return out
+	case prunedArray:
+		idx := make([]int, 0, len(tv))
+		for i := range tv {
+			idx = append(idx, i)
+		}
+		slices.Sort(idx)
+		out := make([]any, len(idx))
+		for n, i := range idx {
+			out[n] = finalize(tv[i])
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,99 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func decode(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("decode %s: %v", s, err)
+	}
+	return v
+}
+
+func TestProjection_Apply(t *testing.T) {
+	doc := `{"total":3,"meta":{"page":1,"trace":"x"},"items":[
+		{"id":1,"name":"a","tags":["t1"]},
+		{"id":2,"name":"b"},
+		{"id":3}]}`
+	cases := []struct {
+		name  string
+		paths []string
+		want  string
+	}{
+		{"root", []string{"$"}, doc},
+		{"field", []string{"$.total"}, `{"total":3}`},
+		{"nested", []string{"$.meta.page"}, `{"meta":{"page":1}}`},
+		{"wildcard", []string{"$.items[*].id"}, `{"items":[{"id":1},{"id":2},{"id":3}]}`},
+		{"wildcard missing becomes null", []string{"$.items[*].name"}, `{"items":[{"name":"a"},{"name":"b"},null]}`},
+		{"merged wildcards", []string{"$.items[*].id", "$.items[*].name"},
+			`{"items":[{"id":1,"name":"a"},{"id":2,"name":"b"},{"id":3}]}`},
+		{"indexes keep order", []string{"$.items[2].id", "$.items[0].id"}, `{"items":[{"id":1},{"id":3}]}`},
+		{"whole subtree absorbs subset", []string{"$.meta.page", "$.meta"}, `{"meta":{"page":1,"trace":"x"}}`},
+		{"unmatched path skipped", []string{"$.total", "$.nope.deeper"}, `{"total":3}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := CompileProjection(tc.paths)
+			if err != nil {
+				t.Fatalf("compile: %v", err)
+			}
+			root := decode(t, doc)
+			got, ok := p.Apply(root)
+			if !ok {
+				t.Fatal("no path matched")
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(decode(t, tc.want))
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("got %s, want %s", gotJSON, wantJSON)
+			}
+			if again, _ := json.Marshal(root); string(again) != string(mustMarshal(t, decode(t, doc))) {
+				t.Error("Apply modified its input")
+			}
+		})
+	}
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestProjection_NoMatch(t *testing.T) {
+	p, err := CompileProjection([]string{"$.missing", "$.list[*].x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := p.Apply(decode(t, `{"list":[1,2]}`)); ok || got != nil {
+		t.Errorf("got %v, %v; want no match", got, ok)
+	}
+}
+
+func TestCompileProjection_Errors(t *testing.T) {
+	cases := map[string][]string{
+		"needs at least one": nil,
+		"must start with":    {"items"},
+		"numeric index":      {"$.a[x]"},
+		"limit is":           make([]string, MaxProjectionPaths+1),
+	}
+	for want, paths := range cases {
+		if _, err := CompileProjection(paths); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%v: err = %v, want %q", paths, err, want)
+		}
+	}
+}
+
+func TestResolve_RejectsWildcard(t *testing.T) {
+	if _, err := Resolve("$.a[*]", map[string]any{"a": []any{1}}); err == nil || !strings.Contains(err.Error(), "only supported in projections") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package notifychannel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/txn2/mcp-data-platform/internal/notification/notifyrender"
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// format builds the request body a channel kind expects.
+func format(kind string, m notifyrender.Message) ([]byte, error) {
+	var v any
+	switch kind {
+	case notification.ChannelWebhook:
+		v = m
+	case notification.ChannelSlack:
+		v = slackBody(m)
+	case notification.ChannelTeams:
+		v = teamsBody(m)
+	default:
+		return nil, fmt.Errorf("unknown channel kind %q", kind)
+	}
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s message: %w", kind, err)
+	}
+	return body, nil
+}
+
+// slackEscaper escapes the three characters Slack's mrkdwn reserves for
+// links and mentions, so text a person wrote cannot ping a channel.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackBody is an incoming-webhook message: Block Kit for Slack, with a
+// top-level text that Slack uses for the notification preview and that
+// Slack-compatible receivers without Block Kit render on its own.
+func slackBody(m notifyrender.Message) map[string]any {
+	title := "*" + slackEscaper.Replace(m.Title) + "*"
+	lines := []string{title}
+	blocks := []map[string]any{slackSection(title)}
+	if m.Body != "" {
+		lines = append(lines, slackEscaper.Replace(m.Body))
+		blocks = append(blocks, slackSection(slackEscaper.Replace(m.Body)))
+	}
+	if m.Quote != "" {
+		quoted := "> " + strings.ReplaceAll(slackEscaper.Replace(m.Quote), "\n", "\n> ")
+		lines = append(lines, quoted)
+		blocks = append(blocks, slackSection(quoted))
+	}
+	if m.Link != "" {
+		link := fmt.Sprintf("<%s|%s>", m.Link, slackEscaper.Replace(m.LinkText))
+		lines = append(lines, link)
+		blocks = append(blocks, slackSection(link))
+	}
+	footer := slackEscaper.Replace(m.Brand)
+	if m.SettingsURL != "" {
+		footer += fmt.Sprintf(" · <%s|Notification settings>", m.SettingsURL)
+	}
+	blocks = append(blocks, map[string]any{
+		"type":     "context",
+		"elements": []map[string]any{{"type": "mrkdwn", "text": footer}},
+	})
+	return map[string]any{"text": strings.Join(lines, "\n"), "blocks": blocks}
+}
+
+func slackSection(text string) map[string]any {
+	return map[string]any{"type": "section", "text": map[string]any{"type": "mrkdwn", "text": text}}
+}
+
+// teamsBody is an Adaptive Card in the message envelope Teams incoming
+// webhooks and workflows accept.
+func teamsBody(m notifyrender.Message) map[string]any {
+	body := []map[string]any{
+		{"type": "TextBlock", "text": m.Brand, "size": "Small", "isSubtle": true, "wrap": true},
+		{"type": "TextBlock", "text": m.Title, "weight": "Bolder", "size": "Medium", "wrap": true},
+	}
+	if m.Body != "" {
+		body = append(body, map[string]any{"type": "TextBlock", "text": m.Body, "wrap": true})
+	}
+	if m.Quote != "" {
+		body = append(body, map[string]any{
+			"type": "Container", "style": "emphasis",
+			"items": []map[string]any{{"type": "TextBlock", "text": m.Quote, "wrap": true}},
+		})
+	}
+	var actions []map[string]any
+	if m.Link != "" {
+		actions = append(actions, map[string]any{"type": "Action.OpenUrl", "title": m.LinkText, "url": m.Link})
+	}
+	if m.SettingsURL != "" {
+		actions = append(actions, map[string]any{"type": "Action.OpenUrl", "title": "Notification settings", "url": m.SettingsURL})
+	}
+	card := map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+	if len(actions) > 0 {
+		card["actions"] = actions
+	}
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content":     card,
+		}},
+	}
+}
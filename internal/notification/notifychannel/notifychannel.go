@@ -0,0 +1,156 @@
+// Package notifychannel is the chat and webhook transport of the notification
+// substrate: it posts an already-rendered message to one of a person's
+// channels -- a signed JSON webhook, a Slack-compatible incoming webhook, or a
+// Microsoft Teams card.
+//
+// Like notifysend, it decides nothing about content or scheduling. The worker
+// hands it an opened channel and a notifyrender.Message; it formats the body
+// the receiver expects and reports whether the receiver accepted it.
+package notifychannel
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/internal/notification/notifyrender"
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// Transport limits.
+const (
+	// postTimeout bounds one post, dial to response. A receiver slower than
+	// this is treated as down and the row is retried on the worker's backoff.
+	postTimeout = 15 * time.Second
+	// maxErrorBody caps how much of a refusal's body an error quotes.
+	maxErrorBody = 256
+)
+
+// Signature headers on a ChannelWebhook post. The signature is
+// hex(HMAC-SHA256(secret, timestamp + "." + body)); a receiver recomputes it
+// and rejects a timestamp it considers stale.
+const (
+	HeaderTimestamp = "X-Notification-Timestamp"
+	HeaderSignature = "X-Notification-Signature"
+	// HeaderID carries the queue row's ID, stable across retries, so a
+	// receiver can drop a post it has already handled.
+	HeaderID = "X-Notification-Id"
+)
+
+// Poster delivers one rendered message to one channel.
+type Poster interface {
+	Post(ctx context.Context, ch notification.Channel, m notifyrender.Message) error
+}
+
+// HTTPPoster implements Poster over HTTPS. Its dialer refuses private,
+// loopback, and link-local addresses: a channel's URL is whatever its owner
+// typed, and the worker must not become a way to reach the platform's own
+// network.
+type HTTPPoster struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewHTTPPoster creates the production poster.
+func NewHTTPPoster() *HTTPPoster {
+	dialer := &net.Dialer{Timeout: postTimeout, Control: refusePrivate}
+	return &HTTPPoster{
+		client: &http.Client{
+			Timeout:   postTimeout,
+			Transport: &http.Transport{DialContext: dialer.DialContext, ForceAttemptHTTP2: true},
+			// A webhook that redirects is misconfigured, and following it
+			// would carry the post somewhere its owner did not register.
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		now: time.Now,
+	}
+}
+
+// Post formats m for the channel's kind and posts it. Any answer outside
+// 2xx is an error carrying the status and the start of the body.
+func (p *HTTPPoster) Post(ctx context.Context, ch notification.Channel, m notifyrender.Message) error {
+	body, err := format(ch.Kind, m)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ch.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building %s post to %s: %w", ch.Kind, ch.URLHint, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if ch.Kind == notification.ChannelWebhook {
+		ts := strconv.FormatInt(p.now().Unix(), 10)
+		req.Header.Set(HeaderTimestamp, ts)
+		req.Header.Set(HeaderSignature, Sign(ch.SigningSecret, ts, body))
+		req.Header.Set(HeaderID, strconv.FormatInt(m.ID, 10))
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		// The client error repeats the URL, and the URL is a credential.
+		return fmt.Errorf("posting to %s channel at %s: %w", ch.Kind, ch.URLHint, unwrapURLError(err))
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+		return nil
+	}
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return fmt.Errorf("%s channel at %s answered %d: %s", ch.Kind, ch.URLHint, resp.StatusCode, bytes.TrimSpace(snippet))
+}
+
+// Sign returns the signature a ChannelWebhook post carries in
+// HeaderSignature.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// unwrapURLError drops the *url.Error wrapper, whose message quotes the URL.
+func unwrapURLError(err error) error {
+	if inner := errors.Unwrap(err); inner != nil {
+		return inner
+	}
+	return err
+}
+
+// refusePrivate is the dialer's Control hook. It runs on the resolved
+// address, so a public name that resolves inward is refused too.
+func refusePrivate(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("channel address %q: %w", address, err)
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("channel address %q: %w", address, err)
+	}
+	if !publicAddr(addr.Unmap()) {
+		return fmt.Errorf("channel address %s is not a public address", addr)
+	}
+	return nil
+}
+
+// cgnat is the shared address space (RFC 6598), which IsPrivate leaves out.
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// publicAddr reports whether a is somewhere a webhook may live.
+func publicAddr(a netip.Addr) bool {
+	return a.IsGlobalUnicast() && !a.IsPrivate() && !cgnat.Contains(a)
+}
+
+// Verify interface compliance.
+var _ Poster = (*HTTPPoster)(nil)
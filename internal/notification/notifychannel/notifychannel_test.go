@@ -0,0 +1,140 @@
+package notifychannel
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/internal/notification/notifyrender"
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// message is a failed-run alert as the renderer produces it.
+func message() notifyrender.Message {
+	return notifyrender.Message{
+		ID: 42, Brand: "ACME", Category: notification.CategoryScriptRun, Kind: notification.KindScriptRun,
+		Title: `The scheduled script "daily-sales" failed`, Body: "Error: <!channel> division by zero",
+		Link: "https://data.acme.io/portal/scripts/s1", LinkText: "Open in ACME",
+		SettingsURL: "https://data.acme.io/portal/settings",
+	}
+}
+
+// capture is a receiver recording the last post.
+type capture struct {
+	header http.Header
+	body   []byte
+	status int
+}
+
+func newReceiver(t *testing.T, c *capture) (*httptest.Server, *HTTPPoster) {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.header = r.Header.Clone()
+		c.body, _ = io.ReadAll(r.Body)
+		if c.status != 0 {
+			w.WriteHeader(c.status)
+			_, _ = w.Write([]byte("invalid_token"))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	// The test receiver listens on loopback, which the production dialer
+	// refuses; the test poster uses the server's own client instead.
+	return srv, &HTTPPoster{client: srv.Client(), now: func() time.Time { return time.Unix(1700000000, 0) }}
+}
+
+// TestPost_WebhookIsSigned checks a receiver can verify a webhook post from
+// its headers and its own copy of the secret.
+func TestPost_WebhookIsSigned(t *testing.T) {
+	c := &capture{}
+	srv, p := newReceiver(t, c)
+	ch := notification.Channel{Kind: notification.ChannelWebhook, URL: srv.URL, URLHint: "127.0.0.1", SigningSecret: "whsec_k"}
+
+	if err := p.Post(context.Background(), ch, message()); err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	if got, want := c.header.Get(HeaderSignature), Sign("whsec_k", "1700000000", c.body); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+	if c.header.Get(HeaderID) != "42" {
+		t.Errorf("delivery id = %q", c.header.Get(HeaderID))
+	}
+	var got notifyrender.Message
+	if err := json.Unmarshal(c.body, &got); err != nil || got.Title != message().Title {
+		t.Errorf("webhook body = %s (%v)", c.body, err)
+	}
+}
+
+// TestPost_SlackEscapesWhatAPersonWrote keeps a payload from pinging a whole
+// channel and leaves the platform's own link working.
+func TestPost_SlackEscapesWhatAPersonWrote(t *testing.T) {
+	c := &capture{}
+	srv, p := newReceiver(t, c)
+	if err := p.Post(context.Background(), notification.Channel{Kind: notification.ChannelSlack, URL: srv.URL}, message()); err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	var body struct {
+		Text   string           `json:"text"`
+		Blocks []map[string]any `json:"blocks"`
+	}
+	if err := json.Unmarshal(c.body, &body); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(body.Text, "<!channel>") || !strings.Contains(body.Text, "&lt;!channel&gt;") {
+		t.Errorf("mention not escaped: %q", body.Text)
+	}
+	if !strings.Contains(body.Text, "<https://data.acme.io/portal/scripts/s1|Open in ACME>") {
+		t.Errorf("link missing: %q", body.Text)
+	}
+	if len(body.Blocks) == 0 || c.header.Get(HeaderSignature) != "" {
+		t.Errorf("expected blocks and no signature: %s", c.body)
+	}
+}
+
+func TestPost_TeamsCard(t *testing.T) {
+	c := &capture{}
+	srv, p := newReceiver(t, c)
+	if err := p.Post(context.Background(), notification.Channel{Kind: notification.ChannelTeams, URL: srv.URL}, message()); err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	body := string(c.body)
+	for _, want := range []string{"application/vnd.microsoft.card.adaptive", "AdaptiveCard", "Action.OpenUrl", "daily-sales"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("card lacks %q: %s", want, body)
+		}
+	}
+}
+
+// TestPost_RefusalIsAnError reports the status and the receiver's word, but
+// never the URL, which is a credential.
+func TestPost_RefusalIsAnError(t *testing.T) {
+	c := &capture{status: http.StatusForbidden}
+	srv, p := newReceiver(t, c)
+	err := p.Post(context.Background(), notification.Channel{Kind: notification.ChannelSlack, URL: srv.URL + "/secret-path", URLHint: "hooks.example"}, message())
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "invalid_token") {
+		t.Fatalf("expected a 403 error, got %v", err)
+	}
+	if strings.Contains(err.Error(), "secret-path") {
+		t.Errorf("error leaks the url: %v", err)
+	}
+}
+
+func TestPublicAddr(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"8.8.8.8": true, "2606:4700::1111": true,
+		"127.0.0.1": false, "10.1.2.3": false, "169.254.169.254": false,
+		"100.64.0.1": false, "::1": false, "fd00::1": false, "0.0.0.0": false,
+	} {
+		if got := publicAddr(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("publicAddr(%s) = %v, want %v", addr, got, want)
+		}
+	}
+	if err := refusePrivate("tcp", "127.0.0.1:443", nil); err == nil {
+		t.Error("the dialer must refuse loopback")
+	}
+}
@@ -0,0 +1,185 @@
+package notifyprefs
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// maxChannelsPerPerson bounds how many channels one person registers. A
+// channel is somewhere a category is routed; a handful covers a team room,
+// a DM, and an on-call hook.
+const maxChannelsPerPerson = 20
+
+// pgUniqueViolation is the SQLSTATE a second channel of the same name for one
+// person raises.
+const pgUniqueViolation = "23505"
+
+// Encryptor seals a channel's webhook URL and signing secret at rest.
+// *fieldcrypt.RestFieldEncryptor satisfies it; a passthrough is acceptable
+// when encryption is disabled, as it is for the SMTP password.
+type Encryptor interface {
+	Encrypt(plaintext string) (string, error)
+	Decrypt(ciphertext string) (string, error)
+}
+
+// errNoEncryptor refuses a read or write of a sealed field on a store built
+// only to resolve routes.
+var errNoEncryptor = errors.New("notification channel store has no encryptor")
+
+// ChannelStore implements notification.ChannelStore backed by
+// notification_channels.
+type ChannelStore struct {
+	db  *sql.DB
+	enc Encryptor
+}
+
+// NewChannelStore creates a PostgreSQL-backed channel store. enc may be nil
+// for a store that only resolves routes (Lookup, List, Delete), which is all
+// the enqueue path asks of it; Create and Get then refuse.
+func NewChannelStore(db *sql.DB, enc Encryptor) *ChannelStore {
+	return &ChannelStore{db: db, enc: enc}
+}
+
+// List returns the person's channels by name, without URLs or secrets.
+func (s *ChannelStore) List(ctx context.Context, email string) ([]notification.Channel, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, email, name, kind, url_hint, created_at
+		 FROM notification_channels WHERE email = $1 ORDER BY name`, email)
+	if err != nil {
+		return nil, fmt.Errorf("listing notification channels: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	out := []notification.Channel{}
+	for rows.Next() {
+		var c notification.Channel
+		if err := rows.Scan(&c.ID, &c.Email, &c.Name, &c.Kind, &c.URLHint, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning notification channel: %w", err)
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating notification channels: %w", err)
+	}
+	return out, nil
+}
+
+// Create registers a channel, sealing its URL and, for a webhook, a freshly
+// generated signing secret. The returned channel carries that secret once.
+func (s *ChannelStore) Create(ctx context.Context, c notification.Channel) (*notification.Channel, error) {
+	if s.enc == nil {
+		return nil, errNoEncryptor
+	}
+	if err := notification.ValidateChannelName(c.Name); err != nil {
+		return nil, err //nolint:wrapcheck // the validation message is the answer
+	}
+	if !notification.ValidChannelKind(c.Kind) {
+		return nil, fmt.Errorf("channel kind must be %s, %s, or %s",
+			notification.ChannelWebhook, notification.ChannelSlack, notification.ChannelTeams)
+	}
+	host, err := notification.ValidateChannelURL(c.URL)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // the validation message is the answer
+	}
+	c.URLHint = host
+	c.SigningSecret = ""
+	if c.Kind == notification.ChannelWebhook {
+		c.SigningSecret = "whsec_" + rand.Text()
+	}
+	sealedURL, err := s.enc.Encrypt(c.URL)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting channel url: %w", err)
+	}
+	sealedSecret := ""
+	if c.SigningSecret != "" {
+		if sealedSecret, err = s.enc.Encrypt(c.SigningSecret); err != nil {
+			return nil, fmt.Errorf("encrypting channel signing secret: %w", err)
+		}
+	}
+	// The cap is checked in the insert itself, so two concurrent creates
+	// cannot both slip under it.
+	err = s.db.QueryRowContext(ctx,
+		`INSERT INTO notification_channels (email, name, kind, url, url_hint, signing_secret)
+		 SELECT $1, $2, $3, $4, $5, $6
+		  WHERE (SELECT COUNT(*) FROM notification_channels WHERE email = $1) < $7
+		 RETURNING id, created_at`,
+		c.Email, c.Name, c.Kind, sealedURL, c.URLHint, sealedSecret, maxChannelsPerPerson).
+		Scan(&c.ID, &c.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: at most %d per person", notification.ErrChannelLimit, maxChannelsPerPerson)
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && string(pqErr.Code) == pgUniqueViolation {
+		return nil, notification.ErrChannelNameTaken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storing notification channel: %w", err)
+	}
+	return &c, nil
+}
+
+// Delete removes one of the person's channels; the foreign key drops
+// whatever was queued for it.
+func (s *ChannelStore) Delete(ctx context.Context, email, name string) error {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM notification_channels WHERE email = $1 AND name = $2`, email, name)
+	if err != nil {
+		return fmt.Errorf("deleting notification channel: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return notification.ErrChannelNotFound
+	}
+	return nil
+}
+
+// Lookup resolves one of the person's channel names to its ID.
+func (s *ChannelStore) Lookup(ctx context.Context, email, name string) (int64, error) {
+	var id int64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT id FROM notification_channels WHERE email = $1 AND name = $2`, email, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, notification.ErrChannelNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("resolving notification channel: %w", err)
+	}
+	return id, nil
+}
+
+// Get returns a channel with its URL and signing secret opened, for the send
+// worker.
+func (s *ChannelStore) Get(ctx context.Context, id int64) (*notification.Channel, error) {
+	if s.enc == nil {
+		return nil, errNoEncryptor
+	}
+	var c notification.Channel
+	var sealedURL, sealedSecret string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT id, email, name, kind, url, url_hint, signing_secret, created_at
+		 FROM notification_channels WHERE id = $1`, id).
+		Scan(&c.ID, &c.Email, &c.Name, &c.Kind, &sealedURL, &c.URLHint, &sealedSecret, &c.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notification.ErrChannelNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying notification channel: %w", err)
+	}
+	if c.URL, err = s.enc.Decrypt(sealedURL); err != nil {
+		return nil, fmt.Errorf("decrypting channel url: %w", err)
+	}
+	if sealedSecret != "" {
+		if c.SigningSecret, err = s.enc.Decrypt(sealedSecret); err != nil {
+			return nil, fmt.Errorf("decrypting channel signing secret: %w", err)
+		}
+	}
+	return &c, nil
+}
+
+// Verify interface compliance.
+var _ notification.ChannelStore = (*ChannelStore)(nil)
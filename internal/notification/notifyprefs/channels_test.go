@@ -0,0 +1,139 @@
+package notifyprefs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// sealer is a reversible stand-in for the field encryptor.
+type sealer struct{}
+
+func (sealer) Encrypt(p string) (string, error) { return "sealed:" + p, nil }
+func (sealer) Decrypt(c string) (string, error) { return strings.TrimPrefix(c, "sealed:"), nil }
+
+func newMockChannelStore(t *testing.T, enc Encryptor) (*ChannelStore, sqlmock.Sqlmock, func()) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewChannelStore(db, enc), mock, func() { _ = db.Close() }
+}
+
+// TestChannelStore_Create_SealsAndSigns stores the URL and a generated
+// signing secret sealed, and returns the secret in the clear once.
+func TestChannelStore_Create_SealsAndSigns(t *testing.T) {
+	store, mock, done := newMockChannelStore(t, sealer{})
+	defer done()
+
+	mock.ExpectQuery("INSERT INTO notification_channels").
+		WithArgs("a@b.io", "oncall", notification.ChannelWebhook, "sealed:https://hooks.example.com/x",
+			"hooks.example.com", sqlmock.AnyArg(), maxChannelsPerPerson).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(4, time.Now()))
+
+	c, err := store.Create(context.Background(), notification.Channel{
+		Email: "a@b.io", Name: "oncall", Kind: notification.ChannelWebhook, URL: "https://hooks.example.com/x",
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if c.ID != 4 || c.URLHint != "hooks.example.com" || !strings.HasPrefix(c.SigningSecret, "whsec_") {
+		t.Errorf("unexpected channel: %+v", c)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestChannelStore_Create_Refusals(t *testing.T) {
+	valid := notification.Channel{Email: "a@b.io", Name: "team", Kind: notification.ChannelSlack, URL: "https://hooks.slack.com/services/x"}
+	for name, c := range map[string]notification.Channel{
+		"email name": {Email: "a@b.io", Name: notification.RouteEmail, Kind: valid.Kind, URL: valid.URL},
+		"bad kind":   {Email: "a@b.io", Name: "team", Kind: "discord", URL: valid.URL},
+		"http url":   {Email: "a@b.io", Name: "team", Kind: valid.Kind, URL: "http://hooks.slack.com/x"},
+	} {
+		store, _, done := newMockChannelStore(t, sealer{})
+		if _, err := store.Create(context.Background(), c); err == nil {
+			t.Errorf("%s: expected a refusal", name)
+		}
+		done()
+	}
+
+	store, _, done := newMockChannelStore(t, nil)
+	defer done()
+	if _, err := store.Create(context.Background(), valid); err == nil {
+		t.Error("a store without an encryptor must refuse to create")
+	}
+}
+
+func TestChannelStore_Create_Conflicts(t *testing.T) {
+	c := notification.Channel{Email: "a@b.io", Name: "team", Kind: notification.ChannelTeams, URL: "https://example.webhook.office.com/x"}
+
+	store, mock, done := newMockChannelStore(t, sealer{})
+	defer done()
+	mock.ExpectQuery("INSERT INTO notification_channels").WillReturnError(&pq.Error{Code: pgUniqueViolation})
+	if _, err := store.Create(context.Background(), c); !errors.Is(err, notification.ErrChannelNameTaken) {
+		t.Errorf("duplicate name: got %v", err)
+	}
+	mock.ExpectQuery("INSERT INTO notification_channels").WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}))
+	if _, err := store.Create(context.Background(), c); !errors.Is(err, notification.ErrChannelLimit) {
+		t.Errorf("over the cap: got %v", err)
+	}
+}
+
+func TestChannelStore_GetOpensSealedFields(t *testing.T) {
+	store, mock, done := newMockChannelStore(t, sealer{})
+	defer done()
+
+	mock.ExpectQuery("SELECT id, email, name, kind, url, url_hint, signing_secret, created_at").
+		WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name", "kind", "url", "url_hint", "signing_secret", "created_at"}).
+			AddRow(4, "a@b.io", "oncall", notification.ChannelWebhook, "sealed:https://h.example.com/x", "h.example.com", "sealed:whsec_k", time.Now()))
+	c, err := store.Get(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if c.URL != "https://h.example.com/x" || c.SigningSecret != "whsec_k" {
+		t.Errorf("sealed fields not opened: %+v", c)
+	}
+
+	mock.ExpectQuery("SELECT id, email, name").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if _, err := store.Get(context.Background(), 5); !errors.Is(err, notification.ErrChannelNotFound) {
+		t.Errorf("missing channel: got %v", err)
+	}
+}
+
+func TestChannelStore_LookupAndDelete(t *testing.T) {
+	store, mock, done := newMockChannelStore(t, nil)
+	defer done()
+
+	mock.ExpectQuery("SELECT id FROM notification_channels").
+		WithArgs("a@b.io", "dm").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+	if id, err := store.Lookup(context.Background(), "a@b.io", "dm"); err != nil || id != 9 {
+		t.Errorf("Lookup = %d, %v", id, err)
+	}
+	mock.ExpectQuery("SELECT id FROM notification_channels").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if _, err := store.Lookup(context.Background(), "a@b.io", "gone"); !errors.Is(err, notification.ErrChannelNotFound) {
+		t.Errorf("Lookup missing: got %v", err)
+	}
+
+	mock.ExpectExec("DELETE FROM notification_channels").
+		WithArgs("a@b.io", "dm").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := store.Delete(context.Background(), "a@b.io", "dm"); err != nil {
+		t.Errorf("Delete: %v", err)
+	}
+	mock.ExpectExec("DELETE FROM notification_channels").WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := store.Delete(context.Background(), "a@b.io", "dm"); !errors.Is(err, notification.ErrChannelNotFound) {
+		t.Errorf("Delete missing: got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 // Package notifyprefs is the persistence layer for per-user notification
-// preferences: the PostgreSQL implementation of notification.PrefsStore,
-// backed by the user_notification_prefs table.
+// preferences: the PostgreSQL implementations of notification.PrefsStore,
+// backed by the user_notification_prefs table, and of
+// notification.ChannelStore, backed by notification_channels.
 //
 // The preference model itself (modes, categories, defaults) lives in
 // pkg/notification, so the enqueue path and the HTTP surface share one
@@ -10,6 +11,7 @@ package notifyprefs
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -30,16 +32,21 @@ func NewPostgresStore(db *sql.DB) *PostgresStore {
 // Get returns the stored preferences or notification.DefaultPrefs when absent.
 func (s *PostgresStore) Get(ctx context.Context, email string) (notification.Prefs, error) {
 	var p notification.Prefs
+	var routes []byte
 	err := s.db.QueryRowContext(ctx,
-		`SELECT email, mode, shares_enabled, comments_enabled, mentions_enabled, updated_at
+		`SELECT email, mode, shares_enabled, comments_enabled, mentions_enabled, routes, updated_at
 		 FROM user_notification_prefs WHERE email = $1`, email).
-		Scan(&p.Email, &p.Mode, &p.SharesEnabled, &p.CommentsEnabled, &p.MentionsEnabled, &p.UpdatedAt)
+		Scan(&p.Email, &p.Mode, &p.SharesEnabled, &p.CommentsEnabled, &p.MentionsEnabled, &routes, &p.UpdatedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return notification.DefaultPrefs(email), nil
 	}
 	if err != nil {
 		return notification.Prefs{}, fmt.Errorf("querying notification prefs: %w", err)
 	}
+	p.Routes = map[string][]string{}
+	if err := json.Unmarshal(routes, &p.Routes); err != nil {
+		return notification.Prefs{}, fmt.Errorf("decoding notification routes: %w", err)
+	}
 	return p, nil
 }
 
@@ -54,18 +61,29 @@ func (s *PostgresStore) Set(ctx context.Context, email string, u notification.Pr
 	if !notification.ValidMode(current.Mode) {
 		return notification.Prefs{}, fmt.Errorf("invalid notification mode %q", current.Mode)
 	}
+	if err := notification.ValidateRoutes(current.Routes); err != nil {
+		return notification.Prefs{}, err //nolint:wrapcheck // the validation message is the answer
+	}
+	if current.Routes == nil {
+		current.Routes = map[string][]string{}
+	}
+	routes, err := json.Marshal(current.Routes)
+	if err != nil {
+		return notification.Prefs{}, fmt.Errorf("encoding notification routes: %w", err)
+	}
 
 	err = s.db.QueryRowContext(ctx,
-		`INSERT INTO user_notification_prefs (email, mode, shares_enabled, comments_enabled, mentions_enabled)
-		 VALUES ($1, $2, $3, $4, $5)
+		`INSERT INTO user_notification_prefs (email, mode, shares_enabled, comments_enabled, mentions_enabled, routes)
+		 VALUES ($1, $2, $3, $4, $5, $6)
 		 ON CONFLICT (email) DO UPDATE SET
 		   mode = EXCLUDED.mode,
 		   shares_enabled = EXCLUDED.shares_enabled,
 		   comments_enabled = EXCLUDED.comments_enabled,
 		   mentions_enabled = EXCLUDED.mentions_enabled,
+		   routes = EXCLUDED.routes,
 		   updated_at = NOW()
 		 RETURNING updated_at`,
-		email, current.Mode, current.SharesEnabled, current.CommentsEnabled, current.MentionsEnabled).
+		email, current.Mode, current.SharesEnabled, current.CommentsEnabled, current.MentionsEnabled, routes).
 		Scan(&current.UpdatedAt)
 	if err != nil {
 		return notification.Prefs{}, fmt.Errorf("storing notification prefs: %w", err)
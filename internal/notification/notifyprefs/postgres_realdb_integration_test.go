@@ -36,3 +36,44 @@ func TestPrefsStoreRealDB(t *testing.T) {
 	require.Equal(t, notification.ModeDaily, p.Mode)
 	require.False(t, p.CommentsEnabled)
 }
+
+// TestChannelStoreRealDB round-trips a channel and a route naming it, and
+// checks the unique name and the cascade a delete relies on.
+func TestChannelStoreRealDB(t *testing.T) {
+	db := testdb.New(t)
+	channels := NewChannelStore(db, sealer{})
+	prefs := NewPostgresStore(db)
+	ctx := context.Background()
+
+	c, err := channels.Create(ctx, notification.Channel{
+		Email: "a@example.com", Name: "team", Kind: notification.ChannelSlack, URL: "https://hooks.slack.com/services/T/B/x",
+	})
+	require.NoError(t, err)
+	_, err = channels.Create(ctx, notification.Channel{
+		Email: "a@example.com", Name: "team", Kind: notification.ChannelTeams, URL: "https://example.webhook.office.com/x",
+	})
+	require.ErrorIs(t, err, notification.ErrChannelNameTaken)
+
+	id, err := channels.Lookup(ctx, "a@example.com", "team")
+	require.NoError(t, err)
+	require.Equal(t, c.ID, id)
+	opened, err := channels.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, "https://hooks.slack.com/services/T/B/x", opened.URL)
+
+	routes := map[string][]string{notification.CategoryScriptRun: {"team", notification.RouteEmail}}
+	_, err = prefs.Set(ctx, "a@example.com", notification.PrefsUpdate{Routes: &routes})
+	require.NoError(t, err)
+	p, err := prefs.Get(ctx, "a@example.com")
+	require.NoError(t, err)
+	require.Equal(t, routes, p.Routes)
+
+	_, err = db.ExecContext(ctx,
+		`INSERT INTO notifications (recipient, category, payload, channel_id) VALUES ($1, $2, '{}', $3)`,
+		"a@example.com", notification.CategoryScriptRun, id)
+	require.NoError(t, err)
+	require.NoError(t, channels.Delete(ctx, "a@example.com", "team"))
+	var queued int
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notifications WHERE channel_id = $1`, id).Scan(&queued))
+	require.Zero(t, queued)
+}
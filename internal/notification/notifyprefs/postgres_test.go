@@ -26,19 +26,19 @@ func prefsRows(mode string, shares, comments bool) *sqlmock.Rows {
 
 func prefsRowsWithMentions(mode string, shares, comments, mentions bool) *sqlmock.Rows {
 	return sqlmock.NewRows(prefsColumns).
-		AddRow("a@b.io", mode, shares, comments, mentions, time.Now())
+		AddRow("a@b.io", mode, shares, comments, mentions, []byte(`{}`), time.Now())
 }
 
 // prefsColumns mirrors the stored preference columns, in select order.
 var prefsColumns = []string{
-	"email", "mode", "shares_enabled", "comments_enabled", "mentions_enabled", "updated_at",
+	"email", "mode", "shares_enabled", "comments_enabled", "mentions_enabled", "routes", "updated_at",
 }
 
 func TestPrefsStore_Get(t *testing.T) {
 	store, mock, done := newMockPrefsStore(t)
 	defer done()
 
-	mock.ExpectQuery("SELECT email, mode, shares_enabled, comments_enabled, mentions_enabled, updated_at").
+	mock.ExpectQuery("SELECT email, mode, shares_enabled, comments_enabled, mentions_enabled, routes, updated_at").
 		WithArgs("a@b.io").
 		WillReturnRows(prefsRows(notification.ModeDaily, true, false))
 
@@ -55,7 +55,7 @@ func TestPrefsStore_Get_DefaultsWhenAbsent(t *testing.T) {
 	store, mock, done := newMockPrefsStore(t)
 	defer done()
 
-	mock.ExpectQuery("SELECT email, mode, shares_enabled, comments_enabled, mentions_enabled, updated_at").
+	mock.ExpectQuery("SELECT email, mode, shares_enabled, comments_enabled, mentions_enabled, routes, updated_at").
 		WillReturnRows(sqlmock.NewRows(prefsColumns))
 
 	p, err := store.Get(context.Background(), "new@b.io")
@@ -87,7 +87,7 @@ func TestPrefsStore_Set_AppliesOverDefaults(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows(prefsColumns))
 	mode := notification.ModeDaily
 	mock.ExpectQuery("INSERT INTO user_notification_prefs").
-		WithArgs("a@b.io", notification.ModeDaily, true, true, true).
+		WithArgs("a@b.io", notification.ModeDaily, true, true, true, []byte(`{}`)).
 		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
 
 	p, err := store.Set(context.Background(), "a@b.io", notification.PrefsUpdate{Mode: &mode})
@@ -110,7 +110,7 @@ func TestPrefsStore_Set_PartialUpdatePreservesStored(t *testing.T) {
 		WillReturnRows(prefsRows(notification.ModeDaily, false, true))
 	comments := false
 	mock.ExpectQuery("INSERT INTO user_notification_prefs").
-		WithArgs("a@b.io", notification.ModeDaily, false, false, true).
+		WithArgs("a@b.io", notification.ModeDaily, false, false, true, []byte(`{}`)).
 		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
 
 	p, err := store.Set(context.Background(), "a@b.io", notification.PrefsUpdate{CommentsEnabled: &comments})
@@ -159,3 +159,39 @@ func TestPrefsStore_Set_GetError(t *testing.T) {
 		t.Fatal("expected read error")
 	}
 }
+
+// TestPrefsStore_Routes round-trips the routing map through its JSONB column
+// and refuses a map naming an unknown category before writing.
+func TestPrefsStore_Routes(t *testing.T) {
+	store, mock, done := newMockPrefsStore(t)
+	defer done()
+
+	mock.ExpectQuery("SELECT email, mode").
+		WillReturnRows(sqlmock.NewRows(prefsColumns).
+			AddRow("a@b.io", notification.ModeImmediate, true, true, true, []byte(`{"script_run":["team"]}`), time.Now()))
+	p, err := store.Get(context.Background(), "a@b.io")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got := p.RoutesFor(notification.CategoryScriptRun); len(got) != 1 || got[0] != "team" {
+		t.Errorf("script_run routes = %v", got)
+	}
+
+	mock.ExpectQuery("SELECT email, mode").WillReturnRows(prefsRows(notification.ModeImmediate, true, true))
+	routes := map[string][]string{notification.CategoryMention: {"dm"}}
+	mock.ExpectQuery("INSERT INTO user_notification_prefs").
+		WithArgs("a@b.io", notification.ModeImmediate, true, true, true, []byte(`{"mention":["dm"]}`)).
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+	if _, err := store.Set(context.Background(), "a@b.io", notification.PrefsUpdate{Routes: &routes}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT email, mode").WillReturnRows(prefsRows(notification.ModeImmediate, true, true))
+	bad := map[string][]string{"weekly": {notification.RouteEmail}}
+	if _, err := store.Set(context.Background(), "a@b.io", notification.PrefsUpdate{Routes: &bad}); err == nil {
+		t.Error("expected an unknown-category refusal")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package notifyprefs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// WatchStore implements notification.WatchStore backed by
+// notification_watches.
+type WatchStore struct {
+	db *sql.DB
+}
+
+// NewWatchStore creates a PostgreSQL-backed watch store.
+func NewWatchStore(db *sql.DB) *WatchStore {
+	return &WatchStore{db: db}
+}
+
+// List returns the person's watches, newest first.
+func (s *WatchStore) List(ctx context.Context, email string) ([]notification.Watch, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT email, target_type, target_id, created_at
+		 FROM notification_watches WHERE email = $1
+		 ORDER BY created_at DESC, target_type, target_id`, email)
+	if err != nil {
+		return nil, fmt.Errorf("listing notification watches: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	out := []notification.Watch{}
+	for rows.Next() {
+		var w notification.Watch
+		if err := rows.Scan(&w.Email, &w.TargetType, &w.TargetID, &w.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning notification watch: %w", err)
+		}
+		out = append(out, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating notification watches: %w", err)
+	}
+	return out, nil
+}
+
+// Add records a watch, reporting whether it is new.
+func (s *WatchStore) Add(ctx context.Context, w notification.Watch) (bool, error) {
+	if err := notification.ValidateWatchTarget(w.TargetType, w.TargetID); err != nil {
+		return false, err //nolint:wrapcheck // the validation message is the answer
+	}
+	// The cap is checked in the insert itself, as it is for channels, so two
+	// concurrent adds cannot both slip under it.
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO notification_watches (email, target_type, target_id)
+		 SELECT $1, $2, $3
+		  WHERE (SELECT COUNT(*) FROM notification_watches WHERE email = $1) < $4
+		 ON CONFLICT (email, target_type, target_id) DO NOTHING`,
+		w.Email, w.TargetType, w.TargetID, notification.MaxWatchesPerPerson)
+	if err != nil {
+		return false, fmt.Errorf("storing notification watch: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		return true, nil
+	}
+	// Nothing written: either it was already watched, which is success, or
+	// the person is at the cap.
+	var exists bool
+	err = s.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM notification_watches
+		 WHERE email = $1 AND target_type = $2 AND target_id = $3)`,
+		w.Email, w.TargetType, w.TargetID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("reading notification watch: %w", err)
+	}
+	if !exists {
+		return false, fmt.Errorf("%w: at most %d per person",
+			notification.ErrWatchLimit, notification.MaxWatchesPerPerson)
+	}
+	return false, nil
+}
+
+// Remove drops one of the person's watches.
+func (s *WatchStore) Remove(ctx context.Context, email, targetType, targetID string) error {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM notification_watches
+		 WHERE email = $1 AND target_type = $2 AND target_id = $3`,
+		email, targetType, targetID)
+	if err != nil {
+		return fmt.Errorf("deleting notification watch: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return notification.ErrWatchNotFound
+	}
+	return nil
+}
+
+// Watchers returns the addresses watching a target.
+func (s *WatchStore) Watchers(ctx context.Context, targetType, targetID string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT email FROM notification_watches
+		 WHERE target_type = $1 AND target_id = $2 ORDER BY email`,
+		targetType, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("listing watchers: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var out []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, fmt.Errorf("scanning watcher: %w", err)
+		}
+		out = append(out, email)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating watchers: %w", err)
+	}
+	return out, nil
+}
+
+// Verify interface compliance.
+var _ notification.WatchStore = (*WatchStore)(nil)
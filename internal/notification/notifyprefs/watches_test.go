@@ -0,0 +1,108 @@
+package notifyprefs
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+func newMockWatchStore(t *testing.T) (*WatchStore, sqlmock.Sqlmock, func()) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewWatchStore(db), mock, func() { _ = db.Close() }
+}
+
+const datasetURN = "urn:li:dataset:(urn:li:dataPlatform:trino,hive.sales.orders,PROD)"
+
+func TestWatchStore_Add(t *testing.T) {
+	w := notification.Watch{Email: "a@b.io", TargetType: notification.WatchDataset, TargetID: datasetURN}
+
+	t.Run("a new watch is created under the cap", func(t *testing.T) {
+		store, mock, done := newMockWatchStore(t)
+		defer done()
+		mock.ExpectExec("INSERT INTO notification_watches").
+			WithArgs("a@b.io", notification.WatchDataset, datasetURN, notification.MaxWatchesPerPerson).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		created, err := store.Add(context.Background(), w)
+		if err != nil || !created {
+			t.Fatalf("Add = %v, %v", created, err)
+		}
+	})
+
+	t.Run("watching again succeeds and creates nothing", func(t *testing.T) {
+		store, mock, done := newMockWatchStore(t)
+		defer done()
+		mock.ExpectExec("INSERT INTO notification_watches").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS")).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		created, err := store.Add(context.Background(), w)
+		if err != nil || created {
+			t.Fatalf("Add = %v, %v", created, err)
+		}
+	})
+
+	t.Run("a new watch past the cap is refused", func(t *testing.T) {
+		store, mock, done := newMockWatchStore(t)
+		defer done()
+		mock.ExpectExec("INSERT INTO notification_watches").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS")).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		if _, err := store.Add(context.Background(), w); !errors.Is(err, notification.ErrWatchLimit) {
+			t.Fatalf("Add err = %v, want ErrWatchLimit", err)
+		}
+	})
+
+	t.Run("a malformed target never reaches the database", func(t *testing.T) {
+		store, mock, done := newMockWatchStore(t)
+		defer done()
+		bad := w
+		bad.TargetID = "hive.sales.orders"
+		if _, err := store.Add(context.Background(), bad); err == nil {
+			t.Fatal("expected a validation error")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unexpected queries: %v", err)
+		}
+	})
+}
+
+func TestWatchStore_Remove(t *testing.T) {
+	store, mock, done := newMockWatchStore(t)
+	defer done()
+	mock.ExpectExec("DELETE FROM notification_watches").
+		WithArgs("a@b.io", notification.WatchAsset, "ast_1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := store.Remove(context.Background(), "a@b.io", notification.WatchAsset, "ast_1"); !errors.Is(err, notification.ErrWatchNotFound) {
+		t.Fatalf("Remove err = %v, want ErrWatchNotFound", err)
+	}
+}
+
+func TestWatchStore_ListAndWatchers(t *testing.T) {
+	store, mock, done := newMockWatchStore(t)
+	defer done()
+	mock.ExpectQuery("SELECT email, target_type, target_id, created_at").
+		WithArgs("a@b.io").
+		WillReturnRows(sqlmock.NewRows([]string{"email", "target_type", "target_id", "created_at"}).
+			AddRow("a@b.io", notification.WatchAsset, "ast_1", time.Now()))
+	mock.ExpectQuery("SELECT email FROM notification_watches").
+		WithArgs(notification.WatchAsset, "ast_1").
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("a@b.io").AddRow("c@d.io"))
+
+	watches, err := store.List(context.Background(), "a@b.io")
+	if err != nil || len(watches) != 1 || watches[0].TargetID != "ast_1" {
+		t.Fatalf("List = %+v, %v", watches, err)
+	}
+	watchers, err := store.Watchers(context.Background(), notification.WatchAsset, "ast_1")
+	if err != nil || len(watchers) != 2 {
+		t.Fatalf("Watchers = %v, %v", watchers, err)
+	}
+}
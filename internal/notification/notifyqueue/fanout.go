@@ -0,0 +1,76 @@
+package notifyqueue
+
+import (
+	"sync"
+
+	"github.com/txn2/mcp-data-platform/internal/pglisten"
+)
+
+// Fanout relays InboxChannel wakeups to the inbox streams each open portal tab
+// holds. A wakeup names the recipient whose inbox changed, and only that
+// recipient's streams are woken: one person marking an entry read, or being
+// sent one, costs nobody else a recount.
+//
+// Each subscription buffers one wakeup and drops the rest while it is busy; a
+// burst of changes costs a slow reader one recount, not one per row. A wakeup
+// without a recipient — the listener reconnecting after missing whatever was
+// said meanwhile — wakes every stream.
+type Fanout struct {
+	mu   sync.Mutex
+	subs map[string]map[chan struct{}]struct{}
+}
+
+var _ pglisten.PayloadNotifier = (*Fanout)(nil)
+
+// NewFanout returns a Fanout with no subscribers.
+func NewFanout() *Fanout {
+	return &Fanout{subs: make(map[string]map[chan struct{}]struct{})}
+}
+
+// Notify wakes every subscriber. It never blocks.
+func (f *Fanout) Notify() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, chans := range f.subs {
+		wake(chans)
+	}
+}
+
+// NotifyPayload wakes the subscribers of the recipient the payload names. It
+// never blocks.
+func (f *Fanout) NotifyPayload(recipient string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	wake(f.subs[recipient])
+}
+
+// wake signals each channel that has room.
+func wake(chans map[chan struct{}]struct{}) {
+	for ch := range chans {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Subscribe registers a subscriber to one recipient's inbox and returns its
+// wakeup channel and the func that unregisters it. The caller must call the
+// func when it stops reading.
+func (f *Fanout) Subscribe(recipient string) (wake <-chan struct{}, cancel func()) {
+	ch := make(chan struct{}, 1)
+	f.mu.Lock()
+	if f.subs[recipient] == nil {
+		f.subs[recipient] = make(map[chan struct{}]struct{})
+	}
+	f.subs[recipient][ch] = struct{}{}
+	f.mu.Unlock()
+	return ch, func() {
+		f.mu.Lock()
+		delete(f.subs[recipient], ch)
+		if len(f.subs[recipient]) == 0 {
+			delete(f.subs, recipient)
+		}
+		f.mu.Unlock()
+	}
+}
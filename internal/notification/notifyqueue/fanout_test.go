@@ -0,0 +1,64 @@
+package notifyqueue
+
+import "testing"
+
+// woken reports whether ch holds a wakeup, consuming it.
+func woken(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestFanout_WakesOnlyTheRecipientsSubscribers(t *testing.T) {
+	f := NewFanout()
+	a1, cancelA1 := f.Subscribe("a@b.io")
+	defer cancelA1()
+	a2, cancelA2 := f.Subscribe("a@b.io")
+	defer cancelA2()
+	c, cancelC := f.Subscribe("c@d.io")
+	defer cancelC()
+
+	// Two wakeups with nobody reading must not block, and coalesce into one.
+	f.NotifyPayload("a@b.io")
+	f.NotifyPayload("a@b.io")
+	for name, ch := range map[string]<-chan struct{}{"a1": a1, "a2": a2} {
+		if !woken(ch) {
+			t.Fatalf("subscriber %s not woken", name)
+		}
+		if woken(ch) {
+			t.Fatalf("subscriber %s woken twice", name)
+		}
+	}
+	if woken(c) {
+		t.Fatal("another recipient's stream was woken")
+	}
+
+	f.NotifyPayload("nobody@b.io") // no subscribers: a no-op, not a panic
+}
+
+func TestFanout_NotifyWakesEverySubscriber(t *testing.T) {
+	f := NewFanout()
+	a, cancelA := f.Subscribe("a@b.io")
+	c, cancelC := f.Subscribe("c@d.io")
+	defer cancelC()
+
+	f.Notify()
+	if !woken(a) || !woken(c) {
+		t.Fatal("a reconnect must wake every stream")
+	}
+
+	cancelA()
+	f.Notify()
+	if woken(a) {
+		t.Fatal("a cancelled subscriber was woken")
+	}
+	if !woken(c) {
+		t.Fatal("the remaining subscriber was not woken")
+	}
+	if _, ok := f.subs["a@b.io"]; ok {
+		t.Error("a recipient with no streams left must be forgotten")
+	}
+}
@@ -0,0 +1,149 @@
+package notifyqueue
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/lib/pq"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// inboxFrom joins each listed notification to its reader state. A row with no
+// state is unread, so the LEFT JOIN's NULL is read through inboxState.
+const inboxFrom = ` FROM notifications LEFT JOIN notification_inbox ON notification_id = id`
+
+// inboxState is the entry's state as one of the notification.Inbox* values.
+const inboxState = `COALESCE(state, 'unread')`
+
+// inboxWhere builds the WHERE clause and arguments shared by the inbox
+// queries. Like historyWhere, every caller value is a bound parameter.
+func inboxWhere(filter notification.InboxFilter) (clause string, args []any) {
+	args = []any{filter.Recipient}
+	clause = ` WHERE recipient = $1 AND NOT duplicate`
+	if filter.State == "" {
+		return clause + ` AND ` + inboxState + ` <> 'archived'`, args
+	}
+	args = append(args, filter.State)
+	return clause + ` AND ` + inboxState + ` = $2`, args
+}
+
+// Inbox returns one page of a person's inbox, newest first.
+func (s *PostgresStore) Inbox(ctx context.Context, filter notification.InboxFilter) ([]notification.InboxItem, error) {
+	where, args := inboxWhere(filter)
+	args = append(args, filter.EffectiveLimit(), max(filter.Offset, 0))
+	// #nosec G202 -- every part is fixed text: the column list and FROM are
+	// package constants, `where` is inboxWhere's constant clause text, and
+	// the placeholder numbers are derived from len(args).
+	query := `SELECT ` + notificationColumns + `, ` + inboxState + inboxFrom + where +
+		` ORDER BY created_at DESC, id DESC` +
+		` LIMIT $` + strconv.Itoa(len(args)-1) + ` OFFSET $` + strconv.Itoa(len(args))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing inbox: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []notification.InboxItem
+	for rows.Next() {
+		var state string
+		n, err := scanNotification(stateScanner{rows, &state})
+		if err != nil {
+			return nil, fmt.Errorf("scanning inbox row: %w", err)
+		}
+		out = append(out, notification.InboxItem{Notification: *n, State: state})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating inbox rows: %w", err)
+	}
+	return out, nil
+}
+
+// stateScanner appends the inbox state column to the destinations
+// scanNotification asks for, so the one row scan serves both reads.
+type stateScanner struct {
+	row   interface{ Scan(dest ...any) error }
+	state *string
+}
+
+// Scan implements the scanner scanNotification reads from.
+func (s stateScanner) Scan(dest ...any) error {
+	return s.row.Scan(append(dest, s.state)...) //nolint:wrapcheck // scanNotification's callers add context
+}
+
+// InboxCount returns how many entries match the filter, ignoring its paging
+// fields.
+func (s *PostgresStore) InboxCount(ctx context.Context, filter notification.InboxFilter) (int, error) {
+	where, args := inboxWhere(filter)
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*)`+inboxFrom+where, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("counting inbox: %w", err)
+	}
+	return total, nil
+}
+
+// Unread returns how many of the recipient's entries are unread.
+func (s *PostgresStore) Unread(ctx context.Context, recipient string) (int, error) {
+	return s.InboxCount(ctx, notification.InboxFilter{Recipient: recipient, State: notification.InboxUnread})
+}
+
+// SetInboxState moves the recipient's listed entries among ids to state. The
+// recipient predicate is on the notifications row, so an id belonging to
+// someone else matches nothing.
+func (s *PostgresStore) SetInboxState(ctx context.Context, recipient string, ids []int64, state string) (int64, error) {
+	if !notification.ValidInboxState(state) {
+		return 0, fmt.Errorf("unknown inbox state %q", state)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	if state == notification.InboxUnread {
+		return s.changeInbox(ctx, recipient,
+			`DELETE FROM notification_inbox
+			  USING notifications
+			  WHERE notification_id = id AND recipient = $1 AND id = ANY($2)`,
+			recipient, pq.Array(ids))
+	}
+	return s.changeInbox(ctx, recipient,
+		`INSERT INTO notification_inbox (notification_id, state)
+		 SELECT id, $3 FROM notifications
+		  WHERE recipient = $1 AND id = ANY($2) AND NOT duplicate
+		 ON CONFLICT (notification_id) DO UPDATE
+		    SET state = EXCLUDED.state, updated_at = NOW()
+		  WHERE notification_inbox.state <> EXCLUDED.state`,
+		recipient, pq.Array(ids), state)
+}
+
+// MarkAllRead marks every unread entry of the recipient read. Archived
+// entries are already read and keep their state.
+func (s *PostgresStore) MarkAllRead(ctx context.Context, recipient string) (int64, error) {
+	return s.changeInbox(ctx, recipient,
+		`INSERT INTO notification_inbox (notification_id, state)
+		 SELECT id, 'read' FROM notifications
+		  WHERE recipient = $1 AND NOT duplicate
+		 ON CONFLICT (notification_id) DO NOTHING`,
+		recipient)
+}
+
+// changeInbox runs one inbox state write of recipient's and, when it changed
+// anything, fires a best-effort pg_notify on InboxChannel naming them: their
+// other open tabs recount their badge off it, and nobody else's do.
+func (s *PostgresStore) changeInbox(ctx context.Context, recipient, query string, args ...any) (int64, error) {
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("updating inbox state: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("counting updated inbox entries: %w", err)
+	}
+	if n > 0 {
+		_, _ = s.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, InboxChannel, recipient)
+	}
+	return n, nil
+}
+
+// Verify interface compliance.
+var _ notification.InboxStore = (*PostgresStore)(nil)
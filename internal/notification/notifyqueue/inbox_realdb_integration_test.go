@@ -0,0 +1,92 @@
+//go:build integration
+
+package notifyqueue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/internal/testdb"
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// TestInboxRealDB proves the inbox against real Postgres: an event queued to
+// several targets lists once, state changes are scoped to their owner, and
+// the unread count follows them.
+func TestInboxRealDB(t *testing.T) {
+	db := testdb.New(t)
+	store := NewPostgresStore(db)
+	ctx := context.Background()
+
+	enqueue := func(recipient string, duplicate bool) {
+		require.NoError(t, store.Enqueue(ctx, notification.Notification{
+			Recipient: recipient, Category: notification.CategoryShare, Duplicate: duplicate,
+			Payload: notification.Payload{Kind: notification.KindAsset, ItemTitle: "Report"},
+		}))
+	}
+	enqueue("a@example.com", false)
+	enqueue("a@example.com", true)
+	enqueue("a@example.com", false)
+	enqueue("b@example.com", false)
+
+	items, err := store.Inbox(ctx, notification.InboxFilter{Recipient: "a@example.com"})
+	require.NoError(t, err)
+	require.Len(t, items, 2, "the duplicate row must not list")
+	require.Equal(t, notification.InboxUnread, items[0].State)
+	unread, err := store.Unread(ctx, "a@example.com")
+	require.NoError(t, err)
+	require.Equal(t, 2, unread)
+
+	t.Run("another person's id changes nothing", func(t *testing.T) {
+		others, err := store.Inbox(ctx, notification.InboxFilter{Recipient: "b@example.com"})
+		require.NoError(t, err)
+		require.Len(t, others, 1)
+		n, err := store.SetInboxState(ctx, "a@example.com", []int64{others[0].ID}, notification.InboxRead)
+		require.NoError(t, err)
+		require.Zero(t, n)
+		stillUnread, err := store.Unread(ctx, "b@example.com")
+		require.NoError(t, err)
+		require.Equal(t, 1, stillUnread)
+	})
+
+	t.Run("archive hides from the default view and counts as read", func(t *testing.T) {
+		n, err := store.SetInboxState(ctx, "a@example.com", []int64{items[0].ID}, notification.InboxArchived)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, n)
+
+		listed, err := store.Inbox(ctx, notification.InboxFilter{Recipient: "a@example.com"})
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		archived, err := store.InboxCount(ctx, notification.InboxFilter{
+			Recipient: "a@example.com", State: notification.InboxArchived,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, archived)
+		unread, err := store.Unread(ctx, "a@example.com")
+		require.NoError(t, err)
+		require.Equal(t, 1, unread)
+	})
+
+	t.Run("mark all read leaves archived alone and unread restores", func(t *testing.T) {
+		n, err := store.MarkAllRead(ctx, "a@example.com")
+		require.NoError(t, err)
+		require.EqualValues(t, 1, n)
+		unread, err := store.Unread(ctx, "a@example.com")
+		require.NoError(t, err)
+		require.Zero(t, unread)
+		archived, err := store.InboxCount(ctx, notification.InboxFilter{
+			Recipient: "a@example.com", State: notification.InboxArchived,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, archived)
+
+		n, err = store.SetInboxState(ctx, "a@example.com", []int64{items[1].ID}, notification.InboxUnread)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, n)
+		unread, err = store.Unread(ctx, "a@example.com")
+		require.NoError(t, err)
+		require.Equal(t, 1, unread)
+	})
+}
@@ -0,0 +1,168 @@
+package notifyqueue
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// TestInboxWhereAlwaysScopesToTheRecipient pins the inbox's authorization: the
+// recipient is always the first bound parameter, duplicate rows never list,
+// and the state filter is bound rather than spliced.
+func TestInboxWhereAlwaysScopesToTheRecipient(t *testing.T) {
+	tests := map[string]struct {
+		filter     notification.InboxFilter
+		wantClause string
+		wantArgs   []any
+	}{
+		"default view hides archived": {
+			filter:     notification.InboxFilter{Recipient: "a@b.io"},
+			wantClause: ` WHERE recipient = $1 AND NOT duplicate AND COALESCE(state, 'unread') <> 'archived'`,
+			wantArgs:   []any{"a@b.io"},
+		},
+		"state is bound": {
+			filter:     notification.InboxFilter{Recipient: "a@b.io", State: notification.InboxUnread},
+			wantClause: ` WHERE recipient = $1 AND NOT duplicate AND COALESCE(state, 'unread') = $2`,
+			wantArgs:   []any{"a@b.io", notification.InboxUnread},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			clause, args := inboxWhere(tc.filter)
+			if clause != tc.wantClause {
+				t.Errorf("clause = %q, want %q", clause, tc.wantClause)
+			}
+			if len(args) != len(tc.wantArgs) {
+				t.Fatalf("args = %v, want %v", args, tc.wantArgs)
+			}
+			for i := range args {
+				if args[i] != tc.wantArgs[i] {
+					t.Errorf("args[%d] = %v, want %v", i, args[i], tc.wantArgs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestInbox_ScansTheState(t *testing.T) {
+	store, mock, done := newMockQueueStore(t)
+	defer done()
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{
+		"id", "recipient", "category", "payload", "digest",
+		"status", "attempts", "last_error", "scheduled_for", "sent_at", "created_at", "channel_id", "duplicate", "state",
+	}).
+		AddRow(2, "a@b.io", "share", []byte(`{"item_title":"Report"}`), false, "sent", 1, "", now, nil, now, nil, false, "unread").
+		AddRow(1, "a@b.io", "comment", []byte(`{}`), true, "pending", 0, "", now, nil, now, nil, false, "read")
+	mock.ExpectQuery(regexp.QuoteMeta("LIMIT $2 OFFSET $3")).
+		WithArgs("a@b.io", 50, 0).
+		WillReturnRows(rows)
+
+	items, err := store.Inbox(context.Background(), notification.InboxFilter{Recipient: "a@b.io"})
+	if err != nil {
+		t.Fatalf("Inbox: %v", err)
+	}
+	if len(items) != 2 || items[0].State != notification.InboxUnread || items[1].State != notification.InboxRead {
+		t.Fatalf("items = %+v", items)
+	}
+	if items[0].Payload.ItemTitle != "Report" {
+		t.Errorf("payload not decoded: %+v", items[0].Payload)
+	}
+}
+
+func TestInbox_QueryError(t *testing.T) {
+	store, mock, done := newMockQueueStore(t)
+	defer done()
+
+	mock.ExpectQuery("SELECT").WillReturnError(errors.New("down"))
+	if _, err := store.Inbox(context.Background(), notification.InboxFilter{Recipient: "a@b.io"}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestUnread_CountsUnreadOnly(t *testing.T) {
+	store, mock, done := newMockQueueStore(t)
+	defer done()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*)")).
+		WithArgs("a@b.io", notification.InboxUnread).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	n, err := store.Unread(context.Background(), "a@b.io")
+	if err != nil || n != 3 {
+		t.Fatalf("Unread = %d, %v", n, err)
+	}
+}
+
+func TestSetInboxState(t *testing.T) {
+	t.Run("read upserts the caller's rows and wakes streams", func(t *testing.T) {
+		store, mock, done := newMockQueueStore(t)
+		defer done()
+
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO notification_inbox")).
+			WithArgs("a@b.io", pq.Array([]int64{4, 5}), notification.InboxRead).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("SELECT pg_notify").
+			WithArgs(InboxChannel, "a@b.io").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		n, err := store.SetInboxState(context.Background(), "a@b.io", []int64{4, 5}, notification.InboxRead)
+		if err != nil || n != 2 {
+			t.Fatalf("SetInboxState = %d, %v", n, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet expectations: %v", err)
+		}
+	})
+
+	t.Run("unread deletes the state and an unchanged write stays quiet", func(t *testing.T) {
+		store, mock, done := newMockQueueStore(t)
+		defer done()
+
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM notification_inbox")).
+			WithArgs("a@b.io", pq.Array([]int64{4})).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		n, err := store.SetInboxState(context.Background(), "a@b.io", []int64{4}, notification.InboxUnread)
+		if err != nil || n != 0 {
+			t.Fatalf("SetInboxState = %d, %v", n, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet expectations: %v", err)
+		}
+	})
+
+	t.Run("unknown state and empty ids touch nothing", func(t *testing.T) {
+		store, mock, done := newMockQueueStore(t)
+		defer done()
+
+		if _, err := store.SetInboxState(context.Background(), "a@b.io", []int64{4}, "pinned"); err == nil {
+			t.Error("expected unknown state error")
+		}
+		if n, err := store.SetInboxState(context.Background(), "a@b.io", nil, notification.InboxRead); err != nil || n != 0 {
+			t.Errorf("empty ids = %d, %v", n, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unexpected queries: %v", err)
+		}
+	})
+}
+
+func TestMarkAllRead(t *testing.T) {
+	store, mock, done := newMockQueueStore(t)
+	defer done()
+
+	mock.ExpectExec(regexp.QuoteMeta("ON CONFLICT (notification_id) DO NOTHING")).
+		WithArgs("a@b.io").
+		WillReturnError(errors.New("down"))
+	if _, err := store.MarkAllRead(context.Background(), "a@b.io"); err == nil {
+		t.Fatal("expected error")
+	}
+}
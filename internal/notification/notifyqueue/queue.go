@@ -4,7 +4,8 @@
 //
 // The store and the listener are two halves of one mechanism — the store
 // NOTIFYs on NotifyChannel, a listener LISTENs on it and wakes the send worker
-// — so the channel name that binds them lives here. The listener itself is
+// — so the channel name that binds them lives here, with InboxChannel, the
+// one open inbox streams are woken from. The listener itself is
 // internal/pglisten, shared with every other queue that wants the same wakeup.
 package notifyqueue
 
@@ -25,12 +26,24 @@ import (
 // row is enqueued. Producers fire it best-effort; the worker also polls.
 const NotifyChannel = "notifications"
 
+// InboxChannel is the pg_notify channel an inbox change fires on, with the
+// recipient whose inbox changed as its payload: an enqueue for them, or a
+// state change they made. It is separate from NotifyChannel so marking an
+// entry read does not wake the send worker, and it names the recipient so
+// only that person's open streams recount.
+const InboxChannel = "notification_inbox"
+
 // NewListener builds the LISTEN adapter for this queue's channel. It binds the
 // channel name so callers wire a listener without repeating it.
 func NewListener(dsn string, notifiers ...pglisten.Notifier) *pglisten.Listener {
 	return pglisten.New(dsn, NotifyChannel, notifiers...)
 }
 
+// NewInboxListener builds the LISTEN adapter for InboxChannel.
+func NewInboxListener(dsn string, notifiers ...pglisten.Notifier) *pglisten.Listener {
+	return pglisten.New(dsn, InboxChannel, notifiers...)
+}
+
 // dueClause matches rows ready for a worker: pending rows whose schedule has
 // arrived, plus sending rows whose lease expired (crashed worker reclaim).
 const dueClause = `((status = 'pending' AND scheduled_for <= NOW())
@@ -47,7 +60,8 @@ func NewPostgresStore(db *sql.DB) *PostgresStore {
 }
 
 // Enqueue inserts a pending notification row and fires a best-effort
-// pg_notify so a listening worker wakes without waiting for the next poll.
+// pg_notify so a listening worker wakes without waiting for the next poll, and
+// the recipient's open inbox streams recount.
 func (s *PostgresStore) Enqueue(ctx context.Context, n notification.Notification) error {
 	payload, err := json.Marshal(n.Payload)
 	if err != nil {
@@ -60,35 +74,43 @@ func (s *PostgresStore) Enqueue(ctx context.Context, n notification.Notification
 	if !n.ScheduledFor.IsZero() {
 		scheduled = n.ScheduledFor
 	}
+	// A zero ChannelID is the mailbox, stored as NULL so the foreign key
+	// holds only for a channel row.
+	var channel any
+	if n.ChannelID != 0 {
+		channel = n.ChannelID
+	}
 	_, err = s.db.ExecContext(ctx,
-		`INSERT INTO notifications (recipient, category, payload, digest, scheduled_for)
-		 VALUES ($1, $2, $3, $4, COALESCE($5, NOW()))`,
-		n.Recipient, n.Category, payload, n.Digest, scheduled)
+		`INSERT INTO notifications (recipient, category, payload, digest, scheduled_for, channel_id, duplicate)
+		 VALUES ($1, $2, $3, $4, COALESCE($5, NOW()), $6, $7)`,
+		n.Recipient, n.Category, payload, n.Digest, scheduled, channel, n.Duplicate)
 	if err != nil {
 		return fmt.Errorf("enqueueing notification: %w", err)
 	}
 	// Best-effort wakeup; the worker's poll ticker is the fallback.
-	_, _ = s.db.ExecContext(ctx, `SELECT pg_notify($1, '')`, NotifyChannel)
+	_, _ = s.db.ExecContext(ctx, `SELECT pg_notify($1, ''), pg_notify($2, $3)`,
+		NotifyChannel, InboxChannel, n.Recipient)
 	return nil
 }
 
 // notificationColumns is the scan list shared by the claim queries.
 const notificationColumns = `id, recipient, category, payload, digest, status,
-	attempts, last_error, scheduled_for, sent_at, created_at`
+	attempts, last_error, scheduled_for, sent_at, created_at, channel_id, duplicate`
 
-// ClaimImmediate claims the next due non-digest row.
-func (s *PostgresStore) ClaimImmediate(ctx context.Context, lease time.Duration) (*notification.Notification, error) {
+// ClaimImmediate claims the next due non-digest row, leaving email rows alone
+// unless includeEmail.
+func (s *PostgresStore) ClaimImmediate(ctx context.Context, lease time.Duration, includeEmail bool) (*notification.Notification, error) {
 	rows, err := s.claim(ctx, lease,
 		`UPDATE notifications
 		   SET status = 'sending', attempts = attempts + 1,
 		       locked_until = NOW() + ($1 || ' seconds')::INTERVAL
 		 WHERE id = (
 		     SELECT id FROM notifications
-		      WHERE digest = FALSE AND `+dueClause+`
+		      WHERE digest = FALSE AND (channel_id IS NOT NULL OR $2) AND `+dueClause+`
 		      ORDER BY scheduled_for, id
 		      LIMIT 1
 		      FOR UPDATE SKIP LOCKED)
-		 RETURNING `+notificationColumns)
+		 RETURNING `+notificationColumns, includeEmail)
 	if err != nil {
 		return nil, err
 	}
@@ -113,8 +135,8 @@ func (s *PostgresStore) ClaimDigest(ctx context.Context, lease time.Duration) ([
 }
 
 // claim runs one of the claim UPDATE queries and scans the returned rows.
-func (s *PostgresStore) claim(ctx context.Context, lease time.Duration, query string) ([]notification.Notification, error) {
-	rows, err := s.db.QueryContext(ctx, query, int(lease.Seconds()))
+func (s *PostgresStore) claim(ctx context.Context, lease time.Duration, query string, args ...any) ([]notification.Notification, error) {
+	rows, err := s.db.QueryContext(ctx, query, append([]any{int(lease.Seconds())}, args...)...)
 	if err != nil {
 		return nil, fmt.Errorf("claiming notifications: %w", err)
 	}
@@ -197,8 +219,9 @@ func scanNotification(row interface{ Scan(dest ...any) error }) (*notification.N
 	var n notification.Notification
 	var payload []byte
 	var sentAt sql.NullTime
+	var channelID sql.NullInt64
 	if err := row.Scan(&n.ID, &n.Recipient, &n.Category, &payload, &n.Digest,
-		&n.Status, &n.Attempts, &n.LastError, &n.ScheduledFor, &sentAt, &n.CreatedAt); err != nil {
+		&n.Status, &n.Attempts, &n.LastError, &n.ScheduledFor, &sentAt, &n.CreatedAt, &channelID, &n.Duplicate); err != nil {
 		return nil, err //nolint:wrapcheck // callers add context per call site
 	}
 	if err := json.Unmarshal(payload, &n.Payload); err != nil {
@@ -207,6 +230,7 @@ func scanNotification(row interface{ Scan(dest ...any) error }) (*notification.N
 	if sentAt.Valid {
 		n.SentAt = &sentAt.Time
 	}
+	n.ChannelID = channelID.Int64
 	return &n, nil
 }
 
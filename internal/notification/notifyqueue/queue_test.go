@@ -25,7 +25,7 @@ func notificationRows(t *testing.T, ns ...notification.Notification) *sqlmock.Ro
 	t.Helper()
 	rows := sqlmock.NewRows([]string{
 		"id", "recipient", "category", "payload", "digest",
-		"status", "attempts", "last_error", "scheduled_for", "sent_at", "created_at",
+		"status", "attempts", "last_error", "scheduled_for", "sent_at", "created_at", "channel_id", "duplicate",
 	})
 	for _, n := range ns {
 		payload, err := json.Marshal(n.Payload)
@@ -33,11 +33,20 @@ func notificationRows(t *testing.T, ns ...notification.Notification) *sqlmock.Ro
 			t.Fatal(err)
 		}
 		rows.AddRow(n.ID, n.Recipient, n.Category, payload, n.Digest,
-			n.Status, n.Attempts, n.LastError, n.ScheduledFor, nil, n.CreatedAt)
+			n.Status, n.Attempts, n.LastError, n.ScheduledFor, nil, n.CreatedAt, channelArg(n.ChannelID), n.Duplicate)
 	}
 	return rows
 }
 
+// channelArg is the channel_id column as the database returns it: NULL for
+// the mailbox.
+func channelArg(id int64) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
 // enqueueInsert is the exact statement Enqueue must issue. Matching the full
 // column list rather than the table name alone means a column added, dropped or
 // reordered fails here instead of being rubber-stamped: sqlmock validates
@@ -45,7 +54,7 @@ func notificationRows(t *testing.T, ns ...notification.Notification) *sqlmock.Ro
 // insert real Postgres rejects. TestQueueStoreRealDB is the backstop that
 // catches what no mock can.
 var enqueueInsert = regexp.QuoteMeta(
-	`INSERT INTO notifications (recipient, category, payload, digest, scheduled_for)`)
+	`INSERT INTO notifications (recipient, category, payload, digest, scheduled_for, channel_id, duplicate)`)
 
 func TestQueueStore_Enqueue(t *testing.T) {
 	t.Run("unscheduled notification defers to the database clock", func(t *testing.T) {
@@ -60,10 +69,10 @@ func TestQueueStore_Enqueue(t *testing.T) {
 		// stamp the row with the database clock. Passing a Go-side timestamp
 		// here would reintroduce the host/DB clock skew the nil exists to avoid.
 		mock.ExpectExec(enqueueInsert).
-			WithArgs("a@b.io", notification.CategoryShare, payload, false, nil).
+			WithArgs("a@b.io", notification.CategoryShare, payload, false, nil, nil, false).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectExec("SELECT pg_notify").
-			WithArgs(NotifyChannel).
+			WithArgs(NotifyChannel, InboxChannel, "a@b.io").
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
 		if err := store.Enqueue(context.Background(), notification.Notification{
@@ -87,10 +96,10 @@ func TestQueueStore_Enqueue(t *testing.T) {
 			t.Fatal(err)
 		}
 		mock.ExpectExec(enqueueInsert).
-			WithArgs("a@b.io", notification.CategoryShare, payload, true, when).
+			WithArgs("a@b.io", notification.CategoryShare, payload, true, when, nil, false).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectExec("SELECT pg_notify").
-			WithArgs(NotifyChannel).
+			WithArgs(NotifyChannel, InboxChannel, "a@b.io").
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
 		if err := store.Enqueue(context.Background(), notification.Notification{
@@ -142,10 +151,10 @@ func TestQueueStore_ClaimImmediate(t *testing.T) {
 		Payload: notification.Payload{Kind: notification.KindAsset, ItemTitle: "Report"},
 	}
 	mock.ExpectQuery("UPDATE notifications").
-		WithArgs(120).
+		WithArgs(120, true).
 		WillReturnRows(notificationRows(t, n))
 
-	got, err := store.ClaimImmediate(context.Background(), 2*time.Minute)
+	got, err := store.ClaimImmediate(context.Background(), 2*time.Minute, true)
 	if err != nil {
 		t.Fatalf("ClaimImmediate: %v", err)
 	}
@@ -154,6 +163,44 @@ func TestQueueStore_ClaimImmediate(t *testing.T) {
 	}
 }
 
+// TestQueueStore_ChannelRows covers both halves of a channel row: Enqueue
+// binds its channel, and a claim with no mail server asks only for channel
+// rows and reads the channel back.
+func TestQueueStore_ChannelRows(t *testing.T) {
+	store, mock, done := newMockQueueStore(t)
+	defer done()
+
+	payload, err := json.Marshal(notification.Payload{Kind: notification.KindScriptRun})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectExec(enqueueInsert).
+		WithArgs("a@b.io", notification.CategoryScriptRun, payload, false, nil, int64(7), true).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("SELECT pg_notify").WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := store.Enqueue(context.Background(), notification.Notification{
+		Recipient: "a@b.io", Category: notification.CategoryScriptRun, ChannelID: 7, Duplicate: true,
+		Payload: notification.Payload{Kind: notification.KindScriptRun},
+	}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	n := notification.Notification{ID: 3, Recipient: "a@b.io", ChannelID: 7, ScheduledFor: time.Now(), CreatedAt: time.Now()}
+	mock.ExpectQuery(regexp.QuoteMeta("(channel_id IS NOT NULL OR $2)")).
+		WithArgs(60, false).
+		WillReturnRows(notificationRows(t, n))
+	got, err := store.ClaimImmediate(context.Background(), time.Minute, false)
+	if err != nil {
+		t.Fatalf("ClaimImmediate: %v", err)
+	}
+	if got.ChannelID != 7 {
+		t.Errorf("ChannelID = %d, want 7", got.ChannelID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
 func TestQueueStore_ClaimImmediate_NoWork(t *testing.T) {
 	store, mock, done := newMockQueueStore(t)
 	defer done()
@@ -161,7 +208,7 @@ func TestQueueStore_ClaimImmediate_NoWork(t *testing.T) {
 	mock.ExpectQuery("UPDATE notifications").
 		WillReturnRows(notificationRows(t))
 
-	if _, err := store.ClaimImmediate(context.Background(), time.Minute); !errors.Is(err, notification.ErrNoWork) {
+	if _, err := store.ClaimImmediate(context.Background(), time.Minute, true); !errors.Is(err, notification.ErrNoWork) {
 		t.Fatalf("expected notification.ErrNoWork, got %v", err)
 	}
 }
@@ -202,13 +249,13 @@ func TestQueueStore_Claim_BadPayload(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{
 		"id", "recipient", "category", "payload", "digest",
-		"status", "attempts", "last_error", "scheduled_for", "sent_at", "created_at",
+		"status", "attempts", "last_error", "scheduled_for", "sent_at", "created_at", "channel_id", "duplicate",
 	}).
 		AddRow(1, "a@b.io", notification.CategoryShare, []byte("{bad"), false,
-			notification.StatusSending, 1, "", time.Now(), nil, time.Now())
+			notification.StatusSending, 1, "", time.Now(), nil, time.Now(), nil, false)
 	mock.ExpectQuery("UPDATE notifications").WillReturnRows(rows)
 
-	if _, err := store.ClaimImmediate(context.Background(), time.Minute); err == nil {
+	if _, err := store.ClaimImmediate(context.Background(), time.Minute, true); err == nil {
 		t.Fatal("expected payload decode error")
 	}
 }
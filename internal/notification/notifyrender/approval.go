@@ -0,0 +1,62 @@
+package notifyrender
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// approvalLinkText labels the approval emails' button. The link opens the
+// approval record; the generic label would quote the request line back.
+const approvalLinkText = "Open the approval"
+
+// approvalSubject is the subject and heading of the three approval emails: a
+// request an approver is asked to decide, and the decision its requester is
+// told about. ItemTitle is the request's one-line summary ("POST /v1/orders on
+// crm").
+func approvalSubject(p notification.Payload) string {
+	what := "a write operation"
+	if p.ItemTitle != "" {
+		what = p.ItemTitle
+	}
+	switch p.Kind {
+	case notification.KindApprovalApproved:
+		return fmt.Sprintf("Your request to run %s was approved by %s", what, actorOr(p.Actor, "an approver"))
+	case notification.KindApprovalRejected:
+		return fmt.Sprintf("Your request to run %s was rejected by %s", what, actorOr(p.Actor, "an approver"))
+	default:
+		return fmt.Sprintf("%s asks to run %s", actorOr(p.Actor, "An agent"), what)
+	}
+}
+
+// approvalBody is the prose body of an approval email. For a request it
+// carries the request itself, which renders unquoted because the platform is
+// speaking (emailItem.Body): it is a tool call's arguments, not something a
+// person wrote. A decision's reason stays in Message, quoted, because the
+// approver wrote it.
+func approvalBody(p notification.Payload) string {
+	switch p.Kind {
+	case notification.KindApprovalApproved:
+		return "The agent may now repeat the call exactly as it was made, and it will run once. " +
+			"An approval that goes unused expires."
+	case notification.KindApprovalRejected:
+		return "The call was not run. Repeating it starts a new approval request."
+	}
+	body := "An agent made a call that an approval rule holds for review. It has not run, and it will not run unless someone approves it before it expires."
+	if p.ItemID != "" {
+		body += fmt.Sprintf(" Its approval is %s.", p.ItemID)
+	}
+	if detail := strings.TrimSpace(p.Message); detail != "" {
+		body += "\n\n" + detail
+	}
+	return body
+}
+
+// actorOr returns actor, or fallback when it is empty.
+func actorOr(actor, fallback string) string {
+	if actor == "" {
+		return fallback
+	}
+	return actor
+}
@@ -0,0 +1,90 @@
+package notifyrender
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// approvalNotification is one of the three approval emails for a parked
+// order creation.
+func approvalNotification(kind, actor, message string) notification.Notification {
+	return notification.Notification{
+		Recipient: "lead@b.io",
+		Payload: notification.Payload{
+			Kind: kind, ItemID: "apr_1", ItemTitle: "POST /v1/orders on crm",
+			Actor: actor, Message: message, Link: "https://portal.example.com/api/v1/portal/approvals/apr_1",
+		},
+	}
+}
+
+// TestApprovalSubject pins the line an inbox shows for each kind: who asks
+// for what, and who decided.
+func TestApprovalSubject(t *testing.T) {
+	cases := []struct {
+		kind, actor string
+		want        []string
+	}{
+		{notification.KindApprovalRequest, "jane@b.io", []string{"jane@b.io", "POST /v1/orders on crm"}},
+		{notification.KindApprovalApproved, "lead@b.io", []string{"approved", "lead@b.io"}},
+		{notification.KindApprovalRejected, "lead@b.io", []string{"rejected", "lead@b.io"}},
+	}
+	for _, c := range cases {
+		got := Subject(approvalNotification(c.kind, c.actor, ""))
+		for _, w := range c.want {
+			if !strings.Contains(got, w) {
+				t.Errorf("%s subject = %q, want it to carry %q", c.kind, got, w)
+			}
+		}
+	}
+	if bare := approvalSubject(notification.Payload{Kind: notification.KindApprovalRequest}); bare == "" {
+		t.Error("a payload with no title or actor must still render a meaningful line")
+	}
+}
+
+// TestApprovalRequestBody pins that the request detail renders as the
+// platform's prose, not as a quotation, and that the button names the action.
+func TestApprovalRequestBody(t *testing.T) {
+	item := buildItem(approvalNotification(notification.KindApprovalRequest, "jane@b.io", "Tool: api_invoke_endpoint"))
+	if item.Message != "" {
+		t.Error("the request detail must not render as a quotation")
+	}
+	for _, want := range []string{"apr_1", "api_invoke_endpoint", "has not run"} {
+		if !strings.Contains(item.Body, want) {
+			t.Errorf("body must carry %q, got %q", want, item.Body)
+		}
+	}
+	if item.LinkText != approvalLinkText {
+		t.Errorf("LinkText = %q", item.LinkText)
+	}
+}
+
+// TestApprovalDecisionKeepsTheReasonQuoted pins that an approver's reason
+// stays a quotation: a person wrote it.
+func TestApprovalDecisionKeepsTheReasonQuoted(t *testing.T) {
+	item := buildItem(approvalNotification(notification.KindApprovalRejected, "lead@b.io", "wrong customer"))
+	if item.Message != "wrong customer" {
+		t.Errorf("Message = %q, want the reason", item.Message)
+	}
+	if !strings.Contains(item.Body, "not run") {
+		t.Errorf("body = %q", item.Body)
+	}
+}
+
+// TestApprovalRendersThroughTheRealTemplates pins that each kind survives the
+// renderer end to end.
+func TestApprovalRendersThroughTheRealTemplates(t *testing.T) {
+	for _, kind := range []string{notification.KindApprovalRequest, notification.KindApprovalApproved, notification.KindApprovalRejected} {
+		email, err := testRenderer(t).Render([]notification.Notification{approvalNotification(kind, "lead@b.io", "detail")})
+		if err != nil {
+			t.Fatalf("%s: %v", kind, err)
+		}
+		if !strings.Contains(email.Subject, "POST /v1/orders on crm") {
+			t.Errorf("%s subject = %q", kind, email.Subject)
+		}
+		if !strings.Contains(email.HTML, approvalLinkText) || !strings.Contains(email.Text, "detail") {
+			t.Errorf("%s: bodies must carry the link label and the detail", kind)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package notifyrender
+
+import "github.com/txn2/mcp-data-platform/pkg/notification"
+
+// maxMessageBody bounds a chat message's body. Slack truncates a section
+// block's text at 3000 characters and Teams renders a long card as a wall;
+// the link carries the rest.
+const maxMessageBody = 2500
+
+// Message is one notification rendered for a chat or webhook channel: the
+// same sentences its email carries, without the email's layout, footer, or
+// logo. A transport formats it for the receiver.
+type Message struct {
+	// ID is the queue row's, stable across retries of the same delivery.
+	ID int64 `json:"id"`
+	// Brand names the deployment the message comes from.
+	Brand string `json:"brand"`
+	// Category and Kind identify the event for a receiver that routes on
+	// them; a person reads Title.
+	Category string `json:"category"`
+	Kind     string `json:"kind"`
+	// Title is the line the email's subject carries.
+	Title string `json:"title"`
+	// Body is the platform's prose about the event; Quote is what a person
+	// wrote. Either may be empty.
+	Body  string `json:"body,omitempty"`
+	Quote string `json:"quote,omitempty"`
+	// Link opens the item in the portal; LinkText labels it.
+	Link     string `json:"link,omitempty"`
+	LinkText string `json:"link_text,omitempty"`
+	// SettingsURL is where the recipient changes what reaches this channel.
+	SettingsURL string `json:"settings_url,omitempty"`
+}
+
+// RenderMessage renders one notification for a channel. A channel row is
+// never a digest, so it renders the per-event sentences alone.
+func (r *Renderer) RenderMessage(n notification.Notification) Message {
+	item := buildItem(n)
+	linkText := item.LinkText
+	if linkText == "" && item.Link != "" {
+		linkText = "Open in " + r.branding.Name
+	}
+	return Message{
+		ID:          n.ID,
+		Brand:       r.branding.Name,
+		Category:    n.Category,
+		Kind:        n.Payload.Kind,
+		Title:       item.Detail,
+		Body:        clip(item.Body, maxMessageBody),
+		Quote:       clip(item.Message, maxMessageBody),
+		Link:        item.Link,
+		LinkText:    linkText,
+		SettingsURL: joinURL(r.branding.BaseURL, "/portal/settings"),
+	}
+}
+
+// clip shortens s to at most n runes, marking the cut.
+func clip(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,36 @@
+package notifyrender
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// TestRenderMessage pins that a channel message says what the email says: the
+// subject line as its title, the platform's prose unquoted, and a link back.
+func TestRenderMessage(t *testing.T) {
+	r, err := NewRenderer(Branding{Name: "ACME", BaseURL: "https://data.acme.io/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := scriptRunNotification()
+	n.Category = notification.CategoryScriptRun
+	n.Payload.Link = "https://data.acme.io/portal/scripts/s1"
+
+	m := r.RenderMessage(n)
+	if m.Title != scriptRunSubject(n.Payload) {
+		t.Errorf("title = %q; want the email subject", m.Title)
+	}
+	if m.Quote != "" || !strings.Contains(m.Body, "division by zero") {
+		t.Errorf("the failure detail must be prose, not a quotation: %+v", m)
+	}
+	if m.LinkText != "Open in ACME" || m.SettingsURL != "https://data.acme.io/portal/settings" {
+		t.Errorf("links: %+v", m)
+	}
+
+	n.Payload.Message = strings.Repeat("x", 3*maxMessageBody)
+	if got := len([]rune(r.RenderMessage(n).Body)); got > maxMessageBody {
+		t.Errorf("body of %d runes exceeds the cap", got)
+	}
+}
@@ -201,6 +201,23 @@ func (r *Renderer) RenderGuestLink(to, link string) (*Email, error) {
 	return r.execute(to, data)
 }
 
+// RenderShareCode renders the verification-code email a guarded public share
+// link sends when it checks the visitor's address. Like the guest link it is
+// transactional: the visitor asked for it, so it carries no unsubscribe
+// footer.
+func (r *Renderer) RenderShareCode(to, code string) (*Email, error) {
+	data := emailData{
+		Brand:   r.branding,
+		Subject: fmt.Sprintf("%s: your verification code", r.branding.Name),
+		Heading: "Your verification code",
+		Items: []emailItem{{
+			Body: "Your code is " + code + ". Enter it on the shared link's page to open it. " +
+				"It expires in 10 minutes. If you did not ask for it, you can ignore this email.",
+		}},
+	}
+	return r.execute(to, data)
+}
+
 // RenderTest renders the admin "send test email" message used to verify a
 // new SMTP configuration end to end.
 func (r *Renderer) RenderTest(to string) (*Email, error) {
@@ -285,11 +302,26 @@ func buildItem(n notification.Notification) emailItem {
 	case notification.KindReviewQueue:
 		item.Body = reviewQueueBody(n.Payload.Review)
 		item.LinkText = reviewQueueLinkText
-	case notification.KindScriptRun:
+	case notification.KindScriptRun, notification.KindScriptQuality:
 		// The failure detail moves from Message to Body: Message renders as a
 		// quotation, and a backtrace is not something a colleague said.
 		item.Body = scriptRunBody(n.Payload)
 		item.Message = ""
+	case notification.KindApprovalRequest:
+		// The request is the platform's own record of a tool call, so it
+		// renders as prose, not as a quotation.
+		item.Body = approvalBody(n.Payload)
+		item.Message = ""
+		item.LinkText = approvalLinkText
+	case notification.KindApprovalApproved, notification.KindApprovalRejected:
+		item.Body = approvalBody(n.Payload)
+		item.LinkText = approvalLinkText
+	case notification.KindWatchChangeset, notification.KindWatchSchema:
+		// What changed is the platform's description, carried in Body.
+		item.Body = watchBody(n.Payload)
+		item.Message = ""
+	case notification.KindWatchVersion, notification.KindWatchThread:
+		item.Body = watchBody(n.Payload)
 	}
 	return item
 }
@@ -314,8 +346,13 @@ func subjectFor(n notification.Notification) string {
 		return fmt.Sprintf("%s mentioned you on %q", n.Payload.Actor, n.Payload.ItemTitle)
 	case notification.KindReviewQueue:
 		return reviewQueueSubject(n.Payload.Review)
-	case notification.KindScriptRun:
+	case notification.KindScriptRun, notification.KindScriptQuality:
 		return scriptRunSubject(n.Payload)
+	case notification.KindApprovalRequest, notification.KindApprovalApproved, notification.KindApprovalRejected:
+		return approvalSubject(n.Payload)
+	case notification.KindWatchVersion, notification.KindWatchThread,
+		notification.KindWatchChangeset, notification.KindWatchSchema:
+		return watchSubject(n.Payload)
 	default:
 		return fmt.Sprintf("%s commented on %q", n.Payload.Actor, n.Payload.ItemTitle)
 	}
@@ -67,3 +67,19 @@ func TestRenderOmitsUnsubscribeFooterWithoutBuilder(t *testing.T) {
 	assert.NotContains(t, email.Text, "Unsubscribe")
 	assert.Empty(t, email.UnsubURL)
 }
+
+func TestRenderShareCode(t *testing.T) {
+	r, err := NewRenderer(Branding{Name: "ACME Data", BaseURL: "https://platform.example.com"})
+	require.NoError(t, err)
+
+	email, err := r.RenderShareCode("bob@example.com", "042917")
+	require.NoError(t, err)
+
+	assert.Equal(t, "bob@example.com", email.To)
+	assert.Equal(t, "ACME Data: your verification code", email.Subject)
+	for _, body := range []string{email.HTML, email.Text} {
+		assert.Contains(t, body, "042917")
+		assert.Contains(t, body, "expires in 10 minutes")
+	}
+	assert.Empty(t, email.UnsubURL, "a transactional send must not trigger List-Unsubscribe headers")
+}
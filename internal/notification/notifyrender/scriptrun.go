@@ -15,6 +15,12 @@ import (
 // the check that wrote it, so a row enqueued by one build may be delivered by
 // another.
 func scriptRunSubject(p notification.Payload) string {
+	if p.Kind == notification.KindScriptQuality {
+		if p.ItemTitle == "" {
+			return "A scheduled script's data-quality checks failed"
+		}
+		return fmt.Sprintf("The scheduled script %q failed its data-quality checks", p.ItemTitle)
+	}
 	if p.ItemTitle == "" {
 		return "A scheduled script failed"
 	}
@@ -25,15 +31,28 @@ func scriptRunSubject(p notification.Payload) string {
 // printed by then. It renders unquoted (emailItem.Body) because the platform is
 // speaking here — the text it carries is a stack trace and a program's own
 // output, not something a person wrote.
+//
+// A data-quality failure says the reverse of an execution failure: the script
+// ran to the end, and what it checked did not hold. Telling its owner to
+// correct the script would send them to the one thing that worked.
 func scriptRunBody(p notification.Payload) string {
+	quality := p.Kind == notification.KindScriptQuality
 	sentences := []string{
 		"The platform ran this script on its schedule and the run did not finish.",
 	}
+	if quality {
+		sentences[0] = "The platform ran this script on its schedule. The script ran to the end, and data it checks did not pass."
+	}
 	if p.ItemID != "" {
 		sentences = append(sentences, fmt.Sprintf("Its run is %s.", p.ItemID))
 	}
-	sentences = append(sentences,
-		"A script failure is never retried: the same version on the same inputs fails the same way, so the schedule will try again at its next fire and fail again until the script is corrected and the correction approved.")
+	if quality {
+		sentences = append(sentences,
+			"The run is recorded as a data-quality failure rather than a script error; the checks below say what was measured and what was expected. The schedule will measure the data again at its next fire.")
+	} else {
+		sentences = append(sentences,
+			"A script failure is never retried: the same version on the same inputs fails the same way, so the schedule will try again at its next fire and fail again until the script is corrected and the correction approved.")
+	}
 	body := strings.Join(sentences, " ")
 	if detail := strings.TrimSpace(p.Message); detail != "" {
 		body += "\n\n" + detail
@@ -64,3 +64,24 @@ func TestScriptRunRendersThroughTheRealTemplates(t *testing.T) {
 		t.Error("both bodies must carry the failure")
 	}
 }
+
+// TestScriptQualityAlert pins that a data-quality failure is not reported as a
+// broken script: its owner is told the data failed, not to correct the code.
+func TestScriptQualityAlert(t *testing.T) {
+	n := scriptRunNotification()
+	n.Payload.Kind = notification.KindScriptQuality
+	n.Payload.Message = "Failed checks:\nrow_count failed on hive.sales.orders: observed 3 rows, expected at least 10 rows"
+
+	if got := Subject(n); !strings.Contains(got, "daily-sales") || !strings.Contains(got, "data-quality") {
+		t.Errorf("subject must name the script and the kind of failure, got %q", got)
+	}
+	item := buildItem(n)
+	for _, want := range []string{"dpx_1", "ran to the end", "observed 3 rows"} {
+		if !strings.Contains(item.Body, want) {
+			t.Errorf("body must carry %q, got %q", want, item.Body)
+		}
+	}
+	if strings.Contains(item.Body, "never retried") {
+		t.Error("a data-quality failure must not tell the owner to correct the script")
+	}
+}
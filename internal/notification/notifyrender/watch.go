@@ -0,0 +1,60 @@
+package notifyrender
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// watchReason closes every watch email: the recipient asked for this by
+// watching the item, and unwatching it is the way to stop it, since the watch
+// category has no toggle of its own.
+const watchReason = "You are receiving this because you watch this item. Unwatch it in the portal to stop these."
+
+// watchSubject is the subject and heading of news about a watched item.
+// ItemTitle names the item; a payload without one still renders a line,
+// because a queue row outlives the sweep that wrote it.
+func watchSubject(p notification.Payload) string {
+	what := "an item you watch"
+	if p.ItemTitle != "" {
+		what = fmt.Sprintf("%q", p.ItemTitle)
+	}
+	switch p.Kind {
+	case notification.KindWatchThread:
+		return fmt.Sprintf("%s started a thread on %s", actorOr(p.Actor, "Someone"), what)
+	case notification.KindWatchChangeset:
+		return fmt.Sprintf("%s applied a knowledge change to %s", actorOr(p.Actor, "Someone"), what)
+	case notification.KindWatchSchema:
+		return fmt.Sprintf("The schema of %s changed", what)
+	default:
+		if p.Actor == "" {
+			return fmt.Sprintf("A new version of %s is available", what)
+		}
+		return fmt.Sprintf("%s published a new version of %s", p.Actor, what)
+	}
+}
+
+// watchBody is the prose body of a watch email. A knowledge change and a
+// schema change carry the platform's own description of what changed, which
+// renders unquoted; a version's change summary and a thread's title stay in
+// Message, quoted, because a person wrote them.
+func watchBody(p notification.Payload) string {
+	detail := strings.TrimSpace(p.Message)
+	switch p.Kind {
+	case notification.KindWatchChangeset:
+		body := "An approved knowledge change was applied to the catalog."
+		if detail != "" {
+			body = fmt.Sprintf("An approved knowledge change (%s) was applied to the catalog.", detail)
+		}
+		return body + " " + watchReason
+	case notification.KindWatchSchema:
+		body := "The catalog reports different columns than it did when last checked."
+		if detail != "" {
+			body += "\n\n" + detail
+		}
+		return body + "\n\n" + watchReason
+	default:
+		return watchReason
+	}
+}
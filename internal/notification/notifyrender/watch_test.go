@@ -0,0 +1,62 @@
+package notifyrender
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+func watchNotification(kind, actor, message string) notification.Notification {
+	return notification.Notification{
+		Recipient: "a@b.io",
+		Category:  notification.CategoryWatch,
+		Payload: notification.Payload{
+			Kind: kind, ItemID: "ast_1", ItemTitle: "Revenue", Actor: actor, Message: message,
+			Link: "https://portal.example.com/portal/assets/ast_1",
+		},
+	}
+}
+
+// TestWatchSubject pins the line an inbox shows for each kind of news.
+func TestWatchSubject(t *testing.T) {
+	cases := []struct {
+		kind, actor string
+		want        []string
+	}{
+		{notification.KindWatchVersion, "ed@b.io", []string{"ed@b.io", "new version", `"Revenue"`}},
+		{notification.KindWatchVersion, "", []string{"A new version", `"Revenue"`}},
+		{notification.KindWatchThread, "ann@b.io", []string{"ann@b.io", "thread"}},
+		{notification.KindWatchChangeset, "lead@b.io", []string{"lead@b.io", "knowledge change"}},
+		{notification.KindWatchSchema, "", []string{"schema", `"Revenue"`}},
+	}
+	for _, c := range cases {
+		got := Subject(watchNotification(c.kind, c.actor, ""))
+		for _, w := range c.want {
+			if !strings.Contains(got, w) {
+				t.Errorf("%s subject = %q, want it to carry %q", c.kind, got, w)
+			}
+		}
+	}
+	if bare := watchSubject(notification.Payload{Kind: notification.KindWatchThread}); !strings.Contains(bare, "an item you watch") {
+		t.Errorf("a payload with no title must still render a meaningful line, got %q", bare)
+	}
+}
+
+// TestWatchBody pins who is speaking: a schema diff is the platform's prose,
+// while a version's change summary is quoted because a person wrote it.
+func TestWatchBody(t *testing.T) {
+	schema := buildItem(watchNotification(notification.KindWatchSchema, "", "Added columns: region."))
+	if schema.Message != "" || !strings.Contains(schema.Body, "Added columns: region.") {
+		t.Errorf("schema item = %+v", schema)
+	}
+	version := buildItem(watchNotification(notification.KindWatchVersion, "ed@b.io", "Fixed Q3 totals"))
+	if version.Message != "Fixed Q3 totals" {
+		t.Errorf("the change summary must stay quoted, got %+v", version)
+	}
+	for _, item := range []emailItem{schema, version} {
+		if !strings.Contains(item.Body, "Unwatch") {
+			t.Errorf("body must say how to stop these: %q", item.Body)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package notifysend
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	mail "github.com/wneessen/go-mail"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification/smtp"
+)
+
+// Attachment is one file mailed to a fixed set of recipients: a script output
+// delivered to an email destination. Unlike a notification it carries no
+// branding and no unsubscribe link, because nobody subscribed to it — an
+// operator addressed it in configuration.
+type Attachment struct {
+	To          []string
+	Subject     string
+	Text        string
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// SendAttachment delivers one attachment over the same admin SMTP settings a
+// notification uses, in one message to every recipient.
+func (*SMTPSender) SendAttachment(ctx context.Context, settings smtp.Settings, a Attachment) error {
+	msg, err := buildAttachmentMessage(settings, a)
+	if err != nil {
+		return err
+	}
+	client, err := buildClient(settings)
+	if err != nil {
+		return err
+	}
+	if err := client.DialAndSendWithContext(ctx, msg); err != nil {
+		return fmt.Errorf("sending %s: %w", a.Filename, err)
+	}
+	return nil
+}
+
+// buildAttachmentMessage assembles the plaintext message and its one file.
+func buildAttachmentMessage(settings smtp.Settings, a Attachment) (*mail.Msg, error) {
+	if len(a.To) == 0 {
+		return nil, errors.New("an attachment needs at least one recipient")
+	}
+	msg := mail.NewMsg()
+	if settings.FromName != "" {
+		if err := msg.FromFormat(settings.FromName, settings.From); err != nil {
+			return nil, fmt.Errorf("invalid from address %q: %w", settings.From, err)
+		}
+	} else if err := msg.From(settings.From); err != nil {
+		return nil, fmt.Errorf("invalid from address %q: %w", settings.From, err)
+	}
+	if err := msg.To(a.To...); err != nil {
+		return nil, fmt.Errorf("invalid recipient address in %v: %w", a.To, err)
+	}
+	if domain := messageIDDomain(settings.From); domain != "" {
+		msg.SetMessageIDWithValue(rand.Text() + "@" + domain)
+	}
+	msg.Subject(a.Subject)
+	msg.SetBodyString(mail.TypeTextPlain, a.Text)
+	msg.AttachReadSeeker(a.Filename, bytes.NewReader(a.Data),
+		mail.WithFileContentType(mail.ContentType(a.ContentType)))
+	return msg, nil
+}
@@ -387,3 +387,37 @@ func TestSMTPSender_Send_BadClient(t *testing.T) {
 		t.Fatal("expected client build error for empty host")
 	}
 }
+
+func TestSMTPSender_SendAttachment_EndToEnd(t *testing.T) {
+	port, dataCh := startFakeSMTPServer(t, false)
+	s := NewSMTPSender()
+
+	err := s.SendAttachment(context.Background(), smtp.Settings{
+		Host: "127.0.0.1", Port: port, From: "p@example.com", TLSMode: smtp.TLSModeNone,
+	}, Attachment{
+		To: []string{"a@b.io", "c@d.io"}, Subject: "weekly-sales", Text: "attached",
+		Filename: "sales.csv", ContentType: "text/csv", Data: []byte("region,total\nwest,3\n"),
+	})
+	if err != nil {
+		t.Fatalf("SendAttachment: %v", err)
+	}
+
+	select {
+	case data := <-dataCh:
+		for _, want := range []string{"Subject: weekly-sales", `filename="sales.csv"`, "text/csv", "c@d.io"} {
+			if !strings.Contains(data, want) {
+				t.Errorf("DATA missing %q:\n%s", want, data)
+			}
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no DATA received")
+	}
+}
+
+func TestSMTPSender_SendAttachment_NoRecipients(t *testing.T) {
+	s := NewSMTPSender()
+	err := s.SendAttachment(context.Background(), smtp.Settings{From: "p@example.com"}, Attachment{Filename: "a.csv"})
+	if err == nil {
+		t.Fatal("expected an error for an attachment addressed to nobody")
+	}
+}
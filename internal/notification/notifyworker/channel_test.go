@@ -0,0 +1,99 @@
+package notifyworker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/txn2/mcp-data-platform/internal/notification/notifyrender"
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// fakeChannels opens a fixed set of channels by ID.
+type fakeChannels struct {
+	byID map[int64]notification.Channel
+}
+
+func (f *fakeChannels) Get(_ context.Context, id int64) (*notification.Channel, error) {
+	c, ok := f.byID[id]
+	if !ok {
+		return nil, notification.ErrChannelNotFound
+	}
+	return &c, nil
+}
+
+func (*fakeChannels) List(context.Context, string) ([]notification.Channel, error) { return nil, nil }
+func (*fakeChannels) Create(context.Context, notification.Channel) (*notification.Channel, error) {
+	return nil, nil
+}
+func (*fakeChannels) Delete(context.Context, string, string) error          { return nil }
+func (*fakeChannels) Lookup(context.Context, string, string) (int64, error) { return 0, nil }
+
+// fakePoster captures posts or fails on demand.
+type fakePoster struct {
+	mu     sync.Mutex
+	posted []notifyrender.Message
+	err    error
+}
+
+func (f *fakePoster) Post(_ context.Context, _ notification.Channel, m notifyrender.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.posted = append(f.posted, m)
+	return nil
+}
+
+func channelWorker(t *testing.T, queue notification.QueueStore, poster *fakePoster) *Worker {
+	t.Helper()
+	w := testWorker(t, queue, &fakeSettingsStore{}, &fakeSender{})
+	w.cfg.Channels = &fakeChannels{byID: map[int64]notification.Channel{7: {ID: 7, Name: "team", Kind: notification.ChannelSlack}}}
+	w.cfg.Poster = poster
+	return w
+}
+
+// TestWorker_Drain_PostsChannelRowsWithoutSMTP delivers a channel row on a
+// deployment with no mail server, and leaves the email row behind it queued.
+func TestWorker_Drain_PostsChannelRowsWithoutSMTP(t *testing.T) {
+	queue := &fakeQueueStore{immediate: [][]notification.Notification{
+		{{ID: 1, Recipient: "a@b.io", ChannelID: 7, Attempts: 1, Payload: notification.Payload{Kind: notification.KindScriptRun, ItemTitle: "daily"}}},
+		{{ID: 2, Recipient: "a@b.io", Attempts: 1, Payload: notification.Payload{Kind: notification.KindScriptRun, ItemTitle: "daily"}}},
+	}}
+	poster := &fakePoster{}
+	w := channelWorker(t, queue, poster)
+
+	w.drain()
+
+	if len(poster.posted) != 1 || poster.posted[0].ID != 1 {
+		t.Fatalf("expected row 1 posted, got %+v", poster.posted)
+	}
+	if len(queue.sent) != 1 || queue.sent[0][0] != 1 {
+		t.Errorf("row 1 not marked sent: %+v", queue.sent)
+	}
+	if len(queue.immediate) != 1 {
+		t.Errorf("the email row must stay queued without SMTP: %+v", queue.immediate)
+	}
+}
+
+// TestWorker_Deliver_ChannelRetrySemantics holds a channel row to the email
+// budget: a refused post retries until the budget is spent, and a deleted
+// channel fails at once.
+func TestWorker_Deliver_ChannelRetrySemantics(t *testing.T) {
+	queue := &fakeQueueStore{}
+	w := channelWorker(t, queue, &fakePoster{err: errors.New("503 from receiver")})
+	ctx := context.Background()
+
+	w.deliver(ctx, nil, []notification.Notification{{ID: 3, ChannelID: 7, Attempts: 1}})
+	w.deliver(ctx, nil, []notification.Notification{{ID: 4, ChannelID: 7, Attempts: DefaultMaxAttempts}})
+	w.deliver(ctx, nil, []notification.Notification{{ID: 5, ChannelID: 99, Attempts: 1}})
+
+	if len(queue.retried) != 1 || queue.retried[0][0] != 3 {
+		t.Errorf("expected row 3 retried: %+v", queue.retried)
+	}
+	if len(queue.failed) != 2 || queue.failed[0][0] != 4 || queue.failed[1][0] != 5 {
+		t.Errorf("expected rows 4 and 5 failed: %+v", queue.failed)
+	}
+}
@@ -27,12 +27,17 @@ type fakeQueueStore struct {
 
 func (*fakeQueueStore) Enqueue(context.Context, notification.Notification) error { return nil }
 
-func (f *fakeQueueStore) ClaimImmediate(_ context.Context, _ time.Duration) (*notification.Notification, error) {
+func (f *fakeQueueStore) ClaimImmediate(_ context.Context, _ time.Duration, includeEmail bool) (*notification.Notification, error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	if f.claimErr != nil {
 		return nil, f.claimErr
 	}
+	// Without email the next row is claimable only if it is a channel row;
+	// an email row stays queued, as the real store leaves it.
+	if !includeEmail && len(f.immediate) > 0 && f.immediate[0] != nil && f.immediate[0][0].ChannelID == 0 {
+		return nil, notification.ErrNoWork
+	}
 	if len(f.immediate) == 0 || f.immediate[0] == nil {
 		if len(f.immediate) > 0 {
 			f.immediate = f.immediate[1:]
@@ -1,10 +1,11 @@
 // Package notifyworker drains the notification queue: it claims due rows under
-// a lease, renders them, delivers them over SMTP, and resolves each batch to
-// sent, retried, or failed.
+// a lease, renders them, delivers them over SMTP or to the recipient's chat
+// and webhook channels, and resolves each batch to sent, retried, or failed.
 //
 // It owns the delivery policy — the deliverability gate, the retry budget and
 // its backoff, and the retention purge that bounds the table — and holds the
-// rendering and transport layers behind the two collaborators in Config.
+// rendering and transport layers behind the collaborators in Config. A channel
+// row is held to the same lease, budget, and backoff as an email.
 package notifyworker
 
 import (
@@ -16,6 +17,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/txn2/mcp-data-platform/internal/notification/notifychannel"
 	"github.com/txn2/mcp-data-platform/internal/notification/notifyrender"
 	"github.com/txn2/mcp-data-platform/internal/notification/notifysend"
 	"github.com/txn2/mcp-data-platform/pkg/notification"
@@ -56,6 +58,10 @@ type Config struct {
 	Settings smtp.SettingsStore
 	Renderer *notifyrender.Renderer
 	Sender   notifysend.Sender
+	// Channels opens the channel a row is addressed to and Poster delivers
+	// to it. Both nil leaves channel rows pending, as rows wait for SMTP.
+	Channels notification.ChannelStore
+	Poster   notifychannel.Poster
 	// PollEvery, Lease, and MaxAttempts default to the package constants
 	// when zero.
 	PollEvery   time.Duration
@@ -64,10 +70,11 @@ type Config struct {
 }
 
 // Worker drains the notification queue: it claims due rows, renders branded
-// emails, and delivers them over SMTP. It follows the indexjobs worker shape
-// (poll ticker + LISTEN/NOTIFY wakeup, lease-based claiming, retry with
-// exponential backoff). When SMTP is unconfigured or disabled the worker
-// leaves rows pending without burning delivery attempts.
+// emails or channel messages, and delivers them over SMTP or to the channel.
+// It follows the indexjobs worker shape (poll ticker + LISTEN/NOTIFY wakeup,
+// lease-based claiming, retry with exponential backoff). When SMTP is
+// unconfigured or disabled the worker leaves email rows pending without
+// burning delivery attempts, and still delivers channel rows.
 type Worker struct {
 	cfg      Config
 	wakeup   chan struct{}
@@ -146,7 +153,7 @@ func (w *Worker) drain() {
 	// bounded even on deployments that never configure SMTP.
 	w.maybePurge(ctx)
 	settings := w.deliverableSettings(ctx)
-	if settings == nil {
+	if settings == nil && !w.channelsEnabled() {
 		return
 	}
 	for {
@@ -177,6 +184,11 @@ func (w *Worker) maybePurge(ctx context.Context) {
 	}
 }
 
+// channelsEnabled reports whether the worker can deliver channel rows.
+func (w *Worker) channelsEnabled() bool {
+	return w.cfg.Channels != nil && w.cfg.Poster != nil
+}
+
 // deliverableSettings returns SMTP settings ready for sending, or nil when
 // SMTP is unconfigured or disabled (rows stay pending, no attempts burned).
 func (w *Worker) deliverableSettings(ctx context.Context) *smtp.Settings {
@@ -195,9 +207,10 @@ func (w *Worker) deliverableSettings(ctx context.Context) *smtp.Settings {
 }
 
 // processNext claims and delivers one unit of work (one immediate row or one
-// recipient's digest batch). It reports whether more work may remain.
+// recipient's digest batch). Nil settings claims channel rows only. It reports
+// whether more work may remain.
 func (w *Worker) processNext(ctx context.Context, settings *smtp.Settings) bool {
-	batch, err := w.claimNext(ctx)
+	batch, err := w.claimNext(ctx, settings != nil)
 	if errors.Is(err, notification.ErrNoWork) {
 		return false
 	}
@@ -209,16 +222,21 @@ func (w *Worker) processNext(ctx context.Context, settings *smtp.Settings) bool
 	return true
 }
 
-// claimNext prefers immediate rows, then falls back to digest batches.
-// notification.ErrNoWork wraps through so processNext can match it with errors.Is.
-func (w *Worker) claimNext(ctx context.Context) ([]notification.Notification, error) {
-	n, err := w.cfg.Queue.ClaimImmediate(ctx, w.cfg.Lease)
+// claimNext prefers immediate rows, then falls back to digest batches. Without
+// email it claims only immediate channel rows, and without channels it leaves
+// them to wait like email does without SMTP. notification.ErrNoWork wraps
+// through so processNext can match it with errors.Is.
+func (w *Worker) claimNext(ctx context.Context, email bool) ([]notification.Notification, error) {
+	n, err := w.cfg.Queue.ClaimImmediate(ctx, w.cfg.Lease, email)
 	if err == nil {
 		return []notification.Notification{*n}, nil
 	}
 	if !errors.Is(err, notification.ErrNoWork) {
 		return nil, fmt.Errorf("claiming immediate notification: %w", err)
 	}
+	if !email {
+		return nil, err //nolint:wrapcheck // ErrNoWork is matched, not reported
+	}
 	batch, err := w.cfg.Queue.ClaimDigest(ctx, w.cfg.Lease)
 	if err != nil {
 		return nil, fmt.Errorf("claiming digest batch: %w", err)
@@ -231,6 +249,10 @@ func (w *Worker) deliver(ctx context.Context, settings *smtp.Settings, batch []n
 	if len(batch) == 0 {
 		return
 	}
+	if batch[0].ChannelID != 0 {
+		w.deliverToChannel(ctx, batch[0])
+		return
+	}
 	email, err := w.cfg.Renderer.Render(batch)
 	if err != nil {
 		// A render failure is deterministic; retrying cannot fix it.
@@ -248,6 +270,31 @@ func (w *Worker) deliver(ctx context.Context, settings *smtp.Settings, batch []n
 	slog.Info("notification: sent", "recipient", batch[0].Recipient, "count", len(batch))
 }
 
+// deliverToChannel renders and posts one channel row, then resolves it. A row
+// whose channel is gone, or that claims while channels are not wired, fails
+// at once: no retry can bring either back.
+func (w *Worker) deliverToChannel(ctx context.Context, n notification.Notification) {
+	batch := []notification.Notification{n}
+	if !w.channelsEnabled() {
+		w.resolve(ctx, batch, errors.New("channel delivery is not configured"), true)
+		return
+	}
+	ch, err := w.cfg.Channels.Get(ctx, n.ChannelID)
+	if err != nil {
+		w.resolve(ctx, batch, err, errors.Is(err, notification.ErrChannelNotFound))
+		return
+	}
+	if err := w.cfg.Poster.Post(ctx, *ch, w.cfg.Renderer.RenderMessage(n)); err != nil {
+		w.resolve(ctx, batch, err, false)
+		return
+	}
+	if err := w.cfg.Queue.MarkSent(ctx, ids(batch)); err != nil {
+		slog.Error("notification: marking sent failed", logKeyError, err)
+		return
+	}
+	slog.Info("notification: posted", "recipient", n.Recipient, "channel", ch.Name, "kind", ch.Kind)
+}
+
 // resolve routes a failed batch to retry or permanent failure.
 func (w *Worker) resolve(ctx context.Context, batch []notification.Notification, sendErr error, terminal bool) {
 	attempts := maxAttempts(batch)
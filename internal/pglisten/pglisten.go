@@ -32,6 +32,16 @@ type Notifier interface {
 	Notify()
 }
 
+// PayloadNotifier is a Notifier that can use what a NOTIFY said. A listener
+// hands such a notifier each notification's payload instead of calling Notify,
+// so a producer that names what changed wakes only what that concerns. Notify
+// is still called after a reconnect, when the payloads missed meanwhile are
+// gone and anything may have changed.
+type PayloadNotifier interface {
+	Notifier
+	NotifyPayload(payload string)
+}
+
 // logKeyChannel is the structured-logging key for the watched channel.
 const logKeyChannel = "channel"
 
@@ -102,17 +112,24 @@ func (l *Listener) consume(ch <-chan *pq.Notification) {
 		select {
 		case <-l.stopCh:
 			return
-		case <-ch:
+		case n := <-ch:
 			// A nil notification signals a reconnect ("you may have missed
 			// events"); either way wake every notifier to re-query.
-			l.broadcast()
+			l.broadcast(n)
 		}
 	}
 }
 
-func (l *Listener) broadcast() {
-	for _, n := range l.notifiers {
-		n.Notify()
+// broadcast wakes every notifier for one notification, handing its payload to
+// those that use one. A nil notification is a reconnect, which has no payload
+// and wakes everything.
+func (l *Listener) broadcast(n *pq.Notification) {
+	for _, notifier := range l.notifiers {
+		if pn, ok := notifier.(PayloadNotifier); ok && n != nil {
+			pn.NotifyPayload(n.Extra)
+			continue
+		}
+		notifier.Notify()
 	}
 }
 
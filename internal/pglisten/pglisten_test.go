@@ -23,7 +23,7 @@ func TestPGListen_Broadcast(t *testing.T) {
 	n2 := &countingNotifier{ch: make(chan struct{}, 1)}
 	l := New("dsn-unused", "probe-channel", n1, n2)
 
-	l.broadcast()
+	l.broadcast(&pq.Notification{Channel: "probe-channel"})
 
 	for i, n := range []*countingNotifier{n1, n2} {
 		select {
@@ -83,3 +83,29 @@ func TestPGListen_ConsumeWakesOnEveryNotification(t *testing.T) {
 		t.Fatal("consume did not return after Stop")
 	}
 }
+
+// payloadNotifier records what it was told.
+type payloadNotifier struct {
+	payloads []string
+	woken    int
+}
+
+func (p *payloadNotifier) Notify()                      { p.woken++ }
+func (p *payloadNotifier) NotifyPayload(payload string) { p.payloads = append(p.payloads, payload) }
+
+// TestPGListen_BroadcastHandsOverThePayload gives a payload notifier what the
+// NOTIFY said, and wakes it outright on a reconnect, when there is nothing to
+// hand over.
+func TestPGListen_BroadcastHandsOverThePayload(t *testing.T) {
+	p := &payloadNotifier{}
+	l := New("dsn-unused", "probe-channel", p)
+
+	l.broadcast(&pq.Notification{Channel: "probe-channel", Extra: "a@b.io"})
+	if len(p.payloads) != 1 || p.payloads[0] != "a@b.io" || p.woken != 0 {
+		t.Fatalf("payloads = %v, woken = %d; want the payload and no blanket wakeup", p.payloads, p.woken)
+	}
+	l.broadcast(nil)
+	if p.woken != 1 || len(p.payloads) != 1 {
+		t.Fatalf("payloads = %v, woken = %d; a reconnect must wake without a payload", p.payloads, p.woken)
+	}
+}
@@ -0,0 +1,387 @@
+// Package approvalgate puts a person between an agent and the write
+// operations an operator marks for review. A tools/call that matches an
+// approval rule (a non-GET operation on an API or gRPC gateway connection, or
+// an MCP gateway tool the upstream marks destructive) is not executed: it is
+// parked as a pending approval holding its full request, the rule's approvers
+// are emailed, and the agent is told the approval id. An approver decides
+// through the portal REST surface; the agent polls approval_status and, once
+// approved, repeats the identical call, which then executes exactly once.
+//
+// Replaying the call rather than executing it on the approver's click keeps
+// the execution where every other call happens: inside the agent's own
+// request, under its own identity, through the full middleware chain, so the
+// audit row, the persona checks, and the route policy all apply to the call
+// that actually reaches the upstream. A call is matched to its approval by a
+// fingerprint of caller, tool, and canonical arguments, so an approval covers
+// exactly the request the approver read and nothing else.
+//
+// Like toolratelimit, the seam is built inside the middleware-chain
+// registration with its lifetime on the lifecycle, so the platform facade
+// gains no field or method for it. It needs a database: approvals outlive a
+// replica and are decided on whichever one the approver reaches.
+package approvalgate
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/txn2/mcp-data-platform/pkg/middleware"
+)
+
+const (
+	// methodToolsCall is the MCP method the gate inspects. Everything else
+	// passes through untouched.
+	methodToolsCall = "tools/call"
+
+	// Error codes an agent branches on. All share one category: none is
+	// a fault, each is a step in a workflow the agent is expected to follow.
+	codeApprovalRequired    = "approval_required"
+	codeApprovalRejected    = "approval_rejected"
+	codeApprovalUnavailable = "approval_unavailable"
+	codeApprovalUnresolved  = "approval_unresolved"
+	categoryApproval        = "approval"
+
+	// DefaultTTL is how long a request waits for a decision, and how long an
+	// approval stays usable once granted, when the config names no ttl.
+	DefaultTTL = 24 * time.Hour
+)
+
+// Config is the approvals section of the platform config. Defined here and
+// aliased in pkg/platform so operator YAML addresses it unchanged.
+type Config struct {
+	// Enabled activates the gate. Off by default: with no rules nothing would
+	// be gated anyway, and a deployment that has not thought about approvals
+	// should not grow a tool for them.
+	Enabled bool `yaml:"enabled"`
+
+	// TTL bounds both windows an approval has: a pending request expires if
+	// nobody decides it in time, and an approved one expires if the agent
+	// does not repeat the call in time. Zero means DefaultTTL.
+	TTL time.Duration `yaml:"ttl"`
+
+	// Rules select the calls that need approval and name who may grant it.
+	// A call matching several rules is governed by the first.
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule selects calls on matching connections and names their approvers.
+type Rule struct {
+	// Name labels the rule in approval records, emails, and audit events.
+	// Defaults to the connection glob.
+	Name string `yaml:"name"`
+
+	// Connection is a glob (filepath.Match semantics) matched against the
+	// connection the call targets. Required.
+	Connection string `yaml:"connection"`
+
+	// Methods are HTTP method globs for API gateway calls; gRPC calls present
+	// as POST. Empty means every method except GET, HEAD, and OPTIONS.
+	Methods []string `yaml:"methods"`
+
+	// Paths are path globs for API gateway calls, matched against the
+	// resolved path ("/pkg.Service/Method" for gRPC). Empty means any path.
+	Paths []string `yaml:"paths"`
+
+	// Tools are globs matched against MCP gateway tool names
+	// ("crm__delete_contact"). They apply to MCP gateway connections only.
+	Tools []string `yaml:"tools"`
+
+	// Destructive gates every MCP gateway tool on the connection that the
+	// upstream annotates with destructiveHint: true.
+	Destructive bool `yaml:"destructive"`
+
+	// Approvers names who may decide a matching request.
+	Approvers Approvers `yaml:"approvers"`
+}
+
+// Approvers names the people who may decide a request. Admins may always
+// decide; nobody may decide their own request.
+type Approvers struct {
+	// Personas whose members may decide.
+	Personas []string `yaml:"personas" json:"personas,omitempty"`
+	// Users are email addresses that may decide. Each is also emailed when a
+	// request is parked.
+	Users []string `yaml:"users" json:"users,omitempty"`
+	// Notify are extra addresses emailed when a request is parked, typically
+	// the mailing list of an approving persona. Listing an address here does
+	// not let it decide.
+	Notify []string `yaml:"notify" json:"notify,omitempty"`
+}
+
+// EffectiveTTL returns TTL, or DefaultTTL when unset.
+func (c Config) EffectiveTTL() time.Duration {
+	if c.TTL <= 0 {
+		return DefaultTTL
+	}
+	return c.TTL
+}
+
+// Errors validates the section and returns one message per problem, in the
+// shape platform.Config.Validate collects.
+func (c Config) Errors() []string {
+	if !c.Enabled {
+		return nil
+	}
+	var errs []string
+	for i, r := range c.Rules {
+		prefix := fmt.Sprintf("approvals.rules[%d]", i)
+		if r.Connection == "" {
+			errs = append(errs, prefix+".connection is required")
+		}
+		if len(r.Approvers.Personas) == 0 && len(r.Approvers.Users) == 0 {
+			errs = append(errs, prefix+".approvers needs at least one persona or user")
+		}
+		for _, glob := range append(append(append([]string{r.Connection}, r.Methods...), r.Paths...), r.Tools...) {
+			if !validGlob(glob) {
+				errs = append(errs, fmt.Sprintf("%s: invalid glob %q", prefix, glob))
+			}
+		}
+	}
+	return errs
+}
+
+// Deps carries what the gate needs from the platform.
+type Deps struct {
+	Config Config
+	// DB holds the approvals. Without one the gate is still built when
+	// enabled, and refuses every matching call rather than running it
+	// unreviewed.
+	DB *sql.DB
+	// Inspector answers the questions about a call that only the gateway
+	// toolkits can: where an operation_id points, and which MCP tools are
+	// destructive. Nil treats every call as addressed by method and path and
+	// no MCP tool as destructive.
+	Inspector Inspector
+	// Audit records requests. Optional.
+	Audit middleware.AuditLogger
+	// NotificationsDisabled mirrors notifications.enabled: false.
+	NotificationsDisabled bool
+	// DigestHourUTC schedules an approver's email when their mode is daily.
+	DigestHourUTC int
+	// BaseURL is the portal's public base URL, used for links in emails.
+	BaseURL string
+}
+
+// Handle is the approval gate: the middleware, the approval_status tool,
+// and the store and notifier they share.
+type Handle struct {
+	cfg       Config
+	store     Store
+	inspector Inspector
+	audit     middleware.AuditLogger
+	notifier  Notifier
+	closer    func()
+	baseURL   string
+	now       func() time.Time
+}
+
+// New builds the gate, or returns nil when approvals are disabled.
+func New(deps Deps) *Handle {
+	if !deps.Config.Enabled {
+		return nil
+	}
+	h := &Handle{
+		cfg:       deps.Config,
+		inspector: deps.Inspector,
+		audit:     deps.Audit,
+		baseURL:   deps.BaseURL,
+		now:       time.Now,
+	}
+	if deps.DB != nil {
+		h.store = NewPostgresStore(deps.DB)
+		h.notifier, h.closer = newNotifier(deps.DB, deps.NotificationsDisabled, deps.DigestHourUTC)
+	} else {
+		slog.Warn("approvals: enabled without a database; calls that need approval will be refused")
+	}
+	return h
+}
+
+// Close releases the notification enqueuer the handle built. Nil-safe.
+func (h *Handle) Close() {
+	if h != nil && h.closer != nil {
+		h.closer()
+	}
+}
+
+// Middleware returns the receiving middleware that parks matching calls.
+//
+// It must be INNER to MCPToolCallMiddleware, which writes the PlatformContext
+// it reads and strips the platform-injected arguments it must not fingerprint,
+// and OUTER to the observers: a parked call never executed, so it is recorded
+// as a tool_approval event rather than as a tool call.
+func (h *Handle) Middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != methodToolsCall {
+				return next(ctx, method, req)
+			}
+			pc := middleware.GetPlatformContext(ctx)
+			if pc == nil {
+				return next(ctx, method, req)
+			}
+			args := callArguments(req)
+			c, rule := h.match(ctx, pc, args)
+			if rule == nil {
+				return next(ctx, method, req)
+			}
+			if c.unresolved {
+				return unresolvedError(pc.ToolName), nil
+			}
+			if res := h.check(ctx, pc, c, rule); res != nil {
+				return res, nil
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// check decides a matching call: nil lets it through (consuming its
+// approval), anything else is the result the agent receives instead.
+func (h *Handle) check(ctx context.Context, pc *middleware.PlatformContext, c call, rule *Rule) mcp.Result {
+	if h.store == nil {
+		return unavailableError(pc.ToolName, "approvals need a database and this deployment has none")
+	}
+	if pc.UserID == "" {
+		// The fingerprint keys on the caller; without one, every anonymous
+		// caller would share, and consume, each other's approvals.
+		return unavailableError(pc.ToolName, "the caller has no user identity to tie an approval to")
+	}
+	now := h.now()
+	fp := fingerprint(pc, c.args)
+	open, err := h.store.Open(ctx, fp, now)
+	if errors.Is(err, ErrNotFound) {
+		return h.park(ctx, pc, c, rule, fp, now)
+	}
+	if err != nil {
+		slog.Error("approvals: reading open approval failed", "tool", pc.ToolName, "error", err)
+		return unavailableError(pc.ToolName, "the approval store could not be read")
+	}
+	switch open.Status {
+	case StatusApproved:
+		consumed, err := h.store.Consume(ctx, open.ID, now)
+		if err != nil {
+			slog.Error("approvals: consuming approval failed", "approval_id", open.ID, "error", err)
+			return unavailableError(pc.ToolName, "the approval could not be recorded as used")
+		}
+		if !consumed {
+			// A concurrent identical call used it first; this one needs its own.
+			return h.park(ctx, pc, c, rule, fp, now)
+		}
+		slog.Info("approvals: executing approved call", "approval_id", open.ID, "tool", pc.ToolName)
+		return nil
+	case StatusRejected:
+		if _, err := h.store.Consume(ctx, open.ID, now); err != nil {
+			slog.Warn("approvals: consuming rejection failed", "approval_id", open.ID, "error", err)
+		}
+		return rejectedError(open)
+	default:
+		return requiredError(open, false)
+	}
+}
+
+// park records a new pending approval, tells the approvers, and returns the
+// APPROVAL_REQUIRED result. When an identical request is already pending the
+// store hands that one back and nobody is emailed twice.
+func (h *Handle) park(ctx context.Context, pc *middleware.PlatformContext, c call, rule *Rule, fp string, now time.Time) mcp.Result {
+	a := Approval{
+		ID:               newID(),
+		Fingerprint:      fp,
+		Status:           StatusPending,
+		Rule:             ruleName(rule),
+		ToolName:         pc.ToolName,
+		ToolkitKind:      pc.ToolkitKind,
+		Connection:       pc.Connection,
+		Method:           c.method,
+		Path:             c.path,
+		Arguments:        c.args,
+		RequestedBy:      pc.UserID,
+		RequestedByEmail: pc.UserEmail,
+		Persona:          pc.PersonaName,
+		Approvers:        rule.Approvers,
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(h.cfg.EffectiveTTL()),
+	}
+	stored, created, err := h.store.Create(ctx, a, now)
+	if err != nil {
+		slog.Error("approvals: parking call failed", "tool", pc.ToolName, "error", err)
+		return unavailableError(pc.ToolName, "the request could not be recorded for approval")
+	}
+	if created {
+		recordAudit(ctx, h.audit, stored, actionRequested, auditActor{
+			UserID: pc.UserID, Email: pc.UserEmail, Persona: pc.PersonaName, RequestID: pc.RequestID,
+			SessionID: pc.SessionID,
+		})
+		notifyApprovers(ctx, h.notifier, stored, h.baseURL)
+	}
+	return requiredError(stored, created)
+}
+
+// callArguments returns a tools/call request's raw arguments, or an empty
+// object when it carries none.
+func callArguments(req mcp.Request) json.RawMessage {
+	if req == nil {
+		return json.RawMessage(`{}`)
+	}
+	params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+	if !ok || params == nil || len(params.Arguments) == 0 {
+		return json.RawMessage(`{}`)
+	}
+	return params.Arguments
+}
+
+// requiredError is the result of a parked call: a pending approval the agent
+// polls with approval_status before repeating the call unchanged.
+func requiredError(a *Approval, created bool) mcp.Result {
+	lead := "is awaiting approval"
+	if created {
+		lead = "needs approval and has been sent to its approvers"
+	}
+	msg := fmt.Sprintf(
+		"APPROVAL_REQUIRED: this %s call %s (approval_id %s, rule %q, expires %s). It was NOT executed.",
+		describe(a), lead, a.ID, a.Rule, a.ExpiresAt.UTC().Format(time.RFC3339),
+	)
+	hint := fmt.Sprintf(
+		"Call %s with approval_id %q (use wait_seconds to wait for a decision). Once it reports "+
+			"approved, repeat this exact call with identical arguments; it will then execute once. "+
+			"Tell the user the request is waiting for a person to approve it.",
+		ToolNameApprovalStatus, a.ID,
+	)
+	return middleware.BuildErrorResult(middleware.NewToolError(codeApprovalRequired, categoryApproval, msg, hint))
+}
+
+// rejectedError reports a rejection once; a repeat of the call after this
+// parks a fresh request.
+func rejectedError(a *Approval) mcp.Result {
+	msg := fmt.Sprintf("APPROVAL_REJECTED: %s rejected this %s call (approval_id %s).", a.DecidedBy, describe(a), a.ID)
+	if a.Reason != "" {
+		msg += " Reason: " + a.Reason
+	}
+	hint := "Do not retry the call unchanged. Report the rejection and its reason to the user; " +
+		"repeating the call starts a new approval request."
+	return middleware.BuildErrorResult(middleware.NewToolError(codeApprovalRejected, categoryApproval, msg, hint))
+}
+
+// unavailableError refuses a call that needs approval when the approval
+// could not be handled. Failing closed is the point of the gate.
+func unavailableError(tool, why string) mcp.Result {
+	msg := fmt.Sprintf("APPROVAL_UNAVAILABLE: %s needs approval, but %s. It was NOT executed.", tool, why)
+	hint := "This is a platform-side problem, not a rejection. Tell the user and try again later."
+	return middleware.BuildErrorResult(middleware.NewToolError(codeApprovalUnavailable, categoryApproval, msg, hint))
+}
+
+// unresolvedError refuses a gRPC call whose method the connection does not
+// declare: without its canonical path the gate cannot tell whether a rule
+// governs it.
+func unresolvedError(tool string) mcp.Result {
+	msg := fmt.Sprintf("APPROVAL_UNRESOLVED: %s names a method this connection does not declare, "+
+		"so whether it needs approval cannot be decided. It was NOT executed.", tool)
+	hint := "Use grpc_list_methods to find the method's canonical \"pkg.Service/Method\" name and call it by that name."
+	return middleware.BuildErrorResult(middleware.NewToolError(codeApprovalUnresolved, categoryApproval, msg, hint))
+}
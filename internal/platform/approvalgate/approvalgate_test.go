@@ -0,0 +1,391 @@
+package approvalgate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/pkg/middleware"
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+	apigatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway"
+)
+
+// memStore is an in-memory Store with the PostgreSQL store's semantics.
+type memStore struct {
+	mu   sync.Mutex
+	rows []*Approval
+}
+
+func (s *memStore) Create(_ context.Context, a Approval, now time.Time) (*Approval, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.rows {
+		if r.Fingerprint == a.Fingerprint && r.Status == StatusPending {
+			if now.Before(r.ExpiresAt) {
+				cp := *r
+				return &cp, false, nil
+			}
+			r.Status = StatusExpired
+		}
+	}
+	a.Status = StatusPending
+	s.rows = append(s.rows, &a)
+	cp := a
+	return &cp, true, nil
+}
+
+func (s *memStore) Open(_ context.Context, fp string, now time.Time) (*Approval, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.rows) - 1; i >= 0; i-- {
+		r := s.rows[i]
+		if r.Fingerprint == fp && r.ConsumedAt == nil && r.Status != StatusExpired && now.Before(r.ExpiresAt) {
+			cp := *r
+			return &cp, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *memStore) Get(_ context.Context, id string) (*Approval, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.rows {
+		if r.ID == id {
+			cp := *r
+			return &cp, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *memStore) List(_ context.Context, f Filter) ([]Approval, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []Approval{}
+	for i := len(s.rows) - 1; i >= 0; i-- {
+		r := *s.rows[i]
+		settled := r
+		settled.settle(f.Now)
+		if f.Status != "" && settled.Status != f.Status {
+			continue
+		}
+		if f.RequestedBy != "" && r.RequestedBy != f.RequestedBy {
+			continue
+		}
+		if f.DecidableBy != nil && !f.DecidableBy.CanDecide(&r) {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func (s *memStore) Decide(_ context.Context, id, status, decidedBy, reason string, now, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.rows {
+		if r.ID == id && r.Status == StatusPending && now.Before(r.ExpiresAt) {
+			r.Status, r.DecidedBy, r.Reason, r.DecidedAt, r.ExpiresAt = status, decidedBy, reason, &now, expiresAt
+			return nil
+		}
+	}
+	return ErrNotPending
+}
+
+func (s *memStore) Consume(_ context.Context, id string, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.rows {
+		if r.ID == id && r.ConsumedAt == nil && (r.Status == StatusApproved || r.Status == StatusRejected) {
+			r.ConsumedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// recordingNotifier captures what would have been emailed.
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent map[string][]notification.Payload
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, recipient, _ string, p notification.Payload) (bool, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.sent == nil {
+		n.sent = map[string][]notification.Payload{}
+	}
+	n.sent[recipient] = append(n.sent[recipient], p)
+	return true, nil
+}
+
+// recordingAudit captures audit events.
+type recordingAudit struct {
+	mu     sync.Mutex
+	events []middleware.AuditEvent
+}
+
+func (a *recordingAudit) Log(_ context.Context, e middleware.AuditEvent) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, e)
+	return nil
+}
+
+// ordersRule gates every write on the crm connection, decided by the
+// finance-leads persona or lead@example.com.
+var ordersRule = Rule{
+	Name:       "crm-writes",
+	Connection: "crm",
+	Approvers: Approvers{
+		Personas: []string{"finance-leads"},
+		Users:    []string{"lead@example.com"},
+		Notify:   []string{"finance-leads@example.com"},
+	},
+}
+
+// gateFixture is a gate over memory, with its collaborators exposed.
+type gateFixture struct {
+	h        *Handle
+	store    *memStore
+	notifier *recordingNotifier
+	audit    *recordingAudit
+	runs     int
+}
+
+func newFixture(rules ...Rule) *gateFixture {
+	f := &gateFixture{store: &memStore{}, notifier: &recordingNotifier{}, audit: &recordingAudit{}}
+	f.h = &Handle{
+		cfg:      Config{Enabled: true, Rules: rules},
+		store:    f.store,
+		audit:    f.audit,
+		notifier: f.notifier,
+		now:      time.Now,
+	}
+	return f
+}
+
+// call runs one tools/call through the gate and reports the result, or nil
+// when the call reached the handler.
+func (f *gateFixture) call(t *testing.T, pc *middleware.PlatformContext, args string) *mcp.CallToolResult {
+	t.Helper()
+	next := f.h.Middleware()(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		f.runs++
+		return nil, nil
+	})
+	ctx := middleware.WithPlatformContext(context.Background(), pc)
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: pc.ToolName, Arguments: json.RawMessage(args)}}
+	res, err := next(ctx, methodToolsCall, req)
+	require.NoError(t, err)
+	if res == nil {
+		return nil
+	}
+	cr, ok := res.(*mcp.CallToolResult)
+	require.True(t, ok)
+	return cr
+}
+
+// apiCall is the platform context of an api_invoke_endpoint call by jane.
+func apiCall(connection string) *middleware.PlatformContext {
+	pc := middleware.NewPlatformContext("req-1")
+	pc.UserID, pc.UserEmail, pc.PersonaName = "jane", "jane@example.com", "analyst"
+	pc.ToolName, pc.ToolkitKind, pc.ToolkitName, pc.Connection =
+		apigatewaykit.ToolInvokeEndpoint, apigatewaykit.Kind, "crm", connection
+	return pc
+}
+
+const createOrder = `{"method":"POST","path":"/v1/orders","body":{"sku":"A-1","qty":2}}`
+
+// resultText returns a tool result's text.
+func resultText(r *mcp.CallToolResult) string {
+	var b strings.Builder
+	for _, c := range r.Content {
+		if tc, ok := c.(*mcp.TextContent); ok {
+			b.WriteString(tc.Text)
+		}
+	}
+	return b.String()
+}
+
+// onlyApproval returns the single approval the store holds.
+func (f *gateFixture) onlyApproval(t *testing.T) *Approval {
+	t.Helper()
+	require.Len(t, f.store.rows, 1)
+	return f.store.rows[0]
+}
+
+func TestNew_DisabledBuildsNothing(t *testing.T) {
+	assert.Nil(t, New(Deps{Config: Config{Rules: []Rule{ordersRule}}}))
+	var h *Handle
+	h.Close()
+	h.RegisterTools(nil)
+}
+
+func TestMiddleware_ReadsPassThrough(t *testing.T) {
+	f := newFixture(ordersRule)
+	res := f.call(t, apiCall("crm"), `{"method":"GET","path":"/v1/orders"}`)
+	assert.Nil(t, res)
+	assert.Equal(t, 1, f.runs)
+	assert.Empty(t, f.store.rows)
+}
+
+func TestMiddleware_OtherConnectionsPassThrough(t *testing.T) {
+	f := newFixture(ordersRule)
+	assert.Nil(t, f.call(t, apiCall("billing"), createOrder))
+	assert.Equal(t, 1, f.runs)
+}
+
+// TestMiddleware_ApprovedCallRunsExactlyOnce walks the whole workflow: park,
+// repeat while pending, approve, repeat to run, and repeat again to park a
+// fresh request.
+func TestMiddleware_ApprovedCallRunsExactlyOnce(t *testing.T) {
+	f := newFixture(ordersRule)
+
+	res := f.call(t, apiCall("crm"), createOrder)
+	require.NotNil(t, res)
+	assert.True(t, res.IsError)
+	assert.Contains(t, resultText(res), "APPROVAL_REQUIRED")
+	assert.Contains(t, resultText(res), ToolNameApprovalStatus)
+	assert.Zero(t, f.runs, "a parked call must not reach the upstream")
+
+	a := f.onlyApproval(t)
+	assert.Equal(t, "POST", a.Method)
+	assert.Equal(t, "/v1/orders", a.Path)
+	assert.Equal(t, "crm-writes", a.Rule)
+	assert.JSONEq(t, createOrder, string(a.Arguments))
+	assert.Len(t, f.notifier.sent["lead@example.com"], 1)
+	assert.Len(t, f.notifier.sent["finance-leads@example.com"], 1)
+	require.Len(t, f.audit.events, 1)
+	assert.Equal(t, "tool_approval", f.audit.events[0].EventKind)
+
+	// The same call again, with its keys reordered, joins the pending request.
+	res = f.call(t, apiCall("crm"), `{"path":"/v1/orders","body":{"qty":2,"sku":"A-1"},"method":"POST"}`)
+	require.NotNil(t, res)
+	assert.Contains(t, resultText(res), a.ID)
+	assert.Len(t, f.store.rows, 1)
+	assert.Len(t, f.notifier.sent["lead@example.com"], 1, "approvers are emailed once per request")
+
+	d := NewDecider(DeciderDeps{Store: f.store, Notifier: f.notifier})
+	_, err := d.Decide(context.Background(), Caller{UserID: "lead", Email: "lead@example.com"}, a.ID, true, "")
+	require.NoError(t, err)
+
+	assert.Nil(t, f.call(t, apiCall("crm"), createOrder))
+	assert.Equal(t, 1, f.runs)
+
+	res = f.call(t, apiCall("crm"), createOrder)
+	require.NotNil(t, res, "an approval covers one execution")
+	assert.Equal(t, 1, f.runs)
+	assert.Len(t, f.store.rows, 2)
+}
+
+func TestMiddleware_ChangedArgumentsNeedTheirOwnApproval(t *testing.T) {
+	f := newFixture(ordersRule)
+	f.call(t, apiCall("crm"), createOrder)
+	a := f.onlyApproval(t)
+	d := NewDecider(DeciderDeps{Store: f.store})
+	_, err := d.Decide(context.Background(), Caller{Email: "lead@example.com"}, a.ID, true, "")
+	require.NoError(t, err)
+
+	res := f.call(t, apiCall("crm"), `{"method":"POST","path":"/v1/orders","body":{"sku":"A-1","qty":200}}`)
+	require.NotNil(t, res)
+	assert.Zero(t, f.runs)
+}
+
+func TestMiddleware_RejectionIsReportedOnce(t *testing.T) {
+	f := newFixture(ordersRule)
+	f.call(t, apiCall("crm"), createOrder)
+	a := f.onlyApproval(t)
+	d := NewDecider(DeciderDeps{Store: f.store, Notifier: f.notifier})
+	_, err := d.Decide(context.Background(), Caller{Email: "lead@example.com"}, a.ID, false, "wrong customer")
+	require.NoError(t, err)
+	require.Len(t, f.notifier.sent["jane@example.com"], 1)
+	assert.Equal(t, notification.KindApprovalRejected, f.notifier.sent["jane@example.com"][0].Kind)
+
+	res := f.call(t, apiCall("crm"), createOrder)
+	require.NotNil(t, res)
+	assert.Contains(t, resultText(res), "APPROVAL_REJECTED")
+	assert.Contains(t, resultText(res), "wrong customer")
+
+	res = f.call(t, apiCall("crm"), createOrder)
+	require.NotNil(t, res)
+	assert.Contains(t, resultText(res), "APPROVAL_REQUIRED", "a repeat after the rejection starts over")
+	assert.Zero(t, f.runs)
+}
+
+func TestMiddleware_ExpiredRequestParksAFreshOne(t *testing.T) {
+	f := newFixture(ordersRule)
+	start := time.Now()
+	f.h.now = func() time.Time { return start }
+	f.call(t, apiCall("crm"), createOrder)
+
+	f.h.now = func() time.Time { return start.Add(DefaultTTL + time.Minute) }
+	res := f.call(t, apiCall("crm"), createOrder)
+	require.NotNil(t, res)
+	require.Len(t, f.store.rows, 2)
+	assert.Equal(t, StatusExpired, f.store.rows[0].Status)
+	assert.Equal(t, StatusPending, f.store.rows[1].Status)
+}
+
+func TestMiddleware_FailsClosedWithoutAStore(t *testing.T) {
+	f := newFixture(ordersRule)
+	f.h.store = nil
+	res := f.call(t, apiCall("crm"), createOrder)
+	require.NotNil(t, res)
+	assert.Contains(t, resultText(res), "APPROVAL_UNAVAILABLE")
+	assert.Zero(t, f.runs)
+}
+
+func TestMiddleware_FailsClosedWithoutAUserIdentity(t *testing.T) {
+	f := newFixture(ordersRule)
+	pc := apiCall("crm")
+	pc.UserID = ""
+	res := f.call(t, pc, createOrder)
+	require.NotNil(t, res)
+	assert.Contains(t, resultText(res), "APPROVAL_UNAVAILABLE")
+	assert.Zero(t, f.runs)
+	assert.Empty(t, f.store.rows, "an anonymous call must not park a request others could approve or reuse")
+}
+
+// failingStore fails every read.
+type failingStore struct{ memStore }
+
+func (*failingStore) Open(context.Context, string, time.Time) (*Approval, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestMiddleware_FailsClosedWhenTheStoreFails(t *testing.T) {
+	f := newFixture(ordersRule)
+	f.h.store = &failingStore{}
+	res := f.call(t, apiCall("crm"), createOrder)
+	require.NotNil(t, res)
+	assert.Contains(t, resultText(res), "APPROVAL_UNAVAILABLE")
+	assert.Zero(t, f.runs)
+}
+
+func TestConfigErrors(t *testing.T) {
+	assert.Empty(t, Config{Rules: []Rule{{}}}.Errors(), "a disabled section is not validated")
+	errs := Config{Enabled: true, Rules: []Rule{
+		ordersRule,
+		{Approvers: Approvers{Notify: []string{"x@example.com"}}},
+		{Connection: "crm", Paths: []string{"[bad"}, Approvers: Approvers{Users: []string{"a@example.com"}}},
+	}}.Errors()
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[0], "approvals.rules[1].connection")
+	assert.Contains(t, errs[1], "approvals.rules[1].approvers")
+	assert.Contains(t, errs[2], `invalid glob "[bad"`)
+}
+
+func TestEffectiveTTL(t *testing.T) {
+	assert.Equal(t, DefaultTTL, Config{}.EffectiveTTL())
+	assert.Equal(t, time.Hour, Config{TTL: time.Hour}.EffectiveTTL())
+}
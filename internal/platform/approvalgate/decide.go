@@ -0,0 +1,243 @@
+package approvalgate
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/pkg/audit"
+	"github.com/txn2/mcp-data-platform/pkg/middleware"
+)
+
+// Audit actions, carried in the tool_approval event's parameters.
+const (
+	actionRequested = "requested"
+	actionApproved  = "approved"
+	actionRejected  = "rejected"
+)
+
+// List scopes.
+const (
+	// ScopeDecide lists the requests the caller may decide.
+	ScopeDecide = "decide"
+	// ScopeMine lists the caller's own requests.
+	ScopeMine = "mine"
+)
+
+var (
+	// ErrNotApprover is returned when the caller may not decide a request.
+	ErrNotApprover = errors.New("you are not an approver for this request")
+	// ErrOwnRequest is returned when the caller tries to decide their own
+	// request. An approval is a second person's judgment; nobody, admins
+	// included, supplies it for themselves.
+	ErrOwnRequest = errors.New("you cannot decide your own request")
+)
+
+// Caller is the person reading or deciding approvals.
+type Caller struct {
+	UserID string
+	Email  string
+	// Personas are the personas the caller's roles admit them to.
+	Personas []string
+	IsAdmin  bool
+}
+
+// DeciderDeps carries what the decision surface needs.
+type DeciderDeps struct {
+	Store Store
+	// Audit records decisions. Optional.
+	Audit middleware.AuditLogger
+	// Notifier tells the requester how their request was decided. Optional.
+	Notifier Notifier
+	// TTL is how long an approval stays usable once granted; zero means
+	// DefaultTTL.
+	TTL     time.Duration
+	BaseURL string
+}
+
+// Decider is the approver's side of the workflow: listing, reading, and
+// deciding requests. The portal REST surface serves it.
+type Decider struct {
+	store    Store
+	audit    middleware.AuditLogger
+	notifier Notifier
+	ttl      time.Duration
+	baseURL  string
+	now      func() time.Time
+}
+
+// NewDecider builds the decision surface.
+func NewDecider(deps DeciderDeps) *Decider {
+	ttl := deps.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Decider{
+		store:    deps.Store,
+		audit:    deps.Audit,
+		notifier: deps.Notifier,
+		ttl:      ttl,
+		baseURL:  deps.BaseURL,
+		now:      time.Now,
+	}
+}
+
+// isRequester reports whether c made the request.
+func (c Caller) isRequester(a *Approval) bool {
+	return c.UserID != "" && c.UserID == a.RequestedBy
+}
+
+// isApprover reports whether the request names c as an approver.
+func (c Caller) isApprover(a *Approval) bool {
+	for _, p := range a.Approvers.Personas {
+		if slices.Contains(c.Personas, p) {
+			return true
+		}
+	}
+	return c.Email != "" && slices.ContainsFunc(a.Approvers.Users, func(u string) bool { return strings.EqualFold(u, c.Email) })
+}
+
+// CanDecide reports whether c may decide a: an admin or a named approver,
+// and never the requester.
+func (c Caller) CanDecide(a *Approval) bool {
+	return !c.isRequester(a) && (c.IsAdmin || c.isApprover(a))
+}
+
+// CanView reports whether c may read a: its requester, an admin, or a named
+// approver.
+func (c Caller) CanView(a *Approval) bool {
+	return c.isRequester(a) || c.IsAdmin || c.isApprover(a)
+}
+
+// Get returns one approval the caller may read. One they may not is
+// reported as ErrNotFound, so ids do not leak who is asking for what.
+func (d *Decider) Get(ctx context.Context, c Caller, id string) (*Approval, error) {
+	a, err := d.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !c.CanView(a) {
+		return nil, ErrNotFound
+	}
+	a.settle(d.now())
+	return a, nil
+}
+
+// List returns the caller's own requests (ScopeMine) or the requests they
+// may decide (ScopeDecide), newest first, optionally narrowed to one status.
+func (d *Decider) List(ctx context.Context, c Caller, scope, status string) ([]Approval, error) {
+	if scope == ScopeMine && c.UserID == "" {
+		// An empty RequestedBy is no filter at all; a caller without an
+		// identity has no requests of their own.
+		return []Approval{}, nil
+	}
+	now := d.now()
+	f := Filter{Status: status, Now: now}
+	if scope == ScopeMine {
+		f.RequestedBy = c.UserID
+	} else {
+		f.DecidableBy = &c
+	}
+	rows, err := d.store.List(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Approval, 0, len(rows))
+	for i := range rows {
+		a := &rows[i]
+		a.settle(now)
+		if status != "" && a.Status != status {
+			continue
+		}
+		if scope != ScopeMine && !c.CanDecide(a) {
+			continue
+		}
+		out = append(out, *a)
+	}
+	return out, nil
+}
+
+// Decide approves or rejects a pending request. Approving opens a fresh
+// window of the configured TTL in which the agent's repeat of the call runs;
+// rejecting holds that long for the repeat to be told. The decision is
+// audited and the requester is emailed.
+func (d *Decider) Decide(ctx context.Context, c Caller, id string, approve bool, reason string) (*Approval, error) {
+	a, err := d.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.isRequester(a) {
+		return nil, ErrOwnRequest
+	}
+	if !c.CanDecide(a) {
+		if !c.CanView(a) {
+			return nil, ErrNotFound
+		}
+		return nil, ErrNotApprover
+	}
+	status, action := StatusRejected, actionRejected
+	if approve {
+		status, action = StatusApproved, actionApproved
+	}
+	decidedBy := c.Email
+	if decidedBy == "" {
+		decidedBy = c.UserID
+	}
+	now := d.now()
+	reason = strings.TrimSpace(reason)
+	if err := d.store.Decide(ctx, id, status, decidedBy, reason, now, now.Add(d.ttl)); err != nil {
+		return nil, err
+	}
+	a.Status, a.DecidedBy, a.Reason, a.DecidedAt, a.ExpiresAt = status, decidedBy, reason, &now, now.Add(d.ttl)
+	recordAudit(ctx, d.audit, a, action, auditActor{UserID: c.UserID, Email: c.Email})
+	notifyRequester(ctx, d.notifier, a, d.baseURL)
+	return a, nil
+}
+
+// auditActor is who performed an audited step: the requester's agent for a
+// request, the approver for a decision.
+type auditActor struct {
+	UserID    string
+	Email     string
+	Persona   string
+	RequestID string
+	SessionID string
+}
+
+// recordAudit writes one tool_approval event. A failure to record is logged
+// and never fails the step; audit is off the decision path by design, as it
+// is off the execution path.
+func recordAudit(ctx context.Context, logger middleware.AuditLogger, a *Approval, action string, actor auditActor) {
+	if logger == nil {
+		return
+	}
+	event := middleware.AuditEvent{
+		Timestamp:   time.Now().UTC(),
+		RequestID:   actor.RequestID,
+		SessionID:   actor.SessionID,
+		UserID:      actor.UserID,
+		UserEmail:   actor.Email,
+		Persona:     actor.Persona,
+		ToolName:    a.ToolName,
+		ToolkitKind: a.ToolkitKind,
+		Connection:  a.Connection,
+		Success:     true,
+		Authorized:  true,
+		EventKind:   string(audit.EventTypeToolApproval),
+		Parameters: map[string]any{
+			"approval_id":  a.ID,
+			"action":       action,
+			"rule":         a.Rule,
+			"method":       a.Method,
+			"path":         a.Path,
+			"requested_by": a.RequestedBy,
+			"reason":       a.Reason,
+		},
+	}
+	if err := logger.Log(context.WithoutCancel(ctx), event); err != nil {
+		slog.Warn("approvals: recording the audit event failed", "approval_id", a.ID, "error", err)
+	}
+}
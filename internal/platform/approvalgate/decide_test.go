@@ -0,0 +1,147 @@
+package approvalgate
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/pkg/middleware"
+)
+
+// parked returns a fixture holding one pending request by jane.
+func parked(t *testing.T) (*gateFixture, *Approval) {
+	t.Helper()
+	f := newFixture(ordersRule)
+	f.call(t, apiCall("crm"), createOrder)
+	return f, f.onlyApproval(t)
+}
+
+func TestCaller_CanDecide(t *testing.T) {
+	_, a := parked(t)
+	cases := []struct {
+		name   string
+		caller Caller
+		want   bool
+	}{
+		{"named user", Caller{UserID: "lead", Email: "LEAD@example.com"}, true},
+		{"persona member", Caller{UserID: "kim", Personas: []string{"finance-leads"}}, true},
+		{"admin", Caller{UserID: "root", IsAdmin: true}, true},
+		{"stranger", Caller{UserID: "bob", Email: "bob@example.com", Personas: []string{"analyst"}}, false},
+		{"requester, even an admin", Caller{UserID: "jane", IsAdmin: true, Personas: []string{"finance-leads"}}, false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, c.caller.CanDecide(a), c.name)
+	}
+	assert.True(t, Caller{UserID: "jane"}.CanView(a), "a requester reads their own request")
+	assert.False(t, Caller{UserID: "bob"}.CanView(a))
+}
+
+func TestDecide_Refusals(t *testing.T) {
+	f, a := parked(t)
+	d := NewDecider(DeciderDeps{Store: f.store})
+	ctx := context.Background()
+
+	_, err := d.Decide(ctx, Caller{UserID: "jane", IsAdmin: true}, a.ID, true, "")
+	assert.ErrorIs(t, err, ErrOwnRequest)
+
+	_, err = d.Decide(ctx, Caller{UserID: "bob"}, a.ID, true, "")
+	assert.ErrorIs(t, err, ErrNotFound, "a stranger cannot learn the request exists")
+
+	_, err = d.Decide(ctx, Caller{UserID: "missing"}, "apr_missing", true, "")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = d.Decide(ctx, Caller{UserID: "lead", Email: "lead@example.com"}, a.ID, false, "no")
+	require.NoError(t, err)
+	_, err = d.Decide(ctx, Caller{UserID: "root", IsAdmin: true}, a.ID, true, "")
+	assert.ErrorIs(t, err, ErrNotPending, "a request is decided once")
+}
+
+func TestDecide_AuditsAndOpensTheWindow(t *testing.T) {
+	f, a := parked(t)
+	audit := &recordingAudit{}
+	now := time.Now()
+	d := NewDecider(DeciderDeps{Store: f.store, Audit: audit, TTL: time.Hour})
+	d.now = func() time.Time { return now }
+
+	got, err := d.Decide(context.Background(), Caller{UserID: "lead", Email: "lead@example.com"}, a.ID, true, " ok ")
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, got.Status)
+	assert.Equal(t, "lead@example.com", got.DecidedBy)
+	assert.Equal(t, "ok", got.Reason)
+	assert.Equal(t, now.Add(time.Hour), got.ExpiresAt)
+	require.Len(t, audit.events, 1)
+	assert.Equal(t, actionApproved, audit.events[0].Parameters["action"])
+	assert.Equal(t, "lead", audit.events[0].UserID)
+}
+
+func TestList_Scopes(t *testing.T) {
+	f, a := parked(t)
+	d := NewDecider(DeciderDeps{Store: f.store})
+	ctx := context.Background()
+
+	mine, err := d.List(ctx, Caller{UserID: "jane"}, ScopeMine, "")
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+
+	anonymous, err := d.List(ctx, Caller{}, ScopeMine, "")
+	require.NoError(t, err)
+	assert.Empty(t, anonymous, "a caller without an identity owns no requests")
+
+	decide, err := d.List(ctx, Caller{UserID: "jane"}, ScopeDecide, StatusPending)
+	require.NoError(t, err)
+	assert.Empty(t, decide, "a requester is never offered their own request")
+
+	decide, err = d.List(ctx, Caller{UserID: "kim", Personas: []string{"finance-leads"}}, ScopeDecide, StatusPending)
+	require.NoError(t, err)
+	require.Len(t, decide, 1)
+	assert.Equal(t, a.ID, decide[0].ID)
+
+	d.now = func() time.Time { return a.ExpiresAt.Add(time.Second) }
+	expired, err := d.List(ctx, Caller{UserID: "jane"}, ScopeMine, StatusExpired)
+	require.NoError(t, err)
+	require.Len(t, expired, 1, "expiry is settled on read")
+	pending, err := d.List(ctx, Caller{UserID: "jane"}, ScopeMine, StatusPending)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+// statusOf calls approval_status as user and decodes the answer.
+func statusOf(t *testing.T, h *Handle, user, id string, wait int) (map[string]any, bool) {
+	t.Helper()
+	pc := middleware.NewPlatformContext("req-2")
+	pc.UserID = user
+	ctx := middleware.WithPlatformContext(context.Background(), pc)
+	res, _, err := h.handleStatus(ctx, approvalStatusInput{ApprovalID: id, WaitSeconds: wait})
+	require.NoError(t, err)
+	if res.IsError {
+		return nil, false
+	}
+	var out map[string]any
+	require.NoError(t, json.Unmarshal([]byte(resultText(res)), &out))
+	return out, true
+}
+
+func TestApprovalStatus(t *testing.T) {
+	f, a := parked(t)
+
+	out, ok := statusOf(t, f.h, "jane", a.ID, 0)
+	require.True(t, ok)
+	assert.Equal(t, StatusPending, out["status"])
+
+	_, ok = statusOf(t, f.h, "bob", a.ID, 0)
+	assert.False(t, ok, "only the requester's agent may poll a request")
+
+	d := NewDecider(DeciderDeps{Store: f.store})
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = d.Decide(context.Background(), Caller{UserID: "lead", Email: "lead@example.com"}, a.ID, true, "")
+	}()
+	out, ok = statusOf(t, f.h, "jane", a.ID, 5)
+	require.True(t, ok)
+	assert.Equal(t, StatusApproved, out["status"], "a wait returns as soon as the request is decided")
+	assert.Contains(t, out["next_step"], "Repeat the original call")
+}
@@ -0,0 +1,203 @@
+package approvalgate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/txn2/mcp-data-platform/pkg/middleware"
+	apigatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway"
+	gatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/gateway"
+	grpcgatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/grpcgateway"
+)
+
+// grpcMethod is how a gRPC call presents to method globs, matching the
+// persona api_routes convention.
+const grpcMethod = "POST"
+
+// readMethods are the HTTP methods a rule with no methods list leaves alone.
+var readMethods = map[string]bool{"GET": true, "HEAD": true, "OPTIONS": true}
+
+// Inspector answers what the gate cannot tell from a call's arguments.
+// internal/platform/gatewaywire implements it over the registered toolkits.
+type Inspector interface {
+	// Route resolves the method and path an api_invoke_endpoint call that
+	// names an operation_id addresses on connection. ok is false when the
+	// catalog does not resolve it, in which case the toolkit refuses the
+	// call too.
+	Route(ctx context.Context, connection string, in apigatewaykit.InvokeInput) (method, path string, ok bool)
+	// MethodPath resolves the method a grpc_invoke_method call names on
+	// connection to its canonical "/pkg.Service/Method" path. ok is false
+	// when the connection's descriptors do not declare it.
+	MethodPath(ctx context.Context, connection, method string) (path string, ok bool)
+	// Destructive reports whether the upstream behind a namespaced MCP
+	// gateway tool annotates it as destructive.
+	Destructive(tool string) bool
+}
+
+// call is what the gate knows about a tools/call after classifying it.
+type call struct {
+	method string
+	path   string
+	args   json.RawMessage
+	// unresolved is set when a rule may govern the call but the method it
+	// names could not be resolved to the path rules are written against.
+	unresolved bool
+}
+
+// match classifies a call and returns the first rule that governs it, or a
+// nil rule when the call runs without approval.
+func (h *Handle) match(ctx context.Context, pc *middleware.PlatformContext, args json.RawMessage) (call, *Rule) {
+	c := call{args: args}
+	switch {
+	case pc.ToolkitKind == apigatewaykit.Kind && pc.ToolName == apigatewaykit.ToolInvokeEndpoint:
+		return h.matchAPI(ctx, pc.Connection, c)
+	case pc.ToolkitKind == grpcgatewaykit.Kind && pc.ToolName == grpcgatewaykit.ToolInvokeMethod:
+		return h.matchGRPC(ctx, pc.Connection, c)
+	case pc.ToolkitKind == gatewaykit.Kind:
+		destructive := h.inspector != nil && h.inspector.Destructive(pc.ToolName)
+		return c, h.firstRule(pc.Connection, func(r *Rule) bool { return r.matchesTool(pc.ToolName, destructive) })
+	default:
+		return c, nil
+	}
+}
+
+// matchAPI classifies an api_invoke_endpoint call by its method and path,
+// resolving an operation_id through the catalog first.
+func (h *Handle) matchAPI(ctx context.Context, connection string, c call) (call, *Rule) {
+	var in apigatewaykit.InvokeInput
+	if json.Unmarshal(c.args, &in) != nil {
+		return c, nil // the handler reports malformed arguments
+	}
+	c.method, c.path = strings.ToUpper(in.Method), in.Path
+	if in.OperationID != "" && h.inspector != nil {
+		method, path, ok := h.inspector.Route(ctx, connection, in)
+		if !ok {
+			return c, nil // an unresolvable operation never reaches the upstream
+		}
+		c.method, c.path = strings.ToUpper(method), path
+	}
+	return c, h.firstRule(connection, func(r *Rule) bool { return r.matchesRoute(c.method, c.path) })
+}
+
+// matchGRPC classifies a grpc_invoke_method call by the canonical path of the
+// method it names. Matching the raw name would let the dotted or padded
+// spelling of a method slip past a rule written against its canonical path,
+// so a method that does not resolve is marked unresolved and governed by any
+// rule that could cover a gRPC call on the connection.
+func (h *Handle) matchGRPC(ctx context.Context, connection string, c call) (call, *Rule) {
+	var in grpcgatewaykit.InvokeInput
+	if json.Unmarshal(c.args, &in) != nil {
+		return c, nil
+	}
+	c.method = grpcMethod
+	if h.inspector != nil {
+		c.path, _ = h.inspector.MethodPath(ctx, connection, in.Method)
+	}
+	if c.path == "" {
+		c.unresolved = true
+		return c, h.firstRule(connection, func(r *Rule) bool { return r.matchesMethod(c.method) })
+	}
+	return c, h.firstRule(connection, func(r *Rule) bool { return r.matchesRoute(c.method, c.path) })
+}
+
+// firstRule returns the first rule on connection that accepts the call.
+func (h *Handle) firstRule(connection string, accepts func(*Rule) bool) *Rule {
+	if connection == "" {
+		return nil
+	}
+	for i := range h.cfg.Rules {
+		r := &h.cfg.Rules[i]
+		if matchGlob(r.Connection, connection) && accepts(r) {
+			return r
+		}
+	}
+	return nil
+}
+
+// matchesRoute reports whether an API or gRPC call falls under the rule.
+func (r *Rule) matchesRoute(method, path string) bool {
+	return r.matchesMethod(method) && (len(r.Paths) == 0 || matchAny(r.Paths, path))
+}
+
+// matchesMethod reports whether the rule governs calls with method on some
+// path.
+func (r *Rule) matchesMethod(method string) bool {
+	if len(r.Methods) == 0 {
+		return !readMethods[method]
+	}
+	return matchAny(r.Methods, method)
+}
+
+// matchesTool reports whether an MCP gateway tool falls under the rule.
+func (r *Rule) matchesTool(tool string, destructive bool) bool {
+	return (r.Destructive && destructive) || (len(r.Tools) > 0 && matchAny(r.Tools, tool))
+}
+
+// matchAny reports whether any glob matches value.
+func matchAny(globs []string, value string) bool {
+	for _, g := range globs {
+		if matchGlob(g, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob applies filepath.Match semantics, the same globs persona rules
+// use. A malformed glob matches nothing; Config.Errors reports it at load.
+func matchGlob(glob, value string) bool {
+	ok, err := filepath.Match(glob, value)
+	return err == nil && ok
+}
+
+// validGlob reports whether glob is well-formed.
+func validGlob(glob string) bool {
+	_, err := filepath.Match(glob, "")
+	return err == nil
+}
+
+// fingerprint identifies a request: who made it, which tool, and its
+// arguments in canonical form. Decoding and re-encoding sorts object keys, so
+// the agent's repeat need not reproduce the original key order.
+func fingerprint(pc *middleware.PlatformContext, args json.RawMessage) string {
+	canonical := []byte(args)
+	var v any
+	if json.Unmarshal(args, &v) == nil {
+		if b, err := json.Marshal(v); err == nil {
+			canonical = b
+		}
+	}
+	sum := sha256.New()
+	for _, part := range [][]byte{[]byte(pc.UserID), []byte(pc.ToolName), canonical} {
+		sum.Write(part)
+		sum.Write([]byte{0})
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// newID mints an approval id.
+func newID() string { return "apr_" + uuid.New().String() }
+
+// ruleName labels a rule, falling back to its connection glob.
+func ruleName(r *Rule) string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Connection
+}
+
+// describe is the one-line summary of a request used in messages and emails:
+// "POST /v1/orders on crm" for a route, "crm__delete_contact on crm" for a
+// tool.
+func describe(a *Approval) string {
+	if a.Method != "" {
+		return a.Method + " " + a.Path + " on " + a.Connection
+	}
+	return a.ToolName + " on " + a.Connection
+}
@@ -0,0 +1,139 @@
+package approvalgate
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/txn2/mcp-data-platform/pkg/middleware"
+	apigatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway"
+	gatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/gateway"
+	grpcgatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/grpcgateway"
+)
+
+// fakeInspector resolves one operation id and marks one tool destructive.
+type fakeInspector struct{}
+
+func (fakeInspector) Route(_ context.Context, _ string, in apigatewaykit.InvokeInput) (method, path string, ok bool) {
+	if in.OperationID == "createOrder" {
+		return "post", "/v1/orders", true
+	}
+	return "", "", false
+}
+
+func (fakeInspector) MethodPath(_ context.Context, _, method string) (path string, ok bool) {
+	switch strings.TrimSpace(method) {
+	case "orders.v1.Orders/CreateOrder", "orders.v1.Orders.CreateOrder":
+		return "/orders.v1.Orders/CreateOrder", true
+	case "orders.v1.Orders/GetOrder":
+		return "/orders.v1.Orders/GetOrder", true
+	}
+	return "", false
+}
+
+func (fakeInspector) Destructive(tool string) bool { return tool == "crm__delete_contact" }
+
+// matchRule runs the matcher over one call and returns the rule's name, or
+// empty when nothing governs the call.
+func matchRule(rules []Rule, pc *middleware.PlatformContext, args string) string {
+	h := &Handle{cfg: Config{Enabled: true, Rules: rules}, inspector: fakeInspector{}}
+	_, r := h.match(context.Background(), pc, json.RawMessage(args))
+	if r == nil {
+		return ""
+	}
+	return ruleName(r)
+}
+
+func TestMatch_APIRoutes(t *testing.T) {
+	rules := []Rule{
+		{Name: "deletes", Connection: "crm", Methods: []string{"DELETE"}},
+		{Name: "orders", Connection: "crm", Paths: []string{"/v1/orders*"}},
+	}
+	cases := []struct {
+		args, want string
+	}{
+		{`{"method":"GET","path":"/v1/orders"}`, ""},
+		{`{"method":"post","path":"/v1/orders"}`, "orders"},
+		{`{"method":"POST","path":"/v1/customers"}`, ""},
+		{`{"method":"DELETE","path":"/v1/customers/7"}`, "deletes"},
+		{`{"operation_id":"createOrder"}`, "orders"},
+		{`{"operation_id":"nope"}`, ""},
+		{`not json`, ""},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, matchRule(rules, apiCall("crm"), c.args), c.args)
+	}
+}
+
+func TestMatch_ConnectionGlob(t *testing.T) {
+	rules := []Rule{{Name: "prod", Connection: "*-prod"}}
+	assert.Equal(t, "prod", matchRule(rules, apiCall("crm-prod"), createOrder))
+	assert.Empty(t, matchRule(rules, apiCall("crm-staging"), createOrder))
+}
+
+func TestMatch_GRPCPresentsAsPost(t *testing.T) {
+	pc := apiCall("orders")
+	pc.ToolName, pc.ToolkitKind = grpcgatewaykit.ToolInvokeMethod, grpcgatewaykit.Kind
+	rules := []Rule{{Name: "grpc", Connection: "orders", Paths: []string{"/orders.v1.Orders/Create*"}}}
+	assert.Equal(t, "grpc", matchRule(rules, pc, `{"method":"orders.v1.Orders/CreateOrder"}`))
+	assert.Empty(t, matchRule(rules, pc, `{"method":"orders.v1.Orders/GetOrder"}`))
+}
+
+func TestMatch_GRPCResolvesTheMethod(t *testing.T) {
+	pc := apiCall("orders")
+	pc.ToolName, pc.ToolkitKind = grpcgatewaykit.ToolInvokeMethod, grpcgatewaykit.Kind
+	rules := []Rule{{Name: "grpc", Connection: "orders", Paths: []string{"/orders.v1.Orders/*"}}}
+	h := &Handle{cfg: Config{Enabled: true, Rules: rules}, inspector: fakeInspector{}}
+
+	// The dotted and padded spellings are the same method to the toolkit.
+	c, r := h.match(context.Background(), pc, json.RawMessage(`{"method":" orders.v1.Orders.CreateOrder "}`))
+	if assert.NotNil(t, r) {
+		assert.Equal(t, "/orders.v1.Orders/CreateOrder", c.path)
+		assert.False(t, c.unresolved)
+	}
+
+	// A name the connection does not declare is refused, not waved through.
+	c, r = h.match(context.Background(), pc, json.RawMessage(`{"method":"orders.v1.Orders/Nope"}`))
+	assert.NotNil(t, r)
+	assert.True(t, c.unresolved)
+
+	// ... unless no rule on the connection could govern a gRPC call at all.
+	pc.Connection = "billing"
+	_, r = h.match(context.Background(), pc, json.RawMessage(`{"method":"orders.v1.Orders/Nope"}`))
+	assert.Nil(t, r)
+}
+
+func TestMatch_MCPTools(t *testing.T) {
+	pc := apiCall("crm")
+	pc.ToolkitKind = gatewaykit.Kind
+	destructive := []Rule{{Name: "destructive", Connection: "crm", Destructive: true}}
+	named := []Rule{{Name: "named", Connection: "crm", Tools: []string{"crm__update_*"}}}
+
+	pc.ToolName = "crm__delete_contact"
+	assert.Equal(t, "destructive", matchRule(destructive, pc, `{}`))
+	assert.Empty(t, matchRule(named, pc, `{}`))
+
+	pc.ToolName = "crm__update_contact"
+	assert.Empty(t, matchRule(destructive, pc, `{}`), "an unannotated tool is not destructive")
+	assert.Equal(t, "named", matchRule(named, pc, `{}`))
+}
+
+func TestMatch_OtherToolkitsAreNeverGated(t *testing.T) {
+	pc := apiCall("crm")
+	pc.ToolName, pc.ToolkitKind = "trino_query", "trino"
+	assert.Empty(t, matchRule([]Rule{{Connection: "*"}}, pc, `{}`))
+}
+
+func TestFingerprint(t *testing.T) {
+	pc := apiCall("crm")
+	a := fingerprint(pc, json.RawMessage(`{"a":1,"b":2}`))
+	assert.Equal(t, a, fingerprint(pc, json.RawMessage(`{"b":2, "a":1}`)), "key order is not part of a request")
+	assert.NotEqual(t, a, fingerprint(pc, json.RawMessage(`{"a":1,"b":3}`)))
+
+	other := apiCall("crm")
+	other.UserID = "mallory"
+	assert.NotEqual(t, a, fingerprint(other, json.RawMessage(`{"a":1,"b":2}`)), "an approval is one caller's")
+}
@@ -0,0 +1,128 @@
+package approvalgate
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/internal/logsan"
+	"github.com/txn2/mcp-data-platform/internal/notification/notifyprefs"
+	"github.com/txn2/mcp-data-platform/internal/notification/notifyqueue"
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+const (
+	// notifyWriteTimeout bounds each enqueue. The request is already parked
+	// and the agent is waiting on its answer, so a slow database delays the
+	// email, never the response.
+	notifyWriteTimeout = 5 * time.Second
+
+	// maxEmailArguments bounds the request an email quotes. The whole request
+	// is on the approval record; the email carries enough to decide whether
+	// to go and read it.
+	maxEmailArguments = 4000
+)
+
+// Notifier queues one notification. It is the enqueue half of the email
+// substrate, narrowed to the one call this package makes; a
+// *notification.Enqueuer satisfies it.
+type Notifier interface {
+	Notify(ctx context.Context, recipient, category string, p notification.Payload) (bool, error)
+}
+
+// newNotifier builds the enqueue side of the notification substrate over db,
+// with the func that releases it. Built here rather than handed in for the
+// reason scriptexec gives: the substrate's running handle belongs to the HTTP
+// composition root, which is assembled after the middleware chain, and the
+// enqueue side is stateless over the pool.
+func newNotifier(db *sql.DB, disabled bool, digestHourUTC int) (Notifier, func()) {
+	if db == nil || disabled {
+		return nil, nil
+	}
+	enq := notification.NewEnqueuer(notifyprefs.NewPostgresStore(db), notifyqueue.NewPostgresStore(db), digestHourUTC)
+	enq.SetChannels(notifyprefs.NewChannelStore(db, nil))
+	return enq, enq.Close
+}
+
+// notifyApprovers emails the named approvers and notify addresses of a newly
+// parked request. Persona approvers are not emailed: a persona is a set of
+// IdP roles, not a list of addresses, which is what Approvers.Notify is for.
+func notifyApprovers(ctx context.Context, n Notifier, a *Approval, baseURL string) {
+	if n == nil {
+		return
+	}
+	recipients := notification.RecipientsExcluding(a.RequestedByEmail, append(append([]string{}, a.Approvers.Users...), a.Approvers.Notify...)...)
+	payload := notification.Payload{
+		Kind:      notification.KindApprovalRequest,
+		ItemID:    a.ID,
+		ItemTitle: describe(a),
+		Actor:     a.RequestedByEmail,
+		Message:   approvalDetail(a),
+		Link:      approvalLink(baseURL, a.ID),
+	}
+	send(ctx, n, a.ID, recipients, payload)
+}
+
+// notifyRequester tells the person whose agent made the request how it was
+// decided, so a person who stepped away from the agent hears too.
+func notifyRequester(ctx context.Context, n Notifier, a *Approval, baseURL string) {
+	if n == nil || a.RequestedByEmail == "" {
+		return
+	}
+	kind := notification.KindApprovalRejected
+	if a.Status == StatusApproved {
+		kind = notification.KindApprovalApproved
+	}
+	payload := notification.Payload{
+		Kind:      kind,
+		ItemID:    a.ID,
+		ItemTitle: describe(a),
+		Actor:     a.DecidedBy,
+		Message:   a.Reason,
+		Link:      approvalLink(baseURL, a.ID),
+	}
+	send(ctx, n, a.ID, []string{a.RequestedByEmail}, payload)
+}
+
+// send queues payload for each recipient. A failed enqueue is logged and the
+// next recipient is still told.
+func send(ctx context.Context, n Notifier, id string, recipients []string, p notification.Payload) {
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notifyWriteTimeout)
+	defer cancel()
+	for _, r := range recipients {
+		if _, err := n.Notify(writeCtx, r, notification.CategoryApproval, p); err != nil {
+			slog.Warn("approvals: queueing notification failed", // #nosec G706 -- structured slog call; error sanitized
+				"approval_id", id, "error", logsan.SanitizeForLog(err.Error()))
+		}
+	}
+}
+
+// approvalDetail is what an approver reads in the email: the request itself,
+// its rule, and its deadline.
+func approvalDetail(a *Approval) string {
+	args := string(a.Arguments)
+	var pretty any
+	if json.Unmarshal(a.Arguments, &pretty) == nil {
+		if b, err := json.MarshalIndent(pretty, "", "  "); err == nil {
+			args = string(b)
+		}
+	}
+	if runes := []rune(args); len(runes) > maxEmailArguments {
+		args = string(runes[:maxEmailArguments]) + "\n[truncated]"
+	}
+	return fmt.Sprintf("Tool: %s\nRule: %s\nDecide by: %s\n\nRequest:\n%s",
+		a.ToolName, a.Rule, a.ExpiresAt.UTC().Format(time.RFC1123), args)
+}
+
+// approvalLink is the portal REST address of one approval, or empty without a
+// public base URL. It opens the record; deciding is a POST beside it.
+func approvalLink(baseURL, id string) string {
+	if baseURL == "" {
+		return ""
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/api/v1/portal/approvals/" + id
+}
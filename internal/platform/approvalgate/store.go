@@ -0,0 +1,295 @@
+package approvalgate
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Approval statuses. Expired is never written by a decision: a pending row
+// whose window closed is marked expired when the next identical call parks a
+// fresh request, and reported as expired on read before then.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+	StatusExpired  = "expired"
+)
+
+var (
+	// ErrNotFound is returned when no approval has the requested id.
+	ErrNotFound = errors.New("approval not found")
+	// ErrNotPending is returned when a decision targets a request that was
+	// already decided or has expired.
+	ErrNotPending = errors.New("approval is no longer pending")
+)
+
+// Approval is one parked request and what became of it.
+type Approval struct {
+	ID          string `json:"id"`
+	Fingerprint string `json:"-"`
+	Status      string `json:"status"`
+	Rule        string `json:"rule"`
+	ToolName    string `json:"tool_name"`
+	ToolkitKind string `json:"toolkit_kind"`
+	Connection  string `json:"connection"`
+	Method      string `json:"method,omitempty"`
+	Path        string `json:"path,omitempty"`
+	// Arguments is the full request as the agent sent it, after the platform
+	// took its own arguments (session handle, purpose) off.
+	Arguments        json.RawMessage `json:"arguments"`
+	RequestedBy      string          `json:"requested_by"`
+	RequestedByEmail string          `json:"requested_by_email,omitempty"`
+	Persona          string          `json:"persona,omitempty"`
+	// Approvers is the rule's approver list as it stood when the request was
+	// parked. A later config change does not move a request to other people.
+	Approvers  Approvers  `json:"approvers"`
+	DecidedBy  string     `json:"decided_by,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	DecidedAt  *time.Time `json:"decided_at,omitempty"`
+	ConsumedAt *time.Time `json:"consumed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+}
+
+// settle reports the approval's status as of now: an open request or unused
+// approval whose window has closed reads as expired.
+func (a *Approval) settle(now time.Time) {
+	if (a.Status == StatusPending || (a.Status == StatusApproved && a.ConsumedAt == nil)) && !now.Before(a.ExpiresAt) {
+		a.Status = StatusExpired
+	}
+}
+
+// Filter narrows List.
+type Filter struct {
+	// Status selects one status; empty means any.
+	Status string
+	// RequestedBy selects one requester's user id; empty means anyone.
+	RequestedBy string
+	// DecidableBy, when set, selects only the requests that caller may
+	// decide, as Caller.CanDecide does, so a page is filled with them
+	// rather than narrowed after the limit.
+	DecidableBy *Caller
+	// Now settles Status the way a read does: a pending request or an
+	// unused approval whose window has closed matches expired. Zero means
+	// the current time.
+	Now time.Time
+	// Limit caps the rows returned; zero means defaultListLimit.
+	Limit int
+}
+
+// defaultListLimit and maxListLimit bound List.
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
+
+// Store persists approvals.
+type Store interface {
+	// Create parks a, first expiring stale pending rows for its fingerprint.
+	// When an identical request is already pending, that row is returned
+	// with created false and nothing is written.
+	Create(ctx context.Context, a Approval, now time.Time) (stored *Approval, created bool, err error)
+	// Open returns the newest request for fingerprint that still governs the
+	// next identical call: pending and unexpired, approved and unused within
+	// its window, or rejected and not yet reported within it. ErrNotFound
+	// when there is none.
+	Open(ctx context.Context, fingerprint string, now time.Time) (*Approval, error)
+	// Get returns one approval by id, or ErrNotFound.
+	Get(ctx context.Context, id string) (*Approval, error)
+	// List returns approvals newest first.
+	List(ctx context.Context, f Filter) ([]Approval, error)
+	// Decide records a decision on a pending, unexpired request, or returns
+	// ErrNotPending. expiresAt is the new window: how long an approval
+	// stays usable, or a rejection is still reported to a repeat.
+	Decide(ctx context.Context, id, status, decidedBy, reason string, now, expiresAt time.Time) error
+	// Consume marks a decided request as used and reports whether this call
+	// was the one that used it.
+	Consume(ctx context.Context, id string, now time.Time) (bool, error)
+}
+
+// PostgresStore is the tool_approvals table.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates the PostgreSQL-backed approval store.
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// approvalColumns is the column list every read selects, in scanApproval's
+// order.
+const approvalColumns = `id, fingerprint, status, rule, tool_name, toolkit_kind, connection,
+	method, path, arguments, requested_by, requested_by_email, persona, approvers,
+	decided_by, reason, decided_at, consumed_at, created_at, expires_at`
+
+// rowScanner is the Scan half of *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanApproval reads one approvalColumns row.
+func scanApproval(row rowScanner) (*Approval, error) {
+	var a Approval
+	var args, approvers []byte
+	if err := row.Scan(&a.ID, &a.Fingerprint, &a.Status, &a.Rule, &a.ToolName, &a.ToolkitKind,
+		&a.Connection, &a.Method, &a.Path, &args, &a.RequestedBy, &a.RequestedByEmail, &a.Persona,
+		&approvers, &a.DecidedBy, &a.Reason, &a.DecidedAt, &a.ConsumedAt, &a.CreatedAt, &a.ExpiresAt); err != nil {
+		return nil, err //nolint:wrapcheck // callers wrap with the operation
+	}
+	a.Arguments = json.RawMessage(args)
+	if err := json.Unmarshal(approvers, &a.Approvers); err != nil {
+		return nil, fmt.Errorf("decoding approvers of %s: %w", a.ID, err)
+	}
+	return &a, nil
+}
+
+// Create parks a request.
+func (s *PostgresStore) Create(ctx context.Context, a Approval, now time.Time) (*Approval, bool, error) {
+	approvers, err := json.Marshal(a.Approvers)
+	if err != nil {
+		return nil, false, fmt.Errorf("encoding approvers: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx,
+		`UPDATE tool_approvals SET status = 'expired'
+		  WHERE fingerprint = $1 AND status = 'pending' AND expires_at <= $2`,
+		a.Fingerprint, now); err != nil {
+		return nil, false, fmt.Errorf("expiring stale approvals: %w", err)
+	}
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO tool_approvals (id, fingerprint, status, rule, tool_name, toolkit_kind,
+		     connection, method, path, arguments, requested_by, requested_by_email, persona,
+		     approvers, created_at, expires_at)
+		 VALUES ($1, $2, 'pending', $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		 ON CONFLICT (fingerprint) WHERE status = 'pending' DO NOTHING`,
+		a.ID, a.Fingerprint, a.Rule, a.ToolName, a.ToolkitKind, a.Connection, a.Method, a.Path,
+		[]byte(a.Arguments), a.RequestedBy, a.RequestedByEmail, a.Persona, approvers, a.CreatedAt, a.ExpiresAt)
+	if err != nil {
+		return nil, false, fmt.Errorf("inserting approval: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 1 {
+		a.Status = StatusPending
+		return &a, true, nil
+	}
+	// An identical request is already pending: hand that one back.
+	existing, err := s.Open(ctx, a.Fingerprint, now)
+	if err != nil {
+		return nil, false, err
+	}
+	return existing, false, nil
+}
+
+// Open returns the request governing the next identical call.
+func (s *PostgresStore) Open(ctx context.Context, fingerprint string, now time.Time) (*Approval, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+approvalColumns+` FROM tool_approvals
+		  WHERE fingerprint = $1 AND consumed_at IS NULL
+		    AND status IN ('pending', 'approved', 'rejected') AND expires_at > $2
+		  ORDER BY created_at DESC LIMIT 1`,
+		fingerprint, now)
+	a, err := scanApproval(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading open approval: %w", err)
+	}
+	return a, nil
+}
+
+// Get returns one approval.
+func (s *PostgresStore) Get(ctx context.Context, id string) (*Approval, error) {
+	a, err := scanApproval(s.db.QueryRowContext(ctx,
+		`SELECT `+approvalColumns+` FROM tool_approvals WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading approval %s: %w", id, err)
+	}
+	return a, nil
+}
+
+// settledStatusSQL is Approval.settle as a column expression, evaluated
+// against List's $4.
+const settledStatusSQL = `CASE WHEN expires_at <= $4
+	AND (status = 'pending' OR (status = 'approved' AND consumed_at IS NULL))
+	THEN 'expired' ELSE status END`
+
+// List returns approvals newest first.
+func (s *PostgresStore) List(ctx context.Context, f Filter) ([]Approval, error) {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	limit = min(limit, maxListLimit)
+	now := f.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	var decider Caller
+	if f.DecidableBy != nil {
+		decider = *f.DecidableBy
+	}
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+approvalColumns+` FROM tool_approvals
+		  WHERE ($1 = '' OR `+settledStatusSQL+` = $1) AND ($2 = '' OR requested_by = $2)
+		    AND (NOT $5 OR (($6 = '' OR requested_by <> $6) AND ($7
+		         OR COALESCE(approvers->'personas' ?| $8, false)
+		         OR ($9 <> '' AND EXISTS (SELECT 1 FROM jsonb_array_elements_text(approvers->'users') u
+		                                   WHERE lower(u) = lower($9))))))
+		  ORDER BY created_at DESC LIMIT $3`,
+		f.Status, f.RequestedBy, limit, now,
+		f.DecidableBy != nil, decider.UserID, decider.IsAdmin, pq.Array(decider.Personas), decider.Email)
+	if err != nil {
+		return nil, fmt.Errorf("listing approvals: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	out := []Approval{}
+	for rows.Next() {
+		a, err := scanApproval(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning approval: %w", err)
+		}
+		out = append(out, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("listing approvals: %w", err)
+	}
+	return out, nil
+}
+
+// Decide records a decision.
+func (s *PostgresStore) Decide(ctx context.Context, id, status, decidedBy, reason string, now, expiresAt time.Time) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE tool_approvals
+		    SET status = $2, decided_by = $3, reason = $4, decided_at = $5, expires_at = $6
+		  WHERE id = $1 AND status = 'pending' AND expires_at > $5`,
+		id, status, decidedBy, reason, now, expiresAt)
+	if err != nil {
+		return fmt.Errorf("recording decision on %s: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotPending
+	}
+	return nil
+}
+
+// Consume marks a decided request as used.
+func (s *PostgresStore) Consume(ctx context.Context, id string, now time.Time) (bool, error) {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE tool_approvals SET consumed_at = $2
+		  WHERE id = $1 AND consumed_at IS NULL AND status IN ('approved', 'rejected')`,
+		id, now)
+	if err != nil {
+		return false, fmt.Errorf("consuming approval %s: %w", id, err)
+	}
+	n, _ := res.RowsAffected()
+	return n == 1, nil
+}
@@ -0,0 +1,138 @@
+package approvalgate
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMockStore returns a store over a mocked database plus the mock.
+func newMockStore(t *testing.T) (*PostgresStore, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	return NewPostgresStore(db), mock
+}
+
+var approvalCols = []string{
+	"id", "fingerprint", "status", "rule", "tool_name", "toolkit_kind", "connection",
+	"method", "path", "arguments", "requested_by", "requested_by_email", "persona", "approvers",
+	"decided_by", "reason", "decided_at", "consumed_at", "created_at", "expires_at",
+}
+
+// approvalRow is one stored pending request.
+func approvalRow(now time.Time) *sqlmock.Rows {
+	return sqlmock.NewRows(approvalCols).AddRow(
+		"apr_1", "fp", StatusPending, "crm-writes", "api_invoke_endpoint", "api", "crm",
+		"POST", "/v1/orders", []byte(`{"method":"POST"}`), "jane", "jane@example.com", "analyst",
+		[]byte(`{"users":["lead@example.com"]}`), "", "", nil, nil, now, now.Add(time.Hour))
+}
+
+func TestPostgresStore_CreateInserts(t *testing.T) {
+	s, mock := newMockStore(t)
+	now := time.Now()
+	mock.ExpectExec("UPDATE tool_approvals SET status = 'expired'").
+		WithArgs("fp", now).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO tool_approvals").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	got, created, err := s.Create(context.Background(), Approval{ID: "apr_1", Fingerprint: "fp", Arguments: []byte(`{}`)}, now)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, StatusPending, got.Status)
+}
+
+func TestPostgresStore_CreateReturnsThePendingTwin(t *testing.T) {
+	s, mock := newMockStore(t)
+	now := time.Now()
+	mock.ExpectExec("UPDATE tool_approvals SET status = 'expired'").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO tool_approvals").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("FROM tool_approvals").WithArgs("fp", now).WillReturnRows(approvalRow(now))
+
+	got, created, err := s.Create(context.Background(), Approval{ID: "apr_2", Fingerprint: "fp", Arguments: []byte(`{}`)}, now)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "apr_1", got.ID)
+	assert.Equal(t, []string{"lead@example.com"}, got.Approvers.Users)
+}
+
+func TestPostgresStore_OpenAndGetReportNotFound(t *testing.T) {
+	s, mock := newMockStore(t)
+	mock.ExpectQuery("FROM tool_approvals").WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("FROM tool_approvals WHERE id").WillReturnError(sql.ErrNoRows)
+
+	_, err := s.Open(context.Background(), "fp", time.Now())
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Get(context.Background(), "apr_x")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestPostgresStore_ListBoundsTheLimit(t *testing.T) {
+	s, mock := newMockStore(t)
+	now := time.Now()
+	mock.ExpectQuery("FROM tool_approvals").
+		WithArgs(StatusPending, "", maxListLimit, now, false, "", false, pq.Array([]string(nil)), "").
+		WillReturnRows(approvalRow(now))
+
+	got, err := s.List(context.Background(), Filter{Status: StatusPending, Limit: 10_000, Now: now})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "/v1/orders", got[0].Path)
+}
+
+func TestPostgresStore_ListFiltersDeciderInSQL(t *testing.T) {
+	s, mock := newMockStore(t)
+	now := time.Now()
+	c := Caller{UserID: "kim", Email: "kim@example.com", Personas: []string{"finance-leads"}}
+	mock.ExpectQuery(`CASE WHEN expires_at <= \$4.+requested_by <> \$6.+approvers->'personas' \?\| \$8`).
+		WithArgs(StatusExpired, "", defaultListLimit, now, true, "kim", false, pq.Array(c.Personas), "kim@example.com").
+		WillReturnRows(approvalRow(now))
+
+	_, err := s.List(context.Background(), Filter{Status: StatusExpired, DecidableBy: &c, Now: now})
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStore_DecideOnlyPending(t *testing.T) {
+	s, mock := newMockStore(t)
+	now := time.Now()
+	mock.ExpectExec("UPDATE tool_approvals").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE tool_approvals").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, s.Decide(context.Background(), "apr_1", StatusApproved, "lead", "", now, now.Add(time.Hour)))
+	assert.ErrorIs(t, s.Decide(context.Background(), "apr_1", StatusApproved, "lead", "", now, now.Add(time.Hour)), ErrNotPending)
+}
+
+func TestPostgresStore_ConsumeOnce(t *testing.T) {
+	s, mock := newMockStore(t)
+	now := time.Now()
+	mock.ExpectExec("SET consumed_at").WithArgs("apr_1", now).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("SET consumed_at").WithArgs("apr_1", now).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ok, err := s.Consume(context.Background(), "apr_1", now)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = s.Consume(context.Background(), "apr_1", now)
+	require.NoError(t, err)
+	assert.False(t, ok, "a concurrent repeat must not run the same approval twice")
+}
+
+func TestApprovalSettle(t *testing.T) {
+	now := time.Now()
+	a := &Approval{Status: StatusApproved, ExpiresAt: now}
+	a.settle(now)
+	assert.Equal(t, StatusExpired, a.Status)
+
+	used := &Approval{Status: StatusApproved, ConsumedAt: &now, ExpiresAt: now}
+	used.settle(now)
+	assert.Equal(t, StatusApproved, used.Status, "a used approval stays approved")
+}
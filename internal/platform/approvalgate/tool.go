@@ -0,0 +1,158 @@
+package approvalgate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/txn2/mcp-data-platform/pkg/middleware"
+)
+
+// ToolNameApprovalStatus is the tool an agent polls for the decision on a
+// request it parked. Personas that may reach gated tools must also allow it.
+const ToolNameApprovalStatus = "approval_status"
+
+// Waiting policy for approval_status. The wait is short on purpose: a person
+// decides, and people take minutes, so the agent is expected to tell its user
+// and poll again rather than hold a request open.
+const (
+	// MaxWaitSeconds caps how long one call waits for a decision.
+	MaxWaitSeconds = 30
+
+	// pollEvery is how often the wait re-reads the approval row.
+	pollEvery = time.Second
+)
+
+// approvalStatusInput is the approval_status argument set.
+type approvalStatusInput struct {
+	ApprovalID string `json:"approval_id"`
+	// WaitSeconds waits up to this long for a pending request to be decided.
+	// Zero answers at once.
+	WaitSeconds int `json:"wait_seconds,omitempty"`
+}
+
+// RegisterTools registers approval_status. No-op on a nil Handle or one
+// without a store: with no database nothing is ever parked, so there is
+// nothing to ask about.
+func (h *Handle) RegisterTools(server *mcp.Server) {
+	if h == nil || h.store == nil || server == nil {
+		return
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:  ToolNameApprovalStatus,
+		Title: "Approval Status",
+		Description: "Reports the decision on a write operation that was held for approval. " +
+			"When a tool call returns APPROVAL_REQUIRED with an approval_id, call this tool with that id " +
+			"(wait_seconds waits briefly for a decision). status is pending, approved, rejected, or expired. " +
+			"Once approved, repeat the original call with identical arguments: it then executes exactly once. " +
+			"Rejected and expired requests are not executed; repeating the call starts a new request.",
+		InputSchema: approvalStatusSchema(),
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input approvalStatusInput) (*mcp.CallToolResult, any, error) {
+		return h.handleStatus(ctx, input)
+	})
+}
+
+// handleStatus answers approval_status for the request's own requester. An
+// approval made by somebody else reads as not found, so ids do not leak.
+func (h *Handle) handleStatus(ctx context.Context, input approvalStatusInput) (*mcp.CallToolResult, any, error) {
+	if input.ApprovalID == "" {
+		return errorResult("approval_id is required"), nil, nil
+	}
+	pc := middleware.GetPlatformContext(ctx)
+	var userID string
+	if pc != nil {
+		userID = pc.UserID
+	}
+	deadline := h.now().Add(time.Duration(min(max(input.WaitSeconds, 0), MaxWaitSeconds)) * time.Second)
+	for {
+		a, err := h.store.Get(ctx, input.ApprovalID)
+		if errors.Is(err, ErrNotFound) || (err == nil && a.RequestedBy != userID) {
+			return errorResult(fmt.Sprintf("no approval %q was requested by you", input.ApprovalID)), nil, nil
+		}
+		if err != nil {
+			slog.Error("approvals: reading approval failed", "approval_id", input.ApprovalID, "error", err)
+			return errorResult("failed to read the approval"), nil, nil
+		}
+		a.settle(h.now())
+		if a.Status != StatusPending || !h.now().Before(deadline) {
+			return jsonResult(statusResult(a))
+		}
+		select {
+		case <-ctx.Done():
+			return jsonResult(statusResult(a))
+		case <-time.After(pollEvery):
+		}
+	}
+}
+
+// statusResult renders an approval for the agent, with the next step spelled
+// out: an agent that reads only the status still knows what to do.
+func statusResult(a *Approval) map[string]any {
+	out := map[string]any{
+		"approval_id": a.ID,
+		"status":      a.Status,
+		"request":     describe(a),
+		"rule":        a.Rule,
+		"expires_at":  a.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+	if a.DecidedBy != "" {
+		out["decided_by"] = a.DecidedBy
+	}
+	if a.Reason != "" {
+		out["reason"] = a.Reason
+	}
+	switch a.Status {
+	case StatusApproved:
+		if a.ConsumedAt != nil {
+			out["next_step"] = "The approved call has already run. Repeating it starts a new approval request."
+		} else {
+			out["next_step"] = "Repeat the original call with identical arguments before expires_at; it will execute once."
+		}
+	case StatusRejected:
+		out["next_step"] = "Do not repeat the call. Tell the user it was rejected and why."
+	case StatusExpired:
+		out["next_step"] = "The request expired unused. Repeating the call starts a new approval request."
+	default:
+		out["next_step"] = "Still waiting for a person to decide. Tell the user, then call approval_status again."
+	}
+	return out
+}
+
+// approvalStatusSchema is approval_status's input schema.
+func approvalStatusSchema() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"approval_id": map[string]any{
+				"type":        "string",
+				"description": "The approval_id an APPROVAL_REQUIRED result named.",
+			},
+			"wait_seconds": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Wait up to this many seconds for a pending request to be decided (default 0, maximum %d).", MaxWaitSeconds),
+			},
+		},
+		"required":             []string{"approval_id"},
+		"additionalProperties": false,
+	}
+}
+
+// errorResult builds a tool error result carrying a caller-safe message.
+func errorResult(msg string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: msg}}}
+}
+
+// jsonResult creates a JSON tool result.
+func jsonResult(v any) (*mcp.CallToolResult, any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errorResult(fmt.Sprintf("failed to marshal result: %v", err)), nil, nil
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(data)}}}, nil, nil
+}
@@ -0,0 +1,43 @@
+// Package assetcontentindex is the asset-content consumer of the shared
+// indexjobs framework. The asset consumer (assetindex) embeds an asset's name,
+// description and tags; this one reads the asset's body from blob storage and
+// indexes what is inside it, so a phrase from the middle of a report finds the
+// report, and search can say where.
+//
+// SourceID is the asset id. A unit reads the current version's body, splits it
+// into passages by content type — heading sections of HTML, markdown and
+// plain text through the textpatch document model, blocks of rows for CSV and
+// TSV — chunks each passage to the provider's input budget, and yields one
+// Item per chunk. The chunks live in portal_asset_content_chunks with their
+// section, URL fragment and text, which is what lets search return the
+// matching passage and a link that lands on it.
+//
+// Every asset write goes through the version store, which enqueues a job here
+// once the version commits; the reconciler is the backstop, finding any asset
+// whose passages were read from an older version or embedded by another model.
+package assetcontentindex
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/txn2/mcp-data-platform/pkg/indexjobs"
+)
+
+// SourceKind is the indexjobs source_kind this package serves.
+const SourceKind = "portal-asset-content"
+
+// RegisterConsumer registers the asset-content Source/Sink pair on the shared
+// indexjobs registry. blobs reads asset bodies; currentModel is the embedding
+// provider's model identifier; maxInputBytes is its per-text input budget
+// (embedding.MaxInputBytes), the size passages are chunked to.
+func RegisterConsumer(reg interface {
+	Register(indexjobs.Source, indexjobs.Sink) error
+}, db *sql.DB, blobs BlobReader, currentModel string, maxInputBytes int,
+) error {
+	store := NewStore(db)
+	if err := reg.Register(NewSource(store, blobs, maxInputBytes), NewSink(store, currentModel)); err != nil {
+		return fmt.Errorf("registering asset-content index consumer: %w", err)
+	}
+	return nil
+}
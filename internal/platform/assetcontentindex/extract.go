@@ -0,0 +1,143 @@
+package assetcontentindex
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"github.com/txn2/mcp-data-platform/pkg/contenttype"
+	"github.com/txn2/mcp-data-platform/pkg/portal/knowledgepage"
+	"github.com/txn2/mcp-data-platform/pkg/textpatch"
+)
+
+// Extraction limits.
+const (
+	// maxContentBytes is the largest body the consumer reads. A bigger asset
+	// is indexed by its metadata alone, through the asset consumer.
+	maxContentBytes = 4 << 20
+	// maxChunks bounds the provider calls one asset can cost. Passages past it
+	// are not indexed; the head of a document is where a reader lands anyway.
+	maxChunks = 256
+	// rowsPerPassage is how many data rows of a delimited file one passage
+	// holds, so a hit lands near the matching row rather than at the top.
+	rowsPerPassage = 25
+)
+
+// indexedTypes are the content types whose body the consumer reads, in their
+// canonical form. indexableAsset lists the same types for the gap query.
+var indexedTypes = map[string]bool{
+	contenttype.HTML:      true,
+	contenttype.Markdown:  true,
+	contenttype.PlainText: true,
+	contenttype.CSV:       true,
+	contenttype.TSV:       true,
+}
+
+// indexable reports whether the consumer reads bodies of this content type.
+func indexable(contentType string) bool {
+	return indexedTypes[contenttype.Normalize(contentType)]
+}
+
+// extractPassages reads a body into passages by its content type. HTML and
+// markdown split at headings through the textpatch document model, so a
+// passage's section and fragment are the ones a patch or the viewer would
+// name; plain text is read as markdown, as textpatch reads it; CSV and TSV
+// split into blocks of rows, each row rendered with its column names so a
+// passage reads on its own.
+func extractPassages(contentType string, body []byte) []textpatch.Passage {
+	text := strings.ReplaceAll(strings.ToValidUTF8(string(body), ""), "\x00", "")
+	switch ct := contenttype.Normalize(contentType); ct {
+	case contenttype.CSV:
+		return rowPassages(text, ',')
+	case contenttype.TSV:
+		return rowPassages(text, '\t')
+	default:
+		return textpatch.Passages(text, textpatch.SyntaxForContentType(ct))
+	}
+}
+
+// rowPassages splits a delimited file into passages of rowsPerPassage rows.
+// The fragment of each is "row-N", N the 1-based data row it starts at. A
+// malformed record ends the read: the rows before it are still indexed.
+func rowPassages(text string, comma rune) []textpatch.Passage {
+	r := csv.NewReader(strings.NewReader(text))
+	r.Comma = comma
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil
+	}
+	var (
+		out   []textpatch.Passage
+		lines []string
+		first = 1
+	)
+	flush := func(last int) {
+		if len(lines) == 0 {
+			return
+		}
+		out = append(out, textpatch.Passage{
+			Section:  fmt.Sprintf("Rows %d-%d", first, last),
+			Fragment: fmt.Sprintf("row-%d", first),
+			Text:     strings.Join(lines, "\n"),
+		})
+		lines, first = nil, last+1
+	}
+	n := 0
+	for {
+		// io.EOF and a malformed record both end the read.
+		record, err := r.Read()
+		if err != nil {
+			break
+		}
+		n++
+		if line := rowText(header, record); line != "" {
+			lines = append(lines, line)
+		}
+		if n%rowsPerPassage == 0 {
+			flush(n)
+		}
+	}
+	flush(n)
+	return out
+}
+
+// rowText renders one record as "column: value" pairs, skipping empty cells. A
+// cell past the header is labeled by its 1-based position.
+func rowText(header, record []string) string {
+	parts := make([]string, 0, len(record))
+	for i, v := range record {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		name := fmt.Sprintf("column %d", i+1)
+		if i < len(header) && strings.TrimSpace(header[i]) != "" {
+			name = strings.TrimSpace(header[i])
+		}
+		parts = append(parts, name+": "+v)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// chunkPassages splits each passage to the provider's input budget, keeping
+// the section in every chunk's embed text so a chunk is ranked in its
+// context. It stops at maxChunks.
+func chunkPassages(passages []textpatch.Passage, maxInputBytes int) []Chunk {
+	var out []Chunk
+	for _, p := range passages {
+		for _, part := range knowledgepage.IndexChunks("", p.Text, nil, maxInputBytes-len(p.Section)-1) {
+			if len(out) == maxChunks {
+				return out
+			}
+			out = append(out, Chunk{Section: p.Section, Fragment: p.Fragment, Text: strings.TrimSpace(part)})
+		}
+	}
+	return out
+}
+
+// embedText is the text a chunk is embedded on: its section, then its text.
+func (c Chunk) embedText() string {
+	return knowledgepage.IndexText(c.Section, c.Text, nil)
+}
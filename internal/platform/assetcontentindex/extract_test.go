@@ -0,0 +1,91 @@
+package assetcontentindex
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIndexedTypesMatchGapPredicate holds indexedTypes and the gap query's
+// type list together: a type read here but missing there would never be
+// enqueued by the reconciler, and one listed there but not read here would be
+// a gap the consumer converges with no content.
+func TestIndexedTypesMatchGapPredicate(t *testing.T) {
+	var listed []string
+	for _, m := range regexp.MustCompile(`'([a-z/-]+)'`).FindAllStringSubmatch(indexableAsset, -1) {
+		if strings.Contains(m[1], "/") {
+			listed = append(listed, m[1])
+		}
+	}
+	var read []string
+	for ct := range indexedTypes {
+		read = append(read, ct)
+	}
+	slices.Sort(listed)
+	slices.Sort(read)
+	assert.Equal(t, read, listed)
+}
+
+func TestExtractPassages_Markdown(t *testing.T) {
+	body := "# Plan\n\nShip in Q3.\n\n## Risks\n\nVendor delay.\n"
+	ps := extractPassages("text/markdown; charset=utf-8", []byte(body))
+	require.Len(t, ps, 2)
+	assert.Equal(t, "Plan > Risks", ps[1].Section)
+	assert.Equal(t, "risks", ps[1].Fragment)
+	assert.Equal(t, "Risks Vendor delay.", ps[1].Text)
+}
+
+func TestExtractPassages_HTMLDropsScript(t *testing.T) {
+	body := `<h1 id="kpi">KPIs</h1><p>Churn fell.</p><script>var x = "hidden";</script>`
+	ps := extractPassages("text/html", []byte(body))
+	require.Len(t, ps, 1)
+	assert.Equal(t, "kpi", ps[0].Fragment)
+	assert.Equal(t, "KPIs Churn fell.", ps[0].Text)
+}
+
+func TestExtractPassages_CSVRowBlocks(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("region,revenue\n")
+	for i := 1; i <= rowsPerPassage+2; i++ {
+		fmt.Fprintf(&b, "r%d,%d\n", i, i*10)
+	}
+	ps := extractPassages("text/csv", []byte(b.String()))
+	require.Len(t, ps, 2)
+	assert.Equal(t, "row-1", ps[0].Fragment)
+	assert.Equal(t, fmt.Sprintf("Rows 1-%d", rowsPerPassage), ps[0].Section)
+	assert.True(t, strings.HasPrefix(ps[0].Text, "region: r1; revenue: 10\n"))
+	assert.Equal(t, fmt.Sprintf("row-%d", rowsPerPassage+1), ps[1].Fragment)
+	assert.Equal(t, fmt.Sprintf("region: r%d; revenue: %d", rowsPerPassage+2, (rowsPerPassage+2)*10),
+		strings.Split(ps[1].Text, "\n")[1])
+}
+
+func TestExtractPassages_TSVLabelsExtraCells(t *testing.T) {
+	ps := extractPassages("text/tab-separated-values", []byte("name\nalpha\tstray\n"))
+	require.Len(t, ps, 1)
+	assert.Equal(t, "name: alpha; column 2: stray", ps[0].Text)
+}
+
+func TestExtractPassages_SanitizesBytes(t *testing.T) {
+	ps := extractPassages("text/plain", []byte("caf\xff\x00e"))
+	require.Len(t, ps, 1)
+	assert.Equal(t, "cafe", ps[0].Text)
+}
+
+func TestChunkPassages_SplitsAndCaps(t *testing.T) {
+	long := strings.Repeat("word ", 100)
+	ps := extractPassages("text/markdown", []byte("## Long\n\n"+long))
+	chunks := chunkPassages(ps, 128)
+	require.Greater(t, len(chunks), 1)
+	for _, c := range chunks {
+		assert.Equal(t, "long", c.Fragment)
+		assert.LessOrEqual(t, len(c.embedText()), 128)
+	}
+
+	many := strings.Repeat("## S\n\ntext\n\n", maxChunks+10)
+	assert.Len(t, chunkPassages(extractPassages("text/markdown", []byte(many)), 6000), maxChunks)
+}
@@ -0,0 +1,69 @@
+package assetcontentindex
+
+import (
+	"context"
+
+	"github.com/txn2/mcp-data-platform/pkg/indexjobs"
+)
+
+// Sink implements indexjobs.Sink for the asset-content kind over the passage
+// chunk table. currentModel is the provider model the gap query diffs stored
+// passage sets against, so a model swap re-embeds them.
+type Sink struct {
+	store        *Store
+	currentModel string
+}
+
+// NewSink returns a Sink backed by the given store. currentModel is the
+// embedding provider's model identifier (embedding.ModelName).
+func NewSink(store *Store, currentModel string) *Sink {
+	return &Sink{store: store, currentModel: currentModel}
+}
+
+// Compile-time interface checks.
+var (
+	_ indexjobs.Sink             = (*Sink)(nil)
+	_ indexjobs.CoverageReporter = (*Sink)(nil)
+)
+
+// Kind reports the asset-content source kind.
+func (*Sink) Kind() string { return SourceKind }
+
+// ListExisting returns the asset's embedded chunks keyed by item id, so a new
+// version re-embeds only the passages whose text moved.
+func (s *Sink) ListExisting(ctx context.Context, key indexjobs.Key) (map[string]indexjobs.Vector, error) {
+	return s.store.ListVectors(ctx, key.SourceID)
+}
+
+// Upsert writes the vectors of the asset's chunk set, pruning any chunk outside
+// it.
+func (s *Sink) Upsert(ctx context.Context, key indexjobs.Key, rows []indexjobs.Vector) error {
+	return s.store.ReplaceVectors(ctx, key.SourceID, rows)
+}
+
+// UpsertBatch writes one batch of the embed pass in place.
+func (s *Sink) UpsertBatch(ctx context.Context, key indexjobs.Key, rows []indexjobs.Vector) error {
+	return s.store.UpsertVectors(ctx, key.SourceID, rows)
+}
+
+// StampExpected marks the asset's passage set as embedded by the current
+// model. As for knowledge pages, the count is not stored: convergence is the
+// marker, not a chunk count.
+func (s *Sink) StampExpected(ctx context.Context, key indexjobs.Key, _ int) error {
+	return s.store.StampModel(ctx, key.SourceID, s.currentModel)
+}
+
+// FindGaps returns the indexable asset ids whose passage set is missing,
+// stale, or not yet embedded by the current model.
+func (s *Sink) FindGaps(ctx context.Context) ([]string, error) {
+	return s.store.FindGaps(ctx, s.currentModel)
+}
+
+// Coverage reports indexed-vs-expected totals for the asset-content kind.
+func (s *Sink) Coverage(ctx context.Context) (indexjobs.Coverage, error) {
+	indexed, expected, err := s.store.Coverage(ctx, s.currentModel)
+	if err != nil {
+		return indexjobs.Coverage{}, err
+	}
+	return indexjobs.Coverage{Indexed: indexed, Expected: expected, ExpectedKnown: true}, nil
+}
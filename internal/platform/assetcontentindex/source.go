@@ -0,0 +1,105 @@
+package assetcontentindex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/txn2/mcp-data-platform/pkg/indexjobs"
+	"github.com/txn2/mcp-data-platform/pkg/resource"
+)
+
+// logKeyAssetID is the structured-log key for an asset id.
+const logKeyAssetID = "asset_id"
+
+// BlobReader fetches asset content from blob storage: the read half of the
+// portal's S3 client, declared narrowly so the consumer can only read.
+type BlobReader interface {
+	GetObject(ctx context.Context, bucket, key string) (body []byte, contentType string, err error)
+}
+
+// Source implements indexjobs.Source for the asset-content kind. A unit is one
+// asset (SourceID = asset id) and yields one item per passage chunk of its
+// current version's body. Loading the unit also writes its passage rows, so
+// lexical search over the new text does not wait on the embed pass that
+// follows; resourceindex settles extracted text in its Source the same way.
+type Source struct {
+	store *Store
+	blobs BlobReader
+	// maxInputBytes is the provider's per-text input budget, the size each
+	// passage is chunked to.
+	maxInputBytes int
+}
+
+// NewSource returns a Source backed by the given store, reading bodies through
+// blobs and chunking passages to maxInputBytes per item.
+func NewSource(store *Store, blobs BlobReader, maxInputBytes int) *Source {
+	return &Source{store: store, blobs: blobs, maxInputBytes: maxInputBytes}
+}
+
+// Compile-time interface check.
+var _ indexjobs.Source = (*Source)(nil)
+
+// Kind reports the asset-content source kind.
+func (*Source) Kind() string { return SourceKind }
+
+// LoadItems reads the asset's current body, records its passages, and returns
+// one item per chunk. An asset deleted between enqueue and claim reports
+// indexjobs.ErrSourceGone. An asset with nothing to read — a type the consumer
+// does not index, an oversized body, a missing object — records an empty
+// passage set and yields no items, which converges it. A transient blob
+// failure fails the job and leaves the previous passages in place.
+func (s *Source) LoadItems(ctx context.Context, sourceID string) ([]indexjobs.Item, error) {
+	row, err := s.store.Load(ctx, sourceID)
+	if errors.Is(err, errGone) {
+		return nil, fmt.Errorf("asset %s: %w", sourceID, indexjobs.ErrSourceGone)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("assetContentSource: load items: %w", err)
+	}
+	body, err := s.readBody(ctx, sourceID, row)
+	if err != nil {
+		return nil, fmt.Errorf("assetContentSource: read %s: %w", sourceID, err)
+	}
+	var chunks []Chunk
+	if body != nil {
+		chunks = chunkPassages(extractPassages(row.ContentType, body), s.maxInputBytes)
+	}
+	if err := s.store.ReplacePassages(ctx, sourceID, row.Version, chunks); err != nil {
+		return nil, err
+	}
+	items := make([]indexjobs.Item, 0, len(chunks))
+	for i, c := range chunks {
+		items = append(items, indexjobs.Item{ItemID: itemID(sourceID, i), Text: c.embedText()})
+	}
+	return items, nil
+}
+
+// readBody returns the asset's body, or nil when there is none to index.
+func (s *Source) readBody(ctx context.Context, id string, row Row) ([]byte, error) {
+	if s.blobs == nil || row.Key == "" || !indexable(row.ContentType) {
+		return nil, nil
+	}
+	if row.SizeBytes > maxContentBytes {
+		slog.Info("asset content index: body too large to index; metadata only",
+			logKeyAssetID, id, "size_bytes", row.SizeBytes) // #nosec G706 -- server-generated id and size, not user input
+		return nil, nil
+	}
+	body, _, err := s.blobs.GetObject(ctx, row.Bucket, row.Key)
+	switch {
+	case err == nil:
+		return body, nil
+	case resource.IsObjectNotFound(err):
+		// The portal reads blobs through the same client as resources, so the
+		// same not-found signatures apply.
+		slog.Warn("asset content index: backing object missing; indexing no content",
+			logKeyAssetID, id) // #nosec G706 -- server-generated id, not user input
+		return nil, nil
+	default:
+		return nil, err //nolint:wrapcheck // wrapped by LoadItems with the asset id
+	}
+}
+
+// OnSucceeded is a no-op: search reads the chunk table directly.
+func (*Source) OnSucceeded(string) {}
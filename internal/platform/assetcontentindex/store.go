@@ -0,0 +1,281 @@
+package assetcontentindex
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/pgvector/pgvector-go"
+
+	"github.com/txn2/mcp-data-platform/pkg/indexjobs"
+)
+
+// Store reads and writes asset-content index state for the indexjobs
+// asset-content consumer: the passage rows in portal_asset_content_chunks, and
+// the set-level marker (content_index_version, content_index_model) on
+// portal_assets. It is separate from the portal asset store for the reason
+// knowledgepageindex.Store is: it touches only indexing state and serves only
+// the backfill path.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore returns a Store over the given database.
+func NewStore(db *sql.DB) *Store { return &Store{db: db} }
+
+// errGone is returned by Load when the asset is missing or soft-deleted.
+var errGone = errors.New("assetcontentindex: asset missing or deleted")
+
+// indexableAsset is the predicate for an asset the consumer owes a passage set:
+// live, with a stored body, of a content type extractPassages reads. It lists
+// the canonical forms of indexedTypes (a test holds the two together) and
+// ignores any parameter after ';'. Shared by FindGaps and Coverage so the gap
+// query and the coverage report cannot disagree about what "expected" means.
+const indexableAsset = `deleted_at IS NULL AND s3_key <> '' AND ` +
+	`split_part(lower(content_type), ';', 1) IN ` +
+	`('text/html', 'text/markdown', 'text/plain', 'text/csv', 'text/tab-separated-values')`
+
+// Row is what the Source needs to read one asset's body.
+type Row struct {
+	ContentType string
+	Bucket      string
+	Key         string
+	SizeBytes   int64
+	Version     int
+}
+
+// Chunk is one indexed passage chunk: the section it sits under, the fragment
+// that lands on it, and its text.
+type Chunk struct {
+	Section  string
+	Fragment string
+	Text     string
+}
+
+// itemID names one chunk of an asset: "<asset id>:<chunk index>". Only this
+// package interprets it; chunkIndex is the inverse.
+func itemID(assetID string, index int) string {
+	return assetID + ":" + strconv.Itoa(index)
+}
+
+// chunkIndex recovers the chunk ordinal from an item id produced by itemID,
+// rejecting an id that belongs to another asset or carries no ordinal.
+func chunkIndex(assetID, id string) (int, error) {
+	suffix, ok := strings.CutPrefix(id, assetID+":")
+	if !ok {
+		return 0, fmt.Errorf("assetcontentindex: item id %q is not a chunk of asset %q", id, assetID)
+	}
+	n, err := strconv.Atoi(suffix)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("assetcontentindex: item id %q has no chunk index", id)
+	}
+	return n, nil
+}
+
+// Load returns the location and current version of a live asset's body, or
+// errGone when the asset was deleted between enqueue and claim.
+func (s *Store) Load(ctx context.Context, id string) (Row, error) {
+	const q = `SELECT content_type, s3_bucket, s3_key, size_bytes, current_version
+		FROM portal_assets WHERE id = $1 AND deleted_at IS NULL`
+	var r Row
+	err := s.db.QueryRowContext(ctx, q, id).Scan(&r.ContentType, &r.Bucket, &r.Key, &r.SizeBytes, &r.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Row{}, errGone
+	}
+	if err != nil {
+		return Row{}, fmt.Errorf("assetcontentindex: load: %w", err)
+	}
+	return r, nil
+}
+
+// ReplacePassages writes the asset's passage set as read from version, in one
+// transaction: every chunk is upserted, chunks past the new set are deleted,
+// and the marker records the version with the model cleared, so the asset
+// stays a gap until the embed pass that follows stamps it. A chunk whose text
+// changed loses its vector in the same write; one whose text did not keeps it,
+// and the worker's dedup pass reuses it.
+func (s *Store) ReplacePassages(ctx context.Context, assetID string, version int, chunks []Chunk) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("assetcontentindex: begin passages: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // commit below on success
+
+	const upsert = `INSERT INTO portal_asset_content_chunks AS c
+		(asset_id, chunk_index, section, fragment, passage, indexed_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (asset_id, chunk_index) DO UPDATE SET
+			section = EXCLUDED.section, fragment = EXCLUDED.fragment, passage = EXCLUDED.passage,
+			text_hash = CASE WHEN c.passage = EXCLUDED.passage THEN c.text_hash END,
+			embedding = CASE WHEN c.passage = EXCLUDED.passage THEN c.embedding END,
+			indexed_at = NOW()`
+	for i, c := range chunks {
+		if _, err := tx.ExecContext(ctx, upsert, assetID, i, c.Section, c.Fragment, c.Text); err != nil {
+			return fmt.Errorf("assetcontentindex: upsert passage: %w", err)
+		}
+	}
+	const prune = `DELETE FROM portal_asset_content_chunks WHERE asset_id = $1 AND chunk_index >= $2`
+	if _, err := tx.ExecContext(ctx, prune, assetID, len(chunks)); err != nil {
+		return fmt.Errorf("assetcontentindex: prune passages: %w", err)
+	}
+	const mark = `UPDATE portal_assets SET content_index_version = $2, content_index_model = NULL WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, mark, assetID, version); err != nil {
+		return fmt.Errorf("assetcontentindex: mark version: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("assetcontentindex: commit passages: %w", err)
+	}
+	return nil
+}
+
+// ListVectors returns the asset's embedded chunks keyed by item id, for the
+// worker's text-hash + model dedup pass. A chunk written but not yet embedded
+// is left out, so the worker embeds it.
+func (s *Store) ListVectors(ctx context.Context, assetID string) (map[string]indexjobs.Vector, error) {
+	const q = `SELECT chunk_index, text_hash, embedding, model
+		FROM portal_asset_content_chunks WHERE asset_id = $1 AND embedding IS NOT NULL`
+	rows, err := s.db.QueryContext(ctx, q, assetID)
+	if err != nil {
+		return nil, fmt.Errorf("assetcontentindex: list vectors: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // close error on read-only iteration is not actionable
+
+	out := map[string]indexjobs.Vector{}
+	for rows.Next() {
+		var (
+			index int
+			hash  []byte
+			vec   pgvector.Vector
+			model string
+		)
+		if err := rows.Scan(&index, &hash, &vec, &model); err != nil {
+			return nil, fmt.Errorf("assetcontentindex: list vectors scan: %w", err)
+		}
+		emb := vec.Slice()
+		id := itemID(assetID, index)
+		out[id] = indexjobs.Vector{ItemID: id, TextHash: hash, Embedding: emb, Model: model, Dim: len(emb)}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("assetcontentindex: list vectors rows: %w", err)
+	}
+	return out, nil
+}
+
+// ReplaceVectors writes the vectors of the asset's chunk set and deletes every
+// chunk outside it, atomically. An empty row set deletes every chunk, which is
+// how the worker clears an asset that is gone.
+func (s *Store) ReplaceVectors(ctx context.Context, assetID string, rows []indexjobs.Vector) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("assetcontentindex: begin replace: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // commit below on success
+
+	keep := make([]int64, 0, len(rows))
+	for _, r := range rows {
+		index, err := chunkIndex(assetID, r.ItemID)
+		if err != nil {
+			return err
+		}
+		if err := setVector(ctx, tx, assetID, index, r); err != nil {
+			return err
+		}
+		keep = append(keep, int64(index))
+	}
+	const prune = `DELETE FROM portal_asset_content_chunks
+		WHERE asset_id = $1 AND NOT (chunk_index = ANY($2))`
+	if _, err := tx.ExecContext(ctx, prune, assetID, pq.Array(keep)); err != nil {
+		return fmt.Errorf("assetcontentindex: prune chunks: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("assetcontentindex: commit replace: %w", err)
+	}
+	return nil
+}
+
+// UpsertVectors writes one batch of chunk vectors in place, leaving every
+// chunk outside the batch alone, so a job that fails mid-pass keeps its
+// completed chunks for the next attempt's dedup read.
+func (s *Store) UpsertVectors(ctx context.Context, assetID string, rows []indexjobs.Vector) error {
+	for _, r := range rows {
+		index, err := chunkIndex(assetID, r.ItemID)
+		if err != nil {
+			return err
+		}
+		if err := setVector(ctx, s.db, assetID, index, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execer is the write surface setVector needs, satisfied by both *sql.DB (the
+// per-batch path) and *sql.Tx (the atomic replace).
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// setVector stores one chunk's vector. The passage row already exists — the
+// Source wrote it before the worker embedded it — so this is an update; a row
+// a newer passage set has since pruned is simply not there to update.
+func setVector(ctx context.Context, db execer, assetID string, index int, r indexjobs.Vector) error {
+	const q = `UPDATE portal_asset_content_chunks
+		SET text_hash = $3, embedding = $4, model = $5, dim = $6, indexed_at = NOW()
+		WHERE asset_id = $1 AND chunk_index = $2`
+	if _, err := db.ExecContext(ctx, q, assetID, index, r.TextHash,
+		pgvector.NewVector(r.Embedding), r.Model, len(r.Embedding)); err != nil {
+		return fmt.Errorf("assetcontentindex: set vector: %w", err)
+	}
+	return nil
+}
+
+// StampModel records that the asset's passage set was embedded by model. With
+// the version ReplacePassages recorded, it is what takes the asset out of the
+// gap query. The asset's own updated_at is untouched: indexing is not an edit.
+func (s *Store) StampModel(ctx context.Context, assetID, model string) error {
+	const q = `UPDATE portal_assets SET content_index_model = $2 WHERE id = $1`
+	if _, err := s.db.ExecContext(ctx, q, assetID, model); err != nil {
+		return fmt.Errorf("assetcontentindex: stamp model: %w", err)
+	}
+	return nil
+}
+
+// FindGaps returns the ids of indexable assets whose passage set was read from
+// an older version, was embedded by another model, or is still being embedded.
+func (s *Store) FindGaps(ctx context.Context, currentModel string) ([]string, error) {
+	const q = `SELECT id FROM portal_assets WHERE ` + indexableAsset + `
+		AND (content_index_version <> current_version OR content_index_model IS DISTINCT FROM $1)`
+	rows, err := s.db.QueryContext(ctx, q, currentModel)
+	if err != nil {
+		return nil, fmt.Errorf("assetcontentindex: find gaps: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // close error on read-only iteration is not actionable
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("assetcontentindex: find gaps scan: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("assetcontentindex: find gaps rows: %w", err)
+	}
+	return ids, nil
+}
+
+// Coverage returns the number of indexable assets whose passage set is current
+// (indexed) and the number of indexable assets (expected).
+func (s *Store) Coverage(ctx context.Context, currentModel string) (indexed, expected int, err error) {
+	const q = `SELECT COUNT(*) FILTER (WHERE content_index_version = current_version
+			AND content_index_model IS NOT DISTINCT FROM $1) AS indexed,
+		COUNT(*) AS expected FROM portal_assets WHERE ` + indexableAsset
+	if err := s.db.QueryRowContext(ctx, q, currentModel).Scan(&indexed, &expected); err != nil {
+		return 0, 0, fmt.Errorf("assetcontentindex: coverage: %w", err)
+	}
+	return indexed, expected, nil
+}
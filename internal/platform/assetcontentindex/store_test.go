@@ -0,0 +1,207 @@
+package assetcontentindex
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/pkg/indexjobs"
+)
+
+// fakeRegistry records Register calls for RegisterConsumer tests.
+type fakeRegistry struct {
+	calls int
+	err   error
+}
+
+func (f *fakeRegistry) Register(_ indexjobs.Source, _ indexjobs.Sink) error {
+	f.calls++
+	return f.err
+}
+
+// fakeBlobs serves one body, or an error.
+type fakeBlobs struct {
+	body []byte
+	err  error
+	gets int
+}
+
+func (f *fakeBlobs) GetObject(_ context.Context, _, _ string) ([]byte, string, error) {
+	f.gets++
+	return f.body, "", f.err
+}
+
+func newMock(t *testing.T) (*Store, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return NewStore(db), mock
+}
+
+var assetCols = []string{"content_type", "s3_bucket", "s3_key", "size_bytes", "current_version"}
+
+func TestKindAndRegister(t *testing.T) {
+	assert.Equal(t, SourceKind, NewSource(nil, nil, 0).Kind())
+	assert.Equal(t, SourceKind, NewSink(nil, "m").Kind())
+
+	store, _ := newMock(t)
+	reg := &fakeRegistry{}
+	require.NoError(t, RegisterConsumer(reg, store.db, &fakeBlobs{}, "m", 6000))
+	assert.Equal(t, 1, reg.calls)
+	assert.Error(t, RegisterConsumer(&fakeRegistry{err: errors.New("boom")}, store.db, nil, "m", 6000))
+}
+
+func TestChunkIndexRejectsForeignItemIDs(t *testing.T) {
+	got, err := chunkIndex("a1", itemID("a1", 3))
+	require.NoError(t, err)
+	assert.Equal(t, 3, got)
+	for _, id := range []string{"a1", "a2:0", "a1:", "a1:x", "a1:-1"} {
+		_, err := chunkIndex("a1", id)
+		assert.Error(t, err, "item id %q must be rejected", id)
+	}
+}
+
+func TestSourceLoadItems_WritesPassagesThenYieldsChunks(t *testing.T) {
+	store, mock := newMock(t)
+	blobs := &fakeBlobs{body: []byte("# Plan\n\nShip in Q3.\n\n## Risks\n\nVendor delay.\n")}
+
+	mock.ExpectQuery("SELECT content_type, s3_bucket, s3_key, size_bytes, current_version FROM portal_assets").
+		WithArgs("a1").
+		WillReturnRows(sqlmock.NewRows(assetCols).AddRow("text/markdown", "b", "k", 64, 4))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO portal_asset_content_chunks").
+		WithArgs("a1", 0, "Plan", "plan", "Plan Ship in Q3.").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO portal_asset_content_chunks").
+		WithArgs("a1", 1, "Plan > Risks", "risks", "Risks Vendor delay.").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM portal_asset_content_chunks WHERE asset_id = \\$1 AND chunk_index >= \\$2").
+		WithArgs("a1", 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE portal_assets SET content_index_version = \\$2, content_index_model = NULL").
+		WithArgs("a1", 4).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	items, err := NewSource(store, blobs, 6000).LoadItems(context.Background(), "a1")
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, indexjobs.Item{ItemID: "a1:1", Text: "Plan > Risks\nRisks Vendor delay."}, items[1])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSourceLoadItems_NothingToReadConverges(t *testing.T) {
+	cases := map[string]struct {
+		contentType string
+		size        int64
+		blobs       *fakeBlobs
+	}{
+		"unindexed type": {"application/json", 10, &fakeBlobs{body: []byte(`{}`)}},
+		"oversized":      {"text/plain", maxContentBytes + 1, &fakeBlobs{body: []byte("x")}},
+		"missing object": {"text/plain", 10, &fakeBlobs{err: errors.New("NoSuchKey: not found")}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			store, mock := newMock(t)
+			mock.ExpectQuery("SELECT content_type").WithArgs("a1").
+				WillReturnRows(sqlmock.NewRows(assetCols).AddRow(tc.contentType, "b", "k", tc.size, 2))
+			mock.ExpectBegin()
+			mock.ExpectExec("DELETE FROM portal_asset_content_chunks").WithArgs("a1", 0).
+				WillReturnResult(sqlmock.NewResult(0, 3))
+			mock.ExpectExec("UPDATE portal_assets SET content_index_version").WithArgs("a1", 2).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			items, err := NewSource(store, tc.blobs, 6000).LoadItems(context.Background(), "a1")
+			require.NoError(t, err)
+			assert.Empty(t, items)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSourceLoadItems_TransientBlobErrorKeepsPassages(t *testing.T) {
+	store, mock := newMock(t)
+	mock.ExpectQuery("SELECT content_type").WithArgs("a1").
+		WillReturnRows(sqlmock.NewRows(assetCols).AddRow("text/html", "b", "k", 10, 2))
+
+	_, err := NewSource(store, &fakeBlobs{err: errors.New("connection reset")}, 6000).
+		LoadItems(context.Background(), "a1")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, indexjobs.ErrSourceGone)
+	require.NoError(t, mock.ExpectationsWereMet(), "a failed read must not touch the passage rows")
+}
+
+func TestSourceLoadItems_GoneAsset(t *testing.T) {
+	store, mock := newMock(t)
+	mock.ExpectQuery("SELECT content_type").WithArgs("a1").WillReturnRows(sqlmock.NewRows(assetCols))
+
+	_, err := NewSource(store, &fakeBlobs{}, 6000).LoadItems(context.Background(), "a1")
+	assert.ErrorIs(t, err, indexjobs.ErrSourceGone)
+}
+
+func TestListVectors_SkipsUnembeddedChunks(t *testing.T) {
+	store, mock := newMock(t)
+	mock.ExpectQuery("FROM portal_asset_content_chunks WHERE asset_id = \\$1 AND embedding IS NOT NULL").
+		WithArgs("a1").
+		WillReturnRows(sqlmock.NewRows([]string{"chunk_index", "text_hash", "embedding", "model"}).
+			AddRow(1, []byte{9}, pgvector.NewVector([]float32{1, 2}), "m"))
+
+	got, err := store.ListVectors(context.Background(), "a1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]indexjobs.Vector{
+		"a1:1": {ItemID: "a1:1", TextHash: []byte{9}, Embedding: []float32{1, 2}, Model: "m", Dim: 2},
+	}, got)
+}
+
+func TestReplaceVectors_UpdatesAndPrunes(t *testing.T) {
+	store, mock := newMock(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE portal_asset_content_chunks").
+		WithArgs("a1", 0, []byte{1}, sqlmock.AnyArg(), "m", 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM portal_asset_content_chunks").
+		WithArgs("a1", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	sink := NewSink(store, "m")
+	require.NoError(t, sink.Upsert(context.Background(), indexjobs.Key{SourceID: "a1"}, []indexjobs.Vector{
+		{ItemID: "a1:0", TextHash: []byte{1}, Embedding: []float32{1, 2}, Model: "m"},
+	}))
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	assert.Error(t, sink.Upsert(context.Background(), indexjobs.Key{SourceID: "a1"},
+		[]indexjobs.Vector{{ItemID: "a2:0"}}), "a foreign item id is refused")
+}
+
+func TestSinkGapsCoverageAndStamp(t *testing.T) {
+	store, mock := newMock(t)
+	sink := NewSink(store, "m")
+	key := indexjobs.Key{SourceID: "a1"}
+
+	mock.ExpectExec("UPDATE portal_asset_content_chunks").
+		WithArgs("a1", 2, []byte{1}, sqlmock.AnyArg(), "m", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, sink.UpsertBatch(context.Background(), key, []indexjobs.Vector{
+		{ItemID: "a1:2", TextHash: []byte{1}, Embedding: []float32{1}, Model: "m"},
+	}))
+
+	mock.ExpectExec("UPDATE portal_assets SET content_index_model = \\$2").
+		WithArgs("a1", "m").WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, sink.StampExpected(context.Background(), key, 3))
+
+	mock.ExpectQuery("content_index_version <> current_version OR content_index_model IS DISTINCT FROM \\$1").
+		WithArgs("m").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("a1").AddRow("a2"))
+	gaps, err := sink.FindGaps(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a1", "a2"}, gaps)
+
+	mock.ExpectQuery("SELECT COUNT").WithArgs("m").
+		WillReturnRows(sqlmock.NewRows([]string{"indexed", "expected"}).AddRow(3, 5))
+	cov, err := sink.Coverage(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, indexjobs.Coverage{Indexed: 3, Expected: 5, ExpectedKnown: true}, cov)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -88,6 +88,23 @@ func (l *Lister) ForPersona(ctx context.Context, personaName string, unrestricte
 	return conns
 }
 
+// ForRoles lists what the persona roles resolve to reaches, which is the
+// reach of a session presenting those roles: a script run presents its
+// version author's captured roles. Roles that resolve to no persona reach
+// nothing.
+func (l *Lister) ForRoles(ctx context.Context, roles []string) []Connection {
+	if l == nil {
+		return nil
+	}
+	var personaName string
+	if l.personas != nil {
+		if p, ok := l.personas.GetForRoles(roles); ok {
+			personaName = p.Name
+		}
+	}
+	return l.ForPersona(ctx, personaName, false)
+}
+
 // value is the name a call actually binds, which is not always the name the
 // enumeration leads with: a single-connection toolkit's entry carries its
 // INSTANCE name in Name and its connection name in Connection, and the
@@ -73,6 +73,16 @@ func TestForPersona_DeniesAnUnresolvedPersona(t *testing.T) {
 	assert.Empty(t, connreach.New(fixture(t)).ForPersona(context.Background(), "nobody", false))
 }
 
+// TestForRoles_ResolvesThePersonaTheRolesPresent lists what a session
+// presenting the roles reaches, and nothing for roles no persona claims.
+func TestForRoles_ResolvesThePersonaTheRolesPresent(t *testing.T) {
+	l := connreach.New(fixture(t))
+	got := l.ForRoles(context.Background(), []string{"dp_analyst"})
+	require.Len(t, got, 1)
+	assert.Equal(t, "warehouse", got[0].Name)
+	assert.Empty(t, l.ForRoles(context.Background(), []string{"dp_stranger"}))
+}
+
 // TestForPersona_FallsBackToTheInstanceName covers a toolkit configured with no
 // connection name of its own: there is nothing else for a caller to name it by,
 // so the instance name is the value.
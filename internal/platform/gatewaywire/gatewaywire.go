@@ -0,0 +1,173 @@
+// Package gatewaywire attaches platform services to the gateway-kind
+// toolkits in the registry: the MCP gateway (kind mcp), the API gateway
+// (kind api), and the gRPC gateway (kind grpc). Each function walks the
+// registered toolkits and hands the service to every toolkit of a kind
+// that uses it, so a new gateway kind is wired in one place.
+// ApprovalInspector runs the other way, answering the approval gate's
+// questions about a call from the toolkits that serve it.
+//
+// Split out of pkg/platform to keep that package under its size budget.
+// The Wire* methods on Platform stay the public entry points and delegate
+// here.
+package gatewaywire
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/txn2/mcp-data-platform/internal/platform/approvalgate"
+	"github.com/txn2/mcp-data-platform/pkg/registry"
+	"github.com/txn2/mcp-data-platform/pkg/session"
+	apigatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway"
+	apigatewaycatalog "github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway/catalog"
+	gatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/gateway"
+	grpcgatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/grpcgateway"
+)
+
+// Broadcaster attaches b to every MCP gateway toolkit so SSE long-poll
+// subscribers receive tools/list_changed events whenever a gateway
+// connection is added, removed, or comes up after re-auth. A nil b is a
+// no-op.
+func Broadcaster(toolkits []registry.Toolkit, b session.Broadcaster) {
+	if b == nil {
+		return
+	}
+	notifier := listChangedNotifier{b: b}
+	for _, tk := range toolkits {
+		if gw, ok := tk.(*gatewaykit.Toolkit); ok {
+			gw.SetToolListChangedNotifier(notifier)
+		}
+	}
+}
+
+// listChangedNotifier adapts session.Broadcaster onto
+// gatewaykit.ToolListChangedNotifier so the gateway package doesn't
+// have to import pkg/session directly. Holds a reference to the
+// shared broadcaster; Publish errors are swallowed (logged) since
+// tools/list_changed is best-effort.
+type listChangedNotifier struct {
+	b session.Broadcaster
+}
+
+// NotifyToolsListChanged publishes a notifications/tools/list_changed
+// event to every connected SSE long-poll subscriber.
+func (n listChangedNotifier) NotifyToolsListChanged(ctx context.Context) {
+	if n.b == nil {
+		return
+	}
+	if err := n.b.Publish(ctx, session.Event{Method: "notifications/tools/list_changed"}); err != nil {
+		// source=gateway lets operators correlate this warning back to
+		// the gateway publish path vs. other broadcaster publishers
+		// (today there are none, but the broadcaster is shared and a
+		// future publisher would otherwise produce identically-shaped
+		// noise in dashboards).
+		slog.Warn("broadcaster: publish tools/list_changed failed",
+			"source", "gateway",
+			"method", "notifications/tools/list_changed",
+			"error", err)
+	}
+}
+
+// CatalogStore attaches store to every API and gRPC gateway toolkit.
+// API gateway connections read their OpenAPI specs from it and are
+// reloaded so connections registered before the store existed pick up
+// their catalog content; when the store is database-backed it also
+// answers the promoted-examples lookup (#1321) and keeps the contract
+// drift report. gRPC connections write
+// their synthesized service specs into it rather than read from it. A
+// nil store is a no-op.
+func CatalogStore(toolkits []registry.Toolkit, store apigatewaycatalog.Store) {
+	if store == nil {
+		return
+	}
+	for _, tk := range toolkits {
+		switch gw := tk.(type) {
+		case *grpcgatewaykit.Toolkit:
+			gw.SetCatalogStore(store)
+		case *apigatewaykit.Toolkit:
+			gw.SetCatalogStore(store)
+			if examples, ok := store.(apigatewaycatalog.ExampleStore); ok {
+				gw.SetExampleStore(examples)
+			}
+			if drift, ok := store.(apigatewaycatalog.DriftStore); ok {
+				gw.SetDriftStore(drift)
+			}
+			for _, detail := range gw.ListConnections() {
+				if err := gw.ReloadConnection(detail.Name); err != nil {
+					slog.Warn("apigateway: catalog wire reload failed",
+						"connection", detail.Name, "error", err)
+				}
+			}
+		}
+	}
+}
+
+// RoutePolicy installs policy on every API and gRPC gateway toolkit.
+// gRPC calls present as POST on "/pkg.Service/Method", so the same
+// persona api_routes rules gate both gateway kinds.
+func RoutePolicy(toolkits []registry.Toolkit, policy apigatewaykit.RoutePolicy) {
+	for _, tk := range toolkits {
+		switch gw := tk.(type) {
+		case *apigatewaykit.Toolkit:
+			gw.SetRoutePolicy(policy)
+		case *grpcgatewaykit.Toolkit:
+			gw.SetRoutePolicy(policy)
+		}
+	}
+}
+
+// ToolkitLister is the live toolkit registry.
+type ToolkitLister interface {
+	All() []registry.Toolkit
+}
+
+// ApprovalInspector answers the approval gate's questions about a gateway
+// call from the toolkits that serve it. It reads the registry on every
+// call rather than holding toolkits resolved at assembly, because
+// connections are added, reloaded and removed while the platform runs.
+func ApprovalInspector(toolkits ToolkitLister) approvalgate.Inspector {
+	return approvalInspector{toolkits: toolkits}
+}
+
+// approvalInspector implements approvalgate.Inspector.
+type approvalInspector struct {
+	toolkits ToolkitLister
+}
+
+// Route resolves an operation_id through the API gateway toolkit serving
+// connection, with the same resolution api_invoke_endpoint performs.
+func (i approvalInspector) Route(ctx context.Context, connection string, in apigatewaykit.InvokeInput) (method, path string, ok bool) {
+	for _, tk := range i.toolkits.All() {
+		if gw, isAPI := tk.(*apigatewaykit.Toolkit); isAPI {
+			if m, p, resolved := gw.ResolveOperationRequest(ctx, connection, in.OperationID, in.Spec, in.PathParams); resolved {
+				return m, p, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// MethodPath resolves a gRPC method name through the gRPC gateway
+// toolkit serving connection, with the same lookup grpc_invoke_method
+// performs.
+func (i approvalInspector) MethodPath(ctx context.Context, connection, method string) (path string, ok bool) {
+	for _, tk := range i.toolkits.All() {
+		if gw, isGRPC := tk.(*grpcgatewaykit.Toolkit); isGRPC {
+			if p, resolved := gw.ResolveMethodPath(ctx, connection, method); resolved {
+				return p, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Destructive reports whether an MCP gateway toolkit's upstream annotates
+// the namespaced tool as destructive.
+func (i approvalInspector) Destructive(tool string) bool {
+	for _, tk := range i.toolkits.All() {
+		if gw, isMCP := tk.(*gatewaykit.Toolkit); isMCP && gw.IsDestructive(tool) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package gatewaywire
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/txn2/mcp-data-platform/pkg/registry"
+	"github.com/txn2/mcp-data-platform/pkg/session"
+	apigatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway"
+	gatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/gateway"
+)
+
+// TestListChangedNotifier_NilBroadcaster proves the adapter
+// silently no-ops when its broadcaster is nil — the gateway must not
+// panic if the platform never wired one (e.g., headless tests).
+//
+// Captures the slog output so a regression that changes the no-op
+// path into a Warn/Error (or vice versa) is caught instead of just
+// "doesn't panic" coverage.
+func TestListChangedNotifier_NilBroadcaster(t *testing.T) {
+	var buf bytes.Buffer
+	prevDefault := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer slog.SetDefault(prevDefault)
+
+	n := listChangedNotifier{b: nil}
+	n.NotifyToolsListChanged(context.Background()) // must not panic
+
+	if buf.Len() != 0 {
+		t.Errorf("nil-broadcaster path must be silent, got log output: %q", buf.String())
+	}
+}
+
+// TestListChangedNotifier_PublishError proves the adapter
+// swallows publish errors (logging them via slog) — list_changed is
+// best-effort and should never propagate to the gateway's caller.
+func TestListChangedNotifier_PublishError(t *testing.T) {
+	var buf bytes.Buffer
+	prevDefault := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer slog.SetDefault(prevDefault)
+
+	b := session.NewMemoryBroadcaster(nil)
+	_ = b.Close()
+	n := listChangedNotifier{b: b}
+	// Publish on a closed broadcaster returns ErrBroadcasterClosed —
+	// the adapter must log it and not propagate.
+	n.NotifyToolsListChanged(context.Background())
+
+	if got := buf.String(); !strings.Contains(got, "publish tools/list_changed failed") {
+		t.Errorf("expected publish-failure warning in slog output, got %q", got)
+	}
+}
+
+// toolkitList is a fixed registry.
+type toolkitList []registry.Toolkit
+
+func (l toolkitList) All() []registry.Toolkit { return l }
+
+// TestApprovalInspector_UnknownCallsAreNotResolved proves the inspector
+// answers "no" rather than guessing when no toolkit serves the call: an
+// unresolvable operation is refused by the toolkit itself, and a tool no
+// upstream annotates is not destructive.
+func TestApprovalInspector_UnknownCallsAreNotResolved(t *testing.T) {
+	in := ApprovalInspector(toolkitList{gatewaykit.New("primary")})
+
+	if _, _, ok := in.Route(context.Background(), "crm", apigatewaykit.InvokeInput{OperationID: "createOrder"}); ok {
+		t.Error("Route resolved an operation no toolkit serves")
+	}
+	if in.Destructive("crm__delete_contact") {
+		t.Error("Destructive reported a tool no upstream serves")
+	}
+}
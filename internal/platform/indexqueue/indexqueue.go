@@ -2,8 +2,8 @@
 // (pkg/indexjobs) behind one Handle: the Postgres store, the Source/Sink
 // registry, the worker/reaper/reconciler, the optional retention sweep and
 // LISTEN/NOTIFY adapter, and every enabled consumer (api-catalog, tools,
-// memory, prompts, portal assets/collections/knowledge-pages, managed
-// resources, managed scripts, catalog datasets).
+// memory, prompts, portal assets/asset content/collections/knowledge-pages,
+// managed resources, managed scripts, catalog datasets).
 //
 // New takes an explicit Config: callers translate their own config into Config
 // at the boundary and wire the returned Handle's Start/Stop into their own
@@ -17,6 +17,7 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/txn2/mcp-data-platform/internal/platform/assetcontentindex"
 	"github.com/txn2/mcp-data-platform/internal/platform/assetindex"
 	"github.com/txn2/mcp-data-platform/internal/platform/callindex"
 	"github.com/txn2/mcp-data-platform/internal/platform/collectionindex"
@@ -48,7 +49,12 @@ type Consumers struct {
 	PortalAssets         bool
 	PortalCollections    bool
 	PortalKnowledgePages bool
-	Resources            bool
+	// PortalAssetContent registers the asset-content consumer, which reads
+	// each HTML, markdown, text and delimited asset's body from AssetBlobs and
+	// indexes it passage by passage, so search can return where inside an
+	// asset a match is. It needs AssetBlobs; without one it is not registered.
+	PortalAssetContent bool
+	Resources          bool
 	// Scripts registers the managed-script consumer, which embeds each script's
 	// description card so an automation is found by what it does and not only
 	// by the words it was named with (#1370).
@@ -129,6 +135,10 @@ type Config struct {
 	// reader leaves the consumer indexing metadata only.
 	ResourceBlobs  resourceindex.BlobReader
 	ResourceBucket string
+
+	// AssetBlobs reads portal asset bodies for the asset-content consumer.
+	// Each asset row names its own bucket, so no bucket is configured here.
+	AssetBlobs assetcontentindex.BlobReader
 }
 
 // Handle owns the assembled queue and its runtime goroutines. All components
@@ -330,6 +340,12 @@ func (h *Handle) registerDataConsumers(cfg Config) {
 		return knowledgepageindex.RegisterConsumer(h.registry, cfg.DB, cfg.ModelName,
 			embedding.MaxInputBytes(cfg.Embedder))
 	})
+	// Asset-content consumer: indexes the passages inside portal assets, read
+	// from blob storage and chunked to the same budget as knowledge pages.
+	tryRegister(cfg.Consumers.PortalAssetContent && cfg.AssetBlobs != nil, "portal asset content", func() error {
+		return assetcontentindex.RegisterConsumer(h.registry, cfg.DB, cfg.AssetBlobs, cfg.ModelName,
+			embedding.MaxInputBytes(cfg.Embedder))
+	})
 	// Catalog-dataset consumer: mirrors the semantic catalog's dataset text into
 	// the platform's own index so a fact applied to a description is reachable
 	// from a topical query that names no entity (#1131). Its corpus is DataHub,
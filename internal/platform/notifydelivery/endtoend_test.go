@@ -39,12 +39,12 @@ func (q *memQueue) Enqueue(_ context.Context, n notification.Notification) error
 	return nil
 }
 
-func (q *memQueue) ClaimImmediate(_ context.Context, _ time.Duration) (*notification.Notification, error) {
+func (q *memQueue) ClaimImmediate(_ context.Context, _ time.Duration, includeEmail bool) (*notification.Notification, error) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 	for i := range q.rows {
 		r := &q.rows[i]
-		if !r.Digest && r.Status == notification.StatusPending && !r.ScheduledFor.After(time.Now()) {
+		if !r.Digest && (r.ChannelID != 0 || includeEmail) && r.Status == notification.StatusPending && !r.ScheduledFor.After(time.Now()) {
 			r.Status = notification.StatusSending
 			r.Attempts++
 			clone := *r
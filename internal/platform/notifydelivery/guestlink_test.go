@@ -66,6 +66,36 @@ func TestSendGuestLink_Refusals(t *testing.T) {
 	}
 }
 
+func TestSendShareCode(t *testing.T) {
+	sender := &captureSender{}
+	h := &Handle{
+		settings: &fakeSettings{settings: &smtp.Settings{
+			Enabled: true, Host: "smtp.example.com", Port: 587, From: "p@example.com",
+		}},
+		renderer: testRenderer(t),
+		sender:   sender,
+	}
+
+	if err := h.SendShareCode(context.Background(), "bob@example.com", "042917"); err != nil {
+		t.Fatalf("SendShareCode: %v", err)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].To != "bob@example.com" {
+		t.Fatalf("unexpected sends: %+v", sender.sent)
+	}
+	if !strings.Contains(sender.sent[0].Text, "042917") {
+		t.Error("email must carry the code")
+	}
+
+	var nilHandle *Handle
+	if err := nilHandle.SendShareCode(context.Background(), "a@b.io", "1"); err == nil {
+		t.Error("nil handle SendShareCode must error")
+	}
+	disabled := &Handle{settings: &fakeSettings{settings: &smtp.Settings{}}, renderer: testRenderer(t), sender: sender}
+	if err := disabled.SendShareCode(context.Background(), "a@b.io", "1"); err == nil {
+		t.Error("disabled smtp must refuse")
+	}
+}
+
 func TestNew_UnsubscribeURLReachesRenderer(t *testing.T) {
 	db, _, err := sqlmock.New()
 	if err != nil {
@@ -1,7 +1,8 @@
 // Package notifydelivery assembles the email-notification substrate into one
 // startable handle: the settings, preference, and queue stores, the
-// trigger-side enqueuer, the renderer and SMTP sender, and the send worker
-// with its LISTEN/NOTIFY wakeup adapter.
+// trigger-side enqueuer, the renderer, the SMTP sender and channel poster, the
+// chat and webhook channel store, and the send worker with its LISTEN/NOTIFY
+// wakeup adapter.
 //
 // It is the only place that names every layer of the substrate at once. Each
 // layer is a package of its own (pkg/notification for the domain,
@@ -23,6 +24,7 @@ import (
 	"log/slog"
 
 	"github.com/txn2/mcp-data-platform/internal/logsan"
+	"github.com/txn2/mcp-data-platform/internal/notification/notifychannel"
 	"github.com/txn2/mcp-data-platform/internal/notification/notifyprefs"
 	"github.com/txn2/mcp-data-platform/internal/notification/notifyqueue"
 	"github.com/txn2/mcp-data-platform/internal/notification/notifyrender"
@@ -42,8 +44,9 @@ type Config struct {
 	// DSN is the raw database DSN for the LISTEN connection. Empty disables
 	// LISTEN/NOTIFY; the worker degrades to poll-only.
 	DSN string
-	// Encryptor protects the SMTP password at rest. Required (may be a
-	// nil-wrapping passthrough when encryption is disabled).
+	// Encryptor protects the SMTP password, and each channel's webhook URL
+	// and signing secret, at rest. Required (may be a nil-wrapping
+	// passthrough when encryption is disabled).
 	Encryptor smtp.StringEncryptor
 	// Branding is the deployment identity emails render with.
 	Branding notifyrender.Branding
@@ -65,16 +68,28 @@ type listenerControl interface {
 type Handle struct {
 	settings smtp.SettingsStore
 	prefs    notification.PrefsStore
+	channels notification.ChannelStore
+	watches  notification.WatchStore
 	queue    notification.QueueStore
 	// history is the same PostgreSQL queue store narrowed to its read
 	// contract, held separately so the accessor cannot hand a caller the
 	// worker's write path.
-	history  notification.HistoryStore
+	history notification.HistoryStore
+	// inbox is the same store again, narrowed to the per-person inbox.
+	inbox notification.InboxStore
+	// wakeups relays inbox changes to the open inbox streams of the person
+	// each one names. It is registered on inboxListener, so without one it
+	// never fires and the streams fall back to their poll.
+	wakeups  *notifyqueue.Fanout
 	enqueuer *notification.Enqueuer
 	renderer *notifyrender.Renderer
 	sender   notifysend.Sender
 	worker   *notifyworker.Worker
 	listener listenerControl
+	// inboxListener LISTENs on the inbox channel, apart from the worker's:
+	// an inbox change is nothing to send, and an enqueue is only an inbox
+	// change for its one recipient.
+	inboxListener listenerControl
 }
 
 // New composes the substrate. Returns nil when cfg.DB is nil (no database:
@@ -94,39 +109,55 @@ func New(cfg Config) (*Handle, error) {
 	h := &Handle{
 		settings: smtp.NewPostgresStore(cfg.DB, cfg.Encryptor),
 		prefs:    notifyprefs.NewPostgresStore(cfg.DB),
+		channels: notifyprefs.NewChannelStore(cfg.DB, cfg.Encryptor),
+		watches:  notifyprefs.NewWatchStore(cfg.DB),
 		queue:    queue,
 		history:  queue,
+		inbox:    queue,
+		wakeups:  notifyqueue.NewFanout(),
 		renderer: renderer,
 		sender:   notifysend.NewSMTPSender(),
 	}
 	h.enqueuer = notification.NewEnqueuer(h.prefs, h.queue, cfg.DigestHourUTC)
+	h.enqueuer.SetChannels(h.channels)
 	h.worker = notifyworker.New(notifyworker.Config{
 		Queue:    h.queue,
 		Settings: h.settings,
 		Renderer: renderer,
 		Sender:   h.sender,
+		Channels: h.channels,
+		Poster:   notifychannel.NewHTTPPoster(),
 	})
 	if cfg.DSN != "" {
 		h.listener = notifyqueue.NewListener(cfg.DSN, h.worker)
+		h.inboxListener = notifyqueue.NewInboxListener(cfg.DSN, h.wakeups)
 	}
 	return h, nil
 }
 
-// Start launches the send worker and, when configured, the LISTEN adapter.
+// Start launches the send worker and, when configured, the LISTEN adapters.
 // A LISTEN failure (e.g. missing privilege) degrades to poll-only delivery
-// rather than failing startup. Nil-safe.
+// and poll-only inbox streams rather than failing startup. Nil-safe.
 func (h *Handle) Start(ctx context.Context) {
 	if h == nil {
 		return
 	}
 	h.worker.Start(ctx)
-	if h.listener == nil {
-		return
+	h.listener = startListener(ctx, h.listener, "notification: LISTEN unavailable; falling back to polling")
+	h.inboxListener = startListener(ctx, h.inboxListener, "notification: inbox LISTEN unavailable; inbox streams fall back to polling")
+}
+
+// startListener starts l, returning nil in its place when there is none or it
+// fails to start, so Stop skips it.
+func startListener(ctx context.Context, l listenerControl, warning string) listenerControl {
+	if l == nil {
+		return nil
 	}
-	if err := h.listener.Start(ctx); err != nil {
-		slog.Warn("notification: LISTEN unavailable; falling back to polling", logKeyError, err)
-		h.listener = nil
+	if err := l.Start(ctx); err != nil {
+		slog.Warn(warning, logKeyError, err)
+		return nil
 	}
+	return l
 }
 
 // Stop shuts down the listener, worker, and enqueuer limiter, waiting for
@@ -138,6 +169,9 @@ func (h *Handle) Stop() {
 	if h.listener != nil {
 		h.listener.Stop()
 	}
+	if h.inboxListener != nil {
+		h.inboxListener.Stop()
+	}
 	h.worker.Stop()
 	h.enqueuer.Close()
 }
@@ -159,6 +193,23 @@ func (h *Handle) Prefs() notification.PrefsStore {
 	return h.prefs
 }
 
+// Channels returns the chat and webhook channel store, or nil when the
+// feature is unavailable.
+func (h *Handle) Channels() notification.ChannelStore {
+	if h == nil {
+		return nil
+	}
+	return h.channels
+}
+
+// Watches returns the watch store, or nil when the feature is unavailable.
+func (h *Handle) Watches() notification.WatchStore {
+	if h == nil {
+		return nil
+	}
+	return h.watches
+}
+
 // Settings returns the settings store, or nil when the feature is unavailable.
 func (h *Handle) Settings() smtp.SettingsStore {
 	if h == nil {
@@ -178,6 +229,24 @@ func (h *Handle) History() notification.HistoryStore {
 	return h.history
 }
 
+// Inbox returns the per-person inbox store, or nil when the feature is
+// unavailable.
+func (h *Handle) Inbox() notification.InboxStore {
+	if h == nil {
+		return nil
+	}
+	return h.inbox
+}
+
+// InboxWakeups returns the relay open inbox streams subscribe to, or nil when
+// the feature is unavailable.
+func (h *Handle) InboxWakeups() *notifyqueue.Fanout {
+	if h == nil {
+		return nil
+	}
+	return h.wakeups
+}
+
 // HistoryRetention is how long a resolved row survives before the worker's
 // purge removes it -- the window History can report on. Both surfaces show it
 // so a reader knows the listing is recent history, not an archive.
@@ -208,6 +277,28 @@ func (h *Handle) SendGuestLink(ctx context.Context, to, link string) error {
 	return nil
 }
 
+// SendShareCode delivers the verification code a guarded public share link
+// emails to the address its visitor entered. Like SendGuestLink it is
+// transactional and goes straight to the sender.
+func (h *Handle) SendShareCode(ctx context.Context, to, code string) error {
+	if h == nil {
+		return errors.New("notifications unavailable: no database configured")
+	}
+	settings, err := h.smtpSettings(ctx)
+	if err != nil {
+		return err
+	}
+	email, err := h.renderer.RenderShareCode(to, code)
+	if err != nil {
+		return fmt.Errorf("rendering share code email: %w", err)
+	}
+	if err := h.sender.Send(ctx, *settings, *email); err != nil {
+		slog.Error("notification: share code send failed", logKeyError, err)
+		return err //nolint:wrapcheck // sender error already carries context
+	}
+	return nil
+}
+
 // smtpSettings loads the stored SMTP settings, refusing when the feature is
 // unconfigured or disabled. Shared by the direct (non-queued) send paths.
 func (h *Handle) smtpSettings(ctx context.Context) (*smtp.Settings, error) {
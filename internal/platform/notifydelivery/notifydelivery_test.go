@@ -81,7 +81,7 @@ func TestNew_WithDB(t *testing.T) {
 	if h.Enqueuer() == nil || h.Prefs() == nil || h.Settings() == nil || h.History() == nil {
 		t.Error("accessors must be populated")
 	}
-	if h.listener != nil {
+	if h.listener != nil || h.inboxListener != nil {
 		t.Error("empty DSN must not build a listener")
 	}
 }
@@ -100,6 +100,9 @@ func TestNew_WithDSNBuildsListener(t *testing.T) {
 	if h.listener == nil {
 		t.Error("DSN must build a listener")
 	}
+	if h.inboxListener == nil {
+		t.Error("DSN must build the inbox listener")
+	}
 }
 
 func TestNilHandleAccessors(t *testing.T) {
@@ -378,3 +381,29 @@ func TestSendTest_DisabledOrUnconfigured(t *testing.T) {
 		})
 	}
 }
+
+// TestStart_InboxListenerFailsOnItsOwn keeps the send worker's wakeup when only
+// the inbox channel cannot be listened on, and stops both otherwise.
+func TestStart_InboxListenerFailsOnItsOwn(t *testing.T) {
+	worker := &fakeListener{}
+	inbox := &fakeListener{startErr: errors.New("no LISTEN privilege")}
+	h := newTestHandle(t, worker)
+	h.inboxListener = inbox
+	h.Start(context.Background())
+	if h.listener == nil || h.inboxListener != nil {
+		t.Fatal("only the failed inbox listener must be dropped")
+	}
+	h.Stop()
+	if !worker.stopped || inbox.stopped {
+		t.Error("Stop must stop the running listener and skip the dropped one")
+	}
+
+	worker, inbox = &fakeListener{}, &fakeListener{}
+	h = newTestHandle(t, worker)
+	h.inboxListener = inbox
+	h.Start(context.Background())
+	h.Stop()
+	if !inbox.started || !inbox.stopped {
+		t.Error("the inbox listener must start and stop with the handle")
+	}
+}
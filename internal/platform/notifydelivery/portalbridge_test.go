@@ -38,7 +38,7 @@ func (q *recordQueue) Enqueue(_ context.Context, n notification.Notification) er
 	return nil
 }
 
-func (*recordQueue) ClaimImmediate(context.Context, time.Duration) (*notification.Notification, error) {
+func (*recordQueue) ClaimImmediate(context.Context, time.Duration, bool) (*notification.Notification, error) {
 	return nil, notification.ErrNoWork
 }
 
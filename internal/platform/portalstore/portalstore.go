@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/txn2/mcp-data-platform/internal/platform/assetcontentindex"
 	"github.com/txn2/mcp-data-platform/internal/platform/assetindex"
 	"github.com/txn2/mcp-data-platform/internal/platform/collectionindex"
 	"github.com/txn2/mcp-data-platform/internal/platform/knowledgepageindex"
@@ -122,17 +123,20 @@ func New(db *sql.DB, s3Client portal.S3Client, embedder embedding.Provider, cfg
 	assets := indexjobs.NewProducer(assetindex.SourceKind)
 	collections := indexjobs.NewProducer(collectionindex.SourceKind)
 	pages := indexjobs.NewProducer(knowledgepageindex.SourceKind)
+	// Asset bodies change only through new versions, so the version store is
+	// the writer that enqueues the asset-content kind.
+	contents := indexjobs.NewProducer(assetcontentindex.SourceKind)
 
 	h := NewFromStores(Stores{
 		Asset:         portal.NewPostgresAssetStore(db, indexjobs.WithProducer(assets)),
 		Share:         portal.NewPostgresShareStore(db),
-		Version:       portal.NewPostgresVersionStore(db, s3Client, cfg.MaxVersions),
+		Version:       portal.NewPostgresVersionStore(db, s3Client, cfg.MaxVersions, indexjobs.WithProducer(contents)),
 		Collection:    portal.NewPostgresCollectionStore(db, indexjobs.WithProducer(collections)),
 		Thread:        portal.NewPostgresThreadStore(db),
 		KnowledgePage: knowledgepage.NewPostgresStore(db, indexjobs.WithProducer(pages)),
 		S3Client:      s3Client,
 	}, embedder, cfg)
-	h.indexProducers = []*indexjobs.Producer{assets, collections, pages}
+	h.indexProducers = []*indexjobs.Producer{assets, collections, pages, contents}
 	h.notices = notices.New(db, h.assetStore, h.shareStore, h.threadStore)
 	return h
 }
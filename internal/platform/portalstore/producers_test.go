@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/txn2/mcp-data-platform/internal/platform/assetcontentindex"
 	"github.com/txn2/mcp-data-platform/internal/platform/assetindex"
 	"github.com/txn2/mcp-data-platform/internal/platform/collectionindex"
 	"github.com/txn2/mcp-data-platform/internal/platform/knowledgepageindex"
@@ -27,8 +28,8 @@ func (r *recordingEnqueuer) Enqueue(_ context.Context, key indexjobs.Key, _ inde
 }
 
 // TestIndexProducersCoverEveryIndexedKind pins the set the queue binds: one
-// producer per indexed portal kind, and nothing for the stores that carry no
-// index (shares, versions, threads).
+// producer per indexed portal kind — asset content through the version store —
+// and nothing for the stores that carry no index (shares, threads).
 func TestIndexProducersCoverEveryIndexedKind(t *testing.T) {
 	t.Parallel()
 	db, _, err := sqlmock.New()
@@ -38,12 +39,13 @@ func TestIndexProducersCoverEveryIndexedKind(t *testing.T) {
 	h := New(db, nil, nil, Config{Name: "portal"})
 	require.NotNil(t, h)
 
-	kinds := make([]string, 0, 3)
+	kinds := make([]string, 0, 4)
 	for _, p := range h.IndexProducers() {
 		kinds = append(kinds, p.Kind())
 	}
 	assert.ElementsMatch(t,
-		[]string{assetindex.SourceKind, collectionindex.SourceKind, knowledgepageindex.SourceKind},
+		[]string{assetindex.SourceKind, collectionindex.SourceKind, knowledgepageindex.SourceKind,
+			assetcontentindex.SourceKind},
 		kinds)
 }
 
@@ -0,0 +1,352 @@
+// Package scriptcheck builds the data-quality checks a managed script asserts:
+// the one query that measures each, and the verdict read from its answer.
+//
+// It holds no Starlark and issues no calls. The platform.assert_* bindings in
+// scriptrun unpack the author's arguments, hand them here, send the query over
+// the run's session like any other, and pass the rows back to be judged, so
+// what a check measures and how it is judged can be read and tested without
+// an interpreter or a warehouse.
+package scriptcheck
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+// Check kinds, recorded as QualityCheck.Check.
+const (
+	KindRowCount = "row_count"
+	KindNullRate = "null_rate"
+	KindFresh    = "freshness"
+	KindUnique   = "uniqueness"
+	KindEmpty    = "empty"
+)
+
+// EmptySampleRows is how many offending rows an empty check reads back. One is
+// enough to fail it; the rest are there so the author sees what kind of row
+// broke the rule.
+const EmptySampleRows = 10
+
+// maxTableParts is catalog.schema.table.
+const maxTableParts = 3
+
+// Check is one assertion ready to issue.
+type Check struct {
+	// SQL measures the check, and Limit is the row limit it is sent with.
+	SQL   string
+	Limit int
+	// Record is the check as it will be reported, with Observed and Passed
+	// left for Judge.
+	Record script.QualityCheck
+
+	judge func(rows []any, c *script.QualityCheck) error
+}
+
+// Judge reads the query's rows into the check's verdict. An error is a result
+// it cannot read, which is a failure to measure rather than a failed check.
+func (c Check) Judge(rows []any) (script.QualityCheck, error) {
+	out := c.Record
+	if err := c.judge(rows, &out); err != nil {
+		return script.QualityCheck{}, err
+	}
+	return out, nil
+}
+
+// Source locates the table a check reads: its name as the author wrote it,
+// and a filter already bound by the caller, exactly as a query statement is.
+type Source struct {
+	Table string
+	Where string
+}
+
+// from renders the FROM clause: the table quoted part by part, then the filter.
+//
+// The table is quoted because it is a name, and Trino binds no names: quoting
+// is what keeps a table argument computed from run.params a table rather than
+// a statement.
+func (s Source) from() (string, error) {
+	parts := strings.Split(s.Table, ".")
+	if len(parts) > maxTableParts {
+		return "", fmt.Errorf("table %q has more than three parts; name it as catalog.schema.table", s.Table)
+	}
+	quoted := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if strings.TrimSpace(part) == "" {
+			return "", fmt.Errorf("table %q has an empty part; name it as catalog.schema.table, schema.table, or table", s.Table)
+		}
+		quoted = append(quoted, QuoteIdent(part))
+	}
+	from := strings.Join(quoted, ".")
+	if strings.TrimSpace(s.Where) != "" {
+		from += " WHERE " + s.Where
+	}
+	return from, nil
+}
+
+// RowCount checks that the table's row count lies within the bounds given. A
+// nil bound is open.
+func RowCount(src Source, minRows, maxRows *int64) (Check, error) {
+	if minRows == nil && maxRows == nil {
+		return Check{}, errors.New("give min, max, or both; a row count with no bound asserts nothing")
+	}
+	for _, bound := range []*int64{minRows, maxRows} {
+		if bound != nil && *bound < 0 {
+			return Check{}, fmt.Errorf("a row bound must not be negative, got %d", *bound)
+		}
+	}
+	if minRows != nil && maxRows != nil && *minRows > *maxRows {
+		return Check{}, fmt.Errorf("min %d is above max %d, so no row count could pass", *minRows, *maxRows)
+	}
+	from, err := src.from()
+	if err != nil {
+		return Check{}, err
+	}
+	return Check{
+		SQL: "SELECT COUNT(*) AS row_count FROM " + from, Limit: 1,
+		Record: script.QualityCheck{Check: KindRowCount, Table: src.Table, Expected: countBounds(minRows, maxRows)},
+		judge: func(rows []any, c *script.QualityCheck) error {
+			n, err := firstNumber(rows, "row_count")
+			if err != nil {
+				return err
+			}
+			count := int64(n)
+			c.Observed = fmt.Sprintf("%d rows", count)
+			c.Passed = (minRows == nil || count >= *minRows) && (maxRows == nil || count <= *maxRows)
+			return nil
+		},
+	}, nil
+}
+
+// NullRate checks that the share of rows in which column is NULL is at most
+// maxRate, a fraction between 0 and 1.
+func NullRate(src Source, column string, maxRate float64) (Check, error) {
+	if maxRate < 0 || maxRate > 1 {
+		return Check{}, fmt.Errorf("max_rate must be a fraction between 0 and 1, got %v", maxRate)
+	}
+	from, col, err := sourceAndColumn(src, column)
+	if err != nil {
+		return Check{}, err
+	}
+	return Check{
+		SQL: "SELECT COUNT(*) AS total, COUNT_IF(" + col + " IS NULL) AS nulls FROM " + from, Limit: 1,
+		Record: script.QualityCheck{
+			Check: KindNullRate, Table: src.Table, Columns: []string{column},
+			Expected: "at most " + percent(maxRate) + " null",
+		},
+		judge: func(rows []any, c *script.QualityCheck) error {
+			total, err := firstNumber(rows, "total")
+			if err != nil {
+				return err
+			}
+			nulls, err := firstNumber(rows, "nulls")
+			if err != nil {
+				return err
+			}
+			// An empty table has no null rate to exceed. Whether it should be
+			// empty is the row count's question.
+			rate := 0.0
+			if total > 0 {
+				rate = nulls / total
+			}
+			c.Observed = fmt.Sprintf("%s null (%d of %d rows)", percent(rate), int64(nulls), int64(total))
+			c.Passed = rate <= maxRate
+			return nil
+		},
+	}, nil
+}
+
+// Fresh checks that the newest value of a timestamp column is at most maxAge
+// older than fire.
+//
+// Age is measured against the run's fire time, never a clock, for the reason
+// every other computation in a script is: a run re-executed to explain what it
+// said must reach the same verdict it reached the first time. A table with no
+// rows has no newest value and fails.
+func Fresh(src Source, column string, maxAge time.Duration, fire time.Time) (Check, error) {
+	if maxAge <= 0 {
+		return Check{}, fmt.Errorf("max_age_hours must be positive, got %v", maxAge.Hours())
+	}
+	from, col, err := sourceAndColumn(src, column)
+	if err != nil {
+		return Check{}, err
+	}
+	at := "from_iso8601_timestamp('" + fire.UTC().Format(time.RFC3339) + "')"
+	return Check{
+		SQL: "SELECT CAST(MAX(" + col + ") AS VARCHAR) AS latest, date_diff('second', CAST(MAX(" + col +
+			") AS TIMESTAMP(6) WITH TIME ZONE), " + at + ") AS age_seconds FROM " + from,
+		Limit: 1,
+		Record: script.QualityCheck{
+			Check: KindFresh, Table: src.Table, Columns: []string{column},
+			Expected: "newest value at most " + hours(maxAge) + " before the fire time",
+		},
+		judge: func(rows []any, c *script.QualityCheck) error {
+			row, err := firstRow(rows)
+			if err != nil {
+				return err
+			}
+			if row["latest"] == nil {
+				c.Observed = "no rows"
+				return nil
+			}
+			age, ok := number(row["age_seconds"])
+			if !ok {
+				return fmt.Errorf("the age_seconds column is %T, not a number", row["age_seconds"])
+			}
+			observed := time.Duration(age) * time.Second
+			c.Observed = fmt.Sprintf("newest value %v, %s before the fire time", row["latest"], hours(observed))
+			c.Passed = observed <= maxAge
+			return nil
+		},
+	}, nil
+}
+
+// Unique checks that no two rows share a value of the columns taken together.
+func Unique(src Source, columns []string) (Check, error) {
+	if len(columns) == 0 {
+		return Check{}, errors.New("columns is empty; name the column or columns that must be unique")
+	}
+	from, err := src.from()
+	if err != nil {
+		return Check{}, err
+	}
+	quoted := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if strings.TrimSpace(column) == "" {
+			return Check{}, errors.New("a column name is empty")
+		}
+		quoted = append(quoted, QuoteIdent(column))
+	}
+	key := strings.Join(quoted, ", ")
+	return Check{
+		SQL: "SELECT COUNT(*) AS duplicates FROM (SELECT " + key + " FROM " + from +
+			" GROUP BY " + key + " HAVING COUNT(*) > 1)",
+		Limit:  1,
+		Record: script.QualityCheck{Check: KindUnique, Table: src.Table, Columns: columns, Expected: "no duplicated keys"},
+		judge: func(rows []any, c *script.QualityCheck) error {
+			n, err := firstNumber(rows, "duplicates")
+			if err != nil {
+				return err
+			}
+			c.Observed = fmt.Sprintf("%d duplicated keys", int64(n))
+			c.Passed = n == 0
+			return nil
+		},
+	}, nil
+}
+
+// Empty checks that a statement the author wrote returns no rows. It is the
+// general form of every other check — select the rows that break the rule, and
+// expect none — for the rules the named ones do not cover.
+func Empty(sql string) (Check, error) {
+	if strings.TrimSpace(sql) == "" {
+		return Check{}, errors.New("sql is empty; select the rows that break the rule")
+	}
+	return Check{
+		SQL: sql, Limit: EmptySampleRows,
+		Record: script.QualityCheck{Check: KindEmpty, Expected: "no rows"},
+		judge: func(rows []any, c *script.QualityCheck) error {
+			switch {
+			case len(rows) == 0:
+				c.Observed = "no rows"
+			case len(rows) >= EmptySampleRows:
+				c.Observed = fmt.Sprintf("at least %d rows", len(rows))
+			default:
+				c.Observed = fmt.Sprintf("%d rows", len(rows))
+			}
+			c.Passed = len(rows) == 0
+			return nil
+		},
+	}, nil
+}
+
+// QuoteIdent renders a name as a Trino delimited identifier. Doubling the quote
+// is what keeps a name a name.
+func QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// sourceAndColumn renders the FROM clause and one quoted column.
+func sourceAndColumn(src Source, column string) (from, col string, err error) {
+	if strings.TrimSpace(column) == "" {
+		return "", "", errors.New("column is empty")
+	}
+	from, err = src.from()
+	if err != nil {
+		return "", "", err
+	}
+	return from, QuoteIdent(column), nil
+}
+
+// countBounds renders a row-count check's expectation.
+func countBounds(minRows, maxRows *int64) string {
+	switch {
+	case minRows != nil && maxRows != nil:
+		return fmt.Sprintf("between %d and %d rows", *minRows, *maxRows)
+	case minRows != nil:
+		return fmt.Sprintf("at least %d rows", *minRows)
+	default:
+		return fmt.Sprintf("at most %d rows", *maxRows)
+	}
+}
+
+// percent renders a fraction as a percentage.
+func percent(rate float64) string {
+	return strconv.FormatFloat(rate*100, 'f', 2, 64) + "%"
+}
+
+// hours renders a duration in hours, to the tenth.
+func hours(d time.Duration) string {
+	return strconv.FormatFloat(d.Hours(), 'f', -1, 64) + "h"
+}
+
+// firstRow returns the single row an aggregate check reads.
+func firstRow(rows []any) (map[string]any, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("the query returned no rows")
+	}
+	row, ok := rows[0].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("the row is %T, not a row dict", rows[0])
+	}
+	return row, nil
+}
+
+// firstNumber reads one numeric column of the single row an aggregate check
+// reads.
+func firstNumber(rows []any, column string) (float64, error) {
+	row, err := firstRow(rows)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := number(row[column])
+	if !ok {
+		return 0, fmt.Errorf("the %s column is %T, not a number", column, row[column])
+	}
+	return n, nil
+}
+
+// number reads a numeric value in any of the shapes a tool result carries one:
+// a JSON number, a Go integer, or a decimal the engine rendered as text.
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,162 @@
+package scriptcheck
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// row is one aggregate result row as the query tool returns it.
+func row(kv ...any) []any {
+	out := map[string]any{}
+	for i := 0; i+1 < len(kv); i += 2 {
+		key, _ := kv[i].(string)
+		out[key] = kv[i+1]
+	}
+	return []any{out}
+}
+
+func bound(n int64) *int64 { return &n }
+
+func TestRowCount(t *testing.T) {
+	c, err := RowCount(Source{Table: "hive.sales.orders", Where: "ds = '2026-08-12'"}, bound(10), nil)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT COUNT(*) AS row_count FROM "hive"."sales"."orders" WHERE ds = '2026-08-12'`, c.SQL)
+	assert.Equal(t, 1, c.Limit)
+
+	got, err := c.Judge(row("row_count", float64(3)))
+	require.NoError(t, err)
+	assert.False(t, got.Passed)
+	assert.Equal(t, "3 rows", got.Observed)
+	assert.Equal(t, "at least 10 rows", got.Expected)
+
+	c, err = RowCount(Source{Table: "orders"}, bound(1), bound(100))
+	require.NoError(t, err)
+	got, err = c.Judge(row("row_count", "42"))
+	require.NoError(t, err, "a count the engine rendered as text is still a count")
+	assert.True(t, got.Passed)
+	assert.Equal(t, "between 1 and 100 rows", got.Expected)
+}
+
+func TestRowCount_Refusals(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      Source
+		min, max *int64
+		wantErr  string
+	}{
+		{"no bound", Source{Table: "t"}, nil, nil, "asserts nothing"},
+		{"negative", Source{Table: "t"}, bound(-1), nil, "must not be negative"},
+		{"inverted", Source{Table: "t"}, bound(5), bound(1), "no row count could pass"},
+		{"four parts", Source{Table: "a.b.c.d"}, bound(1), nil, "more than three parts"},
+		{"empty part", Source{Table: "hive..orders"}, bound(1), nil, "empty part"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RowCount(tt.src, tt.min, tt.max)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+// TestNames_AreQuotedNotSpliced is the security-relevant one: a table or column
+// computed from run.params stays a name.
+func TestNames_AreQuotedNotSpliced(t *testing.T) {
+	c, err := NullRate(Source{Table: `orders"; DROP TABLE x; --`}, `id" IS NULL) --`, 0.1)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT COUNT(*) AS total, COUNT_IF("id"" IS NULL) --" IS NULL) AS nulls FROM "orders""; DROP TABLE x; --"`, c.SQL)
+}
+
+func TestNullRate(t *testing.T) {
+	c, err := NullRate(Source{Table: "orders"}, "customer_id", 0.01)
+	require.NoError(t, err)
+
+	got, err := c.Judge(row("total", float64(1000), "nulls", float64(25)))
+	require.NoError(t, err)
+	assert.False(t, got.Passed)
+	assert.Equal(t, "2.50% null (25 of 1000 rows)", got.Observed)
+	assert.Equal(t, "at most 1.00% null", got.Expected)
+
+	got, err = c.Judge(row("total", float64(0), "nulls", float64(0)))
+	require.NoError(t, err)
+	assert.True(t, got.Passed, "an empty table has no null rate to exceed")
+
+	_, err = NullRate(Source{Table: "orders"}, "customer_id", 1.5)
+	require.ErrorContains(t, err, "between 0 and 1")
+	_, err = NullRate(Source{Table: "orders"}, " ", 0.1)
+	require.ErrorContains(t, err, "column is empty")
+}
+
+// TestFresh_MeasuresAgainstTheFireTime pins that age is computed from the
+// run's pinned instant, so a re-run reaches the same verdict.
+func TestFresh_MeasuresAgainstTheFireTime(t *testing.T) {
+	fire := time.Date(2026, 8, 13, 7, 30, 0, 0, time.UTC)
+	c, err := Fresh(Source{Table: "orders"}, "loaded_at", 24*time.Hour, fire)
+	require.NoError(t, err)
+	assert.Contains(t, c.SQL, "from_iso8601_timestamp('2026-08-13T07:30:00Z')")
+	assert.Contains(t, c.SQL, `MAX("loaded_at")`)
+
+	got, err := c.Judge(row("latest", "2026-08-12 01:30:00.000", "age_seconds", json.Number("108000")))
+	require.NoError(t, err)
+	assert.False(t, got.Passed)
+	assert.Equal(t, "newest value 2026-08-12 01:30:00.000, 30h before the fire time", got.Observed)
+	assert.Equal(t, "newest value at most 24h before the fire time", got.Expected)
+
+	got, err = c.Judge(row("latest", nil, "age_seconds", nil))
+	require.NoError(t, err)
+	assert.False(t, got.Passed, "a table with no rows has nothing fresh in it")
+	assert.Equal(t, "no rows", got.Observed)
+
+	_, err = Fresh(Source{Table: "orders"}, "loaded_at", 0, fire)
+	require.ErrorContains(t, err, "must be positive")
+}
+
+func TestUnique(t *testing.T) {
+	c, err := Unique(Source{Table: "sales.orders"}, []string{"order_id", "line"})
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT COUNT(*) AS duplicates FROM (SELECT "order_id", "line" FROM "sales"."orders" GROUP BY "order_id", "line" HAVING COUNT(*) > 1)`, c.SQL)
+
+	got, err := c.Judge(row("duplicates", float64(3)))
+	require.NoError(t, err)
+	assert.False(t, got.Passed)
+	assert.Equal(t, "3 duplicated keys", got.Observed)
+
+	_, err = Unique(Source{Table: "orders"}, nil)
+	require.ErrorContains(t, err, "columns is empty")
+}
+
+func TestEmpty(t *testing.T) {
+	c, err := Empty("SELECT * FROM orders WHERE total < 0")
+	require.NoError(t, err)
+	assert.Equal(t, EmptySampleRows, c.Limit, "a failing check reads back a sample of what broke it")
+
+	got, err := c.Judge([]any{})
+	require.NoError(t, err)
+	assert.True(t, got.Passed)
+
+	got, err = c.Judge(make([]any, EmptySampleRows))
+	require.NoError(t, err)
+	assert.False(t, got.Passed)
+	assert.Equal(t, "at least 10 rows", got.Observed, "a full sample says there may be more")
+
+	_, err = Empty("  ")
+	require.ErrorContains(t, err, "sql is empty")
+}
+
+// TestJudge_AnUnreadableResultIsAnError pins that a result the check cannot
+// read fails to measure rather than passing or failing the check.
+func TestJudge_AnUnreadableResultIsAnError(t *testing.T) {
+	c, err := RowCount(Source{Table: "orders"}, bound(1), nil)
+	require.NoError(t, err)
+
+	_, err = c.Judge([]any{})
+	require.ErrorContains(t, err, "returned no rows")
+	_, err = c.Judge(row("row_count", true))
+	require.ErrorContains(t, err, "not a number")
+	_, err = c.Judge([]any{"not a row"})
+	require.ErrorContains(t, err, "not a row dict")
+}
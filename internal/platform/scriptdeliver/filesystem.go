@@ -0,0 +1,62 @@
+package scriptdeliver
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path"
+)
+
+// filePerm and dirPerm are what a delivery creates with. Group-readable,
+// because the consumer of a mounted drop is rarely the worker's own user.
+const (
+	filePerm = 0o640
+	dirPerm  = 0o750
+)
+
+// WriteFile writes data at key beneath root, creating the directories between.
+//
+// The root is opened as an os.Root, so the confinement ValidateObjectKey gives
+// a key on paper also holds on disk: a symlink inside the volume that points
+// out of it is refused by the kernel walk, not followed. The root itself is not
+// created; a missing directory means the volume is not mounted, and writing
+// into the container's own filesystem instead would report a delivery nobody
+// can read.
+//
+// The file is written beside its final name and renamed over it, so a consumer
+// watching the directory never reads a partial file.
+func WriteFile(root, key string, data []byte) (err error) {
+	r, err := os.OpenRoot(root)
+	if err != nil {
+		return fmt.Errorf("opening the destination directory %s: %w", root, err)
+	}
+	defer func() { _ = r.Close() }()
+
+	dir, name := path.Split(key)
+	if dir != "" {
+		if err := r.MkdirAll(dir, dirPerm); err != nil {
+			return fmt.Errorf("creating %s under %s: %w", dir, root, err)
+		}
+	}
+	tmp := path.Join(dir, "."+name+"."+rand.Text()+".tmp")
+	f, err := r.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, filePerm)
+	if err != nil {
+		return fmt.Errorf("creating %s under %s: %w", key, root, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = r.Remove(tmp)
+		}
+	}()
+	_, werr := f.Write(data)
+	if cerr := f.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr != nil {
+		return fmt.Errorf("writing %s under %s: %w", key, root, werr)
+	}
+	if err := r.Rename(tmp, key); err != nil {
+		return fmt.Errorf("placing %s under %s: %w", key, root, err)
+	}
+	return nil
+}
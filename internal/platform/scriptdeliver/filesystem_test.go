@@ -0,0 +1,52 @@
+package scriptdeliver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile_CreatesTheDirectoriesBetween(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, WriteFile(root, "weekly/2026/sales.csv", []byte("a,b\n")))
+
+	got, err := os.ReadFile(filepath.Join(root, "weekly", "2026", "sales.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "a,b\n", string(got))
+
+	entries, err := os.ReadDir(filepath.Join(root, "weekly", "2026"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the temporary file is renamed, never left beside the output")
+}
+
+func TestWriteFile_ReplacesWhatWasThere(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, WriteFile(root, "sales.csv", []byte("first")))
+	require.NoError(t, WriteFile(root, "sales.csv", []byte("second")))
+
+	got, err := os.ReadFile(filepath.Join(root, "sales.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(got))
+}
+
+// TestWriteFile_ASymlinkOutOfTheVolumeIsNotFollowed is the confinement a key
+// check cannot give on its own: the key is clean, and the directory it names
+// points somewhere else.
+func TestWriteFile_ASymlinkOutOfTheVolumeIsNotFollowed(t *testing.T) {
+	root, outside := t.TempDir(), t.TempDir()
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "escape")))
+
+	require.Error(t, WriteFile(root, "escape/sales.csv", []byte("x")))
+	_, err := os.Stat(filepath.Join(outside, "sales.csv"))
+	assert.True(t, os.IsNotExist(err), "nothing is written outside the root")
+}
+
+func TestWriteFile_AMissingRootIsNotCreated(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "not-mounted")
+	require.Error(t, WriteFile(root, "sales.csv", []byte("x")))
+	_, err := os.Stat(root)
+	assert.True(t, os.IsNotExist(err))
+}
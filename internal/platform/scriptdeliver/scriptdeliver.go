@@ -0,0 +1,127 @@
+// Package scriptdeliver writes a managed script's output to the destinations
+// the platform reaches itself rather than through a platform tool: a directory
+// on an SFTP server, a directory on a volume mounted into the worker, and a
+// mailbox.
+//
+// It decides nothing about whether a write may happen. The caller
+// (internal/platform/scriptexec) has already resolved the destination from
+// configuration, validated the key, and authorized the run against the
+// destination's capability; what remains here is the transport, and the one
+// rule every transport re-applies however it was called: a key lands beneath
+// the destination's directory and nowhere else.
+package scriptdeliver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/internal/notification/notifysend"
+	"github.com/txn2/mcp-data-platform/pkg/notification/smtp"
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+// DefaultTimeout bounds one SFTP delivery, from dialing to closing the file,
+// when the context carries no earlier deadline.
+const DefaultTimeout = 2 * time.Minute
+
+// File is one output on its way out: the bytes a formatter produced and the
+// key they are written under, the destination's prefix already applied.
+type File struct {
+	Key         string
+	ContentType string
+	Data        []byte
+
+	// Subject and Body describe the delivery where a person reads it, which is
+	// only an email destination: the message's subject line and its text.
+	Subject string
+	Body    string
+}
+
+// Mailer sends one attachment over the admin SMTP settings. It is the
+// attachment half of notifysend.SMTPSender, narrowed so the email destination
+// is testable without a mail server.
+type Mailer interface {
+	SendAttachment(ctx context.Context, settings smtp.Settings, a notifysend.Attachment) error
+}
+
+// Config carries what the transports need.
+type Config struct {
+	// Settings is the admin-configured SMTP connection an email destination
+	// sends over. Nil refuses every email delivery.
+	Settings smtp.SettingsStore
+
+	// Mailer overrides the SMTP sender. Nil uses notifysend's.
+	Mailer Mailer
+
+	// Timeout overrides DefaultTimeout.
+	Timeout time.Duration
+}
+
+// Deliverer writes files to sftp, filesystem, and email destinations.
+type Deliverer struct {
+	settings smtp.SettingsStore
+	mailer   Mailer
+	timeout  time.Duration
+}
+
+// New builds a Deliverer.
+func New(cfg Config) *Deliverer {
+	d := &Deliverer{settings: cfg.Settings, mailer: cfg.Mailer, timeout: cfg.Timeout}
+	if d.mailer == nil {
+		d.mailer = notifysend.NewSMTPSender()
+	}
+	if d.timeout <= 0 {
+		d.timeout = DefaultTimeout
+	}
+	return d
+}
+
+// Deliver writes f to the destination. A kind this package does not write —
+// the portal, or a bucket, which is a platform tool call — is refused rather
+// than guessed at.
+func (d *Deliverer) Deliver(ctx context.Context, dest script.Destination, f File) error {
+	if err := script.ValidateObjectKey(f.Key); err != nil {
+		return fmt.Errorf("key %q cannot be written to %s: %w", f.Key, dest.Name, err)
+	}
+	switch dest.Kind {
+	case script.DestinationKindFilesystem:
+		return WriteFile(dest.Path, f.Key, f.Data)
+	case script.DestinationKindSFTP:
+		ctx, cancel := context.WithTimeout(ctx, d.timeout)
+		defer cancel()
+		return putSFTP(ctx, dest, f.Key, f.Data)
+	case script.DestinationKindEmail:
+		return d.mail(ctx, dest, f)
+	default:
+		return fmt.Errorf("destination %q is of kind %q, which this package does not write", dest.Name, dest.Kind)
+	}
+}
+
+// mail sends f as one attachment to the destination's recipients, over the
+// SMTP settings notifications use. A deployment that has not configured or has
+// turned off mail refuses here, where the run records it, rather than queueing
+// an attachment nothing will send.
+func (d *Deliverer) mail(ctx context.Context, dest script.Destination, f File) error {
+	if d.settings == nil {
+		return fmt.Errorf("destination %q is an email destination, and this deployment has no mail settings: %w",
+			dest.Name, smtp.ErrNotConfigured)
+	}
+	settings, err := d.settings.Get(ctx)
+	if errors.Is(err, smtp.ErrNotFound) || (err == nil && !settings.Enabled) {
+		return fmt.Errorf("destination %q is an email destination: %w", dest.Name, smtp.ErrNotConfigured)
+	}
+	if err != nil {
+		return fmt.Errorf("reading the mail settings: %w", err)
+	}
+	err = d.mailer.SendAttachment(ctx, *settings, notifysend.Attachment{
+		To: dest.To, Subject: f.Subject, Text: f.Body,
+		Filename: path.Base(f.Key), ContentType: f.ContentType, Data: f.Data,
+	})
+	if err != nil {
+		return fmt.Errorf("mailing to %s: %w", dest.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package scriptdeliver
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/internal/notification/notifysend"
+	"github.com/txn2/mcp-data-platform/pkg/notification/smtp"
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+type fakeSettings struct {
+	settings *smtp.Settings
+	err      error
+}
+
+func (f *fakeSettings) Get(context.Context) (*smtp.Settings, error) { return f.settings, f.err }
+
+func (*fakeSettings) Set(context.Context, smtp.Settings, string) error { return nil }
+
+type fakeMailer struct {
+	sent []notifysend.Attachment
+	err  error
+}
+
+func (f *fakeMailer) SendAttachment(_ context.Context, _ smtp.Settings, a notifysend.Attachment) error {
+	f.sent = append(f.sent, a)
+	return f.err
+}
+
+var finance = script.Destination{
+	Name: "finance", Kind: script.DestinationKindEmail, To: []string{"finance@acme.example"},
+}
+
+func TestDeliver_EmailAttachesTheFileUnderItsBaseName(t *testing.T) {
+	mailer := &fakeMailer{}
+	d := New(Config{Settings: &fakeSettings{settings: &smtp.Settings{Enabled: true}}, Mailer: mailer})
+
+	err := d.Deliver(context.Background(), finance, File{
+		Key: "weekly/sales.csv", ContentType: "text/csv", Data: []byte("a\n"), Subject: "weekly-report: sales",
+	})
+	require.NoError(t, err)
+	require.Len(t, mailer.sent, 1)
+	assert.Equal(t, "sales.csv", mailer.sent[0].Filename)
+	assert.Equal(t, []string{"finance@acme.example"}, mailer.sent[0].To)
+	assert.Equal(t, "weekly-report: sales", mailer.sent[0].Subject)
+}
+
+func TestDeliver_EmailRefusesWithoutWorkingMailSettings(t *testing.T) {
+	tests := map[string]*Deliverer{
+		"no store":       New(Config{Mailer: &fakeMailer{}}),
+		"never set":      New(Config{Settings: &fakeSettings{err: smtp.ErrNotFound}, Mailer: &fakeMailer{}}),
+		"turned off":     New(Config{Settings: &fakeSettings{settings: &smtp.Settings{}}, Mailer: &fakeMailer{}}),
+		"store failures": New(Config{Settings: &fakeSettings{err: errors.New("boom")}, Mailer: &fakeMailer{}}),
+	}
+	for name, d := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := d.Deliver(context.Background(), finance, File{Key: "sales.csv"})
+			require.Error(t, err)
+			if name != "store failures" {
+				require.ErrorIs(t, err, smtp.ErrNotConfigured)
+			}
+		})
+	}
+}
+
+func TestDeliver_FilesystemWritesBeneathThePath(t *testing.T) {
+	root := t.TempDir()
+	dest := script.Destination{Name: "nfs", Kind: script.DestinationKindFilesystem, Path: root}
+	require.NoError(t, New(Config{}).Deliver(context.Background(), dest, File{Key: "weekly/sales.csv", Data: []byte("x")}))
+
+	got, err := os.ReadFile(filepath.Join(root, "weekly", "sales.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "x", string(got))
+}
+
+// TestDeliver_TheKeyIsCheckedAgain: the caller validated it, and this is the
+// last thing between a key and a disk.
+func TestDeliver_TheKeyIsCheckedAgain(t *testing.T) {
+	root := t.TempDir()
+	dest := script.Destination{Name: "nfs", Kind: script.DestinationKindFilesystem, Path: root}
+	err := New(Config{}).Deliver(context.Background(), dest, File{Key: "../etc/passwd", Data: []byte("x")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be written")
+}
+
+func TestDeliver_RefusesAKindItDoesNotWrite(t *testing.T) {
+	err := New(Config{}).Deliver(context.Background(), script.Destination{Name: "drop", Kind: script.DestinationKindS3}, File{Key: "a.csv"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not write")
+}
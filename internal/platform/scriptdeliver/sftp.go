@@ -0,0 +1,152 @@
+package scriptdeliver
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+const (
+	defaultSFTPPort = "22"
+
+	// posixRename is the OpenSSH extension that renames over an existing
+	// file. Plain SSH_FXP_RENAME refuses when the target exists.
+	posixRename = "posix-rename@openssh.com"
+)
+
+// putSFTP writes data at key beneath the destination's directory.
+//
+// Like WriteFile, the file is written beside its final name and renamed over
+// it, so a consumer polling the drop never picks up a partial file, and a
+// delivery that fails part way leaves nothing under the name it was meant for.
+func putSFTP(ctx context.Context, dest script.Destination, key string, data []byte) (err error) {
+	conn, err := dialSSH(ctx, dest)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		return fmt.Errorf("starting sftp on %s: %w", dest.Host, err)
+	}
+	defer func() { _ = client.Close() }()
+
+	dir, name := path.Split(key)
+	// Each directory between the root and the file is created in turn, and a
+	// failure is not checked: "already exists" is the common answer. A
+	// directory that really is missing fails the create below, which names
+	// the file. The root itself is never created, as with WriteFile.
+	current := dest.Path
+	for _, part := range strings.Split(strings.Trim(dir, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		current = path.Join(current, part)
+		_ = client.Mkdir(current)
+	}
+
+	target := path.Join(dest.Path, key)
+	tmp := path.Join(dest.Path, dir, "."+name+"."+rand.Text()+".tmp")
+	if err := writeSFTPFile(client, tmp, data); err != nil {
+		_ = client.Remove(tmp)
+		return fmt.Errorf("writing %s on %s: %w", target, dest.Host, err)
+	}
+	if err := renameSFTP(client, tmp, target); err != nil {
+		_ = client.Remove(tmp)
+		return fmt.Errorf("placing %s on %s: %w", target, dest.Host, err)
+	}
+	return nil
+}
+
+// writeSFTPFile creates the file at name, which must not exist, and writes
+// data to it.
+func writeSFTPFile(client *sftp.Client, name string, data []byte) error {
+	f, err := client.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+	if err != nil {
+		return err
+	}
+	_, werr := f.Write(data)
+	// A close can be where the server reports the write failing, so its
+	// status is the delivery's.
+	if cerr := f.Close(); werr == nil {
+		werr = cerr
+	}
+	return werr
+}
+
+// renameSFTP moves tmp over target. Without the posix-rename extension the
+// old file is removed first, which leaves a moment with no file at target
+// but never a partial one.
+func renameSFTP(client *sftp.Client, tmp, target string) error {
+	if _, ok := client.HasExtension(posixRename); ok {
+		return client.PosixRename(tmp, target)
+	}
+	_ = client.Remove(target)
+	return client.Rename(tmp, target)
+}
+
+// dialSSH connects and authenticates as the destination's user, accepting
+// only the host key the destination declares.
+func dialSSH(ctx context.Context, dest script.Destination) (*ssh.Client, error) {
+	config, err := sshConfig(dest)
+	if err != nil {
+		return nil, err
+	}
+	addr := dest.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, defaultSFTPPort)
+	}
+	var dialer net.Dialer
+	raw, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", addr, err)
+	}
+	// The deadline covers the whole delivery, not only the handshake: a server
+	// that accepts the connection and then stalls must not hold a run forever.
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = raw.SetDeadline(deadline)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(raw, addr, config)
+	if err != nil {
+		_ = raw.Close()
+		return nil, fmt.Errorf("opening an ssh session with %s: %w", addr, err)
+	}
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+// sshConfig builds the client configuration from the destination: its one
+// credential, and its host key pinned.
+func sshConfig(dest script.Destination) (*ssh.ClientConfig, error) {
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(dest.HostKey))
+	if err != nil {
+		return nil, fmt.Errorf("destination %q has an unusable host_key: %w", dest.Name, err)
+	}
+	var auth ssh.AuthMethod
+	if dest.PrivateKeyFile != "" {
+		pem, err := os.ReadFile(dest.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading destination %q's private key: %w", dest.Name, err)
+		}
+		signer, err := ssh.ParsePrivateKey(pem)
+		if err != nil {
+			return nil, fmt.Errorf("destination %q has an unusable private key: %w", dest.Name, err)
+		}
+		auth = ssh.PublicKeys(signer)
+	} else {
+		auth = ssh.Password(dest.Password)
+	}
+	return &ssh.ClientConfig{
+		User:            dest.User,
+		Auth:            []ssh.AuthMethod{auth},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+	}, nil
+}
@@ -0,0 +1,201 @@
+package scriptdeliver
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+// testSFTPServer is an SSH server on loopback that serves SFTP, with a
+// directory standing in for the destination's filesystem.
+type testSFTPServer struct {
+	addr    string
+	hostKey string
+	root    string
+}
+
+// startSFTPServer starts a server accepting the password "secret" for "drop",
+// or the given client key when it is set.
+func startSFTPServer(t *testing.T, clientKey ssh.PublicKey) *testSFTPServer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(priv)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if meta.User() == "drop" && string(password) == "secret" {
+				return &ssh.Permissions{}, nil
+			}
+			return nil, assert.AnError
+		},
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if clientKey != nil && string(key.Marshal()) == string(clientKey.Marshal()) {
+				return &ssh.Permissions{}, nil
+			}
+			return nil, assert.AnError
+		},
+	}
+	config.AddHostKey(signer)
+
+	ln, err := (&net.ListenConfig{}).Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+	s := &testSFTPServer{
+		addr:    ln.Addr().String(),
+		hostKey: strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))),
+		root:    t.TempDir(),
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serveConn(conn, config)
+		}
+	}()
+	return s
+}
+
+func (s *testSFTPServer) serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	defer func() { _ = conn.Close() }()
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for nc := range chans {
+		if nc.ChannelType() != "session" {
+			_ = nc.Reject(ssh.UnknownChannelType, "session only")
+			continue
+		}
+		ch, requests, err := nc.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+				if ok {
+					go s.serveSFTP(ch)
+				}
+			}
+		}()
+	}
+}
+
+// serveSFTP serves the session with pkg/sftp's own server, over the real
+// filesystem; destinations point beneath the server's temporary root.
+func (s *testSFTPServer) serveSFTP(ch ssh.Channel) {
+	defer func() { _ = ch.Close() }()
+	server, err := sftp.NewServer(ch)
+	if err != nil {
+		return
+	}
+	_ = server.Serve()
+}
+
+func (s *testSFTPServer) destination() script.Destination {
+	return script.Destination{
+		Name: "partner-drop", Kind: script.DestinationKindSFTP,
+		Host: s.addr, User: "drop", Password: "secret", HostKey: s.hostKey, Path: path.Join(filepath.ToSlash(s.root), "incoming"),
+	}
+}
+
+func TestDeliver_SFTPWritesBeneathThePath(t *testing.T) {
+	s := startSFTPServer(t, nil)
+	require.NoError(t, os.Mkdir(filepath.Join(s.root, "incoming"), 0o750))
+
+	// Larger than one SFTP write, so the chunking is exercised.
+	data := []byte(strings.Repeat("region,total\n", 4000))
+	err := New(Config{}).Deliver(context.Background(), s.destination(), File{Key: "weekly/2026/sales.csv", Data: data})
+	require.NoError(t, err)
+
+	got, err := os.ReadFile(filepath.Join(s.root, "incoming", "weekly", "2026", "sales.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+}
+
+func TestDeliver_SFTPAuthenticatesWithAPrivateKey(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	clientKey, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(block), 0o600))
+
+	s := startSFTPServer(t, clientKey)
+	require.NoError(t, os.Mkdir(filepath.Join(s.root, "incoming"), 0o750))
+	dest := s.destination()
+	dest.Password, dest.PrivateKeyFile = "", keyFile
+
+	require.NoError(t, New(Config{}).Deliver(context.Background(), dest, File{Key: "sales.csv", Data: []byte("x")}))
+	_, err = os.Stat(filepath.Join(s.root, "incoming", "sales.csv"))
+	require.NoError(t, err)
+}
+
+// TestDeliver_SFTPRefusesAServerWithAnotherKey is the reason host_key is
+// required: whoever answers at the address is not thereby the server.
+func TestDeliver_SFTPRefusesAServerWithAnotherKey(t *testing.T) {
+	s := startSFTPServer(t, nil)
+	other := startSFTPServer(t, nil)
+	dest := s.destination()
+	dest.HostKey = other.hostKey
+
+	err := New(Config{}).Deliver(context.Background(), dest, File{Key: "sales.csv", Data: []byte("x")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "host key")
+}
+
+func TestDeliver_SFTPReportsTheServerRefusing(t *testing.T) {
+	s := startSFTPServer(t, nil) // no /incoming on the server
+	err := New(Config{}).Deliver(context.Background(), s.destination(), File{Key: "sales.csv", Data: []byte("x")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sales.csv")
+}
+
+// TestDeliver_SFTPReplacesTheFileWhole checks a delivery lands by rename: a
+// second delivery replaces the first, and no temporary file is left beside it.
+func TestDeliver_SFTPReplacesTheFileWhole(t *testing.T) {
+	s := startSFTPServer(t, nil)
+	incoming := filepath.Join(s.root, "incoming")
+	require.NoError(t, os.Mkdir(incoming, 0o750))
+
+	d := New(Config{})
+	require.NoError(t, d.Deliver(context.Background(), s.destination(), File{Key: "sales.csv", Data: []byte("first, and longer")}))
+	require.NoError(t, d.Deliver(context.Background(), s.destination(), File{Key: "sales.csv", Data: []byte("second")}))
+
+	got, err := os.ReadFile(filepath.Join(incoming, "sales.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(got))
+	entries, err := os.ReadDir(incoming)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "sales.csv", entries[0].Name())
+}
+
+func TestDeliver_SFTPRefusesAWrongPassword(t *testing.T) {
+	s := startSFTPServer(t, nil)
+	dest := s.destination()
+	dest.Password = "guess"
+	require.Error(t, New(Config{}).Deliver(context.Background(), dest, File{Key: "sales.csv", Data: []byte("x")}))
+}
@@ -0,0 +1,50 @@
+package scriptdiff
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/txn2/mcp-data-platform/internal/portal/portaldomain"
+)
+
+// VersionReader is the part of the portal version store a comparison needs.
+type VersionReader interface {
+	GetByVersion(ctx context.Context, assetID string, version int) (*portaldomain.AssetVersion, error)
+}
+
+// ObjectReader is the part of the portal's object store a comparison needs.
+type ObjectReader interface {
+	GetObject(ctx context.Context, bucket, key string) ([]byte, string, error)
+}
+
+// PortalContent reads output content from the portal: the version row says
+// where the bytes are, the object store holds them.
+type PortalContent struct {
+	Versions VersionReader
+	Objects  ObjectReader
+}
+
+// Read implements Content. A version row that is gone — pruned by the asset's
+// version cap, or by the asset's deletion — is ErrNotRetained.
+func (c PortalContent) Read(ctx context.Context, assetID string, version int) ([]byte, error) {
+	if c.Versions == nil || c.Objects == nil {
+		return nil, errors.New("portal storage is not configured")
+	}
+	v, err := c.Versions.GetByVersion(ctx, assetID, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotRetained
+	}
+	if err != nil {
+		return nil, fmt.Errorf("looking up the version: %w", err)
+	}
+	if v.SizeBytes > MaxCompareBytes {
+		return nil, fmt.Errorf("version %d is larger than the %d MiB a comparison reads", version, MaxCompareBytes>>20)
+	}
+	data, _, err := c.Objects.GetObject(ctx, v.S3Bucket, v.S3Key)
+	if err != nil {
+		return nil, fmt.Errorf("reading the version's content: %w", err)
+	}
+	return data, nil
+}
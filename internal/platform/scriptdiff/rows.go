@@ -0,0 +1,306 @@
+package scriptdiff
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The tabular formats an export writes and a comparison can read back.
+const (
+	formatCSV  = "csv"
+	formatJSON = "json"
+)
+
+// Row change kinds.
+const (
+	RowAdded   = "added"
+	RowRemoved = "removed"
+	RowChanged = "changed"
+)
+
+// maxRowSamples bounds the rows a diff carries. The counts are exact; the
+// samples are there to show what kind of change it was, and fifty rows of a
+// million-row churn say that as well as the million would.
+const maxRowSamples = 50
+
+// RowDiff is how a tabular output's rows changed.
+type RowDiff struct {
+	// Key is the columns rows were matched by; empty when whole rows were.
+	Key            []string `json:"key,omitempty" example:"region"`
+	Columns        []string `json:"columns"`
+	ColumnsAdded   []string `json:"columns_added,omitempty"`
+	ColumnsRemoved []string `json:"columns_removed,omitempty"`
+
+	BaseRows  int `json:"base_rows"`
+	HeadRows  int `json:"head_rows"`
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Changed   int `json:"changed"`
+	Unchanged int `json:"unchanged"`
+
+	Samples []RowChange `json:"samples,omitempty"`
+	// Truncated reports that there were more changed rows than samples.
+	Truncated bool `json:"truncated,omitempty"`
+
+	note string
+}
+
+// RowChange is one sampled row. Values are compared, and reported, as text:
+// the serialized form is what a reader of either version saw.
+type RowChange struct {
+	Change string            `json:"change" example:"changed"`
+	Before map[string]string `json:"before,omitempty"`
+	After  map[string]string `json:"after,omitempty"`
+}
+
+// identical reports whether the diff found nothing to say.
+func (d *RowDiff) identical() bool {
+	return d.Added == 0 && d.Removed == 0 && d.Changed == 0 &&
+		len(d.ColumnsAdded) == 0 && len(d.ColumnsRemoved) == 0
+}
+
+// sample records one changed row, up to the cap.
+func (d *RowDiff) sample(c RowChange) {
+	if len(d.Samples) == maxRowSamples {
+		d.Truncated = true
+		return
+	}
+	d.Samples = append(d.Samples, c)
+}
+
+// table is a parsed tabular output.
+type table struct {
+	columns []string
+	rows    [][]string
+}
+
+// diffRows parses both versions and compares their rows over the columns they
+// share. A column only one side has is reported as added or removed rather
+// than marking every row changed: a new column is one change, not a million.
+func diffRows(format string, before, after []byte, key []string) (*RowDiff, error) {
+	base, err := parseTable(format, before)
+	if err != nil {
+		return nil, fmt.Errorf("the earlier version does not parse as %s: %w", format, err)
+	}
+	head, err := parseTable(format, after)
+	if err != nil {
+		return nil, fmt.Errorf("the later version does not parse as %s: %w", format, err)
+	}
+
+	d := &RowDiff{Columns: head.columns, BaseRows: len(base.rows), HeadRows: len(head.rows)}
+	var shared []string
+	for _, c := range head.columns {
+		if slices.Contains(base.columns, c) {
+			shared = append(shared, c)
+		} else {
+			d.ColumnsAdded = append(d.ColumnsAdded, c)
+		}
+	}
+	for _, c := range base.columns {
+		if !slices.Contains(head.columns, c) {
+			d.ColumnsRemoved = append(d.ColumnsRemoved, c)
+		}
+	}
+
+	if len(key) > 0 {
+		if missing := missingColumn(key, shared); missing != "" {
+			d.note = fmt.Sprintf("key column %q is not in both versions; compared whole rows", missing)
+		} else if diffKeyed(d, base, head, shared, key) {
+			d.Key = key
+			return d, nil
+		} else {
+			d.note = fmt.Sprintf("key %s does not identify one row on each side; compared whole rows", strings.Join(key, ", "))
+		}
+	}
+	diffWhole(d, base, head, shared)
+	return d, nil
+}
+
+// missingColumn returns the first key column not among the shared ones.
+func missingColumn(key, shared []string) string {
+	for _, k := range key {
+		if !slices.Contains(shared, k) {
+			return k
+		}
+	}
+	return ""
+}
+
+// diffWhole compares rows as a multiset over the shared columns: a row is
+// unchanged when the other side has an equal one not already matched, so
+// reordering changes nothing and duplicates are counted.
+func diffWhole(d *RowDiff, base, head *table, shared []string) {
+	baseIdx, headIdx := indexes(base, shared), indexes(head, shared)
+	remaining := make(map[string]int, len(base.rows))
+	for _, r := range base.rows {
+		remaining[rowKey(r, baseIdx)]++
+	}
+	for _, r := range head.rows {
+		k := rowKey(r, headIdx)
+		if remaining[k] > 0 {
+			remaining[k]--
+			d.Unchanged++
+			continue
+		}
+		d.Added++
+		d.sample(RowChange{Change: RowAdded, After: rowMap(r, head.columns)})
+	}
+	for _, r := range base.rows {
+		k := rowKey(r, baseIdx)
+		if remaining[k] > 0 {
+			remaining[k]--
+			d.Removed++
+			d.sample(RowChange{Change: RowRemoved, Before: rowMap(r, base.columns)})
+		}
+	}
+}
+
+// diffKeyed matches rows by key, so a row whose other values moved is one
+// change. It returns false, having changed nothing, when the key repeats on
+// either side: a key that does not identify a row cannot pair them.
+func diffKeyed(d *RowDiff, base, head *table, shared, key []string) bool {
+	baseKey, headKey := indexes(base, key), indexes(head, key)
+	baseBy, ok := uniqueRows(base, baseKey)
+	if !ok {
+		return false
+	}
+	headBy, ok := uniqueRows(head, headKey)
+	if !ok {
+		return false
+	}
+	baseIdx, headIdx := indexes(base, shared), indexes(head, shared)
+	for _, r := range head.rows {
+		prior, found := baseBy[rowKey(r, headKey)]
+		switch {
+		case !found:
+			d.Added++
+			d.sample(RowChange{Change: RowAdded, After: rowMap(r, head.columns)})
+		case rowKey(prior, baseIdx) == rowKey(r, headIdx):
+			d.Unchanged++
+		default:
+			d.Changed++
+			d.sample(RowChange{Change: RowChanged, Before: rowMap(prior, base.columns), After: rowMap(r, head.columns)})
+		}
+	}
+	for _, r := range base.rows {
+		if _, found := headBy[rowKey(r, baseKey)]; !found {
+			d.Removed++
+			d.sample(RowChange{Change: RowRemoved, Before: rowMap(r, base.columns)})
+		}
+	}
+	return true
+}
+
+// uniqueRows indexes a table's rows by key, reporting false on a repeat.
+func uniqueRows(t *table, keyIdx []int) (map[string][]string, bool) {
+	by := make(map[string][]string, len(t.rows))
+	for _, r := range t.rows {
+		k := rowKey(r, keyIdx)
+		if _, dup := by[k]; dup {
+			return nil, false
+		}
+		by[k] = r
+	}
+	return by, true
+}
+
+// indexes returns where each named column sits in a table.
+func indexes(t *table, names []string) []int {
+	idx := make([]int, len(names))
+	for i, n := range names {
+		idx[i] = slices.Index(t.columns, n)
+	}
+	return idx
+}
+
+// rowKey joins the chosen cells of a row into one comparable string. The
+// cells are quoted so no value can forge the separator.
+func rowKey(r []string, idx []int) string {
+	var b strings.Builder
+	for _, i := range idx {
+		cell := ""
+		if i >= 0 && i < len(r) {
+			cell = r[i]
+		}
+		b.WriteString(strconv.Quote(cell))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+// rowMap names a row's cells for a sample.
+func rowMap(r, columns []string) map[string]string {
+	m := make(map[string]string, len(columns))
+	for i, c := range columns {
+		if i < len(r) {
+			m[c] = r[i]
+		}
+	}
+	return m
+}
+
+// parseTable reads an export back into columns and rows.
+func parseTable(format string, data []byte) (*table, error) {
+	if format == formatCSV {
+		return parseCSV(data)
+	}
+	return parseJSON(data)
+}
+
+// parseCSV reads a header row and records, as an export writes them.
+func parseCSV(data []byte) (*table, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return &table{}, nil
+	}
+	return &table{columns: records[0], rows: records[1:]}, nil
+}
+
+// jsonExport is the shape the JSON export format writes.
+type jsonExport struct {
+	Columns []string                     `json:"columns"`
+	Data    []map[string]json.RawMessage `json:"data"`
+}
+
+// parseJSON reads the columns-and-data document a JSON export writes. A cell
+// is kept as the compact JSON it was written as, so 42 and "42" stay different
+// values, as they were to whatever read the export.
+func parseJSON(data []byte) (*table, error) {
+	var doc jsonExport
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("reading JSON: %w", err)
+	}
+	if doc.Columns == nil {
+		return nil, errors.New(`no "columns" array`)
+	}
+	t := &table{columns: doc.Columns, rows: make([][]string, len(doc.Data))}
+	for i, obj := range doc.Data {
+		row := make([]string, len(doc.Columns))
+		for j, c := range doc.Columns {
+			row[j] = jsonCell(obj[c])
+		}
+		t.rows[i] = row
+	}
+	return t, nil
+}
+
+// jsonCell renders one JSON value as compact text. A column an object lacks
+// reads as empty, apart from an explicit null.
+func jsonCell(raw json.RawMessage) string {
+	var compact bytes.Buffer
+	if json.Compact(&compact, raw) != nil {
+		return string(raw)
+	}
+	return compact.String()
+}
@@ -0,0 +1,304 @@
+// Package scriptdiff compares what two runs of one managed script produced.
+//
+// A scheduled script writes a new version of the same asset every fire, so the
+// question a reader of its history has is rarely "what did Tuesday's run
+// write" and usually "what changed since Monday". Compare answers it output by
+// output: a tabular export is compared row by row, a document line by line
+// through textpatch, and an output delivered out of the platform — which the
+// platform does not hold — by the digest the run recorded when it wrote it.
+//
+// Changes answers the cheaper question a run listing asks of every row, from
+// the run records alone, without reading a single object.
+package scriptdiff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+// Output statuses.
+const (
+	// StatusUnchanged is an output both runs wrote with the same content.
+	StatusUnchanged = "unchanged"
+	// StatusChanged is an output both runs wrote with different content.
+	StatusChanged = "changed"
+	// StatusAdded is an output only the later run wrote.
+	StatusAdded = "added"
+	// StatusRemoved is an output only the earlier run wrote.
+	StatusRemoved = "removed"
+	// StatusUnknown is an output whose content cannot be compared: a version
+	// retention has pruned, one too large to read, or one recorded before
+	// runs kept a digest of what they wrote.
+	StatusUnknown = "unknown"
+)
+
+// Comparison kinds.
+const (
+	KindRows = "rows"
+	KindText = "text"
+)
+
+// MaxCompareBytes bounds each side of one content comparison. An output past
+// it is reported as changed or unchanged by digest, but not read: a diff of
+// two large extracts is a job for the warehouse they came from.
+const MaxCompareBytes = 8 << 20
+
+// ErrNotRetained reports an output version that retention has pruned.
+var ErrNotRetained = errors.New("this version is no longer retained")
+
+// Content reads the bytes of one portal asset version.
+type Content interface {
+	// Read returns the content, or ErrNotRetained when the version is gone.
+	Read(ctx context.Context, assetID string, version int) ([]byte, error)
+}
+
+// RunRef identifies one side of a comparison.
+type RunRef struct {
+	ID       string    `json:"id" example:"run_a1b2c3d4"`
+	Version  int       `json:"version" example:"3"`
+	Status   string    `json:"status" example:"succeeded"`
+	FireTime time.Time `json:"fire_time"`
+}
+
+// Comparison is how a later run's outputs differ from an earlier run's.
+type Comparison struct {
+	Base    RunRef       `json:"base"`
+	Head    RunRef       `json:"head"`
+	Summary Summary      `json:"summary"`
+	Outputs []OutputDiff `json:"outputs"`
+}
+
+// OutputDiff is one output compared across the two runs.
+type OutputDiff struct {
+	Name        string `json:"name" example:"daily"`
+	Destination string `json:"destination" example:"portal"`
+	Status      string `json:"status" example:"changed"`
+	// Kind is how the content was compared, empty when it was not read.
+	Kind string `json:"kind,omitempty" example:"rows"`
+	// Note says why the content was not compared, or how the comparison was
+	// narrowed, in a sentence a reader can act on.
+	Note string `json:"note,omitempty"`
+
+	Base *script.RunOutput `json:"base,omitempty"`
+	Head *script.RunOutput `json:"head,omitempty"`
+
+	Rows *RowDiff  `json:"rows,omitempty"`
+	Text *TextDiff `json:"text,omitempty"`
+}
+
+// Summary counts the outputs of a comparison by status, and the net change in
+// the rows the tabular ones carry.
+type Summary struct {
+	Changed   int `json:"changed"`
+	Unchanged int `json:"unchanged"`
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Unknown   int `json:"unknown"`
+	// RowDelta is the later run's tabular row total less the earlier run's.
+	RowDelta int `json:"row_delta"`
+}
+
+// count adds one output to the summary.
+func (s *Summary) count(status string) {
+	switch status {
+	case StatusChanged:
+		s.Changed++
+	case StatusUnchanged:
+		s.Unchanged++
+	case StatusAdded:
+		s.Added++
+	case StatusRemoved:
+		s.Removed++
+	default:
+		s.Unknown++
+	}
+}
+
+// Options narrows a comparison.
+type Options struct {
+	// Key names the columns that identify a row. With a key, a row present on
+	// both sides whose other values moved is reported as changed rather than
+	// as one row removed and another added.
+	Key []string
+}
+
+// Changes summarizes how head's outputs differ from base's from the run
+// records alone: the digests each run recorded decide changed or unchanged,
+// and nothing is read. It is what a run listing computes for every row.
+func Changes(base, head *script.Run) Summary {
+	var s Summary
+	for _, p := range pairOutputs(base, head) {
+		s.count(p.statusByRecord())
+		s.RowDelta += p.rowDelta()
+	}
+	return s
+}
+
+// Compare reads and compares the content of every output the two runs wrote.
+// A failure to read one output is reported on that output, never as the
+// comparison's error: the others are still worth having.
+func Compare(ctx context.Context, content Content, base, head *script.Run, opts Options) *Comparison {
+	out := &Comparison{Base: refOf(base), Head: refOf(head), Outputs: []OutputDiff{}}
+	for _, p := range pairOutputs(base, head) {
+		d := p.compare(ctx, content, opts)
+		out.Summary.count(d.Status)
+		out.Summary.RowDelta += p.rowDelta()
+		out.Outputs = append(out.Outputs, d)
+	}
+	return out
+}
+
+// refOf identifies a run.
+func refOf(r *script.Run) RunRef {
+	return RunRef{ID: r.ID, Version: r.Version, Status: r.Status, FireTime: r.FireTime}
+}
+
+// pair is one output identity — a name at a destination — and what each run
+// recorded for it.
+type pair struct {
+	name, destination string
+	base, head        *script.RunOutput
+}
+
+// pairOutputs matches the two runs' outputs by name and destination, the pair
+// that identifies a recorded output, in the order the later run wrote them
+// followed by those only the earlier one did.
+func pairOutputs(base, head *script.Run) []pair {
+	key := func(o script.RunOutput) string { return o.Name + "\x00" + destinationOf(o) }
+	baseBy := make(map[string]*script.RunOutput, len(base.Outputs))
+	for i := range base.Outputs {
+		baseBy[key(base.Outputs[i])] = &base.Outputs[i]
+	}
+	pairs := make([]pair, 0, len(head.Outputs)+len(base.Outputs))
+	seen := make(map[string]bool, len(head.Outputs))
+	for i := range head.Outputs {
+		o := &head.Outputs[i]
+		seen[key(*o)] = true
+		pairs = append(pairs, pair{name: o.Name, destination: destinationOf(*o), base: baseBy[key(*o)], head: o})
+	}
+	for i := range base.Outputs {
+		o := &base.Outputs[i]
+		if !seen[key(*o)] {
+			pairs = append(pairs, pair{name: o.Name, destination: destinationOf(*o), base: o})
+		}
+	}
+	return pairs
+}
+
+// destinationOf reads a recorded output's destination, an unset one being the
+// portal, as the run record reads it.
+func destinationOf(o script.RunOutput) string {
+	if o.Destination == "" {
+		return script.DestinationPortal
+	}
+	return o.Destination
+}
+
+// statusByRecord decides a pair's status from what the runs recorded.
+func (p pair) statusByRecord() string {
+	switch {
+	case p.base == nil:
+		return StatusAdded
+	case p.head == nil:
+		return StatusRemoved
+	case p.base.AssetID != "" && p.base.AssetID == p.head.AssetID && p.base.AssetVersion == p.head.AssetVersion:
+		// A reclaimed run that found its output already written records the
+		// same version again; it is one write, whatever the digests say.
+		return StatusUnchanged
+	case p.base.SHA256 == "" || p.head.SHA256 == "":
+		return StatusUnknown
+	case p.base.SHA256 == p.head.SHA256:
+		return StatusUnchanged
+	default:
+		return StatusChanged
+	}
+}
+
+// rowDelta is the change in the rows a tabular output carries. A document
+// carries none, and a refresh's row count is its payload's, not the asset's.
+func (p pair) rowDelta() int {
+	rows := func(o *script.RunOutput) int {
+		if o == nil || o.Document || o.Refresh {
+			return 0
+		}
+		return o.RowCount
+	}
+	return rows(p.head) - rows(p.base)
+}
+
+// compare builds one output's diff, reading content only where a difference
+// is possible and the platform holds both sides.
+func (p pair) compare(ctx context.Context, content Content, opts Options) OutputDiff {
+	d := OutputDiff{
+		Name: p.name, Destination: p.destination,
+		Status: p.statusByRecord(), Base: p.base, Head: p.head,
+	}
+	if p.base == nil || p.head == nil || d.Status == StatusUnchanged {
+		return d
+	}
+	if p.base.AssetID == "" || p.head.AssetID == "" {
+		d.Note = "delivered out of the platform, which keeps no copy; compared by the digest each run recorded"
+		return d
+	}
+	if p.base.Bytes > MaxCompareBytes || p.head.Bytes > MaxCompareBytes {
+		d.Note = fmt.Sprintf("larger than the %d MiB a comparison reads", MaxCompareBytes>>20)
+		return d
+	}
+	before, err := readSide(ctx, content, p.base, "earlier")
+	if err == nil {
+		var after []byte
+		if after, err = readSide(ctx, content, p.head, "later"); err == nil {
+			d.Status = diffContent(&d, before, after, opts)
+			return d
+		}
+	}
+	d.Status, d.Note = StatusUnknown, err.Error()
+	return d
+}
+
+// readSide reads one side of a pair, saying which side failed.
+func readSide(ctx context.Context, content Content, o *script.RunOutput, side string) ([]byte, error) {
+	data, err := content.Read(ctx, o.AssetID, o.AssetVersion)
+	switch {
+	case errors.Is(err, ErrNotRetained):
+		return nil, fmt.Errorf("the %s run's version %d is no longer retained", side, o.AssetVersion)
+	case err != nil:
+		return nil, fmt.Errorf("reading the %s run's version %d: %w", side, o.AssetVersion, err)
+	case len(data) > MaxCompareBytes:
+		return nil, fmt.Errorf("the %s run's version is larger than the %d MiB a comparison reads", side, MaxCompareBytes>>20)
+	}
+	return data, nil
+}
+
+// diffContent compares the two versions' bytes by the output's shape, filling
+// in the diff and returning the status the content decides.
+func diffContent(d *OutputDiff, before, after []byte, opts Options) string {
+	if tabular(d.Head) && tabular(d.Base) && d.Head.Format == d.Base.Format {
+		rows, err := diffRows(d.Head.Format, before, after, opts.Key)
+		if err == nil {
+			d.Kind, d.Rows, d.Note = KindRows, rows, rows.note
+			if rows.identical() {
+				return StatusUnchanged
+			}
+			return StatusChanged
+		}
+		// A table that does not parse is still text, and a line diff of it is
+		// more useful than no diff at all.
+		d.Note = "compared as text: " + err.Error()
+	}
+	d.Kind, d.Text = KindText, diffText(before, after, d.Base, d.Head)
+	if d.Text.LinesAdded == 0 && d.Text.LinesRemoved == 0 {
+		return StatusUnchanged
+	}
+	return StatusChanged
+}
+
+// tabular reports whether an output was serialized from rows in a format
+// that can be read back into them.
+func tabular(o *script.RunOutput) bool {
+	return !o.Document && !o.Refresh && (o.Format == formatCSV || o.Format == formatJSON)
+}
@@ -0,0 +1,306 @@
+package scriptdiff
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/internal/portal/portaldomain"
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+// content is an in-memory Content keyed by asset and version.
+type content map[string][]byte
+
+func (c content) Read(_ context.Context, assetID string, version int) ([]byte, error) {
+	data, ok := c[fmt.Sprintf("%s@%d", assetID, version)]
+	if !ok {
+		return nil, ErrNotRetained
+	}
+	return data, nil
+}
+
+func portalOut(name, format string, version, rows int, digest string) script.RunOutput {
+	return script.RunOutput{
+		Name: name, AssetID: "asset-" + name, AssetVersion: version,
+		Format: format, RowCount: rows, Bytes: 100, SHA256: digest,
+	}
+}
+
+func run(id string, outs ...script.RunOutput) *script.Run {
+	return &script.Run{ID: id, Status: script.RunStatusSucceeded, Outputs: outs}
+}
+
+func TestChanges_DecidesFromDigestsAlone(t *testing.T) {
+	base := run("r1",
+		portalOut("daily", "csv", 1, 10, "aaa"),
+		portalOut("summary", "csv", 1, 3, "bbb"),
+		portalOut("legacy", "csv", 1, 5, ""),
+		portalOut("dropped", "csv", 1, 2, "ccc"),
+	)
+	head := run("r2",
+		portalOut("daily", "csv", 2, 12, "aa2"),
+		portalOut("summary", "csv", 2, 3, "bbb"),
+		portalOut("legacy", "csv", 2, 5, "ddd"),
+		portalOut("fresh", "csv", 1, 4, "eee"),
+	)
+
+	got := Changes(base, head)
+	assert.Equal(t, Summary{Changed: 1, Unchanged: 1, Added: 1, Removed: 1, Unknown: 1, RowDelta: 4}, got)
+}
+
+func TestChanges_PairsByNameAndDestination(t *testing.T) {
+	portal := portalOut("daily", "csv", 1, 1, "aaa")
+	s3 := script.RunOutput{Name: "daily", Destination: "reports-bucket", Format: "csv", SHA256: "aaa"}
+	explicit := portal
+	explicit.Destination = script.DestinationPortal
+
+	got := Changes(run("r1", portal, s3), run("r2", explicit, s3))
+	assert.Equal(t, 2, got.Unchanged, "an unset destination is the portal")
+	assert.Zero(t, got.Added+got.Removed)
+}
+
+func TestChanges_ARewrittenVersionIsUnchanged(t *testing.T) {
+	out := portalOut("daily", "csv", 4, 1, "")
+	assert.Equal(t, 1, Changes(run("r1", out), run("r2", out)).Unchanged,
+		"a reclaimed run that recorded the same version wrote nothing new")
+}
+
+func TestCompare_DiffsCSVRowsAsAMultiset(t *testing.T) {
+	c := content{
+		"asset-daily@1": []byte("region,total\nEMEA,10\nAPAC,7\nAPAC,7\n"),
+		"asset-daily@2": []byte("total,region\n7,APAC\n10,EMEA\n9,AMER\n"),
+	}
+	got := Compare(context.Background(), c,
+		run("r1", portalOut("daily", "csv", 1, 3, "a")),
+		run("r2", portalOut("daily", "csv", 2, 3, "b")), Options{})
+
+	require.Len(t, got.Outputs, 1)
+	d := got.Outputs[0]
+	assert.Equal(t, StatusChanged, d.Status)
+	assert.Equal(t, KindRows, d.Kind)
+	require.NotNil(t, d.Rows)
+	assert.Equal(t, 2, d.Rows.Unchanged, "column order is not a change")
+	assert.Equal(t, 1, d.Rows.Added)
+	assert.Equal(t, 1, d.Rows.Removed, "one of the two APAC rows went")
+	assert.Equal(t, []RowChange{
+		{Change: RowAdded, After: map[string]string{"region": "AMER", "total": "9"}},
+		{Change: RowRemoved, Before: map[string]string{"region": "APAC", "total": "7"}},
+	}, d.Rows.Samples)
+	assert.Equal(t, "r1", got.Base.ID)
+	assert.Equal(t, 1, got.Summary.Changed)
+}
+
+func TestCompare_AKeyPairsRowsWhoseValuesMoved(t *testing.T) {
+	c := content{
+		"asset-daily@1": []byte("region,total\nEMEA,10\nAPAC,7\n"),
+		"asset-daily@2": []byte("region,total\nEMEA,11\nAMER,2\n"),
+	}
+	got := Compare(context.Background(), c,
+		run("r1", portalOut("daily", "csv", 1, 2, "a")),
+		run("r2", portalOut("daily", "csv", 2, 2, "b")), Options{Key: []string{"region"}})
+
+	rows := got.Outputs[0].Rows
+	require.NotNil(t, rows)
+	assert.Equal(t, []string{"region"}, rows.Key)
+	assert.Equal(t, 1, rows.Changed)
+	assert.Equal(t, 1, rows.Added)
+	assert.Equal(t, 1, rows.Removed)
+	assert.Equal(t, RowChange{
+		Change: RowChanged,
+		Before: map[string]string{"region": "EMEA", "total": "10"},
+		After:  map[string]string{"region": "EMEA", "total": "11"},
+	}, rows.Samples[0])
+}
+
+func TestCompare_AKeyThatRepeatsFallsBackToWholeRows(t *testing.T) {
+	c := content{
+		"asset-daily@1": []byte("region,total\nEMEA,10\nEMEA,7\n"),
+		"asset-daily@2": []byte("region,total\nEMEA,10\n"),
+	}
+	got := Compare(context.Background(), c,
+		run("r1", portalOut("daily", "csv", 1, 2, "a")),
+		run("r2", portalOut("daily", "csv", 2, 1, "b")), Options{Key: []string{"region"}})
+
+	d := got.Outputs[0]
+	assert.Empty(t, d.Rows.Key)
+	assert.Equal(t, 1, d.Rows.Removed)
+	assert.Contains(t, d.Note, "does not identify one row")
+}
+
+func TestCompare_ReportsColumnsOnceNotPerRow(t *testing.T) {
+	c := content{
+		"asset-daily@1": []byte(`{"columns":["region","old"],"data":[{"region":"EMEA","old":1}],"row_count":1}`),
+		"asset-daily@2": []byte(`{"columns":["region","total"],"data":[{"region":"EMEA","total":"42"}],"row_count":1}`),
+	}
+	got := Compare(context.Background(), c,
+		run("r1", portalOut("daily", "json", 1, 1, "a")),
+		run("r2", portalOut("daily", "json", 2, 1, "b")), Options{})
+
+	rows := got.Outputs[0].Rows
+	require.NotNil(t, rows)
+	assert.Equal(t, []string{"total"}, rows.ColumnsAdded)
+	assert.Equal(t, []string{"old"}, rows.ColumnsRemoved)
+	assert.Equal(t, 1, rows.Unchanged, "the shared column did not move")
+	assert.Equal(t, StatusChanged, got.Outputs[0].Status)
+}
+
+func TestCompare_JSONKeepsNumbersAndStringsApart(t *testing.T) {
+	c := content{
+		"asset-daily@1": []byte(`{"columns":["n"],"data":[{"n":42}]}`),
+		"asset-daily@2": []byte(`{"columns":["n"],"data":[{"n":"42"}]}`),
+	}
+	got := Compare(context.Background(), c,
+		run("r1", portalOut("daily", "json", 1, 1, "a")),
+		run("r2", portalOut("daily", "json", 2, 1, "b")), Options{})
+	assert.Equal(t, 1, got.Outputs[0].Rows.Added)
+}
+
+func TestCompare_SameRowsInADifferentOrderAreUnchanged(t *testing.T) {
+	c := content{
+		"asset-daily@1": []byte("a\n1\n2\n"),
+		"asset-daily@2": []byte("a\n2\n1\n"),
+	}
+	got := Compare(context.Background(), c,
+		run("r1", portalOut("daily", "csv", 1, 2, "a")),
+		run("r2", portalOut("daily", "csv", 2, 2, "b")), Options{})
+	assert.Equal(t, StatusUnchanged, got.Outputs[0].Status)
+	assert.Equal(t, 1, got.Summary.Unchanged)
+}
+
+func TestCompare_SamplesAreCapped(t *testing.T) {
+	before, after := "a\n", "a\n"
+	for i := range maxRowSamples + 10 {
+		after += fmt.Sprintf("%d\n", i)
+	}
+	c := content{"asset-daily@1": []byte(before), "asset-daily@2": []byte(after)}
+	got := Compare(context.Background(), c,
+		run("r1", portalOut("daily", "csv", 1, 0, "a")),
+		run("r2", portalOut("daily", "csv", 2, maxRowSamples+10, "b")), Options{})
+
+	rows := got.Outputs[0].Rows
+	assert.Equal(t, maxRowSamples+10, rows.Added, "the count is exact")
+	assert.Len(t, rows.Samples, maxRowSamples)
+	assert.True(t, rows.Truncated)
+}
+
+func TestCompare_DiffsDocumentsAsText(t *testing.T) {
+	doc := func(v int, digest string) script.RunOutput {
+		o := portalOut("report", "html", v, 0, digest)
+		o.Document = true
+		return o
+	}
+	c := content{
+		"asset-report@1": []byte("<h1>Sales</h1>\n<p>10</p>\n"),
+		"asset-report@2": []byte("<h1>Sales</h1>\n<p>12</p>\n"),
+	}
+	got := Compare(context.Background(), c, run("r1", doc(1, "a")), run("r2", doc(2, "b")), Options{})
+
+	d := got.Outputs[0]
+	assert.Equal(t, KindText, d.Kind)
+	require.NotNil(t, d.Text)
+	assert.Equal(t, 1, d.Text.LinesAdded)
+	assert.Equal(t, 1, d.Text.LinesRemoved)
+	assert.Contains(t, d.Text.Patch, "--- report v1\n+++ report v2\n")
+	assert.Contains(t, d.Text.Patch, "+<p>12</p>")
+}
+
+func TestCompare_AnUnparseableTableIsComparedAsText(t *testing.T) {
+	c := content{
+		"asset-daily@1": []byte(`not json`),
+		"asset-daily@2": []byte(`still not json`),
+	}
+	got := Compare(context.Background(), c,
+		run("r1", portalOut("daily", "json", 1, 0, "a")),
+		run("r2", portalOut("daily", "json", 2, 0, "b")), Options{})
+
+	d := got.Outputs[0]
+	assert.Equal(t, KindText, d.Kind)
+	assert.Contains(t, d.Note, "compared as text")
+}
+
+func TestCompare_APrunedVersionIsUnknown(t *testing.T) {
+	c := content{"asset-daily@2": []byte("a\n1\n")}
+	got := Compare(context.Background(), c,
+		run("r1", portalOut("daily", "csv", 1, 1, "a")),
+		run("r2", portalOut("daily", "csv", 2, 1, "b")), Options{})
+
+	d := got.Outputs[0]
+	assert.Equal(t, StatusUnknown, d.Status)
+	assert.Equal(t, "the earlier run's version 1 is no longer retained", d.Note)
+	assert.Equal(t, 1, got.Summary.Unknown)
+}
+
+func TestCompare_DeliveredOutputsAreComparedByDigest(t *testing.T) {
+	out := func(digest string) script.RunOutput {
+		return script.RunOutput{Name: "daily", Destination: "reports-bucket", Format: "csv", SHA256: digest}
+	}
+	got := Compare(context.Background(), content{}, run("r1", out("a")), run("r2", out("b")), Options{})
+
+	d := got.Outputs[0]
+	assert.Equal(t, StatusChanged, d.Status)
+	assert.Empty(t, d.Kind)
+	assert.Contains(t, d.Note, "keeps no copy")
+}
+
+func TestCompare_DoesNotReadAnOversizedOutput(t *testing.T) {
+	big := portalOut("daily", "csv", 2, 1, "b")
+	big.Bytes = MaxCompareBytes + 1
+	got := Compare(context.Background(), content{},
+		run("r1", portalOut("daily", "csv", 1, 1, "a")), run("r2", big), Options{})
+
+	d := got.Outputs[0]
+	assert.Equal(t, StatusChanged, d.Status, "the digests still decide")
+	assert.Contains(t, d.Note, "larger than")
+}
+
+type versions map[int]*portaldomain.AssetVersion
+
+func (v versions) GetByVersion(_ context.Context, _ string, version int) (*portaldomain.AssetVersion, error) {
+	if got, ok := v[version]; ok {
+		return got, nil
+	}
+	return nil, fmt.Errorf("querying version: %w", sql.ErrNoRows)
+}
+
+type objects map[string][]byte
+
+func (o objects) GetObject(_ context.Context, _, key string) ([]byte, string, error) {
+	if data, ok := o[key]; ok {
+		return data, "text/csv", nil
+	}
+	return nil, "", errors.New("no such key")
+}
+
+func TestPortalContent_Read(t *testing.T) {
+	c := PortalContent{
+		Versions: versions{
+			1: {S3Bucket: "b", S3Key: "k1", SizeBytes: 3},
+			2: {S3Bucket: "b", S3Key: "gone", SizeBytes: 3},
+			3: {S3Bucket: "b", S3Key: "k3", SizeBytes: MaxCompareBytes + 1},
+		},
+		Objects: objects{"k1": []byte("a,b")},
+	}
+	ctx := context.Background()
+
+	data, err := c.Read(ctx, "asset", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "a,b", string(data))
+
+	_, err = c.Read(ctx, "asset", 9)
+	require.ErrorIs(t, err, ErrNotRetained)
+
+	_, err = c.Read(ctx, "asset", 2)
+	require.ErrorContains(t, err, "no such key")
+
+	_, err = c.Read(ctx, "asset", 3)
+	require.ErrorContains(t, err, "larger than")
+
+	_, err = PortalContent{}.Read(ctx, "asset", 1)
+	require.Error(t, err)
+}
@@ -0,0 +1,52 @@
+package scriptdiff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/txn2/mcp-data-platform/pkg/script"
+	"github.com/txn2/mcp-data-platform/pkg/textpatch"
+)
+
+// maxPatchBytes bounds the unified diff a comparison carries. The line counts
+// are exact whatever the patch's size; past the bound a reader is better served
+// by opening the two versions than by scrolling a diff of them.
+const maxPatchBytes = 64 << 10
+
+// TextDiff is how a document's lines changed.
+type TextDiff struct {
+	// Patch is the unified diff from the earlier version to the later one.
+	Patch        string `json:"patch"`
+	LinesAdded   int    `json:"lines_added"`
+	LinesRemoved int    `json:"lines_removed"`
+	// Truncated reports that Patch was cut at a line boundary.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// diffText renders a line diff of the two versions, labelled with the asset
+// version each is, the way the portal labels a version comparison.
+func diffText(before, after []byte, base, head *script.RunOutput) *TextDiff {
+	patch := textpatch.UnifiedDiffLabeled(string(before), string(after),
+		versionLabel(base), versionLabel(head), 0)
+	d := &TextDiff{Patch: patch}
+	for i, line := range strings.Split(patch, "\n") {
+		switch {
+		case i < 2:
+			// The ---/+++ header names the versions, not a change.
+		case strings.HasPrefix(line, "+"):
+			d.LinesAdded++
+		case strings.HasPrefix(line, "-"):
+			d.LinesRemoved++
+		}
+	}
+	if len(patch) > maxPatchBytes {
+		cut := strings.LastIndexByte(patch[:maxPatchBytes], '\n')
+		d.Patch, d.Truncated = patch[:cut+1], true
+	}
+	return d
+}
+
+// versionLabel names one side of a diff.
+func versionLabel(o *script.RunOutput) string {
+	return fmt.Sprintf("%s v%d", o.Name, o.AssetVersion)
+}
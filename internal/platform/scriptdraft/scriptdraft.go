@@ -28,6 +28,7 @@ import (
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptlib"
 	"github.com/txn2/mcp-data-platform/internal/platform/scriptrun"
 	"github.com/txn2/mcp-data-platform/pkg/middleware"
 	"github.com/txn2/mcp-data-platform/pkg/script"
@@ -86,6 +87,9 @@ type Request struct {
 	// and a platform run bind by one rule.
 	Params   map[string]any
 	Identity Identity
+	// Libraries resolves the source's load() statements. Nil refuses every
+	// load, which is a draft run on a surface with no script store.
+	Libraries scriptlib.Resolver
 }
 
 // Outcome is what one draft execution did. Failure is a normal outcome and is
@@ -175,7 +179,7 @@ func (r *Runner) Run(ctx context.Context, req Request) (*Outcome, error) {
 		// run.fire_time: even a draft never reads a clock, so what an author
 		// verifies in the loop is what a scheduled run will do.
 		FireTime: r.now(), Params: req.Params, Caller: caller,
-		Destinations: r.destinations,
+		Destinations: r.destinations, Libraries: req.Libraries,
 	})
 	return &Outcome{RunID: runID, Result: result, Err: runErr}, nil
 }
@@ -8,6 +8,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptdeliver"
 	"github.com/txn2/mcp-data-platform/internal/platform/scriptrun"
 	"github.com/txn2/mcp-data-platform/pkg/contenttype"
 	"github.com/txn2/mcp-data-platform/pkg/script"
@@ -24,17 +25,14 @@ import (
 // reach is refused however the configuration names it.
 const toolPutObject = "s3_put_object"
 
-// deliver writes one output as an object in a configured bucket destination.
+// deliver writes one output to a configured destination: an object in a
+// bucket, or a file on an SFTP server, on a mounted volume, or in a mailbox.
 //
 // The bytes are identical to what the portal would have stored: the same
 // formatter produced them, and the same size ceiling applies. What differs is
 // where they land and who can read them afterwards — which is why the address
 // comes from the deployment's configuration, never from the script.
 func (w *outputWriter) deliver(ctx context.Context, req scriptrun.ExportRequest, identity scriptrun.OutputIdentity, data []byte) (*scriptrun.ExportResult, script.RunOutput, error) {
-	if w.caller == nil {
-		return nil, script.RunOutput{}, fmt.Errorf("output %q cannot be delivered to %q: this deployment has no platform session to write it through",
-			req.Name, req.Destination.Name)
-	}
 	key, err := deliveryKey(req, identity.Extension)
 	if err != nil {
 		return nil, script.RunOutput{}, err
@@ -50,6 +48,39 @@ func (w *outputWriter) deliver(ctx context.Context, req scriptrun.ExportRequest,
 			req.Name, key, prior)
 	}
 
+	bytes := len(data)
+	if req.Destination.Kind == script.DestinationKindS3 {
+		bytes, err = w.putObject(ctx, req, identity, key, data)
+	} else {
+		err = w.deliverDirect(ctx, req, identity, key, data)
+	}
+	if err != nil {
+		return nil, script.RunOutput{}, err
+	}
+	w.delivered[objectAddress(req.Destination, key)] = req.Name
+	slog.Info("scripts: delivered an output",
+		logKeyRunID, w.run.ID, "output", req.Name,
+		"destination", req.Destination.Name, "bucket", req.Destination.Bucket,
+		"key", key, "bytes", len(data))
+
+	record := script.RunOutput{
+		Name: req.Name, Destination: req.Destination.Name,
+		Bucket: req.Destination.Bucket, Key: key,
+		Format: req.Format, RowCount: len(req.Rows), Document: req.Body != nil,
+		Bytes: bytes, SHA256: digest(data),
+	}
+	return &scriptrun.ExportResult{
+		Bucket: req.Destination.Bucket, Key: key, Bytes: bytes,
+	}, record, nil
+}
+
+// putObject delivers one output to a bucket as the s3_put_object call it is,
+// and returns the size the tool reports having written.
+func (w *outputWriter) putObject(ctx context.Context, req scriptrun.ExportRequest, identity scriptrun.OutputIdentity, key string, data []byte) (int, error) {
+	if w.caller == nil {
+		return 0, fmt.Errorf("output %q cannot be delivered to %q: this deployment has no platform session to write it through",
+			req.Name, req.Destination.Name)
+	}
 	// Content crosses as base64 rather than as text. Every format written here
 	// is textual today, but the argument is a JSON string either way, and bytes
 	// that are not valid UTF-8 do not survive one intact — a single such byte
@@ -64,32 +95,56 @@ func (w *outputWriter) deliver(ctx context.Context, req scriptrun.ExportRequest,
 		"content_type": contenttype.Normalize(identity.ContentType),
 	})
 	if err != nil {
-		return nil, script.RunOutput{}, fmt.Errorf("delivering output %q to %s: %w",
+		return 0, fmt.Errorf("delivering output %q to %s: %w",
 			req.Name, req.Destination.Label(), err)
 	}
-	w.delivered[objectAddress(req.Destination, key)] = req.Name
-	slog.Info("scripts: delivered an output",
-		logKeyRunID, w.run.ID, "output", req.Name,
-		"destination", req.Destination.Name, "bucket", req.Destination.Bucket,
-		"key", key, "bytes", len(data))
+	return deliveredBytes(out, len(data)), nil
+}
 
-	bytes := deliveredBytes(out, len(data))
-	record := script.RunOutput{
-		Name: req.Name, Destination: req.Destination.Name,
-		Bucket: req.Destination.Bucket, Key: key,
-		Format: req.Format, RowCount: len(req.Rows), Document: req.Body != nil,
-		Bytes: bytes,
+// deliverDirect delivers one output to an sftp, filesystem, or email
+// destination, which the platform writes itself.
+//
+// No tool call carries such a write, so the check the middleware would make is
+// made here, through the same authorizer and for the same persona — the one
+// the version author's roles resolve to — asking for the destination's
+// capability on the destination's name. A persona reaches an SFTP drop the way
+// it reaches a bucket, by being granted it, and a deployment that wired no
+// authorizer reaches none: the check fails closed rather than being skipped.
+func (w *outputWriter) deliverDirect(ctx context.Context, req scriptrun.ExportRequest, identity scriptrun.OutputIdentity, key string, data []byte) error {
+	dest := req.Destination
+	if w.deps.Authorizer == nil || w.deps.Deliver == nil {
+		return fmt.Errorf("output %q cannot be delivered to %q: this deployment cannot authorize a write to a %s destination",
+			req.Name, dest.Name, dest.Kind)
 	}
-	return &scriptrun.ExportResult{
-		Bucket: req.Destination.Bucket, Key: key, Bytes: bytes,
-	}, record, nil
+	allowed, persona, reason := w.deps.Authorizer.IsAuthorized(ctx, w.script.Principal(), w.roles, dest.Capability(), dest.Name)
+	if !allowed {
+		return fmt.Errorf("output %q cannot be delivered to %q: persona %q is not granted %s on it: %s",
+			req.Name, dest.Name, persona, dest.Capability(), reason)
+	}
+	err := w.deps.Deliver.Deliver(ctx, dest, scriptdeliver.File{
+		Key: key, ContentType: contenttype.Normalize(identity.ContentType), Data: data,
+		Subject: w.script.Name + ": " + req.Name,
+		Body:    fmt.Sprintf("Output %q of script %q, run %s, is attached.", req.Name, w.script.Name, w.run.ID),
+	})
+	if err != nil {
+		return fmt.Errorf("delivering output %q to %s: %w", req.Name, dest.Label(), err)
+	}
+	return nil
 }
 
-// objectAddress identifies one delivered object: the bucket it lands in and the
-// key it lands on. The connection is deliberately not part of it — two
-// connections to the same bucket are the same object.
+// objectAddress identifies one delivered file: the place it lands in and the
+// key it lands on. For a bucket the place is the bucket, not the connection —
+// two connections to the same bucket are the same object — and for the other
+// kinds it is the host and directory, or the recipients, the key lands under.
 func objectAddress(destination script.Destination, key string) string {
-	return destination.Bucket + "\x00" + key
+	switch destination.Kind {
+	case script.DestinationKindS3:
+		return destination.Bucket + "\x00" + key
+	case script.DestinationKindEmail:
+		return destination.Kind + "\x00" + strings.Join(destination.To, ",") + "\x00" + key
+	default:
+		return destination.Kind + "\x00" + destination.Host + "\x00" + destination.Path + "\x00" + key
+	}
 }
 
 // deliveryKey composes the object key one delivery writes, under the
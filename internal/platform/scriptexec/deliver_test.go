@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptdeliver"
 	"github.com/txn2/mcp-data-platform/internal/platform/scriptrun"
 	"github.com/txn2/mcp-data-platform/pkg/script"
 )
@@ -253,3 +254,105 @@ func TestOutputWriter_RefusesTwoOutputsOnOneObject(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "already wrote")
 }
+
+// partnerDrop is a configured destination the platform writes itself.
+func partnerDrop() script.Destination {
+	return script.Destination{
+		Name: "partner-drop", Kind: script.DestinationKindSFTP,
+		Host: "sftp.acme.example", User: "drop", Password: "secret",
+		HostKey: "ssh-ed25519 AAAA", Path: "/incoming", Prefix: "weekly",
+	}
+}
+
+// authzCall is one authorization the writer asked for.
+type authzCall struct {
+	userID, tool, connection string
+	roles                    []string
+}
+
+// fakeAuthorizer answers every question one way and records what it was asked.
+type fakeAuthorizer struct {
+	allow bool
+	calls []authzCall
+}
+
+func (f *fakeAuthorizer) IsAuthorized(_ context.Context, userID string, roles []string, tool, connection string) (authorized bool, personaName, reason string) {
+	f.calls = append(f.calls, authzCall{userID: userID, tool: tool, connection: connection, roles: roles})
+	if !f.allow {
+		return false, "analyst", "tool not allowed"
+	}
+	return true, "analyst", ""
+}
+
+// fakeDeliverer records the files it was handed.
+type fakeDeliverer struct {
+	files []scriptdeliver.File
+	dests []string
+}
+
+func (f *fakeDeliverer) Deliver(_ context.Context, dest script.Destination, file scriptdeliver.File) error {
+	f.files = append(f.files, file)
+	f.dests = append(f.dests, dest.Name)
+	return nil
+}
+
+// TestOutputWriter_DeliversDirectlyAsTheGrantedPersona covers the kinds no tool
+// call carries: the write is authorized by the destination's capability for
+// the author's roles, on the destination's name, and only then handed over.
+func TestOutputWriter_DeliversDirectlyAsTheGrantedPersona(t *testing.T) {
+	h := newWriterHarness(t)
+	authz, sink := &fakeAuthorizer{allow: true}, &fakeDeliverer{}
+	h.writer.deps.Authorizer, h.writer.deps.Deliver = authz, sink
+	h.writer.roles = []string{"analyst"}
+	req := csvRequest("daily")
+	req.Destination, req.Key = partnerDrop(), "2026/sales.csv"
+
+	result, err := h.writer.Export(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "weekly/2026/sales.csv", result.Key)
+	assert.Empty(t, result.Bucket)
+
+	require.Len(t, authz.calls, 1)
+	assert.Equal(t, authzCall{
+		userID: "script:daily", tool: "destination:sftp", connection: "partner-drop", roles: []string{"analyst"},
+	}, authz.calls[0])
+	require.Len(t, sink.files, 1)
+	assert.Equal(t, "weekly/2026/sales.csv", sink.files[0].Key)
+	assert.Equal(t, "text/csv", sink.files[0].ContentType)
+	assert.Empty(t, h.caller.calls, "no platform tool call is made for it")
+
+	require.Len(t, h.runs.outputs, 1)
+	assert.Equal(t, "partner-drop", h.runs.outputs[0].Destination)
+	assert.Equal(t, "weekly/2026/sales.csv", h.runs.outputs[0].Key)
+}
+
+// TestOutputWriter_DirectDeliveryIsRefusedWithoutAGrant is the same refusal a
+// bucket the persona cannot reach gets, and the same when nothing was wired to
+// decide: a check that cannot be made is not a check that passed.
+func TestOutputWriter_DirectDeliveryIsRefusedWithoutAGrant(t *testing.T) {
+	tests := map[string]struct {
+		authorizer *fakeAuthorizer
+		wantErr    string
+	}{
+		"denied":        {&fakeAuthorizer{}, "is not granted destination:sftp"},
+		"no authorizer": {nil, "cannot authorize"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := newWriterHarness(t)
+			sink := &fakeDeliverer{}
+			h.writer.deps.Deliver = sink
+			if tt.authorizer != nil {
+				h.writer.deps.Authorizer = tt.authorizer
+			}
+			req := csvRequest("daily")
+			req.Destination = partnerDrop()
+
+			_, err := h.writer.Export(context.Background(), req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.Empty(t, sink.files, "nothing was handed to the transport")
+			assert.Empty(t, h.runs.outputs)
+		})
+	}
+}
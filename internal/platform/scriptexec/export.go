@@ -2,6 +2,8 @@ package scriptexec
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"log/slog"
 	"path"
@@ -51,6 +53,13 @@ type outputWriter struct {
 	// delivered file — two output names can arrive at one key, and the second
 	// write would silently replace the first.
 	delivered map[string]string
+	// roles are the version author's, which the session presents to the
+	// middleware and which a write no tool call carries is authorized by.
+	roles []string
+	// outputCap is the script's output_versions, read once per run on the
+	// first portal write (capRead).
+	outputCap *int
+	capRead   bool
 }
 
 // newOutputWriter builds the writer for one claimed run.
@@ -151,6 +160,12 @@ func (w *outputWriter) record(ctx context.Context, out script.RunOutput) {
 	w.written[outputKey(out.Name, out.Destination)] = true
 }
 
+// digest is the content digest a recorded output carries (RunOutput.SHA256).
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 // outputKey identifies one write: an output name at one destination.
 func outputKey(name, destination string) string {
 	return name + "\x00" + destination
@@ -184,7 +199,7 @@ func (w *outputWriter) writePortal(ctx context.Context, req scriptrun.ExportRequ
 		Name: req.Name, Destination: req.Destination.Name,
 		AssetID: asset.ID, AssetVersion: version,
 		Format: req.Format, RowCount: len(req.Rows), Document: req.Body != nil,
-		Bytes: len(data),
+		Bytes: len(data), SHA256: digest(data),
 	}
 	return &scriptrun.ExportResult{AssetID: asset.ID, AssetVersion: version, Bytes: len(data)}, out, nil
 }
@@ -234,6 +249,7 @@ func (w *outputWriter) assetFor(ctx context.Context, name, contentType string) (
 	owner := w.script.Principal()
 	key := w.outputIdentityKey(name)
 	if existing, err := w.deps.Assets.GetByIdempotencyKey(ctx, owner, key); err == nil && existing != nil {
+		w.applyOutputCap(ctx, existing)
 		return existing, nil
 	}
 	asset := portal.Asset{
@@ -249,6 +265,7 @@ func (w *outputWriter) assetFor(ctx context.Context, name, contentType string) (
 		// records the first version's content.
 		SessionID:      w.run.ID,
 		IdempotencyKey: key,
+		MaxVersions:    w.scriptOutputCap(ctx),
 		Provenance: portal.Provenance{
 			UserID:    owner,
 			SessionID: w.run.ID,
@@ -260,6 +277,7 @@ func (w *outputWriter) assetFor(ctx context.Context, name, contentType string) (
 		// loser reads the winner's asset and writes its own version of it, which
 		// is exactly the intended shape.
 		if found, lookupErr := w.deps.Assets.GetByIdempotencyKey(ctx, owner, key); lookupErr == nil && found != nil {
+			w.applyOutputCap(ctx, found)
 			return found, nil
 		}
 		return nil, fmt.Errorf("creating the output asset for %q: %w", name, err)
@@ -267,6 +285,46 @@ func (w *outputWriter) assetFor(ctx context.Context, name, contentType string) (
 	return &asset, nil
 }
 
+// scriptOutputCap is the version cap the script's retention sets for the
+// assets it writes, or nil when it sets none. A failed read is logged and reads
+// as none: an asset that keeps its history a little longer is not a reason to
+// fail the run that wrote it.
+func (w *outputWriter) scriptOutputCap(ctx context.Context) *int {
+	if w.capRead {
+		return w.outputCap
+	}
+	w.capRead = true
+	if w.deps.Retention == nil {
+		return nil
+	}
+	retention, err := w.deps.Retention.GetRetention(ctx, w.script.ID)
+	if err != nil {
+		slog.Warn("scripts: reading the script's retention failed; output assets keep their caps",
+			logKeyRunID, w.run.ID, logKeyError, err)
+		return nil
+	}
+	w.outputCap = retention.OutputVersions
+	return w.outputCap
+}
+
+// applyOutputCap brings an output asset's version cap into line with the
+// script's retention before a version is written to it, so the prune that
+// version's write triggers already keeps what the script asked for. A script
+// that sets no cap leaves whatever the asset carries, including a cap an
+// administrator set on the asset itself.
+func (w *outputWriter) applyOutputCap(ctx context.Context, asset *portal.Asset) {
+	limit := w.scriptOutputCap(ctx)
+	if limit == nil || (asset.MaxVersions != nil && *asset.MaxVersions == *limit) {
+		return
+	}
+	if err := w.deps.Assets.Update(ctx, asset.ID, portal.AssetUpdate{MaxVersions: limit}); err != nil {
+		slog.Warn("scripts: applying the script's output retention failed",
+			logKeyRunID, w.run.ID, "asset_id", asset.ID, logKeyError, err)
+		return
+	}
+	asset.MaxVersions = limit
+}
+
 // objectKey composes the S3 key for one output version. It includes the run id,
 // so each version is its own immutable object and a reclaimed run rewriting the
 // same output lands on the same key with the same bytes rather than clobbering
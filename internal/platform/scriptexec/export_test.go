@@ -28,6 +28,7 @@ type fakeAssets struct {
 	// lookupOnce fails only the first lookup, which is the shape of a read that
 	// missed or failed while the row was in fact there.
 	lookupOnce bool
+	updates    []portal.AssetUpdate
 }
 
 func newFakeAssets() *fakeAssets { return &fakeAssets{byKey: map[string]*portal.Asset{}} }
@@ -66,7 +67,10 @@ func (*fakeAssets) GetByIDs(context.Context, []string) (assets map[string]*porta
 func (*fakeAssets) List(context.Context, portal.AssetFilter) (assets []portal.Asset, total int, err error) {
 	return nil, 0, nil
 }
-func (*fakeAssets) Update(context.Context, string, portal.AssetUpdate) error { return nil }
+func (f *fakeAssets) Update(_ context.Context, _ string, u portal.AssetUpdate) error {
+	f.updates = append(f.updates, u)
+	return nil
+}
 func (*fakeAssets) AppendProvenanceCapture(context.Context, string, portal.ProvenanceCapture) error {
 	return nil
 }
@@ -374,6 +378,64 @@ func TestOutputWriter_SameNameIsANewVersionOfOneAsset(t *testing.T) {
 	assert.Len(t, h.s3.objects, 2, "each version keeps its own object")
 }
 
+// fakeRetention answers one script's retention.
+type fakeRetention struct {
+	retention script.Retention
+	err       error
+	reads     int
+}
+
+func (f *fakeRetention) GetRetention(context.Context, string) (*script.Retention, error) {
+	f.reads++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &f.retention, nil
+}
+
+func (*fakeRetention) SetRetention(context.Context, *script.Retention) error { return nil }
+
+// TestOutputWriter_AppliesTheScriptsOutputRetention pins that a script's
+// output_versions reaches the asset before the version is written, so the
+// prune that write triggers keeps what the script asked for — on the asset a
+// first run creates and on one an earlier run left uncapped.
+func TestOutputWriter_AppliesTheScriptsOutputRetention(t *testing.T) {
+	h := newWriterHarness(t)
+	ctx := context.Background()
+	keep := 14
+	retention := &fakeRetention{retention: script.Retention{OutputVersions: &keep}}
+	h.writer.deps.Retention = retention
+
+	_, err := h.writer.Export(ctx, csvRequest("daily"))
+	require.NoError(t, err)
+	require.Len(t, h.assets.inserted, 1)
+	assert.Equal(t, &keep, h.assets.inserted[0].MaxVersions, "a new output asset is created with the cap")
+	assert.Empty(t, h.assets.updates, "and needs no update to carry it")
+	assert.Len(t, h.writer.run.Outputs[0].SHA256, 64, "the output records a digest of what it stored")
+
+	// An asset an earlier run left uncapped is brought into line.
+	for _, asset := range h.assets.byKey {
+		asset.MaxVersions = nil
+	}
+	next := newOutputWriter(h.writer.deps, h.runs, &script.Run{ID: "dpx_2", ScriptID: h.run.ScriptID}, h.writer.script, h.caller)
+	_, err = next.Export(ctx, csvRequest("daily"))
+	require.NoError(t, err)
+	require.Len(t, h.assets.updates, 1)
+	assert.Equal(t, &keep, h.assets.updates[0].MaxVersions)
+	assert.Equal(t, 2, retention.reads, "retention is read once per run, not once per output")
+}
+
+// TestOutputWriter_AnUnreadableRetentionLeavesTheCap pins that retention is
+// never a reason to fail the run that wrote an output.
+func TestOutputWriter_AnUnreadableRetentionLeavesTheCap(t *testing.T) {
+	h := newWriterHarness(t)
+	h.writer.deps.Retention = &fakeRetention{err: errors.New("database unavailable")}
+
+	_, err := h.writer.Export(context.Background(), csvRequest("daily"))
+	require.NoError(t, err)
+	assert.Nil(t, h.assets.inserted[0].MaxVersions)
+}
+
 // TestOutputWriter_ReclaimedRunDoesNotWriteTwice is the idempotency the queue
 // needs: a run that died after writing an output and was reclaimed re-executes
 // from the top, and must not produce a second version of that output.
@@ -32,7 +32,9 @@ const (
 // Only scheduled runs. A run somebody asked for through run_script reports its
 // own failure in the tool response they are already reading, and mailing that
 // as well would be telling a person something they just read. A schedule is the
-// case with nobody present, which is the whole reason this exists.
+// case with nobody present, which is the whole reason this exists. A backfill's
+// runs are not mailed either: a replay of a month reports its failures in the
+// backfill somebody asked for, not as one alert per historical day.
 func (w *worker) notifyFailure(ctx context.Context, run *script.Run, sc *script.Script, res script.RunResult) {
 	if w.cfg.notifier == nil || sc == nil ||
 		run.Trigger != script.TriggerSchedule || res.Status != script.RunStatusFailed {
@@ -44,8 +46,12 @@ func (w *worker) notifyFailure(ctx context.Context, run *script.Run, sc *script.
 			logKeyRunID, run.ID, "script", logsan.SanitizeForLog(sc.Name))
 		return
 	}
+	kind := notification.KindScriptRun
+	if res.FailureKind == script.FailureKindDataQuality {
+		kind = notification.KindScriptQuality
+	}
 	payload := notification.Payload{
-		Kind:      notification.KindScriptRun,
+		Kind:      kind,
 		ItemID:    run.ID,
 		ItemTitle: sc.Name,
 		// The actor is the SCRIPT, which is both true and load-bearing: the
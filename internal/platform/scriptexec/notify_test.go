@@ -88,6 +88,22 @@ func TestNotifyFailure_TellsTheOwner(t *testing.T) {
 	assert.Equal(t, f.script.Principal(), n.payloads[0].Actor)
 }
 
+// TestNotifyFailure_ADataQualityFailureSaysSo pins that a run whose checks
+// failed raises the alert of its own kind, in the same category.
+func TestNotifyFailure_ADataQualityFailureSaysSo(t *testing.T) {
+	f := failedScheduledRun()
+	f.result.FailureKind = script.FailureKindDataQuality
+	f.result.Error = "data quality: 1 of 1 checks failed"
+	n := &fakeNotifier{}
+
+	notifierWorker(n).notifyFailure(context.Background(), f.run, f.script, f.result)
+
+	require.Len(t, n.payloads, 1)
+	assert.Equal(t, notification.KindScriptQuality, n.payloads[0].Kind)
+	assert.Equal(t, notification.CategoryScriptRun, n.categories[0])
+	assert.Contains(t, n.payloads[0].Message, "1 of 1 checks failed")
+}
+
 // TestNotifyFailure_OnlyForAScheduledFailure pins the boundary. Every other
 // case is either already reported to somebody who is reading it, or is not a
 // failure at all.
@@ -0,0 +1,264 @@
+package scriptexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/internal/logsan"
+	"github.com/txn2/mcp-data-platform/pkg/script"
+	pkgsession "github.com/txn2/mcp-data-platform/pkg/session"
+)
+
+// maxOpenPipelineRuns bounds how many open pipeline runs one pass advances,
+// oldest first. A pass that reaches it leaves the rest for the next tick.
+const maxOpenPipelineRuns = 100
+
+// logKeyPipelineRunID is the structured-logging key for a pipeline run id.
+const logKeyPipelineRunID = "pipeline_run_id"
+
+// pipelinePass starts the pipelines whose cadence came due and advances every
+// open pipeline run.
+//
+// It rides the materializer's pass rather than running a loop of its own, for
+// the reason the materializer runs everywhere the worker does: it keeps no
+// state between passes, and what decides that exactly one replica starts a step
+// is the revision the pipeline run is written against, not which replica
+// noticed. A step therefore starts within one pass of its dependencies
+// finishing.
+func (s *scheduler) pipelinePass(ctx context.Context, now time.Time) {
+	if s.cfg.pipelines == nil || s.cfg.runs == nil {
+		return
+	}
+	s.firePipelines(ctx, now)
+	open, err := s.cfg.pipelines.ListPipelineRuns(ctx, script.PipelineRunFilter{
+		Status: script.RunStatusRunning, Limit: maxOpenPipelineRuns,
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("scripts: reading open pipeline runs failed", logKeyError, err)
+		}
+		return
+	}
+	for i := range open {
+		select {
+		case <-s.stopCh:
+			return
+		default:
+		}
+		s.advancePipelineRun(ctx, &open[i], now)
+	}
+}
+
+// firePipelines starts a run of every pipeline whose cadence came due.
+//
+// The order is materialize's: the run is inserted first and the pipeline moved
+// afterwards, so a process that dies between them fires the same fire again on
+// the next pass, where the unique index on (pipeline, fire time) answers
+// "already started". The misfire policy is the schedule's, fire-once-latest.
+func (s *scheduler) firePipelines(ctx context.Context, now time.Time) {
+	due, err := s.cfg.pipelines.DuePipelines(ctx, now, 0)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("scripts: reading due pipelines failed", logKeyError, err)
+		}
+		return
+	}
+	for i := range due {
+		p := &due[i]
+		cronSpec, err := script.ParseCron(p.CronSpec, p.Timezone)
+		if err != nil {
+			// Unlike a schedule's, a pipeline's cadence was parsed when it was
+			// saved and nothing reparks it, so this is the deployment's zone
+			// database. It is left due and logged until that is fixed.
+			slog.Error("scripts: a pipeline's cadence cannot be computed", // #nosec G706 -- structured slog call; ids sanitized
+				"pipeline", logsan.SanitizeForLog(p.Name), logKeyError, logsan.SanitizeForLog(err.Error()))
+			continue
+		}
+		fire := (&script.Schedule{NextRunAt: p.NextRunAt}).NextFire(cronSpec, now)
+		if fire.Due {
+			run := p.NewRun(script.TriggerSchedule, p.UpdatedBy, fire.At)
+			if _, err := s.cfg.pipelines.StartPipelineRun(ctx, run); err != nil {
+				if ctx.Err() == nil {
+					slog.Warn("scripts: starting a scheduled pipeline run failed",
+						"pipeline", logsan.SanitizeForLog(p.Name), logKeyError, err)
+				}
+				continue
+			}
+		}
+		if fire.Missed > 0 {
+			slog.Info("scripts: a pipeline stepped over missed fires", // #nosec G706 -- structured slog call; ids sanitized
+				"pipeline", logsan.SanitizeForLog(p.Name), "missed", fire.Missed)
+		}
+		if _, err := s.cfg.pipelines.AdvancePipeline(ctx, p.ID, p.NextRunAt, fire.Next); err != nil && ctx.Err() == nil {
+			slog.Warn("scripts: advancing a pipeline failed", "pipeline", logsan.SanitizeForLog(p.Name), logKeyError, err)
+		}
+	}
+}
+
+// advancePipelineRun folds the state of a pipeline run's script runs into it,
+// starts every step that became ready, and records the result.
+//
+// A step is started in two writes: the pipeline run is updated to name the
+// step's run id, and only then is the run enqueued under that id. The update is
+// what one replica wins; a replica that dies between the two leaves a step
+// naming a run that does not exist, which the next pass re-enqueues under the
+// same id, so the run's primary key keeps the step to one execution.
+func (s *scheduler) advancePipelineRun(ctx context.Context, r *script.PipelineRun, now time.Time) {
+	runs, missing, err := s.stepRuns(ctx, r)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("scripts: reading a pipeline run's steps failed", logKeyPipelineRunID, r.ID, logKeyError, err)
+		}
+		return
+	}
+	before := pipelineState(r)
+	queue := []*script.Run{}
+	for _, name := range missing {
+		step := r.Step(name)
+		run, err := s.stepRun(ctx, r, step, runs)
+		if err != nil {
+			step.Status, step.Error = script.RunStatusFailed, err.Error()
+			continue
+		}
+		queue = append(queue, run)
+	}
+	for {
+		ready := r.Advance(runs, now)
+		if len(ready) == 0 {
+			break
+		}
+		for _, name := range ready {
+			step := r.Step(name)
+			run, err := s.stepRun(ctx, r, step, runs)
+			if err != nil {
+				step.Status, step.Error = script.RunStatusFailed, err.Error()
+				continue
+			}
+			step.Status, step.RunID = script.RunStatusRunning, run.ID
+			queue = append(queue, run)
+		}
+	}
+	if pipelineState(r) != before {
+		if err := s.cfg.pipelines.UpdatePipelineRun(ctx, r); err != nil {
+			// Moved means another replica advanced it first, and enqueues what
+			// it started; anything else is retried on the next pass.
+			if !errors.Is(err, script.ErrPipelineRunMoved) && ctx.Err() == nil {
+				slog.Warn("scripts: recording a pipeline run failed", logKeyPipelineRunID, r.ID, logKeyError, err)
+			}
+			return
+		}
+		if r.Terminal() {
+			slog.Info("scripts: pipeline run finished", logKeyPipelineRunID, r.ID, "status", r.Status)
+		}
+	}
+	s.enqueueSteps(ctx, r, queue)
+}
+
+// stepRuns reads back the script runs of the pipeline run's running steps,
+// keyed by run id, and names the steps whose run was never enqueued.
+func (s *scheduler) stepRuns(ctx context.Context, r *script.PipelineRun) (map[string]*script.Run, []string, error) {
+	runs := map[string]*script.Run{}
+	missing := []string{}
+	for _, step := range r.Steps {
+		if step.Status != script.RunStatusRunning || step.RunID == "" {
+			continue
+		}
+		run, err := s.cfg.runs.GetRun(ctx, step.RunID)
+		if errors.Is(err, script.ErrRunNotFound) {
+			missing = append(missing, step.Name)
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading step %q's run: %w", step.Name, err)
+		}
+		runs[run.ID] = run
+	}
+	return runs, missing, nil
+}
+
+// stepRun assembles the script run one step starts, under the step's run id
+// when it already has one. Its refusals become the step's failure, and say why
+// in the pipeline run rather than in a log nobody is reading.
+func (s *scheduler) stepRun(ctx context.Context, r *script.PipelineRun, step *script.StepRun, runs map[string]*script.Run) (*script.Run, error) {
+	sc, v, err := s.current(ctx, step.ScriptID)
+	if err != nil {
+		return nil, err
+	}
+	if refusal := script.RefuseRun(sc); refusal != nil {
+		return nil, refusal
+	}
+	upstream, err := s.upstreamRuns(ctx, r, runs)
+	if err != nil {
+		return nil, err
+	}
+	params, err := r.BindStep(step, v.Params, upstream)
+	if err != nil {
+		return nil, fmt.Errorf("its parameters do not satisfy the script's contract: %w", err)
+	}
+	runID := step.RunID
+	if runID == "" {
+		if runID, err = pkgsession.GenerateScriptSessionID(); err != nil {
+			return nil, fmt.Errorf("minting a run id failed: %w", err)
+		}
+	}
+	// Requested by whoever started the pipeline run, and executed, as every
+	// run is, as the script's own principal.
+	return &script.Run{
+		ID: runID, ScriptID: sc.ID, VersionID: v.ID, Version: v.Version,
+		Trigger: script.TriggerPipeline, Params: params,
+		RequestedBy: r.RequestedBy, FireTime: r.FireTime,
+	}, nil
+}
+
+// upstreamRuns maps every succeeded step to its run, reading the ones this
+// pass has not already read.
+func (s *scheduler) upstreamRuns(ctx context.Context, r *script.PipelineRun, runs map[string]*script.Run) (map[string]*script.Run, error) {
+	out := map[string]*script.Run{}
+	for _, step := range r.Steps {
+		if step.Status != script.RunStatusSucceeded {
+			continue
+		}
+		run, ok := runs[step.RunID]
+		if !ok {
+			read, err := s.cfg.runs.GetRun(ctx, step.RunID)
+			if err != nil {
+				return nil, fmt.Errorf("reading step %q's run: %w", step.Name, err)
+			}
+			runs[read.ID], run = read, read
+		}
+		out[step.Name] = run
+	}
+	return out, nil
+}
+
+// enqueueSteps puts the started steps' runs on the queue. A failure is logged
+// and left: the step names its run, and the next pass finds it missing and
+// enqueues it again.
+func (s *scheduler) enqueueSteps(ctx context.Context, r *script.PipelineRun, queue []*script.Run) {
+	for _, run := range queue {
+		if err := s.cfg.runs.Enqueue(ctx, run); err != nil {
+			if ctx.Err() == nil {
+				slog.Info("scripts: enqueueing a pipeline step did not complete; the next pass retries it",
+					logKeyPipelineRunID, r.ID, logKeyRunID, run.ID, logKeyError, err)
+			}
+			continue
+		}
+		slog.Info("scripts: pipeline step started", logKeyPipelineRunID, r.ID, logKeyRunID, run.ID)
+	}
+	if len(queue) > 0 && s.cfg.wake != nil {
+		s.cfg.wake()
+	}
+}
+
+// pipelineState summarizes what an advance can change, so a pass that changed
+// nothing writes nothing.
+func pipelineState(r *script.PipelineRun) string {
+	state := r.Status
+	for _, step := range r.Steps {
+		state += "|" + step.Status + ":" + step.RunID
+	}
+	return state
+}
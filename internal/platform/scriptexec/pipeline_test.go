@@ -0,0 +1,313 @@
+package scriptexec
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+// fakePipelines is an in-memory pipeline store that models the revision a
+// pipeline run is written against: an update from a stale read is refused, the
+// way the real store's compare-and-set refuses it. Without that a test could
+// not tell one replica starting a step from two.
+type fakePipelines struct {
+	mu        sync.Mutex
+	pipelines []script.Pipeline
+	runs      []*script.PipelineRun
+	// fired stands in for the unique index on (pipeline, fire time).
+	fired    map[string]bool
+	updates  int
+	advanced []time.Time
+}
+
+func (*fakePipelines) SetPipeline(context.Context, *script.Pipeline) error { return nil }
+
+func (*fakePipelines) GetPipeline(context.Context, string, string) (*script.Pipeline, error) {
+	return nil, script.ErrPipelineNotFound
+}
+
+func (f *fakePipelines) ListPipelines(context.Context, string, int) ([]script.Pipeline, error) {
+	return f.pipelines, nil
+}
+
+func (*fakePipelines) DeletePipeline(context.Context, string) error { return nil }
+
+func (f *fakePipelines) DuePipelines(_ context.Context, now time.Time, _ int) ([]script.Pipeline, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := []script.Pipeline{}
+	for _, p := range f.pipelines {
+		if p.Enabled && p.CronSpec != "" && !p.NextRunAt.After(now) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakePipelines) AdvancePipeline(_ context.Context, id string, from, next time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.pipelines {
+		if f.pipelines[i].ID == id && f.pipelines[i].NextRunAt.Equal(from) {
+			f.pipelines[i].NextRunAt = next
+			f.advanced = append(f.advanced, next)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakePipelines) StartPipelineRun(_ context.Context, r *script.PipelineRun) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Trigger == script.TriggerSchedule {
+		key := r.PipelineID + "|" + r.FireTime.String()
+		if f.fired[key] {
+			return false, nil
+		}
+		f.fired[key] = true
+	}
+	r.ID = "prun_" + r.PipelineID
+	stored := *r
+	stored.Steps = append([]script.StepRun(nil), r.Steps...)
+	f.runs = append(f.runs, &stored)
+	return true, nil
+}
+
+func (f *fakePipelines) GetPipelineRun(_ context.Context, id string) (*script.PipelineRun, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range f.runs {
+		if r.ID == id {
+			out := *r
+			out.Steps = append([]script.StepRun(nil), r.Steps...)
+			return &out, nil
+		}
+	}
+	return nil, script.ErrPipelineRunNotFound
+}
+
+func (f *fakePipelines) ListPipelineRuns(_ context.Context, filter script.PipelineRunFilter) ([]script.PipelineRun, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := []script.PipelineRun{}
+	for _, r := range f.runs {
+		if filter.Status == "" || r.Status == filter.Status {
+			cp := *r
+			cp.Steps = append([]script.StepRun(nil), r.Steps...)
+			out = append(out, cp)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakePipelines) UpdatePipelineRun(_ context.Context, r *script.PipelineRun) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, stored := range f.runs {
+		if stored.ID != r.ID {
+			continue
+		}
+		if stored.Revision != r.Revision {
+			return script.ErrPipelineRunMoved
+		}
+		r.Revision++
+		cp := *r
+		cp.Steps = append([]script.StepRun(nil), r.Steps...)
+		f.runs[i] = &cp
+		f.updates++
+		return nil
+	}
+	return script.ErrPipelineRunNotFound
+}
+
+// twoStepPipeline is an extract step and a publish step that reads the
+// extract's output.
+func twoStepPipeline() script.Pipeline {
+	return script.Pipeline{
+		ID: "pipe_1", Name: "daily-revenue", OwnerEmail: "jane@example.com", Enabled: true,
+		Steps: []script.PipelineStep{
+			{Name: "extract", ScriptID: "script_1"},
+			{Name: "publish", ScriptID: "script_1", DependsOn: []string{"extract"},
+				Params: map[string]any{"source": "${steps.extract.rows}"}},
+		},
+		UpdatedBy: "jane@example.com",
+	}
+}
+
+// pipelineSchedulerOver assembles a scheduler with one open run of the
+// two-step pipeline and nothing on the schedule side.
+func pipelineSchedulerOver(t *testing.T, now time.Time) (*scheduler, *fakePipelines, *fakeRuns) {
+	t.Helper()
+	sc, v, _ := executableState()
+	v.Params = []script.Param{{Name: "source", Type: script.ParamTypeString}}
+	p := twoStepPipeline()
+	pipelines := &fakePipelines{fired: map[string]bool{}}
+	_, err := pipelines.StartPipelineRun(context.Background(), p.NewRun(script.TriggerTool, "jane@example.com", now))
+	require.NoError(t, err)
+	runs := &fakeRuns{}
+	s := newScheduler(schedulerConfig{
+		schedules: &fakeSchedules{fired: map[string]bool{}, open: map[string]bool{}},
+		scripts:   &fakeScripts{script: sc},
+		versions:  &fakeVersions{version: v},
+		pipelines: pipelines,
+		runs:      runs,
+		now:       func() time.Time { return now },
+	})
+	require.NotNil(t, s)
+	return s, pipelines, runs
+}
+
+// finishRun moves a queued run to a terminal status, standing in for the
+// worker.
+func finishRun(runs *fakeRuns, id, status string, outputs ...script.RunOutput) {
+	runs.mu.Lock()
+	defer runs.mu.Unlock()
+	for _, r := range runs.queue {
+		if r.ID == id {
+			r.Status, r.Outputs = status, outputs
+		}
+	}
+}
+
+// TestPipeline_StepsStartInDependencyOrderAndHandOffOutputs is the happy path:
+// the step with no dependencies starts first, the dependent step starts only
+// once it succeeded, and it receives the output the first step published.
+func TestPipeline_StepsStartInDependencyOrderAndHandOffOutputs(t *testing.T) {
+	now := time.Date(2026, 8, 14, 7, 0, 0, 0, time.UTC)
+	s, pipelines, runs := pipelineSchedulerOver(t, now)
+
+	s.pass(context.Background())
+
+	require.Len(t, runs.queue, 1, "only the step with no dependencies starts")
+	extract := runs.queue[0]
+	assert.Equal(t, script.TriggerPipeline, extract.Trigger)
+	assert.Equal(t, "jane@example.com", extract.RequestedBy)
+	r, err := pipelines.GetPipelineRun(context.Background(), "prun_pipe_1")
+	require.NoError(t, err)
+	assert.Equal(t, extract.ID, r.Step("extract").RunID)
+	assert.Equal(t, script.RunStatusPending, r.Step("publish").Status)
+
+	s.pass(context.Background())
+	assert.Len(t, runs.queue, 1, "a pass with nothing finished starts nothing")
+
+	finishRun(runs, extract.ID, script.RunStatusSucceeded,
+		script.RunOutput{Name: "rows", AssetID: "asset_42"})
+	s.pass(context.Background())
+
+	require.Len(t, runs.queue, 2)
+	assert.Equal(t, "asset_42", runs.queue[1].Params["source"], "the upstream output is handed to the next step")
+
+	finishRun(runs, runs.queue[1].ID, script.RunStatusSucceeded)
+	s.pass(context.Background())
+	r, err = pipelines.GetPipelineRun(context.Background(), "prun_pipe_1")
+	require.NoError(t, err)
+	assert.Equal(t, script.RunStatusSucceeded, r.Status)
+	require.NotNil(t, r.FinishedAt)
+}
+
+// TestPipeline_AFailedStepSkipsItsDependents pins that a failure stops the
+// branch: the dependent step never runs, and the run fails naming why.
+func TestPipeline_AFailedStepSkipsItsDependents(t *testing.T) {
+	now := time.Date(2026, 8, 14, 7, 0, 0, 0, time.UTC)
+	s, pipelines, runs := pipelineSchedulerOver(t, now)
+
+	s.pass(context.Background())
+	require.Len(t, runs.queue, 1)
+	finishRun(runs, runs.queue[0].ID, script.RunStatusFailed)
+	s.pass(context.Background())
+
+	assert.Len(t, runs.queue, 1, "the dependent step is not started")
+	r, err := pipelines.GetPipelineRun(context.Background(), "prun_pipe_1")
+	require.NoError(t, err)
+	assert.Equal(t, script.RunStatusFailed, r.Status)
+	assert.Equal(t, script.StepStatusSkipped, r.Step("publish").Status)
+}
+
+// TestPipeline_AStepThatCannotRunFailsWithTheReason pins that a refusal lands
+// on the step, where whoever looks at the pipeline run will read it.
+func TestPipeline_AStepThatCannotRunFailsWithTheReason(t *testing.T) {
+	now := time.Date(2026, 8, 14, 7, 0, 0, 0, time.UTC)
+	s, pipelines, runs := pipelineSchedulerOver(t, now)
+	s.cfg.scripts = &fakeScripts{}
+
+	s.pass(context.Background())
+
+	assert.Empty(t, runs.queue)
+	r, err := pipelines.GetPipelineRun(context.Background(), "prun_pipe_1")
+	require.NoError(t, err)
+	assert.Equal(t, script.RunStatusFailed, r.Step("extract").Status)
+	assert.Contains(t, r.Step("extract").Error, "no longer exists")
+	assert.Equal(t, script.RunStatusFailed, r.Status)
+}
+
+// TestPipeline_ALostRaceStartsNothing is the exactly-once guarantee: a replica
+// whose read of the pipeline run went stale enqueues nothing, because the
+// replica that won the update enqueues what it started.
+func TestPipeline_ALostRaceStartsNothing(t *testing.T) {
+	now := time.Date(2026, 8, 14, 7, 0, 0, 0, time.UTC)
+	s, pipelines, runs := pipelineSchedulerOver(t, now)
+	stale, err := pipelines.GetPipelineRun(context.Background(), "prun_pipe_1")
+	require.NoError(t, err)
+	pipelines.runs[0].Revision++
+
+	s.advancePipelineRun(context.Background(), stale, now)
+
+	assert.Empty(t, runs.queue)
+	assert.Zero(t, pipelines.updates)
+}
+
+// TestPipeline_AStepWhoseRunWasNeverEnqueuedIsEnqueuedAgain covers a replica
+// that died between recording a step and enqueueing it: the next pass enqueues
+// the run under the id the step already names.
+func TestPipeline_AStepWhoseRunWasNeverEnqueuedIsEnqueuedAgain(t *testing.T) {
+	now := time.Date(2026, 8, 14, 7, 0, 0, 0, time.UTC)
+	s, pipelines, runs := pipelineSchedulerOver(t, now)
+	step := pipelines.runs[0].Step("extract")
+	step.Status, step.RunID = script.RunStatusRunning, "dpx_orphan"
+
+	s.pass(context.Background())
+
+	require.Len(t, runs.queue, 1)
+	assert.Equal(t, "dpx_orphan", runs.queue[0].ID)
+}
+
+// TestPipeline_ADueCadenceStartsOneRun pins the pipeline's own schedule: a due
+// pipeline starts a run for the fire and moves on, and a second pass over the
+// same fire starts nothing.
+func TestPipeline_ADueCadenceStartsOneRun(t *testing.T) {
+	fire := time.Date(2026, 8, 14, 6, 0, 0, 0, time.UTC)
+	s, pipelines, _ := pipelineSchedulerOver(t, fire.Add(time.Minute))
+	pipelines.runs = nil
+	p := twoStepPipeline()
+	p.CronSpec, p.Timezone, p.NextRunAt = "0 6 * * *", "UTC", fire
+	pipelines.pipelines = []script.Pipeline{p}
+
+	s.pass(context.Background())
+	pipelines.pipelines[0].NextRunAt = fire
+	s.pass(context.Background())
+
+	require.Len(t, pipelines.runs, 1)
+	assert.Equal(t, script.TriggerSchedule, pipelines.runs[0].Trigger)
+	assert.True(t, pipelines.runs[0].FireTime.Equal(fire))
+	require.Len(t, pipelines.advanced, 2)
+	assert.True(t, pipelines.advanced[0].After(fire))
+}
+
+// TestPipeline_NoStoreNoPass pins that a deployment without a pipeline store
+// runs the schedule side alone.
+func TestPipeline_NoStoreNoPass(t *testing.T) {
+	now := time.Date(2026, 8, 14, 7, 0, 0, 0, time.UTC)
+	s, _, runs := pipelineSchedulerOver(t, now)
+	s.cfg.pipelines = nil
+
+	s.pass(context.Background())
+
+	assert.Empty(t, runs.queue)
+}
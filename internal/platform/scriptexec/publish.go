@@ -67,7 +67,7 @@ func (w *outputWriter) PublishData(ctx context.Context, req scriptrun.PublishReq
 		Name: req.Name, Destination: destination,
 		AssetID: asset.ID, AssetVersion: version,
 		Format: scriptrun.PublishFormat, RowCount: scriptrun.PublishRowCount(req.Data),
-		Refresh: true, Bytes: len(payload),
+		Refresh: true, Bytes: len(payload), SHA256: digest([]byte(spliced)),
 	}
 	w.record(ctx, out)
 	return &scriptrun.ExportResult{AssetID: asset.ID, AssetVersion: version, Bytes: len(payload)}, nil
@@ -91,6 +91,7 @@ func (w *outputWriter) refreshTarget(ctx context.Context, name string) (asset *p
 		return nil, "", fmt.Errorf("this script has no output asset named %q to refresh, and platform.publish_data replaces a region of an existing document rather than creating one; publish the dashboard once with platform.export(%q, body, format=\"html\") or format=\"jsx\", then refresh its data here",
 			name, name)
 	}
+	w.applyOutputCap(ctx, asset)
 	ct := contenttype.Normalize(asset.ContentType)
 	if ct != contenttype.HTML && ct != contenttype.JSX && ct != contenttype.Markdown {
 		return nil, "", fmt.Errorf("output %q is a %s document, which carries no data region; only an html, jsx, or markdown document can be refreshed",
@@ -4,17 +4,26 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptlib"
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptquota"
 	"github.com/txn2/mcp-data-platform/internal/platform/scriptrun"
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptsecret"
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptstore"
 	"github.com/txn2/mcp-data-platform/pkg/audit"
+	"github.com/txn2/mcp-data-platform/pkg/memory"
 	"github.com/txn2/mcp-data-platform/pkg/middleware"
 	"github.com/txn2/mcp-data-platform/pkg/script"
 	pkgsession "github.com/txn2/mcp-data-platform/pkg/session"
+	memorykit "github.com/txn2/mcp-data-platform/pkg/toolkits/memory"
 )
 
 // surfaceRunScript is what a platform run records as its tool name in audit,
@@ -22,6 +31,14 @@ import (
 // run, matching how a served prompt records prompts/get or manage_prompt.
 const surfaceRunScript = "run_script"
 
+// checkConnectionKind is the kind of connection a data-quality check names:
+// every check is issued as a trino_query, so its connection is a Trino one.
+const checkConnectionKind = "trino"
+
+// qualifiedTableParts is catalog.schema.table, the only form of a checked
+// table that names one dataset.
+const qualifiedTableParts = 3
+
 // workerTokenBytes is the entropy in a worker's fencing name.
 const workerTokenBytes = 8
 
@@ -41,19 +58,48 @@ type runner struct {
 	export       ExportDeps
 	audit        middleware.AuditLogger
 	destinations []script.Destination
+	insights     InsightCapturer
+	buildURN     middleware.URNBuilder
+	// quotas answers what a run may spend. Nil bounds nothing.
+	quotas interface {
+		Allowance(ctx context.Context, scriptID, owner string) (scriptquota.Limits, error)
+	}
+	// libraries resolves a run's load() statements. Nil refuses every load.
+	libraries scriptlib.Resolver
+	// secrets holds what operators granted to script versions. Nil refuses
+	// every platform.secret.
+	secrets *scriptsecret.Store
 }
 
 // newRunner builds the executor the worker drives.
 func newRunner(runs script.RunStore, cfg Config) *runner {
-	return &runner{
-		runs: runs, server: cfg.Server, export: cfg.Export,
+	export := cfg.Export
+	export.Deliver = newDeliverer(cfg)
+	if export.Retention == nil && cfg.DB != nil {
+		export.Retention = scriptstore.New(cfg.DB)
+	}
+	r := &runner{
+		runs: runs, server: cfg.Server, export: export,
 		audit: cfg.Audit, destinations: cfg.Destinations,
+		insights: insightCapturer(cfg.Insights), buildURN: cfg.BuildURN,
 	}
+	if cfg.DB != nil {
+		r.quotas = scriptquota.New(cfg.DB)
+		r.libraries = scriptstore.New(cfg.DB)
+	}
+	if cfg.DB != nil && cfg.Encryptor != nil {
+		r.secrets = scriptsecret.New(cfg.DB, cfg.Encryptor)
+	}
+	return r
 }
 
 // execute runs one claimed run: open a session as the script principal,
 // execute the queued version's source, and report the outcome.
 func (r *runner) execute(ctx context.Context, run *script.Run, sc *script.Script, v *script.Version) attempt {
+	quota, refused := r.allowance(ctx, sc)
+	if refused != nil {
+		return *refused
+	}
 	caller, cleanup, err := r.connect(ctx, run, sc, v)
 	if err != nil {
 		// The session is platform machinery, not the script: failing to open one
@@ -74,14 +120,86 @@ func (r *runner) execute(ctx context.Context, run *script.Run, sc *script.Script
 	opts.Params = run.Params
 	opts.Caller = caller
 	opts.Destinations = r.destinations
-	opts.Exporter = r.exporter(run, sc, caller)
+	opts.Exporter = r.exporter(run, sc, v, caller)
+	opts.Quota = quota
+	opts.Libraries = r.libraries
+	if r.secrets != nil {
+		opts.Secrets = r.secrets.Vault(sc.ID, v.Version)
+	}
+
+	tail := &logTail{}
+	opts.OnLog = tail.set
+	stopLog := r.publishLog(ctx, run, tail)
 
 	result, runErr := scriptrun.Run(ctx, opts)
+	stopLog()
 	outcome := attemptFrom(result, runErr)
 	r.recordAudit(ctx, run, sc, v, outcome.result)
+	r.recordInsights(ctx, run, sc, outcome.result.Checks)
 	return outcome
 }
 
+// logFlushEvery is how often a running run's log is written to its row. A
+// reader following the run sees a print line within this long of the script
+// printing it; the final log is written by Finish either way.
+var logFlushEvery = time.Second
+
+// logTail is the log a run has printed so far, handed over from the
+// interpreter's thread to the goroutine that writes it.
+type logTail struct {
+	mu        sync.Mutex
+	log       string
+	truncated bool
+	dirty     bool
+}
+
+// set records the log so far. It is the engine's OnLog and never blocks on
+// the database.
+func (t *logTail) set(log string, truncated bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.log, t.truncated, t.dirty = log, truncated, true
+}
+
+// take returns the log when it changed since the last take.
+func (t *logTail) take() (log string, truncated, changed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	changed, t.dirty = t.dirty, false
+	return t.log, t.truncated, changed
+}
+
+// publishLog writes a run's log to its row as it grows, and returns the stop
+// that ends the writing. Stop waits for a write in flight, so none can land
+// after Finish and replace the final log. A failed write is logged and
+// dropped: the log is a view of the run, and the run does not wait on it.
+func (r *runner) publishLog(ctx context.Context, run *script.Run, tail *logTail) (stop func()) {
+	done, finished := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(logFlushEvery)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			log, truncated, changed := tail.take()
+			if !changed {
+				continue
+			}
+			if err := r.runs.RecordLog(ctx, run.Lease(), log, truncated); err != nil {
+				slog.Debug("scripts: writing the running log failed", logKeyRunID, run.ID, "error", err)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
 // attemptFrom turns an engine result into the attempt the worker resolves.
 //
 // Every outcome here is terminal. The interpreter has run, which means the
@@ -93,19 +211,61 @@ func attemptFrom(result *scriptrun.Result, runErr error) attempt {
 		out.result.Log = result.Log
 		out.result.LogTruncated = result.LogTruncated
 		out.result.Metrics = script.RunMetrics{
-			Steps:      result.Steps,
-			DurationMS: result.Duration.Milliseconds(),
-			Queries:    result.Queries,
-			Exports:    len(result.Exports),
+			Steps:       result.Steps,
+			DurationMS:  result.Duration.Milliseconds(),
+			Queries:     result.Queries,
+			Exports:     len(result.Exports),
+			ToolCalls:   result.ToolCalls,
+			RowsScanned: result.RowsScanned,
+			OutputBytes: result.OutputBytes,
 		}
+		out.result.Checks = result.Checks
 	}
 	if runErr != nil {
 		out.result.Status = script.RunStatusFailed
 		out.result.Error = runErr.Error()
+		if errors.Is(runErr, scriptrun.ErrQuota) {
+			out.result.FailureKind = script.FailureKindQuota
+		}
+		return out
+	}
+	// A script that ran to the end and found its data wanting has failed, but
+	// not as a script: the failure kind is what sends its owner to the data
+	// rather than to the code.
+	if failure := script.QualityFailure(out.result.Checks); failure != "" {
+		out.result.Status = script.RunStatusFailed
+		out.result.FailureKind = script.FailureKindDataQuality
+		out.result.Error = failure
 	}
 	return out
 }
 
+// allowance reads what the run may spend under its script's quota and its
+// owner's. A spent allowance fails the run before it starts, terminally: the
+// window moving is what frees it, not another attempt. A quota that cannot be
+// read is the platform's fault, and the run goes back on the queue rather than
+// running unbounded.
+func (r *runner) allowance(ctx context.Context, sc *script.Script) (scriptrun.Quota, *attempt) {
+	if r.quotas == nil {
+		return scriptrun.Quota{}, nil
+	}
+	left, err := r.quotas.Allowance(ctx, sc.ID, sc.OwnerEmail)
+	if errors.Is(err, scriptquota.ErrSpent) {
+		return scriptrun.Quota{}, &attempt{result: script.RunResult{
+			Status: script.RunStatusFailed, FailureKind: script.FailureKindQuota, Error: err.Error(),
+		}}
+	}
+	if err != nil {
+		return scriptrun.Quota{}, retryable("reading the script's quota failed: " + err.Error())
+	}
+	return scriptrun.Quota{
+		WallTime:    time.Duration(left.WallSeconds) * time.Second,
+		RowsScanned: left.RowsScanned,
+		ToolCalls:   int(left.ToolCalls),
+		OutputBytes: left.OutputBytes,
+	}, nil
+}
+
 // connect opens the run's in-memory MCP session as the script principal.
 //
 // Three things are established here and nowhere else:
@@ -121,8 +281,20 @@ func attemptFrom(result *scriptrun.Result, runErr error) attempt {
 //     one session id in audit and none of them touch the owner's own discovery
 //     or gate state.
 func (r *runner) connect(ctx context.Context, run *script.Run, sc *script.Script, v *script.Version) (scriptrun.Caller, func(), error) {
+	caller, cleanup, err := scriptrun.Connect(principalContext(ctx, run.ID, sc, v), r.server, "script-run")
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening the run's session: %w", err)
+	}
+	return caller, cleanup, nil
+}
+
+// principalContext establishes the script principal for a session: the identity
+// and the authority connect describes. It is shared with the trigger observer,
+// which reads a trigger's source as the same principal a run of the script
+// would, so a trigger can never see what the script could not.
+func principalContext(ctx context.Context, sessionID string, sc *script.Script, v *script.Version) context.Context {
 	serverCtx := middleware.WithSource(ctx, middleware.SourceScript)
-	serverCtx = pkgsession.WithAwareSessionID(serverCtx, run.ID)
+	serverCtx = pkgsession.WithAwareSessionID(serverCtx, sessionID)
 	serverCtx = middleware.WithPreAuthenticatedUser(serverCtx, &middleware.UserInfo{
 		UserID:   sc.Principal(),
 		Email:    sc.OwnerEmail,
@@ -142,11 +314,7 @@ func (r *runner) connect(ctx context.Context, run *script.Run, sc *script.Script
 		// persona already reach every asset through each check's admin arm.
 		OnBehalfOf: v.Author,
 	})
-	caller, cleanup, err := scriptrun.Connect(serverCtx, r.server, "script-run")
-	if err != nil {
-		return nil, nil, fmt.Errorf("opening the run's session: %w", err)
-	}
-	return caller, cleanup, nil
+	return serverCtx
 }
 
 // exporter builds the output writer for one run.
@@ -161,12 +329,14 @@ func (r *runner) connect(ctx context.Context, run *script.Run, sc *script.Script
 //
 // (A draft run still previews everywhere: that is decided by the authoring
 // path, which passes no exporter at all.)
-func (r *runner) exporter(run *script.Run, sc *script.Script, caller scriptrun.Caller) scriptrun.Exporter {
+func (r *runner) exporter(run *script.Run, sc *script.Script, v *script.Version, caller scriptrun.Caller) scriptrun.Exporter {
 	if !r.export.ready() {
 		slog.Warn("scripts: this deployment has no portal asset store or object storage; runs that write portal outputs will fail",
 			logKeyRunID, run.ID)
 	}
-	return newOutputWriter(r.export, r.runs, run, sc, caller)
+	w := newOutputWriter(r.export, r.runs, run, sc, caller)
+	w.roles = v.AuthorRoles
+	return w
 }
 
 // recordAudit writes the script_run lifecycle event.
@@ -208,3 +378,51 @@ func (r *runner) recordAudit(ctx context.Context, run *script.Run, sc *script.Sc
 		slog.Warn("scripts: recording the run audit event failed", logKeyRunID, run.ID, logKeyError, err)
 	}
 }
+
+// recordInsights records each failed data-quality check as a data_quality
+// insight against the table it read, so what a schedule found wrong with a
+// table is known to the next person or agent who looks that table up, not
+// only to whoever reads the run.
+//
+// The insight is platform-minted: it goes through the memory toolkit's own
+// capture path, under the script's owner, and is reviewed and deduplicated
+// like any other. A failure to record is logged and never changes the run.
+func (r *runner) recordInsights(ctx context.Context, run *script.Run, sc *script.Script, checks []script.QualityCheck) {
+	if r.insights == nil {
+		return
+	}
+	for _, c := range script.FailedChecks(checks) {
+		_, err := r.insights.AutoCapture(ctx, memorykit.AutoCaptureInput{
+			SinkClass:  memory.SinkSchemaEntity,
+			Content:    fmt.Sprintf("The managed script %q found a data-quality problem: %s.", sc.Name, c.Message()),
+			Category:   memory.CategoryDataQuality,
+			Source:     memory.SourceAutomation,
+			EntityURNs: r.checkURNs(c),
+			Metadata: map[string]any{
+				"script": sc.Name, "script_id": sc.ID, "run_id": run.ID,
+				"check": c.Check, "observed": c.Observed, "expected": c.Expected,
+			},
+			CreatedBy: sc.OwnerEmail,
+			UserID:    sc.Principal(),
+			SessionID: run.ID,
+		})
+		if err != nil {
+			slog.Warn("scripts: recording a data-quality insight failed", logKeyRunID, run.ID, logKeyError, err)
+		}
+	}
+}
+
+// checkURNs keys an insight to the dataset its check read. Only a fully
+// qualified table names one; a shorter name resolves against whatever catalog
+// and schema the connection defaults to, which this side cannot see.
+func (r *runner) checkURNs(c script.QualityCheck) []string {
+	parts := strings.Split(c.Table, ".")
+	if r.buildURN == nil || len(parts) != qualifiedTableParts {
+		return nil
+	}
+	urn := r.buildURN(checkConnectionKind, c.Connection, parts[0], parts[1], parts[2])
+	if urn == "" {
+		return nil
+	}
+	return []string{urn}
+}
@@ -3,6 +3,7 @@ package scriptexec
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -12,9 +13,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptquota"
 	"github.com/txn2/mcp-data-platform/internal/platform/scriptrun"
+	"github.com/txn2/mcp-data-platform/pkg/memory"
 	"github.com/txn2/mcp-data-platform/pkg/middleware"
 	"github.com/txn2/mcp-data-platform/pkg/script"
+	memorykit "github.com/txn2/mcp-data-platform/pkg/toolkits/memory"
 )
 
 // recordingAudit collects the events the runner writes.
@@ -124,6 +128,35 @@ func TestRunner_ExecutesAsTheScriptPrincipal(t *testing.T) {
 	assert.True(t, events[0].Success)
 }
 
+// TestPublishLog_WritesTheLogAsItGrows is what lets a reader follow a run's
+// log: each change reaches the row while the run is still going, and nothing
+// is written once stop returns, so a late write cannot replace Finish's log.
+func TestPublishLog_WritesTheLogAsItGrows(t *testing.T) {
+	defer func(every time.Duration) { logFlushEvery = every }(logFlushEvery)
+	logFlushEvery = 5 * time.Millisecond
+
+	_, _, run := executableState()
+	runs := &fakeRuns{}
+	require.NoError(t, runs.Enqueue(context.Background(), run))
+	run.Status, run.LockedBy, run.Attempt = script.RunStatusRunning, "worker-a", 1
+
+	tail := &logTail{}
+	stop := newRunner(runs, Config{}).publishLog(context.Background(), run, tail)
+	tail.set("starting\n", false)
+	require.Eventually(t, func() bool {
+		runs.mu.Lock()
+		defer runs.mu.Unlock()
+		return len(runs.logs) == 1
+	}, time.Second, time.Millisecond)
+	stop()
+
+	tail.set("starting\ndone\n", false)
+	time.Sleep(20 * time.Millisecond)
+	runs.mu.Lock()
+	defer runs.mu.Unlock()
+	assert.Equal(t, []string{"starting\n"}, runs.logs, "an unchanged log is not rewritten, and nothing lands after stop")
+}
+
 // TestRunner_PinsTheFireTimeToWhatTheRunWasCreatedFor pins that a delayed run
 // computes the report it was asked for, not one shifted by the delay. The run
 // here is one an infrastructure retry has already pushed out: its due time has
@@ -184,6 +217,122 @@ func TestAttemptFrom_EveryInterpreterOutcomeIsTerminal(t *testing.T) {
 	assert.Equal(t, script.RunStatusFailed, nilResult.result.Status)
 }
 
+// TestAttemptFrom_AFailedCheckIsADataQualityFailure pins the distinction the
+// failure kind exists for: a script that ran to the end with a failed check
+// failed on its data, and one that also errored failed as a script.
+func TestAttemptFrom_AFailedCheckIsADataQualityFailure(t *testing.T) {
+	checks := []script.QualityCheck{
+		{Name: "row_count", Table: "hive.sales.orders", Passed: true, Observed: "12 rows", Expected: "at least 1 rows"},
+		{Name: "freshness", Table: "hive.sales.orders", Observed: "no rows", Expected: "newest value at most 24h before the fire time"},
+	}
+	result := &scriptrun.Result{Checks: checks}
+
+	quality := attemptFrom(result, nil)
+	assert.False(t, quality.retryable, "the data is measured again at the next fire, not by a retry")
+	assert.Equal(t, script.RunStatusFailed, quality.result.Status)
+	assert.Equal(t, script.FailureKindDataQuality, quality.result.FailureKind)
+	assert.Contains(t, quality.result.Error, "1 of 2 checks failed")
+	assert.Equal(t, checks, quality.result.Checks)
+
+	crashed := attemptFrom(result, errors.New("Traceback: boom"))
+	assert.Empty(t, crashed.result.FailureKind, "a script that did not finish failed as a script")
+	assert.Equal(t, checks, crashed.result.Checks, "the checks it made before failing are still recorded")
+
+	passed := attemptFrom(&scriptrun.Result{Checks: checks[:1]}, nil)
+	assert.Equal(t, script.RunStatusSucceeded, passed.result.Status)
+	assert.Empty(t, passed.result.FailureKind)
+}
+
+func TestAttemptFrom_AQuotaStopIsAQuotaFailure(t *testing.T) {
+	result := &scriptrun.Result{ToolCalls: 3, RowsScanned: 9000, OutputBytes: 512}
+	out := attemptFrom(result, fmt.Errorf("%w: in query: scanned too much", scriptrun.ErrQuota))
+
+	assert.False(t, out.retryable)
+	assert.Equal(t, script.FailureKindQuota, out.result.FailureKind)
+	assert.Equal(t, script.RunMetrics{ToolCalls: 3, RowsScanned: 9000, OutputBytes: 512}, out.result.Metrics,
+		"what the run spent before it stopped counts against the next one")
+}
+
+// fakeQuotas answers every allowance with the same limits or error.
+type fakeQuotas struct {
+	left scriptquota.Limits
+	err  error
+}
+
+func (f fakeQuotas) Allowance(context.Context, string, string) (scriptquota.Limits, error) {
+	return f.left, f.err
+}
+
+func TestRunner_QuotaIsReadBeforeTheSessionOpens(t *testing.T) {
+	sc, v, run := executableState()
+
+	spent := &runner{quotas: fakeQuotas{err: fmt.Errorf("%w: the script has used 10 of its daily 10 tool calls", scriptquota.ErrSpent)}}
+	out := spent.execute(context.Background(), run, sc, v)
+	assert.False(t, out.retryable, "the window moving frees a spent allowance, not another attempt")
+	assert.Equal(t, script.FailureKindQuota, out.result.FailureKind)
+	assert.Contains(t, out.result.Error, "daily 10 tool calls")
+
+	unreadable := &runner{quotas: fakeQuotas{err: errors.New("connection refused")}}
+	out = unreadable.execute(context.Background(), run, sc, v)
+	assert.True(t, out.retryable, "an unread quota is the platform's fault, and the run never runs unbounded")
+
+	left, refused := (&runner{quotas: fakeQuotas{left: scriptquota.Limits{WallSeconds: 90, ToolCalls: 4}}}).allowance(context.Background(), sc)
+	require.Nil(t, refused)
+	assert.Equal(t, scriptrun.Quota{WallTime: 90 * time.Second, ToolCalls: 4}, left)
+}
+
+// fakeCapturer records the insights a run asked to capture.
+type fakeCapturer struct {
+	captured []memorykit.AutoCaptureInput
+	err      error
+}
+
+func (f *fakeCapturer) AutoCapture(_ context.Context, in memorykit.AutoCaptureInput) (*memorykit.CaptureResult, error) {
+	f.captured = append(f.captured, in)
+	return &memorykit.CaptureResult{}, f.err
+}
+
+// TestRecordInsights_KeysEachFailedCheckToItsTable pins what a failed check
+// leaves for the next reader of the table: one data_quality insight, keyed to
+// the dataset when the check named one.
+func TestRecordInsights_KeysEachFailedCheckToItsTable(t *testing.T) {
+	sc, _, run := executableState()
+	capturer := &fakeCapturer{}
+	r := &runner{
+		insights: capturer,
+		buildURN: func(kind, connection, catalog, schema, table string) string {
+			return "urn:" + kind + ":" + connection + ":" + catalog + "." + schema + "." + table
+		},
+	}
+
+	r.recordInsights(context.Background(), run, sc, []script.QualityCheck{
+		{Name: "row_count", Check: "row_count", Connection: "warehouse", Table: "hive.sales.orders", Observed: "3 rows", Expected: "at least 10 rows"},
+		{Name: "passes", Check: "uniqueness", Table: "hive.sales.orders", Passed: true},
+		{Name: "orphans", Check: "empty", Observed: "2 rows", Expected: "no rows"},
+	})
+
+	require.Len(t, capturer.captured, 2, "only failed checks are insights")
+	first := capturer.captured[0]
+	assert.Equal(t, memory.CategoryDataQuality, first.Category)
+	assert.Equal(t, memory.SourceAutomation, first.Source)
+	assert.Equal(t, []string{"urn:trino:warehouse:hive.sales.orders"}, first.EntityURNs)
+	assert.Equal(t, sc.OwnerEmail, first.CreatedBy)
+	assert.Equal(t, run.ID, first.SessionID)
+	assert.Contains(t, first.Content, "observed 3 rows, expected at least 10 rows")
+	assert.Empty(t, capturer.captured[1].EntityURNs, "a check over arbitrary SQL names no dataset")
+
+	capturer.err = errors.New("memory store down")
+	assert.NotPanics(t, func() {
+		r.recordInsights(context.Background(), run, sc, []script.QualityCheck{{Name: "x", Table: "t"}})
+	}, "a failure to record never changes the run")
+}
+
+func TestInsightCapturer_ATypedNilIsNone(t *testing.T) {
+	var tk *memorykit.Toolkit
+	assert.Nil(t, insightCapturer(tk))
+	assert.Nil(t, insightCapturer(nil))
+}
+
 // TestRunner_WithoutPortalDepsFailsAnExportingRun covers the deployment that
 // can run scripts but cannot persist their output. The run FAILS: a scheduled
 // report recorded as succeeded, with no asset behind it, is the one outcome
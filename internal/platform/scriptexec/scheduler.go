@@ -47,6 +47,16 @@ type schedulerConfig struct {
 	// that is quietly not keeping its cadence is visible without reading the
 	// table (#1307). Nil is a no-op.
 	metrics *observability.Metrics
+	// observer reads an event schedule's source. Nil leaves event schedules
+	// unobserved; each pass logs why.
+	observer observer
+	// pipelines and runs let the same pass start due pipelines and advance the
+	// open ones. Nil pipelines leaves pipelines unadvanced.
+	pipelines script.PipelineStore
+	runs      script.RunStore
+	// backfills lets the same pass replay the open backfills a few fires at a
+	// time. Nil leaves backfills unstarted.
+	backfills script.BackfillStore
 	// interval overrides defaultMaterializeEvery, and now overrides the clock.
 	// Both are testing hooks.
 	interval time.Duration
@@ -130,9 +140,17 @@ func (s *scheduler) Stop() {
 	s.wg.Wait()
 }
 
-// pass materializes every schedule that has come due.
+// pass materializes every schedule that has come due, starts due pipelines and
+// advances the open ones, then tops up the open backfills.
 func (s *scheduler) pass(ctx context.Context) {
 	now := s.cfg.now()
+	s.materializeDue(ctx, now)
+	s.pipelinePass(ctx, now)
+	s.backfillPass(ctx)
+}
+
+// materializeDue materializes every schedule that has come due.
+func (s *scheduler) materializeDue(ctx context.Context, now time.Time) {
 	due, err := s.cfg.schedules.DueSchedules(ctx, now, 0)
 	if err != nil {
 		if ctx.Err() == nil {
@@ -167,6 +185,10 @@ func (s *scheduler) pass(ctx context.Context) {
 // unique index answers "already materialized" and the pass moves on. The
 // reverse order would lose the fire outright.
 func (s *scheduler) materialize(ctx context.Context, sched *script.Schedule, now time.Time) {
+	if sched.Event() {
+		s.materializeEvent(ctx, sched, now)
+		return
+	}
 	cronSpec, err := script.ParseCron(sched.CronSpec, sched.Timezone)
 	if err != nil {
 		s.refuseCadence(ctx, sched, err)
@@ -245,15 +267,15 @@ func (s *scheduler) buildRun(ctx context.Context, sched *script.Schedule, fire t
 	return run
 }
 
-// current resolves the script a schedule names and its latest saved version,
-// which is the version a fire executes.
+// current resolves the script a schedule or a pipeline step names and its
+// latest saved version, which is the version a fire executes.
 func (s *scheduler) current(ctx context.Context, scriptID string) (*script.Script, *script.Version, error) {
 	sc, err := s.cfg.scripts.GetByID(ctx, scriptID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("reading the script failed: %w", err)
 	}
 	if sc == nil {
-		return nil, nil, errors.New("the script this schedule belongs to no longer exists")
+		return nil, nil, errors.New("the script no longer exists")
 	}
 	v, err := s.cfg.versions.GetVersion(ctx, sc.ID, sc.Version)
 	if err != nil {
@@ -369,3 +391,128 @@ func (s *scheduler) park(ctx context.Context, sched *script.Schedule, cause erro
 			logKeyScheduleID, logsan.SanitizeForLog(sched.ID), logKeyError, err)
 	}
 }
+
+// maxOpenBackfills bounds how many running backfills one pass tops up, oldest
+// first, matching the store's own cap.
+const maxOpenBackfills = 100
+
+// backfillPass starts the next fires of every running backfill, up to each
+// one's concurrency, and finishes the backfills that have nothing left.
+//
+// It rides the materializer's pass for the reason pipelines do. Nothing here is
+// held between passes: which fires are started is read back from the runs the
+// backfill produced, and what stops two replicas starting the same fire is the
+// (backfill, fire time) index the insert conflicts against. A replica that
+// loses that race has started one fewer run than it meant to this pass, and the
+// next pass makes up the difference.
+func (s *scheduler) backfillPass(ctx context.Context) {
+	if s.cfg.backfills == nil {
+		return
+	}
+	open, err := s.cfg.backfills.OpenBackfills(ctx, maxOpenBackfills)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("scripts: reading open backfills failed", logKeyError, err)
+		}
+		return
+	}
+	for i := range open {
+		select {
+		case <-s.stopCh:
+			return
+		default:
+		}
+		s.advanceBackfill(ctx, &open[i])
+	}
+}
+
+// advanceBackfill starts as many of one backfill's waiting fires as its
+// concurrency leaves room for, in fire order.
+func (s *scheduler) advanceBackfill(ctx context.Context, b *script.Backfill) {
+	runs, err := s.cfg.backfills.BackfillRuns(ctx, b.ID)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("scripts: reading a backfill's runs failed", "backfill_id", b.ID, logKeyError, err)
+		}
+		return
+	}
+	open := 0
+	for _, r := range runs {
+		if r.Status == script.RunStatusPending || r.Status == script.RunStatusRunning {
+			open++
+		}
+	}
+	waiting := b.Waiting(runs)
+	if len(waiting) == 0 {
+		if open == 0 {
+			s.finishBackfill(ctx, b, "", "")
+		}
+		return
+	}
+	room := min(b.Concurrency-open, len(waiting))
+	if room <= 0 {
+		return
+	}
+	// One read of the script serves every fire this pass starts: they all run
+	// the version that is current now.
+	sc, v, err := s.current(ctx, b.ScriptID)
+	if err == nil {
+		err = script.RefuseRun(sc)
+	}
+	if err != nil {
+		s.finishBackfill(ctx, b, schedulePrincipal, err.Error())
+		return
+	}
+	loc, err := b.Location()
+	if err != nil {
+		// The zone is a property of the binary, as refuseCadence explains; the
+		// backfill is left running for a build that can read it.
+		slog.Error("scripts: a backfill names a timezone this build cannot load", "backfill_id", b.ID, logKeyError, err)
+		return
+	}
+	for _, fire := range waiting[:room] {
+		params, err := script.BindScheduleParams(v.Params, b.Params, fire, loc)
+		if err != nil {
+			s.finishBackfill(ctx, b, schedulePrincipal,
+				fmt.Sprintf("its bound parameters no longer satisfy the script's contract: %v", err))
+			return
+		}
+		runID, err := pkgsession.GenerateScriptSessionID()
+		if err != nil {
+			slog.Warn("scripts: minting a backfill run id failed", "backfill_id", b.ID, logKeyError, err)
+			return
+		}
+		run := &script.Run{
+			ID: runID, ScriptID: sc.ID, VersionID: v.ID, Version: v.Version,
+			ScheduleID: b.ScheduleID, BackfillID: b.ID, Trigger: script.TriggerBackfill,
+			Params: params, RequestedBy: b.RequestedBy, FireTime: fire, ScheduledFor: fire,
+		}
+		if _, err := s.cfg.schedules.MaterializeRun(ctx, run); err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("scripts: materializing a backfill run failed", "backfill_id", b.ID, logKeyError, err)
+			}
+			return
+		}
+	}
+	if s.cfg.wake != nil {
+		s.cfg.wake()
+	}
+}
+
+// finishBackfill ends a backfill: completed when actor is empty, otherwise
+// stopped by the platform because no further fire could be materialized. The
+// stop is recorded on the backfill rather than mailed, for the reason its runs'
+// failures are.
+func (s *scheduler) finishBackfill(ctx context.Context, b *script.Backfill, actor, reason string) {
+	moved, err := s.cfg.backfills.FinishBackfill(ctx, b.ID, actor, reason)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("scripts: finishing a backfill failed", "backfill_id", b.ID, logKeyError, err)
+		}
+		return
+	}
+	if moved {
+		slog.Info("scripts: backfill finished", "backfill_id", b.ID, // #nosec G706 -- structured slog call; reason sanitized
+			"script_id", logsan.SanitizeForLog(b.ScriptID), "stopped", logsan.SanitizeForLog(reason))
+	}
+}
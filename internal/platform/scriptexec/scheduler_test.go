@@ -102,9 +102,25 @@ func (f *fakeSchedules) MaterializeRun(_ context.Context, r *script.Run) (script
 		return "", f.insertErr
 	}
 	key := r.ScheduleID + "|" + r.FireTime.String()
+	if r.EventKey != "" {
+		key = r.ScheduleID + "|" + r.EventKey
+	}
+	if r.BackfillID != "" {
+		// A backfill run answers only to its own (backfill, fire) index.
+		key = r.BackfillID + "|" + r.FireTime.String()
+	}
 	if f.fired[key] {
 		return script.MaterializedDuplicate, nil
 	}
+	if r.BackfillID != "" {
+		f.fired[key] = true
+		r.Status = script.RunStatusPending
+		f.runs = append(f.runs, *r)
+		return script.MaterializedRun, nil
+	}
+	if r.EventKey != "" && f.open[r.ScheduleID] {
+		return script.MaterializedDeferred, nil
+	}
 	f.fired[key] = true
 	if f.open[r.ScheduleID] {
 		r.Status = script.RunStatusSkippedOverlap
@@ -130,6 +146,9 @@ func (f *fakeSchedules) AdvanceSchedule(_ context.Context, adv script.ScheduleAd
 		}
 		f.schedules[i].NextRunAt = adv.Next
 		f.schedules[i].MissedFires += adv.Missed
+		if adv.Cursor != nil {
+			f.schedules[i].EventCursor = adv.Cursor
+		}
 		return true, nil
 	}
 	return false, nil
@@ -514,3 +533,109 @@ func TestScheduler_RecordsNothingWhenNoFireIsMissed(t *testing.T) {
 
 	assert.NotContains(t, scrapeWorkerMetrics(t, m), "script_missed_fires_total")
 }
+
+// fakeBackfills is an in-memory backfill store over the runs a fakeSchedules
+// materialized, which is where the real store reads them back from.
+type fakeBackfills struct {
+	*fakeSchedules
+	backfill script.Backfill
+	finished []string
+}
+
+func (*fakeBackfills) CreateBackfill(context.Context, *script.Backfill) error { return nil }
+
+func (f *fakeBackfills) GetBackfill(context.Context, string) (*script.Backfill, error) {
+	return &f.backfill, nil
+}
+
+func (*fakeBackfills) ListBackfills(context.Context, string, int) ([]script.Backfill, error) {
+	return nil, nil
+}
+
+func (f *fakeBackfills) OpenBackfills(context.Context, int) ([]script.Backfill, error) {
+	if !f.backfill.Open() {
+		return nil, nil
+	}
+	return []script.Backfill{f.backfill}, nil
+}
+
+func (f *fakeBackfills) BackfillRuns(_ context.Context, id string) ([]script.BackfillFire, error) {
+	var out []script.BackfillFire
+	for _, r := range f.materialized() {
+		if r.BackfillID == id {
+			out = append(out, script.BackfillFire{At: r.FireTime, RunID: r.ID, Status: r.Status})
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeBackfills) FinishBackfill(_ context.Context, _, actor, reason string) (bool, error) {
+	f.finished = append(f.finished, actor+"|"+reason)
+	f.backfill.Status = "completed"
+	return true, nil
+}
+
+// backfillOver assembles a materializer with one running backfill of three
+// daily fires, two at a time, and no live fire due.
+func backfillOver(t *testing.T) (*scheduler, *fakeBackfills) {
+	t.Helper()
+	fire := time.Date(2026, 8, 1, 7, 0, 0, 0, time.UTC)
+	s, store, _ := schedulerOver(t, fire.AddDate(0, 1, 0), fire.AddDate(0, 0, 10), nil)
+	sched := dueSchedule("script_1", time.Time{})
+	sched.CronSpec = "0 7 * * *"
+	b, err := sched.PlanBackfill("2026-08-01", "2026-08-03", 2, false, "jane@example.com", fire.AddDate(0, 0, 10))
+	require.NoError(t, err)
+	b.ID = "bf_1"
+	backfills := &fakeBackfills{fakeSchedules: store, backfill: *b}
+	s.cfg.backfills = backfills
+	return s, backfills
+}
+
+// TestScheduler_BackfillStartsFiresUpToItsConcurrency pins the replay: each
+// fire becomes a run carrying its own fire time and date, no more than the
+// concurrency are open at once, and the backfill completes only once every
+// fire has finished.
+func TestScheduler_BackfillStartsFiresUpToItsConcurrency(t *testing.T) {
+	s, store := backfillOver(t)
+
+	s.pass(context.Background())
+	runs := store.materialized()
+	require.Len(t, runs, 2, "two at a time")
+	assert.Equal(t, script.TriggerBackfill, runs[0].Trigger)
+	assert.Equal(t, "bf_1", runs[0].BackfillID)
+	assert.Equal(t, "sched_1", runs[0].ScheduleID, "a replayed fire reads in its schedule's history")
+	assert.Equal(t, "2026-08-01", runs[0].Params["report_date"])
+	assert.Equal(t, "2026-08-02", runs[1].Params["report_date"])
+
+	s.pass(context.Background())
+	assert.Len(t, store.materialized(), 2, "nothing more starts while both are open")
+
+	store.mu.Lock()
+	store.runs[0].Status = script.RunStatusFailed
+	store.mu.Unlock()
+	s.pass(context.Background())
+	runs = store.materialized()
+	require.Len(t, runs, 3)
+	assert.Equal(t, "2026-08-03", runs[2].Params["report_date"])
+	assert.Empty(t, store.finished)
+
+	store.mu.Lock()
+	store.runs[1].Status, store.runs[2].Status = script.RunStatusSucceeded, script.RunStatusSucceeded
+	store.mu.Unlock()
+	s.pass(context.Background())
+	assert.Equal(t, []string{"|"}, store.finished, "every fire finished, so the backfill completes")
+}
+
+// TestScheduler_BackfillOfAScriptThatCannotRunStops pins that a fire the
+// platform cannot materialize stops the backfill with the reason, rather than
+// being retried every pass for every remaining day.
+func TestScheduler_BackfillOfAScriptThatCannotRunStops(t *testing.T) {
+	s, store := backfillOver(t)
+	s.cfg.scripts = &fakeScripts{}
+
+	s.pass(context.Background())
+
+	assert.Empty(t, store.materialized())
+	require.Len(t, store.finished, 1)
+	assert.Contains(t, store.finished[0], schedulePrincipal+"|")
+}
@@ -33,12 +33,15 @@ import (
 	"github.com/txn2/mcp-data-platform/internal/notification/notifyprefs"
 	"github.com/txn2/mcp-data-platform/internal/notification/notifyqueue"
 	"github.com/txn2/mcp-data-platform/internal/pglisten"
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptdeliver"
 	"github.com/txn2/mcp-data-platform/internal/platform/scriptstore"
 	"github.com/txn2/mcp-data-platform/pkg/middleware"
 	"github.com/txn2/mcp-data-platform/pkg/notification"
+	"github.com/txn2/mcp-data-platform/pkg/notification/smtp"
 	"github.com/txn2/mcp-data-platform/pkg/observability"
 	"github.com/txn2/mcp-data-platform/pkg/portal"
 	"github.com/txn2/mcp-data-platform/pkg/script"
+	memorykit "github.com/txn2/mcp-data-platform/pkg/toolkits/memory"
 )
 
 // Structured-logging keys.
@@ -64,7 +67,7 @@ type Config struct {
 	// nil handle is a no-op.
 	DB *sql.DB
 
-	// Runs, Scripts, Versions and Schedules, when non-nil, are used directly
+	// Runs, Scripts, Versions, Schedules and Pipelines, when non-nil, are used directly
 	// instead of building PostgreSQL stores from DB. Production passes DB and
 	// leaves them nil; they exist so the execution side can be assembled over
 	// in-memory stores, which is the only way to exercise the worker, the
@@ -74,6 +77,7 @@ type Config struct {
 	Scripts   ScriptReader
 	Versions  VersionReader
 	Schedules script.ScheduleStore
+	Pipelines script.PipelineStore
 
 	// DSN is the raw database DSN for the LISTEN connection that wakes the
 	// worker the moment a run is enqueued. Empty degrades to poll-only.
@@ -89,10 +93,15 @@ type Config struct {
 	// each affected run reports.
 	Export ExportDeps
 
-	// Destinations is the deployment's configured bucket destinations, which a
-	// run resolves platform.export names against at run time.
+	// Destinations is the deployment's configured destinations, which a run
+	// resolves platform.export names against at run time.
 	Destinations []script.Destination
 
+	// Encryptor decrypts the stored SMTP password an email destination sends
+	// with, when the deliverer is built over DB, and the script secrets a run
+	// is granted.
+	Encryptor smtp.StringEncryptor
+
 	// Metrics records what the run queue is doing: runs by script, trigger and
 	// status, their duration, how many are executing on this replica, and the
 	// fires the misfire policy stepped over (#1307). Optional — every method on
@@ -103,6 +112,14 @@ type Config struct {
 	// Audit records the script_run lifecycle event. Optional.
 	Audit middleware.AuditLogger
 
+	// Insights records each failed data-quality check as a data_quality
+	// insight against the table it read, and BuildURN keys the insight to that
+	// table's dataset. Both are optional: without Insights a failed check is
+	// on the run and in its alert only, and without BuildURN the insight is
+	// recorded unkeyed.
+	Insights InsightCapturer
+	BuildURN middleware.URNBuilder
+
 	// Notifier queues the alert a failed SCHEDULED run raises. When nil and DB
 	// is set, one is built over the notification queue unless
 	// NotificationsDisabled says the deployment turned notifications off.
@@ -127,13 +144,47 @@ type Config struct {
 	WorkerDisabled bool
 }
 
-// ExportDeps is what turning a script's rows into a portal asset needs.
+// ExportDeps is what turning a script's rows into a portal asset, or a file
+// somewhere else, needs.
 type ExportDeps struct {
 	Assets   portal.AssetStore
 	Versions portal.VersionStore
 	S3       portal.S3Client
 	Bucket   string
 	Prefix   string
+
+	// Authorizer decides whether a run may write to an sftp, filesystem, or
+	// email destination, which no platform tool call carries through the
+	// middleware. Nil refuses every such write.
+	Authorizer middleware.Authorizer
+
+	// Deliver writes those destinations. When nil, one is built over DB.
+	Deliver Deliverer
+
+	// Retention reads the script's output_versions, applied as the version cap
+	// of each portal asset its runs write. When nil, one is built over DB; with
+	// neither, every output asset keeps the cap it has.
+	Retention script.RetentionStore
+}
+
+// Deliverer writes one file to a destination the platform reaches itself.
+// Satisfied by *scriptdeliver.Deliverer.
+type Deliverer interface {
+	Deliver(ctx context.Context, dest script.Destination, f scriptdeliver.File) error
+}
+
+// newDeliverer builds the deliverer over the SMTP settings in db, for the
+// reason newNotifier builds the enqueuer here: the execution side may run on a
+// replica that assembles nothing else.
+func newDeliverer(cfg Config) Deliverer {
+	if cfg.Export.Deliver != nil {
+		return cfg.Export.Deliver
+	}
+	dc := scriptdeliver.Config{}
+	if cfg.DB != nil {
+		dc.Settings = smtp.NewPostgresStore(cfg.DB, cfg.Encryptor)
+	}
+	return scriptdeliver.New(dc)
 }
 
 // ready reports whether an output can actually be written.
@@ -164,10 +215,29 @@ func newNotifier(cfg Config) Notifier {
 	if cfg.DB == nil || cfg.NotificationsDisabled {
 		return nil
 	}
-	return notification.NewEnqueuer(
+	enq := notification.NewEnqueuer(
 		notifyprefs.NewPostgresStore(cfg.DB),
 		notifyqueue.NewPostgresStore(cfg.DB),
 		cfg.DigestHourUTC)
+	// Resolving a route needs no key: the send worker opens the channel.
+	enq.SetChannels(notifyprefs.NewChannelStore(cfg.DB, nil))
+	return enq
+}
+
+// InsightCapturer records platform-minted memory. Satisfied by
+// *memorykit.Toolkit, whose AutoCapture takes a capture through the same
+// review and dedup path an agent's memory_capture takes.
+type InsightCapturer interface {
+	AutoCapture(ctx context.Context, in memorykit.AutoCaptureInput) (*memorykit.CaptureResult, error)
+}
+
+// insightCapturer resolves the configured capturer, reading a typed nil — what
+// the memory layer hands over on a deployment without memory — as none.
+func insightCapturer(c InsightCapturer) InsightCapturer {
+	if tk, ok := c.(*memorykit.Toolkit); ok && tk == nil {
+		return nil
+	}
+	return c
 }
 
 // listenerControl narrows the LISTEN adapter to the two calls the handle makes,
@@ -230,6 +300,10 @@ func New(cfg Config) *Handle {
 		versions:  stores.versions,
 		wake:      h.worker.Notify,
 		metrics:   cfg.Metrics,
+		observer:  &toolObserver{server: cfg.Server, runs: stores.runs},
+		pipelines: stores.pipelines,
+		runs:      stores.runs,
+		backfills: backfillStore(stores.schedules),
 	})
 	if cfg.DSN != "" {
 		h.listener = pglisten.New(cfg.DSN, scriptstore.NotifyChannel, h)
@@ -246,8 +320,9 @@ func New(cfg Config) *Handle {
 // in-memory run, script, and version stores and no schedule store is the
 // execution side with no scheduling, which the materializer reads as "this
 // deployment does not schedule" rather than as a broken configuration.
+// Pipelines may be absent on the same terms.
 func (c Config) stores() storeSet {
-	runs, scripts, versions, schedules := c.Runs, c.Scripts, c.Versions, c.Schedules
+	runs, scripts, versions, schedules, pipelines := c.Runs, c.Scripts, c.Versions, c.Schedules, c.Pipelines
 	if c.DB != nil {
 		store := scriptstore.New(c.DB)
 		if runs == nil {
@@ -262,11 +337,14 @@ func (c Config) stores() storeSet {
 		if schedules == nil {
 			schedules = store
 		}
+		if pipelines == nil {
+			pipelines = store
+		}
 	}
 	if runs == nil || scripts == nil || versions == nil {
 		return storeSet{}
 	}
-	return storeSet{runs: runs, scripts: scripts, versions: versions, schedules: schedules}
+	return storeSet{runs: runs, scripts: scripts, versions: versions, schedules: schedules, pipelines: pipelines}
 }
 
 // storeSet is the resolved set of stores the execution side reads and writes.
@@ -277,6 +355,15 @@ type storeSet struct {
 	scripts   ScriptReader
 	versions  VersionReader
 	schedules script.ScheduleStore
+	pipelines script.PipelineStore
+}
+
+// backfillStore is the schedule store's backfill half, when it has one. The
+// PostgreSQL store does; an in-memory schedule store without it leaves
+// backfills unstarted, as a missing pipeline store leaves pipelines.
+func backfillStore(schedules script.ScheduleStore) script.BackfillStore {
+	b, _ := schedules.(script.BackfillStore)
+	return b
 }
 
 // orDefaultRetention applies the default when a deployment names no retention.
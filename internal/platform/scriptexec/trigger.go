@@ -0,0 +1,335 @@
+package scriptexec
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/txn2/mcp-data-platform/internal/logsan"
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptrun"
+	"github.com/txn2/mcp-data-platform/pkg/script"
+	pkgsession "github.com/txn2/mcp-data-platform/pkg/session"
+)
+
+// The tools a trigger's source is read through. They are ordinary platform tool
+// calls over a session opened as the script principal, so an observation is
+// authorized, rate limited, and audited exactly as the script's own calls are.
+const (
+	toolS3List      = "s3_list_objects"
+	toolTrinoQuery  = "trino_query"
+	toolDataHubGet  = "datahub_get_entity"
+	observerSession = "script-trigger"
+)
+
+// maxObservePages bounds how many listing pages one s3_object observation
+// reads. The newest object can sit anywhere in a key-ordered listing, so an
+// observation reads the whole prefix; one past this many pages (100,000
+// objects at the tool's default page size) fails rather than report a key
+// that may miss new objects.
+const maxObservePages = 100
+
+// observer reads the current state of a trigger's source as one comparable
+// key. An empty key means the source is empty.
+type observer interface {
+	observe(ctx context.Context, sc *script.Script, v *script.Version, t *script.Trigger) (string, error)
+}
+
+// toolObserver observes a source through the platform's own tools, or, for a
+// script_run trigger, through the run history.
+type toolObserver struct {
+	server *mcp.Server
+	runs   script.RunStore
+}
+
+// observe implements observer.
+func (o *toolObserver) observe(ctx context.Context, sc *script.Script, v *script.Version, t *script.Trigger) (string, error) {
+	if t.Kind == script.TriggerScriptRun {
+		return o.lastSuccess(ctx, t.ScriptID)
+	}
+	if o.server == nil {
+		return "", errors.New("observing a trigger's source needs the platform's tools, and this deployment has none")
+	}
+	sessionID, err := pkgsession.GenerateScriptSessionID()
+	if err != nil {
+		return "", fmt.Errorf("minting an observation session id: %w", err)
+	}
+	caller, cleanup, err := scriptrun.Connect(principalContext(ctx, sessionID, sc, v), o.server, observerSession)
+	if err != nil {
+		return "", fmt.Errorf("opening the observation session: %w", err)
+	}
+	defer cleanup()
+	switch t.Kind {
+	case script.TriggerS3Object:
+		return observeS3(ctx, caller, t)
+	case script.TriggerTrinoPartition:
+		return observePartition(ctx, caller, t)
+	case script.TriggerDataHubEntity:
+		return observeEntity(ctx, caller, t)
+	default:
+		return "", fmt.Errorf("unknown trigger kind %q", t.Kind)
+	}
+}
+
+// lastSuccess keys a script_run trigger on the followed script's most recent
+// successful run.
+func (o *toolObserver) lastSuccess(ctx context.Context, scriptID string) (string, error) {
+	if o.runs == nil {
+		return "", errors.New("observing another script's runs needs the run store")
+	}
+	runs, err := o.runs.ListRuns(ctx, script.RunFilter{
+		ScriptID: scriptID, Status: script.RunStatusSucceeded, Limit: 1,
+	})
+	if err != nil {
+		return "", fmt.Errorf("reading the followed script's runs: %w", err)
+	}
+	if len(runs) == 0 {
+		return "", nil
+	}
+	return runs[0].ID, nil
+}
+
+// observeS3 keys an s3_object trigger on the newest object under the prefix:
+// its modification time and its key, so a new object and a replaced one both
+// read as a change.
+func observeS3(ctx context.Context, caller scriptrun.Caller, t *script.Trigger) (string, error) {
+	args := map[string]any{"bucket": t.Bucket, "prefix": t.Prefix}
+	withConnection(args, t.Connection)
+	var newest s3Newest
+	for page := 0; ; page++ {
+		if page == maxObservePages {
+			return "", fmt.Errorf("the watched prefix lists more than %d pages of objects; watch a narrower prefix", maxObservePages)
+		}
+		out, err := caller.CallTool(ctx, toolS3List, args)
+		if err != nil {
+			return "", fmt.Errorf("listing the watched prefix: %w", err)
+		}
+		objects, _ := out["objects"].([]any)
+		newest.consider(objects)
+		token, _ := out["next_continuation_token"].(string)
+		if truncated, _ := out["is_truncated"].(bool); !truncated || token == "" {
+			break
+		}
+		args["continuation_token"] = token
+	}
+	if newest.key == "" {
+		return "", nil
+	}
+	return newest.at.UTC().Format(time.RFC3339Nano) + " " + newest.key, nil
+}
+
+// s3Newest is the newest object an s3_object observation has listed so far.
+type s3Newest struct {
+	at  time.Time
+	key string
+}
+
+// consider folds one listing page into the newest object. Ties on the
+// modification time go to the greater key, so the result does not depend on
+// page order.
+func (n *s3Newest) consider(objects []any) {
+	for _, raw := range objects {
+		obj, _ := raw.(map[string]any)
+		name, _ := obj["key"].(string)
+		modified, _ := obj["last_modified"].(string)
+		at, err := time.Parse(time.RFC3339, modified)
+		if name == "" || err != nil {
+			continue
+		}
+		if at.After(n.at) || (at.Equal(n.at) && name > n.key) {
+			n.at, n.key = at, name
+		}
+	}
+}
+
+// observePartition keys a trino_partition trigger on the greatest value of
+// the partition column. It reads max(column) over the table itself rather than
+// the Hive connector's $partitions table, which Iceberg and Delta Lake shape
+// differently and other connectors lack, at the cost of reading the column.
+// The identifiers were validated as plain names when the trigger was set,
+// which is what makes quoting them here sufficient.
+func observePartition(ctx context.Context, caller scriptrun.Caller, t *script.Trigger) (string, error) {
+	catalog, schema, table, ok := t.TableParts()
+	if !ok {
+		return "", fmt.Errorf("the trigger's table %q is not catalog.schema.table", t.Table)
+	}
+	args := map[string]any{
+		"sql": fmt.Sprintf(`SELECT CAST(max(%q) AS varchar) AS watermark FROM %q.%q.%q`,
+			t.Column, catalog, schema, table),
+		"limit": 1,
+	}
+	withConnection(args, t.Connection)
+	out, err := caller.CallTool(ctx, toolTrinoQuery, args)
+	if err != nil {
+		return "", fmt.Errorf("reading the watched table's partitions: %w", err)
+	}
+	rows, _ := out["rows"].([]any)
+	if len(rows) == 0 {
+		return "", nil
+	}
+	var value any
+	switch row := rows[0].(type) {
+	case map[string]any:
+		value = row["watermark"]
+	case []any:
+		if len(row) > 0 {
+			value = row[0]
+		}
+	}
+	if value == nil {
+		return "", nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// observeEntity keys a datahub_entity trigger on a digest of everything
+// DataHub reports about the entity. json.Marshal sorts map keys, so the same
+// entity always produces the same digest.
+func observeEntity(ctx context.Context, caller scriptrun.Caller, t *script.Trigger) (string, error) {
+	args := map[string]any{"urn": t.URN}
+	withConnection(args, t.Connection)
+	out, err := caller.CallTool(ctx, toolDataHubGet, args)
+	if err != nil {
+		return "", fmt.Errorf("reading the watched entity: %w", err)
+	}
+	body, err := json.Marshal(out)
+	if err != nil {
+		return "", fmt.Errorf("encoding the watched entity: %w", err)
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// withConnection names the connection a call goes through, when the trigger
+// names one.
+func withConnection(args map[string]any, connection string) {
+	if connection != "" {
+		args["connection"] = connection
+	}
+}
+
+// materializeEvent observes an event schedule's source and, when it changed,
+// writes the run it fires.
+//
+// The order matches materialize: the run is written first and the cursor moved
+// afterwards, so a process that dies between them observes the same change
+// again on the next pass, where the unique index on (schedule, event key)
+// answers "already materialized".
+func (s *scheduler) materializeEvent(ctx context.Context, sched *script.Schedule, now time.Time) {
+	adv := script.ScheduleAdvance{ID: sched.ID, From: sched.NextRunAt, Next: now.Add(sched.Trigger.Interval())}
+	sc, v, err := s.current(ctx, sched.ScriptID)
+	if err != nil {
+		refuse(sched, err)
+		s.advance(ctx, sched, adv)
+		return
+	}
+	if s.cfg.observer == nil {
+		refuse(sched, errors.New("this replica cannot observe a trigger's source"))
+		s.advance(ctx, sched, adv)
+		return
+	}
+	key, err := s.cfg.observer.observe(ctx, sc, v, sched.Trigger)
+	if err != nil {
+		// Not a missed fire: nothing is known to have changed. The source is
+		// observed again on the next interval.
+		if ctx.Err() == nil {
+			slog.Warn("scripts: observing a trigger's source failed", // #nosec G706 -- structured slog call; ids sanitized
+				logKeyScheduleID, logsan.SanitizeForLog(sched.ID),
+				"trigger", logsan.SanitizeForLog(string(sched.Trigger.Kind)),
+				logKeyError, logsan.SanitizeForLog(err.Error()))
+		}
+		s.advance(ctx, sched, adv)
+		return
+	}
+	fire := sched.Observed(key, now)
+	adv.Next = fire.Next
+	if !fire.Due {
+		if sched.EventCursor == nil || *sched.EventCursor != key {
+			adv.Cursor = &fire.Key
+		}
+		s.advance(ctx, sched, adv)
+		return
+	}
+	run := buildEventRun(sched, sc, v, fire.Key, now)
+	if run == nil {
+		// Refused, and counted missed as a refused cron fire is. The change is
+		// consumed: firing it later, once the script is runnable again, would
+		// run the report for a change nobody is waiting on any more.
+		adv.Missed++
+		adv.Cursor = &fire.Key
+		s.advance(ctx, sched, adv)
+		return
+	}
+	outcome, ok := s.insertEvent(ctx, sched, run)
+	if !ok {
+		// The write failed, so the change is recorded nowhere. Leaving the
+		// schedule where it is makes the next pass observe it again.
+		return
+	}
+	if outcome != script.MaterializedDeferred {
+		adv.Fired = now
+		adv.Cursor = &fire.Key
+	}
+	s.advance(ctx, sched, adv)
+}
+
+// buildEventRun assembles the run one observed change produces, or nil when it
+// must not produce one. The fire time is the moment of the observation.
+func buildEventRun(sched *script.Schedule, sc *script.Script, v *script.Version, key string, now time.Time) *script.Run {
+	if refusal := script.RefuseRun(sc); refusal != nil {
+		refuse(sched, refusal)
+		return nil
+	}
+	loc, err := sched.Location()
+	if err != nil {
+		refuse(sched, err)
+		return nil
+	}
+	params, err := script.BindScheduleParams(v.Params, sched.Params, now, loc)
+	if err != nil {
+		refuse(sched, fmt.Errorf("its bound parameters no longer satisfy the script's contract: %w", err))
+		return nil
+	}
+	runID, err := pkgsession.GenerateScriptSessionID()
+	if err != nil {
+		refuse(sched, fmt.Errorf("minting a run id failed: %w", err))
+		return nil
+	}
+	return &script.Run{
+		ID: runID, ScriptID: sc.ID, VersionID: v.ID, Version: v.Version,
+		ScheduleID: sched.ID, Trigger: script.TriggerEvent, EventKey: key, Params: params,
+		RequestedBy: sched.CreatedBy, FireTime: now, ScheduledFor: now,
+	}
+}
+
+// insertEvent writes an event run and reports its outcome, with false only when
+// the write failed outright.
+func (s *scheduler) insertEvent(ctx context.Context, sched *script.Schedule, run *script.Run) (script.Materialization, bool) {
+	outcome, err := s.cfg.schedules.MaterializeRun(ctx, run)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("scripts: materializing an event run failed",
+				logKeyScheduleID, logsan.SanitizeForLog(sched.ID), logKeyError, err)
+		}
+		return "", false
+	}
+	switch outcome {
+	case script.MaterializedRun:
+		slog.Info("scripts: trigger fired", logKeyScheduleID, logsan.SanitizeForLog(sched.ID),
+			logKeyRunID, run.ID, "trigger", string(sched.Trigger.Kind))
+		if s.cfg.wake != nil {
+			s.cfg.wake()
+		}
+	case script.MaterializedDeferred:
+		slog.Info("scripts: trigger fire deferred; the previous run is still going",
+			logKeyScheduleID, logsan.SanitizeForLog(sched.ID))
+	case script.MaterializedDuplicate, script.MaterializedSkippedOverlap:
+	}
+	return outcome, true
+}
@@ -0,0 +1,233 @@
+package scriptexec
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+// fakeObserver answers every observation with one key, or fails.
+type fakeObserver struct {
+	key   string
+	err   error
+	calls int
+}
+
+func (f *fakeObserver) observe(context.Context, *script.Script, *script.Version, *script.Trigger) (string, error) {
+	f.calls++
+	return f.key, f.err
+}
+
+// eventSchedulerOver assembles a materializer over one event schedule, due at
+// now, whose last observation is cursor.
+func eventSchedulerOver(t *testing.T, now time.Time, cursor *string, obs *fakeObserver) (*scheduler, *fakeSchedules) {
+	t.Helper()
+	sc, v, _ := executableState()
+	v.Params = []script.Param{{Name: "report_date", Type: script.ParamTypeDate, Required: true}}
+	sched := dueSchedule(sc.ID, now)
+	sched.CronSpec = ""
+	sched.Trigger = &script.Trigger{Kind: script.TriggerS3Object, Bucket: "landing", Every: "10m"}
+	sched.EventCursor = cursor
+	store := newFakeSchedules(sched)
+	s := newScheduler(schedulerConfig{
+		schedules: store,
+		scripts:   &fakeScripts{script: sc},
+		versions:  &fakeVersions{version: v},
+		observer:  obs,
+		now:       func() time.Time { return now },
+	})
+	require.NotNil(t, s)
+	return s, store
+}
+
+func TestScheduler_AnObservedChangeBecomesAnEventRun(t *testing.T) {
+	now := time.Date(2026, 8, 14, 7, 3, 0, 0, time.UTC)
+	seen := "old"
+	s, store := eventSchedulerOver(t, now, &seen, &fakeObserver{key: "new"})
+
+	s.pass(context.Background())
+
+	runs := store.materialized()
+	require.Len(t, runs, 1)
+	assert.Equal(t, script.TriggerEvent, runs[0].Trigger)
+	assert.Equal(t, "new", runs[0].EventKey)
+	assert.True(t, runs[0].FireTime.Equal(now), "an event run computes against the observation")
+	assert.Equal(t, "2026-08-14", runs[0].Params["report_date"])
+
+	require.Len(t, store.advances, 1)
+	adv := store.advances[0]
+	assert.True(t, adv.Fired.Equal(now))
+	require.NotNil(t, adv.Cursor)
+	assert.Equal(t, "new", *adv.Cursor)
+	assert.True(t, adv.Next.Equal(now.Add(10*time.Minute)))
+}
+
+func TestScheduler_TheFirstObservationIsABaseline(t *testing.T) {
+	now := time.Date(2026, 8, 14, 7, 3, 0, 0, time.UTC)
+	s, store := eventSchedulerOver(t, now, nil, &fakeObserver{key: "existing"})
+
+	s.pass(context.Background())
+
+	assert.Empty(t, store.materialized(), "data already there when the trigger was set is not news")
+	require.Len(t, store.advances, 1)
+	require.NotNil(t, store.advances[0].Cursor)
+	assert.Equal(t, "existing", *store.advances[0].Cursor)
+	assert.True(t, store.advances[0].Fired.IsZero())
+}
+
+func TestScheduler_AnUnchangedSourceOnlyMovesThePoll(t *testing.T) {
+	now := time.Date(2026, 8, 14, 7, 3, 0, 0, time.UTC)
+	seen := "same"
+	s, store := eventSchedulerOver(t, now, &seen, &fakeObserver{key: "same"})
+
+	s.pass(context.Background())
+
+	assert.Empty(t, store.materialized())
+	require.Len(t, store.advances, 1)
+	assert.Nil(t, store.advances[0].Cursor)
+	assert.True(t, store.advances[0].Next.After(now))
+}
+
+// A change that arrives while the previous run is open is deferred, not
+// skipped: the cursor stays put so the next observation fires it.
+func TestScheduler_AnEventDuringAnOpenRunIsDeferred(t *testing.T) {
+	now := time.Date(2026, 8, 14, 7, 3, 0, 0, time.UTC)
+	seen := "old"
+	s, store := eventSchedulerOver(t, now, &seen, &fakeObserver{key: "new"})
+	store.open["sched_1"] = true
+
+	s.pass(context.Background())
+
+	assert.Empty(t, store.materialized())
+	require.Len(t, store.advances, 1)
+	assert.Nil(t, store.advances[0].Cursor, "the change is left unconsumed")
+	assert.True(t, store.advances[0].Fired.IsZero())
+}
+
+func TestScheduler_AFailedObservationFiresNothing(t *testing.T) {
+	now := time.Date(2026, 8, 14, 7, 3, 0, 0, time.UTC)
+	seen := "old"
+	s, store := eventSchedulerOver(t, now, &seen, &fakeObserver{err: errors.New("access denied")})
+
+	s.pass(context.Background())
+
+	assert.Empty(t, store.materialized())
+	require.Len(t, store.advances, 1)
+	assert.Nil(t, store.advances[0].Cursor)
+	assert.Zero(t, store.advances[0].Missed, "nothing is known to have changed")
+}
+
+func TestScheduler_AFailedEventWriteLeavesTheScheduleDue(t *testing.T) {
+	now := time.Date(2026, 8, 14, 7, 3, 0, 0, time.UTC)
+	seen := "old"
+	s, store := eventSchedulerOver(t, now, &seen, &fakeObserver{key: "new"})
+	store.insertErr = errors.New("connection reset")
+
+	s.pass(context.Background())
+
+	assert.Empty(t, store.advances, "the next pass observes the same change again")
+}
+
+func TestScheduler_ARefusedEventIsConsumedAndCountedMissed(t *testing.T) {
+	now := time.Date(2026, 8, 14, 7, 3, 0, 0, time.UTC)
+	seen := "old"
+	s, store := eventSchedulerOver(t, now, &seen, &fakeObserver{key: "new"})
+	s.cfg.scripts = &fakeScripts{script: &script.Script{
+		ID: "script_1", Name: "daily", Enabled: false, Status: script.StatusActive, Version: 3,
+	}}
+
+	s.pass(context.Background())
+
+	assert.Empty(t, store.materialized())
+	require.Len(t, store.advances, 1)
+	assert.Equal(t, 1, store.advances[0].Missed)
+	require.NotNil(t, store.advances[0].Cursor)
+	assert.Equal(t, "new", *store.advances[0].Cursor)
+}
+
+func TestObserveS3_KeysOnTheNewestObject(t *testing.T) {
+	caller := &fakeCaller{result: map[string]any{
+		"objects": []any{
+			map[string]any{"key": "sales/a.csv", "last_modified": "2026-08-14T06:00:00Z"},
+			map[string]any{"key": "sales/b.csv", "last_modified": "2026-08-14T07:00:00Z"},
+			map[string]any{"key": "sales/c.csv", "last_modified": "2026-08-13T07:00:00Z"},
+		},
+		"is_truncated": false,
+	}}
+
+	key, err := observeS3(context.Background(), caller, &script.Trigger{
+		Kind: script.TriggerS3Object, Bucket: "landing", Prefix: "sales/", Connection: "lake",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "2026-08-14T07:00:00Z sales/b.csv", key)
+	require.Len(t, caller.calls, 1)
+	assert.Equal(t, toolS3List, caller.calls[0].tool)
+	assert.Equal(t, "lake", caller.calls[0].args["connection"])
+}
+
+func TestObserveS3_APrefixPastThePageCapFails(t *testing.T) {
+	caller := &fakeCaller{result: map[string]any{
+		"objects":                 []any{map[string]any{"key": "sales/a.csv", "last_modified": "2026-08-14T06:00:00Z"}},
+		"is_truncated":            true,
+		"next_continuation_token": "more",
+	}}
+	_, err := observeS3(context.Background(), caller, &script.Trigger{Kind: script.TriggerS3Object, Bucket: "landing"})
+	require.ErrorContains(t, err, "narrower prefix")
+	assert.Len(t, caller.calls, maxObservePages, "every page up to the cap was read")
+}
+
+func TestObserveS3_AnEmptyPrefixIsAnEmptyKey(t *testing.T) {
+	key, err := observeS3(context.Background(), &fakeCaller{result: map[string]any{"objects": []any{}}},
+		&script.Trigger{Kind: script.TriggerS3Object, Bucket: "landing"})
+	require.NoError(t, err)
+	assert.Empty(t, key)
+}
+
+func TestObservePartition_QuotesTheIdentifiers(t *testing.T) {
+	caller := &fakeCaller{result: map[string]any{
+		"rows": []any{map[string]any{"watermark": "2026-08-14"}},
+	}}
+
+	key, err := observePartition(context.Background(), caller, &script.Trigger{
+		Kind: script.TriggerTrinoPartition, Table: "hive.sales.orders", Column: "dt",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "2026-08-14", key)
+	require.Len(t, caller.calls, 1)
+	assert.Equal(t, toolTrinoQuery, caller.calls[0].tool)
+	assert.Equal(t, `SELECT CAST(max("dt") AS varchar) AS watermark FROM "hive"."sales"."orders"`,
+		caller.calls[0].args["sql"])
+}
+
+func TestObserveEntity_IsStableAndChangesWithTheEntity(t *testing.T) {
+	trigger := &script.Trigger{Kind: script.TriggerDataHubEntity, URN: "urn:li:dataset:(x)"}
+	observe := func(desc string) string {
+		key, err := observeEntity(context.Background(),
+			&fakeCaller{result: map[string]any{"urn": "urn:li:dataset:(x)", "description": desc}}, trigger)
+		require.NoError(t, err)
+		return key
+	}
+	assert.Equal(t, observe("orders"), observe("orders"))
+	assert.NotEqual(t, observe("orders"), observe("orders, deduplicated"))
+}
+
+func TestToolObserver_ScriptRunKeysOnTheLatestSuccess(t *testing.T) {
+	o := &toolObserver{runs: &fakeRuns{}}
+	key, err := o.observe(context.Background(), nil, nil,
+		&script.Trigger{Kind: script.TriggerScriptRun, ScriptID: "script_2"})
+	require.NoError(t, err)
+	assert.Empty(t, key, "a script that has never succeeded is an empty source")
+}
+
+func TestToolObserver_NoServerRefusesToolSources(t *testing.T) {
+	o := &toolObserver{}
+	_, err := o.observe(context.Background(), nil, nil,
+		&script.Trigger{Kind: script.TriggerS3Object, Bucket: "landing"})
+	require.Error(t, err)
+}
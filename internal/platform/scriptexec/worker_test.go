@@ -32,6 +32,7 @@ type fakeRuns struct {
 	finished []script.RunResult
 	retried  []string
 	outputs  []script.RunOutput
+	logs     []string
 	// claims counts every claim attempt, which is how a replica that must not
 	// claim is held to it.
 	claims   int
@@ -116,6 +117,16 @@ func (f *fakeRuns) RecordOutput(_ context.Context, lease script.RunLease, out sc
 	return nil
 }
 
+func (f *fakeRuns) RecordLog(_ context.Context, lease script.RunLease, log string, _ bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.held(lease); err != nil {
+		return err
+	}
+	f.logs = append(f.logs, log)
+	return nil
+}
+
 func (f *fakeRuns) Finish(_ context.Context, lease script.RunLease, res script.RunResult) error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
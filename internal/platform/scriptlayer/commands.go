@@ -27,15 +27,19 @@ func (h *Handle) handleCreate(ctx context.Context, input manageScriptInput) (*mc
 		Category: derefOr(input.Category), Source: input.Source, Params: input.Params,
 		Tags:       orEmpty(input.Tags),
 		OwnerEmail: resolveEmail(ctx), Enabled: true, Status: script.StatusActive,
+		Library: input.Library,
 	}
 	if err := sc.Validate(); err != nil {
 		return errorResult(err.Error()), nil, nil
 	}
-	if report := scriptrun.Validate(sc.Source); !report.OK {
-		return jsonResult(refusedReport("the source does not parse, so it was not saved", report))
+	if report := scriptrun.ValidateScript(sc); !report.OK {
+		return jsonResult(refusedReport("the source does not validate, so it was not saved", report))
 	}
 	author := callerAuthor(ctx)
 	if err := h.store.Create(ctx, sc, author); err != nil {
+		if errors.Is(err, script.ErrDependency) {
+			return errorResult(err.Error()), nil, nil
+		}
 		slog.Error("failed to create script", fieldName, input.Name, logKeyError, err)
 		return errorResult("failed to create script"), nil, nil
 	}
@@ -43,6 +47,10 @@ func (h *Handle) handleCreate(ctx context.Context, input manageScriptInput) (*mc
 		fieldStatus: "created", "id": sc.ID, fieldName: sc.Name, fieldVersion: sc.Version,
 		"next": "Saved, and it runs: run_script executes it under the access you held when you saved it, and a schedule you set will fire it. Use run_draft to iterate on changes before saving them.",
 	}
+	if sc.Library {
+		out["next"] = fmt.Sprintf("Saved as a library: a script loads it with load(\"%s@%d\", ...), and it never runs on its own.",
+			sc.Name, sc.Version)
+	}
 	addDescriptionNotice(out, sc)
 	return jsonResult(out)
 }
@@ -93,11 +101,11 @@ func (h *Handle) handleUpdate(ctx context.Context, input manageScriptInput) (*mc
 // the resulting record as a whole.
 func (h *Handle) applyUpdates(ctx context.Context, sc *script.Script, input manageScriptInput) *mcp.CallToolResult {
 	if input.Source != "" {
-		if report := scriptrun.Validate(input.Source); !report.OK {
-			result, _, _ := jsonResult(refusedReport("the source does not parse, so the edit was not saved", report))
+		sc.Source = input.Source
+		if report := scriptrun.ValidateScript(sc); !report.OK {
+			result, _, _ := jsonResult(refusedReport("the source does not validate, so the edit was not saved", report))
 			return result
 		}
-		sc.Source = input.Source
 	}
 	if input.Params != nil {
 		sc.Params = input.Params
@@ -185,7 +193,7 @@ func runsNow(sc *script.Script) string {
 // detail stays in the log.
 func editError(err error) *mcp.CallToolResult {
 	switch {
-	case errors.Is(err, script.ErrVersionConflict):
+	case errors.Is(err, script.ErrVersionConflict), errors.Is(err, script.ErrDependency):
 		return errorResult(err.Error())
 	default:
 		slog.Error("failed to update script", logKeyError, err)
@@ -203,6 +211,9 @@ func (h *Handle) handleDelete(ctx context.Context, input manageScriptInput) (*mc
 		return errResult, nil, nil
 	}
 	if err := h.store.Delete(ctx, existing.ID); err != nil {
+		if errors.Is(err, script.ErrDependency) {
+			return errorResult(err.Error()), nil, nil
+		}
 		slog.Error("failed to delete script", fieldName, existing.Name, logKeyError, err)
 		return errorResult("failed to delete script"), nil, nil
 	}
@@ -93,12 +93,11 @@ func (h *Handle) handlePatch(ctx context.Context, input manageScriptInput) (*mcp
 		report["message"] = "Dry run: no version was created."
 		return jsonResult(report)
 	}
-	if validation := scriptrun.Validate(res.Body); !validation.OK {
-		return jsonResult(refusedReport("the patched source does not parse, so the edit was not saved", validation))
-	}
-
 	before := *existing
 	existing.Source = res.Body
+	if validation := scriptrun.ValidateScript(existing); !validation.OK {
+		return jsonResult(refusedReport("the patched source does not validate, so the edit was not saved", validation))
+	}
 	// The record check that create and update both run. Without it a patch is
 	// the one way past it: an edit that deletes the whole body leaves an empty
 	// source that parses fine, and repeated inserts walk a script past the size
@@ -155,6 +155,17 @@ func (m *memRuns) RecordOutput(_ context.Context, lease script.RunLease, out scr
 	return nil
 }
 
+func (m *memRuns) RecordLog(_ context.Context, lease script.RunLease, log string, truncated bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, err := m.held(lease)
+	if err != nil {
+		return err
+	}
+	r.Log, r.LogTruncated = log, truncated
+	return nil
+}
+
 func (m *memRuns) Finish(_ context.Context, lease script.RunLease, res script.RunResult) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
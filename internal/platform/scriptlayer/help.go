@@ -23,7 +23,9 @@ const manageScriptDescription = "Author, validate, and dry-run managed scripts:
 	"reports what the script would reach, runs nothing), then run_draft (executes for real under YOUR " +
 	"identity and persona, with tighter limits, persisting nothing). " +
 	"A saved script runs: run_script executes its latest saved version as the script's own principal, " +
-	"presenting the roles you held when you saved it, and a schedule fires it the same way."
+	"presenting the roles you held when you saved it, and a schedule fires it the same way. " +
+	"A pipeline (pipeline_set) runs several of your scripts as one process, each step once the steps it " +
+	"depends on have succeeded, and can hand a step what an earlier one published."
 
 // DialectContract is the help command's body: what a script is, what is
 // predeclared, and what a Python instinct will reach for and not find.
@@ -128,6 +130,18 @@ WHAT IS AVAILABLE
       A run executes one at a time, so a script waiting on a run it started
       would wait on the worker running it. Give the second script its own
       schedule.
+  platform.secret(name)  A secret an operator stored and granted to THIS
+      version of the script. It is a handle, not a string: it prints as
+      <secret "name">, cannot be concatenated, encoded or exported, and opens
+      in exactly two places: as a value of platform.call's "headers" dict
+      (platform.call("api_invoke_endpoint", {..., "headers": {"X-Api-Key":
+      platform.secret("acme")}})) and as platform.hmac's key. Editing the
+      script ends the grant until an operator grants the new version, and a
+      draft run has no secrets at all.
+  platform.hmac(key, message, algorithm="sha256", encoding="hex")  Signs
+      message with a secret handle (sha256 or sha512; hex or base64) and
+      returns the signature, which may go anywhere, e.g. a webhook's
+      signature header.
   print(...)  Goes to the run log (capped; anything larger is an export).
   run.run_id, run.fire_time, run.params["name"]  The frozen run record.
       A parameter is typed string, int, float, bool, date, enum or connection.
@@ -145,9 +159,15 @@ WHAT IS AVAILABLE
   The Starlark built-ins: len, range, sorted, min, max, enumerate, zip,
       str, int, float, dict, list, set, any, all, fail, and the string, list and
       dict methods (including "{}".format(x) and "%d" % x).
+  load("fiscal-calendar@3", "quarter", fmt = "format_row")  Binds what a
+      library script defines. The version is required: a new version of the
+      library changes nothing here until this source moves its pin. A library
+      is created with library=true, has json, date and sum but no platform or
+      run, and never runs on its own; hand it the rows you queried.
 
 WHAT IS NOT, AND WHAT TO WRITE INSTEAD
-  import              There is no module system. json and date are already here.
+  import              There is no import. json and date are already here, and
+                      shared code is a library reached with load().
   try / except        Errors fail the run by design, so the failure is recorded
                       rather than swallowed. Check first, or call fail("why").
   while               Unbounded loops are off so a script's cost is readable
@@ -163,7 +183,8 @@ WHAT IS NOT, AND WHAT TO WRITE INSTEAD
                       API through a configured connection with
                       platform.call("api_invoke_endpoint", {...}).
   credentials         Never in the source. Name a connection; the platform holds
-                      its credentials and authorizes the call.
+                      its credentials and authorizes the call. A key a connection
+                      does not cover is a secret: platform.secret(name).
 
 WHAT DETERMINISTIC MEANS HERE
   Same script version + same parameters + same underlying data produce the same
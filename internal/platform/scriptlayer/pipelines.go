@@ -0,0 +1,298 @@
+package scriptlayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+// pipelineStepInput is one step pipeline_set accepts: script.PipelineStep with
+// the script named, as every other argument names one, rather than by id.
+type pipelineStepInput struct {
+	Name      string         `json:"name"`
+	Script    string         `json:"script"`
+	DependsOn []string       `json:"depends_on,omitempty"`
+	Params    map[string]any `json:"params,omitempty"`
+}
+
+// pipelineOwner is whose pipelines a command addresses: the caller's, or, for
+// an administrator naming owner_email, that person's.
+func (h *Handle) pipelineOwner(ctx context.Context, input manageScriptInput) string {
+	if input.OwnerEmail != "" && h.isAdminPersona(ctx) {
+		return input.OwnerEmail
+	}
+	return resolveEmail(ctx)
+}
+
+// namedPipeline resolves the pipeline a command names. A pipeline is looked up
+// under its owner, so the owner-or-admin rule is the lookup itself: a caller
+// naming somebody else's pipeline finds none.
+func (h *Handle) namedPipeline(ctx context.Context, input manageScriptInput) (*script.Pipeline, *mcp.CallToolResult) {
+	if h.pipelines == nil {
+		return nil, errorResult("this deployment cannot store pipelines")
+	}
+	if input.Pipeline == "" {
+		return nil, errorResult("pipeline is required")
+	}
+	p, err := h.pipelines.GetPipeline(ctx, h.pipelineOwner(ctx, input), input.Pipeline)
+	if errors.Is(err, script.ErrPipelineNotFound) {
+		return nil, errorResult(fmt.Sprintf("pipeline %q not found", input.Pipeline))
+	}
+	if err != nil {
+		slog.Error("failed to read a script pipeline", "pipeline", input.Pipeline, logKeyError, err)
+		return nil, errorResult("failed to read the pipeline")
+	}
+	return p, nil
+}
+
+// handlePipelineSet creates or replaces a pipeline. Steps left unsent keep the
+// pipeline's current ones, so a cadence or an enable can be changed alone.
+//
+// Every step's script is resolved under the read rule. The pipeline adds no
+// authority — each step runs as its own script's principal — so the only
+// question is whether the caller may run those scripts, which is the same one.
+func (h *Handle) handlePipelineSet(ctx context.Context, input manageScriptInput) (*mcp.CallToolResult, any, error) {
+	if h.pipelines == nil {
+		return errorResult("this deployment cannot store pipelines"), nil, nil
+	}
+	if input.Pipeline == "" {
+		return errorResult("pipeline is required"), nil, nil
+	}
+	owner, actor := h.pipelineOwner(ctx, input), resolveEmail(ctx)
+	prev, err := h.pipelines.GetPipeline(ctx, owner, input.Pipeline)
+	switch {
+	case errors.Is(err, script.ErrPipelineNotFound):
+		prev = nil
+	case err != nil:
+		slog.Error("failed to read a script pipeline", "pipeline", input.Pipeline, logKeyError, err)
+		return errorResult("failed to read the current pipeline"), nil, nil
+	}
+	p := &script.Pipeline{
+		Name: input.Pipeline, Description: input.Description, OwnerEmail: owner,
+		CronSpec: input.Cron, Timezone: input.Timezone, Enabled: true,
+		CreatedBy: actor, UpdatedBy: actor,
+	}
+	if prev != nil {
+		p.Steps, p.Enabled = prev.Steps, prev.Enabled
+	}
+	if input.Enabled != nil {
+		p.Enabled = *input.Enabled
+	}
+	if input.Steps != nil {
+		steps, errResult := h.pipelineSteps(ctx, input)
+		if errResult != nil {
+			return errResult, nil, nil
+		}
+		p.Steps = steps
+	}
+	if err := p.Prepare(prev, time.Now()); err != nil {
+		return errorResult(err.Error()), nil, nil
+	}
+	if err := h.pipelines.SetPipeline(ctx, p); err != nil {
+		slog.Error("failed to set a script pipeline", "pipeline", p.Name, logKeyError, err)
+		return errorResult("failed to set the pipeline"), nil, nil
+	}
+	out := h.pipelineFields(ctx, p)
+	out["message"] = pipelineNote(p)
+	return jsonResult(out)
+}
+
+// pipelineSteps resolves the scripts pipeline_set's steps name.
+func (h *Handle) pipelineSteps(ctx context.Context, input manageScriptInput) ([]script.PipelineStep, *mcp.CallToolResult) {
+	steps := make([]script.PipelineStep, 0, len(input.Steps))
+	for _, in := range input.Steps {
+		sc, errResult := h.readable(ctx, manageScriptInput{Name: in.Script, OwnerEmail: input.OwnerEmail})
+		if errResult != nil {
+			return nil, errResult
+		}
+		steps = append(steps, script.PipelineStep{
+			Name: in.Name, ScriptID: sc.ID, DependsOn: in.DependsOn, Params: in.Params,
+		})
+	}
+	return steps, nil
+}
+
+// handlePipelineList returns the caller's pipelines, or one owner's, or every
+// pipeline for an administrator who names no owner.
+func (h *Handle) handlePipelineList(ctx context.Context, input manageScriptInput) (*mcp.CallToolResult, any, error) {
+	if h.pipelines == nil {
+		return errorResult("this deployment cannot store pipelines"), nil, nil
+	}
+	owner := h.pipelineOwner(ctx, input)
+	if input.OwnerEmail == "" && h.isAdminPersona(ctx) {
+		owner = ""
+	}
+	pipelines, err := h.pipelines.ListPipelines(ctx, owner, input.Limit)
+	if err != nil {
+		slog.Error("failed to list script pipelines", logKeyError, err)
+		return errorResult("failed to list pipelines"), nil, nil
+	}
+	items := make([]map[string]any, 0, len(pipelines))
+	for i := range pipelines {
+		items = append(items, h.pipelineFields(ctx, &pipelines[i]))
+	}
+	return jsonResult(map[string]any{"pipelines": items, "count": len(items)})
+}
+
+// handlePipelineDelete removes a pipeline with its run records. The script
+// runs its steps produced stay in each script's own history.
+func (h *Handle) handlePipelineDelete(ctx context.Context, input manageScriptInput) (*mcp.CallToolResult, any, error) {
+	p, errResult := h.namedPipeline(ctx, input)
+	if errResult != nil {
+		return errResult, nil, nil
+	}
+	if err := h.pipelines.DeletePipeline(ctx, p.ID); err != nil && !errors.Is(err, script.ErrPipelineNotFound) {
+		slog.Error("failed to delete a script pipeline", "pipeline", p.Name, logKeyError, err)
+		return errorResult("failed to delete the pipeline"), nil, nil
+	}
+	return jsonResult(map[string]any{fieldStatus: "deleted", "pipeline": p.Name})
+}
+
+// handlePipelineRun starts a run of a pipeline now. Enabled governs only the
+// cadence, so a disabled pipeline can still be run by hand.
+func (h *Handle) handlePipelineRun(ctx context.Context, input manageScriptInput) (*mcp.CallToolResult, any, error) {
+	p, errResult := h.namedPipeline(ctx, input)
+	if errResult != nil {
+		return errResult, nil, nil
+	}
+	if h.runs == nil {
+		return errorResult("this deployment cannot execute scripts"), nil, nil
+	}
+	r := p.NewRun(script.TriggerTool, resolveEmail(ctx), time.Now().UTC())
+	if _, err := h.pipelines.StartPipelineRun(ctx, r); err != nil {
+		slog.Error("failed to start a script pipeline run", "pipeline", p.Name, logKeyError, err)
+		return errorResult("failed to start the pipeline"), nil, nil
+	}
+	out := pipelineRunFields(r)
+	out["message"] = "Started. Each step runs once the steps it depends on have succeeded, usually within a minute of them; " +
+		"follow it with command=pipeline_runs and this run_id."
+	return jsonResult(out)
+}
+
+// handlePipelineRuns lists a pipeline's runs newest first, or returns one in
+// full when run_id names it.
+func (h *Handle) handlePipelineRuns(ctx context.Context, input manageScriptInput) (*mcp.CallToolResult, any, error) {
+	p, errResult := h.namedPipeline(ctx, input)
+	if errResult != nil {
+		return errResult, nil, nil
+	}
+	if input.RunID != "" {
+		r, errResult := h.pipelineRun(ctx, p, input.RunID)
+		if errResult != nil {
+			return errResult, nil, nil
+		}
+		return jsonResult(pipelineRunFields(r))
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultRunsLimit
+	}
+	runs, err := h.pipelines.ListPipelineRuns(ctx, script.PipelineRunFilter{
+		PipelineID: p.ID, Status: input.RunStatus, Limit: limit,
+	})
+	if err != nil {
+		slog.Error("failed to list script pipeline runs", "pipeline", p.Name, logKeyError, err)
+		return errorResult("failed to list pipeline runs"), nil, nil
+	}
+	items := make([]map[string]any, 0, len(runs))
+	for i := range runs {
+		items = append(items, pipelineRunFields(&runs[i]))
+	}
+	return jsonResult(map[string]any{"pipeline": p.Name, "runs": items, "count": len(items)})
+}
+
+// handlePipelineRetry returns a finished pipeline run to running from its
+// failed steps, or from the step named, keeping what already succeeded.
+func (h *Handle) handlePipelineRetry(ctx context.Context, input manageScriptInput) (*mcp.CallToolResult, any, error) {
+	p, errResult := h.namedPipeline(ctx, input)
+	if errResult != nil {
+		return errResult, nil, nil
+	}
+	if input.RunID == "" {
+		return errorResult("run_id is required"), nil, nil
+	}
+	r, errResult := h.pipelineRun(ctx, p, input.RunID)
+	if errResult != nil {
+		return errResult, nil, nil
+	}
+	if err := r.Retry(input.Step); err != nil {
+		return errorResult(err.Error()), nil, nil
+	}
+	if err := h.pipelines.UpdatePipelineRun(ctx, r); err != nil {
+		if errors.Is(err, script.ErrPipelineRunMoved) {
+			return errorResult("the pipeline run changed while it was being retried; read it again with command=pipeline_runs"), nil, nil
+		}
+		slog.Error("failed to retry a script pipeline run", "pipeline", p.Name, logKeyError, err)
+		return errorResult("failed to retry the pipeline run"), nil, nil
+	}
+	out := pipelineRunFields(r)
+	out["message"] = "Retrying. The reset steps run again in dependency order; the steps that succeeded keep their runs and outputs."
+	return jsonResult(out)
+}
+
+// pipelineRun reads one run of p. A run of another pipeline answers as not
+// found, for the reason a run of another person's script does.
+func (h *Handle) pipelineRun(ctx context.Context, p *script.Pipeline, id string) (*script.PipelineRun, *mcp.CallToolResult) {
+	r, err := h.pipelines.GetPipelineRun(ctx, id)
+	if errors.Is(err, script.ErrPipelineRunNotFound) || (err == nil && r.PipelineID != p.ID) {
+		return nil, errorResult("pipeline run not found")
+	}
+	if err != nil {
+		slog.Error("failed to read a script pipeline run", "run_id", id, logKeyError, err)
+		return nil, errorResult("failed to read the pipeline run")
+	}
+	return r, nil
+}
+
+// pipelineFields renders one pipeline for a response, naming each step's
+// script the way the caller named it.
+func (h *Handle) pipelineFields(ctx context.Context, p *script.Pipeline) map[string]any {
+	steps := make([]map[string]any, 0, len(p.Steps))
+	for _, s := range p.Steps {
+		step := map[string]any{fieldName: s.Name, "script": s.ScriptID, "depends_on": s.DependsOn, "params": s.Params}
+		if sc, err := h.store.GetByID(ctx, s.ScriptID); err == nil && sc != nil {
+			step["script"] = sc.Name
+		}
+		steps = append(steps, step)
+	}
+	out := map[string]any{
+		"pipeline": p.Name, "description": p.Description, "owner_email": p.OwnerEmail,
+		"steps": steps, "cron": p.CronSpec, "timezone": p.Timezone, "enabled": p.Enabled,
+	}
+	if p.Enabled && !p.NextRunAt.IsZero() {
+		out["next_run_at"] = p.NextRunAt.UTC()
+	}
+	return out
+}
+
+// pipelineRunFields renders one pipeline run for a response.
+func pipelineRunFields(r *script.PipelineRun) map[string]any {
+	out := map[string]any{
+		"run_id": r.ID, fieldStatus: r.Status, "trigger": r.Trigger, "steps": r.Steps,
+		"attempt": r.Attempt, "requested_by": r.RequestedBy,
+		"fire_time": r.FireTime.UTC(), "created_at": r.CreatedAt,
+	}
+	if r.FinishedAt != nil {
+		out["finished_at"] = r.FinishedAt.UTC()
+	}
+	return out
+}
+
+// pipelineNote states what the saved pipeline will do.
+func pipelineNote(p *script.Pipeline) string {
+	switch {
+	case p.CronSpec == "":
+		return "The pipeline is saved. It has no cadence, so it runs when started with command=pipeline_run."
+	case !p.Enabled:
+		return "The pipeline is saved and disabled, so its cadence does not fire; command=pipeline_run still starts it."
+	default:
+		return "The platform will start the pipeline on this cadence. Each step runs the latest saved version of its script, " +
+			"as that script's own principal, once the steps it depends on have succeeded."
+	}
+}
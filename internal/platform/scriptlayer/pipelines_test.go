@@ -0,0 +1,230 @@
+package scriptlayer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+// The pipeline half of the in-memory store, keyed on owner and name as the
+// real store's unique index is.
+func (m *memStore) SetPipeline(_ context.Context, p *script.Pipeline) error {
+	if p.ID == "" {
+		p.ID = "pipe_" + p.Name
+	}
+	stored := *p
+	m.pipelines[p.OwnerEmail+"/"+p.Name] = &stored
+	return nil
+}
+
+func (m *memStore) GetPipeline(_ context.Context, owner, name string) (*script.Pipeline, error) {
+	p, ok := m.pipelines[owner+"/"+name]
+	if !ok {
+		return nil, script.ErrPipelineNotFound
+	}
+	out := *p
+	return &out, nil
+}
+
+func (m *memStore) ListPipelines(_ context.Context, owner string, _ int) ([]script.Pipeline, error) {
+	out := []script.Pipeline{}
+	for _, p := range m.pipelines {
+		if owner == "" || p.OwnerEmail == owner {
+			out = append(out, *p)
+		}
+	}
+	return out, nil
+}
+
+func (m *memStore) DeletePipeline(_ context.Context, id string) error {
+	for key, p := range m.pipelines {
+		if p.ID == id {
+			delete(m.pipelines, key)
+			return nil
+		}
+	}
+	return script.ErrPipelineNotFound
+}
+
+func (*memStore) DuePipelines(context.Context, time.Time, int) ([]script.Pipeline, error) {
+	return nil, nil
+}
+
+func (*memStore) AdvancePipeline(context.Context, string, time.Time, time.Time) (bool, error) {
+	return true, nil
+}
+
+func (m *memStore) StartPipelineRun(_ context.Context, r *script.PipelineRun) (bool, error) {
+	r.ID = fmt.Sprintf("prun_%d", len(m.pipelineRuns)+1)
+	stored := *r
+	m.pipelineRuns = append(m.pipelineRuns, &stored)
+	return true, nil
+}
+
+func (m *memStore) GetPipelineRun(_ context.Context, id string) (*script.PipelineRun, error) {
+	for _, r := range m.pipelineRuns {
+		if r.ID == id {
+			out := *r
+			out.Steps = slices.Clone(r.Steps)
+			return &out, nil
+		}
+	}
+	return nil, script.ErrPipelineRunNotFound
+}
+
+func (m *memStore) ListPipelineRuns(_ context.Context, filter script.PipelineRunFilter) ([]script.PipelineRun, error) {
+	out := []script.PipelineRun{}
+	for _, r := range m.pipelineRuns {
+		if r.PipelineID == filter.PipelineID {
+			out = append(out, *r)
+		}
+	}
+	return out, nil
+}
+
+// UpdatePipelineRun models the revision check, so a retry written against a
+// stale read is refused here as it is in PostgreSQL.
+func (m *memStore) UpdatePipelineRun(_ context.Context, r *script.PipelineRun) error {
+	for i, stored := range m.pipelineRuns {
+		if stored.ID != r.ID {
+			continue
+		}
+		if stored.Revision != r.Revision {
+			return script.ErrPipelineRunMoved
+		}
+		r.Revision++
+		out := *r
+		out.Steps = slices.Clone(r.Steps)
+		m.pipelineRuns[i] = &out
+		return nil
+	}
+	return script.ErrPipelineRunNotFound
+}
+
+// pipelineHandle is a runnable Handle with a second script and a two-step
+// pipeline over the two, owned by the author.
+func pipelineHandle(t *testing.T) (*Handle, *memStore) {
+	t.Helper()
+	h, store, _ := runnableHandle(t)
+	res := call(t, h, authorCtx(), manageScriptInput{Command: cmdCreate, Name: "publish", Source: "print(2)\n"})
+	require.False(t, res.IsError, resultText(res))
+	res = call(t, h, authorCtx(), manageScriptInput{
+		Command: cmdPipelineSet, Pipeline: "nightly", Cron: "0 6 * * *",
+		Steps: []pipelineStepInput{
+			{Name: "extract", Script: "daily"},
+			{Name: "publish", Script: "publish", DependsOn: []string{"extract"}},
+		},
+	})
+	require.False(t, res.IsError, resultText(res))
+	return h, store
+}
+
+func TestPipelineSet_ResolvesTheStepScriptsByName(t *testing.T) {
+	h, store := pipelineHandle(t)
+
+	stored, err := store.GetPipeline(context.Background(), "jane@example.com", "nightly")
+	require.NoError(t, err)
+	require.Len(t, stored.Steps, 2)
+	daily, err := store.GetByName(context.Background(), "jane@example.com", "daily")
+	require.NoError(t, err)
+	assert.Equal(t, daily.ID, stored.Steps[0].ScriptID)
+	assert.True(t, stored.Enabled)
+	assert.False(t, stored.NextRunAt.IsZero(), "a pipeline with a cadence has a next fire")
+
+	fields := resultFields(t, call(t, h, authorCtx(), manageScriptInput{Command: cmdPipelineList}))
+	require.InDelta(t, 1, fields["count"], 0)
+	steps := fields["pipelines"].([]any)[0].(map[string]any)["steps"].([]any)
+	assert.Equal(t, "publish", steps[1].(map[string]any)["script"], "steps are reported by script name")
+}
+
+func TestPipelineSet_RefusesWhatCannotRun(t *testing.T) {
+	h, _ := pipelineHandle(t)
+	for name, steps := range map[string][]pipelineStepInput{
+		"not found": {{Name: "a", Script: "missing"}},
+		"cycle": {
+			{Name: "a", Script: "daily", DependsOn: []string{"b"}},
+			{Name: "b", Script: "daily", DependsOn: []string{"a"}},
+		},
+	} {
+		res := call(t, h, authorCtx(), manageScriptInput{Command: cmdPipelineSet, Pipeline: "broken", Steps: steps})
+		assert.True(t, res.IsError, name)
+		assert.Contains(t, resultText(res), name)
+	}
+}
+
+// TestPipeline_SomebodyElsesPipelineIsNotFound pins that a pipeline is its
+// owner's: another caller can neither read nor start it.
+func TestPipeline_SomebodyElsesPipelineIsNotFound(t *testing.T) {
+	h, _ := pipelineHandle(t)
+	other := callerCtx("bob@example.com", "analyst")
+	for _, command := range []string{cmdPipelineRun, cmdPipelineRuns, cmdPipelineDelete} {
+		res := call(t, h, other, manageScriptInput{Command: command, Pipeline: "nightly"})
+		assert.True(t, res.IsError, command)
+		assert.Contains(t, resultText(res), "not found", command)
+	}
+	res := call(t, h, adminCtx(), manageScriptInput{
+		Command: cmdPipelineRuns, Pipeline: "nightly", OwnerEmail: "jane@example.com",
+	})
+	assert.False(t, res.IsError, "an administrator addresses it by owner")
+}
+
+func TestPipelineRun_StartsWithEveryStepPending(t *testing.T) {
+	h, store := pipelineHandle(t)
+
+	fields := resultFields(t, call(t, h, authorCtx(), manageScriptInput{Command: cmdPipelineRun, Pipeline: "nightly"}))
+	assert.Equal(t, script.RunStatusRunning, fields[fieldStatus])
+	assert.Equal(t, script.TriggerTool, fields["trigger"])
+	require.Len(t, store.pipelineRuns, 1)
+	assert.Equal(t, "jane@example.com", store.pipelineRuns[0].RequestedBy)
+	for _, step := range store.pipelineRuns[0].Steps {
+		assert.Equal(t, script.RunStatusPending, step.Status)
+	}
+
+	listed := resultFields(t, call(t, h, authorCtx(), manageScriptInput{Command: cmdPipelineRuns, Pipeline: "nightly"}))
+	require.InDelta(t, 1, listed["count"], 0)
+	one := resultFields(t, call(t, h, authorCtx(), manageScriptInput{
+		Command: cmdPipelineRuns, Pipeline: "nightly", RunID: fields["run_id"].(string),
+	}))
+	assert.Len(t, one["steps"], 2)
+}
+
+func TestPipelineRetry_ResetsTheFailedBranch(t *testing.T) {
+	h, store := pipelineHandle(t)
+	call(t, h, authorCtx(), manageScriptInput{Command: cmdPipelineRun, Pipeline: "nightly"})
+	r := store.pipelineRuns[0]
+	r.Status = script.RunStatusFailed
+	r.Steps[0].Status, r.Steps[0].RunID = script.RunStatusSucceeded, "dpx_1"
+	r.Steps[1].Status, r.Steps[1].RunID = script.RunStatusFailed, "dpx_2"
+
+	res := call(t, h, authorCtx(), manageScriptInput{Command: cmdPipelineRetry, Pipeline: "nightly"})
+	assert.True(t, res.IsError)
+	assert.Contains(t, resultText(res), "run_id is required")
+
+	fields := resultFields(t, call(t, h, authorCtx(), manageScriptInput{
+		Command: cmdPipelineRetry, Pipeline: "nightly", RunID: r.ID,
+	}))
+	assert.Equal(t, script.RunStatusRunning, fields[fieldStatus])
+	stored := store.pipelineRuns[0]
+	assert.Equal(t, "dpx_1", stored.Steps[0].RunID, "the step that succeeded keeps its run")
+	assert.Equal(t, script.RunStatusPending, stored.Steps[1].Status)
+	assert.Equal(t, 1, stored.Attempt)
+
+	res = call(t, h, authorCtx(), manageScriptInput{Command: cmdPipelineRetry, Pipeline: "nightly", RunID: r.ID})
+	assert.True(t, res.IsError, "a run still going cannot be retried")
+}
+
+func TestPipeline_NoPipelineStoreIsSaid(t *testing.T) {
+	h := New(Config{Store: &scheduleless{newMemStore()}, AdminPersona: "admin"})
+	for _, command := range []string{cmdPipelineSet, cmdPipelineList, cmdPipelineRun} {
+		res := call(t, h, authorCtx(), manageScriptInput{Command: command, Pipeline: "nightly"})
+		assert.True(t, res.IsError, command)
+		assert.Contains(t, resultText(res), "cannot store pipelines")
+	}
+}
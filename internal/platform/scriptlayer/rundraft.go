@@ -7,6 +7,7 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/txn2/mcp-data-platform/internal/platform/scriptdraft"
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptlib"
 	"github.com/txn2/mcp-data-platform/internal/platform/scriptrun"
 	"github.com/txn2/mcp-data-platform/pkg/middleware"
 	"github.com/txn2/mcp-data-platform/pkg/script"
@@ -93,8 +94,9 @@ func (h *Handle) handleRunDraft(ctx context.Context, input manageScriptInput) (*
 	if pc == nil {
 		return errorResult(scriptdraft.ErrNoIdentity.Error()), nil, nil
 	}
+	libs, _ := h.store.(scriptlib.Resolver)
 	outcome, err := scriptdraft.New(h.server, h.destinations).Run(ctx, scriptdraft.Request{
-		Source: source, Name: sc.Name, Params: params,
+		Source: source, Name: sc.Name, Params: params, Libraries: libs,
 		Identity: scriptdraft.Identity{
 			UserID: pc.UserID, Email: pc.UserEmail, Claims: pc.UserClaims,
 			Roles: pc.Roles, AuthType: pc.AuthType,
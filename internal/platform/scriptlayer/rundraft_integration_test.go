@@ -582,7 +582,7 @@ func TestIntegration_RunDraftRefusesASubmittedSourceThatDoesNotValidate(t *testi
 }
 
 // TestIntegration_ValidateRefusesAnUndeclaredDestination is #1415: on a
-// deployment declaring no bucket destinations, a script naming one reported
+// deployment declaring no destinations, a script naming one reported
 // ok and failed at the export, after its queries had already run.
 func TestIntegration_ValidateRefusesAnUndeclaredDestination(t *testing.T) {
 	ctx := context.Background()
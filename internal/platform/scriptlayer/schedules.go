@@ -43,13 +43,24 @@ func (h *Handle) handleScheduleSet(ctx context.Context, input manageScriptInput)
 		slog.Error("failed to read a script schedule", fieldName, sc.Name, logKeyError, err)
 		return errorResult("failed to read the current schedule"), nil, nil
 	}
+	trigger, errResult := h.scheduleTrigger(ctx, input)
+	if errResult != nil {
+		return errResult, nil, nil
+	}
 	sched, err := script.BuildSchedule(sc, prev, script.ScheduleRequest{
-		CronSpec: input.Cron, Timezone: input.Timezone,
+		CronSpec: input.Cron, Trigger: trigger, Timezone: input.Timezone,
 		Params: input.Args, Actor: resolveEmail(ctx),
 	}, time.Now())
 	if err != nil {
 		return errorResult(err.Error()), nil, nil
 	}
+	if err := script.RefuseTriggerCycle(ctx, h.schedules.GetSchedule, sched); err != nil {
+		if errors.Is(err, script.ErrTriggerCycle) {
+			return errorResult(err.Error()), nil, nil
+		}
+		slog.Error("failed to check a script trigger for a loop", fieldName, sc.Name, logKeyError, err)
+		return errorResult("failed to read the schedules the trigger follows"), nil, nil
+	}
 	if err := h.schedules.SetSchedule(ctx, sched); err != nil {
 		slog.Error("failed to set a script schedule", fieldName, sc.Name, logKeyError, err)
 		return errorResult("failed to set the schedule"), nil, nil
@@ -59,6 +70,78 @@ func (h *Handle) handleScheduleSet(ctx context.Context, input manageScriptInput)
 	return jsonResult(out)
 }
 
+// triggerInput is the trigger schedule_set accepts. It is script.Trigger with
+// the followed script named the way every other argument names one, by name,
+// rather than by an id the caller has no way to learn.
+type triggerInput struct {
+	Kind       string `json:"kind"`
+	Every      string `json:"every,omitempty"`
+	Connection string `json:"connection,omitempty"`
+	Bucket     string `json:"bucket,omitempty"`
+	Prefix     string `json:"prefix,omitempty"`
+	Table      string `json:"table,omitempty"`
+	Column     string `json:"column,omitempty"`
+	URN        string `json:"urn,omitempty"`
+	Script     string `json:"script,omitempty"`
+}
+
+// scheduleTrigger resolves the trigger a schedule_set carries, or nil for a
+// cron schedule.
+//
+// A script_run trigger's script is resolved under the read rule: following a
+// script reveals when it succeeds, which is its owner's or an administrator's
+// to know, so a caller may follow only a script they could read.
+func (h *Handle) scheduleTrigger(ctx context.Context, input manageScriptInput) (*script.Trigger, *mcp.CallToolResult) {
+	in := input.Trigger
+	if in == nil {
+		return nil, nil
+	}
+	t := &script.Trigger{
+		Kind: script.TriggerKind(in.Kind), Every: in.Every, Connection: in.Connection,
+		Bucket: in.Bucket, Prefix: in.Prefix, Table: in.Table, Column: in.Column, URN: in.URN,
+	}
+	if in.Script != "" {
+		followed, errResult := h.readable(ctx, manageScriptInput{Name: in.Script, OwnerEmail: input.OwnerEmail})
+		if errResult != nil {
+			return nil, errResult
+		}
+		t.ScriptID = followed.ID
+	}
+	return t, nil
+}
+
+// triggerSchema describes the trigger argument.
+func triggerSchema() map[string]any {
+	str := func(desc string) map[string]any { return map[string]any{keyType: valString, keyDescription: desc} }
+	return map[string]any{
+		keyType: valObject,
+		keyDescription: "Fires the schedule_set schedule when a source changes, in place of cron. The source is " +
+			"observed every `every` as the script's own principal, and the first observation is a baseline that fires nothing.",
+		"properties": map[string]any{
+			"kind": map[string]any{
+				keyType: valString,
+				keyEnum: []string{
+					string(script.TriggerS3Object), string(script.TriggerTrinoPartition),
+					string(script.TriggerDataHubEntity), string(script.TriggerScriptRun),
+				},
+				keyDescription: "s3_object: a new or replaced object under bucket/prefix. trino_partition: the greatest " +
+					"value of column in table's partitions changes. datahub_entity: the entity at urn changes. " +
+					"script_run: the named script finishes a successful run.",
+			},
+			"every":      str("How often the source is observed, as a duration (default 5m, between 1m and 24h)."),
+			"connection": str("Platform connection the source is read through; omit for the toolkit's default."),
+			"bucket":     str("s3_object: the bucket watched."),
+			"prefix":     str("s3_object: the key prefix watched."),
+			"table":      str("trino_partition: the table, as catalog.schema.table."),
+			"column":     str("trino_partition: the partition column."),
+			"urn":        str("datahub_entity: the entity's URN."),
+			"script":     str("script_run: the name of the script followed."),
+		},
+		"required":             []string{"kind"},
+		"additionalProperties": false,
+	}
+}
+
 // existingSchedule reads the schedule being replaced, treating "there is none"
 // as a normal outcome rather than a failure.
 func (h *Handle) existingSchedule(ctx context.Context, scriptID string) (*script.Schedule, error) {
@@ -239,6 +322,9 @@ func scheduleFields(sc *script.Script, sched *script.Schedule) map[string]any {
 	if sched.LastFireAt != nil {
 		out["last_fire_at"] = sched.LastFireAt.UTC()
 	}
+	if sched.Trigger != nil {
+		out["trigger"] = sched.Trigger
+	}
 	return out
 }
 
@@ -251,7 +337,157 @@ func scheduleNote(sc *script.Script, sched *script.Schedule) string {
 		return "The schedule is saved, but nothing will execute this script: " + script.RefuseRun(sc).Error() + "."
 	case !sched.Enabled:
 		return "The schedule is saved and disabled; enable it with command=schedule_enable."
+	case sched.Event():
+		return "The platform will run the latest saved version each time the trigger observes a change, with these parameters, as the script's own principal presenting your captured roles. " +
+			"Its first observation records what is already there and fires nothing."
 	default:
 		return "The platform will run the latest saved version on this cadence, with these parameters, as the script's own principal presenting your captured roles."
 	}
 }
+
+// handleBackfill replays a script's schedule over a range of past dates.
+//
+// It is a schedule_set action in authority terms, and held to the same rule: a
+// backfill runs the script the schedule already runs, with the bindings the
+// schedule already has, and the persona filter is re-read at every run.
+func (h *Handle) handleBackfill(ctx context.Context, input manageScriptInput) (*mcp.CallToolResult, any, error) {
+	sc, errResult := h.schedulable(ctx, input)
+	if errResult != nil {
+		return errResult, nil, nil
+	}
+	if h.schedules == nil || h.backfills == nil {
+		return errorResult("this deployment cannot backfill schedules"), nil, nil
+	}
+	sched, err := h.existingSchedule(ctx, sc.ID)
+	if err != nil {
+		slog.Error("failed to read a script schedule", fieldName, sc.Name, logKeyError, err)
+		return errorResult("failed to read the schedule"), nil, nil
+	}
+	if sched == nil {
+		return errorResult("this script has no schedule to backfill; set one with command=schedule_set"), nil, nil
+	}
+	b, err := sched.PlanBackfill(input.From, input.To, input.Concurrency, input.Rerun, resolveEmail(ctx), time.Now())
+	if err != nil {
+		return errorResult(err.Error()), nil, nil
+	}
+	if err := h.backfills.CreateBackfill(ctx, b); err != nil {
+		// An unwrapped error is the store refusing the request — a backfill
+		// already running, every fire already succeeded — and says so in
+		// words meant for the caller. Anything wrapped is a fault.
+		if errors.Unwrap(err) == nil {
+			return errorResult(err.Error()), nil, nil
+		}
+		slog.Error("failed to create a script backfill", fieldName, sc.Name, logKeyError, err)
+		return errorResult("failed to start the backfill"), nil, nil
+	}
+	out := backfillFields(sc, b)
+	out["message"] = fmt.Sprintf("Replaying %d fires, %d at a time, oldest first; each runs the script's current version. "+
+		"Failures are reported here rather than emailed. Follow it with command=backfills and this backfill_id.",
+		len(b.Fires), b.Concurrency)
+	return jsonResult(out)
+}
+
+// handleBackfills lists a script's backfills, or reports one fire by fire when
+// backfill_id names it.
+func (h *Handle) handleBackfills(ctx context.Context, input manageScriptInput) (*mcp.CallToolResult, any, error) {
+	sc, errResult := h.readable(ctx, input)
+	if errResult != nil {
+		return errResult, nil, nil
+	}
+	if h.backfills == nil {
+		return errorResult("this deployment cannot backfill schedules"), nil, nil
+	}
+	if input.BackfillID != "" {
+		b, errResult := h.scriptBackfill(ctx, sc, input.BackfillID)
+		if errResult != nil {
+			return errResult, nil, nil
+		}
+		runs, err := h.backfills.BackfillRuns(ctx, b.ID)
+		if err != nil {
+			slog.Error("failed to read a script backfill's runs", fieldName, sc.Name, logKeyError, err)
+			return errorResult("failed to read the backfill's runs"), nil, nil
+		}
+		fires := b.Outcomes(runs)
+		tally := map[string]int{}
+		for _, f := range fires {
+			tally[f.Status]++
+		}
+		out := backfillFields(sc, b)
+		out["fires"], out["tally"] = fires, tally
+		return jsonResult(out)
+	}
+	backfills, err := h.backfills.ListBackfills(ctx, sc.ID, input.Limit)
+	if err != nil {
+		slog.Error("failed to list script backfills", fieldName, sc.Name, logKeyError, err)
+		return errorResult("failed to list backfills"), nil, nil
+	}
+	items := make([]map[string]any, 0, len(backfills))
+	for i := range backfills {
+		items = append(items, backfillFields(sc, &backfills[i]))
+	}
+	return jsonResult(map[string]any{fieldName: sc.Name, "backfills": items, "count": len(items)})
+}
+
+// handleBackfillCancel stops a running backfill. No further fire starts; the
+// runs already queued run to the end, because a run that was started is a run
+// somebody may be reading the output of.
+func (h *Handle) handleBackfillCancel(ctx context.Context, input manageScriptInput) (*mcp.CallToolResult, any, error) {
+	sc, errResult := h.schedulable(ctx, input)
+	if errResult != nil {
+		return errResult, nil, nil
+	}
+	if h.backfills == nil {
+		return errorResult("this deployment cannot backfill schedules"), nil, nil
+	}
+	b, errResult := h.scriptBackfill(ctx, sc, input.BackfillID)
+	if errResult != nil {
+		return errResult, nil, nil
+	}
+	moved, err := h.backfills.FinishBackfill(ctx, b.ID, resolveEmail(ctx), "")
+	if err != nil {
+		slog.Error("failed to cancel a script backfill", fieldName, sc.Name, logKeyError, err)
+		return errorResult("failed to cancel the backfill"), nil, nil
+	}
+	if !moved {
+		return errorResult(fmt.Sprintf("this backfill already finished (%s)", b.Status)), nil, nil
+	}
+	return jsonResult(map[string]any{fieldName: sc.Name, "backfill_id": b.ID, fieldStatus: "cancelled"})
+}
+
+// scriptBackfill resolves a backfill of the named script. One of another
+// script reads as not found, so the id says nothing about a script the caller
+// did not name.
+func (h *Handle) scriptBackfill(ctx context.Context, sc *script.Script, id string) (*script.Backfill, *mcp.CallToolResult) {
+	if id == "" {
+		return nil, errorResult("backfill_id is required; command=backfills lists this script's backfills")
+	}
+	b, err := h.backfills.GetBackfill(ctx, id)
+	if errors.Is(err, script.ErrBackfillNotFound) || (err == nil && b.ScriptID != sc.ID) {
+		return nil, errorResult(fmt.Sprintf("script %q has no backfill %q", sc.Name, id))
+	}
+	if err != nil {
+		slog.Error("failed to read a script backfill", fieldName, sc.Name, logKeyError, err)
+		return nil, errorResult("failed to read the backfill")
+	}
+	return b, nil
+}
+
+// backfillFields renders one backfill for a response, without its fires.
+func backfillFields(sc *script.Script, b *script.Backfill) map[string]any {
+	out := map[string]any{
+		fieldName: sc.Name, "backfill_id": b.ID, "from": b.From, "to": b.To,
+		"timezone": b.Timezone, "fire_count": len(b.Fires), "skipped_count": len(b.Skipped),
+		"concurrency": b.Concurrency, "rerun": b.Rerun, fieldStatus: b.Status,
+		"requested_by": b.RequestedBy, "created_at": b.CreatedAt,
+	}
+	if b.FinishedAt != nil {
+		out["finished_at"] = *b.FinishedAt
+	}
+	if b.CancelledBy != "" {
+		out["cancelled_by"] = b.CancelledBy
+	}
+	if b.Error != "" {
+		out["error"] = b.Error
+	}
+	return out
+}
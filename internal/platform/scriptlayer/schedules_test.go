@@ -3,6 +3,7 @@ package scriptlayer
 import (
 	"context"
 	"errors"
+	"fmt"
 	"slices"
 	"testing"
 	"time"
@@ -256,6 +257,60 @@ func TestScheduleSet_ANonOwnerIsRefused(t *testing.T) {
 	assert.NotContains(t, byAdmin, "error", byAdmin)
 }
 
+// TestScheduleSet_ATriggerFollowsAScriptByName covers the event arm: the
+// followed script is named, stored by id, and the note says what fires it.
+func TestScheduleSet_ATriggerFollowsAScriptByName(t *testing.T) {
+	h, store, _ := runnableHandle(t)
+	res := call(t, h, authorCtx(), manageScriptInput{Command: cmdCreate, Name: "refresh", Source: "print(1)\n"})
+	require.False(t, res.IsError, resultText(res))
+
+	fields := scheduleSet(t, h, authorCtx(), manageScriptInput{
+		Trigger: &triggerInput{Kind: string(script.TriggerScriptRun), Script: "refresh"},
+	})
+	require.NotContains(t, fields, "error", fields)
+	assert.Empty(t, fields["cron"])
+	assert.Contains(t, fields["message"], "each time the trigger observes a change")
+
+	daily, err := store.GetByName(context.Background(), "jane@example.com", "daily")
+	require.NoError(t, err)
+	refresh, err := store.GetByName(context.Background(), "jane@example.com", "refresh")
+	require.NoError(t, err)
+	stored, err := store.GetSchedule(context.Background(), daily.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.Trigger)
+	assert.Equal(t, refresh.ID, stored.Trigger.ScriptID)
+	assert.Nil(t, stored.EventCursor, "the first observation is a baseline")
+
+	loop := scheduleSet(t, h, authorCtx(), manageScriptInput{
+		Name: "refresh", Trigger: &triggerInput{Kind: string(script.TriggerScriptRun), Script: "daily"},
+	})
+	assert.Contains(t, loop["error"], "loop", "refresh cannot follow daily while daily follows refresh")
+}
+
+// TestScheduleSet_ATriggerRefusals pins that a trigger is checked like a cron
+// expression is, and that following a script is subject to the read rule.
+func TestScheduleSet_ATriggerRefusals(t *testing.T) {
+	h, _, _ := runnableHandle(t)
+	res := call(t, h, callerCtx("bob@example.com", "analyst"),
+		manageScriptInput{Command: cmdCreate, Name: "bobs", Source: "print(1)\n"})
+	require.False(t, res.IsError, resultText(res))
+
+	notHis := scheduleSet(t, h, authorCtx(), manageScriptInput{
+		Trigger: &triggerInput{Kind: string(script.TriggerScriptRun), Script: "bobs"},
+	})
+	assert.Contains(t, notHis["error"], "not found", "another person's script cannot be followed")
+
+	both := scheduleSet(t, h, authorCtx(), manageScriptInput{
+		Cron: "@daily", Trigger: &triggerInput{Kind: string(script.TriggerS3Object), Bucket: "landing"},
+	})
+	assert.Contains(t, both["error"], "not both")
+
+	itself := scheduleSet(t, h, authorCtx(), manageScriptInput{
+		Trigger: &triggerInput{Kind: string(script.TriggerScriptRun), Script: "daily"},
+	})
+	assert.Contains(t, itself["error"], "its own script")
+}
+
 func TestScheduleList(t *testing.T) {
 	h, _, _ := runnableHandle(t)
 	scheduleSet(t, h, authorCtx(), manageScriptInput{Cron: weekdayMornings})
@@ -412,3 +467,123 @@ func TestScheduleList_ResolvesAScriptTheListingCutOff(t *testing.T) {
 	fields = resultFields(t, call(t, h, adminCtx(), manageScriptInput{Command: cmdScheduleList}))
 	assert.EqualValues(t, 2, fields["count"], "an admin sees both real scripts' schedules")
 }
+
+// The backfill half of the in-memory store.
+func (m *memStore) CreateBackfill(_ context.Context, b *script.Backfill) error {
+	for _, open := range m.backfills {
+		if open.ScheduleID == b.ScheduleID && open.Open() {
+			return errors.New("this schedule already has a backfill running; cancel it or wait for it to finish")
+		}
+	}
+	b.ID = fmt.Sprintf("bf_%d", len(m.backfills)+1)
+	stored := *b
+	m.backfills = append(m.backfills, &stored)
+	return nil
+}
+
+func (m *memStore) GetBackfill(_ context.Context, id string) (*script.Backfill, error) {
+	for _, b := range m.backfills {
+		if b.ID == id {
+			out := *b
+			return &out, nil
+		}
+	}
+	return nil, script.ErrBackfillNotFound
+}
+
+func (m *memStore) ListBackfills(_ context.Context, scriptID string, _ int) ([]script.Backfill, error) {
+	out := []script.Backfill{}
+	for _, b := range m.backfills {
+		if b.ScriptID == scriptID {
+			out = append(out, *b)
+		}
+	}
+	return out, nil
+}
+
+func (*memStore) OpenBackfills(context.Context, int) ([]script.Backfill, error) { return nil, nil }
+
+// BackfillRuns reports the first fire as a failed run, so a report has each
+// kind of outcome in it.
+func (m *memStore) BackfillRuns(_ context.Context, id string) ([]script.BackfillFire, error) {
+	b, err := m.GetBackfill(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+	return []script.BackfillFire{{At: b.Fires[0], RunID: "dpx_1", Status: script.RunStatusFailed, Error: "boom"}}, nil
+}
+
+func (m *memStore) FinishBackfill(_ context.Context, id, actor, _ string) (bool, error) {
+	for _, b := range m.backfills {
+		if b.ID == id && b.Open() {
+			b.Status, b.CancelledBy = "cancelled", actor
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// backfillDates is a range of three past days.
+func backfillDates() (string, string) {
+	first := time.Now().UTC().AddDate(0, 0, -5)
+	return first.Format(script.DateLayout), first.AddDate(0, 0, 2).Format(script.DateLayout)
+}
+
+func TestBackfill_StartsReportsAndCancels(t *testing.T) {
+	h, store, _ := runnableHandle(t)
+	scheduleSet(t, h, authorCtx(), manageScriptInput{Cron: "0 7 * * *"})
+	from, to := backfillDates()
+
+	fields := resultFields(t, call(t, h, authorCtx(), manageScriptInput{
+		Command: cmdBackfill, Name: "daily", From: from, To: to, Concurrency: 3,
+	}))
+	assert.InDelta(t, 3, fields["fire_count"], 0)
+	assert.InDelta(t, 3, fields["concurrency"], 0)
+	assert.Contains(t, fields["message"], "rather than emailed")
+	require.Len(t, store.backfills, 1)
+	assert.Equal(t, "jane@example.com", store.backfills[0].RequestedBy)
+
+	res := call(t, h, authorCtx(), manageScriptInput{Command: cmdBackfill, Name: "daily", From: from, To: to})
+	assert.True(t, res.IsError)
+	assert.Contains(t, resultText(res), "already has a backfill running", "the store's refusal reaches the caller")
+
+	report := resultFields(t, call(t, h, authorCtx(), manageScriptInput{
+		Command: cmdBackfills, Name: "daily", BackfillID: "bf_1",
+	}))
+	fires := report["fires"].([]any)
+	require.Len(t, fires, 3)
+	assert.Equal(t, script.RunStatusFailed, fires[0].(map[string]any)["status"])
+	assert.Equal(t, from, fires[0].(map[string]any)["fire_date"])
+	assert.Equal(t, map[string]any{"failed": 1.0, "waiting": 2.0}, report["tally"])
+
+	cancelled := resultFields(t, call(t, h, authorCtx(), manageScriptInput{
+		Command: cmdBackfillCancel, Name: "daily", BackfillID: "bf_1",
+	}))
+	assert.Equal(t, "cancelled", cancelled[fieldStatus])
+	res = call(t, h, authorCtx(), manageScriptInput{Command: cmdBackfillCancel, Name: "daily", BackfillID: "bf_1"})
+	assert.True(t, res.IsError)
+	assert.Contains(t, resultText(res), "already finished")
+}
+
+func TestBackfill_Refusals(t *testing.T) {
+	h, _, _ := runnableHandle(t)
+	from, to := backfillDates()
+
+	res := call(t, h, authorCtx(), manageScriptInput{Command: cmdBackfill, Name: "daily", From: from, To: to})
+	assert.True(t, res.IsError)
+	assert.Contains(t, resultText(res), "no schedule to backfill")
+
+	scheduleSet(t, h, authorCtx(), manageScriptInput{Cron: "0 7 * * *"})
+	res = call(t, h, authorCtx(), manageScriptInput{Command: cmdBackfill, Name: "daily", From: to, To: from})
+	assert.True(t, res.IsError)
+	assert.Contains(t, resultText(res), "to is before from")
+
+	res = call(t, h, callerCtx("bob@example.com", "analyst"), manageScriptInput{
+		Command: cmdBackfill, Name: "daily", OwnerEmail: "jane@example.com", From: from, To: to,
+	})
+	assert.True(t, res.IsError, "somebody else's schedule is not theirs to replay")
+
+	res = call(t, h, authorCtx(), manageScriptInput{Command: cmdBackfills, Name: "daily", BackfillID: "bf_9"})
+	assert.True(t, res.IsError)
+	assert.Contains(t, resultText(res), "has no backfill")
+}
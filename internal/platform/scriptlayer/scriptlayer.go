@@ -66,7 +66,13 @@ type Handle struct {
 	versions script.VersionStore
 	// schedules is the same store narrowed to its schedule contract, nil where
 	// the deployment has no database and so nothing to schedule with.
-	schedules    script.ScheduleStore
+	schedules script.ScheduleStore
+	// pipelines is the same store narrowed to its pipeline contract, nil on
+	// the same terms.
+	pipelines script.PipelineStore
+	// backfills is the same store narrowed to its backfill contract, nil on
+	// the same terms.
+	backfills    script.BackfillStore
 	runs         script.RunStore
 	adminPersona string
 	// portalURL is the public portal address show_scripts points the human at,
@@ -99,6 +105,8 @@ func New(cfg Config) *Handle {
 	}
 	h.versions, _ = h.store.(script.VersionStore)
 	h.schedules, _ = h.store.(script.ScheduleStore)
+	h.pipelines, _ = h.store.(script.PipelineStore)
+	h.backfills, _ = h.store.(script.BackfillStore)
 	return h
 }
 
@@ -30,6 +30,12 @@ type memStore struct {
 	// scheduleReadErr fails only the read-back, so a write that landed can be
 	// distinguished from one that did not.
 	scheduleReadErr error
+	// pipelines and pipelineRuns are the pipeline half, also in
+	// schedules_test.go.
+	pipelines    map[string]*script.Pipeline
+	pipelineRuns []*script.PipelineRun
+	// backfills is the backfill half, also in schedules_test.go.
+	backfills []*script.Backfill
 	// versionErr fails the current-version lookup, enabledErr the
 	// enable/disable write.
 	versionErr error
@@ -42,6 +48,7 @@ func newMemStore() *memStore {
 		scripts:   map[string]*script.Script{},
 		versions:  map[string][]script.Version{},
 		schedules: map[string]*script.Schedule{},
+		pipelines: map[string]*script.Pipeline{},
 	}
 }
 
@@ -336,6 +343,38 @@ func TestCreate_Refusals(t *testing.T) {
 	}
 }
 
+// TestCreate_ALibraryIsLoadedAndNeverRuns covers the library arm of create: a
+// library is saved with the load() line that reaches it, one that names the
+// platform is refused at the save, and a script that loads it records the pin.
+func TestCreate_ALibraryIsLoadedAndNeverRuns(t *testing.T) {
+	h, store := newHandle()
+	res := call(t, h, authorCtx(), manageScriptInput{
+		Command: cmdCreate, Name: "fiscal", Library: true,
+		Source: "def quarter(d):\n    return (int(date.format(d, 'MM')) + 2) // 3\n",
+	})
+	require.False(t, res.IsError, resultText(res))
+	assert.Contains(t, resultFields(t, res)["next"], `load("fiscal@1", ...)`)
+
+	res = call(t, h, authorCtx(), manageScriptInput{
+		Command: cmdCreate, Name: "reacher", Library: true, Source: "rows = platform.query('x')\n",
+	})
+	assert.Equal(t, "invalid", resultFields(t, res)["status"])
+	assert.Contains(t, resultText(res), "A library has only")
+
+	res = call(t, h, authorCtx(), manageScriptInput{
+		Command: cmdCreate, Name: "daily", Source: "load(\"fiscal@1\", \"quarter\")\nprint(quarter(\"2026-08-13\"))\n",
+	})
+	require.False(t, res.IsError, resultText(res))
+	for _, sc := range store.scripts {
+		switch sc.Name {
+		case "fiscal":
+			assert.NotNil(t, runnable(sc), "a library never runs on its own")
+		case "daily":
+			assert.Equal(t, []script.Dependency{{Name: "fiscal", Version: 1}}, sc.Loads)
+		}
+	}
+}
+
 // TestCreate_NamesAreUniquePerOwner proves two people may each keep a script
 // under the same name, which is what makes a name their own to choose.
 func TestCreate_NamesAreUniquePerOwner(t *testing.T) {
@@ -654,7 +693,7 @@ func TestHelp_StatesTheDialectAndTheExamples(t *testing.T) {
 
 	dialect, ok := fields["dialect"].(string)
 	require.True(t, ok)
-	for _, want := range []string{"platform.query", "run.fire_time", "There is no module system", "while", "deterministic"} {
+	for _, want := range []string{"platform.query", "run.fire_time", "There is no import", "load(\"fiscal-calendar@3\"", "while", "deterministic"} {
 		assert.Contains(t, strings.ToLower(dialect), strings.ToLower(want))
 	}
 	assert.Len(t, fields["examples"], len(examples))
@@ -39,6 +39,17 @@ const (
 	cmdScheduleList    = "schedule_list"
 	cmdScheduleEnable  = "schedule_enable"
 	cmdScheduleDisable = "schedule_disable"
+
+	cmdPipelineSet    = "pipeline_set"
+	cmdPipelineList   = "pipeline_list"
+	cmdPipelineDelete = "pipeline_delete"
+	cmdPipelineRun    = "pipeline_run"
+	cmdPipelineRuns   = "pipeline_runs"
+	cmdPipelineRetry  = "pipeline_retry"
+
+	cmdBackfill       = "backfill"
+	cmdBackfills      = "backfills"
+	cmdBackfillCancel = "backfill_cancel"
 )
 
 // JSON field names shared between the schema and result maps.
@@ -74,6 +85,10 @@ type manageScriptInput struct {
 	Enabled      *bool  `json:"enabled,omitempty"`
 	Status       string `json:"status,omitempty"`
 	SupersededBy string `json:"superseded_by,omitempty"`
+	// Library makes create save a library, which other scripts load and which
+	// never runs on its own. It is read on create only: what a script is does
+	// not change after it exists.
+	Library bool `json:"library,omitempty"`
 
 	// List filters.
 	Search string `json:"search,omitempty"`
@@ -89,6 +104,9 @@ type manageScriptInput struct {
 	// values may contain the ${fire_date} token.
 	Cron     string `json:"cron,omitempty"`
 	Timezone string `json:"timezone,omitempty"`
+	// Trigger replaces Cron on schedule_set: the schedule fires when its
+	// source changes rather than on a clock.
+	Trigger *triggerInput `json:"trigger,omitempty"`
 
 	// RunID names one run for get_run, and RunStatus filters the runs listing.
 	// RunStatus is separate from Status because the two are different
@@ -97,6 +115,22 @@ type manageScriptInput struct {
 	RunID     string `json:"run_id,omitempty"`
 	RunStatus string `json:"run_status,omitempty"`
 
+	// Pipeline names the pipeline a pipeline command acts on, Steps carries
+	// pipeline_set's steps, and Step names the step pipeline_retry re-runs
+	// from. A pipeline reuses cron, timezone, enabled, and description.
+	Pipeline string              `json:"pipeline,omitempty"`
+	Steps    []pipelineStepInput `json:"steps,omitempty"`
+	Step     string              `json:"step,omitempty"`
+
+	// From and To are the dates a backfill replays, inclusive; Concurrency
+	// bounds its open runs and Rerun replays fires that already succeeded.
+	// BackfillID names one backfill for backfills and backfill_cancel.
+	From        string `json:"from,omitempty"`
+	To          string `json:"to,omitempty"`
+	Concurrency int    `json:"concurrency,omitempty"`
+	Rerun       bool   `json:"rerun,omitempty"`
+	BackfillID  string `json:"backfill_id,omitempty"`
+
 	// Content editing and navigation arguments, shared verbatim with
 	// manage_prompt and manage_asset through pkg/textpatch.
 	Edits        []textpatch.Edit `json:"edits,omitempty"`
@@ -166,6 +200,17 @@ func (h *Handle) commands() map[string]commandHandler {
 		cmdScheduleList:    h.handleScheduleList,
 		cmdScheduleEnable:  h.handleScheduleEnable,
 		cmdScheduleDisable: h.handleScheduleDisable,
+
+		cmdPipelineSet:    h.handlePipelineSet,
+		cmdPipelineList:   h.handlePipelineList,
+		cmdPipelineDelete: h.handlePipelineDelete,
+		cmdPipelineRun:    h.handlePipelineRun,
+		cmdPipelineRuns:   h.handlePipelineRuns,
+		cmdPipelineRetry:  h.handlePipelineRetry,
+
+		cmdBackfill:       h.handleBackfill,
+		cmdBackfills:      h.handleBackfills,
+		cmdBackfillCancel: h.handleBackfillCancel,
 	}
 }
 
@@ -192,6 +237,8 @@ func (h *Handle) commands() map[string]commandHandler {
 var scriptWritingCommands = map[string]bool{
 	cmdCreate: true, cmdUpdate: true, cmdPatch: true, cmdDelete: true,
 	cmdScheduleSet: true, cmdScheduleEnable: true, cmdScheduleDisable: true,
+	cmdPipelineSet: true, cmdPipelineDelete: true, cmdPipelineRun: true, cmdPipelineRetry: true,
+	cmdBackfill: true, cmdBackfillCancel: true,
 }
 
 // handleManageScript dispatches manage_script commands.
@@ -260,6 +307,8 @@ func manageScriptSchema() any {
 				cmdRunDraft, cmdHelp, cmdPatch, cmdLocate, cmdGetContent,
 				cmdOutline, cmdStats, cmdDiff, cmdRuns, cmdGetRun,
 				cmdScheduleSet, cmdScheduleList, cmdScheduleEnable, cmdScheduleDisable,
+				cmdPipelineSet, cmdPipelineList, cmdPipelineDelete, cmdPipelineRun,
+				cmdPipelineRuns, cmdPipelineRetry, cmdBackfill, cmdBackfills, cmdBackfillCancel,
 			},
 			keyDescription: "The operation to perform. Call 'help' first if you have not written a " +
 				"script for this platform before: it states the dialect and what is available.",
@@ -308,8 +357,14 @@ func manageScriptSchema() any {
 		"enabled":       map[string]any{keyType: valBoolean, keyDescription: "Whether the script is available."},
 		fieldStatus:     map[string]any{keyType: valString, keyEnum: []string{script.StatusActive, script.StatusDeprecated, script.StatusSuperseded}, keyDescription: "Lifecycle transition to apply."},
 		"superseded_by": map[string]any{keyType: valString, keyDescription: "Name of the replacing script when superseding."},
-		"search":        map[string]any{keyType: valString, keyDescription: "Substring filter for list."},
-		"limit":         map[string]any{keyType: valInteger, keyDescription: "Maximum rows for list, or matches for locate."},
+		"library": map[string]any{
+			keyType: valBoolean,
+			keyDescription: "On create, save a library: shared functions other scripts load with " +
+				"load(\"name@version\", \"fn\"). A library has json, date and sum but no platform or run, " +
+				"and it never runs on its own. Fixed once the script exists.",
+		},
+		"search": map[string]any{keyType: valString, keyDescription: "Substring filter for list."},
+		"limit":  map[string]any{keyType: valInteger, keyDescription: "Maximum rows for list, or matches for locate."},
 		"args": map[string]any{
 			keyType: valObject,
 			keyDescription: "Parameter values for run_draft, or the bound values a schedule fires with, " +
@@ -326,9 +381,10 @@ func manageScriptSchema() any {
 			keyType:        valString,
 			keyDescription: "IANA timezone the cron expression is read in (default UTC), for example America/Los_Angeles.",
 		},
+		"trigger": triggerSchema(),
 		"run_id": map[string]any{
 			keyType:        valString,
-			keyDescription: "Identifies one run for get_run; run_script and the runs listing report it.",
+			keyDescription: "Identifies one run for get_run, or one pipeline run for pipeline_runs and pipeline_retry; run_script, pipeline_run, and the runs listings report it.",
 		},
 		"run_status": map[string]any{
 			keyType: valString,
@@ -338,6 +394,29 @@ func manageScriptSchema() any {
 			},
 			keyDescription: "Filters the runs listing to one run status.",
 		},
+		"pipeline": map[string]any{
+			keyType:        valString,
+			keyDescription: "Pipeline name for the pipeline_* commands (lowercase letters, digits, hyphens, underscores).",
+		},
+		"steps": pipelineStepsSchema(),
+		"step": map[string]any{
+			keyType:        valString,
+			keyDescription: "For pipeline_retry: a step to re-run from, with everything downstream of it, as well as the failed ones.",
+		},
+		"from": map[string]any{keyType: valString, keyDescription: "For backfill: the first date to replay (YYYY-MM-DD), in the schedule's timezone."},
+		"to":   map[string]any{keyType: valString, keyDescription: "For backfill: the last date to replay (YYYY-MM-DD), inclusive."},
+		"concurrency": map[string]any{
+			keyType:        valInteger,
+			keyDescription: "For backfill: how many of its runs may be open at once, 1 to 10 (default 2).",
+		},
+		"rerun": map[string]any{
+			keyType:        valBoolean,
+			keyDescription: "For backfill: replay fires that already succeeded too. By default they are skipped.",
+		},
+		"backfill_id": map[string]any{
+			keyType:        valString,
+			keyDescription: "Identifies one backfill for backfills (its per-fire report) and backfill_cancel; backfill reports it.",
+		},
 	}
 	maps.Copy(props, textpatchProperties())
 	return map[string]any{
@@ -348,6 +427,27 @@ func manageScriptSchema() any {
 	}
 }
 
+// pipelineStepsSchema describes the steps argument.
+func pipelineStepsSchema() map[string]any {
+	return map[string]any{
+		keyType: valArray,
+		keyDescription: "The steps of pipeline_set, each running one of your scripts once the steps it depends on have " +
+			"succeeded. A step's params may contain " + script.FireDateToken + " and ${steps.<step>.<output>}, which " +
+			"expands to where an upstream step published that output: its portal asset id, or s3://bucket/key.",
+		keyItems: map[string]any{
+			keyType: valObject,
+			"properties": map[string]any{
+				fieldName:    map[string]any{keyType: valString, keyDescription: "Step name, unique in the pipeline (letters, digits, underscores)."},
+				"script":     map[string]any{keyType: valString, keyDescription: "Name of the script the step runs."},
+				"depends_on": map[string]any{keyType: valArray, keyItems: map[string]any{keyType: valString}, keyDescription: "Steps that must succeed first."},
+				"params":     map[string]any{keyType: valObject, keyDescription: "Parameter values bound to the script's contract."},
+			},
+			"required":             []string{fieldName, "script"},
+			"additionalProperties": false,
+		},
+	}
+}
+
 // textpatchProperties publishes the shared content-editing grammar. It is kept
 // separate so the manage_script schema and the input struct stay in step with
 // pkg/textpatch rather than with a hand-copied list.
@@ -1,4 +1,4 @@
-package scriptrun
+package scriptlib
 
 import (
 	"fmt"
@@ -11,10 +11,10 @@ import (
 	"github.com/txn2/mcp-data-platform/pkg/script"
 )
 
-// timeLayout is the wire form of an instant (the run's fire time). Dates use
+// TimeLayout is the wire form of an instant (the run's fire time). Dates use
 // script.DateLayout, so a script that slices a date out of the fire time and a
 // schedule that binds one are talking about the same strings.
-const timeLayout = time.RFC3339
+const TimeLayout = time.RFC3339
 
 // dateModule is the curated date arithmetic a report script actually needs, and
 // nothing else. Every function is a pure transformation of its arguments.
@@ -77,7 +77,7 @@ func dateOf(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs
 	if err != nil {
 		return nil, err
 	}
-	t, err := time.Parse(timeLayout, value)
+	t, err := time.Parse(TimeLayout, value)
 	if err != nil {
 		// A caller who already holds a date should get it back unchanged rather
 		// than an error telling them to convert what is already converted.
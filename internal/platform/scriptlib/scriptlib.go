@@ -0,0 +1,162 @@
+// Package scriptlib is the part of the script dialect that needs no platform:
+// the pure built-ins every script sees, and load(), which binds what a library
+// script defines into the script that names it.
+//
+// A library executes in this environment and no other. It has json, date and
+// sum and the Starlark universe, and it has neither platform nor run: it
+// computes over the arguments a caller hands it. That is what lets any script
+// load any library without the load granting anything — a library cannot
+// query, call a tool, or write an output, so the roles a run presents remain
+// the whole of what it reaches.
+//
+// The engine (internal/platform/scriptrun) binds this package's environment
+// into every script beside the platform module, and binds Loader as the run's
+// load. Keeping the two halves apart is what keeps a library honest: the
+// environment a library is executed in is defined here, where the platform
+// module is not importable.
+package scriptlib
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+// PredeclaredNames are the globals a library sees, and the platform-free half
+// of what a script sees.
+var PredeclaredNames = []string{"json", "date", sumBuiltinName}
+
+// Predeclared builds the bindings PredeclaredNames name. Everything absent from
+// it is absent from a library: no platform, no run, no clock, no imports other
+// than load.
+func Predeclared() starlark.StringDict {
+	return starlark.StringDict{
+		"json":         json.Module,
+		"date":         dateModule,
+		sumBuiltinName: sumBuiltin,
+	}
+}
+
+// IsPredeclared reports whether a name is in a library's environment.
+func IsPredeclared(name string) bool {
+	return slices.Contains(PredeclaredNames, name)
+}
+
+// maxDepth bounds how deeply libraries load libraries. The graph cannot cycle —
+// a pin can only name a version that already existed when it was saved — so
+// this bounds a chain, and a chain eight deep is a design to simplify.
+const maxDepth = 8
+
+// ParseModule reads a load() module string: a library's name and the version
+// it pins, written name@version.
+//
+// A load without a pin is refused rather than read as "the latest". A script
+// that followed a library's latest version would change what it executes
+// whenever somebody else saved, with no version of its own to show for it,
+// which is exactly what a script's version history exists to rule out.
+func ParseModule(module string) (script.Dependency, error) {
+	name, version, ok := strings.Cut(module, "@")
+	if !ok {
+		return script.Dependency{}, fmt.Errorf("load(%q) pins no version: name one, as load(\"%s@<version>\", ...)", module, module)
+	}
+	if err := script.ValidateName(name); err != nil {
+		return script.Dependency{}, fmt.Errorf("load(%q): library %w", module, err)
+	}
+	n, err := strconv.Atoi(version)
+	if err != nil || n < 1 {
+		return script.Dependency{}, fmt.Errorf("load(%q): the version is a whole number from 1", module)
+	}
+	return script.Dependency{Name: name, Version: n}, nil
+}
+
+// Resolver reads the source of one library version.
+type Resolver interface {
+	// LibrarySource returns the source of the named library at the pinned
+	// version, or an error that says why it cannot be loaded.
+	LibrarySource(ctx context.Context, name string, version int) (string, error)
+}
+
+// Loader returns the load function a run binds to its thread. Each module is
+// resolved through libs and executed once per run in the library environment,
+// and a second load of the same module in the same run shares the first one's
+// frozen globals.
+//
+// A library executes on the loading thread, so its steps count against the
+// run's step limit, its prints land in the run's log, and the run's deadline
+// cancels it: a library is code the run executes, and it costs what it costs.
+// A nil libs refuses every load, which is a run with nowhere to read one from.
+func Loader(ctx context.Context, libs Resolver, opts *syntax.FileOptions) func(*starlark.Thread, string) (starlark.StringDict, error) {
+	l := &loader{ctx: ctx, libs: libs, opts: opts, cache: map[string]*loaded{}}
+	return l.load
+}
+
+// loader is one run's load function and what it has loaded.
+type loader struct {
+	ctx   context.Context //nolint:containedctx // one run's context, bound for the life of that run's loads
+	libs  Resolver
+	opts  *syntax.FileOptions
+	cache map[string]*loaded
+	depth int
+}
+
+// loaded is one module's outcome. done is false while the module is still
+// executing, which is how a load of it from inside itself is recognized.
+type loaded struct {
+	globals starlark.StringDict
+	err     error
+	done    bool
+}
+
+// load resolves and executes one module, or answers from the cache.
+func (l *loader) load(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+	if e, ok := l.cache[module]; ok {
+		if !e.done {
+			return nil, fmt.Errorf("%s loads itself", module)
+		}
+		return e.globals, e.err
+	}
+	dep, err := ParseModule(module)
+	if err != nil {
+		return nil, err
+	}
+	if l.libs == nil {
+		return nil, fmt.Errorf("this run has no library store to load %s from", module)
+	}
+	if l.depth >= maxDepth {
+		return nil, fmt.Errorf("libraries load libraries more than %d deep at %s", maxDepth, module)
+	}
+	e := &loaded{}
+	l.cache[module] = e
+	l.depth++
+	e.globals, e.err = l.exec(thread, dep)
+	l.depth--
+	e.done = true
+	return e.globals, e.err
+}
+
+// exec reads one library version and executes it in the library environment.
+func (l *loader) exec(thread *starlark.Thread, dep script.Dependency) (starlark.StringDict, error) {
+	src, err := l.libs.LibrarySource(l.ctx, dep.Name, dep.Version)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", dep.Module(), err)
+	}
+	globals, err := starlark.ExecFileOptions(l.opts, thread, dep.Module(), src, Predeclared())
+	if err != nil {
+		return nil, fmt.Errorf("executing %s: %w", dep.Module(), err)
+	}
+	return globals, nil
+}
+
+// argErr prefixes a built-in's argument error with its name, the way the host
+// bindings report theirs.
+func argErr(b *starlark.Builtin, err error) error {
+	return fmt.Errorf("in %s: %w", b.Name(), err)
+}
@@ -0,0 +1,92 @@
+package scriptlib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+// libs is a Resolver over canned sources keyed by module string.
+type libs map[string]string
+
+func (l libs) LibrarySource(_ context.Context, name string, version int) (string, error) {
+	src, ok := l[fmt.Sprintf("%s@%d", name, version)]
+	if !ok {
+		return "", errors.New("no such library version")
+	}
+	return src, nil
+}
+
+// exec runs source with a Loader over l bound, as the engine binds it.
+func exec(t *testing.T, l Resolver, source string) (starlark.StringDict, error) {
+	t.Helper()
+	opts := &syntax.FileOptions{}
+	thread := &starlark.Thread{Load: Loader(context.Background(), l, opts)}
+	return starlark.ExecFileOptions(opts, thread, "t", source, Predeclared())
+}
+
+func TestParseModule(t *testing.T) {
+	dep, err := ParseModule("fiscal-calendar@12")
+	require.NoError(t, err)
+	assert.Equal(t, script.Dependency{Name: "fiscal-calendar", Version: 12}, dep)
+	assert.Equal(t, "fiscal-calendar@12", dep.Module())
+
+	for module, want := range map[string]string{
+		"fiscal-calendar":   "pins no version",
+		"fiscal@":           "whole number",
+		"fiscal@-1":         "whole number",
+		"@3":                "name is required",
+		"../etc/passwd@1":   "lowercase",
+		"fiscal@3@4":        "whole number",
+		"fiscal-calendar@v": "whole number",
+	} {
+		_, err := ParseModule(module)
+		require.Error(t, err, module)
+		assert.Contains(t, err.Error(), want, module)
+	}
+}
+
+// TestLoader_BindsWhatALibraryDefines proves a loaded library's definitions
+// reach the loading file, and that the library ran in the pure environment.
+func TestLoader_BindsWhatALibraryDefines(t *testing.T) {
+	globals, err := exec(t, libs{"fiscal@1": "def month(d):\n    return date.format(d, 'MM')\n"},
+		"load(\"fiscal@1\", \"month\")\nm = month(\"2026-08-13\")\n")
+	require.NoError(t, err)
+	assert.Equal(t, starlark.String("08"), globals["m"])
+}
+
+// TestLoader_Refusals covers every load that cannot be satisfied.
+func TestLoader_Refusals(t *testing.T) {
+	chain := libs{}
+	for i := 1; i <= maxDepth+1; i++ {
+		chain[fmt.Sprintf("c%d@1", i)] = fmt.Sprintf("load(\"c%d@1\", \"x\")\n", i+1)
+	}
+	cases := []struct {
+		name   string
+		libs   Resolver
+		source string
+		want   string
+	}{
+		{"no store", nil, `load("fiscal@1", "x")`, "no library store"},
+		{"unknown version", libs{}, `load("fiscal@9", "x")`, "reading fiscal@9: no such library version"},
+		{"a library that fails", libs{"bad@1": "x = 1 // 0\n"}, `load("bad@1", "x")`, "executing bad@1"},
+		{"itself", libs{"self@1": "load(\"self@1\", \"x\")\n"}, `load("self@1", "x")`, "self@1 loads itself"},
+		{"too deep", chain, `load("c1@1", "x")`, "more than 8 deep"},
+		{"no platform", libs{"p@1": "x = platform\n"}, `load("p@1", "x")`, "undefined: platform"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := exec(t, tc.libs, tc.source)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.want)
+		})
+	}
+}
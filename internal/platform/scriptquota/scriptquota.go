@@ -0,0 +1,130 @@
+// Package scriptquota bounds what managed scripts may spend, and accounts for
+// what they did spend.
+//
+// A quota is a daily allowance on the four axes a run costs the platform:
+// wall time, rows scanned, tool calls, and output bytes. It is set on one
+// script or on one owner, and a run is bounded by the least of what both have
+// left. Usage is not kept in a ledger of its own: every finished run already
+// records what it spent in its metrics, and a script's or an owner's usage is
+// the sum of those over the window. There is one source of truth for what a
+// run cost, and the rollup an operator reads is the same sum a quota is
+// enforced against.
+//
+// Enforcement is the scriptrun host's (scriptrun.Quota). This package answers
+// the question before the run starts — how much may it spend — and the host
+// stops the run the moment it has spent it.
+package scriptquota
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Window is the period an allowance covers: the rolling day before the run.
+// A rolling window rather than a calendar day, so an allowance spent at 23:59
+// is not whole again a minute later.
+const Window = 24 * time.Hour
+
+// ErrSpent reports a run refused because an allowance it falls under is
+// already spent. It is the script's condition, not the platform's, so a caller
+// must not retry it before the window has moved.
+var ErrSpent = errors.New("script quota spent")
+
+// ErrNotFound reports a quota that does not exist, and ErrNoScript a quota set
+// on a script that does not.
+var (
+	ErrNotFound = errors.New("script quota not found")
+	ErrNoScript = errors.New("no script has that id")
+)
+
+// Limits is a daily allowance on each axis a run spends. Zero leaves an axis
+// unbounded.
+type Limits struct {
+	WallSeconds int64 `json:"wall_seconds,omitempty" example:"3600"`
+	RowsScanned int64 `json:"rows_scanned,omitempty" example:"5000000000"`
+	ToolCalls   int64 `json:"tool_calls,omitempty" example:"2000"`
+	OutputBytes int64 `json:"output_bytes,omitempty" example:"1073741824"`
+}
+
+// Quota is an allowance set on one script or on every script one person owns.
+// Exactly one of ScriptID and Owner is set.
+type Quota struct {
+	ScriptID string `json:"script_id,omitempty"`
+	Owner    string `json:"owner,omitempty" example:"jane@example.com"`
+	Limits
+
+	UpdatedBy string     `json:"updated_by,omitempty" example:"admin@example.com"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// Validate checks the subject and the bounds. It is called on every write.
+func (q *Quota) Validate() error {
+	q.Owner = strings.ToLower(strings.TrimSpace(q.Owner))
+	if (q.ScriptID == "") == (q.Owner == "") {
+		return errors.New("a quota is set on exactly one of script_id and owner")
+	}
+	if q.WallSeconds < 0 || q.RowsScanned < 0 || q.ToolCalls < 0 || q.OutputBytes < 0 {
+		return errors.New("a quota limit is 0 (unbounded) or greater")
+	}
+	return nil
+}
+
+// Usage is what a script, or an owner's scripts, spent over a window.
+type Usage struct {
+	ScriptID string `json:"script_id,omitempty"`
+	Name     string `json:"name,omitempty" example:"daily-sales"`
+	Owner    string `json:"owner,omitempty" example:"jane@example.com"`
+	// Runs counts the finished runs, and QuotaStopped those among them that
+	// their quota stopped or refused.
+	Runs         int64 `json:"runs"`
+	QuotaStopped int64 `json:"quota_stopped"`
+	WallMS       int64 `json:"wall_ms"`
+	RowsScanned  int64 `json:"rows_scanned"`
+	ToolCalls    int64 `json:"tool_calls"`
+	OutputBytes  int64 `json:"output_bytes"`
+}
+
+// Remaining returns what a run may still spend under the quotas that apply to
+// it, each paired with the usage it is measured against: on every axis the
+// least any of them has left, zero where none of them bounds it. An axis some
+// quota has already spent is ErrSpent, naming the axis and whose allowance it
+// was, because that run would be stopped by its first cost anyway.
+func Remaining(quotas []Quota, used []Usage) (Limits, error) {
+	var out Limits
+	for i, q := range quotas {
+		u := used[i]
+		for _, axis := range []struct {
+			name         string
+			limit, spent int64
+			into         *int64
+		}{
+			{"wall time (seconds)", q.WallSeconds, u.WallMS / 1000, &out.WallSeconds},
+			{"rows scanned", q.RowsScanned, u.RowsScanned, &out.RowsScanned},
+			{"tool calls", q.ToolCalls, u.ToolCalls, &out.ToolCalls},
+			{"output bytes", q.OutputBytes, u.OutputBytes, &out.OutputBytes},
+		} {
+			if axis.limit == 0 {
+				continue
+			}
+			left := axis.limit - axis.spent
+			if left <= 0 {
+				return Limits{}, fmt.Errorf("%w: %s has used %d of its daily %d %s",
+					ErrSpent, q.subject(), axis.spent, axis.limit, axis.name)
+			}
+			if *axis.into == 0 || left < *axis.into {
+				*axis.into = left
+			}
+		}
+	}
+	return out, nil
+}
+
+// subject names whose allowance a quota is, for a refusal.
+func (q *Quota) subject() string {
+	if q.Owner != "" {
+		return "owner " + q.Owner
+	}
+	return "the script"
+}
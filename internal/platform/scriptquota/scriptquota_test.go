@@ -0,0 +1,67 @@
+package scriptquota
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemaining_TakesTheLeastEachQuotaHasLeft(t *testing.T) {
+	quotas := []Quota{
+		{ScriptID: "s1", Limits: Limits{RowsScanned: 1000, ToolCalls: 50}},
+		{Owner: "jane@example.com", Limits: Limits{RowsScanned: 5000, WallSeconds: 600}},
+	}
+	used := []Usage{
+		{RowsScanned: 400, ToolCalls: 10},
+		{RowsScanned: 4800, WallMS: 120_000},
+	}
+	left, err := Remaining(quotas, used)
+	require.NoError(t, err)
+	assert.Equal(t, Limits{RowsScanned: 200, ToolCalls: 40, WallSeconds: 480}, left,
+		"the owner's scan allowance is the tighter one; output bytes stay unbounded")
+}
+
+func TestRemaining_RefusesASpentAllowance(t *testing.T) {
+	quotas := []Quota{{Owner: "jane@example.com", Limits: Limits{ToolCalls: 100}}}
+	_, err := Remaining(quotas, []Usage{{ToolCalls: 100}})
+	require.ErrorIs(t, err, ErrSpent)
+	assert.Contains(t, err.Error(), "owner jane@example.com has used 100 of its daily 100 tool calls")
+}
+
+func TestRemaining_NoQuotaBoundsNothing(t *testing.T) {
+	left, err := Remaining(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, Limits{}, left)
+}
+
+func TestQuotaValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		quota   Quota
+		wantErr string
+	}{
+		{"a script quota", Quota{ScriptID: "s1", Limits: Limits{ToolCalls: 10}}, ""},
+		{"an owner quota", Quota{Owner: " Jane@Example.com "}, ""},
+		{"neither subject", Quota{}, "exactly one"},
+		{"both subjects", Quota{ScriptID: "s1", Owner: "jane@example.com"}, "exactly one"},
+		{"a negative limit", Quota{ScriptID: "s1", Limits: Limits{OutputBytes: -1}}, "0 (unbounded) or greater"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.quota.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestQuotaValidate_NormalizesTheOwner(t *testing.T) {
+	q := Quota{Owner: " Jane@Example.com "}
+	require.NoError(t, q.Validate())
+	assert.Equal(t, "jane@example.com", q.Owner)
+}
@@ -0,0 +1,222 @@
+package scriptquota
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// pqForeignKeyViolation is the SQLSTATE of a quota naming a script that does
+// not exist.
+const pqForeignKeyViolation = "23503"
+
+// Rollup listing caps.
+const (
+	defaultRollupLimit = 50
+	maxRollupLimit     = 500
+)
+
+// usageSums is the aggregate every usage read selects over script_runs r, in
+// scanUsage order. A run recorded before the quota axes existed has no key for
+// them, which SUM reads as nothing spent.
+const usageSums = `COUNT(*),
+	COUNT(*) FILTER (WHERE r.failure_kind = 'quota'),
+	COALESCE(SUM((r.metrics->>'duration_ms')::bigint), 0),
+	COALESCE(SUM((r.metrics->>'rows_scanned')::bigint), 0),
+	COALESCE(SUM((r.metrics->>'tool_calls')::bigint), 0),
+	COALESCE(SUM((r.metrics->>'output_bytes')::bigint), 0)`
+
+// rollupOrders maps a rollup's sort key to its ORDER BY, so the key a caller
+// sends never reaches the SQL.
+var rollupOrders = map[string]string{
+	"rows_scanned": "SUM((r.metrics->>'rows_scanned')::bigint) DESC NULLS LAST",
+	"wall_time":    "SUM((r.metrics->>'duration_ms')::bigint) DESC NULLS LAST",
+	"tool_calls":   "SUM((r.metrics->>'tool_calls')::bigint) DESC NULLS LAST",
+	"output_bytes": "SUM((r.metrics->>'output_bytes')::bigint) DESC NULLS LAST",
+	"runs":         "COUNT(*) DESC",
+}
+
+// DefaultRollupSort is the order a rollup takes when the caller names none:
+// the scan, because the job an operator goes looking for is the one reading
+// the warehouse end to end every hour.
+const DefaultRollupSort = "rows_scanned"
+
+// RollupFilter selects a usage rollup.
+type RollupFilter struct {
+	Since time.Time
+	Owner string
+	Sort  string
+	Limit int
+}
+
+// Store keeps quotas and reads usage in PostgreSQL.
+type Store struct {
+	db *sql.DB
+}
+
+// New creates a quota store over db.
+func New(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Allowance returns what one run of a script may spend under its own quota
+// and its owner's, or ErrSpent when either allowance is already gone. A script
+// under no quota gets zero Limits, which bounds nothing.
+func (s *Store) Allowance(ctx context.Context, scriptID, owner string) (Limits, error) {
+	quotas, err := s.query(ctx, `WHERE script_id::text = $1 OR owner_email = LOWER($2)`, scriptID, owner)
+	if err != nil {
+		return Limits{}, err
+	}
+	since := time.Now().Add(-Window)
+	used := make([]Usage, len(quotas))
+	for i, q := range quotas {
+		where, subject := `r.script_id = $2`, q.ScriptID
+		if q.Owner != "" {
+			where, subject = `LOWER(s.owner_email) = $2`, q.Owner
+		}
+		u, err := scanUsage(s.db.QueryRowContext(ctx, `
+			SELECT `+usageSums+`
+			  FROM script_runs r JOIN scripts s ON s.id = r.script_id
+			 WHERE r.finished_at >= $1 AND `+where, since, subject))
+		if err != nil {
+			return Limits{}, fmt.Errorf("reading script quota usage: %w", err)
+		}
+		used[i] = u
+	}
+	return Remaining(quotas, used)
+}
+
+// Rollup returns usage per script over the window, heaviest first by the
+// filter's sort key.
+func (s *Store) Rollup(ctx context.Context, f RollupFilter) ([]Usage, error) {
+	order, ok := rollupOrders[f.Sort]
+	if !ok {
+		order = rollupOrders[DefaultRollupSort]
+	}
+	if f.Limit <= 0 {
+		f.Limit = defaultRollupLimit
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT s.id, s.name, s.owner_email, `+usageSums+`
+		  FROM script_runs r JOIN scripts s ON s.id = r.script_id
+		 WHERE r.finished_at >= $1 AND ($2 = '' OR LOWER(s.owner_email) = LOWER($2))
+		 GROUP BY s.id, s.name, s.owner_email
+		 ORDER BY `+order+`, s.name
+		 LIMIT $3`, f.Since, f.Owner, min(f.Limit, maxRollupLimit))
+	if err != nil {
+		return nil, fmt.Errorf("rolling up script usage: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var out []Usage
+	for rows.Next() {
+		var u Usage
+		if err := rows.Scan(&u.ScriptID, &u.Name, &u.Owner, &u.Runs, &u.QuotaStopped,
+			&u.WallMS, &u.RowsScanned, &u.ToolCalls, &u.OutputBytes); err != nil {
+			return nil, fmt.Errorf("scanning script usage: %w", err)
+		}
+		out = append(out, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rolling up script usage: %w", err)
+	}
+	return out, nil
+}
+
+// scanUsage reads one usageSums row.
+func scanUsage(row *sql.Row) (Usage, error) {
+	var u Usage
+	err := row.Scan(&u.Runs, &u.QuotaStopped, &u.WallMS, &u.RowsScanned, &u.ToolCalls, &u.OutputBytes)
+	return u, err
+}
+
+// List returns every quota, script quotas first.
+func (s *Store) List(ctx context.Context) ([]Quota, error) {
+	return s.query(ctx, `ORDER BY owner_email NULLS FIRST, script_id`)
+}
+
+// query reads the quotas a clause selects.
+func (s *Store) query(ctx context.Context, clause string, args ...any) ([]Quota, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT COALESCE(script_id::text, ''), COALESCE(owner_email, ''), wall_seconds, rows_scanned,
+		       tool_calls, output_bytes, updated_by, updated_at
+		  FROM script_quotas `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("reading script quotas: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var out []Quota
+	for rows.Next() {
+		var q Quota
+		var updatedAt time.Time
+		if err := rows.Scan(&q.ScriptID, &q.Owner, &q.WallSeconds, &q.RowsScanned,
+			&q.ToolCalls, &q.OutputBytes, &q.UpdatedBy, &updatedAt); err != nil {
+			return nil, fmt.Errorf("scanning a script quota: %w", err)
+		}
+		q.UpdatedAt = &updatedAt
+		out = append(out, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading script quotas: %w", err)
+	}
+	return out, nil
+}
+
+// Set records one quota, replacing what its script or owner had.
+func (s *Store) Set(ctx context.Context, q *Quota) error {
+	if err := q.Validate(); err != nil {
+		return err
+	}
+	// Each subject has its own partial unique index, and ON CONFLICT names
+	// the one this row would collide on.
+	conflict := `(script_id) WHERE script_id IS NOT NULL`
+	if q.Owner != "" {
+		conflict = `(owner_email) WHERE owner_email IS NOT NULL`
+	}
+	var updatedAt time.Time
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO script_quotas (script_id, owner_email, wall_seconds, rows_scanned, tool_calls,
+		                           output_bytes, updated_by, updated_at)
+		VALUES (NULLIF($1, '')::uuid, NULLIF($2, ''), $3, $4, $5, $6, $7, NOW())
+		ON CONFLICT `+conflict+` DO UPDATE
+		   SET wall_seconds = EXCLUDED.wall_seconds,
+		       rows_scanned = EXCLUDED.rows_scanned,
+		       tool_calls   = EXCLUDED.tool_calls,
+		       output_bytes = EXCLUDED.output_bytes,
+		       updated_by   = EXCLUDED.updated_by,
+		       updated_at   = NOW()
+		RETURNING updated_at`,
+		q.ScriptID, q.Owner, q.WallSeconds, q.RowsScanned, q.ToolCalls, q.OutputBytes, q.UpdatedBy,
+	).Scan(&updatedAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pqForeignKeyViolation {
+		return ErrNoScript
+	}
+	if err != nil {
+		return fmt.Errorf("set script quota: %w", err)
+	}
+	q.UpdatedAt = &updatedAt
+	return nil
+}
+
+// Delete removes the quota on a script or an owner, or reports ErrNotFound.
+func (s *Store) Delete(ctx context.Context, scriptID, owner string) error {
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM script_quotas
+		 WHERE ($1 <> '' AND script_id::text = $1) OR ($2 <> '' AND owner_email = LOWER($2))`,
+		scriptID, owner)
+	if err != nil {
+		return fmt.Errorf("delete script quota: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking a script quota delete: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package scriptquota
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	quotaColumns = []string{"script_id", "owner_email", "wall_seconds", "rows_scanned",
+		"tool_calls", "output_bytes", "updated_by", "updated_at"}
+	usageColumns = []string{"runs", "quota_stopped", "wall_ms", "rows_scanned", "tool_calls", "output_bytes"}
+	rowTime      = time.Date(2026, 3, 1, 7, 0, 0, 0, time.UTC)
+)
+
+func newMock(t *testing.T) (*Store, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return New(db), mock
+}
+
+func TestAllowance_MeasuresEachQuotaAgainstItsOwnSubject(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM script_quotas WHERE script_id::text = $1 OR owner_email = LOWER($2)")).
+		WithArgs("s1", "Jane@example.com").
+		WillReturnRows(sqlmock.NewRows(quotaColumns).
+			AddRow("s1", "", 0, 1000, 0, 0, "admin@example.com", rowTime).
+			AddRow("", "jane@example.com", 0, 0, 20, 0, "admin@example.com", rowTime))
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE r.finished_at >= $1 AND r.script_id = $2")).
+		WithArgs(sqlmock.AnyArg(), "s1").
+		WillReturnRows(sqlmock.NewRows(usageColumns).AddRow(3, 0, 9000, 250, 6, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE r.finished_at >= $1 AND LOWER(s.owner_email) = $2")).
+		WithArgs(sqlmock.AnyArg(), "jane@example.com").
+		WillReturnRows(sqlmock.NewRows(usageColumns).AddRow(9, 0, 30000, 900, 15, 0))
+
+	left, err := s.Allowance(context.Background(), "s1", "Jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, Limits{RowsScanned: 750, ToolCalls: 5}, left)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAllowance_NoQuotaReadsNoUsage(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM script_quotas")).WillReturnRows(sqlmock.NewRows(quotaColumns))
+
+	left, err := s.Allowance(context.Background(), "s1", "jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, Limits{}, left)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRollup_OrdersByAnAllowlistedKey(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY SUM((r.metrics->>'rows_scanned')::bigint) DESC NULLS LAST, s.name")).
+		WithArgs(rowTime, "", maxRollupLimit).
+		WillReturnRows(sqlmock.NewRows(append([]string{"id", "name", "owner_email"}, usageColumns...)).
+			AddRow("s1", "hourly-scan", "jane@example.com", 24, 1, 3_600_000, 9_000_000_000, 48, 1024))
+
+	usage, err := s.Rollup(context.Background(), RollupFilter{Since: rowTime, Sort: "'; DROP TABLE scripts; --", Limit: 10_000})
+	require.NoError(t, err)
+	require.Len(t, usage, 1)
+	assert.Equal(t, Usage{ScriptID: "s1", Name: "hourly-scan", Owner: "jane@example.com", Runs: 24,
+		QuotaStopped: 1, WallMS: 3_600_000, RowsScanned: 9_000_000_000, ToolCalls: 48, OutputBytes: 1024}, usage[0])
+}
+
+func TestSet_UpsertsOnTheSubjectsIndex(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("ON CONFLICT (owner_email) WHERE owner_email IS NOT NULL DO UPDATE")).
+		WithArgs("", "jane@example.com", int64(0), int64(0), int64(200), int64(0), "admin@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(rowTime))
+
+	q := &Quota{Owner: "Jane@example.com", Limits: Limits{ToolCalls: 200}, UpdatedBy: "admin@example.com"}
+	require.NoError(t, s.Set(context.Background(), q))
+	assert.Equal(t, rowTime, *q.UpdatedAt)
+}
+
+func TestSet_AnUnknownScriptIsNamed(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("ON CONFLICT (script_id) WHERE script_id IS NOT NULL")).
+		WillReturnError(&pq.Error{Code: pqForeignKeyViolation})
+
+	err := s.Set(context.Background(), &Quota{ScriptID: "00000000-0000-0000-0000-000000000000"})
+	assert.ErrorIs(t, err, ErrNoScript)
+}
+
+func TestDelete_ReportsAMissingQuota(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM script_quotas")).
+		WithArgs("s1", "").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.ErrorIs(t, s.Delete(context.Background(), "s1", ""), ErrNotFound)
+}
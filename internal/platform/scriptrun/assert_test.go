@@ -0,0 +1,110 @@
+package scriptrun
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAssert_AFailedCheckIsRecordedAndTheRunContinues is the contract of the
+// assertion family: a failed check is a verdict handed back and recorded, not
+// an error, so every check a run makes is reported and the script may act on
+// the result.
+func TestAssert_AFailedCheckIsRecordedAndTheRunContinues(t *testing.T) {
+	caller := &recordingCaller{rows: []any{map[string]any{"row_count": float64(3)}}}
+	result, err := execute(t, `
+c = platform.assert_row_count("hive.sales.orders", min=10, where="ds = :d", params={"d": run.params["day"]},
+    connection="warehouse", name="orders landed")
+print(c["passed"], c["observed"])
+if c["passed"]:
+    platform.export(name="daily", rows=[])
+print("after")
+`, caller, map[string]any{"day": "2026-08-12"})
+
+	require.NoError(t, err, "the script executed correctly; what it found wrong was the data")
+	assert.Equal(t, "False 3 rows\nafter\n", result.Log)
+	assert.Empty(t, result.Exports, "the script branched on the verdict")
+	require.Len(t, result.Checks, 1)
+	check := result.Checks[0]
+	assert.Equal(t, "orders landed", check.Name)
+	assert.Equal(t, "row_count", check.Check)
+	assert.Equal(t, "warehouse", check.Connection)
+	assert.Equal(t, "hive.sales.orders", check.Table)
+	assert.False(t, check.Passed)
+	assert.Equal(t, 1, result.Queries)
+
+	require.Len(t, caller.calls, 1)
+	assert.Equal(t, toolQuery, caller.calls[0].name)
+	assert.Equal(t, `SELECT COUNT(*) AS row_count FROM "hive"."sales"."orders" WHERE ds = '2026-08-12'`,
+		caller.calls[0].args["sql"], "the filter is bound, not spliced")
+	assert.Equal(t, "warehouse", caller.calls[0].args["connection"])
+}
+
+func TestAssert_EveryCheckReportsUnderItsKindByDefault(t *testing.T) {
+	caller := &recordingCaller{rows: []any{}}
+	result, err := execute(t, `
+c = platform.assert_empty("SELECT * FROM orders WHERE total < 0")
+print(c["name"], c["passed"])
+`, caller, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "empty True\n", result.Log)
+	require.Len(t, result.Checks, 1)
+	assert.True(t, result.Checks[0].Passed)
+}
+
+func TestAssert_UniqueTakesOneColumnOrSeveral(t *testing.T) {
+	caller := &recordingCaller{rows: []any{map[string]any{"duplicates": float64(0)}}}
+	result, err := execute(t, `
+platform.assert_unique("orders", "order_id")
+platform.assert_unique("orders", ["order_id", "line"])
+`, caller, nil)
+
+	require.NoError(t, err)
+	require.Len(t, result.Checks, 2)
+	assert.Equal(t, []string{"order_id"}, result.Checks[0].Columns)
+	assert.Equal(t, []string{"order_id", "line"}, result.Checks[1].Columns)
+}
+
+// TestAssert_FreshnessIsMeasuredFromTheFireTime pins the determinism half: the
+// check's query carries the run's pinned instant, not a clock read.
+func TestAssert_FreshnessIsMeasuredFromTheFireTime(t *testing.T) {
+	caller := &recordingCaller{rows: []any{map[string]any{"latest": "2026-08-13 06:00:00", "age_seconds": float64(5400)}}}
+	result, err := execute(t, `platform.assert_fresh("orders", "loaded_at", max_age_hours=2)`, caller, nil)
+
+	require.NoError(t, err)
+	require.Len(t, result.Checks, 1)
+	assert.True(t, result.Checks[0].Passed)
+	assert.Contains(t, caller.calls[0].args["sql"], "from_iso8601_timestamp('2026-08-13T07:30:00Z')")
+}
+
+// TestAssert_MisuseIsAnError pins the other side: a check that cannot be
+// measured stops the run as any failed query does, because it has no verdict.
+func TestAssert_MisuseIsAnError(t *testing.T) {
+	tests := []struct {
+		name, source, wantErr string
+	}{
+		{"no bound", `platform.assert_row_count("orders")`, "asserts nothing"},
+		{"bad rate", `platform.assert_null_rate("orders", "id", max_rate="low")`, "max_rate must be a number"},
+		{"params without a filter", `platform.assert_row_count("orders", min=1, params={"d": 1})`, "no where filter"},
+		{"columns of the wrong type", `platform.assert_unique("orders", 3)`, "column name or a list"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := execute(t, tt.source, &recordingCaller{}, nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	_, err := execute(t, `platform.assert_row_count("orders", min=1)`, &recordingCaller{err: errors.New("table not found")}, nil)
+	require.ErrorContains(t, err, "table not found")
+}
+
+func TestAssert_NotAvailableWithoutACaller(t *testing.T) {
+	_, err := Run(context.Background(), Options{Source: `platform.assert_row_count("orders", min=1)`, Name: "test"})
+	require.ErrorContains(t, err, "not available in this context")
+}
@@ -6,6 +6,9 @@ import (
 	"sort"
 
 	"go.starlark.net/starlark"
+
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptsecret"
+	"github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway"
 )
 
 // maxConvertDepth bounds recursion through a converted value. Tool results are
@@ -156,6 +159,9 @@ func convertScalarFromStarlark(v starlark.Value) (any, error) {
 		return i, nil
 	case starlark.Float:
 		return float64(t), nil
+	case *scriptsecret.Handle:
+		return nil, fmt.Errorf("%s cannot leave the script: pass it as platform.hmac's key or as a %q value of a %s or %s call",
+			t.String(), scriptsecret.HeadersArg, apigateway.ToolInvokeEndpoint, apigateway.ToolExport)
 	default:
 		return nil, fmt.Errorf("values of type %s cannot leave the script", v.Type())
 	}
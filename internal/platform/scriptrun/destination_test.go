@@ -82,7 +82,7 @@ func TestExport_NoConfiguredDestinationsRefusalNamesThePortal(t *testing.T) {
 		`platform.export(name="daily", rows=[], destination="elsewhere")`,
 		nil, &recordingExporter{})
 	require.Error(t, err)
-	assert.Contains(t, err.Error(), "declares no bucket destinations")
+	assert.Contains(t, err.Error(), "declares no destinations")
 	assert.Contains(t, err.Error(), `"portal"`)
 }
 
@@ -141,7 +141,7 @@ func TestCheckDestinations_FlagsAnUndeclaredName(t *testing.T) {
 	require.Len(t, checked.Findings, 1)
 	assert.Equal(t, SeverityError, checked.Findings[0].Severity)
 	assert.Contains(t, checked.Findings[0].Message, `destination "drop" is not configured`)
-	assert.Contains(t, checked.Findings[0].Message, "declares no bucket destinations")
+	assert.Contains(t, checked.Findings[0].Message, "declares no destinations")
 }
 
 // TestCheckDestinations_RefusalIsTheRuntimeRefusal is the point of sharing
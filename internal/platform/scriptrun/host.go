@@ -1,18 +1,25 @@
 package scriptrun
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"maps"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"go.starlark.net/starlark"
 	"go.starlark.net/starlarkstruct"
 
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptcheck"
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptlib"
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptsecret"
 	"github.com/txn2/mcp-data-platform/pkg/contenttype"
 	"github.com/txn2/mcp-data-platform/pkg/script"
+	"github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway"
 	trinokit "github.com/txn2/mcp-data-platform/pkg/toolkits/trino"
 )
 
@@ -48,6 +55,24 @@ const (
 	// call is refused by the middleware in the middleware's own words, exactly
 	// as it refuses an agent.
 	CapabilityCall = "platform.call"
+
+	// The assertion family checks the data rather than reading it for the
+	// script. Each issues one query through the same Caller, records what it
+	// observed beside what was expected, and hands the verdict back without
+	// stopping the run; a run that finishes with a failed check is a
+	// data-quality failure rather than an execution one.
+	CapabilityAssertRowCount = "platform.assert_row_count"
+	CapabilityAssertNullRate = "platform.assert_null_rate"
+	CapabilityAssertFresh    = "platform.assert_fresh"
+	CapabilityAssertUnique   = "platform.assert_unique"
+	CapabilityAssertEmpty    = "platform.assert_empty"
+
+	// CapabilitySecret hands the run an opaque handle on a secret an operator
+	// granted this script version, and CapabilityHMAC signs with one. Neither
+	// is a tool call: a handle reaches the outside world only as platform.hmac's
+	// key or as a header value of platform.call (scriptsecret).
+	CapabilitySecret = "platform.secret"
+	CapabilityHMAC   = "platform.hmac"
 )
 
 // Capabilities is the full member set of the platform module, in the order help
@@ -57,7 +82,17 @@ const (
 // member" refusal. It is not a boundary: platform.call reaches every tool the
 // run's persona authorizes, and what a script reaches is read from the source
 // by Validate, which reports the tool names it names.
-var Capabilities = []string{CapabilityQuery, CapabilityExport, CapabilityPublishData, CapabilityCall}
+var Capabilities = []string{
+	CapabilityQuery, CapabilityExport, CapabilityPublishData, CapabilityCall,
+	CapabilityAssertRowCount, CapabilityAssertNullRate, CapabilityAssertFresh, CapabilityAssertUnique, CapabilityAssertEmpty,
+	CapabilitySecret, CapabilityHMAC,
+}
+
+// assertions is the assertion family, which validate reads alike: each names
+// its connection the way platform.query does.
+var assertions = []string{
+	CapabilityAssertRowCount, CapabilityAssertNullRate, CapabilityAssertFresh, CapabilityAssertUnique, CapabilityAssertEmpty,
+}
 
 // The formats platform.export accepts, split by what serializes them. A format
 // may appear in both sets: markdown and text are sometimes a table computed
@@ -133,6 +168,13 @@ type hostState struct {
 	ctx     context.Context //nolint:containedctx // one run's context, bound for the life of that run and used only by its host bindings
 	queries int
 	exports []ExportRecord
+	checks  []script.QualityCheck
+
+	// What the run has spent against its quota, and whether it stopped there.
+	toolCalls   int
+	rowsScanned int64
+	outputBytes int64
+	quotaSpent  bool
 }
 
 // resolveDestination turns the destination a script named into the address the
@@ -164,7 +206,7 @@ func ResolveDestination(name string, declared []script.Destination) (script.Dest
 		}
 	}
 	if len(declared) == 0 {
-		return script.Destination{}, fmt.Errorf("destination %q is not configured: this deployment declares no bucket destinations, so %q is the only place a script can write",
+		return script.Destination{}, fmt.Errorf("destination %q is not configured: this deployment declares no destinations, so %q is the only place a script can write",
 			name, script.DestinationPortal)
 	}
 	return script.Destination{}, fmt.Errorf("destination %q is not configured; this deployment declares %s, and %q is always available",
@@ -203,7 +245,7 @@ func (h *hostState) runValue() starlark.Value {
 	}
 	rec := starlarkstruct.FromStringDict(starlark.String("run"), starlark.StringDict{
 		"run_id":    starlark.String(h.opts.RunID),
-		"fire_time": starlark.String(h.opts.FireTime.UTC().Format(timeLayout)),
+		"fire_time": starlark.String(h.opts.FireTime.UTC().Format(scriptlib.TimeLayout)),
 		"params":    params,
 	})
 	rec.Freeze()
@@ -235,11 +277,17 @@ func (h *hostState) query(_ *starlark.Thread, b *starlark.Builtin, args starlark
 	if connection != "" {
 		call["connection"] = connection
 	}
+	if err := h.spendCall(b); err != nil {
+		return nil, err
+	}
 	out, err := h.opts.Caller.CallTool(h.ctx, toolQuery, call)
 	if err != nil {
 		return nil, argErr(b, err)
 	}
 	h.queries++
+	if err := h.spendScan(b, toolQuery, out); err != nil {
+		return nil, err
+	}
 	return h.queryResult(b.Name(), out)
 }
 
@@ -271,14 +319,20 @@ func (h *hostState) call(_ *starlark.Thread, b *starlark.Builtin, args starlark.
 	if h.opts.Caller == nil {
 		return nil, fmt.Errorf("host binding %s is not available in this context", b.Name())
 	}
-	payload, err := callArguments(toolArgs)
+	payload, err := callArguments(tool, toolArgs)
 	if err != nil {
 		return nil, argErr(b, err)
 	}
+	if err := h.spendCall(b); err != nil {
+		return nil, err
+	}
 	out, err := h.opts.Caller.CallTool(h.ctx, tool, payload)
 	if err != nil {
 		return nil, argErr(b, err)
 	}
+	if err := h.spendScan(b, tool, out); err != nil {
+		return nil, err
+	}
 	// The byte cap is the query binding's, applied here for the same reason: a
 	// heap the interpreter cannot bound is the one resource no limit in this
 	// engine covers, and a tool asked for more than a run can hold must fail
@@ -294,13 +348,30 @@ func (h *hostState) call(_ *starlark.Thread, b *starlark.Builtin, args starlark.
 	return value, nil
 }
 
+// headerTools are the tools whose headers argument goes out as HTTP request
+// headers. Another tool's "headers" is whatever that tool makes of it — a
+// field it echoes back, a value it writes to the audit log unredacted — so a
+// secret handle is opened for these calls and no others.
+var headerTools = map[string]bool{
+	apigateway.ToolInvokeEndpoint: true,
+	apigateway.ToolExport:         true,
+}
+
 // callArguments converts the argument dict a script passed into the plain Go
 // map a tool call takes. A missing dict is an empty argument set, which is what
-// a tool taking no arguments wants.
-func callArguments(args *starlark.Dict) (map[string]any, error) {
+// a tool taking no arguments wants. A secret handle is opened only as a header
+// value of a tool in headerTools; anywhere else the conversion refuses it.
+func callArguments(tool string, args *starlark.Dict) (map[string]any, error) {
 	if args == nil {
 		return map[string]any{}, nil
 	}
+	if headerTools[tool] {
+		revealed, err := scriptsecret.RevealHeaders(args)
+		if err != nil {
+			return nil, err
+		}
+		args = revealed
+	}
 	converted, err := dictFromStarlark(args, 0)
 	if err != nil {
 		return nil, err
@@ -315,6 +386,42 @@ func callArguments(args *starlark.Dict) (map[string]any, error) {
 	return out, nil
 }
 
+// secret reveals one granted secret as a handle. The name must be granted to
+// the version this run executes; a draft run has no vault, because the source
+// it executes is not the code any grant was made to.
+func (h *hostState) secret(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name); err != nil {
+		return nil, argErr(b, err)
+	}
+	if h.opts.Secrets == nil {
+		return nil, fmt.Errorf("in %s: no secrets are available to this run; a secret is granted to a saved script version, never to a draft", b.Name())
+	}
+	value, err := h.opts.Secrets.Reveal(h.ctx, name)
+	if err != nil {
+		return nil, argErr(b, err)
+	}
+	return scriptsecret.NewHandle(name, value), nil
+}
+
+// hmac signs a message with a secret handle and returns the encoded MAC.
+func (h *hostState) hmac(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		key                 *scriptsecret.Handle
+		message             string
+		algorithm, encoding = "sha256", "hex"
+	)
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "key", &key, "message", &message,
+		"algorithm?", &algorithm, "encoding?", &encoding); err != nil {
+		return nil, argErr(b, fmt.Errorf("%w; key is platform.secret(name)", err))
+	}
+	mac, err := scriptsecret.HMAC(key, message, algorithm, encoding)
+	if err != nil {
+		return nil, argErr(b, err)
+	}
+	return starlark.String(mac), nil
+}
+
 // queryResult caps and converts one query tool result.
 func (h *hostState) queryResult(name string, out map[string]any) (starlark.Value, error) {
 	raw, present := out["rows"]
@@ -386,6 +493,9 @@ func (h *hostState) export(_ *starlark.Thread, b *starlark.Builtin, args starlar
 		return nil, err
 	}
 	h.exports = append(h.exports, record)
+	if err := h.spendOutput(b, record.Bytes); err != nil {
+		return nil, err
+	}
 	return exportValue(record), nil
 }
 
@@ -447,6 +557,9 @@ func (h *hostState) publishData(_ *starlark.Thread, b *starlark.Builtin, args st
 		return nil, err
 	}
 	h.exports = append(h.exports, record)
+	if err := h.spendOutput(b, record.Bytes); err != nil {
+		return nil, err
+	}
 	return exportValue(record), nil
 }
 
@@ -725,15 +838,16 @@ type logBuffer struct {
 	truncated bool
 }
 
-// write appends one print line, stopping at the cap.
-func (l *logBuffer) write(msg string) {
+// write appends one print line, stopping at the cap, and reports whether the
+// log changed.
+func (l *logBuffer) write(msg string) bool {
 	if l.truncated {
-		return
+		return false
 	}
 	remaining := l.limit - l.buf.Len()
 	if remaining <= 0 {
 		l.truncated = true
-		return
+		return true
 	}
 	line := msg + "\n"
 	if len(line) > remaining {
@@ -742,9 +856,10 @@ func (l *logBuffer) write(msg string) {
 		// to U+FFFD in the response.
 		l.buf.WriteString(truncateRunes(line, remaining))
 		l.truncated = true
-		return
+		return true
 	}
 	l.buf.WriteString(line)
+	return true
 }
 
 // truncateRunes cuts s to at most n bytes without splitting a rune.
@@ -890,3 +1005,255 @@ func tabular(columns []string, rows []any) [][]any {
 	}
 	return out
 }
+
+// maxChecks bounds the assertions one run may make. Each is a query, and a run
+// asserting more than this is a test suite, which is a job for a tool built
+// for one.
+const maxChecks = 64
+
+// checkResultFields is the allocation hint for the dict an assertion returns.
+const checkResultFields = 7
+
+// checkArgs are the arguments every assertion over a table shares: the table,
+// an optional filter bound exactly as a platform.query statement is, the
+// connection, and the author's label for the check.
+type checkArgs struct {
+	table, where, connection, name string
+	params                         *starlark.Dict
+}
+
+// optional lists the shared keyword arguments in UnpackArgs form, after each
+// binding's own.
+func (c *checkArgs) optional() []any {
+	return []any{"where?", &c.where, "params?", &c.params, "connection?", &c.connection, "name?", &c.name}
+}
+
+// source binds the filter and locates the table.
+func (c *checkArgs) source() (scriptcheck.Source, error) {
+	if strings.TrimSpace(c.where) == "" {
+		if c.params != nil && c.params.Len() > 0 {
+			return scriptcheck.Source{}, errors.New("params were given with no where filter to bind them into")
+		}
+		return scriptcheck.Source{Table: c.table}, nil
+	}
+	where, err := bindSQL(c.where, c.params)
+	if err != nil {
+		return scriptcheck.Source{}, err
+	}
+	return scriptcheck.Source{Table: c.table, Where: where}, nil
+}
+
+// assertRowCount implements platform.assert_row_count: the table's row count,
+// optionally filtered, lies within min and max.
+func (h *hostState) assertRowCount(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		c          checkArgs
+		minV, maxV starlark.Value = starlark.None, starlark.None
+	)
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs,
+		append([]any{"table", &c.table, "min?", &minV, "max?", &maxV}, c.optional()...)...); err != nil {
+		return nil, argErr(b, err)
+	}
+	var lo, hi *int64
+	if err := optionalCount("min", minV, &lo); err != nil {
+		return nil, argErr(b, err)
+	}
+	if err := optionalCount("max", maxV, &hi); err != nil {
+		return nil, argErr(b, err)
+	}
+	src, err := c.source()
+	if err != nil {
+		return nil, argErr(b, err)
+	}
+	check, err := scriptcheck.RowCount(src, lo, hi)
+	return h.assert(b, c, check, err)
+}
+
+// assertNullRate implements platform.assert_null_rate: the share of rows in
+// which the column is NULL is at most max_rate, a fraction between 0 and 1.
+func (h *hostState) assertNullRate(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		c      checkArgs
+		column string
+		rate   starlark.Value
+	)
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs,
+		append([]any{"table", &c.table, "column", &column, "max_rate", &rate}, c.optional()...)...); err != nil {
+		return nil, argErr(b, err)
+	}
+	maxRate, ok := starlark.AsFloat(rate)
+	if !ok {
+		return nil, fmt.Errorf("in %s: max_rate must be a number, got %s", b.Name(), rate.Type())
+	}
+	src, err := c.source()
+	if err != nil {
+		return nil, argErr(b, err)
+	}
+	check, err := scriptcheck.NullRate(src, column, maxRate)
+	return h.assert(b, c, check, err)
+}
+
+// assertFresh implements platform.assert_fresh: the newest value of a
+// timestamp column is at most max_age_hours older than run.fire_time.
+func (h *hostState) assertFresh(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		c      checkArgs
+		column string
+		age    starlark.Value
+	)
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs,
+		append([]any{"table", &c.table, "column", &column, "max_age_hours", &age}, c.optional()...)...); err != nil {
+		return nil, argErr(b, err)
+	}
+	maxHours, ok := starlark.AsFloat(age)
+	if !ok {
+		return nil, fmt.Errorf("in %s: max_age_hours must be a number, got %s", b.Name(), age.Type())
+	}
+	src, err := c.source()
+	if err != nil {
+		return nil, argErr(b, err)
+	}
+	check, err := scriptcheck.Fresh(src, column, time.Duration(maxHours*float64(time.Hour)), h.opts.FireTime)
+	return h.assert(b, c, check, err)
+}
+
+// assertUnique implements platform.assert_unique: no two rows share a value of
+// the column, or of the list of columns taken together.
+func (h *hostState) assertUnique(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		c       checkArgs
+		columns starlark.Value
+	)
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs,
+		append([]any{"table", &c.table, "columns", &columns}, c.optional()...)...); err != nil {
+		return nil, argErr(b, err)
+	}
+	names, err := columnList(columns)
+	if err != nil {
+		return nil, argErr(b, err)
+	}
+	src, err := c.source()
+	if err != nil {
+		return nil, argErr(b, err)
+	}
+	check, err := scriptcheck.Unique(src, names)
+	return h.assert(b, c, check, err)
+}
+
+// assertEmpty implements platform.assert_empty: a statement the author writes,
+// selecting the rows that break a rule, returns none.
+func (h *hostState) assertEmpty(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		c   checkArgs
+		sql string
+	)
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "sql", &sql,
+		"params?", &c.params, "connection?", &c.connection, "name?", &c.name); err != nil {
+		return nil, argErr(b, err)
+	}
+	bound, err := bindSQL(sql, c.params)
+	if err != nil {
+		return nil, argErr(b, err)
+	}
+	check, err := scriptcheck.Empty(bound)
+	return h.assert(b, c, check, err)
+}
+
+// assert issues one check's query, judges the answer, and records the verdict
+// on the run.
+//
+// A failed check does not stop the script. Every check a run makes is reported,
+// not only the first to fail, and the script may branch on the verdict it is
+// handed — skip publishing a dashboard over data it just found wanting. What
+// marks the run is the record: a run that finishes with a failed check is a
+// data-quality failure, however the script went on. A query that cannot run is
+// different, and stops the run as any failed query does: a check that measured
+// nothing has no verdict to report.
+func (h *hostState) assert(b *starlark.Builtin, args checkArgs, check scriptcheck.Check, err error) (starlark.Value, error) {
+	if err != nil {
+		return nil, argErr(b, err)
+	}
+	if h.opts.Caller == nil {
+		return nil, fmt.Errorf("host binding %s is not available in this context", b.Name())
+	}
+	if len(h.checks) >= maxChecks {
+		return nil, fmt.Errorf("in %s: a run may make at most %d checks", b.Name(), maxChecks)
+	}
+	call := map[string]any{"sql": check.SQL, "limit": check.Limit}
+	if args.connection != "" {
+		call["connection"] = args.connection
+	}
+	if err := h.spendCall(b); err != nil {
+		return nil, err
+	}
+	out, err := h.opts.Caller.CallTool(h.ctx, toolQuery, call)
+	if err != nil {
+		return nil, argErr(b, err)
+	}
+	h.queries++
+	if err := h.spendScan(b, toolQuery, out); err != nil {
+		return nil, err
+	}
+	rows, ok := out["rows"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("result of %s has no rows field; the query tool answered in an unexpected shape", b.Name())
+	}
+	verdict, err := check.Judge(rows)
+	if err != nil {
+		return nil, fmt.Errorf("in %s: reading the check's result: %w", b.Name(), err)
+	}
+	verdict.Name, verdict.Connection = cmp.Or(args.name, verdict.Check), args.connection
+	h.checks = append(h.checks, verdict)
+	return checkValue(verdict), nil
+}
+
+// checkValue renders one check as the dict the script receives.
+func checkValue(c script.QualityCheck) starlark.Value {
+	out := starlark.NewDict(checkResultFields)
+	_ = out.SetKey(starlark.String("name"), starlark.String(c.Name))
+	_ = out.SetKey(starlark.String("check"), starlark.String(c.Check))
+	_ = out.SetKey(starlark.String("table"), starlark.String(c.Table))
+	_ = out.SetKey(starlark.String("passed"), starlark.Bool(c.Passed))
+	_ = out.SetKey(starlark.String("observed"), starlark.String(c.Observed))
+	_ = out.SetKey(starlark.String("expected"), starlark.String(c.Expected))
+	_ = out.SetKey(starlark.String("message"), starlark.String(c.Message()))
+	return out
+}
+
+// columnList reads assert_unique's columns: one name, or a list of names taken
+// together as the key.
+func columnList(v starlark.Value) ([]string, error) {
+	if s, ok := v.(starlark.String); ok {
+		return []string{string(s)}, nil
+	}
+	iter, ok := v.(starlark.Iterable)
+	if !ok {
+		return nil, fmt.Errorf("columns must be a column name or a list of them, got %s", v.Type())
+	}
+	out := []string{}
+	it := iter.Iterate()
+	defer it.Done()
+	var item starlark.Value
+	for it.Next(&item) {
+		s, ok := item.(starlark.String)
+		if !ok {
+			return nil, fmt.Errorf("columns must be strings, got %s", item.Type())
+		}
+		out = append(out, string(s))
+	}
+	return out, nil
+}
+
+// optionalCount reads an optional row bound into dst, leaving it nil when the
+// author gave none.
+func optionalCount(name string, v starlark.Value, dst **int64) error {
+	if v == starlark.None {
+		return nil
+	}
+	var n int64
+	if err := starlark.AsInt(v, &n); err != nil {
+		return fmt.Errorf("%s must be a whole number of rows: %w", name, err)
+	}
+	*dst = &n
+	return nil
+}
@@ -0,0 +1,90 @@
+package scriptrun
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.starlark.net/starlark"
+
+	trinokit "github.com/txn2/mcp-data-platform/pkg/toolkits/trino"
+)
+
+// ErrQuota marks a run stopped by its quota. Like the other limits it is a
+// script-side failure the caller must not retry: the allowance is spent, and
+// another attempt inside the same window only spends more of it.
+var ErrQuota = errors.New("script run exceeded its quota")
+
+// Quota is what one run may spend: the least its script and its owner have
+// left of their allowance, computed by the caller before the run starts. Zero
+// on an axis leaves that axis unbounded, and a draft run carries none.
+//
+// Each axis is checked as the cost is incurred, because only the tool knows
+// what a call cost once it has answered. A tool call is refused before it is
+// made; the query that crosses the scan allowance and the output that crosses
+// the byte allowance have already happened, and the run stops there.
+type Quota struct {
+	WallTime    time.Duration
+	RowsScanned int64
+	ToolCalls   int
+	OutputBytes int64
+}
+
+// spendCall counts one tool call, refusing the call past the allowance.
+func (h *hostState) spendCall(b *starlark.Builtin) error {
+	if limit := h.opts.Quota.ToolCalls; limit > 0 && h.toolCalls >= limit {
+		return h.overQuota(fmt.Errorf("in %s: the run has made the %d tool calls its quota allows", b.Name(), limit))
+	}
+	h.toolCalls++
+	return nil
+}
+
+// spendScan adds the rows a tool result reports scanning. A query whose scan
+// could not be counted stops a run whose quota bounds scanning: charging it
+// nothing would let every query that outlives its task stats past the limit.
+func (h *hostState) spendScan(b *starlark.Builtin, tool string, out map[string]any) error {
+	rows, counted := scannedRows(out)
+	limit := h.opts.Quota.RowsScanned
+	if limit > 0 && tool == toolQuery && !counted {
+		return h.overQuota(fmt.Errorf("in %s: the rows the query scanned could not be counted, and the run's quota bounds them",
+			b.Name()))
+	}
+	h.rowsScanned += rows
+	if limit > 0 && h.rowsScanned > limit {
+		return h.overQuota(fmt.Errorf("in %s: the run has scanned %d rows, over the %d its quota allows; narrow the query to the partitions it needs",
+			b.Name(), h.rowsScanned, limit))
+	}
+	return nil
+}
+
+// spendOutput adds the bytes one output wrote.
+func (h *hostState) spendOutput(b *starlark.Builtin, n int) error {
+	h.outputBytes += int64(n)
+	if limit := h.opts.Quota.OutputBytes; limit > 0 && h.outputBytes > limit {
+		return h.overQuota(fmt.Errorf("in %s: the run has written %d output bytes, over the %d its quota allows",
+			b.Name(), h.outputBytes, limit))
+	}
+	return nil
+}
+
+// overQuota records that the run stopped on its quota, which the interpreter's
+// error would otherwise reduce to a message.
+func (h *hostState) overQuota(err error) error {
+	h.quotaSpent = true
+	return err
+}
+
+// scannedRows reads the rows a query tool reports having scanned from its
+// stats, where the trino toolkit's scan stats middleware puts them, and
+// whether it reported any. A tool other than the query tool that reports none
+// scanned nothing this run can account for.
+func scannedRows(out map[string]any) (int64, bool) {
+	stats, _ := out["stats"].(map[string]any)
+	switch n := stats[trinokit.StatsProcessedRows].(type) {
+	case float64:
+		return int64(n), true
+	case int64:
+		return n, true
+	}
+	return 0, false
+}
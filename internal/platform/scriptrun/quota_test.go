@@ -0,0 +1,165 @@
+package scriptrun
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	trinoclient "github.com/txn2/mcp-trino/pkg/client"
+	trinotools "github.com/txn2/mcp-trino/pkg/tools"
+
+	trinokit "github.com/txn2/mcp-data-platform/pkg/toolkits/trino"
+)
+
+// scanningCaller answers every query as one that scanned 600 rows.
+type scanningCaller struct{ calls int }
+
+func (c *scanningCaller) CallTool(context.Context, string, map[string]any) (map[string]any, error) {
+	c.calls++
+	return map[string]any{
+		"columns":   []any{},
+		"rows":      []any{map[string]any{"n": float64(1)}},
+		"row_count": float64(1),
+		"stats": map[string]any{
+			"row_count": float64(1), "truncated": false, "limit_applied": float64(1000),
+			"query_id": "20260118_101500_00042_abcde", "processed_rows": float64(600), "processed_bytes": float64(48000),
+		},
+	}, nil
+}
+
+// countingTrino is the engine behind a real mcp-trino trino_query: every query
+// answers one row, as a COUNT(*) does whatever it read.
+type countingTrino struct{}
+
+func (countingTrino) Query(context.Context, string, trinoclient.QueryOptions) (*trinoclient.QueryResult, error) {
+	return &trinoclient.QueryResult{
+		Columns: []trinoclient.ColumnInfo{{Name: "n", Type: "bigint"}},
+		Rows:    []map[string]any{{"n": 48000}},
+		Stats:   trinoclient.QueryStats{RowCount: 1, QueryID: "20260118_101500_00042_abcde"},
+	}, nil
+}
+
+func (countingTrino) Explain(context.Context, string, trinoclient.ExplainType) (*trinoclient.ExplainResult, error) {
+	return nil, errors.New("no trino behind this test")
+}
+
+func (countingTrino) ListCatalogs(context.Context) ([]string, error) {
+	return nil, errors.New("no trino behind this test")
+}
+
+func (countingTrino) ListSchemas(context.Context, string) ([]string, error) {
+	return nil, errors.New("no trino behind this test")
+}
+
+func (countingTrino) ListTables(context.Context, string, string) ([]trinoclient.TableInfo, error) {
+	return nil, errors.New("no trino behind this test")
+}
+
+func (countingTrino) DescribeTable(context.Context, string, string, string) (*trinoclient.TableInfo, error) {
+	return nil, errors.New("no trino behind this test")
+}
+
+// TestScannedRows_ReadsARealTrinoQueryResult runs mcp-trino's own trino_query,
+// through the trino toolkit's scan stats middleware and a script session, into
+// scannedRows: the quota is charged what the engine read, not the one row the
+// count returned.
+func TestScannedRows_ReadsARealTrinoQueryResult(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v1"}, nil)
+	trinotools.NewToolkit(countingTrino{}, trinotools.Config{}).RegisterAll(server)
+	server.AddReceivingMiddleware(trinokit.ScanStatsMiddleware(func(_ context.Context, _, queryID string) (rows, bytes int64, err error) {
+		if queryID != "20260118_101500_00042_abcde" {
+			return 0, 0, errors.New("no such query")
+		}
+		return 48000, 3_100_000, nil
+	}, func(context.Context) bool { return true }))
+	caller, closeSession, err := Connect(t.Context(), server, "test")
+	require.NoError(t, err)
+	t.Cleanup(closeSession)
+
+	out, err := caller.CallTool(t.Context(), "trino_query", map[string]any{"sql": "SELECT count(*) FROM events"})
+	require.NoError(t, err)
+	assert.False(t, truncated(out))
+	rows, counted := scannedRows(out)
+	assert.True(t, counted)
+	assert.Equal(t, int64(48000), rows)
+}
+
+func quotaRun(t *testing.T, source string, caller Caller, quota Quota) (*Result, error) {
+	t.Helper()
+	return Run(context.Background(), Options{
+		Source: source, Name: "test", RunID: "dpx_1", FireTime: fireTime,
+		Caller: caller, Exporter: &recordingExporter{}, Quota: quota,
+	})
+}
+
+func TestRun_QuotaRefusesTheToolCallPastTheAllowance(t *testing.T) {
+	caller := &scanningCaller{}
+	result, err := quotaRun(t, `
+platform.query(sql="SELECT 1")
+platform.call("trino_query", {"sql": "SELECT 2"})
+`, caller, Quota{ToolCalls: 1})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrQuota)
+	assert.Contains(t, err.Error(), "the 1 tool calls its quota allows")
+	assert.Equal(t, 1, caller.calls, "the refused call is never made")
+	assert.Equal(t, 1, result.ToolCalls)
+}
+
+func TestRun_QuotaStopsAfterTheQueryThatCrossesTheScanAllowance(t *testing.T) {
+	result, err := quotaRun(t, `
+platform.query(sql="SELECT 1")
+platform.query(sql="SELECT 2")
+print("unreachable")
+`, &scanningCaller{}, Quota{RowsScanned: 1000})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrQuota)
+	assert.Contains(t, err.Error(), "scanned 1200 rows, over the 1000")
+	assert.Equal(t, int64(1200), result.RowsScanned)
+	assert.Empty(t, result.Log)
+}
+
+func TestRun_QuotaStopsOnAQueryWhoseScanWasNotCounted(t *testing.T) {
+	caller := &recordingCaller{}
+	result, err := quotaRun(t, `
+platform.query(sql="SELECT 1")
+print("unreachable")
+`, caller, Quota{RowsScanned: 1000})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrQuota)
+	assert.Contains(t, err.Error(), "could not be counted")
+	assert.Empty(t, result.Log)
+
+	// Without a scan quota the same query runs, charged nothing.
+	result, err = quotaRun(t, `platform.query(sql="SELECT 1")`, &recordingCaller{}, Quota{ToolCalls: 5})
+	require.NoError(t, err)
+	assert.Zero(t, result.RowsScanned)
+}
+
+func TestRun_QuotaStopsAfterTheOutputThatCrossesTheByteAllowance(t *testing.T) {
+	result, err := quotaRun(t, `
+platform.export(name="a", format="csv", rows=[{"n": 1}])
+platform.export(name="b", format="csv", rows=[{"n": 1}])
+`, &recordingCaller{}, Quota{OutputBytes: 600})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrQuota)
+	assert.Len(t, result.Exports, 2, "the crossing output landed and is on the record")
+	assert.Equal(t, int64(1024), result.OutputBytes)
+}
+
+func TestRun_QuotaWallTimeIsReportedAsTheQuota(t *testing.T) {
+	_, err := quotaRun(t, `platform.query(sql="SELECT 1")`, blockingCaller{}, Quota{WallTime: 50 * time.Millisecond})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrQuota)
+	assert.ErrorIs(t, err, ErrTimeout)
+}
+
+func TestRun_SpendIsMeasuredWithoutAQuota(t *testing.T) {
+	result, err := quotaRun(t, `platform.query(sql="SELECT 1")`, &scanningCaller{}, Quota{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ToolCalls)
+	assert.Equal(t, int64(600), result.RowsScanned)
+}
@@ -42,11 +42,12 @@ import (
 	"sync/atomic"
 	"time"
 
-	"go.starlark.net/lib/json"
 	"go.starlark.net/starlark"
 	"go.starlark.net/starlarkstruct"
 	"go.starlark.net/syntax"
 
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptlib"
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptsecret"
 	"github.com/txn2/mcp-data-platform/pkg/script"
 )
 
@@ -168,6 +169,23 @@ type Options struct {
 	MaxRows        int
 	MaxResultBytes int
 	MaxLogBytes    int
+
+	// Quota is what the run may spend of its script's and owner's allowance.
+	// Its wall time shortens Timeout when it is the tighter of the two.
+	Quota Quota
+
+	// Libraries reads the library versions the source loads. Nil refuses
+	// every load.
+	Libraries scriptlib.Resolver
+
+	// Secrets reveals the secrets granted to the version being run. Nil
+	// refuses platform.secret, which is what a draft wants.
+	Secrets scriptsecret.Vault
+
+	// OnLog, when set, is handed the log captured so far each time a print
+	// line is kept, so a caller can publish it while the run executes. It is
+	// called on the interpreter's thread and must not block.
+	OnLog func(log string, truncated bool)
 }
 
 // withDefaults fills unset limits with the draft defaults.
@@ -305,6 +323,15 @@ type Result struct {
 	Queries int `json:"queries"`
 	// Exports lists what every platform.export call did, in call order.
 	Exports []ExportRecord `json:"exports"`
+	// Checks lists every assertion the run made, passed and failed alike, in
+	// call order. A failed one does not make Run return an error: the script
+	// executed correctly, and what it found wrong was the data.
+	Checks []script.QualityCheck `json:"checks"`
+	// ToolCalls, RowsScanned and OutputBytes are what the run spent on the
+	// axes a quota bounds, measured whether or not it carried one.
+	ToolCalls   int   `json:"tool_calls"`
+	RowsScanned int64 `json:"rows_scanned"`
+	OutputBytes int64 `json:"output_bytes"`
 }
 
 // fileOptions is the dialect every managed script is parsed and resolved under.
@@ -324,7 +351,9 @@ type Result struct {
 // write `total = 0` and then accumulate into it inside a loop without wrapping
 // the whole script in a function, which is friction that buys no safety and no
 // determinism: neither switch has anything to do with either. `load` stays
-// file-local (and there is nothing to load).
+// file-local: what a library defines is bound in the file that loads it and
+// nowhere else. A library is executed under these same options
+// (scriptlib.Loader).
 var fileOptions = &syntax.FileOptions{
 	Set:               true,
 	While:             false,
@@ -343,6 +372,10 @@ var fileOptions = &syntax.FileOptions{
 // inputs fails the same way. Callers must not retry them.
 func Run(ctx context.Context, opts Options) (*Result, error) {
 	opts = opts.withDefaults()
+	quotaClock := opts.Quota.WallTime > 0 && opts.Quota.WallTime < opts.Timeout
+	if quotaClock {
+		opts.Timeout = opts.Quota.WallTime
+	}
 
 	runCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
 	defer cancel()
@@ -352,8 +385,13 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 	var overStep atomic.Bool
 
 	thread := &starlark.Thread{
-		Name:  opts.Name,
-		Print: func(_ *starlark.Thread, msg string) { log.write(msg) },
+		Name: opts.Name,
+		Print: func(_ *starlark.Thread, msg string) {
+			if log.write(msg) && opts.OnLog != nil {
+				opts.OnLog(log.string(), log.truncated)
+			}
+		},
+		Load: scriptlib.Loader(runCtx, opts.Libraries, fileOptions),
 	}
 	thread.SetMaxExecutionSteps(opts.MaxSteps)
 	// starlark-go signals both the step limit and an external stop through the
@@ -381,9 +419,17 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 		Duration:     time.Since(started),
 		Queries:      host.queries,
 		Exports:      host.exports,
+		Checks:       host.checks,
+		ToolCalls:    host.toolCalls,
+		RowsScanned:  host.rowsScanned,
+		OutputBytes:  host.outputBytes,
 	}
 	if execErr != nil {
-		return result, classifyExecError(runCtx, execErr, overStep.Load(), opts.MaxSteps)
+		err := classifyExecError(runCtx, execErr, overStep.Load(), opts.MaxSteps)
+		if host.quotaSpent || (quotaClock && errors.Is(err, ErrTimeout)) {
+			return result, fmt.Errorf("%w: %w", ErrQuota, err)
+		}
+		return result, err
 	}
 	return result, nil
 }
@@ -424,14 +470,15 @@ func classifyExecError(ctx context.Context, err error, overStep bool, maxSteps u
 }
 
 // PredeclaredNames are the globals the platform adds on top of the Starlark
-// universe, in the order the dialect contract introduces them.
+// universe: the platform module and the run, then the pure built-ins a library
+// shares (scriptlib.PredeclaredNames).
 //
 // It is the one definition of that set. predeclared() builds the bindings and
 // isPredeclaredName answers for them while validating, and a name present in
 // one and absent from the other is the defect that let the contract advertise
 // a built-in the environment did not have (#1414): validation would resolve a
 // name the run cannot bind, or refuse one it can.
-var PredeclaredNames = []string{"platform", "json", "date", "run", sumBuiltinName}
+var PredeclaredNames = append([]string{"platform", "run"}, scriptlib.PredeclaredNames...)
 
 // predeclared builds the global environment a script sees. Everything absent
 // from this dict is absent from the language: no imports, no filesystem, no
@@ -439,19 +486,25 @@ var PredeclaredNames = []string{"platform", "json", "date", "run", sumBuiltinNam
 //
 // Its keys are PredeclaredNames, which TestPredeclaredMatchesNames pins.
 func predeclared(host *hostState) starlark.StringDict {
-	return starlark.StringDict{
-		"platform": &starlarkstruct.Module{
-			Name: "platform",
-			Members: starlark.StringDict{
-				"query":        starlark.NewBuiltin(CapabilityQuery, host.query),
-				"export":       starlark.NewBuiltin(CapabilityExport, host.export),
-				"publish_data": starlark.NewBuiltin(CapabilityPublishData, host.publishData),
-				"call":         starlark.NewBuiltin(CapabilityCall, host.call),
-			},
+	env := scriptlib.Predeclared()
+	env["run"] = host.runValue()
+	env["platform"] = &starlarkstruct.Module{
+		Name: "platform",
+		Members: starlark.StringDict{
+			"query":        starlark.NewBuiltin(CapabilityQuery, host.query),
+			"export":       starlark.NewBuiltin(CapabilityExport, host.export),
+			"publish_data": starlark.NewBuiltin(CapabilityPublishData, host.publishData),
+			"call":         starlark.NewBuiltin(CapabilityCall, host.call),
+
+			"assert_row_count": starlark.NewBuiltin(CapabilityAssertRowCount, host.assertRowCount),
+			"assert_null_rate": starlark.NewBuiltin(CapabilityAssertNullRate, host.assertNullRate),
+			"assert_fresh":     starlark.NewBuiltin(CapabilityAssertFresh, host.assertFresh),
+			"assert_unique":    starlark.NewBuiltin(CapabilityAssertUnique, host.assertUnique),
+			"assert_empty":     starlark.NewBuiltin(CapabilityAssertEmpty, host.assertEmpty),
+
+			"secret": starlark.NewBuiltin(CapabilitySecret, host.secret),
+			"hmac":   starlark.NewBuiltin(CapabilityHMAC, host.hmac),
 		},
-		"json":         json.Module,
-		"date":         dateModule,
-		"run":          host.runValue(),
-		sumBuiltinName: sumBuiltin,
 	}
+	return env
 }
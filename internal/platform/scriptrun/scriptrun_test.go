@@ -624,3 +624,135 @@ func TestRun_LogTruncationKeepsRunesIntact(t *testing.T) {
 	require.True(t, result.LogTruncated)
 	assert.True(t, utf8.ValidString(result.Log), "a truncated log must still be valid UTF-8")
 }
+
+// fakeLibraries serves library sources by module string and counts the reads.
+type fakeLibraries struct {
+	sources map[string]string
+	reads   int
+}
+
+func (f *fakeLibraries) LibrarySource(_ context.Context, name string, version int) (string, error) {
+	f.reads++
+	src, ok := f.sources[fmt.Sprintf("%s@%d", name, version)]
+	if !ok {
+		return "", fmt.Errorf("no library named %s has a version %d", name, version)
+	}
+	return src, nil
+}
+
+// TestRun_LoadsALibraryAtItsPin proves load() binds what a pinned library
+// version defines, reads each module once per run however often it is loaded,
+// and lets a library load another.
+func TestRun_LoadsALibraryAtItsPin(t *testing.T) {
+	libs := &fakeLibraries{sources: map[string]string{
+		"fiscal-calendar@2": "load(\"fmt@1\", \"pad\")\ndef quarter(d):\n    return \"Q\" + pad((int(date.format(d, 'MM')) - 1) // 3 + 1)\n",
+		"fmt@1":             "def pad(n):\n    return str(n)\n",
+	}}
+	result, err := Run(context.Background(), Options{
+		Source: "load(\"fiscal-calendar@2\", \"quarter\")\nload(\"fmt@1\", \"pad\")\nprint(quarter(date.of(run.fire_time)), pad(7))\n",
+		RunID:  "r", FireTime: fireTime, Libraries: libs,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Q3 7\n", result.Log)
+	assert.Equal(t, 2, libs.reads, "each module is read once per run")
+}
+
+// TestRun_LibraryFailures proves a load that cannot be satisfied fails the run
+// with a message naming the module, and that a library has no platform even
+// when one slipped past validation.
+func TestRun_LibraryFailures(t *testing.T) {
+	libs := &fakeLibraries{sources: map[string]string{
+		"reach@1": "def rows():\n    return platform.query(connection='x', sql='SELECT 1')\n",
+	}}
+	for source, want := range map[string]string{
+		`load("missing@4", "f")`:  "no library named missing has a version 4",
+		`load("reach@1", "rows")`: "undefined: platform",
+		`load("unpinned", "f")`:   "pins no version",
+	} {
+		_, err := Run(context.Background(), Options{Source: source, RunID: "r", FireTime: fireTime, Libraries: libs})
+		require.Error(t, err, source)
+		assert.Contains(t, err.Error(), want, source)
+	}
+
+	_, err := Run(context.Background(), Options{Source: `load("fx@1", "f")`, RunID: "r", FireTime: fireTime})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no library store")
+}
+
+// fakeVault reveals the secrets in its map and refuses the rest.
+type fakeVault map[string]string
+
+func (f fakeVault) Reveal(_ context.Context, name string) (string, error) {
+	value, ok := f[name]
+	if !ok {
+		return "", fmt.Errorf("script secret not granted: %q is not granted to this script", name)
+	}
+	return value, nil
+}
+
+// TestRun_SecretsReachOnlyHeadersAndSignatures proves a handle prints as its
+// name, opens only as a platform.call header or platform.hmac's key, and is
+// refused everywhere else it could carry its value out of the run.
+func TestRun_SecretsReachOnlyHeadersAndSignatures(t *testing.T) {
+	caller := &recordingCaller{}
+	result, err := Run(context.Background(), Options{
+		Source: `
+key = platform.secret("partner-key")
+print(key)
+platform.call("api_invoke_endpoint", {"connection": "acme", "headers": {"X-Api-Key": key}})
+print(platform.hmac(key, "what do ya want for nothing?"))
+`,
+		RunID: "r", FireTime: fireTime, Caller: caller, Secrets: fakeVault{"partner-key": "Jefe"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "<secret \"partner-key\">\n"+
+		"5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843\n", result.Log)
+	require.Len(t, caller.calls, 1)
+	assert.Equal(t, map[string]any{"X-Api-Key": "Jefe"}, caller.calls[0].args["headers"])
+
+	for source, want := range map[string]string{
+		`platform.call("x", {"body": platform.secret("partner-key")})`:                           "cannot leave the script",
+		`platform.export(name="k", rows=[{"k": platform.secret("partner-key")}], format="json")`: "cannot leave the script",
+		`platform.secret("other")`:   "not granted to this script",
+		`platform.hmac("Jefe", "m")`: "key is platform.secret(name)",
+	} {
+		result, err := Run(context.Background(), Options{
+			Source: source, RunID: "r", FireTime: fireTime, Caller: caller,
+			Secrets: fakeVault{"partner-key": "Jefe"},
+		})
+		require.Error(t, err, source)
+		assert.Contains(t, err.Error(), want, source)
+		assert.NotContains(t, err.Error()+result.Log, "Jefe", source)
+	}
+
+	_, err = Run(context.Background(), Options{Source: `platform.secret("partner-key")`, RunID: "r", FireTime: fireTime})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "never to a draft")
+}
+
+// TestRun_SecretsOpenOnlyForToolsThatSendHeaders proves a handle under another
+// tool's "headers" is refused rather than opened: that tool does not send the
+// dict as request headers, so whatever it does with it — echo it, audit it —
+// would carry the plaintext out of the run.
+func TestRun_SecretsOpenOnlyForToolsThatSendHeaders(t *testing.T) {
+	for _, tool := range []string{"trino_query", "datahub_search", "memory_manage"} {
+		caller := &recordingCaller{}
+		_, err := Run(context.Background(), Options{
+			Source: `platform.call("` + tool + `", {"headers": {"X-Api-Key": platform.secret("partner-key")}})`,
+			RunID:  "r", FireTime: fireTime, Caller: caller, Secrets: fakeVault{"partner-key": "Jefe"},
+		})
+		require.Error(t, err, tool)
+		assert.Contains(t, err.Error(), "cannot leave the script", tool)
+		assert.NotContains(t, err.Error(), "Jefe", tool)
+		assert.Empty(t, caller.calls, "%s is never called with the secret", tool)
+	}
+
+	caller := &recordingCaller{}
+	_, err := Run(context.Background(), Options{
+		Source: `platform.call("api_export", {"connection": "acme", "headers": {"X-Api-Key": platform.secret("partner-key")}})`,
+		RunID:  "r", FireTime: fireTime, Caller: caller, Secrets: fakeVault{"partner-key": "Jefe"},
+	})
+	require.NoError(t, err)
+	require.Len(t, caller.calls, 1)
+	assert.Equal(t, map[string]any{"X-Api-Key": "Jefe"}, caller.calls[0].args["headers"])
+}
@@ -12,6 +12,7 @@ import (
 	"go.starlark.net/starlark"
 	"go.starlark.net/syntax"
 
+	"github.com/txn2/mcp-data-platform/internal/platform/scriptlib"
 	"github.com/txn2/mcp-data-platform/pkg/script"
 )
 
@@ -69,6 +70,10 @@ type Report struct {
 	DynamicConnections    bool `json:"dynamic_connections"`
 	DynamicDestinations   bool `json:"dynamic_destinations"`
 	DynamicRefreshTargets bool `json:"dynamic_refresh_targets"`
+	// Loads is the libraries the source loads, each at the version it pins, in
+	// source order. It is what a save records as the script's edges in the
+	// library graph.
+	Loads []script.Dependency `json:"loads"`
 	// DynamicTools is true when a platform.call computes the tool it invokes,
 	// so the tool list is known to be incomplete. A call that computes its
 	// ARGUMENT SET leaves the tool list intact and sets DynamicConnections
@@ -144,7 +149,7 @@ func hasErrors(findings []Finding) bool {
 func Validate(source string) Report {
 	report := Report{
 		Capabilities: []string{}, Connections: []string{}, Tools: []string{},
-		Destinations: []string{}, RefreshTargets: []string{},
+		Destinations: []string{}, RefreshTargets: []string{}, Loads: []script.Dependency{},
 	}
 	findings := scanSource(source)
 
@@ -158,6 +163,7 @@ func Validate(source string) Report {
 
 	found := inspect(file)
 	findings = append(findings, found.findings...)
+	report.Loads, findings = collectLoads(file, findings)
 	report.Capabilities, report.Connections = sortedNames(found.capabilities), sortedNames(found.connections)
 	report.Tools = sortedNames(found.tools)
 	report.Destinations = sortedNames(found.destinations)
@@ -177,6 +183,85 @@ func Validate(source string) Report {
 	return report
 }
 
+// ValidateScript validates a record's source as what the record is — a script,
+// or a library another script loads — and records on it the libraries the
+// source loads. Every surface that saves source calls it, so the dependency
+// graph a save writes is always the one the saved code declares.
+//
+// A library is held to one more rule than a script: it names neither platform
+// nor run, which its environment does not have. Refusing that at the save
+// rather than at the first load means a library that cannot be loaded is never
+// a version anyone can pin.
+func ValidateScript(sc *script.Script) Report {
+	report := Validate(sc.Source)
+	if report.OK && sc.Library {
+		report = withLibraryCheck(report, sc.Source)
+	}
+	if report.OK {
+		sc.Loads = report.Loads
+	}
+	return report
+}
+
+// withLibraryCheck resolves a source that already validates against the
+// library environment, folding in a finding for each name only a script has.
+func withLibraryCheck(report Report, source string) Report {
+	file, err := fileOptions.Parse("library", source, 0)
+	if err != nil {
+		return report
+	}
+	if _, err := starlark.FileProgram(file, scriptlib.IsPredeclared); err != nil {
+		found := resolveFindings(err)
+		for i := range found {
+			found[i].Hint = libraryHint
+		}
+		report.Findings = append(slices.Clone(report.Findings), found...)
+		sortFindings(report.Findings)
+		report.OK = false
+	}
+	return report
+}
+
+// libraryHint is the correction for a library that reached for the platform.
+var libraryHint = fmt.Sprintf(
+	"A library has only %s and the Starlark built-ins: it computes over what its caller passes in. "+
+		"Query in the script that loads it, and hand the rows to the library's functions.",
+	quotedList(scriptlib.PredeclaredNames))
+
+// collectLoads reads the load statements of a parsed file: each library once,
+// at the version it pins. A load whose module string cannot be read, or
+// a second pin of a library already loaded at another version, is a finding.
+func collectLoads(file *syntax.File, findings []Finding) ([]script.Dependency, []Finding) {
+	loads := []script.Dependency{}
+	pinned := map[string]int{}
+	for _, stmt := range file.Stmts {
+		load, ok := stmt.(*syntax.LoadStmt)
+		if !ok {
+			continue
+		}
+		line := int(load.Load.Line)
+		dep, err := scriptlib.ParseModule(load.ModuleName())
+		switch v, seen := pinned[dep.Name]; {
+		case err != nil:
+			findings = append(findings, Finding{Severity: SeverityError, Line: line, Message: err.Error(), Hint: loadHint})
+		case seen && v != dep.Version:
+			findings = append(findings, Finding{
+				Severity: SeverityError, Line: line,
+				Message: fmt.Sprintf("%s is loaded at version %d and at version %d", dep.Name, v, dep.Version),
+				Hint:    "A script loads a library at one version. Move every load to the same pin.",
+			})
+		case !seen:
+			pinned[dep.Name] = dep.Version
+			loads = append(loads, dep)
+		}
+	}
+	return loads, findings
+}
+
+// loadHint is the correction for a load whose module string is not a
+// library pin.
+const loadHint = `A load names a library script and the version it pins: load("fiscal-calendar@3", "fiscal_quarter").`
+
 // isPredeclaredName reports whether a name is part of the script environment.
 // It answers from PredeclaredNames, which is also what predeclared() binds, so
 // a name resolves here exactly when a run can call it.
@@ -220,7 +305,7 @@ func resolveFindings(err error) []Finding {
 // out, so a global the platform adds or drops cannot leave the hint naming a
 // set the resolver disagrees with.
 var undefinedNameHint = fmt.Sprintf(
-	"Only %s are available, plus the Starlark built-ins. There are no imports and no standard library beyond that.",
+	"Only %s are available, plus the Starlark built-ins and what a load() binds from a library script. There are no imports and no standard library beyond that.",
 	quotedList(PredeclaredNames))
 
 // quotedList renders names as a backticked English list ("`a`, `b`, and `c`").
@@ -251,7 +336,7 @@ var dialectCorrections = []struct {
 	{"does not support while loops", "Unbounded loops are disabled so a script's cost is predictable from its source. Iterate over a list with `for`, or express the repetition in SQL."},
 	{"called recursively", "Recursion is disabled for the same reason as `while`. Flatten the work into a loop over a list, or do it in SQL."},
 	{"undefined: ", undefinedNameHint},
-	{`got import\b`, "There is no `import`. Query results come from `platform.query`; JSON is the predeclared `json` module; dates are the predeclared `date` module."},
+	{`got import\b`, "There is no `import`. Query results come from `platform.query`; JSON is the predeclared `json` module; dates are the predeclared `date` module; shared helpers come from a library script, loaded by name and pinned version with load(\"fiscal-calendar@3\", \"fiscal_quarter\")."},
 	{`got (?:try|except|finally)\b`, "There is no `try`/`except`. An error fails the run by design, so the failure is visible in the run record instead of being swallowed."},
 	{`got class\b`, "There are no classes. Use dicts for structured values and functions for behavior."},
 	{`got with\b`, "There is no `with`. Nothing a script touches needs to be opened or closed."},
@@ -476,17 +561,17 @@ func (ins *inspection) visit(call *syntax.CallExpr, dot *syntax.DotExpr) {
 		ins.unreadable(name)
 		return
 	}
-	switch name {
-	case CapabilityQuery:
+	switch {
+	case name == CapabilityQuery || slices.Contains(assertions, name):
 		collectKeyword(call, "connection", ins.connections, &ins.dynamicConnections)
-	case CapabilityExport:
+	case name == CapabilityExport:
 		ins.visitExport(call, int(dot.NamePos.Line))
-	case CapabilityPublishData:
+	case name == CapabilityPublishData:
 		// A refresh writes to the portal and nowhere else, so the call
 		// contributes the portal to the destination list a reader sees.
 		ins.destinations[script.DestinationPortal] = true
 		collectFirstOrKeyword(call, "name", ins.refreshTargets, &ins.dynamicRefreshTargets)
-	case CapabilityCall:
+	case name == CapabilityCall:
 		ins.visitCall(call)
 	}
 }
@@ -514,17 +599,17 @@ func hasStarArg(call *syntax.CallExpr) bool {
 // cannot read, marking every list that member would otherwise have contributed
 // to as incomplete rather than reporting a shorter list as a complete one.
 func (ins *inspection) unreadable(name string) {
-	switch name {
-	case CapabilityQuery:
+	switch {
+	case name == CapabilityQuery || slices.Contains(assertions, name):
 		ins.dynamicConnections = true
-	case CapabilityExport:
+	case name == CapabilityExport:
 		ins.dynamicDestinations = true
-	case CapabilityPublishData:
+	case name == CapabilityPublishData:
 		// A refresh writes to the portal whatever its arguments say, so the
 		// destination is still a fact; only the target name is unreadable.
 		ins.destinations[script.DestinationPortal] = true
 		ins.dynamicRefreshTargets = true
-	case CapabilityCall:
+	case name == CapabilityCall:
 		ins.dynamicTools = true
 		ins.dynamicConnections = true
 	}
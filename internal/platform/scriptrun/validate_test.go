@@ -367,6 +367,19 @@ platform.query(connection=conn, sql="SELECT 1")
 	assert.Empty(t, report.Connections)
 }
 
+// TestValidate_AssertionsReportTheirConnections pins that a check is as much a
+// use of a connection as the query it issues.
+func TestValidate_AssertionsReportTheirConnections(t *testing.T) {
+	report := Validate(`
+platform.assert_row_count("orders", min=1, connection="warehouse")
+platform.assert_empty("SELECT 1", connection="lake")
+platform.assert_unique("orders", "id")
+`)
+	assert.True(t, report.OK, report.Findings)
+	assert.Equal(t, []string{"lake", "warehouse"}, report.Connections)
+	assert.ElementsMatch(t, []string{CapabilityAssertRowCount, CapabilityAssertEmpty, CapabilityAssertUnique}, report.Capabilities)
+}
+
 func TestValidate_FindingsAreOrderedByLine(t *testing.T) {
 	report := Validate("x = 1\n\n\npassword = \"hunter2000\"\n\nimport os\n")
 	require.GreaterOrEqual(t, len(report.Findings), 2)
@@ -469,3 +482,51 @@ func TestValidate_NoRefreshMeansAnEmptyList(t *testing.T) {
 	assert.Empty(t, report.RefreshTargets)
 	assert.False(t, report.DynamicRefreshTargets)
 }
+
+// TestValidateScript_RecordsTheLibrariesASaveLoads proves the edges a save
+// records are read from the source: each library once, at its pin.
+func TestValidateScript_RecordsTheLibrariesASaveLoads(t *testing.T) {
+	sc := &script.Script{Source: `load("fiscal-calendar@3", "fiscal_quarter")
+load("fiscal-calendar@3", "fiscal_year")
+load("fx@1", fx = "convert")
+print(fiscal_quarter("2026-08-13"), fx)
+`}
+	report := ValidateScript(sc)
+	require.True(t, report.OK, "%+v", report.Findings)
+	assert.Equal(t, []script.Dependency{{Name: "fiscal-calendar", Version: 3}, {Name: "fx", Version: 1}}, sc.Loads)
+}
+
+// TestValidateScript_RefusesALoadWithoutOnePin proves a load that follows a
+// library's latest version, or pins one library twice, never reaches a save.
+func TestValidateScript_RefusesALoadWithoutOnePin(t *testing.T) {
+	for source, want := range map[string]string{
+		`load("fiscal-calendar", "q")`:                   "pins no version",
+		`load("fiscal-calendar@latest", "q")`:            "whole number",
+		`load("Fiscal@2", "q")`:                          "lowercase",
+		"load(\"fx@1\", \"a\")\nload(\"fx@2\", \"b\")\n": "at version 1 and at version 2",
+		"load(\"fiscal-calendar@0\", \"q\")\nprint(q)\n": "whole number",
+	} {
+		sc := &script.Script{Source: source}
+		report := ValidateScript(sc)
+		require.False(t, report.OK, source)
+		assert.Contains(t, report.Findings[0].Message, want, source)
+		assert.Nil(t, sc.Loads, "a refused source records no edges")
+	}
+}
+
+// TestValidateScript_ALibraryHasNoPlatform proves a library that reaches for
+// the platform or the run is refused at the save, with the correction, rather
+// than becoming a version that fails whoever loads it.
+func TestValidateScript_ALibraryHasNoPlatform(t *testing.T) {
+	lib := &script.Script{Library: true, Source: "def rows():\n    return platform.query(connection='x', sql='SELECT 1')\n"}
+	report := ValidateScript(lib)
+	require.False(t, report.OK)
+	assert.Contains(t, report.Findings[0].Message, "undefined: platform")
+	assert.Contains(t, report.Findings[0].Hint, "computes over what its caller passes in")
+
+	lib.Source = "def quarter(d):\n    return (int(date.format(d, 'MM')) - 1) // 3 + 1\n"
+	assert.True(t, ValidateScript(lib).OK)
+
+	// The same source is an ordinary script when the record is not a library.
+	assert.True(t, ValidateScript(&script.Script{Source: "x = run.params\n"}).OK)
+}
@@ -0,0 +1,185 @@
+// Package scriptsecret keeps the named secrets an operator grants to managed
+// scripts, and the opaque handle a run holds one through.
+//
+// A script names no endpoint and carries no credential: it reaches the outside
+// world through connections the platform configures and authorizes. That
+// leaves out the cases a connection does not cover — signing a webhook payload,
+// presenting a partner's API key as a header — and the answer is not to let a
+// credential into the source. An operator stores the value once, encrypted at
+// rest with the platform's field key, and grants it to one script. A run of
+// that script asks for it by name and receives a Handle: a value the host
+// bindings that sign and send can consume, and that prints as its name, will
+// not convert to anything that leaves the run, and will not concatenate.
+//
+// A grant is to one VERSION of a script. Whoever may edit a script decides
+// what its code does with a secret, so an edit ends the grant and the operator
+// grants the new version knowing what it sends where.
+package scriptsecret
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"regexp"
+	"time"
+
+	"go.starlark.net/starlark"
+)
+
+// MaxValueBytes bounds one secret. A key, a token, a PEM block fit; a secret
+// larger than this is a file, and a file is not what this holds.
+const MaxValueBytes = 8 << 10
+
+// HeadersArg is the platform.call argument whose values may be handles: the
+// request headers api_invoke_endpoint and api_export send. It is the one place
+// a revealed value goes into a tool call, and the audit log redacts the values
+// under it.
+const HeadersArg = "headers"
+
+var (
+	// ErrNotFound reports a secret, or a grant, that does not exist.
+	ErrNotFound = errors.New("script secret not found")
+	// ErrNoScript reports a grant to a script that does not exist.
+	ErrNoScript = errors.New("no script has that id")
+	// ErrNotGranted reports a run asking for a secret its script version was
+	// not granted.
+	ErrNotGranted = errors.New("script secret not granted")
+	// ErrUnencrypted refuses a value the store would keep in plain text,
+	// which is what it would do on a deployment without an encryption key.
+	ErrUnencrypted = errors.New("script secrets are stored encrypted; configure ENCRYPTION_KEY")
+)
+
+// namePattern is what a secret is called: a lowercase slug, so a name reads
+// the same in the source, the grant, and the audit log.
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,63}$`)
+
+// ValidateName checks a secret name.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("secret name %q must be lowercase letters, digits, '-' or '_', starting with a letter, at most 64 characters", name)
+	}
+	return nil
+}
+
+// Secret is one stored secret as an operator reads it. The value is never
+// part of it: nothing that lists or describes a secret returns what it is.
+type Secret struct {
+	Name        string     `json:"name" example:"partner-api-key"`
+	Description string     `json:"description,omitempty" example:"Signing key for the Acme webhook"`
+	Grants      []Grant    `json:"grants"`
+	UpdatedBy   string     `json:"updated_by,omitempty" example:"admin@example.com"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// Grant is one script version allowed to read a secret.
+type Grant struct {
+	ScriptID   string `json:"script_id"`
+	ScriptName string `json:"script_name,omitempty" example:"daily-sales"`
+	// Version is the script version the grant covers. A run of any other
+	// version is refused, and Current reports whether the script still runs
+	// this one.
+	Version   int        `json:"version" example:"3"`
+	Current   bool       `json:"current"`
+	GrantedBy string     `json:"granted_by,omitempty" example:"admin@example.com"`
+	GrantedAt *time.Time `json:"granted_at,omitempty"`
+}
+
+// Vault reveals the secrets granted to the one script version a run executes.
+type Vault interface {
+	Reveal(ctx context.Context, name string) (string, error)
+}
+
+// Handle is a secret as a script holds it. Its String is its name, so printing
+// it, formatting it, or writing it into a document shows which secret was
+// meant and never what it is.
+type Handle struct {
+	name  string
+	value string
+}
+
+// NewHandle wraps a revealed value.
+func NewHandle(name, value string) *Handle { return &Handle{name: name, value: value} }
+
+var _ starlark.Value = (*Handle)(nil)
+
+// String renders the handle without its value.
+func (h *Handle) String() string { return fmt.Sprintf("<secret %q>", h.name) }
+
+// Type is the handle's Starlark type name.
+func (*Handle) Type() string { return "secret" }
+
+// Freeze is a no-op: a handle is immutable.
+func (*Handle) Freeze() {}
+
+// Truth is always true: a handle exists only for a secret that was revealed.
+func (*Handle) Truth() starlark.Bool { return starlark.True }
+
+// Hash refuses, so a handle cannot key a dict whose keys are later exported.
+func (*Handle) Hash() (uint32, error) { return 0, errors.New("unhashable type: secret") }
+
+// RevealHeaders returns args with every handle in its headers dict replaced by
+// the value it holds, for the one tool call about to be made. args itself is
+// left as the script sees it. A handle anywhere else stays a handle, and the
+// conversion of the argument set refuses it. Which tools' headers are opened
+// is the caller's decision: only one that sends them as HTTP headers.
+func RevealHeaders(args *starlark.Dict) (*starlark.Dict, error) {
+	raw, found, err := args.Get(starlark.String(HeadersArg))
+	if err != nil || !found {
+		return args, err
+	}
+	headers, ok := raw.(*starlark.Dict)
+	if !ok {
+		return args, nil
+	}
+	revealed := starlark.NewDict(headers.Len())
+	for _, item := range headers.Items() {
+		v := item[1]
+		if h, ok := v.(*Handle); ok {
+			v = starlark.String(h.value)
+		}
+		if err := revealed.SetKey(item[0], v); err != nil {
+			return nil, err
+		}
+	}
+	out := starlark.NewDict(args.Len())
+	for _, item := range args.Items() {
+		v := item[1]
+		if k, ok := item[0].(starlark.String); ok && string(k) == HeadersArg {
+			v = revealed
+		}
+		if err := out.SetKey(item[0], v); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// hmacHashes are the digests platform.hmac signs with.
+var hmacHashes = map[string]func() hash.Hash{"sha256": sha256.New, "sha512": sha512.New}
+
+// HMAC signs message with the secret a handle holds and encodes the MAC as hex
+// or base64, the two forms webhook receivers compare against. The MAC reveals
+// nothing of the key, which is why it may leave the run.
+func HMAC(key *Handle, message, algorithm, encoding string) (string, error) {
+	newHash, ok := hmacHashes[algorithm]
+	if !ok {
+		return "", fmt.Errorf("algorithm %q is not one of sha256, sha512", algorithm)
+	}
+	mac := hmac.New(newHash, []byte(key.value))
+	_, _ = mac.Write([]byte(message))
+	sum := mac.Sum(nil)
+	switch encoding {
+	case "hex":
+		return hex.EncodeToString(sum), nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString(sum), nil
+	default:
+		return "", fmt.Errorf("encoding %q is not one of hex, base64", encoding)
+	}
+}
@@ -0,0 +1,85 @@
+package scriptsecret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+)
+
+// run executes source with one handle bound as s, the way a script holds one.
+func run(t *testing.T, source string) (starlark.StringDict, error) {
+	t.Helper()
+	thread := &starlark.Thread{Print: func(*starlark.Thread, string) {}}
+	return starlark.ExecFile(thread, "t", source, starlark.StringDict{
+		"s": NewHandle("partner-key", "hunter2"), "json": json.Module,
+	})
+}
+
+// TestHandle_NeverShowsItsValue covers every way Starlark turns a value into
+// text, and the operations that would splice it into some.
+func TestHandle_NeverShowsItsValue(t *testing.T) {
+	globals, err := run(t, "a = str(s)\nb = \"%s\" % s\nc = \"{}\".format(s)\nd = repr([s])\n")
+	require.NoError(t, err)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		assert.NotContains(t, globals[name].String(), "hunter2", name)
+		assert.Contains(t, globals[name].String(), `<secret \"partner-key\">`, name)
+	}
+
+	for source, want := range map[string]string{
+		"x = s + \"!\"\n":      "unknown binary op",
+		"x = json.encode(s)\n": "cannot encode secret as JSON",
+		"x = {s: 1}\n":         "unhashable",
+	} {
+		_, err := run(t, source)
+		require.Error(t, err, source)
+		assert.Contains(t, err.Error(), want, source)
+		assert.NotContains(t, err.Error(), "hunter2", source)
+	}
+}
+
+// TestRevealHeaders opens a handle only under headers, and leaves the dict the
+// script holds as it was.
+func TestRevealHeaders(t *testing.T) {
+	globals, err := run(t, "args = {\"headers\": {\"X-Api-Key\": s, \"Accept\": \"text/csv\"}, \"body\": s}\n")
+	require.NoError(t, err)
+	args, ok := globals["args"].(*starlark.Dict)
+	require.True(t, ok)
+
+	out, err := RevealHeaders(args)
+	require.NoError(t, err)
+	headers, _, _ := out.Get(starlark.String("headers"))
+	key, _, _ := headers.(*starlark.Dict).Get(starlark.String("X-Api-Key"))
+	assert.Equal(t, starlark.String("hunter2"), key)
+	body, _, _ := out.Get(starlark.String("body"))
+	assert.IsType(t, &Handle{}, body, "a handle outside headers stays a handle")
+
+	original, _, _ := args.Get(starlark.String("headers"))
+	still, _, _ := original.(*starlark.Dict).Get(starlark.String("X-Api-Key"))
+	assert.IsType(t, &Handle{}, still, "the script's own dict is untouched")
+}
+
+// TestHMAC checks RFC 4231 test case 2 in both encodings, and the refusals.
+func TestHMAC(t *testing.T) {
+	key := NewHandle("k", "Jefe")
+	mac, err := HMAC(key, "what do ya want for nothing?", "sha256", "hex")
+	require.NoError(t, err)
+	assert.Equal(t, "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843", mac)
+	mac, err = HMAC(key, "what do ya want for nothing?", "sha256", "base64")
+	require.NoError(t, err)
+	assert.Equal(t, "W9zBRr9gdU5qBCQmCJV1x1oAPwidJzmDnexYuWTsOEM=", mac)
+
+	_, err = HMAC(key, "m", "md5", "hex")
+	assert.ErrorContains(t, err, "not one of sha256, sha512")
+	_, err = HMAC(key, "m", "sha256", "raw")
+	assert.ErrorContains(t, err, "not one of hex, base64")
+}
+
+func TestValidateName(t *testing.T) {
+	require.NoError(t, ValidateName("partner-api_key2"))
+	for _, name := range []string{"", "Partner", "2fa", "a b", "../x", string(make([]byte, 65))} {
+		assert.Error(t, ValidateName(name), name)
+	}
+}
@@ -0,0 +1,232 @@
+package scriptsecret
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// pqForeignKeyViolation is the SQLSTATE of a grant naming a secret that does
+// not exist.
+const pqForeignKeyViolation = "23503"
+
+// Encryptor seals a secret at rest. *fieldcrypt.RestFieldEncryptor satisfies
+// it, so a script secret is sealed with the same key and format as a
+// connection's credentials.
+type Encryptor interface {
+	Encrypt(plaintext string) (string, error)
+	Decrypt(ciphertext string) (string, error)
+}
+
+// Store keeps script secrets and their grants in PostgreSQL.
+type Store struct {
+	db  *sql.DB
+	enc Encryptor
+}
+
+// New creates a secret store over db, sealing values with enc.
+func New(db *sql.DB, enc Encryptor) *Store {
+	return &Store{db: db, enc: enc}
+}
+
+// List returns every secret with the script versions granted it, by name.
+func (s *Store) List(ctx context.Context) ([]Secret, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT name, description, updated_by, updated_at
+		  FROM script_secrets
+		 ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("reading script secrets: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	out := []Secret{}
+	index := map[string]int{}
+	for rows.Next() {
+		sec := Secret{Grants: []Grant{}}
+		var updatedAt time.Time
+		if err := rows.Scan(&sec.Name, &sec.Description, &sec.UpdatedBy, &updatedAt); err != nil {
+			return nil, fmt.Errorf("scanning a script secret: %w", err)
+		}
+		sec.UpdatedAt = &updatedAt
+		index[sec.Name] = len(out)
+		out = append(out, sec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading script secrets: %w", err)
+	}
+	return out, s.attachGrants(ctx, out, index)
+}
+
+// attachGrants reads every grant onto the secrets it belongs to.
+func (s *Store) attachGrants(ctx context.Context, secrets []Secret, index map[string]int) error {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT g.secret_name, g.script_id::text, sc.name, g.script_version,
+		       g.script_version = sc.version, g.granted_by, g.granted_at
+		  FROM script_secret_grants g JOIN scripts sc ON sc.id = g.script_id
+		 ORDER BY g.secret_name, sc.name`)
+	if err != nil {
+		return fmt.Errorf("reading script secret grants: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var name string
+		var g Grant
+		var grantedAt time.Time
+		if err := rows.Scan(&name, &g.ScriptID, &g.ScriptName, &g.Version,
+			&g.Current, &g.GrantedBy, &grantedAt); err != nil {
+			return fmt.Errorf("scanning a script secret grant: %w", err)
+		}
+		g.GrantedAt = &grantedAt
+		if i, ok := index[name]; ok {
+			secrets[i].Grants = append(secrets[i].Grants, g)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("reading script secret grants: %w", err)
+	}
+	return nil
+}
+
+// Set stores a secret's value, creating it or rotating it in place. A
+// rotation keeps its grants: what changed is the credential, not which code
+// may present it.
+func (s *Store) Set(ctx context.Context, name, value, description, actor string) (*Secret, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	if value == "" || len(value) > MaxValueBytes {
+		return nil, fmt.Errorf("a secret value is 1 to %d bytes", MaxValueBytes)
+	}
+	sealed, err := s.enc.Encrypt(value)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting script secret: %w", err)
+	}
+	// An encryptor with no key hands the plaintext back. A secret is the one
+	// value whose whole point is not being readable in the database.
+	if sealed == value {
+		return nil, ErrUnencrypted
+	}
+	var updatedAt time.Time
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO script_secrets (name, value, description, created_by, updated_by)
+		VALUES ($1, $2, $3, $4, $4)
+		ON CONFLICT (name) DO UPDATE
+		   SET value       = EXCLUDED.value,
+		       description = EXCLUDED.description,
+		       updated_by  = EXCLUDED.updated_by,
+		       updated_at  = NOW()
+		RETURNING updated_at`, name, sealed, description, actor).Scan(&updatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("set script secret: %w", err)
+	}
+	return &Secret{
+		Name: name, Description: description, Grants: []Grant{},
+		UpdatedBy: actor, UpdatedAt: &updatedAt,
+	}, nil
+}
+
+// Delete removes a secret and every grant of it, or reports ErrNotFound.
+func (s *Store) Delete(ctx context.Context, name string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM script_secrets WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("delete script secret: %w", err)
+	}
+	return affectedOne(res, "checking a script secret delete")
+}
+
+// Grant allows the script's CURRENT version to read a secret, replacing any
+// earlier grant of it to the same script.
+func (s *Store) Grant(ctx context.Context, name, scriptID, actor string) (*Grant, error) {
+	g := Grant{ScriptID: scriptID, Current: true, GrantedBy: actor}
+	var grantedAt time.Time
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO script_secret_grants (secret_name, script_id, script_version, granted_by)
+		SELECT $1, id, version, $3 FROM scripts WHERE id::text = $2
+		ON CONFLICT (secret_name, script_id) DO UPDATE
+		   SET script_version = EXCLUDED.script_version,
+		       granted_by     = EXCLUDED.granted_by,
+		       granted_at     = NOW()
+		RETURNING script_version, granted_at,
+		          (SELECT name FROM scripts WHERE id = script_id)`,
+		name, scriptID, actor).Scan(&g.Version, &grantedAt, &g.ScriptName)
+	var pqErr *pq.Error
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrNoScript
+	case errors.As(err, &pqErr) && pqErr.Code == pqForeignKeyViolation:
+		return nil, ErrNotFound
+	case err != nil:
+		return nil, fmt.Errorf("grant script secret: %w", err)
+	}
+	g.GrantedAt = &grantedAt
+	return &g, nil
+}
+
+// Revoke ends a script's grant of a secret, or reports ErrNotFound.
+func (s *Store) Revoke(ctx context.Context, name, scriptID string) error {
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM script_secret_grants WHERE secret_name = $1 AND script_id::text = $2`,
+		name, scriptID)
+	if err != nil {
+		return fmt.Errorf("revoke script secret: %w", err)
+	}
+	return affectedOne(res, "checking a script secret revoke")
+}
+
+// affectedOne turns a statement that touched no row into ErrNotFound.
+func affectedOne(res sql.Result, what string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", what, err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Vault is the view of the store one run holds: the secrets granted to the
+// version it executes, and no others.
+func (s *Store) Vault(scriptID string, version int) Vault {
+	return &vault{store: s, scriptID: scriptID, version: version}
+}
+
+// vault reveals secrets for one script version.
+type vault struct {
+	store    *Store
+	scriptID string
+	version  int
+}
+
+// Reveal reads and opens one secret, refusing one that does not exist, one
+// this script was never granted, and one granted to another of its versions.
+func (v *vault) Reveal(ctx context.Context, name string) (string, error) {
+	var sealed string
+	var granted sql.NullInt64
+	err := v.store.db.QueryRowContext(ctx, `
+		SELECT s.value, g.script_version
+		  FROM script_secrets s
+		  LEFT JOIN script_secret_grants g
+		         ON g.secret_name = s.name AND g.script_id::text = $2
+		 WHERE s.name = $1`, name, v.scriptID).Scan(&sealed, &granted)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return "", fmt.Errorf("%w: no secret is named %q", ErrNotFound, name)
+	case err != nil:
+		return "", fmt.Errorf("reading script secret %q: %w", name, err)
+	case !granted.Valid:
+		return "", fmt.Errorf("%w: %q is not granted to this script; an operator grants it", ErrNotGranted, name)
+	case int(granted.Int64) != v.version:
+		return "", fmt.Errorf("%w: %q is granted to version %d of this script and this is version %d; "+
+			"an operator grants it again after an edit", ErrNotGranted, name, granted.Int64, v.version)
+	}
+	value, err := v.store.enc.Decrypt(sealed)
+	if err != nil {
+		return "", fmt.Errorf("decrypting script secret %q: %w", name, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,156 @@
+package scriptsecret
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var rowTime = time.Date(2026, 3, 1, 7, 0, 0, 0, time.UTC)
+
+// sealer is an encryptor whose ciphertext is recognizable, and plain is one
+// with no key, which hands the plaintext back.
+type (
+	sealer struct{}
+	plain  struct{}
+)
+
+func (sealer) Encrypt(p string) (string, error) { return "enc:" + p, nil }
+func (sealer) Decrypt(c string) (string, error) { return strings.TrimPrefix(c, "enc:"), nil }
+func (plain) Encrypt(p string) (string, error)  { return p, nil }
+func (plain) Decrypt(c string) (string, error)  { return c, nil }
+
+func newMock(t *testing.T, enc Encryptor) (*Store, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return New(db, enc), mock
+}
+
+func TestSet_StoresOnlyTheSealedValue(t *testing.T) {
+	s, mock := newMock(t, sealer{})
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO script_secrets")).
+		WithArgs("partner-key", "enc:hunter2", "Acme webhook", "admin@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(rowTime))
+
+	sec, err := s.Set(context.Background(), "partner-key", "hunter2", "Acme webhook", "admin@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "partner-key", sec.Name)
+	assert.Empty(t, sec.Grants)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSet_Refusals(t *testing.T) {
+	s, _ := newMock(t, plain{})
+	_, err := s.Set(context.Background(), "partner-key", "hunter2", "", "admin@example.com")
+	require.ErrorIs(t, err, ErrUnencrypted, "a deployment without a key must not store a secret in plain text")
+
+	s, _ = newMock(t, sealer{})
+	_, err = s.Set(context.Background(), "Partner Key", "hunter2", "", "")
+	assert.ErrorContains(t, err, "lowercase")
+	_, err = s.Set(context.Background(), "k", "", "", "")
+	assert.ErrorContains(t, err, "1 to 8192 bytes")
+	_, err = s.Set(context.Background(), "k", strings.Repeat("x", MaxValueBytes+1), "", "")
+	assert.ErrorContains(t, err, "1 to 8192 bytes")
+}
+
+func TestList_AttachesGrantsToTheirSecrets(t *testing.T) {
+	s, mock := newMock(t, sealer{})
+	mock.ExpectQuery(regexp.QuoteMeta("FROM script_secrets")).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "description", "updated_by", "updated_at"}).
+			AddRow("a-key", "", "admin@example.com", rowTime).
+			AddRow("b-key", "B", "admin@example.com", rowTime))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM script_secret_grants g JOIN scripts sc")).
+		WillReturnRows(sqlmock.NewRows([]string{"secret_name", "script_id", "name", "script_version",
+			"current", "granted_by", "granted_at"}).
+			AddRow("b-key", "s1", "daily-sales", 3, false, "admin@example.com", rowTime))
+
+	secrets, err := s.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, secrets, 2)
+	assert.Empty(t, secrets[0].Grants)
+	require.Len(t, secrets[1].Grants, 1)
+	assert.Equal(t, Grant{
+		ScriptID: "s1", ScriptName: "daily-sales", Version: 3, Current: false,
+		GrantedBy: "admin@example.com", GrantedAt: &rowTime,
+	}, secrets[1].Grants[0])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGrant_PinsTheCurrentVersion(t *testing.T) {
+	s, mock := newMock(t, sealer{})
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT $1, id, version, $3 FROM scripts WHERE id::text = $2")).
+		WithArgs("partner-key", "s1", "admin@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"script_version", "granted_at", "name"}).
+			AddRow(4, rowTime, "daily-sales"))
+
+	g, err := s.Grant(context.Background(), "partner-key", "s1", "admin@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, 4, g.Version)
+	assert.True(t, g.Current)
+	assert.Equal(t, "daily-sales", g.ScriptName)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGrant_Refusals(t *testing.T) {
+	s, mock := newMock(t, sealer{})
+	mock.ExpectQuery("INSERT INTO script_secret_grants").WillReturnError(sql.ErrNoRows)
+	_, err := s.Grant(context.Background(), "partner-key", "missing", "")
+	require.ErrorIs(t, err, ErrNoScript)
+
+	mock.ExpectQuery("INSERT INTO script_secret_grants").
+		WillReturnError(&pq.Error{Code: pqForeignKeyViolation})
+	_, err = s.Grant(context.Background(), "missing", "s1", "")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRevokeAndDelete_ReportWhatIsNotThere(t *testing.T) {
+	s, mock := newMock(t, sealer{})
+	mock.ExpectExec("DELETE FROM script_secret_grants").WithArgs("k", "s1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	require.ErrorIs(t, s.Revoke(context.Background(), "k", "s1"), ErrNotFound)
+	mock.ExpectExec("DELETE FROM script_secrets").WithArgs("k").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, s.Delete(context.Background(), "k"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestVault_RevealsOnlyToTheGrantedVersion covers every answer a run can get.
+func TestVault_RevealsOnlyToTheGrantedVersion(t *testing.T) {
+	cols := []string{"value", "script_version"}
+	for _, tc := range []struct {
+		name  string
+		rows  *sqlmock.Rows
+		want  string
+		isErr error
+	}{
+		{"granted", sqlmock.NewRows(cols).AddRow("enc:hunter2", 3), "hunter2", nil},
+		{"no such secret", sqlmock.NewRows(cols), "no secret is named", ErrNotFound},
+		{"never granted", sqlmock.NewRows(cols).AddRow("enc:hunter2", nil), "not granted to this script", ErrNotGranted},
+		{"granted to another version", sqlmock.NewRows(cols).AddRow("enc:hunter2", 2), "granted to version 2", ErrNotGranted},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, mock := newMock(t, sealer{})
+			mock.ExpectQuery(regexp.QuoteMeta("LEFT JOIN script_secret_grants")).
+				WithArgs("partner-key", "s1").WillReturnRows(tc.rows)
+			value, err := s.Vault("s1", 3).Reveal(context.Background(), "partner-key")
+			if tc.isErr == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tc.want, value)
+				return
+			}
+			require.ErrorIs(t, err, tc.isErr)
+			assert.Contains(t, err.Error(), tc.want)
+			assert.NotContains(t, err.Error(), "hunter2")
+		})
+	}
+}
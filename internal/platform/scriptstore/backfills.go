@@ -0,0 +1,242 @@
+package scriptstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+// Compile-time interface verification.
+var _ script.BackfillStore = (*Store)(nil)
+
+// Backfill listing caps.
+const (
+	defaultBackfillListLimit = 50
+	defaultOpenBackfillLimit = 100
+)
+
+// maxBackfillRunError bounds the error a backfill report carries per fire. The
+// whole failure is on the run; a report of a month of fires is read for which
+// ones failed and roughly why.
+const maxBackfillRunError = 500
+
+// backfillColumns is the column list read by every script_backfills SELECT,
+// mirrored by scanBackfill so the scan order cannot drift from the query.
+const backfillColumns = `id, script_id, schedule_id, to_char(from_date, 'YYYY-MM-DD'),
+	to_char(to_date, 'YYYY-MM-DD'), cron_spec, timezone, params, fires, skipped, rerun,
+	concurrency, status, requested_by, cancelled_by, error, created_at, updated_at, finished_at`
+
+// backfillSelect is the base SELECT for the backfill columns.
+const backfillSelect = "SELECT " + backfillColumns + " FROM script_backfills"
+
+// scanBackfill reads one row in backfillColumns order into a Backfill.
+func scanBackfill(sc rowScanner) (*script.Backfill, error) {
+	b := &script.Backfill{}
+	var paramsJSON, firesJSON, skippedJSON []byte
+	err := sc.Scan(&b.ID, &b.ScriptID, &b.ScheduleID, &b.From, &b.To, &b.CronSpec, &b.Timezone,
+		&paramsJSON, &firesJSON, &skippedJSON, &b.Rerun, &b.Concurrency, &b.Status,
+		&b.RequestedBy, &b.CancelledBy, &b.Error, &b.CreatedAt, &b.UpdatedAt, &b.FinishedAt)
+	if err != nil {
+		return nil, fmt.Errorf("scanning script backfill row: %w", err)
+	}
+	if err := json.Unmarshal(paramsJSON, &b.Params); err != nil {
+		return nil, fmt.Errorf("unmarshal backfill params: %w", err)
+	}
+	if err := json.Unmarshal(firesJSON, &b.Fires); err != nil {
+		return nil, fmt.Errorf("unmarshal backfill fires: %w", err)
+	}
+	if err := json.Unmarshal(skippedJSON, &b.Skipped); err != nil {
+		return nil, fmt.Errorf("unmarshal backfill skipped fires: %w", err)
+	}
+	return b, nil
+}
+
+// CreateBackfill inserts a backfill, first setting aside the fires that have
+// already succeeded unless the caller asked to rerun them.
+//
+// The one-running-backfill rule is the unique index's, not a read's: two
+// requests racing each other both insert, and the loser learns it here.
+func (s *Store) CreateBackfill(ctx context.Context, b *script.Backfill) error {
+	if len(b.Fires) == 0 {
+		return errors.New("a backfill needs at least one fire")
+	}
+	if !b.Rerun {
+		if err := s.setAsideSucceeded(ctx, b); err != nil {
+			return err
+		}
+		if len(b.Fires) == 0 {
+			return fmt.Errorf("every one of the %d fires in that range already succeeded; set rerun to replay them anyway", len(b.Skipped))
+		}
+	}
+	params, err := json.Marshal(orEmptyParams(b.Params))
+	if err != nil {
+		return fmt.Errorf("marshal backfill params: %w", err)
+	}
+	fires, err := json.Marshal(b.Fires)
+	if err != nil {
+		return fmt.Errorf("marshal backfill fires: %w", err)
+	}
+	skipped, err := json.Marshal(orEmptyTimes(b.Skipped))
+	if err != nil {
+		return fmt.Errorf("marshal backfill skipped fires: %w", err)
+	}
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO script_backfills (script_id, schedule_id, from_date, to_date, cron_spec, timezone,
+		                              params, fires, skipped, rerun, concurrency, requested_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (schedule_id) WHERE status = 'running' DO NOTHING
+		RETURNING id, status, created_at, updated_at`,
+		b.ScriptID, b.ScheduleID, b.From, b.To, b.CronSpec, b.Timezone,
+		params, fires, skipped, b.Rerun, b.Concurrency, b.RequestedBy,
+	).Scan(&b.ID, &b.Status, &b.CreatedAt, &b.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("this schedule already has a backfill running; cancel it or wait for it to finish")
+	}
+	if err != nil {
+		return fmt.Errorf("create script backfill: %w", err)
+	}
+	return nil
+}
+
+// setAsideSucceeded moves the fires the schedule has already run successfully,
+// live or in an earlier backfill, from Fires to Skipped.
+func (s *Store) setAsideSucceeded(ctx context.Context, b *script.Backfill) error {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT DISTINCT fire_time FROM script_runs
+		 WHERE schedule_id = $1 AND status = 'succeeded' AND fire_time BETWEEN $2 AND $3`,
+		b.ScheduleID, b.Fires[0], b.Fires[len(b.Fires)-1])
+	if err != nil {
+		return fmt.Errorf("reading succeeded fires: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	done := map[int64]bool{}
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return fmt.Errorf("scanning a succeeded fire: %w", err)
+		}
+		done[t.Unix()] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("reading succeeded fires: %w", err)
+	}
+	fires := b.Fires[:0:0]
+	for _, f := range b.Fires {
+		if done[f.Unix()] {
+			b.Skipped = append(b.Skipped, f)
+		} else {
+			fires = append(fires, f)
+		}
+	}
+	b.Fires = fires
+	return nil
+}
+
+// orEmptyTimes binds a nil slice as an empty JSON array.
+func orEmptyTimes(ts []time.Time) []time.Time {
+	if ts == nil {
+		return []time.Time{}
+	}
+	return ts
+}
+
+// GetBackfill returns one backfill by id.
+func (s *Store) GetBackfill(ctx context.Context, id string) (*script.Backfill, error) {
+	b, err := scanBackfill(s.db.QueryRowContext(ctx, backfillSelect+` WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, script.ErrBackfillNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get script backfill: %w", err)
+	}
+	return b, nil
+}
+
+// ListBackfills returns a script's backfills, newest first.
+func (s *Store) ListBackfills(ctx context.Context, scriptID string, limit int) ([]script.Backfill, error) {
+	if limit <= 0 || limit > defaultBackfillListLimit {
+		limit = defaultBackfillListLimit
+	}
+	return s.queryBackfills(ctx, backfillSelect+`
+		WHERE script_id = $1 ORDER BY created_at DESC LIMIT $2`, scriptID, limit)
+}
+
+// OpenBackfills returns the running backfills, oldest first, so a pass that
+// fills its batch serves the longest-waiting first.
+func (s *Store) OpenBackfills(ctx context.Context, limit int) ([]script.Backfill, error) {
+	if limit <= 0 || limit > defaultOpenBackfillLimit {
+		limit = defaultOpenBackfillLimit
+	}
+	return s.queryBackfills(ctx, backfillSelect+`
+		WHERE status = 'running' ORDER BY created_at LIMIT $1`, limit)
+}
+
+// queryBackfills runs a backfill listing query.
+func (s *Store) queryBackfills(ctx context.Context, query string, args ...any) ([]script.Backfill, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list script backfills: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var out []script.Backfill
+	for rows.Next() {
+		b, err := scanBackfill(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list script backfills: %w", err)
+	}
+	return out, nil
+}
+
+// BackfillRuns returns the outcome of every fire the backfill materialized, in
+// fire order. It reads the run columns a report needs and no more: a month of
+// runs with their logs and outputs is not what "which days failed" costs.
+func (s *Store) BackfillRuns(ctx context.Context, id string) ([]script.BackfillFire, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT fire_time, id, status, LEFT(error, $2), failure_kind
+		  FROM script_runs WHERE backfill_id = $1 ORDER BY fire_time`, id, maxBackfillRunError)
+	if err != nil {
+		return nil, fmt.Errorf("list backfill runs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var out []script.BackfillFire
+	for rows.Next() {
+		var f script.BackfillFire
+		if err := rows.Scan(&f.At, &f.RunID, &f.Status, &f.Error, &f.FailureKind); err != nil {
+			return nil, fmt.Errorf("scanning a backfill run: %w", err)
+		}
+		out = append(out, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list backfill runs: %w", err)
+	}
+	return out, nil
+}
+
+// FinishBackfill ends a running backfill. It is conditional on the row still
+// running, so a completion and a cancellation racing each other leave one
+// answer, and the caller learns whether it was its own.
+func (s *Store) FinishBackfill(ctx context.Context, id, actor, reason string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE script_backfills
+		   SET status = CASE WHEN $2 = '' THEN 'completed' ELSE 'cancelled' END,
+		       cancelled_by = $2, error = $3, finished_at = NOW(), updated_at = NOW()
+		 WHERE id = $1 AND status = 'running'`, id, actor, reason)
+	if err != nil {
+		return false, fmt.Errorf("finish script backfill: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("checking a backfill finish: %w", err)
+	}
+	return n > 0, nil
+}
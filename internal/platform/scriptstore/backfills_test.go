@@ -0,0 +1,125 @@
+package scriptstore
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+// backfillSelectColumns is the result-set shape a backfill SELECT mock must
+// return, in backfillColumns order.
+var backfillSelectColumns = []string{
+	"id", "script_id", "schedule_id", "from_date", "to_date", "cron_spec", "timezone",
+	"params", "fires", "skipped", "rerun", "concurrency", "status",
+	"requested_by", "cancelled_by", "error", "created_at", "updated_at", "finished_at",
+}
+
+func TestBackfillColumnsMatchTheScanOrder(t *testing.T) {
+	assert.Len(t, splitTopLevel(backfillColumns), len(backfillSelectColumns))
+}
+
+// day is a fire of a daily 07:00 UTC schedule.
+func day(d int) time.Time { return time.Date(2026, 3, d, 7, 0, 0, 0, time.UTC) }
+
+func planned() *script.Backfill {
+	return &script.Backfill{
+		ScriptID: "script_1", ScheduleID: "sched_1", From: "2026-03-01", To: "2026-03-03",
+		CronSpec: "0 7 * * *", Timezone: "UTC", Fires: []time.Time{day(1), day(2), day(3)},
+		Concurrency: 2, RequestedBy: "jane@example.com",
+	}
+}
+
+func TestCreateBackfill_SetsAsideFiresThatAlreadySucceeded(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("status = 'succeeded' AND fire_time BETWEEN $2 AND $3")).
+		WithArgs("sched_1", day(1), day(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"fire_time"}).AddRow(day(2)))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO script_backfills")).
+		WithArgs("script_1", "sched_1", "2026-03-01", "2026-03-03", "0 7 * * *", "UTC",
+			[]byte(`{}`), sqlmock.AnyArg(), sqlmock.AnyArg(), false, 2, "jane@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at", "updated_at"}).
+			AddRow("bf_1", "running", rowTime, rowTime))
+
+	b := planned()
+	require.NoError(t, s.CreateBackfill(context.Background(), b))
+	assert.Equal(t, "bf_1", b.ID)
+	assert.Equal(t, []time.Time{day(1), day(3)}, b.Fires)
+	assert.Equal(t, []time.Time{day(2)}, b.Skipped)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateBackfill_Refusals(t *testing.T) {
+	t.Run("every fire already succeeded", func(t *testing.T) {
+		s, mock := newMock(t)
+		mock.ExpectQuery(regexp.QuoteMeta("FROM script_runs")).
+			WillReturnRows(sqlmock.NewRows([]string{"fire_time"}).AddRow(day(1)).AddRow(day(2)).AddRow(day(3)))
+
+		err := s.CreateBackfill(context.Background(), planned())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "set rerun")
+	})
+
+	t.Run("a second running backfill of the schedule", func(t *testing.T) {
+		s, mock := newMock(t)
+		mock.ExpectQuery(regexp.QuoteMeta("ON CONFLICT (schedule_id) WHERE status = 'running' DO NOTHING")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at", "updated_at"}))
+
+		b := planned()
+		b.Rerun = true
+		err := s.CreateBackfill(context.Background(), b)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already has a backfill running")
+		require.NoError(t, mock.ExpectationsWereMet(), "a rerun reads no prior fires")
+	})
+}
+
+func TestGetBackfill(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM script_backfills WHERE id = $1")).
+		WillReturnRows(sqlmock.NewRows(backfillSelectColumns).AddRow(
+			"bf_1", "script_1", "sched_1", "2026-03-01", "2026-03-03", "0 7 * * *", "UTC",
+			[]byte(`{"day":"${fire_date}"}`), []byte(`["2026-03-01T07:00:00Z"]`), []byte(`[]`), false, 2, "running",
+			"jane@example.com", "", "", rowTime, rowTime, nil))
+
+	b, err := s.GetBackfill(context.Background(), "bf_1")
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{day(1)}, b.Fires)
+	assert.Equal(t, "${fire_date}", b.Params["day"])
+	assert.True(t, b.Open())
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM script_backfills")).WillReturnError(sql.ErrNoRows)
+	_, err = s.GetBackfill(context.Background(), "nope")
+	assert.ErrorIs(t, err, script.ErrBackfillNotFound)
+}
+
+func TestBackfillRuns_ReadsTheReportColumns(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE backfill_id = $1 ORDER BY fire_time")).
+		WithArgs("bf_1", maxBackfillRunError).
+		WillReturnRows(sqlmock.NewRows([]string{"fire_time", "id", "status", "error", "failure_kind"}).
+			AddRow(day(1), "dpx_1", script.RunStatusFailed, "boom", script.FailureKindDataQuality))
+
+	runs, err := s.BackfillRuns(context.Background(), "bf_1")
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, script.BackfillFire{At: day(1), RunID: "dpx_1", Status: script.RunStatusFailed,
+		Error: "boom", FailureKind: script.FailureKindDataQuality}, runs[0])
+}
+
+func TestFinishBackfill_IsConditionalOnRunning(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectExec(regexp.QuoteMeta("WHERE id = $1 AND status = 'running'")).
+		WithArgs("bf_1", "jane@example.com", "").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	moved, err := s.FinishBackfill(context.Background(), "bf_1", "jane@example.com", "")
+	require.NoError(t, err)
+	assert.False(t, moved, "a backfill that already finished is not finished twice")
+}
@@ -0,0 +1,292 @@
+package scriptstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+// Compile-time interface verification.
+var _ script.PipelineStore = (*Store)(nil)
+
+// Pipeline listing caps.
+const (
+	defaultPipelineListLimit    = 200
+	defaultPipelineRunListLimit = 50
+)
+
+// pipelineColumns is the column list read by every script_pipelines SELECT,
+// mirrored by scanPipeline so the scan order cannot drift from the query.
+const pipelineColumns = `id, name, description, owner_email, steps, cron_spec, timezone,
+	enabled, next_run_at, created_by, updated_by, created_at, updated_at`
+
+// pipelineSelect is the base SELECT for the pipeline columns.
+const pipelineSelect = "SELECT " + pipelineColumns + " FROM script_pipelines"
+
+// pipelineRunColumns is the column list read by every script_pipeline_runs
+// SELECT, mirrored by scanPipelineRun.
+const pipelineRunColumns = `id, pipeline_id, trigger_kind, status, steps, requested_by,
+	fire_time, attempt, revision, created_at, updated_at, finished_at`
+
+// pipelineRunSelect is the base SELECT for the pipeline run columns.
+const pipelineRunSelect = "SELECT " + pipelineRunColumns + " FROM script_pipeline_runs"
+
+// scanPipeline reads one row in pipelineColumns order into a Pipeline.
+func scanPipeline(sc rowScanner) (*script.Pipeline, error) {
+	p := &script.Pipeline{}
+	var stepsJSON []byte
+	var nextRunAt sql.NullTime
+	err := sc.Scan(&p.ID, &p.Name, &p.Description, &p.OwnerEmail, &stepsJSON, &p.CronSpec, &p.Timezone,
+		&p.Enabled, &nextRunAt, &p.CreatedBy, &p.UpdatedBy, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("scanning script pipeline row: %w", err)
+	}
+	if nextRunAt.Valid {
+		p.NextRunAt = nextRunAt.Time
+	}
+	if err := json.Unmarshal(stepsJSON, &p.Steps); err != nil {
+		return nil, fmt.Errorf("unmarshal pipeline steps: %w", err)
+	}
+	return p, nil
+}
+
+// scanPipelineRun reads one row in pipelineRunColumns order into a PipelineRun.
+func scanPipelineRun(sc rowScanner) (*script.PipelineRun, error) {
+	r := &script.PipelineRun{}
+	var stepsJSON []byte
+	err := sc.Scan(&r.ID, &r.PipelineID, &r.Trigger, &r.Status, &stepsJSON, &r.RequestedBy,
+		&r.FireTime, &r.Attempt, &r.Revision, &r.CreatedAt, &r.UpdatedAt, &r.FinishedAt)
+	if err != nil {
+		return nil, fmt.Errorf("scanning script pipeline run row: %w", err)
+	}
+	if err := json.Unmarshal(stepsJSON, &r.Steps); err != nil {
+		return nil, fmt.Errorf("unmarshal pipeline run steps: %w", err)
+	}
+	return r, nil
+}
+
+// SetPipeline creates or replaces a pipeline, keyed on its owner and name for
+// the reason SetSchedule is keyed on the script: the name is what a caller
+// says. Replacing keeps the id, and so the pipeline's run history.
+func (s *Store) SetPipeline(ctx context.Context, p *script.Pipeline) error {
+	steps, err := json.Marshal(p.Steps)
+	if err != nil {
+		return fmt.Errorf("marshal pipeline steps: %w", err)
+	}
+	row := s.db.QueryRowContext(ctx, `
+		INSERT INTO script_pipelines (name, description, owner_email, steps, cron_spec, timezone,
+		                              enabled, next_run_at, created_by, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+		ON CONFLICT (owner_email, name) DO UPDATE
+		   SET description = EXCLUDED.description, steps = EXCLUDED.steps,
+		       cron_spec = EXCLUDED.cron_spec, timezone = EXCLUDED.timezone,
+		       enabled = EXCLUDED.enabled, next_run_at = EXCLUDED.next_run_at,
+		       updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		RETURNING id, created_by, created_at, updated_at`,
+		p.Name, p.Description, p.OwnerEmail, steps, p.CronSpec, p.Timezone,
+		p.Enabled, orNilTime(p.NextRunAt), p.UpdatedBy)
+	if err := row.Scan(&p.ID, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return fmt.Errorf("set script pipeline: %w", err)
+	}
+	return nil
+}
+
+// GetPipeline returns one owner's pipeline by name.
+func (s *Store) GetPipeline(ctx context.Context, ownerEmail, name string) (*script.Pipeline, error) {
+	p, err := scanPipeline(s.db.QueryRowContext(ctx,
+		pipelineSelect+` WHERE owner_email = $1 AND name = $2`, ownerEmail, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, script.ErrPipelineNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get script pipeline: %w", err)
+	}
+	return p, nil
+}
+
+// ListPipelines returns an owner's pipelines, or every pipeline when
+// ownerEmail is empty, by name.
+func (s *Store) ListPipelines(ctx context.Context, ownerEmail string, limit int) ([]script.Pipeline, error) {
+	if limit <= 0 || limit > defaultPipelineListLimit {
+		limit = defaultPipelineListLimit
+	}
+	return s.queryPipelines(ctx, pipelineSelect+`
+		WHERE ($1 = '' OR owner_email = $1)
+		ORDER BY name, owner_email LIMIT $2`, ownerEmail, limit)
+}
+
+// DuePipelines returns enabled pipelines whose cadence has come due, oldest
+// fire first, for the reason DueSchedules orders them so.
+func (s *Store) DuePipelines(ctx context.Context, now time.Time, limit int) ([]script.Pipeline, error) {
+	if limit <= 0 || limit > defaultDueLimit {
+		limit = defaultDueLimit
+	}
+	return s.queryPipelines(ctx, pipelineSelect+`
+		WHERE enabled AND cron_spec <> '' AND next_run_at <= $1
+		ORDER BY next_run_at LIMIT $2`, now, limit)
+}
+
+// queryPipelines runs a pipeline listing query.
+func (s *Store) queryPipelines(ctx context.Context, query string, args ...any) ([]script.Pipeline, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list script pipelines: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []script.Pipeline{}
+	for rows.Next() {
+		p, err := scanPipeline(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate script pipelines: %w", err)
+	}
+	return out, nil
+}
+
+// DeletePipeline removes a pipeline; its runs go with it by cascade.
+func (s *Store) DeletePipeline(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM script_pipelines WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete script pipeline: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return script.ErrPipelineNotFound
+	}
+	return nil
+}
+
+// AdvancePipeline moves a pipeline's next fire, only if it is still where the
+// caller found it. As with AdvanceSchedule, losing is silent: the unique index
+// on the run is the single-fire guarantee.
+func (s *Store) AdvancePipeline(ctx context.Context, id string, from, next time.Time) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE script_pipelines SET next_run_at = $3, updated_at = NOW()
+		 WHERE id = $1 AND next_run_at = $2`, id, from, orNilTime(next))
+	if err != nil {
+		return false, fmt.Errorf("advance script pipeline: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("checking a pipeline advance: %w", err)
+	}
+	return n > 0, nil
+}
+
+// StartPipelineRun inserts a pipeline run. A scheduled run whose fire another
+// replica already recorded conflicts on idx_script_pipeline_runs_fire and
+// inserts nothing, which is reported as false rather than as an error.
+func (s *Store) StartPipelineRun(ctx context.Context, r *script.PipelineRun) (bool, error) {
+	steps, err := json.Marshal(r.Steps)
+	if err != nil {
+		return false, fmt.Errorf("marshal pipeline run steps: %w", err)
+	}
+	row := s.db.QueryRowContext(ctx, `
+		INSERT INTO script_pipeline_runs (pipeline_id, trigger_kind, status, steps, requested_by, fire_time)
+		VALUES ($1, $2, $3, $4, $5, COALESCE($6, NOW()))
+		ON CONFLICT (pipeline_id, fire_time) WHERE trigger_kind = 'schedule' DO NOTHING
+		RETURNING id, fire_time, revision, created_at, updated_at`,
+		r.PipelineID, r.Trigger, r.Status, steps, r.RequestedBy, orNilTime(r.FireTime))
+	err = row.Scan(&r.ID, &r.FireTime, &r.Revision, &r.CreatedAt, &r.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("start script pipeline run: %w", err)
+	}
+	return true, nil
+}
+
+// GetPipelineRun returns one pipeline run by id. An id that is not a uuid names
+// no run, and is answered as one rather than sent to fail a cast.
+func (s *Store) GetPipelineRun(ctx context.Context, id string) (*script.PipelineRun, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, script.ErrPipelineRunNotFound
+	}
+	r, err := scanPipelineRun(s.db.QueryRowContext(ctx, pipelineRunSelect+` WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, script.ErrPipelineRunNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get script pipeline run: %w", err)
+	}
+	return r, nil
+}
+
+// ListPipelineRuns returns pipeline runs matching the filter. A listing of one
+// status is oldest first, which is the order the pipeline pass walks open runs
+// in; any other listing is a history and newest first.
+func (s *Store) ListPipelineRuns(ctx context.Context, filter script.PipelineRunFilter) ([]script.PipelineRun, error) {
+	q := &listQuery{}
+	if filter.PipelineID != "" {
+		q.add("pipeline_id = $%d", filter.PipelineID)
+	}
+	order := "created_at DESC"
+	if filter.Status != "" {
+		q.add("status = $%d", filter.Status)
+		order = "created_at"
+	}
+	query := pipelineRunSelect
+	if len(q.where) > 0 {
+		query += " WHERE " + joinAnd(q.where)
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > defaultPipelineRunListLimit {
+		limit = defaultPipelineRunListLimit
+	}
+	q.args = append(q.args, limit)
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("%s ORDER BY %s LIMIT $%d", query, order, len(q.args)), q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("list script pipeline runs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []script.PipelineRun{}
+	for rows.Next() {
+		r, err := scanPipelineRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate script pipeline runs: %w", err)
+	}
+	return out, nil
+}
+
+// UpdatePipelineRun writes a pipeline run's state if nobody else has since it
+// was read. The revision is the fence: a replica that lost the race matches no
+// row and is told so, and re-reads on its next pass.
+func (s *Store) UpdatePipelineRun(ctx context.Context, r *script.PipelineRun) error {
+	steps, err := json.Marshal(r.Steps)
+	if err != nil {
+		return fmt.Errorf("marshal pipeline run steps: %w", err)
+	}
+	row := s.db.QueryRowContext(ctx, `
+		UPDATE script_pipeline_runs
+		   SET status = $3, steps = $4, attempt = $5, finished_at = $6,
+		       revision = revision + 1, updated_at = NOW()
+		 WHERE id = $1 AND revision = $2
+		RETURNING revision, updated_at`,
+		r.ID, r.Revision, r.Status, steps, r.Attempt, r.FinishedAt)
+	err = row.Scan(&r.Revision, &r.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return script.ErrPipelineRunMoved
+	}
+	if err != nil {
+		return fmt.Errorf("update script pipeline run: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package scriptstore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+// pipelineSelectColumns is the result-set shape a pipeline SELECT mock must
+// return, in pipelineColumns order.
+var pipelineSelectColumns = []string{
+	"id", "name", "description", "owner_email", "steps", "cron_spec", "timezone",
+	"enabled", "next_run_at", "created_by", "updated_by", "created_at", "updated_at",
+}
+
+// pipelineRunSelectColumns is the same for a pipeline run SELECT.
+var pipelineRunSelectColumns = []string{
+	"id", "pipeline_id", "trigger_kind", "status", "steps", "requested_by",
+	"fire_time", "attempt", "revision", "created_at", "updated_at", "finished_at",
+}
+
+// pipelineRunID is a well-formed pipeline run id.
+const pipelineRunID = "7f1c2a9e-4b3d-4e5f-8a6b-1c2d3e4f5a6b"
+
+func TestPipelineColumnsMatchTheScanOrder(t *testing.T) {
+	assert.Len(t, splitTopLevel(pipelineColumns), len(pipelineSelectColumns))
+	assert.Len(t, splitTopLevel(pipelineRunColumns), len(pipelineRunSelectColumns))
+}
+
+func TestSetPipeline_UpsertsOnTheOwnerAndName(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("ON CONFLICT (owner_email, name) DO UPDATE")).
+		WithArgs("daily-revenue", "", "jane@example.com", sqlmock.AnyArg(), "", "UTC",
+			true, nil, "jane@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_by", "created_at", "updated_at"}).
+			AddRow("pipe_1", "jane@example.com", rowTime, rowTime))
+
+	p := &script.Pipeline{
+		Name: "daily-revenue", OwnerEmail: "jane@example.com", Timezone: "UTC", Enabled: true,
+		Steps:     []script.PipelineStep{{Name: "extract", ScriptID: "script_1"}},
+		UpdatedBy: "jane@example.com",
+	}
+	require.NoError(t, s.SetPipeline(context.Background(), p))
+	assert.Equal(t, "pipe_1", p.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPipeline_ReadsTheSteps(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE owner_email = $1 AND name = $2")).
+		WillReturnRows(sqlmock.NewRows(pipelineSelectColumns).AddRow(
+			"pipe_1", "daily-revenue", "", "jane@example.com",
+			[]byte(`[{"name":"extract","script_id":"script_1"},{"name":"publish","script_id":"script_2","depends_on":["extract"]}]`),
+			"0 6 * * *", "UTC", true, rowTime, "jane@example.com", "jane@example.com", rowTime, rowTime))
+
+	p, err := s.GetPipeline(context.Background(), "jane@example.com", "daily-revenue")
+	require.NoError(t, err)
+	require.Len(t, p.Steps, 2)
+	assert.Equal(t, []string{"extract"}, p.Steps[1].DependsOn)
+	assert.Equal(t, rowTime, p.NextRunAt)
+}
+
+func TestGetPipeline_NotFound(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta(pipelineSelect)).WillReturnError(sql.ErrNoRows)
+	_, err := s.GetPipeline(context.Background(), "jane@example.com", "missing")
+	require.ErrorIs(t, err, script.ErrPipelineNotFound)
+}
+
+func TestStartPipelineRun_AFireAlreadyRecordedIsNotAnError(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("ON CONFLICT (pipeline_id, fire_time) WHERE trigger_kind = 'schedule' DO NOTHING")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "fire_time", "revision", "created_at", "updated_at"}))
+
+	inserted, err := s.StartPipelineRun(context.Background(), &script.PipelineRun{
+		PipelineID: "pipe_1", Trigger: script.TriggerSchedule, Status: script.RunStatusRunning, FireTime: rowTime,
+	})
+	require.NoError(t, err)
+	assert.False(t, inserted)
+}
+
+func TestGetPipelineRun_AnIDThatIsNotAUUIDIsNotFound(t *testing.T) {
+	s, mock := newMock(t)
+	_, err := s.GetPipelineRun(context.Background(), "run_1")
+	require.ErrorIs(t, err, script.ErrPipelineRunNotFound)
+	require.NoError(t, mock.ExpectationsWereMet(), "nothing is sent to the database")
+}
+
+func TestGetPipelineRun_ReadsTheStepState(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta(pipelineRunSelect + " WHERE id = $1")).
+		WithArgs(pipelineRunID).
+		WillReturnRows(sqlmock.NewRows(pipelineRunSelectColumns).AddRow([]driver.Value{
+			pipelineRunID, "pipe_1", "tool", "running",
+			[]byte(`[{"name":"extract","script_id":"script_1","status":"running","run_id":"run_1"}]`),
+			"jane@example.com", rowTime, 0, 3, rowTime, rowTime, nil,
+		}...))
+
+	r, err := s.GetPipelineRun(context.Background(), pipelineRunID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, r.Revision)
+	require.NotNil(t, r.Step("extract"))
+	assert.Equal(t, "run_1", r.Step("extract").RunID)
+}
+
+func TestListPipelineRuns_OpenRunsOldestFirst(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE status = $1 ORDER BY created_at LIMIT $2")).
+		WithArgs(script.RunStatusRunning, 10).
+		WillReturnRows(sqlmock.NewRows(pipelineRunSelectColumns))
+
+	runs, err := s.ListPipelineRuns(context.Background(), script.PipelineRunFilter{Status: script.RunStatusRunning, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdatePipelineRun_ALostRaceIsMoved(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE id = $1 AND revision = $2")).
+		WithArgs(pipelineRunID, 3, script.RunStatusRunning, sqlmock.AnyArg(), 0, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"revision", "updated_at"}))
+
+	err := s.UpdatePipelineRun(context.Background(), &script.PipelineRun{
+		ID: pipelineRunID, Revision: 3, Status: script.RunStatusRunning,
+	})
+	require.ErrorIs(t, err, script.ErrPipelineRunMoved)
+}
+
+func TestUpdatePipelineRun_AdvancesTheRevision(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("revision = revision + 1")).
+		WillReturnRows(sqlmock.NewRows([]string{"revision", "updated_at"}).AddRow(4, rowTime))
+
+	r := &script.PipelineRun{ID: pipelineRunID, Revision: 3, Status: script.RunStatusRunning}
+	require.NoError(t, s.UpdatePipelineRun(context.Background(), r))
+	assert.Equal(t, 4, r.Revision)
+}
@@ -0,0 +1,80 @@
+package scriptstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+// Compile-time interface verification.
+var _ script.RetentionStore = (*Store)(nil)
+
+// GetRetention returns one script's retention, the zero retention when it
+// never set one.
+func (s *Store) GetRetention(ctx context.Context, scriptID string) (*script.Retention, error) {
+	r := &script.Retention{ScriptID: scriptID}
+	var runDays, outputVersions sql.NullInt64
+	var updatedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `
+		SELECT run_retention_days, output_versions, updated_by, updated_at
+		  FROM script_retention
+		 WHERE script_id = $1`, scriptID).Scan(&runDays, &outputVersions, &r.UpdatedBy, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get script retention: %w", err)
+	}
+	r.RunDays = nullInt(runDays)
+	r.OutputVersions = nullInt(outputVersions)
+	if updatedAt.Valid {
+		r.UpdatedAt = &updatedAt.Time
+	}
+	return r, nil
+}
+
+// SetRetention upserts one script's retention. A retention with both fields
+// nil is still written rather than deleted, so the row keeps who last set it.
+func (s *Store) SetRetention(ctx context.Context, r *script.Retention) error {
+	if err := r.Validate(); err != nil {
+		return fmt.Errorf("invalid script retention: %w", err)
+	}
+	var updatedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO script_retention (script_id, run_retention_days, output_versions, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (script_id) DO UPDATE
+		   SET run_retention_days = EXCLUDED.run_retention_days,
+		       output_versions    = EXCLUDED.output_versions,
+		       updated_by         = EXCLUDED.updated_by,
+		       updated_at         = NOW()
+		RETURNING updated_at`,
+		r.ScriptID, nullableInt(r.RunDays), nullableInt(r.OutputVersions), r.UpdatedBy).Scan(&updatedAt)
+	if err != nil {
+		return fmt.Errorf("set script retention: %w", err)
+	}
+	if updatedAt.Valid {
+		r.UpdatedAt = &updatedAt.Time
+	}
+	return nil
+}
+
+// nullInt reads a nullable integer column into an optional setting.
+func nullInt(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	n := int(v.Int64)
+	return &n
+}
+
+// nullableInt binds an optional setting, nil as SQL NULL.
+func nullableInt(v *int) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
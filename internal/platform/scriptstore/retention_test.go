@@ -0,0 +1,62 @@
+package scriptstore
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+func intPtr(n int) *int { return &n }
+
+func TestGetRetention_NoRowIsTheDeploymentDefault(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM script_retention")).WithArgs("script_1").
+		WillReturnRows(sqlmock.NewRows([]string{"run_retention_days", "output_versions", "updated_by", "updated_at"}))
+
+	got, err := s.GetRetention(context.Background(), "script_1")
+	require.NoError(t, err)
+	assert.Equal(t, &script.Retention{ScriptID: "script_1"}, got, "never setting one is a setting, not an error")
+}
+
+func TestGetRetention_ReadsEachColumn(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM script_retention")).WithArgs("script_1").
+		WillReturnRows(sqlmock.NewRows([]string{"run_retention_days", "output_versions", "updated_by", "updated_at"}).
+			AddRow(30, nil, "jane@example.com", rowTime))
+
+	got, err := s.GetRetention(context.Background(), "script_1")
+	require.NoError(t, err)
+	assert.Equal(t, intPtr(30), got.RunDays)
+	assert.Nil(t, got.OutputVersions, "a NULL column keeps the deployment's setting")
+	assert.Equal(t, "jane@example.com", got.UpdatedBy)
+	require.NotNil(t, got.UpdatedAt)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM script_retention")).WillReturnError(errors.New("boom"))
+	_, err = s.GetRetention(context.Background(), "script_1")
+	require.ErrorContains(t, err, "get script retention")
+}
+
+func TestSetRetention_UpsertsAndBindsNullForUnset(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("ON CONFLICT (script_id) DO UPDATE")).
+		WithArgs("script_1", nil, 0, "jane@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(rowTime))
+
+	r := &script.Retention{ScriptID: "script_1", OutputVersions: intPtr(0), UpdatedBy: "jane@example.com"}
+	require.NoError(t, s.SetRetention(context.Background(), r))
+	require.NotNil(t, r.UpdatedAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetRetention_RefusesWhatNoSurfaceCouldMean(t *testing.T) {
+	s, _ := newMock(t)
+	err := s.SetRetention(context.Background(), &script.Retention{ScriptID: "script_1", RunDays: intPtr(0)})
+	require.ErrorContains(t, err, "run_days must be between 1")
+}
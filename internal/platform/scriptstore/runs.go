@@ -30,7 +30,8 @@ const defaultRunListLimit = 50
 const runColumns = `id, script_id, script_version_id, version, trigger_kind, status,
 	params, fire_time, requested_by, scheduled_for, started_at, finished_at, attempt,
 	locked_until, locked_by, error, log_text, log_truncated, metrics, outputs,
-	COALESCE(schedule_id::text, ''), created_at, updated_at`
+	COALESCE(schedule_id::text, ''), created_at, updated_at, COALESCE(event_key, ''),
+	failure_kind, quality_checks, COALESCE(backfill_id::text, '')`
 
 // runSelect is the base SELECT for the run columns.
 const runSelect = "SELECT " + runColumns + " FROM script_runs"
@@ -45,11 +46,12 @@ const dueClause = `((status = 'pending' AND scheduled_for <= NOW())
 // scanRun reads one row in runColumns order into a Run.
 func scanRun(sc rowScanner) (*script.Run, error) {
 	r := &script.Run{}
-	var paramsJSON, metricsJSON, outputsJSON []byte
+	var paramsJSON, metricsJSON, outputsJSON, checksJSON []byte
 	err := sc.Scan(&r.ID, &r.ScriptID, &r.VersionID, &r.Version, &r.Trigger, &r.Status,
 		&paramsJSON, &r.FireTime, &r.RequestedBy, &r.ScheduledFor, &r.StartedAt, &r.FinishedAt,
 		&r.Attempt, &r.LockedUntil, &r.LockedBy, &r.Error, &r.Log, &r.LogTruncated,
-		&metricsJSON, &outputsJSON, &r.ScheduleID, &r.CreatedAt, &r.UpdatedAt)
+		&metricsJSON, &outputsJSON, &r.ScheduleID, &r.CreatedAt, &r.UpdatedAt, &r.EventKey,
+		&r.FailureKind, &checksJSON, &r.BackfillID)
 	if err != nil {
 		return nil, fmt.Errorf("scanning script run row: %w", err)
 	}
@@ -62,6 +64,9 @@ func scanRun(sc rowScanner) (*script.Run, error) {
 	if err := json.Unmarshal(outputsJSON, &r.Outputs); err != nil {
 		return nil, fmt.Errorf("unmarshal run outputs: %w", err)
 	}
+	if err := json.Unmarshal(checksJSON, &r.Checks); err != nil {
+		return nil, fmt.Errorf("unmarshal run quality checks: %w", err)
+	}
 	return r, nil
 }
 
@@ -269,19 +274,41 @@ func (s *Store) RecordOutput(ctx context.Context, lease script.RunLease, out scr
 	return requireLease(res, lease)
 }
 
+// RecordLog writes the log a claimed run has printed so far. It is fenced like
+// every other write, so a stale worker cannot overwrite a reclaimed run's log.
+func (s *Store) RecordLog(ctx context.Context, lease script.RunLease, log string, truncated bool) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE script_runs SET log_text = $4, log_truncated = $5, updated_at = NOW()`+leaseClause,
+		lease.RunID, lease.Worker, lease.Attempt, log, truncated)
+	if err != nil {
+		return fmt.Errorf("record script run log: %w", err)
+	}
+	return requireLease(res, lease)
+}
+
 // Finish records a terminal result for the claimed run and clears its lease.
 func (s *Store) Finish(ctx context.Context, lease script.RunLease, result script.RunResult) error {
 	metrics, err := json.Marshal(result.Metrics)
 	if err != nil {
 		return fmt.Errorf("marshal run metrics: %w", err)
 	}
+	checks := result.Checks
+	if checks == nil {
+		checks = []script.QualityCheck{}
+	}
+	checksJSON, err := json.Marshal(checks)
+	if err != nil {
+		return fmt.Errorf("marshal run quality checks: %w", err)
+	}
 	res, err := s.db.ExecContext(ctx, `
 		UPDATE script_runs
 		   SET status = $4, error = $5, log_text = $6, log_truncated = $7,
-		       metrics = $8, finished_at = NOW(), locked_until = NULL,
+		       metrics = $8, failure_kind = $9, quality_checks = $10,
+		       finished_at = NOW(), locked_until = NULL,
 		       updated_at = NOW()`+leaseClause,
 		lease.RunID, lease.Worker, lease.Attempt,
-		result.Status, result.Error, result.Log, result.LogTruncated, metrics)
+		result.Status, result.Error, result.Log, result.LogTruncated, metrics,
+		result.FailureKind, checksJSON)
 	if err != nil {
 		return fmt.Errorf("finish script run: %w", err)
 	}
@@ -330,11 +357,29 @@ func requireLease(res sql.Result, lease script.RunLease) error {
 // a finished_at from the moment it exists so it ages out on the same clock. A
 // pending or running row is live work, and a retention pass that could delete
 // it would silently drop a run somebody is waiting on.
+//
+// A finished run still belongs to live work while the backfill or pipeline run
+// it is part of is open: the backfill counts its runs to know which fires are
+// still waiting, and the pipeline reads a step's run back to advance. Those
+// runs wait for their backfill or pipeline run to finish.
+//
+// retention is the deployment's window. A script that set its own
+// (script_retention.run_retention_days) is swept on that instead, shorter or
+// longer, in the same statement, so one pass honors every script's choice.
 func (s *Store) PurgeRuns(ctx context.Context, retention time.Duration) (int64, error) {
 	res, err := s.db.ExecContext(ctx, `
-		DELETE FROM script_runs
-		 WHERE status IN ('succeeded', 'failed', 'skipped_overlap')
-		   AND finished_at < NOW() - ($1 || ' seconds')::INTERVAL`,
+		DELETE FROM script_runs r
+		 WHERE r.status IN ('succeeded', 'failed', 'skipped_overlap')
+		   AND r.finished_at < NOW() - COALESCE(
+		         (SELECT t.run_retention_days * INTERVAL '1 day'
+		            FROM script_retention t
+		           WHERE t.script_id = r.script_id),
+		         ($1 || ' seconds')::INTERVAL)
+		   AND NOT EXISTS (SELECT 1 FROM script_backfills b
+		                    WHERE b.id = r.backfill_id AND b.status = 'running')
+		   AND NOT EXISTS (SELECT 1 FROM script_pipeline_runs p
+		                    WHERE p.status = 'running'
+		                      AND p.steps @> jsonb_build_array(jsonb_build_object('run_id', r.id)))`,
 		int(retention.Seconds()))
 	if err != nil {
 		return 0, fmt.Errorf("purge script runs: %w", err)
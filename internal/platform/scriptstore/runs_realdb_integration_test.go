@@ -205,6 +205,78 @@ func TestRealDB_PurgeLeavesLiveWorkAlone(t *testing.T) {
 	assert.Equal(t, script.RunStatusPending, survivors[0].Status)
 }
 
+// TestRealDB_PurgeKeepsAnOpenPipelinesSteps pins the pipeline exclusion
+// against the real schema: a finished run an open pipeline run names as a step
+// survives the sweep, and is swept once the pipeline run finishes.
+func TestRealDB_PurgeKeepsAnOpenPipelinesSteps(t *testing.T) {
+	db := testdb.New(t)
+	s := New(db)
+	ctx := context.Background()
+
+	sc, version := savedScript(ctx, t, s, "extract")
+	require.NoError(t, s.Enqueue(ctx, &script.Run{
+		ID: "dpx_step", ScriptID: sc.ID, VersionID: version.ID, Version: version.Version,
+		Trigger: script.TriggerPipeline,
+	}))
+	run, err := s.Claim(ctx, "worker-a", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, s.Finish(ctx, run.Lease(), script.RunResult{Status: script.RunStatusSucceeded}))
+
+	p := &script.Pipeline{Name: "daily", OwnerEmail: "ana@x.io", Enabled: true,
+		Steps: []script.PipelineStep{{Name: "extract", ScriptID: sc.ID}}}
+	require.NoError(t, s.SetPipeline(ctx, p))
+	pr := p.NewRun(script.TriggerTool, "ana@x.io", time.Time{})
+	pr.Steps[0].Status, pr.Steps[0].RunID = script.RunStatusSucceeded, "dpx_step"
+	_, err = s.StartPipelineRun(ctx, pr)
+	require.NoError(t, err)
+
+	purged, err := s.PurgeRuns(ctx, 0)
+	require.NoError(t, err)
+	assert.Zero(t, purged, "the open pipeline run still reads its step back")
+
+	now := time.Now()
+	pr.Status, pr.FinishedAt = script.RunStatusSucceeded, &now
+	require.NoError(t, s.UpdatePipelineRun(ctx, pr))
+	purged, err = s.PurgeRuns(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+}
+
+// TestRealDB_PurgeHonorsAScriptsOwnWindow pins the per-script retention
+// against the real schema: the same sweep that removes one script's finished
+// run keeps another's whose script asked to keep a month.
+func TestRealDB_PurgeHonorsAScriptsOwnWindow(t *testing.T) {
+	db := testdb.New(t)
+	s := New(db)
+	ctx := context.Background()
+
+	kept, keptVersion := savedScript(ctx, t, s, "monthly")
+	swept, sweptVersion := savedScript(ctx, t, s, "hourly")
+	days := 30
+	require.NoError(t, s.SetRetention(ctx, &script.Retention{ScriptID: kept.ID, RunDays: &days}))
+	for _, r := range []struct {
+		id      string
+		sc      *script.Script
+		version *script.Version
+	}{{"dpx_kept", kept, keptVersion}, {"dpx_swept", swept, sweptVersion}} {
+		require.NoError(t, s.Enqueue(ctx, &script.Run{
+			ID: r.id, ScriptID: r.sc.ID, VersionID: r.version.ID, Version: r.version.Version,
+			Trigger: script.TriggerTool,
+		}))
+		run, err := s.Claim(ctx, "worker-a", time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, s.Finish(ctx, run.Lease(), script.RunResult{Status: script.RunStatusSucceeded}))
+	}
+
+	purged, err := s.PurgeRuns(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	_, err = s.GetRun(ctx, "dpx_kept")
+	require.NoError(t, err, "the script's own month outranks the deployment's window")
+	_, err = s.GetRun(ctx, "dpx_swept")
+	assert.ErrorIs(t, err, script.ErrRunNotFound)
+}
+
 // TestRealDB_LatestRunsIsOneRowPerScript is the portal listing's read against
 // the real schema: DISTINCT ON is Postgres-specific and sqlmock validates no
 // SQL, so only a real database shows that each script yields its newest run and
@@ -21,7 +21,8 @@ var runSelectColumns = []string{
 	"id", "script_id", "script_version_id", "version", "trigger_kind", "status",
 	"params", "fire_time", "requested_by", "scheduled_for", "started_at", "finished_at", "attempt",
 	"locked_until", "locked_by", "error", "log_text", "log_truncated", "metrics", "outputs",
-	"schedule_id", "created_at", "updated_at",
+	"schedule_id", "created_at", "updated_at", "event_key",
+	"failure_kind", "quality_checks", "backfill_id",
 }
 
 // runRow returns one full run row in runColumns order.
@@ -33,7 +34,8 @@ func runRow(status string, attempt int, outputs []byte) []driver.Value { //nolin
 		"dpx_1", "script_1", "sver_1", 3, script.TriggerTool, status,
 		[]byte(`{"day":"2026-08-12"}`), rowTime, "jane@example.com", rowTime, nil, nil, attempt,
 		nil, "worker-a", "", "", false, []byte(`{"steps":10}`), outputs,
-		"", rowTime, rowTime,
+		"", rowTime, rowTime, "",
+		"", []byte("[]"), "",
 	}
 }
 
@@ -276,6 +278,9 @@ func TestLeaseFencing(t *testing.T) {
 		{"record output", "UPDATE script_runs SET outputs", func(s *Store) error {
 			return s.RecordOutput(context.Background(), testLease, script.RunOutput{Name: "daily"})
 		}, "record script run output"},
+		{"record log", "UPDATE script_runs SET log_text", func(s *Store) error {
+			return s.RecordLog(context.Background(), testLease, "rows: 3\n", false)
+		}, "record script run log"},
 		{"finish", "UPDATE script_runs", func(s *Store) error {
 			return s.Finish(context.Background(), testLease, script.RunResult{Status: script.RunStatusSucceeded})
 		}, "finish script run"},
@@ -322,7 +327,8 @@ func TestRecordOutput_AppendsInSQL(t *testing.T) {
 func TestFinish_WritesTheResultAndWakesWaiters(t *testing.T) {
 	s, mock := newMock(t)
 	mock.ExpectExec(regexp.QuoteMeta("UPDATE script_runs")).
-		WithArgs("dpx_1", "worker-a", 1, script.RunStatusFailed, "boom", "log line", false, sqlmock.AnyArg()).
+		WithArgs("dpx_1", "worker-a", 1, script.RunStatusFailed, "boom", "log line", false, sqlmock.AnyArg(),
+			"", []byte("[]")).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_notify")).
 		WithArgs(NotifyChannel, "dpx_1").WillReturnResult(sqlmock.NewResult(0, 1))
@@ -334,12 +340,38 @@ func TestFinish_WritesTheResultAndWakesWaiters(t *testing.T) {
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
+// TestFinish_RecordsADataQualityFailure pins that the failure kind and every
+// check reach the row, and that a run with checks reads them back.
+func TestFinish_RecordsADataQualityFailure(t *testing.T) {
+	s, mock := newMock(t)
+	checks := []script.QualityCheck{{Name: "row_count", Check: "row_count", Table: "hive.sales.orders", Observed: "0", Expected: ">= 1"}}
+	mock.ExpectExec(regexp.QuoteMeta("failure_kind = $9, quality_checks = $10")).
+		WithArgs("dpx_1", "worker-a", 1, script.RunStatusFailed, "data quality: 1 of 1 checks failed", "", false, sqlmock.AnyArg(),
+			script.FailureKindDataQuality, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_notify")).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, s.Finish(context.Background(), testLease, script.RunResult{
+		Status: script.RunStatusFailed, Error: "data quality: 1 of 1 checks failed",
+		FailureKind: script.FailureKindDataQuality, Checks: checks,
+	}))
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	row := runRow(script.RunStatusFailed, 1, nil)
+	row[24], row[25] = script.FailureKindDataQuality, []byte(`[{"name":"row_count","check":"row_count","table":"hive.sales.orders","passed":false,"observed":"0","expected":">= 1"}]`)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM script_runs")).WillReturnRows(sqlmock.NewRows(runSelectColumns).AddRow(row...))
+	got, err := s.GetRun(context.Background(), "dpx_1")
+	require.NoError(t, err)
+	assert.Equal(t, script.FailureKindDataQuality, got.FailureKind)
+	assert.Equal(t, checks, got.Checks)
+}
+
 // TestPurgeRuns_OnlySweepsTerminalRows pins the retention predicate: live work
 // is never swept, however old the row is, and a schedule's skipped fires age
 // out on the same clock as the runs that did execute.
 func TestPurgeRuns_OnlySweepsTerminalRows(t *testing.T) {
 	s, mock := newMock(t)
-	mock.ExpectExec(regexp.QuoteMeta("WHERE status IN ('succeeded', 'failed', 'skipped_overlap')")).
+	mock.ExpectExec(regexp.QuoteMeta("WHERE r.status IN ('succeeded', 'failed', 'skipped_overlap')")).
 		WithArgs(86400).WillReturnResult(sqlmock.NewResult(0, 7))
 
 	n, err := s.PurgeRuns(context.Background(), 24*time.Hour)
@@ -348,6 +380,31 @@ func TestPurgeRuns_OnlySweepsTerminalRows(t *testing.T) {
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
+// TestPurgeRuns_AScriptsOwnWindowWins pins that the deployment window is the
+// fallback, not the rule: a script that set its own retention is swept on it.
+func TestPurgeRuns_AScriptsOwnWindowWins(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectExec(`COALESCE\(\s*\(SELECT t\.run_retention_days \* INTERVAL '1 day'\s+FROM script_retention t`).
+		WithArgs(86400).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, err := s.PurgeRuns(context.Background(), 24*time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPurgeRuns_KeepsTheRunsOfOpenBackfillsAndPipelines pins that a finished
+// run an open backfill or pipeline run still reads back is not swept.
+func TestPurgeRuns_KeepsTheRunsOfOpenBackfillsAndPipelines(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectExec(`NOT EXISTS \(SELECT 1 FROM script_backfills b\s+WHERE b\.id = r\.backfill_id AND b\.status = 'running'\)` +
+		`\s+AND NOT EXISTS \(SELECT 1 FROM script_pipeline_runs p\s+WHERE p\.status = 'running'`).
+		WithArgs(86400).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, err := s.PurgeRuns(context.Background(), 24*time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestPurgeRuns_FailureIsWrapped(t *testing.T) {
 	s, mock := newMock(t)
 	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM script_runs")).WillReturnError(errors.New("boom"))
@@ -368,6 +425,7 @@ func TestScanRun_MalformedJSONIsReported(t *testing.T) {
 		{"params", func(row []driver.Value) { row[6] = []byte("{not json") }, "unmarshal run params"},
 		{"metrics", func(row []driver.Value) { row[18] = []byte("{not json") }, "unmarshal run metrics"},
 		{"outputs", func(row []driver.Value) { row[19] = []byte("{not json") }, "unmarshal run outputs"},
+		{"quality checks", func(row []driver.Value) { row[25] = []byte("{not json") }, "unmarshal run quality checks"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -28,7 +28,7 @@ const defaultDueLimit = 100
 // mirrored by scanSchedule so the scan order cannot drift from the query.
 const scheduleColumns = `id, script_id, cron_spec, timezone, params, enabled,
 	next_run_at, last_fire_at, missed_fires, created_by, updated_by,
-	created_at, updated_at`
+	created_at, updated_at, trigger, event_cursor`
 
 // scheduleSelect is the base SELECT for the schedule columns.
 const scheduleSelect = "SELECT " + scheduleColumns + " FROM script_schedules"
@@ -36,20 +36,30 @@ const scheduleSelect = "SELECT " + scheduleColumns + " FROM script_schedules"
 // scanSchedule reads one row in scheduleColumns order into a Schedule.
 func scanSchedule(sc rowScanner) (*script.Schedule, error) {
 	s := &script.Schedule{}
-	var paramsJSON []byte
+	var paramsJSON, triggerJSON []byte
 	var nextRunAt sql.NullTime
+	var cursor sql.NullString
 	err := sc.Scan(&s.ID, &s.ScriptID, &s.CronSpec, &s.Timezone, &paramsJSON, &s.Enabled,
 		&nextRunAt, &s.LastFireAt, &s.MissedFires, &s.CreatedBy, &s.UpdatedBy,
-		&s.CreatedAt, &s.UpdatedAt)
+		&s.CreatedAt, &s.UpdatedAt, &triggerJSON, &cursor)
 	if err != nil {
 		return nil, fmt.Errorf("scanning script schedule row: %w", err)
 	}
 	if nextRunAt.Valid {
 		s.NextRunAt = nextRunAt.Time
 	}
+	if cursor.Valid {
+		s.EventCursor = &cursor.String
+	}
 	if err := json.Unmarshal(paramsJSON, &s.Params); err != nil {
 		return nil, fmt.Errorf("unmarshal schedule params: %w", err)
 	}
+	if len(triggerJSON) > 0 {
+		s.Trigger = &script.Trigger{}
+		if err := json.Unmarshal(triggerJSON, s.Trigger); err != nil {
+			return nil, fmt.Errorf("unmarshal schedule trigger: %w", err)
+		}
+	}
 	return s, nil
 }
 
@@ -60,23 +70,35 @@ func scanSchedule(sc rowScanner) (*script.Schedule, error) {
 // depend on whether one already exists. Replacing keeps the id and the creation
 // stamp, so a schedule's identity — and the runs that point at it — survive an
 // edit of its cadence.
+//
+// The event cursor is written as the caller computed it: BuildSchedule keeps it
+// across an edit that watches the same source and drops it otherwise.
 func (s *Store) SetSchedule(ctx context.Context, sched *script.Schedule) error {
 	params, err := json.Marshal(orEmptyParams(sched.Params))
 	if err != nil {
 		return fmt.Errorf("marshal schedule params: %w", err)
 	}
+	// A cron schedule binds SQL NULL, not an empty byte slice, which JSONB
+	// would refuse as invalid input.
+	var trigger any
+	if sched.Trigger != nil {
+		if trigger, err = json.Marshal(sched.Trigger); err != nil {
+			return fmt.Errorf("marshal schedule trigger: %w", err)
+		}
+	}
 	row := s.db.QueryRowContext(ctx, `
 		INSERT INTO script_schedules (script_id, cron_spec, timezone, params, enabled,
-		                              next_run_at, created_by, updated_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		                              next_run_at, created_by, updated_by, trigger, event_cursor)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7, $8, $9)
 		ON CONFLICT (script_id) DO UPDATE
 		   SET cron_spec = EXCLUDED.cron_spec, timezone = EXCLUDED.timezone,
 		       params = EXCLUDED.params, enabled = EXCLUDED.enabled,
 		       next_run_at = EXCLUDED.next_run_at, updated_by = EXCLUDED.updated_by,
+		       trigger = EXCLUDED.trigger, event_cursor = EXCLUDED.event_cursor,
 		       updated_at = NOW()
 		RETURNING id, created_at, updated_at`,
 		sched.ScriptID, sched.CronSpec, sched.Timezone, params, sched.Enabled,
-		orNilTime(sched.NextRunAt), sched.UpdatedBy)
+		orNilTime(sched.NextRunAt), sched.UpdatedBy, trigger, sched.EventCursor)
 	if err := row.Scan(&sched.ID, &sched.CreatedAt, &sched.UpdatedAt); err != nil {
 		return fmt.Errorf("set script schedule: %w", err)
 	}
@@ -195,6 +217,15 @@ func (s *Store) DueSchedules(ctx context.Context, now time.Time, limit int) ([]s
 //     The skip is then recorded as its own terminal row, through the same
 //     conflict-tolerant insert, so two replicas racing to record it also
 //     produce exactly one.
+//
+// An event run (one with an EventKey) is told apart by its observation rather
+// than its fire time, and an overlap records nothing: the fire is reported
+// deferred, and the caller leaves the observation unconsumed so it fires once
+// the open run ends. See script.MaterializedDeferred.
+//
+// A backfill run (one with a BackfillID) answers to neither schedule index: its
+// one unique key is (backfill, fire time), so a conflict can only mean another
+// replica materialized the same fire of the same backfill.
 func (s *Store) MaterializeRun(ctx context.Context, r *script.Run) (script.Materialization, error) {
 	inserted, err := s.insertScheduledRun(ctx, r, script.RunStatusPending)
 	if err != nil {
@@ -206,6 +237,19 @@ func (s *Store) MaterializeRun(ctx context.Context, r *script.Run) (script.Mater
 		_, _ = s.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, NotifyChannel, r.ID)
 		return script.MaterializedRun, nil
 	}
+	if r.BackfillID != "" {
+		return script.MaterializedDuplicate, nil
+	}
+	if r.EventKey != "" {
+		taken, err := s.eventTaken(ctx, r.ScheduleID, r.EventKey)
+		if err != nil {
+			return "", err
+		}
+		if taken {
+			return script.MaterializedDuplicate, nil
+		}
+		return script.MaterializedDeferred, nil
+	}
 	taken, err := s.fireTaken(ctx, r.ScheduleID, r.FireTime)
 	if err != nil {
 		return "", err
@@ -238,16 +282,20 @@ func (s *Store) insertScheduledRun(ctx context.Context, r *script.Run, status st
 	if err != nil {
 		return false, fmt.Errorf("marshal run params: %w", err)
 	}
+	trigger := r.Trigger
+	if trigger == "" {
+		trigger = script.TriggerSchedule
+	}
 	row := s.db.QueryRowContext(ctx, `
 		INSERT INTO script_runs (id, script_id, script_version_id, version, trigger_kind,
 		                         status, params, requested_by, fire_time, scheduled_for,
-		                         schedule_id, error, finished_at)
+		                         schedule_id, error, finished_at, event_key, backfill_id)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9, $10, $11,
-		        CASE WHEN $6 = 'skipped_overlap' THEN NOW() END)
+		        CASE WHEN $6 = 'skipped_overlap' THEN NOW() END, NULLIF($12, ''), NULLIF($13, '')::uuid)
 		ON CONFLICT DO NOTHING
 		RETURNING created_at, updated_at`,
-		r.ID, r.ScriptID, r.VersionID, r.Version, script.TriggerSchedule,
-		status, params, r.RequestedBy, r.FireTime, r.ScheduleID, overlapReason(status))
+		r.ID, r.ScriptID, r.VersionID, r.Version, trigger,
+		status, params, r.RequestedBy, r.FireTime, r.ScheduleID, overlapReason(status), r.EventKey, r.BackfillID)
 	err = row.Scan(&r.CreatedAt, &r.UpdatedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return false, nil
@@ -274,7 +322,8 @@ func overlapReason(status string) string {
 func (s *Store) fireTaken(ctx context.Context, scheduleID string, fire time.Time) (bool, error) {
 	var exists bool
 	err := s.db.QueryRowContext(ctx, `
-		SELECT EXISTS (SELECT 1 FROM script_runs WHERE schedule_id = $1 AND fire_time = $2)`,
+		SELECT EXISTS (SELECT 1 FROM script_runs
+		                WHERE schedule_id = $1 AND fire_time = $2 AND backfill_id IS NULL)`,
 		scheduleID, fire).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("checking a materialized fire: %w", err)
@@ -282,6 +331,19 @@ func (s *Store) fireTaken(ctx context.Context, scheduleID string, fire time.Time
 	return exists, nil
 }
 
+// eventTaken reports whether a run already exists for a schedule's observation,
+// the event counterpart of fireTaken.
+func (s *Store) eventTaken(ctx context.Context, scheduleID, key string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM script_runs WHERE schedule_id = $1 AND event_key = $2)`,
+		scheduleID, key).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("checking a materialized event: %w", err)
+	}
+	return exists, nil
+}
+
 // AdvanceSchedule moves a schedule forward, only if it is still where the
 // caller found it.
 //
@@ -296,9 +358,10 @@ func (s *Store) AdvanceSchedule(ctx context.Context, adv script.ScheduleAdvance)
 		   SET next_run_at = $3,
 		       last_fire_at = COALESCE($4, last_fire_at),
 		       missed_fires = missed_fires + $5,
+		       event_cursor = COALESCE($6, event_cursor),
 		       updated_at = NOW()
 		 WHERE id = $1 AND next_run_at = $2`,
-		adv.ID, adv.From, orNilTime(adv.Next), orNilTime(adv.Fired), adv.Missed)
+		adv.ID, adv.From, orNilTime(adv.Next), orNilTime(adv.Fired), adv.Missed, adv.Cursor)
 	if err != nil {
 		return false, fmt.Errorf("advance script schedule: %w", err)
 	}
@@ -21,7 +21,7 @@ import (
 var scheduleSelectColumns = []string{
 	"id", "script_id", "cron_spec", "timezone", "params", "enabled",
 	"next_run_at", "last_fire_at", "missed_fires", "created_by", "updated_by",
-	"created_at", "updated_at",
+	"created_at", "updated_at", "trigger", "event_cursor",
 }
 
 // scheduleRow returns one full schedule row in scheduleColumns order.
@@ -30,7 +30,7 @@ func scheduleRow(nextRunAt any) []driver.Value {
 		"sched_1", "script_1", "0 7 * * 1-5", "America/Los_Angeles",
 		[]byte(`{"report_date":"${fire_date}"}`), true,
 		nextRunAt, nil, 0, "jane@example.com", "jane@example.com",
-		rowTime, rowTime,
+		rowTime, rowTime, nil, nil,
 	}
 }
 
@@ -63,7 +63,7 @@ func TestSetSchedule_UpsertsOnTheScript(t *testing.T) {
 func TestSetSchedule_AZeroNextFireBindsNull(t *testing.T) {
 	s, mock := newMock(t)
 	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO script_schedules")).
-		WithArgs("script_1", "@daily", "UTC", []byte(`{}`), true, nil, "").
+		WithArgs("script_1", "@daily", "UTC", []byte(`{}`), true, nil, "", nil, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
 			AddRow("sched_1", rowTime, rowTime))
 
@@ -269,13 +269,129 @@ func TestMaterializeRun(t *testing.T) {
 	})
 }
 
+// A backfill run conflicts only with the same fire of the same backfill, so a
+// conflict is never an overlap and needs no second look.
+func TestMaterializeRun_Backfill(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO script_runs")).
+		WithArgs("dpx_1", "script_1", "sver_1", 3, script.TriggerBackfill, script.RunStatusPending,
+			[]byte(`{}`), "", rowTime, "sched_1", "", "", "bf_1").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}))
+
+	run := materializing()
+	run.Trigger, run.BackfillID = script.TriggerBackfill, "bf_1"
+	outcome, err := s.MaterializeRun(context.Background(), run)
+	require.NoError(t, err)
+	assert.Equal(t, script.MaterializedDuplicate, outcome)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// An event fire is told apart by its observation, and an overlap defers it
+// rather than recording a skip: a change that is skipped may never come again.
+func TestMaterializeRun_Event(t *testing.T) {
+	event := func() *script.Run {
+		r := materializing()
+		r.Trigger, r.EventKey = script.TriggerEvent, "2026-08-14T11:00:00Z sales/a.csv"
+		return r
+	}
+
+	t.Run("the insert carries the trigger and the observation", func(t *testing.T) {
+		s, mock := newMock(t)
+		mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO script_runs")).
+			WithArgs("dpx_1", "script_1", "sver_1", 3, script.TriggerEvent, script.RunStatusPending,
+				[]byte(`{}`), "", rowTime, "sched_1", "", "2026-08-14T11:00:00Z sales/a.csv", "").
+			WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(rowTime, rowTime))
+		mock.ExpectExec(regexp.QuoteMeta("SELECT pg_notify")).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		outcome, err := s.MaterializeRun(context.Background(), event())
+		require.NoError(t, err)
+		assert.Equal(t, script.MaterializedRun, outcome)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("a conflict on the same observation is another replica", func(t *testing.T) {
+		s, mock := newMock(t)
+		mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO script_runs")).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}))
+		mock.ExpectQuery(regexp.QuoteMeta("event_key = $2")).
+			WithArgs("sched_1", "2026-08-14T11:00:00Z sales/a.csv").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		outcome, err := s.MaterializeRun(context.Background(), event())
+		require.NoError(t, err)
+		assert.Equal(t, script.MaterializedDuplicate, outcome)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("an open run defers the fire and records nothing", func(t *testing.T) {
+		s, mock := newMock(t)
+		mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO script_runs")).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}))
+		mock.ExpectQuery(regexp.QuoteMeta("event_key = $2")).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		outcome, err := s.MaterializeRun(context.Background(), event())
+		require.NoError(t, err)
+		assert.Equal(t, script.MaterializedDeferred, outcome)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("a failed observation lookup is wrapped", func(t *testing.T) {
+		s, mock := newMock(t)
+		mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO script_runs")).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}))
+		mock.ExpectQuery(regexp.QuoteMeta("event_key = $2")).WillReturnError(errors.New("boom"))
+
+		_, err := s.MaterializeRun(context.Background(), event())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "checking a materialized event")
+	})
+}
+
+// An event schedule round-trips its trigger and its cursor, including the
+// difference between never observed (NULL) and observed empty (”).
+func TestGetSchedule_EventSchedule(t *testing.T) {
+	s, mock := newMock(t)
+	row := scheduleRow(rowTime)
+	row[2] = ""
+	row[13] = []byte(`{"kind":"s3_object","bucket":"landing","prefix":"sales/"}`)
+	row[14] = ""
+	mock.ExpectQuery(regexp.QuoteMeta("FROM script_schedules")).
+		WillReturnRows(sqlmock.NewRows(scheduleSelectColumns).AddRow(row...))
+
+	sched, err := s.GetSchedule(context.Background(), "script_1")
+	require.NoError(t, err)
+	require.NotNil(t, sched.Trigger)
+	assert.Equal(t, script.TriggerS3Object, sched.Trigger.Kind)
+	assert.Equal(t, "sales/", sched.Trigger.Prefix)
+	require.NotNil(t, sched.EventCursor, "observed empty is not never observed")
+	assert.Empty(t, *sched.EventCursor)
+}
+
+func TestSetSchedule_WritesTheTrigger(t *testing.T) {
+	s, mock := newMock(t)
+	cursor := "seen"
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO script_schedules")).
+		WithArgs("script_1", "", "UTC", []byte(`{}`), true, rowTime, "",
+			[]byte(`{"kind":"script_run","script_id":"script_2"}`), "seen").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+			AddRow("sched_1", rowTime, rowTime))
+
+	require.NoError(t, s.SetSchedule(context.Background(), &script.Schedule{
+		ScriptID: "script_1", Timezone: "UTC", Enabled: true, NextRunAt: rowTime,
+		Trigger:     &script.Trigger{Kind: script.TriggerScriptRun, ScriptID: "script_2"},
+		EventCursor: &cursor,
+	}))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestAdvanceSchedule(t *testing.T) {
 	next := rowTime.Add(time.Hour)
 
 	t.Run("moves the row it found", func(t *testing.T) {
 		s, mock := newMock(t)
 		mock.ExpectExec(regexp.QuoteMeta("UPDATE script_schedules")).
-			WithArgs("sched_1", rowTime, next, rowTime, 2).
+			WithArgs("sched_1", rowTime, next, rowTime, 2, nil).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
 		moved, err := s.AdvanceSchedule(context.Background(), script.ScheduleAdvance{
@@ -296,10 +412,24 @@ func TestAdvanceSchedule(t *testing.T) {
 		assert.False(t, moved)
 	})
 
+	t.Run("an event schedule stores its observation", func(t *testing.T) {
+		s, mock := newMock(t)
+		cursor := "seen"
+		mock.ExpectExec(regexp.QuoteMeta("event_cursor = COALESCE($6, event_cursor)")).
+			WithArgs("sched_1", rowTime, next, nil, 0, "seen").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err := s.AdvanceSchedule(context.Background(), script.ScheduleAdvance{
+			ID: "sched_1", From: rowTime, Next: next, Cursor: &cursor,
+		})
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("a parked schedule stores a null next fire", func(t *testing.T) {
 		s, mock := newMock(t)
 		mock.ExpectExec(regexp.QuoteMeta("UPDATE script_schedules")).
-			WithArgs("sched_1", rowTime, nil, nil, 0).
+			WithArgs("sched_1", rowTime, nil, nil, 0, nil).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
 		_, err := s.AdvanceSchedule(context.Background(), script.ScheduleAdvance{ID: "sched_1", From: rowTime})
@@ -55,7 +55,7 @@ func New(db *sql.DB, opts ...indexjobs.StoreOption) *Store {
 // place so the scan order in scanScript cannot drift from the query.
 const scriptColumns = `id, name, display_name, description, category, source_code, params,
 	owner_email, tags, enabled, status, superseded_by,
-	deprecated_at, version, created_at, updated_at`
+	deprecated_at, version, created_at, updated_at, library, loads`
 
 // scriptSelect is the base SELECT for the script columns.
 const scriptSelect = "SELECT " + scriptColumns + " FROM scripts"
@@ -68,17 +68,20 @@ type rowScanner interface {
 // scanScript reads one row in scriptColumns order into a Script.
 func scanScript(sc rowScanner) (*script.Script, error) {
 	s := &script.Script{}
-	var paramsJSON []byte
+	var paramsJSON, loadsJSON []byte
 	err := sc.Scan(&s.ID, &s.Name, &s.DisplayName, &s.Description, &s.Category, &s.Source, &paramsJSON,
 		&s.OwnerEmail, pq.Array(&s.Tags), &s.Enabled,
 		&s.Status, &s.SupersededBy, &s.DeprecatedAt, &s.Version,
-		&s.CreatedAt, &s.UpdatedAt)
+		&s.CreatedAt, &s.UpdatedAt, &s.Library, &loadsJSON)
 	if err != nil {
 		return nil, fmt.Errorf("scanning script row: %w", err)
 	}
 	if err := json.Unmarshal(paramsJSON, &s.Params); err != nil {
 		return nil, fmt.Errorf("unmarshal script params: %w", err)
 	}
+	if err := unmarshalLoads(loadsJSON, &s.Loads); err != nil {
+		return nil, err
+	}
 	normalizeSlices(s)
 	return s, nil
 }
@@ -93,6 +96,9 @@ func normalizeSlices(s *script.Script) {
 	if s.Tags == nil {
 		s.Tags = []string{}
 	}
+	if s.Loads == nil {
+		s.Loads = []script.Dependency{}
+	}
 }
 
 // withTx runs fn inside a transaction, rolling back on error. op names the
@@ -114,6 +120,10 @@ func (s *Store) withTx(ctx context.Context, op string, fn func(tx *sql.Tx) error
 
 // Create persists a new script and its v1 snapshot in one transaction, so a
 // script never exists without the version history that explains it.
+//
+// The libraries the source loads are resolved in the same transaction
+// (resolveLoads), and a library whose name another library already has is
+// refused with script.ErrDependency.
 func (s *Store) Create(ctx context.Context, sc *script.Script, author script.Author) error {
 	normalizeSlices(sc)
 	paramsJSON, err := json.Marshal(sc.Params)
@@ -125,14 +135,24 @@ func (s *Store) Create(ctx context.Context, sc *script.Script, author script.Aut
 	}
 	sc.Version = 1
 	if err := s.withTx(ctx, "create script", func(tx *sql.Tx) error {
+		if err := resolveLoads(ctx, tx, sc.Loads); err != nil {
+			return err
+		}
+		loadsJSON, err := json.Marshal(sc.Loads)
+		if err != nil {
+			return fmt.Errorf("marshal script loads: %w", err)
+		}
 		row := tx.QueryRowContext(ctx, `
 			INSERT INTO scripts (name, display_name, description, category, source_code, params,
-			                     owner_email, tags, enabled, status, version)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, 1)
+			                     owner_email, tags, enabled, status, version, library, loads)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, 1, $11, $12)
 			RETURNING id, created_at, updated_at`,
 			sc.Name, sc.DisplayName, sc.Description, sc.Category, sc.Source, paramsJSON,
-			sc.OwnerEmail, pq.Array(sc.Tags), sc.Enabled, sc.Status)
+			sc.OwnerEmail, pq.Array(sc.Tags), sc.Enabled, sc.Status, sc.Library, loadsJSON)
 		if err := row.Scan(&sc.ID, &sc.CreatedAt, &sc.UpdatedAt); err != nil {
+			if isLibraryNameTaken(err) {
+				return fmt.Errorf("%w: another library is already named %q", script.ErrDependency, sc.Name)
+			}
 			return fmt.Errorf("insert script: %w", err)
 		}
 		return insertVersionRow(ctx, tx, versionInsert{
@@ -235,6 +255,10 @@ func updateTx(ctx context.Context, tx *sql.Tx, sc *script.Script) (bool, error)
 	if err != nil {
 		return false, fmt.Errorf("marshal script params: %w", err)
 	}
+	loadsJSON, err := json.Marshal(sc.Loads)
+	if err != nil {
+		return false, fmt.Errorf("marshal script loads: %w", err)
+	}
 	// #nosec G201 -- the only interpolation is a constant parameter index into
 	// constant SQL fragments; every value is bound.
 	q := `
@@ -243,7 +267,7 @@ func updateTx(ctx context.Context, tx *sql.Tx, sc *script.Script) (bool, error)
 		       source_code = $6, params = $7,
 		       owner_email = $8, tags = $9, enabled = $10, status = $11,
 		       superseded_by = $12, deprecated_at = $13, version = $14,
-		       updated_at = NOW()` +
+		       loads = $15, updated_at = NOW()` +
 		fmt.Sprintf(indexInvalidation, updateHashParam) +
 		"\n\t\t WHERE id = $1" +
 		fmt.Sprintf(indexTextChanged, updateHashParam)
@@ -251,7 +275,7 @@ func updateTx(ctx context.Context, tx *sql.Tx, sc *script.Script) (bool, error)
 	err = tx.QueryRowContext(ctx, q,
 		sc.ID, sc.Name, sc.DisplayName, sc.Description, sc.Category, sc.Source, paramsJSON,
 		sc.OwnerEmail, pq.Array(sc.Tags),
-		sc.Enabled, sc.Status, sc.SupersededBy, sc.DeprecatedAt, sc.Version,
+		sc.Enabled, sc.Status, sc.SupersededBy, sc.DeprecatedAt, sc.Version, loadsJSON,
 		indexjobs.TextHash(script.IndexText(sc))).Scan(&changed)
 	if errors.Is(err, sql.ErrNoRows) {
 		return false, fmt.Errorf("script %s not found", sc.ID)
@@ -264,18 +288,36 @@ func updateTx(ctx context.Context, tx *sql.Tx, sc *script.Script) (bool, error)
 
 // updateHashParam is updateTx's placeholder index for the new text hash, one
 // past its last column value.
-const updateHashParam = 15
+const updateHashParam = 16
 
 // Delete removes a script by ID. Its versions cascade.
+//
+// A library that a script still loads is refused with script.ErrDependency:
+// deleting it would leave that script failing at its load. The library row is
+// locked first, and a save resolving a load takes a share lock on the same row
+// (resolveLoads), so a load saved concurrently is either seen here or refused
+// there.
 func (s *Store) Delete(ctx context.Context, id string) error {
-	res, err := s.db.ExecContext(ctx, `DELETE FROM scripts WHERE id = $1`, id)
-	if err != nil {
-		return fmt.Errorf("delete script: %w", err)
-	}
-	if n, _ := res.RowsAffected(); n == 0 {
-		return fmt.Errorf("script %s not found", id)
-	}
-	return nil
+	return s.withTx(ctx, "delete script", func(tx *sql.Tx) error {
+		var library bool
+		err := tx.QueryRowContext(ctx,
+			`SELECT library FROM scripts WHERE id = $1 FOR UPDATE`, id).Scan(&library)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("script %s not found", id)
+		}
+		if err != nil {
+			return fmt.Errorf("delete script: %w", err)
+		}
+		if library {
+			if err := refuseLoadedLibrary(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM scripts WHERE id = $1`, id); err != nil {
+			return fmt.Errorf("delete script: %w", err)
+		}
+		return nil
+	})
 }
 
 // List returns scripts matching the filter, newest first.
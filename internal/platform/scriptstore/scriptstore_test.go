@@ -30,7 +30,7 @@ var scriptSelectColumns = []string{
 	"id", "name", "display_name", "description", "category", "source_code", "params",
 	"owner_email", "tags", "enabled", "status",
 	"superseded_by", "deprecated_at", "version",
-	"created_at", "updated_at",
+	"created_at", "updated_at", "library", "loads",
 }
 
 var rowTime = time.Unix(1700000000, 0).UTC()
@@ -54,7 +54,7 @@ func scriptRow(spec rowSpec) []driver.Value {
 	return []driver.Value{
 		spec.id, spec.name, "Daily", "A daily report", spec.category, source, spec.paramsJSON,
 		spec.owner, pq.Array([]string{}), true, "active",
-		"", nil, 1, rowTime, rowTime,
+		"", nil, 1, rowTime, rowTime, false, []byte(`[]`),
 	}
 }
 
@@ -200,20 +200,33 @@ func TestUpdate_MissingRowIsAnError(t *testing.T) {
 
 func TestDelete(t *testing.T) {
 	s, mock := newMock(t)
+	expectDeleteLock(mock, "script_1", false)
 	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM scripts")).WithArgs("script_1").
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 	require.NoError(t, s.Delete(context.Background(), "script_1"))
 
-	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM scripts")).WithArgs("gone").
-		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT library FROM scripts")).WithArgs("gone").
+		WillReturnRows(sqlmock.NewRows([]string{"library"}))
+	mock.ExpectRollback()
 	assert.ErrorContains(t, s.Delete(context.Background(), "gone"), "not found")
 
+	expectDeleteLock(mock, "bad", false)
 	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM scripts")).WithArgs("bad").
 		WillReturnError(errors.New("boom"))
+	mock.ExpectRollback()
 	assert.ErrorContains(t, s.Delete(context.Background(), "bad"), "delete script")
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
+// expectDeleteLock queues the transaction and row lock Delete starts with.
+func expectDeleteLock(mock sqlmock.Sqlmock, id string, library bool) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT library FROM scripts WHERE id = $1 FOR UPDATE")).
+		WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"library"}).AddRow(library))
+}
+
 // TestBuildListQuery covers the filter assembly directly, including the
 // three-column search clause whose placeholder index is repeated.
 func TestBuildListQuery(t *testing.T) {
@@ -303,7 +303,8 @@ func (s scoreTrailingScanner) Scan(dest ...any) error {
 
 // Contract composes the contract document for one script: the live record and
 // its parameter contract, the cadence when it has one, and the last successful
-// run with what it produced.
+// run with what it produced. A library's contract also names the scripts that
+// load it.
 //
 // Returns nil, nil when no such script exists. A missing schedule is not an
 // error (most scripts have none), and neither is a script that has never
@@ -328,6 +329,11 @@ func (s *Store) Contract(ctx context.Context, id string) (*script.Contract, erro
 		return nil, err
 	}
 	c := script.BuildContract(sc, sched, lastRun)
+	if sc.Library {
+		if c.LoadedBy, err = s.Dependents(ctx, id); err != nil {
+			return nil, err
+		}
+	}
 	return &c, nil
 }
 
@@ -16,7 +16,7 @@ import (
 // mirrored by scanVersion so the scan order cannot drift from the query.
 const versionColumns = `id, script_id, version, display_name, description,
 	category, source_code, params, tags, author, author_roles, status,
-	created_at`
+	created_at, loads`
 
 // versionSelect is the base SELECT for the version columns.
 const versionSelect = "SELECT " + versionColumns + " FROM script_versions"
@@ -24,16 +24,19 @@ const versionSelect = "SELECT " + versionColumns + " FROM script_versions"
 // scanVersion reads one row in versionColumns order into a Version.
 func scanVersion(sc rowScanner) (*script.Version, error) {
 	v := &script.Version{}
-	var paramsJSON []byte
+	var paramsJSON, loadsJSON []byte
 	err := sc.Scan(&v.ID, &v.ScriptID, &v.Version, &v.DisplayName, &v.Description,
 		&v.Category, &v.Source, &paramsJSON, pq.Array(&v.Tags), &v.Author,
-		pq.Array(&v.AuthorRoles), &v.Status, &v.CreatedAt)
+		pq.Array(&v.AuthorRoles), &v.Status, &v.CreatedAt, &loadsJSON)
 	if err != nil {
 		return nil, fmt.Errorf("scanning script version row: %w", err)
 	}
 	if err := json.Unmarshal(paramsJSON, &v.Params); err != nil {
 		return nil, fmt.Errorf("unmarshal version params: %w", err)
 	}
+	if err := unmarshalLoads(loadsJSON, &v.Loads); err != nil {
+		return nil, err
+	}
 	if v.Params == nil {
 		v.Params = []script.Param{}
 	}
@@ -66,6 +69,14 @@ func insertVersionRow(ctx context.Context, tx *sql.Tx, ins versionInsert) error
 	if err != nil {
 		return fmt.Errorf("marshal version params: %w", err)
 	}
+	loads := ins.Snapshot.Loads
+	if loads == nil {
+		loads = []script.Dependency{}
+	}
+	loadsJSON, err := json.Marshal(loads)
+	if err != nil {
+		return fmt.Errorf("marshal version loads: %w", err)
+	}
 	tags := ins.Snapshot.Tags
 	if tags == nil {
 		tags = []string{}
@@ -77,11 +88,11 @@ func insertVersionRow(ctx context.Context, tx *sql.Tx, ins versionInsert) error
 	_, err = tx.ExecContext(ctx, `
 		INSERT INTO script_versions (script_id, version, display_name, description,
 		                             category, source_code, params, tags, author,
-		                             author_roles, status)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		                             author_roles, status, loads)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
 		ins.ScriptID, ins.Version, ins.Snapshot.DisplayName, ins.Snapshot.Description,
 		ins.Snapshot.Category, ins.Snapshot.Source, paramsJSON, pq.Array(tags),
-		ins.Author.Email, pq.Array(roles), ins.Status)
+		ins.Author.Email, pq.Array(roles), ins.Status, loadsJSON)
 	if err != nil {
 		return fmt.Errorf("insert script version: %w", err)
 	}
@@ -118,7 +129,8 @@ func nextVersionNumber(ctx context.Context, tx *sql.Tx, scriptID string) (int, e
 
 // UpdateWithVersion persists sc like Update and, when any versioned snapshot
 // field changed against the stored row, records a new applied version authored
-// by author and advances sc.Version to it.
+// by author and advances sc.Version to it. The libraries the source loads are
+// resolved in the same transaction, refusing with script.ErrDependency.
 func (s *Store) UpdateWithVersion(ctx context.Context, sc *script.Script, author script.Author) error {
 	normalizeSlices(sc)
 	var indexed bool
@@ -127,6 +139,9 @@ func (s *Store) UpdateWithVersion(ctx context.Context, sc *script.Script, author
 		if err != nil {
 			return err
 		}
+		if err := resolveLoads(ctx, tx, sc.Loads); err != nil {
+			return err
+		}
 		if err := snapshotIfMoved(ctx, tx, before, sc, author); err != nil {
 			return err
 		}
@@ -212,3 +227,130 @@ func (s *Store) GetVersionByID(ctx context.Context, id string) (*script.Version,
 	}
 	return v, nil
 }
+
+// libraryNameIndex is the partial unique index that keeps library names unique
+// across owners, because a load names a library by name alone.
+const libraryNameIndex = "idx_scripts_library_name"
+
+// isLibraryNameTaken reports whether err is a collision on libraryNameIndex.
+func isLibraryNameTaken(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && string(pqErr.Code) == pgUniqueViolation &&
+		pqErr.Constraint == libraryNameIndex
+}
+
+// unmarshalLoads reads a loads column, normalizing an empty one to [].
+func unmarshalLoads(data []byte, into *[]script.Dependency) error {
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, into); err != nil {
+			return fmt.Errorf("unmarshal script loads: %w", err)
+		}
+	}
+	if *into == nil {
+		*into = []script.Dependency{}
+	}
+	return nil
+}
+
+// resolveLoads binds each load to the library it names, setting its ScriptID,
+// and refuses one that names no library or a version that library does not
+// have.
+//
+// Each library row is share-locked for the caller's transaction, which is what
+// keeps a concurrent Delete from removing a library between this check and the
+// commit that records the load.
+func resolveLoads(ctx context.Context, tx *sql.Tx, loads []script.Dependency) error {
+	for i := range loads {
+		d := &loads[i]
+		err := tx.QueryRowContext(ctx, `
+			SELECT s.id FROM scripts s
+			 WHERE s.library AND s.name = $1
+			   AND EXISTS (SELECT 1 FROM script_versions v
+			                WHERE v.script_id = s.id AND v.version = $2)
+			   FOR SHARE OF s`, d.Name, d.Version).Scan(&d.ScriptID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: load(%q) names no library with that version", script.ErrDependency, d.Module())
+		}
+		if err != nil {
+			return fmt.Errorf("resolve library %s: %w", d.Module(), err)
+		}
+	}
+	return nil
+}
+
+// loadedBy is the containment argument that matches every scripts.loads array
+// holding an edge to the library, the shape the GIN index answers.
+func loadedBy(libraryID string) (string, error) {
+	data, err := json.Marshal([]map[string]string{{"script_id": libraryID}})
+	if err != nil {
+		return "", fmt.Errorf("marshal library edge: %w", err)
+	}
+	return string(data), nil
+}
+
+// refuseLoadedLibrary refuses the deletion of a library some script loads.
+func refuseLoadedLibrary(ctx context.Context, tx *sql.Tx, libraryID string) error {
+	arg, err := loadedBy(libraryID)
+	if err != nil {
+		return err
+	}
+	var name string
+	err = tx.QueryRowContext(ctx, `
+		SELECT name FROM scripts WHERE loads @> $1::jsonb ORDER BY name LIMIT 1`, arg).Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read library dependents: %w", err)
+	}
+	return fmt.Errorf("%w: script %q still loads this library; move its load off first", script.ErrDependency, name)
+}
+
+// LibrarySource returns the source of the named library at one version. It is
+// the run's load resolver (scriptlib.Resolver): the engine calls it for every
+// load() a run executes.
+func (s *Store) LibrarySource(ctx context.Context, name string, version int) (string, error) {
+	var src string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT v.source_code
+		  FROM scripts s JOIN script_versions v ON v.script_id = s.id
+		 WHERE s.library AND s.name = $1 AND v.version = $2`, name, version).Scan(&src)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("no library %q has version %d", name, version)
+	}
+	if err != nil {
+		return "", fmt.Errorf("read library source: %w", err)
+	}
+	return src, nil
+}
+
+// Dependents returns the scripts that load a library, each with the version of
+// it their source pins, ordered by name.
+func (s *Store) Dependents(ctx context.Context, libraryID string) ([]script.Dependency, error) {
+	arg, err := loadedBy(libraryID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT s.id, s.name, (l->>'version')::int
+		  FROM scripts s, jsonb_array_elements(s.loads) l
+		 WHERE s.loads @> $1::jsonb AND l->>'script_id' = $2
+		 ORDER BY s.name, 3`, arg, libraryID)
+	if err != nil {
+		return nil, fmt.Errorf("list library dependents: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []script.Dependency{}
+	for rows.Next() {
+		var d script.Dependency
+		if err := rows.Scan(&d.ScriptID, &d.Name, &d.Version); err != nil {
+			return nil, fmt.Errorf("scanning library dependent: %w", err)
+		}
+		out = append(out, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate library dependents: %w", err)
+	}
+	return out, nil
+}
@@ -20,7 +20,7 @@ import (
 var versionSelectColumns = []string{
 	"id", "script_id", "version", "display_name", "description", "category",
 	"source_code", "params", "tags", "author", "author_roles", "status",
-	"created_at",
+	"created_at", "loads",
 }
 
 // versionRow returns one full version row in versionColumns order.
@@ -28,7 +28,7 @@ func versionRow(version int, source, status string, paramsJSON []byte) []driver.
 	return []driver.Value{
 		"sver_1", "script_1", version, "Daily", "A daily report", "",
 		source, paramsJSON, pq.Array([]string{}), "jane@example.com",
-		pq.Array([]string{"analyst"}), status, rowTime,
+		pq.Array([]string{"analyst"}), status, rowTime, []byte(`[]`),
 	}
 }
 
@@ -238,3 +238,109 @@ func TestGetVersionByID(t *testing.T) {
 	assert.ErrorContains(t, err, "get script version by id")
 	require.NoError(t, mock.ExpectationsWereMet())
 }
+
+// TestCreate_ResolvesEachLoadToALibraryVersion proves a load is bound to the
+// library it names in the saving transaction, and that one naming no such
+// library version refuses the save as the author's to fix.
+func TestCreate_ResolvesEachLoadToALibraryVersion(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR SHARE OF s")).WithArgs("fiscal", 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("lib_1"))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO scripts")).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			false, []byte(`[{"script_id":"lib_1","name":"fiscal","version":3}]`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+			AddRow("script_1", rowTime, rowTime))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO script_versions")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	sc := &script.Script{Name: "daily", Source: "print(1)",
+		Loads: []script.Dependency{{Name: "fiscal", Version: 3}}}
+	require.NoError(t, s.Create(context.Background(), sc, testAuthor))
+	assert.Equal(t, "lib_1", sc.Loads[0].ScriptID)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR SHARE OF s")).WithArgs("fiscal", 9).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+	err := s.Create(context.Background(), &script.Script{Name: "weekly",
+		Loads: []script.Dependency{{Name: "fiscal", Version: 9}}}, testAuthor)
+	require.ErrorIs(t, err, script.ErrDependency)
+	assert.Contains(t, err.Error(), `load("fiscal@9")`)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestCreate_RefusesALibraryNameAnotherLibraryHas proves a load names exactly
+// one library: the collision is a refusal, not an internal failure.
+func TestCreate_RefusesALibraryNameAnotherLibraryHas(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO scripts")).
+		WillReturnError(&pq.Error{Code: pgUniqueViolation, Constraint: libraryNameIndex})
+	mock.ExpectRollback()
+
+	err := s.Create(context.Background(), &script.Script{Name: "fiscal", Library: true}, testAuthor)
+	require.ErrorIs(t, err, script.ErrDependency)
+	assert.Contains(t, err.Error(), `"fiscal"`)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestDelete_RefusesALibraryAScriptLoads keeps a deletion from leaving a
+// script failing at its load.
+func TestDelete_RefusesALibraryAScriptLoads(t *testing.T) {
+	s, mock := newMock(t)
+	expectDeleteLock(mock, "lib_1", true)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE loads @> $1::jsonb")).
+		WithArgs(`[{"script_id":"lib_1"}]`).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("daily"))
+	mock.ExpectRollback()
+	err := s.Delete(context.Background(), "lib_1")
+	require.ErrorIs(t, err, script.ErrDependency)
+	assert.Contains(t, err.Error(), `"daily"`)
+
+	expectDeleteLock(mock, "lib_1", true)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE loads @> $1::jsonb")).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM scripts")).WithArgs("lib_1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	require.NoError(t, s.Delete(context.Background(), "lib_1"), "a library nothing loads is deleted")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestLibrarySource covers the run's load resolver, including the answer for a
+// version the library does not have.
+func TestLibrarySource(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE s.library AND s.name = $1 AND v.version = $2")).
+		WithArgs("fiscal", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"source_code"}).AddRow("def f(): pass"))
+	src, err := s.LibrarySource(context.Background(), "fiscal", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "def f(): pass", src)
+
+	mock.ExpectQuery("FROM scripts s JOIN script_versions").
+		WillReturnRows(sqlmock.NewRows([]string{"source_code"}))
+	_, err = s.LibrarySource(context.Background(), "fiscal", 7)
+	assert.ErrorContains(t, err, `no library "fiscal" has version 7`)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestDependents reads the library graph from the library's end.
+func TestDependents(t *testing.T) {
+	s, mock := newMock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("jsonb_array_elements(s.loads)")).
+		WithArgs(`[{"script_id":"lib_1"}]`, "lib_1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "version"}).
+			AddRow("script_1", "daily", 2).AddRow("script_2", "weekly", 3))
+	got, err := s.Dependents(context.Background(), "lib_1")
+	require.NoError(t, err)
+	assert.Equal(t, []script.Dependency{
+		{ScriptID: "script_1", Name: "daily", Version: 2},
+		{ScriptID: "script_2", Name: "weekly", Version: 3},
+	}, got)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,204 @@
+package watchalert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/txn2/mcp-data-platform/internal/logsan"
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+	"github.com/txn2/mcp-data-platform/pkg/semantic"
+)
+
+// maxSchemaChecks bounds the catalog reads one sweep makes. The least
+// recently checked datasets go first, so every watched dataset is reached
+// within a few sweeps however many there are.
+const maxSchemaChecks = 100
+
+// SchemaSource reports a dataset's current column names.
+type SchemaSource interface {
+	Columns(ctx context.Context, urn string) ([]string, error)
+}
+
+// Baseline is the column list last recorded for a watched dataset.
+type Baseline struct {
+	URN     string
+	Columns []string
+	// Known is false for a dataset never checked, whose first read only
+	// records the baseline: there is nothing yet to compare it with.
+	Known bool
+}
+
+// SemanticSchema reads columns from the semantic provider.
+type SemanticSchema struct {
+	provider semantic.Provider
+	resolver semantic.URNResolver
+}
+
+// NewSemanticSchema returns a SchemaSource over p, or nil when p cannot
+// resolve a dataset URN to a table (no provider, or the noop one).
+func NewSemanticSchema(p semantic.Provider) SchemaSource {
+	if p == nil {
+		return nil
+	}
+	resolver, ok := semantic.URNResolverFrom(p)
+	if !ok {
+		return nil
+	}
+	return &SemanticSchema{provider: p, resolver: resolver}
+}
+
+// errNoColumns reports a dataset the provider returned no columns for. It is
+// treated as unknown rather than as every column removed.
+var errNoColumns = errors.New("the catalog reported no columns")
+
+// Columns returns the dataset's column names, sorted.
+func (s *SemanticSchema) Columns(ctx context.Context, urn string) ([]string, error) {
+	table, err := s.resolver.ResolveURN(ctx, urn)
+	if err != nil {
+		return nil, fmt.Errorf("resolving dataset urn: %w", err)
+	}
+	columns, err := s.provider.GetColumnsContext(ctx, *table)
+	if err != nil {
+		return nil, fmt.Errorf("reading dataset columns: %w", err)
+	}
+	if len(columns) == 0 {
+		return nil, errNoColumns
+	}
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// schemaChanges checks the least recently checked watched datasets against
+// their baselines and returns an event for each whose columns changed. A
+// dataset the catalog cannot read keeps its baseline and is tried again on a
+// later sweep.
+func (c *Checker) schemaChanges(ctx context.Context, now time.Time) ([]Event, error) {
+	baselines, err := c.cfg.Store.Baselines(ctx, maxSchemaChecks)
+	if err != nil {
+		return nil, fmt.Errorf("reading schema baselines: %w", err)
+	}
+	var out []Event
+	for _, b := range baselines {
+		columns, err := c.cfg.Schema.Columns(ctx, b.URN)
+		if err != nil {
+			slog.Debug("watch schema read skipped", // #nosec G706 -- structured slog call; error sanitized
+				logKeyError, logsan.SanitizeForLog(err.Error()))
+			continue
+		}
+		if err := c.cfg.Store.SaveBaseline(ctx, b.URN, columns, now); err != nil {
+			return out, fmt.Errorf("saving schema baseline: %w", err)
+		}
+		added, removed := diffColumns(b.Columns, columns)
+		if !b.Known || (len(added) == 0 && len(removed) == 0) {
+			continue
+		}
+		out = append(out, Event{
+			Kind:       notification.KindWatchSchema,
+			TargetType: notification.WatchDataset,
+			TargetID:   b.URN,
+			Title:      datasetTitle(b.URN),
+			Message:    describeColumns(added, removed),
+			At:         now,
+		})
+	}
+	if err := c.cfg.Store.PruneBaselines(ctx); err != nil {
+		return out, fmt.Errorf("pruning schema baselines: %w", err)
+	}
+	return out, nil
+}
+
+// diffColumns returns the names in after but not before, and the reverse.
+func diffColumns(before, after []string) (added, removed []string) {
+	for _, name := range after {
+		if !slices.Contains(before, name) {
+			added = append(added, name)
+		}
+	}
+	for _, name := range before {
+		if !slices.Contains(after, name) {
+			removed = append(removed, name)
+		}
+	}
+	return added, removed
+}
+
+// describeColumns is the schema event's message: the columns added and the
+// columns removed, one sentence each.
+func describeColumns(added, removed []string) string {
+	var parts []string
+	if len(added) > 0 {
+		parts = append(parts, "Added columns: "+strings.Join(added, ", ")+".")
+	}
+	if len(removed) > 0 {
+		parts = append(parts, "Removed columns: "+strings.Join(removed, ", ")+".")
+	}
+	return strings.Join(parts, " ")
+}
+
+// Baselines returns up to limit watched datasets, never-checked first, then
+// least recently checked.
+func (s *PostgresStore) Baselines(ctx context.Context, limit int) ([]Baseline, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT w.urn, COALESCE(b.columns, '{}'), b.urn IS NOT NULL
+		  FROM (SELECT DISTINCT target_id AS urn
+		          FROM notification_watches WHERE target_type = $1) w
+		  LEFT JOIN notification_watch_schemas b ON b.urn = w.urn
+		 ORDER BY b.checked_at NULLS FIRST, w.urn
+		 LIMIT $2`, notification.WatchDataset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying schema baselines: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var out []Baseline
+	for rows.Next() {
+		var b Baseline
+		if err := rows.Scan(&b.URN, pq.Array(&b.Columns), &b.Known); err != nil {
+			return nil, fmt.Errorf("scanning schema baseline: %w", err)
+		}
+		out = append(out, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating schema baselines: %w", err)
+	}
+	return out, nil
+}
+
+// SaveBaseline records a dataset's current column list.
+func (s *PostgresStore) SaveBaseline(ctx context.Context, urn string, columns []string, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO notification_watch_schemas (urn, columns, checked_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (urn) DO UPDATE
+		   SET columns = EXCLUDED.columns, checked_at = EXCLUDED.checked_at`,
+		urn, pq.Array(columns), at.UTC())
+	if err != nil {
+		return fmt.Errorf("storing schema baseline: %w", err)
+	}
+	return nil
+}
+
+// PruneBaselines drops the baselines of datasets nobody watches any more, so
+// a dataset watched again later starts from a fresh read rather than
+// reporting everything that changed while nobody was looking.
+func (s *PostgresStore) PruneBaselines(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM notification_watch_schemas b
+		 WHERE NOT EXISTS (SELECT 1 FROM notification_watches w
+		                    WHERE w.target_type = $1 AND w.target_id = b.urn)`,
+		notification.WatchDataset)
+	if err != nil {
+		return fmt.Errorf("deleting schema baselines: %w", err)
+	}
+	return nil
+}
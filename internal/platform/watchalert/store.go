@@ -0,0 +1,157 @@
+package watchalert
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// PostgresStore implements Store over the platform database.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates the sweep's PostgreSQL-backed store.
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Claim advances the sweep cursor with a compare-and-swap: it reads the
+// cursor, then moves it only if it still holds what was read. Two replicas
+// sweeping at once read the same cursor and exactly one of their updates
+// matches.
+func (s *PostgresStore) Claim(ctx context.Context, upto time.Time) (time.Time, bool, error) {
+	var prev time.Time
+	err := s.db.QueryRowContext(ctx, `SELECT swept_until FROM notification_watch_sweep`).Scan(&prev)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The migration seeds the row; a deployment that truncated it starts
+		// over from now rather than from the beginning of history.
+		_, err = s.db.ExecContext(ctx,
+			`INSERT INTO notification_watch_sweep (id, swept_until) VALUES (TRUE, $1)
+			 ON CONFLICT (id) DO NOTHING`, upto.UTC())
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("seeding the watch sweep cursor: %w", err)
+		}
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("reading the watch sweep cursor: %w", err)
+	}
+	if !upto.After(prev) {
+		return time.Time{}, false, nil
+	}
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE notification_watch_sweep SET swept_until = $2 WHERE swept_until = $1`,
+		prev, upto.UTC())
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("advancing the watch sweep cursor: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("reading the watch sweep claim result: %w", err)
+	}
+	if n == 0 {
+		return time.Time{}, false, nil
+	}
+	if earliest := upto.Add(-maxCatchUp); prev.Before(earliest) {
+		return earliest, true, nil
+	}
+	return prev, true, nil
+}
+
+// eventsSQL reads every change to a watched target in ($1, $2]. Each branch
+// is driven from the watched targets, so it walks the per-target indexes the
+// history tables already have rather than scanning them by time.
+//
+// A first version is the item's creation, not news about it, so version
+// branches start at 2; a collection counts as changed when updated_at moves
+// past created_at. A prompt version is news when it is served, which for an
+// approved draft is its approval rather than its proposal.
+const eventsSQL = `
+WITH watched AS (
+    SELECT DISTINCT target_type, target_id FROM notification_watches
+)
+SELECT $3::text, 'asset', v.asset_id, a.name, v.created_by, v.change_summary, v.created_at
+  FROM portal_asset_versions v
+  JOIN portal_assets a ON a.id = v.asset_id AND a.deleted_at IS NULL
+ WHERE v.asset_id IN (SELECT target_id FROM watched WHERE target_type = 'asset')
+   AND v.version > 1 AND v.created_at > $1 AND v.created_at <= $2
+UNION ALL
+SELECT $3::text, 'knowledge_page', v.page_id, v.title, v.created_by, v.change_summary, v.created_at
+  FROM portal_knowledge_page_versions v
+  JOIN portal_knowledge_pages p ON p.id = v.page_id AND p.deleted_at IS NULL
+ WHERE v.page_id IN (SELECT target_id FROM watched WHERE target_type = 'knowledge_page')
+   AND v.version > 1 AND v.created_at > $1 AND v.created_at <= $2
+UNION ALL
+SELECT $3::text, 'prompt', v.prompt_id::text, COALESCE(NULLIF(v.display_name, ''), p.name),
+       v.author, '', GREATEST(v.created_at, v.approved_at)
+  FROM prompt_versions v
+  JOIN prompts p ON p.id = v.prompt_id
+ WHERE v.prompt_id::text IN (SELECT target_id FROM watched WHERE target_type = 'prompt')
+   AND v.version > 1 AND v.status = 'applied'
+   AND GREATEST(v.created_at, v.approved_at) > $1 AND GREATEST(v.created_at, v.approved_at) <= $2
+UNION ALL
+SELECT $3::text, 'collection', c.id, c.name, '', '', c.updated_at
+  FROM portal_collections c
+ WHERE c.id IN (SELECT target_id FROM watched WHERE target_type = 'collection')
+   AND c.deleted_at IS NULL AND c.updated_at > c.created_at
+   AND c.updated_at > $1 AND c.updated_at <= $2
+UNION ALL
+SELECT $4::text, t.target_type, COALESCE(t.asset_id, t.collection_id, t.prompt_id::text, t.knowledge_page_id),
+       COALESCE(a.name, c.name, NULLIF(p.display_name, ''), p.name, k.title, ''),
+       t.author_email, t.title, t.created_at
+  FROM portal_threads t
+  LEFT JOIN portal_assets a ON a.id = t.asset_id
+  LEFT JOIN portal_collections c ON c.id = t.collection_id
+  LEFT JOIN prompts p ON p.id = t.prompt_id
+  LEFT JOIN portal_knowledge_pages k ON k.id = t.knowledge_page_id
+ WHERE t.deleted_at IS NULL AND t.target_type <> 'standalone'
+   AND t.created_at > $1 AND t.created_at <= $2
+   AND (t.target_type, COALESCE(t.asset_id, t.collection_id, t.prompt_id::text, t.knowledge_page_id))
+       IN (SELECT target_type, target_id FROM watched)
+UNION ALL
+SELECT $5::text, 'dataset', k.target_urn, k.target_urn, k.applied_by, k.change_type, k.created_at
+  FROM knowledge_changesets k
+ WHERE k.target_urn IN (SELECT target_id FROM watched WHERE target_type = 'dataset')
+   AND NOT k.rolled_back AND k.created_at > $1 AND k.created_at <= $2
+UNION ALL
+SELECT $5::text, 'knowledge_page', r.page_id, p.title, k.applied_by, k.change_type, k.created_at
+  FROM knowledge_changesets k
+  JOIN knowledge_page_entity_refs r ON r.entity_urn = k.target_urn
+  JOIN portal_knowledge_pages p ON p.id = r.page_id AND p.deleted_at IS NULL
+ WHERE r.page_id IN (SELECT target_id FROM watched WHERE target_type = 'knowledge_page')
+   AND NOT k.rolled_back AND k.created_at > $1 AND k.created_at <= $2
+ORDER BY 7`
+
+// Events returns the changes to watched targets in (from, to], oldest first.
+func (s *PostgresStore) Events(ctx context.Context, from, to time.Time) ([]Event, error) {
+	rows, err := s.db.QueryContext(ctx, eventsSQL, from.UTC(), to.UTC(),
+		notification.KindWatchVersion, notification.KindWatchThread, notification.KindWatchChangeset)
+	if err != nil {
+		return nil, fmt.Errorf("querying watched changes: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var out []Event
+	for rows.Next() {
+		var ev Event
+		if err := rows.Scan(&ev.Kind, &ev.TargetType, &ev.TargetID, &ev.Title,
+			&ev.Actor, &ev.Message, &ev.At); err != nil {
+			return nil, fmt.Errorf("scanning watched change: %w", err)
+		}
+		if ev.TargetType == notification.WatchDataset {
+			ev.Title = datasetTitle(ev.TargetID)
+		}
+		out = append(out, ev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating watched changes: %w", err)
+	}
+	return out, nil
+}
+
+// Verify interface compliance.
+var _ Store = (*PostgresStore)(nil)
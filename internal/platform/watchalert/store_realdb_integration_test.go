@@ -0,0 +1,97 @@
+//go:build integration
+
+package watchalert
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/internal/notification/notifyprefs"
+	"github.com/txn2/mcp-data-platform/internal/testdb"
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+// TestPostgresStoreRealDB runs the cursor claim and the events union against
+// the real schema. The union joins half a dozen history tables by their
+// column names, so sqlmock can only show it was issued.
+func TestPostgresStoreRealDB(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	store := NewPostgresStore(db)
+	exec := func(query string, args ...any) {
+		t.Helper()
+		_, err := db.ExecContext(ctx, query, args...)
+		require.NoError(t, err)
+	}
+
+	// The migration seeds the cursor at its own NOW(); start it in the past.
+	start := time.Now().UTC().Add(-time.Hour).Truncate(time.Microsecond)
+	exec(`UPDATE notification_watch_sweep SET swept_until = $1`, start)
+
+	exec(`INSERT INTO portal_assets (id, owner_id, owner_email, name, content_type, s3_bucket, s3_key)
+	      VALUES ('ast_1', 'u_owner', 'owner@example.com', 'Revenue', 'text/markdown', 'b', 'k')`)
+	exec(`INSERT INTO portal_asset_versions
+	      (id, asset_id, version, s3_key, s3_bucket, content_type, size_bytes, created_by, change_summary, created_at)
+	      VALUES ('v1', 'ast_1', 1, 'k', 'b', 'text/markdown', 1, 'owner@example.com', '', $1),
+	             ('v2', 'ast_1', 2, 'k2', 'b', 'text/markdown', 1, 'ed@example.com', 'Fixed Q3', $1)`,
+		start.Add(10*time.Minute))
+	exec(`INSERT INTO portal_threads (id, kind, target_type, asset_id, title, author_id, author_email, created_at)
+	      VALUES ('thr_1', 'question', 'asset', 'ast_1', 'Q3 looks off', 'u_ann', 'ann@example.com', $1)`,
+		start.Add(20*time.Minute))
+	exec(`INSERT INTO knowledge_changesets (id, target_urn, change_type, applied_by, created_at)
+	      VALUES ('cs_1', $1, 'update_description', 'lead@example.com', $2),
+	             ('cs_2', $1, 'add_tag', 'lead@example.com', $2)`,
+		ordersURN, start.Add(30*time.Minute))
+	exec(`UPDATE knowledge_changesets SET rolled_back = TRUE WHERE id = 'cs_2'`)
+
+	watches := notifyprefs.NewWatchStore(db)
+	for _, w := range []notification.Watch{
+		{Email: "watcher@example.com", TargetType: notification.WatchAsset, TargetID: "ast_1"},
+		{Email: "watcher@example.com", TargetType: notification.WatchDataset, TargetID: ordersURN},
+	} {
+		_, err := watches.Add(ctx, w)
+		require.NoError(t, err)
+	}
+
+	upto := start.Add(45 * time.Minute)
+	from, won, err := store.Claim(ctx, upto)
+	require.NoError(t, err)
+	require.True(t, won)
+	assert.True(t, from.Equal(start))
+	_, won, err = store.Claim(ctx, upto)
+	require.NoError(t, err)
+	assert.False(t, won, "the same window is claimed once")
+
+	events, err := store.Events(ctx, from, upto)
+	require.NoError(t, err)
+	require.Len(t, events, 3, "the first version and the rolled-back changeset are not news")
+	assert.Equal(t, notification.KindWatchVersion, events[0].Kind)
+	assert.Equal(t, "Fixed Q3", events[0].Message)
+	assert.Equal(t, notification.KindWatchThread, events[1].Kind)
+	assert.Equal(t, "Revenue", events[1].Title)
+	assert.Equal(t, notification.KindWatchChangeset, events[2].Kind)
+	assert.Equal(t, "hive.sales.orders", events[2].Title)
+
+	// Baselines: the watched dataset is returned unknown until it is saved,
+	// and pruned once nobody watches it.
+	baselines, err := store.Baselines(ctx, maxSchemaChecks)
+	require.NoError(t, err)
+	require.Len(t, baselines, 1)
+	assert.False(t, baselines[0].Known)
+	require.NoError(t, store.SaveBaseline(ctx, ordersURN, []string{"id", "total"}, upto))
+	baselines, err = store.Baselines(ctx, maxSchemaChecks)
+	require.NoError(t, err)
+	require.Len(t, baselines, 1)
+	assert.True(t, baselines[0].Known)
+	assert.Equal(t, []string{"id", "total"}, baselines[0].Columns)
+
+	require.NoError(t, watches.Remove(ctx, "watcher@example.com", notification.WatchDataset, ordersURN))
+	require.NoError(t, store.PruneBaselines(ctx))
+	var left int
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notification_watch_schemas`).Scan(&left))
+	assert.Zero(t, left)
+}
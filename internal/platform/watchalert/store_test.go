@@ -0,0 +1,129 @@
+package watchalert
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+)
+
+func newMockStore(t *testing.T) (*PostgresStore, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return NewPostgresStore(db), mock
+}
+
+func TestPostgresStoreClaim(t *testing.T) {
+	upto := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	prev := upto.Add(-5 * time.Minute)
+	cursor := func(at time.Time) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"swept_until"}).AddRow(at)
+	}
+
+	t.Run("a won swap returns the previous cursor", func(t *testing.T) {
+		store, mock := newMockStore(t)
+		mock.ExpectQuery("SELECT swept_until").WillReturnRows(cursor(prev))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE notification_watch_sweep SET swept_until = $2 WHERE swept_until = $1")).
+			WithArgs(prev, upto).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		from, won, err := store.Claim(context.Background(), upto)
+		require.NoError(t, err)
+		assert.True(t, won)
+		assert.Equal(t, prev, from)
+	})
+
+	t.Run("a swap another replica made first loses", func(t *testing.T) {
+		store, mock := newMockStore(t)
+		mock.ExpectQuery("SELECT swept_until").WillReturnRows(cursor(prev))
+		mock.ExpectExec("UPDATE notification_watch_sweep").WillReturnResult(sqlmock.NewResult(0, 0))
+		_, won, err := store.Claim(context.Background(), upto)
+		require.NoError(t, err)
+		assert.False(t, won)
+	})
+
+	t.Run("a cursor already there is not moved", func(t *testing.T) {
+		store, mock := newMockStore(t)
+		mock.ExpectQuery("SELECT swept_until").WillReturnRows(cursor(upto))
+		_, won, err := store.Claim(context.Background(), upto)
+		require.NoError(t, err)
+		assert.False(t, won)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("a long outage catches up one day at most", func(t *testing.T) {
+		store, mock := newMockStore(t)
+		mock.ExpectQuery("SELECT swept_until").WillReturnRows(cursor(upto.AddDate(0, 0, -7)))
+		mock.ExpectExec("UPDATE notification_watch_sweep").WillReturnResult(sqlmock.NewResult(0, 1))
+		from, won, err := store.Claim(context.Background(), upto)
+		require.NoError(t, err)
+		assert.True(t, won)
+		assert.Equal(t, upto.Add(-maxCatchUp), from)
+	})
+
+	t.Run("a missing cursor is seeded at now and reports nothing", func(t *testing.T) {
+		store, mock := newMockStore(t)
+		mock.ExpectQuery("SELECT swept_until").WillReturnRows(sqlmock.NewRows([]string{"swept_until"}))
+		mock.ExpectExec("INSERT INTO notification_watch_sweep").WithArgs(upto).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		_, won, err := store.Claim(context.Background(), upto)
+		require.NoError(t, err)
+		assert.False(t, won)
+	})
+
+	t.Run("a read failure is reported", func(t *testing.T) {
+		store, mock := newMockStore(t)
+		mock.ExpectQuery("SELECT swept_until").WillReturnError(errors.New("down"))
+		_, _, err := store.Claim(context.Background(), upto)
+		assert.ErrorContains(t, err, "reading the watch sweep cursor")
+	})
+}
+
+func TestPostgresStoreEvents(t *testing.T) {
+	store, mock := newMockStore(t)
+	from := time.Date(2026, 10, 1, 11, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	mock.ExpectQuery("WITH watched AS").
+		WithArgs(from, to, notification.KindWatchVersion, notification.KindWatchThread, notification.KindWatchChangeset).
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "target_type", "target_id", "title", "actor", "message", "at"}).
+			AddRow(notification.KindWatchThread, "asset", "ast_1", "Revenue", "ann@b.io", "Q3 looks off", from).
+			AddRow(notification.KindWatchChangeset, "dataset", ordersURN, ordersURN, "lead@b.io", "update_description", to))
+
+	events, err := store.Events(context.Background(), from, to)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "Q3 looks off", events[0].Message)
+	assert.Equal(t, "hive.sales.orders", events[1].Title, "a dataset is titled by its name, not its URN")
+}
+
+func TestPostgresStoreBaselines(t *testing.T) {
+	store, mock := newMockStore(t)
+	mock.ExpectQuery("FROM notification_watches").
+		WithArgs(notification.WatchDataset, maxSchemaChecks).
+		WillReturnRows(sqlmock.NewRows([]string{"urn", "columns", "known"}).
+			AddRow(ordersURN, "{id,total}", true))
+	mock.ExpectExec("INSERT INTO notification_watch_schemas").
+		WithArgs(ordersURN, pq.Array([]string{"id"}), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM notification_watch_schemas").
+		WithArgs(notification.WatchDataset).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	baselines, err := store.Baselines(context.Background(), maxSchemaChecks)
+	require.NoError(t, err)
+	require.Len(t, baselines, 1)
+	assert.Equal(t, []string{"id", "total"}, baselines[0].Columns)
+	assert.True(t, baselines[0].Known)
+	require.NoError(t, store.SaveBaseline(context.Background(), ordersURN, []string{"id"}, time.Now()))
+	require.NoError(t, store.PruneBaselines(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
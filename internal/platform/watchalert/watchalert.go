@@ -0,0 +1,273 @@
+// Package watchalert tells people about changes to the things they watch: a
+// portal asset, collection, prompt, or knowledge page, or a DataHub dataset.
+//
+// A watch is one person's row in notification_watches (pkg/notification owns
+// the domain, notifyprefs the store). Nothing in the platform raises an event
+// when a version is written or a thread is opened, and threading a new hook
+// through every writer would couple each of them to notifications, so this
+// package reads the rows those writers already leave behind instead:
+//
+//	version history   portal_asset_versions, portal_knowledge_page_versions,
+//	                  and served prompt_versions; a collection has no
+//	                  versions, so a change to it is its updated_at moving
+//	threads           portal_threads opened on the item
+//	knowledge         applied knowledge_changesets on the dataset, or on a
+//	                  dataset a watched knowledge page references
+//	schema            the column list the semantic provider reports for a
+//	                  watched dataset, compared with the last one it reported
+//
+// A Checker sweeps on a timer. Each sweep claims the window since the last
+// one with a single compare-and-swap on the sweep cursor, so exactly one
+// replica reports a given change, and enqueues through the notification
+// substrate under CategoryWatch, where the watcher's delivery mode (including
+// the daily digest), channel routes, and unsubscribe link all apply.
+//
+// Whether a watcher may still see the item is decided at delivery, by the
+// same audience rule that bounds a mention: a watch on an asset later shared
+// away goes quiet rather than reporting on it. A dataset is open to every
+// authenticated user, so its watchers are not filtered.
+//
+// It must not import pkg/platform: the HTTP composition root supplies the
+// stores, the audience, the semantic provider, and the portal base URL.
+package watchalert
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/internal/logsan"
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+	"github.com/txn2/mcp-data-platform/pkg/portal/mention"
+	"github.com/txn2/mcp-data-platform/pkg/urnbuild"
+)
+
+// DefaultInterval is how often the sweep runs. News about a watched item is
+// worth minutes, not seconds, and a digest recipient reads it once a day
+// regardless.
+const DefaultInterval = 5 * time.Minute
+
+// settleDelay holds each sweep's window back from the present, so a row
+// written by a transaction still open at the sweep (its created_at is its
+// insert time, not its commit time) lands in the next window instead of
+// falling between two.
+const settleDelay = time.Minute
+
+// maxCatchUp bounds how far back a sweep reaches after the platform was down.
+// A watch is about news; a week-old version is history, and mailing a
+// backlog of it after an outage would bury whatever is current.
+const maxCatchUp = 24 * time.Hour
+
+// logKeyError is the structured-logging key for an error value.
+const logKeyError = "error"
+
+// Event is one change to one watched target, as the sweep found it.
+type Event struct {
+	// Kind is one of the notification.KindWatch* constants.
+	Kind string
+	// TargetType and TargetID name the watched target, in the
+	// notification.Watch* vocabulary.
+	TargetType string
+	TargetID   string
+	// Title is the target's display name.
+	Title string
+	// Actor is who made the change, empty when the platform did.
+	Actor string
+	// Message is the change's own text: a version's change summary, a
+	// thread's title, a changeset's type, or a schema diff.
+	Message string
+	// At is when the change happened.
+	At time.Time
+}
+
+// Store is the sweep's persistence: its cursor, the changes in a window, and
+// the schema baselines.
+type Store interface {
+	// Claim advances the sweep cursor to upto and reports the window start
+	// this caller won. It loses when another replica moved the cursor first
+	// or the cursor is already at or past upto.
+	Claim(ctx context.Context, upto time.Time) (from time.Time, won bool, err error)
+	// Events returns the changes to watched targets in (from, to].
+	Events(ctx context.Context, from, to time.Time) ([]Event, error)
+	// Baselines returns up to limit watched datasets, least recently checked
+	// first, with the column list last recorded for each.
+	Baselines(ctx context.Context, limit int) ([]Baseline, error)
+	// SaveBaseline records a dataset's current column list.
+	SaveBaseline(ctx context.Context, urn string, columns []string, at time.Time) error
+	// PruneBaselines drops the baselines of datasets nobody watches.
+	PruneBaselines(ctx context.Context) error
+}
+
+// Audience filters addresses to the people who can view a portal target.
+// *mention.Audience satisfies it.
+type Audience interface {
+	Eligible(ctx context.Context, t mention.Target, emails []string) ([]string, error)
+}
+
+// Config carries the checker's dependencies. Store, Watches, Audience, and
+// Enqueuer are required; New returns nil when any is missing. Schema is
+// optional: without a semantic provider able to resolve dataset URNs, schema
+// changes are simply not detected.
+type Config struct {
+	Store    Store
+	Watches  notification.WatchStore
+	Audience Audience
+	Schema   SchemaSource
+	Enqueuer *notification.Enqueuer
+	// BaseURL is the portal's public base URL, for the deep links.
+	BaseURL string
+	// Interval overrides DefaultInterval. Testing hook.
+	Interval time.Duration
+	// Now overrides time.Now. Testing hook.
+	Now func() time.Time
+}
+
+// Checker sweeps for changes to watched targets on a timer.
+type Checker struct {
+	cfg      Config
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// New builds a Checker, or nil when a dependency is absent. A nil Checker's
+// methods are no-ops, so the caller brackets Start/Stop unconditionally.
+func New(cfg Config) *Checker {
+	if cfg.Store == nil || cfg.Watches == nil || cfg.Audience == nil || cfg.Enqueuer == nil {
+		return nil
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Checker{cfg: cfg, stopCh: make(chan struct{})}
+}
+
+// Start runs the sweep loop until ctx is canceled or Stop is called. The
+// first sweep runs one interval in; the cursor is in the database, so nothing
+// that happened meanwhile is missed. Nil-safe.
+func (c *Checker) Start(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	c.wg.Go(func() {
+		ticker := time.NewTicker(c.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.stopCh:
+				return
+			case <-ticker.C:
+				if err := c.Check(ctx); err != nil {
+					slog.Warn("watch sweep failed", // #nosec G706 -- structured slog call; error sanitized
+						logKeyError, logsan.SanitizeForLog(err.Error()))
+				}
+			}
+		}
+	})
+}
+
+// Stop ends the sweep loop and waits for an in-flight sweep. Nil-safe and
+// idempotent.
+func (c *Checker) Stop() {
+	if c == nil {
+		return
+	}
+	c.stopOnce.Do(func() { close(c.stopCh) })
+	c.wg.Wait()
+}
+
+// Check runs one sweep: it claims the window since the last sweep, reads the
+// changes in it and any schema changes, and notifies each change's watchers.
+//
+// The claim is taken before the changes are read, so a sweep that fails
+// after it drops that window rather than letting two replicas both report
+// it. A missed notification is the cheaper failure than a duplicated one.
+func (c *Checker) Check(ctx context.Context) error {
+	now := c.cfg.Now().UTC()
+	from, won, err := c.cfg.Store.Claim(ctx, now.Add(-settleDelay))
+	if err != nil {
+		return fmt.Errorf("claiming the watch sweep: %w", err)
+	}
+	if !won {
+		return nil
+	}
+	events, err := c.cfg.Store.Events(ctx, from, now.Add(-settleDelay))
+	if err != nil {
+		return fmt.Errorf("reading watched changes: %w", err)
+	}
+	if c.cfg.Schema != nil {
+		changed, err := c.schemaChanges(ctx, now)
+		if err != nil {
+			// The events above are still news; report them.
+			slog.Warn("watch schema check failed", // #nosec G706 -- structured slog call; error sanitized
+				logKeyError, logsan.SanitizeForLog(err.Error()))
+		}
+		events = append(events, changed...)
+	}
+	for _, ev := range events {
+		c.deliver(ctx, ev)
+	}
+	return nil
+}
+
+// deliver notifies the watchers of one change who may still see its target.
+// A failure is logged and the sweep goes on: the window is already claimed,
+// so giving up here would drop every later change in it too.
+func (c *Checker) deliver(ctx context.Context, ev Event) {
+	watchers, err := c.cfg.Watches.Watchers(ctx, ev.TargetType, ev.TargetID)
+	if err == nil && len(watchers) > 0 && ev.TargetType != notification.WatchDataset {
+		// The portal watch types are the mention target types by name.
+		watchers, err = c.cfg.Audience.Eligible(ctx, mention.Target{Type: ev.TargetType, ID: ev.TargetID}, watchers)
+	}
+	if err != nil {
+		slog.Warn("watch notification skipped", // #nosec G706 -- structured slog call; error sanitized
+			"target_type", ev.TargetType, logKeyError, logsan.SanitizeForLog(err.Error()))
+		return
+	}
+	if len(watchers) == 0 {
+		return
+	}
+	// NotifyFanout drops the actor, so a person is not told about their own
+	// edit to something they watch.
+	c.cfg.Enqueuer.NotifyFanout(ctx, watchers, notification.CategoryWatch, notification.Payload{
+		Kind:      ev.Kind,
+		ItemID:    ev.TargetID,
+		ItemTitle: ev.Title,
+		Actor:     ev.Actor,
+		Message:   ev.Message,
+		Link:      notification.PortalLink(c.cfg.BaseURL, targetRoute(ev.TargetType, ev.TargetID)),
+	})
+}
+
+// targetRoute is the portal path that opens a watched target.
+func targetRoute(targetType, targetID string) string {
+	switch targetType {
+	case notification.WatchAsset:
+		return "/assets/" + targetID
+	case notification.WatchCollection:
+		return "/collections/" + targetID
+	case notification.WatchPrompt:
+		return "/prompts/" + targetID
+	case notification.WatchKnowledgePage:
+		return "/knowledge/pages/" + targetID
+	default:
+		return "/knowledge/catalog?urn=" + url.QueryEscape(targetID)
+	}
+}
+
+// datasetTitle is the dataset name a URN carries, or the URN itself when it
+// does not parse.
+func datasetTitle(urn string) string {
+	if parsed, err := urnbuild.ParseDatasetURN(urn); err == nil && parsed.Name != "" {
+		return parsed.Name
+	}
+	return urn
+}
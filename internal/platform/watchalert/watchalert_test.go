@@ -0,0 +1,239 @@
+package watchalert
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/pkg/notification"
+	"github.com/txn2/mcp-data-platform/pkg/portal/mention"
+)
+
+const ordersURN = "urn:li:dataset:(urn:li:dataPlatform:trino,hive.sales.orders,PROD)"
+
+// memStore serves canned events and baselines and models the cursor claim.
+type memStore struct {
+	mu        sync.Mutex
+	cursor    time.Time
+	events    []Event
+	baselines []Baseline
+	saved     map[string][]string
+	pruned    int
+	claimErr  error
+	eventsErr error
+	from, to  time.Time
+}
+
+func (s *memStore) Claim(_ context.Context, upto time.Time) (time.Time, bool, error) {
+	if s.claimErr != nil {
+		return time.Time{}, false, s.claimErr
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !upto.After(s.cursor) {
+		return time.Time{}, false, nil
+	}
+	from := s.cursor
+	s.cursor = upto
+	return from, true, nil
+}
+
+func (s *memStore) Events(_ context.Context, from, to time.Time) ([]Event, error) {
+	s.from, s.to = from, to
+	return s.events, s.eventsErr
+}
+
+func (s *memStore) Baselines(context.Context, int) ([]Baseline, error) {
+	return s.baselines, nil
+}
+
+func (s *memStore) SaveBaseline(_ context.Context, urn string, columns []string, _ time.Time) error {
+	if s.saved == nil {
+		s.saved = map[string][]string{}
+	}
+	s.saved[urn] = columns
+	return nil
+}
+
+func (s *memStore) PruneBaselines(context.Context) error {
+	s.pruned++
+	return nil
+}
+
+// memWatches answers Watchers from a fixed map keyed by target id.
+type memWatches struct {
+	notification.WatchStore
+	byTarget map[string][]string
+}
+
+func (w memWatches) Watchers(_ context.Context, _, targetID string) ([]string, error) {
+	return w.byTarget[targetID], nil
+}
+
+// allowList admits only the listed addresses to any portal target and
+// records what it was asked about.
+type allowList struct {
+	allowed map[string]bool
+	asked   []mention.Target
+}
+
+func (a *allowList) Eligible(_ context.Context, t mention.Target, emails []string) ([]string, error) {
+	a.asked = append(a.asked, t)
+	var out []string
+	for _, e := range emails {
+		if a.allowed[e] {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// fixedSchema reports one column list per URN.
+type fixedSchema map[string][]string
+
+func (f fixedSchema) Columns(_ context.Context, urn string) ([]string, error) {
+	cols, ok := f[urn]
+	if !ok {
+		return nil, errors.New("not in the catalog")
+	}
+	return cols, nil
+}
+
+// captureQueue records what the enqueuer wrote.
+type captureQueue struct {
+	notification.QueueStore
+	mu   sync.Mutex
+	rows []notification.Notification
+}
+
+func (q *captureQueue) Enqueue(_ context.Context, n notification.Notification) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rows = append(q.rows, n)
+	return nil
+}
+
+// defaultPrefs serves the platform default preferences for everyone.
+type defaultPrefs struct{}
+
+func (defaultPrefs) Get(_ context.Context, email string) (notification.Prefs, error) {
+	return notification.DefaultPrefs(email), nil
+}
+
+func (defaultPrefs) Set(_ context.Context, email string, _ notification.PrefsUpdate) (notification.Prefs, error) {
+	return notification.DefaultPrefs(email), nil
+}
+
+var sweepNow = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func newChecker(t *testing.T, store *memStore, watches memWatches, aud Audience, schema SchemaSource) (*Checker, *captureQueue) {
+	t.Helper()
+	queue := &captureQueue{}
+	enq := notification.NewEnqueuer(defaultPrefs{}, queue, 13)
+	t.Cleanup(enq.Close)
+	c := New(Config{
+		Store: store, Watches: watches, Audience: aud, Schema: schema,
+		Enqueuer: enq, BaseURL: "https://portal.example.com",
+		Now: func() time.Time { return sweepNow },
+	})
+	require.NotNil(t, c)
+	return c, queue
+}
+
+func TestNewRequiresItsDependencies(t *testing.T) {
+	assert.Nil(t, New(Config{}))
+	var c *Checker
+	c.Start(context.Background())
+	c.Stop()
+}
+
+// TestCheckNotifiesVisibleWatchersButNotTheActor sweeps one new asset version
+// watched by three people: the editor, someone the asset is shared with, and
+// someone it is not.
+func TestCheckNotifiesVisibleWatchersButNotTheActor(t *testing.T) {
+	store := &memStore{
+		cursor: sweepNow.Add(-time.Hour),
+		events: []Event{{
+			Kind: notification.KindWatchVersion, TargetType: notification.WatchAsset, TargetID: "ast_1",
+			Title: "Revenue", Actor: "ed@b.io", Message: "Fixed Q3",
+		}},
+	}
+	aud := &allowList{allowed: map[string]bool{"ed@b.io": true, "ann@b.io": true}}
+	c, queue := newChecker(t, store, memWatches{byTarget: map[string][]string{
+		"ast_1": {"ed@b.io", "ann@b.io", "out@b.io"},
+	}}, aud, nil)
+
+	require.NoError(t, c.Check(context.Background()))
+
+	require.Len(t, queue.rows, 1)
+	row := queue.rows[0]
+	assert.Equal(t, "ann@b.io", row.Recipient)
+	assert.Equal(t, notification.CategoryWatch, row.Category)
+	assert.Equal(t, "https://portal.example.com/portal/assets/ast_1", row.Payload.Link)
+	assert.Equal(t, []mention.Target{{Type: mention.TargetAsset, ID: "ast_1"}}, aud.asked)
+	assert.Equal(t, sweepNow.Add(-time.Hour), store.from)
+	assert.Equal(t, sweepNow.Add(-settleDelay), store.to, "the window stops short of the present")
+}
+
+func TestCheckLosingTheClaimReadsNothing(t *testing.T) {
+	store := &memStore{cursor: sweepNow, eventsErr: errors.New("must not be read")}
+	c, queue := newChecker(t, store, memWatches{}, &allowList{}, nil)
+	require.NoError(t, c.Check(context.Background()))
+	assert.Empty(t, queue.rows)
+
+	store.claimErr = errors.New("down")
+	assert.ErrorContains(t, c.Check(context.Background()), "claiming the watch sweep")
+}
+
+// TestCheckReportsSchemaChanges checks two watched datasets: one seen for the
+// first time, which only records its baseline, and one whose columns moved.
+func TestCheckReportsSchemaChanges(t *testing.T) {
+	firstURN := "urn:li:dataset:(urn:li:dataPlatform:trino,hive.sales.refunds,PROD)"
+	store := &memStore{
+		cursor: sweepNow.Add(-time.Hour),
+		baselines: []Baseline{
+			{URN: ordersURN, Columns: []string{"id", "total"}, Known: true},
+			{URN: firstURN},
+		},
+	}
+	schema := fixedSchema{
+		ordersURN: {"id", "region", "total_cents"},
+		firstURN:  {"id"},
+	}
+	c, queue := newChecker(t, store, memWatches{byTarget: map[string][]string{
+		ordersURN: {"ann@b.io"}, firstURN: {"ann@b.io"},
+	}}, &allowList{}, schema)
+
+	require.NoError(t, c.Check(context.Background()))
+
+	require.Len(t, queue.rows, 1, "a first read records a baseline and reports nothing")
+	p := queue.rows[0].Payload
+	assert.Equal(t, notification.KindWatchSchema, p.Kind)
+	assert.Equal(t, "hive.sales.orders", p.ItemTitle)
+	assert.Equal(t, "Added columns: region, total_cents. Removed columns: total.", p.Message)
+	assert.Contains(t, p.Link, "/portal/knowledge/catalog?urn=urn%3Ali%3Adataset")
+	assert.Equal(t, []string{"id"}, store.saved[firstURN])
+	assert.Equal(t, 1, store.pruned)
+}
+
+func TestDiffColumns(t *testing.T) {
+	added, removed := diffColumns([]string{"a", "b"}, []string{"a", "b"})
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+	assert.Empty(t, describeColumns(nil, nil))
+	added, removed = diffColumns([]string{"a"}, []string{"b"})
+	assert.Equal(t, "Added columns: b. Removed columns: a.", describeColumns(added, removed))
+}
+
+func TestTargetRoute(t *testing.T) {
+	assert.Equal(t, "/collections/col_1", targetRoute(notification.WatchCollection, "col_1"))
+	assert.Equal(t, "/prompts/p1", targetRoute(notification.WatchPrompt, "p1"))
+	assert.Equal(t, "/knowledge/pages/kp_1", targetRoute(notification.WatchKnowledgePage, "kp_1"))
+	assert.Equal(t, "hive.sales.orders", datasetTitle(ordersURN))
+	assert.Equal(t, "not-a-urn", datasetTitle("not-a-urn"))
+}
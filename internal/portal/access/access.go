@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/txn2/mcp-data-platform/internal/portal/portaldomain"
+	"github.com/txn2/mcp-data-platform/pkg/portal/spaces"
 	"github.com/txn2/mcp-data-platform/pkg/portal/threads"
 	"github.com/txn2/mcp-data-platform/pkg/prompt"
 )
@@ -36,6 +37,10 @@ type Config struct {
 	Collections portaldomain.CollectionStore
 	Shares      portaldomain.ShareStore
 	Prompts     prompt.Store
+	// Spaces resolves the roles team-space members hold on the collections a
+	// space owns and the assets inside them. nil grants nothing through
+	// spaces, leaving owners and shares as the only grants.
+	Spaces spaces.Store
 	// AdminRoles are the roles that grant admin access in the portal.
 	AdminRoles []string
 	// PersonaTools resolves a user's roles to the tool names their persona
@@ -201,8 +206,8 @@ func (c *Checker) AssetSharePermission(ctx context.Context, assetID string, user
 }
 
 // ResolveAssetPermission returns the highest permission a non-owner user holds
-// for an asset, combining a direct share (AssetSharePermission) with a
-// collection share (GetUserAssetPermissionViaCollection). A direct editor
+// for an asset, combining a direct share (AssetSharePermission) with a grant
+// through a collection holding it (collectionGrant). A direct editor
 // short-circuits the collection lookup because editor is the ceiling. The
 // returned error is the direct-share store error (nil on success); a
 // collection-lookup error is treated as no collection access, matching the
@@ -219,7 +224,7 @@ func (c *Checker) ResolveAssetPermission(ctx context.Context, assetID string, us
 	if err == nil && direct == portaldomain.PermissionEditor {
 		return direct, nil // editor is the ceiling; no need to consult the cascade
 	}
-	collPerm, _ := c.cfg.Shares.GetUserAssetPermissionViaCollection(ctx, assetID, user.UserID, user.Email)
+	collPerm := c.collectionGrant(ctx, assetID, user)
 	best := direct
 	if permissionRank(collPerm) > permissionRank(best) {
 		best = collPerm
@@ -228,10 +233,10 @@ func (c *Checker) ResolveAssetPermission(ctx context.Context, assetID string, us
 }
 
 // CanViewAsset reports whether the user may view the asset (owner, a direct
-// share, or a collection share). A direct-share store error is tolerated: a
-// collection grant still allows access. It short-circuits on a direct grant to
-// avoid a collection query on the hot path, where callers resolve many assets
-// in a loop.
+// share, or a collection share or team-space role). A direct-share store error
+// is tolerated: a collection grant still allows access. It short-circuits on a
+// direct grant to avoid a collection query on the hot path, where callers
+// resolve many assets in a loop.
 func (c *Checker) CanViewAsset(ctx context.Context, assetID string, asset *portaldomain.Asset, user *User) bool {
 	if asset.OwnerID == user.UserID {
 		return true
@@ -239,8 +244,7 @@ func (c *Checker) CanViewAsset(ctx context.Context, assetID string, asset *porta
 	if perm, err := c.AssetSharePermission(ctx, assetID, user); err == nil && perm != "" {
 		return true
 	}
-	collPerm, _ := c.cfg.Shares.GetUserAssetPermissionViaCollection(ctx, assetID, user.UserID, user.Email)
-	return collPerm != ""
+	return c.collectionGrant(ctx, assetID, user) != ""
 }
 
 // AssetViewGrant reports whether the user may view the asset, distinguishing a
@@ -258,8 +262,7 @@ func (c *Checker) AssetViewGrant(ctx context.Context, assetID string, asset *por
 	if perm != "" {
 		return true, nil
 	}
-	collPerm, _ := c.cfg.Shares.GetUserAssetPermissionViaCollection(ctx, assetID, user.UserID, user.Email)
-	return collPerm != "", nil
+	return c.collectionGrant(ctx, assetID, user) != "", nil
 }
 
 // CanEditAssetSilent reports owner-or-admin-or-editor access to an asset. A
@@ -276,23 +279,71 @@ func (c *Checker) CanEditAssetSilent(ctx context.Context, assetID string, user *
 	return GrantsEdit(perm)
 }
 
-// CollectionSharePermission returns the highest share permission for a user on
-// a collection. A store error, or no share store at all, yields the empty
-// permission: a deployment without shares grants none rather than panicking on
-// the write gates that now consult it.
+// CollectionSharePermission returns the highest permission a user holds on a
+// collection through a share or a role in the team space that owns it. A store
+// error, or no share or space store at all, yields no grant from that path: a
+// deployment without shares grants none rather than panicking on the write
+// gates that now consult it.
 func (c *Checker) CollectionSharePermission(ctx context.Context, collectionID string, user *User) portaldomain.SharePermission {
-	if c.cfg.Shares == nil || user == nil {
+	if user == nil {
 		return ""
 	}
-	perm, err := c.cfg.Shares.GetUserCollectionPermission(ctx, collectionID, user.UserID, user.Email)
-	if err != nil {
+	var perm portaldomain.SharePermission
+	if c.cfg.Shares != nil {
+		perm, _ = c.cfg.Shares.GetUserCollectionPermission(ctx, collectionID, user.UserID, user.Email)
+	}
+	if perm == portaldomain.PermissionEditor || c.cfg.Spaces == nil {
+		return perm
+	}
+	role, _ := c.cfg.Spaces.CollectionRole(ctx, collectionID, SpacePrincipal(user))
+	return higher(perm, spacePermission(role))
+}
+
+// collectionGrant returns the highest permission a user holds on an asset
+// through the collections holding it: a share on one of them, or a role in a
+// team space owning one. Lookup errors grant nothing, matching the
+// best-effort cascade the view checks use.
+func (c *Checker) collectionGrant(ctx context.Context, assetID string, user *User) portaldomain.SharePermission {
+	perm, _ := c.cfg.Shares.GetUserAssetPermissionViaCollection(ctx, assetID, user.UserID, user.Email)
+	if perm == portaldomain.PermissionEditor || c.cfg.Spaces == nil {
+		return perm
+	}
+	role, _ := c.cfg.Spaces.AssetRole(ctx, assetID, SpacePrincipal(user))
+	return higher(perm, spacePermission(role))
+}
+
+// SpacePrincipal is who the user is to team-space membership: their address,
+// matched against managed members, and the roles on their token, which carry
+// the identity provider's group claims and are matched against group
+// bindings.
+func SpacePrincipal(user *User) spaces.Principal {
+	return spaces.Principal{Email: user.Email, Groups: user.Roles}
+}
+
+// spacePermission maps a space role onto the share permission it confers on
+// what the space owns. A manager's extra rights are over the space itself, so
+// on its contents a manager is an editor.
+func spacePermission(role string) portaldomain.SharePermission {
+	switch {
+	case spaces.AtLeast(role, spaces.RoleEditor):
+		return portaldomain.PermissionEditor
+	case role == spaces.RoleViewer:
+		return portaldomain.PermissionViewer
+	default:
 		return ""
 	}
-	return perm
 }
 
-// CanViewCollection reports whether the user may view the collection (owner or
-// any share).
+// higher returns the stronger of two permissions.
+func higher(a, b portaldomain.SharePermission) portaldomain.SharePermission {
+	if permissionRank(b) > permissionRank(a) {
+		return b
+	}
+	return a
+}
+
+// CanViewCollection reports whether the user may view the collection (owner,
+// any share, or membership of the team space that owns it).
 func (c *Checker) CanViewCollection(ctx context.Context, coll *portaldomain.Collection, user *User) bool {
 	if coll.OwnerID == user.UserID {
 		return true
@@ -3,6 +3,7 @@ package access
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/txn2/mcp-data-platform/internal/portal/portaldomain"
+	"github.com/txn2/mcp-data-platform/pkg/portal/spaces"
 	"github.com/txn2/mcp-data-platform/pkg/portal/threads"
 	"github.com/txn2/mcp-data-platform/pkg/prompt"
 )
@@ -408,6 +410,88 @@ func TestCollectionAccess(t *testing.T) {
 	}
 }
 
+// fakeSpaceStore resolves space roles from maps keyed by target id, for the
+// principal whose address or group it names.
+type fakeSpaceStore struct {
+	spaces.Store
+	collection map[string]string
+	asset      map[string]string
+	member     string
+	calls      int
+}
+
+func (f *fakeSpaceStore) role(roles map[string]string, id string, p spaces.Principal) string {
+	f.calls++
+	if p.Email == f.member || slices.Contains(p.Groups, f.member) {
+		return roles[id]
+	}
+	return ""
+}
+
+func (f *fakeSpaceStore) CollectionRole(_ context.Context, id string, p spaces.Principal) (string, error) {
+	return f.role(f.collection, id, p), nil
+}
+
+func (f *fakeSpaceStore) AssetRole(_ context.Context, id string, p spaces.Principal) (string, error) {
+	return f.role(f.asset, id, p), nil
+}
+
+func TestSpaceGrants(t *testing.T) {
+	asset := &portaldomain.Asset{ID: "a1", OwnerID: "u-owner"}
+	coll := &portaldomain.Collection{ID: "c1", OwnerID: "u-owner"}
+	ctx := context.Background()
+	newChecker := func(sp *fakeSpaceStore, shares *fakeShareStore) *Checker {
+		return New(Config{Shares: shares, Spaces: sp, Collections: &fakeCollectionStore{
+			colls: map[string]*portaldomain.Collection{"c1": coll},
+		}})
+	}
+
+	t.Run("a group member views what the space owns", func(t *testing.T) {
+		sp := &fakeSpaceStore{member: "finance",
+			collection: map[string]string{"c1": spaces.RoleViewer}, asset: map[string]string{"a1": spaces.RoleViewer}}
+		c := newChecker(sp, &fakeShareStore{})
+		member := &User{UserID: "u-fin", Email: "fin@example.com", Roles: []string{"finance"}}
+		assert.True(t, c.CanViewCollection(ctx, coll, member))
+		assert.False(t, c.CanEditCollectionSilent(ctx, "c1", member), "a viewer does not edit")
+		assert.True(t, c.CanViewAsset(ctx, "a1", asset, member))
+		perm, err := c.ResolveAssetPermission(ctx, "a1", member)
+		require.NoError(t, err)
+		assert.Equal(t, portaldomain.PermissionViewer, perm)
+		assert.False(t, c.CanViewCollection(ctx, coll, viewer()), "a non-member gets nothing")
+	})
+
+	t.Run("a manager edits the contents", func(t *testing.T) {
+		sp := &fakeSpaceStore{member: "view@example.com",
+			collection: map[string]string{"c1": spaces.RoleManager}, asset: map[string]string{"a1": spaces.RoleManager}}
+		c := newChecker(sp, &fakeShareStore{})
+		assert.True(t, c.CanEditCollectionSilent(ctx, "c1", viewer()))
+		perm, err := c.ResolveAssetPermission(ctx, "a1", viewer())
+		require.NoError(t, err)
+		assert.Equal(t, portaldomain.PermissionEditor, perm)
+	})
+
+	t.Run("the stronger of share and space wins", func(t *testing.T) {
+		sp := &fakeSpaceStore{member: "view@example.com", collection: map[string]string{"c1": spaces.RoleEditor}}
+		shares := &fakeShareStore{collectionPerm: map[string]portaldomain.SharePermission{"c1": portaldomain.PermissionViewer}}
+		assert.Equal(t, portaldomain.PermissionEditor, newChecker(sp, shares).CollectionSharePermission(ctx, "c1", viewer()))
+
+		sp = &fakeSpaceStore{member: "view@example.com", collection: map[string]string{"c1": spaces.RoleViewer}}
+		shares = &fakeShareStore{collectionPerm: map[string]portaldomain.SharePermission{"c1": portaldomain.PermissionEditor}}
+		assert.Equal(t, portaldomain.PermissionEditor, newChecker(sp, shares).CollectionSharePermission(ctx, "c1", viewer()))
+		assert.Zero(t, sp.calls, "an editor share is the ceiling; the space is not consulted")
+	})
+
+	t.Run("a role change applies on the next check", func(t *testing.T) {
+		sp := &fakeSpaceStore{member: "view@example.com", collection: map[string]string{"c1": spaces.RoleEditor}}
+		c := newChecker(sp, &fakeShareStore{})
+		assert.True(t, c.CanEditCollectionSilent(ctx, "c1", viewer()))
+		sp.collection["c1"] = spaces.RoleViewer
+		assert.False(t, c.CanEditCollectionSilent(ctx, "c1", viewer()))
+		delete(sp.collection, "c1")
+		assert.False(t, c.CanViewCollection(ctx, coll, viewer()))
+	})
+}
+
 // TestCanManage pins the seam every "only the owner can ..." gate now runs
 // through: the owner, an admin, and nobody else (#1293).
 func TestCanManage(t *testing.T) {
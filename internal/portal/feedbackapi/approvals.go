@@ -0,0 +1,446 @@
+package feedbackapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/internal/httpjson"
+	"github.com/txn2/mcp-data-platform/internal/portal/access"
+	"github.com/txn2/mcp-data-platform/internal/portal/portaldomain"
+	"github.com/txn2/mcp-data-platform/pkg/portal/signoff"
+)
+
+// Approval workflows: a collection's approval policy names who must sign off
+// on the assets it holds, and each approval or rejection is appended to an
+// immutable record. The sign-off summary above counts anyone's approval; a
+// policy is what a regulated report needs instead.
+
+// maxApprovalReasonLen caps the reason stored on a decision.
+const maxApprovalReasonLen = 2000
+
+// approvalPolicyRequest is the body for PUT /collections/{id}/approval-policy.
+type approvalPolicyRequest struct {
+	Mode  string         `json:"mode" example:"sequential"` // sequential | parallel
+	Steps []signoff.Step `json:"steps"`
+}
+
+// approvalsResponse is an asset's approval state on its current version under
+// every policy that applies to it, with the whole record.
+type approvalsResponse struct {
+	AssetID      string `json:"asset_id"`
+	AssetVersion int    `json:"asset_version"`
+	// State is approved when every policy is, rejected when any is, pending
+	// otherwise, and none when no policy applies.
+	State    string             `json:"state" example:"pending"`
+	Policies []signoff.Status   `json:"policies"`
+	Record   []signoff.Decision `json:"record"`
+}
+
+// approvalDecisionRequest is the body for POST /assets/{id}/approvals.
+type approvalDecisionRequest struct {
+	// CollectionID names the policy decided under; optional when one applies.
+	CollectionID string `json:"collection_id,omitempty"`
+	// AssetVersion is the version the approver reviewed. It must be the
+	// asset's current version, so a decision never lands on content the
+	// approver has not seen.
+	AssetVersion int    `json:"asset_version" example:"3"`
+	Decision     string `json:"decision" example:"approved"` // approved | rejected
+	Reason       string `json:"reason,omitempty"`
+	// Step is the step index to decide on, for a person named on several
+	// open steps; omitted picks the first.
+	Step *int `json:"step,omitempty"`
+}
+
+// RegisterApprovals wires the approval-policy and approval-record routes. It is
+// separate from Register because it needs the sign-off store rather than the
+// thread store.
+func (h *Handler) RegisterApprovals(mux *http.ServeMux) {
+	if h.cfg.Signoff == nil {
+		return
+	}
+	mux.HandleFunc("GET /api/v1/portal/collections/{id}/approval-policy", h.getApprovalPolicy)
+	mux.HandleFunc("PUT /api/v1/portal/collections/{id}/approval-policy", h.putApprovalPolicy)
+	mux.HandleFunc("DELETE /api/v1/portal/collections/{id}/approval-policy", h.deleteApprovalPolicy)
+	mux.HandleFunc("GET /api/v1/portal/assets/{id}/approvals", h.assetApprovals)
+	mux.HandleFunc("POST /api/v1/portal/assets/{id}/approvals", h.decideAssetApproval)
+	mux.HandleFunc("GET /api/v1/portal/assets/{id}/approvals/export", h.exportAssetApprovals)
+}
+
+// getApprovalPolicy handles GET /api/v1/portal/collections/{id}/approval-policy.
+//
+// @Summary      Get a collection's approval policy
+// @Description  Returns the approval policy applied to the collection's assets. Anyone who can view the collection can read it.
+// @Tags         Feedback
+// @Produce      json
+// @Param        id  path  string  true  "Collection ID"
+// @Success      200  {object}  signoff.Policy
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      403  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/collections/{id}/approval-policy [get]
+func (h *Handler) getApprovalPolicy(w http.ResponseWriter, r *http.Request) {
+	coll := h.policyCollection(w, r, false)
+	if coll == nil {
+		return
+	}
+	p, err := h.cfg.Signoff.GetPolicy(r.Context(), coll.ID)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to load approval policy")
+		return
+	}
+	if p == nil {
+		httpjson.WriteError(w, http.StatusNotFound, "collection has no approval policy")
+		return
+	}
+	httpjson.WriteJSON(w, http.StatusOK, p)
+}
+
+// putApprovalPolicy handles PUT /api/v1/portal/collections/{id}/approval-policy.
+//
+// @Summary      Set a collection's approval policy
+// @Description  Creates or replaces the approval policy. Owner or admin only. Every change is a new revision, and decisions made under an earlier revision no longer count.
+// @Tags         Feedback
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                 true  "Collection ID"
+// @Param        body  body  approvalPolicyRequest  true  "Policy"
+// @Success      200  {object}  signoff.Policy
+// @Failure      400  {object}  httpjson.ProblemDetail
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      403  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/collections/{id}/approval-policy [put]
+func (h *Handler) putApprovalPolicy(w http.ResponseWriter, r *http.Request) {
+	coll := h.policyCollection(w, r, true)
+	if coll == nil {
+		return
+	}
+	var req approvalPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, errInvalidRequestBody)
+		return
+	}
+	p := signoff.Policy{CollectionID: coll.ID, Mode: req.Mode, Steps: req.Steps, UpdatedBy: access.GetUser(r.Context()).Email}
+	if err := p.Normalize(); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	stored, err := h.cfg.Signoff.PutPolicy(r.Context(), p)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to save approval policy")
+		return
+	}
+	httpjson.WriteJSON(w, http.StatusOK, stored)
+}
+
+// deleteApprovalPolicy handles DELETE /api/v1/portal/collections/{id}/approval-policy.
+//
+// @Summary      Remove a collection's approval policy
+// @Description  Removes the policy. Owner or admin only. The approval record is kept.
+// @Tags         Feedback
+// @Param        id  path  string  true  "Collection ID"
+// @Success      204
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      403  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/collections/{id}/approval-policy [delete]
+func (h *Handler) deleteApprovalPolicy(w http.ResponseWriter, r *http.Request) {
+	coll := h.policyCollection(w, r, true)
+	if coll == nil {
+		return
+	}
+	if err := h.cfg.Signoff.DeletePolicy(r.Context(), coll.ID); err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to remove approval policy")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// policyCollection loads the path's collection and checks the caller may read
+// its policy, or with manage set, change it: owner authority, since a policy
+// is a governance control an editor must not be able to loosen. Writes the
+// error and returns nil on failure.
+func (h *Handler) policyCollection(w http.ResponseWriter, r *http.Request, manage bool) *portaldomain.Collection {
+	user := access.GetUser(r.Context())
+	if user == nil {
+		httpjson.WriteError(w, http.StatusUnauthorized, errAuthRequired)
+		return nil
+	}
+	coll, err := h.cfg.Collections.Get(r.Context(), r.PathValue(pathKeyID))
+	if err != nil || coll == nil || coll.DeletedAt != nil {
+		httpjson.WriteError(w, http.StatusNotFound, errCollectionNotFound)
+		return nil
+	}
+	allowed := h.access.CanManage(coll.OwnerID, user)
+	if !manage {
+		allowed = allowed || h.access.CanViewCollection(r.Context(), coll, user)
+	}
+	if !allowed {
+		httpjson.WriteError(w, http.StatusForbidden, errAccessDenied)
+		return nil
+	}
+	return coll
+}
+
+// assetApprovals handles GET /api/v1/portal/assets/{id}/approvals.
+//
+// @Summary      Asset approval state and record
+// @Description  The asset's approval state on its current version under each policy of a collection holding it, and the immutable record of every decision on every version.
+// @Tags         Feedback
+// @Produce      json
+// @Param        id  path  string  true  "Asset ID"
+// @Success      200  {object}  approvalsResponse
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      403  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/assets/{id}/approvals [get]
+func (h *Handler) assetApprovals(w http.ResponseWriter, r *http.Request) {
+	asset := h.approvalAsset(w, r)
+	if asset == nil {
+		return
+	}
+	resp, _, ok := h.loadApprovals(w, r, asset)
+	if ok {
+		httpjson.WriteJSON(w, http.StatusOK, resp)
+	}
+}
+
+// decideAssetApproval handles POST /api/v1/portal/assets/{id}/approvals.
+//
+// @Summary      Approve or reject an asset version
+// @Description  Records the caller's decision on the asset's current version under a collection's approval policy. The body names the version the caller reviewed; a decision on any other version is refused with 409. The caller must be named on an open step, by address or persona, and decides once per version. A rejection needs a reason.
+// @Tags         Feedback
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                   true  "Asset ID"
+// @Param        body  body  approvalDecisionRequest  true  "Decision"
+// @Success      201  {object}  approvalsResponse
+// @Failure      400  {object}  httpjson.ProblemDetail
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      403  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Failure      409  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/assets/{id}/approvals [post]
+func (h *Handler) decideAssetApproval(w http.ResponseWriter, r *http.Request) {
+	asset := h.approvalAsset(w, r)
+	if asset == nil {
+		return
+	}
+	var req approvalDecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, errInvalidRequestBody)
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if msg := req.invalid(); msg != "" {
+		httpjson.WriteError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if req.AssetVersion != asset.CurrentVersion {
+		httpjson.WriteError(w, http.StatusConflict, fmt.Sprintf(
+			"asset_version %d is not the current version %d; review the current version before deciding",
+			req.AssetVersion, asset.CurrentVersion))
+		return
+	}
+	resp, policies, ok := h.loadApprovals(w, r, asset)
+	if !ok {
+		return
+	}
+	i := policyIndex(policies, req.CollectionID)
+	if i < 0 {
+		httpjson.WriteError(w, http.StatusBadRequest, "collection_id must name one policy that applies to this asset")
+		return
+	}
+
+	user := access.GetUser(r.Context())
+	approver := h.approver(user)
+	stepPick := -1
+	if req.Step != nil {
+		stepPick = *req.Step
+	}
+	step, err := signoff.Admit(policies[i], resp.Policies[i], approver, stepPick)
+	if err != nil {
+		writeApprovalError(w, err)
+		return
+	}
+	d := signoff.Decision{
+		ID: signoff.NewDecisionID(), AssetID: asset.ID, AssetVersion: asset.CurrentVersion,
+		CollectionID: policies[i].CollectionID, PolicyRevision: policies[i].Revision,
+		Step: step, StepName: policies[i].Steps[step].Name, Decision: req.Decision, Reason: req.Reason,
+		ApproverID: user.UserID, ApproverEmail: user.Email, ApproverPersona: approver.Persona,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := h.cfg.Signoff.RecordDecision(r.Context(), d); err != nil {
+		writeApprovalError(w, err)
+		return
+	}
+	resp.Record = append(resp.Record, d)
+	resp.Policies[i] = signoff.Evaluate(policies[i], asset.CurrentVersion, resp.Record)
+	resp.State = overallApprovalState(resp.Policies)
+	httpjson.WriteJSON(w, http.StatusCreated, resp)
+}
+
+// approver is the signoff identity of the deciding user: their address and
+// the persona their roles resolve to.
+func (h *Handler) approver(user *access.User) signoff.Approver {
+	a := signoff.Approver{UserID: user.UserID, Email: user.Email}
+	if h.cfg.PersonaName != nil {
+		a.Persona = h.cfg.PersonaName(user.Roles)
+	}
+	return a
+}
+
+// invalid returns what is wrong with the decision request, or "".
+func (req approvalDecisionRequest) invalid() string {
+	switch {
+	case req.AssetVersion <= 0:
+		return "asset_version is required"
+	case req.Decision != signoff.DecisionApproved && req.Decision != signoff.DecisionRejected:
+		return "decision must be 'approved' or 'rejected'"
+	case req.Decision == signoff.DecisionRejected && req.Reason == "":
+		return "a rejection needs a reason"
+	case len(req.Reason) > maxApprovalReasonLen:
+		return "reason is too long"
+	}
+	return ""
+}
+
+// exportAssetApprovals handles GET /api/v1/portal/assets/{id}/approvals/export.
+//
+// @Summary      Export an asset's approval record
+// @Description  The full approval record for auditors, as CSV (the default) or, with format=json, the same body as the approvals read.
+// @Tags         Feedback
+// @Produce      text/csv
+// @Produce      json
+// @Param        id      path   string  true   "Asset ID"
+// @Param        format  query  string  false  "csv or json"
+// @Success      200  {string}  string
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      403  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/assets/{id}/approvals/export [get]
+func (h *Handler) exportAssetApprovals(w http.ResponseWriter, r *http.Request) {
+	asset := h.approvalAsset(w, r)
+	if asset == nil {
+		return
+	}
+	resp, _, ok := h.loadApprovals(w, r, asset)
+	if !ok {
+		return
+	}
+	name := "approvals-" + asset.ID
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Disposition", `attachment; filename="`+name+`.json"`)
+		httpjson.WriteJSON(w, http.StatusOK, resp)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`.csv"`)
+	_ = signoff.WriteCSV(w, resp.Record) //nolint:errcheck // headers are sent; a write failure is the client going away
+}
+
+// approvalAsset loads the path's asset and checks the caller may view it.
+// Writes the error and returns nil on failure.
+func (h *Handler) approvalAsset(w http.ResponseWriter, r *http.Request) *portaldomain.Asset {
+	user := access.GetUser(r.Context())
+	if user == nil {
+		httpjson.WriteError(w, http.StatusUnauthorized, errAuthRequired)
+		return nil
+	}
+	id := r.PathValue(pathKeyID)
+	asset, err := h.cfg.Assets.Get(r.Context(), id)
+	if err != nil || asset == nil || asset.DeletedAt != nil {
+		httpjson.WriteError(w, http.StatusNotFound, errAssetNotFound)
+		return nil
+	}
+	if !h.assetViewable(w, r, id, asset, user) {
+		return nil
+	}
+	return asset
+}
+
+// loadApprovals evaluates every policy applying to the asset on its current
+// version against its record. Writes the error and reports false on failure.
+func (h *Handler) loadApprovals(w http.ResponseWriter, r *http.Request, asset *portaldomain.Asset) (approvalsResponse, []signoff.Policy, bool) {
+	policies, err := h.cfg.Signoff.PoliciesForAsset(r.Context(), asset.ID)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to load approval policies")
+		return approvalsResponse{}, nil, false
+	}
+	record, err := h.cfg.Signoff.ListDecisions(r.Context(), asset.ID)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to load approval record")
+		return approvalsResponse{}, nil, false
+	}
+	resp := approvalsResponse{
+		AssetID: asset.ID, AssetVersion: asset.CurrentVersion,
+		Policies: make([]signoff.Status, len(policies)), Record: record,
+	}
+	for i, p := range policies {
+		resp.Policies[i] = signoff.Evaluate(p, asset.CurrentVersion, record)
+	}
+	resp.State = overallApprovalState(resp.Policies)
+	return resp, policies, true
+}
+
+// overallApprovalState folds the per-policy states into the asset's.
+func overallApprovalState(statuses []signoff.Status) string {
+	if len(statuses) == 0 {
+		return "none"
+	}
+	state := signoff.StateApproved
+	for _, s := range statuses {
+		switch s.State {
+		case signoff.StateRejected:
+			return signoff.StateRejected
+		case signoff.StatePending:
+			state = signoff.StatePending
+		}
+	}
+	return state
+}
+
+// policyIndex finds the policy a decision names: collectionID's, or the only
+// one when it names none. -1 when there is no such single policy.
+func policyIndex(policies []signoff.Policy, collectionID string) int {
+	if collectionID == "" {
+		if len(policies) == 1 {
+			return 0
+		}
+		return -1
+	}
+	for i, p := range policies {
+		if p.CollectionID == collectionID {
+			return i
+		}
+	}
+	return -1
+}
+
+// writeApprovalError maps a refused decision to its status.
+func writeApprovalError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, signoff.ErrNotApprover):
+		httpjson.WriteError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, signoff.ErrAlreadyDecided), errors.Is(err, signoff.ErrStepClosed):
+		httpjson.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to record approval decision")
+	}
+}
@@ -0,0 +1,214 @@
+package feedbackapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/internal/portal/access"
+	"github.com/txn2/mcp-data-platform/internal/portal/portaldomain"
+	"github.com/txn2/mcp-data-platform/pkg/portal/signoff"
+)
+
+// mockSignoffStore keeps policies and the record in memory.
+type mockSignoffStore struct {
+	policies map[string]signoff.Policy
+	record   []signoff.Decision
+	putErr   error
+}
+
+func (m *mockSignoffStore) GetPolicy(_ context.Context, collectionID string) (*signoff.Policy, error) {
+	if p, ok := m.policies[collectionID]; ok {
+		return &p, nil
+	}
+	return nil, nil //nolint:nilnil // test double: no policy
+}
+
+func (m *mockSignoffStore) PutPolicy(_ context.Context, p signoff.Policy) (*signoff.Policy, error) {
+	if m.putErr != nil {
+		return nil, m.putErr
+	}
+	if m.policies == nil {
+		m.policies = map[string]signoff.Policy{}
+	}
+	p.Revision = m.policies[p.CollectionID].Revision + 1
+	m.policies[p.CollectionID] = p
+	return &p, nil
+}
+
+func (m *mockSignoffStore) DeletePolicy(_ context.Context, collectionID string) error {
+	delete(m.policies, collectionID)
+	return nil
+}
+
+func (m *mockSignoffStore) PoliciesForAsset(context.Context, string) ([]signoff.Policy, error) {
+	var out []signoff.Policy
+	for _, p := range m.policies {
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func (m *mockSignoffStore) RecordDecision(_ context.Context, d signoff.Decision) error {
+	m.record = append(m.record, d)
+	return nil
+}
+
+func (m *mockSignoffStore) ListDecisions(context.Context, string) ([]signoff.Decision, error) {
+	return append([]signoff.Decision{}, m.record...), nil
+}
+
+func newApprovalTestHandler(store *mockSignoffStore, version int, user *access.User) http.Handler {
+	return newTestServer(Config{
+		Assets:      &mockAssetStore{getAsset: &portaldomain.Asset{ID: "asset_1", OwnerID: "owner", CurrentVersion: version}},
+		Collections: &mockCollectionStore{getResult: &portaldomain.Collection{ID: "col_1", OwnerID: "owner"}},
+		Shares:      &mockShareStore{collAssetPerm: portaldomain.PermissionViewer, collPerm: portaldomain.PermissionViewer},
+		Signoff:     store,
+		PersonaName: func(roles []string) string {
+			if len(roles) > 0 {
+				return roles[0]
+			}
+			return ""
+		},
+	}, user)
+}
+
+// regulated is a two-step sequential policy: an analyst, then the qa persona.
+func regulated() *mockSignoffStore {
+	return &mockSignoffStore{policies: map[string]signoff.Policy{"col_1": {
+		CollectionID: "col_1", Mode: signoff.ModeSequential, Revision: 1, Steps: []signoff.Step{
+			{Name: "Analyst", Required: 1, Approvers: []string{"ana@x.io"}},
+			{Name: "QA", Required: 1, Personas: []string{"qa"}},
+		},
+	}}}
+}
+
+func decodeApprovals(t *testing.T, body []byte) approvalsResponse {
+	t.Helper()
+	var resp approvalsResponse
+	require.NoError(t, json.Unmarshal(body, &resp))
+	return resp
+}
+
+func TestPutApprovalPolicy(t *testing.T) {
+	store := &mockSignoffStore{}
+	owner := &access.User{UserID: "owner", Email: "owner@x.io"}
+	body := approvalPolicyRequest{Mode: signoff.ModeSequential, Steps: []signoff.Step{{Required: 1, Approvers: []string{"Ana@X.io"}}}}
+
+	w := doThreadReq(t, newApprovalTestHandler(store, 1, owner), http.MethodPut, "/api/v1/portal/collections/col_1/approval-policy", body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{"ana@x.io"}, store.policies["col_1"].Steps[0].Approvers)
+	assert.Equal(t, "owner@x.io", store.policies["col_1"].UpdatedBy)
+
+	w = doThreadReq(t, newApprovalTestHandler(store, 1, owner), http.MethodPut, "/api/v1/portal/collections/col_1/approval-policy",
+		approvalPolicyRequest{Steps: []signoff.Step{{Required: 1}}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	viewer := &access.User{UserID: "ana", Email: "ana@x.io"}
+	w = doThreadReq(t, newApprovalTestHandler(store, 1, viewer), http.MethodPut, "/api/v1/portal/collections/col_1/approval-policy", body)
+	assert.Equal(t, http.StatusForbidden, w.Code, "a viewer cannot change the policy")
+	w = doThreadReq(t, newApprovalTestHandler(store, 1, viewer), http.MethodGet, "/api/v1/portal/collections/col_1/approval-policy", nil)
+	assert.Equal(t, http.StatusOK, w.Code, "but can read it")
+	w = doThreadReq(t, newApprovalTestHandler(store, 1, viewer), http.MethodDelete, "/api/v1/portal/collections/col_1/approval-policy", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doThreadReq(t, newApprovalTestHandler(store, 1, owner), http.MethodDelete, "/api/v1/portal/collections/col_1/approval-policy", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doThreadReq(t, newApprovalTestHandler(store, 1, owner), http.MethodGet, "/api/v1/portal/collections/col_1/approval-policy", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDecideAssetApprovalSequential(t *testing.T) {
+	store := regulated()
+	ana := &access.User{UserID: "ana", Email: "ana@x.io"}
+	qa := &access.User{UserID: "qa1", Email: "qa1@x.io", Roles: []string{"qa"}}
+	approve := approvalDecisionRequest{AssetVersion: 3, Decision: signoff.DecisionApproved}
+
+	w := doThreadReq(t, newApprovalTestHandler(store, 3, qa), http.MethodPost, "/api/v1/portal/assets/asset_1/approvals", approve)
+	assert.Equal(t, http.StatusForbidden, w.Code, "QA waits on the analyst")
+
+	w = doThreadReq(t, newApprovalTestHandler(store, 3, ana), http.MethodPost, "/api/v1/portal/assets/asset_1/approvals", approve)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	resp := decodeApprovals(t, w.Body.Bytes())
+	assert.Equal(t, signoff.StatePending, resp.State)
+	assert.Equal(t, signoff.StatePending, resp.Policies[0].Steps[1].State, "the QA step is open")
+
+	w = doThreadReq(t, newApprovalTestHandler(store, 3, ana), http.MethodPost, "/api/v1/portal/assets/asset_1/approvals", approve)
+	assert.Equal(t, http.StatusConflict, w.Code, "one decision per person per version")
+
+	w = doThreadReq(t, newApprovalTestHandler(store, 3, qa), http.MethodPost, "/api/v1/portal/assets/asset_1/approvals", approve)
+	require.Equal(t, http.StatusCreated, w.Code)
+	resp = decodeApprovals(t, w.Body.Bytes())
+	assert.Equal(t, signoff.StateApproved, resp.State)
+	require.Len(t, resp.Record, 2)
+	assert.Equal(t, "qa", resp.Record[1].ApproverPersona)
+	assert.Equal(t, 3, resp.Record[1].AssetVersion)
+
+	// A new version invalidates the approval; the record keeps it.
+	w = doThreadReq(t, newApprovalTestHandler(store, 4, ana), http.MethodGet, "/api/v1/portal/assets/asset_1/approvals", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	resp = decodeApprovals(t, w.Body.Bytes())
+	assert.Equal(t, signoff.StatePending, resp.State)
+	assert.Equal(t, 4, resp.AssetVersion)
+	assert.Len(t, resp.Record, 2)
+}
+
+func TestDecideAssetApprovalValidation(t *testing.T) {
+	ana := &access.User{UserID: "ana", Email: "ana@x.io"}
+	tests := []struct {
+		name string
+		req  approvalDecisionRequest
+		code int
+	}{
+		{"unknown decision", approvalDecisionRequest{AssetVersion: 1, Decision: "maybe"}, http.StatusBadRequest},
+		{"rejection without a reason", approvalDecisionRequest{AssetVersion: 1, Decision: signoff.DecisionRejected, Reason: " "}, http.StatusBadRequest},
+		{"unknown collection", approvalDecisionRequest{AssetVersion: 1, Decision: signoff.DecisionApproved, CollectionID: "col_9"}, http.StatusBadRequest},
+		{"no version", approvalDecisionRequest{Decision: signoff.DecisionApproved}, http.StatusBadRequest},
+		{"stale version", approvalDecisionRequest{AssetVersion: 2, Decision: signoff.DecisionApproved}, http.StatusConflict},
+		{"rejection with a reason", approvalDecisionRequest{AssetVersion: 1, Decision: signoff.DecisionRejected, Reason: "totals disagree"}, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doThreadReq(t, newApprovalTestHandler(regulated(), 1, ana), http.MethodPost, "/api/v1/portal/assets/asset_1/approvals", tt.req)
+			assert.Equal(t, tt.code, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestAssetApprovalsNoPolicy(t *testing.T) {
+	w := doThreadReq(t, newApprovalTestHandler(&mockSignoffStore{}, 1, &access.User{UserID: "owner"}),
+		http.MethodGet, "/api/v1/portal/assets/asset_1/approvals", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	resp := decodeApprovals(t, w.Body.Bytes())
+	assert.Equal(t, "none", resp.State)
+	assert.Empty(t, resp.Policies)
+}
+
+func TestExportAssetApprovals(t *testing.T) {
+	store := regulated()
+	store.record = []signoff.Decision{{
+		ID: "sgn_1", AssetID: "asset_1", AssetVersion: 1, CollectionID: "col_1", PolicyRevision: 1,
+		Decision: signoff.DecisionApproved, ApproverID: "ana", ApproverEmail: "ana@x.io",
+	}}
+	h := newApprovalTestHandler(store, 1, &access.User{UserID: "owner"})
+
+	w := doThreadReq(t, h, http.MethodGet, "/api/v1/portal/assets/asset_1/approvals/export", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "approvals-asset_1.csv")
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[1], "ana@x.io")
+
+	w = doThreadReq(t, h, http.MethodGet, "/api/v1/portal/assets/asset_1/approvals/export?format=json", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, decodeApprovals(t, w.Body.Bytes()).Record, 1)
+
+	w = doThreadReq(t, newApprovalTestHandler(store, 1, nil), http.MethodGet, "/api/v1/portal/assets/asset_1/approvals/export", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -1,7 +1,7 @@
 // Package feedbackapi holds the portal's feedback surface: threads and their
 // events, the activity feed, the practitioner and SME worklists, asset and
-// collection sign-off, validation responses, and capturing a thread as an
-// insight.
+// collection sign-off, collection approval policies and the approval record,
+// validation responses, and capturing a thread as an insight.
 //
 // These routes are one family. They share a target model (a thread hangs off an
 // asset, a collection, a prompt, a knowledge page, or nothing), one set of
@@ -25,6 +25,7 @@ import (
 	"github.com/txn2/mcp-data-platform/internal/portal/portaldomain"
 	"github.com/txn2/mcp-data-platform/pkg/memory"
 	"github.com/txn2/mcp-data-platform/pkg/portal/knowledgepage"
+	"github.com/txn2/mcp-data-platform/pkg/portal/signoff"
 	"github.com/txn2/mcp-data-platform/pkg/portal/threads"
 	"github.com/txn2/mcp-data-platform/pkg/prompt"
 	"github.com/txn2/mcp-data-platform/pkg/toolkits/knowledge"
@@ -106,6 +107,13 @@ type Config struct {
 	// PersonaName resolves a caller's roles to their persona name, the value
 	// stamped on a captured insight. nil yields no persona.
 	PersonaName func(roles []string) string
+	// Content reads an asset's or knowledge page's current body, so a thread
+	// anchored to a region of it is pinned when it is opened and carried to
+	// the current version when it is read. nil stores anchors as sent.
+	Content threads.ContentReader
+	// Signoff holds collection approval policies and the approval record. nil
+	// leaves the approval routes unregistered.
+	Signoff signoff.Store
 }
 
 // Handler serves the portal's feedback routes.
@@ -304,5 +304,6 @@ func newTestServer(cfg Config, user *access.User) http.Handler {
 	h := New(cfg)
 	h.Register(mux)
 	h.RegisterInsightCapture(mux)
+	h.RegisterApprovals(mux)
 	return testAuthMiddleware(user)(mux)
 }
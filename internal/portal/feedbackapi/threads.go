@@ -1,8 +1,11 @@
 package feedbackapi
 
 import (
+	"cmp"
+	"context"
 	"encoding/json"
 	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
@@ -123,6 +126,11 @@ func (h *Handler) listThreads(w http.ResponseWriter, r *http.Request) {
 	if found == nil {
 		found = []threads.ThreadWithMeta{}
 	}
+	list := make([]*threads.Thread, len(found))
+	for i := range found {
+		list[i] = &found[i].Thread
+	}
+	h.carryAnchors(r.Context(), targetType, cmp.Or(filter.AssetID, filter.KnowledgePageID), list)
 	httpjson.WriteJSON(w, http.StatusOK, pagedResponse{
 		Data: found, Total: total, Limit: filter.EffectiveLimit(), Offset: filter.Offset,
 	})
@@ -184,6 +192,9 @@ func (h *Handler) createThread(w http.ResponseWriter, r *http.Request) {
 		Status:             threads.ThreadStatusOpen,
 		RequiresResolution: req.RequiresResolution,
 	}
+	if !h.pinAnchor(w, r, &thread) {
+		return
+	}
 	first := threads.ThreadEvent{
 		ID:          threads.NewThreadID("evt"),
 		ThreadID:    thread.ID,
@@ -222,9 +233,75 @@ func (h *Handler) getThread(w http.ResponseWriter, r *http.Request) {
 	if thread == nil {
 		return
 	}
+	h.carryAnchors(r.Context(), thread.TargetType, thread.TargetID(), []*threads.Thread{thread})
 	httpjson.WriteJSON(w, http.StatusOK, thread)
 }
 
+// pinAnchor resolves a new thread's document anchor against the content it is
+// being left on, and records that content's version as the thread's target
+// version when the caller sent none. An anchor naming text or a region the
+// content does not have is refused with a 400; content that cannot be read
+// (a binary asset) leaves the anchor as sent.
+func (h *Handler) pinAnchor(w http.ResponseWriter, r *http.Request, t *threads.Thread) bool {
+	if h.cfg.Content == nil || !threads.AnchorableTarget(t.TargetType) {
+		return true
+	}
+	if _, ok := threads.ParseDocumentAnchor(t.Anchor); !ok {
+		return true
+	}
+	content, err := h.cfg.Content(r.Context(), t.TargetType, t.TargetID())
+	if err != nil {
+		return true
+	}
+	pinned, err := threads.PinAnchor(t.Anchor, content)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, "anchor does not resolve: "+err.Error())
+		return false
+	}
+	t.Anchor = pinned
+	if t.TargetVersion == 0 {
+		t.TargetVersion = content.Version
+	}
+	return true
+}
+
+// carryAnchors brings the document anchors of one target's threads forward to
+// its current content, persisting each one that moved or was orphaned.
+// Anchors are carried on read rather than on write because every writer of
+// every content kind (a patch, a full update, a revert, a knowledge apply or
+// rollback) moves the version, and the read compares against it; the
+// content is read once per call, and only when an anchor is behind.
+func (h *Handler) carryAnchors(ctx context.Context, targetType, targetID string, list []*threads.Thread) {
+	if h.cfg.Content == nil || !threads.AnchorableTarget(targetType) || !anyDocumentAnchor(list) {
+		return
+	}
+	content, err := h.cfg.Content(ctx, targetType, targetID)
+	if err != nil {
+		return
+	}
+	writer, _ := h.cfg.Threads.(threads.AnchorWriter)
+	for _, t := range list {
+		changed, err := threads.CarryAnchor(t, content)
+		if err != nil || !changed || writer == nil {
+			continue
+		}
+		if err := writer.UpdateAnchor(ctx, t.ID, t.Anchor); err != nil {
+			slog.Warn("portal: failed to persist a carried thread anchor", "thread_id", t.ID, logKeyError, err)
+		}
+	}
+}
+
+// anyDocumentAnchor reports whether any thread carries a document anchor, so
+// a list without one never reads the target's content.
+func anyDocumentAnchor(list []*threads.Thread) bool {
+	for _, t := range list {
+		if _, ok := threads.ParseDocumentAnchor(t.Anchor); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // listThreadEvents handles GET /api/v1/portal/threads/{id}/events.
 //
 // @Summary      List thread events
@@ -712,3 +712,106 @@ func TestThreadRoutesAbsentWithoutStore(t *testing.T) {
 	// Without a thread store the route is unregistered → 404.
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
+
+// --- document anchors ---
+
+// anchorWritingStore is a thread store that also persists carried anchors.
+type anchorWritingStore struct {
+	mockThreadStore
+	anchors map[string]json.RawMessage
+}
+
+func (m *anchorWritingStore) UpdateAnchor(_ context.Context, id string, anchor json.RawMessage) error {
+	if m.anchors == nil {
+		m.anchors = map[string]json.RawMessage{}
+	}
+	m.anchors[id] = anchor
+	return nil
+}
+
+func staticContent(c threads.TargetContent, err error) threads.ContentReader {
+	return func(context.Context, string, string) (threads.TargetContent, error) { return c, err }
+}
+
+func newAnchorTestHandler(store threads.ThreadStore, content threads.ContentReader) http.Handler {
+	return newTestServer(Config{Assets: ownedAsset("u1"), Shares: &mockShareStore{}, Threads: store, Content: content},
+		&access.User{UserID: "u1", Email: "u1@example.com"})
+}
+
+func TestCreateThreadPinsAnchor(t *testing.T) {
+	report := threads.TargetContent{Body: `<section id="kpi"><p>Churn fell to 3%.</p></section>`, ContentType: "text/html", Version: 4}
+	quote := createThreadRequest{
+		Kind: threads.ThreadKindComment, TargetType: portaldomain.TargetTypeAsset, AssetID: "asset_1", Body: "source?",
+		Anchor: json.RawMessage(`{"type":"text_quote","exact":"fell to 3%"}`),
+	}
+
+	t.Run("resolved and scoped", func(t *testing.T) {
+		store := &mockThreadStore{}
+		w := doThreadReq(t, newAnchorTestHandler(store, staticContent(report, nil)), http.MethodPost, "/api/v1/portal/threads", quote)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		a, ok := threads.ParseDocumentAnchor(store.lastCreated.Anchor)
+		require.True(t, ok)
+		assert.Equal(t, "#kpi", a.Selector)
+		assert.Equal(t, threads.AnchorStateActive, a.State)
+		assert.Equal(t, 4, store.lastCreated.TargetVersion, "the version the comment was left on")
+	})
+
+	t.Run("text the content lacks is refused", func(t *testing.T) {
+		bad := quote
+		bad.Anchor = json.RawMessage(`{"type":"text_quote","exact":"rose to 9%"}`)
+		store := &mockThreadStore{}
+		w := doThreadReq(t, newAnchorTestHandler(store, staticContent(report, nil)), http.MethodPost, "/api/v1/portal/threads", bad)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Nil(t, store.lastCreated)
+	})
+
+	t.Run("unreadable content stores the anchor as sent", func(t *testing.T) {
+		store := &mockThreadStore{}
+		w := doThreadReq(t, newAnchorTestHandler(store, staticContent(threads.TargetContent{}, errNoRow)), http.MethodPost, "/api/v1/portal/threads", quote)
+		require.Equal(t, http.StatusCreated, w.Code)
+		assert.JSONEq(t, string(quote.Anchor), string(store.lastCreated.Anchor))
+	})
+}
+
+func TestListThreadsCarriesAnchors(t *testing.T) {
+	v1 := threads.TargetContent{Body: "# Plan\n\nShip in May.\n", ContentType: "text/markdown", Version: 1}
+	pinned, err := threads.PinAnchor(json.RawMessage(`{"type":"text_quote","exact":"Ship in May"}`), v1)
+	require.NoError(t, err)
+	store := &anchorWritingStore{mockThreadStore: mockThreadStore{listResult: []threads.ThreadWithMeta{
+		{Thread: threads.Thread{ID: "thr_1", TargetType: portaldomain.TargetTypeAsset, AssetID: "asset_1", Anchor: pinned}},
+		{Thread: threads.Thread{ID: "thr_2", TargetType: portaldomain.TargetTypeAsset, AssetID: "asset_1"}},
+	}, listTotal: 2}}
+
+	v2 := threads.TargetContent{Body: "# Plan\n\nShip in June.\n", ContentType: "text/markdown", Version: 2}
+	w := doThreadReq(t, newAnchorTestHandler(store, staticContent(v2, nil)), http.MethodGet, "/api/v1/portal/threads?asset_id=asset_1", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data []threads.ThreadWithMeta `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	a, ok := threads.ParseDocumentAnchor(resp.Data[0].Anchor)
+	require.True(t, ok)
+	assert.Equal(t, threads.AnchorStateOrphaned, a.State)
+	assert.Equal(t, 2, a.OrphanedAtVersion)
+	require.Contains(t, store.anchors, "thr_1", "the carried anchor is persisted")
+	assert.NotContains(t, store.anchors, "thr_2")
+}
+
+func TestGetThreadCarriesAnchor(t *testing.T) {
+	v1 := threads.TargetContent{Body: "Alpha. Beta.", ContentType: "text/plain", Version: 1}
+	pinned, err := threads.PinAnchor(json.RawMessage(`{"type":"text_quote","exact":"Beta"}`), v1)
+	require.NoError(t, err)
+	store := &anchorWritingStore{mockThreadStore: mockThreadStore{getResult: &threads.Thread{
+		ID: "thr_1", TargetType: portaldomain.TargetTypeAsset, AssetID: "asset_1", Anchor: pinned,
+	}}}
+
+	v2 := threads.TargetContent{Body: "Intro. Alpha. Beta.", ContentType: "text/plain", Version: 2}
+	w := doThreadReq(t, newAnchorTestHandler(store, staticContent(v2, nil)), http.MethodGet, "/api/v1/portal/threads/thr_1", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	a, ok := threads.ParseDocumentAnchor(store.anchors["thr_1"])
+	require.True(t, ok)
+	assert.Equal(t, threads.AnchorStateActive, a.State)
+	assert.Equal(t, 2, a.Version)
+	assert.Equal(t, "Intro. Alpha. ", a.Prefix)
+}
@@ -2,6 +2,7 @@ package portaldomain
 
 import (
 	"context"
+	"net/url"
 	"strings"
 )
 
@@ -94,20 +95,53 @@ func clampSearchLimit(limit int) int {
 // identity, differing configured email, empty on legacy rows). A nil Embedding
 // selects lexical-only ranking (the graceful-degradation path when no embedding
 // provider is configured); a non-nil Embedding selects hybrid ranking.
+//
+// SpaceCollectionIDs widens the scope to the assets held by those collections:
+// the collections owned by team spaces the caller is a member of, resolved by
+// the caller's handler. Empty leaves the search owner-only.
+//
+// Passages also ranks the passages indexed from inside each asset's content,
+// so an asset whose body matches is found even when its name does not, and a
+// result carries the passage that matched.
 type AssetSearchQuery struct {
-	Embedding []float32 // query vector; nil selects lexical-only ranking
-	QueryText string    // raw query text for the lexical arm
-	OwnerID   string    // caller identity; mandatory owner scope (owner_id)
-	Limit     int       // max results; clamped into [1, maxSearchLimit]
+	Embedding          []float32 // query vector; nil selects lexical-only ranking
+	QueryText          string    // raw query text for the lexical arm
+	OwnerID            string    // caller identity; mandatory owner scope (owner_id)
+	SpaceCollectionIDs []string  // caller's team-space collections; widens the scope
+	Passages           bool      // also rank indexed content passages
+	Limit              int       // max results; clamped into [1, maxSearchLimit]
 }
 
 // EffectiveLimit clamps the requested limit into the search bounds.
 func (q AssetSearchQuery) EffectiveLimit() int { return clampSearchLimit(q.Limit) }
 
-// ScoredAsset pairs an asset with its relevance score in [0,1].
+// ScoredAsset pairs an asset with its relevance score in [0,1]. Passage is the
+// content passage that matched, set only on a passage search where one did.
 type ScoredAsset struct {
-	Asset Asset   `json:"asset"`
-	Score float64 `json:"score"`
+	Asset   Asset         `json:"asset"`
+	Score   float64       `json:"score"`
+	Passage *AssetPassage `json:"passage,omitempty"`
+}
+
+// AssetPassage is the passage inside an asset's content that matched a search:
+// the section it sits under, an excerpt around the match, and a portal link
+// that opens the asset at it.
+type AssetPassage struct {
+	Section  string `json:"section,omitempty" example:"Quarterly Report > Findings"`
+	Fragment string `json:"fragment,omitempty" example:"findings"`
+	Excerpt  string `json:"excerpt" example:"Revenue grew 12% year over year"`
+	Link     string `json:"link" example:"/portal/assets/abc123#findings"`
+}
+
+// AssetPassageLink is the portal path that opens an asset at a passage: the
+// asset's page with the passage's fragment, or the page alone when the
+// passage has none (the lead of a document).
+func AssetPassageLink(assetID, fragment string) string {
+	link := "/portal/assets/" + url.PathEscape(assetID)
+	if fragment != "" {
+		link += "#" + url.PathEscape(fragment)
+	}
+	return link
 }
 
 // AssetSearcher ranks the caller's assets by relevance to a query. It is a
@@ -121,12 +155,14 @@ type AssetSearcher interface {
 
 // CollectionSearchQuery describes a relevance ranking request over curated
 // collections, scoped to the caller's own non-deleted collections by owner_id
-// (the ownership key, as for assets — see AssetSearchQuery).
+// (the ownership key, as for assets — see AssetSearchQuery), plus the
+// collections named in SpaceCollectionIDs.
 type CollectionSearchQuery struct {
-	Embedding []float32 // query vector; nil selects lexical-only ranking
-	QueryText string    // raw query text for the lexical arm
-	OwnerID   string    // caller identity; mandatory owner scope (owner_id)
-	Limit     int       // max results; clamped into [1, maxSearchLimit]
+	Embedding          []float32 // query vector; nil selects lexical-only ranking
+	QueryText          string    // raw query text for the lexical arm
+	OwnerID            string    // caller identity; mandatory owner scope (owner_id)
+	SpaceCollectionIDs []string  // caller's team-space collections; widens the scope
+	Limit              int       // max results; clamped into [1, maxSearchLimit]
 }
 
 // EffectiveLimit clamps the requested limit into the search bounds.
@@ -0,0 +1,207 @@
+package portalstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pgvector/pgvector-go"
+
+	"github.com/txn2/mcp-data-platform/internal/portal/portaldomain"
+)
+
+// passageFTSExpr is the full-text expression the passage arms match and rank
+// against, the same call idx_portal_asset_content_chunks_fts (migration 000139)
+// is built on so the planner can use it.
+const passageFTSExpr = `to_tsvector('english', c.passage)`
+
+// passageFrom joins each passage chunk to its asset, so the scope predicate
+// and the result columns read the asset row. The chunk table shares no column
+// name with assetSearchColumns, so neither needs qualifying.
+const passageFrom = `portal_asset_content_chunks c JOIN portal_assets ON portal_assets.id = c.asset_id`
+
+// passageColumns follows assetSearchColumns in every passage SELECT, in
+// collectPassageHits scan order.
+const passageColumns = `c.section, c.fragment, c.passage`
+
+// passagesPerAsset widens each passage arm's top-k, since several chunks of
+// one asset can crowd the head of the ranking before they are deduped.
+const passagesPerAsset = 4
+
+// excerptBytes bounds the excerpt a passage hit returns. A chunk is sized for
+// the embedding provider, far more than a result list can show.
+const excerptBytes = 280
+
+// searchAssetPassages ranks the passage chunks of the assets in scope and
+// returns the best-scoring chunk per asset, scored on the same scale as the
+// metadata arms: fused hybrid scores when q carries an embedding, normalized
+// ts_rank_cd otherwise.
+func (s *postgresAssetStore) searchAssetPassages(ctx context.Context, q portaldomain.AssetSearchQuery) ([]portaldomain.ScoredAsset, error) {
+	k := q.EffectiveLimit() * passagesPerAsset
+	var (
+		query string
+		args  []any
+	)
+	// #nosec G201 -- column lists, expressions and the join are constants; the
+	// scope uses only parameterized placeholders; k and the normalization
+	// bitmask are sanitized ints. No user input is concatenated into the SQL.
+	if len(q.Embedding) > 0 {
+		var base string
+		base, args = assetScope(q, []any{pgvector.NewVector(q.Embedding), q.QueryText})
+		vecArm := fmt.Sprintf(
+			"SELECT %s, %s, 1 - (c.embedding <=> $1) AS vec_score, (%s @@ %s) AS lex_match "+
+				"FROM %s WHERE c.embedding IS NOT NULL AND %s "+
+				"ORDER BY c.embedding <=> $1 LIMIT %d",
+			assetSearchColumns, passageColumns, passageFTSExpr, assetFTSQueryHybrid, passageFrom, base, k)
+		lexArm := fmt.Sprintf(
+			"SELECT %s, %s, CASE WHEN c.embedding IS NOT NULL THEN 1 - (c.embedding <=> $1) ELSE 0 END AS vec_score, "+
+				"TRUE AS lex_match FROM %s WHERE %s @@ %s AND %s "+
+				"ORDER BY ts_rank_cd(%s, %s) DESC LIMIT %d",
+			assetSearchColumns, passageColumns, passageFrom, passageFTSExpr, assetFTSQueryHybrid, base,
+			passageFTSExpr, assetFTSQueryHybrid, k)
+		// #nosec G202 -- both arms are assembled from constants with
+		// parameterized placeholders.
+		query = "(" + vecArm + ") UNION ALL (" + lexArm + ")"
+	} else {
+		var base string
+		base, args = assetScope(q, []any{q.QueryText})
+		query = fmt.Sprintf(
+			"SELECT %s, %s, ts_rank_cd(%s, %s, %d) AS lex_rank FROM %s "+
+				"WHERE %s @@ %s AND %s ORDER BY lex_rank DESC LIMIT %d",
+			assetSearchColumns, passageColumns, passageFTSExpr, assetFTSQueryLexical, lexRankNormalization,
+			passageFrom, passageFTSExpr, assetFTSQueryLexical, base, k)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search asset passages: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // best-effort cleanup after read-only query
+	return collectPassageHits(rows, len(q.Embedding) > 0, q.QueryText)
+}
+
+// collectPassageHits scans passage rows, keeps the best-scoring chunk per
+// asset, and renders its excerpt. hybrid selects the (vec_score, lex_match)
+// tail of the hybrid arms over the lex_rank tail of the lexical query.
+func collectPassageHits(rows *sql.Rows, hybrid bool, queryText string) ([]portaldomain.ScoredAsset, error) {
+	byID := make(map[string]portaldomain.ScoredAsset)
+	for rows.Next() {
+		var (
+			asset                      portaldomain.Asset
+			tags, prov                 []byte
+			deletedAt                  sql.NullTime
+			maxVersions                sql.NullInt64
+			section, fragment, passage string
+			score                      float64
+			lexMatch                   bool
+		)
+		dest := append(assetScanDest(&asset, &tags, &prov, &deletedAt, &maxVersions), &section, &fragment, &passage, &score)
+		if hybrid {
+			dest = append(dest, &lexMatch)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scanning asset passage row: %w", err)
+		}
+		if err := finishScannedAsset(&asset, tags, prov, deletedAt, maxVersions); err != nil {
+			return nil, err
+		}
+		if hybrid {
+			score = fuseHybridScore(score, lexMatch)
+		}
+		if prev, ok := byID[asset.ID]; ok && prev.Score >= score {
+			continue
+		}
+		byID[asset.ID] = portaldomain.ScoredAsset{Asset: asset, Score: score, Passage: &portaldomain.AssetPassage{
+			Section:  section,
+			Fragment: fragment,
+			Excerpt:  passageExcerpt(passage, queryText),
+			Link:     portaldomain.AssetPassageLink(asset.ID, fragment),
+		}}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating asset passage rows: %w", err)
+	}
+	out := make([]portaldomain.ScoredAsset, 0, len(byID))
+	for _, sa := range byID {
+		out = append(out, sa)
+	}
+	return out, nil
+}
+
+// mergePassageHits folds passage hits into the metadata hits: an asset found
+// both ways keeps the higher score and gains the passage, an asset found only
+// by its content joins the results. The union is re-sorted by score (ties by
+// name) and cut to limit.
+func mergePassageHits(scored, passages []portaldomain.ScoredAsset, limit int) []portaldomain.ScoredAsset {
+	index := make(map[string]int, len(scored))
+	for i := range scored {
+		index[scored[i].Asset.ID] = i
+	}
+	for _, p := range passages {
+		i, ok := index[p.Asset.ID]
+		if !ok {
+			index[p.Asset.ID] = len(scored)
+			scored = append(scored, p)
+			continue
+		}
+		scored[i].Passage = p.Passage
+		scored[i].Score = max(scored[i].Score, p.Score)
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].Score != scored[j].Score {
+			return scored[i].Score > scored[j].Score
+		}
+		return scored[i].Asset.Name < scored[j].Asset.Name
+	})
+	if len(scored) > limit {
+		scored = scored[:limit]
+	}
+	return scored
+}
+
+// passageExcerpt cuts a window of at most excerptBytes out of a passage,
+// centered on the first query word it contains, or its head when it contains
+// none (a purely semantic match). Cuts land on spaces, and an ellipsis marks
+// each side that was trimmed.
+func passageExcerpt(passage, queryText string) string {
+	if len(passage) <= excerptBytes {
+		return passage
+	}
+	lower := strings.ToLower(passage)
+	at := -1
+	for _, word := range strings.Fields(strings.ToLower(queryText)) {
+		if i := strings.Index(lower, word); i >= 0 && (at < 0 || i < at) {
+			at = i
+		}
+	}
+	at = max(at, 0)
+	start := max(0, at-excerptBytes/3)
+	if start > 0 {
+		if sp := strings.IndexByte(passage[start:], ' '); sp >= 0 && sp < excerptBytes/3 {
+			start += sp + 1
+		}
+	}
+	end := min(len(passage), start+excerptBytes)
+	if end < len(passage) {
+		if sp := strings.LastIndexByte(passage[start:end], ' '); sp > 0 {
+			end = start + sp
+		}
+	}
+	for start < end && !utf8.RuneStart(passage[start]) {
+		start++
+	}
+	for end < len(passage) && !utf8.RuneStart(passage[end]) {
+		end--
+	}
+	out := strings.TrimSpace(passage[start:end])
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(passage) {
+		out += "…"
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+package portalstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/internal/portal/portaldomain"
+)
+
+// passageSearchCols is the passage-arm projection: the asset columns, then the
+// chunk's section, fragment and passage, then the per-arm score columns.
+func passageSearchCols(score ...string) []string {
+	cols := append(append([]string{}, assetSearchCols...), "section", "fragment", "passage")
+	return append(cols, score...)
+}
+
+func TestSearchAssets_PassagesLexical(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close() //nolint:errcheck // test cleanup
+	store := &postgresAssetStore{db: db}
+
+	meta := sqlmock.NewRows(append(append([]string{}, assetSearchCols...), "lex_rank"))
+	addAssetRow(meta, "a-1", "Churn deck", driverValueList{0.3})
+	mock.ExpectQuery("FROM portal_assets WHERE").WithArgs("churn", "alice@example.com").WillReturnRows(meta)
+
+	passages := sqlmock.NewRows(passageSearchCols("lex_rank"))
+	addAssetRow(passages, "a-1", "Churn deck", driverValueList{"Findings", "findings", "Churn fell 3%.", 0.5})
+	addAssetRow(passages, "a-1", "Churn deck", driverValueList{"Appendix", "appendix", "Churn table.", 0.2})
+	addAssetRow(passages, "a-2", "Q3 notes", driverValueList{"Rows 1-25", "row-1", "segment: churned", 0.4})
+	mock.ExpectQuery("FROM portal_asset_content_chunks c JOIN portal_assets").
+		WithArgs("churn", "alice@example.com").
+		WillReturnRows(passages)
+	expectEmptyCollections(mock)
+
+	scored, err := store.SearchAssets(context.Background(), portaldomain.AssetSearchQuery{
+		QueryText: "churn", OwnerID: "alice@example.com", Passages: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.Len(t, scored, 2)
+
+	assert.Equal(t, "a-1", scored[0].Asset.ID)
+	assert.InDelta(t, 0.5, scored[0].Score, 1e-9, "a passage hit lifts an asset the metadata matched weakly")
+	assert.Equal(t, &portaldomain.AssetPassage{
+		Section: "Findings", Fragment: "findings", Excerpt: "Churn fell 3%.", Link: "/portal/assets/a-1#findings",
+	}, scored[0].Passage, "the best passage of an asset is the one returned")
+
+	assert.Equal(t, "a-2", scored[1].Asset.ID, "an asset found only by its content joins the results")
+	assert.Equal(t, "/portal/assets/a-2#row-1", scored[1].Passage.Link)
+}
+
+func TestSearchAssets_PassagesHybrid(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close() //nolint:errcheck // test cleanup
+	store := &postgresAssetStore{db: db}
+
+	meta := sqlmock.NewRows(append(append([]string{}, assetSearchCols...), "vec_score", "lex_match"))
+	mock.ExpectQuery("FROM portal_assets WHERE embedding IS NOT NULL").WillReturnRows(meta)
+
+	passages := sqlmock.NewRows(passageSearchCols("vec_score", "lex_match"))
+	addAssetRow(passages, "a-1", "Deck", driverValueList{"", "", "Lead text.", 0.2, false})
+	addAssetRow(passages, "a-1", "Deck", driverValueList{"Method", "method", "Cohorts by month.", 0.2, true})
+	mock.ExpectQuery(`\(SELECT .* FROM portal_asset_content_chunks c .* WHERE c.embedding IS NOT NULL .*\) UNION ALL`).
+		WithArgs(sqlmock.AnyArg(), "cohorts", "alice@example.com").
+		WillReturnRows(passages)
+	expectEmptyCollections(mock)
+
+	scored, err := store.SearchAssets(context.Background(), portaldomain.AssetSearchQuery{
+		Embedding: []float32{0.1}, QueryText: "cohorts", OwnerID: "alice@example.com", Passages: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.Len(t, scored, 1)
+	// cosine 0.2 -> semantic 0.6; with lexical match: 0.6*0.6 + 0.4 = 0.76
+	assert.InDelta(t, 0.76, scored[0].Score, 1e-9)
+	assert.Equal(t, "method", scored[0].Passage.Fragment)
+}
+
+func TestSearchAssets_PassagesOffRunsOneQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close() //nolint:errcheck // test cleanup
+	store := &postgresAssetStore{db: db}
+
+	mock.ExpectQuery("ORDER BY lex_rank DESC").
+		WillReturnRows(sqlmock.NewRows(append(append([]string{}, assetSearchCols...), "lex_rank")))
+
+	scored, err := store.SearchAssets(context.Background(), portaldomain.AssetSearchQuery{
+		QueryText: "x", OwnerID: "alice@example.com",
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+	assert.Empty(t, scored)
+}
+
+func TestMergePassageHitsTruncates(t *testing.T) {
+	scored := []portaldomain.ScoredAsset{
+		{Asset: portaldomain.Asset{ID: "a", Name: "A"}, Score: 0.9},
+		{Asset: portaldomain.Asset{ID: "b", Name: "B"}, Score: 0.1},
+	}
+	passages := []portaldomain.ScoredAsset{
+		{Asset: portaldomain.Asset{ID: "c", Name: "C"}, Score: 0.5, Passage: &portaldomain.AssetPassage{Fragment: "x"}},
+		{Asset: portaldomain.Asset{ID: "a", Name: "A"}, Score: 0.2, Passage: &portaldomain.AssetPassage{Fragment: "y"}},
+	}
+	got := mergePassageHits(scored, passages, 2)
+	require.Len(t, got, 2)
+	assert.Equal(t, "a", got[0].Asset.ID)
+	assert.InDelta(t, 0.9, got[0].Score, 1e-9, "the metadata score stands when it is higher")
+	assert.Equal(t, "y", got[0].Passage.Fragment)
+	assert.Equal(t, "c", got[1].Asset.ID)
+}
+
+func TestPassageExcerpt(t *testing.T) {
+	assert.Equal(t, "Short passage.", passageExcerpt("Short passage.", "anything"))
+
+	long := strings.Repeat("filler words here ", 40) + "the retention curve flattens " + strings.Repeat("trailing text ", 40)
+	got := passageExcerpt(long, "Retention")
+	assert.Contains(t, got, "retention curve")
+	assert.True(t, strings.HasPrefix(got, "…") && strings.HasSuffix(got, "…"))
+	assert.LessOrEqual(t, len(got), excerptBytes+2*len("…"))
+
+	head := passageExcerpt(long, "absent")
+	assert.True(t, strings.HasPrefix(head, "filler"), "with no term in the passage the excerpt is its head")
+	assert.True(t, strings.HasSuffix(head, "…"))
+
+	first := passageExcerpt(long, "filler retention")
+	assert.True(t, strings.HasPrefix(first, "filler"), "a match at the very start is the earliest match")
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/lib/pq"
 	"github.com/pgvector/pgvector-go"
 
 	"github.com/txn2/mcp-data-platform/internal/portal/portaldomain"
@@ -48,7 +49,8 @@ const (
 // A non-nil q.Embedding selects hybrid (semantic + lexical) ranking; a nil
 // embedding selects the lexical-only fallback used when no embedding provider is
 // configured. Owner scope is applied in SQL before ranking, so an asset the
-// caller does not own is never returned.
+// caller does not own is never returned. With q.Passages set, the assets'
+// indexed content is ranked the same way and each hit carries its best passage.
 func (s *postgresAssetStore) SearchAssets(ctx context.Context, q portaldomain.AssetSearchQuery) ([]portaldomain.ScoredAsset, error) { //nolint:revive // interface impl
 	var (
 		scored []portaldomain.ScoredAsset
@@ -62,6 +64,13 @@ func (s *postgresAssetStore) SearchAssets(ctx context.Context, q portaldomain.As
 	if err != nil {
 		return nil, err
 	}
+	if q.Passages {
+		passages, err := s.searchAssetPassages(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		scored = mergePassageHits(scored, passages, q.EffectiveLimit())
+	}
 	if err := s.populateScoredCollections(ctx, scored); err != nil {
 		return nil, fmt.Errorf("populating collections: %w", err)
 	}
@@ -77,8 +86,7 @@ func (s *postgresAssetStore) SearchAssets(ctx context.Context, q portaldomain.As
 // deduped by id (keeping the higher fused score) and sorted.
 func (s *postgresAssetStore) searchAssetsHybrid(ctx context.Context, q portaldomain.AssetSearchQuery) ([]portaldomain.ScoredAsset, error) {
 	limit := q.EffectiveLimit()
-	base := "deleted_at IS NULL AND owner_id = $3"
-	args := []any{pgvector.NewVector(q.Embedding), q.QueryText, q.OwnerID}
+	base, args := assetScope(q, []any{pgvector.NewVector(q.Embedding), q.QueryText})
 
 	// #nosec G201 -- column list and FTS expr are constants; base uses only
 	// parameterized placeholders; limit is a sanitized int. No user input is
@@ -159,17 +167,18 @@ func collectHybridAssets(rows *sql.Rows, limit int) ([]portaldomain.ScoredAsset,
 // rows, and orders by a length-normalized ts_rank_cd score (lexRankNormalization)
 // so single-match records do not collapse to a flat 0.1.
 func (s *postgresAssetStore) searchAssetsLexical(ctx context.Context, q portaldomain.AssetSearchQuery) ([]portaldomain.ScoredAsset, error) {
-	// #nosec G201 -- column list and FTS expr are constants; owner_id is a
-	// parameterized placeholder; limit and the normalization bitmask are
-	// sanitized ints.
+	base, args := assetScope(q, []any{q.QueryText})
+	// #nosec G201 -- column list and FTS expr are constants; the scope uses
+	// only parameterized placeholders; limit and the normalization bitmask
+	// are sanitized ints.
 	query := fmt.Sprintf(
 		"SELECT %s, ts_rank_cd(%s, %s, %d) AS lex_rank "+
-			"FROM portal_assets WHERE deleted_at IS NULL AND owner_id = $2 "+
+			"FROM portal_assets WHERE %s "+
 			"AND %s @@ %s ORDER BY lex_rank DESC LIMIT %d",
 		assetSearchColumns, assetFTSExpr, assetFTSQueryLexical, lexRankNormalization,
-		assetFTSExpr, assetFTSQueryLexical, q.EffectiveLimit())
+		base, assetFTSExpr, assetFTSQueryLexical, q.EffectiveLimit())
 
-	rows, err := s.db.QueryContext(ctx, query, q.QueryText, q.OwnerID)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("search assets (lexical): %w", err)
 	}
@@ -199,6 +208,25 @@ func (s *postgresAssetStore) searchAssetsLexical(ctx context.Context, q portaldo
 	return scored, nil
 }
 
+// assetScope returns the search's scope predicate and its arguments appended to
+// args: the caller's own live assets, widened to the assets held by the
+// caller's live team-space collections when there are any. The space arm is only
+// added when it can match, so an owner-only search binds the same parameters
+// it always has.
+func assetScope(q portaldomain.AssetSearchQuery, args []any) (where string, params []any) {
+	args = append(args, q.OwnerID)
+	owner := fmt.Sprintf("owner_id = $%d", len(args))
+	if len(q.SpaceCollectionIDs) == 0 {
+		return "deleted_at IS NULL AND " + owner, args
+	}
+	args = append(args, pq.Array(q.SpaceCollectionIDs))
+	return fmt.Sprintf("deleted_at IS NULL AND (%s OR id IN ("+
+		"SELECT ci.asset_id FROM portal_collection_items ci "+
+		"JOIN portal_collection_sections cs ON cs.id = ci.section_id "+
+		"JOIN portal_collections c ON c.id = cs.collection_id AND c.deleted_at IS NULL "+
+		"WHERE cs.collection_id = ANY($%d)))", owner, len(args)), args
+}
+
 // populateScoredCollections fills the Collections field of each scored asset in
 // one query, reusing the list-path helper so search results carry the same
 // collection associations the list action returns.
@@ -114,6 +114,38 @@ func TestSearchAssets_Lexical(t *testing.T) {
 	assert.InDelta(t, 0.42, scored[0].Score, 1e-9)
 }
 
+func TestSearchAssets_SpaceScope(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close() //nolint:errcheck // test cleanup
+	store := &postgresAssetStore{db: db}
+
+	// The caller's team-space collections widen the owner scope to the assets
+	// they hold, in both the lexical and the hybrid query.
+	rows := sqlmock.NewRows(append(append([]string{}, assetSearchCols...), "lex_rank"))
+	addAssetRow(rows, "a-3", "Team notes", driverValueList{0.5})
+	mock.ExpectQuery(`\(owner_id = \$2 OR id IN \(SELECT ci.asset_id .+ c.deleted_at IS NULL WHERE cs.collection_id = ANY\(\$3\)\)\)`).
+		WithArgs("notes", "alice@example.com", sqlmock.AnyArg()).
+		WillReturnRows(rows)
+	expectEmptyCollections(mock)
+
+	scored, err := store.SearchAssets(context.Background(), portaldomain.AssetSearchQuery{
+		QueryText: "notes", OwnerID: "alice@example.com", SpaceCollectionIDs: []string{"col_team"},
+	})
+	require.NoError(t, err)
+	require.Len(t, scored, 1)
+
+	mock.ExpectQuery(`owner_id = \$3 OR id IN .+ ANY\(\$4\)`).
+		WithArgs(sqlmock.AnyArg(), "notes", "alice@example.com", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(append(append([]string{}, assetSearchCols...), "vec_score", "lex_match")))
+	_, err = store.SearchAssets(context.Background(), portaldomain.AssetSearchQuery{
+		Embedding: []float32{0.1}, QueryText: "notes", OwnerID: "alice@example.com",
+		SpaceCollectionIDs: []string{"col_team"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestSearchAssets_HybridQueryError(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
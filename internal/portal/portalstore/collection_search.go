@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/lib/pq"
 	"github.com/pgvector/pgvector-go"
 
 	"github.com/txn2/mcp-data-platform/internal/portal/portaldomain"
@@ -57,8 +58,7 @@ func (s *postgresCollectionStore) SearchCollections(ctx context.Context, q porta
 // same two-index strategy as asset and prompt search.
 func (s *postgresCollectionStore) searchCollectionsHybrid(ctx context.Context, q portaldomain.CollectionSearchQuery) ([]portaldomain.ScoredCollection, error) {
 	limit := q.EffectiveLimit()
-	base := "deleted_at IS NULL AND owner_id = $3"
-	args := []any{pgvector.NewVector(q.Embedding), q.QueryText, q.OwnerID}
+	base, args := collectionScope(q, []any{pgvector.NewVector(q.Embedding), q.QueryText})
 
 	// #nosec G201 -- column list and FTS expr are constants; base uses only
 	// parameterized placeholders; limit is a sanitized int.
@@ -124,17 +124,18 @@ func (s *postgresCollectionStore) searchCollectionsHybrid(ctx context.Context, q
 // searchCollectionsLexical ranks the caller's non-deleted collections by
 // full-text relevance only (the no-embedder fallback).
 func (s *postgresCollectionStore) searchCollectionsLexical(ctx context.Context, q portaldomain.CollectionSearchQuery) ([]portaldomain.ScoredCollection, error) {
-	// #nosec G201 -- column list and FTS expr are constants; owner_id is a
-	// parameterized placeholder; limit and the normalization bitmask are
-	// sanitized ints.
+	base, args := collectionScope(q, []any{q.QueryText})
+	// #nosec G201 -- column list and FTS expr are constants; the scope uses
+	// only parameterized placeholders; limit and the normalization bitmask
+	// are sanitized ints.
 	query := fmt.Sprintf(
 		"SELECT %s, ts_rank_cd(%s, %s, %d) AS lex_rank "+
-			"FROM portal_collections WHERE deleted_at IS NULL AND owner_id = $2 "+
+			"FROM portal_collections WHERE %s "+
 			"AND %s @@ %s ORDER BY lex_rank DESC LIMIT %d",
 		collectionColumns, collectionFTSExpr, collectionFTSQueryLexical, lexRankNormalization,
-		collectionFTSExpr, collectionFTSQueryLexical, q.EffectiveLimit())
+		base, collectionFTSExpr, collectionFTSQueryLexical, q.EffectiveLimit())
 
-	rows, err := s.db.QueryContext(ctx, query, q.QueryText, q.OwnerID)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("search collections (lexical): %w", err)
 	}
@@ -161,6 +162,18 @@ func (s *postgresCollectionStore) searchCollectionsLexical(ctx context.Context,
 	return scored, nil
 }
 
+// collectionScope is assetScope for collections: the caller's own live
+// collections, plus the caller's team-space collections when there are any.
+func collectionScope(q portaldomain.CollectionSearchQuery, args []any) (where string, params []any) {
+	args = append(args, q.OwnerID)
+	owner := fmt.Sprintf("owner_id = $%d", len(args))
+	if len(q.SpaceCollectionIDs) == 0 {
+		return "deleted_at IS NULL AND " + owner, args
+	}
+	args = append(args, pq.Array(q.SpaceCollectionIDs))
+	return fmt.Sprintf("deleted_at IS NULL AND (%s OR id = ANY($%d))", owner, len(args)), args
+}
+
 // populateScoredAssetTags fills the AssetTags of each scored collection in one
 // query, reusing the list-path helper so search results carry the same
 // aggregated tags the list action returns.
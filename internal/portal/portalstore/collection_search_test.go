@@ -83,6 +83,28 @@ func TestSearchCollections_Lexical(t *testing.T) {
 	assert.InDelta(t, 0.33, scored[0].Score, 1e-9)
 }
 
+func TestSearchCollections_SpaceScope(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close() //nolint:errcheck // test cleanup
+	store := &postgresCollectionStore{db: db}
+
+	rows := sqlmock.NewRows(append(append([]string{}, collectionSearchCols...), "lex_rank"))
+	addCollectionRow(rows, "c-3", "Team board", 0.5)
+	mock.ExpectQuery(`\(owner_id = \$2 OR id = ANY\(\$3\)\)`).
+		WithArgs("board", "alice@example.com", sqlmock.AnyArg()).
+		WillReturnRows(rows)
+	expectEmptyAssetTags(mock)
+
+	scored, err := store.SearchCollections(context.Background(), portaldomain.CollectionSearchQuery{
+		QueryText: "board", OwnerID: "alice@example.com", SpaceCollectionIDs: []string{"c-3"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.Len(t, scored, 1)
+	assert.Equal(t, "c-3", scored[0].Collection.ID)
+}
+
 func TestSearchCollections_HybridQueryError(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
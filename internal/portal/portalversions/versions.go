@@ -20,6 +20,7 @@ import (
 	"log/slog"
 
 	"github.com/txn2/mcp-data-platform/internal/portal/portaldomain"
+	"github.com/txn2/mcp-data-platform/pkg/indexjobs"
 )
 
 // --- PostgreSQL VersionStore ---
@@ -41,13 +42,20 @@ type store struct {
 	// every asset that carries no override of its own. Nil selects
 	// portaldomain.DefaultMaxVersions.
 	platformMaxVersions *int
+	// index enqueues the asset's content for indexing once a version commits.
+	// Nil-safe: a store built without a producer leaves it to the reconciler.
+	index *indexjobs.Producer
 }
 
 // NewPostgres creates the PostgreSQL asset version store. objects deletes the
 // blobs of versions the retention cap prunes and may be nil; platformMaxVersions
-// is the deployment default a per-asset override supersedes.
-func NewPostgres(db *sql.DB, objects ObjectDeleter, platformMaxVersions *int) portaldomain.VersionStore {
-	return &store{db: db, objects: objects, platformMaxVersions: platformMaxVersions}
+// is the deployment default a per-asset override supersedes. Pass
+// indexjobs.WithProducer to have each new version enqueue a content-index job.
+func NewPostgres(db *sql.DB, objects ObjectDeleter, platformMaxVersions *int, opts ...indexjobs.StoreOption) portaldomain.VersionStore {
+	return &store{
+		db: db, objects: objects, platformMaxVersions: platformMaxVersions,
+		index: indexjobs.ResolveStoreOptions(opts).Producer,
+	}
 }
 
 func (s *store) CreateVersion(ctx context.Context, version portaldomain.AssetVersion) (int, error) { //nolint:revive // interface impl
@@ -59,6 +67,8 @@ func (s *store) CreateVersion(ctx context.Context, version portaldomain.AssetVer
 	// that outlives its row is reclaimable while a row whose object was deleted
 	// under a rolled-back transaction is a version that lists and cannot be read.
 	s.deletePrunedObjects(ctx, version.AssetID, pruned)
+	// Also after the commit: a job claimed earlier would read the old head.
+	s.index.NotifyWrite(ctx, version.AssetID)
 	return nextVersion, nil
 }
 
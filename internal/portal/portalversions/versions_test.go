@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/txn2/mcp-data-platform/internal/portal/portaldomain"
+	"github.com/txn2/mcp-data-platform/pkg/indexjobs"
 )
 
 // expectSurvivingKeys sets up the in-transaction read of the keys the versions
@@ -78,6 +79,53 @@ func TestPostgresVersionStoreCreateVersion(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+// recordingEnqueuer stands in for the index-job store.
+type recordingEnqueuer struct {
+	keys []indexjobs.Key
+}
+
+func (r *recordingEnqueuer) Enqueue(_ context.Context, key indexjobs.Key, _ indexjobs.Trigger) (bool, error) {
+	r.keys = append(r.keys, key)
+	return true, nil
+}
+
+// TestPostgresVersionStoreCreateVersionEnqueuesContentIndex pins that a
+// committed version enqueues its asset for content indexing, and a failed one
+// does not.
+func TestPostgresVersionStoreCreateVersionEnqueuesContentIndex(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close() //nolint:errcheck // test cleanup
+
+	producer := indexjobs.NewProducer("portal-asset-content")
+	enq := &recordingEnqueuer{}
+	producer.Bind(enq)
+	store := NewPostgres(db, nil, nil, indexjobs.WithProducer(producer))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT current_version, max_versions FROM portal_assets").
+		WithArgs("a1").
+		WillReturnRows(sqlmock.NewRows([]string{"current_version", "max_versions"}).AddRow(1, nil))
+	mock.ExpectExec("INSERT INTO portal_asset_versions").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE portal_assets").WillReturnError(errors.New("db error"))
+	mock.ExpectRollback()
+	_, err = store.CreateVersion(context.Background(), portaldomain.AssetVersion{AssetID: "a1"})
+	require.Error(t, err)
+	assert.Empty(t, enq.keys)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT current_version, max_versions FROM portal_assets").
+		WithArgs("a1").
+		WillReturnRows(sqlmock.NewRows([]string{"current_version", "max_versions"}).AddRow(1, nil))
+	mock.ExpectExec("INSERT INTO portal_asset_versions").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE portal_assets").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	_, err = store.CreateVersion(context.Background(), portaldomain.AssetVersion{AssetID: "a1"})
+	require.NoError(t, err)
+	assert.Equal(t, []indexjobs.Key{{SourceKind: "portal-asset-content", SourceID: "a1"}}, enq.keys)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestPostgresVersionStoreCreateVersionInsertError(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -114,3 +114,12 @@ func AssetCSP() string {
 func CollectionCSP() string {
 	return "frame-src 'self' blob: data:; " + baseCSP
 }
+
+// EmbedCSP returns the Content-Security-Policy for an asset opened through an
+// embed token: the single-asset policy plus the frame-ancestors directive
+// naming who may frame it. The share viewer carries no frame-ancestors
+// directive; an embed is the one page meant to load inside another site, so
+// it is the one page that says which sites.
+func EmbedCSP(frameAncestors string) string {
+	return AssetCSP() + " " + frameAncestors + ";"
+}
@@ -47,6 +47,17 @@ func TestCollectionCSP(t *testing.T) {
 	require.Contains(t, csp, "frame-src 'self' blob: data:")
 }
 
+func TestEmbedCSP(t *testing.T) {
+	t.Parallel()
+
+	csp := publicviewer.EmbedCSP("frame-ancestors https://wiki.example.com")
+	d := directives(csp)
+	require.Equal(t, []string{"https://wiki.example.com"}, d["frame-ancestors"])
+	require.Equal(t, directives(publicviewer.AssetCSP())["script-src"], d["script-src"],
+		"an embed renders the asset under the single-asset policy")
+	require.NotContains(t, publicviewer.AssetCSP(), "frame-ancestors", "share pages carry no frame-ancestors")
+}
+
 // directives splits a policy into directive name → source list.
 func directives(csp string) map[string][]string {
 	out := map[string][]string{}
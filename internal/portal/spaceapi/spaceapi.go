@@ -0,0 +1,57 @@
+// Package spaceapi serves the /api/v1/portal/spaces surface: team spaces, the
+// members and group bindings that make someone part of one, and the
+// collections a space owns.
+//
+// The routes only maintain the space records. What a membership is worth is
+// decided by the access checker, which resolves a caller's space role on every
+// permission check, so nothing written here has to be fanned out onto shares:
+// a role change or a removed member applies to the next request.
+//
+// Who may change what follows from how far a change reaches. A group binding
+// admits everyone the identity provider puts in that group, so creating a
+// space, deleting one, and binding groups to it are admin operations. A
+// space's managers run everything else: its name and description, its managed
+// members, and which collections it owns.
+package spaceapi
+
+import (
+	"net/http"
+
+	"github.com/txn2/mcp-data-platform/internal/portal/access"
+	"github.com/txn2/mcp-data-platform/internal/portal/portaldomain"
+	"github.com/txn2/mcp-data-platform/pkg/portal/spaces"
+)
+
+// Config carries what the routes need. A nil Spaces leaves them unregistered.
+type Config struct {
+	// Spaces persists the spaces and resolves member roles.
+	Spaces spaces.Store
+	// Collections loads the collection a space is given, to check the caller
+	// may give it away.
+	Collections portaldomain.CollectionStore
+	// Access is the portal's authorization core, shared so admin reach and
+	// collection ownership are decided the way every other route decides them.
+	Access *access.Checker
+}
+
+// handler binds the routes to their dependencies.
+type handler struct {
+	cfg Config
+}
+
+// Register mounts the team-space routes on mux.
+func Register(mux *http.ServeMux, cfg Config) {
+	if cfg.Spaces == nil {
+		return
+	}
+	h := &handler{cfg: cfg}
+	mux.HandleFunc("GET /api/v1/portal/spaces", h.listSpaces)
+	mux.HandleFunc("POST /api/v1/portal/spaces", h.createSpace)
+	mux.HandleFunc("GET /api/v1/portal/spaces/{id}", h.getSpace)
+	mux.HandleFunc("PUT /api/v1/portal/spaces/{id}", h.updateSpace)
+	mux.HandleFunc("DELETE /api/v1/portal/spaces/{id}", h.deleteSpace)
+	mux.HandleFunc("PUT /api/v1/portal/spaces/{id}/members/{email}", h.putMember)
+	mux.HandleFunc("DELETE /api/v1/portal/spaces/{id}/members/{email}", h.removeMember)
+	mux.HandleFunc("POST /api/v1/portal/spaces/{id}/collections", h.attachCollection)
+	mux.HandleFunc("DELETE /api/v1/portal/spaces/{id}/collections/{collectionID}", h.detachCollection)
+}
@@ -0,0 +1,488 @@
+package spaceapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/txn2/mcp-data-platform/internal/httpjson"
+	"github.com/txn2/mcp-data-platform/internal/portal/access"
+	"github.com/txn2/mcp-data-platform/pkg/portal/spaces"
+)
+
+// Messages a client sees. errAuthRequired is spelled here rather than
+// imported from pkg/portal, which imports this package to register the routes;
+// the wording must stay identical on both sides.
+const (
+	errAuthRequired       = "authentication required"
+	errInvalidRequestBody = "invalid request body"
+	errAdminOnly          = "only an admin can do that to a space"
+	errRoleTooWeak        = "your role in this space does not allow that"
+	errCollectionNotFound = "collection not found"
+)
+
+// paramAll asks an admin's listing for every space rather than their own.
+const paramAll = "all"
+
+// spaceRequest is the body of a create or update. On update a nil Groups
+// keeps the bindings as they are.
+type spaceRequest struct {
+	Name        string                 `json:"name" example:"Revenue Analytics"`
+	Description string                 `json:"description" example:"Finance reporting team"`
+	Groups      *[]spaces.GroupBinding `json:"groups,omitempty"`
+}
+
+// memberRequest is the body of a membership change.
+type memberRequest struct {
+	Role string `json:"role" example:"editor"`
+}
+
+// collectionRequest names the collection a space is given.
+type collectionRequest struct {
+	CollectionID string `json:"collection_id" example:"col_7d2e"`
+}
+
+// spaceListResponse wraps the spaces a listing returns.
+type spaceListResponse struct {
+	Data []spaces.Space `json:"data"`
+}
+
+// spaceDetail is a space with its managed members and the collections it owns.
+type spaceDetail struct {
+	spaces.Space
+	Members     []spaces.Member `json:"members"`
+	Collections []string        `json:"collection_ids"`
+}
+
+// listSpaces handles GET /api/v1/portal/spaces.
+//
+// @Summary      List my team spaces
+// @Description  Returns the spaces the caller is a member of, through a managed membership or a group claim on their token, each with the caller's strongest role. An admin may pass all=true for every space.
+// @Tags         Spaces
+// @Produce      json
+// @Param        all  query  boolean  false  "Every space (admin only)"
+// @Success      200  {object}  spaceListResponse
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      403  {object}  httpjson.ProblemDetail
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/spaces [get]
+func (h *handler) listSpaces(w http.ResponseWriter, r *http.Request) {
+	user := access.GetUser(r.Context())
+	if user == nil {
+		httpjson.WriteError(w, http.StatusUnauthorized, errAuthRequired)
+		return
+	}
+	var (
+		list []spaces.Space
+		err  error
+	)
+	if r.URL.Query().Get(paramAll) == "true" {
+		if !h.cfg.Access.IsAdmin(user) {
+			httpjson.WriteError(w, http.StatusForbidden, errAdminOnly)
+			return
+		}
+		list, err = h.cfg.Spaces.List(r.Context())
+	} else {
+		list, err = h.cfg.Spaces.ForPrincipal(r.Context(), access.SpacePrincipal(user))
+	}
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to list spaces")
+		return
+	}
+	if list == nil {
+		list = []spaces.Space{}
+	}
+	httpjson.WriteJSON(w, http.StatusOK, spaceListResponse{Data: list})
+}
+
+// createSpace handles POST /api/v1/portal/spaces.
+//
+// @Summary      Create a team space
+// @Description  Creates a space with its group bindings. Admin only, since a binding admits everyone in the group. The creator is added as the space's first manager.
+// @Tags         Spaces
+// @Accept       json
+// @Produce      json
+// @Param        body  body  spaceRequest  true  "Space"
+// @Success      201  {object}  spaces.Space
+// @Failure      400  {object}  httpjson.ProblemDetail
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      403  {object}  httpjson.ProblemDetail
+// @Failure      409  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/spaces [post]
+func (h *handler) createSpace(w http.ResponseWriter, r *http.Request) {
+	user := access.GetUser(r.Context())
+	if user == nil {
+		httpjson.WriteError(w, http.StatusUnauthorized, errAuthRequired)
+		return
+	}
+	if !h.cfg.Access.IsAdmin(user) {
+		httpjson.WriteError(w, http.StatusForbidden, errAdminOnly)
+		return
+	}
+	var req spaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, errInvalidRequestBody)
+		return
+	}
+	s := spaces.Space{ID: spaces.NewID(), Name: req.Name, Description: req.Description, CreatedBy: user.Email}
+	if req.Groups != nil {
+		s.Groups = *req.Groups
+	}
+	if err := s.Normalize(); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	created, err := h.cfg.Spaces.Create(r.Context(), s)
+	if err != nil {
+		writeSpaceError(w, err, "failed to create space")
+		return
+	}
+	if user.Email != "" {
+		creator := spaces.Member{Email: user.Email, Role: spaces.RoleManager, AddedBy: user.Email}
+		if err := spaces.NormalizeMember(&creator); err == nil {
+			if err := h.cfg.Spaces.PutMember(r.Context(), created.ID, creator); err != nil {
+				httpjson.WriteError(w, http.StatusInternalServerError, "failed to add the space's first manager")
+				return
+			}
+		}
+	}
+	httpjson.WriteJSON(w, http.StatusCreated, created)
+}
+
+// getSpace handles GET /api/v1/portal/spaces/{id}.
+//
+// @Summary      Get a team space
+// @Description  Returns a space with its managed members and the collections it owns. Members and admins only; to anyone else the space does not exist.
+// @Tags         Spaces
+// @Produce      json
+// @Param        id  path  string  true  "Space ID"
+// @Success      200  {object}  spaceDetail
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Failure      500  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/spaces/{id} [get]
+func (h *handler) getSpace(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.authorize(w, r, spaces.RoleViewer)
+	if !ok {
+		return
+	}
+	members, err := h.cfg.Spaces.Members(r.Context(), s.ID)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to load space members")
+		return
+	}
+	collections, err := h.cfg.Spaces.Collections(r.Context(), s.ID)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to load space collections")
+		return
+	}
+	if members == nil {
+		members = []spaces.Member{}
+	}
+	if collections == nil {
+		collections = []string{}
+	}
+	httpjson.WriteJSON(w, http.StatusOK, spaceDetail{Space: *s, Members: members, Collections: collections})
+}
+
+// updateSpace handles PUT /api/v1/portal/spaces/{id}.
+//
+// @Summary      Update a team space
+// @Description  Changes a space's name and description. Space managers and admins. Only an admin may change the group bindings; omit groups to keep them.
+// @Tags         Spaces
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string        true  "Space ID"
+// @Param        body  body  spaceRequest  true  "Space"
+// @Success      200  {object}  spaces.Space
+// @Failure      400  {object}  httpjson.ProblemDetail
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      403  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Failure      409  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/spaces/{id} [put]
+func (h *handler) updateSpace(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.authorize(w, r, spaces.RoleManager)
+	if !ok {
+		return
+	}
+	var req spaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, errInvalidRequestBody)
+		return
+	}
+	if req.Groups != nil {
+		if !h.cfg.Access.IsAdmin(access.GetUser(r.Context())) {
+			httpjson.WriteError(w, http.StatusForbidden, errAdminOnly)
+			return
+		}
+		s.Groups = *req.Groups
+	}
+	s.Name, s.Description = req.Name, req.Description
+	if err := s.Normalize(); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	updated, err := h.cfg.Spaces.Update(r.Context(), *s)
+	if err != nil {
+		writeSpaceError(w, err, "failed to update space")
+		return
+	}
+	httpjson.WriteJSON(w, http.StatusOK, updated)
+}
+
+// deleteSpace handles DELETE /api/v1/portal/spaces/{id}.
+//
+// @Summary      Delete a team space
+// @Description  Deletes a space. Admin only. Its collections and their assets are kept, governed again by their owners and shares alone.
+// @Tags         Spaces
+// @Param        id  path  string  true  "Space ID"
+// @Success      204
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      403  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/spaces/{id} [delete]
+func (h *handler) deleteSpace(w http.ResponseWriter, r *http.Request) {
+	user := access.GetUser(r.Context())
+	if user == nil {
+		httpjson.WriteError(w, http.StatusUnauthorized, errAuthRequired)
+		return
+	}
+	if !h.cfg.Access.IsAdmin(user) {
+		httpjson.WriteError(w, http.StatusForbidden, errAdminOnly)
+		return
+	}
+	if err := h.cfg.Spaces.Delete(r.Context(), r.PathValue("id")); err != nil {
+		writeSpaceError(w, err, "failed to delete space")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// putMember handles PUT /api/v1/portal/spaces/{id}/members/{email}.
+//
+// @Summary      Add or change a space member
+// @Description  Adds a managed member or changes their role. Space managers and admins. The role applies to everything the space owns from the next request on.
+// @Tags         Spaces
+// @Accept       json
+// @Param        id     path  string         true  "Space ID"
+// @Param        email  path  string         true  "Member email"
+// @Param        body   body  memberRequest  true  "Role"
+// @Success      204
+// @Failure      400  {object}  httpjson.ProblemDetail
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      403  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/spaces/{id}/members/{email} [put]
+func (h *handler) putMember(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.authorize(w, r, spaces.RoleManager)
+	if !ok {
+		return
+	}
+	var req memberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, errInvalidRequestBody)
+		return
+	}
+	m := spaces.Member{Email: r.PathValue("email"), Role: req.Role, AddedBy: access.GetUser(r.Context()).Email}
+	if err := spaces.NormalizeMember(&m); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.cfg.Spaces.PutMember(r.Context(), s.ID, m); err != nil {
+		writeSpaceError(w, err, "failed to save space member")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// removeMember handles DELETE /api/v1/portal/spaces/{id}/members/{email}.
+//
+// @Summary      Remove a space member
+// @Description  Removes a managed member. Space managers and admins. Someone who is also a member through a group claim keeps that membership.
+// @Tags         Spaces
+// @Param        id     path  string  true  "Space ID"
+// @Param        email  path  string  true  "Member email"
+// @Success      204
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      403  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/spaces/{id}/members/{email} [delete]
+func (h *handler) removeMember(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.authorize(w, r, spaces.RoleManager)
+	if !ok {
+		return
+	}
+	email := strings.ToLower(strings.TrimSpace(r.PathValue("email")))
+	if err := h.cfg.Spaces.RemoveMember(r.Context(), s.ID, email); err != nil {
+		writeSpaceError(w, err, "failed to remove space member")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// attachCollection handles POST /api/v1/portal/spaces/{id}/collections.
+//
+// @Summary      Give a collection to a team space
+// @Description  Makes the space the collection's owner, so every member holds their space role on it and on the assets inside it. The caller must manage the collection and be an editor or manager in the space, or be an admin. A collection belongs to at most one space.
+// @Tags         Spaces
+// @Accept       json
+// @Param        id    path  string             true  "Space ID"
+// @Param        body  body  collectionRequest  true  "Collection"
+// @Success      204
+// @Failure      400  {object}  httpjson.ProblemDetail
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      403  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Failure      409  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/spaces/{id}/collections [post]
+func (h *handler) attachCollection(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.authorize(w, r, spaces.RoleEditor)
+	if !ok {
+		return
+	}
+	var req collectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.CollectionID) == "" {
+		httpjson.WriteError(w, http.StatusBadRequest, errInvalidRequestBody)
+		return
+	}
+	user := access.GetUser(r.Context())
+	owner, found := h.collectionOwner(r.Context(), req.CollectionID)
+	if !found {
+		httpjson.WriteError(w, http.StatusNotFound, errCollectionNotFound)
+		return
+	}
+	if !h.cfg.Access.CanManage(owner, user) {
+		httpjson.WriteError(w, http.StatusForbidden, "only the collection's owner can give it to a space")
+		return
+	}
+	if err := h.cfg.Spaces.AttachCollection(r.Context(), s.ID, req.CollectionID, user.Email); err != nil {
+		writeSpaceError(w, err, "failed to add collection to space")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// detachCollection handles DELETE /api/v1/portal/spaces/{id}/collections/{collectionID}.
+//
+// @Summary      Take a collection out of a team space
+// @Description  Releases a collection the space owns. Its members lose the roles the space gave them on it. The collection's owner, a space manager, or an admin.
+// @Tags         Spaces
+// @Param        id            path  string  true  "Space ID"
+// @Param        collectionID  path  string  true  "Collection ID"
+// @Success      204
+// @Failure      401  {object}  httpjson.ProblemDetail
+// @Failure      403  {object}  httpjson.ProblemDetail
+// @Failure      404  {object}  httpjson.ProblemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/spaces/{id}/collections/{collectionID} [delete]
+func (h *handler) detachCollection(w http.ResponseWriter, r *http.Request) {
+	s, role, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	collectionID := r.PathValue("collectionID")
+	if !spaces.AtLeast(role, spaces.RoleManager) {
+		owner, found := h.collectionOwner(r.Context(), collectionID)
+		if !found || !h.cfg.Access.CanManage(owner, access.GetUser(r.Context())) {
+			httpjson.WriteError(w, http.StatusForbidden, errRoleTooWeak)
+			return
+		}
+	}
+	if err := h.cfg.Spaces.DetachCollection(r.Context(), s.ID, collectionID); err != nil {
+		writeSpaceError(w, err, "failed to remove collection from space")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorize loads the path's space and checks the caller holds at least
+// minRole in it. Writes the error and returns false on failure.
+func (h *handler) authorize(w http.ResponseWriter, r *http.Request, minRole string) (*spaces.Space, bool) {
+	s, role, ok := h.load(w, r)
+	if !ok {
+		return nil, false
+	}
+	if !spaces.AtLeast(role, minRole) {
+		httpjson.WriteError(w, http.StatusForbidden, errRoleTooWeak)
+		return nil, false
+	}
+	return s, true
+}
+
+// load returns the path's space and the caller's role in it, an admin
+// counting as a manager. A space the caller is not a member of is answered as
+// not found, the same answer an unknown id gets, so membership of a space
+// cannot be probed by id. Writes the error and returns false on failure.
+func (h *handler) load(w http.ResponseWriter, r *http.Request) (*spaces.Space, string, bool) {
+	user := access.GetUser(r.Context())
+	if user == nil {
+		httpjson.WriteError(w, http.StatusUnauthorized, errAuthRequired)
+		return nil, "", false
+	}
+	s, err := h.cfg.Spaces.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeSpaceError(w, err, "failed to load space")
+		return nil, "", false
+	}
+	if h.cfg.Access.IsAdmin(user) {
+		return s, spaces.RoleManager, true
+	}
+	role, err := h.cfg.Spaces.Role(r.Context(), s.ID, access.SpacePrincipal(user))
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, "failed to load space")
+		return nil, "", false
+	}
+	if role == "" {
+		httpjson.WriteError(w, http.StatusNotFound, spaces.ErrNotFound.Error())
+		return nil, "", false
+	}
+	return s, role, true
+}
+
+// collectionOwner returns the owner of a live collection, reporting false for
+// a missing or deleted one.
+func (h *handler) collectionOwner(ctx context.Context, collectionID string) (string, bool) {
+	if h.cfg.Collections == nil {
+		return "", false
+	}
+	coll, err := h.cfg.Collections.Get(ctx, collectionID)
+	if err != nil || coll == nil || coll.DeletedAt != nil {
+		return "", false
+	}
+	return coll.OwnerID, true
+}
+
+// writeSpaceError maps a store error to its status, falling back to a 500 with
+// msg for anything unexpected.
+func writeSpaceError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, spaces.ErrNotFound):
+		httpjson.WriteError(w, http.StatusNotFound, spaces.ErrNotFound.Error())
+	case errors.Is(err, spaces.ErrInvalid):
+		httpjson.WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, spaces.ErrNameTaken), errors.Is(err, spaces.ErrOwnedByPeer):
+		httpjson.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		httpjson.WriteError(w, http.StatusInternalServerError, msg)
+	}
+}
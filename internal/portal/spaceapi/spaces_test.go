@@ -0,0 +1,340 @@
+package spaceapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/internal/portal/access"
+	"github.com/txn2/mcp-data-platform/internal/portal/portaldomain"
+	"github.com/txn2/mcp-data-platform/pkg/portal/spaces"
+)
+
+// memStore keeps spaces in memory and resolves roles the way the Postgres
+// store does: the strongest of a managed membership and any bound group.
+type memStore struct {
+	spaces      map[string]spaces.Space
+	members     map[string]map[string]string // space -> email -> role
+	collections map[string]string            // collection -> space
+}
+
+func newMemStore() *memStore {
+	return &memStore{spaces: map[string]spaces.Space{}, members: map[string]map[string]string{}, collections: map[string]string{}}
+}
+
+func (m *memStore) Create(_ context.Context, s spaces.Space) (*spaces.Space, error) {
+	for _, other := range m.spaces {
+		if other.Name == s.Name {
+			return nil, spaces.ErrNameTaken
+		}
+	}
+	m.spaces[s.ID] = s
+	return &s, nil
+}
+
+func (m *memStore) Get(_ context.Context, id string) (*spaces.Space, error) {
+	s, ok := m.spaces[id]
+	if !ok {
+		return nil, spaces.ErrNotFound
+	}
+	return &s, nil
+}
+
+func (m *memStore) Update(_ context.Context, s spaces.Space) (*spaces.Space, error) {
+	m.spaces[s.ID] = s
+	return &s, nil
+}
+
+func (m *memStore) Delete(_ context.Context, id string) error {
+	if _, ok := m.spaces[id]; !ok {
+		return spaces.ErrNotFound
+	}
+	delete(m.spaces, id)
+	return nil
+}
+
+func (m *memStore) List(context.Context) ([]spaces.Space, error) {
+	var out []spaces.Space
+	for _, s := range m.spaces {
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func (m *memStore) ForPrincipal(ctx context.Context, p spaces.Principal) ([]spaces.Space, error) {
+	var out []spaces.Space
+	for id, s := range m.spaces {
+		if role, _ := m.Role(ctx, id, p); role != "" {
+			s.Role = role
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (m *memStore) Role(_ context.Context, spaceID string, p spaces.Principal) (string, error) {
+	roles := []string{m.members[spaceID][p.Email]}
+	for _, g := range m.spaces[spaceID].Groups {
+		if slices.Contains(p.Groups, g.Group) {
+			roles = append(roles, g.Role)
+		}
+	}
+	return spaces.Strongest(roles...), nil
+}
+
+func (m *memStore) PutMember(_ context.Context, spaceID string, mem spaces.Member) error {
+	if m.members[spaceID] == nil {
+		m.members[spaceID] = map[string]string{}
+	}
+	m.members[spaceID][mem.Email] = mem.Role
+	return nil
+}
+
+func (m *memStore) RemoveMember(_ context.Context, spaceID, email string) error {
+	if _, ok := m.members[spaceID][email]; !ok {
+		return spaces.ErrNotFound
+	}
+	delete(m.members[spaceID], email)
+	return nil
+}
+
+func (m *memStore) Members(_ context.Context, spaceID string) ([]spaces.Member, error) {
+	var out []spaces.Member
+	for email, role := range m.members[spaceID] {
+		out = append(out, spaces.Member{Email: email, Role: role})
+	}
+	return out, nil
+}
+
+func (m *memStore) AttachCollection(_ context.Context, spaceID, collectionID, _ string) error {
+	if owner, ok := m.collections[collectionID]; ok && owner != spaceID {
+		return spaces.ErrOwnedByPeer
+	}
+	m.collections[collectionID] = spaceID
+	return nil
+}
+
+func (m *memStore) DetachCollection(_ context.Context, spaceID, collectionID string) error {
+	if m.collections[collectionID] != spaceID {
+		return spaces.ErrNotFound
+	}
+	delete(m.collections, collectionID)
+	return nil
+}
+
+func (m *memStore) Collections(_ context.Context, spaceID string) ([]string, error) {
+	var out []string
+	for coll, owner := range m.collections {
+		if owner == spaceID {
+			out = append(out, coll)
+		}
+	}
+	return out, nil
+}
+
+func (m *memStore) CollectionSpace(_ context.Context, collectionID string) (string, error) {
+	return m.collections[collectionID], nil
+}
+
+func (m *memStore) CollectionRole(ctx context.Context, collectionID string, p spaces.Principal) (string, error) {
+	return m.Role(ctx, m.collections[collectionID], p)
+}
+
+func (m *memStore) AssetRole(context.Context, string, spaces.Principal) (string, error) {
+	return "", nil
+}
+
+func (m *memStore) CollectionIDs(context.Context, spaces.Principal) ([]string, error) {
+	return nil, nil
+}
+
+func (m *memStore) ObserveGroups(context.Context, string, []string) error { return nil }
+
+// collectionStore serves one collection owned by "owner".
+type collectionStore struct {
+	portaldomain.CollectionStore
+}
+
+func (collectionStore) Get(_ context.Context, id string) (*portaldomain.Collection, error) {
+	if id != "col_1" {
+		return nil, nil //nolint:nilnil // test double: no such collection
+	}
+	return &portaldomain.Collection{ID: id, OwnerID: "owner"}, nil
+}
+
+var (
+	admin   = &access.User{UserID: "root", Email: "root@x.io", Roles: []string{"admin"}}
+	owner   = &access.User{UserID: "owner", Email: "owner@x.io"}
+	analyst = &access.User{UserID: "ana", Email: "ana@x.io", Roles: []string{"finance"}}
+	outside = &access.User{UserID: "eve", Email: "eve@x.io"}
+)
+
+// do runs one request against the routes as user; a nil user sends it
+// unauthenticated.
+func do(t *testing.T, store spaces.Store, user *access.User, method, target string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := http.NewServeMux()
+	Register(mux, Config{
+		Spaces:      store,
+		Collections: collectionStore{},
+		Access:      access.New(access.Config{AdminRoles: []string{"admin"}}),
+	})
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+	req := httptest.NewRequestWithContext(t.Context(), method, target, &buf)
+	if user != nil {
+		req = req.WithContext(access.ContextWithUser(req.Context(), user))
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+// seeded is one space binding the finance group as viewers, with owner as a
+// managed editor.
+func seeded() *memStore {
+	store := newMemStore()
+	store.spaces["spc_1"] = spaces.Space{ID: "spc_1", Name: "Finance",
+		Groups: []spaces.GroupBinding{{Group: "finance", Role: spaces.RoleViewer}}}
+	store.members["spc_1"] = map[string]string{"owner@x.io": spaces.RoleEditor}
+	return store
+}
+
+func TestRegister_NilStoreLeavesRoutesOff(t *testing.T) {
+	rec := do(t, nil, admin, http.MethodGet, "/api/v1/portal/spaces", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestCreateSpace(t *testing.T) {
+	store := newMemStore()
+	body := spaceRequest{Name: " Finance ", Groups: &[]spaces.GroupBinding{{Group: "finance", Role: spaces.RoleViewer}}}
+
+	rec := do(t, store, owner, http.MethodPost, "/api/v1/portal/spaces", body)
+	assert.Equal(t, http.StatusForbidden, rec.Code, "binding a group is an admin's call")
+
+	rec = do(t, store, admin, http.MethodPost, "/api/v1/portal/spaces", body)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created spaces.Space
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, "Finance", created.Name)
+	assert.Equal(t, spaces.RoleManager, store.members[created.ID]["root@x.io"], "the creator manages it")
+
+	rec = do(t, store, admin, http.MethodPost, "/api/v1/portal/spaces", body)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	rec = do(t, store, admin, http.MethodPost, "/api/v1/portal/spaces", spaceRequest{Name: " "})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = do(t, store, nil, http.MethodPost, "/api/v1/portal/spaces", body)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestListAndGetSpace(t *testing.T) {
+	store := seeded()
+
+	rec := do(t, store, analyst, http.MethodGet, "/api/v1/portal/spaces", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list spaceListResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, spaces.RoleViewer, list.Data[0].Role, "a member through the group claim")
+
+	rec = do(t, store, outside, http.MethodGet, "/api/v1/portal/spaces", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"data":[]}`, rec.Body.String())
+	rec = do(t, store, outside, http.MethodGet, "/api/v1/portal/spaces?all=true", nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = do(t, store, analyst, http.MethodGet, "/api/v1/portal/spaces/spc_1", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var detail spaceDetail
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &detail))
+	assert.Len(t, detail.Members, 1)
+
+	rec = do(t, store, outside, http.MethodGet, "/api/v1/portal/spaces/spc_1", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "a non-member cannot tell the space exists")
+	rec = do(t, store, admin, http.MethodGet, "/api/v1/portal/spaces/spc_9", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestUpdateSpace(t *testing.T) {
+	store := seeded()
+	store.members["spc_1"]["lead@x.io"] = spaces.RoleManager
+	lead := &access.User{UserID: "lead", Email: "lead@x.io"}
+
+	rec := do(t, store, owner, http.MethodPut, "/api/v1/portal/spaces/spc_1", spaceRequest{Name: "Renamed"})
+	assert.Equal(t, http.StatusForbidden, rec.Code, "an editor does not run the space")
+
+	rec = do(t, store, lead, http.MethodPut, "/api/v1/portal/spaces/spc_1", spaceRequest{Name: "Renamed"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "Renamed", store.spaces["spc_1"].Name)
+	assert.Len(t, store.spaces["spc_1"].Groups, 1, "omitted groups are kept")
+
+	groups := &[]spaces.GroupBinding{{Group: "everyone", Role: spaces.RoleManager}}
+	rec = do(t, store, lead, http.MethodPut, "/api/v1/portal/spaces/spc_1", spaceRequest{Name: "Renamed", Groups: groups})
+	assert.Equal(t, http.StatusForbidden, rec.Code, "only an admin binds groups")
+	rec = do(t, store, admin, http.MethodPut, "/api/v1/portal/spaces/spc_1", spaceRequest{Name: "Renamed", Groups: groups})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "everyone", store.spaces["spc_1"].Groups[0].Group)
+
+	rec = do(t, store, lead, http.MethodDelete, "/api/v1/portal/spaces/spc_1", nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec = do(t, store, admin, http.MethodDelete, "/api/v1/portal/spaces/spc_1", nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestSpaceMembers(t *testing.T) {
+	store := seeded()
+
+	rec := do(t, store, owner, http.MethodPut, "/api/v1/portal/spaces/spc_1/members/bo@x.io", memberRequest{Role: spaces.RoleViewer})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = do(t, store, admin, http.MethodPut, "/api/v1/portal/spaces/spc_1/members/Bo@X.io", memberRequest{Role: spaces.RoleEditor})
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	assert.Equal(t, spaces.RoleEditor, store.members["spc_1"]["bo@x.io"])
+
+	rec = do(t, store, admin, http.MethodPut, "/api/v1/portal/spaces/spc_1/members/bo@x.io", memberRequest{Role: "owner"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = do(t, store, admin, http.MethodDelete, "/api/v1/portal/spaces/spc_1/members/BO@x.io", nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = do(t, store, admin, http.MethodDelete, "/api/v1/portal/spaces/spc_1/members/bo@x.io", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSpaceCollections(t *testing.T) {
+	store := seeded()
+	give := collectionRequest{CollectionID: "col_1"}
+
+	rec := do(t, store, analyst, http.MethodPost, "/api/v1/portal/spaces/spc_1/collections", give)
+	assert.Equal(t, http.StatusForbidden, rec.Code, "a viewer cannot bring collections in")
+
+	rec = do(t, store, owner, http.MethodPost, "/api/v1/portal/spaces/spc_1/collections", collectionRequest{CollectionID: "col_9"})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = do(t, store, owner, http.MethodPost, "/api/v1/portal/spaces/spc_1/collections", give)
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	assert.Equal(t, "spc_1", store.collections["col_1"])
+
+	store.spaces["spc_2"] = spaces.Space{ID: "spc_2", Name: "Ops"}
+	rec = do(t, store, admin, http.MethodPost, "/api/v1/portal/spaces/spc_2/collections", give)
+	assert.Equal(t, http.StatusConflict, rec.Code, "one space owns a collection")
+
+	store.members["spc_1"]["ed@x.io"] = spaces.RoleEditor
+	editor := &access.User{UserID: "ed", Email: "ed@x.io"}
+	rec = do(t, store, editor, http.MethodPost, "/api/v1/portal/spaces/spc_1/collections", give)
+	assert.Equal(t, http.StatusForbidden, rec.Code, "an editor gives only collections they own")
+	rec = do(t, store, editor, http.MethodDelete, "/api/v1/portal/spaces/spc_1/collections/col_1", nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = do(t, store, owner, http.MethodDelete, "/api/v1/portal/spaces/spc_1/collections/col_1", nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code, "the collection's owner takes it back")
+	assert.Empty(t, store.collections)
+}
@@ -0,0 +1,243 @@
+package versiondiff
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"github.com/txn2/mcp-data-platform/pkg/textpatch"
+)
+
+// maxRowChanges bounds the rows one cell diff reports. A rewrite of every row
+// is better read as "everything changed" than as a page of every cell.
+const maxRowChanges = 1000
+
+// CellDiff is the difference between two delimited files. Columns are matched
+// by header name, so a column moved to another position is not a change; rows
+// are aligned on the columns both versions have.
+type CellDiff struct {
+	AddedColumns   []string    `json:"added_columns,omitempty"`
+	RemovedColumns []string    `json:"removed_columns,omitempty"`
+	Rows           []RowChange `json:"rows,omitempty"`
+	// Truncated is true when more rows changed than the diff reports; Rows
+	// holds the first of them.
+	Truncated bool `json:"truncated,omitempty" example:"false"`
+}
+
+// RowChange is one row added, removed, or changed, in the words the
+// structural diff uses (textpatch.BlockAdded and its peers). Rows are numbered
+// from 1 after the header, in the version they come from: a removal has only
+// OldRow, an addition only NewRow. A changed row lists only the cells that
+// differ; an added or removed row lists every non-empty cell.
+type RowChange struct {
+	Op     string       `json:"op" example:"changed"`
+	OldRow int          `json:"old_row,omitempty" example:"12"`
+	NewRow int          `json:"new_row,omitempty" example:"12"`
+	Cells  []CellChange `json:"cells"`
+}
+
+// CellChange is one cell of a changed row, or one value of an added or
+// removed row.
+type CellChange struct {
+	Column string `json:"column" example:"revenue"`
+	Old    string `json:"old,omitempty" example:"1200"`
+	New    string `json:"new,omitempty" example:"1350"`
+}
+
+// identical reports whether the diff found nothing.
+func (d CellDiff) identical() bool {
+	return len(d.AddedColumns) == 0 && len(d.RemovedColumns) == 0 && len(d.Rows) == 0
+}
+
+// table is one parsed delimited file: its column labels, where each label sits
+// in a record, and its data rows.
+type table struct {
+	labels []string
+	index  map[string]int
+	rows   [][]string
+}
+
+// cell returns the value of a labeled column in row r, "" past the record's end.
+func (t table) cell(r int, label string) string {
+	if i := t.index[label]; i < len(t.rows[r]) {
+		return t.rows[r][i]
+	}
+	return ""
+}
+
+// parseTable reads a delimited file. Quoting is read leniently and ragged rows
+// are kept, as the CSV viewer shows them; a file that still does not parse is
+// an error, so the caller can fall back to a line diff rather than compare a
+// part of it.
+func parseTable(body string, comma rune) (table, error) {
+	r := csv.NewReader(strings.NewReader(body))
+	r.Comma = comma
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return table{}, fmt.Errorf("versiondiff: parse delimited file: %w", err)
+	}
+	t := table{index: map[string]int{}}
+	if len(records) == 0 {
+		return t, nil
+	}
+	for i, name := range records[0] {
+		base := strings.TrimSpace(name)
+		if base == "" {
+			base = fmt.Sprintf("column %d", i+1)
+		}
+		label := base
+		for n := 2; t.hasLabel(label); n++ {
+			label = fmt.Sprintf("%s (%d)", base, n)
+		}
+		t.labels = append(t.labels, label)
+		t.index[label] = i
+	}
+	t.rows = records[1:]
+	return t, nil
+}
+
+// hasLabel reports whether the label is already taken.
+func (t table) hasLabel(label string) bool {
+	_, ok := t.index[label]
+	return ok
+}
+
+// compareCells diffs two delimited files. It returns an error only when either
+// side does not parse.
+func compareCells(oldBody, newBody string, comma rune) (CellDiff, error) {
+	a, err := parseTable(oldBody, comma)
+	if err != nil {
+		return CellDiff{}, err
+	}
+	b, err := parseTable(newBody, comma)
+	if err != nil {
+		return CellDiff{}, err
+	}
+
+	rd := rowDiffer{a: a, b: b}
+	rd.common, rd.d.AddedColumns, rd.d.RemovedColumns = matchColumns(a, b)
+	for _, st := range textpatch.Align(rowKeys(a, rd.common), rowKeys(b, rd.common)) {
+		switch {
+		case st.Old >= 0 && st.New >= 0:
+			if !rd.flush() {
+				return rd.d, nil
+			}
+		case st.Old >= 0:
+			rd.removed = append(rd.removed, st.Old)
+		default:
+			rd.added = append(rd.added, st.New)
+		}
+	}
+	rd.flush()
+	return rd.d, nil
+}
+
+// matchColumns splits the labels of two tables into those both have, in the
+// newer table's order, and those only one has.
+func matchColumns(a, b table) (common, added, removed []string) {
+	for _, label := range b.labels {
+		if a.hasLabel(label) {
+			common = append(common, label)
+		} else {
+			added = append(added, label)
+		}
+	}
+	for _, label := range a.labels {
+		if !b.hasLabel(label) {
+			removed = append(removed, label)
+		}
+	}
+	return common, added, removed
+}
+
+// rowDiffer accumulates the row changes of one cell diff: the run of removed
+// and added rows since the last row both tables share, and the changes
+// reported so far.
+type rowDiffer struct {
+	a, b           table
+	common         []string
+	removed, added []int
+	d              CellDiff
+}
+
+// emit records one row change, or marks the diff truncated and returns false
+// once maxRowChanges are recorded.
+func (rd *rowDiffer) emit(c RowChange) bool {
+	if len(rd.d.Rows) == maxRowChanges {
+		rd.d.Truncated = true
+		return false
+	}
+	rd.d.Rows = append(rd.d.Rows, c)
+	return true
+}
+
+// flush reports the pending run: removed and added rows paired in order as
+// changes, the rest as removals and additions. It returns false when the diff
+// was truncated.
+func (rd *rowDiffer) flush() bool {
+	defer func() { rd.removed, rd.added = rd.removed[:0], rd.added[:0] }()
+	n := min(len(rd.removed), len(rd.added))
+	for k := range n {
+		if !rd.emit(changedRow(rd.a, rd.b, rd.removed[k], rd.added[k], rd.common)) {
+			return false
+		}
+	}
+	for _, i := range rd.removed[n:] {
+		if !rd.emit(RowChange{Op: textpatch.BlockRemoved, OldRow: i + 1, Cells: rowCells(rd.a, i, false)}) {
+			return false
+		}
+	}
+	for _, j := range rd.added[n:] {
+		if !rd.emit(RowChange{Op: textpatch.BlockAdded, NewRow: j + 1, Cells: rowCells(rd.b, j, true)}) {
+			return false
+		}
+	}
+	return true
+}
+
+// rowKeys renders each row of t over the given columns as one comparable
+// string. Aligning on the shared columns keeps an added or dropped column from
+// turning every row into a change.
+func rowKeys(t table, columns []string) []string {
+	keys := make([]string, len(t.rows))
+	for r := range t.rows {
+		vals := make([]string, len(columns))
+		for i, label := range columns {
+			vals[i] = t.cell(r, label)
+		}
+		keys[r] = strings.Join(vals, "\x1f")
+	}
+	return keys
+}
+
+// changedRow pairs a removed row with the added row that took its place and
+// lists the shared cells that differ.
+func changedRow(a, b table, i, j int, columns []string) RowChange {
+	c := RowChange{Op: textpatch.BlockChanged, OldRow: i + 1, NewRow: j + 1}
+	for _, label := range columns {
+		if was, is := a.cell(i, label), b.cell(j, label); was != is {
+			c.Cells = append(c.Cells, CellChange{Column: label, Old: was, New: is})
+		}
+	}
+	return c
+}
+
+// rowCells lists the non-empty cells of one row, as new values for an added
+// row and old values for a removed one.
+func rowCells(t table, r int, isNew bool) []CellChange {
+	cells := []CellChange{}
+	for _, label := range t.labels {
+		v := t.cell(r, label)
+		if v == "" {
+			continue
+		}
+		if isNew {
+			cells = append(cells, CellChange{Column: label, New: v})
+		} else {
+			cells = append(cells, CellChange{Column: label, Old: v})
+		}
+	}
+	return cells
+}
@@ -0,0 +1,62 @@
+package versiondiff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/pkg/textpatch"
+)
+
+func TestCompareCSVIsCells(t *testing.T) {
+	old := "region,revenue,owner\nEMEA,100,ana\nAPAC,80,raj\nLATAM,40,lu\n"
+	updated := "revenue,region,notes\n120,EMEA,up\n40,LATAM,\n15,NA,new\n"
+
+	d, err := Compare("text/csv", []byte(old), "text/csv", []byte(updated))
+	require.NoError(t, err)
+	assert.Equal(t, FormatCells, d.Format)
+	require.NotNil(t, d.Cells)
+	assert.Equal(t, []string{"notes"}, d.Cells.AddedColumns)
+	assert.Equal(t, []string{"owner"}, d.Cells.RemovedColumns)
+	assert.Equal(t, []RowChange{
+		{Op: textpatch.BlockChanged, OldRow: 1, NewRow: 1, Cells: []CellChange{{Column: "revenue", Old: "100", New: "120"}}},
+		{Op: textpatch.BlockRemoved, OldRow: 2, Cells: []CellChange{
+			{Column: "region", Old: "APAC"}, {Column: "revenue", Old: "80"}, {Column: "owner", Old: "raj"},
+		}},
+		{Op: textpatch.BlockAdded, NewRow: 3, Cells: []CellChange{
+			{Column: "revenue", New: "15"}, {Column: "region", New: "NA"}, {Column: "notes", New: "new"},
+		}},
+	}, d.Cells.Rows, "columns match by name, so reordering them is not a change")
+}
+
+func TestCompareTSVAndIdentical(t *testing.T) {
+	body := []byte("a\tb\n1\t2\n")
+	d, err := Compare("text/tab-separated-values", body, "text/tab-separated-values", body)
+	require.NoError(t, err)
+	assert.Equal(t, FormatCells, d.Format)
+	assert.True(t, d.Identical)
+}
+
+func TestCompareCellsLabelsBlankAndRepeatedHeaders(t *testing.T) {
+	d, err := compareCells("x,,x\n1,2,3\n", "x,,x\n1,2,4\n", ',')
+	require.NoError(t, err)
+	assert.Equal(t, []RowChange{{Op: textpatch.BlockChanged, OldRow: 1, NewRow: 1,
+		Cells: []CellChange{{Column: "x (2)", Old: "3", New: "4"}}}}, d.Rows)
+}
+
+func TestCompareCellsTruncates(t *testing.T) {
+	var old, updated strings.Builder
+	old.WriteString("n\n")
+	updated.WriteString("n\n")
+	for i := range maxRowChanges + 10 {
+		fmt.Fprintf(&old, "%d\n", i)
+		fmt.Fprintf(&updated, "%d!\n", i)
+	}
+	d, err := compareCells(old.String(), updated.String(), ',')
+	require.NoError(t, err)
+	assert.Len(t, d.Rows, maxRowChanges)
+	assert.True(t, d.Truncated)
+}
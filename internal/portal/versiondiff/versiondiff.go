@@ -0,0 +1,84 @@
+// Package versiondiff compares two stored versions of a portal asset in the
+// shape that suits the asset's content type: a structural, block-by-block diff
+// for HTML through the textpatch document model, a cell diff for CSV and TSV,
+// and a unified line diff for every other textual type, markdown and plain text
+// included.
+//
+// It compares bodies the caller has already read and authorized; it knows
+// nothing of stores, storage or permissions, so the REST handler and any later
+// surface render the same diff for the same pair.
+package versiondiff
+
+import (
+	"errors"
+
+	"github.com/txn2/mcp-data-platform/pkg/contenttype"
+	"github.com/txn2/mcp-data-platform/pkg/textpatch"
+)
+
+// Diff formats.
+const (
+	// FormatStructure is the HTML block diff, in Diff.Blocks.
+	FormatStructure = "structure"
+	// FormatCells is the delimited-file cell diff, in Diff.Cells.
+	FormatCells = "cells"
+	// FormatLines is the unified line diff, in Diff.Unified.
+	FormatLines = "lines"
+)
+
+// MaxBodyBytes is the largest version body Compare accepts on either side.
+// Both bodies and the alignment between them are held in memory for one
+// request, and a diff longer than the documents is no longer a summary.
+const MaxBodyBytes = 4 << 20
+
+var (
+	// ErrNotComparable is returned for a version whose content is not text: an
+	// image, a PDF, an archive.
+	ErrNotComparable = errors.New("versiondiff: content type cannot be compared")
+	// ErrTooLarge is returned when either body exceeds MaxBodyBytes.
+	ErrTooLarge = errors.New("versiondiff: version too large to compare")
+)
+
+// Diff is the difference between two versions of an asset. Format names the
+// one populated field; Identical is true when the versions do not differ in
+// that format (an HTML edit that moves no rendered text is identical).
+type Diff struct {
+	Format    string                  `json:"format" example:"structure"`
+	Identical bool                    `json:"identical" example:"false"`
+	Blocks    []textpatch.BlockChange `json:"blocks,omitempty"`
+	Cells     *CellDiff               `json:"cells,omitempty"`
+	Unified   string                  `json:"unified,omitempty"`
+}
+
+// Compare diffs two version bodies. The format follows the newer version's
+// content type when both sides share it; a pair whose types differ (a revert
+// across a type change, say) falls back to the line diff, which reads any text.
+func Compare(oldType string, oldBody []byte, newType string, newBody []byte) (Diff, error) {
+	if !contenttype.IsTextual(oldType) || !contenttype.IsTextual(newType) {
+		return Diff{}, ErrNotComparable
+	}
+	if len(oldBody) > MaxBodyBytes || len(newBody) > MaxBodyBytes {
+		return Diff{}, ErrTooLarge
+	}
+	ct := contenttype.Normalize(newType)
+	if contenttype.Normalize(oldType) != ct {
+		ct = ""
+	}
+	switch ct {
+	case contenttype.HTML:
+		blocks := textpatch.StructuralDiff(string(oldBody), string(newBody))
+		return Diff{Format: FormatStructure, Identical: len(blocks) == 0, Blocks: blocks}, nil
+	case contenttype.CSV, contenttype.TSV:
+		comma := ','
+		if ct == contenttype.TSV {
+			comma = '\t'
+		}
+		// A file that does not parse as a table is still text; it gets the
+		// line diff rather than a cell diff of the part that parsed.
+		if cells, err := compareCells(string(oldBody), string(newBody), comma); err == nil {
+			return Diff{Format: FormatCells, Identical: cells.identical(), Cells: &cells}, nil
+		}
+	}
+	unified := textpatch.UnifiedDiff(string(oldBody), string(newBody), 0)
+	return Diff{Format: FormatLines, Identical: unified == "", Unified: unified}, nil
+}
@@ -0,0 +1,53 @@
+package versiondiff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/pkg/textpatch"
+)
+
+func TestCompareHTMLIsStructural(t *testing.T) {
+	d, err := Compare("text/html", []byte("<h1>Report</h1><p>Draft.</p>"),
+		"text/html; charset=utf-8", []byte(`<h1>Report</h1><p class="x">Final.</p>`))
+	require.NoError(t, err)
+	assert.Equal(t, FormatStructure, d.Format)
+	assert.False(t, d.Identical)
+	assert.Equal(t, []textpatch.BlockChange{
+		{Op: textpatch.BlockChanged, Element: "p", Section: "Report", Old: "Draft.", New: "Final."},
+	}, d.Blocks)
+
+	d, err = Compare("text/html", []byte("<p>Same.</p>"), "text/html", []byte(`<p style="color: red">Same.</p>`))
+	require.NoError(t, err)
+	assert.True(t, d.Identical, "a style-only edit renders no different text")
+}
+
+func TestCompareMarkdownIsLines(t *testing.T) {
+	d, err := Compare("text/markdown", []byte("# A\n\nold\n"), "text/markdown", []byte("# A\n\nnew\n"))
+	require.NoError(t, err)
+	assert.Equal(t, FormatLines, d.Format)
+	assert.Equal(t, "@@ -1,3 +1,3 @@\n # A\n \n-old\n+new\n", d.Unified)
+
+	d, err = Compare("text/plain", []byte("x\n"), "text/plain", []byte("x\n"))
+	require.NoError(t, err)
+	assert.True(t, d.Identical)
+	assert.Empty(t, d.Unified)
+}
+
+func TestCompareAcrossTypesFallsBackToLines(t *testing.T) {
+	d, err := Compare("text/markdown", []byte("a\n"), "text/html", []byte("<p>a</p>\n"))
+	require.NoError(t, err)
+	assert.Equal(t, FormatLines, d.Format)
+}
+
+func TestCompareRefusals(t *testing.T) {
+	_, err := Compare("image/png", []byte{0x89}, "image/png", []byte{0x89})
+	require.ErrorIs(t, err, ErrNotComparable)
+
+	big := []byte(strings.Repeat("a", MaxBodyBytes+1))
+	_, err = Compare("text/plain", big, "text/plain", []byte("a"))
+	require.ErrorIs(t, err, ErrTooLarge)
+}
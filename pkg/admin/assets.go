@@ -598,8 +598,9 @@ func (h *Handler) getAdminVersionContent(w http.ResponseWriter, r *http.Request)
 // @Description  Creates a new asset version by reverting to a previous version's content.
 // @Tags         Portal Assets
 // @Produce      json
-// @Param        id       path  string  true  "Asset ID"
-// @Param        version  path  string  true  "Version number to revert to"
+// @Param        id                path    string  true   "Asset ID"
+// @Param        version           path    string  true   "Version number to revert to"
+// @Param        X-Change-Summary  header  string  false  "Change summary for the new version"
 // @Success      200  {object}  map[string]interface{}
 // @Failure      400  {object}  problemDetail
 // @Failure      404  {object}  problemDetail
@@ -661,7 +662,7 @@ func (h *Handler) revertAdminVersion(w http.ResponseWriter, r *http.Request) {
 		ContentType:   targetVer.ContentType,
 		SizeBytes:     int64(len(data)),
 		CreatedBy:     adminUserEmail(r),
-		ChangeSummary: fmt.Sprintf("Reverted from v%d (admin)", versionNum),
+		ChangeSummary: adminChangeSummary(r, fmt.Sprintf("Reverted from v%d (admin)", versionNum)),
 	}
 	assignedVersion, err := h.deps.VersionStore.CreateVersion(r.Context(), av)
 	if err != nil {
@@ -114,7 +114,14 @@ type Deps struct {
 	CallCatalog CallCatalog
 	// CallPromoter publishes a reviewed record. nil leaves the promote and
 	// reject actions unregistered.
-	CallPromoter      *CallPromoter
+	CallPromoter *CallPromoter
+	// ScriptQuotas is what managed scripts spent and the allowances bounding
+	// it. nil leaves the /api/v1/admin/scripts usage and quota routes
+	// unregistered.
+	ScriptQuotas ScriptQuotas
+	// ScriptSecrets keeps the secrets granted to script versions. nil leaves
+	// the /api/v1/admin/script-secrets routes unregistered.
+	ScriptSecrets     ScriptSecrets
 	Knowledge         *KnowledgeHandler
 	APIKeyManager     APIKeyManager
 	BrowserAuth       *browsersession.Authenticator
@@ -366,6 +373,8 @@ func (h *Handler) registerRoutes() {
 	h.registerAuditRoutes()
 	h.registerSessionRoutes()
 	h.registerCallRoutes()
+	h.registerScriptQuotaRoutes()
+	h.registerScriptSecretRoutes()
 	h.registerConfigRoutes()
 	h.registerPersonaRoutes()
 	h.registerAuthKeyRoutes()
@@ -0,0 +1,19 @@
+package admin
+
+import (
+	"github.com/txn2/mcp-data-platform/internal/admin/scriptquotaapi"
+)
+
+// ScriptQuotas reads managed-script usage and keeps script quotas. Aliased to
+// the seam's declaration rather than restated so the two cannot drift.
+type ScriptQuotas = scriptquotaapi.Store
+
+// registerScriptQuotaRoutes mounts the script usage rollup and the quota
+// routes, implemented in the scriptquotaapi subpackage. The actor is supplied
+// from here because the authenticated identity is the admin handler's to know.
+func (h *Handler) registerScriptQuotaRoutes() {
+	scriptquotaapi.Register(h.mux, scriptquotaapi.Config{
+		Quotas: h.deps.ScriptQuotas,
+		Actor:  adminUserEmail,
+	})
+}
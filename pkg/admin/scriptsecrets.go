@@ -0,0 +1,19 @@
+package admin
+
+import (
+	"github.com/txn2/mcp-data-platform/internal/admin/scriptsecretapi"
+)
+
+// ScriptSecrets keeps the secrets operators grant to managed scripts. Aliased
+// to the seam's declaration rather than restated so the two cannot drift.
+type ScriptSecrets = scriptsecretapi.Store
+
+// registerScriptSecretRoutes mounts the script secret and grant routes,
+// implemented in the scriptsecretapi subpackage. The actor is supplied from
+// here because the authenticated identity is the admin handler's to know.
+func (h *Handler) registerScriptSecretRoutes() {
+	scriptsecretapi.Register(h.mux, scriptsecretapi.Config{
+		Secrets: h.deps.ScriptSecrets,
+		Actor:   adminUserEmail,
+	})
+}
@@ -56,6 +56,22 @@ const (
 	// executed it and how it ended — and both carry the run id as their session
 	// so a run and its calls join on one key (#1284).
 	EventTypeScriptRun EventType = "script_run"
+
+	// EventTypeToolApproval categorizes a step in the approval workflow for
+	// a gated write operation: a call parked for review, or a person's
+	// decision on it. The event's parameters carry approval_id, action
+	// (requested, approved, rejected), rule, and the request's method and
+	// path. The call an approval releases is audited separately, as the
+	// ordinary tool call it is, when the agent repeats it.
+	EventTypeToolApproval EventType = "tool_approval"
+
+	// EventTypeEmbedView categorizes a portal asset opened through an embed
+	// token: the page load, not the content fetches the page makes. The
+	// event's parameters carry asset_id, version, embed_id (the token's id,
+	// so views trace to the mint), issued_by, and the embedding page's
+	// origin when the browser sent a referrer. The viewer is anonymous, so
+	// the event carries no user.
+	EventTypeEmbedView EventType = "embed_view"
 )
 
 // toolkitKindAPIGateway is the toolkit-kind discriminator for the
@@ -235,11 +251,29 @@ func SanitizeParameters(params map[string]any) map[string]any {
 
 	sanitized := make(map[string]any)
 	for k, v := range params {
-		if sensitiveKeys[k] {
+		switch {
+		case sensitiveKeys[k]:
 			sanitized[k] = "[REDACTED]"
-		} else {
+		case k == "headers":
+			sanitized[k] = sanitizeHeaders(v)
+		default:
 			sanitized[k] = v
 		}
 	}
 	return sanitized
 }
+
+// sanitizeHeaders keeps the names of the request headers a tool call sends and
+// redacts their values: a header is where a credential travels, including the
+// secret a managed script presents (internal/platform/scriptsecret).
+func sanitizeHeaders(v any) any {
+	headers, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	out := make(map[string]any, len(headers))
+	for name := range headers {
+		out[name] = "[REDACTED]"
+	}
+	return out
+}
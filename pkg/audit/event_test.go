@@ -169,6 +169,24 @@ func TestSanitizeParameters(t *testing.T) {
 	}
 }
 
+func TestSanitizeParameters_HeaderValues(t *testing.T) {
+	sanitized := SanitizeParameters(map[string]any{
+		"connection": "acme",
+		"headers":    map[string]any{"X-Api-Key": "hunter2", "Accept": "text/csv"},
+	})
+
+	headers, ok := sanitized["headers"].(map[string]any)
+	if !ok {
+		t.Fatalf("headers = %T, want a map keeping the header names", sanitized["headers"])
+	}
+	if headers["X-Api-Key"] != redactedValue || headers["Accept"] != redactedValue {
+		t.Errorf("headers = %v, want every value %s", headers, redactedValue)
+	}
+	if sanitized["connection"] != "acme" {
+		t.Error("connection should not be sanitized")
+	}
+}
+
 func TestSanitizeParameters_Nil(t *testing.T) {
 	sanitized := SanitizeParameters(nil)
 	if sanitized != nil {
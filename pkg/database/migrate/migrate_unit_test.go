@@ -15,7 +15,7 @@ import (
 )
 
 const (
-	migrateTestFileCount    = 242
+	migrateTestFileCount    = 278
 	migrateTestSuccess      = "success"
 	migrateTestFactoryError = "factory error"
 )
@@ -0,0 +1,13 @@
+-- 000122 (down): restore the pre-grpc source_kind CHECK constraint.
+--
+-- Rows written with source_kind = 'grpc' would violate the restored
+-- constraint, so delete them first. A grpc connection re-creates its
+-- specs the next time it loads on a binary that supports the kind.
+DELETE FROM api_catalog_specs WHERE source_kind = 'grpc';
+
+ALTER TABLE api_catalog_specs
+    DROP CONSTRAINT IF EXISTS api_catalog_specs_source_kind_check;
+
+ALTER TABLE api_catalog_specs
+    ADD CONSTRAINT api_catalog_specs_source_kind_check
+        CHECK (source_kind IN ('inline', 'upload', 'url', 'embedded'));
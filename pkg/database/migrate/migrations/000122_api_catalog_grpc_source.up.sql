@@ -0,0 +1,16 @@
+-- 000122: allow 'grpc' as an api_catalog_specs source_kind
+--
+-- A kind=grpc connection (pkg/toolkits/grpcgateway) indexes the services
+-- it loads from a FileDescriptorSet or server reflection into its API
+-- catalog as a synthesized OpenAPI document per service. Those rows are
+-- written with source_kind = 'grpc' so they are distinguishable from
+-- operator-managed specs and re-derived on every connection load rather
+-- than edited in place.
+--
+-- Same DROP/ADD shape as 000058.
+ALTER TABLE api_catalog_specs
+    DROP CONSTRAINT IF EXISTS api_catalog_specs_source_kind_check;
+
+ALTER TABLE api_catalog_specs
+    ADD CONSTRAINT api_catalog_specs_source_kind_check
+        CHECK (source_kind IN ('inline', 'upload', 'url', 'embedded', 'grpc'));
@@ -0,0 +1,7 @@
+-- Reverse 000123. Pending requests and the decision history are discarded;
+-- the audit log keeps its tool_approval events.
+DROP INDEX IF EXISTS tool_approvals_requested_by_idx;
+DROP INDEX IF EXISTS tool_approvals_status_idx;
+DROP INDEX IF EXISTS tool_approvals_fingerprint_idx;
+DROP INDEX IF EXISTS tool_approvals_pending_key;
+DROP TABLE IF EXISTS tool_approvals;
@@ -0,0 +1,53 @@
+-- 000123: write operations parked for a person's approval.
+--
+-- A row is one tools/call an approval rule held back: the full request as
+-- the agent sent it, the rule's approvers as they stood when it was parked,
+-- and what became of it. The call itself is never executed from here. The
+-- agent repeats it once approved, and the gate matches the repeat to its row
+-- by fingerprint (caller, tool, canonical arguments), so the approval covers
+-- exactly the request the approver read.
+--
+-- expires_at is the row's live window. It starts as the deadline for a
+-- decision and a decision moves it: an approval is usable until then, a
+-- rejection is reported to a repeat until then. consumed_at marks the one
+-- repeat that used the decision.
+CREATE TABLE IF NOT EXISTS tool_approvals (
+    id                 TEXT PRIMARY KEY,
+    fingerprint        TEXT NOT NULL,
+    status             TEXT NOT NULL CHECK (status IN ('pending', 'approved', 'rejected', 'expired')),
+    rule               TEXT NOT NULL DEFAULT '',
+    tool_name          TEXT NOT NULL,
+    toolkit_kind       TEXT NOT NULL DEFAULT '',
+    connection         TEXT NOT NULL DEFAULT '',
+    method             TEXT NOT NULL DEFAULT '',
+    path               TEXT NOT NULL DEFAULT '',
+    arguments          JSONB NOT NULL DEFAULT '{}'::jsonb,
+    requested_by       TEXT NOT NULL,
+    requested_by_email TEXT NOT NULL DEFAULT '',
+    persona            TEXT NOT NULL DEFAULT '',
+    approvers          JSONB NOT NULL DEFAULT '{}'::jsonb,
+    decided_by         TEXT NOT NULL DEFAULT '',
+    reason             TEXT NOT NULL DEFAULT '',
+    decided_at         TIMESTAMPTZ,
+    consumed_at        TIMESTAMPTZ,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at         TIMESTAMPTZ NOT NULL
+);
+
+-- One pending request per fingerprint. An agent that repeats a parked call
+-- while it waits joins the open request instead of mailing the approvers a
+-- second time; the insert's ON CONFLICT arbitrates two replicas racing.
+CREATE UNIQUE INDEX IF NOT EXISTS tool_approvals_pending_key
+    ON tool_approvals (fingerprint) WHERE status = 'pending';
+
+-- The gate's read on every gated call: the newest row for one fingerprint.
+CREATE INDEX IF NOT EXISTS tool_approvals_fingerprint_idx
+    ON tool_approvals (fingerprint, created_at DESC);
+
+-- The approver's queue: requests by status, newest first.
+CREATE INDEX IF NOT EXISTS tool_approvals_status_idx
+    ON tool_approvals (status, created_at DESC);
+
+-- A requester's own history.
+CREATE INDEX IF NOT EXISTS tool_approvals_requested_by_idx
+    ON tool_approvals (requested_by, created_at DESC);
@@ -0,0 +1,4 @@
+-- Reverse 000124. The recorded drift is discarded; the contract-check
+-- counters in the metrics backend are unaffected.
+DROP INDEX IF EXISTS idx_api_contract_drift_catalog;
+DROP TABLE IF EXISTS api_contract_drift;
@@ -0,0 +1,35 @@
+-- 000124: api_contract_drift
+--
+-- Contract validation compares a connection's live responses with the
+-- operation in its API catalog that describes them. This table is where the
+-- differences land: one row per operation per connection, counting the
+-- violations seen and keeping the latest one, so an operator reading a
+-- catalog can see which endpoints no longer answer the way their spec says.
+--
+-- It is keyed by connection rather than by catalog, as api_endpoint_examples
+-- is: two connections can share a spec and front different deployments of
+-- the upstream, and only one of them may have changed. path is the spec's
+-- path template, not the concrete path a call used, so every call to one
+-- operation lands on one row.
+--
+-- last_violation describes where the body failed and why, never the value
+-- that failed, so the table holds no upstream response data.
+CREATE TABLE IF NOT EXISTS api_contract_drift (
+    connection     TEXT        NOT NULL,
+    method         TEXT        NOT NULL,
+    path           TEXT        NOT NULL,
+    catalog_id     TEXT        NOT NULL,
+    spec_name      TEXT        NOT NULL,
+    operation_id   TEXT        NOT NULL DEFAULT '',
+    violations     BIGINT      NOT NULL DEFAULT 0,
+    last_status    INTEGER     NOT NULL DEFAULT 0,
+    last_violation TEXT        NOT NULL DEFAULT '',
+    first_seen_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (connection, method, path),
+    FOREIGN KEY (catalog_id, spec_name)
+        REFERENCES api_catalog_specs(catalog_id, spec_name) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_contract_drift_catalog
+    ON api_contract_drift(catalog_id, last_seen_at DESC);
@@ -0,0 +1,23 @@
+-- Reverse 000125. Drop the event columns and narrow trigger_kind back.
+--
+-- Runs recorded as 'event' are relabelled 'schedule' before the check is
+-- narrowed, as 000114's reversal does for 'portal': the run is kept and only
+-- the label is lost. Event schedules are disabled rather than deleted, because
+-- their runs point at them; with the trigger gone they carry no cron
+-- expression, and disabled is what a schedule that cannot fire should read as.
+
+DROP INDEX IF EXISTS idx_script_runs_schedule_event;
+
+UPDATE script_runs SET trigger_kind = 'schedule' WHERE trigger_kind = 'event';
+
+ALTER TABLE script_runs DROP CONSTRAINT IF EXISTS script_runs_trigger_kind_check;
+ALTER TABLE script_runs ADD CONSTRAINT script_runs_trigger_kind_check
+    CHECK (trigger_kind IN ('tool', 'schedule', 'portal'));
+
+ALTER TABLE script_runs DROP COLUMN IF EXISTS event_key;
+
+UPDATE script_schedules SET enabled = FALSE, next_run_at = NULL WHERE trigger IS NOT NULL;
+
+ALTER TABLE script_schedules
+    DROP COLUMN IF EXISTS event_cursor,
+    DROP COLUMN IF EXISTS trigger;
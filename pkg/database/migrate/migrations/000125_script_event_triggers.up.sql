@@ -0,0 +1,41 @@
+-- 000125: event triggers for managed scripts.
+--
+-- A schedule said WHEN a script runs by the clock. A report that should run when
+-- its data lands had to guess the hour instead. A schedule may now carry a
+-- trigger in place of its cron expression: an object appearing under an S3
+-- prefix, a Trino table's latest partition changing, a DataHub entity changing,
+-- or another script finishing a successful run.
+--
+-- No new producer of runs is added. An event schedule is still a schedule row,
+-- walked by the same materializer on the same pass: next_run_at is when its
+-- source is next observed rather than when it next fires, and an observation
+-- that differs from the last one materializes a run through the same insert,
+-- under the same guarantees.
+--
+-- trigger is NULL for a cron schedule, which is every row that exists today.
+-- event_cursor is the last observation of the trigger's source. NULL means the
+-- source has never been observed, and is distinct from '' (observed, and empty):
+-- the first observation of a new trigger is a baseline, not an event, so
+-- setting a trigger does not fire for data that landed before it was set.
+
+ALTER TABLE script_schedules
+    ADD COLUMN IF NOT EXISTS trigger      JSONB,
+    ADD COLUMN IF NOT EXISTS event_cursor TEXT;
+
+-- event_key names the observation a run was materialized for, and is NULL for
+-- every other run.
+ALTER TABLE script_runs
+    ADD COLUMN IF NOT EXISTS event_key TEXT;
+
+ALTER TABLE script_runs DROP CONSTRAINT IF EXISTS script_runs_trigger_kind_check;
+ALTER TABLE script_runs ADD CONSTRAINT script_runs_trigger_kind_check
+    CHECK (trigger_kind IN ('tool', 'schedule', 'portal', 'event'));
+
+-- The single-fire guarantee for events, in the shape 000100 gave it for cron.
+-- Every replica observes the source, so several see the same change, each at a
+-- moment of its own. fire_time therefore differs between them and cannot be the
+-- key; the observation itself can, and this index means exactly one run exists
+-- per schedule per observed change.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_script_runs_schedule_event
+    ON script_runs(schedule_id, event_key)
+    WHERE schedule_id IS NOT NULL AND event_key IS NOT NULL;
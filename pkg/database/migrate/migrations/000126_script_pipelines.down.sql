@@ -0,0 +1,14 @@
+-- Reverse 000126. Drop the pipeline tables and narrow trigger_kind back.
+--
+-- Runs a pipeline started are relabelled 'tool' before the check is narrowed,
+-- as 000125's reversal does for 'event': the run is kept and only the label is
+-- lost. With the pipelines gone, a request is the nearest true description.
+
+UPDATE script_runs SET trigger_kind = 'tool' WHERE trigger_kind = 'pipeline';
+
+ALTER TABLE script_runs DROP CONSTRAINT IF EXISTS script_runs_trigger_kind_check;
+ALTER TABLE script_runs ADD CONSTRAINT script_runs_trigger_kind_check
+    CHECK (trigger_kind IN ('tool', 'schedule', 'portal', 'event'));
+
+DROP TABLE IF EXISTS script_pipeline_runs;
+DROP TABLE IF EXISTS script_pipelines;
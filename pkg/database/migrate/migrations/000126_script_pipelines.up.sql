@@ -0,0 +1,76 @@
+-- 000126: pipelines of managed scripts.
+--
+-- A script is one unit with one schedule, so a multi-step process was either
+-- one very large script or several schedules with guessed offsets between them.
+-- A pipeline names scripts as steps with dependencies between them, and hands a
+-- step the outputs published by the steps before it.
+--
+-- No new executor is added. Each step runs as an ordinary script_runs row, on
+-- the same queue, through the same worker, under its own script's principal;
+-- the pipeline run records which run each step is and is advanced by reading
+-- those runs back.
+--
+-- steps is the graph as JSONB. A pipeline run copies it, so a run walks the
+-- pipeline as it was when the run began and an edit changes only later runs.
+-- Steps name scripts by id and carry no foreign key: a step whose script was
+-- deleted fails when it starts, with a reason, rather than the pipeline
+-- disappearing with the script.
+
+CREATE TABLE IF NOT EXISTS script_pipelines (
+    id          UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        TEXT        NOT NULL,
+    description TEXT        NOT NULL DEFAULT '',
+    owner_email TEXT        NOT NULL DEFAULT '',
+    steps       JSONB       NOT NULL DEFAULT '[]',
+    cron_spec   TEXT        NOT NULL DEFAULT '',
+    timezone    TEXT        NOT NULL DEFAULT 'UTC',
+    enabled     BOOLEAN     NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMPTZ,
+    created_by  TEXT        NOT NULL DEFAULT '',
+    updated_by  TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (owner_email, name)
+);
+
+-- The due query of the pipeline pass, in the shape of idx_script_schedules_due.
+CREATE INDEX IF NOT EXISTS idx_script_pipelines_due
+    ON script_pipelines(next_run_at)
+    WHERE enabled AND cron_spec <> '';
+
+-- revision is the optimistic lock every replica's pipeline pass writes
+-- against: a step is started by the one update that moved the row, so two
+-- replicas reading the same run cannot both start it.
+CREATE TABLE IF NOT EXISTS script_pipeline_runs (
+    id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    pipeline_id  UUID        NOT NULL REFERENCES script_pipelines(id) ON DELETE CASCADE,
+    trigger_kind TEXT        NOT NULL CHECK (trigger_kind IN ('tool', 'schedule', 'portal')),
+    status       TEXT        NOT NULL DEFAULT 'running'
+                             CHECK (status IN ('running', 'succeeded', 'failed')),
+    steps        JSONB       NOT NULL DEFAULT '[]',
+    requested_by TEXT        NOT NULL DEFAULT '',
+    fire_time    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    attempt      INTEGER     NOT NULL DEFAULT 0,
+    revision     INTEGER     NOT NULL DEFAULT 0,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_script_pipeline_runs_pipeline
+    ON script_pipeline_runs(pipeline_id, created_at DESC);
+
+-- The pipeline pass walks the open runs on every tick.
+CREATE INDEX IF NOT EXISTS idx_script_pipeline_runs_open
+    ON script_pipeline_runs(created_at)
+    WHERE status = 'running';
+
+-- The single-fire guarantee of 000100, for a pipeline's cadence: every replica
+-- notices the same fire, and exactly one run exists for it.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_script_pipeline_runs_fire
+    ON script_pipeline_runs(pipeline_id, fire_time)
+    WHERE trigger_kind = 'schedule';
+
+ALTER TABLE script_runs DROP CONSTRAINT IF EXISTS script_runs_trigger_kind_check;
+ALTER TABLE script_runs ADD CONSTRAINT script_runs_trigger_kind_check
+    CHECK (trigger_kind IN ('tool', 'schedule', 'portal', 'event', 'pipeline'));
@@ -0,0 +1,10 @@
+-- Reverse 000127. Drop the data-quality columns.
+--
+-- A data-quality failure stays a failed run with its summary in error; only
+-- the distinction from an execution failure, and the per-check detail, are
+-- lost.
+
+ALTER TABLE script_runs DROP CONSTRAINT IF EXISTS script_runs_failure_kind_check;
+ALTER TABLE script_runs
+    DROP COLUMN IF EXISTS quality_checks,
+    DROP COLUMN IF EXISTS failure_kind;
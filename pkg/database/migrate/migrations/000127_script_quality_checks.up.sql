@@ -0,0 +1,23 @@
+-- 000127: data-quality assertions in managed scripts.
+--
+-- A script could query and publish, but it could not say what it expected of
+-- the data. It may now assert it — row counts, null rates, freshness,
+-- uniqueness, a query that must return nothing — and a run whose assertions
+-- fail is a failed run.
+--
+-- It is still status 'failed'. Every surface that reads a failure already
+-- reads this one, and a pipeline step whose data did not hold up stops the
+-- steps after it exactly as a crashed one does. failure_kind is what tells the
+-- two apart: '' for a script that did not run to the end, 'data_quality' for
+-- one that did and found its data wrong. Every existing row is the former.
+--
+-- quality_checks is every assertion the run made, passed and failed alike, so
+-- a run's history shows what was verified as well as what was not.
+
+ALTER TABLE script_runs
+    ADD COLUMN IF NOT EXISTS failure_kind   TEXT  NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS quality_checks JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE script_runs DROP CONSTRAINT IF EXISTS script_runs_failure_kind_check;
+ALTER TABLE script_runs ADD CONSTRAINT script_runs_failure_kind_check
+    CHECK (failure_kind IN ('', 'data_quality'));
@@ -0,0 +1,23 @@
+-- 000128: per-script retention of run history and output versions.
+--
+-- scripts.run_retention_days is one window for every script, and the asset
+-- version cap is one number for every asset. Neither fits a deployment whose
+-- hourly refresh and quarterly report live side by side: the first wants a
+-- week of history and a handful of versions, the second wants years of both.
+--
+-- A script may now say what it keeps. The row sits beside the script rather
+-- than on it, as its schedule does: it governs what the platform keeps, not
+-- what the script is, so changing it is not an edit a version snapshots. A
+-- script with no row, or a NULL column, keeps what the deployment keeps.
+--
+-- run_retention_days is read by the run sweep in place of the deployment
+-- window. output_versions is applied to each portal asset the script writes as
+-- that asset's max_versions when a run writes it; 0 keeps every version.
+
+CREATE TABLE IF NOT EXISTS script_retention (
+    script_id          UUID        PRIMARY KEY REFERENCES scripts(id) ON DELETE CASCADE,
+    run_retention_days INTEGER     CHECK (run_retention_days BETWEEN 1 AND 3650),
+    output_versions    INTEGER     CHECK (output_versions >= 0),
+    updated_by         TEXT        NOT NULL DEFAULT '',
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,31 @@
+-- Reverse 000129. Drop the backfill table and restore the schedule indexes.
+--
+-- A replayed run may share its (schedule, fire time) with the fire it
+-- replayed, which the restored single-fire index would refuse. Backfill runs
+-- are therefore detached from their schedule and relabelled 'tool' first, as
+-- 000126's reversal does for 'pipeline': the run is kept and only its
+-- provenance is lost.
+
+DROP INDEX IF EXISTS idx_script_runs_backfill_fire;
+
+UPDATE script_runs
+   SET trigger_kind = 'tool', schedule_id = NULL
+ WHERE trigger_kind = 'backfill';
+
+DROP INDEX IF EXISTS idx_script_runs_schedule_open;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_script_runs_schedule_open
+    ON script_runs(schedule_id)
+    WHERE schedule_id IS NOT NULL AND status IN ('pending', 'running');
+
+DROP INDEX IF EXISTS idx_script_runs_schedule_fire;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_script_runs_schedule_fire
+    ON script_runs(schedule_id, fire_time)
+    WHERE schedule_id IS NOT NULL;
+
+ALTER TABLE script_runs DROP CONSTRAINT IF EXISTS script_runs_trigger_kind_check;
+ALTER TABLE script_runs ADD CONSTRAINT script_runs_trigger_kind_check
+    CHECK (trigger_kind IN ('tool', 'schedule', 'portal', 'event', 'pipeline'));
+
+ALTER TABLE script_runs DROP COLUMN IF EXISTS backfill_id;
+
+DROP TABLE IF EXISTS script_backfills;
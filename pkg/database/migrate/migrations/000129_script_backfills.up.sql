@@ -0,0 +1,75 @@
+-- 000129: backfills of scheduled scripts over a date range.
+--
+-- A schedule's misfire policy is fire-once-latest (000100), so a script that
+-- was created late, or broken for a week and then fixed, has a gap nothing
+-- refills. A backfill replays a schedule's fires between two dates, a few at a
+-- time, through the same materialization every fire uses.
+--
+-- A backfill run is an ordinary scheduled run that also names its backfill.
+-- It keeps schedule_id, so it reads in the schedule's history as the fire it
+-- is, and fire_time, so the script computes the day it was replayed for. What
+-- changes is which unique index governs it: a replay exists to run a fire the
+-- schedule may already have run, and to run several at once, so the schedule's
+-- single-fire and one-open-run indexes now exclude backfill runs, and a
+-- backfill's own single-fire guarantee is (backfill_id, fire_time).
+--
+-- fires is the enumeration made when the backfill was requested, and skipped
+-- the fires in the range that had already succeeded and were left alone. Both
+-- are bounded by the domain (script.Backfill), so they live on the row.
+
+CREATE TABLE IF NOT EXISTS script_backfills (
+    id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    script_id    UUID        NOT NULL REFERENCES scripts(id) ON DELETE CASCADE,
+    schedule_id  UUID        NOT NULL REFERENCES script_schedules(id) ON DELETE CASCADE,
+    from_date    DATE        NOT NULL,
+    to_date      DATE        NOT NULL,
+    cron_spec    TEXT        NOT NULL,
+    timezone     TEXT        NOT NULL DEFAULT 'UTC',
+    params       JSONB       NOT NULL DEFAULT '{}',
+    fires        JSONB       NOT NULL DEFAULT '[]',
+    skipped      JSONB       NOT NULL DEFAULT '[]',
+    rerun        BOOLEAN     NOT NULL DEFAULT FALSE,
+    concurrency  INTEGER     NOT NULL DEFAULT 2 CHECK (concurrency BETWEEN 1 AND 10),
+    status       TEXT        NOT NULL DEFAULT 'running'
+                             CHECK (status IN ('running', 'completed', 'cancelled')),
+    requested_by TEXT        NOT NULL DEFAULT '',
+    cancelled_by TEXT        NOT NULL DEFAULT '',
+    error        TEXT        NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_script_backfills_script
+    ON script_backfills(script_id, created_at DESC);
+
+-- One running backfill per schedule. Two overlapping replays of the same days
+-- would each run them, and the second is almost always a repeated click.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_script_backfills_open
+    ON script_backfills(schedule_id)
+    WHERE status = 'running';
+
+-- ON DELETE SET NULL: a run is history, and removing a backfill's record does
+-- not un-run what it ran.
+ALTER TABLE script_runs
+    ADD COLUMN IF NOT EXISTS backfill_id UUID REFERENCES script_backfills(id) ON DELETE SET NULL;
+
+ALTER TABLE script_runs DROP CONSTRAINT IF EXISTS script_runs_trigger_kind_check;
+ALTER TABLE script_runs ADD CONSTRAINT script_runs_trigger_kind_check
+    CHECK (trigger_kind IN ('tool', 'schedule', 'portal', 'event', 'pipeline', 'backfill'));
+
+DROP INDEX IF EXISTS idx_script_runs_schedule_fire;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_script_runs_schedule_fire
+    ON script_runs(schedule_id, fire_time)
+    WHERE schedule_id IS NOT NULL AND backfill_id IS NULL;
+
+DROP INDEX IF EXISTS idx_script_runs_schedule_open;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_script_runs_schedule_open
+    ON script_runs(schedule_id)
+    WHERE schedule_id IS NOT NULL AND backfill_id IS NULL AND status IN ('pending', 'running');
+
+-- The single-fire guarantee of 000100, for a replay: every replica walks the
+-- same open backfill, and exactly one run exists per backfill per fire.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_script_runs_backfill_fire
+    ON script_runs(backfill_id, fire_time)
+    WHERE backfill_id IS NOT NULL;
@@ -0,0 +1,12 @@
+-- Reverse 000130. Drop the quotas.
+--
+-- A run a quota stopped stays a failed run with its reason in error; only the
+-- distinction from an execution failure is lost. The spend recorded in each
+-- run's metrics is left where it is.
+
+UPDATE script_runs SET failure_kind = '' WHERE failure_kind = 'quota';
+ALTER TABLE script_runs DROP CONSTRAINT IF EXISTS script_runs_failure_kind_check;
+ALTER TABLE script_runs ADD CONSTRAINT script_runs_failure_kind_check
+    CHECK (failure_kind IN ('', 'data_quality'));
+
+DROP TABLE IF EXISTS script_quotas;
@@ -0,0 +1,39 @@
+-- 000130: quotas on what managed script runs spend.
+--
+-- A run was bounded per run (steps, wall time, rows returned per query) and
+-- never over time, so a schedule that scans the warehouse end to end every
+-- hour was as welcome as one that reads a summary table once a day. A quota
+-- is a daily allowance on one script, or on every script one person owns,
+-- over wall time, rows scanned, tool calls, and output bytes; 0 leaves an
+-- axis unbounded.
+--
+-- Usage is not stored here. Every finished run records what it spent in
+-- script_runs.metrics, and the allowance left is the sum of those over the
+-- window, so the rollup an operator reads and the figure a quota is enforced
+-- against cannot disagree.
+--
+-- A run the quota stopped, or refused before it started, fails with
+-- failure_kind 'quota'.
+
+CREATE TABLE IF NOT EXISTS script_quotas (
+    id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    script_id    UUID        REFERENCES scripts(id) ON DELETE CASCADE,
+    owner_email  TEXT,
+    wall_seconds BIGINT      NOT NULL DEFAULT 0 CHECK (wall_seconds >= 0),
+    rows_scanned BIGINT      NOT NULL DEFAULT 0 CHECK (rows_scanned >= 0),
+    tool_calls   BIGINT      NOT NULL DEFAULT 0 CHECK (tool_calls >= 0),
+    output_bytes BIGINT      NOT NULL DEFAULT 0 CHECK (output_bytes >= 0),
+    updated_by   TEXT        NOT NULL DEFAULT '',
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((script_id IS NULL) <> (owner_email IS NULL))
+);
+
+-- One quota per script and one per owner.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_script_quotas_script
+    ON script_quotas(script_id) WHERE script_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_script_quotas_owner
+    ON script_quotas(owner_email) WHERE owner_email IS NOT NULL;
+
+ALTER TABLE script_runs DROP CONSTRAINT IF EXISTS script_runs_failure_kind_check;
+ALTER TABLE script_runs ADD CONSTRAINT script_runs_failure_kind_check
+    CHECK (failure_kind IN ('', 'data_quality', 'quota'));
@@ -0,0 +1,13 @@
+-- Reverse 000131. Drop libraries and the load graph.
+--
+-- A library becomes an ordinary script that nothing schedules, and a script
+-- that loads one keeps its source, which fails at the load until it is edited.
+
+DROP INDEX IF EXISTS idx_scripts_loads;
+DROP INDEX IF EXISTS idx_scripts_library_name;
+
+ALTER TABLE script_versions DROP COLUMN IF EXISTS loads;
+
+ALTER TABLE scripts
+    DROP COLUMN IF EXISTS loads,
+    DROP COLUMN IF EXISTS library;
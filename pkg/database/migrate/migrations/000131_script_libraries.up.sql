@@ -0,0 +1,25 @@
+-- 000131: library scripts and the load graph between scripts.
+--
+-- A library is a script other scripts load by name at a pinned version, with
+-- load("name@version", ...). It never runs on its own, and whether a script is
+-- a library is fixed when it is created. Its name is unique among libraries,
+-- because that name is how a load finds it.
+--
+-- loads records the libraries a script's source loads, each as
+-- {script_id, name, version}. It is resolved and checked when the source is
+-- saved, so a stored edge always names a library version that exists, and
+-- script_versions keeps each version's own loads beside its source. The other
+-- end of an edge — which scripts load a library — is read from scripts.loads
+-- through the GIN index rather than stored twice.
+
+ALTER TABLE scripts
+    ADD COLUMN IF NOT EXISTS library BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS loads   JSONB   NOT NULL DEFAULT '[]';
+
+ALTER TABLE script_versions
+    ADD COLUMN IF NOT EXISTS loads JSONB NOT NULL DEFAULT '[]';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_scripts_library_name
+    ON scripts(name) WHERE library;
+CREATE INDEX IF NOT EXISTS idx_scripts_loads
+    ON scripts USING GIN (loads jsonb_path_ops);
@@ -0,0 +1,7 @@
+-- Reverse 000132. Drop the secrets and their grants.
+--
+-- A script that asks for a secret fails at the ask from then on, which is the
+-- honest report for a credential that no longer exists.
+
+DROP TABLE IF EXISTS script_secret_grants;
+DROP TABLE IF EXISTS script_secrets;
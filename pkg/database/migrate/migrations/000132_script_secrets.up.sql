@@ -0,0 +1,33 @@
+-- 000132: named secrets an operator grants to managed scripts.
+--
+-- A script carries no credential and names no endpoint, which leaves out
+-- signing a webhook payload or presenting a partner's API key. A secret is
+-- stored here once, sealed with the platform's field key (value holds the
+-- enc: ciphertext, never plaintext), and read by a run only through an opaque
+-- handle the signing and sending bindings consume.
+--
+-- A grant is to one version of one script: script_version is the version
+-- current when it was granted, and a run of any other version is refused, so
+-- an edit to what the code does with a secret needs the operator again.
+
+CREATE TABLE IF NOT EXISTS script_secrets (
+    name        TEXT        PRIMARY KEY,
+    value       TEXT        NOT NULL,
+    description TEXT        NOT NULL DEFAULT '',
+    created_by  TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_by  TEXT        NOT NULL DEFAULT '',
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS script_secret_grants (
+    secret_name    TEXT        NOT NULL REFERENCES script_secrets(name) ON DELETE CASCADE,
+    script_id      UUID        NOT NULL REFERENCES scripts(id) ON DELETE CASCADE,
+    script_version INT         NOT NULL,
+    granted_by     TEXT        NOT NULL DEFAULT '',
+    granted_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (secret_name, script_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_script_secret_grants_script
+    ON script_secret_grants(script_id);
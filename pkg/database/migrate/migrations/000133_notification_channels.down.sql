@@ -0,0 +1,3 @@
+ALTER TABLE notifications DROP COLUMN IF EXISTS channel_id;
+ALTER TABLE user_notification_prefs DROP COLUMN IF EXISTS routes;
+DROP TABLE IF EXISTS notification_channels;
@@ -0,0 +1,31 @@
+-- Chat and webhook notification channels beside email.
+--
+-- A channel is one person's: an incoming-webhook address they registered for
+-- a Slack or Teams conversation, or a generic HTTPS endpoint that receives a
+-- signed JSON post. The address and the signing secret are sealed with the
+-- platform's field key; a Slack or Teams webhook URL is itself a credential,
+-- since whoever holds it can post into the conversation.
+CREATE TABLE IF NOT EXISTS notification_channels (
+    id             BIGSERIAL   PRIMARY KEY,
+    email          TEXT        NOT NULL,
+    name           TEXT        NOT NULL,
+    kind           TEXT        NOT NULL CHECK (kind IN ('webhook', 'slack', 'teams')),
+    url            TEXT        NOT NULL,
+    url_hint       TEXT        NOT NULL DEFAULT '',
+    signing_secret TEXT        NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (email, name)
+);
+
+-- Per-category routing: category -> the targets it is delivered to, each
+-- 'email' or the name of one of the person's channels. A category absent
+-- from the map goes to email, so an empty map is the behavior before
+-- channels existed.
+ALTER TABLE user_notification_prefs
+    ADD COLUMN IF NOT EXISTS routes JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- A queued row addressed to a channel rather than to the mailbox. NULL is
+-- email. Deleting a channel drops what was queued for it.
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS channel_id BIGINT
+        REFERENCES notification_channels(id) ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS notification_inbox;
+ALTER TABLE notifications DROP COLUMN IF EXISTS duplicate;
@@ -0,0 +1,18 @@
+-- In-app notification inbox.
+--
+-- The inbox is the notifications table read per recipient, so it needs two
+-- things the queue did not record. One event routed to several targets is
+-- queued once per target; every row but the one the inbox shows is marked
+-- duplicate so the event is listed once. And each listed row carries the
+-- reader's state: no row here is unread, otherwise read or archived. The
+-- owner is the notification's recipient, so the state is reached through
+-- idx_notifications_recipient and needs no index of its own.
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS duplicate BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS notification_inbox (
+    notification_id BIGINT      PRIMARY KEY
+        REFERENCES notifications(id) ON DELETE CASCADE,
+    state           TEXT        NOT NULL CHECK (state IN ('read', 'archived')),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
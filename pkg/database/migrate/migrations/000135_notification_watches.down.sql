@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS notification_watch_schemas;
+DROP TABLE IF EXISTS notification_watch_sweep;
+DROP TABLE IF EXISTS notification_watches;
@@ -0,0 +1,40 @@
+-- Watches: one person's subscription to news about a portal item or a DataHub
+-- dataset.
+--
+-- There is no event log to subscribe to, so a periodic sweep reads the rows
+-- that already record each change (version history, threads, applied
+-- knowledge changesets) over the window since the last sweep, and notifies
+-- the watchers of what changed. The sweep is driven from the watched targets,
+-- so the (target_type, target_id) index is what keeps it cheap.
+CREATE TABLE IF NOT EXISTS notification_watches (
+    email       TEXT        NOT NULL,
+    target_type TEXT        NOT NULL
+        CHECK (target_type IN ('asset', 'collection', 'prompt', 'knowledge_page', 'dataset')),
+    target_id   TEXT        NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (email, target_type, target_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_watches_target
+    ON notification_watches (target_type, target_id);
+
+-- The sweep's cursor: everything up to swept_until has been reported. One
+-- row, advanced with a compare-and-swap so one replica wins each window.
+-- Seeded at the migration so the first sweep reports news, not history.
+CREATE TABLE IF NOT EXISTS notification_watch_sweep (
+    id          BOOLEAN     PRIMARY KEY DEFAULT TRUE CHECK (id),
+    swept_until TIMESTAMPTZ NOT NULL
+);
+
+INSERT INTO notification_watch_sweep (id, swept_until)
+VALUES (TRUE, NOW())
+ON CONFLICT (id) DO NOTHING;
+
+-- The column list a watched dataset last had, as the semantic provider
+-- reported it. A schema change is a difference from this baseline; the first
+-- read of a dataset only records it.
+CREATE TABLE IF NOT EXISTS notification_watch_schemas (
+    urn        TEXT        PRIMARY KEY,
+    columns    TEXT[]      NOT NULL,
+    checked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS portal_share_access_log;
+DROP TABLE IF EXISTS portal_share_verify_codes;
+DROP TABLE IF EXISTS portal_share_guards;
@@ -0,0 +1,49 @@
+-- Guards on public share links: a passphrase, a view limit, and an email
+-- verification step, each optional. A public link is a bearer credential;
+-- these narrow who holding the URL can actually open it.
+--
+-- The guard is kept beside the share rather than on it: only the public
+-- viewer's gate reads it, and a share with no row is unguarded. Only a
+-- bcrypt hash of the passphrase is stored. view_count counts visits that
+-- passed the guard, and the conditional increment that admits one is what
+-- enforces max_views (0 means no limit).
+CREATE TABLE IF NOT EXISTS portal_share_guards (
+    share_id        TEXT        PRIMARY KEY REFERENCES portal_shares(id) ON DELETE CASCADE,
+    passphrase_hash TEXT        NOT NULL DEFAULT '',
+    max_views       INTEGER     NOT NULL DEFAULT 0 CHECK (max_views >= 0),
+    view_count      INTEGER     NOT NULL DEFAULT 0,
+    verify_email    BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One-time codes for the email verification step. A code is bound to the
+-- share and the address it was sent to, stored as a keyed hash, single use,
+-- and dead after a few wrong guesses.
+CREATE TABLE IF NOT EXISTS portal_share_verify_codes (
+    id         TEXT        PRIMARY KEY,
+    share_id   TEXT        NOT NULL REFERENCES portal_shares(id) ON DELETE CASCADE,
+    email      TEXT        NOT NULL,
+    code_hash  TEXT        NOT NULL,
+    attempts   INTEGER     NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ
+);
+
+-- Backs the newest-code lookup and the per-address issue cap.
+CREATE INDEX IF NOT EXISTS idx_portal_share_verify_codes_share_email
+    ON portal_share_verify_codes (share_id, email, created_at);
+
+-- Per-share access log the share's owner reads: each page view of the share
+-- and each step of a guarded link's unlock, with the address the visit is
+-- attributed to when one is known.
+CREATE TABLE IF NOT EXISTS portal_share_access_log (
+    id         BIGSERIAL   PRIMARY KEY,
+    share_id   TEXT        NOT NULL REFERENCES portal_shares(id) ON DELETE CASCADE,
+    event      TEXT        NOT NULL,
+    email      TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_portal_share_access_log_share_created
+    ON portal_share_access_log (share_id, created_at DESC);
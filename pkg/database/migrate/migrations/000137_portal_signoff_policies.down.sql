@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS portal_signoff_decisions;
+DROP FUNCTION IF EXISTS portal_signoff_decisions_immutable();
+DROP TABLE IF EXISTS portal_signoff_policies;
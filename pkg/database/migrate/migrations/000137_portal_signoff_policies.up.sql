@@ -0,0 +1,53 @@
+-- Approval policies on collections and the record of the decisions made under
+-- them. A policy names the people and personas who must sign off on the
+-- collection's assets, in one or more steps taken in order or all at once;
+-- an asset is approved for a version once every step has its required count.
+--
+-- A policy's revision increases on every change, starting past any revision
+-- already named in the record, so a policy removed and written again never
+-- revives decisions made under the old one.
+CREATE TABLE IF NOT EXISTS portal_signoff_policies (
+    collection_id TEXT        PRIMARY KEY REFERENCES portal_collections(id) ON DELETE CASCADE,
+    mode          TEXT        NOT NULL CHECK (mode IN ('sequential', 'parallel')),
+    steps         JSONB       NOT NULL,
+    revision      INTEGER     NOT NULL,
+    updated_by    TEXT        NOT NULL DEFAULT '',
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The decision record. Each row is one person's approval or rejection of
+-- one asset version under one policy revision. It has no foreign keys so it
+-- outlives the asset, the collection, and the policy it names, and it is
+-- append-only: the trigger below refuses UPDATE and DELETE, so the record
+-- an auditor exports is the record as it was written.
+CREATE TABLE IF NOT EXISTS portal_signoff_decisions (
+    id               TEXT        PRIMARY KEY,
+    asset_id         TEXT        NOT NULL,
+    asset_version    INTEGER     NOT NULL,
+    collection_id    TEXT        NOT NULL,
+    policy_revision  INTEGER     NOT NULL,
+    step             INTEGER     NOT NULL,
+    step_name        TEXT        NOT NULL DEFAULT '',
+    decision         TEXT        NOT NULL CHECK (decision IN ('approved', 'rejected')),
+    reason           TEXT        NOT NULL DEFAULT '',
+    approver_id      TEXT        NOT NULL,
+    approver_email   TEXT        NOT NULL DEFAULT '',
+    approver_persona TEXT        NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- One decision per person per version under a policy revision.
+    UNIQUE (asset_id, collection_id, policy_revision, asset_version, approver_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_portal_signoff_decisions_asset
+    ON portal_signoff_decisions (asset_id, created_at);
+
+CREATE OR REPLACE FUNCTION portal_signoff_decisions_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'portal_signoff_decisions is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS portal_signoff_decisions_append_only ON portal_signoff_decisions;
+CREATE TRIGGER portal_signoff_decisions_append_only
+    BEFORE UPDATE OR DELETE ON portal_signoff_decisions
+    FOR EACH ROW EXECUTE FUNCTION portal_signoff_decisions_immutable();
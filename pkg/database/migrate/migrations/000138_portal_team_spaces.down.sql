@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS portal_space_collections;
+DROP TABLE IF EXISTS portal_space_group_members;
+DROP TABLE IF EXISTS portal_space_members;
+DROP TABLE IF EXISTS portal_space_groups;
+DROP TABLE IF EXISTS portal_spaces;
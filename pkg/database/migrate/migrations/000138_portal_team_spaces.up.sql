@@ -0,0 +1,61 @@
+-- Team spaces: named groups that own collections. A member of a space holds
+-- a role on every collection the space owns and on every asset inside those
+-- collections. Roles are resolved from these tables when a permission is
+-- checked rather than copied onto shares, so a role changed here takes effect
+-- on the next request.
+CREATE TABLE IF NOT EXISTS portal_spaces (
+    id          TEXT        PRIMARY KEY,
+    name        TEXT        NOT NULL,
+    description TEXT        NOT NULL DEFAULT '',
+    created_by  TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_portal_spaces_name ON portal_spaces (LOWER(name));
+
+-- Group bindings. Everyone whose token carries the group claim value is a
+-- member with the bound role.
+CREATE TABLE IF NOT EXISTS portal_space_groups (
+    space_id   TEXT NOT NULL REFERENCES portal_spaces(id) ON DELETE CASCADE,
+    group_name TEXT NOT NULL,
+    role       TEXT NOT NULL CHECK (role IN ('viewer', 'editor', 'manager')),
+    PRIMARY KEY (space_id, group_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_portal_space_groups_group ON portal_space_groups (group_name);
+
+-- Managed members, named by address.
+CREATE TABLE IF NOT EXISTS portal_space_members (
+    space_id   TEXT        NOT NULL REFERENCES portal_spaces(id) ON DELETE CASCADE,
+    email      TEXT        NOT NULL,
+    role       TEXT        NOT NULL CHECK (role IN ('viewer', 'editor', 'manager')),
+    added_by   TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (space_id, email)
+);
+
+CREATE INDEX IF NOT EXISTS idx_portal_space_members_email ON portal_space_members (email);
+
+-- The bound groups each address was last seen holding. Group claims arrive on
+-- a token, not in a table, so this is what lets the members a group brings in
+-- be listed (the mention picker, activity notifications). It never grants
+-- anything: access checks read the claims on the request itself.
+CREATE TABLE IF NOT EXISTS portal_space_group_members (
+    email      TEXT        NOT NULL,
+    group_name TEXT        NOT NULL,
+    seen_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (email, group_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_portal_space_group_members_group ON portal_space_group_members (group_name);
+
+-- The collections a space owns. A collection belongs to at most one space.
+CREATE TABLE IF NOT EXISTS portal_space_collections (
+    collection_id TEXT        PRIMARY KEY REFERENCES portal_collections(id) ON DELETE CASCADE,
+    space_id      TEXT        NOT NULL REFERENCES portal_spaces(id) ON DELETE CASCADE,
+    added_by      TEXT        NOT NULL DEFAULT '',
+    added_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_portal_space_collections_space ON portal_space_collections (space_id);
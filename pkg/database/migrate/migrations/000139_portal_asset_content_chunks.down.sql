@@ -0,0 +1,6 @@
+ALTER TABLE portal_assets DROP COLUMN IF EXISTS content_index_model;
+ALTER TABLE portal_assets DROP COLUMN IF EXISTS content_index_version;
+
+DROP INDEX IF EXISTS idx_portal_asset_content_chunks_fts;
+DROP INDEX IF EXISTS idx_portal_asset_content_chunks_embedding_hnsw;
+DROP TABLE IF EXISTS portal_asset_content_chunks;
@@ -0,0 +1,49 @@
+-- 000139: passage index over portal asset content
+--
+-- Asset search ranks names, descriptions and tags (000063); the body of an
+-- asset lives in S3 and was never indexed, so a phrase from inside a report
+-- found nothing. The asset-content index consumer now reads the body of each
+-- HTML, markdown, plain-text and delimited asset, splits it into passages (one
+-- per heading section, or per block of rows for CSV/TSV), and writes one row
+-- per passage chunk here. Search ranks these rows and returns the best passage
+-- per asset with the fragment that lands on it.
+--
+-- Unlike the knowledge-page chunk table (000097) the passage text is stored:
+-- the body is not in Postgres, and search has to return the matched passage
+-- and match it lexically. The consumer writes a passage before it is embedded,
+-- so embedding and its text_hash are nullable; a passage whose text changes
+-- has its stale vector cleared in the same write.
+--
+-- content_index_version and content_index_model on portal_assets are the
+-- set-level marker: the version whose content the passages were read from, and
+-- the model that embedded them (NULL while that pass is owed). An asset is a
+-- gap when either differs from its current version or the provider's model, so
+-- a new version re-indexes without any trigger on the version table.
+--
+-- pgvector is enabled by migration 000031; re-enable defensively so this
+-- migration is self-contained.
+
+CREATE EXTENSION IF NOT EXISTS vector;
+
+CREATE TABLE IF NOT EXISTS portal_asset_content_chunks (
+    asset_id    TEXT        NOT NULL REFERENCES portal_assets(id) ON DELETE CASCADE,
+    chunk_index INTEGER     NOT NULL,
+    section     TEXT        NOT NULL DEFAULT '',
+    fragment    TEXT        NOT NULL DEFAULT '',
+    passage     TEXT        NOT NULL,
+    text_hash   BYTEA,
+    embedding   vector(768),
+    model       TEXT        NOT NULL DEFAULT '',
+    dim         INTEGER     NOT NULL DEFAULT 0,
+    indexed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (asset_id, chunk_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_portal_asset_content_chunks_embedding_hnsw
+    ON portal_asset_content_chunks USING hnsw (embedding vector_cosine_ops);
+
+CREATE INDEX IF NOT EXISTS idx_portal_asset_content_chunks_fts
+    ON portal_asset_content_chunks USING GIN (to_tsvector('english', passage));
+
+ALTER TABLE portal_assets ADD COLUMN IF NOT EXISTS content_index_version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE portal_assets ADD COLUMN IF NOT EXISTS content_index_model TEXT;
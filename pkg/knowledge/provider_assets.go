@@ -65,6 +65,7 @@ func (p *AssetsProvider) Search(ctx context.Context, q Query) ([]Hit, error) {
 		QueryText: q.Intent,
 		OwnerID:   q.Caller.UserID,
 		Limit:     q.Limit,
+		Passages:  true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("asset search: %w", err)
@@ -75,7 +76,7 @@ func (p *AssetsProvider) Search(ctx context.Context, q Query) ([]Hit, error) {
 	for i := range scored {
 		byID[scored[i].Asset.ID] = scored[i].Asset
 		hits = append(hits, Hit{
-			Text:      assetHitText(scored[i].Asset),
+			Text:      assetHitText(scored[i]),
 			Source:    SourceAssets,
 			Ref:       scored[i].Asset.ID,
 			Score:     scored[i].Score,
@@ -156,10 +157,18 @@ func assetOutboundRefs(a portal.Asset) []DocumentRef {
 
 // assetHitText renders an asset as a knowledge snippet: its name, and its
 // description when present, so a hit conveys what the asset is without a
-// follow-up fetch.
-func assetHitText(a portal.Asset) string {
-	if a.Description == "" {
-		return a.Name
+// follow-up fetch. A hit found in the asset's content adds the passage's
+// section and excerpt, so the snippet shows what matched.
+func assetHitText(sa portal.ScoredAsset) string {
+	text := sa.Asset.Name
+	if sa.Asset.Description != "" {
+		text = strings.TrimSpace(text + "\n" + sa.Asset.Description)
 	}
-	return strings.TrimSpace(a.Name + "\n" + a.Description)
+	if p := sa.Passage; p != nil {
+		if p.Section != "" {
+			text += "\n" + p.Section + ":"
+		}
+		text += "\n" + p.Excerpt
+	}
+	return text
 }
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/txn2/mcp-data-platform/internal/portal/portaldomain"
 	"github.com/txn2/mcp-data-platform/pkg/portal"
 	"github.com/txn2/mcp-data-platform/pkg/portal/knowledgepage"
 )
@@ -95,6 +96,27 @@ func TestAssetsProvider_ScopesAndMaps(t *testing.T) {
 	}
 }
 
+func TestAssetsProvider_PassageHitText(t *testing.T) {
+	s := &fakeAssetSearcher{
+		scored: []portal.ScoredAsset{{
+			Asset: portal.Asset{ID: "a1", Name: "Churn report"},
+			Passage: &portaldomain.AssetPassage{
+				Section: "Report > Findings", Fragment: "findings", Excerpt: "Churn fell 3% in Q3.",
+			},
+		}},
+	}
+	hits, err := NewAssetsProvider(s).Search(context.Background(), Query{Intent: "churn", Caller: Caller{UserID: "uuid-1"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !s.got.Passages {
+		t.Error("asset search must rank content passages")
+	}
+	if want := "Churn report\nReport > Findings:\nChurn fell 3% in Q3."; len(hits) != 1 || hits[0].Text != want {
+		t.Errorf("hits = %+v, want one hit with text %q", hits, want)
+	}
+}
+
 func TestAssetsProvider_SearchError(t *testing.T) {
 	s := &fakeAssetSearcher{err: errors.New("boom")}
 	p := NewAssetsProvider(s)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
 	"strings"
 	"time"
 
@@ -171,13 +172,14 @@ func MCPAuditMiddleware(logger AuditLogger, opts ...AuditOption) mcp.Middleware
 			// writer's Close, not the request context. Depending on delivery
 			// mode Log either enqueues (async) or writes inline within its
 			// per-write timeout (sync); neither fails the tool call.
-			if err := logger.Log(context.Background(), event); err != nil {
-				slog.Error("failed to log audit event",
-					"error", err,
-					"tool", event.ToolName,
-					"user_id", event.UserID,
-					"request_id", event.RequestID,
-				)
+			logAuditEvent(logger, event)
+
+			// Upstream requests the tool made beyond the one this row
+			// describes (the followed pages of an auto-paginated API
+			// call) are each audited as a row of their own.
+			callResult, _ := result.(*mcp.CallToolResult)
+			for _, page := range readAuditPagesMeta(callResult) {
+				logAuditEvent(logger, buildPageAuditEvent(event, page, policy))
 			}
 
 			return result, err
@@ -185,6 +187,54 @@ func MCPAuditMiddleware(logger AuditLogger, opts ...AuditOption) mcp.Middleware
 	}
 }
 
+// logAuditEvent hands one event to the logger, logging (never returning)
+// a failure so the tool call is unaffected.
+func logAuditEvent(logger AuditLogger, event AuditEvent) {
+	if err := logger.Log(context.Background(), event); err != nil {
+		slog.Error("failed to log audit event",
+			"error", err,
+			"tool", event.ToolName,
+			"user_id", event.UserID,
+			"request_id", event.RequestID,
+		)
+	}
+}
+
+// buildPageAuditEvent derives the row for one additional upstream
+// request from its call's row. Identity, session, tool, and connection
+// are the call's; timing, parameters, and outcome are the page's. The
+// ID is cleared so the store mints one, since the call's ID belongs to
+// the call's own row. Response and request sizes stay zero: the page's
+// content reached the client inside the call's response.
+func buildPageAuditEvent(call AuditEvent, page observability.AuditPage, policy auditParamPolicy) AuditEvent {
+	ev := call
+	ev.ID = ""
+	ev.Timestamp = page.StartedAt
+	ev.DurationMS = page.DurationMS
+	ev.Parameters = applyParamPolicy(maps.Clone(page.Parameters), policy)
+	ev.Success = page.Outcome == "" || page.Outcome == observability.OutcomeOK
+	ev.ErrorCategory = ""
+	ev.ErrorMessage = ""
+	if !ev.Success {
+		ev.ErrorCategory = page.Outcome
+		ev.ErrorMessage = page.Message
+	}
+	ev.ResponseChars = 0
+	ev.RequestChars = 0
+	ev.ContentBlocks = 0
+	return ev
+}
+
+// readAuditPagesMeta returns the additional upstream requests a result
+// carries under observability.MetaAuditPages, or nil.
+func readAuditPagesMeta(result *mcp.CallToolResult) []observability.AuditPage {
+	if result == nil || result.Meta == nil {
+		return nil
+	}
+	pages, _ := result.Meta[observability.MetaAuditPages].([]observability.AuditPage)
+	return pages
+}
+
 // auditCallInfo groups the call-related parameters for building an audit event.
 type auditCallInfo struct {
 	Request   mcp.Request
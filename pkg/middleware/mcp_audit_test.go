@@ -967,3 +967,54 @@ func TestMCPAuditMiddleware_KeepsAValueWithinTheBound(t *testing.T) {
 	event := auditMWWithParams(t, map[string]any{"sql": sql})
 	assert.Equal(t, sql, event.Parameters["sql"])
 }
+
+func TestMCPAuditMiddleware_LogsAuditPages(t *testing.T) {
+	mockLogger := newCapturingAuditLogger()
+	mw := MCPAuditMiddleware(mockLogger, WithRedactKeys([]string{"query_params"}))
+
+	pageStart := time.Now().Add(-time.Second)
+	wrapped := mw(func(_ context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "[]"}},
+			Meta: mcp.Meta{
+				observability.MetaAuditOutcome: observability.OutcomeOK,
+				observability.MetaAuditPages: []observability.AuditPage{
+					{StartedAt: pageStart, DurationMS: 7, Outcome: observability.OutcomeOK,
+						Parameters: map[string]any{"path": "/items", "query_params": map[string]any{"cursor": "c2"}, "follow_page": 2}},
+					{StartedAt: pageStart, DurationMS: 9, Outcome: observability.OutcomeUpstream5xx, Message: "Bad Gateway",
+						Parameters: map[string]any{"path": "/items", "follow_page": 3}},
+				},
+			},
+		}, nil
+	})
+
+	pc := NewPlatformContext("req-pages")
+	pc.EventID = "evt-call"
+	pc.UserID = testAuditEmail
+	pc.ToolName = "api_invoke_endpoint"
+	pc.ToolkitKind = "api"
+	pc.Connection = "vendor"
+	ctx := WithPlatformContext(context.Background(), pc)
+
+	_, err := wrapped(ctx, testAuditMethodCall, createAuditTestRequest(t, "api_invoke_endpoint", map[string]any{"path": "/items"}))
+	require.NoError(t, err)
+
+	events := mockLogger.Events()
+	require.Len(t, events, 3, "the call plus one row per followed page")
+	assert.Equal(t, "evt-call", events[0].ID)
+
+	page := events[1]
+	assert.Empty(t, page.ID, "a page row must not reuse the call's id")
+	assert.Equal(t, "req-pages", page.RequestID)
+	assert.Equal(t, "vendor", page.Connection)
+	assert.Equal(t, pageStart, page.Timestamp)
+	assert.Equal(t, int64(7), page.DurationMS)
+	assert.True(t, page.Success)
+	assert.Equal(t, redactedPlaceholder, page.Parameters["query_params"], "page parameters follow the capture policy")
+	assert.Equal(t, 2, page.Parameters["follow_page"])
+
+	failed := events[2]
+	assert.False(t, failed.Success)
+	assert.Equal(t, observability.OutcomeUpstream5xx, failed.ErrorCategory)
+	assert.Equal(t, "Bad Gateway", failed.ErrorMessage)
+}
@@ -0,0 +1,130 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// Channel kinds. Each is a way of posting into a conversation from outside it,
+// configured by the person it notifies and delivered by the same queue, lease,
+// and retry budget as email.
+const (
+	// ChannelWebhook posts a JSON body signed with the channel's own secret
+	// (HMAC-SHA256 over the timestamp and the body), for a receiver of the
+	// recipient's choosing: an on-call tool, an automation platform, a bot.
+	ChannelWebhook = "webhook"
+	// ChannelSlack posts to a Slack-compatible incoming webhook. One created
+	// for the person's own direct-message conversation with an app is how a
+	// category reaches them as a DM.
+	ChannelSlack = "slack"
+	// ChannelTeams posts an Adaptive Card to a Microsoft Teams incoming
+	// webhook or workflow.
+	ChannelTeams = "teams"
+)
+
+// RouteEmail is the routing target that means the recipient's mailbox. Every
+// other target names one of the recipient's channels.
+const RouteEmail = "email"
+
+var (
+	// ErrChannelNotFound reports a channel that does not exist, or is not
+	// the caller's.
+	ErrChannelNotFound = errors.New("notification: channel not found")
+	// ErrChannelNameTaken reports a create naming a channel the person
+	// already has.
+	ErrChannelNameTaken = errors.New("notification: a channel by that name already exists")
+	// ErrChannelLimit reports a create past the per-person channel cap.
+	ErrChannelLimit = errors.New("notification: too many channels")
+)
+
+// channelNamePattern is what a channel is called: the slug a route names it
+// by.
+var channelNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// ValidChannelKind reports whether k is one of the channel kinds.
+func ValidChannelKind(k string) bool {
+	return k == ChannelWebhook || k == ChannelSlack || k == ChannelTeams
+}
+
+// ValidateChannelName checks a channel name. "email" is refused because a
+// route could not tell the channel from the mailbox.
+func ValidateChannelName(name string) error {
+	if name == RouteEmail || !channelNamePattern.MatchString(name) {
+		return fmt.Errorf("channel name %q must be lowercase letters, digits, '-' or '_', at most 63 characters, and not %q",
+			name, RouteEmail)
+	}
+	return nil
+}
+
+// ValidateChannelURL checks a channel's webhook address and returns the host
+// it points at, the hint a listing shows in its place. Only https is
+// accepted: the address is a credential, and so is what is posted to it.
+func ValidateChannelURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return "", errors.New("channel url must be an absolute https:// address with no credentials in it")
+	}
+	return u.Hostname(), nil
+}
+
+// Channel is one person's registered chat or webhook destination.
+type Channel struct {
+	ID    int64  `json:"id"`
+	Email string `json:"-"`
+	Name  string `json:"name" example:"team-alerts"`
+	Kind  string `json:"kind" example:"slack"`
+	// URL is the webhook address. It is a credential -- whoever holds a Slack
+	// or Teams webhook URL can post into the conversation -- so it is sealed
+	// at rest and never returned; URLHint names the host it points at.
+	URL     string `json:"-"`
+	URLHint string `json:"url_hint" example:"hooks.slack.com"`
+	// SigningSecret signs a ChannelWebhook post. It is generated by the store
+	// and returned once, in the response that creates the channel.
+	SigningSecret string    `json:"signing_secret,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ChannelResolver resolves the channel names a route holds. It is the part of
+// ChannelStore the enqueue path needs, and needs no key to open a channel.
+type ChannelResolver interface {
+	// Lookup resolves one of the person's channel names to its ID, or reports
+	// ErrChannelNotFound.
+	Lookup(ctx context.Context, email, name string) (int64, error)
+}
+
+// ChannelStore persists each person's channels. internal/notification/
+// notifyprefs holds the PostgreSQL implementation.
+type ChannelStore interface {
+	ChannelResolver
+	// List returns the person's channels by name, without URLs or secrets.
+	List(ctx context.Context, email string) ([]Channel, error)
+	// Create registers a channel, generating a webhook's signing secret, and
+	// returns it with that secret.
+	Create(ctx context.Context, c Channel) (*Channel, error)
+	// Delete removes one of the person's channels, and whatever is queued for
+	// it, or reports ErrChannelNotFound.
+	Delete(ctx context.Context, email, name string) error
+	// Get returns a channel with its URL and secret opened, for delivery.
+	Get(ctx context.Context, id int64) (*Channel, error)
+}
+
+// Categories is every notification category, in the order a settings page
+// lists them.
+var Categories = []string{
+	CategoryShare, CategoryComment, CategoryMention,
+	CategoryReviewQueue, CategoryScriptRun, CategoryApproval, CategoryWatch,
+}
+
+// ValidCategory reports whether c is a notification category.
+func ValidCategory(c string) bool {
+	for _, known := range Categories {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
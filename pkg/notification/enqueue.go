@@ -2,8 +2,10 @@ package notification
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/txn2/mcp-data-platform/internal/logsan"
@@ -37,6 +39,9 @@ type Enqueuer struct {
 	// an unbounded outbound-mail primitive. Per-process; multi-replica
 	// deployments multiply the cap by replica count, which still bounds it.
 	limiter *ratelimit.Limiter
+	// channels resolves the channel names a route holds. Nil delivers every
+	// category to email, whatever its routes say.
+	channels ChannelResolver
 }
 
 // NewEnqueuer creates an Enqueuer. digestHourUTC is the hour of day (0-23,
@@ -52,6 +57,12 @@ func NewEnqueuer(prefs PrefsStore, queue QueueStore, digestHourUTC int) *Enqueue
 	}
 }
 
+// SetChannels installs the store a category routed to a channel is resolved
+// against. Call it before the first Notify.
+func (e *Enqueuer) SetChannels(channels ChannelResolver) {
+	e.channels = channels
+}
+
 // Close stops the limiter's background eviction goroutine. Nil-safe.
 func (e *Enqueuer) Close() {
 	if e == nil || e.limiter == nil {
@@ -157,16 +168,61 @@ func (e *Enqueuer) enqueue(ctx context.Context, recipient, category string, p Pa
 		return false, nil
 	}
 
-	n := Notification{Recipient: recipient, Category: category, Payload: p}
-	if prefs.Mode == ModeDaily {
-		n.Digest = true
-		n.ScheduledFor = NextDigestTime(e.now().UTC(), e.digestHourUTC)
+	queued := false
+	targets := e.targets(ctx, recipient, prefs, category)
+	// The inbox lists the event by the email row when there is one: a
+	// channel row goes with its channel when that is deleted.
+	listed := max(slices.Index(targets, 0), 0)
+	for i, channelID := range targets {
+		n := Notification{
+			Recipient: recipient, Category: category, Payload: p,
+			ChannelID: channelID, Duplicate: i != listed,
+		}
+		if channelID == 0 && prefs.Mode == ModeDaily {
+			n.Digest = true
+			n.ScheduledFor = NextDigestTime(e.now().UTC(), e.digestHourUTC)
+		}
+		if err := e.queue.Enqueue(ctx, n); err != nil {
+			return queued, fmt.Errorf("enqueueing %s notification for %s: %w",
+				category, logsan.SanitizeForLog(recipient), err)
+		}
+		queued = true
 	}
-	if err := e.queue.Enqueue(ctx, n); err != nil {
-		return false, fmt.Errorf("enqueueing %s notification for %s: %w",
-			category, logsan.SanitizeForLog(recipient), err)
+	return queued, nil
+}
+
+// targets resolves a category's routes to the rows it is queued as: 0 for the
+// mailbox and a channel's ID for each channel. A route naming a channel that no
+// longer exists is skipped, and a category left with no target goes to email,
+// so deleting a channel never silences what was routed to it.
+func (e *Enqueuer) targets(ctx context.Context, recipient string, prefs Prefs, category string) []int64 {
+	var out []int64
+	seen := map[int64]bool{}
+	for _, route := range prefs.RoutesFor(category) {
+		var id int64
+		if route != RouteEmail {
+			if e.channels == nil {
+				continue
+			}
+			resolved, err := e.channels.Lookup(ctx, recipient, route)
+			if err != nil {
+				if !errors.Is(err, ErrChannelNotFound) {
+					slog.Warn("notification: resolving a channel route failed", // #nosec G706 -- structured slog call; error sanitized
+						"category", category, "error", logsan.SanitizeForLog(err.Error()))
+				}
+				continue
+			}
+			id = resolved
+		}
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
 	}
-	return true, nil
+	if len(out) == 0 {
+		return []int64{0}
+	}
+	return out
 }
 
 // firstOf returns the first entry of a non-empty slice, used as the rate-limit
@@ -208,11 +264,16 @@ func wantsCategory(prefs Prefs, category string) bool {
 		return prefs.CommentsEnabled
 	case CategoryMention:
 		return prefs.MentionsEnabled
-	case CategoryReviewQueue, CategoryScriptRun:
-		// Addressed by responsibility rather than by interest, so neither has a
+	case CategoryReviewQueue, CategoryScriptRun, CategoryApproval:
+		// Addressed by responsibility rather than by interest, so none has a
 		// per-user category toggle: the recipients are named by the admin
-		// settings (the review queue) or by owning the automation (script
-		// run), and Mode (checked above) is the recipient's own opt-out.
+		// settings (the review queue), by owning the automation (script
+		// run), or by the approval rule, and Mode (checked above) is the
+		// recipient's own opt-out.
+		return true
+	case CategoryWatch:
+		// The recipient asked for this target by watching it, which is a
+		// finer opt-in than a category toggle.
 		return true
 	default:
 		return false
@@ -56,7 +56,7 @@ func (f *fakeQueueStore) Enqueue(_ context.Context, n Notification) error {
 	return nil
 }
 
-func (f *fakeQueueStore) ClaimImmediate(_ context.Context, _ time.Duration) (*Notification, error) {
+func (f *fakeQueueStore) ClaimImmediate(_ context.Context, _ time.Duration, _ bool) (*Notification, error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	if f.claimErr != nil {
@@ -464,3 +464,103 @@ func TestEnqueuer_Notify_ScriptRunHasNoPerCategoryToggle(t *testing.T) {
 		t.Fatalf("the run reference did not survive the enqueue: %+v", rows)
 	}
 }
+
+// TestEnqueuer_Notify_ApprovalHasNoPerCategoryToggle pins the approval category
+// as gated by Mode alone: an approver named by a rule must hear of a request
+// waiting on them even with shares, comments, and mentions muted.
+func TestEnqueuer_Notify_ApprovalHasNoPerCategoryToggle(t *testing.T) {
+	queue := &fakeQueueStore{}
+	e := NewEnqueuer(&fakePrefsStore{prefs: map[string]Prefs{
+		"lead@b.io": {Mode: ModeImmediate},
+		"off@b.io":  {Mode: ModeOff},
+	}}, queue, 13)
+	defer e.Close()
+
+	p := Payload{Kind: KindApprovalRequest, ItemID: "apr_1", ItemTitle: "POST /v1/orders on crm", Actor: "agent@b.io"}
+	if queued, err := e.Notify(context.Background(), "lead@b.io", CategoryApproval, p); err != nil || !queued {
+		t.Fatalf("approver not notified: queued=%v err=%v", queued, err)
+	}
+	if queued, _ := e.Notify(context.Background(), "off@b.io", CategoryApproval, p); queued {
+		t.Fatal("ModeOff must still silence approval requests")
+	}
+}
+
+// TestEnqueuer_NotifyFanout_WatchFollowsMode pins the watch category as gated
+// by Mode alone, digest included: a watcher with every category toggle off
+// still hears about what they watch, a daily watcher gets it in the digest,
+// and ModeOff silences it.
+func TestEnqueuer_NotifyFanout_WatchFollowsMode(t *testing.T) {
+	queue := &fakeQueueStore{}
+	e := NewEnqueuer(&fakePrefsStore{prefs: map[string]Prefs{
+		"now@b.io":   {Mode: ModeImmediate},
+		"daily@b.io": {Mode: ModeDaily},
+		"off@b.io":   {Mode: ModeOff},
+	}}, queue, 13)
+	defer e.Close()
+
+	p := Payload{Kind: KindWatchVersion, ItemID: "ast_1", ItemTitle: "Revenue", Actor: "editor@b.io"}
+	sent := e.NotifyFanout(context.Background(), []string{"now@b.io", "daily@b.io", "off@b.io"}, CategoryWatch, p)
+	if len(sent) != 2 {
+		t.Fatalf("sent = %v, want the immediate and daily watchers", sent)
+	}
+	for _, row := range queue.enqueuedCopy() {
+		if row.Digest != (row.Recipient == "daily@b.io") {
+			t.Errorf("%s digest = %v", row.Recipient, row.Digest)
+		}
+	}
+}
+
+// fakeChannels resolves a fixed set of one person's channel names.
+type fakeChannels struct {
+	ids map[string]int64
+}
+
+func (f *fakeChannels) Lookup(_ context.Context, _, name string) (int64, error) {
+	if id, ok := f.ids[name]; ok {
+		return id, nil
+	}
+	return 0, ErrChannelNotFound
+}
+
+// TestEnqueuer_Notify_RoutesACategoryToItsChannels queues one row per target:
+// the channel row is immediate even for a daily-digest reader, a route to a
+// deleted channel falls back to the mailbox rather than going nowhere, and
+// every row but one per event -- the email row where there is one -- is marked
+// duplicate so the inbox lists the event once.
+func TestEnqueuer_Notify_RoutesACategoryToItsChannels(t *testing.T) {
+	queue := &fakeQueueStore{}
+	prefs := DefaultPrefs("a@b.io")
+	prefs.Mode = ModeDaily
+	prefs.Routes = map[string][]string{
+		CategoryScriptRun: {"team-alerts", RouteEmail},
+		CategoryMention:   {"dm"},
+		CategoryComment:   {"deleted"},
+	}
+	e := NewEnqueuer(&fakePrefsStore{prefs: map[string]Prefs{"a@b.io": prefs}}, queue, 13)
+	e.SetChannels(&fakeChannels{ids: map[string]int64{"team-alerts": 7, "dm": 9}})
+
+	for _, category := range []string{CategoryScriptRun, CategoryMention, CategoryComment} {
+		if _, err := e.Notify(context.Background(), "a@b.io", category, Payload{Actor: "x@y.z"}); err != nil {
+			t.Fatalf("Notify %s: %v", category, err)
+		}
+	}
+	got := queue.enqueuedCopy()
+	if len(got) != 4 {
+		t.Fatalf("expected 4 rows, got %+v", got)
+	}
+	type row struct {
+		category  string
+		channelID int64
+		digest    bool
+		duplicate bool
+	}
+	want := []row{
+		{CategoryScriptRun, 7, false, true}, {CategoryScriptRun, 0, true, false},
+		{CategoryMention, 9, false, false}, {CategoryComment, 0, true, false},
+	}
+	for i, n := range got {
+		if r := (row{n.Category, n.ChannelID, n.Digest, n.Duplicate}); r != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, r, want[i])
+		}
+	}
+}
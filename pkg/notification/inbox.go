@@ -0,0 +1,77 @@
+package notification
+
+import "context"
+
+// Inbox states. A notification the reader has not touched is unread; marking
+// it read or archived records the state, and marking it unread again clears
+// it.
+const (
+	// InboxUnread is a notification the reader has not opened.
+	InboxUnread = "unread"
+	// InboxRead is a notification the reader has opened.
+	InboxRead = "read"
+	// InboxArchived is a notification the reader put out of the default
+	// view. It still counts as read.
+	InboxArchived = "archived"
+)
+
+// ValidInboxState reports whether s is one of the Inbox* constants.
+func ValidInboxState(s string) bool {
+	switch s {
+	case InboxUnread, InboxRead, InboxArchived:
+		return true
+	}
+	return false
+}
+
+// InboxFilter narrows one person's inbox listing.
+//
+// Recipient is the whole authorization of the listing, as it is for
+// HistoryFilter, and is matched against the stored NormalizeAddress form.
+type InboxFilter struct {
+	// Recipient is the inbox owner. It is required.
+	Recipient string
+	// State is one of the Inbox* constants. Empty means everything not
+	// archived, which is the inbox as the portal opens it.
+	State string
+	// Limit bounds the page with HistoryFilter's defaults and cap.
+	Limit int
+	// Offset is the page start.
+	Offset int
+}
+
+// EffectiveLimit resolves the page size the store will apply.
+func (f InboxFilter) EffectiveLimit() int {
+	return HistoryFilter{Limit: f.Limit}.EffectiveLimit()
+}
+
+// InboxItem is one event in a person's inbox with the reader's state of it.
+type InboxItem struct {
+	Notification
+	// State is one of the Inbox* constants.
+	State string `json:"state"`
+}
+
+// InboxStore is the in-app inbox: the notification history read per person,
+// one entry per event rather than per delivery target, with the reader's
+// read and archived state kept beside it.
+//
+// It sits over the same rows as HistoryStore, so it is bounded by the same
+// retention pass; an event the worker purges leaves the inbox with its state.
+// A recipient whose mode is ModeOff has nothing queued and so an empty inbox.
+type InboxStore interface {
+	// Inbox returns one page of the filter's inbox, newest first.
+	Inbox(ctx context.Context, filter InboxFilter) ([]InboxItem, error)
+	// InboxCount returns how many entries match the filter, ignoring its
+	// paging fields.
+	InboxCount(ctx context.Context, filter InboxFilter) (int, error)
+	// Unread returns how many of the recipient's entries are unread.
+	Unread(ctx context.Context, recipient string) (int, error)
+	// SetInboxState moves the recipient's listed entries among ids to state
+	// and returns how many it changed. An id that is not one of the
+	// recipient's entries is ignored, never an error.
+	SetInboxState(ctx context.Context, recipient string, ids []int64, state string) (int64, error)
+	// MarkAllRead marks every unread entry of the recipient read and
+	// returns how many it changed.
+	MarkAllRead(ctx context.Context, recipient string) (int64, error)
+}
@@ -1,5 +1,6 @@
-// Package notification is the domain of the platform's email notifications:
-// the event model, the preference model, the two store contracts that persist
+// Package notification is the domain of the platform's notifications: the
+// event model, the preference model and its per-category routing to email or
+// to a person's chat and webhook channels, the store contracts that persist
 // them, and the enqueue path share and thread-comment triggers call.
 //
 // It is the vocabulary every other layer of the substrate is written in, and
@@ -10,6 +11,7 @@
 //	internal/notification/notifyqueue         queue persistence + LISTEN wakeup
 //	internal/notification/notifyrender        branded email rendering
 //	internal/notification/notifysend          SMTP transport
+//	internal/notification/notifychannel       webhook, Slack, and Teams transport
 //	internal/notification/notifyworker        the send worker that drains the queue
 //	internal/httpserver/notifyhttp            self-scoped preference REST
 //	internal/httpserver/unsubhttp             no-login unsubscribe endpoint
@@ -57,6 +59,19 @@ const (
 	// it as well would notify a person about something they are already
 	// reading.
 	CategoryScriptRun = "script_run"
+	// CategoryApproval covers write-operation approvals: a request an approver
+	// is asked to decide, and the decision reported back to the person whose
+	// agent made it. Like the script-run alert it is addressed to a
+	// responsibility, named by the approval rule or by having made the
+	// request, so it carries no per-user toggle; ModeOff remains the
+	// recipient's own opt-out.
+	CategoryApproval = "approval"
+	// CategoryWatch covers news about something the recipient chose to
+	// watch: a new version, a new thread, an applied knowledge change, or a
+	// schema change. Watching is itself the opt-in, so it carries no
+	// category toggle; unwatching is how to stop it, and ModeOff and the
+	// daily digest still apply.
+	CategoryWatch = "watch"
 )
 
 // Delivery modes for user preferences.
@@ -102,6 +117,33 @@ const (
 	// names the script in ItemTitle, the run in ItemID, and carries the failure
 	// and the tail of what the script printed in Message.
 	KindScriptRun = "script_run"
+	// KindScriptQuality marks a scheduled script run that executed to the end
+	// and failed its data-quality checks. It shares CategoryScriptRun and the
+	// KindScriptRun payload shape, with the failed checks in Message; it is a
+	// kind of its own because the alert has to say the opposite thing — the
+	// script worked and the data did not — to a reader who will act on it.
+	KindScriptQuality = "script_quality"
+	// KindApprovalRequest marks a tool call parked for approval. ItemID is
+	// the approval id, ItemTitle the one-line request summary, Actor the
+	// requester, and Message the rule, deadline, and request arguments.
+	KindApprovalRequest = "approval_request"
+	// KindApprovalApproved and KindApprovalRejected report a decision to the
+	// requester. Actor is the approver and Message their stated reason.
+	KindApprovalApproved = "approval_approved"
+	KindApprovalRejected = "approval_rejected"
+	// KindWatchVersion marks a new version of a watched item, or a change to
+	// a watched collection. Message carries the change summary, if any.
+	KindWatchVersion = "watch_version"
+	// KindWatchThread marks a new thread on a watched item. Message carries
+	// the thread's title.
+	KindWatchThread = "watch_thread"
+	// KindWatchChangeset marks an applied knowledge change to a watched
+	// dataset, or to a dataset a watched knowledge page references. Message
+	// carries the change type.
+	KindWatchChangeset = "watch_changeset"
+	// KindWatchSchema marks a column change the semantic provider reports
+	// for a watched dataset. Message lists the columns added and removed.
+	KindWatchSchema = "watch_schema"
 )
 
 // ReviewQueue is the pending-review rollup a KindReviewQueue notification
@@ -147,11 +189,19 @@ type Payload struct {
 
 // Notification is one queued delivery.
 type Notification struct {
-	ID           int64      `json:"id"`
-	Recipient    string     `json:"recipient"`
-	Category     string     `json:"category"`
-	Payload      Payload    `json:"payload"`
-	Digest       bool       `json:"digest"`
+	ID        int64   `json:"id"`
+	Recipient string  `json:"recipient"`
+	Category  string  `json:"category"`
+	Payload   Payload `json:"payload"`
+	Digest    bool    `json:"digest"`
+	// ChannelID addresses the row to one of the recipient's channels. Zero is
+	// the mailbox. A channel row is never a digest: Mode's daily batching is
+	// a property of email.
+	ChannelID int64 `json:"channel_id,omitempty"`
+	// Duplicate marks a further row of an event already queued to the
+	// recipient for another target. The inbox lists each event once, by its
+	// one row with Duplicate unset.
+	Duplicate    bool       `json:"duplicate,omitempty"`
 	Status       string     `json:"status"`
 	Attempts     int        `json:"attempts"`
 	LastError    string     `json:"last_error,omitempty"`
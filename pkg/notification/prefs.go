@@ -2,6 +2,7 @@ package notification
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
@@ -9,12 +10,15 @@ import (
 // DefaultPrefs applies (immediate delivery, all categories on), per the
 // platform's important-features-default-on convention.
 type Prefs struct {
-	Email           string    `json:"email"`
-	Mode            string    `json:"mode"`
-	SharesEnabled   bool      `json:"shares_enabled"`
-	CommentsEnabled bool      `json:"comments_enabled"`
-	MentionsEnabled bool      `json:"mentions_enabled"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	Email           string `json:"email"`
+	Mode            string `json:"mode"`
+	SharesEnabled   bool   `json:"shares_enabled"`
+	CommentsEnabled bool   `json:"comments_enabled"`
+	MentionsEnabled bool   `json:"mentions_enabled"`
+	// Routes sends a category to its targets: RouteEmail, or the name of one
+	// of the person's channels. A category it does not name goes to email.
+	Routes    map[string][]string `json:"routes"`
+	UpdatedAt time.Time           `json:"updated_at"`
 }
 
 // DefaultPrefs returns the preferences applied to a user with no stored row.
@@ -25,9 +29,18 @@ func DefaultPrefs(email string) Prefs {
 		SharesEnabled:   true,
 		CommentsEnabled: true,
 		MentionsEnabled: true,
+		Routes:          map[string][]string{},
 	}
 }
 
+// RoutesFor returns the targets a category is delivered to.
+func (p Prefs) RoutesFor(category string) []string {
+	if targets := p.Routes[category]; len(targets) > 0 {
+		return targets
+	}
+	return []string{RouteEmail}
+}
+
 // PrefsUpdate carries the fields of a preferences write; nil fields keep the
 // current (or default) value.
 type PrefsUpdate struct {
@@ -35,6 +48,8 @@ type PrefsUpdate struct {
 	SharesEnabled   *bool   `json:"shares_enabled,omitempty"`
 	CommentsEnabled *bool   `json:"comments_enabled,omitempty"`
 	MentionsEnabled *bool   `json:"mentions_enabled,omitempty"`
+	// Routes replaces the whole routing map when set.
+	Routes *map[string][]string `json:"routes,omitempty"`
 }
 
 // Apply overlays the update's set fields onto p, leaving the rest untouched.
@@ -54,6 +69,35 @@ func (u PrefsUpdate) Apply(p *Prefs) {
 	if u.MentionsEnabled != nil {
 		p.MentionsEnabled = *u.MentionsEnabled
 	}
+	if u.Routes != nil {
+		p.Routes = *u.Routes
+	}
+}
+
+// maxRouteTargets bounds how many places one category is delivered to.
+const maxRouteTargets = 4
+
+// ValidateRoutes checks a routing map's shape: known categories, and targets
+// that are RouteEmail or could name a channel. Whether a named channel exists
+// is the caller's to check against the person's own.
+func ValidateRoutes(routes map[string][]string) error {
+	for category, targets := range routes {
+		if !ValidCategory(category) {
+			return fmt.Errorf("unknown notification category %q", category)
+		}
+		if len(targets) > maxRouteTargets {
+			return fmt.Errorf("category %q routes to more than %d targets", category, maxRouteTargets)
+		}
+		for _, target := range targets {
+			if target == RouteEmail {
+				continue
+			}
+			if err := ValidateChannelName(target); err != nil {
+				return fmt.Errorf("category %q: %w", category, err)
+			}
+		}
+	}
+	return nil
 }
 
 // ValidMode reports whether m is one of the delivery modes.
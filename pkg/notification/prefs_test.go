@@ -1,6 +1,9 @@
 package notification
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestDefaultPrefs(t *testing.T) {
 	p := DefaultPrefs("a@b.io")
@@ -34,7 +37,7 @@ func TestPrefsUpdate_Apply(t *testing.T) {
 
 	untouched := DefaultPrefs("a@b.io")
 	PrefsUpdate{}.Apply(&untouched)
-	if untouched != DefaultPrefs("a@b.io") {
+	if !reflect.DeepEqual(untouched, DefaultPrefs("a@b.io")) {
 		t.Errorf("empty update changed prefs: %+v", untouched)
 	}
 
@@ -57,3 +60,35 @@ func TestValidMode(t *testing.T) {
 		}
 	}
 }
+
+// TestPrefs_Routes pins the routing defaults: a category the map does not name
+// goes to email, and an update replaces the map whole.
+func TestPrefs_Routes(t *testing.T) {
+	p := DefaultPrefs("a@b.io")
+	if got := p.RoutesFor(CategoryScriptRun); !reflect.DeepEqual(got, []string{RouteEmail}) {
+		t.Errorf("default route = %v; want email", got)
+	}
+	routes := map[string][]string{CategoryScriptRun: {"team-alerts"}, CategoryMention: {"dm", RouteEmail}}
+	PrefsUpdate{Routes: &routes}.Apply(&p)
+	if got := p.RoutesFor(CategoryScriptRun); !reflect.DeepEqual(got, []string{"team-alerts"}) {
+		t.Errorf("script_run route = %v", got)
+	}
+	if got := p.RoutesFor(CategoryShare); !reflect.DeepEqual(got, []string{RouteEmail}) {
+		t.Errorf("an unrouted category must stay on email, got %v", got)
+	}
+}
+
+func TestValidateRoutes(t *testing.T) {
+	if err := ValidateRoutes(map[string][]string{CategoryReviewQueue: {"team-alerts", RouteEmail}}); err != nil {
+		t.Errorf("valid routes refused: %v", err)
+	}
+	for name, routes := range map[string]map[string][]string{
+		"unknown category": {"digest": {RouteEmail}},
+		"bad target":       {CategoryMention: {"Team Alerts"}},
+		"too many":         {CategoryMention: {"a", "b", "c", "d", "e"}},
+	} {
+		if err := ValidateRoutes(routes); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
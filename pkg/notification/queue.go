@@ -13,8 +13,10 @@ type QueueStore interface {
 	// Enqueue inserts a pending row and nudges the send worker.
 	Enqueue(ctx context.Context, n Notification) error
 	// ClaimImmediate claims the next due non-digest row under a lease,
-	// returning ErrNoWork when none is due.
-	ClaimImmediate(ctx context.Context, lease time.Duration) (*Notification, error)
+	// returning ErrNoWork when none is due. With includeEmail false it
+	// claims only channel rows, which is what a worker with no mail server
+	// can deliver.
+	ClaimImmediate(ctx context.Context, lease time.Duration, includeEmail bool) (*Notification, error)
 	// ClaimDigest claims every due digest row for one recipient under a
 	// lease, returning ErrNoWork when none is due.
 	ClaimDigest(ctx context.Context, lease time.Duration) ([]Notification, error)
@@ -0,0 +1,88 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/pkg/urnbuild"
+)
+
+// Watch target types. The portal types name the same things a thread or a
+// share targets; a dataset is a DataHub dataset, named by its URN.
+const (
+	WatchAsset         = "asset"
+	WatchCollection    = "collection"
+	WatchPrompt        = "prompt"
+	WatchKnowledgePage = "knowledge_page"
+	WatchDataset       = "dataset"
+)
+
+// MaxWatchesPerPerson bounds how many things one person watches. Each watch
+// is a target the sweep looks up on every pass, and a dataset watch is a
+// catalog read, so the cap bounds the sweep as much as the table.
+const MaxWatchesPerPerson = 200
+
+// maxWatchTargetID bounds a stored target id. Portal ids are short; a dataset
+// URN is the long case.
+const maxWatchTargetID = 512
+
+var (
+	// ErrWatchNotFound reports a watch the caller does not hold.
+	ErrWatchNotFound = errors.New("notification: watch not found")
+	// ErrWatchLimit reports an add past MaxWatchesPerPerson.
+	ErrWatchLimit = errors.New("notification: too many watches")
+)
+
+// ValidWatchType reports whether t is one of the Watch* target types.
+func ValidWatchType(t string) bool {
+	switch t {
+	case WatchAsset, WatchCollection, WatchPrompt, WatchKnowledgePage, WatchDataset:
+		return true
+	}
+	return false
+}
+
+// ValidateWatchTarget checks a target a person asks to watch. It checks the
+// shape only: whether the person may see the target is decided when there is
+// news about it, so a watch on something later shared away stops reporting
+// rather than leaking.
+func ValidateWatchTarget(targetType, targetID string) error {
+	if !ValidWatchType(targetType) {
+		return fmt.Errorf("target type must be %s, %s, %s, %s, or %s",
+			WatchAsset, WatchCollection, WatchPrompt, WatchKnowledgePage, WatchDataset)
+	}
+	if targetID == "" || len(targetID) > maxWatchTargetID {
+		return fmt.Errorf("target id is required and at most %d characters", maxWatchTargetID)
+	}
+	if targetType == WatchDataset {
+		if _, err := urnbuild.ParseDatasetURN(targetID); err != nil {
+			return errors.New("a dataset is watched by its DataHub dataset URN")
+		}
+	}
+	return nil
+}
+
+// Watch is one person's subscription to changes of one target.
+type Watch struct {
+	Email      string    `json:"-"`
+	TargetType string    `json:"target_type" example:"asset"`
+	TargetID   string    `json:"target_id" example:"ast_01HZX3"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WatchStore persists watches. Every method but Watchers is scoped to one
+// person's address, in NormalizeAddress form.
+type WatchStore interface {
+	// List returns the person's watches, newest first.
+	List(ctx context.Context, email string) ([]Watch, error)
+	// Add records a watch and reports whether it is new; watching something
+	// already watched succeeds and changes nothing. Past the cap it returns
+	// ErrWatchLimit.
+	Add(ctx context.Context, w Watch) (created bool, err error)
+	// Remove drops one of the person's watches, or returns ErrWatchNotFound.
+	Remove(ctx context.Context, email, targetType, targetID string) error
+	// Watchers returns the addresses watching a target.
+	Watchers(ctx context.Context, targetType, targetID string) ([]string, error)
+}
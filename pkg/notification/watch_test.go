@@ -0,0 +1,29 @@
+package notification
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateWatchTarget(t *testing.T) {
+	tests := map[string]struct {
+		targetType, targetID string
+		ok                   bool
+	}{
+		"asset":            {WatchAsset, "ast_1", true},
+		"knowledge page":   {WatchKnowledgePage, "kp_1", true},
+		"dataset urn":      {WatchDataset, "urn:li:dataset:(urn:li:dataPlatform:trino,hive.sales.orders,PROD)", true},
+		"unknown type":     {"table", "x", false},
+		"empty id":         {WatchPrompt, "", false},
+		"overlong id":      {WatchCollection, strings.Repeat("c", maxWatchTargetID+1), false},
+		"dataset by name":  {WatchDataset, "hive.sales.orders", false},
+		"other entity urn": {WatchDataset, "urn:li:glossaryTerm:revenue", false},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := ValidateWatchTarget(tc.targetType, tc.targetID); (err == nil) != tc.ok {
+				t.Errorf("ValidateWatchTarget(%q, %q) = %v, want ok=%v", tc.targetType, tc.targetID, err, tc.ok)
+			}
+		})
+	}
+}
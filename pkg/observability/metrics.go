@@ -34,6 +34,7 @@ import (
 //   - apigateway_outbound_duration_seconds
 //   - apigateway_inbound_requests_total{connection, operation_id, method, status_class, identity}
 //   - apigateway_inbound_duration_seconds{connection, operation_id, method, status_class}
+//   - apigateway_contract_checks_total{connection, operation_id, result}
 //
 // Inbound cardinality: the inbound series count is bounded by
 // connections × operation_ids × methods × status_classes × identities.
@@ -61,6 +62,14 @@ const (
 	instAPIGwInbound         = "apigateway_inbound_requests"
 	instAPIGwInboundLatency  = "apigateway_inbound_duration"
 
+	// instAPIGwContractChecks counts upstream responses compared with the
+	// response their catalog operation declares, on connections that opt in
+	// to contract validation. result is conformed or violated, so the
+	// violation rate per operation is one ratio: a rising one means the
+	// upstream changed and its spec did not. operation_id is bounded by the
+	// catalog, as on the inbound counter.
+	instAPIGwContractChecks = "apigateway_contract_checks"
+
 	// instAuditEventsDropped counts audit events lost by the bounded async
 	// writer — queue-full drops plus writes that failed or were abandoned
 	// at the per-write timeout (issue #884). Exposed name:
@@ -172,6 +181,7 @@ const (
 	attrMethod         = "method"
 	attrStatusClass    = "status_class"
 	attrIdentity       = "identity"
+	attrResult         = "result"
 	// Toolkit / provider metric attribute keys (issue #461).
 	attrScript    = "script"
 	attrTrigger   = "trigger"
@@ -220,6 +230,16 @@ type APIGatewayInboundAttrs struct {
 	Identity    string
 }
 
+// APIGatewayContractAttrs is the label set for one contract check: the
+// connection, the operation the response was compared with, and whether it
+// conformed. The difference itself is recorded in the catalog's drift
+// report, not here.
+type APIGatewayContractAttrs struct {
+	Connection  string
+	OperationID string
+	Result      string
+}
+
 // Metrics owns the OTel MeterProvider and the registered
 // instruments. A nil *Metrics is a valid no-op recorder: every Record
 // method becomes a fast nil-check, so call sites can record
@@ -239,6 +259,7 @@ type Metrics struct {
 	apigwOutboundDuration metric.Float64Histogram
 	apigwInboundTotal     metric.Int64Counter
 	apigwInboundDuration  metric.Float64Histogram
+	apigwContractChecks   metric.Int64Counter
 	auditEventsDropped    metric.Int64Counter
 	rateLimited           metric.Int64Counter
 
@@ -440,6 +461,13 @@ func (m *Metrics) registerInstruments(meter metric.Meter) error {
 	if err != nil {
 		return fmt.Errorf(instErrFmt, instAPIGwInboundLatency, err)
 	}
+	m.apigwContractChecks, err = meter.Int64Counter(
+		instAPIGwContractChecks,
+		metric.WithDescription("Total upstream responses compared with the response their API catalog operation declares, labeled by connection, operation_id, and result (conformed or violated). A rising violated share means an upstream changed without its spec."),
+	)
+	if err != nil {
+		return fmt.Errorf(instErrFmt, instAPIGwContractChecks, err)
+	}
 	m.auditEventsDropped, err = meter.Int64Counter(
 		instAuditEventsDropped,
 		metric.WithDescription("Total audit events lost by the bounded async writer: queue-full drops plus writes that failed or were abandoned at the per-write timeout (#884). A growing value means audit rows are being lost because the store cannot keep up; audit delivery is best-effort."),
@@ -683,6 +711,19 @@ func (m *Metrics) RecordAPIGatewayInbound(ctx context.Context, attrs APIGatewayI
 	m.apigwInboundDuration.Record(ctx, duration.Seconds(), histSet)
 }
 
+// RecordAPIGatewayContractCheck records one response compared with its
+// spec. Nil-safe.
+func (m *Metrics) RecordAPIGatewayContractCheck(ctx context.Context, attrs APIGatewayContractAttrs) {
+	if m == nil {
+		return
+	}
+	m.apigwContractChecks.Add(ctx, 1, metric.WithAttributes(
+		attribute.String(attrConnection, attrs.Connection),
+		attribute.String(attrOperationID, attrs.OperationID),
+		attribute.String(attrResult, attrs.Result),
+	))
+}
+
 // RecordAuditEventDropped records one audit event lost by the bounded async
 // writer — a queue-full drop or a write that failed or was abandoned at the
 // per-write timeout (issue #884). Carries no labels — a single scalar is enough
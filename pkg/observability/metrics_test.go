@@ -198,6 +198,33 @@ func TestRecordAPIGatewayInbound(t *testing.T) {
 	}
 }
 
+func TestRecordAPIGatewayContractCheck(t *testing.T) {
+	m, err := New(Config{Enabled: true, ListenAddr: ":0"})
+	if err != nil {
+		t.Fatalf("New(enabled) err = %v", err)
+	}
+	defer func() { _ = m.Shutdown(context.Background()) }()
+
+	m.RecordAPIGatewayContractCheck(context.Background(), APIGatewayContractAttrs{
+		Connection: "salesforce", OperationID: "getAccount", Result: "violated",
+	})
+
+	body := scrapeMetrics(t, m.Handler())
+	for _, want := range []string{
+		"apigateway_contract_checks_total",
+		`connection="salesforce"`,
+		`operation_id="getAccount"`,
+		`result="violated"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("scrape body missing %q\n--- body ---\n%s", want, body)
+		}
+	}
+
+	var nilMetrics *Metrics
+	nilMetrics.RecordAPIGatewayContractCheck(context.Background(), APIGatewayContractAttrs{}) // must not panic
+}
+
 func TestRecordEnrichmentBytes(t *testing.T) {
 	m, err := New(Config{Enabled: true, ListenAddr: ":0"})
 	if err != nil {
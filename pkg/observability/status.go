@@ -1,6 +1,9 @@
 package observability
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 // Status category labels for tool calls and outbound HTTP. The set is
 // closed and small so total label cardinality on counters and
@@ -52,8 +55,27 @@ const (
 	// the scrubbed transport error). Used to populate
 	// audit_logs.error_message when no other source is available.
 	MetaAuditOutcomeMessage = "audit_outcome_message"
+
+	// MetaAuditPages carries a []AuditPage: upstream requests the tool
+	// made beyond the one its own audit row describes, such as the
+	// follow-on pages of an auto-paginated call. The audit middleware
+	// writes one additional row per entry.
+	MetaAuditPages = "audit_pages"
 )
 
+// AuditPage describes one additional upstream request a tool call made,
+// carried under MetaAuditPages. Parameters are the arguments the request
+// would have taken as a call of its own, so the row it becomes reads
+// like any other call of the same tool. Outcome and Message follow
+// MetaAuditOutcome and MetaAuditOutcomeMessage.
+type AuditPage struct {
+	StartedAt  time.Time      `json:"started_at"`
+	DurationMS int64          `json:"duration_ms"`
+	Parameters map[string]any `json:"parameters"`
+	Outcome    string         `json:"outcome"`
+	Message    string         `json:"message,omitempty"`
+}
+
 // HTTP status class labels for outbound calls. The "other" bucket
 // covers transport-level failures (status code 0) and the rarely-seen
 // 1xx informational range. Recording the raw status code as a label
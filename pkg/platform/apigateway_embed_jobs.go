@@ -86,6 +86,7 @@ func (p *Platform) WireAPIGatewayEmbedJobsFromDB() {
 			PortalAssets:         p.portalStore.AssetStore() != nil,
 			PortalCollections:    p.portalStore.CollectionStore() != nil,
 			PortalKnowledgePages: p.portalStore.KnowledgePageStore() != nil,
+			PortalAssetContent:   p.portalStore.S3Client() != nil,
 			Resources:            p.resources.Store() != nil,
 			Calls:                p.audit.Calls() != nil,
 			Scripts:              p.scripts.IndexProducer() != nil,
@@ -97,6 +98,7 @@ func (p *Platform) WireAPIGatewayEmbedJobsFromDB() {
 		CatalogIndexConfig: p.config.Knowledge.CatalogIndex,
 		ResourceBlobs:      p.resources.S3Client(),
 		ResourceBucket:     p.config.Resources.Managed.S3Bucket,
+		AssetBlobs:         p.portalStore.S3Client(),
 	})
 	if handle == nil {
 		// db + embedder are present but nothing registered. A worker with no
@@ -15,6 +15,7 @@ import (
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/txn2/mcp-data-platform/internal/platform/approvalgate"
 	"github.com/txn2/mcp-data-platform/internal/platform/datasetindex"
 	"github.com/txn2/mcp-data-platform/internal/platform/dedup"
 	"github.com/txn2/mcp-data-platform/internal/platform/portalcfg"
@@ -23,6 +24,7 @@ import (
 	"github.com/txn2/mcp-data-platform/internal/platform/toolargs"
 	"github.com/txn2/mcp-data-platform/internal/platform/toolkitcfg"
 	"github.com/txn2/mcp-data-platform/pkg/browsersession"
+	"github.com/txn2/mcp-data-platform/pkg/portal/embedtoken"
 	"github.com/txn2/mcp-data-platform/pkg/portal/knowledgepage"
 	"github.com/txn2/mcp-data-platform/pkg/script"
 	datahubsemantic "github.com/txn2/mcp-data-platform/pkg/semantic/datahub"
@@ -51,6 +53,7 @@ const (
 	kindS3      = "s3"
 	kindMCP     = "mcp"
 	kindAPI     = "api"
+	kindGRPC    = "grpc"
 	// toolListConns is the unified platform-provided list-connections
 	// tool name.
 	toolListConns = "list_connections"
@@ -137,6 +140,7 @@ type Config struct {
 	SessionGate          SessionGateConfig   `yaml:"session_gate"`
 	Purpose              PurposeConfig       `yaml:"purpose"`
 	RateLimit            RateLimitConfig     `yaml:"rate_limit"`
+	Approvals            ApprovalsConfig     `yaml:"approvals"`
 	APIGateway           APIGatewayConfig    `yaml:"apigateway"`
 	Observability        ObservabilityConfig `yaml:"observability"`
 
@@ -400,6 +404,7 @@ type PortalConfig struct {
 	ReplyTo        string                `yaml:"reply_to"`        // optional Reply-To address applied to all outgoing email; unset leaves the header off
 	RateLimit      PortalRateLimitConfig `yaml:"rate_limit"`
 	Export         PortalExportConfig    `yaml:"export"` // trino_export configuration
+	Embed          embedtoken.Config     `yaml:"embed"`  // signed iframe embeds of one asset version (docs/server/admin-portal.md)
 }
 
 // PortalExportConfig configures the trino_export tool.
@@ -1342,12 +1347,12 @@ type ScriptsConfig struct {
 	// Zero or negative takes the default.
 	RunRetentionDays int `yaml:"run_retention_days"`
 
-	// Destinations declares the named bucket destinations a script's
-	// platform.export may write to, each a complete address: the platform S3
-	// connection, the bucket, and an optional key prefix. A run resolves the
-	// name a script writes against this list at run time, so repointing a
-	// destination here takes effect on the next run. The portal is built in
-	// and never declared.
+	// Destinations declares the named destinations a script's platform.export
+	// may write to, each a complete address of its kind: an S3 connection and
+	// bucket, an SFTP server and directory, a mounted directory, or a set of
+	// email recipients. A run resolves the name a script writes against this
+	// list at run time, so repointing a destination here takes effect on the
+	// next run. The portal is built in and never declared.
 	Destinations []script.Destination `yaml:"destinations"`
 
 	// Worker decides whether this replica executes queued runs or only
@@ -1356,7 +1361,7 @@ type ScriptsConfig struct {
 }
 
 // ScriptDestinations returns the configured destinations normalized, with the
-// kind defaulted to s3: configuration declares only bucket destinations.
+// kind defaulted to s3, the one kind there was before the others.
 func (c *ScriptsConfig) ScriptDestinations() []script.Destination {
 	out := make([]script.Destination, 0, len(c.Destinations))
 	for _, d := range c.Destinations {
@@ -1779,6 +1784,11 @@ type SessionHandlesConfig = toolargs.SessionHandles
 // callers address it unchanged; see that package for the field contract.
 type PurposeConfig = toolargs.Purpose
 
+// ApprovalsConfig configures the approval workflow for write operations on
+// API and gateway tools. Defined in internal/platform/approvalgate and aliased
+// here so operator YAML and library callers address it unchanged.
+type ApprovalsConfig = approvalgate.Config
+
 // LoadConfig loads configuration from a file.
 // The path is expected to come from command line arguments, controlled by the administrator.
 func LoadConfig(path string) (*Config, error) {
@@ -2040,6 +2050,8 @@ func (c *Config) Validate() error {
 	// means. Refuse here and name the candidates rather than resolve to one
 	// the operator did not choose.
 	errs = append(errs, toolkitcfg.MissingDefaults(c.Toolkits)...)
+	errs = append(errs, c.Approvals.Errors()...)
+	errs = append(errs, c.Portal.Embed.Errors()...)
 
 	errs = c.validateOAuth(errs)
 	errs = c.validateSessions(errs)
@@ -11,8 +11,8 @@ import (
 
 // TestScriptDestinations_NormalizesAndDefaultsTheKind proves the accessor the
 // wiring reads hands the engine addresses in one canonical form: fields
-// trimmed, the prefix without its slashes, and the kind defaulted to s3 —
-// configuration declares only bucket destinations.
+// trimmed, the prefix without its slashes, and the kind defaulted to s3, the
+// kind a declaration that names none has always meant.
 func TestScriptDestinations_NormalizesAndDefaultsTheKind(t *testing.T) {
 	cfg := ScriptsConfig{Destinations: []script.Destination{
 		{Name: " acme-drop ", Connection: " acme-s3", Bucket: "acme-exports ", Prefix: "/weekly/"},
@@ -50,6 +50,21 @@ func TestConfigValidate_ScriptDestinations(t *testing.T) {
 			name: "no declarations at all is the ordinary state",
 			cfg:  base(),
 		},
+		{
+			name: "an sftp declaration is accepted",
+			cfg: base(script.Destination{
+				Name: "partner-drop", Kind: script.DestinationKindSFTP, Host: "sftp.acme.example",
+				User: "drop", Password: "secret", HostKey: "ssh-ed25519 AAAA", Path: "/incoming",
+			}),
+		},
+		{
+			name: "an sftp declaration without the server's key is refused",
+			cfg: base(script.Destination{
+				Name: "partner-drop", Kind: script.DestinationKindSFTP, Host: "sftp.acme.example",
+				User: "drop", Password: "secret", Path: "/incoming",
+			}),
+			wantErr: "must set host_key",
+		},
 		{
 			name:    "a destination without a connection is refused",
 			cfg:     base(script.Destination{Name: "acme-drop", Bucket: "acme-exports"}),
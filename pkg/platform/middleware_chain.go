@@ -2,13 +2,17 @@ package platform
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 
+	"github.com/txn2/mcp-data-platform/internal/platform/approvalgate"
+	"github.com/txn2/mcp-data-platform/internal/platform/gatewaywire"
 	"github.com/txn2/mcp-data-platform/internal/platform/mwchain"
 	"github.com/txn2/mcp-data-platform/internal/platform/provenance"
 	"github.com/txn2/mcp-data-platform/internal/platform/toolargs"
 	"github.com/txn2/mcp-data-platform/internal/platform/toolratelimit"
 	"github.com/txn2/mcp-data-platform/pkg/middleware"
+	trinokit "github.com/txn2/mcp-data-platform/pkg/toolkits/trino"
 )
 
 // mwName identifies a receiving middleware within the canonical chain order.
@@ -30,6 +34,7 @@ const (
 	mwSessionGate         mwName = "session_gate"
 	mwWorkflowGate        mwName = "workflow_gate"
 	mwRateLimit           mwName = "rate_limit"
+	mwApprovalGate        mwName = "approval_gate"
 	mwReflexiveCapture    mwName = "reflexive_capture"
 	mwTracing             mwName = "tracing"
 	mwMetrics             mwName = "metrics"
@@ -37,6 +42,7 @@ const (
 	mwErrorContract       mwName = "error_contract"
 	mwClientLogging       mwName = "client_logging"
 	mwManagedResource     mwName = "managed_resource"
+	mwScanStats           mwName = "scan_stats"
 	mwCallReference       mwName = "call_reference"
 	mwEnrichment          mwName = "enrichment"
 	mwUnwrapJSON          mwName = "unwrap_json"
@@ -127,6 +133,32 @@ func (p *Platform) receivingMiddlewareChain() []mwSpec {
 			)
 		}},
 
+		// Approval gate: parks write operations an approval rule holds for a
+		// person, so it is outer to the observers (a parked call never ran)
+		// and inner to the auth/authz middleware whose PlatformContext it
+		// fingerprints. Owned by the approvalgate seam, wired like the rate
+		// limiter so the facade gains no field or method for it.
+		{Name: mwApprovalGate, Requires: []mwName{mwToolCall}, Register: func() {
+			if !p.config.Approvals.Enabled {
+				return
+			}
+			h := approvalgate.New(approvalgate.Deps{
+				Config:                p.config.Approvals,
+				DB:                    p.db,
+				Inspector:             gatewaywire.ApprovalInspector(p.toolkitRegistry),
+				Audit:                 p.audit.Logger(),
+				NotificationsDisabled: !p.config.Notifications.IsEnabled(),
+				DigestHourUTC:         p.config.Notifications.DigestHour(),
+				BaseURL:               p.config.Portal.PublicBaseURL,
+			})
+			h.RegisterTools(p.mcpServer)
+			p.mcpServer.AddReceivingMiddleware(h.Middleware())
+			p.lifecycle.OnStop(func(context.Context) error {
+				h.Close()
+				return nil
+			})
+		}},
+
 		// Observers: read PlatformContext (identity/session/tool metadata), so
 		// they require the auth/authz middleware that writes it. Reflexive
 		// capture observes the tool result on the way out and must see the
@@ -145,6 +177,23 @@ func (p *Platform) receivingMiddlewareChain() []mwSpec {
 		{Name: mwClientLogging, Register: p.addClientLoggingMiddleware},
 		{Name: mwManagedResource, Register: p.addManagedResourceMiddleware},
 
+		// Scan stats add what Trino read to the stats of a managed script's
+		// trino_query, which the run's rows_scanned quota is charged from. Owned
+		// by the trino toolkit. It reads PlatformContext (the call's source and
+		// the toolkit that served it), so it requires the auth/authz middleware
+		// that writes it.
+		{Name: mwScanStats, Requires: []mwName{mwToolCall}, Register: func() {
+			var trinos []*trinokit.Toolkit
+			for _, tk := range p.toolkitRegistry.GetByKind("trino") {
+				if trinoTk, ok := tk.(*trinokit.Toolkit); ok {
+					trinos = append(trinos, trinoTk)
+				}
+			}
+			if len(trinos) > 0 {
+				p.mcpServer.AddReceivingMiddleware(trinokit.ScanStatsMiddleware(processedInputOn(trinos), scriptCall))
+			}
+		}},
+
 		// The call reference stamps a data call's own audit event id onto its
 		// result. It reads PlatformContext (pc.EventID, pc.ToolkitKind), so it
 		// requires the auth/authz middleware that writes them.
@@ -289,3 +338,27 @@ func (p *Platform) addToolCallMiddleware() {
 		}),
 	)
 }
+
+// processedInputOn asks the trino toolkit that served a call, as its
+// PlatformContext names it, what the call's query read.
+func processedInputOn(trinos []*trinokit.Toolkit) trinokit.ProcessedInputFunc {
+	return func(ctx context.Context, connection, queryID string) (rows, bytes int64, err error) {
+		name := ""
+		if pc := middleware.GetPlatformContext(ctx); pc != nil {
+			name = pc.ToolkitName
+		}
+		for _, tk := range trinos {
+			if tk.Name() == name {
+				return tk.ProcessedInput(ctx, connection, queryID)
+			}
+		}
+		return 0, 0, fmt.Errorf("no trino toolkit named %q served the query", name)
+	}
+}
+
+// scriptCall reports whether a call comes from a managed script run, the only
+// caller whose scans are charged against a quota.
+func scriptCall(ctx context.Context) bool {
+	pc := middleware.GetPlatformContext(ctx)
+	return pc != nil && pc.Source == middleware.SourceScript
+}
@@ -38,6 +38,7 @@ func TestReceivingMiddlewareChain_CanonicalOrder(t *testing.T) {
 		mwSessionGate,
 		mwWorkflowGate,
 		mwRateLimit,
+		mwApprovalGate,
 		mwReflexiveCapture,
 		mwTracing,
 		mwMetrics,
@@ -45,6 +46,7 @@ func TestReceivingMiddlewareChain_CanonicalOrder(t *testing.T) {
 		mwErrorContract,
 		mwClientLogging,
 		mwManagedResource,
+		mwScanStats,
 		mwCallReference,
 		mwEnrichment,
 		mwUnwrapJSON,
@@ -85,6 +87,7 @@ func TestReceivingMiddlewareChain_PlatformContextReadersRequireAuth(t *testing.T
 		mwSessionGate:      true,
 		mwWorkflowGate:     true,
 		mwRateLimit:        true,
+		mwApprovalGate:     true,
 		mwReflexiveCapture: true,
 		mwTracing:          true,
 		mwMetrics:          true,
@@ -122,6 +125,9 @@ func TestReceivingMiddlewareChain_DeclaredDependencies(t *testing.T) {
 		// The rate limiter reads PlatformContext identity to key its per-user
 		// bucket, so it depends on the auth/authz writer.
 		mwRateLimit: {mwToolCall},
+		// The approval gate fingerprints the caller and the arguments the
+		// tool-call middleware has already stripped of platform arguments.
+		mwApprovalGate: {mwToolCall},
 		// Observers of EnrichmentApplied (set on the way out) must be outer to
 		// enrichment; metrics is deliberately excluded (it does not read it).
 		mwEnrichment: {mwToolCall, mwTracing, mwAudit, mwClientLogging},
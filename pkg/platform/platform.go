@@ -33,6 +33,7 @@ import (
 	"github.com/txn2/mcp-data-platform/internal/platform/datasetindex"
 	"github.com/txn2/mcp-data-platform/internal/platform/dedup"
 	"github.com/txn2/mcp-data-platform/internal/platform/exportadapters"
+	"github.com/txn2/mcp-data-platform/internal/platform/gatewaywire"
 	"github.com/txn2/mcp-data-platform/internal/platform/iam"
 	"github.com/txn2/mcp-data-platform/internal/platform/indexqueue"
 	"github.com/txn2/mcp-data-platform/internal/platform/knowledgelayer"
@@ -680,34 +681,6 @@ func (p *Platform) Broadcaster() session.Broadcaster {
 	return p.sessions.Broadcaster()
 }
 
-// gatewayListChangedNotifier adapts session.Broadcaster onto
-// gatewaykit.ToolListChangedNotifier so the gateway package doesn't
-// have to import pkg/session directly. Holds a reference to the
-// shared broadcaster; Publish errors are swallowed (logged) since
-// tools/list_changed is best-effort.
-type gatewayListChangedNotifier struct {
-	b session.Broadcaster
-}
-
-// NotifyToolsListChanged publishes a notifications/tools/list_changed
-// event to every connected SSE long-poll subscriber.
-func (n gatewayListChangedNotifier) NotifyToolsListChanged(ctx context.Context) {
-	if n.b == nil {
-		return
-	}
-	if err := n.b.Publish(ctx, session.Event{Method: "notifications/tools/list_changed"}); err != nil {
-		// source=gateway lets operators correlate this warning back to
-		// the gateway publish path vs. other broadcaster publishers
-		// (today there are none, but the broadcaster is shared and a
-		// future publisher would otherwise produce identically-shaped
-		// noise in dashboards).
-		slog.Warn("broadcaster: publish tools/list_changed failed",
-			"source", "gateway",
-			"method", "notifications/tools/list_changed",
-			"error", err)
-	}
-}
-
 // WireAPIGatewayTokenStore attaches the unified connoauth.Store to
 // every live api gateway toolkit. Mirrors WireGatewayTokenStore in
 // placement and lifecycle. Safe to call multiple times — the
@@ -790,32 +763,11 @@ func (p *Platform) APIGatewayCatalogStore() apigatewaycatalog.Store {
 // platform-level wiring) would leave connections in the "catalog_id
 // set but zero ops" state until the next admin save.
 func (p *Platform) WireAPIGatewayCatalogStore(store apigatewaycatalog.Store) {
-	if store == nil {
-		return
-	}
-	for _, tk := range p.toolkitRegistry.All() {
-		api, ok := tk.(*apigatewaykit.Toolkit)
-		if !ok {
-			continue
-		}
-		api.SetCatalogStore(store)
-		// The same store answers "what examples were promoted on this
-		// endpoint" when it is database-backed, so reading an endpoint's
-		// schema shows the requests known to have worked (#1321).
-		if examples, ok := store.(apigatewaycatalog.ExampleStore); ok {
-			api.SetExampleStore(examples)
-		}
-		for _, detail := range api.ListConnections() {
-			if err := api.ReloadConnection(detail.Name); err != nil {
-				slog.Warn("apigateway: catalog wire reload failed",
-					"connection", detail.Name, "error", err)
-			}
-		}
-	}
+	gatewaywire.CatalogStore(p.toolkitRegistry.All(), store)
 }
 
 // WireAPIGatewayRoutePolicy installs a per-(connection, method, path)
-// authorization gate on every live api gateway toolkit. No-op when
+// authorization gate on every live api and grpc gateway toolkit. No-op when
 // the platform's authorizer is not the persona-based implementation
 // (custom authorizers may opt in later via their own wiring).
 //
@@ -829,12 +781,7 @@ func (p *Platform) WireAPIGatewayRoutePolicy() {
 	if !ok {
 		return
 	}
-	policy := routepolicy.New(routepolicy.Deps{Authenticator: p.authenticator, Authorizer: pa})
-	for _, tk := range p.toolkitRegistry.All() {
-		if api, ok := tk.(*apigatewaykit.Toolkit); ok {
-			api.SetRoutePolicy(policy)
-		}
-	}
+	gatewaywire.RoutePolicy(p.toolkitRegistry.All(), routepolicy.New(routepolicy.Deps{Authenticator: p.authenticator, Authorizer: pa}))
 }
 
 // WireGatewayBroadcaster attaches the platform's session broadcaster
@@ -846,16 +793,7 @@ func (p *Platform) WireAPIGatewayRoutePolicy() {
 // call before or after RegisterTools — gateway toolkits read the
 // notifier atomically per call, so wiring order does not matter.
 func (p *Platform) WireGatewayBroadcaster() {
-	b := p.sessions.Broadcaster()
-	if b == nil {
-		return
-	}
-	notifier := gatewayListChangedNotifier{b: b}
-	for _, tk := range p.toolkitRegistry.All() {
-		if gw, ok := tk.(*gatewaykit.Toolkit); ok {
-			gw.SetToolListChangedNotifier(notifier)
-		}
-	}
+	gatewaywire.Broadcaster(p.toolkitRegistry.All(), p.sessions.Broadcaster())
 }
 
 // DB returns the platform's database handle, or nil when running
@@ -3067,13 +3005,14 @@ func (p *Platform) mergeDBConnectionsIntoConfig() {
 
 	// (1) The gateway toolkits need no instance config to be useful —
 	// auto-enable so the admin UI's "Add Connection" path produces a
-	// live toolkit on the next request. Both the MCP gateway (#338)
-	// and the HTTP API gateway (#364) follow the same convention:
-	// connections are added dynamically through the admin UI rather
-	// than via YAML 'instances' blocks, so the kind has to be
+	// live toolkit on the next request. The MCP gateway (#338), the
+	// HTTP API gateway (#364), and the gRPC gateway follow the same
+	// convention: connections are added dynamically through the admin
+	// UI rather than via YAML 'instances' blocks, so the kind has to be
 	// pre-enabled for saves to land in a live toolkit.
 	toolkitcfg.AutoEnableKind(p.config.Toolkits, kindMCP)
 	toolkitcfg.AutoEnableKind(p.config.Toolkits, kindAPI)
+	toolkitcfg.AutoEnableKind(p.config.Toolkits, kindGRPC)
 
 	instances, err := p.connectionStore.List(context.Background())
 	if err != nil {
@@ -3091,6 +3030,7 @@ func (p *Platform) mergeDBConnectionsIntoConfig() {
 		kindS3:    true,
 		kindMCP:   true,
 		kindAPI:   true,
+		kindGRPC:  true,
 	}
 
 	for _, inst := range instances {
@@ -1,11 +1,9 @@
 package platform
 
 import (
-	"bytes"
 	"context"
 	"encoding/base64"
 	"errors"
-	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -4608,48 +4606,6 @@ func TestBroadcaster_NonNilAfterNew(t *testing.T) {
 	}
 }
 
-// TestGatewayListChangedNotifier_NilBroadcaster proves the adapter
-// silently no-ops when its broadcaster is nil — the gateway must not
-// panic if the platform never wired one (e.g., headless tests).
-//
-// Captures the slog output so a regression that changes the no-op
-// path into a Warn/Error (or vice versa) is caught instead of just
-// "doesn't panic" coverage.
-func TestGatewayListChangedNotifier_NilBroadcaster(t *testing.T) {
-	var buf bytes.Buffer
-	prevDefault := slog.Default()
-	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
-	defer slog.SetDefault(prevDefault)
-
-	n := gatewayListChangedNotifier{b: nil}
-	n.NotifyToolsListChanged(context.Background()) // must not panic
-
-	if buf.Len() != 0 {
-		t.Errorf("nil-broadcaster path must be silent, got log output: %q", buf.String())
-	}
-}
-
-// TestGatewayListChangedNotifier_PublishError proves the adapter
-// swallows publish errors (logging them via slog) — list_changed is
-// best-effort and should never propagate to the gateway's caller.
-func TestGatewayListChangedNotifier_PublishError(t *testing.T) {
-	var buf bytes.Buffer
-	prevDefault := slog.Default()
-	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
-	defer slog.SetDefault(prevDefault)
-
-	b := session.NewMemoryBroadcaster(nil)
-	_ = b.Close()
-	n := gatewayListChangedNotifier{b: b}
-	// Publish on a closed broadcaster returns ErrBroadcasterClosed —
-	// the adapter must log it and not propagate.
-	n.NotifyToolsListChanged(context.Background())
-
-	if got := buf.String(); !strings.Contains(got, "publish tools/list_changed failed") {
-		t.Errorf("expected publish-failure warning in slog output, got %q", got)
-	}
-}
-
 // TestWireGatewayBroadcaster_NoBroadcaster proves WireGatewayBroadcaster
 // is safe to call when the platform has no broadcaster (early no-op
 // path), which happens on tests that mock the registry.
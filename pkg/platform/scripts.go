@@ -27,13 +27,17 @@ func wireScripts(p *Platform) *scriptexec.Handle {
 		DSN:    p.config.Database.DSN,
 		Server: p.mcpServer,
 		Export: scriptexec.ExportDeps{
-			Assets:   p.portalStore.AssetStore(),
-			Versions: p.portalStore.VersionStore(),
-			S3:       p.portalStore.S3Client(),
-			Bucket:   p.config.Portal.S3Bucket,
-			Prefix:   p.config.Portal.S3Prefix,
+			Assets:     p.portalStore.AssetStore(),
+			Versions:   p.portalStore.VersionStore(),
+			S3:         p.portalStore.S3Client(),
+			Bucket:     p.config.Portal.S3Bucket,
+			Prefix:     p.config.Portal.S3Prefix,
+			Authorizer: p.authorizer,
 		},
+		Encryptor:             p.restEncryptor,
 		Audit:                 p.audit.Logger(),
+		Insights:              p.memory.Toolkit(),
+		BuildURN:              p.datasetURNFor,
 		Metrics:               p.obs.Metrics(),
 		Destinations:          p.config.Scripts.ScriptDestinations(),
 		RunRetention:          p.config.Scripts.RunRetention(),
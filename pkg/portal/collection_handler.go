@@ -747,11 +747,20 @@ func (h *Handler) createCollectionShare(w http.ResponseWriter, r *http.Request)
 		writeError(w, http.StatusBadRequest, buildErr.Error())
 		return
 	}
+	guard := req.guardSpec()
+	if err := h.validateShareGuard(guard, &share); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	if err := h.deps.ShareStore.Insert(r.Context(), share); err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to create share")
 		return
 	}
+	if err := h.storeShareGuard(r.Context(), &share, guard); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create share")
+		return
+	}
 
 	if req.wantsNotify() {
 		h.notifyShare(r.Context(), &share, ShareEvent{
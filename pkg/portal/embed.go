@@ -0,0 +1,273 @@
+package portal
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/internal/portal/publicviewer"
+	"github.com/txn2/mcp-data-platform/pkg/audit"
+	"github.com/txn2/mcp-data-platform/pkg/blobserve"
+	"github.com/txn2/mcp-data-platform/pkg/middleware"
+	"github.com/txn2/mcp-data-platform/pkg/portal/embedtoken"
+)
+
+// embedPathPrefix is where an embed token opens. It is a sibling of
+// publicViewPathPrefix rather than a route under it: every share route puts
+// the share token in the segment an "embed" literal would occupy.
+const embedPathPrefix = "/portal/embed/"
+
+// registerEmbedRoutes registers the embed mint and viewer routes. Embeds
+// render one stored version, so a deployment without versioned storage offers
+// none.
+func (h *Handler) registerEmbedRoutes() {
+	if h.deps.Embed == nil || h.deps.VersionStore == nil {
+		return
+	}
+	h.mux.HandleFunc("POST /api/v1/portal/assets/{id}/embed-tokens", h.createEmbedToken)
+	// The token is its own gate: it is verified before anything is read, so
+	// these routes are rate limited but sit outside the share access gate.
+	h.publicMux.Handle("GET "+embedPathPrefix+"{token}",
+		h.rateLimiter.Middleware(http.HandlerFunc(h.embedView)))
+	h.publicMux.Handle("GET "+embedPathPrefix+"{token}/content",
+		h.rateLimiter.Middleware(http.HandlerFunc(h.embedContent)))
+}
+
+// createEmbedTokenRequest is the body of POST /api/v1/portal/assets/{id}/embed-tokens.
+type createEmbedTokenRequest struct {
+	// Version pins the embed to one asset version. 0 (omitted) means the
+	// current version.
+	Version int `json:"version,omitempty" example:"3"`
+	// ExpiresIn is a duration string ("2h") bounding the token's life. Empty
+	// means the configured default; it may not exceed the configured maximum.
+	ExpiresIn string `json:"expires_in,omitempty" example:"2h"`
+}
+
+// embedTokenResponse is returned by POST /api/v1/portal/assets/{id}/embed-tokens.
+type embedTokenResponse struct {
+	Token     string    `json:"token"`
+	EmbedURL  string    `json:"embed_url" example:"https://platform.example.com/portal/embed/k1.eyJ.sig"`
+	AssetID   string    `json:"asset_id" example:"ast_1a2b3c"`
+	Version   int       `json:"version" example:"3"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// createEmbedToken handles POST /api/v1/portal/assets/{id}/embed-tokens.
+//
+// @Summary      Mint asset embed token
+// @Description  Signs a short-lived URL that renders one asset version, without portal chrome, inside an iframe on an allowed origin. The owner or an admin.
+// @Tags         Shares
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                   true  "Asset ID"
+// @Param        body  body  createEmbedTokenRequest  true  "Embed configuration"
+// @Success      201  {object}  embedTokenResponse
+// @Failure      400  {object}  problemDetail
+// @Failure      401  {object}  problemDetail
+// @Failure      403  {object}  problemDetail
+// @Failure      404  {object}  problemDetail
+// @Failure      500  {object}  problemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/assets/{id}/embed-tokens [post]
+func (h *Handler) createEmbedToken(w http.ResponseWriter, r *http.Request) {
+	user := GetUser(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, errAuthRequired)
+		return
+	}
+
+	assetID := r.PathValue(pathKeyID)
+	asset, err := h.deps.AssetStore.Get(r.Context(), assetID)
+	if err != nil || asset.DeletedAt != nil {
+		writeError(w, http.StatusNotFound, errAssetNotFound)
+		return
+	}
+	if !h.access.CanManage(asset.OwnerID, user) {
+		writeError(w, http.StatusForbidden, "only the owner can embed this asset")
+		return
+	}
+
+	var req createEmbedTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	ttl, err := h.embedTTL(req.ExpiresIn)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	version := req.Version
+	switch {
+	case version < 0:
+		writeError(w, http.StatusBadRequest, "invalid version number")
+		return
+	case version == 0:
+		version = asset.CurrentVersion
+	}
+	if _, err := h.deps.VersionStore.GetByVersion(r.Context(), assetID, version); err != nil {
+		writeError(w, http.StatusNotFound, "version not found")
+		return
+	}
+
+	token, claims, err := h.deps.Embed.Mint(assetID, version, user.Email, ttl)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create embed token")
+		return
+	}
+
+	embedURL := embedPathPrefix + token
+	if h.deps.PublicBaseURL != "" {
+		embedURL = h.deps.PublicBaseURL + embedURL
+	}
+	writeJSON(w, http.StatusCreated, embedTokenResponse{
+		Token:     token,
+		EmbedURL:  embedURL,
+		AssetID:   assetID,
+		Version:   version,
+		ExpiresAt: claims.Expiry(),
+	})
+}
+
+// embedTTL parses a requested token lifetime. Empty means the signer's
+// default, which Mint applies.
+func (h *Handler) embedTTL(expiresIn string) (time.Duration, error) {
+	if expiresIn == "" {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(expiresIn)
+	if err != nil || ttl <= 0 {
+		return 0, errors.New("expires_in must be a positive duration such as \"2h\"")
+	}
+	if limit := h.deps.Embed.MaxTTL(); ttl > limit {
+		return 0, errors.New("expires_in may not exceed " + limit.String())
+	}
+	return ttl, nil
+}
+
+// embedView handles GET /portal/embed/{token}: the asset viewer without its
+// chrome, frameable only by the configured ancestors.
+func (h *Handler) embedView(w http.ResponseWriter, r *http.Request) {
+	claims, asset, ok := h.resolveEmbed(w, r)
+	if !ok {
+		return
+	}
+	pad, err := h.loadPublicAsset(r, asset)
+	if err != nil {
+		writePublicError(w, err)
+		return
+	}
+
+	data := h.assetViewerData(pad, embedPathPrefix+r.PathValue(pathKeyToken)+"/content")
+	data["Embedded"] = true
+
+	h.recordEmbedView(r, claims)
+
+	w.Header().Set("Content-Security-Policy", publicviewer.EmbedCSP(h.deps.Embed.FrameAncestors()))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set(headerContentType, "text/html; charset=utf-8")
+	_ = publicviewer.AssetTemplate.Execute(w, data)
+}
+
+// embedContent handles GET /portal/embed/{token}/content: the raw bytes of
+// the embedded version, for binary assets the page renders by URL.
+func (h *Handler) embedContent(w http.ResponseWriter, r *http.Request) {
+	_, asset, ok := h.resolveEmbed(w, r)
+	if !ok {
+		return
+	}
+	data, err := h.publicObject(r, asset)
+	if err != nil {
+		writePublicError(w, err)
+		return
+	}
+	blobserve.Serve(w, r, blobserve.Options{
+		Name:        asset.Name,
+		ContentType: asset.ContentType,
+		ModTime:     asset.UpdatedAt,
+		Data:        data,
+	})
+}
+
+// resolveEmbed verifies the path's token and returns the asset as it stood at
+// the token's version: the version's object, type, and size over the live
+// asset's name and description. It writes the error response itself.
+func (h *Handler) resolveEmbed(w http.ResponseWriter, r *http.Request) (embedtoken.Claims, *Asset, bool) {
+	claims, err := h.deps.Embed.Verify(r.PathValue(pathKeyToken))
+	if errors.Is(err, embedtoken.ErrExpired) {
+		http.Error(w, "This embed link has expired.", http.StatusGone)
+		return claims, nil, false
+	}
+	if err != nil {
+		http.Error(w, "Embed not found.", http.StatusNotFound)
+		return claims, nil, false
+	}
+
+	live, err := h.livePublicAsset(r, claims.AssetID)
+	if err != nil {
+		writePublicError(w, err)
+		return claims, nil, false
+	}
+	ver, err := h.deps.VersionStore.GetByVersion(r.Context(), claims.AssetID, claims.Version)
+	if err != nil {
+		http.Error(w, "This version is no longer available.", http.StatusGone)
+		return claims, nil, false
+	}
+
+	asset := *live
+	asset.S3Bucket = ver.S3Bucket
+	asset.S3Key = ver.S3Key
+	asset.ContentType = cmp.Or(ver.ContentType, live.ContentType)
+	asset.SizeBytes = ver.SizeBytes
+	asset.CurrentVersion = ver.Version
+	asset.UpdatedAt = ver.CreatedAt
+	return claims, &asset, true
+}
+
+// recordEmbedView writes one embed_view event. Like every audit write off a
+// request path, a failure is logged and never fails the page.
+func (h *Handler) recordEmbedView(r *http.Request, c embedtoken.Claims) {
+	if h.deps.EmbedAudit == nil {
+		return
+	}
+	params := map[string]any{
+		"asset_id":  c.AssetID,
+		"version":   c.Version,
+		"embed_id":  c.ID,
+		"issued_by": c.IssuedBy,
+	}
+	if origin := referrerOrigin(r); origin != "" {
+		params["referrer_origin"] = origin
+	}
+	event := middleware.AuditEvent{
+		Timestamp:  time.Now().UTC(),
+		ToolName:   "portal_embed_view",
+		Transport:  "http",
+		Source:     "portal",
+		Parameters: params,
+		Success:    true,
+		Authorized: true,
+		EventKind:  string(audit.EventTypeEmbedView),
+	}
+	if err := h.deps.EmbedAudit.Log(context.WithoutCancel(r.Context()), event); err != nil {
+		slog.Warn("portal embed: recording the audit event failed", "embed_id", c.ID, "error", err) // #nosec G706 -- structured log
+	}
+}
+
+// referrerOrigin returns the scheme and host of the page that framed the
+// embed, or "" when the browser sent no usable referrer. Only the origin is
+// kept: the rest of a wiki page's URL is the embedding site's business.
+func referrerOrigin(r *http.Request) string {
+	u, err := url.Parse(r.Referer())
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
@@ -0,0 +1,225 @@
+package portal
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/pkg/audit"
+	mw "github.com/txn2/mcp-data-platform/pkg/middleware"
+	"github.com/txn2/mcp-data-platform/pkg/portal/embedtoken"
+)
+
+// This file drives embed tokens through the assembled portal handler: minting
+// on the authenticated API, then the chrome-less viewer and its content on
+// the public mux.
+
+var errVersionMissing = errors.New("version not found")
+
+type recordingAuditLogger struct {
+	mu     sync.Mutex
+	events []mw.AuditEvent
+}
+
+func (l *recordingAuditLogger) Log(_ context.Context, e mw.AuditEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, e)
+	return nil
+}
+
+type embedFixture struct {
+	h        *Handler
+	assets   *mockAssetStore
+	versions *mockVersionStore
+	s3       *mockS3Client
+	signer   *embedtoken.Signer
+	audit    *recordingAuditLogger
+}
+
+func newEmbedFixture(t *testing.T, user *User) *embedFixture {
+	t.Helper()
+	signer, err := embedtoken.New(embedtoken.Config{
+		Enabled:        true,
+		SigningKey:     base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef")),
+		MaxTTL:         4 * time.Hour,
+		FrameAncestors: []string{"https://wiki.example.com"},
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	f := &embedFixture{
+		assets: &mockAssetStore{getAsset: &Asset{
+			ID: "ast_1", OwnerID: "u_owner", Name: "Revenue", ContentType: "text/markdown",
+			S3Bucket: "b", S3Key: "v3", CurrentVersion: 3, CreatedAt: now, UpdatedAt: now,
+		}},
+		versions: &mockVersionStore{getVersion: &AssetVersion{
+			AssetID: "ast_1", Version: 2, S3Bucket: "b", S3Key: "v2", ContentType: "text/markdown",
+			SizeBytes: 9, CreatedAt: now.Add(-time.Hour),
+		}},
+		s3:     &mockS3Client{getData: []byte("# Revenue"), getCT: "text/markdown"},
+		signer: signer,
+		audit:  &recordingAuditLogger{},
+	}
+	f.h = NewHandler(Deps{
+		AssetStore:    f.assets,
+		ShareStore:    &mockShareStore{},
+		VersionStore:  f.versions,
+		S3Client:      f.s3,
+		PublicBaseURL: "https://example.com",
+		RateLimit:     RateLimitConfig{RequestsPerMinute: 600, BurstSize: 100},
+		Embed:         signer,
+		EmbedAudit:    f.audit,
+	}, testAuthMiddleware(user))
+	return f
+}
+
+func (f *embedFixture) mint(t *testing.T, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/portal/assets/ast_1/embed-tokens", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	f.h.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateEmbedToken(t *testing.T) {
+	owner := &User{UserID: "u_owner", Email: "owner@example.com"}
+
+	t.Run("owner mints a token for a pinned version", func(t *testing.T) {
+		f := newEmbedFixture(t, owner)
+		w := f.mint(t, `{"version":2,"expires_in":"2h"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var resp embedTokenResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "https://example.com/portal/embed/"+resp.Token, resp.EmbedURL)
+		assert.Equal(t, 2, resp.Version)
+		assert.WithinDuration(t, time.Now().Add(2*time.Hour), resp.ExpiresAt, time.Minute)
+
+		claims, err := f.signer.Verify(resp.Token)
+		require.NoError(t, err)
+		assert.Equal(t, "ast_1", claims.AssetID)
+		assert.Equal(t, 2, claims.Version)
+		assert.Equal(t, "owner@example.com", claims.IssuedBy)
+	})
+
+	t.Run("no version means the current one", func(t *testing.T) {
+		f := newEmbedFixture(t, owner)
+		w := f.mint(t, `{}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var resp embedTokenResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, 3, resp.Version)
+	})
+
+	t.Run("refusals", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			user   *User
+			setup  func(*embedFixture)
+			body   string
+			status int
+		}{
+			{"anonymous", nil, nil, `{}`, http.StatusUnauthorized},
+			{"not the owner", &User{UserID: "u_other", Email: "other@example.com"}, nil, `{}`, http.StatusForbidden},
+			{"deleted asset", owner, func(f *embedFixture) {
+				now := time.Now()
+				f.assets.getAsset.DeletedAt = &now
+			}, `{}`, http.StatusNotFound},
+			{"unknown version", owner, func(f *embedFixture) { f.versions.getErr = errVersionMissing }, `{"version":9}`, http.StatusNotFound},
+			{"ttl over the cap", owner, nil, `{"expires_in":"5h"}`, http.StatusBadRequest},
+			{"bad ttl", owner, nil, `{"expires_in":"soon"}`, http.StatusBadRequest},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				f := newEmbedFixture(t, tt.user)
+				if tt.setup != nil {
+					tt.setup(f)
+				}
+				assert.Equal(t, tt.status, f.mint(t, tt.body).Code)
+			})
+		}
+	})
+
+	t.Run("disabled registers no route", func(t *testing.T) {
+		h := newTestHandlerWithVersions(&mockAssetStore{}, &mockShareStore{}, &mockVersionStore{}, &mockS3Client{}, owner)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/portal/assets/ast_1/embed-tokens", strings.NewReader(`{}`))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestEmbedView(t *testing.T) {
+	f := newEmbedFixture(t, nil)
+	token, claims, err := f.signer.Mint("ast_1", 2, "owner@example.com", 0)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/portal/embed/"+token, http.NoBody)
+	req.Header.Set("Referer", "https://wiki.example.com/spaces/FIN/pages/42?x=1")
+	w := httptest.NewRecorder()
+	f.h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	csp := w.Header().Get("Content-Security-Policy")
+	assert.Contains(t, csp, "frame-ancestors https://wiki.example.com;")
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	body := w.Body.String()
+	assert.Contains(t, body, "/portal/embed/"+token+"/content")
+	assert.Equal(t, "v2", f.s3.getKey, "the pinned version is served, not the current one")
+
+	require.Len(t, f.audit.events, 1)
+	ev := f.audit.events[0]
+	assert.Equal(t, string(audit.EventTypeEmbedView), ev.EventKind)
+	assert.Empty(t, ev.UserID)
+	assert.Equal(t, map[string]any{
+		"asset_id":        "ast_1",
+		"version":         2,
+		"embed_id":        claims.ID,
+		"issued_by":       "owner@example.com",
+		"referrer_origin": "https://wiki.example.com",
+	}, ev.Parameters)
+
+	t.Run("content", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/portal/embed/"+token+"/content", http.NoBody)
+		w := httptest.NewRecorder()
+		f.h.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "# Revenue", w.Body.String())
+		assert.Len(t, f.audit.events, 1, "content fetches are not views")
+	})
+}
+
+func TestEmbedViewRefusals(t *testing.T) {
+	f := newEmbedFixture(t, nil)
+	token, _, err := f.signer.Mint("ast_1", 2, "owner@example.com", 0)
+	require.NoError(t, err)
+
+	get := func(path string) int {
+		w := httptest.NewRecorder()
+		f.h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNotFound, get("/portal/embed/not-a-token"))
+	assert.Equal(t, http.StatusNotFound, get("/portal/embed/"+token[:len(token)-2]+"xx"), "a forged signature")
+
+	f.versions.getErr = errVersionMissing
+	assert.Equal(t, http.StatusGone, get("/portal/embed/"+token), "a pruned version")
+	f.versions.getErr = nil
+
+	now := time.Now()
+	f.assets.getAsset.DeletedAt = &now
+	assert.Equal(t, http.StatusGone, get("/portal/embed/"+token+"/content"))
+	assert.Empty(t, f.audit.events)
+}
@@ -0,0 +1,285 @@
+// Package embedtoken mints and verifies the signed URLs that embed one portal
+// asset version in another site's page: an internal wiki, a BI tool, anything
+// that can render an iframe.
+//
+// An embed token is a bearer credential like a public share link, but narrower
+// in every direction a share is not: it names one asset at one version, it
+// lives minutes to hours rather than until revoked, and the page it opens may
+// only be framed by the origins the operator lists. Nothing is stored per
+// token. The token carries its own claims under an HMAC, and verification
+// resolves the key by id through a signkey.Ring, so the signing key rotates
+// the way the OAuth server's does: add the new key as a previous key
+// everywhere, promote it, then drop the old one once its tokens have expired.
+package embedtoken
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/pkg/oauth/signkey"
+)
+
+// Token lifetimes.
+const (
+	// DefaultTTL is the lifetime of a token minted without one.
+	DefaultTTL = time.Hour
+	// DefaultMaxTTL is the longest lifetime a mint may ask for when the
+	// operator sets no max_ttl.
+	DefaultMaxTTL = 24 * time.Hour
+)
+
+// minKeyBytes is the shortest signing key accepted, matching the OAuth
+// server's floor for its HS256 keys.
+const minKeyBytes = 32
+
+// signLabel is prefixed to the signed input. It keeps an embed signature from
+// ever verifying as anything else a deployment signs, should an operator
+// reuse one key for two purposes.
+const signLabel = "portal-embed-v1"
+
+// Verification errors.
+var (
+	ErrMalformed = errors.New("malformed embed token")
+	ErrSignature = errors.New("embed token signature is invalid")
+	ErrExpired   = errors.New("embed token has expired")
+)
+
+// Config is the portal.embed block.
+type Config struct {
+	// Enabled turns embed tokens on. Off by default: an embed is content
+	// leaving the portal, so a deployment opts in.
+	Enabled bool `yaml:"enabled"`
+	// SigningKey is the base64-encoded HMAC key new tokens are signed with.
+	SigningKey string `yaml:"signing_key"`
+	// PreviousSigningKeys are base64-encoded keys retained to verify tokens
+	// minted before a rotation. A key can be dropped once max_ttl has passed
+	// since it last signed.
+	PreviousSigningKeys []string `yaml:"previous_signing_keys"`
+	// TTL is the lifetime of a token minted without one. Zero means
+	// DefaultTTL.
+	TTL time.Duration `yaml:"ttl"`
+	// MaxTTL caps the lifetime a mint may ask for. Zero means DefaultMaxTTL.
+	MaxTTL time.Duration `yaml:"max_ttl"`
+	// FrameAncestors are the origins allowed to frame an embed, as CSP
+	// frame-ancestors sources: 'self', or a scheme and host with an optional
+	// port and a leading "*." wildcard label. Required when enabled.
+	FrameAncestors []string `yaml:"frame_ancestors"`
+}
+
+// Errors reports what makes the block unusable, one message per problem, or
+// nil when it is disabled or sound.
+func (c Config) Errors() []string {
+	if !c.Enabled {
+		return nil
+	}
+	var errs []string
+	if c.SigningKey == "" {
+		errs = append(errs, "portal.embed.signing_key is required when embeds are enabled")
+	} else if _, err := decodeKey(c.SigningKey); err != nil {
+		errs = append(errs, "portal.embed.signing_key: "+err.Error())
+	}
+	for i, k := range c.PreviousSigningKeys {
+		if _, err := decodeKey(k); err != nil {
+			errs = append(errs, fmt.Sprintf("portal.embed.previous_signing_keys[%d]: %v", i, err))
+		}
+	}
+	if c.TTL < 0 || c.MaxTTL < 0 {
+		errs = append(errs, "portal.embed.ttl and portal.embed.max_ttl must not be negative")
+	} else if c.ttl() > c.maxTTL() {
+		errs = append(errs, fmt.Sprintf("portal.embed.ttl (%s) exceeds portal.embed.max_ttl (%s)", c.ttl(), c.maxTTL()))
+	}
+	if len(c.FrameAncestors) == 0 {
+		errs = append(errs, "portal.embed.frame_ancestors must list at least one origin when embeds are enabled")
+	}
+	for _, a := range c.FrameAncestors {
+		if !validAncestor(a) {
+			errs = append(errs, fmt.Sprintf("portal.embed.frame_ancestors: %q is not 'self' or a scheme://host[:port] origin", a))
+		}
+	}
+	return errs
+}
+
+func (c Config) ttl() time.Duration {
+	if c.TTL == 0 {
+		return DefaultTTL
+	}
+	return c.TTL
+}
+
+func (c Config) maxTTL() time.Duration {
+	if c.MaxTTL == 0 {
+		return DefaultMaxTTL
+	}
+	return c.MaxTTL
+}
+
+// decodeKey decodes a base64 signing key and enforces minKeyBytes.
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decoding signing key: %w", err)
+	}
+	if len(key) < minKeyBytes {
+		return nil, fmt.Errorf("signing key must be at least %d bytes", minKeyBytes)
+	}
+	return key, nil
+}
+
+// validAncestor reports whether a is a frame-ancestors source this package
+// will emit: 'self', or an http(s) origin with nothing after the host and
+// port. Anything else either widens the policy ('*', a bare scheme) or would
+// let a value break out of the directive.
+func validAncestor(a string) bool {
+	if a == "'self'" {
+		return true
+	}
+	if strings.ContainsAny(a, " ;,'\"") {
+		return false
+	}
+	u, err := url.Parse(a)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return false
+	}
+	if u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return false
+	}
+	host := strings.TrimPrefix(u.Hostname(), "*.")
+	return host != "" && !strings.Contains(host, "*")
+}
+
+// Claims are what a token grants.
+type Claims struct {
+	// ID identifies the token in the audit log, so views trace to the mint.
+	ID string `json:"jti"`
+	// AssetID and Version name the one asset version the token opens.
+	AssetID string `json:"aid"`
+	Version int    `json:"ver"`
+	// IssuedBy is the email of the person who minted the token.
+	IssuedBy  string `json:"sub"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Expiry returns the token's expiry as a time.
+func (c Claims) Expiry() time.Time { return time.Unix(c.ExpiresAt, 0).UTC() }
+
+// Signer mints and verifies embed tokens. It is immutable after New and safe
+// for concurrent use.
+type Signer struct {
+	key       []byte
+	kid       string
+	ring      *signkey.Ring
+	ttl       time.Duration
+	maxTTL    time.Duration
+	ancestors string
+	now       func() time.Time
+}
+
+// New builds a Signer from the config block. It returns nil, nil when embeds
+// are disabled; callers nil-check to decide whether to offer them at all.
+func New(cfg Config) (*Signer, error) {
+	if !cfg.Enabled {
+		return nil, nil //nolint:nilnil // nil signer means embeds are disabled
+	}
+	if errs := cfg.Errors(); len(errs) > 0 {
+		return nil, fmt.Errorf("portal embed config: %s", strings.Join(errs, "; "))
+	}
+	key, _ := decodeKey(cfg.SigningKey) // validated by Errors
+	previous := make([][]byte, 0, len(cfg.PreviousSigningKeys))
+	for _, k := range cfg.PreviousSigningKeys {
+		p, _ := decodeKey(k) // validated by Errors
+		previous = append(previous, p)
+	}
+	return &Signer{
+		key:       key,
+		kid:       signkey.KeyID(key),
+		ring:      signkey.NewRing(key, previous),
+		ttl:       cfg.ttl(),
+		maxTTL:    cfg.maxTTL(),
+		ancestors: strings.Join(cfg.FrameAncestors, " "),
+		now:       time.Now,
+	}, nil
+}
+
+// MaxTTL returns the longest lifetime Mint accepts.
+func (s *Signer) MaxTTL() time.Duration { return s.maxTTL }
+
+// FrameAncestors returns the CSP directive that limits who may frame an embed.
+func (s *Signer) FrameAncestors() string { return "frame-ancestors " + s.ancestors }
+
+// Mint signs a token for one asset version. A zero ttl takes the configured
+// default; the caller bounds a requested one by MaxTTL first.
+func (s *Signer) Mint(assetID string, version int, issuedBy string, ttl time.Duration) (string, Claims, error) {
+	if ttl <= 0 {
+		ttl = s.ttl
+	}
+	if ttl > s.maxTTL {
+		ttl = s.maxTTL
+	}
+	var id [12]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", Claims{}, fmt.Errorf("generating embed token id: %w", err)
+	}
+	now := s.now()
+	c := Claims{
+		ID:        "emb_" + hex.EncodeToString(id[:]),
+		AssetID:   assetID,
+		Version:   version,
+		IssuedBy:  issuedBy,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	}
+	body, err := json.Marshal(c)
+	if err != nil {
+		return "", Claims{}, fmt.Errorf("encoding embed token: %w", err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(body)
+	sig := sign(s.key, s.kid, payload)
+	return s.kid + "." + payload + "." + sig, c, nil
+}
+
+// Verify checks a token's signature and expiry and returns its claims. A
+// token whose key id is not in the ring was signed by a retired key and is
+// refused like a forged one.
+func (s *Signer) Verify(token string) (Claims, error) {
+	kid, rest, ok := strings.Cut(token, ".")
+	if !ok {
+		return Claims{}, ErrMalformed
+	}
+	payload, sig, ok := strings.Cut(rest, ".")
+	if !ok || payload == "" || sig == "" {
+		return Claims{}, ErrMalformed
+	}
+	key, ok := s.ring.VerificationKey(kid)
+	if !ok || !hmac.Equal([]byte(sig), []byte(sign(key, kid, payload))) {
+		return Claims{}, ErrSignature
+	}
+	body, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return Claims{}, ErrMalformed
+	}
+	var c Claims
+	if err := json.Unmarshal(body, &c); err != nil || c.AssetID == "" || c.Version < 1 {
+		return Claims{}, ErrMalformed
+	}
+	if !s.now().Before(c.Expiry()) {
+		return Claims{}, ErrExpired
+	}
+	return c, nil
+}
+
+// sign returns the base64url HMAC-SHA256 of the labelled signing input.
+func sign(key []byte, kid, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(signLabel + "." + kid + "." + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,152 @@
+package embedtoken
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testKey  = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	otherKey = base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210"))
+)
+
+func testConfig() Config {
+	return Config{
+		Enabled:        true,
+		SigningKey:     testKey,
+		FrameAncestors: []string{"https://wiki.example.com"},
+	}
+}
+
+func newTestSigner(t *testing.T, cfg Config, now time.Time) *Signer {
+	t.Helper()
+	s, err := New(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestConfigErrors(t *testing.T) {
+	assert.Nil(t, Config{}.Errors(), "a disabled block is never checked")
+	assert.Empty(t, testConfig().Errors())
+
+	tests := []struct {
+		name   string
+		mutate func(*Config)
+		want   string
+	}{
+		{"no key", func(c *Config) { c.SigningKey = "" }, "signing_key is required"},
+		{"short key", func(c *Config) { c.SigningKey = base64.StdEncoding.EncodeToString([]byte("short")) }, "at least 32 bytes"},
+		{"bad previous", func(c *Config) { c.PreviousSigningKeys = []string{"%%"} }, "previous_signing_keys[0]"},
+		{"negative ttl", func(c *Config) { c.TTL = -time.Minute }, "must not be negative"},
+		{"ttl over max", func(c *Config) { c.TTL = 2 * time.Hour; c.MaxTTL = time.Hour }, "exceeds portal.embed.max_ttl"},
+		{"no ancestors", func(c *Config) { c.FrameAncestors = nil }, "at least one origin"},
+		{"wildcard", func(c *Config) { c.FrameAncestors = []string{"*"} }, `"*"`},
+		{"directive break", func(c *Config) { c.FrameAncestors = []string{"https://a.example.com; script-src *"} }, "script-src"},
+		{"path", func(c *Config) { c.FrameAncestors = []string{"https://wiki.example.com/page"} }, "/page"},
+		{"none", func(c *Config) { c.FrameAncestors = []string{"'none'"} }, "'none'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			tt.mutate(&cfg)
+			errs := cfg.Errors()
+			require.Len(t, errs, 1)
+			assert.Contains(t, errs[0], tt.want)
+		})
+	}
+
+	cfg := testConfig()
+	cfg.FrameAncestors = []string{"'self'", "https://*.example.com", "http://localhost:8080"}
+	assert.Empty(t, cfg.Errors())
+}
+
+func TestNew(t *testing.T) {
+	s, err := New(Config{SigningKey: testKey})
+	require.NoError(t, err)
+	assert.Nil(t, s, "disabled yields no signer")
+
+	_, err = New(Config{Enabled: true})
+	require.Error(t, err)
+
+	cfg := testConfig()
+	cfg.FrameAncestors = []string{"'self'", "https://wiki.example.com"}
+	s = newTestSigner(t, cfg, time.Now())
+	assert.Equal(t, "frame-ancestors 'self' https://wiki.example.com", s.FrameAncestors())
+	assert.Equal(t, DefaultMaxTTL, s.MaxTTL())
+}
+
+func TestMintVerify(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestSigner(t, testConfig(), now)
+
+	token, claims, err := s.Mint("ast_1", 3, "alice@example.com", 0)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(claims.ID, "emb_"))
+	assert.Equal(t, now.Add(DefaultTTL), claims.Expiry(), "zero ttl takes the default")
+
+	got, err := s.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, claims, got)
+	assert.Equal(t, "ast_1", got.AssetID)
+	assert.Equal(t, 3, got.Version)
+	assert.Equal(t, "alice@example.com", got.IssuedBy)
+
+	_, long, err := s.Mint("ast_1", 3, "alice@example.com", 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(DefaultMaxTTL), long.Expiry(), "a ttl over the cap is clamped")
+
+	t.Run("expired", func(t *testing.T) {
+		s.now = func() time.Time { return now.Add(DefaultTTL) }
+		defer func() { s.now = func() time.Time { return now } }()
+		_, err := s.Verify(token)
+		assert.ErrorIs(t, err, ErrExpired)
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		parts := strings.Split(token, ".")
+		forged := `{"jti":"x","aid":"ast_2","ver":1,"sub":"a","iat":0,"exp":9999999999}`
+		parts[1] = base64.RawURLEncoding.EncodeToString([]byte(forged))
+		_, err := s.Verify(strings.Join(parts, "."))
+		assert.ErrorIs(t, err, ErrSignature)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, bad := range []string{"", "abc", "a.b", "a..c"} {
+			_, err := s.Verify(bad)
+			assert.ErrorIs(t, err, ErrMalformed, bad)
+		}
+	})
+}
+
+func TestKeyRotation(t *testing.T) {
+	now := time.Now()
+	old := newTestSigner(t, testConfig(), now)
+	token, _, err := old.Mint("ast_1", 1, "alice@example.com", 0)
+	require.NoError(t, err)
+
+	// The new key signs; the old one still verifies what it signed.
+	rotated := testConfig()
+	rotated.SigningKey = otherKey
+	rotated.PreviousSigningKeys = []string{testKey}
+	s := newTestSigner(t, rotated, now)
+	_, err = s.Verify(token)
+	require.NoError(t, err)
+	fresh, _, err := s.Mint("ast_1", 1, "alice@example.com", 0)
+	require.NoError(t, err)
+	_, err = old.Verify(fresh)
+	assert.ErrorIs(t, err, ErrSignature, "a key the ring does not hold verifies nothing")
+
+	// Once the old key is dropped its tokens stop working.
+	retired := testConfig()
+	retired.SigningKey = otherKey
+	s = newTestSigner(t, retired, now)
+	_, err = s.Verify(token)
+	assert.ErrorIs(t, err, ErrSignature)
+}
@@ -4,6 +4,7 @@ import (
 	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -24,10 +25,16 @@ import (
 	"github.com/txn2/mcp-data-platform/internal/portal/viewerlimit"
 	"github.com/txn2/mcp-data-platform/pkg/audit"
 	"github.com/txn2/mcp-data-platform/pkg/blobserve"
+	"github.com/txn2/mcp-data-platform/pkg/contenttype"
 	"github.com/txn2/mcp-data-platform/pkg/embedding"
 	"github.com/txn2/mcp-data-platform/pkg/memory"
+	"github.com/txn2/mcp-data-platform/pkg/middleware"
+	"github.com/txn2/mcp-data-platform/pkg/portal/embedtoken"
 	"github.com/txn2/mcp-data-platform/pkg/portal/knowledgepage"
 	"github.com/txn2/mcp-data-platform/pkg/portal/shareguest"
+	"github.com/txn2/mcp-data-platform/pkg/portal/signoff"
+	"github.com/txn2/mcp-data-platform/pkg/portal/spaces"
+	"github.com/txn2/mcp-data-platform/pkg/portal/threads"
 	"github.com/txn2/mcp-data-platform/pkg/ratelimit"
 	"github.com/txn2/mcp-data-platform/pkg/toolkits/knowledge"
 )
@@ -134,6 +141,13 @@ type Deps struct {
 	CollectionStore    CollectionStore
 	ThreadStore        ThreadStore
 	KnowledgePageStore knowledgepage.Store
+	// SignoffStore holds collection approval policies and the approval
+	// record; nil leaves the approval routes unregistered.
+	SignoffStore signoff.Store
+	// SpaceStore holds team spaces, whose members hold a role on every
+	// collection a space owns; nil leaves the space routes unregistered and
+	// grants nothing through spaces.
+	SpaceStore spaces.Store
 	// RestoreBuiltinPages un-hides the operator-hidden built-in knowledge pages
 	// and reconciles them to the running release, returning how many came back
 	// (#1390). Wired by the composition root (the reconcile lives in an
@@ -225,6 +239,12 @@ type Deps struct {
 	// denial pages, one-time view links, and guest sessions. nil keeps the
 	// pre-#1001 plain-text denials and registers no guest routes.
 	ShareGuest *shareguest.Service
+	// Embed signs and verifies the tokens that frame one asset version in
+	// another site. nil (embeds disabled) registers no embed routes.
+	Embed *embedtoken.Signer
+	// EmbedAudit records embed page loads. nil (audit disabled) serves
+	// embeds unrecorded.
+	EmbedAudit middleware.AuditLogger
 	// NotificationRegistrar, when set, registers the self-scoped
 	// notification-preference REST routes onto the portal's authenticated
 	// mux (the DataHubRegistrar pattern): the feature lives with the
@@ -278,6 +298,7 @@ func newAccessChecker(deps Deps) *access.Checker {
 		Collections: deps.CollectionStore,
 		Shares:      deps.ShareStore,
 		Prompts:     deps.PromptStore,
+		Spaces:      deps.SpaceStore,
 		AdminRoles:  deps.AdminRoles,
 	}
 	if deps.PersonaResolver != nil {
@@ -308,6 +329,10 @@ func (h *Handler) feedbackConfig() feedbackapi.Config {
 		Mentions:       h.deps.MentionResolver,
 		Notifier:       h.deps.Notifier,
 		Access:         h.access,
+		Signoff:        h.deps.SignoffStore,
+	}
+	if h.deps.S3Client != nil || h.deps.KnowledgePageStore != nil {
+		cfg.Content = h.threadTargetContent
 	}
 	if h.deps.PersonaResolver != nil {
 		cfg.PersonaName = func(roles []string) string {
@@ -320,6 +345,36 @@ func (h *Handler) feedbackConfig() feedbackapi.Config {
 	return cfg
 }
 
+// threadTargetContent reads the current body of a thread's asset or knowledge
+// page, for the feedback seam to pin and carry document anchors against. A
+// deleted target, a binary asset, or one the deployment cannot read is an
+// error, and its anchors stay as they are.
+func (h *Handler) threadTargetContent(ctx context.Context, targetType, targetID string) (threads.TargetContent, error) {
+	switch {
+	case targetType == targetTypeAsset && h.deps.S3Client != nil:
+		asset, err := h.deps.AssetStore.Get(ctx, targetID)
+		if err != nil || asset.DeletedAt != nil {
+			return threads.TargetContent{}, errors.New(errAssetNotFound)
+		}
+		if !contenttype.IsTextual(asset.ContentType) {
+			return threads.TargetContent{}, fmt.Errorf("asset content %q is not text", asset.ContentType)
+		}
+		data, _, err := h.deps.S3Client.GetObject(ctx, asset.S3Bucket, asset.S3Key)
+		if err != nil {
+			return threads.TargetContent{}, fmt.Errorf("reading asset content: %w", err)
+		}
+		return threads.TargetContent{Body: string(data), ContentType: asset.ContentType, Version: asset.CurrentVersion}, nil
+	case targetType == targetTypeKnowledgePage && h.deps.KnowledgePageStore != nil:
+		page, err := h.deps.KnowledgePageStore.Get(ctx, targetID)
+		if err != nil {
+			return threads.TargetContent{}, fmt.Errorf("reading knowledge page: %w", err)
+		}
+		return threads.TargetContent{Body: page.Body, ContentType: contenttype.Markdown, Version: page.CurrentVersion}, nil
+	default:
+		return threads.TargetContent{}, fmt.Errorf("no readable content for a %s thread", targetType)
+	}
+}
+
 // ServeHTTP implements http.Handler.
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// The share viewer's own chunks. This is checked ahead of the share
@@ -345,7 +400,7 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.viewerAssets.ServeHTTP(w, r)
 		return
 	}
-	if strings.HasPrefix(r.URL.Path, "/portal/view/") {
+	if strings.HasPrefix(r.URL.Path, "/portal/view/") || strings.HasPrefix(r.URL.Path, embedPathPrefix) {
 		h.publicMux.ServeHTTP(w, r)
 		return
 	}
@@ -375,11 +430,13 @@ func (h *Handler) registerRoutes() {
 	h.mux.HandleFunc("PUT /api/v1/portal/assets/{id}", h.updateAsset)
 	h.mux.HandleFunc("DELETE /api/v1/portal/assets/{id}", h.deleteAsset)
 	h.mux.HandleFunc("GET /api/v1/portal/assets/{id}/versions", h.listVersions)
+	h.mux.HandleFunc("GET /api/v1/portal/assets/{id}/versions/diff", h.diffVersions)
 	h.mux.HandleFunc("GET /api/v1/portal/assets/{id}/versions/{version}/content", h.getVersionContent)
 	h.mux.HandleFunc("POST /api/v1/portal/assets/{id}/versions/{version}/revert", h.revertToVersion)
 	h.mux.HandleFunc("POST /api/v1/portal/assets/{id}/shares", h.createShare)
 	h.mux.HandleFunc("GET /api/v1/portal/assets/{id}/shares", h.listShares)
 	h.mux.HandleFunc("DELETE /api/v1/portal/shares/{id}", h.revokeShare)
+	h.mux.HandleFunc("GET /api/v1/portal/shares/{id}/access-log", h.getShareAccessLog)
 	h.mux.HandleFunc("GET /api/v1/portal/shared-with-me", h.listSharedWithMe)
 	h.mux.HandleFunc("POST /api/v1/portal/assets/{id}/copy", h.copyAsset)
 
@@ -423,12 +480,16 @@ func (h *Handler) registerRoutes() {
 	feedback := feedbackapi.New(h.feedbackConfig())
 	feedback.Register(h.mux)
 	feedback.RegisterInsightCapture(h.mux)
+	feedback.RegisterApprovals(h.mux)
 
 	// The caller's own sessions: the individual runs behind the activity
 	// dashboard's aggregates, each openable.
 	h.registerSessionRoutes()
 	h.registerCallRoutes()
 
+	// Team spaces: collections governed as a unit by a named group.
+	h.registerSpaceRoutes()
+
 	// Activity routes (user-scoped audit metrics)
 	if h.deps.AuditMetrics != nil {
 		h.mux.HandleFunc("GET /api/v1/portal/activity/overview", h.getActivityOverview)
@@ -474,7 +535,10 @@ func (h *Handler) registerRoutes() {
 			h.rateLimiter.Middleware(http.HandlerFunc(h.deps.ShareGuest.HandleClaim)))
 		h.publicMux.Handle("POST /portal/view/{token}/resubscribe",
 			h.rateLimiter.Middleware(http.HandlerFunc(h.deps.ShareGuest.HandleResubscribe)))
+		h.publicMux.Handle("POST /portal/view/{token}/unlock",
+			h.rateLimiter.Middleware(http.HandlerFunc(h.deps.ShareGuest.HandleUnlock)))
 	}
+	h.registerEmbedRoutes()
 }
 
 // publicChain wraps a share-viewer handler in the rate limiter and the share
@@ -535,6 +599,9 @@ func (h *Handler) getMe(w http.ResponseWriter, r *http.Request) {
 			resp.Tools = info.Tools
 		}
 	}
+	// /me is the SPA's first call of a visit, so the caller's group claims
+	// are recorded here for space-member listings.
+	h.observeSpaceGroups(user)
 
 	writeJSON(w, http.StatusOK, resp)
 }
@@ -1328,11 +1395,12 @@ func (h *Handler) getVersionContent(w http.ResponseWriter, r *http.Request) {
 // revertToVersion handles POST /api/v1/portal/assets/{id}/versions/{version}/revert.
 //
 // @Summary      Revert to version
-// @Description  Reverts the asset content to a specific version by creating a new version with that content.
+// @Description  Reverts the asset content to a specific version by creating a new version with that content. The new version's change summary is the X-Change-Summary header, or "Reverted from vN" without one.
 // @Tags         Assets
 // @Produce      json
-// @Param        id       path  string   true  "Asset ID"
-// @Param        version  path  integer  true  "Version number to revert to"
+// @Param        id                path    string   true   "Asset ID"
+// @Param        version           path    integer  true   "Version number to revert to"
+// @Param        X-Change-Summary  header  string   false  "Change summary for the new version"
 // @Success      200  {object}  map[string]any
 // @Failure      400  {object}  problemDetail
 // @Failure      401  {object}  problemDetail
@@ -1381,7 +1449,8 @@ func (h *Handler) revertToVersion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	assignedVersion, revertErr := h.revertContentToVersion(r.Context(), asset, id, targetVer, user.Email)
+	summary := changeSummaryFromHeader(r, fmt.Sprintf("Reverted from v%d", targetVer.Version))
+	assignedVersion, revertErr := h.revertContentToVersion(r.Context(), asset, id, targetVer, user.Email, summary)
 	if revertErr != nil {
 		writeError(w, revertErr.code, revertErr.msg)
 		return
@@ -1398,7 +1467,7 @@ type httpError struct {
 	msg  string
 }
 
-func (h *Handler) revertContentToVersion(ctx context.Context, asset *Asset, assetID string, targetVer *AssetVersion, createdBy string) (int, *httpError) {
+func (h *Handler) revertContentToVersion(ctx context.Context, asset *Asset, assetID string, targetVer *AssetVersion, createdBy, summary string) (int, *httpError) {
 	data, _, err := h.deps.S3Client.GetObject(ctx, targetVer.S3Bucket, targetVer.S3Key)
 	if err != nil {
 		return 0, &httpError{http.StatusInternalServerError, "failed to read version content"}
@@ -1420,7 +1489,7 @@ func (h *Handler) revertContentToVersion(ctx context.Context, asset *Asset, asse
 		ContentType:   targetVer.ContentType,
 		SizeBytes:     int64(len(data)),
 		CreatedBy:     createdBy,
-		ChangeSummary: fmt.Sprintf("Reverted from v%d", targetVer.Version),
+		ChangeSummary: summary,
 	}
 	assignedVersion, err := h.deps.VersionStore.CreateVersion(ctx, av)
 	if err != nil {
@@ -1463,6 +1532,13 @@ type createShareRequest struct {
 	// is reported rather than silently dropped; a note on a share that sends
 	// no email is accepted and then goes nowhere.
 	Message string `json:"message,omitempty" example:"Here's the Q3 revenue breakdown you asked about"`
+	// Passphrase, MaxViews, and VerifyEmail guard a public link
+	// (shareguest.GuardSpec): a passphrase to enter, a cap on admitted visits,
+	// and an emailed-code check of the visitor's address. Each is optional
+	// and each is rejected on a share that is not public.
+	Passphrase  string `json:"passphrase,omitempty" example:"correct horse battery"`
+	MaxViews    int    `json:"max_views,omitempty" example:"10"`
+	VerifyEmail bool   `json:"verify_email,omitempty" example:"false"`
 }
 
 // wantsNotify reports whether a share created from this request should notify
@@ -1541,11 +1617,20 @@ func (h *Handler) createShare(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, buildErr.Error())
 		return
 	}
+	guard := req.guardSpec()
+	if err := h.validateShareGuard(guard, &share); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	if err := h.deps.ShareStore.Insert(r.Context(), share); err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to create share")
 		return
 	}
+	if err := h.storeShareGuard(r.Context(), &share, guard); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create share")
+		return
+	}
 
 	if req.wantsNotify() {
 		h.notifyShare(r.Context(), &share, ShareEvent{
@@ -1670,36 +1755,9 @@ func (h *Handler) revokeShare(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	switch {
-	case share.PromptID != "":
-		if h.deps.PromptStore == nil {
-			writeError(w, http.StatusNotFound, "associated prompt not found")
-			return
-		}
-		pr, err := h.deps.PromptStore.GetByID(r.Context(), share.PromptID)
-		if err != nil || pr == nil {
-			writeError(w, http.StatusNotFound, "associated prompt not found")
-			return
-		}
-		if !h.access.CanManageEmail(pr.OwnerEmail, user) {
-			writeError(w, http.StatusForbidden, "only the owner can revoke this share")
-			return
-		}
-	case share.CollectionID != "":
-		if err := h.verifyCollectionManager(r.Context(), share.CollectionID, user); err != nil {
-			writeError(w, err.code, err.message)
-			return
-		}
-	default:
-		asset, err := h.deps.AssetStore.Get(r.Context(), share.AssetID)
-		if err != nil {
-			writeError(w, http.StatusNotFound, "associated asset not found")
-			return
-		}
-		if !h.access.CanManage(asset.OwnerID, user) {
-			writeError(w, http.StatusForbidden, "only the owner can revoke this share")
-			return
-		}
+	if err := h.verifyShareManager(r.Context(), share, user); err != nil {
+		writeError(w, err.code, err.message)
+		return
 	}
 
 	if err := h.deps.ShareStore.Revoke(r.Context(), shareID); err != nil {
@@ -1717,8 +1775,42 @@ type ownerError struct {
 
 func (e *ownerError) Error() string { return e.message }
 
+// errShareOwnerOnly is the 403 for share management by anyone but the owner
+// of the shared item or an admin.
+const errShareOwnerOnly = "only the owner can manage this share"
+
+// verifyShareManager reports that the caller may manage a share: revoke it or
+// read its access log. That is the owner of the shared prompt, collection, or
+// asset, or an admin.
+func (h *Handler) verifyShareManager(ctx context.Context, share *Share, user *User) *ownerError {
+	switch {
+	case share.PromptID != "":
+		if h.deps.PromptStore == nil {
+			return &ownerError{http.StatusNotFound, "associated prompt not found"}
+		}
+		pr, err := h.deps.PromptStore.GetByID(ctx, share.PromptID)
+		if err != nil || pr == nil {
+			return &ownerError{http.StatusNotFound, "associated prompt not found"}
+		}
+		if !h.access.CanManageEmail(pr.OwnerEmail, user) {
+			return &ownerError{http.StatusForbidden, errShareOwnerOnly}
+		}
+	case share.CollectionID != "":
+		return h.verifyCollectionManager(ctx, share.CollectionID, user)
+	default:
+		asset, err := h.deps.AssetStore.Get(ctx, share.AssetID)
+		if err != nil {
+			return &ownerError{http.StatusNotFound, "associated asset not found"}
+		}
+		if !h.access.CanManage(asset.OwnerID, user) {
+			return &ownerError{http.StatusForbidden, errShareOwnerOnly}
+		}
+	}
+	return nil
+}
+
 // verifyCollectionManager reports that the caller may exercise owner authority
-// over the collection a share targets. Revoking a share is a share-management
+// over the collection a share targets. Managing a share is a share-management
 // right, so an Editor on the collection is deliberately not admitted.
 func (h *Handler) verifyCollectionManager(ctx context.Context, collectionID string, user *User) *ownerError {
 	if h.deps.CollectionStore == nil {
@@ -1729,7 +1821,7 @@ func (h *Handler) verifyCollectionManager(ctx context.Context, collectionID stri
 		return &ownerError{http.StatusNotFound, "associated collection not found"}
 	}
 	if !h.access.CanManage(coll.OwnerID, user) {
-		return &ownerError{http.StatusForbidden, "only the owner can revoke this share"}
+		return &ownerError{http.StatusForbidden, errShareOwnerOnly}
 	}
 	return nil
 }
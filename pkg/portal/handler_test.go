@@ -88,6 +88,7 @@ type mockShareStore struct {
 	sharedWithTot  int
 	sharedWithErr  error
 	revokeErr      error
+	revokedIDs     []string
 	incrementErr   error
 	summaries      map[string]ShareSummary
 	summariesErr   error
@@ -125,7 +126,13 @@ func (m *mockShareStore) ListByAsset(_ context.Context, _ string) ([]Share, erro
 func (m *mockShareStore) ListSharedWithUser(_ context.Context, _, _ string, _, _ int) ([]SharedAsset, int, error) {
 	return m.sharedWithRes, m.sharedWithTot, m.sharedWithErr
 }
-func (m *mockShareStore) Revoke(_ context.Context, _ string) error          { return m.revokeErr }
+func (m *mockShareStore) Revoke(_ context.Context, id string) error {
+	if m.revokeErr == nil {
+		m.revokedIDs = append(m.revokedIDs, id)
+	}
+	return m.revokeErr
+}
+
 func (m *mockShareStore) IncrementAccess(_ context.Context, _ string) error { return m.incrementErr }
 func (m *mockShareStore) ListActiveShareSummaries(_ context.Context, _ []string) (map[string]ShareSummary, error) {
 	return m.summaries, m.summariesErr
@@ -3984,6 +3991,33 @@ func TestRevertToVersionSuccess(t *testing.T) {
 	assert.Equal(t, float64(3), result["version"])
 }
 
+func TestRevertToVersionRecordsTheChangeSummary(t *testing.T) {
+	asset := &Asset{ID: "a1", OwnerID: "u1", S3Bucket: "b", CurrentVersion: 2}
+	targetVer := &AssetVersion{ID: "v1", AssetID: "a1", Version: 1, S3Key: "k1", S3Bucket: "b", ContentType: "text/html"}
+	vs := &mockVersionStore{getVersion: targetVer, createVersion: 3}
+	h := newTestHandlerWithVersions(
+		&mockAssetStore{getAsset: asset},
+		&mockShareStore{},
+		vs,
+		&mockS3Client{getData: []byte("<html>v1</html>"), getCT: "text/html"},
+		&User{UserID: "u1"},
+	)
+
+	req := httptest.NewRequestWithContext(context.Background(), "POST", "/api/v1/portal/assets/a1/versions/1/revert", http.NoBody)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, vs.lastCreated)
+	assert.Equal(t, "Reverted from v1", vs.lastCreated.ChangeSummary)
+
+	req = httptest.NewRequestWithContext(context.Background(), "POST", "/api/v1/portal/assets/a1/versions/1/revert", http.NoBody)
+	req.Header.Set("X-Change-Summary", "Restore the Q2 figures")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Restore the Q2 figures", vs.lastCreated.ChangeSummary)
+}
+
 func TestRevertToVersionNotFound(t *testing.T) {
 	asset := &Asset{ID: "a1", OwnerID: "u1", S3Bucket: "b", CurrentVersion: 2}
 	h := newTestHandlerWithVersions(
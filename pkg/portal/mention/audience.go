@@ -68,9 +68,11 @@ type ListOptions struct {
 // The rule mirrors the portal's own view checks so a mentionable person is
 // always a person who can open the thing being discussed:
 //
-//   - asset: the owner, recipients of an active direct share, and recipients
-//     of an active share on a collection holding the asset (portal.userCanViewAsset)
-//   - collection: the owner and recipients of an active share
+//   - asset: the owner, recipients of an active direct share, recipients of an
+//     active share on a collection holding the asset, and members of a team
+//     space owning such a collection (portal.userCanViewAsset)
+//   - collection: the owner, recipients of an active share, and members of
+//     the team space that owns it
 //   - prompt: everyone for a persona- or global-scoped prompt, since those are
 //     visible platform-wide; the owner and share recipients for a personal one
 //     (portal.userCanViewPrompt)
@@ -234,11 +236,11 @@ func (a *Audience) source(ctx context.Context, t Target) (query string, args []a
 }
 
 // Grantees returns the addresses holding an explicit grant on a target: its
-// owner and the recipients of active shares (for an asset, including shares of
-// a collection that holds it). It never widens to the directory the way the
-// mention audience does for an open target, because it answers a different
-// question -- who is attached to this item and should hear about activity on
-// it, not who is allowed to read it. Targets with no grant concept (knowledge
+// owner, the recipients of active shares (for an asset, including shares of a
+// collection that holds it), and the members of an owning team space. It never
+// widens to the directory the way the mention audience does for an open
+// target, because it answers a different question -- who is attached to this
+// item and should hear about activity on it, not who is allowed to read it. Targets with no grant concept (knowledge
 // pages, the standalone channel) return nothing.
 func (a *Audience) Grantees(ctx context.Context, targetType, targetID string) ([]string, error) {
 	source, args := grantSource(Target{Type: targetType, ID: targetID})
@@ -320,7 +322,8 @@ const activeShare = `revoked = FALSE
 		     AND COALESCE(shared_with_email, '') <> ''`
 
 // assetAudienceSQL is the owner plus recipients of active direct shares and of
-// active shares on any collection holding the asset. Every branch is gated on
+// active shares on any collection holding the asset, plus the members of the
+// team spaces owning a live collection that holds it. Every branch is gated on
 // the asset still existing: a share row outlives the soft-delete of its asset,
 // so without the guard a deleted asset would keep an audience.
 const assetAudienceSQL = `
@@ -341,7 +344,27 @@ const assetAudienceSQL = `
 	   AND ps.collection_id IS NOT NULL
 	   AND ps.revoked = FALSE
 	   AND (ps.expires_at IS NULL OR ps.expires_at > NOW())
-	   AND COALESCE(ps.shared_with_email, '') <> ''`
+	   AND COALESCE(ps.shared_with_email, '') <> ''
+	 UNION
+	SELECT sm.email
+	  FROM portal_collection_items ci
+	  JOIN portal_collection_sections cs ON cs.id = ci.section_id
+	  JOIN portal_collections c ON c.id = cs.collection_id AND c.deleted_at IS NULL
+	  JOIN portal_space_collections sc ON sc.collection_id = c.id
+	  JOIN (` + spaceMembersSQL + `) sm ON sm.space_id = sc.space_id
+	 WHERE ci.asset_id = $1 AND ` + assetLives
+
+// spaceMembersSQL is every member of every team space, as (space_id, email):
+// the managed members, and the addresses last seen holding a group the space
+// binds. A group member is listed once they have been seen holding the claim
+// (pkg/portal/spaces.Store.ObserveGroups), since the claim itself arrives on a
+// token rather than in a table.
+const spaceMembersSQL = `
+	SELECT space_id, email FROM portal_space_members
+	 UNION
+	SELECT g.space_id, gm.email
+	  FROM portal_space_groups g
+	  JOIN portal_space_group_members gm ON gm.group_name = g.group_name`
 
 // assetLives requires the target asset to still exist, for the share-derived
 // branches that do not otherwise touch portal_assets.
@@ -350,7 +373,8 @@ const assetLives = `EXISTS (SELECT 1 FROM portal_assets a WHERE a.id = $1 AND a.
 // collectionLives is assetLives for a collection target.
 const collectionLives = `EXISTS (SELECT 1 FROM portal_collections c WHERE c.id = $1 AND c.deleted_at IS NULL)`
 
-// collectionAudienceSQL is the owner plus recipients of active shares.
+// collectionAudienceSQL is the owner plus recipients of active shares, plus the
+// members of the team space that owns the collection.
 const collectionAudienceSQL = `
 	SELECT LOWER(owner_email) AS email
 	  FROM portal_collections
@@ -358,7 +382,12 @@ const collectionAudienceSQL = `
 	 UNION
 	SELECT LOWER(shared_with_email)
 	  FROM portal_shares
-	 WHERE collection_id = $1 AND ` + collectionLives + ` AND ` + activeShare
+	 WHERE collection_id = $1 AND ` + collectionLives + ` AND ` + activeShare + `
+	 UNION
+	SELECT sm.email
+	  FROM portal_space_collections sc
+	  JOIN (` + spaceMembersSQL + `) sm ON sm.space_id = sc.space_id
+	 WHERE sc.collection_id = $1 AND ` + collectionLives
 
 // promptAudienceSQL is a personal prompt's owner plus recipients of active
 // shares.
@@ -95,6 +95,43 @@ func TestRealDB_AssetAudience(t *testing.T) {
 		"a stranger, a revoked share, and an expired share are all outside the audience")
 }
 
+func TestRealDB_SpaceMembersJoinTheAudience(t *testing.T) {
+	db := testdb.New(t)
+	const (
+		managed     = "managed.member@example.com"
+		groupMember = "group.member@example.com"
+	)
+	seedPeople(t, db, ownerEmail, sharedEmail, viaCollection, managed, groupMember, strangerEmail)
+	seedAssetWithShares(t, db)
+	ctx := context.Background()
+	for _, q := range []string{
+		`INSERT INTO portal_spaces (id, name) VALUES ('spc_1', 'Finance')`,
+		`INSERT INTO portal_space_collections (collection_id, space_id) VALUES ('col_1', 'spc_1')`,
+		`INSERT INTO portal_space_members (space_id, email, role) VALUES ('spc_1', '` + managed + `', 'viewer')`,
+		`INSERT INTO portal_space_groups (space_id, group_name, role) VALUES ('spc_1', 'finance', 'viewer')`,
+		`INSERT INTO portal_space_group_members (email, group_name) VALUES ('` + groupMember + `', 'finance')`,
+		// A group no space binds brings nobody in.
+		`INSERT INTO portal_space_group_members (email, group_name) VALUES ('` + strangerEmail + `', 'marketing')`,
+	} {
+		_, err := db.ExecContext(ctx, q)
+		require.NoError(t, err)
+	}
+	audience := NewAudience(db)
+
+	for _, target := range []Target{{Type: TargetAsset, ID: "asset_1"}, {Type: TargetCollection, ID: "col_1"}} {
+		eligible, err := audience.Eligible(ctx, target, []string{managed, groupMember, strangerEmail})
+		require.NoError(t, err)
+		assert.Equal(t, []string{managed, groupMember}, eligible,
+			"members of the owning space, managed or through a group, are in the %s audience", target.Type)
+	}
+
+	_, err := db.ExecContext(ctx, `DELETE FROM portal_space_collections WHERE collection_id = 'col_1'`)
+	require.NoError(t, err)
+	eligible, err := audience.Eligible(ctx, Target{Type: TargetAsset, ID: "asset_1"}, []string{managed, groupMember})
+	require.NoError(t, err)
+	assert.Empty(t, eligible, "a collection moved out of the space takes its members with it")
+}
+
 func TestRealDB_AudienceListFiltersAndNames(t *testing.T) {
 	db := testdb.New(t)
 	seedPeople(t, db, ownerEmail, sharedEmail, viaCollection)
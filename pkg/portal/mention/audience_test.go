@@ -95,6 +95,21 @@ func TestAudienceEligible_KeepsOnlyMembersInWrittenOrder(t *testing.T) {
 		"a named address outside the audience is dropped; the rest keep the order they were written in")
 }
 
+func TestAudienceEligible_CollectionIncludesSpaceMembers(t *testing.T) {
+	audience, mock, done := newMockAudience(t)
+	defer done()
+
+	mock.ExpectQuery(`FROM portal_space_collections sc\s+JOIN \(\s+SELECT space_id, email FROM portal_space_members`).
+		WithArgs("col_1", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("member@example.com"))
+
+	got, err := audience.Eligible(context.Background(),
+		Target{Type: TargetCollection, ID: "col_1"}, []string{"member@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"member@example.com"}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestAudienceEligible_NoNamesSkipsTheQuery(t *testing.T) {
 	audience, mock, done := newMockAudience(t)
 	defer done()
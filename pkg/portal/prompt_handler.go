@@ -670,6 +670,10 @@ func (h *Handler) createPromptShare(w http.ResponseWriter, r *http.Request) {
 		writePortalError(w, http.StatusBadRequest, buildErr.Error())
 		return
 	}
+	if err := h.validateShareGuard(req.guardSpec(), &share); err != nil {
+		writePortalError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err := h.deps.ShareStore.Insert(r.Context(), share); err != nil {
 		writePortalError(w, http.StatusInternalServerError, "failed to create share")
 		return
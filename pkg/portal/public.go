@@ -20,6 +20,7 @@ import (
 	"github.com/txn2/mcp-data-platform/internal/portal/sharecache"
 	"github.com/txn2/mcp-data-platform/pkg/blobserve"
 	"github.com/txn2/mcp-data-platform/pkg/contenttype"
+	"github.com/txn2/mcp-data-platform/pkg/portal/shareguest"
 )
 
 // resolvePublicBaseURL returns the absolute URL prefix the public viewer
@@ -153,14 +154,17 @@ func (h *Handler) publicView(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Increment access count asynchronously. Use a detached context because
-	// the request context is canceled after the handler returns.
+	// Increment access count and log the view asynchronously. Use a detached
+	// context because the request context is canceled after the handler
+	// returns.
+	viewedBy := accessEmail(r)
 	go func() { // #nosec G118 -- intentionally detached: request ctx is canceled after handler returns
 		ctx, cancel := context.WithTimeout(context.Background(), incrementAccessTimeout)
 		defer cancel()
 		if incErr := h.deps.ShareStore.IncrementAccess(ctx, share.ID); incErr != nil {
 			slog.Warn("public view: failed to increment access", "error", incErr, "share_id", share.ID) // #nosec G706 -- structured log, not user-facing
 		}
+		h.deps.ShareGuest.RecordAccess(ctx, share.ID, shareguest.EventView, viewedBy)
 	}()
 
 	// A signed-in viewer arriving through a public link gets a derived viewer
@@ -185,13 +189,49 @@ type publicAssetData struct {
 }
 
 func (h *Handler) renderAssetViewer(w http.ResponseWriter, r *http.Request, pad publicAssetData, share *Share) { //nolint:revive // clear param naming
-	asset := pad.Asset
-
 	// Build download URL for the public viewer.
 	// Single-asset shares: /portal/view/{token}/content
 	// Collection items: /portal/view/{token}/items/{assetId}/content
 	downloadURL := fmt.Sprintf("/portal/view/%s/content", share.Token)
 
+	var expiresAtISO string
+	if share.ExpiresAt != nil {
+		expiresAtISO = share.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+
+	// OG/Twitter metadata. Empty baseURL → ShareURL/OGImageURL stay empty,
+	// and the template gates each meta tag on its corresponding field, so
+	// requests without a resolvable base URL still render valid HTML — they
+	// just don't emit OG tags (which require absolute URLs anyway).
+	baseURL := resolvePublicBaseURL(r, h.deps.PublicBaseURL)
+	var shareURL string
+	if baseURL != "" {
+		shareURL = baseURL + publicViewPathPrefix + share.Token
+	}
+
+	data := h.assetViewerData(pad, downloadURL)
+	data["ExpiresAtISO"] = expiresAtISO
+	data["HideExpiration"] = share.HideExpiration
+	data["NoticeText"] = share.NoticeText
+	data["Embedded"] = r.URL.Query().Get("embedded") == "1"
+	data["ShareURL"] = shareURL
+	data["OGImageURL"] = publicAssetOGImage(pad.Asset, share.Token, baseURL)
+	data["SignedIn"] = h.resolvePublicViewer(r) != nil
+	data["SignInURL"] = signInToLeaveFeedbackURL(r)
+	data["PortalURL"] = portalAppPath
+	data["IsGuest"] = isGuestRequest(r)
+
+	w.Header().Set("Content-Security-Policy", publicviewer.AssetCSP())
+	w.Header().Set(headerContentType, "text/html; charset=utf-8")
+	_ = publicviewer.AssetTemplate.Execute(w, data)
+}
+
+// assetViewerData returns the public_viewer.html fields that describe the
+// asset and the deployment's brand, with contentURL as the endpoint its raw
+// bytes are served from. The caller adds what depends on how the page was
+// reached: a share's expiry and notice, or an embed's chrome-less mode.
+func (h *Handler) assetViewerData(pad publicAssetData, contentURL string) map[string]any {
+	asset := pad.Asset
 	contentData := map[string]any{
 		"contentType":  asset.ContentType,
 		"content":      string(pad.Content),
@@ -200,22 +240,18 @@ func (h *Handler) renderAssetViewer(w http.ResponseWriter, r *http.Request, pad
 		colTags:        asset.Tags,
 		"sizeBytes":    asset.SizeBytes,
 		"tooLarge":     pad.TooLarge,
-		"downloadURL":  downloadURL,
+		"downloadURL":  contentURL,
 		// contentURL is the same endpoint as downloadURL, named for the role it
 		// plays for binary families: the <img>/<audio>/<video>/<iframe> source
 		// the viewer renders from instead of embedded bytes. It supports byte
 		// ranges, so media seek works without fetching the whole object.
-		"contentURL":   downloadURL,
+		"contentURL":   contentURL,
 		"serveFromURL": pad.ServeFromURL,
 		"createdAt":    asset.CreatedAt.UTC().Format(time.RFC3339),
 		"updatedAt":    asset.UpdatedAt.UTC().Format(time.RFC3339),
 	}
 	contentJSON, _ := json.Marshal(contentData) // #nosec G104 -- simple map marshaling cannot fail
 
-	csp := publicviewer.AssetCSP()
-	w.Header().Set("Content-Security-Policy", csp)
-	w.Header().Set(headerContentType, "text/html; charset=utf-8")
-
 	brandName := h.deps.BrandName
 	if brandName == "" {
 		brandName = "MCP Data Platform"
@@ -225,22 +261,7 @@ func (h *Handler) renderAssetViewer(w http.ResponseWriter, r *http.Request, pad
 		brandLogo = publicviewer.DefaultLogoSVG
 	}
 
-	var expiresAtISO string
-	if share.ExpiresAt != nil {
-		expiresAtISO = share.ExpiresAt.UTC().Format(time.RFC3339)
-	}
-
-	// OG/Twitter metadata. Empty baseURL → ShareURL/OGImageURL stay empty,
-	// and the template gates each meta tag on its corresponding field, so
-	// requests without a resolvable base URL still render valid HTML — they
-	// just don't emit OG tags (which require absolute URLs anyway).
-	baseURL := resolvePublicBaseURL(r, h.deps.PublicBaseURL)
-	var shareURL string
-	if baseURL != "" {
-		shareURL = baseURL + publicViewPathPrefix + share.Token
-	}
-
-	_ = publicviewer.AssetTemplate.Execute(w, map[string]any{
+	return map[string]any{
 		"Name":               asset.Name,
 		"ContentType":        asset.ContentType,
 		"Description":        asset.Description,
@@ -257,17 +278,7 @@ func (h *Handler) renderAssetViewer(w http.ResponseWriter, r *http.Request, pad
 		"ImplementorLogoSVG": template.HTML(h.deps.ImplementorLogoSVG), // #nosec G203 -- operator-provided SVG from config
 		"ImplementorURL":     h.deps.ImplementorURL,
 		"Version":            asset.CurrentVersion,
-		"ExpiresAtISO":       expiresAtISO,
-		"HideExpiration":     share.HideExpiration,
-		"NoticeText":         share.NoticeText,
-		"Embedded":           r.URL.Query().Get("embedded") == "1",
-		"ShareURL":           shareURL,
-		"OGImageURL":         publicAssetOGImage(asset, share.Token, baseURL),
-		"SignedIn":           h.resolvePublicViewer(r) != nil,
-		"SignInURL":          signInToLeaveFeedbackURL(r),
-		"PortalURL":          portalAppPath,
-		"IsGuest":            isGuestRequest(r),
-	})
+	}
 }
 
 // resolvePublicViewer returns the authenticated user behind a public request,
@@ -417,22 +428,41 @@ const largeAssetPreviewThreshold int64 = 2 * 1024 * 1024 // 2 MB
 // fetchAssetContent retrieves an asset and always fetches its S3 content.
 // Used by download/raw-content endpoints that must serve full content regardless of size.
 func (h *Handler) fetchAssetContent(r *http.Request, assetID string) (*Asset, []byte, error) {
+	asset, err := h.livePublicAsset(r, assetID)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := h.publicObject(r, asset)
+	if err != nil {
+		return nil, nil, err
+	}
+	return asset, data, nil
+}
+
+// livePublicAsset reads an asset a public surface is about to serve, refusing
+// one that is gone.
+func (h *Handler) livePublicAsset(r *http.Request, assetID string) (*Asset, error) {
 	asset, err := h.deps.AssetStore.Get(r.Context(), assetID)
 	if err != nil {
-		return nil, nil, &publicAssetError{Message: "Asset not found.", Status: http.StatusNotFound}
+		return nil, &publicAssetError{Message: "Asset not found.", Status: http.StatusNotFound}
 	}
 	if asset.DeletedAt != nil {
-		return nil, nil, &publicAssetError{Message: "This asset has been deleted.", Status: http.StatusGone}
+		return nil, &publicAssetError{Message: "This asset has been deleted.", Status: http.StatusGone}
 	}
+	return asset, nil
+}
+
+// publicObject fetches the stored bytes asset points at.
+func (h *Handler) publicObject(r *http.Request, asset *Asset) ([]byte, error) {
 	if h.deps.S3Client == nil {
-		return nil, nil, &publicAssetError{Message: "Content storage not configured.", Status: http.StatusServiceUnavailable}
+		return nil, &publicAssetError{Message: "Content storage not configured.", Status: http.StatusServiceUnavailable}
 	}
 	data, _, err := h.deps.S3Client.GetObject(r.Context(), asset.S3Bucket, asset.S3Key)
 	if err != nil {
 		slog.Error("public view: failed to fetch content", "error", err, "asset_id", asset.ID) // #nosec G706 -- structured log
-		return nil, nil, &publicAssetError{Message: "Failed to retrieve content.", Status: http.StatusInternalServerError}
+		return nil, &publicAssetError{Message: "Failed to retrieve content.", Status: http.StatusInternalServerError}
 	}
-	return asset, data, nil
+	return data, nil
 }
 
 // fetchPublicAsset retrieves an asset and, when the viewer can use it, its S3
@@ -442,14 +472,16 @@ func (h *Handler) fetchAssetContent(r *http.Request, assetID string) (*Asset, []
 // bytes, so images, audio, video and PDFs render from the raw content endpoint
 // instead and there is nothing for the page to hold.
 func (h *Handler) fetchPublicAsset(r *http.Request, assetID string) (publicAssetData, error) {
-	asset, err := h.deps.AssetStore.Get(r.Context(), assetID)
+	asset, err := h.livePublicAsset(r, assetID)
 	if err != nil {
-		return publicAssetData{}, &publicAssetError{Message: "Asset not found.", Status: http.StatusNotFound}
-	}
-	if asset.DeletedAt != nil {
-		return publicAssetData{}, &publicAssetError{Message: "This asset has been deleted.", Status: http.StatusGone}
+		return publicAssetData{}, err
 	}
+	return h.loadPublicAsset(r, asset)
+}
 
+// loadPublicAsset is fetchPublicAsset for an asset already read: it decides
+// whether the page embeds the bytes, links to them, or offers a download.
+func (h *Handler) loadPublicAsset(r *http.Request, asset *Asset) (publicAssetData, error) {
 	if h.deps.S3Client == nil {
 		return publicAssetData{}, &publicAssetError{Message: "Content storage not configured.", Status: http.StatusServiceUnavailable}
 	}
@@ -463,12 +495,10 @@ func (h *Handler) fetchPublicAsset(r *http.Request, assetID string) (publicAsset
 		return publicAssetData{Asset: asset, ServeFromURL: true}, nil
 	}
 
-	data, _, err := h.deps.S3Client.GetObject(r.Context(), asset.S3Bucket, asset.S3Key)
+	data, err := h.publicObject(r, asset)
 	if err != nil {
-		slog.Error("public view: failed to fetch content", "error", err, "asset_id", asset.ID) // #nosec G706 -- structured log, not user-facing
-		return publicAssetData{}, &publicAssetError{Message: "Failed to retrieve content.", Status: http.StatusInternalServerError}
+		return publicAssetData{}, err
 	}
-
 	return publicAssetData{Asset: asset, Content: data}, nil
 }
 
@@ -500,13 +530,15 @@ func (h *Handler) publicCollectionView(w http.ResponseWriter, r *http.Request, s
 		return
 	}
 
-	// Increment access count asynchronously.
+	// Increment access count and log the view asynchronously.
+	viewedBy := accessEmail(r)
 	go func() { // #nosec G118 -- intentionally detached
 		ctx, cancel := context.WithTimeout(context.Background(), incrementAccessTimeout)
 		defer cancel()
 		if incErr := h.deps.ShareStore.IncrementAccess(ctx, share.ID); incErr != nil {
 			slog.Warn("public collection view: failed to increment access", "error", incErr, "share_id", share.ID) // #nosec G706 -- structured log, not user-facing
 		}
+		h.deps.ShareGuest.RecordAccess(ctx, share.ID, shareguest.EventView, viewedBy)
 	}()
 
 	h.maybeAutoPromoteViewer(r, promoteTarget{targetTypeCollection, share.CollectionID, coll.OwnerID, share.CreatedBy})
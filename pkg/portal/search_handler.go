@@ -8,7 +8,7 @@ import (
 // searchMyAssets handles GET /api/v1/portal/assets/search.
 //
 // @Summary      Search my assets
-// @Description  Ranks the current user's saved assets by relevance to q. Uses hybrid (semantic + lexical) ranking when an embedding provider is configured, falling back to lexical-only otherwise. Always scoped server-side to the requesting user's own assets.
+// @Description  Ranks the current user's saved assets by relevance to q. Uses hybrid (semantic + lexical) ranking when an embedding provider is configured, falling back to lexical-only otherwise. Matches the assets' indexed HTML, markdown, text, and CSV content as well as their metadata; a content match carries the passage's section, an excerpt, and a deep link to it. Always scoped server-side to the requesting user's own assets and the assets in collections owned by team spaces the user is a member of.
 // @Tags         Assets
 // @Produce      json
 // @Param        q      query  string   true   "Search query"
@@ -28,10 +28,11 @@ func (h *Handler) searchMyAssets(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusUnauthorized, errAuthRequired)
 		return
 	}
-	// owner_id is the sole server-side scoping key — the same key the asset
-	// library and ownership checks use, so search returns exactly the assets the
-	// caller sees in the library. A blank id would scope to the shared "" owner
-	// bucket, so fail closed rather than run an unscoped search.
+	// owner_id is the server-side scoping key — the same key the asset library
+	// and ownership checks use, so search returns the assets the caller sees in
+	// the library, widened only by the collections the caller's team spaces
+	// own. A blank id would scope to the shared "" owner bucket, so fail closed
+	// rather than run an unscoped search.
 	if strings.TrimSpace(user.UserID) == "" {
 		writeError(w, http.StatusForbidden, errSearchScopeRequired)
 		return
@@ -51,10 +52,12 @@ func (h *Handler) searchMyAssets(w http.ResponseWriter, r *http.Request) {
 
 	limit := intParam(r, paramLimit, DefaultSearchLimit)
 	scored, err := searcher.SearchAssets(r.Context(), AssetSearchQuery{
-		Embedding: h.embedSearchQuery(r.Context(), query),
-		QueryText: query,
-		OwnerID:   user.UserID,
-		Limit:     limit,
+		Embedding:          h.embedSearchQuery(r.Context(), query),
+		QueryText:          query,
+		OwnerID:            user.UserID,
+		SpaceCollectionIDs: h.spaceCollectionIDs(r.Context(), user),
+		Limit:              limit,
+		Passages:           true,
 	})
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to search assets")
@@ -72,7 +75,7 @@ func (h *Handler) searchMyAssets(w http.ResponseWriter, r *http.Request) {
 // searchMyCollections handles GET /api/v1/portal/collections/search.
 //
 // @Summary      Search my collections
-// @Description  Ranks the current user's collections by relevance to q (matching name, description, and section titles/descriptions). Uses hybrid (semantic + lexical) ranking when an embedding provider is configured, falling back to lexical-only otherwise. Always scoped server-side to the requesting user's own collections.
+// @Description  Ranks the current user's collections by relevance to q (matching name, description, and section titles/descriptions). Uses hybrid (semantic + lexical) ranking when an embedding provider is configured, falling back to lexical-only otherwise. Always scoped server-side to the requesting user's own collections and the collections owned by team spaces the user is a member of.
 // @Tags         Collections
 // @Produce      json
 // @Param        q      query  string   true   "Search query"
@@ -111,10 +114,11 @@ func (h *Handler) searchMyCollections(w http.ResponseWriter, r *http.Request) {
 
 	limit := intParam(r, paramLimit, DefaultSearchLimit)
 	scored, err := searcher.SearchCollections(r.Context(), CollectionSearchQuery{
-		Embedding: h.embedSearchQuery(r.Context(), query),
-		QueryText: query,
-		OwnerID:   user.UserID,
-		Limit:     limit,
+		Embedding:          h.embedSearchQuery(r.Context(), query),
+		QueryText:          query,
+		OwnerID:            user.UserID,
+		SpaceCollectionIDs: h.spaceCollectionIDs(r.Context(), user),
+		Limit:              limit,
 	})
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to search collections")
@@ -17,11 +17,14 @@ type gateCtxKey struct{}
 // token names, the authenticated viewer (nil when anonymous, which only a
 // public share admits), and whether the caller was admitted as a guest
 // through a one-time email link (#1001). A guest is never a viewer: the two
-// fields are mutually exclusive.
+// fields are mutually exclusive. Verified is the address an anonymous caller
+// is attributed to in the access log: a guest's recipient address, or the
+// address a visitor proved to a guarded public link's email check.
 type gateResult struct {
-	Share  *Share
-	Viewer *User
-	Guest  bool
+	Share    *Share
+	Viewer   *User
+	Guest    bool
+	Verified string
 }
 
 // shareFromRequest returns the share the public gate resolved for this
@@ -58,6 +61,9 @@ func isGuestRequest(r *http.Request) bool {
 // the guest service for a valid one-time-link session scoped to this share
 // (#1001); share availability is checked first, so revoking a share refuses
 // its guests immediately, without waiting for their cookies to expire.
+//
+// An admitted public share then passes its guard, if it has one
+// (checkShareGuard): a passphrase, a view limit, or an email check.
 func (h *Handler) publicShareGate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		token := r.PathValue(pathKeyToken)
@@ -89,8 +95,8 @@ func (h *Handler) publicShareGate(next http.Handler) http.Handler {
 			caller = &shareaccess.Viewer{UserID: viewer.UserID, Email: viewer.Email}
 		}
 		if msg, ok := shareaccess.Authorize(target, caller); !ok {
-			if viewer == nil && h.deps.ShareGuest.Admit(r, share.ID) != nil {
-				ctx := context.WithValue(r.Context(), gateCtxKey{}, gateResult{Share: share, Guest: true})
+			if guest := h.guestFor(r, share, viewer); guest != nil {
+				ctx := context.WithValue(r.Context(), gateCtxKey{}, gateResult{Share: share, Guest: true, Verified: guest.Email})
 				next.ServeHTTP(w, r.WithContext(ctx))
 				return
 			}
@@ -102,11 +108,24 @@ func (h *Handler) publicShareGate(next http.Handler) http.Handler {
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), gateCtxKey{}, gateResult{Share: share, Viewer: viewer})
+		verified, ok := h.checkShareGuard(w, r, share, viewer)
+		if !ok {
+			return
+		}
+		ctx := context.WithValue(r.Context(), gateCtxKey{}, gateResult{Share: share, Viewer: viewer, Verified: verified})
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// guestFor returns the guest session an anonymous caller holds for share, or
+// nil. A signed-in caller is never admitted as a guest.
+func (h *Handler) guestFor(r *http.Request, share *Share, viewer *User) *shareguest.Guest {
+	if viewer != nil {
+		return nil
+	}
+	return h.deps.ShareGuest.Admit(r, share.ID)
+}
+
 // viewerEmail returns the signed-in caller's email, or "" for anonymous.
 func viewerEmail(viewer *User) string {
 	if viewer == nil {
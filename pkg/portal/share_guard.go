@@ -0,0 +1,129 @@
+package portal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/txn2/mcp-data-platform/pkg/portal/shareaccess"
+	"github.com/txn2/mcp-data-platform/pkg/portal/shareguest"
+)
+
+// guardSpec returns the link guard the request asks for.
+func (req createShareRequest) guardSpec() shareguest.GuardSpec {
+	return shareguest.GuardSpec{
+		Passphrase:  req.Passphrase,
+		MaxViews:    req.MaxViews,
+		VerifyEmail: req.VerifyEmail,
+	}
+}
+
+// validateShareGuard checks a requested guard against the built share before
+// anything is stored. Guards protect public links only: a restricted or
+// authenticated share already knows who its viewer is, and a prompt share has
+// no viewer link at all.
+func (h *Handler) validateShareGuard(spec shareguest.GuardSpec, share *Share) error {
+	public := share.PromptID == "" && share.AccessMode == shareaccess.ModePublic
+	return h.deps.ShareGuest.ValidateGuard(spec, public) //nolint:wrapcheck // message is the verbatim 400 body
+}
+
+// storeShareGuard stores the guard of a just-inserted share. A failed write
+// revokes the share: the creator asked for a protected link, and an
+// unprotected one must not be left live in its place.
+func (h *Handler) storeShareGuard(ctx context.Context, share *Share, spec shareguest.GuardSpec) error {
+	if spec.Empty() {
+		return nil
+	}
+	err := h.deps.ShareGuest.SetGuard(ctx, share.ID, spec)
+	if err == nil {
+		return nil
+	}
+	if revokeErr := h.deps.ShareStore.Revoke(ctx, share.ID); revokeErr != nil {
+		slog.Error("share guard: revoking unguarded share failed", "error", revokeErr, "share_id", share.ID) // #nosec G706 -- structured log, not user-facing
+	}
+	return fmt.Errorf("storing share guard: %w", err)
+}
+
+// checkShareGuard enforces a public share's guard inside the gate. The
+// share's creator is exempt, so checking how a link renders neither prompts
+// them nor spends a view. It returns the address the visitor verified, and
+// false when the guard refused (the refusal is already written).
+func (h *Handler) checkShareGuard(w http.ResponseWriter, r *http.Request, share *Share, viewer *User) (string, bool) {
+	if share.AccessMode != shareaccess.ModePublic ||
+		(viewer != nil && strings.EqualFold(viewer.Email, share.CreatedBy)) {
+		return "", true
+	}
+	verdict := h.deps.ShareGuest.CheckGuard(w, r, shareguest.ShareInfo{
+		ID:     share.ID,
+		Token:  share.Token,
+		Public: true,
+	})
+	if !verdict.Admit {
+		h.denyShare(w, r, share, verdict.Denial)
+		return "", false
+	}
+	return verdict.Email, true
+}
+
+// accessEmail returns the address a viewer request is attributed to in the
+// share's access log: the signed-in viewer, else the address a guard
+// verified. Anonymous visits to an unguarded link are recorded without one.
+func accessEmail(r *http.Request) string {
+	g, ok := r.Context().Value(gateCtxKey{}).(gateResult)
+	if !ok {
+		return ""
+	}
+	if g.Viewer != nil {
+		return g.Viewer.Email
+	}
+	return g.Verified
+}
+
+// shareAccessLogResponse is the response for a share's access log.
+type shareAccessLogResponse struct {
+	// Guard is what protects the link; absent when it is unguarded.
+	Guard   *shareguest.GuardSummary `json:"guard,omitempty"`
+	Entries []shareguest.AccessEntry `json:"entries"`
+}
+
+// getShareAccessLog handles GET /api/v1/portal/shares/{id}/access-log.
+//
+// @Summary      Get share access log
+// @Description  Returns a share's guard summary and its most recent access log entries (views and unlock attempts), newest first. The owner of the shared item, or an admin.
+// @Tags         Shares
+// @Produce      json
+// @Param        id  path  string  true  "Share ID"
+// @Success      200  {object}  shareAccessLogResponse
+// @Failure      401  {object}  problemDetail
+// @Failure      403  {object}  problemDetail
+// @Failure      404  {object}  problemDetail
+// @Failure      500  {object}  problemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/shares/{id}/access-log [get]
+func (h *Handler) getShareAccessLog(w http.ResponseWriter, r *http.Request) {
+	user := GetUser(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, errAuthRequired)
+		return
+	}
+
+	share, err := h.deps.ShareStore.GetByID(r.Context(), r.PathValue(pathKeyID))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "share not found")
+		return
+	}
+	if err := h.verifyShareManager(r.Context(), share, user); err != nil {
+		writeError(w, err.code, err.message)
+		return
+	}
+
+	guard, entries, err := h.deps.ShareGuest.AccessLog(r.Context(), share.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to read access log")
+		return
+	}
+	writeJSON(w, http.StatusOK, shareAccessLogResponse{Guard: guard, Entries: entries})
+}
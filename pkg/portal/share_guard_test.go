@@ -0,0 +1,319 @@
+package portal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mw "github.com/txn2/mcp-data-platform/pkg/middleware"
+	"github.com/txn2/mcp-data-platform/pkg/portal/shareaccess"
+	"github.com/txn2/mcp-data-platform/pkg/portal/shareguest"
+)
+
+// This file drives guarded public links through the assembled portal handler:
+// creating a guarded share, the unlock journey on the public mux, the
+// creator's exemption, and the owner's access log.
+
+// memShareGuards is an in-memory shareguest.GuardStore.
+type memShareGuards struct {
+	mu     sync.Mutex
+	guards map[string]shareguest.Guard
+	log    []shareguest.AccessEntry
+	putErr error
+}
+
+func newMemShareGuards() *memShareGuards {
+	return &memShareGuards{guards: map[string]shareguest.Guard{}}
+}
+
+func (m *memShareGuards) PutGuard(_ context.Context, g shareguest.Guard) error {
+	if m.putErr != nil {
+		return m.putErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.guards[g.ShareID] = g
+	return nil
+}
+
+func (m *memShareGuards) GetGuard(_ context.Context, shareID string) (*shareguest.Guard, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	g, ok := m.guards[shareID]
+	if !ok {
+		return nil, nil //nolint:nilnil // GuardStore contract: nil, nil means unguarded
+	}
+	return &g, nil
+}
+
+func (m *memShareGuards) ConsumeView(_ context.Context, shareID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	g, ok := m.guards[shareID]
+	if !ok || (g.MaxViews > 0 && g.Views >= g.MaxViews) {
+		return false, nil
+	}
+	g.Views++
+	m.guards[shareID] = g
+	return true, nil
+}
+
+func (*memShareGuards) InsertCode(context.Context, shareguest.VerifyCode) error { return nil }
+
+func (*memShareGuards) CountCodesSince(context.Context, string, string, time.Time) (int, error) {
+	return 0, nil
+}
+
+func (*memShareGuards) ClaimCode(context.Context, string, string, string, time.Time, int) (bool, error) {
+	return false, nil
+}
+
+func (m *memShareGuards) RecordAccess(_ context.Context, _ string, e shareguest.AccessEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.log = append(m.log, e)
+	return nil
+}
+
+func (m *memShareGuards) ListAccess(_ context.Context, _ string, _ int) ([]shareguest.AccessEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]shareguest.AccessEntry, 0, len(m.log))
+	for i := len(m.log) - 1; i >= 0; i-- {
+		out = append(out, m.log[i])
+	}
+	return out, nil
+}
+
+func (m *memShareGuards) hasEvent(event string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.log {
+		if e.Event == event {
+			return true
+		}
+	}
+	return false
+}
+
+// guardedShare is the public link the journey opens.
+func guardedShare() *Share {
+	expires := time.Now().Add(24 * time.Hour)
+	return &Share{
+		ID: "pub1", AssetID: "a1", Token: "ptok", CreatedBy: "alice@example.com",
+		AccessMode: AccessModePublic, ExpiresAt: &expires,
+	}
+}
+
+// newGuardHandler assembles the portal handler with guards wired. apiUser is
+// the caller the API routes see; publicViewer, when set, is who the public
+// viewer's authenticator resolves requests carrying X-API-Key to.
+func newGuardHandler(share *Share, guards *memShareGuards, apiUser, publicViewer *User) (*Handler, *mockShareStore) {
+	now := time.Now()
+	asset := &Asset{
+		ID: "a1", OwnerID: "owner", Name: "Doc", ContentType: "text/plain",
+		S3Bucket: "b1", S3Key: "assets/a1",
+		Tags: []string{}, CreatedAt: now, UpdatedAt: now,
+	}
+	shares := &mockShareStore{getByTokenRes: share, getByIDShare: share}
+	svc := shareguest.New(shareguest.Config{
+		Resolve: func(ctx context.Context, token string) (shareguest.ShareInfo, bool) {
+			s, err := shares.GetByToken(ctx, token)
+			if err != nil || s == nil || s.Token != token {
+				return shareguest.ShareInfo{}, false
+			}
+			return shareguest.ShareInfo{
+				ID: s.ID, Token: s.Token,
+				Public:  s.AccessMode == shareaccess.ModePublic,
+				Revoked: s.Revoked,
+			}, true
+		},
+		SessionKey: []byte("0123456789abcdef0123456789abcdef"),
+		Brand:      shareguest.Brand{Name: "ACME Data"},
+		Guards:     guards,
+	})
+
+	deps := Deps{
+		AssetStore:    &mockAssetStore{getAsset: asset},
+		ShareStore:    shares,
+		S3Client:      &mockS3Client{getData: []byte("file content"), getCT: "text/plain"},
+		S3Bucket:      "b1",
+		PublicBaseURL: "https://platform.example.com",
+		RateLimit:     RateLimitConfig{RequestsPerMinute: 6000, BurstSize: 1000},
+		ShareGuest:    svc,
+	}
+	if publicViewer != nil {
+		deps.Authenticator = NewAuthenticator(&mockAuthenticator{
+			info: &mw.UserInfo{UserID: publicViewer.UserID, Email: publicViewer.Email},
+		})
+	}
+	return NewHandler(deps, testAuthMiddleware(apiUser)), shares
+}
+
+// postUnlock submits the unlock form through the public mux.
+func postUnlock(h *Handler, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodPost,
+		"/portal/view/ptok/unlock", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateShareWithGuard(t *testing.T) {
+	guards := newMemShareGuards()
+	h, shares := newGuardHandler(guardedShare(), guards, &User{UserID: "owner", Email: "alice@example.com"}, nil)
+
+	w := postShare(t, h, `{"access_mode":"public","expires_in":"24h","passphrase":"partner-2026","max_views":3}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	require.NotNil(t, shares.inserted)
+	g, ok := guards.guards[shares.inserted.ID]
+	require.True(t, ok, "the guard is stored beside the share")
+	assert.NotEmpty(t, g.PassphraseHash)
+	assert.NotContains(t, w.Body.String(), "partner-2026", "the passphrase is never echoed")
+	assert.Equal(t, 3, g.MaxViews)
+}
+
+func TestCreateShareGuardRejections(t *testing.T) {
+	owner := &User{UserID: "owner", Email: "alice@example.com"}
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"non-public share", `{"shared_with_email":"bob@example.com","passphrase":"partner-2026"}`, shareguest.ErrGuardNotPublic.Error()},
+		{"short passphrase", `{"access_mode":"public","expires_in":"24h","passphrase":"short"}`, shareguest.ErrPassphraseLength.Error()},
+		{"email check without mailer", `{"access_mode":"public","expires_in":"24h","verify_email":true}`, shareguest.ErrVerifyUnavailable.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, shares := newGuardHandler(guardedShare(), newMemShareGuards(), owner, nil)
+			w := postShare(t, h, tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tt.want)
+			assert.Nil(t, shares.inserted, "a rejected guard creates no share")
+		})
+	}
+
+	t.Run("no guard service", func(t *testing.T) {
+		h := newTestHandler(&mockAssetStore{getAsset: &Asset{ID: "a1", OwnerID: "u1"}},
+			&mockShareStore{}, &mockS3Client{}, &User{UserID: "u1"})
+		w := postShare(t, h, `{"access_mode":"public","expires_in":"24h","max_views":2}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), shareguest.ErrGuardUnavailable.Error())
+	})
+}
+
+func TestCreateShareGuardWriteFailureRevokes(t *testing.T) {
+	guards := newMemShareGuards()
+	guards.putErr = errors.New("db down")
+	h, shares := newGuardHandler(guardedShare(), guards, &User{UserID: "owner", Email: "alice@example.com"}, nil)
+
+	w := postShare(t, h, `{"access_mode":"public","expires_in":"24h","max_views":3}`)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	require.NotNil(t, shares.inserted)
+	assert.Equal(t, []string{shares.inserted.ID}, shares.revokedIDs,
+		"an unguarded link must not stay live in place of the guarded one")
+}
+
+func TestGuardedLinkJourney(t *testing.T) {
+	guards := newMemShareGuards()
+	h, _ := newGuardHandler(guardedShare(), guards, &User{UserID: "owner", Email: "alice@example.com"}, nil)
+	require.NoError(t, h.deps.ShareGuest.SetGuard(context.Background(), "pub1",
+		shareguest.GuardSpec{Passphrase: "partner-2026", MaxViews: 2}))
+
+	// 1. A browser navigation gets the unlock form; subresources get text.
+	w := browserGet(h, "/portal/view/ptok", nil)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), `action="/portal/view/ptok/unlock"`)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	wc := httptest.NewRecorder()
+	h.ServeHTTP(wc, httptest.NewRequestWithContext(context.Background(),
+		http.MethodGet, "/portal/view/ptok/content", http.NoBody))
+	assert.Equal(t, http.StatusUnauthorized, wc.Code)
+	assert.NotContains(t, wc.Body.String(), "file content")
+
+	// 2. A wrong passphrase re-renders the form.
+	wf := postUnlock(h, url.Values{"passphrase": {"wrong-guess"}})
+	assert.Equal(t, http.StatusUnauthorized, wf.Code)
+
+	// 3. The right one sets the pass and redirects into the viewer.
+	wu := postUnlock(h, url.Values{"passphrase": {"partner-2026"}})
+	require.Equal(t, http.StatusSeeOther, wu.Code)
+	assert.Equal(t, "/portal/view/ptok", wu.Header().Get("Location"))
+	cookies := wu.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	// 4. The pass opens the page and its content without spending views.
+	wv := browserGet(h, "/portal/view/ptok", cookies)
+	require.Equal(t, http.StatusOK, wv.Code)
+	wd := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/portal/view/ptok/content", http.NoBody)
+	req.AddCookie(cookies[0])
+	h.ServeHTTP(wd, req)
+	require.Equal(t, http.StatusOK, wd.Code)
+	assert.Equal(t, "file content", wd.Body.String())
+	assert.Equal(t, 1, guards.guards["pub1"].Views)
+
+	// 5. The owner's access log shows the attempt, the unlock, and the view.
+	assert.Eventually(t, func() bool { return guards.hasEvent(shareguest.EventView) },
+		time.Second, 10*time.Millisecond, "the page view is logged")
+	wl := httptest.NewRecorder()
+	h.ServeHTTP(wl, httptest.NewRequestWithContext(context.Background(),
+		http.MethodGet, "/api/v1/portal/shares/pub1/access-log", http.NoBody))
+	require.Equal(t, http.StatusOK, wl.Code)
+	var resp shareAccessLogResponse
+	require.NoError(t, json.NewDecoder(wl.Body).Decode(&resp))
+	require.NotNil(t, resp.Guard)
+	assert.True(t, resp.Guard.Passphrase)
+	assert.Equal(t, 2, resp.Guard.MaxViews)
+	assert.Equal(t, 1, resp.Guard.Views)
+	var events []string
+	for _, e := range resp.Entries {
+		events = append(events, e.Event)
+	}
+	assert.Contains(t, events, shareguest.EventPassphraseFailed)
+	assert.Contains(t, events, shareguest.EventUnlocked)
+	assert.Contains(t, events, shareguest.EventView)
+	assert.NotContains(t, wl.Body.String(), "$2a$", "the passphrase hash never leaves the store")
+}
+
+func TestGuardedLinkCreatorExempt(t *testing.T) {
+	guards := newMemShareGuards()
+	creator := &User{UserID: "owner", Email: "alice@example.com"}
+	h, _ := newGuardHandler(guardedShare(), guards, nil, creator)
+	require.NoError(t, h.deps.ShareGuest.SetGuard(context.Background(), "pub1",
+		shareguest.GuardSpec{Passphrase: "partner-2026", MaxViews: 1}))
+
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/portal/view/ptok", http.NoBody)
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("X-API-Key", "k")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, "the creator previews without unlocking")
+	assert.Equal(t, 0, guards.guards["pub1"].Views, "and spends no view")
+}
+
+func TestShareAccessLogOwnerOnly(t *testing.T) {
+	h, _ := newGuardHandler(guardedShare(), newMemShareGuards(), &User{UserID: "stranger", Email: "eve@example.com"}, nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequestWithContext(context.Background(),
+		http.MethodGet, "/api/v1/portal/shares/pub1/access-log", http.NoBody))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	anon, _ := newGuardHandler(guardedShare(), newMemShareGuards(), nil, nil)
+	w = httptest.NewRecorder()
+	anon.ServeHTTP(w, httptest.NewRequestWithContext(context.Background(),
+		http.MethodGet, "/api/v1/portal/shares/pub1/access-log", http.NoBody))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
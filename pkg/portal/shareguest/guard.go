@@ -0,0 +1,286 @@
+package shareguest
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Guard bounds and lifetimes. A guard narrows who holding a public link can
+// open it: the URL alone stops being enough once the link reaches a partner
+// outside the organization.
+const (
+	// MinPassphraseLen is the shortest passphrase a guard accepts.
+	MinPassphraseLen = 8
+	// MaxPassphraseBytes is bcrypt's input limit; a longer passphrase would
+	// be silently truncated, so it is refused instead.
+	MaxPassphraseBytes = 72
+	// MaxViewLimit caps max_views so a typo cannot mean "effectively none".
+	MaxViewLimit = 10000
+	// CodeTTL is how long an emailed verification code stays claimable.
+	CodeTTL = 10 * time.Minute
+	// maxCodeAttempts is how many wrong guesses kill a verification code.
+	maxCodeAttempts = 5
+	// maxCodesPerWindow caps codes sent per share and address inside
+	// linkCapWindow, so the unlock form cannot be used to mailbomb anyone.
+	maxCodesPerWindow = 5
+	// unlockRPM and unlockBurst size the per-share unlock limiter. The
+	// limiter is keyed on the share rather than the client, so rotating IPs
+	// buys a passphrase guesser nothing.
+	unlockRPM   = 10
+	unlockBurst = 5
+	// accessLogLimit bounds one read of a share's access log.
+	accessLogLimit = 500
+)
+
+// Access log events.
+const (
+	EventView             = "view"
+	EventUnlocked         = "unlocked"
+	EventPassphraseFailed = "passphrase_failed"
+	EventCodeSent         = "code_sent"
+	EventCodeFailed       = "code_failed"
+	EventViewLimitReached = "view_limit_reached"
+	EventThrottled        = "throttled"
+)
+
+// Guard denial messages.
+const (
+	MsgLocked          = "This link is protected. Unlock it to view."
+	MsgViewLimit       = "This link has reached its view limit."
+	msgGuardUnknown    = "This link cannot be opened right now. Try again in a moment."
+	msgTooManyAttempts = "Too many attempts. Wait a minute and try again."
+)
+
+// Guard validation errors, surfaced to the share creator as 400s.
+var (
+	ErrGuardNotPublic    = errors.New("passphrase, max_views, and verify_email apply only to public shares")
+	ErrGuardUnavailable  = errors.New("link protection is not available on this deployment")
+	ErrVerifyUnavailable = errors.New("email verification is not available: no mailer is configured")
+	ErrPassphraseLength  = fmt.Errorf("passphrase must be %d to %d bytes", MinPassphraseLen, MaxPassphraseBytes)
+	ErrMaxViewsRange     = fmt.Errorf("max_views must be between 1 and %d", MaxViewLimit)
+)
+
+// GuardSpec is a guard as the share creator requests it.
+type GuardSpec struct {
+	// Passphrase, when set, must be entered before the link opens.
+	Passphrase string
+	// MaxViews, when positive, caps the visits the link admits.
+	MaxViews int
+	// VerifyEmail requires the visitor to prove an address with an emailed
+	// one-time code; the address is recorded in the access log.
+	VerifyEmail bool
+}
+
+// Empty reports whether the spec requests no guard at all.
+func (g GuardSpec) Empty() bool {
+	return g.Passphrase == "" && g.MaxViews == 0 && !g.VerifyEmail
+}
+
+// Guard is the stored guard of one share. Only the passphrase's bcrypt hash
+// is kept.
+type Guard struct {
+	ShareID        string
+	PassphraseHash string
+	MaxViews       int
+	Views          int
+	VerifyEmail    bool
+}
+
+// needsUnlock reports whether the visitor has to submit the unlock form, as
+// opposed to a view limit alone, which admits without interaction.
+func (g *Guard) needsUnlock() bool { return g.PassphraseHash != "" || g.VerifyEmail }
+
+// spent reports whether the view limit is used up.
+func (g *Guard) spent() bool { return g.MaxViews > 0 && g.Views >= g.MaxViews }
+
+// GuardSummary is the owner-facing view of a guard: what is enforced and how
+// many views it has admitted, never the passphrase hash.
+type GuardSummary struct {
+	Passphrase  bool `json:"passphrase"`
+	MaxViews    int  `json:"max_views"`
+	Views       int  `json:"views"`
+	VerifyEmail bool `json:"verify_email"`
+}
+
+// AccessEntry is one row of a share's access log.
+type AccessEntry struct {
+	Event string    `json:"event"`
+	Email string    `json:"email,omitempty"`
+	At    time.Time `json:"at"`
+}
+
+// GuardsAvailable reports whether guards can be stored and enforced: a guard
+// store is wired and pass cookies can be signed. Nil-safe.
+func (s *Service) GuardsAvailable() bool {
+	return s != nil && s.guards != nil && len(s.guestKey) > 0
+}
+
+// VerifyAvailable reports whether the email-verification step can send
+// codes. Nil-safe.
+func (s *Service) VerifyAvailable() bool {
+	return s.GuardsAvailable() && s.sendCode != nil
+}
+
+// ValidateGuard checks a requested guard against the share it would protect
+// and what this deployment can enforce. An empty spec is always valid.
+func (s *Service) ValidateGuard(spec GuardSpec, public bool) error {
+	if spec.Empty() {
+		return nil
+	}
+	if !public {
+		return ErrGuardNotPublic
+	}
+	if !s.GuardsAvailable() {
+		return ErrGuardUnavailable
+	}
+	if spec.VerifyEmail && !s.VerifyAvailable() {
+		return ErrVerifyUnavailable
+	}
+	if spec.Passphrase != "" && (len(spec.Passphrase) < MinPassphraseLen || len(spec.Passphrase) > MaxPassphraseBytes) {
+		return ErrPassphraseLength
+	}
+	if spec.MaxViews < 0 || spec.MaxViews > MaxViewLimit {
+		return ErrMaxViewsRange
+	}
+	return nil
+}
+
+// SetGuard hashes the passphrase and stores the guard for shareID. The
+// caller validates the spec first (ValidateGuard); an empty spec is a no-op.
+func (s *Service) SetGuard(ctx context.Context, shareID string, spec GuardSpec) error {
+	if spec.Empty() {
+		return nil
+	}
+	g := Guard{ShareID: shareID, MaxViews: spec.MaxViews, VerifyEmail: spec.VerifyEmail}
+	if spec.Passphrase != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(spec.Passphrase), bcrypt.DefaultCost)
+		if err != nil {
+			return fmt.Errorf("hashing share passphrase: %w", err)
+		}
+		g.PassphraseHash = string(hash)
+	}
+	if err := s.guards.PutGuard(ctx, g); err != nil {
+		return fmt.Errorf("storing share guard: %w", err)
+	}
+	return nil
+}
+
+// Verdict is CheckGuard's answer for one request.
+type Verdict struct {
+	// Admit reports whether the request may proceed.
+	Admit bool
+	// Email is the address the visitor verified, when the guard required one.
+	Email string
+	// Denial is the refusal to render when Admit is false.
+	Denial Denial
+}
+
+// CheckGuard enforces the guard of a public share on one viewer request. A
+// share with no guard is admitted untouched. A valid pass cookie admits; a
+// guard that needs the unlock form refuses with 401 and the form's prompt; a
+// view limit alone counts this request as a new visit and, while views
+// remain, sets the pass so the visit's subresources and reloads are not
+// counted again. A guard lookup failure refuses (fail closed): a guarded
+// link must never open because the database hiccupped.
+//
+// Nil-safe: without a service or a guard store every request is admitted,
+// and ValidateGuard refuses to create guards in the first place.
+func (s *Service) CheckGuard(w http.ResponseWriter, r *http.Request, share ShareInfo) Verdict {
+	if !s.GuardsAvailable() {
+		return Verdict{Admit: true}
+	}
+	g, err := s.guards.GetGuard(r.Context(), share.ID)
+	if err != nil {
+		slog.Warn("share guard: lookup failed", logKeyError, err, logKeyShareID, share.ID)
+		return Verdict{Denial: Denial{Status: http.StatusServiceUnavailable, Message: msgGuardUnknown}}
+	}
+	if g == nil {
+		return Verdict{Admit: true}
+	}
+	if email, ok := s.readPass(r, share.ID); ok {
+		return Verdict{Admit: true, Email: email}
+	}
+	if g.spent() {
+		return Verdict{Denial: Denial{Status: http.StatusGone, Message: MsgViewLimit}}
+	}
+	if g.needsUnlock() {
+		return Verdict{Denial: Denial{Status: http.StatusUnauthorized, Message: MsgLocked, Guard: promptFor(g)}}
+	}
+	if d := s.consumeView(r.Context(), share.ID, ""); d != nil {
+		return Verdict{Denial: *d}
+	}
+	s.grantPass(w, share, "")
+	return Verdict{Admit: true}
+}
+
+// consumeView counts one admitted visit against the share's view limit. It
+// returns nil when a view remained and the refusal to render otherwise: the
+// spent limit, or (fail closed, as in CheckGuard) a store failure.
+func (s *Service) consumeView(ctx context.Context, shareID, email string) *Denial {
+	ok, err := s.guards.ConsumeView(ctx, shareID)
+	if err != nil {
+		slog.Warn("share guard: view count failed", logKeyError, err, logKeyShareID, shareID)
+		return &Denial{Status: http.StatusServiceUnavailable, Message: msgGuardUnknown}
+	}
+	if !ok {
+		s.RecordAccess(ctx, shareID, EventViewLimitReached, email)
+		return &Denial{Status: http.StatusGone, Message: MsgViewLimit}
+	}
+	return nil
+}
+
+// RecordAccess appends one event to the share's access log. Failures are
+// logged, never surfaced: the log is informational and must not break the
+// viewer. Nil-safe.
+func (s *Service) RecordAccess(ctx context.Context, shareID, event, email string) {
+	if s == nil || s.guards == nil {
+		return
+	}
+	err := s.guards.RecordAccess(ctx, shareID, AccessEntry{Event: event, Email: email, At: s.now()})
+	if err != nil {
+		slog.Warn("share access log: write failed", logKeyError, err, logKeyShareID, shareID)
+	}
+}
+
+// AccessLog returns the share's guard (nil when unguarded) and its most
+// recent access log entries, newest first, for the share's owner.
+func (s *Service) AccessLog(ctx context.Context, shareID string) (*GuardSummary, []AccessEntry, error) {
+	if s == nil || s.guards == nil {
+		return nil, []AccessEntry{}, nil
+	}
+	g, err := s.guards.GetGuard(ctx, shareID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading share guard: %w", err)
+	}
+	entries, err := s.guards.ListAccess(ctx, shareID, accessLogLimit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading share access log: %w", err)
+	}
+	if g == nil {
+		return nil, entries, nil
+	}
+	return &GuardSummary{
+		Passphrase:  g.PassphraseHash != "",
+		MaxViews:    g.MaxViews,
+		Views:       g.Views,
+		VerifyEmail: g.VerifyEmail,
+	}, entries, nil
+}
+
+// hashCode returns the keyed hash a verification code is stored under. The
+// code space is small (six digits), so a plain digest would fall to a table
+// lookup; keying it with the guest key means a leaked row reveals nothing.
+func (s *Service) hashCode(shareID, email, code string) string {
+	mac := hmac.New(sha256.New, s.guestKey)
+	mac.Write([]byte(shareID + "\x00" + email + "\x00" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
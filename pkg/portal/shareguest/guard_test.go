@@ -0,0 +1,574 @@
+package shareguest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/pkg/ratelimit"
+)
+
+// memGuardStore is an in-memory GuardStore for tests.
+type memGuardStore struct {
+	mu      sync.Mutex
+	guards  map[string]*Guard
+	codes   []*memCode
+	log     []AccessEntry
+	getErr  error
+	viewErr error
+}
+
+type memCode struct {
+	VerifyCode
+	attempts int
+	used     bool
+}
+
+func newMemGuardStore() *memGuardStore {
+	return &memGuardStore{guards: map[string]*Guard{}}
+}
+
+func (m *memGuardStore) PutGuard(_ context.Context, g Guard) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.guards[g.ShareID] = &g
+	return nil
+}
+
+func (m *memGuardStore) GetGuard(_ context.Context, shareID string) (*Guard, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	g, ok := m.guards[shareID]
+	if !ok {
+		return nil, nil //nolint:nilnil // GuardStore contract: nil, nil means unguarded
+	}
+	cp := *g
+	return &cp, nil
+}
+
+func (m *memGuardStore) ConsumeView(_ context.Context, shareID string) (bool, error) {
+	if m.viewErr != nil {
+		return false, m.viewErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	g, ok := m.guards[shareID]
+	if !ok || g.spent() {
+		return false, nil
+	}
+	g.Views++
+	return true, nil
+}
+
+func (m *memGuardStore) InsertCode(_ context.Context, c VerifyCode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.codes = append(m.codes, &memCode{VerifyCode: c})
+	return nil
+}
+
+func (m *memGuardStore) CountCodesSince(_ context.Context, shareID, email string, since time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, c := range m.codes {
+		if c.ShareID == shareID && c.Email == email && c.CreatedAt.After(since) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *memGuardStore) ClaimCode(_ context.Context, shareID, email, codeHash string, now time.Time, maxAttempts int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.codes) - 1; i >= 0; i-- {
+		c := m.codes[i]
+		if c.ShareID != shareID || c.Email != email || c.used || !c.ExpiresAt.After(now) || c.attempts >= maxAttempts {
+			continue
+		}
+		if c.CodeHash == codeHash {
+			c.used = true
+			return true, nil
+		}
+		c.attempts++
+		return false, nil
+	}
+	return false, nil
+}
+
+func (m *memGuardStore) RecordAccess(_ context.Context, _ string, e AccessEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.log = append(m.log, e)
+	return nil
+}
+
+func (m *memGuardStore) ListAccess(_ context.Context, _ string, limit int) ([]AccessEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := []AccessEntry{}
+	for i := len(m.log) - 1; i >= 0 && len(out) < limit; i-- {
+		out = append(out, m.log[i])
+	}
+	return out, nil
+}
+
+func (m *memGuardStore) events() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []string
+	for _, e := range m.log {
+		out = append(out, e.Event)
+	}
+	return out
+}
+
+// codeRecorder captures sent verification codes.
+type codeRecorder struct {
+	mu    sync.Mutex
+	to    []string
+	codes []string
+}
+
+func (c *codeRecorder) send(_ context.Context, to, code string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.to = append(c.to, to)
+	c.codes = append(c.codes, code)
+	return nil
+}
+
+func (c *codeRecorder) last() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.codes[len(c.codes)-1]
+}
+
+// publicShare is the live public link the guard tests resolve to.
+func publicShare() ShareInfo {
+	return ShareInfo{ID: "pub1", Token: "ptok", Public: true}
+}
+
+// newGuardService builds a service with guards enabled over publicShare.
+func newGuardService(t *testing.T, guards *memGuardStore, codes *codeRecorder) *Service {
+	t.Helper()
+	share := publicShare()
+	svc := New(Config{
+		Resolve: func(_ context.Context, token string) (ShareInfo, bool) {
+			if token == share.Token {
+				return share, true
+			}
+			return ShareInfo{}, false
+		},
+		SessionKey: testKey,
+		Brand:      Brand{Name: "ACME Data"},
+		Guards:     guards,
+		SendCode:   codes.send,
+	})
+	t.Cleanup(svc.unlockLimiter.Close)
+	return svc
+}
+
+// viewRequest is a browser navigation to the public link, carrying cookies.
+func viewRequest(cookies ...*http.Cookie) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/portal/view/ptok", http.NoBody)
+	r.Header.Set("Accept", "text/html")
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	return r
+}
+
+// unlockRequest posts the unlock form.
+func unlockRequest(form url.Values, cookies ...*http.Cookie) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/portal/view/ptok/unlock", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.SetPathValue(pathKeyToken, "ptok")
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	return r
+}
+
+// passCookie returns the pass cookie a response set, or nil.
+func passCookie(rec *httptest.ResponseRecorder) *http.Cookie {
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == passCookieName {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestValidateGuard(t *testing.T) {
+	svc := newGuardService(t, newMemGuardStore(), &codeRecorder{})
+	noMail := New(Config{SessionKey: testKey, Guards: newMemGuardStore()})
+	t.Cleanup(noMail.unlockLimiter.Close)
+	var nilSvc *Service
+
+	tests := []struct {
+		name   string
+		svc    *Service
+		spec   GuardSpec
+		public bool
+		want   error
+	}{
+		{"empty spec always valid", nilSvc, GuardSpec{}, false, nil},
+		{"valid passphrase", svc, GuardSpec{Passphrase: "partner-2026"}, true, nil},
+		{"valid everything", svc, GuardSpec{Passphrase: "partner-2026", MaxViews: 3, VerifyEmail: true}, true, nil},
+		{"not public", svc, GuardSpec{MaxViews: 3}, false, ErrGuardNotPublic},
+		{"no guard store", nilSvc, GuardSpec{MaxViews: 3}, true, ErrGuardUnavailable},
+		{"verify without mailer", noMail, GuardSpec{VerifyEmail: true}, true, ErrVerifyUnavailable},
+		{"short passphrase", svc, GuardSpec{Passphrase: "short"}, true, ErrPassphraseLength},
+		{"long passphrase", svc, GuardSpec{Passphrase: strings.Repeat("x", MaxPassphraseBytes+1)}, true, ErrPassphraseLength},
+		{"negative views", svc, GuardSpec{MaxViews: -1}, true, ErrMaxViewsRange},
+		{"too many views", svc, GuardSpec{MaxViews: MaxViewLimit + 1}, true, ErrMaxViewsRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.svc.ValidateGuard(tt.spec, tt.public), tt.want)
+		})
+	}
+}
+
+func TestSetGuardHashesPassphrase(t *testing.T) {
+	guards := newMemGuardStore()
+	svc := newGuardService(t, guards, &codeRecorder{})
+
+	require.NoError(t, svc.SetGuard(context.Background(), "pub1", GuardSpec{Passphrase: "partner-2026", MaxViews: 2}))
+	g := guards.guards["pub1"]
+	require.NotNil(t, g)
+	assert.NotEmpty(t, g.PassphraseHash)
+	assert.NotContains(t, g.PassphraseHash, "partner-2026", "only a hash is stored")
+	assert.Equal(t, 2, g.MaxViews)
+
+	require.NoError(t, svc.SetGuard(context.Background(), "other", GuardSpec{}))
+	assert.NotContains(t, guards.guards, "other", "an empty spec stores nothing")
+}
+
+func TestCheckGuard(t *testing.T) {
+	t.Run("unguarded share admits", func(t *testing.T) {
+		svc := newGuardService(t, newMemGuardStore(), &codeRecorder{})
+		v := svc.CheckGuard(httptest.NewRecorder(), viewRequest(), publicShare())
+		assert.True(t, v.Admit)
+	})
+
+	t.Run("nil service admits", func(t *testing.T) {
+		var svc *Service
+		assert.True(t, svc.CheckGuard(httptest.NewRecorder(), viewRequest(), publicShare()).Admit)
+	})
+
+	t.Run("lookup failure fails closed", func(t *testing.T) {
+		guards := newMemGuardStore()
+		guards.getErr = errors.New("db down")
+		svc := newGuardService(t, guards, &codeRecorder{})
+		v := svc.CheckGuard(httptest.NewRecorder(), viewRequest(), publicShare())
+		assert.False(t, v.Admit)
+		assert.Equal(t, http.StatusServiceUnavailable, v.Denial.Status)
+	})
+
+	t.Run("passphrase prompts", func(t *testing.T) {
+		guards := newMemGuardStore()
+		svc := newGuardService(t, guards, &codeRecorder{})
+		require.NoError(t, svc.SetGuard(context.Background(), "pub1", GuardSpec{Passphrase: "partner-2026"}))
+		v := svc.CheckGuard(httptest.NewRecorder(), viewRequest(), publicShare())
+		assert.False(t, v.Admit)
+		assert.Equal(t, http.StatusUnauthorized, v.Denial.Status)
+		require.NotNil(t, v.Denial.Guard)
+		assert.True(t, v.Denial.Guard.Passphrase)
+		assert.False(t, v.Denial.Guard.Email)
+	})
+
+	t.Run("view limit alone counts the visit and sets a pass", func(t *testing.T) {
+		guards := newMemGuardStore()
+		svc := newGuardService(t, guards, &codeRecorder{})
+		require.NoError(t, svc.SetGuard(context.Background(), "pub1", GuardSpec{MaxViews: 1}))
+
+		rec := httptest.NewRecorder()
+		v := svc.CheckGuard(rec, viewRequest(), publicShare())
+		require.True(t, v.Admit)
+		pass := passCookie(rec)
+		require.NotNil(t, pass, "the first visit gets a pass")
+		assert.Equal(t, "/portal/view/ptok", pass.Path, "the pass is scoped to one share")
+		assert.Equal(t, 1, guards.guards["pub1"].Views)
+
+		again := svc.CheckGuard(httptest.NewRecorder(), viewRequest(pass), publicShare())
+		assert.True(t, again.Admit, "the pass re-admits without spending a view")
+		assert.Equal(t, 1, guards.guards["pub1"].Views)
+
+		spent := svc.CheckGuard(httptest.NewRecorder(), viewRequest(), publicShare())
+		assert.False(t, spent.Admit)
+		assert.Equal(t, http.StatusGone, spent.Denial.Status)
+		assert.Equal(t, MsgViewLimit, spent.Denial.Message)
+	})
+
+	t.Run("view count failure fails closed", func(t *testing.T) {
+		guards := newMemGuardStore()
+		svc := newGuardService(t, guards, &codeRecorder{})
+		require.NoError(t, svc.SetGuard(context.Background(), "pub1", GuardSpec{MaxViews: 5}))
+		guards.viewErr = errors.New("db down")
+		rec := httptest.NewRecorder()
+		v := svc.CheckGuard(rec, viewRequest(), publicShare())
+		assert.False(t, v.Admit)
+		assert.Equal(t, http.StatusServiceUnavailable, v.Denial.Status)
+		assert.Nil(t, passCookie(rec))
+	})
+
+	t.Run("pass for another share does not admit", func(t *testing.T) {
+		guards := newMemGuardStore()
+		svc := newGuardService(t, guards, &codeRecorder{})
+		require.NoError(t, svc.SetGuard(context.Background(), "pub1", GuardSpec{Passphrase: "partner-2026"}))
+		signed, err := svc.signPass("other", "", "", GuestSessionTTL)
+		require.NoError(t, err)
+		v := svc.CheckGuard(httptest.NewRecorder(), viewRequest(&http.Cookie{Name: passCookieName, Value: signed}), publicShare())
+		assert.False(t, v.Admit)
+	})
+
+	t.Run("pending pass does not admit", func(t *testing.T) {
+		guards := newMemGuardStore()
+		svc := newGuardService(t, guards, &codeRecorder{})
+		require.NoError(t, svc.SetGuard(context.Background(), "pub1", GuardSpec{VerifyEmail: true}))
+		signed, err := svc.signPass("pub1", "eve@example.com", passStagePending, CodeTTL)
+		require.NoError(t, err)
+		v := svc.CheckGuard(httptest.NewRecorder(), viewRequest(&http.Cookie{Name: passCookieName, Value: signed}), publicShare())
+		assert.False(t, v.Admit, "a code-step pass proves nothing yet")
+	})
+
+	t.Run("guest session does not pose as a pass", func(t *testing.T) {
+		guards := newMemGuardStore()
+		svc := newGuardService(t, guards, &codeRecorder{})
+		require.NoError(t, svc.SetGuard(context.Background(), "pub1", GuardSpec{Passphrase: "partner-2026"}))
+		signed, err := svc.signGuestSession("pub1", "bob@example.com")
+		require.NoError(t, err)
+		v := svc.CheckGuard(httptest.NewRecorder(), viewRequest(&http.Cookie{Name: passCookieName, Value: signed}), publicShare())
+		assert.False(t, v.Admit)
+	})
+}
+
+func TestHandleUnlockPassphrase(t *testing.T) {
+	guards := newMemGuardStore()
+	svc := newGuardService(t, guards, &codeRecorder{})
+	require.NoError(t, svc.SetGuard(context.Background(), "pub1", GuardSpec{Passphrase: "partner-2026", MaxViews: 5}))
+
+	rec := httptest.NewRecorder()
+	svc.HandleUnlock(rec, unlockRequest(url.Values{"passphrase": {"wrong-guess"}}))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "That passphrase is not correct.")
+	assert.Contains(t, rec.Body.String(), `action="/portal/view/ptok/unlock"`)
+	assert.Nil(t, passCookie(rec))
+	assert.Equal(t, 0, guards.guards["pub1"].Views, "a failed attempt spends no view")
+
+	rec = httptest.NewRecorder()
+	svc.HandleUnlock(rec, unlockRequest(url.Values{"passphrase": {"partner-2026"}}))
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	assert.Equal(t, "/portal/view/ptok", rec.Header().Get("Location"))
+	pass := passCookie(rec)
+	require.NotNil(t, pass)
+	assert.True(t, pass.HttpOnly)
+	assert.Equal(t, 1, guards.guards["pub1"].Views)
+
+	v := svc.CheckGuard(httptest.NewRecorder(), viewRequest(pass), publicShare())
+	assert.True(t, v.Admit, "the pass admits the redirected view")
+	assert.Equal(t, []string{EventPassphraseFailed, EventUnlocked}, guards.events())
+}
+
+func TestHandleUnlockEmailVerification(t *testing.T) {
+	guards := newMemGuardStore()
+	codes := &codeRecorder{}
+	svc := newGuardService(t, guards, codes)
+	// The flow below posts more often than the per-share burst allows;
+	// throttling has its own test.
+	svc.unlockLimiter.Close()
+	svc.unlockLimiter = ratelimit.New(ratelimit.Config{RequestsPerMinute: 600, BurstSize: 100})
+	t.Cleanup(svc.unlockLimiter.Close)
+	require.NoError(t, svc.SetGuard(context.Background(), "pub1", GuardSpec{Passphrase: "partner-2026", VerifyEmail: true}))
+
+	rec := httptest.NewRecorder()
+	svc.HandleUnlock(rec, unlockRequest(url.Values{"passphrase": {"partner-2026"}, "email": {"not an address"}}))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Enter a valid email address.")
+
+	rec = httptest.NewRecorder()
+	svc.HandleUnlock(rec, unlockRequest(url.Values{"passphrase": {"partner-2026"}, "email": {" Eve@Partner.example "}}))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "eve@partner.example")
+	assert.Contains(t, rec.Body.String(), `name="code"`)
+	require.Len(t, codes.to, 1)
+	assert.Equal(t, "eve@partner.example", codes.to[0])
+	assert.Len(t, codes.last(), codeDigits)
+	pending := passCookie(rec)
+	require.NotNil(t, pending)
+
+	v := svc.CheckGuard(httptest.NewRecorder(), viewRequest(pending), publicShare())
+	assert.False(t, v.Admit, "the code step alone does not open the link")
+
+	rec = httptest.NewRecorder()
+	svc.HandleUnlock(rec, unlockRequest(url.Values{"code": {"000000x"}}, pending))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "That code is not correct or has expired.")
+
+	rec = httptest.NewRecorder()
+	svc.HandleUnlock(rec, unlockRequest(url.Values{"code": {codes.last()}}))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "the code step needs the pending pass")
+
+	rec = httptest.NewRecorder()
+	svc.HandleUnlock(rec, unlockRequest(url.Values{"code": {codes.last()}}, pending))
+	require.Equal(t, http.StatusSeeOther, rec.Code)
+	pass := passCookie(rec)
+	require.NotNil(t, pass)
+
+	v = svc.CheckGuard(httptest.NewRecorder(), viewRequest(pass), publicShare())
+	assert.True(t, v.Admit)
+	assert.Equal(t, "eve@partner.example", v.Email, "the verified address is attributed")
+
+	rec = httptest.NewRecorder()
+	svc.HandleUnlock(rec, unlockRequest(url.Values{"code": {codes.last()}}, pending))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "a code is single use")
+
+	_, entries, err := svc.AccessLog(context.Background(), "pub1")
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+	assert.Equal(t, EventCodeFailed, entries[0].Event)
+	assert.Contains(t, guards.events(), EventCodeSent)
+	assert.Contains(t, guards.events(), EventUnlocked)
+}
+
+func TestHandleUnlockCodeCap(t *testing.T) {
+	guards := newMemGuardStore()
+	codes := &codeRecorder{}
+	svc := newGuardService(t, guards, codes)
+	require.NoError(t, svc.SetGuard(context.Background(), "pub1", GuardSpec{VerifyEmail: true}))
+
+	now := time.Now()
+	for i := range maxCodesPerWindow {
+		require.NoError(t, guards.InsertCode(context.Background(), VerifyCode{
+			ID: string(rune('a' + i)), ShareID: "pub1", Email: "eve@partner.example",
+			CreatedAt: now, ExpiresAt: now.Add(CodeTTL),
+		}))
+	}
+	rec := httptest.NewRecorder()
+	svc.HandleUnlock(rec, unlockRequest(url.Values{"email": {"eve@partner.example"}}))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Empty(t, codes.to, "the cap stops the send")
+}
+
+func TestHandleUnlockThrottledPerShare(t *testing.T) {
+	guards := newMemGuardStore()
+	svc := newGuardService(t, guards, &codeRecorder{})
+	require.NoError(t, svc.SetGuard(context.Background(), "pub1", GuardSpec{Passphrase: "partner-2026"}))
+	// Each wrong guess is a bcrypt compare, slow enough under -race for the
+	// limiter to refill a token mid-burst on the wall clock. A pinned clock
+	// makes the burst the only thing under test.
+	frozen := time.Now()
+	svc.now = func() time.Time { return frozen }
+
+	var last int
+	for range unlockBurst + 1 {
+		rec := httptest.NewRecorder()
+		svc.HandleUnlock(rec, unlockRequest(url.Values{"passphrase": {"wrong-guess"}}))
+		last = rec.Code
+	}
+	assert.Equal(t, http.StatusTooManyRequests, last, "guesses past the burst are refused")
+	assert.Contains(t, guards.events(), EventThrottled)
+}
+
+func TestHandleUnlockRefusals(t *testing.T) {
+	t.Run("unknown token", func(t *testing.T) {
+		svc := newGuardService(t, newMemGuardStore(), &codeRecorder{})
+		r := unlockRequest(url.Values{})
+		r.SetPathValue(pathKeyToken, "nope")
+		rec := httptest.NewRecorder()
+		svc.HandleUnlock(rec, r)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("unguarded link redirects to the viewer", func(t *testing.T) {
+		svc := newGuardService(t, newMemGuardStore(), &codeRecorder{})
+		rec := httptest.NewRecorder()
+		svc.HandleUnlock(rec, unlockRequest(url.Values{}))
+		assert.Equal(t, http.StatusSeeOther, rec.Code)
+	})
+
+	t.Run("spent view limit", func(t *testing.T) {
+		guards := newMemGuardStore()
+		svc := newGuardService(t, guards, &codeRecorder{})
+		require.NoError(t, guards.PutGuard(context.Background(), Guard{ShareID: "pub1", PassphraseHash: "x", MaxViews: 1, Views: 1}))
+		rec := httptest.NewRecorder()
+		svc.HandleUnlock(rec, unlockRequest(url.Values{"passphrase": {"partner-2026"}}))
+		assert.Equal(t, http.StatusGone, rec.Code)
+		assert.Contains(t, rec.Body.String(), MsgViewLimit)
+	})
+
+	t.Run("guard lookup failure", func(t *testing.T) {
+		guards := newMemGuardStore()
+		guards.getErr = errors.New("db down")
+		svc := newGuardService(t, guards, &codeRecorder{})
+		rec := httptest.NewRecorder()
+		svc.HandleUnlock(rec, unlockRequest(url.Values{}))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+}
+
+func TestDenyRendersUnlockForm(t *testing.T) {
+	svc := newGuardService(t, newMemGuardStore(), &codeRecorder{})
+
+	rec := httptest.NewRecorder()
+	svc.Deny(rec, viewRequest(), Denial{
+		Status: http.StatusUnauthorized, Message: MsgLocked, Token: "ptok",
+		Guard: &GuardPrompt{Passphrase: true, Email: true},
+	})
+	body := rec.Body.String()
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, body, "This link is protected")
+	assert.Contains(t, body, `name="passphrase"`)
+	assert.Contains(t, body, `name="email"`)
+	assert.NotContains(t, body, "Sign in", "a public link's unlock page offers no sign-in")
+	assert.Contains(t, rec.Header().Get("Content-Security-Policy"), "form-action 'self'")
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+
+	plain := httptest.NewRequest(http.MethodGet, "/portal/view/ptok/content", http.NoBody)
+	rec = httptest.NewRecorder()
+	svc.Deny(rec, plain, Denial{Status: http.StatusUnauthorized, Message: MsgLocked, Guard: &GuardPrompt{Passphrase: true}})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, MsgLocked+"\n", rec.Body.String(), "subresources keep the plain-text refusal")
+}
+
+func TestAccessLogSummary(t *testing.T) {
+	guards := newMemGuardStore()
+	svc := newGuardService(t, guards, &codeRecorder{})
+
+	summary, entries, err := svc.AccessLog(context.Background(), "pub1")
+	require.NoError(t, err)
+	assert.Nil(t, summary, "an unguarded share has no summary")
+	assert.Empty(t, entries)
+
+	require.NoError(t, svc.SetGuard(context.Background(), "pub1", GuardSpec{Passphrase: "partner-2026", MaxViews: 4}))
+	svc.RecordAccess(context.Background(), "pub1", EventView, "eve@partner.example")
+	summary, entries, err = svc.AccessLog(context.Background(), "pub1")
+	require.NoError(t, err)
+	require.NotNil(t, summary)
+	assert.True(t, summary.Passphrase)
+	assert.Equal(t, 4, summary.MaxViews)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "eve@partner.example", entries[0].Email)
+
+	var nilSvc *Service
+	nilSvc.RecordAccess(context.Background(), "pub1", EventView, "")
+	summary, entries, err = nilSvc.AccessLog(context.Background(), "pub1")
+	require.NoError(t, err)
+	assert.Nil(t, summary)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,218 @@
+package shareguest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// VerifyCode is one emailed verification code. Only its keyed hash is
+// stored; the plaintext exists solely in the email.
+type VerifyCode struct {
+	ID        string
+	ShareID   string
+	Email     string
+	CodeHash  string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// GuardStore persists share guards, their verification codes, and the
+// per-share access log.
+type GuardStore interface {
+	// PutGuard creates or replaces the guard of g.ShareID.
+	PutGuard(ctx context.Context, g Guard) error
+	// GetGuard returns the share's guard, or nil when it has none.
+	GetGuard(ctx context.Context, shareID string) (*Guard, error)
+	// ConsumeView atomically counts one view, provided the guard's limit
+	// (if any) is not spent. ok reports whether the view was admitted.
+	ConsumeView(ctx context.Context, shareID string) (ok bool, err error)
+	// InsertCode records a freshly sent verification code.
+	InsertCode(ctx context.Context, c VerifyCode) error
+	// CountCodesSince returns how many codes were sent for the share and
+	// address after since, backing the per-address send cap.
+	CountCodesSince(ctx context.Context, shareID, email string, since time.Time) (int, error)
+	// ClaimCode checks codeHash against the newest live code for the share
+	// and address: unused, unexpired at now, and under maxAttempts wrong
+	// guesses. A match marks it used; a miss counts an attempt. ok reports
+	// whether the claim won.
+	ClaimCode(ctx context.Context, shareID, email, codeHash string, now time.Time, maxAttempts int) (ok bool, err error)
+	// RecordAccess appends one access log entry.
+	RecordAccess(ctx context.Context, shareID string, e AccessEntry) error
+	// ListAccess returns up to limit entries for the share, newest first.
+	ListAccess(ctx context.Context, shareID string, limit int) ([]AccessEntry, error)
+}
+
+// PostgresGuardStore implements GuardStore on portal_share_guards,
+// portal_share_verify_codes, and portal_share_access_log.
+type PostgresGuardStore struct {
+	db *sql.DB
+}
+
+// NewPostgresGuardStore creates the production guard store.
+func NewPostgresGuardStore(db *sql.DB) *PostgresGuardStore {
+	return &PostgresGuardStore{db: db}
+}
+
+// accessLogRetention is how long access log entries are kept. Entries older
+// than this are swept on write, per share, so the log stays bounded without
+// a background job.
+const accessLogRetention = 90 * 24 * time.Hour
+
+// PutGuard upserts the share's guard, leaving its view count untouched.
+func (s *PostgresGuardStore) PutGuard(ctx context.Context, g Guard) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO portal_share_guards (share_id, passphrase_hash, max_views, verify_email)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (share_id) DO UPDATE
+		 SET passphrase_hash = EXCLUDED.passphrase_hash,
+		     max_views = EXCLUDED.max_views,
+		     verify_email = EXCLUDED.verify_email`,
+		g.ShareID, g.PassphraseHash, g.MaxViews, g.VerifyEmail)
+	if err != nil {
+		return fmt.Errorf("upserting share guard: %w", err)
+	}
+	return nil
+}
+
+// GetGuard reads the share's guard.
+func (s *PostgresGuardStore) GetGuard(ctx context.Context, shareID string) (*Guard, error) {
+	g := Guard{ShareID: shareID}
+	err := s.db.QueryRowContext(ctx,
+		`SELECT passphrase_hash, max_views, view_count, verify_email
+		 FROM portal_share_guards WHERE share_id = $1`,
+		shareID).Scan(&g.PassphraseHash, &g.MaxViews, &g.Views, &g.VerifyEmail)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil //nolint:nilnil // nil guard means the share is unguarded
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading share guard: %w", err)
+	}
+	return &g, nil
+}
+
+// ConsumeView counts the view in one conditional statement, so concurrent
+// visitors cannot overrun the limit: the row's view_count is both the check
+// and the write.
+func (s *PostgresGuardStore) ConsumeView(ctx context.Context, shareID string) (bool, error) {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE portal_share_guards SET view_count = view_count + 1
+		 WHERE share_id = $1 AND (max_views = 0 OR view_count < max_views)`,
+		shareID)
+	if err != nil {
+		return false, fmt.Errorf("counting share view: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("counting share view: %w", err)
+	}
+	return n > 0, nil
+}
+
+// InsertCode records a code, first sweeping the share's codes long past
+// expiry so the table stays bounded by recent activity.
+func (s *PostgresGuardStore) InsertCode(ctx context.Context, c VerifyCode) error {
+	if _, err := s.db.ExecContext(ctx,
+		`DELETE FROM portal_share_verify_codes WHERE share_id = $1 AND expires_at < $2`,
+		c.ShareID, c.CreatedAt.Add(-purgeAge)); err != nil {
+		return fmt.Errorf("purging expired share verify codes: %w", err)
+	}
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO portal_share_verify_codes (id, share_id, email, code_hash, created_at, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		c.ID, c.ShareID, c.Email, c.CodeHash, c.CreatedAt, c.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("inserting share verify code: %w", err)
+	}
+	return nil
+}
+
+// CountCodesSince counts codes sent for the share and address in the window.
+func (s *PostgresGuardStore) CountCodesSince(ctx context.Context, shareID, email string, since time.Time) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM portal_share_verify_codes
+		 WHERE share_id = $1 AND email = $2 AND created_at > $3`,
+		shareID, email, since).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("counting share verify codes: %w", err)
+	}
+	return n, nil
+}
+
+// ClaimCode locks the newest live code and, in the same statement, either
+// marks it used (match) or counts the miss, so neither a replay nor parallel
+// guessing can get past the single-use and attempt limits.
+func (s *PostgresGuardStore) ClaimCode(
+	ctx context.Context, shareID, email, codeHash string, now time.Time, maxAttempts int,
+) (bool, error) {
+	var used bool
+	err := s.db.QueryRowContext(ctx,
+		`WITH latest AS (
+		     SELECT id FROM portal_share_verify_codes
+		     WHERE share_id = $1 AND email = $2 AND used_at IS NULL
+		       AND expires_at > $4 AND attempts < $5
+		     ORDER BY created_at DESC LIMIT 1
+		     FOR UPDATE
+		 )
+		 UPDATE portal_share_verify_codes c
+		 SET used_at = CASE WHEN c.code_hash = $3 THEN $4 END,
+		     attempts = c.attempts + CASE WHEN c.code_hash = $3 THEN 0 ELSE 1 END
+		 FROM latest WHERE c.id = latest.id
+		 RETURNING c.used_at IS NOT NULL`,
+		shareID, email, codeHash, now, maxAttempts).Scan(&used)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("claiming share verify code: %w", err)
+	}
+	return used, nil
+}
+
+// RecordAccess appends an entry, first sweeping the share's entries older
+// than accessLogRetention.
+func (s *PostgresGuardStore) RecordAccess(ctx context.Context, shareID string, e AccessEntry) error {
+	if _, err := s.db.ExecContext(ctx,
+		`DELETE FROM portal_share_access_log WHERE share_id = $1 AND created_at < $2`,
+		shareID, e.At.Add(-accessLogRetention)); err != nil {
+		return fmt.Errorf("purging share access log: %w", err)
+	}
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO portal_share_access_log (share_id, event, email, created_at)
+		 VALUES ($1, $2, $3, $4)`,
+		shareID, e.Event, e.Email, e.At)
+	if err != nil {
+		return fmt.Errorf("inserting share access log entry: %w", err)
+	}
+	return nil
+}
+
+// ListAccess reads the share's newest entries.
+func (s *PostgresGuardStore) ListAccess(ctx context.Context, shareID string, limit int) ([]AccessEntry, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT event, email, created_at FROM portal_share_access_log
+		 WHERE share_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2`,
+		shareID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing share access log: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	entries := []AccessEntry{}
+	for rows.Next() {
+		var e AccessEntry
+		if err := rows.Scan(&e.Event, &e.Email, &e.At); err != nil {
+			return nil, fmt.Errorf("scanning share access log entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating share access log: %w", err)
+	}
+	return entries, nil
+}
+
+// Verify interface compliance.
+var _ GuardStore = (*PostgresGuardStore)(nil)
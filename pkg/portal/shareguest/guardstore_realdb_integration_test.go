@@ -0,0 +1,126 @@
+//go:build integration
+
+package shareguest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/internal/testdb"
+)
+
+// TestPostgresGuardStoreRealDB runs the conditional view count and the code
+// claim against the real schema. Both carry the guard's guarantees in a single
+// statement, so sqlmock can only show they were issued.
+func TestPostgresGuardStoreRealDB(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	store := NewPostgresGuardStore(db)
+	exec := func(query string, args ...any) {
+		t.Helper()
+		_, err := db.ExecContext(ctx, query, args...)
+		require.NoError(t, err)
+	}
+
+	exec(`INSERT INTO portal_assets (id, owner_id, owner_email, name, content_type, s3_bucket, s3_key)
+	      VALUES ('ast_1', 'u_owner', 'owner@example.com', 'Revenue', 'text/markdown', 'b', 'k')`)
+	exec(`INSERT INTO portal_shares (id, asset_id, token, created_by, access_mode)
+	      VALUES ('shr_1', 'ast_1', 'tok_1', 'owner@example.com', 'public')`)
+
+	g, err := store.GetGuard(ctx, "shr_1")
+	require.NoError(t, err)
+	assert.Nil(t, g, "a share without a row is unguarded")
+
+	require.NoError(t, store.PutGuard(ctx, Guard{ShareID: "shr_1", MaxViews: 3, VerifyEmail: true}))
+
+	// Concurrent visitors cannot overrun the limit.
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	admitted := 0
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := store.ConsumeView(ctx, "shr_1")
+			assert.NoError(t, err)
+			if ok {
+				mu.Lock()
+				admitted++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 3, admitted)
+
+	// Replacing the guard keeps the view count.
+	require.NoError(t, store.PutGuard(ctx, Guard{ShareID: "shr_1", PassphraseHash: "h", MaxViews: 5, VerifyEmail: true}))
+	g, err = store.GetGuard(ctx, "shr_1")
+	require.NoError(t, err)
+	require.NotNil(t, g)
+	assert.Equal(t, Guard{ShareID: "shr_1", PassphraseHash: "h", MaxViews: 5, Views: 3, VerifyEmail: true}, *g)
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	require.NoError(t, store.InsertCode(ctx, VerifyCode{
+		ID: "c1", ShareID: "shr_1", Email: "ann@example.com", CodeHash: "right",
+		CreatedAt: now, ExpiresAt: now.Add(CodeTTL),
+	}))
+	n, err := store.CountCodesSince(ctx, "shr_1", "ann@example.com", now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	ok, err := store.ClaimCode(ctx, "shr_1", "ann@example.com", "wrong", now, maxCodeAttempts)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = store.ClaimCode(ctx, "shr_1", "bob@example.com", "right", now, maxCodeAttempts)
+	require.NoError(t, err)
+	assert.False(t, ok, "a code is bound to its address")
+	ok, err = store.ClaimCode(ctx, "shr_1", "ann@example.com", "right", now.Add(CodeTTL+time.Second), maxCodeAttempts)
+	require.NoError(t, err)
+	assert.False(t, ok, "an expired code is dead")
+	ok, err = store.ClaimCode(ctx, "shr_1", "ann@example.com", "right", now, maxCodeAttempts)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = store.ClaimCode(ctx, "shr_1", "ann@example.com", "right", now, maxCodeAttempts)
+	require.NoError(t, err)
+	assert.False(t, ok, "a code is single use")
+
+	// Wrong guesses kill a code.
+	require.NoError(t, store.InsertCode(ctx, VerifyCode{
+		ID: "c2", ShareID: "shr_1", Email: "ann@example.com", CodeHash: "right",
+		CreatedAt: now.Add(time.Second), ExpiresAt: now.Add(CodeTTL),
+	}))
+	for range maxCodeAttempts {
+		ok, err = store.ClaimCode(ctx, "shr_1", "ann@example.com", "wrong", now, maxCodeAttempts)
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+	ok, err = store.ClaimCode(ctx, "shr_1", "ann@example.com", "right", now, maxCodeAttempts)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// The access log drops entries past retention on the next write.
+	require.NoError(t, store.RecordAccess(ctx, "shr_1",
+		AccessEntry{Event: EventView, At: now.Add(-accessLogRetention - time.Hour)}))
+	require.NoError(t, store.RecordAccess(ctx, "shr_1",
+		AccessEntry{Event: EventCodeSent, Email: "ann@example.com", At: now.Add(-time.Minute)}))
+	require.NoError(t, store.RecordAccess(ctx, "shr_1",
+		AccessEntry{Event: EventUnlocked, Email: "ann@example.com", At: now}))
+	entries, err := store.ListAccess(ctx, "shr_1", accessLogLimit)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, EventUnlocked, entries[0].Event)
+	assert.Equal(t, EventCodeSent, entries[1].Event)
+	assert.Equal(t, "ann@example.com", entries[1].Email)
+
+	// Deleting the share takes its guard, codes, and log with it.
+	exec(`DELETE FROM portal_shares WHERE id = 'shr_1'`)
+	g, err = store.GetGuard(ctx, "shr_1")
+	require.NoError(t, err)
+	assert.Nil(t, g)
+}
@@ -29,7 +29,8 @@ type Brand struct {
 // Denial describes one refused share request for rendering. The portal's
 // share gate builds it from the share and verdict it already holds.
 type Denial struct {
-	// Status is the HTTP status of the refusal: 403 or 410.
+	// Status is the HTTP status of the refusal: 403 or 410, or for a guarded
+	// public link 401 (unlock required), 429, or 503.
 	Status int
 	// Message is the plain-text denial, used verbatim for non-HTML requests
 	// and as page copy.
@@ -45,12 +46,16 @@ type Denial struct {
 	// caller is signed in as this address but the share names someone else.
 	// Naming it on the page makes the wrong-account case self-diagnosing.
 	SignedInEmail string
+	// Guard, when set, renders the unlock form of a guarded public link
+	// instead of the sign-in offer.
+	Guard *GuardPrompt
 }
 
 // landingCSP locks the landing page down to its own inline style and script;
-// the request-link button needs connect-src for its same-origin POST.
+// the request-link button needs connect-src for its same-origin POST, and the
+// unlock form needs form-action (which default-src does not cover) for its.
 const landingCSP = "default-src 'none'; style-src 'unsafe-inline'; " +
-	"script-src 'unsafe-inline'; img-src data:; connect-src 'self'"
+	"script-src 'unsafe-inline'; img-src data:; connect-src 'self'; form-action 'self'"
 
 // linkStatusParam is the query parameter a failed one-time-link claim
 // redirects back with, so the landing page explains what happened and offers
@@ -69,6 +74,13 @@ func (s *Service) Deny(w http.ResponseWriter, r *http.Request, d Denial) {
 		http.Error(w, d.Message, d.Status)
 		return
 	}
+	s.renderPage(w, r, d)
+}
+
+// renderPage writes the branded page for d regardless of the request's
+// shape; the unlock form's POST responses render through it directly.
+func (s *Service) renderPage(w http.ResponseWriter, r *http.Request, d Denial) {
+	w.Header().Set("Cache-Control", "no-store")
 	data := s.landingData(r, d)
 	w.Header().Set("Content-Security-Policy", landingCSP)
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -97,8 +109,12 @@ func (s *Service) landingData(r *http.Request, d Denial) map[string]any {
 	}
 	viewPath := "/portal/view/" + url.PathEscape(d.Token)
 	switch {
+	case d.Guard != nil:
+		applyGuardPrompt(d.Guard, viewPath, data)
 	case d.Status == http.StatusGone:
 		data["Title"] = "This link is no longer available"
+	case d.Status == http.StatusServiceUnavailable:
+		data["Title"] = "This link cannot be opened right now"
 	case d.SignedInEmail != "":
 		data["Title"] = "This link was shared with someone else"
 		data["Message"] = "It is restricted to the person it was shared with, and you are signed in as " +
@@ -118,6 +134,35 @@ func (s *Service) landingData(r *http.Request, d Denial) map[string]any {
 	return data
 }
 
+// applyGuardPrompt fills the unlock form of a guarded public link: the
+// passphrase and address fields for the first step, the code field once a
+// code was sent.
+func applyGuardPrompt(p *GuardPrompt, viewPath string, data map[string]any) {
+	data["Title"] = "This link is protected"
+	data["UnlockPath"] = viewPath + "/unlock"
+	data["LinkNotice"] = p.Notice
+	if p.CodeSentTo != "" {
+		data["Title"] = "Check your email"
+		data["Message"] = "Enter the code sent to " + p.CodeSentTo + ". It expires in 10 minutes."
+		data["AskCode"] = true
+		data["UnlockLabel"] = "Verify"
+		return
+	}
+	data["AskPassphrase"] = p.Passphrase
+	data["AskEmail"] = p.Email
+	data["UnlockLabel"] = "Open"
+	switch {
+	case p.Passphrase && p.Email:
+		data["Message"] = "Enter the passphrase you were given and your email address. A code will be sent to confirm it."
+		data["UnlockLabel"] = "Continue"
+	case p.Email:
+		data["Message"] = "Enter your email address. A code will be sent to confirm it."
+		data["UnlockLabel"] = "Send code"
+	default:
+		data["Message"] = "Enter the passphrase you were given to open it."
+	}
+}
+
 // applyOptOutState adds the opt-out notice and opt-back-in action for an
 // email share whose recipient has unsubscribed from notification emails
 // (#1022). The landing page is the recipient's natural re-engagement point:
@@ -12,6 +12,10 @@
 //   - a signed guest session scoped to one share, admitting a view-only
 //     variant of the public viewer and never the portal.
 //
+// It also guards public links: a passphrase, a view limit, and an
+// emailed-code address check, any of which a share's creator may attach, and
+// a per-share access log the creator reads.
+//
 // The package is composed by the portal handler (which registers the two
 // public routes and consults Admit/Deny from its share gate) and wired by the
 // composition root, which supplies the share resolver, the link store, the
@@ -24,6 +28,8 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"time"
+
+	"github.com/txn2/mcp-data-platform/pkg/ratelimit"
 )
 
 // Link lifetimes and issue caps. The one-time link is deliberately short-lived
@@ -90,6 +96,10 @@ type Resolver func(ctx context.Context, token string) (ShareInfo, bool)
 // rather than through the notification queue and its digest deferral.
 type LinkMailer func(ctx context.Context, to, link string) error
 
+// CodeMailer delivers one verification-code email for a guarded public link.
+// Like LinkMailer, the send is transactional and must deliver directly.
+type CodeMailer func(ctx context.Context, to, code string) error
+
 // Config carries the dependencies for New. Links, SendLink, SessionKey, and
 // BaseURL are each individually optional: when any of them is absent the
 // one-time-link flow is disabled and the service still renders landing and
@@ -118,6 +128,12 @@ type Config struct {
 	// Resubscribe re-enables notification delivery for an address (#1022).
 	// nil omits the landing page's opt-back-in action.
 	Resubscribe func(ctx context.Context, email string) error
+	// Guards persists public-link guards and the access log. nil disables
+	// guards: none can be created, and the access log stays empty.
+	Guards GuardStore
+	// SendCode delivers verification codes. nil disables the email
+	// verification guard while keeping passphrases and view limits.
+	SendCode CodeMailer
 }
 
 // Service implements the guest access path. A nil *Service is inert: Admit
@@ -132,7 +148,12 @@ type Service struct {
 	brand        Brand
 	optOutStatus func(ctx context.Context, email string) (bool, error)
 	resubscribe  func(ctx context.Context, email string) error
-	now          func() time.Time
+	guards       GuardStore
+	sendCode     CodeMailer
+	// unlockLimiter throttles unlock attempts per share. It lives as long as
+	// the service, which the composition root builds once per process.
+	unlockLimiter *ratelimit.Limiter
+	now           func() time.Time
 }
 
 // New builds the service. See Config for which absences disable the
@@ -147,11 +168,16 @@ func New(cfg Config) *Service {
 		brand:        cfg.Brand,
 		optOutStatus: cfg.OptOutStatus,
 		resubscribe:  cfg.Resubscribe,
+		guards:       cfg.Guards,
+		sendCode:     cfg.SendCode,
 		now:          time.Now,
 	}
 	if len(cfg.SessionKey) > 0 {
 		s.guestKey = DeriveKey(cfg.SessionKey, guestKeyLabel)
 	}
+	if cfg.Guards != nil {
+		s.unlockLimiter = ratelimit.New(ratelimit.Config{RequestsPerMinute: unlockRPM, BurstSize: unlockBurst})
+	}
 	return s
 }
 
@@ -2,6 +2,7 @@ package shareguest
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
 	"time"
@@ -88,3 +89,130 @@ func TestPostgresLinkStoreCountSince(t *testing.T) {
 	assert.Error(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func newMockGuardStore(t *testing.T) (*PostgresGuardStore, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return NewPostgresGuardStore(db), mock
+}
+
+func TestPostgresGuardStoreGuard(t *testing.T) {
+	store, mock := newMockGuardStore(t)
+	ctx := context.Background()
+
+	mock.ExpectExec(`INSERT INTO portal_share_guards .* ON CONFLICT \(share_id\) DO UPDATE`).
+		WithArgs("sh1", "hash", 3, true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, store.PutGuard(ctx, Guard{ShareID: "sh1", PassphraseHash: "hash", MaxViews: 3, VerifyEmail: true}))
+
+	mock.ExpectQuery(`SELECT passphrase_hash, max_views, view_count, verify_email`).
+		WithArgs("sh1").
+		WillReturnRows(sqlmock.NewRows([]string{"passphrase_hash", "max_views", "view_count", "verify_email"}).
+			AddRow("hash", 3, 1, true))
+	g, err := store.GetGuard(ctx, "sh1")
+	require.NoError(t, err)
+	assert.Equal(t, &Guard{ShareID: "sh1", PassphraseHash: "hash", MaxViews: 3, Views: 1, VerifyEmail: true}, g)
+
+	mock.ExpectQuery(`SELECT passphrase_hash`).WithArgs("none").WillReturnError(sql.ErrNoRows)
+	g, err = store.GetGuard(ctx, "none")
+	require.NoError(t, err)
+	assert.Nil(t, g, "no row means unguarded")
+
+	mock.ExpectQuery(`SELECT passphrase_hash`).WillReturnError(errors.New("db down"))
+	_, err = store.GetGuard(ctx, "sh1")
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresGuardStoreConsumeView(t *testing.T) {
+	store, mock := newMockGuardStore(t)
+	ctx := context.Background()
+
+	mock.ExpectExec(`UPDATE portal_share_guards SET view_count = view_count \+ 1\s+WHERE share_id = \$1 AND \(max_views = 0 OR view_count < max_views\)`).
+		WithArgs("sh1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ok, err := store.ConsumeView(ctx, "sh1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	mock.ExpectExec(`UPDATE portal_share_guards`).WithArgs("sh1").WillReturnResult(sqlmock.NewResult(0, 0))
+	ok, err = store.ConsumeView(ctx, "sh1")
+	require.NoError(t, err)
+	assert.False(t, ok, "a spent limit matches no row")
+
+	mock.ExpectExec(`UPDATE portal_share_guards`).WillReturnError(errors.New("db down"))
+	_, err = store.ConsumeView(ctx, "sh1")
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresGuardStoreCodes(t *testing.T) {
+	store, mock := newMockGuardStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	c := VerifyCode{ID: "c1", ShareID: "sh1", Email: "eve@x.io", CodeHash: "h", CreatedAt: now, ExpiresAt: now.Add(CodeTTL)}
+
+	mock.ExpectExec(`DELETE FROM portal_share_verify_codes`).
+		WithArgs("sh1", now.Add(-purgeAge)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO portal_share_verify_codes`).
+		WithArgs("c1", "sh1", "eve@x.io", "h", c.CreatedAt, c.ExpiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, store.InsertCode(ctx, c))
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM portal_share_verify_codes`).
+		WithArgs("sh1", "eve@x.io", now).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	n, err := store.CountCodesSince(ctx, "sh1", "eve@x.io", now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	mock.ExpectQuery(`WITH latest AS .* FOR UPDATE`).
+		WithArgs("sh1", "eve@x.io", "h", now, maxCodeAttempts).
+		WillReturnRows(sqlmock.NewRows([]string{"used"}).AddRow(true))
+	ok, err := store.ClaimCode(ctx, "sh1", "eve@x.io", "h", now, maxCodeAttempts)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	mock.ExpectQuery(`WITH latest AS`).
+		WillReturnRows(sqlmock.NewRows([]string{"used"}).AddRow(false))
+	ok, err = store.ClaimCode(ctx, "sh1", "eve@x.io", "wrong", now, maxCodeAttempts)
+	require.NoError(t, err)
+	assert.False(t, ok, "a miss counts an attempt and loses")
+
+	mock.ExpectQuery(`WITH latest AS`).WillReturnError(sql.ErrNoRows)
+	ok, err = store.ClaimCode(ctx, "sh1", "eve@x.io", "h", now, maxCodeAttempts)
+	require.NoError(t, err)
+	assert.False(t, ok, "no live code loses")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresGuardStoreAccessLog(t *testing.T) {
+	store, mock := newMockGuardStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	mock.ExpectExec(`DELETE FROM portal_share_access_log`).
+		WithArgs("sh1", now.Add(-accessLogRetention)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO portal_share_access_log`).
+		WithArgs("sh1", EventView, "eve@x.io", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	require.NoError(t, store.RecordAccess(ctx, "sh1", AccessEntry{Event: EventView, Email: "eve@x.io", At: now}))
+
+	mock.ExpectQuery(`SELECT event, email, created_at FROM portal_share_access_log`).
+		WithArgs("sh1", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"event", "email", "created_at"}).
+			AddRow(EventUnlocked, "eve@x.io", now).
+			AddRow(EventCodeSent, "eve@x.io", now.Add(-time.Minute)))
+	entries, err := store.ListAccess(ctx, "sh1", 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, EventUnlocked, entries[0].Event)
+
+	mock.ExpectExec(`DELETE FROM portal_share_access_log`).WillReturnError(errors.New("db down"))
+	assert.Error(t, store.RecordAccess(ctx, "sh1", AccessEntry{Event: EventView, At: now}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
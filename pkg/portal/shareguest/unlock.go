@@ -0,0 +1,362 @@
+package shareguest
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// passCookieName carries the pass a guarded public link issues once its
+// guard is satisfied. Like the guest cookie it is separate from the
+// browser-session cookie, and its path is narrowed to the one share's viewer
+// routes, so a pass for one link is never even sent to another.
+const passCookieName = "mcp_share_pass"
+
+// passTokenType is the "typ" claim of a pass JWT, asserted on verify so a
+// guest session (signed with the same derived key) can never pose as a pass.
+const passTokenType = "share-pass"
+
+// passStagePending marks a pass that only proves the first unlock step (the
+// passphrase, when there is one) and the address a code was sent to. It
+// admits nothing; it carries the code step.
+const passStagePending = "code"
+
+// maxUnlockForm bounds the unlock form body; it holds at most a passphrase
+// and an address.
+const maxUnlockForm = 8 << 10
+
+// codeDigits is the length of an emailed verification code.
+const codeDigits = 6
+
+// GuardPrompt describes the unlock form a guarded link renders.
+type GuardPrompt struct {
+	// Passphrase asks for the link's passphrase.
+	Passphrase bool
+	// Email asks for the visitor's address, to send a verification code to.
+	Email bool
+	// CodeSentTo, when set, switches the form to the code step.
+	CodeSentTo string
+	// Notice explains why the form is shown again (a wrong passphrase, an
+	// expired code).
+	Notice string
+}
+
+// promptFor returns the first-step prompt for a guard.
+func promptFor(g *Guard) *GuardPrompt {
+	return &GuardPrompt{Passphrase: g.PassphraseHash != "", Email: g.VerifyEmail}
+}
+
+// HandleUnlock serves POST /portal/view/{token}/unlock, the form a guarded
+// public link renders. The first step checks the passphrase and, when the
+// guard verifies addresses, emails a one-time code; the second step claims
+// the code. Satisfying the guard consumes one view, sets the pass, and
+// redirects into the viewer.
+//
+// The portal registers it on the public mux inside the client rate limiter
+// and outside the access gate. Attempts are additionally limited per share,
+// so a passphrase cannot be brute-forced by spreading guesses across clients.
+func (s *Service) HandleUnlock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	share, g, ok := s.unlockTarget(ctx, r.PathValue(pathKeyToken))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if g == nil {
+		s.renderPage(w, r, Denial{Status: http.StatusServiceUnavailable, Message: msgGuardUnknown, Token: share.Token})
+		return
+	}
+	viewPath := "/portal/view/" + url.PathEscape(share.Token)
+	if !g.needsUnlock() {
+		http.Redirect(w, r, viewPath, http.StatusSeeOther)
+		return
+	}
+	if g.spent() {
+		s.renderPage(w, r, Denial{Status: http.StatusGone, Message: MsgViewLimit, Token: share.Token})
+		return
+	}
+	prompt := promptFor(g)
+	if !s.unlockLimiter.AllowAt(share.ID, s.now()) {
+		s.RecordAccess(ctx, share.ID, EventThrottled, "")
+		prompt.Notice = msgTooManyAttempts
+		s.renderPrompt(w, r, share, http.StatusTooManyRequests, prompt)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxUnlockForm)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	if r.PostForm.Has("code") {
+		s.unlockCode(w, r, share, g)
+		return
+	}
+	s.unlockStart(w, r, share, g)
+}
+
+// unlockTarget resolves the live public share behind token. A nil guard with
+// ok set means the guard lookup failed; the caller refuses.
+func (s *Service) unlockTarget(ctx context.Context, token string) (ShareInfo, *Guard, bool) {
+	if token == "" || !s.GuardsAvailable() {
+		return ShareInfo{}, nil, false
+	}
+	share, ok := s.resolve(ctx, token)
+	if !ok || !share.Live() || !share.Public {
+		return ShareInfo{}, nil, false
+	}
+	g, err := s.guards.GetGuard(ctx, share.ID)
+	if err != nil {
+		slog.Warn("share guard: lookup failed", logKeyError, err, logKeyShareID, share.ID)
+		return share, nil, true
+	}
+	if g == nil {
+		g = &Guard{ShareID: share.ID}
+	}
+	return share, g, true
+}
+
+// unlockStart handles the first step: the passphrase, then either the grant
+// or, when the guard verifies addresses, a code emailed to the given address.
+func (s *Service) unlockStart(w http.ResponseWriter, r *http.Request, share ShareInfo, g *Guard) {
+	ctx := r.Context()
+	prompt := promptFor(g)
+	if g.PassphraseHash != "" &&
+		bcrypt.CompareHashAndPassword([]byte(g.PassphraseHash), []byte(r.PostForm.Get("passphrase"))) != nil {
+		s.RecordAccess(ctx, share.ID, EventPassphraseFailed, "")
+		prompt.Notice = "That passphrase is not correct."
+		s.renderPrompt(w, r, share, http.StatusUnauthorized, prompt)
+		return
+	}
+	if !g.VerifyEmail {
+		s.grant(w, r, share, "")
+		return
+	}
+	email, ok := normalizeEmail(r.PostForm.Get("email"))
+	if !ok {
+		prompt.Notice = "Enter a valid email address."
+		s.renderPrompt(w, r, share, http.StatusUnauthorized, prompt)
+		return
+	}
+	status, notice := s.sendVerifyCode(ctx, share.ID, email)
+	if status != http.StatusOK {
+		prompt.Notice = notice
+		s.renderPrompt(w, r, share, status, prompt)
+		return
+	}
+	pending, err := s.signPass(share.ID, email, passStagePending, CodeTTL)
+	if err != nil {
+		slog.Warn("share guard: pass signing failed", logKeyError, err, logKeyShareID, share.ID)
+		http.Error(w, msgGuardUnknown, http.StatusServiceUnavailable)
+		return
+	}
+	s.setPassCookie(w, share.Token, pending)
+	s.renderPrompt(w, r, share, http.StatusOK, &GuardPrompt{CodeSentTo: email})
+}
+
+// sendVerifyCode mints, stores, and emails a verification code for email
+// under the per-address cap, returning the status and notice to render when
+// it did not.
+func (s *Service) sendVerifyCode(ctx context.Context, shareID, email string) (int, string) {
+	if s.sendCode == nil {
+		// The guard predates a configuration change that removed the mailer.
+		return http.StatusServiceUnavailable, msgGuardUnknown
+	}
+	now, since := s.claimWindow()
+	count, err := s.guards.CountCodesSince(ctx, shareID, email, since)
+	if err != nil {
+		slog.Warn("share guard: code-cap query failed", logKeyError, err, logKeyShareID, shareID)
+		return http.StatusServiceUnavailable, msgGuardUnknown
+	}
+	if count >= maxCodesPerWindow {
+		return http.StatusTooManyRequests, "Too many codes were sent to that address. Try again later."
+	}
+	code, err := mintCode()
+	if err != nil {
+		slog.Warn("share guard: code generation failed", logKeyError, err, logKeyShareID, shareID)
+		return http.StatusServiceUnavailable, msgGuardUnknown
+	}
+	err = s.guards.InsertCode(ctx, VerifyCode{
+		ID:        uuid.New().String(),
+		ShareID:   shareID,
+		Email:     email,
+		CodeHash:  s.hashCode(shareID, email, code),
+		CreatedAt: now,
+		ExpiresAt: now.Add(CodeTTL),
+	})
+	if err != nil {
+		slog.Warn("share guard: code insert failed", logKeyError, err, logKeyShareID, shareID)
+		return http.StatusServiceUnavailable, msgGuardUnknown
+	}
+	if err := s.sendCode(ctx, email, code); err != nil {
+		slog.Warn("share guard: code send failed", logKeyError, err, logKeyShareID, shareID)
+		return http.StatusServiceUnavailable, "The code could not be sent. Try again in a moment."
+	}
+	s.RecordAccess(ctx, shareID, EventCodeSent, email)
+	return http.StatusOK, ""
+}
+
+// unlockCode handles the second step: the pending pass names the address
+// the code went to, and the code must be the newest live one for it.
+func (s *Service) unlockCode(w http.ResponseWriter, r *http.Request, share ShareInfo, g *Guard) {
+	ctx := r.Context()
+	email, ok := s.readPassStage(r, share.ID, passStagePending)
+	if !ok {
+		prompt := promptFor(g)
+		prompt.Notice = "That code has expired. Start again to get a new one."
+		s.renderPrompt(w, r, share, http.StatusUnauthorized, prompt)
+		return
+	}
+	code := strings.TrimSpace(r.PostForm.Get("code"))
+	claimed, err := s.guards.ClaimCode(ctx, share.ID, email, s.hashCode(share.ID, email, code), s.now(), maxCodeAttempts)
+	if err != nil {
+		slog.Warn("share guard: code claim failed", logKeyError, err, logKeyShareID, share.ID)
+		http.Error(w, msgGuardUnknown, http.StatusServiceUnavailable)
+		return
+	}
+	if !claimed {
+		s.RecordAccess(ctx, share.ID, EventCodeFailed, email)
+		s.renderPrompt(w, r, share, http.StatusUnauthorized, &GuardPrompt{
+			CodeSentTo: email,
+			Notice:     "That code is not correct or has expired.",
+		})
+		return
+	}
+	s.grant(w, r, share, email)
+}
+
+// grant admits a visitor who satisfied the guard: it consumes one view, sets
+// the pass, records the unlock, and redirects into the viewer.
+func (s *Service) grant(w http.ResponseWriter, r *http.Request, share ShareInfo, email string) {
+	if d := s.consumeView(r.Context(), share.ID, email); d != nil {
+		d.Token = share.Token
+		s.renderPage(w, r, *d)
+		return
+	}
+	if !s.grantPass(w, share, email) {
+		http.Error(w, msgGuardUnknown, http.StatusServiceUnavailable)
+		return
+	}
+	s.RecordAccess(r.Context(), share.ID, EventUnlocked, email)
+	http.Redirect(w, r, "/portal/view/"+url.PathEscape(share.Token), http.StatusSeeOther)
+}
+
+// grantPass signs and sets the full pass for share, reporting success.
+func (s *Service) grantPass(w http.ResponseWriter, share ShareInfo, email string) bool {
+	signed, err := s.signPass(share.ID, email, "", GuestSessionTTL)
+	if err != nil {
+		slog.Warn("share guard: pass signing failed", logKeyError, err, logKeyShareID, share.ID)
+		return false
+	}
+	s.setPassCookie(w, share.Token, signed)
+	return true
+}
+
+// renderPrompt renders the unlock form for share.
+func (s *Service) renderPrompt(w http.ResponseWriter, r *http.Request, share ShareInfo, status int, p *GuardPrompt) {
+	s.renderPage(w, r, Denial{Status: status, Message: MsgLocked, Token: share.Token, Guard: p})
+}
+
+// normalizeEmail lowercases and validates a bare address, rejecting display
+// names and anything net/mail would rewrite.
+func normalizeEmail(raw string) (string, bool) {
+	email := strings.ToLower(strings.TrimSpace(raw))
+	if email == "" || len(email) > 254 {
+		return "", false
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", false
+	}
+	return email, true
+}
+
+// mintCode returns a uniformly random zero-padded six-digit code.
+func mintCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", fmt.Errorf("generating verification code: %w", err)
+	}
+	return fmt.Sprintf("%0*d", codeDigits, n.Int64()), nil
+}
+
+// signPass mints a pass JWT for shareID. stage is empty for a full pass and
+// passStagePending for the code step.
+func (s *Service) signPass(shareID, email, stage string, ttl time.Duration) (string, error) {
+	now := s.now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"typ": passTokenType,
+		"sid": shareID,
+		"sub": email,
+		"stg": stage,
+		"iat": now.Unix(),
+		"exp": now.Add(ttl).Unix(),
+	})
+	signed, err := token.SignedString(s.guestKey)
+	if err != nil {
+		return "", fmt.Errorf("signing share pass: %w", err)
+	}
+	return signed, nil
+}
+
+// readPass returns the address behind a valid full pass for shareID.
+func (s *Service) readPass(r *http.Request, shareID string) (string, bool) {
+	return s.readPassStage(r, shareID, "")
+}
+
+// readPassStage returns the address behind a valid pass of the given stage
+// for shareID.
+func (s *Service) readPassStage(r *http.Request, shareID, stage string) (string, bool) {
+	cookie, err := r.Cookie(passCookieName)
+	if err != nil {
+		return "", false
+	}
+	token, err := jwt.Parse(cookie.Value, func(t *jwt.Token) (any, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return s.guestKey, nil
+	})
+	if err != nil {
+		return "", false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return "", false
+	}
+	typ, _ := claims["typ"].(string)
+	sid, _ := claims["sid"].(string)
+	stg, _ := claims["stg"].(string)
+	if typ != passTokenType || sid != shareID || stg != stage {
+		return "", false
+	}
+	email, _ := claims["sub"].(string)
+	return email, true
+}
+
+// setPassCookie writes a pass as a browser-session cookie scoped to one
+// share's viewer routes. SameSite=Lax, as for the guest cookie: a visitor
+// returning through the emailed or chat-pasted link keeps the pass for the
+// rest of the browser session instead of unlocking (and spending a view)
+// again.
+func (s *Service) setPassCookie(w http.ResponseWriter, token, signed string) {
+	// nosemgrep: go.lang.security.audit.net.cookie-missing-secure.cookie-missing-secure
+	http.SetCookie(w, &http.Cookie{ // #nosec G124 -- Secure mirrors the browser-session cookie's cfg-driven setting
+		Name:     passCookieName,
+		Value:    signed,
+		Path:     guestCookiePath + url.PathEscape(token),
+		HttpOnly: true,
+		Secure:   s.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
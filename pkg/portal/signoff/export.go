@@ -0,0 +1,55 @@
+package signoff
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// csvHeader is the export's column order.
+var csvHeader = []string{
+	"decided_at", "asset_id", "asset_version", "collection_id", "policy_revision",
+	"step_number", "step_name", "decision", "approver_email", "approver_id", "approver_persona",
+	"reason", "decision_id",
+}
+
+// WriteCSV writes the record as CSV, one row per decision in record order.
+// Steps are numbered from 1 in step_number, for a reader counting them rather
+// than indexing them. Free-text cells are escaped so a spreadsheet does not
+// evaluate them as formulas.
+func WriteCSV(w io.Writer, record []Decision) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("writing approval record header: %w", err)
+	}
+	for _, d := range record {
+		row := []string{
+			d.CreatedAt.UTC().Format(time.RFC3339), d.AssetID, strconv.Itoa(d.AssetVersion),
+			d.CollectionID, strconv.Itoa(d.PolicyRevision), strconv.Itoa(d.Step + 1),
+			escapeCell(d.StepName), d.Decision, escapeCell(d.ApproverEmail), d.ApproverID,
+			escapeCell(d.ApproverPersona), escapeCell(d.Reason), d.ID,
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("writing approval record row: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("flushing approval record: %w", err)
+	}
+	return nil
+}
+
+// escapeCell prefixes a cell that starts with a character a spreadsheet
+// reads as the start of a formula.
+func escapeCell(s string) string {
+	r, _ := utf8.DecodeRuneInString(s)
+	switch r {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + s
+	}
+	return s
+}
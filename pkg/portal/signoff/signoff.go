@@ -0,0 +1,279 @@
+// Package signoff holds approval policies for portal collections and the
+// record of the decisions made under them.
+//
+// The feedback surface's sign-off counts the distinct people who left an
+// approval on an artifact's threads, so one approval from anyone satisfies
+// it. A regulated report needs more than that: named people or personas, a
+// required number of them, possibly in a fixed order, and a record that says
+// who approved which version. A Policy on a collection says who must sign
+// off on the assets it holds; a Decision is one person's approval or
+// rejection of one asset version; Evaluate reads a policy's state for a
+// version off the record. Approval is per version, so a new version starts
+// with no approvals and the decisions on the old one stay in the record.
+package signoff
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Policy modes. Sequential steps are taken in order, each opening once the
+// step before it is approved; parallel steps are all open at once.
+const (
+	ModeSequential = "sequential"
+	ModeParallel   = "parallel"
+)
+
+// Decision values.
+const (
+	DecisionApproved = "approved"
+	DecisionRejected = "rejected"
+)
+
+// Step and policy states.
+const (
+	// StatePending is a step open for decisions, or a policy with one.
+	StatePending = "pending"
+	// StateWaiting is a sequential step whose predecessors are not approved.
+	StateWaiting = "waiting"
+	// StateApproved is a step holding its required approvals, or a policy
+	// whose every step does.
+	StateApproved = "approved"
+	// StateRejected is a step with a rejection, or a policy with such a
+	// step. A rejection stands for the version; a new version starts over.
+	StateRejected = "rejected"
+)
+
+// Policy limits.
+const (
+	maxSteps            = 10
+	maxApproversPerStep = 50
+)
+
+// Errors a policy or a decision is refused with. Their messages are what the
+// REST surface returns.
+var (
+	ErrInvalidPolicy  = errors.New("invalid approval policy")
+	ErrNotApprover    = errors.New("you are not an approver on an open step of this policy")
+	ErrStepClosed     = errors.New("this step is not open for decisions")
+	ErrAlreadyDecided = errors.New("you have already decided on this version under this policy")
+)
+
+// Step is one stage of a policy: Required approvals from the people named in
+// Approvers (email addresses) or holding one of Personas.
+type Step struct {
+	Name      string   `json:"name,omitempty" example:"Quality review"`
+	Required  int      `json:"required" example:"2"`
+	Approvers []string `json:"approvers,omitempty"`
+	Personas  []string `json:"personas,omitempty"`
+}
+
+// Policy is a collection's approval policy. Revision increases on every
+// change; decisions record the revision they were made under, and only those
+// made under the current one count.
+type Policy struct {
+	CollectionID string    `json:"collection_id"`
+	Mode         string    `json:"mode" example:"sequential"`
+	Steps        []Step    `json:"steps"`
+	Revision     int       `json:"revision"`
+	UpdatedBy    string    `json:"updated_by,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Normalize validates p in place: a mode of sequential or parallel (empty
+// means parallel), one to ten steps, each naming at least one approver or
+// persona and requiring at least one approval, and no step requiring more
+// named approvers than it lists when it names no persona. Addresses are
+// lowercased and duplicates dropped.
+func (p *Policy) Normalize() error {
+	if p.Mode == "" {
+		p.Mode = ModeParallel
+	}
+	if p.Mode != ModeSequential && p.Mode != ModeParallel {
+		return fmt.Errorf("%w: mode must be %q or %q", ErrInvalidPolicy, ModeSequential, ModeParallel)
+	}
+	if len(p.Steps) == 0 || len(p.Steps) > maxSteps {
+		return fmt.Errorf("%w: a policy has 1 to %d steps", ErrInvalidPolicy, maxSteps)
+	}
+	for i := range p.Steps {
+		if err := p.Steps[i].normalize(); err != nil {
+			return fmt.Errorf("%w: step %d: %w", ErrInvalidPolicy, i+1, err)
+		}
+	}
+	return nil
+}
+
+func (s *Step) normalize() error {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Approvers = cleanList(s.Approvers, strings.ToLower)
+	s.Personas = cleanList(s.Personas, func(v string) string { return v })
+	if len(s.Approvers) == 0 && len(s.Personas) == 0 {
+		return errors.New("name at least one approver or persona")
+	}
+	if len(s.Approvers) > maxApproversPerStep {
+		return fmt.Errorf("at most %d approvers", maxApproversPerStep)
+	}
+	if s.Required < 1 {
+		return errors.New("required must be at least 1")
+	}
+	if len(s.Personas) == 0 && s.Required > len(s.Approvers) {
+		return fmt.Errorf("requires %d approvals but names %d approvers", s.Required, len(s.Approvers))
+	}
+	return nil
+}
+
+// cleanList trims, maps, and de-duplicates vs, dropping empty entries.
+func cleanList(vs []string, mapFn func(string) string) []string {
+	out := make([]string, 0, len(vs))
+	for _, v := range vs {
+		v = mapFn(strings.TrimSpace(v))
+		if v != "" && !slices.Contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Approver identifies the person deciding: their address and the persona
+// their roles resolve to.
+type Approver struct {
+	UserID  string
+	Email   string
+	Persona string
+}
+
+// admits reports whether the step names a.
+func (s Step) admits(a Approver) bool {
+	if a.Email != "" && slices.Contains(s.Approvers, strings.ToLower(a.Email)) {
+		return true
+	}
+	return a.Persona != "" && slices.Contains(s.Personas, a.Persona)
+}
+
+// Decision is one entry in the approval record: one person's approval or
+// rejection of one asset version, on one step of a collection's policy.
+// Entries are never changed or removed.
+type Decision struct {
+	ID              string    `json:"id"`
+	AssetID         string    `json:"asset_id"`
+	AssetVersion    int       `json:"asset_version"`
+	CollectionID    string    `json:"collection_id"`
+	PolicyRevision  int       `json:"policy_revision"`
+	Step            int       `json:"step"`
+	StepName        string    `json:"step_name,omitempty"`
+	Decision        string    `json:"decision" example:"approved"`
+	Reason          string    `json:"reason,omitempty"`
+	ApproverID      string    `json:"approver_id"`
+	ApproverEmail   string    `json:"approver_email"`
+	ApproverPersona string    `json:"approver_persona,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// StepStatus is a step's state for one asset version.
+type StepStatus struct {
+	Index     int        `json:"index"`
+	Name      string     `json:"name,omitempty"`
+	Required  int        `json:"required"`
+	State     string     `json:"state" example:"pending"`
+	Decisions []Decision `json:"decisions"`
+}
+
+// approvals counts the step's approvals.
+func (s StepStatus) approvals() int {
+	n := 0
+	for _, d := range s.Decisions {
+		if d.Decision == DecisionApproved {
+			n++
+		}
+	}
+	return n
+}
+
+// Status is a policy's state for one asset version.
+type Status struct {
+	CollectionID   string       `json:"collection_id"`
+	PolicyRevision int          `json:"policy_revision"`
+	Mode           string       `json:"mode"`
+	AssetVersion   int          `json:"asset_version"`
+	State          string       `json:"state" example:"pending"`
+	Steps          []StepStatus `json:"steps"`
+}
+
+// Evaluate reads p's state for an asset version off record, counting only
+// the decisions made on that version under p's current revision.
+func Evaluate(p Policy, version int, record []Decision) Status {
+	st := Status{
+		CollectionID: p.CollectionID, PolicyRevision: p.Revision, Mode: p.Mode,
+		AssetVersion: version, Steps: make([]StepStatus, len(p.Steps)),
+	}
+	for i, s := range p.Steps {
+		st.Steps[i] = StepStatus{Index: i, Name: s.Name, Required: s.Required, Decisions: []Decision{}}
+	}
+	for _, d := range record {
+		if d.CollectionID == p.CollectionID && d.PolicyRevision == p.Revision &&
+			d.AssetVersion == version && d.Step >= 0 && d.Step < len(st.Steps) {
+			st.Steps[d.Step].Decisions = append(st.Steps[d.Step].Decisions, d)
+		}
+	}
+
+	st.State = StateApproved
+	blocked := false
+	for i := range st.Steps {
+		step := &st.Steps[i]
+		switch {
+		case slices.ContainsFunc(step.Decisions, func(d Decision) bool { return d.Decision == DecisionRejected }):
+			step.State = StateRejected
+		case step.approvals() >= step.Required:
+			step.State = StateApproved
+		case blocked:
+			step.State = StateWaiting
+		default:
+			step.State = StatePending
+		}
+		if step.State != StateApproved && p.Mode == ModeSequential {
+			blocked = true
+		}
+		switch {
+		case step.State == StateRejected:
+			st.State = StateRejected
+		case step.State != StateApproved && st.State != StateRejected:
+			st.State = StatePending
+		}
+	}
+	return st
+}
+
+// Admit decides whether a may record a decision under p on the status st
+// evaluated, returning the step it is recorded on. step selects one when a
+// is named on several open steps; -1 picks the first. A rejected version
+// takes no more decisions, and a person decides once per version.
+func Admit(p Policy, st Status, a Approver, step int) (int, error) {
+	for _, s := range st.Steps {
+		for _, d := range s.Decisions {
+			if d.ApproverID == a.UserID {
+				return 0, ErrAlreadyDecided
+			}
+		}
+	}
+	if st.State == StateRejected {
+		return 0, ErrStepClosed
+	}
+	if step >= 0 {
+		if step >= len(p.Steps) || !p.Steps[step].admits(a) {
+			return 0, ErrNotApprover
+		}
+		if st.Steps[step].State != StatePending {
+			return 0, ErrStepClosed
+		}
+		return step, nil
+	}
+	for i, s := range p.Steps {
+		if st.Steps[i].State == StatePending && s.admits(a) {
+			return i, nil
+		}
+	}
+	return 0, ErrNotApprover
+}
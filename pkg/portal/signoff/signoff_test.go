@@ -0,0 +1,142 @@
+package signoff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyNormalize(t *testing.T) {
+	p := Policy{Steps: []Step{{
+		Name: " QA ", Required: 1, Approvers: []string{" Ana@Example.com", "ana@example.com", ""},
+	}}}
+	require.NoError(t, p.Normalize())
+	assert.Equal(t, ModeParallel, p.Mode, "parallel by default")
+	assert.Equal(t, "QA", p.Steps[0].Name)
+	assert.Equal(t, []string{"ana@example.com"}, p.Steps[0].Approvers)
+
+	tests := []struct {
+		name string
+		p    Policy
+	}{
+		{"unknown mode", Policy{Mode: "any", Steps: []Step{{Required: 1, Personas: []string{"qa"}}}}},
+		{"no steps", Policy{}},
+		{"step names nobody", Policy{Steps: []Step{{Required: 1}}}},
+		{"required below one", Policy{Steps: []Step{{Personas: []string{"qa"}}}}},
+		{"required beyond the named approvers", Policy{Steps: []Step{{Required: 2, Approvers: []string{"a@x.io"}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.p.Normalize(), ErrInvalidPolicy)
+		})
+	}
+
+	personas := Policy{Steps: []Step{{Required: 3, Personas: []string{"qa"}}}}
+	assert.NoError(t, personas.Normalize(), "a persona's size is unknown, so any count is allowed")
+}
+
+// twoStep is a policy of two named approvals, then one from the qa persona.
+func twoStep(mode string) Policy {
+	return Policy{CollectionID: "c1", Mode: mode, Revision: 2, Steps: []Step{
+		{Name: "Analysts", Required: 2, Approvers: []string{"ana@x.io", "bo@x.io", "cy@x.io"}},
+		{Name: "QA", Required: 1, Personas: []string{"qa"}},
+	}}
+}
+
+func decided(step, version int, who, decision string) Decision {
+	return Decision{
+		CollectionID: "c1", PolicyRevision: 2, AssetVersion: version,
+		Step: step, ApproverID: who, Decision: decision,
+	}
+}
+
+func stepStates(st Status) []string {
+	out := make([]string, len(st.Steps))
+	for i, s := range st.Steps {
+		out[i] = s.State
+	}
+	return out
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   string
+		record []Decision
+		state  string
+		steps  []string
+	}{
+		{"nothing decided, sequential", ModeSequential, nil,
+			StatePending, []string{StatePending, StateWaiting}},
+		{"nothing decided, parallel", ModeParallel, nil,
+			StatePending, []string{StatePending, StatePending}},
+		{"first step short of its count", ModeSequential,
+			[]Decision{decided(0, 3, "ana", DecisionApproved)},
+			StatePending, []string{StatePending, StateWaiting}},
+		{"first step done opens the second", ModeSequential,
+			[]Decision{decided(0, 3, "ana", DecisionApproved), decided(0, 3, "bo", DecisionApproved)},
+			StatePending, []string{StateApproved, StatePending}},
+		{"every step done", ModeSequential, []Decision{
+			decided(0, 3, "ana", DecisionApproved), decided(0, 3, "bo", DecisionApproved),
+			decided(1, 3, "qa1", DecisionApproved),
+		}, StateApproved, []string{StateApproved, StateApproved}},
+		{"a rejection rejects the version", ModeParallel, []Decision{
+			decided(0, 3, "ana", DecisionApproved), decided(1, 3, "qa1", DecisionRejected),
+		}, StateRejected, []string{StatePending, StateRejected}},
+		{"approvals on an earlier version do not count", ModeSequential, []Decision{
+			decided(0, 2, "ana", DecisionApproved), decided(0, 2, "bo", DecisionApproved),
+			decided(1, 2, "qa1", DecisionApproved),
+		}, StatePending, []string{StatePending, StateWaiting}},
+		{"approvals under an earlier revision do not count", ModeParallel, []Decision{
+			{CollectionID: "c1", PolicyRevision: 1, AssetVersion: 3, Step: 1, ApproverID: "qa1", Decision: DecisionApproved},
+		}, StatePending, []string{StatePending, StatePending}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := Evaluate(twoStep(tt.mode), 3, tt.record)
+			assert.Equal(t, tt.state, st.State)
+			assert.Equal(t, tt.steps, stepStates(st))
+			assert.Equal(t, 3, st.AssetVersion)
+		})
+	}
+}
+
+func TestAdmit(t *testing.T) {
+	p := twoStep(ModeSequential)
+	ana := Approver{UserID: "ana", Email: "ANA@x.io"}
+	qa := Approver{UserID: "qa1", Email: "qa1@x.io", Persona: "qa"}
+
+	st := Evaluate(p, 3, nil)
+	step, err := Admit(p, st, ana, -1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, step, "addresses match case-insensitively")
+
+	_, err = Admit(p, st, qa, -1)
+	assert.ErrorIs(t, err, ErrNotApprover, "the QA step waits on the analysts")
+	_, err = Admit(p, st, qa, 1)
+	assert.ErrorIs(t, err, ErrStepClosed)
+	_, err = Admit(p, st, Approver{UserID: "zed", Email: "zed@x.io"}, -1)
+	assert.ErrorIs(t, err, ErrNotApprover)
+	_, err = Admit(p, st, ana, 1)
+	assert.ErrorIs(t, err, ErrNotApprover, "ana is not named on the QA step")
+
+	st = Evaluate(p, 3, []Decision{decided(0, 3, "ana", DecisionApproved)})
+	_, err = Admit(p, st, ana, -1)
+	assert.ErrorIs(t, err, ErrAlreadyDecided)
+
+	st = Evaluate(p, 3, []Decision{decided(0, 3, "ana", DecisionApproved), decided(0, 3, "bo", DecisionApproved)})
+	step, err = Admit(p, st, qa, -1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, step)
+	_, err = Admit(p, st, Approver{UserID: "cy", Email: "cy@x.io"}, -1)
+	assert.ErrorIs(t, err, ErrNotApprover, "the analysts' step is already approved")
+
+	st = Evaluate(p, 3, []Decision{decided(0, 3, "ana", DecisionRejected)})
+	_, err = Admit(p, st, Approver{UserID: "bo", Email: "bo@x.io"}, -1)
+	assert.ErrorIs(t, err, ErrStepClosed, "a rejected version takes no more decisions")
+
+	st = Evaluate(p, 4, []Decision{decided(0, 3, "ana", DecisionRejected)})
+	_, err = Admit(p, st, ana, -1)
+	assert.NoError(t, err, "a new version starts over")
+}
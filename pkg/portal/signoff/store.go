@@ -0,0 +1,193 @@
+package signoff
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// pgUniqueViolation is the SQLSTATE of a unique-constraint violation, which
+// the decision record's one-per-person key raises on a second decision.
+const pgUniqueViolation = "23505"
+
+// Store persists approval policies and the decision record.
+type Store interface {
+	// GetPolicy returns the collection's policy, or nil when it has none.
+	GetPolicy(ctx context.Context, collectionID string) (*Policy, error)
+	// PutPolicy creates or replaces the collection's policy under a new
+	// revision, returning the stored policy.
+	PutPolicy(ctx context.Context, p Policy) (*Policy, error)
+	// DeletePolicy removes the collection's policy. The record is kept.
+	DeletePolicy(ctx context.Context, collectionID string) error
+	// PoliciesForAsset returns the policies of the live collections that
+	// hold the asset, ordered by collection id.
+	PoliciesForAsset(ctx context.Context, assetID string) ([]Policy, error)
+	// RecordDecision appends d to the record, returning ErrAlreadyDecided
+	// when its approver already decided on that version and revision.
+	RecordDecision(ctx context.Context, d Decision) error
+	// ListDecisions returns the asset's whole record, oldest first.
+	ListDecisions(ctx context.Context, assetID string) ([]Decision, error)
+}
+
+// PostgresStore implements Store on portal_signoff_policies and
+// portal_signoff_decisions.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates the production sign-off store.
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// NewDecisionID returns a unique id for a record entry.
+func NewDecisionID() string {
+	return "sgn_" + uuid.New().String()
+}
+
+const policyColumns = `collection_id, mode, steps, revision, updated_by, updated_at`
+
+// GetPolicy reads the collection's policy.
+func (s *PostgresStore) GetPolicy(ctx context.Context, collectionID string) (*Policy, error) {
+	p, err := scanPolicy(s.db.QueryRowContext(ctx,
+		`SELECT `+policyColumns+` FROM portal_signoff_policies WHERE collection_id = $1`, collectionID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil //nolint:nilnil // nil policy means the collection has none
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading approval policy: %w", err)
+	}
+	return p, nil
+}
+
+// PutPolicy upserts the policy. A first write starts past the highest
+// revision the record already names for the collection, so a policy that
+// was deleted and written again does not revive the old decisions.
+func (s *PostgresStore) PutPolicy(ctx context.Context, p Policy) (*Policy, error) {
+	steps, err := json.Marshal(p.Steps)
+	if err != nil {
+		return nil, fmt.Errorf("encoding approval policy steps: %w", err)
+	}
+	err = s.db.QueryRowContext(ctx,
+		`INSERT INTO portal_signoff_policies (collection_id, mode, steps, revision, updated_by, updated_at)
+		 VALUES ($1, $2, $3, 1 + COALESCE(
+		     (SELECT MAX(policy_revision) FROM portal_signoff_decisions WHERE collection_id = $1), 0), $4, NOW())
+		 ON CONFLICT (collection_id) DO UPDATE
+		 SET mode = EXCLUDED.mode,
+		     steps = EXCLUDED.steps,
+		     revision = portal_signoff_policies.revision + 1,
+		     updated_by = EXCLUDED.updated_by,
+		     updated_at = NOW()
+		 RETURNING revision, updated_at`,
+		p.CollectionID, p.Mode, steps, p.UpdatedBy).Scan(&p.Revision, &p.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("upserting approval policy: %w", err)
+	}
+	return &p, nil
+}
+
+// DeletePolicy removes the policy row.
+func (s *PostgresStore) DeletePolicy(ctx context.Context, collectionID string) error {
+	if _, err := s.db.ExecContext(ctx,
+		`DELETE FROM portal_signoff_policies WHERE collection_id = $1`, collectionID); err != nil {
+		return fmt.Errorf("deleting approval policy: %w", err)
+	}
+	return nil
+}
+
+// PoliciesForAsset reads the policies of the collections holding the asset.
+func (s *PostgresStore) PoliciesForAsset(ctx context.Context, assetID string) ([]Policy, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+policyColumns+` FROM portal_signoff_policies p
+		 WHERE EXISTS (
+		     SELECT 1 FROM portal_collections c
+		     JOIN portal_collection_sections sec ON sec.collection_id = c.id
+		     JOIN portal_collection_items i ON i.section_id = sec.id
+		     WHERE c.id = p.collection_id AND c.deleted_at IS NULL AND i.asset_id = $1)
+		 ORDER BY p.collection_id`, assetID)
+	if err != nil {
+		return nil, fmt.Errorf("listing approval policies: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // best-effort cleanup after read-only query
+
+	var out []Policy
+	for rows.Next() {
+		p, err := scanPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning approval policy: %w", err)
+		}
+		out = append(out, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating approval policies: %w", err)
+	}
+	return out, nil
+}
+
+// RecordDecision inserts one record entry.
+func (s *PostgresStore) RecordDecision(ctx context.Context, d Decision) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO portal_signoff_decisions
+		 (id, asset_id, asset_version, collection_id, policy_revision, step, step_name,
+		  decision, reason, approver_id, approver_email, approver_persona, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		d.ID, d.AssetID, d.AssetVersion, d.CollectionID, d.PolicyRevision, d.Step, d.StepName,
+		d.Decision, d.Reason, d.ApproverID, d.ApproverEmail, d.ApproverPersona, d.CreatedAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && string(pqErr.Code) == pgUniqueViolation {
+		return ErrAlreadyDecided
+	}
+	if err != nil {
+		return fmt.Errorf("recording approval decision: %w", err)
+	}
+	return nil
+}
+
+// ListDecisions reads the asset's record.
+func (s *PostgresStore) ListDecisions(ctx context.Context, assetID string) ([]Decision, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, asset_id, asset_version, collection_id, policy_revision, step, step_name,
+		        decision, reason, approver_id, approver_email, approver_persona, created_at
+		 FROM portal_signoff_decisions WHERE asset_id = $1 ORDER BY created_at, id`, assetID)
+	if err != nil {
+		return nil, fmt.Errorf("listing approval decisions: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // best-effort cleanup after read-only query
+
+	out := []Decision{}
+	for rows.Next() {
+		var d Decision
+		if err := rows.Scan(&d.ID, &d.AssetID, &d.AssetVersion, &d.CollectionID, &d.PolicyRevision,
+			&d.Step, &d.StepName, &d.Decision, &d.Reason, &d.ApproverID, &d.ApproverEmail,
+			&d.ApproverPersona, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning approval decision: %w", err)
+		}
+		out = append(out, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating approval decisions: %w", err)
+	}
+	return out, nil
+}
+
+// scanner is the Scan method shared by *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPolicy(row scanner) (*Policy, error) {
+	var p Policy
+	var steps []byte
+	if err := row.Scan(&p.CollectionID, &p.Mode, &steps, &p.Revision, &p.UpdatedBy, &p.UpdatedAt); err != nil {
+		return nil, err //nolint:wrapcheck // callers wrap with their own context
+	}
+	if err := json.Unmarshal(steps, &p.Steps); err != nil {
+		return nil, fmt.Errorf("decoding approval policy steps: %w", err)
+	}
+	return &p, nil
+}
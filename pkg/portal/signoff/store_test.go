@@ -0,0 +1,119 @@
+package signoff
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockStore(t *testing.T) (*PostgresStore, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return NewPostgresStore(db), mock
+}
+
+var policyCols = []string{"collection_id", "mode", "steps", "revision", "updated_by", "updated_at"}
+
+func TestPostgresStorePolicies(t *testing.T) {
+	store, mock := newMockStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	steps := `[{"name":"QA","required":1,"personas":["qa"]}]`
+
+	mock.ExpectQuery(`SELECT .+ FROM portal_signoff_policies WHERE collection_id`).WithArgs("c1").
+		WillReturnRows(sqlmock.NewRows(policyCols).AddRow("c1", ModeParallel, []byte(steps), 3, "u1", now))
+	p, err := store.GetPolicy(ctx, "c1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, p.Revision)
+	assert.Equal(t, []Step{{Name: "QA", Required: 1, Personas: []string{"qa"}}}, p.Steps)
+
+	mock.ExpectQuery(`SELECT .+ FROM portal_signoff_policies WHERE collection_id`).WithArgs("c2").
+		WillReturnRows(sqlmock.NewRows(policyCols))
+	p, err = store.GetPolicy(ctx, "c2")
+	require.NoError(t, err)
+	assert.Nil(t, p, "no row is no policy")
+
+	mock.ExpectQuery(`INSERT INTO portal_signoff_policies .+ ON CONFLICT .+ RETURNING revision, updated_at`).
+		WithArgs("c1", ModeSequential, []byte(steps), "u1").
+		WillReturnRows(sqlmock.NewRows([]string{"revision", "updated_at"}).AddRow(4, now))
+	p, err = store.PutPolicy(ctx, Policy{CollectionID: "c1", Mode: ModeSequential, UpdatedBy: "u1",
+		Steps: []Step{{Name: "QA", Required: 1, Personas: []string{"qa"}}}})
+	require.NoError(t, err)
+	assert.Equal(t, 4, p.Revision)
+
+	mock.ExpectExec(`DELETE FROM portal_signoff_policies`).WithArgs("c1").WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, store.DeletePolicy(ctx, "c1"))
+
+	mock.ExpectQuery(`FROM portal_signoff_policies p\s+WHERE EXISTS`).WithArgs("a1").
+		WillReturnRows(sqlmock.NewRows(policyCols).
+			AddRow("c1", ModeParallel, []byte(steps), 1, "", now).
+			AddRow("c2", ModeParallel, []byte(steps), 2, "", now))
+	all, err := store.PoliciesForAsset(ctx, "a1")
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	mock.ExpectQuery(`FROM portal_signoff_policies p`).WillReturnError(errors.New("db down"))
+	_, err = store.PoliciesForAsset(ctx, "a1")
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStoreDecisions(t *testing.T) {
+	store, mock := newMockStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	d := Decision{
+		ID: "sgn_1", AssetID: "a1", AssetVersion: 2, CollectionID: "c1", PolicyRevision: 1,
+		Step: 0, StepName: "QA", Decision: DecisionApproved, ApproverID: "u1",
+		ApproverEmail: "u1@x.io", CreatedAt: now,
+	}
+
+	mock.ExpectExec(`INSERT INTO portal_signoff_decisions`).
+		WithArgs(d.ID, d.AssetID, d.AssetVersion, d.CollectionID, d.PolicyRevision, d.Step, d.StepName,
+			d.Decision, d.Reason, d.ApproverID, d.ApproverEmail, d.ApproverPersona, d.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, store.RecordDecision(ctx, d))
+
+	mock.ExpectExec(`INSERT INTO portal_signoff_decisions`).WillReturnError(&pq.Error{Code: pgUniqueViolation})
+	assert.ErrorIs(t, store.RecordDecision(ctx, d), ErrAlreadyDecided, "the one-per-person key")
+
+	mock.ExpectExec(`INSERT INTO portal_signoff_decisions`).WillReturnError(errors.New("db down"))
+	err := store.RecordDecision(ctx, d)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrAlreadyDecided)
+
+	mock.ExpectQuery(`FROM portal_signoff_decisions WHERE asset_id = \$1 ORDER BY created_at, id`).WithArgs("a1").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "asset_id", "asset_version", "collection_id", "policy_revision", "step", "step_name",
+			"decision", "reason", "approver_id", "approver_email", "approver_persona", "created_at",
+		}).AddRow(d.ID, d.AssetID, d.AssetVersion, d.CollectionID, d.PolicyRevision, d.Step, d.StepName,
+			d.Decision, d.Reason, d.ApproverID, d.ApproverEmail, d.ApproverPersona, d.CreatedAt))
+	record, err := store.ListDecisions(ctx, "a1")
+	require.NoError(t, err)
+	assert.Equal(t, []Decision{d}, record)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWriteCSV(t *testing.T) {
+	at := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, []Decision{{
+		ID: "sgn_1", AssetID: "a1", AssetVersion: 2, CollectionID: "c1", PolicyRevision: 1,
+		Step: 0, StepName: "QA", Decision: DecisionRejected, Reason: "=HYPERLINK(\"x\")",
+		ApproverID: "u1", ApproverEmail: "u1@x.io", CreatedAt: at,
+	}}))
+	assert.Equal(t,
+		"decided_at,asset_id,asset_version,collection_id,policy_revision,step_number,step_name,decision,"+
+			"approver_email,approver_id,approver_persona,reason,decision_id\n"+
+			"2026-10-01T09:30:00Z,a1,2,c1,1,1,QA,rejected,u1@x.io,u1,,\"'=HYPERLINK(\"\"x\"\")\",sgn_1\n",
+		buf.String())
+}
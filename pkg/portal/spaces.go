@@ -0,0 +1,58 @@
+package portal
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/internal/portal/access"
+	"github.com/txn2/mcp-data-platform/internal/portal/spaceapi"
+)
+
+// observeGroupsTimeout bounds the detached write that records a caller's
+// group claims.
+const observeGroupsTimeout = 5 * time.Second
+
+// registerSpaceRoutes mounts the team-space routes, implemented in the
+// internal/portal/spaceapi seam. With no SpaceStore wired the seam registers
+// nothing, and the access checker grants nothing through spaces.
+func (h *Handler) registerSpaceRoutes() {
+	spaceapi.Register(h.mux, spaceapi.Config{
+		Spaces:      h.deps.SpaceStore,
+		Collections: h.deps.CollectionStore,
+		Access:      h.access,
+	})
+}
+
+// observeSpaceGroups records the caller's group claims so the members a space
+// binding brings in can be listed, e.g. as mention candidates. Access never
+// reads this record — it is decided from the claims on each request — so the
+// write is detached and a failure only logs.
+func (h *Handler) observeSpaceGroups(user *User) {
+	if h.deps.SpaceStore == nil || user.Email == "" {
+		return
+	}
+	store, email, groups := h.deps.SpaceStore, user.Email, append([]string(nil), user.Roles...)
+	go func() { // #nosec G118 -- intentionally detached: request ctx is canceled after handler returns
+		ctx, cancel := context.WithTimeout(context.Background(), observeGroupsTimeout)
+		defer cancel()
+		if err := store.ObserveGroups(ctx, email, groups); err != nil {
+			slog.Warn("portal: failed to record space group claims", "error", err) // #nosec G706 -- structured log, not user-facing
+		}
+	}()
+}
+
+// spaceCollectionIDs returns the collections owned by the caller's team
+// spaces, which widen the caller's search scope. A lookup failure narrows the
+// search back to the caller's own items rather than failing it.
+func (h *Handler) spaceCollectionIDs(ctx context.Context, user *User) []string {
+	if h.deps.SpaceStore == nil {
+		return nil
+	}
+	ids, err := h.deps.SpaceStore.CollectionIDs(ctx, access.SpacePrincipal(user))
+	if err != nil {
+		slog.Warn("portal: failed to resolve team-space collections for search", "error", err) // #nosec G706 -- structured log, not user-facing
+		return nil
+	}
+	return ids
+}
@@ -0,0 +1,174 @@
+// Package spaces holds team spaces: named groups that own portal collections.
+//
+// Shares grant access one asset, collection or prompt at a time, so a team
+// working out of a set of collections has to be re-granted on every one of
+// them and on each newcomer. A Space is the unit a team is governed as: it
+// owns collections, and each of its members holds a role on every collection
+// it owns and on every asset inside them. Members come from two places: group
+// claims on the caller's token, bound to the space with a role, and managed
+// members named by address.
+//
+// Nothing here is copied onto shares. The role a person holds is resolved
+// from the space's bindings and members when a permission is checked, so a
+// changed role, a removed member, or a collection moved out of the space takes
+// effect on the next request.
+package spaces
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Member roles, weakest first. A viewer reads what the space owns; an editor
+// also edits it and may bring their own collections into the space; a manager
+// also manages the space's members and collections.
+const (
+	RoleViewer  = "viewer"
+	RoleEditor  = "editor"
+	RoleManager = "manager"
+)
+
+// Space limits.
+const (
+	maxNameLength        = 120
+	maxDescriptionLength = 2000
+	maxGroups            = 50
+)
+
+// Errors a space change is refused with. Their messages are what the REST
+// surface returns.
+var (
+	ErrNotFound    = errors.New("space not found")
+	ErrInvalid     = errors.New("invalid space")
+	ErrNameTaken   = errors.New("a space with that name already exists")
+	ErrOwnedByPeer = errors.New("the collection already belongs to another space")
+)
+
+// Space is a team space. Groups are its claim bindings. Role is the caller's
+// effective role in it, set only on the spaces listed for a member.
+type Space struct {
+	ID          string         `json:"id" example:"spc_4b1f2c8e"`
+	Name        string         `json:"name" example:"Revenue Analytics"`
+	Description string         `json:"description" example:"Finance reporting team"`
+	Groups      []GroupBinding `json:"groups"`
+	CreatedBy   string         `json:"created_by" example:"admin@example.com"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	Role        string         `json:"role,omitempty" example:"editor"`
+}
+
+// GroupBinding makes everyone holding a group claim value a member with Role.
+type GroupBinding struct {
+	Group string `json:"group" example:"finance-analysts"`
+	Role  string `json:"role" example:"viewer"`
+}
+
+// Member is a managed member, named by address.
+type Member struct {
+	Email     string    `json:"email" example:"ana@example.com"`
+	Role      string    `json:"role" example:"editor"`
+	AddedBy   string    `json:"added_by" example:"lead@example.com"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Principal is who a role is resolved for: an address, matched against the
+// managed members, and the group claims on their token, matched against the
+// bindings.
+type Principal struct {
+	Email  string
+	Groups []string
+}
+
+// NewID returns a unique space id.
+func NewID() string {
+	return "spc_" + uuid.New().String()
+}
+
+// ValidRole reports whether r is a member role.
+func ValidRole(r string) bool {
+	return rank(r) > 0
+}
+
+// rank orders roles so the strongest wins when a person is a member more than
+// once (by address and through a group, or through two groups).
+func rank(r string) int {
+	switch r {
+	case RoleManager:
+		return 3
+	case RoleEditor:
+		return 2
+	case RoleViewer:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Strongest returns the strongest of roles, or "" for none.
+func Strongest(roles ...string) string {
+	best := ""
+	for _, r := range roles {
+		if rank(r) > rank(best) {
+			best = r
+		}
+	}
+	return best
+}
+
+// AtLeast reports whether role grants at least min.
+func AtLeast(role, minRole string) bool {
+	return rank(role) > 0 && rank(role) >= rank(minRole)
+}
+
+// Normalize trims the space's fields and its bindings, and validates them.
+func (s *Space) Normalize() error {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Description = strings.TrimSpace(s.Description)
+	if s.Name == "" || len(s.Name) > maxNameLength {
+		return fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalid, maxNameLength)
+	}
+	if len(s.Description) > maxDescriptionLength {
+		return fmt.Errorf("%w: description exceeds %d characters", ErrInvalid, maxDescriptionLength)
+	}
+	return NormalizeGroups(&s.Groups)
+}
+
+// NormalizeGroups trims and validates bindings in place. A group bound twice
+// keeps its last role.
+func NormalizeGroups(groups *[]GroupBinding) error {
+	out := make([]GroupBinding, 0, len(*groups))
+	for _, g := range *groups {
+		g.Group = strings.TrimSpace(g.Group)
+		if g.Group == "" {
+			return fmt.Errorf("%w: a group binding names no group", ErrInvalid)
+		}
+		if !ValidRole(g.Role) {
+			return fmt.Errorf("%w: group %q: role must be %s, %s or %s",
+				ErrInvalid, g.Group, RoleViewer, RoleEditor, RoleManager)
+		}
+		out = slices.DeleteFunc(out, func(b GroupBinding) bool { return b.Group == g.Group })
+		out = append(out, g)
+	}
+	if len(out) > maxGroups {
+		return fmt.Errorf("%w: at most %d group bindings", ErrInvalid, maxGroups)
+	}
+	*groups = out
+	return nil
+}
+
+// NormalizeMember lower-cases and validates a managed member.
+func NormalizeMember(m *Member) error {
+	m.Email = strings.ToLower(strings.TrimSpace(m.Email))
+	if m.Email == "" || !strings.Contains(m.Email, "@") {
+		return fmt.Errorf("%w: a member is named by email address", ErrInvalid)
+	}
+	if !ValidRole(m.Role) {
+		return fmt.Errorf("%w: role must be %s, %s or %s", ErrInvalid, RoleViewer, RoleEditor, RoleManager)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package spaces
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpaceNormalize(t *testing.T) {
+	s := Space{Name: "  Revenue  ", Groups: []GroupBinding{
+		{Group: " finance ", Role: RoleViewer},
+		{Group: "finance", Role: RoleEditor},
+		{Group: "leads", Role: RoleManager},
+	}}
+	require.NoError(t, s.Normalize())
+	assert.Equal(t, "Revenue", s.Name)
+	assert.Equal(t, []GroupBinding{{Group: "finance", Role: RoleEditor}, {Group: "leads", Role: RoleManager}}, s.Groups,
+		"a group bound twice keeps its last role")
+
+	tests := []struct {
+		name string
+		s    Space
+	}{
+		{"no name", Space{Name: " "}},
+		{"blank group", Space{Name: "x", Groups: []GroupBinding{{Role: RoleViewer}}}},
+		{"unknown role", Space{Name: "x", Groups: []GroupBinding{{Group: "g", Role: "owner"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.s.Normalize(), ErrInvalid)
+		})
+	}
+}
+
+func TestNormalizeMember(t *testing.T) {
+	m := Member{Email: " Ana@Example.com ", Role: RoleEditor}
+	require.NoError(t, NormalizeMember(&m))
+	assert.Equal(t, "ana@example.com", m.Email)
+
+	assert.ErrorIs(t, NormalizeMember(&Member{Email: "ana", Role: RoleEditor}), ErrInvalid)
+	assert.ErrorIs(t, NormalizeMember(&Member{Email: "ana@x.io", Role: "admin"}), ErrInvalid)
+}
+
+func TestRoles(t *testing.T) {
+	assert.Equal(t, RoleManager, Strongest(RoleViewer, RoleManager, RoleEditor))
+	assert.Equal(t, RoleViewer, Strongest("", RoleViewer, "bogus"))
+	assert.Empty(t, Strongest())
+
+	assert.True(t, AtLeast(RoleManager, RoleEditor))
+	assert.True(t, AtLeast(RoleEditor, RoleEditor))
+	assert.False(t, AtLeast(RoleViewer, RoleEditor))
+	assert.False(t, AtLeast("", ""), "no role grants nothing")
+	assert.True(t, ValidRole(RoleViewer))
+	assert.False(t, ValidRole(""))
+}
@@ -0,0 +1,516 @@
+package spaces
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// pgUniqueViolation is the SQLSTATE of a unique-constraint violation, which
+// the case-insensitive name index raises on a duplicate space name.
+const pgUniqueViolation = "23505"
+
+// Store persists team spaces and resolves the roles their members hold.
+type Store interface {
+	// Create stores a new space with its group bindings.
+	Create(ctx context.Context, s Space) (*Space, error)
+	// Get returns a space with its bindings, or ErrNotFound.
+	Get(ctx context.Context, id string) (*Space, error)
+	// Update replaces a space's name, description and bindings.
+	Update(ctx context.Context, s Space) (*Space, error)
+	// Delete removes a space. Its collections go back to being governed by
+	// their owners and shares alone.
+	Delete(ctx context.Context, id string) error
+	// List returns every space, ordered by name.
+	List(ctx context.Context) ([]Space, error)
+	// ForPrincipal returns the spaces p is a member of, ordered by name, each
+	// with Role set to p's strongest role in it.
+	ForPrincipal(ctx context.Context, p Principal) ([]Space, error)
+	// Role returns p's strongest role in a space, or "" for a non-member.
+	Role(ctx context.Context, spaceID string, p Principal) (string, error)
+
+	// PutMember adds a managed member or changes their role.
+	PutMember(ctx context.Context, spaceID string, m Member) error
+	// RemoveMember removes a managed member, or returns ErrNotFound.
+	RemoveMember(ctx context.Context, spaceID, email string) error
+	// Members returns a space's managed members, ordered by address.
+	Members(ctx context.Context, spaceID string) ([]Member, error)
+
+	// AttachCollection makes the space the collection's owner, returning
+	// ErrOwnedByPeer when another space already owns it.
+	AttachCollection(ctx context.Context, spaceID, collectionID, addedBy string) error
+	// DetachCollection releases a collection the space owns, or returns
+	// ErrNotFound.
+	DetachCollection(ctx context.Context, spaceID, collectionID string) error
+	// Collections returns the ids of the collections a space owns, in the
+	// order they were added.
+	Collections(ctx context.Context, spaceID string) ([]string, error)
+	// CollectionSpace returns the id of the space owning a collection, or "".
+	CollectionSpace(ctx context.Context, collectionID string) (string, error)
+
+	// CollectionRole returns p's strongest role on a collection through the
+	// space that owns it, or "".
+	CollectionRole(ctx context.Context, collectionID string, p Principal) (string, error)
+	// AssetRole returns p's strongest role on an asset through the spaces
+	// owning live collections that hold it, or "".
+	AssetRole(ctx context.Context, assetID string, p Principal) (string, error)
+	// CollectionIDs returns the ids of every collection owned by a space p is
+	// a member of.
+	CollectionIDs(ctx context.Context, p Principal) ([]string, error)
+
+	// ObserveGroups records which bound groups an address holds, replacing
+	// what was recorded for it before, so the members a group brings in can
+	// be listed. It never grants anything.
+	ObserveGroups(ctx context.Context, email string, groups []string) error
+}
+
+// PostgresStore implements Store on the portal_space* tables.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates the production team-space store.
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// memberRolesSQL yields (space_id, role) for every membership of the
+// principal bound as $1 (address) and $2 (group claims): one row per managed
+// membership and per matching group binding. Callers keep the strongest.
+const memberRolesSQL = `
+	SELECT space_id, role FROM portal_space_members WHERE email = $1
+	 UNION ALL
+	SELECT space_id, role FROM portal_space_groups WHERE group_name = ANY($2)`
+
+// principalArgs binds a principal as memberRolesSQL expects it.
+func principalArgs(p Principal) []any {
+	return []any{strings.ToLower(strings.TrimSpace(p.Email)), pq.Array(p.Groups)}
+}
+
+// anonymous reports whether p can match no membership at all, so the lookup
+// can be skipped.
+func anonymous(p Principal) bool {
+	return strings.TrimSpace(p.Email) == "" && len(p.Groups) == 0
+}
+
+const spaceColumns = `id, name, description, created_by, created_at, updated_at`
+
+// Create inserts the space and its bindings in one transaction.
+func (s *PostgresStore) Create(ctx context.Context, sp Space) (*Space, error) {
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx,
+			`INSERT INTO portal_spaces (id, name, description, created_by)
+			 VALUES ($1, $2, $3, $4) RETURNING created_at, updated_at`,
+			sp.ID, sp.Name, sp.Description, sp.CreatedBy).Scan(&sp.CreatedAt, &sp.UpdatedAt); err != nil {
+			return mapNameErr(err, "creating space")
+		}
+		return writeGroups(ctx, tx, sp.ID, sp.Groups)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sp, nil
+}
+
+// Get reads one space and its bindings.
+func (s *PostgresStore) Get(ctx context.Context, id string) (*Space, error) {
+	var sp Space
+	err := s.db.QueryRowContext(ctx, `SELECT `+spaceColumns+` FROM portal_spaces WHERE id = $1`, id).
+		Scan(&sp.ID, &sp.Name, &sp.Description, &sp.CreatedBy, &sp.CreatedAt, &sp.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading space: %w", err)
+	}
+	all := []Space{sp}
+	if err := s.loadGroups(ctx, all); err != nil {
+		return nil, err
+	}
+	return &all[0], nil
+}
+
+// Update rewrites the space's header and replaces its bindings.
+func (s *PostgresStore) Update(ctx context.Context, sp Space) (*Space, error) {
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx,
+			`UPDATE portal_spaces SET name = $2, description = $3, updated_at = NOW()
+			 WHERE id = $1 RETURNING created_by, created_at, updated_at`,
+			sp.ID, sp.Name, sp.Description).Scan(&sp.CreatedBy, &sp.CreatedAt, &sp.UpdatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return mapNameErr(err, "updating space")
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM portal_space_groups WHERE space_id = $1`, sp.ID); err != nil {
+			return fmt.Errorf("clearing space groups: %w", err)
+		}
+		return writeGroups(ctx, tx, sp.ID, sp.Groups)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sp, nil
+}
+
+// Delete removes the space; its bindings, members and collection ownership
+// go with it.
+func (s *PostgresStore) Delete(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM portal_spaces WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting space: %w", err)
+	}
+	return requireRow(res, "deleting space")
+}
+
+// List reads every space.
+func (s *PostgresStore) List(ctx context.Context) ([]Space, error) {
+	return s.querySpaces(ctx, `SELECT `+spaceColumns+`, '' FROM portal_spaces ORDER BY name, id`)
+}
+
+// ForPrincipal reads the principal's spaces with their role in each.
+func (s *PostgresStore) ForPrincipal(ctx context.Context, p Principal) ([]Space, error) {
+	if anonymous(p) {
+		return nil, nil
+	}
+	return s.querySpaces(ctx, `
+		SELECT s.id, s.name, s.description, s.created_by, s.created_at, s.updated_at, r.role
+		  FROM portal_spaces s
+		  JOIN (`+memberRolesSQL+`) r ON r.space_id = s.id
+		 ORDER BY s.name, s.id`, principalArgs(p)...)
+}
+
+// Role resolves the principal's role in one space.
+func (s *PostgresStore) Role(ctx context.Context, spaceID string, p Principal) (string, error) {
+	if anonymous(p) {
+		return "", nil
+	}
+	return s.strongest(ctx, "resolving space role",
+		`SELECT r.role FROM (`+memberRolesSQL+`) r WHERE r.space_id = $3`,
+		append(principalArgs(p), spaceID)...)
+}
+
+// PutMember upserts a managed member.
+func (s *PostgresStore) PutMember(ctx context.Context, spaceID string, m Member) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO portal_space_members (space_id, email, role, added_by)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (space_id, email) DO UPDATE SET role = EXCLUDED.role`,
+		spaceID, m.Email, m.Role, m.AddedBy)
+	if err != nil {
+		return fmt.Errorf("writing space member: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember deletes a managed member.
+func (s *PostgresStore) RemoveMember(ctx context.Context, spaceID, email string) error {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM portal_space_members WHERE space_id = $1 AND email = $2`,
+		spaceID, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		return fmt.Errorf("removing space member: %w", err)
+	}
+	return requireRow(res, "removing space member")
+}
+
+// Members reads a space's managed members.
+func (s *PostgresStore) Members(ctx context.Context, spaceID string) ([]Member, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT email, role, added_by, created_at FROM portal_space_members
+		 WHERE space_id = $1 ORDER BY email`, spaceID)
+	if err != nil {
+		return nil, fmt.Errorf("listing space members: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // best-effort cleanup after read-only query
+
+	members := []Member{}
+	for rows.Next() {
+		var m Member
+		if err := rows.Scan(&m.Email, &m.Role, &m.AddedBy, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning space member: %w", err)
+		}
+		members = append(members, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating space members: %w", err)
+	}
+	return members, nil
+}
+
+// AttachCollection records the space as the collection's owner. Attaching a
+// collection the space already owns is a no-op; one owned by another space
+// affects no row, which is how the conflict is told apart.
+func (s *PostgresStore) AttachCollection(ctx context.Context, spaceID, collectionID, addedBy string) error {
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO portal_space_collections (collection_id, space_id, added_by)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (collection_id) DO UPDATE SET space_id = EXCLUDED.space_id
+		 WHERE portal_space_collections.space_id = EXCLUDED.space_id`,
+		collectionID, spaceID, addedBy)
+	if err != nil {
+		return fmt.Errorf("attaching collection to space: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrOwnedByPeer
+	}
+	return nil
+}
+
+// DetachCollection removes the space's ownership of a collection.
+func (s *PostgresStore) DetachCollection(ctx context.Context, spaceID, collectionID string) error {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM portal_space_collections WHERE space_id = $1 AND collection_id = $2`,
+		spaceID, collectionID)
+	if err != nil {
+		return fmt.Errorf("detaching collection from space: %w", err)
+	}
+	return requireRow(res, "detaching collection from space")
+}
+
+// Collections reads the ids of the collections a space owns.
+func (s *PostgresStore) Collections(ctx context.Context, spaceID string) ([]string, error) {
+	return s.queryIDs(ctx, "listing space collections",
+		`SELECT collection_id FROM portal_space_collections WHERE space_id = $1
+		 ORDER BY added_at, collection_id`, spaceID)
+}
+
+// CollectionSpace reads the id of the space owning a collection.
+func (s *PostgresStore) CollectionSpace(ctx context.Context, collectionID string) (string, error) {
+	var id string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT space_id FROM portal_space_collections WHERE collection_id = $1`, collectionID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading collection space: %w", err)
+	}
+	return id, nil
+}
+
+// CollectionRole resolves the principal's role on a collection.
+func (s *PostgresStore) CollectionRole(ctx context.Context, collectionID string, p Principal) (string, error) {
+	if anonymous(p) {
+		return "", nil
+	}
+	return s.strongest(ctx, "resolving collection space role", `
+		SELECT r.role
+		  FROM portal_space_collections sc
+		  JOIN (`+memberRolesSQL+`) r ON r.space_id = sc.space_id
+		 WHERE sc.collection_id = $3`,
+		append(principalArgs(p), collectionID)...)
+}
+
+// AssetRole resolves the principal's role on an asset through every live
+// collection holding it that a space owns.
+func (s *PostgresStore) AssetRole(ctx context.Context, assetID string, p Principal) (string, error) {
+	if anonymous(p) {
+		return "", nil
+	}
+	return s.strongest(ctx, "resolving asset space role", `
+		SELECT r.role
+		  FROM portal_collection_items ci
+		  JOIN portal_collection_sections cs ON cs.id = ci.section_id
+		  JOIN portal_collections c ON c.id = cs.collection_id AND c.deleted_at IS NULL
+		  JOIN portal_space_collections sc ON sc.collection_id = c.id
+		  JOIN (`+memberRolesSQL+`) r ON r.space_id = sc.space_id
+		 WHERE ci.asset_id = $3`,
+		append(principalArgs(p), assetID)...)
+}
+
+// CollectionIDs reads the ids of the live collections the principal's spaces
+// own.
+func (s *PostgresStore) CollectionIDs(ctx context.Context, p Principal) ([]string, error) {
+	if anonymous(p) {
+		return nil, nil
+	}
+	return s.queryIDs(ctx, "listing space collections for member", `
+		SELECT DISTINCT sc.collection_id
+		  FROM portal_space_collections sc
+		  JOIN portal_collections c ON c.id = sc.collection_id AND c.deleted_at IS NULL
+		  JOIN (`+memberRolesSQL+`) r ON r.space_id = sc.space_id
+		 ORDER BY sc.collection_id`, principalArgs(p)...)
+}
+
+// ObserveGroups replaces the recorded bound groups of an address. Groups no
+// space binds are not recorded.
+func (s *PostgresStore) ObserveGroups(ctx context.Context, email string, groups []string) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil
+	}
+	if groups == nil {
+		groups = []string{} // a NULL array would match nothing and clear nothing
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM portal_space_group_members WHERE email = $1 AND NOT (group_name = ANY($2))`,
+			email, pq.Array(groups)); err != nil {
+			return fmt.Errorf("clearing observed space groups: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO portal_space_group_members (email, group_name)
+			 SELECT DISTINCT $1::text, g.group_name FROM portal_space_groups g WHERE g.group_name = ANY($2)
+			 ON CONFLICT (email, group_name) DO UPDATE SET seen_at = NOW()`,
+			email, pq.Array(groups)); err != nil {
+			return fmt.Errorf("recording observed space groups: %w", err)
+		}
+		return nil
+	})
+}
+
+// inTx runs fn in a transaction, committing when it returns nil.
+func (s *PostgresStore) inTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning space transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback() //nolint:errcheck // the fn error is the one worth returning
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing space transaction: %w", err)
+	}
+	return nil
+}
+
+// writeGroups inserts a space's bindings.
+func writeGroups(ctx context.Context, tx *sql.Tx, spaceID string, groups []GroupBinding) error {
+	for _, g := range groups {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO portal_space_groups (space_id, group_name, role) VALUES ($1, $2, $3)`,
+			spaceID, g.Group, g.Role); err != nil {
+			return fmt.Errorf("writing space group: %w", err)
+		}
+	}
+	return nil
+}
+
+// querySpaces reads spaces whose query selects spaceColumns plus a role, and
+// attaches their bindings. A space listed once per membership is folded into
+// one entry carrying the strongest role.
+func (s *PostgresStore) querySpaces(ctx context.Context, query string, args ...any) ([]Space, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing spaces: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // best-effort cleanup after read-only query
+
+	spaces := []Space{}
+	index := map[string]int{}
+	for rows.Next() {
+		var sp Space
+		if err := rows.Scan(&sp.ID, &sp.Name, &sp.Description, &sp.CreatedBy,
+			&sp.CreatedAt, &sp.UpdatedAt, &sp.Role); err != nil {
+			return nil, fmt.Errorf("scanning space: %w", err)
+		}
+		if i, ok := index[sp.ID]; ok {
+			spaces[i].Role = Strongest(spaces[i].Role, sp.Role)
+			continue
+		}
+		index[sp.ID] = len(spaces)
+		spaces = append(spaces, sp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating spaces: %w", err)
+	}
+	if err := s.loadGroups(ctx, spaces); err != nil {
+		return nil, err
+	}
+	return spaces, nil
+}
+
+// loadGroups fills the bindings of spaces in one query.
+func (s *PostgresStore) loadGroups(ctx context.Context, spaces []Space) error {
+	if len(spaces) == 0 {
+		return nil
+	}
+	ids := make([]string, len(spaces))
+	index := make(map[string]int, len(spaces))
+	for i := range spaces {
+		ids[i] = spaces[i].ID
+		index[spaces[i].ID] = i
+		spaces[i].Groups = []GroupBinding{}
+	}
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT space_id, group_name, role FROM portal_space_groups
+		 WHERE space_id = ANY($1) ORDER BY group_name`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("listing space groups: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // best-effort cleanup after read-only query
+
+	for rows.Next() {
+		var spaceID string
+		var g GroupBinding
+		if err := rows.Scan(&spaceID, &g.Group, &g.Role); err != nil {
+			return fmt.Errorf("scanning space group: %w", err)
+		}
+		if i, ok := index[spaceID]; ok {
+			spaces[i].Groups = append(spaces[i].Groups, g)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating space groups: %w", err)
+	}
+	return nil
+}
+
+// strongest runs a query yielding one role per row and returns the strongest.
+func (s *PostgresStore) strongest(ctx context.Context, what, query string, args ...any) (string, error) {
+	roles, err := s.queryIDs(ctx, what, query, args...)
+	if err != nil {
+		return "", err
+	}
+	return Strongest(roles...), nil
+}
+
+// queryIDs runs a query yielding one string column.
+func (s *PostgresStore) queryIDs(ctx context.Context, what, query string, args ...any) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", what, err)
+	}
+	defer rows.Close() //nolint:errcheck // best-effort cleanup after read-only query
+
+	var out []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("%s: %w", what, err)
+		}
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", what, err)
+	}
+	return out, nil
+}
+
+// mapNameErr maps the name index's unique violation to ErrNameTaken.
+func mapNameErr(err error, what string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && string(pqErr.Code) == pgUniqueViolation {
+		return ErrNameTaken
+	}
+	return fmt.Errorf("%s: %w", what, err)
+}
+
+// requireRow maps a statement that affected no row to ErrNotFound.
+func requireRow(res sql.Result, what string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", what, err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
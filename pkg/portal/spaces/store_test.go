@@ -0,0 +1,175 @@
+package spaces
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockStore(t *testing.T) (*PostgresStore, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return NewPostgresStore(db), mock
+}
+
+var groupCols = []string{"space_id", "group_name", "role"}
+
+func TestPostgresStoreSpaces(t *testing.T) {
+	store, mock := newMockStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	sp := Space{ID: "spc_1", Name: "Revenue", CreatedBy: "admin@x.io",
+		Groups: []GroupBinding{{Group: "finance", Role: RoleViewer}}}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO portal_spaces`).WithArgs("spc_1", "Revenue", "", "admin@x.io").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(now, now))
+	mock.ExpectExec(`INSERT INTO portal_space_groups`).WithArgs("spc_1", "finance", RoleViewer).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	created, err := store.Create(ctx, sp)
+	require.NoError(t, err)
+	assert.Equal(t, now, created.CreatedAt)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO portal_spaces`).WillReturnError(&pq.Error{Code: pgUniqueViolation})
+	mock.ExpectRollback()
+	_, err = store.Create(ctx, sp)
+	assert.ErrorIs(t, err, ErrNameTaken)
+
+	mock.ExpectQuery(`FROM portal_spaces WHERE id = \$1`).WithArgs("spc_1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_by", "created_at", "updated_at"}).
+			AddRow("spc_1", "Revenue", "", "admin@x.io", now, now))
+	mock.ExpectQuery(`FROM portal_space_groups`).
+		WillReturnRows(sqlmock.NewRows(groupCols).AddRow("spc_1", "finance", RoleViewer))
+	got, err := store.Get(ctx, "spc_1")
+	require.NoError(t, err)
+	assert.Equal(t, []GroupBinding{{Group: "finance", Role: RoleViewer}}, got.Groups)
+
+	mock.ExpectQuery(`FROM portal_spaces WHERE id = \$1`).WithArgs("spc_9").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = store.Get(ctx, "spc_9")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE portal_spaces`).WithArgs("spc_9", "Revenue", "").
+		WillReturnRows(sqlmock.NewRows([]string{"created_by"}))
+	mock.ExpectRollback()
+	_, err = store.Update(ctx, Space{ID: "spc_9", Name: "Revenue"})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	mock.ExpectExec(`DELETE FROM portal_spaces`).WithArgs("spc_9").WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, store.Delete(ctx, "spc_9"), ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStoreForPrincipal(t *testing.T) {
+	store, mock := newMockStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	p := Principal{Email: "Ana@X.io", Groups: []string{"finance"}}
+
+	// Ana is a managed editor and a viewer through the finance group: one
+	// entry, the stronger role.
+	mock.ExpectQuery(`JOIN \(\s+SELECT space_id, role FROM portal_space_members`).
+		WithArgs("ana@x.io", pq.Array([]string{"finance"})).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_by", "created_at", "updated_at", "role"}).
+			AddRow("spc_1", "Revenue", "", "", now, now, RoleViewer).
+			AddRow("spc_1", "Revenue", "", "", now, now, RoleEditor))
+	mock.ExpectQuery(`FROM portal_space_groups`).WillReturnRows(sqlmock.NewRows(groupCols))
+	mine, err := store.ForPrincipal(ctx, p)
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+	assert.Equal(t, RoleEditor, mine[0].Role)
+
+	none, err := store.ForPrincipal(ctx, Principal{})
+	require.NoError(t, err)
+	assert.Empty(t, none, "an anonymous principal skips the query")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStoreRoles(t *testing.T) {
+	store, mock := newMockStore(t)
+	ctx := context.Background()
+	p := Principal{Email: "ana@x.io", Groups: []string{"finance"}}
+
+	mock.ExpectQuery(`FROM portal_space_collections sc\s+JOIN`).
+		WithArgs("ana@x.io", pq.Array([]string{"finance"}), "col_1").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(RoleViewer).AddRow(RoleManager))
+	role, err := store.CollectionRole(ctx, "col_1", p)
+	require.NoError(t, err)
+	assert.Equal(t, RoleManager, role)
+
+	mock.ExpectQuery(`FROM portal_collection_items ci`).WithArgs("ana@x.io", pq.Array([]string{"finance"}), "a1").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}))
+	role, err = store.AssetRole(ctx, "a1", p)
+	require.NoError(t, err)
+	assert.Empty(t, role)
+
+	mock.ExpectQuery(`FROM portal_collection_items ci`).WillReturnError(errors.New("db down"))
+	_, err = store.AssetRole(ctx, "a1", p)
+	assert.Error(t, err)
+
+	mock.ExpectQuery(`SELECT DISTINCT sc.collection_id .+ JOIN portal_collections c ON c.id = sc.collection_id AND c.deleted_at IS NULL`).
+		WillReturnRows(sqlmock.NewRows([]string{"collection_id"}).AddRow("col_1").AddRow("col_2"))
+	ids, err := store.CollectionIDs(ctx, p)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"col_1", "col_2"}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStoreCollections(t *testing.T) {
+	store, mock := newMockStore(t)
+	ctx := context.Background()
+
+	mock.ExpectExec(`INSERT INTO portal_space_collections`).WithArgs("col_1", "spc_1", "ana@x.io").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, store.AttachCollection(ctx, "spc_1", "col_1", "ana@x.io"))
+
+	mock.ExpectExec(`INSERT INTO portal_space_collections`).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, store.AttachCollection(ctx, "spc_2", "col_1", "ana@x.io"), ErrOwnedByPeer,
+		"the conflict update only applies within the same space")
+
+	mock.ExpectExec(`DELETE FROM portal_space_collections`).WithArgs("spc_2", "col_1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, store.DetachCollection(ctx, "spc_2", "col_1"), ErrNotFound)
+
+	mock.ExpectQuery(`SELECT space_id FROM portal_space_collections`).WithArgs("col_3").
+		WillReturnRows(sqlmock.NewRows([]string{"space_id"}))
+	id, err := store.CollectionSpace(ctx, "col_3")
+	require.NoError(t, err)
+	assert.Empty(t, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStoreMembers(t *testing.T) {
+	store, mock := newMockStore(t)
+	ctx := context.Background()
+
+	mock.ExpectExec(`INSERT INTO portal_space_members .+ ON CONFLICT`).
+		WithArgs("spc_1", "ana@x.io", RoleEditor, "lead@x.io").WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, store.PutMember(ctx, "spc_1", Member{Email: "ana@x.io", Role: RoleEditor, AddedBy: "lead@x.io"}))
+
+	mock.ExpectExec(`DELETE FROM portal_space_members`).WithArgs("spc_1", "ana@x.io").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, store.RemoveMember(ctx, "spc_1", " ANA@x.io"), ErrNotFound)
+
+	// Losing every group clears what was recorded rather than matching nothing.
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM portal_space_group_members`).WithArgs("ana@x.io", pq.Array([]string{})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`INSERT INTO portal_space_group_members`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	require.NoError(t, store.ObserveGroups(ctx, "Ana@x.io", nil))
+
+	require.NoError(t, store.ObserveGroups(ctx, " ", []string{"finance"}), "no address, nothing to record")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -53,9 +53,10 @@ func NewPostgresShareStore(db *sql.DB) ShareStore { return portalstore.NewPostgr
 // s3Client deletes the objects of versions the retention cap prunes and may be
 // nil in database-only mode, where the prune still trims the table; maxVersions
 // is the deployment default a per-asset override supersedes, nil selecting the
-// platform default of 100.
-func NewPostgresVersionStore(db *sql.DB, s3Client S3Client, maxVersions *int) VersionStore {
-	return portalversions.NewPostgres(db, s3Client, maxVersions)
+// platform default of 100. Pass indexjobs.WithProducer to have each new version
+// enqueue its content for indexing.
+func NewPostgresVersionStore(db *sql.DB, s3Client S3Client, maxVersions *int, opts ...indexjobs.StoreOption) VersionStore {
+	return portalversions.NewPostgres(db, s3Client, maxVersions, opts...)
 }
 
 // NewPostgresCollectionStore creates a new PostgreSQL collection store. Pass
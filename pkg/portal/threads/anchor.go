@@ -0,0 +1,141 @@
+package threads
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/txn2/mcp-data-platform/pkg/textpatch"
+)
+
+// Document anchor types. A text_quote anchor is what the viewer sends for a
+// selection; a region anchor names an element or section with no quote. Any
+// other type (a collection's "section" anchor) is stored as sent and never
+// resolved against content.
+const (
+	AnchorTypeTextQuote = "text_quote"
+	AnchorTypeRegion    = "region"
+)
+
+// Document anchor states.
+const (
+	// AnchorStateActive means the anchor resolved against the version it was
+	// last checked on.
+	AnchorStateActive = "active"
+	// AnchorStateOrphaned means the anchored text or region was deleted. The
+	// anchor keeps what it last pointed at, and is checked again on each new
+	// version, so an edit that restores the text (a revert) re-attaches it.
+	AnchorStateOrphaned = "orphaned"
+)
+
+// DocumentAnchor is the stored anchor of a thread pinned to a region of an
+// asset or knowledge page. The embedded textpatch.Anchor says where; State and
+// Version say whether that still holds, and as of which content version.
+type DocumentAnchor struct {
+	Type string `json:"type"`
+	textpatch.Anchor
+	State string `json:"state,omitempty"`
+	// Version is the content version the anchor was last checked against.
+	Version int `json:"version,omitempty"`
+	// OrphanedAtVersion is the first version the anchor failed to resolve on,
+	// set while it is orphaned.
+	OrphanedAtVersion int `json:"orphaned_at_version,omitempty"`
+}
+
+// ParseDocumentAnchor decodes a thread's anchor, reporting false for an empty
+// anchor or one that is not anchored to document content.
+func ParseDocumentAnchor(raw json.RawMessage) (DocumentAnchor, bool) {
+	if len(raw) == 0 {
+		return DocumentAnchor{}, false
+	}
+	var a DocumentAnchor
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return DocumentAnchor{}, false
+	}
+	if a.Type != AnchorTypeTextQuote && a.Type != AnchorTypeRegion {
+		return DocumentAnchor{}, false
+	}
+	return a, true
+}
+
+// TargetContent is the current content of an anchorable target.
+type TargetContent struct {
+	Body        string
+	ContentType string
+	Version     int
+}
+
+// ContentReader returns the current content of an asset or knowledge page, for
+// pinning and carrying anchors. It returns an error for a target whose content
+// is not text.
+type ContentReader func(ctx context.Context, targetType, targetID string) (TargetContent, error)
+
+// AnchorWriter persists an anchor carried to a new version. The PostgreSQL
+// thread store implements it; it is a separate capability, like SearchThreads,
+// because only the readers that carry anchors need it.
+type AnchorWriter interface {
+	UpdateAnchor(ctx context.Context, id string, anchor json.RawMessage) error
+}
+
+// AnchorableTarget reports whether a target type has document content to
+// anchor to.
+func AnchorableTarget(targetType string) bool {
+	return targetType == targetTypeAsset || targetType == targetTypeKnowledgePage
+}
+
+// PinAnchor resolves a new thread's anchor against the content it is being
+// left on and returns it in stored form: scoped, with fresh context, active as
+// of c.Version. An anchor that is not a document anchor is returned as sent. A
+// document anchor that does not resolve is refused with the textpatch error,
+// whose message says what did not match.
+func PinAnchor(raw json.RawMessage, c TargetContent) (json.RawMessage, error) {
+	a, ok := ParseDocumentAnchor(raw)
+	if !ok {
+		return raw, nil
+	}
+	pinned, err := textpatch.PinAnchor(c.Body, textpatch.SyntaxForContentType(c.ContentType), a.Anchor)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // the textpatch error is the corrective message
+	}
+	a.Anchor = pinned
+	if a.Exact == "" {
+		a.Type = AnchorTypeRegion
+	}
+	a.State, a.Version, a.OrphanedAtVersion = AnchorStateActive, c.Version, 0
+	return marshalAnchor(a)
+}
+
+// CarryAnchor brings t's document anchor forward to c, the target's current
+// content, and reports whether the anchor changed. An anchor already checked
+// against c.Version is left alone, so a thread list re-resolves each anchor
+// once per content version rather than once per read. One that no longer
+// resolves is marked orphaned.
+func CarryAnchor(t *Thread, c TargetContent) (bool, error) {
+	a, ok := ParseDocumentAnchor(t.Anchor)
+	if !ok || a.Version >= c.Version {
+		return false, nil
+	}
+	moved, found := textpatch.Reanchor(c.Body, textpatch.SyntaxForContentType(c.ContentType), a.Anchor)
+	switch {
+	case found:
+		a.Anchor, a.State, a.OrphanedAtVersion = moved, AnchorStateActive, 0
+	case a.State != AnchorStateOrphaned:
+		a.State, a.OrphanedAtVersion = AnchorStateOrphaned, c.Version
+	}
+	a.Version = c.Version
+	raw, err := marshalAnchor(a)
+	if err != nil {
+		return false, err
+	}
+	t.Anchor = raw
+	return true, nil
+}
+
+// marshalAnchor encodes a document anchor for the anchor column.
+func marshalAnchor(a DocumentAnchor) (json.RawMessage, error) {
+	raw, err := json.Marshal(a)
+	if err != nil {
+		return nil, fmt.Errorf("encoding anchor: %w", err)
+	}
+	return raw, nil
+}
@@ -0,0 +1,97 @@
+package threads
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txn2/mcp-data-platform/pkg/textpatch"
+)
+
+func TestParseDocumentAnchor(t *testing.T) {
+	_, ok := ParseDocumentAnchor(nil)
+	assert.False(t, ok)
+	_, ok = ParseDocumentAnchor(json.RawMessage(`{"type":"section","section_id":"s1"}`))
+	assert.False(t, ok, "a collection section anchor is not a document anchor")
+	_, ok = ParseDocumentAnchor(json.RawMessage(`not json`))
+	assert.False(t, ok)
+
+	a, ok := ParseDocumentAnchor(json.RawMessage(`{"type":"text_quote","exact":"q","selector":"#k","state":"active","version":2}`))
+	require.True(t, ok)
+	assert.Equal(t, "#k", a.Selector)
+	assert.Equal(t, 2, a.Version)
+}
+
+func TestPinAnchor(t *testing.T) {
+	page := TargetContent{Body: "# Plan\n\n## Risks\n\nVendor lock-in is likely.\n", ContentType: "text/markdown", Version: 3}
+
+	raw, err := PinAnchor(json.RawMessage(`{"type":"text_quote","exact":"lock-in is likely"}`), page)
+	require.NoError(t, err)
+	a, ok := ParseDocumentAnchor(raw)
+	require.True(t, ok)
+	assert.Equal(t, "Plan > Risks", a.Section)
+	assert.Equal(t, AnchorStateActive, a.State)
+	assert.Equal(t, 3, a.Version)
+
+	raw, err = PinAnchor(json.RawMessage(`{"type":"text_quote","section":"Risks"}`), page)
+	require.NoError(t, err)
+	a, _ = ParseDocumentAnchor(raw)
+	assert.Equal(t, AnchorTypeRegion, a.Type, "a quote-less anchor is a region anchor")
+
+	_, err = PinAnchor(json.RawMessage(`{"type":"text_quote","exact":"not in the page"}`), page)
+	var pe *textpatch.Error
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, textpatch.CodeNoMatch, pe.Code)
+
+	section := json.RawMessage(`{"type":"section","section_id":"s1"}`)
+	raw, err = PinAnchor(section, page)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(section), string(raw), "other anchors are stored as sent")
+}
+
+func TestCarryAnchor(t *testing.T) {
+	v1 := TargetContent{Body: `<table><tr><td id="q3">Q3 revenue: $4.1M</td></tr></table>`, ContentType: "text/html", Version: 1}
+	raw, err := PinAnchor(json.RawMessage(`{"type":"text_quote","exact":"$4.1M"}`), v1)
+	require.NoError(t, err)
+	thread := &Thread{ID: "thr_1", Anchor: raw}
+
+	changed, err := CarryAnchor(thread, v1)
+	require.NoError(t, err)
+	assert.False(t, changed, "already checked against this version")
+
+	v2 := TargetContent{Body: `<h1>Revenue</h1><table><tr><td id="q3">Q3 revenue: $4.1M (audited)</td></tr></table>`, ContentType: "text/html", Version: 2}
+	changed, err = CarryAnchor(thread, v2)
+	require.NoError(t, err)
+	require.True(t, changed)
+	a, _ := ParseDocumentAnchor(thread.Anchor)
+	assert.Equal(t, AnchorStateActive, a.State)
+	assert.Equal(t, "#q3", a.Selector)
+	assert.Equal(t, " (audited)", a.Suffix)
+	assert.Equal(t, 2, a.Version)
+
+	v3 := TargetContent{Body: `<h1>Revenue</h1><table><tr><td id="q4">Q4 pending</td></tr></table>`, ContentType: "text/html", Version: 3}
+	changed, err = CarryAnchor(thread, v3)
+	require.NoError(t, err)
+	require.True(t, changed)
+	a, _ = ParseDocumentAnchor(thread.Anchor)
+	assert.Equal(t, AnchorStateOrphaned, a.State)
+	assert.Equal(t, 3, a.OrphanedAtVersion)
+	assert.Equal(t, "$4.1M", a.Exact, "an orphan keeps what it pointed at")
+
+	v4 := TargetContent{Body: `<p>Still pending</p>`, ContentType: "text/html", Version: 4}
+	_, err = CarryAnchor(thread, v4)
+	require.NoError(t, err)
+	a, _ = ParseDocumentAnchor(thread.Anchor)
+	assert.Equal(t, 3, a.OrphanedAtVersion, "the first orphaned version is kept")
+	assert.Equal(t, 4, a.Version)
+
+	v5 := v2
+	v5.Version = 5
+	_, err = CarryAnchor(thread, v5)
+	require.NoError(t, err)
+	a, _ = ParseDocumentAnchor(thread.Anchor)
+	assert.Equal(t, AnchorStateActive, a.State, "a revert re-attaches an orphan")
+	assert.Zero(t, a.OrphanedAtVersion)
+}
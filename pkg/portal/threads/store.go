@@ -600,6 +600,18 @@ func (s *postgresThreadStore) SoftDeleteThread(ctx context.Context, id string) e
 	return nil
 }
 
+// UpdateAnchor replaces a thread's anchor (AnchorWriter). Carrying an anchor to
+// a new content version is bookkeeping, not activity on the thread, so
+// updated_at is left alone and the thread does not jump to the top of a list.
+func (s *postgresThreadStore) UpdateAnchor(ctx context.Context, id string, anchor json.RawMessage) error {
+	if _, err := s.db.ExecContext(ctx,
+		`UPDATE portal_threads SET anchor = $1 WHERE id = $2 AND deleted_at IS NULL`,
+		nullJSON(anchor), id); err != nil {
+		return fmt.Errorf("updating thread anchor: %w", err)
+	}
+	return nil
+}
+
 func (s *postgresThreadStore) LinkInsight(ctx context.Context, threadIDs []string, insightID, actorID, actorEmail string) ([]string, error) { //nolint:revive // interface impl
 	if len(threadIDs) == 0 || insightID == "" {
 		return nil, nil
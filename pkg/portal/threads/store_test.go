@@ -237,6 +237,21 @@ func TestThreadStoreSoftDeleteThread(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestThreadStoreUpdateAnchor(t *testing.T) {
+	store, mock := newThreadStoreMock(t)
+	anchor := json.RawMessage(`{"type":"text_quote","exact":"x","state":"orphaned"}`)
+
+	mock.ExpectExec("UPDATE portal_threads SET anchor").
+		WithArgs([]byte(anchor), "thr_1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, store.UpdateAnchor(context.Background(), "thr_1", anchor))
+
+	mock.ExpectExec("UPDATE portal_threads SET anchor").
+		WillReturnError(errors.New("db down"))
+	require.Error(t, store.UpdateAnchor(context.Background(), "thr_1", anchor))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestThreadStoreCreateThreadInsertError(t *testing.T) {
 	store, mock := newThreadStoreMock(t)
 	mock.ExpectBegin()
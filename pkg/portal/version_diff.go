@@ -0,0 +1,152 @@
+package portal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/txn2/mcp-data-platform/internal/portal/versiondiff"
+)
+
+// errVersionTooLargeToCompare is returned for a version over
+// versiondiff.MaxBodyBytes, whether its row or its body says so.
+const errVersionTooLargeToCompare = "version too large to compare"
+
+// versionDiffResponse is the body of GET /api/v1/portal/assets/{id}/versions/diff:
+// the pair compared and the diff between them, in the format the content type
+// calls for.
+type versionDiffResponse struct {
+	AssetID     string `json:"asset_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	FromVersion int    `json:"from_version" example:"2"`
+	ToVersion   int    `json:"to_version" example:"3"`
+	versiondiff.Diff
+}
+
+// diffVersions handles GET /api/v1/portal/assets/{id}/versions/diff.
+//
+// Comparing is reading: both bodies are already open to anyone who may view
+// the asset through the version content route, so the diff is gated the same
+// way. Restoring an old version is the revert route, gated like editing.
+//
+// @Summary      Compare asset versions
+// @Description  Compares two versions of an asset. HTML is compared block by block as it renders, CSV and TSV cell by cell with columns matched by header, and other text line by line as a unified diff. to defaults to the current version and from to the one before it.
+// @Tags         Assets
+// @Produce      json
+// @Param        id    path   string   true   "Asset ID"
+// @Param        from  query  integer  false  "Older version (default: to - 1)"
+// @Param        to    query  integer  false  "Newer version (default: current version)"
+// @Success      200  {object}  versionDiffResponse
+// @Failure      400  {object}  problemDetail
+// @Failure      401  {object}  problemDetail
+// @Failure      403  {object}  problemDetail
+// @Failure      404  {object}  problemDetail
+// @Failure      410  {object}  problemDetail
+// @Failure      422  {object}  problemDetail
+// @Failure      500  {object}  problemDetail
+// @Failure      503  {object}  problemDetail
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /portal/assets/{id}/versions/diff [get]
+func (h *Handler) diffVersions(w http.ResponseWriter, r *http.Request) {
+	user := GetUser(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, errAuthRequired)
+		return
+	}
+
+	id := r.PathValue(pathKeyID)
+	asset, err := h.deps.AssetStore.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, errAssetNotFound)
+		return
+	}
+	if asset.DeletedAt != nil {
+		writeError(w, http.StatusGone, errAssetDeleted)
+		return
+	}
+	if !h.canViewAsset(w, r, id, asset, user) {
+		return
+	}
+	if !h.versionedStorageReady() {
+		writeError(w, http.StatusServiceUnavailable, errStorageNotReady)
+		return
+	}
+
+	from, to, ok := diffRange(w, r, asset.CurrentVersion)
+	if !ok {
+		return
+	}
+	diff, herr := h.compareVersions(r.Context(), id, from, to)
+	if herr != nil {
+		writeError(w, herr.code, herr.msg)
+		return
+	}
+	writeJSON(w, http.StatusOK, versionDiffResponse{AssetID: id, FromVersion: from, ToVersion: to, Diff: diff})
+}
+
+// diffRange resolves the from and to query parameters: to defaults to the
+// current version and from to the version before to.
+func diffRange(w http.ResponseWriter, r *http.Request, current int) (from, to int, ok bool) {
+	if to, ok = versionQueryParam(w, r, "to", current); !ok {
+		return 0, 0, false
+	}
+	if from, ok = versionQueryParam(w, r, "from", to-1); !ok {
+		return 0, 0, false
+	}
+	if from < 1 {
+		writeError(w, http.StatusBadRequest, "there is no earlier version to compare against")
+		return 0, 0, false
+	}
+	return from, to, true
+}
+
+// compareVersions reads two versions of an asset and diffs them.
+func (h *Handler) compareVersions(ctx context.Context, assetID string, from, to int) (versiondiff.Diff, *httpError) {
+	var (
+		types  [2]string
+		bodies [2][]byte
+	)
+	for i, n := range [2]int{from, to} {
+		ver, err := h.deps.VersionStore.GetByVersion(ctx, assetID, n)
+		if err != nil {
+			return versiondiff.Diff{}, &httpError{http.StatusNotFound, "version not found"}
+		}
+		// Refuse before reading: the size is on the version row.
+		if ver.SizeBytes > versiondiff.MaxBodyBytes {
+			return versiondiff.Diff{}, &httpError{http.StatusUnprocessableEntity, errVersionTooLargeToCompare}
+		}
+		data, _, err := h.deps.S3Client.GetObject(ctx, ver.S3Bucket, ver.S3Key)
+		if err != nil {
+			return versiondiff.Diff{}, &httpError{http.StatusInternalServerError, "failed to retrieve version content"}
+		}
+		types[i], bodies[i] = ver.ContentType, data
+	}
+
+	diff, err := versiondiff.Compare(types[0], bodies[0], types[1], bodies[1])
+	switch {
+	case errors.Is(err, versiondiff.ErrNotComparable):
+		return versiondiff.Diff{}, &httpError{http.StatusUnprocessableEntity, "versions of this content type cannot be compared"}
+	case errors.Is(err, versiondiff.ErrTooLarge):
+		return versiondiff.Diff{}, &httpError{http.StatusUnprocessableEntity, errVersionTooLargeToCompare}
+	case err != nil:
+		return versiondiff.Diff{}, &httpError{http.StatusInternalServerError, "failed to compare versions"}
+	}
+	return diff, nil
+}
+
+// versionQueryParam reads a version number from the query, fallback when the
+// parameter is absent. Unlike intParam it rejects a malformed value: comparing
+// a version the caller did not ask for would answer a different question.
+func versionQueryParam(w http.ResponseWriter, r *http.Request, name string, fallback int) (int, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return fallback, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		writeError(w, http.StatusBadRequest, "invalid "+name+" version")
+		return 0, false
+	}
+	return n, true
+}
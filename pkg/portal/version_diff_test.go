@@ -0,0 +1,137 @@
+package portal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// numberedVersionStore serves GetByVersion from a fixed set of versions, so a
+// diff can read two different ones.
+type numberedVersionStore struct {
+	*mockVersionStore
+	byNumber map[int]*AssetVersion
+}
+
+func (s *numberedVersionStore) GetByVersion(_ context.Context, _ string, version int) (*AssetVersion, error) {
+	if v, ok := s.byNumber[version]; ok {
+		return v, nil
+	}
+	return nil, errVersionMissing
+}
+
+// keyedS3Client serves GetObject by key.
+type keyedS3Client struct {
+	*mockS3Client
+	objects map[string][]byte
+}
+
+func (c *keyedS3Client) GetObject(_ context.Context, _, key string) (body []byte, contentType string, err error) {
+	return c.objects[key], "", nil
+}
+
+func newVersionDiffHandler(asset *Asset, user *User, versions map[int]*AssetVersion, objects map[string][]byte) *Handler {
+	return NewHandler(Deps{
+		AssetStore:   &mockAssetStore{getAsset: asset},
+		ShareStore:   &mockShareStore{},
+		VersionStore: &numberedVersionStore{mockVersionStore: &mockVersionStore{}, byNumber: versions},
+		S3Client:     &keyedS3Client{mockS3Client: &mockS3Client{}, objects: objects},
+		RateLimit:    RateLimitConfig{RequestsPerMinute: 600, BurstSize: 100},
+	}, testAuthMiddleware(user))
+}
+
+func getVersionDiff(t *testing.T, h *Handler, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet,
+		"/api/v1/portal/assets/a1/versions/diff"+query, http.NoBody)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestDiffVersions(t *testing.T) {
+	owner := &User{UserID: "u1", Email: "owner@example.com"}
+	asset := &Asset{ID: "a1", OwnerID: "u1", CurrentVersion: 3}
+	versions := map[int]*AssetVersion{
+		1: {Version: 1, S3Key: "k1", ContentType: "text/html"},
+		2: {Version: 2, S3Key: "k2", ContentType: "text/html"},
+		3: {Version: 3, S3Key: "k3", ContentType: "text/markdown"},
+	}
+	objects := map[string][]byte{
+		"k1": []byte("<h1>Revenue</h1><p>Up 3%.</p>"),
+		"k2": []byte("<h1>Revenue</h1><p>Up 4%.</p>"),
+		"k3": []byte("# Revenue\n\nUp 4%.\n"),
+	}
+
+	t.Run("html versions get a structural diff", func(t *testing.T) {
+		w := getVersionDiff(t, newVersionDiffHandler(asset, owner, versions, objects), "?from=1&to=2")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var got map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Equal(t, "structure", got["format"])
+		assert.Equal(t, false, got["identical"])
+		assert.InDelta(t, 1, got["from_version"], 0)
+		assert.InDelta(t, 2, got["to_version"], 0)
+		blocks, ok := got["blocks"].([]any)
+		require.True(t, ok)
+		require.Len(t, blocks, 1)
+		assert.Equal(t, map[string]any{
+			"op": "changed", "element": "p", "section": "Revenue", "old": "Up 3%.", "new": "Up 4%.",
+		}, blocks[0])
+	})
+
+	t.Run("defaults compare the current version with the one before it", func(t *testing.T) {
+		w := getVersionDiff(t, newVersionDiffHandler(asset, owner, versions, objects), "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var got versionDiffResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Equal(t, 2, got.FromVersion)
+		assert.Equal(t, 3, got.ToVersion)
+		assert.Equal(t, "lines", got.Format, "a pair whose types differ falls back to the line diff")
+		assert.NotEmpty(t, got.Unified)
+	})
+
+	t.Run("the first version has nothing before it", func(t *testing.T) {
+		w := getVersionDiff(t, newVersionDiffHandler(asset, owner, versions, objects), "?to=1")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("a malformed version is rejected", func(t *testing.T) {
+		w := getVersionDiff(t, newVersionDiffHandler(asset, owner, versions, objects), "?from=two")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("a missing version is not found", func(t *testing.T) {
+		w := getVersionDiff(t, newVersionDiffHandler(asset, owner, versions, objects), "?from=1&to=9")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("binary content cannot be compared", func(t *testing.T) {
+		images := map[int]*AssetVersion{
+			1: {Version: 1, S3Key: "k1", ContentType: "image/png"},
+			2: {Version: 2, S3Key: "k2", ContentType: "image/png"},
+		}
+		w := getVersionDiff(t, newVersionDiffHandler(asset, owner, images, objects), "?from=1&to=2")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("an oversized version is refused before it is read", func(t *testing.T) {
+		big := map[int]*AssetVersion{
+			1: {Version: 1, S3Key: "k1", ContentType: "text/plain", SizeBytes: 1 << 30},
+			2: {Version: 2, S3Key: "k2", ContentType: "text/plain"},
+		}
+		w := getVersionDiff(t, newVersionDiffHandler(asset, owner, big, objects), "?from=1&to=2")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("a user who cannot view the asset cannot compare its versions", func(t *testing.T) {
+		stranger := &User{UserID: "u2", Email: "stranger@example.com"}
+		w := getVersionDiff(t, newVersionDiffHandler(asset, stranger, versions, objects), "?from=1&to=2")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
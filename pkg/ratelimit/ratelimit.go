@@ -96,23 +96,33 @@ func (l *Limiter) Rate() float64 { return l.rate }
 // Allow checks whether a request under the given key should be allowed,
 // consuming one token when it is.
 func (l *Limiter) Allow(key string) bool {
+	return l.AllowAt(key, time.Now())
+}
+
+// AllowAt is Allow with the caller's clock: the bucket refills for the time
+// between now and the key's previous request. A caller that keeps its own
+// clock passes it, so the bucket and the rest of its time-dependent logic
+// agree on what time it is. A now earlier than the previous request refills
+// nothing.
+func (l *Limiter) AllowAt(key string, now time.Time) bool {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	now := time.Now()
 	b, ok := l.buckets[key]
 	if !ok {
 		b = &bucket{tokens: float64(l.burst), lastSeen: now}
 		l.buckets[key] = b
 	}
 
-	// Refill tokens based on elapsed time.
-	elapsed := now.Sub(b.lastSeen).Seconds()
-	b.tokens += elapsed * l.rate
-	if b.tokens > float64(l.burst) {
-		b.tokens = float64(l.burst)
+	// Refill tokens based on elapsed time. A clock that went backwards
+	// neither refills nor moves the bucket's reference point.
+	if now.After(b.lastSeen) {
+		b.tokens += now.Sub(b.lastSeen).Seconds() * l.rate
+		if b.tokens > float64(l.burst) {
+			b.tokens = float64(l.burst)
+		}
+		b.lastSeen = now
 	}
-	b.lastSeen = now
 
 	if b.tokens < 1 {
 		return false
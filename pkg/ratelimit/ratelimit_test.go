@@ -78,6 +78,24 @@ func TestLimiterTokenRefill(t *testing.T) {
 	assert.True(t, l.Allow("refill"))
 }
 
+func TestLimiterAllowAtUsesTheCallersClock(t *testing.T) {
+	l := New(Config{RequestsPerMinute: 60, BurstSize: 2})
+	defer l.Close()
+	at := time.Date(2026, 1, 18, 10, 0, 0, 0, time.UTC)
+
+	// A clock that does not move refills nothing, however long the calls take.
+	assert.True(t, l.AllowAt("k", at))
+	assert.True(t, l.AllowAt("k", at))
+	assert.False(t, l.AllowAt("k", at))
+
+	// Nor does one that moves backwards.
+	assert.False(t, l.AllowAt("k", at.Add(-time.Hour)))
+
+	// One second at 60/min is one token.
+	assert.True(t, l.AllowAt("k", at.Add(time.Second)))
+	assert.False(t, l.AllowAt("k", at.Add(time.Second)))
+}
+
 func TestLimiterClose(t *testing.T) {
 	l := New(Config{RequestsPerMinute: 60, BurstSize: 5})
 	// Close should not panic and should be idempotent.
@@ -6,6 +6,7 @@ import (
 	apigatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway"
 	datahubkit "github.com/txn2/mcp-data-platform/pkg/toolkits/datahub"
 	gatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/gateway"
+	grpcgatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/grpcgateway"
 	s3kit "github.com/txn2/mcp-data-platform/pkg/toolkits/s3"
 	trinokit "github.com/txn2/mcp-data-platform/pkg/toolkits/trino"
 )
@@ -17,6 +18,7 @@ func RegisterBuiltinFactories(r *Registry) {
 	r.RegisterFactory("s3", S3Factory)
 	r.RegisterAggregateFactory(gatewaykit.Kind, GatewayAggregateFactory)
 	r.RegisterAggregateFactory(apigatewaykit.Kind, APIGatewayAggregateFactory)
+	r.RegisterAggregateFactory(grpcgatewaykit.Kind, GRPCGatewayAggregateFactory)
 }
 
 // TrinoAggregateFactory creates a single multi-connection Trino toolkit
@@ -102,6 +104,19 @@ func APIGatewayAggregateFactory(defaultName string, instances map[string]map[str
 	return apigatewaykit.NewMulti(cfg), nil
 }
 
+// GRPCGatewayAggregateFactory creates a multi-connection grpc-gateway
+// toolkit from all configured instances. Parse errors and
+// descriptor-set link errors skip the bad instance; an upstream that
+// is unreachable at startup still registers, and its reflection load
+// is retried on first use.
+func GRPCGatewayAggregateFactory(defaultName string, instances map[string]map[string]any) (Toolkit, error) {
+	cfg, err := grpcgatewaykit.ParseMultiConfig(defaultName, instances)
+	if err != nil {
+		return nil, fmt.Errorf("parsing grpcgateway multi config: %w", err)
+	}
+	return grpcgatewaykit.NewMulti(cfg), nil
+}
+
 // ValidateConnectionConfig validates a connection config map against
 // the per-kind parser. Returns nil when the config is valid or the
 // kind has no registered validator.
@@ -118,6 +133,8 @@ func ValidateConnectionConfig(kind string, cfg map[string]any) error {
 		_, err = gatewaykit.ParseConfig(cfg)
 	case apigatewaykit.Kind:
 		_, err = apigatewaykit.ParseConfig(cfg)
+	case grpcgatewaykit.Kind:
+		_, err = grpcgatewaykit.ParseConfig(cfg)
 	default:
 		return nil
 	}
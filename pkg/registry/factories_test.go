@@ -65,6 +65,18 @@ func TestValidateConnectionConfig(t *testing.T) {
 			cfg:     map[string]any{"base_url": "http://api.example.com"},
 			wantErr: false,
 		},
+		{
+			name:    "grpc gateway missing descriptor source",
+			kind:    "grpc",
+			cfg:     map[string]any{"target": "orders.example.com:443"},
+			wantErr: true,
+		},
+		{
+			name:    "grpc gateway valid",
+			kind:    "grpc",
+			cfg:     map[string]any{"target": "orders.example.com:443", "reflection": true},
+			wantErr: false,
+		},
 		{
 			name:    "unknown kind passes",
 			kind:    "custom",
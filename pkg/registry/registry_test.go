@@ -475,6 +475,29 @@ func TestAPIGatewayAggregateFactory_LoadsValidInstance(t *testing.T) {
 	_ = tk.Close()
 }
 
+func TestGRPCGatewayAggregateFactory_UnreachableReflectionRegisters(t *testing.T) {
+	tk, err := GRPCGatewayAggregateFactory(regTestTest, map[string]map[string]any{
+		"broken": {}, // missing target, skipped
+		"orders": {
+			"target":          "127.0.0.1:1",
+			"plaintext":       true,
+			"reflection":      true,
+			"connect_timeout": "250ms",
+		},
+	})
+	if err != nil {
+		t.Fatalf("GRPCGatewayAggregateFactory: %v", err)
+	}
+	defer func() { _ = tk.Close() }()
+	if tk.Kind() != "grpc" {
+		t.Errorf("Kind: got %q, want %q", tk.Kind(), "grpc")
+	}
+	cm, ok := tk.(interface{ HasConnection(string) bool })
+	if !ok || !cm.HasConnection("orders") || cm.HasConnection("broken") {
+		t.Error("expected the unreachable reflection connection registered and the invalid one skipped")
+	}
+}
+
 func TestGatewayAggregateFactory_UnreachableInstanceAbsorbed(t *testing.T) {
 	// Unreachable endpoint must not fail the factory — the toolkit still
 	// constructs and the platform startup continues. The failed initial
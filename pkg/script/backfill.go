@@ -0,0 +1,239 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Backfill bounds.
+const (
+	// maxBackfillFires bounds the fires one backfill replays. A daily schedule
+	// reaches it after almost three years; an hourly one after six weeks, which
+	// is about where replaying stops being a repair and becomes a migration
+	// somebody should plan.
+	maxBackfillFires = 1000
+
+	// maxBackfillConcurrency bounds how many of a backfill's runs are open at
+	// once. Every one of them queries the warehouse the live schedule queries,
+	// so a replay must not be able to crowd out the work it is repairing.
+	maxBackfillConcurrency = 10
+
+	// defaultBackfillConcurrency is the bound when a request names none.
+	defaultBackfillConcurrency = 2
+)
+
+// backfillRunning is the status of a backfill that may still start fires.
+const backfillRunning = "running"
+
+// Fire outcomes that are not a run's status: a fire not yet materialized, and
+// one passed over because it had already succeeded.
+const (
+	fireWaiting = "waiting"
+	fireSkipped = "skipped"
+)
+
+// Backfill is a replay of a schedule over a range of past dates: every fire the
+// cron expression had between From and To, materialized as runs a few at a
+// time.
+//
+// The fires are enumerated once, when the backfill is requested, and stored.
+// The schedule's expression, zone and parameters are copied the same way, so a
+// backfill means what the schedule said when somebody asked for it, and editing
+// the schedule while one is in progress changes only the live cadence. Each
+// run executes the script's latest saved version at the moment it is
+// materialized — replaying with the fix is usually the point.
+type Backfill struct {
+	ID         string `json:"id"`
+	ScriptID   string `json:"script_id"`
+	ScheduleID string `json:"schedule_id"`
+
+	// From and To are the first and last dates replayed, inclusive, in the
+	// schedule's timezone.
+	From     string `json:"from" example:"2026-01-01"`
+	To       string `json:"to" example:"2026-01-31"`
+	CronSpec string `json:"cron_spec"`
+	Timezone string `json:"timezone"`
+	// Params are the schedule's bindings with their tokens unexpanded, each
+	// fire expanding ${fire_date} to its own date.
+	Params map[string]any `json:"params,omitempty"`
+
+	// Fires are the fires to replay, in order. Skipped are the fires in the
+	// range that already had a succeeded run and were left alone, which is
+	// what a backfill does unless Rerun is set.
+	Fires   []time.Time `json:"fires"`
+	Skipped []time.Time `json:"skipped,omitempty"`
+	Rerun   bool        `json:"rerun"`
+
+	// Concurrency is how many of this backfill's runs may be open at once.
+	Concurrency int `json:"concurrency"`
+
+	// Status is running until every fire has finished (completed) or somebody
+	// stopped it (cancelled). Stopping starts no further fires; runs already
+	// queued still run.
+	Status      string `json:"status" example:"running"`
+	RequestedBy string `json:"requested_by,omitempty"`
+	CancelledBy string `json:"cancelled_by,omitempty"`
+	// Error is why the platform stopped the backfill itself, when it did: a
+	// fire it could not materialize would fail the same way for every fire
+	// after it.
+	Error string `json:"error,omitempty"`
+
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// BackfillFire is the outcome of one fire of a backfill.
+type BackfillFire struct {
+	At time.Time `json:"fire_time"`
+	// Date is At in the schedule's timezone, the value ${fire_date} expanded
+	// to.
+	Date string `json:"fire_date,omitempty" example:"2026-01-05"`
+	// Status is the run's status, "waiting" for a fire not yet materialized,
+	// or "skipped" for one that had already succeeded.
+	Status      string `json:"status" example:"succeeded"`
+	RunID       string `json:"run_id,omitempty"`
+	Error       string `json:"error,omitempty"`
+	FailureKind string `json:"failure_kind,omitempty"`
+}
+
+// PlanBackfill enumerates the fires of this schedule between two dates, in the
+// schedule's timezone, and returns the backfill that replays them.
+//
+// Only fires that have already come due are replayed; a backfill is a repair of
+// the past, and the live schedule owns everything after now. concurrency zero
+// takes the default.
+func (s *Schedule) PlanBackfill(from, to string, concurrency int, rerun bool, actor string, now time.Time) (*Backfill, error) {
+	if s.Event() {
+		return nil, errors.New("this schedule fires on a trigger, not a clock, so it has no past fires to replay")
+	}
+	if concurrency == 0 {
+		concurrency = defaultBackfillConcurrency
+	}
+	if concurrency < 1 || concurrency > maxBackfillConcurrency {
+		return nil, fmt.Errorf("concurrency must be between 1 and %d", maxBackfillConcurrency)
+	}
+	c, err := ParseCron(s.CronSpec, s.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	start, err := time.ParseInLocation(DateLayout, strings.TrimSpace(from), c.Location())
+	if err != nil {
+		return nil, fmt.Errorf("from must be a date in %s form", DateLayout)
+	}
+	last, err := time.ParseInLocation(DateLayout, strings.TrimSpace(to), c.Location())
+	if err != nil {
+		return nil, fmt.Errorf("to must be a date in %s form", DateLayout)
+	}
+	if last.Before(start) {
+		return nil, errors.New("to is before from")
+	}
+	fires, err := firesBetween(c, start, last.AddDate(0, 0, 1), now)
+	if err != nil {
+		return nil, err
+	}
+	return &Backfill{
+		ScriptID: s.ScriptID, ScheduleID: s.ID,
+		From: start.Format(DateLayout), To: last.Format(DateLayout),
+		CronSpec: s.CronSpec, Timezone: c.Location().String(), Params: s.Params,
+		Fires: fires, Rerun: rerun, Concurrency: concurrency,
+		Status: backfillRunning, RequestedBy: actor,
+	}, nil
+}
+
+// firesBetween returns the fires in [start, end) that are not after now.
+func firesBetween(c Cron, start, end, now time.Time) ([]time.Time, error) {
+	var fires []time.Time
+	// Next is strictly after its argument, and a fire falls on a whole second,
+	// so one second before the range starts finds a fire at its first instant.
+	for t := c.Next(start.Add(-time.Second)); !t.IsZero() && t.Before(end) && !t.After(now); t = c.Next(t) {
+		if len(fires) == maxBackfillFires {
+			return nil, fmt.Errorf("the range holds more than %d fires of this schedule; split it into smaller backfills", maxBackfillFires)
+		}
+		fires = append(fires, t.UTC())
+	}
+	if len(fires) == 0 {
+		return nil, errors.New("the schedule had no fires in that range that have already come due")
+	}
+	return fires, nil
+}
+
+// Open reports whether the backfill may still start fires.
+func (b *Backfill) Open() bool { return b.Status == backfillRunning }
+
+// Location returns the zone the backfill's fire dates are read in.
+func (b *Backfill) Location() (*time.Location, error) { return loadTimezone(b.Timezone) }
+
+// Waiting returns the fires not yet materialized, in order, given the runs the
+// backfill has produced so far.
+func (b *Backfill) Waiting(runs []BackfillFire) []time.Time {
+	started := make(map[int64]bool, len(runs))
+	for _, r := range runs {
+		started[r.At.Unix()] = true
+	}
+	var out []time.Time
+	for _, f := range b.Fires {
+		if !started[f.Unix()] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Outcomes reports every fire in the range, in order: the run each replayed
+// fire produced, the fires still waiting, and the ones skipped.
+func (b *Backfill) Outcomes(runs []BackfillFire) []BackfillFire {
+	loc, err := b.Location()
+	if err != nil {
+		loc = time.UTC
+	}
+	out := make([]BackfillFire, 0, len(b.Fires)+len(b.Skipped))
+	out = append(out, runs...)
+	for _, f := range b.Waiting(runs) {
+		out = append(out, BackfillFire{At: f, Status: fireWaiting})
+	}
+	for _, f := range b.Skipped {
+		out = append(out, BackfillFire{At: f, Status: fireSkipped})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	for i := range out {
+		out[i].Date = out[i].At.In(loc).Format(DateLayout)
+	}
+	return out
+}
+
+// BackfillStore persists backfills and reads back the runs they produced. The
+// runs themselves are materialized through ScheduleStore.MaterializeRun, with
+// Run.BackfillID set.
+type BackfillStore interface {
+	// CreateBackfill inserts a backfill, assigning ID. Unless b.Rerun is set,
+	// fires that already have a succeeded run of the schedule move from Fires
+	// to Skipped first. A schedule has at most one running backfill, and a
+	// second is refused.
+	CreateBackfill(ctx context.Context, b *Backfill) error
+
+	// GetBackfill returns one backfill, or ErrBackfillNotFound.
+	GetBackfill(ctx context.Context, id string) (*Backfill, error)
+
+	// ListBackfills returns a script's backfills, newest first.
+	ListBackfills(ctx context.Context, scriptID string, limit int) ([]Backfill, error)
+
+	// OpenBackfills returns the running backfills, oldest first.
+	OpenBackfills(ctx context.Context, limit int) ([]Backfill, error)
+
+	// BackfillRuns returns the outcome of every fire the backfill has
+	// materialized, without the dates Outcomes fills in.
+	BackfillRuns(ctx context.Context, id string) ([]BackfillFire, error)
+
+	// FinishBackfill ends a running backfill: completed when actor is empty,
+	// otherwise cancelled by actor, with reason recorded when the platform
+	// stopped it. It reports false when the backfill had already finished.
+	FinishBackfill(ctx context.Context, id, actor, reason string) (bool, error)
+}
+
+// ErrBackfillNotFound reports a lookup for a backfill that does not exist.
+var ErrBackfillNotFound = errors.New("script backfill not found")
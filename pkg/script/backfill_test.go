@@ -0,0 +1,81 @@
+package script
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanBackfill_EnumeratesFiresInTheScheduleZone(t *testing.T) {
+	la := losAngeles(t)
+	sched := &Schedule{ID: "sch-1", ScriptID: "s1", CronSpec: "0 7 * * *", Timezone: "America/Los_Angeles",
+		Params: map[string]any{"day": FireDateToken}}
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, la)
+
+	b, err := sched.PlanBackfill("2026-03-07", "2026-03-09", 0, false, "jane@example.com", now)
+	require.NoError(t, err)
+	require.Len(t, b.Fires, 3)
+	// 03-08 is the spring-forward date: the fire stays at 07:00 local.
+	for i, day := range []int{7, 8, 9} {
+		assert.True(t, b.Fires[i].Equal(time.Date(2026, 3, day, 7, 0, 0, 0, la)), b.Fires[i])
+	}
+	assert.Equal(t, defaultBackfillConcurrency, b.Concurrency)
+	assert.Equal(t, "s1", b.ScriptID)
+	assert.Equal(t, "sch-1", b.ScheduleID)
+	assert.True(t, b.Open())
+}
+
+func TestPlanBackfill_StopsAtNow(t *testing.T) {
+	sched := &Schedule{CronSpec: "0 7 * * *"}
+	now := time.Date(2026, 3, 10, 6, 0, 0, 0, time.UTC)
+
+	b, err := sched.PlanBackfill("2026-03-08", "2026-03-31", 1, false, "", now)
+	require.NoError(t, err)
+	assert.Len(t, b.Fires, 2, "the 10th has not come due; the live schedule owns it")
+}
+
+func TestPlanBackfill_Refusals(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	daily := &Schedule{CronSpec: "0 7 * * *"}
+	tests := []struct {
+		name        string
+		sched       *Schedule
+		from, to    string
+		concurrency int
+		wantErr     string
+	}{
+		{"an event schedule", &Schedule{Trigger: &Trigger{}}, "2026-03-01", "2026-03-02", 0, "fires on a trigger"},
+		{"a malformed date", daily, "March 1", "2026-03-02", 0, "from must be a date"},
+		{"a reversed range", daily, "2026-03-05", "2026-03-01", 0, "to is before from"},
+		{"concurrency over the bound", daily, "2026-03-01", "2026-03-02", maxBackfillConcurrency + 1, "concurrency must be between"},
+		{"a range in the future", daily, "2026-04-01", "2026-04-02", 0, "no fires in that range"},
+		{"a range too long", &Schedule{CronSpec: "0 * * * *"}, "2025-01-01", "2026-03-01", 0, "more than 1000 fires"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.sched.PlanBackfill(tt.from, tt.to, tt.concurrency, false, "", now)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestBackfillOutcomes_MergesRunsWaitingAndSkipped(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 7, 0, 0, 0, time.UTC) }
+	b := &Backfill{Timezone: "UTC", Fires: []time.Time{day(2), day(3), day(4)}, Skipped: []time.Time{day(1)}}
+	runs := []BackfillFire{{At: day(3), Status: RunStatusFailed, RunID: "r3", Error: "boom"}}
+
+	assert.Equal(t, []time.Time{day(2), day(4)}, b.Waiting(runs))
+
+	got := b.Outcomes(runs)
+	require.Len(t, got, 4)
+	statuses := make([]string, len(got))
+	for i, f := range got {
+		statuses[i] = f.Status
+	}
+	assert.Equal(t, []string{fireSkipped, fireWaiting, RunStatusFailed, fireWaiting}, statuses)
+	assert.Equal(t, "2026-03-03", got[2].Date)
+	assert.Equal(t, "r3", got[2].RunID)
+}
@@ -39,6 +39,15 @@ type Contract struct {
 	// Version is the version a run executes: the latest saved one.
 	Version int `json:"version" example:"3"`
 
+	// Library marks a script other scripts load rather than one that runs.
+	// Loads is the libraries this one loads at the versions it pins, and
+	// LoadedBy, on a library, every script whose live source loads it, with
+	// the version of this library each pins: the scripts a change would reach
+	// once their pins move to it.
+	Library  bool         `json:"library,omitempty" example:"false"`
+	Loads    []Dependency `json:"loads,omitempty"`
+	LoadedBy []Dependency `json:"loaded_by,omitempty"`
+
 	// Refusal states why a run requested now would be refused, and is empty
 	// when one would be admitted.
 	Refusal string `json:"refusal,omitempty" example:"the script is disabled"`
@@ -78,8 +87,9 @@ type ContractRun struct {
 const (
 	// OutputKindAsset is a portal asset the platform versions and serves.
 	OutputKindAsset = "portal_asset"
-	// OutputKindObject is an object delivered to a configured bucket
-	// destination, which the platform wrote and does not hold.
+	// OutputKindObject is a file delivered to a configured destination — an
+	// object in a bucket, or a file over SFTP, on a volume, or in a mailbox —
+	// which the platform wrote and does not hold.
 	OutputKindObject = "object"
 )
 
@@ -105,7 +115,8 @@ type ContractOutput struct {
 	AssetID      string `json:"asset_id,omitempty" example:"asset_a1b2c3d4"`
 	AssetVersion int    `json:"asset_version,omitempty" example:"7"`
 
-	// Bucket and Key locate an OutputKindObject output.
+	// Bucket and Key locate an OutputKindObject output. Bucket is empty for a
+	// destination that is not a bucket, where Key is the path beneath it.
 	Bucket string `json:"bucket,omitempty" example:"acme-exports"`
 	Key    string `json:"key,omitempty" example:"weekly/2026/08/sales.csv"`
 }
@@ -135,7 +146,13 @@ func (c Contract) Text() string {
 	if names := ParamSummary(c.Params); names != "" {
 		parts = append(parts, "Parameters: "+names)
 	}
+	if len(c.Loads) > 0 {
+		parts = append(parts, "Loads: "+dependencyList(c.Loads))
+	}
 	parts = append(parts, c.runLine())
+	if c.Library {
+		parts = append(parts, c.loadedByLine())
+	}
 	if c.Schedule != nil {
 		parts = append(parts, fmt.Sprintf("Schedule: %s (%s)%s",
 			c.Schedule.CronSpec, c.Schedule.Timezone, c.Schedule.stateSuffix()))
@@ -147,12 +164,29 @@ func (c Contract) Text() string {
 // runLine states in one line whether anything will execute this script, naming
 // the refusal when there is one so a reader is never left to infer it.
 func (c Contract) runLine() string {
+	if c.Library {
+		return fmt.Sprintf("Library: version %d is the latest; a script loads it with load(%q, ...) and it never runs on its own.",
+			c.Version, Dependency{Name: c.Name, Version: c.Version}.Module())
+	}
 	if c.Refusal != "" {
 		return fmt.Sprintf("Runs: a run requested now would be refused: %s.", c.Refusal)
 	}
 	return fmt.Sprintf("Runs: version %d, the latest saved version; run_script executes it and a schedule fires it.", c.Version)
 }
 
+// loadedByLine names the scripts that load a library, each with the version
+// it pins, which is the answer to "who does changing this reach".
+func (c Contract) loadedByLine() string {
+	if len(c.LoadedBy) == 0 {
+		return "Loaded by: no script."
+	}
+	users := make([]string, 0, len(c.LoadedBy))
+	for _, d := range c.LoadedBy {
+		users = append(users, fmt.Sprintf("%s (pins v%d)", d.Name, d.Version))
+	}
+	return "Loaded by: " + strings.Join(users, ", ") + "."
+}
+
 // stateSuffix reports a disabled cadence or an expression with nothing left to
 // fire, either of which is otherwise indistinguishable from a schedule that
 // simply has not fired yet.
@@ -199,6 +233,9 @@ func (o ContractOutput) location() string {
 	case OutputKindAsset:
 		return fmt.Sprintf("portal asset %s v%d", o.AssetID, o.AssetVersion)
 	case OutputKindObject:
+		if o.Bucket == "" {
+			return fmt.Sprintf("file %s delivered to %s", o.Key, o.Destination)
+		}
 		return fmt.Sprintf("object %s/%s delivered to %s", o.Bucket, o.Key, o.Destination)
 	default:
 		return "destination " + o.Destination
@@ -235,7 +272,8 @@ func (c Contract) OwnedBy(email string) bool {
 
 // BuildContract renders the contract for one script from the records that
 // define it: the live row, the schedule (nil when it has none), and the last
-// successful run (nil when it has never had one).
+// successful run (nil when it has never had one). A library's LoadedBy is read
+// from the other scripts' rows, which the caller composing the document sets.
 //
 // It is a pure function over records the caller has already read, so every
 // surface that resolves a script reference produces the identical document and
@@ -256,6 +294,8 @@ func BuildContract(sc *Script, sched *Schedule, lastRun *Run) Contract {
 		Enabled:     sc.Enabled,
 		Params:      sc.Params,
 		Version:     sc.Version,
+		Library:     sc.Library,
+		Loads:       sc.Loads,
 		Refusal:     refusalText(RefuseRun(sc)),
 	}
 	if sched != nil {
@@ -324,7 +364,7 @@ func contractOutput(o RunOutput) ContractOutput {
 	switch {
 	case o.AssetID != "":
 		out.Kind = OutputKindAsset
-	case o.Bucket != "":
+	case o.Key != "":
 		out.Kind = OutputKindObject
 	}
 	return out
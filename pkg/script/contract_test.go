@@ -92,22 +92,26 @@ func TestContractNamesEachOutputShape(t *testing.T) {
 		Outputs: []RunOutput{
 			{Name: "sales", AssetID: "asset_7", AssetVersion: 4, Format: "csv"},
 			{Name: "sales", Destination: "acme-drop", Bucket: "acme-exports", Key: "2026/08/sales.csv", Format: "csv"},
+			{Name: "sales", Destination: "partner-drop", Key: "weekly/sales.csv", Format: "csv"},
 		},
 	}
 
 	c := BuildContract(liveScript(), nil, run)
 
 	require.NotNil(t, c.LastRun)
-	require.Len(t, c.LastRun.Outputs, 2)
+	require.Len(t, c.LastRun.Outputs, 3)
 	assert.Equal(t, OutputKindAsset, c.LastRun.Outputs[0].Kind)
 	assert.Equal(t, DestinationPortal, c.LastRun.Outputs[0].Destination,
 		"an output with no recorded destination is a portal write")
 	assert.Equal(t, OutputKindObject, c.LastRun.Outputs[1].Kind)
 	assert.Equal(t, "acme-drop", c.LastRun.Outputs[1].Destination)
+	assert.Equal(t, OutputKindObject, c.LastRun.Outputs[2].Kind,
+		"a file on a destination that is not a bucket is still one the platform does not hold")
 
 	text := c.Text()
 	assert.Contains(t, text, "portal asset asset_7 v4")
 	assert.Contains(t, text, "object acme-exports/2026/08/sales.csv delivered to acme-drop")
+	assert.Contains(t, text, "file weekly/sales.csv delivered to partner-drop")
 }
 
 // TestContractTextReportsNeverHavingRun proves the absence of a run is stated
@@ -227,3 +231,23 @@ func TestEffectiveSearchLimitIsClamped(t *testing.T) {
 	assert.Equal(t, DefaultSearchLimit, SearchQuery{Limit: maxSearchLimit + 1}.EffectiveLimit())
 	assert.Equal(t, 5, SearchQuery{Limit: 5}.EffectiveLimit())
 }
+
+// TestContractReportsTheLibraryGraphFromBothEnds proves a script's contract
+// names what it loads, and a library's names who loads it at which pin, which
+// is what an author changing a library reads to know who a change reaches.
+func TestContractReportsTheLibraryGraphFromBothEnds(t *testing.T) {
+	sc := liveScript()
+	sc.Loads = []Dependency{{ScriptID: "script_lib", Name: "fiscal-calendar", Version: 2}}
+	assert.Contains(t, BuildContract(sc, nil, nil).Text(), "Loads: fiscal-calendar@2")
+
+	lib := liveScript()
+	lib.Name, lib.DisplayName, lib.Library, lib.Version = "fiscal-calendar", "", true, 4
+	c := BuildContract(lib, nil, nil)
+	assert.True(t, c.Library)
+	assert.Contains(t, c.Refusal, "library")
+	assert.Contains(t, c.Text(), `load("fiscal-calendar@4", ...)`)
+	assert.Contains(t, c.Text(), "Loaded by: no script.")
+
+	c.LoadedBy = []Dependency{{ScriptID: "script_1", Name: "daily-sales", Version: 2}}
+	assert.Contains(t, c.Text(), "Loaded by: daily-sales (pins v2).")
+}
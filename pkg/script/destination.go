@@ -3,6 +3,8 @@ package script
 import (
 	"errors"
 	"fmt"
+	"net/mail"
+	"path"
 	"slices"
 	"strings"
 	"unicode"
@@ -24,12 +26,30 @@ const (
 	DestinationKindPortal = "portal"
 
 	// DestinationKindS3 writes an object to a bucket over a named platform S3
-	// connection. It is the only way an output leaves the platform.
+	// connection.
 	DestinationKindS3 = "s3"
+
+	// DestinationKindSFTP writes a file beneath a directory on an SFTP server.
+	DestinationKindSFTP = "sftp"
+
+	// DestinationKindFilesystem writes a file beneath a directory on a volume
+	// mounted into the worker.
+	DestinationKindFilesystem = "filesystem"
+
+	// DestinationKindEmail mails the output as an attachment to a fixed set of
+	// recipients, over the deployment's SMTP settings.
+	DestinationKindEmail = "email"
 )
 
 // DestinationKinds is the full set of destination kinds.
-var DestinationKinds = []string{DestinationKindPortal, DestinationKindS3}
+var DestinationKinds = []string{
+	DestinationKindPortal, DestinationKindS3, DestinationKindSFTP,
+	DestinationKindFilesystem, DestinationKindEmail,
+}
+
+// destinationCapabilityPrefix prefixes the persona capability that admits a
+// run to a destination no platform tool writes to. See Capability.
+const destinationCapabilityPrefix = "destination:"
 
 // Key limits. maxObjectKeyLength is S3's own limit on a full key; a
 // destination's prefix is bounded well inside it so the key a script writes
@@ -55,8 +75,8 @@ type Destination struct {
 	// configured set.
 	Name string `json:"name" yaml:"name" example:"acme-drop"`
 
-	// Kind is one of DestinationKinds. Configuration declares only bucket
-	// destinations, so it defaults to s3 there.
+	// Kind is one of DestinationKinds. Configuration defaults it to s3, the
+	// kind destinations were declared as before there were others.
 	Kind string `json:"kind" yaml:"kind" example:"s3"`
 
 	// Connection is the named platform S3 connection the object is written
@@ -72,6 +92,29 @@ type Destination struct {
 	// the portal and optional for a bucket. It is the destination's boundary:
 	// the script chooses a key beneath it and can never write outside it.
 	Prefix string `json:"prefix,omitempty" yaml:"prefix" example:"weekly"`
+
+	// Host, User, and HostKey address an sftp destination: the server as host
+	// or host:port, the account, and the server's public key in
+	// authorized_keys form. The host key is required rather than learned on
+	// first contact, because a key accepted from whoever answered is not a
+	// check at all.
+	Host    string `json:"host,omitempty" yaml:"host" example:"sftp.acme.example:22"`
+	User    string `json:"user,omitempty" yaml:"user" example:"drop"`
+	HostKey string `json:"-" yaml:"host_key"`
+
+	// Password and PrivateKeyFile authenticate an sftp destination; exactly
+	// one is set. Neither is ever rendered.
+	Password       string `json:"-" yaml:"password"`
+	PrivateKeyFile string `json:"-" yaml:"private_key_file"`
+
+	// Path is the absolute directory an sftp or filesystem destination writes
+	// beneath. Like Prefix, which sits under it, it is a boundary the key a
+	// script chooses cannot climb out of.
+	Path string `json:"path,omitempty" yaml:"path" example:"/incoming"`
+
+	// To is an email destination's recipients. They are configuration, not
+	// arguments: a script chooses what is attached, never who receives it.
+	To []string `json:"to,omitempty" yaml:"to"`
 }
 
 // PortalDestination returns the canonical portal destination.
@@ -85,10 +128,34 @@ func (d Destination) IsPortal() bool { return d.Kind == DestinationKindPortal }
 // Label renders a destination for an error message or a log line: the name a
 // script writes, and the address it resolves to.
 func (d Destination) Label() string {
-	if d.IsPortal() {
+	switch d.Kind {
+	case DestinationKindPortal:
 		return d.Name
+	case DestinationKindSFTP:
+		return fmt.Sprintf("%s (sftp %s@%s %s)", d.Name, d.User, d.Host, path.Join(d.Path, d.Prefix))
+	case DestinationKindFilesystem:
+		return fmt.Sprintf("%s (filesystem %s)", d.Name, path.Join(d.Path, d.Prefix))
+	case DestinationKindEmail:
+		return fmt.Sprintf("%s (email to %s)", d.Name, strings.Join(d.To, ", "))
+	default:
+		return fmt.Sprintf("%s (%s %s %s/%s)", d.Name, d.Kind, d.Connection, d.Bucket, d.Prefix)
+	}
+}
+
+// Capability is the persona capability a run must hold to write to a
+// destination that no platform tool writes to, checked with the destination's
+// name as the connection. An s3 write is authorized as the s3_put_object call
+// it is; an sftp, filesystem, or email write is made by the platform itself, so
+// the same persona check is applied to this name instead, and a persona
+// reaches such a destination only when its tool rules admit the capability and
+// its connection rules admit the destination. Empty for the portal and s3.
+func (d Destination) Capability() string {
+	switch d.Kind {
+	case DestinationKindSFTP, DestinationKindFilesystem, DestinationKindEmail:
+		return destinationCapabilityPrefix + d.Kind
+	default:
+		return ""
 	}
-	return fmt.Sprintf("%s (%s %s %s/%s)", d.Name, d.Kind, d.Connection, d.Bucket, d.Prefix)
 }
 
 // Normalized returns the destination with its fields trimmed and its prefix in
@@ -100,6 +167,20 @@ func (d Destination) Normalized() Destination {
 	d.Connection = strings.TrimSpace(d.Connection)
 	d.Bucket = strings.TrimSpace(d.Bucket)
 	d.Prefix = strings.Trim(strings.TrimSpace(d.Prefix), "/")
+	d.Host = strings.TrimSpace(d.Host)
+	d.User = strings.TrimSpace(d.User)
+	d.HostKey = strings.TrimSpace(d.HostKey)
+	d.PrivateKeyFile = strings.TrimSpace(d.PrivateKeyFile)
+	if p := strings.TrimSpace(d.Path); p != "" {
+		d.Path = path.Clean(p)
+	}
+	var to []string
+	for _, addr := range d.To {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+	d.To = to
 	return d
 }
 
@@ -112,10 +193,23 @@ func (d Destination) Validate() error {
 		return fmt.Errorf("destination %q has unknown kind %q: the platform implements %v",
 			d.Name, d.Kind, DestinationKinds)
 	}
-	if d.IsPortal() {
+	switch d.Kind {
+	case DestinationKindPortal:
 		return d.validatePortal()
+	case DestinationKindS3:
+		return d.validateBucket()
+	}
+	if err := d.validateExternal(); err != nil {
+		return err
+	}
+	switch d.Kind {
+	case DestinationKindSFTP:
+		return d.validateSFTP()
+	case DestinationKindFilesystem:
+		return d.validateFilesystem()
+	default:
+		return d.validateEmail()
 	}
-	return d.validateBucket()
 }
 
 // validatePortal refuses a portal destination carrying an address. The
@@ -148,6 +242,18 @@ func (d Destination) validateBucket() error {
 	if d.Bucket == "" {
 		return fmt.Errorf("destination %q must name a bucket; a script never supplies one", d.Name)
 	}
+	if err := d.refuseFields(d.Host, d.User, d.HostKey, d.Password, d.PrivateKeyFile, d.Path); err != nil {
+		return err
+	}
+	if len(d.To) > 0 {
+		return fmt.Errorf("destination %q is an s3 destination and takes no recipients", d.Name)
+	}
+	return d.validatePrefix()
+}
+
+// validatePrefix checks the optional key prefix every external kind but email
+// writes under.
+func (d Destination) validatePrefix() error {
 	if len(d.Prefix) > maxPrefixLength {
 		return fmt.Errorf("destination %q has a %d-character prefix, over the %d-character limit",
 			d.Name, len(d.Prefix), maxPrefixLength)
@@ -160,9 +266,101 @@ func (d Destination) validateBucket() error {
 	return nil
 }
 
+// validateExternal applies what the sftp, filesystem, and email kinds share:
+// the reserved name, and no bucket address, since the platform writes these
+// itself rather than over a platform connection.
+func (d Destination) validateExternal() error {
+	if d.Name == DestinationPortal {
+		return fmt.Errorf("the destination name %q is reserved for the platform's own asset store; give the %s destination its own name", DestinationPortal, d.Kind)
+	}
+	if d.Connection != "" || d.Bucket != "" {
+		return fmt.Errorf("destination %q is of kind %s and takes no connection or bucket: the platform writes it directly, and a persona reaches it by its name", d.Name, d.Kind)
+	}
+	return nil
+}
+
+// refuseFields refuses a destination that sets a field its kind does not use,
+// which is a declaration that means something other than what it says.
+func (d Destination) refuseFields(values ...string) error {
+	for _, v := range values {
+		if v != "" {
+			return fmt.Errorf("destination %q sets a field the %s kind does not use", d.Name, d.Kind)
+		}
+	}
+	return nil
+}
+
+// validateSFTP refuses an sftp destination that does not name a server, an
+// account, one credential, the server's key, and an absolute directory.
+func (d Destination) validateSFTP() error {
+	switch {
+	case d.Host == "":
+		return fmt.Errorf("destination %q must name the sftp host", d.Name)
+	case d.User == "":
+		return fmt.Errorf("destination %q must name the sftp user", d.Name)
+	case (d.Password == "") == (d.PrivateKeyFile == ""):
+		return fmt.Errorf("destination %q must set exactly one of password and private_key_file", d.Name)
+	case d.HostKey == "":
+		return fmt.Errorf("destination %q must set host_key, the server's public key; a key accepted from whoever answers checks nothing", d.Name)
+	case len(d.To) > 0:
+		return fmt.Errorf("destination %q is an sftp destination and takes no recipients", d.Name)
+	}
+	if err := d.validatePath(); err != nil {
+		return err
+	}
+	return d.validatePrefix()
+}
+
+// validateFilesystem refuses a filesystem destination that does not name an
+// absolute directory, or that carries another kind's address.
+func (d Destination) validateFilesystem() error {
+	if err := d.refuseFields(d.Host, d.User, d.HostKey, d.Password, d.PrivateKeyFile); err != nil {
+		return err
+	}
+	if len(d.To) > 0 {
+		return fmt.Errorf("destination %q is a filesystem destination and takes no recipients", d.Name)
+	}
+	if err := d.validatePath(); err != nil {
+		return err
+	}
+	return d.validatePrefix()
+}
+
+// validatePath checks the directory an sftp or filesystem destination writes
+// beneath. The root itself is refused: a destination is a place set aside for
+// output, not the whole machine.
+func (d Destination) validatePath() error {
+	switch {
+	case d.Path == "":
+		return fmt.Errorf("destination %q must name the directory it writes beneath", d.Name)
+	case !path.IsAbs(d.Path):
+		return fmt.Errorf("destination %q has path %q; it must be absolute", d.Name, d.Path)
+	case d.Path == "/":
+		return fmt.Errorf("destination %q cannot write beneath /; name the directory set aside for it", d.Name)
+	}
+	return nil
+}
+
+// validateEmail refuses an email destination without well-formed recipients.
+// A prefix is refused with the rest: an attachment has a file name, not a path.
+func (d Destination) validateEmail() error {
+	if err := d.refuseFields(d.Host, d.User, d.HostKey, d.Password, d.PrivateKeyFile, d.Path, d.Prefix); err != nil {
+		return err
+	}
+	if len(d.To) == 0 {
+		return fmt.Errorf("destination %q must name at least one recipient", d.Name)
+	}
+	for _, addr := range d.To {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return fmt.Errorf("destination %q has an unusable recipient %q: %w", d.Name, addr, err)
+		}
+	}
+	return nil
+}
+
 // ValidateDeclaredDestinations checks the destination set a deployment
-// declares in configuration: each must be a complete bucket address, one name
-// is one place, and the built-in portal cannot be redeclared.
+// declares in configuration: each must be a complete address of its kind, one
+// name is one place, and the built-in portal cannot be redeclared.
 func ValidateDeclaredDestinations(destinations []Destination) error {
 	seen := make(map[string]bool, len(destinations))
 	for _, d := range destinations {
@@ -148,3 +148,128 @@ func TestDestination_ValidateRefusesWhatCannotBeWritten(t *testing.T) {
 		})
 	}
 }
+
+// TestDestination_TheOtherKindsNameTheirAddress covers the kinds the platform
+// writes itself: each validates as declared, labels where it goes, and names
+// the capability a persona must hold to reach it.
+func TestDestination_TheOtherKindsNameTheirAddress(t *testing.T) {
+	tests := map[string]struct {
+		destination script.Destination
+		label       string
+	}{
+		"sftp": {
+			script.Destination{
+				Name: "partner-drop", Kind: script.DestinationKindSFTP,
+				Host: "sftp.acme.example:22", User: "drop", Password: "secret",
+				HostKey: "ssh-ed25519 AAAA", Path: "/incoming", Prefix: "weekly",
+			},
+			"partner-drop (sftp drop@sftp.acme.example:22 /incoming/weekly)",
+		},
+		"filesystem": {
+			script.Destination{Name: "nfs", Kind: script.DestinationKindFilesystem, Path: "/mnt/exports"},
+			"nfs (filesystem /mnt/exports)",
+		},
+		"email": {
+			script.Destination{
+				Name: "finance", Kind: script.DestinationKindEmail,
+				To: []string{"finance@acme.example", "Ops <ops@acme.example>"},
+			},
+			"finance (email to finance@acme.example, Ops <ops@acme.example>)",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, tt.destination.Validate())
+			assert.Equal(t, tt.label, tt.destination.Label())
+			assert.Equal(t, "destination:"+tt.destination.Kind, tt.destination.Capability())
+		})
+	}
+	assert.Empty(t, script.PortalDestination().Capability())
+	assert.Empty(t, script.Destination{Kind: script.DestinationKindS3}.Capability(),
+		"an s3 write is authorized as the tool call it is")
+}
+
+// TestDestination_TheOtherKindsRefuseAnIncompleteAddress: the kinds the
+// platform writes itself carry the same obligation as a bucket, every part of
+// the address decided in configuration.
+func TestDestination_TheOtherKindsRefuseAnIncompleteAddress(t *testing.T) {
+	sftp := func(edit func(*script.Destination)) script.Destination {
+		d := script.Destination{
+			Name: "partner-drop", Kind: script.DestinationKindSFTP,
+			Host: "sftp.acme.example", User: "drop", Password: "secret",
+			HostKey: "ssh-ed25519 AAAA", Path: "/incoming",
+		}
+		edit(&d)
+		return d
+	}
+	tests := map[string]struct {
+		destination script.Destination
+		wantErr     string
+	}{
+		"sftp without a host key": {
+			sftp(func(d *script.Destination) { d.HostKey = "" }), "must set host_key",
+		},
+		"sftp with two credentials": {
+			sftp(func(d *script.Destination) { d.PrivateKeyFile = "/keys/id" }), "exactly one of",
+		},
+		"sftp with a relative path": {
+			sftp(func(d *script.Destination) { d.Path = "incoming" }), "must be absolute",
+		},
+		"sftp with a bucket": {
+			sftp(func(d *script.Destination) { d.Bucket = "exports" }), "takes no connection or bucket",
+		},
+		"filesystem at the root": {
+			script.Destination{Name: "all", Kind: script.DestinationKindFilesystem, Path: "/"},
+			"cannot write beneath /",
+		},
+		"filesystem with a climbing prefix": {
+			script.Destination{Name: "nfs", Kind: script.DestinationKindFilesystem, Path: "/mnt", Prefix: "../etc"},
+			"unusable prefix",
+		},
+		"filesystem with an sftp host": {
+			script.Destination{Name: "nfs", Kind: script.DestinationKindFilesystem, Path: "/mnt", Host: "h"},
+			"does not use",
+		},
+		"email wearing the portal name": {
+			script.Destination{Name: "portal", Kind: script.DestinationKindEmail, To: []string{"a@acme.example"}},
+			"reserved",
+		},
+		"email without recipients": {
+			script.Destination{Name: "finance", Kind: script.DestinationKindEmail}, "at least one recipient",
+		},
+		"email with a malformed recipient": {
+			script.Destination{Name: "finance", Kind: script.DestinationKindEmail, To: []string{"finance"}},
+			"unusable recipient",
+		},
+		"email with a prefix": {
+			script.Destination{Name: "finance", Kind: script.DestinationKindEmail, To: []string{"a@acme.example"}, Prefix: "x"},
+			"does not use",
+		},
+		"s3 with recipients": {
+			script.Destination{
+				Name: "drop", Kind: script.DestinationKindS3, Connection: "acme-s3", Bucket: "exports",
+				To: []string{"a@acme.example"},
+			},
+			"takes no recipients",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tt.destination.Normalized().Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+// TestDestination_CredentialsAreNeverRendered pins that a destination read back
+// by a client says where output goes and never how the platform logs in.
+func TestDestination_CredentialsAreNeverRendered(t *testing.T) {
+	data, err := json.Marshal(script.Destination{
+		Name: "partner-drop", Kind: script.DestinationKindSFTP, Host: "sftp.acme.example",
+		User: "drop", Password: "secret", PrivateKeyFile: "/keys/id", HostKey: "ssh-ed25519 AAAA",
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+	assert.NotContains(t, string(data), "/keys/id")
+}
@@ -18,9 +18,11 @@ type Edit struct {
 }
 
 // SnapshotChanged reports whether any versioned snapshot field (source, params,
-// display name, description, category, tags) differs between the two states.
+// display name, description, category, tags, loads) differs between the two
+// states.
 func SnapshotChanged(before, after *Script) bool {
 	return before.Source != after.Source ||
+		!slices.Equal(before.Loads, after.Loads) ||
 		before.DisplayName != after.DisplayName ||
 		before.Description != after.Description ||
 		before.Category != after.Category ||
@@ -35,6 +37,12 @@ func SnapshotChanged(before, after *Script) bool {
 // applied version when a versioned field changed, so the saved script and the
 // script a run executes are always the same code; a store without it degrades
 // to a plain unversioned update.
+//
+// The libraries the edit loads are checked in the same write: each must be a
+// library that has the version the source pins, or the edit is refused with
+// ErrDependency and nothing lands. A library is governed by this funnel like
+// any script, and its history is its version rows; what it adds is that other
+// scripts name those rows.
 func ApplyEdit(ctx context.Context, store Store, e Edit) error {
 	if versions, ok := store.(VersionStore); ok {
 		if err := versions.UpdateWithVersion(ctx, e.After, e.Author); err != nil {
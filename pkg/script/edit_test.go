@@ -104,6 +104,7 @@ func TestSnapshotChanged(t *testing.T) {
 		func(s *script.Script) { s.Description = "d" },
 		func(s *script.Script) { s.Params = nil },
 		func(s *script.Script) { s.Tags = []string{"t"} },
+		func(s *script.Script) { s.Loads = []script.Dependency{{Name: "fiscal", Version: 2}} },
 	} {
 		after := inService()
 		mutate(after)
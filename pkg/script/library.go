@@ -0,0 +1,54 @@
+package script
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDependency marks a save refused because a library relationship does not
+// hold: a load naming no library, or a version that library does not have, a
+// library name another library already has, or a library deleted while a
+// script still loads it. It is the author's to fix, so a surface reports it as
+// a refusal rather than as an internal failure.
+var ErrDependency = errors.New("script library dependency")
+
+// Dependency is one edge of the library graph: a load of one library at one
+// pinned version.
+//
+// A script's Loads name the libraries it loads, each at the version its
+// source pins. A library's contract reports the same edge from the other end
+// in LoadedBy, naming each script that loads it and the version of this
+// library that script pins. One type serves both ends because an edge is the
+// same fact read from either script.
+//
+// The pin is the whole of the compatibility model. A load names an immutable
+// version, so saving a new version of a library changes nothing any script
+// executes until that script's own source moves its pin, and a library's
+// LoadedBy says exactly which scripts a move would reach.
+type Dependency struct {
+	// ScriptID identifies the script at the other end of the edge. The store
+	// resolves it when the source is saved, so the graph follows a library by
+	// identity rather than by the name a load happens to spell.
+	ScriptID string `json:"script_id,omitempty" example:"script_a1b2c3d4"`
+	Name     string `json:"name" example:"fiscal-calendar"`
+	Version  int    `json:"version" example:"3"`
+}
+
+// Module renders the load() module string that names this dependency.
+func (d Dependency) Module() string {
+	return fmt.Sprintf("%s@%d", d.Name, d.Version)
+}
+
+// errLibraryRun is the run gate's answer for a library, which other scripts
+// load and nothing executes on its own.
+var errLibraryRun = errors.New("the script is a library: other scripts load it, and it never runs on its own")
+
+// dependencyList renders a dependency list as its module strings.
+func dependencyList(deps []Dependency) string {
+	mods := make([]string, 0, len(deps))
+	for _, d := range deps {
+		mods = append(mods, d.Module())
+	}
+	return strings.Join(mods, ", ")
+}
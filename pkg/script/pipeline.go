@@ -0,0 +1,562 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxPipelineSteps bounds the steps one pipeline may name. A process larger
+// than this is several pipelines, and the cap keeps one run's record readable.
+const maxPipelineSteps = 20
+
+// maxStepError bounds the failure a step run records. The whole failure lives
+// on the script run the step points at; the step carries the line that says
+// which one to read.
+const maxStepError = 500
+
+// StepStatusSkipped marks a pipeline step that never ran because a step it
+// depends on did not succeed. The other step statuses are the run statuses:
+// pending (waiting on its dependencies), running (its script run is on the
+// queue or executing), succeeded, and failed.
+const StepStatusSkipped = "skipped"
+
+// Pipeline errors.
+var (
+	// ErrPipelineNotFound reports a lookup for a pipeline that does not exist.
+	ErrPipelineNotFound = errors.New("pipeline not found")
+
+	// ErrPipelineRunNotFound reports a lookup for a pipeline run that does not
+	// exist.
+	ErrPipelineRunNotFound = errors.New("pipeline run not found")
+
+	// ErrPipelineRunMoved reports an update written against a pipeline run
+	// that another writer changed since it was read. Every replica advances
+	// pipelines, and this is how exactly one of them starts each step.
+	ErrPipelineRunMoved = errors.New("the pipeline run changed since it was read")
+)
+
+// stepTokenPattern matches a reference to an upstream step's published output,
+// ${steps.<step>.<output>}.
+var stepTokenPattern = regexp.MustCompile(`^\$\{steps\.([A-Za-z_][A-Za-z0-9_]*)\.([^.}]+)\}$`)
+
+// Pipeline is a set of managed scripts run as one process: each step runs one
+// script, after the steps it depends on have succeeded, with parameters that
+// may name what those steps published.
+//
+// A pipeline is not an authority. Each step executes as its own script's
+// principal, through the same queue and worker as any other run, so a pipeline
+// can do nothing its scripts could not already do one at a time. What it adds
+// is the ordering, and the handoff that replaces guessing how long the step
+// before will take.
+type Pipeline struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name" example:"daily-revenue"`
+	Description string         `json:"description,omitempty"`
+	OwnerEmail  string         `json:"owner_email"`
+	Steps       []PipelineStep `json:"steps"`
+
+	// CronSpec and Timezone are the pipeline's cadence. An empty CronSpec is a
+	// pipeline started only on request.
+	CronSpec string `json:"cron,omitempty" example:"0 6 * * *"`
+	Timezone string `json:"timezone,omitempty" example:"UTC"`
+	Enabled  bool   `json:"enabled"`
+	// NextRunAt is the next fire of the cadence, zero when there is none.
+	NextRunAt time.Time `json:"next_run_at,omitzero"`
+
+	CreatedBy string    `json:"created_by,omitempty"`
+	UpdatedBy string    `json:"updated_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PipelineStep is one script in a pipeline.
+//
+// A parameter value may carry ${fire_date}, as a schedule's may, and
+// ${steps.<step>.<output>}, which expands to where an upstream step's run
+// published that output: the portal asset id, or s3://bucket/key for a
+// delivered object. The referenced step must be one this step depends on,
+// directly or through another, so the output exists by the time it is read.
+type PipelineStep struct {
+	Name      string         `json:"name" example:"extract"`
+	ScriptID  string         `json:"script_id"`
+	DependsOn []string       `json:"depends_on,omitempty"`
+	Params    map[string]any `json:"params,omitempty"`
+}
+
+// Prepare validates a pipeline about to be saved and computes its first fire.
+// prev is the pipeline it replaces, or nil, and keeps its identity.
+func (p *Pipeline) Prepare(prev *Pipeline, now time.Time) error {
+	p.CronSpec, p.Timezone = strings.TrimSpace(p.CronSpec), strings.TrimSpace(p.Timezone)
+	if p.Timezone == "" {
+		p.Timezone = DefaultTimezone
+	}
+	if prev != nil {
+		p.ID, p.CreatedBy = prev.ID, prev.CreatedBy
+	}
+	if err := ValidateName(p.Name); err != nil {
+		return fmt.Errorf("pipeline %w", err)
+	}
+	if err := validateSteps(p.Steps); err != nil {
+		return err
+	}
+	p.NextRunAt = time.Time{}
+	if p.CronSpec == "" {
+		return nil
+	}
+	cronSpec, err := ParseCron(p.CronSpec, p.Timezone)
+	if err != nil {
+		return err
+	}
+	p.NextRunAt = cronSpec.Next(now)
+	return nil
+}
+
+// validateSteps checks that steps form a graph a run can walk: uniquely named,
+// each naming a script, depending only on steps that exist, without a cycle,
+// and referencing only outputs of steps that will have run first.
+func validateSteps(steps []PipelineStep) error {
+	if len(steps) == 0 || len(steps) > maxPipelineSteps {
+		return fmt.Errorf("a pipeline needs between 1 and %d steps", maxPipelineSteps)
+	}
+	names := make(map[string]bool, len(steps))
+	for _, s := range steps {
+		if !identPattern.MatchString(s.Name) {
+			return fmt.Errorf("step name %q must be a plain name of letters, digits, and underscores", s.Name)
+		}
+		if names[s.Name] {
+			return fmt.Errorf("step %q is named twice", s.Name)
+		}
+		names[s.Name] = true
+		if s.ScriptID == "" {
+			return fmt.Errorf("step %q needs the script it runs", s.Name)
+		}
+	}
+	for _, s := range steps {
+		seen := map[string]bool{}
+		for _, dep := range s.DependsOn {
+			switch {
+			case dep == s.Name:
+				return fmt.Errorf("step %q cannot depend on itself", s.Name)
+			case !names[dep]:
+				return fmt.Errorf("step %q depends on %q, which is not a step of this pipeline", s.Name, dep)
+			case seen[dep]:
+				return fmt.Errorf("step %q names %q twice in depends_on", s.Name, dep)
+			}
+			seen[dep] = true
+		}
+	}
+	order, err := stepOrder(steps)
+	if err != nil {
+		return err
+	}
+	ancestors := ancestorsOf(steps, order)
+	for _, s := range steps {
+		if err := checkStepTokens(s, ancestors[s.Name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stepOrder returns the steps' indexes in an order that puts every step after
+// the steps it depends on, or an error naming the steps caught in a cycle.
+func stepOrder(steps []PipelineStep) ([]int, error) {
+	index := make(map[string]int, len(steps))
+	for i, s := range steps {
+		index[s.Name] = i
+	}
+	waiting := make([]int, len(steps))
+	dependents := make([][]int, len(steps))
+	for i, s := range steps {
+		for _, dep := range s.DependsOn {
+			if j, ok := index[dep]; ok {
+				waiting[i]++
+				dependents[j] = append(dependents[j], i)
+			}
+		}
+	}
+	order := make([]int, 0, len(steps))
+	for i := range steps {
+		if waiting[i] == 0 {
+			order = append(order, i)
+		}
+	}
+	for k := 0; k < len(order); k++ {
+		for _, d := range dependents[order[k]] {
+			if waiting[d]--; waiting[d] == 0 {
+				order = append(order, d)
+			}
+		}
+	}
+	if len(order) < len(steps) {
+		cycle := []string{}
+		for i, s := range steps {
+			if waiting[i] > 0 {
+				cycle = append(cycle, s.Name)
+			}
+		}
+		return nil, fmt.Errorf("the steps %s depend on each other in a cycle; a pipeline must be able to start somewhere",
+			strings.Join(cycle, ", "))
+	}
+	return order, nil
+}
+
+// ancestorsOf maps each step to every step that runs before it.
+func ancestorsOf(steps []PipelineStep, order []int) map[string]map[string]bool {
+	out := make(map[string]map[string]bool, len(steps))
+	for _, i := range order {
+		set := map[string]bool{}
+		for _, dep := range steps[i].DependsOn {
+			set[dep] = true
+			for a := range out[dep] {
+				set[a] = true
+			}
+		}
+		out[steps[i].Name] = set
+	}
+	return out
+}
+
+// checkStepTokens refuses a parameter token the step cannot expand: one the
+// vocabulary does not define, or an output of a step that has not run by then.
+func checkStepTokens(s PipelineStep, ancestors map[string]bool) error {
+	for param, value := range s.Params {
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+		for _, token := range tokenPattern.FindAllString(str, -1) {
+			if token == FireDateToken {
+				continue
+			}
+			m := stepTokenPattern.FindStringSubmatch(token)
+			if m == nil {
+				return fmt.Errorf("step %q parameter %q: %s is not a token a pipeline defines (%w); use %s or ${steps.<step>.<output>}",
+					s.Name, param, token, ErrUnknownToken, FireDateToken)
+			}
+			if !ancestors[m[1]] {
+				return fmt.Errorf("step %q parameter %q reads step %q, which it does not depend on; add it to depends_on so the output exists first",
+					s.Name, param, m[1])
+			}
+		}
+	}
+	return nil
+}
+
+// NewRun starts a record of one run of the pipeline. The steps are copied
+// into the run, so a run walks the pipeline as it was when the run began and an
+// edit made meanwhile changes only the runs that start after it.
+func (p *Pipeline) NewRun(trigger, actor string, fire time.Time) *PipelineRun {
+	r := &PipelineRun{
+		PipelineID: p.ID, Trigger: trigger, Status: RunStatusRunning,
+		RequestedBy: actor, FireTime: fire, Steps: make([]StepRun, 0, len(p.Steps)),
+	}
+	for _, s := range p.Steps {
+		r.Steps = append(r.Steps, StepRun{PipelineStep: s, Status: RunStatusPending})
+	}
+	return r
+}
+
+// PipelineRun is one execution of a pipeline, with the state of every step.
+//
+// It is the pipeline's counterpart of Run, and holds no execution of its own:
+// each step it starts is an ordinary script run on the queue, which the step
+// names, and the pipeline run is advanced by reading those runs back.
+type PipelineRun struct {
+	ID         string    `json:"id"`
+	PipelineID string    `json:"pipeline_id"`
+	Trigger    string    `json:"trigger" example:"tool"`
+	Status     string    `json:"status" example:"running"`
+	Steps      []StepRun `json:"steps"`
+
+	RequestedBy string `json:"requested_by,omitempty"`
+	// FireTime is the instant the run computes against; every step's
+	// ${fire_date} expands from it, so the steps of one run agree on the day.
+	FireTime time.Time `json:"fire_time"`
+	// Attempt counts the times the run was retried from a failed step.
+	Attempt int `json:"attempt"`
+	// Revision is the version of the row this value was read at, which an
+	// update must still match.
+	Revision int `json:"-"`
+
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// StepRun is the state of one step within a pipeline run.
+type StepRun struct {
+	PipelineStep
+
+	Status string `json:"status" example:"succeeded"`
+	// RunID names the script run that executes the step, assigned when the
+	// step starts.
+	RunID string `json:"run_id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Terminal reports whether the pipeline run has finished, however it ended.
+func (r *PipelineRun) Terminal() bool {
+	return r.Status == RunStatusSucceeded || r.Status == RunStatusFailed
+}
+
+// Step returns the named step, or nil.
+func (r *PipelineRun) Step(name string) *StepRun {
+	for i := range r.Steps {
+		if r.Steps[i].Name == name {
+			return &r.Steps[i]
+		}
+	}
+	return nil
+}
+
+// definition returns the steps the run walks.
+func (r *PipelineRun) definition() []PipelineStep {
+	steps := make([]PipelineStep, len(r.Steps))
+	for i := range r.Steps {
+		steps[i] = r.Steps[i].PipelineStep
+	}
+	return steps
+}
+
+// order returns the run's steps in dependency order. The steps were validated
+// when the pipeline was saved; should a stored run read as cyclic all the same,
+// the listed order is walked, which can only leave steps pending.
+func (r *PipelineRun) order() []int {
+	order, err := stepOrder(r.definition())
+	if err != nil {
+		order = make([]int, len(r.Steps))
+		for i := range order {
+			order[i] = i
+		}
+	}
+	return order
+}
+
+// Advance folds what the running steps' script runs have become into the
+// pipeline run and returns the steps that may start now. runs maps run ids to
+// the runs read back; a step whose run is absent is still going.
+//
+// A step whose dependency failed or was skipped is skipped in turn, and the
+// run finishes once no step is pending or running: succeeded when every step
+// did, failed otherwise.
+func (r *PipelineRun) Advance(runs map[string]*Run, now time.Time) []string {
+	for i := range r.Steps {
+		s := &r.Steps[i]
+		run := runs[s.RunID]
+		if s.Status != RunStatusRunning || run == nil || !run.Terminal() {
+			continue
+		}
+		if run.Status == RunStatusSucceeded {
+			s.Status = RunStatusSucceeded
+			continue
+		}
+		s.Status = RunStatusFailed
+		s.Error = stepFailure(run)
+	}
+	ready := []string{}
+	open := false
+	for _, i := range r.order() {
+		s := &r.Steps[i]
+		if s.Status == RunStatusRunning {
+			open = true
+		}
+		if s.Status != RunStatusPending {
+			continue
+		}
+		switch blocked, waiting := r.blockers(s); {
+		case blocked != "":
+			s.Status = StepStatusSkipped
+			s.Error = fmt.Sprintf("step %q did not succeed", blocked)
+		case waiting:
+			open = true
+		default:
+			ready = append(ready, s.Name)
+		}
+	}
+	if open || len(ready) > 0 || r.Terminal() {
+		return ready
+	}
+	r.Status = RunStatusSucceeded
+	for i := range r.Steps {
+		if r.Steps[i].Status != RunStatusSucceeded {
+			r.Status = RunStatusFailed
+		}
+	}
+	r.FinishedAt = &now
+	return ready
+}
+
+// blockers reports the first dependency of s that did not succeed, and whether
+// any dependency has yet to finish.
+func (r *PipelineRun) blockers(s *StepRun) (blocked string, waiting bool) {
+	for _, dep := range s.DependsOn {
+		d := r.Step(dep)
+		switch {
+		case d == nil:
+		case d.Status == RunStatusFailed || d.Status == StepStatusSkipped:
+			return dep, false
+		case d.Status != RunStatusSucceeded:
+			waiting = true
+		}
+	}
+	return "", waiting
+}
+
+// stepFailure is the line a failed step records: which run failed and the
+// first line of why.
+func stepFailure(run *Run) string {
+	first, _, _ := strings.Cut(strings.TrimSpace(run.Error), "\n")
+	msg := fmt.Sprintf("run %s %s", run.ID, run.Status)
+	if first != "" {
+		msg += ": " + first
+	}
+	if runes := []rune(msg); len(runes) > maxStepError {
+		msg = string(runes[:maxStepError])
+	}
+	return msg
+}
+
+// Retry returns a finished run to running from its failed steps, keeping
+// every step that succeeded and the outputs it published. from names a step to
+// re-run from as well, with everything downstream of it, which is how a run is
+// repeated after an upstream step's data was corrected.
+func (r *PipelineRun) Retry(from string) error {
+	if !r.Terminal() {
+		return errors.New("the pipeline run is still going; retry it once it has finished")
+	}
+	reset := map[string]bool{}
+	if from != "" {
+		if r.Step(from) == nil {
+			return fmt.Errorf("the pipeline run has no step %q", from)
+		}
+		reset[from] = true
+	}
+	for _, i := range r.order() {
+		s := &r.Steps[i]
+		if s.Status == RunStatusFailed || s.Status == StepStatusSkipped {
+			reset[s.Name] = true
+		}
+		for _, dep := range s.DependsOn {
+			if reset[dep] {
+				reset[s.Name] = true
+			}
+		}
+	}
+	if len(reset) == 0 {
+		return errors.New("every step of this run succeeded; name a step to re-run from")
+	}
+	for i := range r.Steps {
+		if s := &r.Steps[i]; reset[s.Name] {
+			s.Status, s.RunID, s.Error = RunStatusPending, "", ""
+		}
+	}
+	r.Status, r.FinishedAt = RunStatusRunning, nil
+	r.Attempt++
+	return nil
+}
+
+// BindStep expands a step's parameters against the runs of the steps before
+// it and binds them to the script's contract. upstream maps step names to the
+// runs that executed them.
+func (r *PipelineRun) BindStep(s *StepRun, defs []Param, upstream map[string]*Run) (map[string]any, error) {
+	expanded := make(map[string]any, len(s.Params))
+	for name, value := range s.Params {
+		str, ok := value.(string)
+		if !ok {
+			expanded[name] = value
+			continue
+		}
+		var failure error
+		expanded[name] = tokenPattern.ReplaceAllStringFunc(str, func(token string) string {
+			m := stepTokenPattern.FindStringSubmatch(token)
+			if m == nil {
+				return token
+			}
+			ref, err := publishedOutput(upstream[m[1]], m[1], m[2])
+			if err != nil && failure == nil {
+				failure = fmt.Errorf("parameter %q: %w", name, err)
+			}
+			return ref
+		})
+		if failure != nil {
+			return nil, failure
+		}
+	}
+	return BindScheduleParams(defs, expanded, r.FireTime, time.UTC)
+}
+
+// publishedOutput is where a step's run published one output: the portal asset
+// when the output was versioned there, the delivered object otherwise.
+func publishedOutput(run *Run, step, output string) (string, error) {
+	if run == nil || run.Status != RunStatusSucceeded {
+		return "", fmt.Errorf("step %q has no successful run to read %q from", step, output)
+	}
+	delivered := ""
+	for _, o := range run.Outputs {
+		switch {
+		case o.Name != output:
+		case o.AssetID != "":
+			return o.AssetID, nil
+		case o.Bucket != "" && delivered == "":
+			delivered = "s3://" + o.Bucket + "/" + o.Key
+		}
+	}
+	if delivered == "" {
+		return "", fmt.Errorf("step %q published no output named %q", step, output)
+	}
+	return delivered, nil
+}
+
+// PipelineRunFilter selects pipeline runs for a listing.
+type PipelineRunFilter struct {
+	// PipelineID scopes the listing to one pipeline.
+	PipelineID string
+	// Status scopes the listing to one run status.
+	Status string
+	// Limit caps the rows returned; zero means the store default.
+	Limit int
+}
+
+// PipelineStore persists pipelines and their runs.
+type PipelineStore interface {
+	// SetPipeline creates or replaces a pipeline, keyed on its owner and name,
+	// assigning ID when empty.
+	SetPipeline(ctx context.Context, p *Pipeline) error
+
+	// GetPipeline returns one owner's pipeline by name, or ErrPipelineNotFound.
+	GetPipeline(ctx context.Context, ownerEmail, name string) (*Pipeline, error)
+
+	// ListPipelines returns an owner's pipelines by name, or every pipeline
+	// when ownerEmail is empty.
+	ListPipelines(ctx context.Context, ownerEmail string, limit int) ([]Pipeline, error)
+
+	// DeletePipeline removes a pipeline and its run records. The script runs
+	// its steps produced stay in each script's history.
+	DeletePipeline(ctx context.Context, id string) error
+
+	// DuePipelines returns enabled pipelines whose cadence has come due.
+	DuePipelines(ctx context.Context, now time.Time, limit int) ([]Pipeline, error)
+
+	// AdvancePipeline moves a pipeline's next fire from from to next,
+	// reporting false when another replica moved it first.
+	AdvancePipeline(ctx context.Context, id string, from, next time.Time) (bool, error)
+
+	// StartPipelineRun inserts a run, assigning ID. It reports false, and
+	// inserts nothing, when a scheduled run for the same fire already exists.
+	StartPipelineRun(ctx context.Context, r *PipelineRun) (bool, error)
+
+	// GetPipelineRun returns one run, or ErrPipelineRunNotFound.
+	GetPipelineRun(ctx context.Context, id string) (*PipelineRun, error)
+
+	// ListPipelineRuns returns runs matching the filter, newest first.
+	ListPipelineRuns(ctx context.Context, filter PipelineRunFilter) ([]PipelineRun, error)
+
+	// UpdatePipelineRun writes the run's steps and status if the row is still
+	// at r.Revision, advancing it, and returns ErrPipelineRunMoved otherwise.
+	UpdatePipelineRun(ctx context.Context, r *PipelineRun) error
+}
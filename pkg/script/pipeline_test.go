@@ -0,0 +1,289 @@
+package script
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// revenuePipeline is extract → (aggregate, audit) → publish.
+func revenuePipeline() *Pipeline {
+	return &Pipeline{
+		ID: "pipe_1", Name: "daily-revenue", OwnerEmail: "jane@example.com",
+		Steps: []PipelineStep{
+			{Name: "publish", ScriptID: "script_4", DependsOn: []string{"aggregate", "audit"},
+				Params: map[string]any{"source": "${steps.extract.orders}"}},
+			{Name: "extract", ScriptID: "script_1", Params: map[string]any{"report_date": FireDateToken}},
+			{Name: "aggregate", ScriptID: "script_2", DependsOn: []string{"extract"},
+				Params: map[string]any{"input": "${steps.extract.orders}"}},
+			{Name: "audit", ScriptID: "script_3", DependsOn: []string{"extract"}},
+		},
+	}
+}
+
+func TestPipelinePrepare(t *testing.T) {
+	now := time.Date(2026, 8, 14, 12, 0, 0, 0, time.UTC)
+	mutate := func(f func(p *Pipeline)) *Pipeline {
+		p := revenuePipeline()
+		f(p)
+		return p
+	}
+	tests := []struct {
+		name     string
+		pipeline *Pipeline
+		wantErr  string
+	}{
+		{name: "a valid graph", pipeline: revenuePipeline()},
+		{
+			name:     "no steps",
+			pipeline: mutate(func(p *Pipeline) { p.Steps = nil }),
+			wantErr:  "between 1 and",
+		},
+		{
+			name:     "a bad pipeline name",
+			pipeline: mutate(func(p *Pipeline) { p.Name = "Daily Revenue" }),
+			wantErr:  "pipeline name",
+		},
+		{
+			name:     "a duplicate step",
+			pipeline: mutate(func(p *Pipeline) { p.Steps[3].Name = "aggregate" }),
+			wantErr:  "named twice",
+		},
+		{
+			name:     "a step without a script",
+			pipeline: mutate(func(p *Pipeline) { p.Steps[1].ScriptID = "" }),
+			wantErr:  "needs the script",
+		},
+		{
+			name:     "an unknown dependency",
+			pipeline: mutate(func(p *Pipeline) { p.Steps[3].DependsOn = []string{"load"} }),
+			wantErr:  "not a step of this pipeline",
+		},
+		{
+			name:     "a self dependency",
+			pipeline: mutate(func(p *Pipeline) { p.Steps[3].DependsOn = []string{"audit"} }),
+			wantErr:  "cannot depend on itself",
+		},
+		{
+			name:     "a cycle",
+			pipeline: mutate(func(p *Pipeline) { p.Steps[1].DependsOn = []string{"publish"} }),
+			wantErr:  "cycle",
+		},
+		{
+			name: "an output of a step that runs later",
+			pipeline: mutate(func(p *Pipeline) {
+				p.Steps[3].Params = map[string]any{"input": "${steps.aggregate.totals}"}
+			}),
+			wantErr: "does not depend on",
+		},
+		{
+			name: "an unknown token",
+			pipeline: mutate(func(p *Pipeline) {
+				p.Steps[3].Params = map[string]any{"input": "${yesterday}"}
+			}),
+			wantErr: "not a token a pipeline defines",
+		},
+		{
+			name:     "a bad cadence",
+			pipeline: mutate(func(p *Pipeline) { p.CronSpec = "every morning" }),
+			wantErr:  "cron",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.pipeline.Prepare(nil, now)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	t.Run("an output of an indirect ancestor is readable", func(t *testing.T) {
+		require.NoError(t, revenuePipeline().Prepare(nil, now), "publish reads extract through aggregate")
+	})
+
+	t.Run("a cadence computes the first fire and an edit keeps the identity", func(t *testing.T) {
+		p := revenuePipeline()
+		p.ID, p.CronSpec = "", "0 6 * * *"
+		require.NoError(t, p.Prepare(&Pipeline{ID: "pipe_1", CreatedBy: "jane@example.com"}, now))
+		assert.Equal(t, "pipe_1", p.ID)
+		assert.Equal(t, "jane@example.com", p.CreatedBy)
+		assert.Equal(t, DefaultTimezone, p.Timezone)
+		assert.Equal(t, time.Date(2026, 8, 15, 6, 0, 0, 0, time.UTC), p.NextRunAt)
+	})
+}
+
+// stepDone records a finished script run for a running step.
+func stepDone(t *testing.T, r *PipelineRun, runs map[string]*Run, step, status string, outputs ...RunOutput) {
+	t.Helper()
+	s := r.Step(step)
+	require.NotNil(t, s)
+	require.Equal(t, RunStatusRunning, s.Status)
+	runs[s.RunID] = &Run{ID: s.RunID, Status: status, Error: "boom\ntraceback", Outputs: outputs}
+}
+
+// startAll marks the ready steps as started under predictable run ids.
+func startAll(r *PipelineRun, ready []string) {
+	for _, name := range ready {
+		s := r.Step(name)
+		s.Status, s.RunID = RunStatusRunning, "run_"+name
+	}
+}
+
+func TestPipelineRunAdvance(t *testing.T) {
+	now := time.Date(2026, 8, 14, 12, 0, 0, 0, time.UTC)
+
+	t.Run("steps start as their dependencies succeed", func(t *testing.T) {
+		r := revenuePipeline().NewRun(TriggerTool, "jane@example.com", now)
+		runs := map[string]*Run{}
+
+		ready := r.Advance(runs, now)
+		assert.Equal(t, []string{"extract"}, ready)
+		startAll(r, ready)
+		assert.Empty(t, r.Advance(runs, now), "nothing starts while extract runs")
+
+		stepDone(t, r, runs, "extract", RunStatusSucceeded)
+		ready = r.Advance(runs, now)
+		assert.ElementsMatch(t, []string{"aggregate", "audit"}, ready)
+		startAll(r, ready)
+
+		stepDone(t, r, runs, "aggregate", RunStatusSucceeded)
+		assert.Empty(t, r.Advance(runs, now), "publish waits for audit as well")
+		stepDone(t, r, runs, "audit", RunStatusSucceeded)
+		ready = r.Advance(runs, now)
+		assert.Equal(t, []string{"publish"}, ready)
+		startAll(r, ready)
+
+		stepDone(t, r, runs, "publish", RunStatusSucceeded)
+		assert.Empty(t, r.Advance(runs, now))
+		assert.Equal(t, RunStatusSucceeded, r.Status)
+		require.NotNil(t, r.FinishedAt)
+	})
+
+	t.Run("a failure skips everything downstream and fails the run", func(t *testing.T) {
+		r := revenuePipeline().NewRun(TriggerTool, "jane@example.com", now)
+		runs := map[string]*Run{}
+		startAll(r, r.Advance(runs, now))
+		stepDone(t, r, runs, "extract", RunStatusSucceeded)
+		startAll(r, r.Advance(runs, now))
+		stepDone(t, r, runs, "aggregate", RunStatusFailed)
+
+		assert.Empty(t, r.Advance(runs, now))
+		assert.Equal(t, RunStatusFailed, r.Step("aggregate").Status)
+		assert.Equal(t, "run run_aggregate failed: boom", r.Step("aggregate").Error)
+		assert.Equal(t, StepStatusSkipped, r.Step("publish").Status)
+		assert.False(t, r.Terminal(), "audit is still running")
+
+		stepDone(t, r, runs, "audit", RunStatusSucceeded)
+		r.Advance(runs, now)
+		assert.Equal(t, RunStatusFailed, r.Status)
+	})
+
+	t.Run("a step whose run is not back yet stays running", func(t *testing.T) {
+		r := revenuePipeline().NewRun(TriggerTool, "jane@example.com", now)
+		startAll(r, r.Advance(map[string]*Run{}, now))
+		assert.Empty(t, r.Advance(map[string]*Run{"run_extract": {ID: "run_extract", Status: RunStatusRunning}}, now))
+		assert.Equal(t, RunStatusRunning, r.Step("extract").Status)
+	})
+}
+
+func TestPipelineRunRetry(t *testing.T) {
+	now := time.Date(2026, 8, 14, 12, 0, 0, 0, time.UTC)
+	failedRun := func(t *testing.T) *PipelineRun {
+		t.Helper()
+		r := revenuePipeline().NewRun(TriggerTool, "jane@example.com", now)
+		runs := map[string]*Run{}
+		startAll(r, r.Advance(runs, now))
+		stepDone(t, r, runs, "extract", RunStatusSucceeded)
+		startAll(r, r.Advance(runs, now))
+		stepDone(t, r, runs, "aggregate", RunStatusFailed)
+		stepDone(t, r, runs, "audit", RunStatusSucceeded)
+		r.Advance(runs, now)
+		require.Equal(t, RunStatusFailed, r.Status)
+		return r
+	}
+
+	t.Run("from the failed step", func(t *testing.T) {
+		r := failedRun(t)
+		require.NoError(t, r.Retry(""))
+		assert.Equal(t, RunStatusRunning, r.Status)
+		assert.Nil(t, r.FinishedAt)
+		assert.Equal(t, 1, r.Attempt)
+		assert.Equal(t, RunStatusSucceeded, r.Step("extract").Status, "succeeded steps keep their outputs")
+		assert.Equal(t, "run_extract", r.Step("extract").RunID)
+		assert.Equal(t, RunStatusSucceeded, r.Step("audit").Status)
+		assert.Equal(t, RunStatusPending, r.Step("aggregate").Status)
+		assert.Empty(t, r.Step("aggregate").RunID)
+		assert.Equal(t, RunStatusPending, r.Step("publish").Status)
+		assert.Equal(t, []string{"aggregate"}, r.Advance(map[string]*Run{}, now))
+	})
+
+	t.Run("from a named step re-runs what is downstream of it", func(t *testing.T) {
+		r := failedRun(t)
+		require.NoError(t, r.Retry("extract"))
+		for _, s := range r.Steps {
+			assert.Equal(t, RunStatusPending, s.Status, s.Name)
+		}
+	})
+
+	t.Run("refused while running, for an unknown step, and with nothing to redo", func(t *testing.T) {
+		r := revenuePipeline().NewRun(TriggerTool, "jane@example.com", now)
+		require.ErrorContains(t, r.Retry(""), "still going")
+
+		require.ErrorContains(t, failedRun(t).Retry("load"), "no step")
+
+		done := revenuePipeline().NewRun(TriggerTool, "jane@example.com", now)
+		done.Status = RunStatusSucceeded
+		for i := range done.Steps {
+			done.Steps[i].Status = RunStatusSucceeded
+		}
+		require.ErrorContains(t, done.Retry(""), "name a step")
+	})
+}
+
+func TestPipelineRunBindStep(t *testing.T) {
+	fire := time.Date(2026, 8, 14, 6, 0, 0, 0, time.UTC)
+	r := revenuePipeline().NewRun(TriggerSchedule, "jane@example.com", fire)
+	defs := []Param{
+		{Name: "input", Type: ParamTypeString, Required: true},
+		{Name: "report_date", Type: ParamTypeDate},
+	}
+	extract := &Run{ID: "run_extract", Status: RunStatusSucceeded, Outputs: []RunOutput{
+		{Name: "orders", Destination: "warehouse", Bucket: "lake", Key: "orders/2026-08-14.csv"},
+		{Name: "orders", AssetID: "asset_9", AssetVersion: 3},
+	}}
+
+	t.Run("a portal output is its asset", func(t *testing.T) {
+		params, err := r.BindStep(r.Step("aggregate"), defs, map[string]*Run{"extract": extract})
+		require.NoError(t, err)
+		assert.Equal(t, "asset_9", params["input"])
+	})
+
+	t.Run("a delivered output is its object", func(t *testing.T) {
+		delivered := *extract
+		delivered.Outputs = delivered.Outputs[:1]
+		params, err := r.BindStep(r.Step("aggregate"), defs, map[string]*Run{"extract": &delivered})
+		require.NoError(t, err)
+		assert.Equal(t, "s3://lake/orders/2026-08-14.csv", params["input"])
+	})
+
+	t.Run("fire_date expands against the run", func(t *testing.T) {
+		step := &StepRun{PipelineStep: PipelineStep{Name: "x", Params: map[string]any{
+			"input": "sales", "report_date": FireDateToken,
+		}}}
+		params, err := r.BindStep(step, defs, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "2026-08-14", params["report_date"])
+	})
+
+	t.Run("an output the step did not publish", func(t *testing.T) {
+		step := &StepRun{PipelineStep: PipelineStep{Name: "x", Params: map[string]any{"input": "${steps.extract.refunds}"}}}
+		_, err := r.BindStep(step, defs, map[string]*Run{"extract": extract})
+		require.ErrorContains(t, err, `no output named "refunds"`)
+	})
+}
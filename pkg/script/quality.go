@@ -0,0 +1,90 @@
+package script
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FailureKindDataQuality marks a failed run whose script executed to the end
+// and whose data did not hold up: one of its assertions failed. It is kept
+// apart from an execution failure because the two ask different people for
+// different things — a backtrace asks the author to fix the code, a failed
+// assertion asks whoever owns the table to look at the data — and a run
+// history that reported both as "failed" would send one of them to the wrong
+// place.
+//
+// An execution failure has no kind: the empty string is every failure that
+// predates assertions, and every failure that is not about the data.
+const FailureKindDataQuality = "data_quality"
+
+// FailureKindQuota marks a run stopped, or never started, because its script
+// or its owner had spent their allowance. It is not a fault in the code or the
+// data, and it asks a third person — whoever sets the quota — to decide
+// whether the job is worth what it costs.
+const FailureKindQuota = "quota"
+
+// QualityCheck is the outcome of one assertion a run made about its data.
+//
+// It records what was observed beside what was expected, rather than only
+// whether the two agreed, so a failure alert reads "3,112 rows, expected at
+// least 10,000" and the reader knows how wrong the data is without re-running
+// anything.
+type QualityCheck struct {
+	// Name is the author's label for the check, or the check kind when they
+	// gave none.
+	Name  string `json:"name"`
+	Check string `json:"check" example:"row_count"`
+
+	// Connection and Table locate what was checked. Table is the name the
+	// author wrote; an assert_empty check over arbitrary SQL names no table.
+	Connection string   `json:"connection,omitempty"`
+	Table      string   `json:"table,omitempty"`
+	Columns    []string `json:"columns,omitempty"`
+
+	Passed   bool   `json:"passed"`
+	Observed string `json:"observed"`
+	Expected string `json:"expected"`
+}
+
+// Message renders the check as one line for a log, an alert, or an insight.
+func (c QualityCheck) Message() string {
+	outcome := "passed"
+	if !c.Passed {
+		outcome = "failed"
+	}
+	subject := c.Table
+	if len(c.Columns) > 0 {
+		subject += " (" + strings.Join(c.Columns, ", ") + ")"
+	}
+	if subject != "" {
+		subject = " on " + subject
+	}
+	return fmt.Sprintf("%s %s%s: observed %s, expected %s", c.Name, outcome, subject, c.Observed, c.Expected)
+}
+
+// FailedChecks returns the checks that did not pass, in the order they ran.
+func FailedChecks(checks []QualityCheck) []QualityCheck {
+	out := []QualityCheck{}
+	for _, c := range checks {
+		if !c.Passed {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// QualityFailure composes the failure a run records when its checks did not
+// pass, or "" when they all did. The first line counts them so a history list
+// reads at a glance; each failed check follows on its own line.
+func QualityFailure(checks []QualityCheck) string {
+	failed := FailedChecks(checks)
+	if len(failed) == 0 {
+		return ""
+	}
+	lines := make([]string, 0, len(failed)+1)
+	lines = append(lines, fmt.Sprintf("data quality: %d of %d checks failed", len(failed), len(checks)))
+	for _, c := range failed {
+		lines = append(lines, "  "+c.Message())
+	}
+	return strings.Join(lines, "\n")
+}
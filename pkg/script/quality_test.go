@@ -0,0 +1,34 @@
+package script_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/txn2/mcp-data-platform/pkg/script"
+)
+
+func TestQualityCheckMessage(t *testing.T) {
+	c := script.QualityCheck{
+		Name: "order ids are unique", Check: "uniqueness", Table: "hive.sales.orders",
+		Columns: []string{"order_id"}, Observed: "3 duplicated keys", Expected: "none",
+	}
+	assert.Equal(t, "order ids are unique failed on hive.sales.orders (order_id): observed 3 duplicated keys, expected none", c.Message())
+
+	c = script.QualityCheck{Name: "no orphans", Check: "empty", Passed: true, Observed: "0 rows", Expected: "0 rows"}
+	assert.Equal(t, "no orphans passed: observed 0 rows, expected 0 rows", c.Message(), "a check over arbitrary SQL names no table")
+}
+
+func TestQualityFailure(t *testing.T) {
+	checks := []script.QualityCheck{
+		{Name: "row_count", Table: "hive.sales.orders", Passed: true, Observed: "12000", Expected: ">= 10000"},
+		{Name: "freshness", Table: "hive.sales.orders", Columns: []string{"loaded_at"}, Observed: "30h old", Expected: "<= 24h old"},
+	}
+	assert.Equal(t, "data quality: 1 of 2 checks failed\n"+
+		"  freshness failed on hive.sales.orders (loaded_at): observed 30h old, expected <= 24h old",
+		script.QualityFailure(checks))
+	assert.Len(t, script.FailedChecks(checks), 1)
+
+	assert.Empty(t, script.QualityFailure(checks[:1]), "a run whose checks all passed has no failure")
+	assert.Empty(t, script.QualityFailure(nil), "nor does a run that asserted nothing")
+}
@@ -0,0 +1,61 @@
+package script
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MaxRunRetentionDays bounds a script's own run retention. Ten years is
+// longer than any report's history is read for; the cap is there so a typo
+// does not read as "keep forever" and so the interval arithmetic in the sweep
+// stays far from overflow.
+const MaxRunRetentionDays = 3650
+
+// Retention is how much of one script's history the platform keeps: its run
+// records, and the versions of the portal assets its outputs write.
+//
+// Each field is a pointer because "use the deployment's setting" and every
+// value a script may choose are different answers, and the deployment setting
+// can change underneath a script that did not choose. A script with no
+// retention row, or one whose fields are nil, keeps what the deployment keeps.
+//
+// It sits beside the script rather than on it for the reason a schedule does:
+// it governs what the platform keeps, not what the script is, and editing it
+// is not an edit of the code a version snapshots.
+type Retention struct {
+	ScriptID string `json:"script_id"`
+	// RunDays is how long a finished run of this script is kept, from 1 to
+	// MaxRunRetentionDays. Nil keeps runs for scripts.run_retention_days.
+	RunDays *int `json:"run_days,omitempty" example:"30"`
+	// OutputVersions is how many versions each portal asset the script writes
+	// keeps, applied as that asset's version cap when a run writes it. Zero
+	// keeps every version; nil leaves each asset's own cap as it is.
+	OutputVersions *int `json:"output_versions,omitempty" example:"14"`
+
+	UpdatedBy string     `json:"updated_by,omitempty" example:"jane@example.com"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// Validate checks the bounds. It is called on every write, so a value no
+// surface could have meant never reaches the sweep.
+func (r *Retention) Validate() error {
+	if r.RunDays != nil && (*r.RunDays < 1 || *r.RunDays > MaxRunRetentionDays) {
+		return fmt.Errorf("run_days must be between 1 and %d, got %d", MaxRunRetentionDays, *r.RunDays)
+	}
+	if r.OutputVersions != nil && *r.OutputVersions < 0 {
+		return fmt.Errorf("output_versions must be 0 (keep every version) or greater, got %d", *r.OutputVersions)
+	}
+	return nil
+}
+
+// RetentionStore keeps each script's retention.
+type RetentionStore interface {
+	// GetRetention returns one script's retention. A script that never set
+	// one has the zero retention — every field nil — rather than an error,
+	// because keeping what the deployment keeps is a setting, not an absence.
+	GetRetention(ctx context.Context, scriptID string) (*Retention, error)
+
+	// SetRetention records one script's retention, replacing what it had.
+	SetRetention(ctx context.Context, r *Retention) error
+}
@@ -42,6 +42,18 @@ const (
 	// read by the person who clicked it, and recording their own click as an
 	// agent's tool call is a false statement about who did what.
 	TriggerPortal = "portal"
+	// TriggerEvent marks a run materialized by a schedule's trigger: a change
+	// in the source the schedule watches, rather than a tick of its clock.
+	TriggerEvent = "event"
+	// TriggerPipeline marks a run started as one step of a pipeline run, which
+	// names it. It executes as every other run does; the pipeline only decides
+	// when it starts and with which parameters.
+	TriggerPipeline = "pipeline"
+	// TriggerBackfill marks a run a backfill materialized for one past fire of
+	// a schedule. It is a schedule fire replayed, and a distinct label because
+	// its failure is reported in the backfill rather than mailed: a replay of
+	// a month that failed every day is one problem, not thirty alerts.
+	TriggerBackfill = "backfill"
 )
 
 // Run queue and lifecycle errors.
@@ -62,11 +74,19 @@ var (
 
 // RunMetrics is what one execution cost, recorded on the run for capacity
 // review and for sizing a script's limits against what it actually uses.
+//
+// ToolCalls, RowsScanned and OutputBytes are the axes a quota bounds, with
+// DurationMS as its wall time; a script's and an owner's usage is the sum of
+// them over their finished runs. RowsScanned is what the query tool reported
+// scanning, and zero where it reports nothing.
 type RunMetrics struct {
-	Steps      uint64 `json:"steps"`
-	DurationMS int64  `json:"duration_ms"`
-	Queries    int    `json:"queries"`
-	Exports    int    `json:"exports"`
+	Steps       uint64 `json:"steps"`
+	DurationMS  int64  `json:"duration_ms"`
+	Queries     int    `json:"queries"`
+	Exports     int    `json:"exports"`
+	ToolCalls   int    `json:"tool_calls"`
+	RowsScanned int64  `json:"rows_scanned"`
+	OutputBytes int64  `json:"output_bytes"`
 }
 
 // RunOutput is one persisted output of a run: where it went, and what landed
@@ -104,6 +124,12 @@ type RunOutput struct {
 	// is the payload spliced in, not the document.
 	Refresh bool `json:"refresh,omitempty"`
 	Bytes   int  `json:"bytes"`
+	// SHA256 is the hex digest of what the output left behind: the bytes an
+	// export or a delivery wrote, or the whole document a refresh produced. Two
+	// runs whose outputs carry the same digest wrote the same content, which is
+	// how a run history says "unchanged" without reading either object. It is
+	// empty on outputs recorded before it was.
+	SHA256 string `json:"sha256,omitempty"`
 }
 
 // destinationOf reads a recorded output's destination, treating an unset one as
@@ -140,6 +166,16 @@ type Run struct {
 	// against: the run's (schedule, fire time) pair is unique, so however many
 	// replicas notice the same fire, exactly one run exists for it.
 	ScheduleID string `json:"schedule_id,omitempty"`
+	// EventKey is the observation an event run was materialized for, and is
+	// empty for every other run. It is the event counterpart of FireTime in the
+	// single-fire guarantee: replicas observe the same change at different
+	// moments, so the observation, not the moment, is what is unique.
+	EventKey string `json:"event_key,omitempty"`
+	// BackfillID names the backfill that materialized this run, and is empty
+	// for every other run. A backfill run also carries its ScheduleID and
+	// FireTime; the single-fire guarantee for it is (backfill, fire time),
+	// which is what lets a replay run a fire the schedule already ran.
+	BackfillID string `json:"backfill_id,omitempty"`
 
 	// Params are the bound, type-checked parameter values the run executes
 	// with. They are bound once, when the run is created, so a re-read of the
@@ -166,11 +202,15 @@ type Run struct {
 	LockedUntil *time.Time `json:"locked_until,omitempty"`
 	LockedBy    string     `json:"locked_by,omitempty"`
 
-	Error        string      `json:"error,omitempty"`
-	Log          string      `json:"log,omitempty"`
-	LogTruncated bool        `json:"log_truncated,omitempty"`
-	Metrics      RunMetrics  `json:"metrics"`
-	Outputs      []RunOutput `json:"outputs,omitempty"`
+	Error string `json:"error,omitempty"`
+	// FailureKind is FailureKindDataQuality when a failed run's assertions
+	// failed, and empty for every other outcome.
+	FailureKind  string         `json:"failure_kind,omitempty"`
+	Log          string         `json:"log,omitempty"`
+	LogTruncated bool           `json:"log_truncated,omitempty"`
+	Metrics      RunMetrics     `json:"metrics"`
+	Outputs      []RunOutput    `json:"outputs,omitempty"`
+	Checks       []QualityCheck `json:"checks,omitempty"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -223,10 +263,15 @@ type RunResult struct {
 	Status string
 	// Error is the failure message, carrying the Starlark backtrace when the
 	// script itself failed.
-	Error        string
+	Error string
+	// FailureKind is FailureKindDataQuality when the script ran to the end
+	// and one of its assertions failed, and empty otherwise.
+	FailureKind  string
 	Log          string
 	LogTruncated bool
 	Metrics      RunMetrics
+	// Checks are the assertions the run made, passed and failed alike.
+	Checks []QualityCheck
 }
 
 // RefuseRun reports why the platform must not execute this script, or nil when
@@ -241,6 +286,8 @@ func RefuseRun(sc *Script) error {
 	switch {
 	case sc == nil:
 		return errors.New("the script does not exist")
+	case sc.Library:
+		return errLibraryRun
 	case !sc.Enabled:
 		return errors.New("the script is disabled")
 	case sc.Status == StatusSuperseded:
@@ -280,6 +327,8 @@ func RefuseDraftRun(sc *Script) error {
 	switch {
 	case sc == nil:
 		return errors.New("the script does not exist")
+	case sc.Library:
+		return errLibraryRun
 	case !sc.Enabled:
 		return errors.New("this script is disabled; enable it before running a draft")
 	case sc.Status == StatusSuperseded:
@@ -329,6 +378,11 @@ type RunStore interface {
 	// can tell what it already wrote.
 	RecordOutput(ctx context.Context, lease RunLease, out RunOutput) error
 
+	// RecordLog replaces the claimed run's log with what it has printed so
+	// far, so a reader following the run sees its log as it is written
+	// rather than only once it finishes. Finish writes the final log.
+	RecordLog(ctx context.Context, lease RunLease, log string, truncated bool) error
+
 	// Finish records a terminal result for the claimed run.
 	Finish(ctx context.Context, lease RunLease, res RunResult) error
 
@@ -36,6 +36,9 @@ func TestRefuseRun(t *testing.T) {
 		{"deprecated", func(sc *script.Script) {
 			sc.Status = script.StatusDeprecated
 		}, "deprecated"},
+		{"library", func(sc *script.Script) {
+			sc.Library = true
+		}, "never runs on its own"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -68,8 +68,9 @@ var (
 	ErrUnknownTimezone = errors.New("unknown timezone")
 )
 
-// Schedule is the cadence one script runs on: the cron expression, the zone it
-// is read in, and the parameter values every fire binds.
+// Schedule is the cadence one script runs on: the cron expression or the event
+// trigger that fires it, the zone it is read in, and the parameter values every
+// fire binds.
 //
 // A schedule is not an authority. It names when the script's latest saved
 // version runs and with which parameters, and nothing else; the roles a run
@@ -80,11 +81,21 @@ type Schedule struct {
 	ScriptID string `json:"script_id"`
 
 	// CronSpec is a standard five-field cron expression or one of the
-	// descriptors (@daily, @hourly, @every 30m).
+	// descriptors (@daily, @hourly, @every 30m). It is empty on an event
+	// schedule.
 	CronSpec string `json:"cron_spec" example:"0 7 * * 1-5"`
-	// Timezone is the IANA zone the spec is read in.
+	// Timezone is the IANA zone the spec is read in, and the zone an event
+	// fire's ${fire_date} is expanded in.
 	Timezone string `json:"timezone" example:"America/Los_Angeles"`
 
+	// Trigger, when set, fires the schedule on a change in its source instead
+	// of on a clock. See trigger.go.
+	Trigger *Trigger `json:"trigger,omitempty"`
+	// EventCursor is the last observation of the trigger's source, and nil
+	// until the source has been observed once. It is what the next observation
+	// is compared with.
+	EventCursor *string `json:"event_cursor,omitempty"`
+
 	// Params are the values every fire binds, with tokens unexpanded. They are
 	// stored as written so the schedule reads as what it means ("report on the
 	// day it fires") rather than as whatever date happened to be current when
@@ -94,7 +105,8 @@ type Schedule struct {
 	Enabled bool `json:"enabled"`
 
 	// NextRunAt is when the next fire is due, and zero when the expression has
-	// no further fire at all. It is the materializer's efficiency index, not
+	// no further fire at all. On an event schedule it is when the source is
+	// next observed. It is the materializer's efficiency index, not
 	// its correctness guarantee: two replicas may read the same due schedule,
 	// and what stops them producing two runs is the unique index on the run
 	// they insert.
@@ -240,20 +252,42 @@ func (s *Schedule) Validate(params []Param) error {
 	if s.ScriptID == "" {
 		return errors.New("a schedule needs a script")
 	}
-	cronSpec, err := ParseCron(s.CronSpec, s.Timezone)
+	loc, err := s.location()
 	if err != nil {
 		return err
 	}
 	// Bind against a representative fire so a token that expands to a value
 	// the contract refuses (a ${fire_date} bound to an int parameter) is
 	// caught now rather than at the first fire.
-	_, err = BindScheduleParams(params, s.Params, time.Now().In(cronSpec.Location()), cronSpec.Location())
+	_, err = BindScheduleParams(params, s.Params, time.Now().In(loc), loc)
 	return err
 }
 
+// location checks the schedule's timing — its cron expression, or its trigger
+// — and returns the zone its fires are read in. A schedule has one or the
+// other: a cron expression beside a trigger would read as firing on both.
+func (s *Schedule) location() (*time.Location, error) {
+	if s.Trigger == nil {
+		cronSpec, err := ParseCron(s.CronSpec, s.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		return cronSpec.Location(), nil
+	}
+	if s.CronSpec != "" {
+		return nil, errors.New("a schedule fires on a cron expression or on a trigger, not both")
+	}
+	if err := s.Trigger.Validate(s.ScriptID); err != nil {
+		return nil, err
+	}
+	return loadTimezone(s.Timezone)
+}
+
 // ScheduleRequest is a surface's request to set a script's schedule.
 type ScheduleRequest struct {
 	CronSpec string
+	// Trigger, when set, replaces CronSpec.
+	Trigger  *Trigger
 	Timezone string
 	// Params are the bindings, with tokens as written.
 	Params map[string]any
@@ -278,6 +312,7 @@ func BuildSchedule(sc *Script, prev *Schedule, req ScheduleRequest, now time.Tim
 	sched := &Schedule{
 		ScriptID:  sc.ID,
 		CronSpec:  strings.TrimSpace(req.CronSpec),
+		Trigger:   req.Trigger,
 		Timezone:  strings.TrimSpace(req.Timezone),
 		Params:    req.Params,
 		Enabled:   true,
@@ -296,6 +331,16 @@ func BuildSchedule(sc *Script, prev *Schedule, req ScheduleRequest, now time.Tim
 	if err := sched.Validate(contract); err != nil {
 		return nil, err
 	}
+	if sched.Event() {
+		// Observed on the next pass. An edit that keeps the source keeps the
+		// last observation, so it fires only on a change made since; a new
+		// source starts from a baseline (see Observed).
+		if prev != nil && prev.Trigger.sameSource(sched.Trigger) {
+			sched.EventCursor = prev.EventCursor
+		}
+		sched.NextRunAt = now
+		return sched, nil
+	}
 	cronSpec, err := ParseCron(sched.CronSpec, sched.Timezone)
 	if err != nil {
 		return nil, err
@@ -331,7 +376,7 @@ type ScheduleFire struct {
 // mean a burst of identical reports against the warehouse the moment the
 // platform came back, each computing a date nobody is waiting on any more,
 // which is a worse failure than a visible gap. A backfill somebody actually
-// wants is a run_script call with the parameters they want.
+// wants is asked for explicitly, with a range and a bound; see PlanBackfill.
 //
 // Due is false when nothing is due yet, and when the schedule is further
 // behind than one pass will walk.
@@ -409,6 +454,12 @@ const (
 	// MaterializedDuplicate means another replica materialized this fire
 	// first. It is the normal outcome of racing materializers, not a fault.
 	MaterializedDuplicate Materialization = "duplicate"
+	// MaterializedDeferred means an EVENT fire found the previous run still
+	// open and recorded nothing. Unlike a cron fire, which the next fire
+	// supersedes, a change in the source may never be followed by another, so
+	// skipping it would lose it: the observation is left unconsumed and the
+	// next one, after the open run ends, fires it.
+	MaterializedDeferred Materialization = "deferred"
 )
 
 // ScheduleFilter selects schedules for a listing.
@@ -480,4 +531,7 @@ type ScheduleAdvance struct {
 	Fired time.Time
 	// Missed is added to the schedule's cumulative missed-fire count.
 	Missed int
+	// Cursor, when non-nil, is the observation an event schedule stores as
+	// its new EventCursor.
+	Cursor *string
 }
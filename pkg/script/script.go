@@ -148,6 +148,18 @@ type Script struct {
 	Tags     []string `json:"tags" example:"sales,reporting"`
 	Enabled  bool     `json:"enabled" example:"true"`
 
+	// Library marks a script other scripts load by name and pinned version
+	// rather than one that runs. It is chosen when the script is created and
+	// never changes: a script that started loading it would otherwise find the
+	// ground moved under a pin. A library's source has the pure built-ins and
+	// nothing else — no platform and no run — so loading one grants a script
+	// no reach it did not have.
+	Library bool `json:"library,omitempty" example:"false"`
+	// Loads is the libraries the live source loads, each at the version it
+	// pins. It is read from the source when the source is saved and recorded
+	// with every version, so the dependency graph is the one the code declares.
+	Loads []Dependency `json:"loads"`
+
 	// Lifecycle.
 	Status       string     `json:"status" example:"active"`
 	SupersededBy string     `json:"superseded_by,omitempty" example:"daily-sales-report-v2"`
@@ -0,0 +1,242 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TriggerKind names the source an event schedule watches.
+type TriggerKind string
+
+// Trigger kinds.
+const (
+	// TriggerS3Object fires when an object appears, or is replaced, under a
+	// bucket prefix.
+	TriggerS3Object TriggerKind = "s3_object"
+	// TriggerTrinoPartition fires when the greatest value of a table's
+	// partition column changes, which is how a daily-partitioned table says
+	// "today has landed".
+	TriggerTrinoPartition TriggerKind = "trino_partition"
+	// TriggerDataHubEntity fires when anything DataHub reports about one
+	// entity changes.
+	TriggerDataHubEntity TriggerKind = "datahub_entity"
+	// TriggerScriptRun fires when another script finishes a successful run,
+	// which is how one report follows the refresh it reads.
+	TriggerScriptRun TriggerKind = "script_run"
+)
+
+// Event polling bounds.
+const (
+	// DefaultEventEvery is how often an event schedule observes its source
+	// when it names no interval.
+	DefaultEventEvery = 5 * time.Minute
+	// MaxEventEvery is the longest interval a trigger may name. Beyond a day
+	// the trigger is a worse cron expression.
+	MaxEventEvery = 24 * time.Hour
+)
+
+// identPattern matches one Trino identifier a trigger may name. It is narrower
+// than what Trino accepts, deliberately: the identifiers are quoted into a
+// statement the platform writes, and refusing anything but plain names is how
+// that statement stays exactly the one the author meant.
+var identPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Trigger is what an event schedule watches in place of a clock. Which fields
+// apply depends on Kind; Validate refuses a field the kind does not read, so a
+// stored trigger says exactly what it watches.
+//
+// A trigger is not an authority either. Its source is observed through the
+// platform's own tools as the script's principal, presenting the same captured
+// roles a run presents, so a trigger can watch only what the script could
+// already read.
+type Trigger struct {
+	Kind TriggerKind `json:"kind" example:"s3_object"`
+	// Every is how often the source is observed, as a Go duration. Empty means
+	// DefaultEventEvery.
+	Every string `json:"every,omitempty" example:"5m"`
+
+	// Connection names the platform connection the source is read through.
+	// Empty means the toolkit's default connection. Not used by script_run.
+	Connection string `json:"connection,omitempty" example:"landing"`
+
+	// Bucket and Prefix locate the objects an s3_object trigger watches.
+	Bucket string `json:"bucket,omitempty" example:"landing-zone"`
+	Prefix string `json:"prefix,omitempty" example:"sales/daily/"`
+
+	// Table is the catalog.schema.table a trino_partition trigger watches and
+	// Column the partition column whose greatest value it compares.
+	Table  string `json:"table,omitempty" example:"hive.sales.orders"`
+	Column string `json:"column,omitempty" example:"dt"`
+
+	// URN names the entity a datahub_entity trigger watches.
+	URN string `json:"urn,omitempty" example:"urn:li:dataset:(urn:li:dataPlatform:trino,sales.orders,PROD)"`
+
+	// ScriptID names the script whose successful runs a script_run trigger
+	// follows.
+	ScriptID string `json:"script_id,omitempty"`
+}
+
+// Interval returns how often the source is observed.
+func (t *Trigger) Interval() time.Duration {
+	if t == nil || t.Every == "" {
+		return DefaultEventEvery
+	}
+	d, err := time.ParseDuration(t.Every)
+	if err != nil {
+		return DefaultEventEvery
+	}
+	return d
+}
+
+// TableParts splits Table into its catalog, schema, and table names.
+func (t *Trigger) TableParts() (catalog, schema, table string, ok bool) {
+	parts := strings.Split(t.Table, ".")
+	if len(parts) != 3 {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+// Validate checks that a trigger names a source it can observe. scriptID is
+// the script the trigger belongs to, which a script_run trigger may not follow:
+// a script that fires on its own success fires forever.
+func (t *Trigger) Validate(scriptID string) error {
+	if t.Every != "" {
+		d, err := time.ParseDuration(t.Every)
+		if err != nil {
+			return fmt.Errorf("the trigger interval %q is not a duration such as 5m or 1h", t.Every)
+		}
+		if d < MinFireInterval || d > MaxEventEvery {
+			return fmt.Errorf("the trigger interval must be between %s and %s", MinFireInterval, MaxEventEvery)
+		}
+	}
+	switch t.Kind {
+	case TriggerS3Object:
+		if strings.TrimSpace(t.Bucket) == "" {
+			return errors.New("an s3_object trigger needs the bucket it watches")
+		}
+		return t.refuseUnused(t.Table, t.Column, t.URN, t.ScriptID)
+	case TriggerTrinoPartition:
+		catalog, schema, table, ok := t.TableParts()
+		if !ok || !identPattern.MatchString(catalog) || !identPattern.MatchString(schema) || !identPattern.MatchString(table) {
+			return fmt.Errorf("a trino_partition trigger needs the table as catalog.schema.table of plain names, not %q", t.Table)
+		}
+		if !identPattern.MatchString(t.Column) {
+			return fmt.Errorf("a trino_partition trigger needs the partition column as a plain name, not %q", t.Column)
+		}
+		return t.refuseUnused(t.Bucket, t.Prefix, t.URN, t.ScriptID)
+	case TriggerDataHubEntity:
+		if !strings.HasPrefix(t.URN, "urn:li:") {
+			return fmt.Errorf("a datahub_entity trigger needs the entity's urn (urn:li:...), not %q", t.URN)
+		}
+		return t.refuseUnused(t.Bucket, t.Prefix, t.Table, t.Column, t.ScriptID)
+	case TriggerScriptRun:
+		if t.ScriptID == "" {
+			return errors.New("a script_run trigger needs the script it follows")
+		}
+		if t.ScriptID == scriptID {
+			return errors.New("a script_run trigger cannot follow its own script; it would fire on every run it produced")
+		}
+		return t.refuseUnused(t.Connection, t.Bucket, t.Prefix, t.Table, t.Column, t.URN)
+	default:
+		return fmt.Errorf("unknown trigger kind %q; use %s, %s, %s, or %s", t.Kind,
+			TriggerS3Object, TriggerTrinoPartition, TriggerDataHubEntity, TriggerScriptRun)
+	}
+}
+
+// ErrTriggerCycle marks a script_run trigger that closes a loop: the script it
+// follows already follows this one, directly or through others, so every
+// success in the loop would fire the next script forever.
+var ErrTriggerCycle = errors.New("a script_run trigger cannot close a loop of scripts following one another")
+
+// ScheduleLookup reads one script's schedule, returning ErrScheduleNotFound
+// when it has none. ScheduleStore.GetSchedule is one.
+type ScheduleLookup func(ctx context.Context, scriptID string) (*Schedule, error)
+
+// RefuseTriggerCycle walks the chain of script_run triggers from the script s
+// follows and refuses s when the chain leads back to it. Validate catches a
+// script following itself; this catches the loop through others, which only
+// the stored schedules can show. A disabled schedule is still followed: it is
+// enabled without being validated again.
+func RefuseTriggerCycle(ctx context.Context, lookup ScheduleLookup, s *Schedule) error {
+	if s.Trigger == nil || s.Trigger.Kind != TriggerScriptRun {
+		return nil
+	}
+	seen := map[string]bool{}
+	for next := s.Trigger.ScriptID; next != "" && !seen[next]; {
+		if next == s.ScriptID {
+			return fmt.Errorf("%w: %s already follows this script", ErrTriggerCycle, s.Trigger.ScriptID)
+		}
+		seen[next] = true
+		followed, err := lookup(ctx, next)
+		if errors.Is(err, ErrScheduleNotFound) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading the schedule of %s: %w", next, err)
+		}
+		if followed.Trigger == nil || followed.Trigger.Kind != TriggerScriptRun {
+			return nil
+		}
+		next = followed.Trigger.ScriptID
+	}
+	return nil
+}
+
+// refuseUnused rejects a field the trigger's kind does not read. Accepting one
+// would store a trigger that reads as watching something it ignores.
+func (t *Trigger) refuseUnused(fields ...string) error {
+	for _, f := range fields {
+		if f != "" {
+			return fmt.Errorf("a %s trigger does not take that field; send only the fields its kind reads", t.Kind)
+		}
+	}
+	return nil
+}
+
+// sameSource reports whether two triggers watch the same thing, however often.
+// An edit that keeps the source keeps the last observation, so changing how
+// often a trigger looks does not make it fire for a change it already saw.
+func (t *Trigger) sameSource(o *Trigger) bool {
+	if t == nil || o == nil {
+		return false
+	}
+	a, b := *t, *o
+	a.Every, b.Every = "", ""
+	return a == b
+}
+
+// EventFire is what one observation of a trigger's source concluded.
+type EventFire struct {
+	// Key is the observation, stored as the schedule's cursor.
+	Key string
+	// Due reports whether the observation is a change that fires a run.
+	Due bool
+	// Next is when the source is observed again.
+	Next time.Time
+}
+
+// Observed compares an observation with the schedule's cursor.
+//
+// The first observation of a trigger is a baseline and fires nothing: the data
+// that had already landed when the trigger was set is not news, and firing for
+// it would run every newly triggered report once at an arbitrary moment. An
+// empty observation (nothing under the prefix yet, a table with no partitions)
+// fires nothing either. It is recorded all the same, so the first thing that
+// appears afterwards is a change.
+func (s *Schedule) Observed(key string, now time.Time) EventFire {
+	fire := EventFire{Key: key, Next: now.Add(s.Trigger.Interval())}
+	fire.Due = s.EventCursor != nil && key != "" && key != *s.EventCursor
+	return fire
+}
+
+// Event reports whether the schedule fires on an event rather than a clock.
+func (s *Schedule) Event() bool { return s.Trigger != nil }
+
+// Location returns the zone an event fire's ${fire_date} is expanded in. A cron
+// schedule reads its zone from ParseCron instead, alongside the expression.
+func (s *Schedule) Location() (*time.Location, error) { return loadTimezone(s.Timezone) }
@@ -0,0 +1,224 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTriggerValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		trigger Trigger
+		wantErr string
+	}{
+		{name: "s3 object", trigger: Trigger{Kind: TriggerS3Object, Bucket: "landing", Prefix: "sales/"}},
+		{name: "trino partition", trigger: Trigger{Kind: TriggerTrinoPartition, Table: "hive.sales.orders", Column: "dt"}},
+		{name: "datahub entity", trigger: Trigger{Kind: TriggerDataHubEntity, URN: "urn:li:dataset:(x)"}},
+		{name: "script run", trigger: Trigger{Kind: TriggerScriptRun, ScriptID: "script_2"}},
+		{name: "an interval", trigger: Trigger{Kind: TriggerS3Object, Bucket: "landing", Every: "15m"}},
+		{
+			name: "unknown kind", trigger: Trigger{Kind: "webhook"},
+			wantErr: "unknown trigger kind",
+		},
+		{
+			name: "s3 without a bucket", trigger: Trigger{Kind: TriggerS3Object, Prefix: "sales/"},
+			wantErr: "needs the bucket",
+		},
+		{
+			name: "a table that is not three names", trigger: Trigger{Kind: TriggerTrinoPartition, Table: "orders", Column: "dt"},
+			wantErr: "catalog.schema.table",
+		},
+		{
+			name:    "a quoted identifier is refused",
+			trigger: Trigger{Kind: TriggerTrinoPartition, Table: `hive.sales."orders"`, Column: "dt"},
+			wantErr: "catalog.schema.table",
+		},
+		{
+			name:    "a column that is not a plain name",
+			trigger: Trigger{Kind: TriggerTrinoPartition, Table: "hive.sales.orders", Column: "dt) OR (1=1"},
+			wantErr: "partition column",
+		},
+		{
+			name: "a urn that is not one", trigger: Trigger{Kind: TriggerDataHubEntity, URN: "sales.orders"},
+			wantErr: "urn:li:",
+		},
+		{
+			name: "its own script", trigger: Trigger{Kind: TriggerScriptRun, ScriptID: "script_1"},
+			wantErr: "cannot follow its own script",
+		},
+		{
+			name:    "a field the kind does not read",
+			trigger: Trigger{Kind: TriggerS3Object, Bucket: "landing", URN: "urn:li:dataset:(x)"},
+			wantErr: "does not take that field",
+		},
+		{
+			name:    "a connection on a script run",
+			trigger: Trigger{Kind: TriggerScriptRun, ScriptID: "script_2", Connection: "prod"},
+			wantErr: "does not take that field",
+		},
+		{
+			name: "an interval that is not a duration", trigger: Trigger{Kind: TriggerS3Object, Bucket: "b", Every: "often"},
+			wantErr: "not a duration",
+		},
+		{
+			name: "sub-minute polling", trigger: Trigger{Kind: TriggerS3Object, Bucket: "b", Every: "10s"},
+			wantErr: "must be between",
+		},
+		{
+			name: "polling slower than daily", trigger: Trigger{Kind: TriggerS3Object, Bucket: "b", Every: "48h"},
+			wantErr: "must be between",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.trigger.Validate("script_1")
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestTriggerInterval(t *testing.T) {
+	assert.Equal(t, DefaultEventEvery, (*Trigger)(nil).Interval())
+	assert.Equal(t, DefaultEventEvery, (&Trigger{}).Interval())
+	assert.Equal(t, 15*time.Minute, (&Trigger{Every: "15m"}).Interval())
+}
+
+func TestScheduleObserved(t *testing.T) {
+	now := time.Date(2026, 8, 14, 12, 0, 0, 0, time.UTC)
+	cursor := func(s string) *string { return &s }
+	sched := func(c *string) *Schedule {
+		return &Schedule{Trigger: &Trigger{Kind: TriggerS3Object, Bucket: "b", Every: "10m"}, EventCursor: c}
+	}
+
+	t.Run("the first observation is a baseline", func(t *testing.T) {
+		fire := sched(nil).Observed("2026-08-14T11:00:00Z sales/a.csv", now)
+		assert.False(t, fire.Due, "data that landed before the trigger was set is not news")
+		assert.Equal(t, "2026-08-14T11:00:00Z sales/a.csv", fire.Key)
+		assert.Equal(t, now.Add(10*time.Minute), fire.Next)
+	})
+
+	t.Run("a change fires", func(t *testing.T) {
+		fire := sched(cursor("old")).Observed("new", now)
+		assert.True(t, fire.Due)
+	})
+
+	t.Run("the same observation does not", func(t *testing.T) {
+		assert.False(t, sched(cursor("same")).Observed("same", now).Due)
+	})
+
+	t.Run("the first thing after an empty source fires", func(t *testing.T) {
+		assert.True(t, sched(cursor("")).Observed("first", now).Due)
+	})
+
+	t.Run("an empty observation does not", func(t *testing.T) {
+		assert.False(t, sched(cursor("old")).Observed("", now).Due,
+			"objects being removed is not the arrival the trigger waits for")
+	})
+}
+
+func TestBuildSchedule_Trigger(t *testing.T) {
+	now := time.Date(2026, 8, 14, 12, 0, 0, 0, time.UTC)
+	landing := &Trigger{Kind: TriggerS3Object, Bucket: "landing", Prefix: "sales/"}
+	req := ScheduleRequest{
+		Trigger: landing, Timezone: "America/Los_Angeles",
+		Params: map[string]any{"report_date": FireDateToken}, Actor: "jane@example.com",
+	}
+	seen := "2026-08-14T11:00:00Z sales/a.csv"
+
+	t.Run("a trigger is observed on the next pass", func(t *testing.T) {
+		sched, err := BuildSchedule(scheduledScript(), nil, req, now)
+		require.NoError(t, err)
+		assert.True(t, sched.Event())
+		assert.Empty(t, sched.CronSpec)
+		assert.Equal(t, now, sched.NextRunAt)
+		assert.Nil(t, sched.EventCursor, "a new trigger starts from a baseline")
+	})
+
+	t.Run("an edit that keeps the source keeps the cursor", func(t *testing.T) {
+		slower := req
+		slower.Trigger = &Trigger{Kind: TriggerS3Object, Bucket: "landing", Prefix: "sales/", Every: "30m"}
+		prev := &Schedule{ID: "sched_1", Trigger: landing, EventCursor: &seen}
+		sched, err := BuildSchedule(scheduledScript(), prev, slower, now)
+		require.NoError(t, err)
+		require.NotNil(t, sched.EventCursor)
+		assert.Equal(t, seen, *sched.EventCursor, "looking less often is not a reason to fire again")
+	})
+
+	t.Run("a new source drops the cursor", func(t *testing.T) {
+		moved := req
+		moved.Trigger = &Trigger{Kind: TriggerS3Object, Bucket: "landing", Prefix: "finance/"}
+		prev := &Schedule{ID: "sched_1", Trigger: landing, EventCursor: &seen}
+		sched, err := BuildSchedule(scheduledScript(), prev, moved, now)
+		require.NoError(t, err)
+		assert.Nil(t, sched.EventCursor)
+	})
+
+	t.Run("a cron expression beside a trigger is refused", func(t *testing.T) {
+		both := req
+		both.CronSpec = "@daily"
+		_, err := BuildSchedule(scheduledScript(), nil, both, now)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not both")
+	})
+
+	t.Run("an invalid trigger is refused", func(t *testing.T) {
+		bad := req
+		bad.Trigger = &Trigger{Kind: TriggerS3Object}
+		_, err := BuildSchedule(scheduledScript(), nil, bad, now)
+		require.Error(t, err)
+	})
+
+	t.Run("an unknown zone is refused", func(t *testing.T) {
+		bad := req
+		bad.Timezone = "Mars/Olympus"
+		_, err := BuildSchedule(scheduledScript(), nil, bad, now)
+		require.Error(t, err)
+	})
+}
+
+// TestRefuseTriggerCycle walks the stored chain: a loop back to the script is
+// refused however long it is, and a chain that ends, or loops among other
+// scripts, is not this schedule's to refuse.
+func TestRefuseTriggerCycle(t *testing.T) {
+	follows := func(id, followed string) *Schedule {
+		return &Schedule{ScriptID: id, Trigger: &Trigger{Kind: TriggerScriptRun, ScriptID: followed}}
+	}
+	stored := map[string]*Schedule{
+		"b": follows("b", "c"),
+		"c": follows("c", "a"),
+		"d": {ScriptID: "d", CronSpec: "0 7 * * *"},
+		"x": follows("x", "y"),
+		"y": follows("y", "x"),
+	}
+	lookup := func(_ context.Context, id string) (*Schedule, error) {
+		if s, ok := stored[id]; ok {
+			return s, nil
+		}
+		return nil, ErrScheduleNotFound
+	}
+	ctx := context.Background()
+
+	err := RefuseTriggerCycle(ctx, lookup, follows("a", "b"))
+	require.ErrorIs(t, err, ErrTriggerCycle, "a follows b follows c follows a")
+	assert.Contains(t, err.Error(), "b already follows this script")
+
+	require.NoError(t, RefuseTriggerCycle(ctx, lookup, follows("a", "d")), "a chain that ends on a cron schedule")
+	require.NoError(t, RefuseTriggerCycle(ctx, lookup, follows("a", "z")), "a chain that ends on no schedule")
+	require.NoError(t, RefuseTriggerCycle(ctx, lookup, follows("a", "x")), "a loop a is not part of ends the walk")
+	require.NoError(t, RefuseTriggerCycle(ctx, lookup, &Schedule{ScriptID: "a", CronSpec: "0 7 * * *"}))
+
+	failing := func(context.Context, string) (*Schedule, error) { return nil, errors.New("db down") }
+	err = RefuseTriggerCycle(ctx, failing, follows("a", "b"))
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrTriggerCycle)
+}
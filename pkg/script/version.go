@@ -43,7 +43,8 @@ type Author struct {
 }
 
 // Version is one immutable snapshot of a script's versioned fields (source,
-// params, display name, description, category, tags), with the author who
+// params, display name, description, category, tags, and the libraries the
+// source loads), with the author who
 // produced it and the authority they held.
 //
 // The snapshot is what makes a run explainable months later — a run record
@@ -58,7 +59,10 @@ type Version struct {
 	Source      string   `json:"source"`
 	Params      []Param  `json:"params"`
 	Tags        []string `json:"tags" example:"sales,reporting"`
-	Author      string   `json:"author" example:"jane@example.com"`
+	// Loads is the libraries this version's source loads, at the versions it
+	// pins.
+	Loads  []Dependency `json:"loads"`
+	Author string       `json:"author" example:"jane@example.com"`
 	// AuthorRoles is the authority the author held when this snapshot was
 	// written, and the roles a run of this version presents. See Author.
 	AuthorRoles []string  `json:"author_roles,omitempty" example:"analyst"`
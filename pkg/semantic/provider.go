@@ -288,3 +288,11 @@ type URNResolver interface {
 	// BuildURN creates a URN from a table identifier.
 	BuildURN(ctx context.Context, table TableIdentifier) (string, error)
 }
+
+// URNResolverFrom reports the URN-resolving capability of p, returning the
+// innermost provider that implements it. The caching decorator does not
+// forward ResolveURN; resolving is a parse, so there is nothing to cache. ok
+// is false when no provider in the chain can resolve.
+func URNResolverFrom(p Provider) (URNResolver, bool) {
+	return innermostCapability[URNResolver](p)
+}
@@ -0,0 +1,34 @@
+package semantic
+
+import (
+	"context"
+	"testing"
+)
+
+// resolvingProvider adds URN resolution to an embedded provider.
+type resolvingProvider struct {
+	Provider
+}
+
+func (resolvingProvider) ResolveURN(context.Context, string) (*TableIdentifier, error) {
+	return &TableIdentifier{Schema: "sales", Table: "orders"}, nil
+}
+
+func (resolvingProvider) BuildURN(context.Context, TableIdentifier) (string, error) {
+	return "", nil
+}
+
+func TestURNResolverFrom(t *testing.T) {
+	cached := NewCachedProvider(resolvingProvider{Provider: NewNoopProvider()}, CacheConfig{})
+	resolver, ok := URNResolverFrom(cached)
+	if !ok {
+		t.Fatal("expected the resolver behind the caching decorator")
+	}
+	table, err := resolver.ResolveURN(context.Background(), "urn")
+	if err != nil || table.Table != "orders" {
+		t.Fatalf("ResolveURN = %+v, %v", table, err)
+	}
+	if _, ok := URNResolverFrom(NewNoopProvider()); ok {
+		t.Fatal("noop provider must not report a resolver")
+	}
+}
@@ -0,0 +1,386 @@
+package textpatch
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// AnchorContextBytes is how much text PinAnchor and Reanchor keep on each side
+// of an anchored quote. It matches what the portal viewer captures from a
+// selection, so a refreshed anchor reads the same as a fresh one.
+const AnchorContextBytes = 32
+
+// Anchor pins a comment to a region of a document. A region is named the way a
+// patch names one, by Selector on an HTML document or Section on a markdown
+// one, and a quote (Exact, with its Prefix and Suffix context) narrows it to a
+// run of text. Either half may stand alone: a bare region anchors the whole
+// element or section, and a bare quote is searched for across the document.
+//
+// The quote is matched as rendered text: tags, markdown emphasis markers and
+// whitespace are ignored, so a quote lifted from the viewer finds the source
+// it was rendered from.
+type Anchor struct {
+	Selector string `json:"selector,omitempty"`
+	Section  string `json:"section,omitempty"`
+	Exact    string `json:"exact,omitempty"`
+	Prefix   string `json:"prefix,omitempty"`
+	Suffix   string `json:"suffix,omitempty"`
+}
+
+// IsZero reports whether the anchor names nothing at all.
+func (a Anchor) IsZero() bool {
+	return a.Selector == "" && a.Section == "" && a.Exact == ""
+}
+
+// PinAnchor resolves an anchor against the body it was made on and returns it
+// in the form Reanchor tracks best. A quote with no region is scoped to the
+// innermost landmark (HTML) or heading section (markdown) that contains it, so
+// a later edit that repeats the phrase elsewhere does not move the anchor, and
+// the quote's context is refreshed from the source. An anchor that does not
+// resolve is refused with the same corrective errors a patch gets.
+func PinAnchor(body string, syntax Syntax, a Anchor) (Anchor, error) {
+	if a.IsZero() {
+		return Anchor{}, newError(CodeBadEdit, -1,
+			"Anchor a comment with \"selector\", \"section\", or a quoted \"exact\" text.",
+			"the anchor names no region")
+	}
+	pinned, ok, err := locateAnchor(body, syntax, a)
+	if err != nil {
+		return Anchor{}, err
+	}
+	if !ok {
+		return Anchor{}, newError(CodeNoMatch, -1,
+			"Quote the text exactly as it reads in the document.",
+			"quoted text %q not found", truncate(a.Exact, anchorEchoLimit))
+	}
+	return pinned, nil
+}
+
+// Reanchor carries an anchor forward onto a new version of its document. The
+// region and quote are resolved as they were pinned; when the region is gone or
+// no longer holds the quote, the quote is searched for across the document and
+// re-scoped where it now sits. It reports false, with the anchor unchanged,
+// when neither finds it: the anchored text was deleted and the anchor is
+// orphaned.
+func Reanchor(body string, syntax Syntax, a Anchor) (Anchor, bool) {
+	if a.IsZero() {
+		return a, false
+	}
+	if moved, ok, err := locateAnchor(body, syntax, a); err == nil && ok {
+		return moved, true
+	}
+	if a.Exact == "" {
+		return a, false
+	}
+	loose := Anchor{Exact: a.Exact, Prefix: a.Prefix, Suffix: a.Suffix}
+	if moved, ok, err := locateAnchor(body, syntax, loose); err == nil && ok {
+		return moved, true
+	}
+	return a, false
+}
+
+// locateAnchor resolves a's region and finds its quote inside it, returning
+// the anchor re-scoped and with fresh context. ok is false when the region
+// resolves but the quote is not in it; err reports a region that does not.
+func locateAnchor(body string, syntax Syntax, a Anchor) (Anchor, bool, error) {
+	window := span{start: 0, end: len(body)}
+	req := regionRequest{section: a.Section, selector: a.Selector}
+	if req.hasRegion() {
+		sec, err := resolveRegion(body, syntax, req, -1)
+		if err != nil {
+			return Anchor{}, false, err
+		}
+		window = span{start: sec.start, end: sec.end}
+	}
+	if a.Exact == "" {
+		return a, true, nil
+	}
+
+	p := projectText(body, syntax)
+	hit, ok := p.bestHit(a, window, syntax)
+	if !ok {
+		return Anchor{}, false, nil
+	}
+	out := a
+	out.Prefix, out.Suffix = p.context(hit)
+	if !req.hasRegion() {
+		out.Selector, out.Section = scopeFor(body, syntax, p.bodySpan(hit))
+	}
+	return out, true, nil
+}
+
+// scopeFor names the narrowest region holding s that resolves back to exactly
+// one element or section, or nothing when none does.
+func scopeFor(body string, syntax Syntax, s span) (selector, section string) {
+	switch syntax {
+	case SyntaxHTML:
+		return innermostLandmark(body, s), ""
+	case SyntaxMarkdown:
+		secs := docHeadings(body, syntax)
+		for i := len(secs) - 1; i >= 0; i-- {
+			sec := secs[i]
+			if s.start >= sec.start && s.end <= sec.end && len(matchSections(secs, sec.Path)) == 1 {
+				return "", sec.Path
+			}
+		}
+	}
+	return "", ""
+}
+
+// innermostLandmark returns the selector of the smallest balanced landmark
+// enclosing s whose selector matches it alone.
+func innermostLandmark(body string, s span) string {
+	root := parseHTMLDoc(body)
+	best, bestSize := "", len(body)+1
+	for _, n := range walkNodes(root) {
+		if !n.balanced || n.outerStart > s.start || n.outerEnd < s.end || n.outerEnd-n.outerStart >= bestSize {
+			continue
+		}
+		sel, ok := landmarkSelector(n)
+		if !ok {
+			continue
+		}
+		cs, err := parseSelector(sel, -1)
+		if err != nil || len(selectorMatches(root, cs)) != 1 {
+			continue
+		}
+		best, bestSize = sel, n.outerEnd-n.outerStart
+	}
+	return best
+}
+
+// textProjection is a document reduced to the text a reader sees, with every
+// byte traced back to the body. text keeps single spaces between words, for
+// context a person can read; key drops whitespace entirely and is what quotes
+// are matched on, so a line break in the source and a space in the selection
+// compare equal.
+type textProjection struct {
+	text string
+	// textAt[i] is the body offset of text[i].
+	textAt []int
+	key    string
+	// keyAt[j] is the index in text of key[j].
+	keyAt []int
+}
+
+// projectText builds the projection of body. On HTML and markdown it drops
+// tags; on HTML it decodes the common character references; on markdown it
+// drops the markup a renderer consumes (markupLen).
+func projectText(body string, syntax Syntax) *textProjection {
+	pr := projector{p: &textProjection{}, lineStart: true}
+	for i := 0; i < len(body); {
+		i += pr.step(body, i, syntax)
+	}
+	pr.p.text, pr.p.key = pr.text.String(), pr.key.String()
+	return pr.p
+}
+
+// projector accumulates a projection. Whitespace is held back as a pending
+// word break, so runs collapse and nothing trails.
+type projector struct {
+	p         *textProjection
+	text, key strings.Builder
+	space     bool
+	lineStart bool
+}
+
+// step folds the construct at body[i] into the projection and returns how
+// many bytes it consumed.
+func (pr *projector) step(body string, i int, syntax Syntax) int {
+	c := body[i]
+	switch {
+	case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		pr.space = true
+		pr.lineStart = pr.lineStart || c == '\n'
+		return 1
+	case syntax != SyntaxNone && isTagStart(body, i):
+		// A tag may end a block, so it reads as a word break; the key,
+		// which ignores spaces, is unaffected.
+		pr.space, pr.lineStart = true, false
+		if end := strings.IndexByte(body[i:], '>'); end >= 0 {
+			return end + 1
+		}
+		return len(body) - i
+	case syntax == SyntaxHTML && c == '&':
+		ch, n := charRef(body[i:])
+		switch {
+		case n == 0:
+			pr.emit("&", i)
+			n = 1
+		case ch == " ":
+			pr.space = true
+		default:
+			pr.emit(ch, i)
+		}
+		pr.lineStart = false
+		return n
+	case syntax == SyntaxMarkdown:
+		// Markup keeps lineStart, so "> ## Title" drops both markers.
+		if n := markupLen(body, i, pr.lineStart); n > 0 {
+			return n
+		}
+	}
+	pr.emit(body[i:i+1], i)
+	pr.lineStart = false
+	return 1
+}
+
+// emit appends text read from body offset at, preceded by a pending break.
+func (pr *projector) emit(s string, at int) {
+	for i := 0; i < len(s); i++ {
+		if pr.space && pr.text.Len() > 0 {
+			pr.text.WriteByte(' ')
+			pr.p.textAt = append(pr.p.textAt, at)
+		}
+		pr.space = false
+		pr.key.WriteByte(s[i])
+		pr.p.keyAt = append(pr.p.keyAt, pr.text.Len())
+		pr.text.WriteByte(s[i])
+		pr.p.textAt = append(pr.p.textAt, at+i)
+	}
+}
+
+// isTagStart reports whether body[i] opens a tag rather than being a literal
+// "<", as in "a < b".
+func isTagStart(body string, i int) bool {
+	if body[i] != '<' || i+1 >= len(body) {
+		return false
+	}
+	n := body[i+1]
+	return n == '/' || n == '!' || (n|0x20 >= 'a' && n|0x20 <= 'z')
+}
+
+// markupLen returns how many bytes of markdown markup start at body[i], 0
+// when body[i] is text: emphasis, code, strikethrough and table markers and
+// link brackets anywhere, with a link's "(target)" skipped whole; heading,
+// blockquote and list markers at the start of a line.
+func markupLen(body string, i int, lineStart bool) int {
+	c := body[i]
+	next := byte(0)
+	if i+1 < len(body) {
+		next = body[i+1]
+	}
+	switch {
+	case c == ']' && next == '(':
+		if end := strings.IndexByte(body[i:], ')'); end > 0 {
+			return end + 1
+		}
+		return 1
+	case strings.IndexByte("*_`~|[]", c) >= 0, c == '!' && next == '[':
+		return 1
+	case !lineStart:
+		return 0
+	case c == '#' || c == '>', (c == '-' || c == '+') && next == ' ':
+		return 1
+	}
+	return orderedListMarker(body[i:])
+}
+
+// orderedListMarker returns the length of an ordered-list marker ("12." or
+// "3)") opening s, 0 when there is none.
+func orderedListMarker(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	if n == 0 || n+1 >= len(s) || (s[n] != '.' && s[n] != ')') || s[n+1] != ' ' {
+		return 0
+	}
+	return n + 1
+}
+
+// charRefs are the character references projectText decodes: the ones an
+// editor emits for text, not the full HTML table.
+var charRefs = map[string]string{
+	"&amp;": "&", "&lt;": "<", "&gt;": ">", "&quot;": `"`, "&#39;": "'", "&apos;": "'", "&nbsp;": " ",
+}
+
+// charRef decodes the character reference s opens with, reporting how many
+// bytes it spans, or 0 when it is not one charRefs knows.
+func charRef(s string) (string, int) {
+	end := strings.IndexByte(s, ';')
+	if end < 0 || end > len("&quot;") {
+		return "", 0
+	}
+	ch, ok := charRefs[s[:end+1]]
+	if !ok {
+		return "", 0
+	}
+	return ch, end + 1
+}
+
+// quoteKey reduces a quote or context string to the form it is matched in.
+func quoteKey(s string, syntax Syntax) string {
+	if syntax == SyntaxHTML {
+		return strings.Join(strings.Fields(s), "")
+	}
+	return projectText(s, syntax).key
+}
+
+// bestHit finds a's quote among the key spans that start inside window. When
+// it appears more than once, the occurrence whose surroundings agree longest
+// with the anchor's prefix and suffix wins, the first on a tie.
+func (p *textProjection) bestHit(a Anchor, window span, syntax Syntax) (span, bool) {
+	quote := quoteKey(a.Exact, syntax)
+	if quote == "" {
+		return span{}, false
+	}
+	prefix, suffix := quoteKey(a.Prefix, syntax), quoteKey(a.Suffix, syntax)
+	best, bestScore, found := span{}, -1, false
+	for from := 0; ; {
+		i := strings.Index(p.key[from:], quote)
+		if i < 0 {
+			break
+		}
+		hit := span{start: from + i, end: from + i + len(quote)}
+		from = hit.start + 1
+		s := p.bodySpan(hit)
+		if s.start < window.start || s.end > window.end {
+			continue
+		}
+		score := commonSuffixLen(p.key[:hit.start], prefix) + commonPrefixLen(p.key[hit.end:], suffix)
+		if score > bestScore {
+			best, bestScore, found = hit, score, true
+		}
+	}
+	return best, found
+}
+
+// bodySpan maps a key span back to the body bytes it was read from.
+func (p *textProjection) bodySpan(hit span) span {
+	last := p.keyAt[hit.end-1]
+	return span{start: p.textAt[p.keyAt[hit.start]], end: p.textAt[last] + 1}
+}
+
+// context returns up to AnchorContextBytes of readable text on each side of a
+// key span, cut on rune boundaries.
+func (p *textProjection) context(hit span) (prefix, suffix string) {
+	start, end := p.keyAt[hit.start], p.keyAt[hit.end-1]+1
+	from := max(0, start-AnchorContextBytes)
+	for from > 0 && !utf8.RuneStart(p.text[from]) {
+		from++
+	}
+	to := min(len(p.text), end+AnchorContextBytes)
+	for to < len(p.text) && !utf8.RuneStart(p.text[to]) {
+		to--
+	}
+	return strings.TrimLeft(p.text[from:start], " "), strings.TrimRight(p.text[end:to], " ")
+}
+
+// commonSuffixLen is the length of the longest common suffix of a and b.
+func commonSuffixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	return n
+}
+
+// commonPrefixLen is the length of the longest common prefix of a and b.
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
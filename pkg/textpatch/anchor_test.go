@@ -0,0 +1,110 @@
+package textpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPinAnchorScopesQuoteToLandmark(t *testing.T) {
+	body := `<main><section id="summary"><p>Revenue grew <b>12%</b> in Q3.</p></section>` +
+		`<section data-region="notes"><p>Revenue grew slowly.</p></section></main>`
+
+	a, err := PinAnchor(body, SyntaxHTML, Anchor{Exact: "grew 12% in", Prefix: "Revenue "})
+	require.NoError(t, err)
+	assert.Equal(t, "#summary", a.Selector, "the innermost landmark holding the quote")
+	assert.Equal(t, "Revenue ", a.Prefix)
+	assert.Equal(t, " Q3. Revenue grew slowly.", a.Suffix)
+
+	_, err = PinAnchor(body, SyntaxHTML, Anchor{Exact: "shrank"})
+	var pe *Error
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, CodeNoMatch, pe.Code)
+
+	_, err = PinAnchor(body, SyntaxHTML, Anchor{})
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, CodeBadEdit, pe.Code)
+}
+
+func TestPinAnchorMarkdown(t *testing.T) {
+	body := "# Report\n\n## Method\n\nWe sampled **net_revenue** daily.\n\n## Results\n\nWe sampled it once.\n"
+
+	a, err := PinAnchor(body, SyntaxMarkdown, Anchor{Exact: "sampled net_revenue"})
+	require.NoError(t, err)
+	assert.Equal(t, "Report > Method", a.Section)
+	assert.Equal(t, " daily. Results We sampled it on", a.Suffix, "markers dropped, cut at the context width")
+
+	_, err = PinAnchor(body, SyntaxMarkdown, Anchor{Section: "Appendix"})
+	var pe *Error
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, CodeSectionNotFound, pe.Code)
+}
+
+func TestPinAnchorPrefersMatchingContext(t *testing.T) {
+	body := "Total: 4 rows. Filtered: 4 rows. Shown: 4 rows."
+
+	a, err := PinAnchor(body, SyntaxNone, Anchor{Exact: "4 rows", Prefix: "Filtered: "})
+	require.NoError(t, err)
+	assert.Equal(t, "Total: 4 rows. Filtered: ", a.Prefix)
+	assert.Equal(t, ". Shown: 4 rows.", a.Suffix)
+}
+
+func TestReanchor(t *testing.T) {
+	v1 := `<div id="kpis"><p>Churn fell to 3%.</p></div><div id="notes"><p>Draft.</p></div>`
+	pinned, err := PinAnchor(v1, SyntaxHTML, Anchor{Exact: "fell to 3%"})
+	require.NoError(t, err)
+	require.Equal(t, "#kpis", pinned.Selector)
+
+	tests := []struct {
+		name     string
+		body     string
+		ok       bool
+		selector string
+	}{
+		{"unchanged", v1, true, "#kpis"},
+		{"text edited around it", `<h1>Q3</h1><div id="kpis"><p>Overall, churn fell to 3% this quarter.</p></div>`, true, "#kpis"},
+		{"moved to another landmark", `<div id="kpis"></div><aside id="recap"><p>Churn fell to 3%.</p></aside>`, true, "#recap"},
+		{"region deleted, quote survives", `<p>Churn fell to 3%.</p>`, true, ""},
+		{"quote deleted", `<div id="kpis"><p>Churn rose.</p></div>`, false, "#kpis"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Reanchor(tt.body, SyntaxHTML, pinned)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.selector, got.Selector)
+			assert.Equal(t, "fell to 3%", got.Exact)
+		})
+	}
+}
+
+func TestReanchorRegionOnly(t *testing.T) {
+	a := Anchor{Section: "Method"}
+	_, ok := Reanchor("# Method\n\nbody\n", SyntaxMarkdown, a)
+	assert.True(t, ok)
+	got, ok := Reanchor("# Approach\n\nbody\n", SyntaxMarkdown, a)
+	assert.False(t, ok, "a renamed section orphans a region-only anchor")
+	assert.Equal(t, a, got)
+}
+
+func TestProjectTextDecodesAndCollapses(t *testing.T) {
+	body := "<p>Fish &amp;\n  chips&nbsp;<i>daily</i></p>"
+	p := projectText(body, SyntaxHTML)
+	assert.Equal(t, "Fish & chips daily", p.text)
+	assert.Equal(t, "Fish&chipsdaily", p.key)
+
+	hit, ok := p.bestHit(Anchor{Exact: "& chips"}, span{start: 0, end: 100}, SyntaxHTML)
+	require.True(t, ok)
+	s := p.bodySpan(hit)
+	assert.Equal(t, "&amp;\n  chips", body[s.start:s.end])
+}
+
+func TestProjectTextMarkdownMarkup(t *testing.T) {
+	body := "## Notes\n\n- See [the runbook](https://x.example/rb) for ~~old~~ steps.\n2. Then `deploy`.\n\n| a < b | c |\n"
+	p := projectText(body, SyntaxMarkdown)
+	assert.Equal(t, "Notes See the runbook for old steps. Then deploy. a < b c", p.text)
+
+	a, err := PinAnchor(body, SyntaxMarkdown, Anchor{Exact: "See the runbook for old steps. Then deploy."})
+	require.NoError(t, err, "a selection across a link, strikethrough and list items")
+	assert.Equal(t, "Notes", a.Section)
+}
@@ -0,0 +1,181 @@
+package textpatch
+
+import (
+	"cmp"
+	"strings"
+)
+
+// Block change kinds.
+const (
+	// BlockAdded is a block only the newer document has.
+	BlockAdded = "added"
+	// BlockRemoved is a block only the older document has.
+	BlockRemoved = "removed"
+	// BlockChanged is a block whose element survived with different text.
+	BlockChanged = "changed"
+)
+
+// BlockChange is one element-level difference between two HTML documents, read
+// as a viewer renders them: markup that moves no text (an attribute, a class, a
+// stylesheet) is not a change.
+type BlockChange struct {
+	// Op is BlockAdded, BlockRemoved, or BlockChanged.
+	Op string `json:"op"`
+	// Element is the block's tag name, lower-cased ("p", "li", "h2").
+	Element string `json:"element"`
+	// Section is the heading path the block sits under, as Outline reports it:
+	// in the newer document, or the older one for a removal. "" above the first
+	// heading.
+	Section string `json:"section,omitempty"`
+	// Old is the block's rendered text before; "" for an addition.
+	Old string `json:"old,omitempty"`
+	// New is the block's rendered text after; "" for a removal.
+	New string `json:"new,omitempty"`
+}
+
+// blockElements are the elements a structural diff compares as units. A
+// block owns the text inside it that no nested block owns, so the lead-in of
+// a list item above a nested list is compared, and so is loose text in a body.
+var blockElements = newStringSet(
+	"title", "body", "h1", "h2", "h3", "h4", "h5", "h6", "p", "li", "dt", "dd",
+	"pre", "blockquote", "figcaption", "caption", "tr", "div", "section",
+	"article", "header", "footer", "main", "aside", "nav", "img",
+)
+
+// block is one compared element: its tag, heading path, and owned text.
+type block struct {
+	tag     string
+	section string
+	text    string
+}
+
+// key is what alignment compares: a paragraph that became a list item is a
+// removal and an addition, not an edit.
+func (b block) key() string { return b.tag + "\x00" + b.text }
+
+// StructuralDiff compares two HTML documents block by block and returns what
+// changed in document order. Blocks are aligned on their element and text; a
+// removed block followed by an added one of the same element is reported as
+// one change. Identical documents, or documents differing only in markup that
+// renders no text, yield none.
+func StructuralDiff(oldBody, newBody string) []BlockChange {
+	a, b := htmlBlocks(oldBody), htmlBlocks(newBody)
+	keysA, keysB := make([]string, len(a)), make([]string, len(b))
+	for i := range a {
+		keysA[i] = a[i].key()
+	}
+	for i := range b {
+		keysB[i] = b[i].key()
+	}
+
+	var (
+		out            []BlockChange
+		removed, added []int
+	)
+	for _, st := range Align(keysA, keysB) {
+		switch {
+		case st.Old >= 0 && st.New >= 0:
+			out = appendBlockRun(out, a, b, removed, added)
+			removed, added = removed[:0], added[:0]
+		case st.Old >= 0:
+			removed = append(removed, st.Old)
+		default:
+			added = append(added, st.New)
+		}
+	}
+	return appendBlockRun(out, a, b, removed, added)
+}
+
+// appendBlockRun reports one run of removed and added blocks between two
+// blocks both documents share. Leading pairs of the same element are changes;
+// the rest are removals, then additions.
+func appendBlockRun(out []BlockChange, a, b []block, removed, added []int) []BlockChange {
+	n := 0
+	for ; n < len(removed) && n < len(added) && a[removed[n]].tag == b[added[n]].tag; n++ {
+		was, is := a[removed[n]], b[added[n]]
+		out = append(out, BlockChange{Op: BlockChanged, Element: is.tag, Section: is.section, Old: was.text, New: is.text})
+	}
+	for _, i := range removed[n:] {
+		out = append(out, BlockChange{Op: BlockRemoved, Element: a[i].tag, Section: a[i].section, Old: a[i].text})
+	}
+	for _, j := range added[n:] {
+		out = append(out, BlockChange{Op: BlockAdded, Element: b[j].tag, Section: b[j].section, New: b[j].text})
+	}
+	return out
+}
+
+// htmlBlocks returns the blocks of an HTML document in document order, each
+// with the text it owns. Blocks inside non-rendered elements, and blocks that
+// own no text, are left out.
+func htmlBlocks(body string) []block {
+	view, _ := renderedHTML(body)
+	secs := htmlHeadings(body)
+	var out []block
+	for _, n := range walkNodes(parseHTMLDoc(body)) {
+		tag := strings.ToLower(n.tag)
+		if !blockElements[tag] || insideNonRendered(n) {
+			continue
+		}
+		text := ownedText(view, n)
+		if tag == "img" {
+			alt, _ := n.attrValue("alt")
+			src, _ := n.attrValue("src")
+			text = collapseText(cmp.Or(alt, src))
+		}
+		if text == "" {
+			continue
+		}
+		out = append(out, block{tag: tag, section: sectionPathAt(secs, n.outerStart), text: text})
+	}
+	return out
+}
+
+// ownedText is the rendered text of n's interior minus the spans of the
+// blocks nested in it, which are compared on their own.
+func ownedText(view string, n *htmlNode) string {
+	var parts []string
+	at := n.innerStart
+	var cut func(p *htmlNode)
+	cut = func(p *htmlNode) {
+		for _, c := range p.children {
+			if blockElements[strings.ToLower(c.tag)] {
+				// A force-closed element can report a span that overlaps
+				// the one before it; its text is then already taken.
+				if c.outerStart >= at {
+					parts = append(parts, view[at:c.outerStart])
+				}
+				at = max(at, c.outerEnd)
+				continue
+			}
+			cut(c)
+		}
+	}
+	cut(n)
+	if at < n.innerEnd {
+		parts = append(parts, view[at:n.innerEnd])
+	}
+	return projectText(strings.Join(parts, " "), SyntaxHTML).text
+}
+
+// insideNonRendered reports whether n sits in an element a viewer never shows
+// as text.
+func insideNonRendered(n *htmlNode) bool {
+	for p := n.parent; p != nil; p = p.parent {
+		if nonRenderedElements[strings.ToLower(p.tag)] {
+			return true
+		}
+	}
+	return false
+}
+
+// sectionPathAt returns the path of the innermost section containing offset,
+// or "" above the first heading.
+func sectionPathAt(secs []Section, offset int) string {
+	path := ""
+	for _, s := range secs {
+		if offset >= s.start && offset < s.end {
+			path = s.Path
+		}
+	}
+	return path
+}
@@ -0,0 +1,39 @@
+package textpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStructuralDiff(t *testing.T) {
+	old := `<html><head><title>Sales</title><style>p { color: red }</style></head><body>
+<h1>Sales</h1><p>Revenue grew.</p>
+<ul><li>Regions <ul><li>EMEA</li></ul></li></ul>
+<h2>Notes</h2><p>Old note.</p><img src="q3.png" alt="Q3 chart"></body></html>`
+	updated := `<html><head><title>Sales</title><style>p { color: blue }</style></head><body>
+<h1>Sales</h1><p class="lead">Revenue grew 12%.</p>
+<ul><li>Regions <ul><li>EMEA</li><li>APAC</li></ul></li></ul>
+<h2>Notes</h2><blockquote>Old note.</blockquote><img src="q3.png" alt="Q3 chart"></body></html>`
+
+	assert.Equal(t, []BlockChange{
+		{Op: BlockChanged, Element: "p", Section: "Sales", Old: "Revenue grew.", New: "Revenue grew 12%."},
+		{Op: BlockAdded, Element: "li", Section: "Sales", New: "APAC"},
+		{Op: BlockRemoved, Element: "p", Section: "Sales > Notes", Old: "Old note."},
+		{Op: BlockAdded, Element: "blockquote", Section: "Sales > Notes", New: "Old note."},
+	}, StructuralDiff(old, updated))
+}
+
+func TestStructuralDiffIgnoresMarkupThatRendersNoText(t *testing.T) {
+	old := `<p>Same text.</p><script>var a = 1;</script>`
+	updated := `<p class="x" style="color: red">Same   text.</p><script>var a = 2;</script>`
+	assert.Empty(t, StructuralDiff(old, updated))
+}
+
+func TestStructuralDiffComparesTextOwnedAroundNestedBlocks(t *testing.T) {
+	old := `<div>Intro <p>Body.</p> outro</div>`
+	updated := `<div>Preface <p>Body.</p> outro</div>`
+	assert.Equal(t, []BlockChange{
+		{Op: BlockChanged, Element: "div", Old: "Intro outro", New: "Preface outro"},
+	}, StructuralDiff(old, updated))
+}
@@ -55,6 +55,45 @@ func UnifiedDiff(oldBody, newBody string, context int) string {
 	})
 }
 
+// Step is one step of an alignment between two sequences. Old and New index
+// the elements it pairs; -1 marks the side a step is absent from, so an
+// insertion has Old == -1 and a deletion has New == -1.
+type Step struct {
+	Old int
+	New int
+}
+
+// Align returns the shortest edit script turning a into b as a sequence of
+// steps in order, on the same longest-common-subsequence engine UnifiedDiff
+// renders, with the same bound: sequences too long to align in memory are
+// aligned as one deletion of every differing element and one insertion.
+func Align(a, b []string) []Step {
+	prefix := commonPrefix(a, b)
+	suffix := commonSuffix(a[prefix:], b[prefix:])
+	out := make([]Step, 0, max(len(a), len(b)))
+	for k := range prefix {
+		out = append(out, Step{Old: k, New: k})
+	}
+	i, j := prefix, prefix
+	for _, line := range diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		switch line.tag {
+		case tagEqual:
+			out = append(out, Step{Old: i, New: j})
+			i, j = i+1, j+1
+		case tagDelete:
+			out = append(out, Step{Old: i, New: -1})
+			i++
+		default:
+			out = append(out, Step{Old: -1, New: j})
+			j++
+		}
+	}
+	for k := range suffix {
+		out = append(out, Step{Old: i + k, New: j + k})
+	}
+	return out
+}
+
 // trimmed carries the untouched head and tail around a diffed middle, so the
 // renderer can supply context lines and absolute line numbers without the
 // script holding every unchanged line.
@@ -128,3 +128,11 @@ func TestSplitLines(t *testing.T) {
 	assert.Equal(t, []string{"a", "b"}, splitLines("a\nb"))
 	assert.Equal(t, []string{"a", ""}, splitLines("a\n\n"))
 }
+
+func TestAlign(t *testing.T) {
+	got := Align([]string{"a", "b", "c"}, []string{"a", "x", "c", "d"})
+	assert.Equal(t, []Step{{0, 0}, {1, -1}, {-1, 1}, {2, 2}, {-1, 3}}, got)
+
+	assert.Empty(t, Align(nil, nil))
+	assert.Equal(t, []Step{{-1, 0}}, Align(nil, []string{"a"}))
+}
@@ -0,0 +1,116 @@
+package textpatch
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Passage is a stretch of a document read as its viewer renders it: the text
+// under one heading, up to the next heading of any level, or the lead text above
+// the first heading. Passages tile the document without overlapping, so each
+// run of text belongs to exactly one of them.
+type Passage struct {
+	// Section is the heading's path as Outline reports it ("Report >
+	// Methodology"), so it names the section a patch or an anchor would. ""
+	// for the lead.
+	Section string `json:"section,omitempty"`
+	// Fragment is the URL fragment that lands on the heading: the heading
+	// element's id on HTML, else a slug of its title. Slugs repeated in one
+	// document get a numeric suffix ("notes", "notes-1"). "" for the lead.
+	Fragment string `json:"fragment,omitempty"`
+	// Text is the rendered text, heading title included, with whitespace runs
+	// collapsed to single spaces.
+	Text string `json:"text"`
+}
+
+// nonRenderedElements are the HTML elements whose contents a viewer never
+// shows as text. A dashboard asset is often mostly script, and indexing it
+// would bury the prose a reader actually searched for.
+var nonRenderedElements = newStringSet("script", "style", "template", "noscript")
+
+// Passages splits body into its passages in document order. A structureless
+// document (SyntaxNone) is one passage of its raw text. Passages with no text
+// are dropped, so an empty document yields none.
+func Passages(body string, syntax Syntax) []Passage {
+	if syntax == SyntaxNone {
+		if text := collapseText(body); text != "" {
+			return []Passage{{Text: text}}
+		}
+		return nil
+	}
+	view, ids := body, map[int]string(nil)
+	if syntax == SyntaxHTML {
+		view, ids = renderedHTML(body)
+	}
+	secs := docHeadings(body, syntax)
+	slugs := map[string]int{}
+	var out []Passage
+	add := func(p Passage, start, end int) {
+		p.Text = projectText(view[start:end], syntax).text
+		if p.Text != "" {
+			out = append(out, p)
+		}
+	}
+	lead := len(body)
+	if len(secs) > 0 {
+		lead = secs[0].start
+	}
+	add(Passage{}, 0, lead)
+	for i, s := range secs {
+		end := len(body)
+		if i+1 < len(secs) {
+			end = secs[i+1].start
+		}
+		fragment, ok := ids[s.start]
+		if !ok {
+			fragment = uniqueSlug(slugs, s.Title)
+		}
+		add(Passage{Section: s.Path, Fragment: fragment}, s.start, end)
+	}
+	return out
+}
+
+// renderedHTML returns body with the contents of non-rendered elements blanked
+// to spaces, so offsets into it are offsets into body, and the id of every
+// heading keyed by the heading's start offset.
+func renderedHTML(body string) (string, map[int]string) {
+	view := []byte(body)
+	ids := map[int]string{}
+	for _, n := range walkNodes(parseHTMLDoc(body)) {
+		if nonRenderedElements[strings.ToLower(n.tag)] {
+			for i := n.innerStart; i < n.innerEnd; i++ {
+				view[i] = ' '
+			}
+		}
+		if headingLevel(n.tag) == 0 {
+			continue
+		}
+		if id, ok := n.attrValue("id"); ok && id != "" {
+			ids[n.outerStart] = id
+		}
+	}
+	return string(view), ids
+}
+
+// uniqueSlug slugs a heading title the way markdown renderers do — lower-cased,
+// letters and digits kept, spaces to hyphens, everything else dropped — and
+// suffixes a repeat with its count so two "Notes" sections stay addressable.
+func uniqueSlug(seen map[string]int, title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteByte('-')
+		}
+	}
+	slug := b.String()
+	n := seen[slug]
+	seen[slug] = n + 1
+	if n > 0 {
+		slug += "-" + strconv.Itoa(n)
+	}
+	return slug
+}
@@ -0,0 +1,52 @@
+package textpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPassagesMarkdown(t *testing.T) {
+	ps := Passages(report, SyntaxMarkdown)
+	require.Len(t, ps, 6)
+
+	assert.Equal(t, Passage{Section: "Quarterly Report", Fragment: "quarterly-report",
+		Text: "Quarterly Report Intro paragraph."}, ps[0])
+	assert.Equal(t, "Quarterly Report > Findings", ps[1].Section)
+	assert.Equal(t, "Findings Revenue grew 12% year over year.", ps[1].Text,
+		"a passage stops at the next heading, even a deeper one")
+	assert.Equal(t, "regional-detail", ps[2].Fragment)
+	assert.Equal(t, "appendix-b", ps[5].Fragment)
+}
+
+func TestPassagesLeadAndRepeatedSlugs(t *testing.T) {
+	body := "Preamble text.\n\n## Notes\n\nOne.\n\n## Notes\n\nTwo.\n"
+	ps := Passages(body, SyntaxMarkdown)
+	require.Len(t, ps, 3)
+	assert.Equal(t, Passage{Text: "Preamble text."}, ps[0])
+	assert.Equal(t, "notes", ps[1].Fragment)
+	assert.Equal(t, "notes-1", ps[2].Fragment)
+}
+
+func TestPassagesHTML(t *testing.T) {
+	body := `<html><head><title>Sales</title><style>h1 { color: red }</style></head>
+<body><h1 id="top">Sales and Margin</h1><p>Gross &amp; net margin held.</p>
+<script>const secret = "never indexed";</script>
+<h2>By Region</h2><p>EMEA led.</p></body></html>`
+	ps := Passages(body, SyntaxHTML)
+	require.Len(t, ps, 3)
+
+	assert.Equal(t, "Sales", ps[0].Text, "the lead keeps the title but not the stylesheet")
+	assert.Equal(t, "top", ps[1].Fragment, "an HTML heading's own id is its fragment")
+	assert.Equal(t, "Sales and Margin Gross & net margin held.", ps[1].Text)
+	assert.NotContains(t, ps[1].Text, "secret")
+	assert.Equal(t, "Sales and Margin > By Region", ps[2].Section)
+	assert.Equal(t, "by-region", ps[2].Fragment)
+}
+
+func TestPassagesStructureless(t *testing.T) {
+	assert.Equal(t, []Passage{{Text: "a b"}}, Passages("a\n  b\n", SyntaxNone))
+	assert.Empty(t, Passages(" \n", SyntaxNone))
+	assert.Empty(t, Passages("", SyntaxMarkdown))
+}
@@ -70,6 +70,12 @@ const (
 	// operator-managed inline/upload/url specs (e.g. the portal does
 	// not offer a "Refresh"/"Edit content" affordance for it).
 	SourceEmbedded = "embedded"
+	// SourceGRPC marks a spec synthesized from protobuf descriptors by
+	// a kind=grpc connection (pkg/toolkits/grpcgateway): one OpenAPI
+	// document per gRPC service, re-derived whenever the connection
+	// loads its descriptors. Like SourceEmbedded it is not
+	// operator-selectable.
+	SourceGRPC = "grpc"
 )
 
 // Catalog is the header row in api_catalogs. The (Name, Version)
@@ -178,16 +184,17 @@ func ValidateSpecName(s string) error {
 }
 
 // ValidateSourceKind reports whether s is one of the known source
-// kinds. Returns nil on match, an error otherwise. SourceEmbedded is
-// accepted here (the platform-admin self-seed writes it) but is not an
-// operator-selectable kind: the admin handler restricts operator input
-// to inline|upload|url separately.
+// kinds. Returns nil on match, an error otherwise. SourceEmbedded and
+// SourceGRPC are accepted here (the platform-admin self-seed and grpc
+// connections write them) but are not operator-selectable kinds: the
+// admin handler restricts operator input to inline|upload|url
+// separately.
 func ValidateSourceKind(s string) error {
 	switch s {
-	case SourceInline, SourceUpload, SourceURL, SourceEmbedded:
+	case SourceInline, SourceUpload, SourceURL, SourceEmbedded, SourceGRPC:
 		return nil
 	default:
-		return errors.New("catalog: invalid source_kind (want inline|upload|url|embedded)")
+		return errors.New("catalog: invalid source_kind (want inline|upload|url|embedded|grpc)")
 	}
 }
 
@@ -0,0 +1,139 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// A spec in the catalog says what an upstream answers; contract validation
+// checks a connection's live responses against it and records here where
+// they disagreed. The report is per operation, so an operator reading a
+// catalog sees which endpoints drifted, how often, and the latest difference.
+//
+// Drift is keyed by connection, as examples are: a spec is shared, evidence
+// is not. Two connections on one catalog can front different deployments of
+// the upstream, and only one of them may have changed.
+
+// DriftObservation is one response that did not match its operation.
+type DriftObservation struct {
+	Connection  string
+	CatalogID   string
+	SpecName    string
+	OperationID string
+	Method      string
+	// Path is the spec-relative path template of the operation, not the
+	// concrete path the call used.
+	Path      string
+	Status    int
+	Violation string
+	At        time.Time
+}
+
+// DriftEntry is the drift recorded for one operation on one connection.
+type DriftEntry struct {
+	Connection    string    `json:"connection"`
+	SpecName      string    `json:"spec_name"`
+	OperationID   string    `json:"operation_id,omitempty"`
+	Method        string    `json:"method"`
+	Path          string    `json:"path"`
+	Violations    int64     `json:"violations"`
+	LastStatus    int       `json:"last_status"`
+	LastViolation string    `json:"last_violation"`
+	FirstSeenAt   time.Time `json:"first_seen_at"`
+	LastSeenAt    time.Time `json:"last_seen_at"`
+}
+
+// DriftStore records and reports contract drift.
+//
+// Like ExampleStore it is separate from Store rather than part of it: Store
+// is the catalog's own CRUD contract with several implementations, and drift
+// is evidence about live traffic that only a database-backed deployment
+// keeps.
+type DriftStore interface {
+	// RecordDrift counts one violation against its operation. A spec edited
+	// since the operation's last violation starts its count over: the edit
+	// is presumed to answer what was recorded before it.
+	RecordDrift(ctx context.Context, d DriftObservation) error
+	// ListDrift returns the drift recorded against a catalog's specs, most
+	// recently seen first. A violation last seen before its spec was last
+	// edited is not reported.
+	ListDrift(ctx context.Context, catalogID string) ([]DriftEntry, error)
+}
+
+// driftReset holds when a stored drift row no longer describes the spec the
+// call was checked against: the connection moved to another spec, or the
+// spec was edited after the row was last seen.
+const driftReset = `(api_contract_drift.catalog_id <> EXCLUDED.catalog_id
+	   OR api_contract_drift.spec_name <> EXCLUDED.spec_name
+	   OR api_contract_drift.last_seen_at < (SELECT updated_at FROM api_catalog_specs
+	        WHERE catalog_id = EXCLUDED.catalog_id AND spec_name = EXCLUDED.spec_name))`
+
+// recordDriftQuery upserts on (connection, method, path).
+const recordDriftQuery = `
+	INSERT INTO api_contract_drift
+		(connection, method, path, catalog_id, spec_name, operation_id,
+		 violations, last_status, last_violation, first_seen_at, last_seen_at)
+	VALUES ($1, $2, $3, $4, $5, $6, 1, $7, $8, $9, $9)
+	ON CONFLICT (connection, method, path) DO UPDATE
+	SET violations = CASE WHEN ` + driftReset + ` THEN 1 ELSE api_contract_drift.violations + 1 END,
+	    first_seen_at = CASE WHEN ` + driftReset + ` THEN EXCLUDED.first_seen_at ELSE api_contract_drift.first_seen_at END,
+	    catalog_id = EXCLUDED.catalog_id,
+	    spec_name = EXCLUDED.spec_name,
+	    operation_id = EXCLUDED.operation_id,
+	    last_status = EXCLUDED.last_status,
+	    last_violation = EXCLUDED.last_violation,
+	    last_seen_at = EXCLUDED.last_seen_at`
+
+// RecordDrift counts one violation.
+func (s *PostgresStore) RecordDrift(ctx context.Context, d DriftObservation) error {
+	if d.At.IsZero() {
+		d.At = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx, recordDriftQuery,
+		d.Connection, d.Method, d.Path, d.CatalogID, d.SpecName, d.OperationID,
+		d.Status, d.Violation, d.At)
+	if isPGCode(err, pgForeignKeyViolation) {
+		// The spec was deleted while the call was in flight.
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("catalog: record drift: %w", err)
+	}
+	return nil
+}
+
+// listDriftQuery reads a catalog's current drift.
+const listDriftQuery = `
+	SELECT d.connection, d.spec_name, d.operation_id, d.method, d.path, d.violations,
+	       d.last_status, d.last_violation, d.first_seen_at, d.last_seen_at
+	FROM api_contract_drift d
+	JOIN api_catalog_specs s ON s.catalog_id = d.catalog_id AND s.spec_name = d.spec_name
+	WHERE d.catalog_id = $1 AND d.last_seen_at >= s.updated_at
+	ORDER BY d.last_seen_at DESC`
+
+// ListDrift returns a catalog's drift report.
+func (s *PostgresStore) ListDrift(ctx context.Context, catalogID string) ([]DriftEntry, error) {
+	rows, err := s.db.QueryContext(ctx, listDriftQuery, catalogID)
+	if err != nil {
+		return nil, fmt.Errorf("catalog: list drift: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	entries := []DriftEntry{}
+	for rows.Next() {
+		var e DriftEntry
+		if err := rows.Scan(&e.Connection, &e.SpecName, &e.OperationID, &e.Method, &e.Path,
+			&e.Violations, &e.LastStatus, &e.LastViolation, &e.FirstSeenAt, &e.LastSeenAt); err != nil {
+			return nil, fmt.Errorf("catalog: scan drift: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("catalog: list drift: %w", err)
+	}
+	return entries, nil
+}
+
+// Verify interface compliance.
+var _ DriftStore = (*PostgresStore)(nil)
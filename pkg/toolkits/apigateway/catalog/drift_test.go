@@ -0,0 +1,103 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+func TestRecordDrift_UpsertsOnTheOperation(t *testing.T) {
+	t.Parallel()
+	store, mock, done := newMockStore(t)
+	defer done()
+
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO api_contract_drift`)).
+		WithArgs("acme-prod", "GET", "/orders/{id}", "acme-v1", "orders", "getOrder",
+			200, "status 200: /total: value must be a number", at).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := store.RecordDrift(context.Background(), DriftObservation{
+		Connection: "acme-prod", CatalogID: "acme-v1", SpecName: "orders", OperationID: "getOrder",
+		Method: "GET", Path: "/orders/{id}", Status: 200,
+		Violation: "status 200: /total: value must be a number", At: at,
+	})
+	if err != nil {
+		t.Fatalf("RecordDrift: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// A spec edit restarts the count: the edit is presumed to answer what was
+// recorded before it, so the report does not keep blaming a fixed spec.
+func TestRecordDrift_RestartsAfterASpecEdit(t *testing.T) {
+	t.Parallel()
+	if !strings.Contains(recordDriftQuery, "last_seen_at < (SELECT updated_at FROM api_catalog_specs") {
+		t.Fatal("the upsert must compare the row with the spec's last edit")
+	}
+	if !strings.Contains(listDriftQuery, "d.last_seen_at >= s.updated_at") {
+		t.Fatal("the report must hide drift last seen before the spec's last edit")
+	}
+}
+
+func TestRecordDrift_DeletedSpecIsNotFound(t *testing.T) {
+	t.Parallel()
+	store, mock, done := newMockStore(t)
+	defer done()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO api_contract_drift`)).
+		WillReturnError(&pq.Error{Code: pgForeignKeyViolation})
+
+	err := store.RecordDrift(context.Background(), DriftObservation{Connection: "acme", Method: "GET", Path: "/x"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestListDrift_ReadsTheCatalogsRows(t *testing.T) {
+	t.Parallel()
+	store, mock, done := newMockStore(t)
+	defer done()
+
+	first := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	last := first.Add(3 * time.Hour)
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM api_contract_drift d`)).
+		WithArgs("acme-v1").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"connection", "spec_name", "operation_id", "method", "path", "violations",
+			"last_status", "last_violation", "first_seen_at", "last_seen_at",
+		}).AddRow("acme-prod", "orders", "getOrder", "GET", "/orders/{id}", int64(7),
+			200, "status 200: /total: value must be a number", first, last))
+
+	got, err := store.ListDrift(context.Background(), "acme-v1")
+	if err != nil {
+		t.Fatalf("ListDrift: %v", err)
+	}
+	if len(got) != 1 || got[0].Violations != 7 || got[0].OperationID != "getOrder" || !got[0].LastSeenAt.Equal(last) {
+		t.Fatalf("drift = %+v", got)
+	}
+}
+
+func TestListDrift_EmptyIsNotNil(t *testing.T) {
+	t.Parallel()
+	store, mock, done := newMockStore(t)
+	defer done()
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM api_contract_drift d`)).
+		WithArgs("acme-v1").
+		WillReturnRows(sqlmock.NewRows([]string{"connection"}))
+
+	got, err := store.ListDrift(context.Background(), "acme-v1")
+	if err != nil {
+		t.Fatalf("ListDrift: %v", err)
+	}
+	if got == nil {
+		t.Fatal("an empty report must encode as [], not null")
+	}
+}
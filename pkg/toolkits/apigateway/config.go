@@ -22,6 +22,7 @@ import (
 	"golang.org/x/oauth2"
 
 	"github.com/txn2/mcp-data-platform/pkg/connoauth"
+	"github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway/contract"
 )
 
 const (
@@ -199,6 +200,12 @@ const (
 	// dispatches to the in-process handler wired via SetInternalHandler
 	// (issue #1005, the built-in util connection).
 	cfgKeyHandler = "handler"
+
+	// cfgKeyContractValidation selects which calls have their response
+	// checked against the catalog operation (off, sampled, always);
+	// cfgKeyContractSampleRate is the fraction checked when sampled.
+	cfgKeyContractValidation = "contract_validation"
+	cfgKeyContractSampleRate = "contract_sample_rate"
 )
 
 // HandlerInternal marks a connection whose operations are resolved by
@@ -323,6 +330,9 @@ type Config struct {
 	// (the shared-credential Authenticator is skipped) and an empty
 	// inbound token is a hard error rather than an anonymous call.
 	IdentityPassthrough bool
+	// Contract selects which calls have their response compared with the
+	// catalog operation that describes it. The zero value checks none.
+	Contract contract.Policy
 }
 
 // OAuth2Config describes the OAuth 2.1 client_credentials grant
@@ -458,6 +468,10 @@ func ParseConfig(cfg map[string]any) (Config, error) {
 	c.IdentityPassthrough = getBool(cfg, cfgKeyIdentityPassthrough)
 	c.Description = getString(cfg, cfgKeyDescription)
 	c.Handler = getString(cfg, cfgKeyHandler)
+	c.Contract = contract.Policy{
+		Mode:       contract.Mode(getString(cfg, cfgKeyContractValidation)),
+		SampleRate: contract.ParseRate(cfg[cfgKeyContractSampleRate]),
+	}
 	if c.Handler == HandlerInternal && c.BaseURL == "" {
 		c.BaseURL = internalBaseURL
 	}
@@ -496,6 +510,7 @@ func (c Config) Validate() error {
 		c.validateIdentityPassthrough,
 		c.validateHandler,
 		c.validateTLSMaterial,
+		c.Contract.Validate,
 	)
 }
 
@@ -0,0 +1,223 @@
+// Package contract checks live API gateway responses against the OpenAPI
+// operation that describes them.
+//
+// The gateway trusts the spec in the API catalog: it lists endpoints from it,
+// renders their schemas to the model, and negotiates request bodies from it.
+// Nothing told anyone when the upstream stopped answering the way the spec
+// says. A connection that opts in has its responses compared with the
+// operation's declared response (status, media type, and JSON schema), and
+// each difference is counted and recorded against the operation, so a drifted
+// spec shows up in a report instead of in a model's confused retry.
+//
+// The check observes; it never enforces. A response that violates its spec is
+// returned to the caller exactly as it would have been without the check.
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway/catalog"
+)
+
+// Mode selects which calls on a connection are checked.
+type Mode string
+
+// Modes. The zero value is ModeOff.
+const (
+	// ModeOff checks nothing.
+	ModeOff Mode = "off"
+	// ModeSampled checks a random SampleRate fraction of calls, which bounds
+	// the cost on a busy connection while still catching a drift that
+	// affects every response.
+	ModeSampled Mode = "sampled"
+	// ModeAlways checks every call.
+	ModeAlways Mode = "always"
+)
+
+// DefaultSampleRate is the fraction checked in ModeSampled when the
+// connection names none.
+const DefaultSampleRate = 0.1
+
+// Check outcomes, used as the result label on the contract-check counter.
+const (
+	ResultConformed = "conformed"
+	ResultViolated  = "violated"
+)
+
+const (
+	// maxIssues bounds how many schema errors one violation reports. The
+	// first few say what changed; the rest are usually the same change seen
+	// again in every element of an array.
+	maxIssues = 3
+	// maxViolationLen bounds the recorded description.
+	maxViolationLen = 500
+	// driftWriteTimeout bounds the drift write a violation makes on the
+	// caller's turn, so a slow database delays the response this much at
+	// most.
+	driftWriteTimeout = 2 * time.Second
+)
+
+// Policy is a connection's contract-validation setting.
+type Policy struct {
+	Mode Mode
+	// SampleRate is the fraction of calls checked in ModeSampled, in (0, 1].
+	// Zero means DefaultSampleRate.
+	SampleRate float64
+}
+
+// Validate rejects an unknown mode or an out-of-range sample rate.
+func (p Policy) Validate() error {
+	switch p.Mode {
+	case "", ModeOff, ModeSampled, ModeAlways:
+	default:
+		return fmt.Errorf("apigateway: invalid contract_validation %q (want off, sampled, or always)", p.Mode)
+	}
+	if p.SampleRate < 0 || p.SampleRate > 1 {
+		return errors.New("apigateway: contract_sample_rate must be between 0 and 1")
+	}
+	return nil
+}
+
+// Sample reports whether the current call is checked.
+func (p Policy) Sample() bool {
+	switch p.Mode {
+	case ModeAlways:
+		return true
+	case ModeSampled:
+		rate := p.SampleRate
+		if rate == 0 {
+			rate = DefaultSampleRate
+		}
+		return rand.Float64() < rate // #nosec G404 -- sampling, not security
+	default:
+		return false
+	}
+}
+
+// ParseRate reads a sample rate from a connection's config value, which is a
+// float64 from JSON, an int from YAML, or a string from a form. Anything
+// unreadable is -1, which Validate rejects.
+func ParseRate(v any) float64 {
+	switch r := v.(type) {
+	case nil:
+		return 0
+	case float64:
+		return r
+	case int:
+		return float64(r)
+	case string:
+		if strings.TrimSpace(r) == "" {
+			return 0
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(r), 64)
+		if err != nil {
+			return -1
+		}
+		return f
+	default:
+		return -1
+	}
+}
+
+// Check compares one response with the operation that describes it. It
+// returns checked false when there was nothing to compare: no declared
+// responses, a server error (an outage is not a contract change), or a
+// non-success status the spec does not mention, which specs routinely leave
+// out. Otherwise violation is empty when the response conforms and a
+// one-line description of the difference when it does not.
+//
+// body is the response as the gateway decoded it: JSON values for a JSON
+// media type, a string otherwise. Only JSON bodies are compared with the
+// schema. The description names where in the body a value failed and why,
+// never the value itself, so recording it does not copy upstream data.
+func Check(op *openapi3.Operation, status int, contentType string, body any) (violation string, checked bool) {
+	if op == nil || op.Responses == nil || op.Responses.Len() == 0 || status < 200 || status >= 500 {
+		return "", false
+	}
+	ref := op.Responses.Status(status)
+	if ref == nil {
+		ref = op.Responses.Default()
+	}
+	if ref == nil || ref.Value == nil {
+		if status < 300 {
+			return fmt.Sprintf("status %d is not documented", status), true
+		}
+		return "", false
+	}
+	content := ref.Value.Content
+	if len(content) == 0 || body == nil {
+		return "", true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.TrimSpace(contentType)
+	}
+	declared := content.Get(mediaType)
+	if declared == nil {
+		return fmt.Sprintf("status %d: content type %q is not documented", status, mediaType), true
+	}
+	if declared.Schema == nil || declared.Schema.Value == nil || !strings.Contains(mediaType, "json") {
+		return "", true
+	}
+	if err := declared.Schema.Value.VisitJSON(body, openapi3.MultiErrors(), openapi3.VisitAsResponse()); err != nil {
+		return truncate(fmt.Sprintf("status %d: %s", status, describe(err))), true
+	}
+	return "", true
+}
+
+// RecordDrift files one violation in the catalog's drift report. A failed
+// write is logged and dropped: the report is evidence, not a ledger, and the
+// next violation of the same operation files it again.
+func RecordDrift(ctx context.Context, store catalog.DriftStore, d catalog.DriftObservation) {
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), driftWriteTimeout)
+	defer cancel()
+	if err := store.RecordDrift(writeCtx, d); err != nil {
+		slog.Warn("apigateway: recording contract drift failed",
+			"connection", d.Connection, "operation_id", d.OperationID, "error", err)
+	}
+}
+
+// describe renders schema errors as "pointer: reason" pairs. SchemaError's
+// own Error text embeds the offending value and the whole schema, which is
+// both too long to record and upstream data the report should not hold.
+func describe(err error) string {
+	var errs []error
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		errs = multi
+	} else {
+		errs = []error{err}
+	}
+	issues := make([]string, 0, min(len(errs), maxIssues))
+	for _, e := range errs {
+		if len(issues) == maxIssues {
+			issues = append(issues, fmt.Sprintf("and %d more", len(errs)-maxIssues))
+			break
+		}
+		var se *openapi3.SchemaError
+		if errors.As(e, &se) {
+			issues = append(issues, "/"+strings.Join(se.JSONPointer(), "/")+": "+se.Reason)
+			continue
+		}
+		issues = append(issues, "response does not match its schema")
+	}
+	return strings.Join(issues, "; ")
+}
+
+// truncate bounds a description at maxViolationLen runes.
+func truncate(s string) string {
+	if r := []rune(s); len(r) > maxViolationLen {
+		return string(r[:maxViolationLen]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,156 @@
+package contract
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ordersSpec = `
+openapi: 3.0.3
+info: {title: orders, version: "1"}
+paths:
+  /orders/{id}:
+    get:
+      operationId: getOrder
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        "200":
+          description: the order
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id, total]
+                properties:
+                  id: {type: string}
+                  total: {type: number}
+                  lines:
+                    type: array
+                    items:
+                      type: object
+                      required: [sku]
+                      properties:
+                        sku: {type: string}
+        "404":
+          description: no such order
+  /orders:
+    post:
+      operationId: createOrder
+      responses:
+        "201":
+          description: created
+`
+
+func operation(t *testing.T, path, method string) *openapi3.Operation {
+	t.Helper()
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData([]byte(ordersSpec))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+	op := doc.Paths.Value(path).GetOperation(method)
+	require.NotNil(t, op)
+	return op
+}
+
+func TestCheck_ConformingResponse(t *testing.T) {
+	op := operation(t, "/orders/{id}", "GET")
+	body := map[string]any{"id": "o-1", "total": 12.5, "lines": []any{map[string]any{"sku": "A"}}}
+	violation, checked := Check(op, 200, "application/json; charset=utf-8", body)
+	assert.True(t, checked)
+	assert.Empty(t, violation)
+}
+
+func TestCheck_SchemaViolationNamesWhereNotWhat(t *testing.T) {
+	op := operation(t, "/orders/{id}", "GET")
+	body := map[string]any{"id": "o-1", "total": "secret-amount-12.50"}
+	violation, checked := Check(op, 200, "application/json", body)
+	assert.True(t, checked)
+	assert.Contains(t, violation, "status 200")
+	assert.Contains(t, violation, "/total")
+	assert.NotContains(t, violation, "secret-amount", "the offending value must not be recorded")
+}
+
+func TestCheck_MissingRequiredProperty(t *testing.T) {
+	op := operation(t, "/orders/{id}", "GET")
+	violation, checked := Check(op, 200, "application/json", map[string]any{"id": "o-1"})
+	assert.True(t, checked)
+	assert.Contains(t, violation, "total")
+}
+
+func TestCheck_UndocumentedSuccessStatus(t *testing.T) {
+	op := operation(t, "/orders/{id}", "GET")
+	violation, checked := Check(op, 202, "application/json", map[string]any{})
+	assert.True(t, checked)
+	assert.Equal(t, "status 202 is not documented", violation)
+}
+
+func TestCheck_UndocumentedContentType(t *testing.T) {
+	op := operation(t, "/orders/{id}", "GET")
+	violation, checked := Check(op, 200, "text/html", "<html></html>")
+	assert.True(t, checked)
+	assert.Contains(t, violation, `content type "text/html" is not documented`)
+}
+
+func TestCheck_NothingToCompare(t *testing.T) {
+	op := operation(t, "/orders/{id}", "GET")
+	for name, tc := range map[string]struct {
+		status int
+		body   any
+	}{
+		"server error":            {status: 502, body: "bad gateway"},
+		"undocumented client err": {status: 401, body: map[string]any{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, checked := Check(op, tc.status, "application/json", tc.body)
+			assert.False(t, checked)
+		})
+	}
+	_, checked := Check(nil, 200, "application/json", nil)
+	assert.False(t, checked)
+}
+
+func TestCheck_DocumentedWithoutContentConforms(t *testing.T) {
+	violation, checked := Check(operation(t, "/orders/{id}", "GET"), 404, "application/json", map[string]any{"error": "x"})
+	assert.True(t, checked)
+	assert.Empty(t, violation)
+	violation, checked = Check(operation(t, "/orders", "POST"), 201, "", nil)
+	assert.True(t, checked)
+	assert.Empty(t, violation)
+}
+
+func TestCheck_BoundsTheDescription(t *testing.T) {
+	op := operation(t, "/orders/{id}", "GET")
+	lines := make([]any, 50)
+	for i := range lines {
+		lines[i] = map[string]any{"sku": i}
+	}
+	violation, _ := Check(op, 200, "application/json", map[string]any{"id": "o", "total": 1, "lines": lines})
+	assert.LessOrEqual(t, len([]rune(violation)), maxViolationLen+1)
+	assert.LessOrEqual(t, strings.Count(violation, ";"), maxIssues)
+}
+
+func TestPolicy(t *testing.T) {
+	assert.False(t, Policy{}.Sample())
+	assert.False(t, Policy{Mode: ModeOff}.Sample())
+	assert.True(t, Policy{Mode: ModeAlways}.Sample())
+	assert.True(t, Policy{Mode: ModeSampled, SampleRate: 1}.Sample())
+
+	require.NoError(t, Policy{Mode: ModeSampled, SampleRate: 0.25}.Validate())
+	require.Error(t, Policy{Mode: "sometimes"}.Validate())
+	require.Error(t, Policy{Mode: ModeSampled, SampleRate: 1.5}.Validate())
+	require.Error(t, Policy{Mode: ModeSampled, SampleRate: ParseRate("lots")}.Validate())
+}
+
+func TestParseRate(t *testing.T) {
+	assert.InDelta(t, 0.0, ParseRate(nil), 0)
+	assert.InDelta(t, 0.5, ParseRate(0.5), 0)
+	assert.InDelta(t, 1.0, ParseRate(1), 0)
+	assert.InDelta(t, 0.2, ParseRate(" 0.2 "), 1e-9)
+	assert.InDelta(t, -1.0, ParseRate(true), 0)
+}
@@ -0,0 +1,63 @@
+package apigateway
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway/catalog"
+	"github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway/contract"
+)
+
+// checkContract compares a buffered response with the operation the
+// connection's catalog declares for it, when the connection's contract
+// policy selects this call. The outcome is counted per operation, and a
+// violation is recorded in the catalog's drift report. The response goes
+// back to the caller unchanged either way.
+//
+// Specs are consulted in the order operation resolution uses, so a path
+// two specs share is checked against the operation its metric is labeled
+// with, on every call.
+//
+// A truncated body cannot be judged, and the caller skips projected and
+// followed calls, whose body is no longer the upstream's response.
+func (t *Toolkit) checkContract(ctx context.Context, c *conn, method, path string, out InvokeOutput) {
+	if out.BodyTruncated || out.Status == 0 || !c.cfg.Contract.Sample() {
+		return
+	}
+	method = strings.ToUpper(method)
+	for _, name := range specNamesInOrder(c.specs) {
+		st := c.specs[name]
+		if st == nil || st.doc == nil || st.doc.Paths == nil {
+			continue
+		}
+		item, raw := findMostSpecificPathMatch(st, stripQueryAndFragment(path))
+		var op *openapi3.Operation
+		if item != nil {
+			op = operationForMethod(item, method)
+		}
+		if op == nil {
+			continue
+		}
+		violation, checked := contract.Check(op, out.Status, http.Header(out.Headers).Get(headerContentType), out.Body)
+		if !checked {
+			return
+		}
+		t.mu.RLock()
+		metrics, drift := t.metrics, t.driftStore
+		t.mu.RUnlock()
+		id := operationIDOrSynthesized(op, method, raw)
+		recordContractCheck(ctx, metrics, c.cfg.ConnectionName, id, violation != "")
+		// The report lives on the catalog; a spec with no catalog row has
+		// nowhere to file one.
+		if violation != "" && drift != nil && c.cfg.CatalogID != "" {
+			contract.RecordDrift(ctx, drift, catalog.DriftObservation{
+				Connection: c.cfg.ConnectionName, CatalogID: c.cfg.CatalogID, SpecName: name,
+				OperationID: id, Method: method, Path: raw, Status: out.Status, Violation: violation,
+			})
+		}
+		return
+	}
+}
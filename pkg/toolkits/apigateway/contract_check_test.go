@@ -0,0 +1,210 @@
+package apigateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway/catalog"
+)
+
+const contractSpec = `openapi: 3.0.3
+info: {title: orders, version: "1"}
+paths:
+  /orders/{id}:
+    get:
+      operationId: getOrder
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        "200":
+          description: the order
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id, total]
+                properties:
+                  id: {type: string}
+                  total: {type: number}
+`
+
+// fakeDriftStore records observations in memory.
+type fakeDriftStore struct {
+	mu  sync.Mutex
+	got []catalog.DriftObservation
+}
+
+func (f *fakeDriftStore) RecordDrift(_ context.Context, d catalog.DriftObservation) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.got = append(f.got, d)
+	return nil
+}
+
+func (*fakeDriftStore) ListDrift(context.Context, string) ([]catalog.DriftEntry, error) {
+	return []catalog.DriftEntry{}, nil
+}
+
+func (f *fakeDriftStore) observations() []catalog.DriftObservation {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]catalog.DriftObservation(nil), f.got...)
+}
+
+func newContractToolkit(t *testing.T, body, mode string) (*Toolkit, *fakeDriftStore) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", applicationJSON)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	tk := New("test")
+	setupCatalogWithSpec(t, tk, "orders-v1", "orders", contractSpec)
+	drift := &fakeDriftStore{}
+	tk.SetDriftStore(drift)
+	if err := tk.AddConnection("orders", map[string]any{
+		"base_url":            srv.URL,
+		"auth_mode":           AuthModeNone,
+		"catalog_id":          "orders-v1",
+		"contract_validation": mode,
+	}); err != nil {
+		t.Fatalf("AddConnection: %v", err)
+	}
+	return tk, drift
+}
+
+func TestHandleInvoke_ContractViolationIsRecordedNotEnforced(t *testing.T) {
+	tk, drift := newContractToolkit(t, `{"id":"o-1","total":"12.50"}`, "always")
+
+	res, _, err := tk.handleInvoke(context.Background(), nil, InvokeInput{
+		Connection: "orders", Method: "GET", Path: "/orders/o-1",
+	})
+	if err != nil {
+		t.Fatalf("handleInvoke: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("a drifted response must still reach the caller: %s", textContent(res))
+	}
+	if !strings.Contains(textContent(res), "12.50") {
+		t.Errorf("response body changed: %s", textContent(res))
+	}
+
+	got := drift.observations()
+	if len(got) != 1 {
+		t.Fatalf("drift observations = %d; want 1", len(got))
+	}
+	d := got[0]
+	if d.Connection != "orders" || d.CatalogID != "orders-v1" || d.SpecName != "orders" ||
+		d.OperationID != "getOrder" || d.Method != "GET" || d.Path != "/orders/{id}" || d.Status != http.StatusOK {
+		t.Errorf("observation = %+v", d)
+	}
+	if !strings.Contains(d.Violation, "/total") || strings.Contains(d.Violation, "12.50") {
+		t.Errorf("violation = %q; want the pointer, not the value", d.Violation)
+	}
+}
+
+func TestHandleInvoke_ContractConformingResponseRecordsNothing(t *testing.T) {
+	tk, drift := newContractToolkit(t, `{"id":"o-1","total":12.5}`, "always")
+	if _, _, err := tk.handleInvoke(context.Background(), nil, InvokeInput{
+		Connection: "orders", Method: "GET", Path: "/orders/o-1",
+	}); err != nil {
+		t.Fatalf("handleInvoke: %v", err)
+	}
+	if got := drift.observations(); len(got) != 0 {
+		t.Errorf("conforming response recorded drift: %+v", got)
+	}
+}
+
+// TestHandleInvoke_ContractSharedPathUsesResolutionOrder loads two specs
+// that declare the same path. Every call must be checked, and its drift
+// filed, against the spec operation resolution picks, not whichever one a
+// map range reached first.
+func TestHandleInvoke_ContractSharedPathUsesResolutionOrder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", applicationJSON)
+		_, _ = w.Write([]byte(`{"id":"o-1","total":"12.50"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	tk := New("test")
+	store := setupCatalogWithSpec(t, tk, "orders-v1", "orders-b", strings.Replace(contractSpec, "getOrder", "getOrderB", 1))
+	if err := store.UpsertSpec(context.Background(), "orders-v1",
+		newSpecEntry("orders-a", strings.Replace(contractSpec, "getOrder", "getOrderA", 1))); err != nil {
+		t.Fatalf("UpsertSpec: %v", err)
+	}
+	drift := &fakeDriftStore{}
+	tk.SetDriftStore(drift)
+	if err := tk.AddConnection("orders", map[string]any{
+		"base_url":            srv.URL,
+		"auth_mode":           AuthModeNone,
+		"catalog_id":          "orders-v1",
+		"contract_validation": "always",
+	}); err != nil {
+		t.Fatalf("AddConnection: %v", err)
+	}
+
+	if got := tk.ResolveOperationID(context.Background(), "orders", "GET", "/orders/o-1"); got != "getOrderA" {
+		t.Fatalf("ResolveOperationID = %q; want getOrderA, from the first spec by name", got)
+	}
+	const calls = 20
+	for range calls {
+		if _, _, err := tk.handleInvoke(context.Background(), nil, InvokeInput{
+			Connection: "orders", Method: "GET", Path: "/orders/o-1",
+		}); err != nil {
+			t.Fatalf("handleInvoke: %v", err)
+		}
+	}
+	got := drift.observations()
+	if len(got) != calls {
+		t.Fatalf("drift observations = %d; want %d", len(got), calls)
+	}
+	for _, d := range got {
+		if d.SpecName != "orders-a" || d.OperationID != "getOrderA" {
+			t.Fatalf("observation filed against %s/%s; want orders-a/getOrderA", d.SpecName, d.OperationID)
+		}
+	}
+}
+
+func TestHandleInvoke_ContractValidationOffChecksNothing(t *testing.T) {
+	tk, drift := newContractToolkit(t, `{"id":"o-1","total":"12.50"}`, "off")
+	if _, _, err := tk.handleInvoke(context.Background(), nil, InvokeInput{
+		Connection: "orders", Method: "GET", Path: "/orders/o-1",
+	}); err != nil {
+		t.Fatalf("handleInvoke: %v", err)
+	}
+	if got := drift.observations(); len(got) != 0 {
+		t.Errorf("validation off recorded drift: %+v", got)
+	}
+}
+
+func TestParseConfig_ContractValidation(t *testing.T) {
+	cfg, err := ParseConfig(map[string]any{
+		"base_url":             "https://x",
+		"contract_validation":  "sampled",
+		"contract_sample_rate": 0.25,
+	})
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+	if cfg.Contract.Mode != "sampled" || cfg.Contract.SampleRate != 0.25 {
+		t.Errorf("contract = %+v", cfg.Contract)
+	}
+	if _, err := ParseConfig(map[string]any{
+		"base_url":            "https://x",
+		"contract_validation": "sometimes",
+	}); err == nil {
+		t.Error("an unknown contract_validation must be rejected")
+	}
+	if _, err := ParseConfig(map[string]any{
+		"base_url":             "https://x",
+		"contract_validation":  "sampled",
+		"contract_sample_rate": "2",
+	}); err == nil {
+		t.Error("a sample rate above 1 must be rejected")
+	}
+}
@@ -18,12 +18,13 @@ import (
 	"github.com/txn2/mcp-data-platform/pkg/toolkit"
 )
 
-// exportToolName is the MCP tool name. The trino_export pattern
+// ToolExport is the MCP tool name. The trino_export pattern
 // established the *_export naming for tools whose purpose is "run
 // something that produces a potentially-huge result, write it to a
 // portal asset, return asset metadata not data". api_export does
-// the same for upstream HTTP API responses.
-const exportToolName = "api_export"
+// the same for upstream HTTP API responses. Exported so the script
+// runtime names the same literal as the registration site.
+const ToolExport = "api_export"
 
 // defaultExportMaxBytes caps how much of an upstream response will
 // be written to a portal asset when the operator has not configured
@@ -240,7 +241,7 @@ func (t *Toolkit) registerExportTool(s *mcp.Server) {
 		return
 	}
 	mcp.AddTool(s, &mcp.Tool{
-		Name:  exportToolName,
+		Name:  ToolExport,
 		Title: "Export API Endpoint Response",
 		Description: "Invoke an upstream API endpoint and stream the response into a portal asset INSTEAD of returning it through the model context. " +
 			"Use this when api_invoke_endpoint reports body_truncated, when you expect a response too large to be useful through the model, or when you want to hand off the data to trino_query / s3_get_object / a portal share. " +
@@ -666,7 +667,7 @@ func buildExportProvenance(uc *ExportUserContext, in exportInput, status int, de
 		DeclaredContentType: declaredType,
 		ToolCalls: []ExportProvenanceCall{
 			{
-				ToolName:  exportToolName,
+				ToolName:  ToolExport,
 				Timestamp: time.Now().UTC().Format(time.RFC3339),
 				Parameters: map[string]any{
 					"connection":      in.Connection,
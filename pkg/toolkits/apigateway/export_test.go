@@ -675,13 +675,13 @@ func TestRegisterExportTool_AddsToToolListWhenDepsWired(t *testing.T) {
 	deps := defaultExportDeps(&fakeExportAssetStore{}, &fakeExportVersionStore{}, &fakeExportS3Client{})
 
 	wired := buildExportTestToolkit(t, upstream.URL, &deps)
-	if !contains(wired.Tools(), exportToolName) {
-		t.Errorf("Tools() missing %q after wiring; got %v", exportToolName, wired.Tools())
+	if !contains(wired.Tools(), ToolExport) {
+		t.Errorf("Tools() missing %q after wiring; got %v", ToolExport, wired.Tools())
 	}
 
 	unwired := buildExportTestToolkit(t, upstream.URL, nil)
-	if contains(unwired.Tools(), exportToolName) {
-		t.Errorf("Tools() includes %q without wiring; got %v", exportToolName, unwired.Tools())
+	if contains(unwired.Tools(), ToolExport) {
+		t.Errorf("Tools() includes %q without wiring; got %v", ToolExport, unwired.Tools())
 	}
 
 	// And the actual MCP registration: registerExportTool with deps
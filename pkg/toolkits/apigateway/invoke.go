@@ -19,6 +19,7 @@ import (
 
 	"github.com/getkin/kin-openapi/openapi3"
 
+	"github.com/txn2/mcp-data-platform/internal/jsonpath"
 	"github.com/txn2/mcp-data-platform/pkg/mcpcontext"
 	"github.com/txn2/mcp-data-platform/pkg/observability"
 )
@@ -91,6 +92,12 @@ type InvokeInput struct {
 	Headers        map[string]string `json:"headers,omitempty"`
 	Body           any               `json:"body,omitempty"`
 	TimeoutSeconds int               `json:"timeout_seconds,omitempty"`
+	// Follow opts a GET into following the upstream's pagination within
+	// the given budgets (see FollowOptions). nil is a single request.
+	Follow *FollowOptions `json:"follow,omitempty"`
+	// Projection prunes a JSON response to these JSONPath expressions
+	// before max_response_bytes is applied (see applyProjection).
+	Projection []string `json:"projection,omitempty"`
 }
 
 // InvokeOutput is the structured result returned to the model and to
@@ -134,9 +141,19 @@ type InvokeOutput struct {
 	// Pagination is populated when the upstream response carries a
 	// recognizable cursor (RFC 5988 Link rel="next", @odata.nextLink,
 	// next_cursor, etc). The model uses this to decide whether to
-	// issue a follow-up call. The gateway does NOT auto-follow so
-	// each loop iteration stays observable in audit + conversation.
+	// issue a follow-up call. The gateway follows it only when the
+	// caller opted in with InvokeInput.Follow; a followed call reports
+	// the signal for the first page it did not fetch, nil once the
+	// upstream has no more.
 	Pagination *PaginationInfo `json:"pagination,omitempty"`
+	// Follow summarizes a follow-mode call: pages, items, and bytes
+	// collected, why it stopped, and where to resume. Body then holds
+	// the concatenated items of every page. nil for a plain call.
+	Follow *FollowSummary `json:"follow,omitempty"`
+	// UnprojectedBytes is the size of the response body before a
+	// projection pruned it, so the model knows how much it skipped.
+	// Zero when the call carried no projection.
+	UnprojectedBytes int64 `json:"unprojected_bytes,omitempty"`
 	// Hint surfaces operator-actionable advice to the model when the
 	// response itself can't carry it — most importantly the "use
 	// api_export instead" suggestion when the body exceeded
@@ -145,6 +162,11 @@ type InvokeOutput struct {
 	Hint       string `json:"hint,omitempty"`
 	DurationMs int64  `json:"duration_ms"`
 	Error      string `json:"error,omitempty"`
+
+	// bodyBytes is the size of the body as read off the wire, before
+	// decoding, or of the projected body when a projection applied.
+	// Follow mode charges it against the byte budget.
+	bodyBytes int64
 }
 
 // invocation bundles a connection lookup with its supporting types so
@@ -192,6 +214,14 @@ func invoke(ctx context.Context, inv invocation, in InvokeInput) (InvokeOutput,
 	callCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	var proj *jsonpath.Projection
+	if len(in.Projection) > 0 {
+		p, err := jsonpath.CompileProjection(in.Projection)
+		if err != nil {
+			return InvokeOutput{}, err
+		}
+		proj = &p
+	}
 	req, err := buildUpstreamRequest(callCtx, inv.cfg, inv.auth, catalogView{specs: inv.specs, webdavRoutes: inv.webdavRoutes}, in)
 	if err != nil {
 		return InvokeOutput{}, err
@@ -204,6 +234,7 @@ func invoke(ctx context.Context, inv invocation, in InvokeInput) (InvokeOutput,
 		budget:     inv.budget,
 		connection: inv.cfg.ConnectionName,
 		path:       in.Path,
+		projection: proj,
 	})
 }
 
@@ -692,7 +723,7 @@ func resolveDeclaredContentTypesInSpec(st *specState, method, path string) []str
 	if st == nil || st.doc == nil || st.doc.Paths == nil {
 		return nil
 	}
-	item := findMostSpecificPathMatch(st, path)
+	item, _ := findMostSpecificPathMatch(st, path)
 	if item == nil {
 		return nil
 	}
@@ -704,12 +735,13 @@ func resolveDeclaredContentTypesInSpec(st *specState, method, path string) []str
 }
 
 // findMostSpecificPathMatch returns the PathItem whose template
-// matches path with the fewest placeholder segments. nil when no
-// template matches. See resolveDeclaredContentTypes for the
-// motivation.
-func findMostSpecificPathMatch(st *specState, path string) *openapi3.PathItem {
+// matches path with the fewest placeholder segments, and the
+// spec-relative path it is registered under. nil when no template
+// matches. See resolveDeclaredContentTypes for the motivation.
+func findMostSpecificPathMatch(st *specState, path string) (*openapi3.PathItem, string) {
 	var (
 		bestItem  *openapi3.PathItem
+		bestRaw   string
 		bestHoles int
 	)
 	for rawPath, item := range st.doc.Paths.Map() {
@@ -722,11 +754,10 @@ func findMostSpecificPathMatch(st *specState, path string) *openapi3.PathItem {
 		}
 		holes := countTemplatePlaceholders(template)
 		if bestItem == nil || holes < bestHoles {
-			bestItem = item
-			bestHoles = holes
+			bestItem, bestRaw, bestHoles = item, rawPath, holes
 		}
 	}
-	return bestItem
+	return bestItem, bestRaw
 }
 
 // operationForMethod returns the Operation registered on item for the
@@ -1037,8 +1068,16 @@ type execParams struct {
 	budget     *MemBudget
 	connection string
 	path       string
+	// projection, when set, raises the read cap to projectionReadFactor
+	// times maxBytes and applies maxBytes to the projected body instead.
+	projection *jsonpath.Projection
 }
 
+// projectionReadFactor is how much larger than max_response_bytes a raw
+// body may be when the call projects it, bounding the buffer a
+// projection can make the gateway hold.
+const projectionReadFactor = 8
+
 // executeRequest performs the upstream call and buffers the response.
 // The returned error is non-nil only for a pre-buffer refusal the caller
 // renders as a structured tool error: a *budgetError (in-flight memory
@@ -1094,6 +1133,10 @@ func executeRequest(p execParams) (InvokeOutput, error) {
 	if readCap <= 0 {
 		readCap = DefaultMaxResponseBytes
 	}
+	limit := readCap
+	if p.projection != nil {
+		readCap *= projectionReadFactor
+	}
 	reserved, ok := reserveBodyBudget(p.budget, resp.ContentLength, readCap)
 	if !ok {
 		slog.Warn("apigateway: rejecting buffered request, in-flight memory budget exhausted",
@@ -1109,7 +1152,7 @@ func executeRequest(p execParams) (InvokeOutput, error) {
 	}
 	defer p.budget.Release(reserved)
 
-	body, truncated, readErr := readBody(resp.Body, p.maxBytes)
+	body, truncated, readErr := readBody(resp.Body, readCap)
 	if readErr != nil {
 		return InvokeOutput{
 			Status:     resp.StatusCode,
@@ -1126,8 +1169,12 @@ func executeRequest(p execParams) (InvokeOutput, error) {
 		BodyTruncated: truncated,
 		Pagination:    detectPagination(resp.Header, parsed),
 		DurationMs:    time.Since(start).Milliseconds(),
+		bodyBytes:     int64(len(body)),
 	}
-	if truncated {
+	if p.projection != nil {
+		applyProjection(&out, *p.projection, body, limit)
+	}
+	if out.BodyTruncated && out.Hint == "" {
 		// The body exceeded the connection's max_response_bytes
 		// cap. Steer the model toward api_export, which streams
 		// the response directly into a portal asset without
@@ -1138,6 +1185,34 @@ func executeRequest(p execParams) (InvokeOutput, error) {
 	return out, nil
 }
 
+// applyProjection prunes a decoded JSON body to the projection and
+// enforces max_response_bytes on the result rather than on the raw
+// body, recording the raw size in UnprojectedBytes. A body that is not
+// a complete JSON document cannot be projected and falls back to the
+// plain cap.
+func applyProjection(out *InvokeOutput, proj jsonpath.Projection, raw []byte, limit int64) {
+	out.UnprojectedBytes = int64(len(raw))
+	if _, isText := out.Body.(string); isText || out.BodyTruncated || out.Body == nil {
+		if int64(len(raw)) > limit {
+			out.Body, out.BodyTruncated = string(raw[:limit]), true
+		}
+		if !out.BodyTruncated && out.Body != nil {
+			out.Hint = "projection not applied: the response is not JSON"
+		}
+		return
+	}
+	projected, _ := proj.Apply(out.Body)
+	b, err := json.Marshal(projected)
+	if err != nil {
+		return
+	}
+	out.Body, out.bodyBytes = projected, int64(len(b))
+	if int64(len(b)) > limit {
+		out.Body, out.BodyTruncated = string(b[:limit]), true
+		out.Hint = "projected response exceeded max_response_bytes; narrow the projection or use api_export to stream the full response into a portal asset"
+	}
+}
+
 func readBody(r io.Reader, maxBytes int64) (body []byte, truncated bool, err error) {
 	if maxBytes <= 0 {
 		maxBytes = DefaultMaxResponseBytes
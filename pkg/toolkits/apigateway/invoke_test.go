@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -907,3 +908,52 @@ func TestInvoke_TruncatesLargeResponse(t *testing.T) {
 		t.Errorf("body length = %d; want 100", len(s))
 	}
 }
+
+func TestInvoke_ProjectionAppliesBeforeCap(t *testing.T) {
+	var items []string
+	for i := range 20 {
+		items = append(items, fmt.Sprintf(`{"id":%d,"blob":%q}`, i, strings.Repeat("z", 40)))
+	}
+	doc := `{"total":20,"items":[` + strings.Join(items, ",") + `]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, doc)
+	}))
+	defer srv.Close()
+
+	cfg := Config{BaseURL: srv.URL, AuthMode: AuthModeNone, ConnectTimeout: time.Second, CallTimeout: 2 * time.Second, MaxResponseBytes: 300}
+	auth, _ := NewAuthenticator(cfg)
+	inv := invocation{cfg: cfg, auth: auth, client: newHTTPClient(cfg)}
+	call := func(projection ...string) InvokeOutput {
+		t.Helper()
+		out, err := invoke(context.Background(), inv, InvokeInput{Connection: "x", Method: "GET", Path: "/", Projection: projection})
+		if err != nil {
+			t.Fatalf("invoke: %v", err)
+		}
+		return out
+	}
+
+	out := call("$.items[*].id", "$.total")
+	if out.BodyTruncated || out.UnprojectedBytes != int64(len(doc)) {
+		t.Fatalf("truncated=%v unprojected=%d, want whole body and %d", out.BodyTruncated, out.UnprojectedBytes, len(doc))
+	}
+	body, _ := out.Body.(map[string]any)
+	if list, _ := body["items"].([]any); len(list) != 20 || body["total"] != float64(20) {
+		t.Errorf("projected body = %v", out.Body)
+	}
+	if _, ok := body["items"].([]any)[0].(map[string]any)["blob"]; ok {
+		t.Error("projection kept an unselected field")
+	}
+
+	out = call("$.items")
+	if !out.BodyTruncated || !strings.Contains(out.Hint, "narrow the projection") {
+		t.Errorf("over-cap projection: truncated=%v hint=%q", out.BodyTruncated, out.Hint)
+	}
+	if s, _ := out.Body.(string); len(s) != 300 {
+		t.Errorf("over-cap projection body length = %d, want 300", len(s))
+	}
+
+	if _, err := invoke(context.Background(), inv, InvokeInput{Connection: "x", Method: "GET", Path: "/", Projection: []string{"items"}}); err == nil {
+		t.Error("invalid projection path was accepted")
+	}
+}
@@ -2,8 +2,10 @@ package apigateway
 
 import (
 	"context"
+	"maps"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -153,12 +155,17 @@ func buildOperationRouter(specs map[string]*specState) (router routers.Router, r
 	paths := openapi3.NewPaths()
 	rawByKey = make(map[string]string)
 	count := 0
-	for _, st := range specs {
+	for _, name := range specNamesInOrder(specs) {
+		st := specs[name]
 		if st == nil || st.doc == nil || st.doc.Paths == nil {
 			continue
 		}
 		for rawPath, item := range st.doc.Paths.Map() {
 			key := st.effectiveBasePath + rawPath
+			if _, taken := rawByKey[key]; taken {
+				// An earlier spec already declares this path; it keeps it.
+				continue
+			}
 			paths.Set(key, item)
 			rawByKey[key] = rawPath
 			count++
@@ -181,6 +188,15 @@ func buildOperationRouter(specs map[string]*specState) (router routers.Router, r
 	return router, rawByKey
 }
 
+// specNamesInOrder returns the connection's spec names in the order
+// operation resolution consults them: sorted by name, the first spec to
+// declare a path owning it. A map range would hand a path two specs share
+// to a different one from call to call, and with it the operation a call
+// is labeled, checked, and reported against.
+func specNamesInOrder(specs map[string]*specState) []string {
+	return slices.Sorted(maps.Keys(specs))
+}
+
 // ensureLeadingSlash normalizes a runtime path so the router (which
 // matches absolute paths) sees a leading slash. An empty path becomes
 // "/".
@@ -9,9 +9,11 @@ import (
 
 // PaginationInfo is the structured pagination state api_invoke_endpoint
 // surfaces to the model on every response. The model uses HasMore +
-// NextCursor (or NextURL) to decide whether to issue a follow-up call;
-// the gateway does NOT auto-follow so each loop iteration stays
-// observable in the conversation and audit log.
+// NextCursor (or NextURL) to decide whether to issue a follow-up call.
+// By default the gateway does not follow it, so each loop iteration
+// stays observable in the conversation; a caller that wants the whole
+// list in one call opts in with InvokeInput.Follow (followPages), and
+// each followed page is still audited on its own.
 //
 // Fields are populated only when the upstream response carries a
 // recognizable pagination signal. When none are populated the field
@@ -0,0 +1,422 @@
+package apigateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/pkg/observability"
+)
+
+// defaultFollowMaxPages is the page budget when a caller opts into
+// follow mode without naming one. maxFollowPages is the ceiling a
+// caller-supplied budget is clamped to, so one tool call cannot turn
+// into an unbounded crawl of the upstream.
+const (
+	defaultFollowMaxPages = 10
+	maxFollowPages        = 50
+)
+
+// Stop reasons reported in FollowSummary.StoppedBy. Every value other
+// than followComplete means more pages exist upstream; the summary's
+// resume fields say how to fetch the next one when that is possible.
+const (
+	followComplete     = "complete"
+	followMaxPages     = "max_pages"
+	followMaxItems     = "max_items"
+	followMaxBytes     = "max_bytes"
+	followUnfollowable = "unfollowable"
+	followPageError    = "page_error"
+)
+
+// FollowOptions opts an api_invoke_endpoint GET into following the
+// upstream's pagination. The gateway walks the same signals
+// detectPagination reports (Link rel="next", @odata.nextLink, the body
+// cursor fields), concatenates each page's item array, and stops at the
+// first budget reached. Zero values take the defaults: MaxPages
+// defaultFollowMaxPages, MaxItems unlimited, MaxBytes the connection's
+// max_response_bytes, which is also its ceiling so a followed call
+// never holds more response data than a single buffered call may.
+//
+// Budgets are checked between pages and a fetched page is never split:
+// the page that crosses max_items is kept whole so the resume point
+// stays exact, and a page that would cross max_bytes is dropped and
+// becomes the resume point instead.
+type FollowOptions struct {
+	MaxPages int   `json:"max_pages,omitempty"`
+	MaxItems int   `json:"max_items,omitempty"`
+	MaxBytes int64 `json:"max_bytes,omitempty"`
+	// CursorParam names the query parameter a body cursor is sent back
+	// in. Needed only when the cursor field does not imply one (the
+	// generic "next" field); see cursorParamForSource.
+	CursorParam string `json:"cursor_param,omitempty"`
+}
+
+// FollowSummary reports how a followed call went. When StoppedBy is not
+// "complete" and the next page is reachable, ResumePath and
+// ResumeQuery are the GET that fetches it: pass them back as path and
+// query_params (with follow again, if wanted) to continue where this
+// call stopped.
+type FollowSummary struct {
+	Pages int   `json:"pages"`
+	Items int   `json:"items"`
+	Bytes int64 `json:"bytes"`
+	// ItemsKey is the body field the items were read from on each page.
+	// Empty when the upstream returns a bare JSON array.
+	ItemsKey    string         `json:"items_key,omitempty"`
+	StoppedBy   string         `json:"stopped_by"`
+	Detail      string         `json:"detail,omitempty"`
+	ResumePath  string         `json:"resume_path,omitempty"`
+	ResumeQuery map[string]any `json:"resume_query_params,omitempty"`
+}
+
+// pageItemKeys are the body fields recognized as a page's item array,
+// checked in order before falling back to the body's only array field.
+// "value" leads because it is OData's fixed collection name.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var pageItemKeys = []string{"value", "items", "data", "results", "records", "entries", "elements"}
+
+// cursorParamForSource maps a body cursor field to the query parameter
+// the same API family takes it back in. The generic "next" field has no
+// entry: its value's parameter name varies per API, so following it
+// needs FollowOptions.CursorParam.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var cursorParamForSource = map[string]string{
+	"body:next_cursor":     "cursor",
+	"body:nextCursor":      "cursor",
+	"body:next_page_token": "page_token",
+	"body:nextPageToken":   "pageToken",
+}
+
+// followRefusal returns why a follow-mode call cannot run, or "" when
+// it can. Only GET is followed: a paginated POST search would resend
+// its body with every page, and the gateway does not guess whether
+// that is safe. Raw passthrough streams one response straight to the
+// REST client, so there is nothing to concatenate into.
+func followRefusal(ctx context.Context, in InvokeInput) string {
+	if !strings.EqualFold(in.Method, http.MethodGet) {
+		return "follow is only supported for GET requests"
+	}
+	if rawPassthroughFromContext(ctx) != nil {
+		return "follow is not supported with raw passthrough"
+	}
+	return ""
+}
+
+// limits fills FollowOptions defaults and clamps the caller's budgets
+// to the ceilings described on the type.
+func (o FollowOptions) limits(maxResponseBytes int64) FollowOptions {
+	if o.MaxPages <= 0 {
+		o.MaxPages = defaultFollowMaxPages
+	}
+	o.MaxPages = min(o.MaxPages, maxFollowPages)
+	o.MaxItems = max(o.MaxItems, 0)
+	ceiling := maxResponseBytes
+	if ceiling <= 0 {
+		ceiling = DefaultMaxResponseBytes
+	}
+	if o.MaxBytes <= 0 || o.MaxBytes > ceiling {
+		o.MaxBytes = ceiling
+	}
+	return o
+}
+
+// followCall bundles what a follow-mode call needs beyond its input:
+// the connection's invocation, and the route policy every followed page
+// is re-checked against (a next link may name a path the first request
+// did not).
+type followCall struct {
+	inv    invocation
+	policy RoutePolicy
+}
+
+// followState accumulates a follow-mode call across pages.
+type followState struct {
+	opts    FollowOptions
+	summary FollowSummary
+	items   []any
+	pages   []observability.AuditPage
+}
+
+// followPages runs a follow-mode call. The first page is fetched and
+// reported exactly as a plain call would be; when it succeeded and
+// carries an item array, each following page is fetched, audited as an
+// observability.AuditPage, and appended until the upstream runs out of
+// pages or a budget stops the walk. The returned error is reserved for
+// the first page's argument and pre-buffer refusals, matching invoke.
+func followPages(ctx context.Context, fc followCall, in InvokeInput) (InvokeOutput, []observability.AuditPage, error) {
+	start := time.Now()
+	st := &followState{opts: in.Follow.limits(fc.inv.cfg.MaxResponseBytes)}
+	out, err := invoke(ctx, st.pageInvocation(fc.inv), in)
+	if err != nil {
+		return InvokeOutput{}, nil, err
+	}
+	st.summary.Pages = 1
+	st.summary.Bytes = out.bodyBytes
+	switch {
+	case out.Status < http.StatusOK || out.Status >= http.StatusMultipleChoices:
+		st.summary.StoppedBy = followPageError
+	case out.BodyTruncated:
+		st.summary.StoppedBy = followMaxBytes
+	}
+	key, items, ok := pageItems(out.Body)
+	if st.summary.StoppedBy == "" && !ok {
+		st.summary.StoppedBy = followUnfollowable
+		st.summary.Detail = "response has no item array to concatenate"
+	}
+	if st.summary.StoppedBy != "" {
+		out.Follow = &st.summary
+		return out, nil, nil
+	}
+	st.summary.ItemsKey = key
+	st.add(items)
+
+	out.Pagination = st.walk(ctx, fc, in, out.Pagination)
+	out.Body = st.items
+	out.Follow = &st.summary
+	out.DurationMs = time.Since(start).Milliseconds()
+	return out, st.pages, nil
+}
+
+// walk fetches pages after the first until one of the stop conditions
+// holds, and returns the pagination signal for the first page not
+// fetched (nil once the upstream has no more).
+func (st *followState) walk(ctx context.Context, fc followCall, prev InvokeInput, next *PaginationInfo) *PaginationInfo {
+	for next != nil {
+		pageIn, err := nextPageInput(fc.inv.cfg.BaseURL, prev, next, st.opts.CursorParam)
+		if err != nil {
+			st.stop(followUnfollowable, err.Error(), nil)
+			return next
+		}
+		if reason := st.budgetReached(); reason != "" {
+			st.stop(reason, "", &pageIn)
+			return next
+		}
+		if denied := checkRoutePolicy(ctx, fc.policy, pageIn); denied != nil {
+			st.stop(followUnfollowable, "route policy denies the next page "+pageIn.Path, nil)
+			return next
+		}
+		pageStart := time.Now()
+		out, err := invoke(ctx, st.pageInvocation(fc.inv), pageIn)
+		st.audit(pageIn, pageStart, out, err)
+		if reason, detail := pageStopReason(out, err); reason != "" {
+			st.stop(reason, detail, &pageIn)
+			return next
+		}
+		items, ok := pageItemsAt(out.Body, st.summary.ItemsKey)
+		if !ok {
+			st.stop(followUnfollowable, fmt.Sprintf("page %d has no %q item array", st.summary.Pages+1, st.summary.ItemsKey), &pageIn)
+			return next
+		}
+		st.summary.Pages++
+		st.summary.Bytes += out.bodyBytes
+		st.add(items)
+		prev, next = pageIn, out.Pagination
+	}
+	st.summary.StoppedBy = followComplete
+	return nil
+}
+
+// pageInvocation scopes one page's read cap to what is left of the
+// byte budget, so a page that would cross it comes back truncated and
+// is dropped rather than buffered in full.
+func (st *followState) pageInvocation(inv invocation) invocation {
+	inv.cfg.MaxResponseBytes = st.opts.MaxBytes - st.summary.Bytes
+	return inv
+}
+
+func (st *followState) add(items []any) {
+	st.items = append(st.items, items...)
+	st.summary.Items = len(st.items)
+}
+
+// budgetReached names the first budget the pages so far have reached,
+// or "" when another page may be fetched.
+func (st *followState) budgetReached() string {
+	switch {
+	case st.summary.Pages >= st.opts.MaxPages:
+		return followMaxPages
+	case st.opts.MaxItems > 0 && st.summary.Items >= st.opts.MaxItems:
+		return followMaxItems
+	case st.summary.Bytes >= st.opts.MaxBytes:
+		return followMaxBytes
+	}
+	return ""
+}
+
+// stop records why the walk ended and, when the next page is reachable,
+// the request that fetches it.
+func (st *followState) stop(reason, detail string, resume *InvokeInput) {
+	st.summary.StoppedBy = reason
+	st.summary.Detail = detail
+	if resume != nil {
+		st.summary.ResumePath = resume.Path
+		st.summary.ResumeQuery = resume.Query
+	}
+}
+
+// audit records one followed page for the audit middleware. The
+// parameters are the ones the page would have carried as a call of its
+// own, plus its position in the walk.
+func (st *followState) audit(in InvokeInput, start time.Time, out InvokeOutput, err error) {
+	page := observability.AuditPage{
+		StartedAt:  start,
+		DurationMS: time.Since(start).Milliseconds(),
+		Parameters: map[string]any{
+			"connection":   in.Connection,
+			"method":       in.Method,
+			"path":         in.Path,
+			"query_params": in.Query,
+			"follow_page":  st.summary.Pages + 1,
+		},
+	}
+	if err != nil {
+		page.Outcome = observability.OutcomeTransportErr
+		page.Message = err.Error()
+	} else {
+		page.Outcome = ClassifyInvokeOutcome(out)
+		page.Message = auditOutcomeMessage(out)
+	}
+	st.pages = append(st.pages, page)
+}
+
+// pageStopReason reports whether a fetched page ends the walk: a
+// pre-buffer refusal, a transport failure or non-2xx status, or a body
+// cut off by the remaining byte budget.
+func pageStopReason(out InvokeOutput, err error) (reason, detail string) {
+	switch {
+	case err != nil:
+		return followPageError, err.Error()
+	case out.Status == 0:
+		return followPageError, out.Error
+	case out.Status < http.StatusOK || out.Status >= http.StatusMultipleChoices:
+		return followPageError, fmt.Sprintf("upstream returned status %d", out.Status)
+	case out.BodyTruncated:
+		return followMaxBytes, ""
+	}
+	return "", ""
+}
+
+// nextPageInput builds the request for the page a pagination signal
+// points at. A next URL is re-expressed as a path and query against the
+// connection's base_url, so it goes back through buildUpstreamRequest's
+// host pinning and path validation like any caller-supplied path; a
+// body cursor is set on the previous request's query.
+func nextPageInput(baseURL string, prev InvokeInput, p *PaginationInfo, cursorParam string) (InvokeInput, error) {
+	next := prev
+	if p.NextURL != "" {
+		path, query, err := relativeToBase(baseURL, prev.Path, p.NextURL)
+		if err != nil {
+			return InvokeInput{}, err
+		}
+		next.Path, next.Query = path, query
+		return next, nil
+	}
+	if p.NextCursor == "" || p.NextCursor == "true" {
+		return InvokeInput{}, errors.New("upstream signals more pages without a cursor value")
+	}
+	param := cursorParam
+	if param == "" {
+		param = cursorParamForSource[p.Source]
+	}
+	if param == "" {
+		return InvokeInput{}, fmt.Errorf("cursor from %s has no known query parameter; set follow.cursor_param", p.Source)
+	}
+	next.Query = maps.Clone(prev.Query)
+	if next.Query == nil {
+		next.Query = map[string]any{}
+	}
+	next.Query[param] = p.NextCursor
+	return next, nil
+}
+
+// relativeToBase resolves a next link (absolute, or relative to the
+// previous request) and splits it into the path under base_url and its
+// query. A link that leaves the connection's scheme, host, or base path
+// is refused: following it would send the connection's credential
+// somewhere the operator did not configure.
+func relativeToBase(baseURL, prevPath, link string) (string, map[string]any, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", nil, fmt.Errorf("apigateway: parsing base_url: %w", err)
+	}
+	ref, err := url.Parse(link)
+	if err != nil {
+		return "", nil, fmt.Errorf("next link %q does not parse: %w", link, err)
+	}
+	u := base.JoinPath(prevPath).ResolveReference(ref)
+	if u.Scheme != base.Scheme || u.Host != base.Host {
+		return "", nil, fmt.Errorf("next link host %q is not the connection's host; refusing to follow", u.Host)
+	}
+	prefix := strings.TrimSuffix(base.Path, "/")
+	if prefix != "" && u.Path != prefix && !strings.HasPrefix(u.Path, prefix+"/") {
+		return "", nil, fmt.Errorf("next link path %q is outside the connection's base path", u.Path)
+	}
+	path := strings.TrimPrefix(u.Path, prefix)
+	if path == "" {
+		path = "/"
+	}
+	query := map[string]any{}
+	for k, vs := range u.Query() {
+		if len(vs) == 1 {
+			query[k] = vs[0]
+			continue
+		}
+		all := make([]any, len(vs))
+		for i, v := range vs {
+			all[i] = v
+		}
+		query[k] = all
+	}
+	return path, query, nil
+}
+
+// pageItems locates the item array of a first page: the body itself
+// when it is an array, else the first pageItemKeys field holding one,
+// else the body's only array-valued field. The returned key is how
+// later pages are read (pageItemsAt).
+func pageItems(body any) (key string, items []any, ok bool) {
+	switch b := body.(type) {
+	case []any:
+		return "", b, true
+	case map[string]any:
+		for _, k := range pageItemKeys {
+			if arr, isArr := b[k].([]any); isArr {
+				return k, arr, true
+			}
+		}
+		for k, v := range b {
+			arr, isArr := v.([]any)
+			if !isArr {
+				continue
+			}
+			if ok {
+				return "", nil, false
+			}
+			key, items, ok = k, arr, true
+		}
+	}
+	return key, items, ok
+}
+
+// pageItemsAt reads a later page's items from the field the first page
+// used. An empty key means the page body is the array.
+func pageItemsAt(body any, key string) ([]any, bool) {
+	if key == "" {
+		arr, ok := body.([]any)
+		return arr, ok
+	}
+	obj, ok := body.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	arr, ok := obj[key].([]any)
+	return arr, ok
+}
@@ -0,0 +1,200 @@
+package apigateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/txn2/mcp-data-platform/pkg/observability"
+)
+
+// pagedServer serves /base/items as three pages of two items each. The
+// mode picks how the next page is signaled: "link" via a relative Link
+// header, "cursor" via a next_cursor body field, "odata" via an absolute
+// @odata.nextLink under a "value" array.
+func pagedServer(t *testing.T, mode string) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/base/items" {
+			http.NotFound(w, r)
+			return
+		}
+		page := 1
+		if c := r.URL.Query().Get("cursor"); c != "" {
+			_, _ = fmt.Sscanf(c, "p%d", &page)
+		}
+		if r.URL.Query().Get("tenant") != "acme" {
+			t.Errorf("page %d lost the caller's query: %s", page, r.URL.RawQuery)
+		}
+		items := fmt.Sprintf(`[{"n":%d},{"n":%d}]`, page*2-1, page*2)
+		next := ""
+		if page < 3 {
+			next = fmt.Sprintf("p%d", page+1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch mode {
+		case "link":
+			if next != "" {
+				w.Header().Set("Link", fmt.Sprintf(`<items?tenant=acme&cursor=%s>; rel="next"`, next))
+			}
+			_, _ = io.WriteString(w, items)
+		case "cursor":
+			_, _ = fmt.Fprintf(w, `{"data":%s,"next_cursor":%q}`, items, next)
+		case "odata":
+			link := ""
+			if next != "" {
+				link = fmt.Sprintf(`,"@odata.nextLink":"%s/base/items?tenant=acme&cursor=%s"`, srv.URL, next)
+			}
+			_, _ = fmt.Fprintf(w, `{"value":%s%s}`, items, link)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func followToolkit(t *testing.T, srv *httptest.Server) *Toolkit {
+	t.Helper()
+	tk := New("test")
+	if err := tk.AddConnection("c1", map[string]any{"base_url": srv.URL + "/base"}); err != nil {
+		t.Fatalf("AddConnection: %v", err)
+	}
+	return tk
+}
+
+func followInvoke(t *testing.T, tk *Toolkit, follow *FollowOptions) (InvokeOutput, []observability.AuditPage) {
+	t.Helper()
+	res, out, err := tk.handleInvoke(context.Background(), nil, InvokeInput{
+		Connection: "c1", Method: "GET", Path: "/items",
+		Query:  map[string]any{"tenant": "acme"},
+		Follow: follow,
+	})
+	if err != nil {
+		t.Fatalf("handleInvoke: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("IsError=true: %s", textContent(res))
+	}
+	pages, _ := res.Meta[observability.MetaAuditPages].([]observability.AuditPage)
+	return out.(InvokeOutput), pages
+}
+
+func TestFollow_ConcatenatesEverySignal(t *testing.T) {
+	for _, mode := range []string{"link", "cursor", "odata"} {
+		t.Run(mode, func(t *testing.T) {
+			out, pages := followInvoke(t, followToolkit(t, pagedServer(t, mode)), &FollowOptions{})
+			items, ok := out.Body.([]any)
+			if !ok || len(items) != 6 {
+				t.Fatalf("body = %#v, want 6 concatenated items", out.Body)
+			}
+			if out.Follow.StoppedBy != followComplete || out.Follow.Pages != 3 || out.Follow.Items != 6 {
+				t.Errorf("summary = %+v", out.Follow)
+			}
+			if out.Pagination != nil {
+				t.Errorf("complete walk still reports pagination %+v", out.Pagination)
+			}
+			if len(pages) != 2 || pages[1].Parameters["follow_page"] != 3 || pages[1].Outcome != observability.OutcomeOK {
+				t.Errorf("audit pages = %+v", pages)
+			}
+		})
+	}
+}
+
+func TestFollow_BudgetsStopWithResume(t *testing.T) {
+	cases := []struct {
+		name   string
+		follow FollowOptions
+		want   string
+		items  int
+	}{
+		{"max pages", FollowOptions{MaxPages: 2}, followMaxPages, 4},
+		{"max items keeps the crossing page whole", FollowOptions{MaxItems: 3}, followMaxItems, 4},
+		{"max bytes drops the crossing page", FollowOptions{MaxBytes: 60}, followMaxBytes, 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, _ := followInvoke(t, followToolkit(t, pagedServer(t, "cursor")), &tc.follow)
+			if out.Follow.StoppedBy != tc.want || out.Follow.Items != tc.items {
+				t.Fatalf("summary = %+v, want %s with %d items", out.Follow, tc.want, tc.items)
+			}
+			next := fmt.Sprintf("p%d", tc.items/2+1)
+			if out.Follow.ResumePath != "/items" || out.Follow.ResumeQuery["cursor"] != next || out.Follow.ResumeQuery["tenant"] != "acme" {
+				t.Errorf("resume = %s %v, want cursor %s", out.Follow.ResumePath, out.Follow.ResumeQuery, next)
+			}
+			if out.Pagination == nil || out.Pagination.NextCursor != next {
+				t.Errorf("pagination = %+v", out.Pagination)
+			}
+		})
+	}
+}
+
+func TestFollow_Refusals(t *testing.T) {
+	tk := followToolkit(t, pagedServer(t, "cursor"))
+	res, _, err := tk.handleInvoke(context.Background(), nil, InvokeInput{
+		Connection: "c1", Method: "POST", Path: "/items", Follow: &FollowOptions{},
+	})
+	if err != nil || !res.IsError || !strings.Contains(textContent(res), "only supported for GET") {
+		t.Errorf("POST follow = %s, %v", textContent(res), err)
+	}
+}
+
+func TestRelativeToBase(t *testing.T) {
+	cases := []struct {
+		link, wantPath, wantErr string
+	}{
+		{"https://api.example.com/v2/items?page=2", "/items", ""},
+		{"?page=2", "/items", ""},
+		{"/v2/other?page=2", "/other", ""},
+		{"https://evil.example.com/v2/items?page=2", "", "not the connection's host"},
+		{"http://api.example.com/v2/items?page=2", "", "not the connection's host"},
+		{"/v1/items?page=2", "", "outside the connection's base path"},
+	}
+	for _, tc := range cases {
+		path, query, err := relativeToBase("https://api.example.com/v2", "/items", tc.link)
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("%s: err = %v, want %q", tc.link, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil || path != tc.wantPath || query["page"] != "2" {
+			t.Errorf("%s: got %q %v %v", tc.link, path, query, err)
+		}
+	}
+}
+
+func TestNextPageInput_CursorParam(t *testing.T) {
+	prev := InvokeInput{Path: "/items", Query: map[string]any{"limit": 10}}
+	generic := &PaginationInfo{HasMore: true, NextCursor: "abc", Source: "body:next"}
+	if _, err := nextPageInput("https://x", prev, generic, ""); err == nil || !strings.Contains(err.Error(), "cursor_param") {
+		t.Errorf("generic cursor without cursor_param: err = %v", err)
+	}
+	next, err := nextPageInput("https://x", prev, generic, "after")
+	if err != nil || next.Query["after"] != "abc" || next.Query["limit"] != 10 {
+		t.Errorf("next = %+v, %v", next.Query, err)
+	}
+	if _, ok := prev.Query["after"]; ok {
+		t.Error("previous request's query was mutated")
+	}
+	google := &PaginationInfo{HasMore: true, NextCursor: "tok", Source: "body:nextPageToken"}
+	if next, _ := nextPageInput("https://x", prev, google, ""); next.Query["pageToken"] != "tok" {
+		t.Errorf("google cursor = %+v", next.Query)
+	}
+}
+
+func TestPageItems(t *testing.T) {
+	key, items, ok := pageItems(map[string]any{"meta": map[string]any{}, "rows": []any{1, 2}})
+	if !ok || key != "rows" || len(items) != 2 {
+		t.Errorf("single array field = %q %v %v", key, items, ok)
+	}
+	if _, _, ok := pageItems(map[string]any{"a": []any{}, "b": []any{}}); ok {
+		t.Error("two unnamed arrays must be ambiguous")
+	}
+	if key, _, _ := pageItems(map[string]any{"value": []any{}, "other": []any{}}); key != "value" {
+		t.Errorf("known key = %q", key)
+	}
+}
@@ -219,6 +219,40 @@ var invokeEndpointSchema = json.RawMessage(`{
       "minimum": 1,
       "maximum": 600,
       "description": "Optional per-call timeout override in seconds. Capped to 600 (10 minutes). Defaults to the connection's call_timeout."
+    },
+    "follow": {
+      "type": "object",
+      "additionalProperties": false,
+      "description": "Opt in to following the upstream's pagination (Link rel=next, @odata.nextLink, or a body cursor) on a GET. The gateway fetches pages until the upstream runs out or a budget is reached, returns the concatenated item arrays as body, and reports pages, items, bytes, stopped_by, and resume_path / resume_query_params for continuing. Pass {} to use the defaults. The timeout applies to each page.",
+      "properties": {
+        "max_pages": {
+          "type": "integer",
+          "minimum": 1,
+          "maximum": 50,
+          "description": "Most pages to fetch, including the first. Default 10."
+        },
+        "max_items": {
+          "type": "integer",
+          "minimum": 1,
+          "description": "Stop once this many items are collected. The page that crosses it is kept whole. Default unlimited."
+        },
+        "max_bytes": {
+          "type": "integer",
+          "minimum": 1,
+          "description": "Stop before the total response bytes would exceed this. Default and ceiling: the connection's max_response_bytes."
+        },
+        "cursor_param": {
+          "type": "string",
+          "description": "Query parameter to send a body cursor back in. Only needed for a generic \"next\" cursor field; next_cursor, nextCursor, next_page_token, and nextPageToken map to cursor, cursor, page_token, and pageToken."
+        }
+      }
+    },
+    "projection": {
+      "type": "array",
+      "minItems": 1,
+      "maxItems": 32,
+      "items": {"type": "string"},
+      "description": "JSONPath expressions selecting the parts of a JSON response to keep, for example [\"$.items[*].id\", \"$.items[*].name\", \"$.total\"]. Supports $, .field, [n], and the [*] array wildcard. The response is pruned server-side, keeping its nesting, before max_response_bytes is applied, so a large response can come back whole once projected; unprojected_bytes reports the size before pruning. Paths that match nothing are skipped. Ignored for non-JSON responses."
     }
   }
 }`)
@@ -29,7 +29,7 @@ func strictSchemaCases() []strictSchemaCase {
 		{ToolListEndpoints, listEndpointsSchema, ListEndpointsInput{}},
 		{ToolListSpecs, listSpecsSchema, ListSpecsInput{}},
 		{ToolGetEndpointSchema, getEndpointSchemaInputSchema, GetEndpointSchemaInput{}},
-		{exportToolName, apiExportInputSchema, exportInput{}},
+		{ToolExport, apiExportInputSchema, exportInput{}},
 	}
 }
 
@@ -188,7 +188,7 @@ func TestAPITools_RejectUnknownArgumentByName(t *testing.T) {
 		{ToolGetEndpointSchema, map[string]any{
 			"connection": "crm", "operation_id": "getThings", "parameters": "x",
 		}},
-		{exportToolName, map[string]any{
+		{ToolExport, map[string]any{
 			"connection": "crm", "name": "things", "method": "GET", "path": "/v1/things",
 			"parameters": map[string]any{"limit": 1},
 		}},
@@ -276,6 +276,8 @@ func TestAPITools_ValidArgumentsUnaffected(t *testing.T) {
 		"body": map[string]any{"k": "v"}, "timeout_seconds": 5,
 		"name": "things", "description": "d", "tags": []any{"t"},
 		"idempotency_key": "k1", "create_public_link": false,
+		"follow":     map[string]any{"max_pages": 2},
+		"projection": []any{"$.items[*].id"},
 	}
 
 	for _, tc := range strictSchemaCases() {
@@ -194,6 +194,15 @@ func buildTLSConfig(c Config) (*tls.Config, error) {
 	return out, nil
 }
 
+// TLSClientConfig exposes buildTLSConfig to sibling gateway toolkits
+// (pkg/toolkits/grpcgateway) that reuse this package's mTLS and CA
+// bundle keys for a non-HTTP transport. A nil config with a nil error
+// means the connection carries no TLS material and the caller should
+// use its transport's defaults.
+func TLSClientConfig(c Config) (*tls.Config, error) {
+	return buildTLSConfig(c)
+}
+
 // rootPoolWithBundle appends the operator's CA bundle to the system
 // root pool. Substituting (rather than appending) would silently
 // break upstreams that legitimately use public CAs alongside the
@@ -90,6 +90,11 @@ type Toolkit struct {
 	// leaves an endpoint's schema exactly as its spec declares it.
 	exampleStore catalog.ExampleStore
 
+	// driftStore records responses that did not match their operation on
+	// connections with contract validation on. nil still counts checks in
+	// the metrics; it only leaves the catalog's drift report empty.
+	driftStore catalog.DriftStore
+
 	// exportDeps holds platform-side dependencies for api_export
 	// (nil = export disabled, tool not registered).
 	exportDeps *ExportDeps
@@ -143,6 +148,13 @@ func (t *Toolkit) SetCatalogStore(s catalog.Store) {
 	t.catalogStore = s
 }
 
+// SetDriftStore wires the store that contract validation records drift in.
+func (t *Toolkit) SetDriftStore(s catalog.DriftStore) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.driftStore = s
+}
+
 // SetExampleStore wires the store of promoted endpoint examples, so reading an
 // endpoint's schema also shows the requests that are known to have worked
 // against this connection (#1321).
@@ -458,7 +470,11 @@ func (t *Toolkit) RegisterTools(s *mcp.Server) {
 			"Method is restricted to GET, POST, PUT, DELETE, PATCH, HEAD, " +
 			"PROPFIND, MKCOL, MOVE, COPY; " +
 			"path is joined to the connection's base_url; response bodies above the connection's " +
-			"max_response_bytes are truncated and flagged. Use list_connections to discover " +
+			"max_response_bytes are truncated and flagged. For a paginated GET, set follow to " +
+			"collect every page's items in one call within page, item, and byte budgets. " +
+			"Set projection to JSONPath expressions to return only the fields you need from a " +
+			"large JSON response. " +
+			"Use list_connections to discover " +
 			"available kind=api connections. " + toolkit.CaptureRoute,
 		InputSchema: invokeEndpointSchema,
 	}, t.handleInvoke)
@@ -522,7 +538,7 @@ func (t *Toolkit) Tools() []string {
 	hasExport := t.exportDeps != nil
 	t.mu.RUnlock()
 	if hasExport {
-		tools = append(tools, exportToolName)
+		tools = append(tools, ToolExport)
 	}
 	return tools
 }
@@ -1175,6 +1191,14 @@ func (t *Toolkit) handleInvoke(ctx context.Context, _ *mcp.CallToolRequest, in I
 	if res := checkRoutePolicy(ctx, policy, in); res != nil {
 		return res, nil, nil
 	}
+	if in.Follow != nil {
+		if msg := followRefusal(ctx, in); msg != "" {
+			return toolkit.ErrorResult(msg), nil, nil
+		}
+	}
+	if len(in.Projection) > 0 && rawPassthroughFromContext(ctx) != nil {
+		return toolkit.ErrorResult("projection is not supported on a raw passthrough call"), nil, nil
+	}
 
 	// Raw passthrough (issue #535): when the REST shim has installed a
 	// RawSink on the context, stream the upstream body straight to it
@@ -1192,7 +1216,17 @@ func (t *Toolkit) handleInvoke(ctx context.Context, _ *mcp.CallToolRequest, in I
 	hasExport := t.exportDeps != nil
 	t.mu.RUnlock()
 
-	out, err := invoke(ctx, invocation{cfg: c.cfg, auth: c.auth, client: c.client, specs: c.specs, webdavRoutes: c.webdavRoutes(), budget: budget}, in)
+	inv := invocation{cfg: c.cfg, auth: c.auth, client: c.client, specs: c.specs, webdavRoutes: c.webdavRoutes(), budget: budget}
+	var (
+		out   InvokeOutput
+		pages []observability.AuditPage
+		err   error
+	)
+	if in.Follow != nil {
+		out, pages, err = followPages(ctx, followCall{inv: inv, policy: policy}, in)
+	} else {
+		out, err = invoke(ctx, inv, in)
+	}
 	if err != nil {
 		// A binary body refused before buffering renders as a
 		// structured 415 with a steer to api_export; budget rejections
@@ -1217,10 +1251,19 @@ func (t *Toolkit) handleInvoke(ctx context.Context, _ *mcp.CallToolRequest, in I
 	// prefix and the path_params substitution become visible — without it
 	// a prefix that routes to the wrong upstream reads as an unexplained
 	// upstream 4xx (issue #1298).
+	if in.Follow == nil && len(in.Projection) == 0 {
+		t.checkContract(ctx, c, in.Method, in.Path, out)
+	}
 	if in.OperationID != "" {
 		out.ResolvedPath = in.Path
 	}
-	return buildInvokeResult(out), out, nil
+	result := buildInvokeResult(out)
+	if len(pages) > 0 {
+		// Each followed page is an upstream call of its own; the audit
+		// middleware writes one row per entry beside this call's row.
+		result.Meta[observability.MetaAuditPages] = pages
+	}
+	return result, out, nil
 }
 
 // buildInvokeResult wraps an InvokeOutput in a CallToolResult,
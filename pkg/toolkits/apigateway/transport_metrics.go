@@ -1,10 +1,12 @@
 package apigateway
 
 import (
+	"context"
 	"net/http"
 	"time"
 
 	"github.com/txn2/mcp-data-platform/pkg/observability"
+	"github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway/contract"
 )
 
 // instrumentedTransport wraps an http.RoundTripper to record outbound
@@ -66,3 +68,19 @@ func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, er
 	// and break credential scrubbing.
 	return resp, err //nolint:wrapcheck // see comment above
 }
+
+// recordContractCheck counts one response compared with its catalog
+// operation. Unlike the transport above it records per operation: a
+// contract is a property of an operation, and a drift that breaks one
+// endpoint is invisible in a per-connection total.
+func recordContractCheck(ctx context.Context, metrics *observability.Metrics, connection, operationID string, violated bool) {
+	result := contract.ResultConformed
+	if violated {
+		result = contract.ResultViolated
+	}
+	metrics.RecordAPIGatewayContractCheck(ctx, observability.APIGatewayContractAttrs{
+		Connection:  connection,
+		OperationID: operationID,
+		Result:      result,
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/txn2/mcp-data-platform/internal/jsonpath"
 	"github.com/txn2/mcp-data-platform/internal/logsan"
 )
 
@@ -167,7 +168,7 @@ func predicateMatches(p Predicate, response any) bool {
 		return true
 	case PredicateResponseContains:
 		for _, path := range p.Paths {
-			if _, err := resolveJSONPath(path, response); err != nil {
+			if _, err := jsonpath.Resolve(path, response); err != nil {
 				return false
 			}
 		}
@@ -199,7 +200,7 @@ func resolveParameters(params map[string]any, call CallContext, response any) (m
 			out[k] = v
 			continue
 		}
-		resolved, err := resolveJSONPath(s, evalCtx)
+		resolved, err := jsonpath.Resolve(s, evalCtx)
 		if err != nil {
 			return nil, fmt.Errorf("binding %s: %w", k, err)
 		}
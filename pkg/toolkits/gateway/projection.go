@@ -0,0 +1,116 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/txn2/mcp-data-platform/internal/jsonpath"
+)
+
+// projectionArg is the reserved argument every proxied tool accepts on
+// top of its upstream schema. The gateway strips it before forwarding
+// and prunes the upstream's JSON result to the listed JSONPath
+// expressions, so a model can ask a chatty upstream tool for just the
+// fields it needs.
+const projectionArg = "_projection"
+
+// withProjectionArg returns a copy of an upstream input schema with the
+// projectionArg property added. It reports false, leaving the schema
+// untouched, when the schema is not a JSON object schema or the upstream
+// already declares a property by that name, in which case the argument
+// is the upstream's and is forwarded as-is.
+func withProjectionArg(schema any) (any, bool) {
+	m, ok := schema.(map[string]any)
+	if !ok {
+		return schema, false
+	}
+	props, _ := m["properties"].(map[string]any)
+	if _, taken := props[projectionArg]; taken {
+		return schema, false
+	}
+	props = maps.Clone(props)
+	if props == nil {
+		props = map[string]any{}
+	}
+	props[projectionArg] = map[string]any{
+		"type":     "array",
+		"minItems": 1,
+		"maxItems": jsonpath.MaxProjectionPaths,
+		"items":    map[string]any{"type": "string"},
+		"description": "Gateway option, not sent upstream: JSONPath expressions (for example " +
+			"[\"$.items[*].id\", \"$.total\"]) selecting the parts of this tool's JSON result to " +
+			"return. Supports $, .field, [n], and [*]. The result keeps its nesting and a note " +
+			"reports how many bytes the projection skipped.",
+	}
+	out := maps.Clone(m)
+	out["properties"] = props
+	return out, true
+}
+
+// splitProjection removes projectionArg from raw call arguments and
+// compiles it. A nil projection with the original arguments is returned
+// when the call did not set it.
+func splitProjection(raw json.RawMessage) (*jsonpath.Projection, json.RawMessage, error) {
+	if len(raw) == 0 {
+		return nil, raw, nil
+	}
+	var args map[string]json.RawMessage
+	if json.Unmarshal(raw, &args) != nil {
+		// Non-object arguments are the upstream's to reject.
+		return nil, raw, nil
+	}
+	spec, ok := args[projectionArg]
+	if !ok {
+		return nil, raw, nil
+	}
+	var exprs []string
+	if err := json.Unmarshal(spec, &exprs); err != nil {
+		return nil, nil, fmt.Errorf("%s must be an array of JSONPath strings: %w", projectionArg, err)
+	}
+	proj, err := jsonpath.CompileProjection(exprs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", projectionArg, err)
+	}
+	delete(args, projectionArg)
+	stripped, err := json.Marshal(args)
+	if err != nil {
+		return nil, nil, fmt.Errorf("re-encoding arguments: %w", err)
+	}
+	return &proj, stripped, nil
+}
+
+// applyProjection prunes a successful upstream result to the
+// projection. The projected JSON replaces both StructuredContent and the
+// leading text block; trailing blocks (enrichment warnings) are kept and
+// a note with the before and after sizes is appended. A result with no
+// JSON to project is left unchanged with a warning.
+func applyProjection(res *mcp.CallToolResult, proj jsonpath.Projection) {
+	input, ok := structuredInput(res)
+	if !ok {
+		res.Content = append(res.Content, &mcp.TextContent{Text: "warning: " + projectionArg + " not applied: the tool result is not JSON"})
+		return
+	}
+	before, err := json.Marshal(input)
+	if err != nil {
+		return
+	}
+	projected, _ := proj.Apply(input)
+	after, err := json.Marshal(projected)
+	if err != nil {
+		return
+	}
+	res.StructuredContent = projected
+	text := &mcp.TextContent{Text: string(after)}
+	if len(res.Content) > 0 {
+		if _, isText := res.Content[0].(*mcp.TextContent); isText {
+			res.Content = res.Content[1:]
+		}
+	}
+	res.Content = append([]mcp.Content{text}, res.Content...)
+	res.Content = append(res.Content, &mcp.TextContent{
+		Text: fmt.Sprintf("projection kept %d of %d bytes", len(after), len(before)),
+	})
+}
@@ -0,0 +1,113 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listUpstream serves one "list" tool whose JSON result carries more than
+// a caller usually wants, and records the arguments it received.
+func listUpstream(t *testing.T, seen chan<- map[string]any) string {
+	t.Helper()
+	srv := mcp.NewServer(&mcp.Implementation{Name: "upstream", Version: "0.0.1"}, nil)
+	type listArgs struct {
+		Limit int `json:"limit"`
+	}
+	mcp.AddTool(srv, &mcp.Tool{Name: "list", Description: "list rows"},
+		func(_ context.Context, req *mcp.CallToolRequest, _ listArgs) (*mcp.CallToolResult, any, error) {
+			var raw map[string]any
+			_ = json.Unmarshal(req.Params.Arguments, &raw)
+			seen <- raw
+			return nil, map[string]any{
+				"total": 2,
+				"rows": []any{
+					map[string]any{"id": 1, "name": "a", "notes": "long text"},
+					map[string]any{"id": 2, "name": "b", "notes": "more long text"},
+				},
+			}, nil
+		})
+	ts := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return srv }, nil))
+	t.Cleanup(func() {
+		ts.CloseClientConnections()
+		ts.Close()
+	})
+	return ts.URL
+}
+
+func TestEndToEnd_ProjectionPrunesResult(t *testing.T) {
+	seen := make(chan map[string]any, 1)
+	tk := New("primary")
+	t.Cleanup(func() { _ = tk.Close() })
+	require.NoError(t, tk.AddConnection(connCRM, connectionConfig(listUpstream(t, seen), connCRM)))
+
+	client := platformWithToolkit(t, tk)
+	t.Cleanup(func() { _ = client.Close() })
+
+	tools, err := client.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, tools.Tools, 1)
+	props := tools.Tools[0].InputSchema.(map[string]any)["properties"].(map[string]any)
+	assert.Contains(t, props, projectionArg)
+	assert.Contains(t, props, "limit")
+
+	res, err := client.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      connCRM + NamespaceSeparator + "list",
+		Arguments: map[string]any{"limit": 5, projectionArg: []string{"$.rows[*].id", "$.total"}},
+	})
+	require.NoError(t, err)
+	require.False(t, res.IsError, firstText(t, res).Text)
+
+	upstreamArgs := <-seen
+	assert.NotContains(t, upstreamArgs, projectionArg, "the reserved argument must not reach the upstream")
+	assert.InDelta(t, 5, upstreamArgs["limit"], 0)
+
+	assert.JSONEq(t, `{"total":2,"rows":[{"id":1},{"id":2}]}`, firstText(t, res).Text)
+	note := res.Content[len(res.Content)-1].(*mcp.TextContent).Text
+	assert.Contains(t, note, "projection kept")
+}
+
+func TestEndToEnd_ProjectionRejectsBadPath(t *testing.T) {
+	seen := make(chan map[string]any, 1)
+	tk := New("primary")
+	t.Cleanup(func() { _ = tk.Close() })
+	require.NoError(t, tk.AddConnection(connCRM, connectionConfig(listUpstream(t, seen), connCRM)))
+
+	client := platformWithToolkit(t, tk)
+	t.Cleanup(func() { _ = client.Close() })
+
+	res, err := client.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      connCRM + NamespaceSeparator + "list",
+		Arguments: map[string]any{projectionArg: []string{"rows"}},
+	})
+	require.NoError(t, err)
+	assert.True(t, res.IsError)
+	assert.Contains(t, firstText(t, res).Text, "must start with")
+	assert.Empty(t, seen, "a rejected projection must not call the upstream")
+}
+
+func TestWithProjectionArg_KeepsUpstreamOwnedName(t *testing.T) {
+	schema := map[string]any{"type": "object", "properties": map[string]any{projectionArg: map[string]any{"type": "string"}}}
+	got, ok := withProjectionArg(schema)
+	assert.False(t, ok)
+	assert.Equal(t, schema, got)
+
+	_, ok = withProjectionArg(nil)
+	assert.False(t, ok)
+}
+
+func TestApplyProjection_NonJSONWarns(t *testing.T) {
+	res := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "plain"}}}
+	p, _, err := splitProjection(json.RawMessage(`{"_projection":["$.a"]}`))
+	require.NoError(t, err)
+	applyProjection(res, *p)
+	require.Len(t, res.Content, 2)
+	assert.Equal(t, "plain", res.Content[0].(*mcp.TextContent).Text)
+	assert.Contains(t, res.Content[1].(*mcp.TextContent).Text, "not applied")
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/txn2/mcp-data-platform/internal/jsonpath"
 	"github.com/txn2/mcp-data-platform/internal/logsan"
 	"github.com/txn2/mcp-data-platform/pkg/authevents"
 	"github.com/txn2/mcp-data-platform/pkg/connoauth"
@@ -631,6 +632,26 @@ func (t *Toolkit) ConnectionForTool(toolName string) string {
 	return ""
 }
 
+// IsDestructive reports whether the upstream annotates a namespaced local
+// tool with destructiveHint: true. An unannotated tool is not destructive
+// here, even though the MCP spec defaults the hint to true: the approval
+// gate reads this to hold calls for review, and holding every call to an
+// upstream that annotates nothing would make the rule useless. Operators
+// gate such tools by name instead.
+func (t *Toolkit) IsDestructive(toolName string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, u := range t.connections {
+		for _, rt := range u.tools {
+			if rt == nil || u.config.ConnectionName+NamespaceSeparator+rt.Name != toolName {
+				continue
+			}
+			return rt.Annotations != nil && rt.Annotations.DestructiveHint != nil && *rt.Annotations.DestructiveHint
+		}
+	}
+	return false
+}
+
 // RegisterTools captures the server reference and registers every tool from
 // every already-loaded connection. Must be called exactly once, after the
 // toolkit is registered in the platform registry.
@@ -1229,14 +1250,15 @@ func (t *Toolkit) addToolsToServerLocked(u *upstream) {
 		if rt == nil || rt.Name == "" {
 			continue
 		}
+		schema, projectable := withProjectionArg(rt.InputSchema)
 		local := &mcp.Tool{
 			Name:        u.config.ConnectionName + NamespaceSeparator + rt.Name,
 			Description: rt.Description,
-			InputSchema: rt.InputSchema,
+			InputSchema: schema,
 			Title:       rt.Title,
 			Annotations: rt.Annotations,
 		}
-		t.server.AddTool(local, t.makeForwarder(u, rt.Name, local.Name))
+		t.server.AddTool(local, t.makeForwarder(u, rt.Name, local.Name, projectable))
 		registered++
 	}
 	if registered > 0 {
@@ -1246,8 +1268,9 @@ func (t *Toolkit) addToolsToServerLocked(u *upstream) {
 
 // makeForwarder returns a handler that forwards the call upstream and, if
 // an enrichment engine is configured, applies enrichment rules to the
-// upstream response.
-func (t *Toolkit) makeForwarder(u *upstream, remoteName, localName string) mcp.ToolHandler {
+// upstream response. When projectable, the reserved projectionArg is
+// stripped from the arguments and applied to the result last.
+func (t *Toolkit) makeForwarder(u *upstream, remoteName, localName string, projectable bool) mcp.ToolHandler {
 	connection := u.config.ConnectionName
 	callTimeout := u.config.CallTimeout
 	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -1260,6 +1283,14 @@ func (t *Toolkit) makeForwarder(u *upstream, remoteName, localName string) mcp.T
 		}
 
 		args := argumentsFromRequest(req)
+		var proj *jsonpath.Projection
+		if projectable && args != nil {
+			p, stripped, perr := splitProjection(req.Params.Arguments)
+			if perr != nil {
+				return toolkit.ErrorResult(perr.Error()), nil
+			}
+			proj, args = p, stripped
+		}
 		res, err := callTool(ctx, client, callTimeout, remoteName, args)
 		if err != nil && isSessionDropped(err) {
 			// The upstream evicted or restarted the session. Re-dial once and
@@ -1282,6 +1313,9 @@ func (t *Toolkit) makeForwarder(u *upstream, remoteName, localName string) mcp.T
 		u.recordSuccess()
 		if !res.IsError {
 			t.applyEnrichment(ctx, connection, localName, req, res)
+			if proj != nil {
+				applyProjection(res, *proj)
+			}
 		}
 		dropUpstreamServerInfo(res)
 		return res, nil
@@ -48,7 +48,8 @@ func upstreamServer(t *testing.T) string {
 				Content: []mcp.Content{&mcp.TextContent{Text: "echo:" + a.Message}},
 			}, nil, nil
 		})
-	mcp.AddTool(srv, &mcp.Tool{Name: toolBoom, Description: "always errors"},
+	destructive := true
+	mcp.AddTool(srv, &mcp.Tool{Name: toolBoom, Description: "always errors", Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructive}},
 		func(_ context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
 			return &mcp.CallToolResult{
 				IsError: true,
@@ -122,6 +123,24 @@ func TestConnectionForTool_ResolvesPerToolToConnection(t *testing.T) {
 	}
 }
 
+func TestIsDestructive_ReadsTheUpstreamAnnotation(t *testing.T) {
+	tk := New("primary")
+	t.Cleanup(func() { _ = tk.Close() })
+	if err := tk.AddConnection(connCRM, connectionConfig(upstreamServer(t), connCRM)); err != nil {
+		t.Fatalf("AddConnection: %v", err)
+	}
+
+	if !tk.IsDestructive(localCRMBoom) {
+		t.Errorf("IsDestructive(%q) = false, want true", localCRMBoom)
+	}
+	if tk.IsDestructive(localCRMEcho) {
+		t.Errorf("an unannotated tool must not read as destructive")
+	}
+	if tk.IsDestructive("not_a_tool") {
+		t.Errorf("IsDestructive(unknown) = true, want false")
+	}
+}
+
 func TestAddConnection_TwoConnectionsIsolated(t *testing.T) {
 	tk := New("primary")
 	t.Cleanup(func() { _ = tk.Close() })
@@ -1110,7 +1129,7 @@ func TestArgsFromRequest_BadJSONReturnsNil(t *testing.T) {
 func TestMakeForwarder_NilClientReturnsUnavailable(t *testing.T) {
 	tk := New("primary")
 	u := &upstream{config: Config{ConnectionName: "nilc", CallTimeout: time.Second}}
-	handler := tk.makeForwarder(u, "any", "nilc__any")
+	handler := tk.makeForwarder(u, "any", "nilc__any", false)
 	res, err := handler(context.Background(), &mcp.CallToolRequest{})
 	if err != nil {
 		t.Fatalf("handler err: %v", err)
@@ -1182,7 +1201,7 @@ func TestForwarder_ReconnectsAfterDroppedSession(t *testing.T) {
 	require.NotNil(t, u.client)
 	firstClient := u.client
 
-	handler := tk.makeForwarder(u, toolEcho, localCRMEcho)
+	handler := tk.makeForwarder(u, toolEcho, localCRMEcho, false)
 	call := func(msg string) *mcp.CallToolResult {
 		req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{
 			Name:      toolEcho,
@@ -1236,7 +1255,7 @@ func TestForwarder_DropsTheUpstreamServerIdentity(t *testing.T) {
 	u := tk.connections[connCRM]
 	tk.mu.RUnlock()
 
-	res, err := tk.makeForwarder(u, toolEcho, localCRMEcho)(context.Background(), &mcp.CallToolRequest{
+	res, err := tk.makeForwarder(u, toolEcho, localCRMEcho, false)(context.Background(), &mcp.CallToolRequest{
 		Params: &mcp.CallToolParamsRaw{Name: toolEcho, Arguments: json.RawMessage(`{}`)},
 	})
 	require.NoError(t, err)
@@ -1296,7 +1315,7 @@ func TestListConnections_SurfacesHealth(t *testing.T) {
 	tk.mu.RLock()
 	u := tk.connections[connCRM]
 	tk.mu.RUnlock()
-	handler := tk.makeForwarder(u, toolEcho, localCRMEcho)
+	handler := tk.makeForwarder(u, toolEcho, localCRMEcho, false)
 	res, err := handler(context.Background(), &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{
 		Name:      toolEcho,
 		Arguments: json.RawMessage(`{"message":"x"}`),
@@ -1351,7 +1370,7 @@ func TestStatus_HealthyIsSessionLevel_DistinctFromReachability(t *testing.T) {
 	tk.mu.RLock()
 	u := tk.connections[connCRM]
 	tk.mu.RUnlock()
-	handler := tk.makeForwarder(u, toolEcho, localCRMEcho)
+	handler := tk.makeForwarder(u, toolEcho, localCRMEcho, false)
 	res, err := handler(context.Background(), &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{
 		Name:      toolEcho,
 		Arguments: json.RawMessage(`{"message":"x"}`),
@@ -1386,7 +1405,7 @@ func TestForwarder_RemovedConnectionDoesNotReconnect(t *testing.T) {
 	tk.mu.RLock()
 	u := tk.connections[connCRM]
 	tk.mu.RUnlock()
-	handler := tk.makeForwarder(u, toolEcho, localCRMEcho)
+	handler := tk.makeForwarder(u, toolEcho, localCRMEcho, false)
 
 	if err := tk.RemoveConnection(connCRM); err != nil {
 		t.Fatalf("RemoveConnection: %v", err)
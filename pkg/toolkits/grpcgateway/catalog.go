@@ -0,0 +1,199 @@
+package grpcgateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway/catalog"
+)
+
+// specNameMaxLen matches the catalog's spec-name length limit.
+const specNameMaxLen = 64
+
+// invalidSpecNameChars collapses everything outside the catalog's
+// spec-name alphabet into a single hyphen.
+var invalidSpecNameChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// serviceSpecName derives a catalog spec name from a service's full
+// name ("acme.orders.v1.OrderService" becomes
+// "acme-orders-v1-orderservice"). Overlong names keep their tail, which
+// carries the service name, rather than their package prefix.
+func serviceSpecName(svc protoreflect.ServiceDescriptor) string {
+	s := invalidSpecNameChars.ReplaceAllString(strings.ToLower(string(svc.FullName())), "-")
+	if len(s) > specNameMaxLen {
+		s = s[len(s)-specNameMaxLen:]
+	}
+	return strings.Trim(s, "-_")
+}
+
+// serviceSpec renders one gRPC service as an OpenAPI 3 document so it
+// flows through the API catalog unchanged: api_list_specs and the admin
+// catalog UI list it, and the embedding reconciler indexes its methods
+// for semantic ranking. Each unary or streaming method becomes a POST
+// on its gRPC wire path ("/pkg.Service/Method"), with the proto
+// comments as summary and description and the protojson schemas of the
+// request and response messages as the bodies. The document describes
+// the service; calls still go through grpc_invoke_method, never the
+// HTTP api gateway.
+func serviceSpec(svc protoreflect.ServiceDescriptor) (string, int, error) {
+	paths := make(map[string]any, svc.Methods().Len())
+	for i := range svc.Methods().Len() {
+		m := svc.Methods().Get(i)
+		paths[fullMethodPath(m)] = map[string]any{"post": methodOperation(m)}
+	}
+	info := map[string]any{
+		"title":   string(svc.FullName()),
+		"version": "grpc",
+	}
+	if c := leadingComments(svc); c != "" {
+		info["description"] = c
+	}
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info":    info,
+		"paths":   paths,
+	}
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return "", 0, fmt.Errorf("grpcgateway: encoding spec for %s: %w", svc.FullName(), err)
+	}
+	return string(raw), len(paths), nil
+}
+
+func methodOperation(m protoreflect.MethodDescriptor) map[string]any {
+	summary, description := splitComment(leadingComments(m))
+	if summary == "" {
+		summary = string(m.Name())
+	}
+	op := map[string]any{
+		"operationId": methodName(m),
+		"summary":     summary,
+		"tags":        []any{string(m.Parent().FullName())},
+		"requestBody": map[string]any{
+			"required": true,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": messageSchema(m.Input())},
+			},
+		},
+		"responses": map[string]any{
+			"200": map[string]any{
+				"description": "OK",
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": messageSchema(m.Output())},
+				},
+			},
+		},
+	}
+	if streaming := streamingLabel(m); streaming != "" {
+		description = strings.TrimSpace(description + "\n\n" + streaming +
+			" streaming method; not callable through grpc_invoke_method.")
+	}
+	if description != "" {
+		op["description"] = description
+	}
+	return op
+}
+
+// splitComment splits a proto comment into a one-line summary (the
+// first paragraph, joined) and the remaining description.
+func splitComment(c string) (summary, description string) {
+	first, rest, _ := strings.Cut(c, "\n\n")
+	return strings.Join(strings.Fields(first), " "), strings.TrimSpace(rest)
+}
+
+// streamingLabel names a method's streaming shape, or "" for unary.
+func streamingLabel(m protoreflect.MethodDescriptor) string {
+	switch {
+	case m.IsStreamingClient() && m.IsStreamingServer():
+		return "Bidirectional"
+	case m.IsStreamingClient():
+		return "Client"
+	case m.IsStreamingServer():
+		return "Server"
+	default:
+		return ""
+	}
+}
+
+// syncCatalog writes one spec per service into the connection's
+// catalog, creating the catalog header if absent. Specs whose content
+// is unchanged are left alone so a reload does not churn the embedding
+// queue; grpc specs for services the upstream no longer exposes are
+// deleted. Operator-managed specs in the same catalog are never
+// touched. The embedding reconciler picks the new specs up through
+// their OperationCount.
+func syncCatalog(ctx context.Context, store catalog.Store, catalogID string, set *serviceSet) error {
+	if err := ensureCatalog(ctx, store, catalogID); err != nil {
+		return err
+	}
+	keep := make(map[string]bool, len(set.services))
+	for _, svc := range set.services {
+		name := serviceSpecName(svc)
+		keep[name] = true
+		content, ops, err := serviceSpec(svc)
+		if err != nil {
+			return err
+		}
+		if existing, gerr := store.GetSpec(ctx, catalogID, name); gerr == nil &&
+			existing.SourceKind == catalog.SourceGRPC && existing.Content == content {
+			continue
+		}
+		summary, _ := splitComment(leadingComments(svc))
+		if err := store.UpsertSpec(ctx, catalogID, catalog.SpecEntry{
+			SpecName:       name,
+			Content:        content,
+			SourceKind:     catalog.SourceGRPC,
+			Title:          string(svc.FullName()),
+			Description:    summary,
+			OperationCount: ops,
+		}); err != nil {
+			return fmt.Errorf("grpcgateway: upserting spec %s: %w", name, err)
+		}
+	}
+	return pruneSpecs(ctx, store, catalogID, keep)
+}
+
+func pruneSpecs(ctx context.Context, store catalog.Store, catalogID string, keep map[string]bool) error {
+	specs, err := store.ListSpecs(ctx, catalogID)
+	if err != nil {
+		return fmt.Errorf("grpcgateway: listing specs: %w", err)
+	}
+	for _, s := range specs {
+		if s.SourceKind != catalog.SourceGRPC || keep[s.SpecName] {
+			continue
+		}
+		if err := store.DeleteSpec(ctx, catalogID, s.SpecName); err != nil && !errors.Is(err, catalog.ErrNotFound) {
+			return fmt.Errorf("grpcgateway: deleting stale spec %s: %w", s.SpecName, err)
+		}
+	}
+	return nil
+}
+
+// ensureCatalog creates the catalog header if absent, named after its
+// ID. An existing catalog is left untouched.
+func ensureCatalog(ctx context.Context, store catalog.Store, catalogID string) error {
+	_, err := store.GetCatalog(ctx, catalogID)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, catalog.ErrNotFound) {
+		return fmt.Errorf("grpcgateway: looking up catalog: %w", err)
+	}
+	if err := store.CreateCatalog(ctx, catalog.Catalog{
+		ID:          catalogID,
+		Name:        catalogID,
+		Version:     "grpc",
+		DisplayName: catalogID,
+		Description: "gRPC services indexed from a grpc connection's descriptors.",
+		CreatedBy:   "system",
+	}); err != nil && !errors.Is(err, catalog.ErrConflict) {
+		return fmt.Errorf("grpcgateway: creating catalog: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+// Package grpcgateway provides a gRPC gateway toolkit that proxies
+// unary gRPC calls through the platform's auth, persona, and audit
+// pipeline. Sibling to pkg/toolkits/apigateway, which proxies HTTP/JSON
+// APIs described by OpenAPI; this toolkit proxies gRPC services
+// described by protobuf descriptors.
+//
+// A connection learns its services from one of two sources: a
+// serialized FileDescriptorSet supplied by the operator (the output of
+// `protoc --include_imports --descriptor_set_out`), or gRPC server
+// reflection against the upstream itself. Either way the toolkit
+// indexes every service and method with its proto comments, renders
+// request and response messages as JSON Schema for the model, and
+// invokes unary methods by transcoding the model's JSON to protobuf on
+// the way out and back to JSON on the way in. No generated Go code is
+// needed for the upstream's messages.
+//
+// Authentication reuses the api gateway's authenticators unchanged
+// (none, bearer, api_key, oauth client_credentials, mtls): the
+// credential an Authenticator would set as an HTTP header is sent as
+// gRPC request metadata instead, so both kinds share one parsing and
+// validation path for the connection's auth keys.
+package grpcgateway
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/txn2/mcp-data-platform/pkg/connoauth"
+	apigatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway"
+)
+
+const (
+	// Kind is the connection-instance kind discriminator. Operators see
+	// this in the admin UI's connection picker.
+	Kind = "grpc"
+
+	// DefaultConnectTimeout caps connection establishment and the
+	// reflection round trips that load a connection's descriptors.
+	DefaultConnectTimeout = 10 * time.Second
+	// DefaultCallTimeout caps the total time of one unary call.
+	DefaultCallTimeout = 60 * time.Second
+	// DefaultMaxResponseBytes caps the JSON-encoded response returned to
+	// the model. A larger response is replaced with a truncated text
+	// rendering and flagged.
+	DefaultMaxResponseBytes = int64(10 * 1024 * 1024)
+)
+
+// Config keys read from the generic connection map. The auth keys
+// (auth_mode, credential, api_key_header, oauth_*, static_headers,
+// mtls_*, tls_ca_bundle_pem) are the api gateway's and are parsed by
+// apigateway.ParseConfig, so they are not repeated here.
+const (
+	cfgKeyTarget           = "target"
+	cfgKeyPlaintext        = "plaintext"
+	cfgKeyDescriptorSet    = "descriptor_set"
+	cfgKeyReflection       = "reflection"
+	cfgKeyCatalogID        = "catalog_id"
+	cfgKeyDescription      = "description"
+	cfgKeyConnectTimeout   = "connect_timeout"
+	cfgKeyCallTimeout      = "call_timeout"
+	cfgKeyMaxResponseBytes = "max_response_bytes"
+	cfgKeyBaseURL          = "base_url"
+)
+
+// Config holds gRPC gateway configuration for a single upstream
+// server.
+type Config struct {
+	// Target is the upstream address in host:port form. Required.
+	Target string
+	// Plaintext dials without TLS. Off by default; intended for
+	// in-cluster sidecars and local development servers.
+	Plaintext bool
+	// DescriptorSet is a serialized google.protobuf.FileDescriptorSet
+	// describing the upstream's services. Supplied base64-encoded in the
+	// connection config. Exactly one of DescriptorSet and Reflection is
+	// set.
+	DescriptorSet []byte
+	// Reflection loads the descriptors from the upstream's gRPC server
+	// reflection service (grpc.reflection.v1) instead of a supplied set.
+	Reflection bool
+	// CatalogID names the api_catalogs row the connection's services are
+	// indexed into. Empty leaves the services out of the API catalog;
+	// the grpc_* tools work either way.
+	CatalogID string
+	// Description is the optional human-readable description surfaced
+	// by ListConnections. Empty falls back to the target.
+	Description string
+	// ConnectionName is the audit-visible connection identifier, always
+	// populated from the instance name.
+	ConnectionName string
+	// ConnectTimeout caps descriptor loading over reflection.
+	ConnectTimeout time.Duration
+	// CallTimeout caps one unary invocation.
+	CallTimeout time.Duration
+	// MaxResponseBytes caps the JSON response returned to the model.
+	MaxResponseBytes int64
+	// Auth carries the auth-related fields parsed by the api gateway.
+	// Only its auth, static-header, and TLS fields are consulted; its
+	// BaseURL is a synthetic value derived from Target.
+	Auth apigatewaykit.Config
+}
+
+// MultiConfig holds parsed per-connection configs plus the aggregate
+// toolkit's default connection name.
+type MultiConfig struct {
+	DefaultName string
+	Instances   map[string]Config
+}
+
+// ParseMultiConfig validates and returns the parsed config for every
+// instance. Per-instance parse errors are logged and the bad instance
+// is skipped so one misconfigured connection cannot block startup.
+func ParseMultiConfig(defaultName string, raw map[string]map[string]any) (MultiConfig, error) {
+	parsed := make(map[string]Config, len(raw))
+	for name, r := range raw {
+		c, err := ParseConfig(r)
+		if err != nil {
+			slog.Warn("skipping invalid connection instance",
+				"kind", Kind, "instance", name, "error", err)
+			continue
+		}
+		if c.ConnectionName == "" {
+			c.ConnectionName = name
+		}
+		parsed[name] = c
+	}
+	return MultiConfig{DefaultName: defaultName, Instances: parsed}, nil
+}
+
+// ParseConfig parses a Config from a generic map (the form admin-saved
+// connections take in the connection_instances table) and applies
+// defaults. The returned Config is fully validated.
+func ParseConfig(cfg map[string]any) (Config, error) {
+	c := Config{
+		Target:           strings.TrimSpace(getString(cfg, cfgKeyTarget)),
+		Plaintext:        getBool(cfg, cfgKeyPlaintext),
+		Reflection:       getBool(cfg, cfgKeyReflection),
+		CatalogID:        getString(cfg, cfgKeyCatalogID),
+		Description:      getString(cfg, cfgKeyDescription),
+		ConnectTimeout:   getDuration(cfg, cfgKeyConnectTimeout, DefaultConnectTimeout),
+		CallTimeout:      getDuration(cfg, cfgKeyCallTimeout, DefaultCallTimeout),
+		MaxResponseBytes: getInt64(cfg, cfgKeyMaxResponseBytes, DefaultMaxResponseBytes),
+	}
+	if raw := strings.TrimSpace(getString(cfg, cfgKeyDescriptorSet)); raw != "" {
+		set, err := base64.StdEncoding.DecodeString(raw)
+		if err != nil {
+			return Config{}, errors.New("grpcgateway: descriptor_set must be a base64-encoded FileDescriptorSet")
+		}
+		c.DescriptorSet = set
+	}
+	if c.Target == "" {
+		return Config{}, errors.New("grpcgateway: target is required")
+	}
+	auth, err := parseAuth(cfg, c.Target, c.Plaintext)
+	if err != nil {
+		return Config{}, err
+	}
+	c.Auth = auth
+	if err := c.Validate(); err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
+
+// parseAuth runs the connection map through the api gateway's parser so
+// the auth keys mean exactly what they mean on a kind=api connection. The
+// api parser requires a base URL; a synthetic one is derived from the
+// target, which also gives an OAuth config a host to resolve against.
+// The input map is copied, never mutated.
+func parseAuth(cfg map[string]any, target string, plaintext bool) (apigatewaykit.Config, error) {
+	scheme := "https"
+	if plaintext {
+		scheme = "http"
+	}
+	withBase := maps.Clone(cfg)
+	withBase[cfgKeyBaseURL] = scheme + "://" + target
+	// Drop the gRPC-only byte budget: the api parser reads the same key
+	// and would otherwise validate the gRPC value against its own rules.
+	delete(withBase, cfgKeyMaxResponseBytes)
+	auth, err := apigatewaykit.ParseConfig(withBase)
+	if err != nil {
+		return apigatewaykit.Config{}, fmt.Errorf("grpcgateway: %w", err)
+	}
+	return auth, nil
+}
+
+// Validate returns an error if the configuration is missing required
+// fields or contains invalid values.
+func (c Config) Validate() error {
+	if c.Target == "" {
+		return errors.New("grpcgateway: target is required")
+	}
+	if _, port, err := net.SplitHostPort(c.Target); err != nil || port == "" {
+		return fmt.Errorf("grpcgateway: target %q must be host:port", c.Target)
+	}
+	if c.Reflection == (len(c.DescriptorSet) > 0) {
+		return errors.New("grpcgateway: set exactly one of descriptor_set or reflection")
+	}
+	if c.ConnectTimeout <= 0 {
+		return errors.New("grpcgateway: connect_timeout must be positive")
+	}
+	if c.CallTimeout <= 0 {
+		return errors.New("grpcgateway: call_timeout must be positive")
+	}
+	if c.MaxResponseBytes <= 0 {
+		return errors.New("grpcgateway: max_response_bytes must be positive")
+	}
+	return c.validateAuth()
+}
+
+// validateAuth narrows the api gateway's auth modes to the ones that
+// translate to gRPC metadata. HTTP Basic and query-string API keys have
+// no gRPC equivalent, identity passthrough and in-process handlers are
+// api-only concepts, and the OAuth authorization_code grant persists its
+// refresh token under the api kind's connection key, so only the
+// machine-to-machine client_credentials grant is accepted.
+func (c Config) validateAuth() error {
+	a := c.Auth
+	switch a.AuthMode {
+	case apigatewaykit.AuthModeNone, apigatewaykit.AuthModeBearer, apigatewaykit.AuthModeMTLS:
+	case apigatewaykit.AuthModeAPIKey:
+		if a.CredentialPlacement != apigatewaykit.CredentialPlacementHeader {
+			return errors.New("grpcgateway: api_key_placement must be \"header\" (gRPC has no query string)")
+		}
+	case apigatewaykit.AuthModeOAuth:
+		if a.OAuth2.Grant != connoauth.GrantClientCredentials {
+			return errors.New("grpcgateway: oauth connections support the client_credentials grant only")
+		}
+	default:
+		return fmt.Errorf("grpcgateway: auth_mode %q is not supported (want none, bearer, api_key, oauth, or mtls)", a.AuthMode)
+	}
+	if a.IdentityPassthrough || a.Handler != "" {
+		return errors.New("grpcgateway: identity_passthrough and handler are not supported on grpc connections")
+	}
+	if c.Plaintext && (a.MTLSClientCertPEM != "" || a.TLSCABundlePEM != "") {
+		return errors.New("grpcgateway: plaintext connections cannot carry TLS material")
+	}
+	return nil
+}
+
+func getString(cfg map[string]any, key string) string {
+	if v, ok := cfg[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+func getDuration(cfg map[string]any, key string, defaultVal time.Duration) time.Duration {
+	switch v := cfg[key].(type) {
+	case string:
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	case time.Duration:
+		return v
+	case int:
+		return time.Duration(v) * time.Second
+	case int64:
+		return time.Duration(v) * time.Second
+	case float64:
+		return time.Duration(v) * time.Second
+	}
+	return defaultVal
+}
+
+func getInt64(cfg map[string]any, key string, defaultVal int64) int64 {
+	switch v := cfg[key].(type) {
+	case int:
+		return int64(v)
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	}
+	return defaultVal
+}
+
+// getBool reads a boolean flag, accepting a native bool or a string
+// strconv.ParseBool understands. Absent or unrecognized values are false.
+func getBool(cfg map[string]any, key string) bool {
+	switch v := cfg[key].(type) {
+	case bool:
+		return v
+	case string:
+		b, err := strconv.ParseBool(v)
+		return err == nil && b
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package grpcgateway
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	base := func() map[string]any {
+		return map[string]any{"target": "localhost:50051", "reflection": true, "plaintext": true}
+	}
+	c, err := ParseConfig(base())
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+	if c.Target != "localhost:50051" || !c.Reflection || !c.Plaintext {
+		t.Errorf("parsed = %+v", c)
+	}
+	if c.CallTimeout != DefaultCallTimeout || c.MaxResponseBytes != DefaultMaxResponseBytes {
+		t.Errorf("defaults not applied: %+v", c)
+	}
+	if c.Auth.BaseURL != "http://localhost:50051" {
+		t.Errorf("synthetic base url = %q", c.Auth.BaseURL)
+	}
+
+	cases := []struct {
+		name    string
+		mutate  func(map[string]any)
+		wantErr string
+	}{
+		{"missing target", func(m map[string]any) { delete(m, "target") }, "target is required"},
+		{"target without port", func(m map[string]any) { m["target"] = "localhost" }, "host:port"},
+		{"no descriptor source", func(m map[string]any) { delete(m, "reflection") }, "exactly one"},
+		{"both descriptor sources", func(m map[string]any) { m["descriptor_set"] = "AAAA" }, "exactly one"},
+		{"bad base64", func(m map[string]any) { delete(m, "reflection"); m["descriptor_set"] = "%%%" }, "base64"},
+		{"basic auth", func(m map[string]any) {
+			m["auth_mode"] = "basic"
+			m["username"] = "u"
+		}, "not supported"},
+		{"query api key", func(m map[string]any) {
+			m["auth_mode"] = "api_key"
+			m["credential"] = "k"
+			m["api_key_placement"] = "query"
+			m["api_key_param"] = "key"
+		}, "api_key_placement"},
+		{"authorization code", func(m map[string]any) {
+			m["auth_mode"] = "oauth"
+			m["oauth_grant"] = "authorization_code"
+			m["oauth_client_id"] = "id"
+			m["oauth_client_secret"] = "secret"
+			m["oauth_token_url"] = "https://idp.example.com/token"
+			m["oauth_authorization_url"] = "https://idp.example.com/authorize"
+		}, "client_credentials"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := base()
+			tc.mutate(m)
+			_, err := ParseConfig(m)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("err = %v, want containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseConfigBearer(t *testing.T) {
+	c, err := ParseConfig(map[string]any{
+		"target":         "api.example.com:443",
+		"descriptor_set": echoDescriptorSet(t),
+		"auth_mode":      "bearer",
+		"credential":     "tok",
+		"static_headers": map[string]any{"x-tenant": "acme"},
+	})
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+	if c.Auth.AuthMode != "bearer" || c.Auth.Credential != "tok" || c.Auth.StaticHeaders["x-tenant"] != "acme" {
+		t.Errorf("auth = %+v", c.Auth)
+	}
+	if len(c.DescriptorSet) == 0 {
+		t.Error("descriptor set not decoded")
+	}
+}
@@ -0,0 +1,302 @@
+package grpcgateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	// The well-known types register themselves in
+	// protoregistry.GlobalFiles. A descriptor set built without
+	// --include_imports, or a reflection server that declines to serve
+	// google/protobuf/*.proto, still resolves against these.
+	_ "google.golang.org/protobuf/types/known/anypb"
+	_ "google.golang.org/protobuf/types/known/durationpb"
+	_ "google.golang.org/protobuf/types/known/emptypb"
+	_ "google.golang.org/protobuf/types/known/fieldmaskpb"
+	_ "google.golang.org/protobuf/types/known/structpb"
+	_ "google.golang.org/protobuf/types/known/timestamppb"
+	_ "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// reflectionServicePrefix marks the reflection services themselves,
+// which every reflection-enabled server lists but no model should call.
+const reflectionServicePrefix = "grpc.reflection."
+
+// maxReflectionFiles bounds how many files one reflection load will
+// fetch. A real service graph is a few dozen files; the cap only stops
+// a misbehaving server from streaming descriptors forever.
+const maxReflectionFiles = 2000
+
+// serviceSet is the resolved descriptor view of one connection: the
+// file registry every message type resolves against, plus the
+// callable services and a method index keyed on the canonical
+// "pkg.Service/Method" name.
+type serviceSet struct {
+	files    *protoregistry.Files
+	services []protoreflect.ServiceDescriptor
+	methods  map[string]protoreflect.MethodDescriptor
+}
+
+// methodName returns the canonical "pkg.Service/Method" name the tools
+// accept and the catalog indexes under.
+func methodName(m protoreflect.MethodDescriptor) string {
+	return string(m.Parent().FullName()) + "/" + string(m.Name())
+}
+
+// fullMethodPath returns the gRPC wire path ("/pkg.Service/Method").
+func fullMethodPath(m protoreflect.MethodDescriptor) string {
+	return "/" + methodName(m)
+}
+
+// lookupMethod resolves a caller-supplied method name. It accepts the
+// canonical "pkg.Service/Method", the wire form with a leading slash,
+// and the dotted "pkg.Service.Method" form grpcurl users type.
+func (s *serviceSet) lookupMethod(name string) (protoreflect.MethodDescriptor, bool) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "/")
+	if m, ok := s.methods[name]; ok {
+		return m, true
+	}
+	if i := strings.LastIndexByte(name, '.'); i > 0 && !strings.Contains(name, "/") {
+		m, ok := s.methods[name[:i]+"/"+name[i+1:]]
+		return m, ok
+	}
+	return nil, false
+}
+
+// loadDescriptorSet builds a serviceSet from a serialized
+// FileDescriptorSet. Every service declared in the set is callable.
+func loadDescriptorSet(raw []byte) (*serviceSet, error) {
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(raw, &set); err != nil {
+		return nil, fmt.Errorf("grpcgateway: decoding descriptor_set: %w", err)
+	}
+	if len(set.GetFile()) == 0 {
+		return nil, errors.New("grpcgateway: descriptor_set contains no files")
+	}
+	var declared []string
+	for _, f := range set.GetFile() {
+		for _, svc := range f.GetService() {
+			declared = append(declared, qualify(f.GetPackage(), svc.GetName()))
+		}
+	}
+	return newServiceSet(set.GetFile(), declared)
+}
+
+// qualify joins a proto package and a local name into a full name.
+func qualify(pkg, name string) string {
+	if pkg == "" {
+		return name
+	}
+	return pkg + "." + name
+}
+
+// newServiceSet links the given file protos (filling missing
+// well-known imports from the global registry) and indexes the named
+// services. A named service absent from the linked files is an error:
+// it means the descriptor source is incomplete.
+func newServiceSet(fdps []*descriptorpb.FileDescriptorProto, serviceNames []string) (*serviceSet, error) {
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: withGlobalDeps(fdps)})
+	if err != nil {
+		return nil, fmt.Errorf("grpcgateway: linking descriptors: %w", err)
+	}
+	s := &serviceSet{files: files, methods: make(map[string]protoreflect.MethodDescriptor)}
+	sort.Strings(serviceNames)
+	for _, name := range serviceNames {
+		if strings.HasPrefix(name, reflectionServicePrefix) {
+			continue
+		}
+		d, err := files.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			return nil, fmt.Errorf("grpcgateway: service %q not found in descriptors: %w", name, err)
+		}
+		svc, ok := d.(protoreflect.ServiceDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("grpcgateway: %q is not a service", name)
+		}
+		s.services = append(s.services, svc)
+		for i := range svc.Methods().Len() {
+			m := svc.Methods().Get(i)
+			s.methods[methodName(m)] = m
+		}
+	}
+	return s, nil
+}
+
+// withGlobalDeps appends, from protoregistry.GlobalFiles, any imported
+// file the supplied protos reference but do not include. In practice
+// that is the well-known types blank-imported above.
+func withGlobalDeps(fdps []*descriptorpb.FileDescriptorProto) []*descriptorpb.FileDescriptorProto {
+	have := make(map[string]bool, len(fdps))
+	for _, f := range fdps {
+		have[f.GetName()] = true
+	}
+	out := fdps
+	queue := append([]*descriptorpb.FileDescriptorProto(nil), fdps...)
+	for len(queue) > 0 {
+		f := queue[0]
+		queue = queue[1:]
+		for _, dep := range f.GetDependency() {
+			if have[dep] {
+				continue
+			}
+			fd, err := protoregistry.GlobalFiles.FindFileByPath(dep)
+			if err != nil {
+				continue // protodesc.NewFiles reports the missing import
+			}
+			have[dep] = true
+			dp := protodesc.ToFileDescriptorProto(fd)
+			out = append(out, dp)
+			queue = append(queue, dp)
+		}
+	}
+	return out
+}
+
+// loadReflection builds a serviceSet from the upstream's
+// grpc.reflection.v1 service: list the services, fetch the file that
+// defines each one, then fetch transitive imports by filename until the
+// graph is closed. Imports the server cannot serve fall back to the
+// global registry in newServiceSet.
+func loadReflection(ctx context.Context, cc grpc.ClientConnInterface) (*serviceSet, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(cc).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("grpcgateway: opening reflection stream: %w", err)
+	}
+	defer func() { _ = stream.CloseSend() }()
+
+	r := &reflectionLoader{stream: stream, files: make(map[string]*descriptorpb.FileDescriptorProto)}
+	resp, err := r.roundTrip(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		if strings.HasPrefix(svc.GetName(), reflectionServicePrefix) {
+			continue
+		}
+		names = append(names, svc.GetName())
+		if err := r.fetch(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: svc.GetName()},
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.closeImports(); err != nil {
+		return nil, err
+	}
+	fdps := make([]*descriptorpb.FileDescriptorProto, 0, len(r.files))
+	for _, f := range r.files {
+		fdps = append(fdps, f)
+	}
+	return newServiceSet(fdps, names)
+}
+
+// reflectionLoader accumulates file descriptors over one reflection
+// stream, keyed by file name.
+type reflectionLoader struct {
+	stream grpc.BidiStreamingClient[reflectionpb.ServerReflectionRequest, reflectionpb.ServerReflectionResponse]
+	files  map[string]*descriptorpb.FileDescriptorProto
+}
+
+func (r *reflectionLoader) roundTrip(req *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+	if err := r.stream.Send(req); err != nil {
+		return nil, fmt.Errorf("grpcgateway: reflection send: %w", err)
+	}
+	resp, err := r.stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("grpcgateway: reflection stream closed by server")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("grpcgateway: reflection receive: %w", err)
+	}
+	if e := resp.GetErrorResponse(); e != nil {
+		return nil, fmt.Errorf("grpcgateway: reflection error %d: %s", e.GetErrorCode(), e.GetErrorMessage())
+	}
+	return resp, nil
+}
+
+// fetch sends one file request and records every descriptor in the
+// response (servers commonly include the file's imports unprompted).
+func (r *reflectionLoader) fetch(req *reflectionpb.ServerReflectionRequest) error {
+	resp, err := r.roundTrip(req)
+	if err != nil {
+		return err
+	}
+	for _, raw := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		var fdp descriptorpb.FileDescriptorProto
+		if err := proto.Unmarshal(raw, &fdp); err != nil {
+			return fmt.Errorf("grpcgateway: decoding reflected file: %w", err)
+		}
+		if _, seen := r.files[fdp.GetName()]; !seen {
+			r.files[fdp.GetName()] = &fdp
+		}
+	}
+	if len(r.files) > maxReflectionFiles {
+		return fmt.Errorf("grpcgateway: reflection returned more than %d files", maxReflectionFiles)
+	}
+	return nil
+}
+
+// closeImports fetches imports not yet seen until every recorded file's
+// dependencies are present. An import the server refuses is left for
+// the global-registry fallback rather than failing the load: servers
+// built with some toolchains do not serve google/protobuf/*.proto.
+func (r *reflectionLoader) closeImports() error {
+	tried := make(map[string]bool)
+	for {
+		missing := r.missingImports(tried)
+		if len(missing) == 0 {
+			return nil
+		}
+		for _, name := range missing {
+			tried[name] = true
+			err := r.fetch(&reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: name},
+			})
+			if err != nil && !isGlobalFile(name) {
+				return err
+			}
+		}
+	}
+}
+
+func (r *reflectionLoader) missingImports(tried map[string]bool) []string {
+	var out []string
+	for _, f := range r.files {
+		for _, dep := range f.GetDependency() {
+			if _, ok := r.files[dep]; !ok && !tried[dep] {
+				tried[dep] = true
+				out = append(out, dep)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func isGlobalFile(name string) bool {
+	_, err := protoregistry.GlobalFiles.FindFileByPath(name)
+	return err == nil
+}
+
+// leadingComments returns the trimmed leading proto comment attached
+// to a descriptor, or "" when the descriptor source carried no source
+// info (descriptor sets built without --include_source_info, and most
+// reflection servers).
+func leadingComments(d protoreflect.Descriptor) string {
+	loc := d.ParentFile().SourceLocations().ByDescriptor(d)
+	return strings.TrimSpace(loc.LeadingComments)
+}
@@ -0,0 +1,284 @@
+package grpcgateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/txn2/mcp-data-platform/pkg/observability"
+	"github.com/txn2/mcp-data-platform/pkg/toolkit"
+	apigatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway"
+)
+
+// defaultListMethodsLimit caps grpc_list_methods when the caller does
+// not pass limit, matching api_list_endpoints.
+const defaultListMethodsLimit = 50
+
+// ListMethodsInput is the parsed argument shape for grpc_list_methods.
+type ListMethodsInput struct {
+	Connection string `json:"connection"`
+	Service    string `json:"service,omitempty"`
+	Query      string `json:"query,omitempty"`
+	Limit      int    `json:"limit,omitempty"`
+}
+
+// MethodSummary is one row of grpc_list_methods output.
+type MethodSummary struct {
+	Method       string `json:"method"`
+	Service      string `json:"service"`
+	Summary      string `json:"summary,omitempty"`
+	RequestType  string `json:"request_type"`
+	ResponseType string `json:"response_type"`
+	// Streaming names the method's streaming shape ("client", "server",
+	// "bidirectional"); empty for a unary, invocable method.
+	Streaming string `json:"streaming,omitempty"`
+}
+
+// ListMethodsOutput is the structured result for grpc_list_methods.
+type ListMethodsOutput struct {
+	Methods []MethodSummary `json:"methods"`
+	Total   int             `json:"total"`
+	Note    string          `json:"note,omitempty"`
+}
+
+// DescribeMethodInput is the parsed argument shape for
+// grpc_describe_method.
+type DescribeMethodInput struct {
+	Connection string `json:"connection"`
+	Method     string `json:"method"`
+}
+
+// DescribeMethodOutput is the structured result for
+// grpc_describe_method.
+type DescribeMethodOutput struct {
+	MethodSummary
+	Description    string         `json:"description,omitempty"`
+	RequestSchema  map[string]any `json:"request_schema"`
+	ResponseSchema map[string]any `json:"response_schema"`
+}
+
+// lookupConn resolves a connection and its descriptors, running a
+// pending reflection load. The error is the model-facing message.
+func (t *Toolkit) lookupConn(ctx context.Context, name string) (*conn, *serviceSet, apigatewaykit.RoutePolicy, error) {
+	if name == "" {
+		return nil, nil, nil, fmt.Errorf("connection is required")
+	}
+	t.mu.RLock()
+	c, ok := t.connections[name]
+	policy := t.routePolicy
+	t.mu.RUnlock()
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("connection %q not found (use list_connections to discover grpc connections)", name)
+	}
+	set, err := t.descriptors(ctx, name, c)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("connection %q has no service descriptors loaded: %w", name, err)
+	}
+	return c, set, policy, nil
+}
+
+// ResolveMethodPath resolves a caller-supplied method name on connection
+// to the wire path ("/pkg.Service/Method") the route policy checks, with
+// the same lookup grpc_invoke_method performs: the dotted and padded
+// forms resolve to the canonical path. ok is false when the connection
+// is unknown, has no descriptors, or does not declare the method.
+func (t *Toolkit) ResolveMethodPath(ctx context.Context, connection, method string) (path string, ok bool) {
+	_, set, _, err := t.lookupConn(ctx, connection)
+	if err != nil {
+		return "", false
+	}
+	m, ok := set.lookupMethod(method)
+	if !ok {
+		return "", false
+	}
+	return fullMethodPath(m), true
+}
+
+func (t *Toolkit) handleListMethods(ctx context.Context, _ *mcp.CallToolRequest, in ListMethodsInput) (*mcp.CallToolResult, any, error) {
+	_, set, policy, err := t.lookupConn(ctx, in.Connection)
+	if err != nil {
+		return toolkit.ErrorResult(err.Error()), nil, nil
+	}
+	limit := in.Limit
+	if limit <= 0 {
+		limit = defaultListMethodsLimit
+	}
+	query := strings.ToLower(strings.TrimSpace(in.Query))
+	out := ListMethodsOutput{Methods: []MethodSummary{}}
+	for _, svc := range set.services {
+		if in.Service != "" && string(svc.FullName()) != in.Service {
+			continue
+		}
+		for i := range svc.Methods().Len() {
+			m := svc.Methods().Get(i)
+			row := summarize(m)
+			if query != "" && !strings.Contains(strings.ToLower(row.Method+" "+row.Summary), query) {
+				continue
+			}
+			if policy != nil {
+				if ok, _ := policy.Allow(ctx, in.Connection, routeMethod, fullMethodPath(m)); !ok {
+					continue
+				}
+			}
+			out.Total++
+			if len(out.Methods) < limit {
+				out.Methods = append(out.Methods, row)
+			}
+		}
+	}
+	if out.Total > len(out.Methods) {
+		out.Note = fmt.Sprintf("showing %d of %d methods; narrow with service or query, or raise limit", len(out.Methods), out.Total)
+	}
+	return toolkit.JSONResult(out), out, nil
+}
+
+func summarize(m protoreflect.MethodDescriptor) MethodSummary {
+	summary, _ := splitComment(leadingComments(m))
+	return MethodSummary{
+		Method:       methodName(m),
+		Service:      string(m.Parent().FullName()),
+		Summary:      summary,
+		RequestType:  string(m.Input().FullName()),
+		ResponseType: string(m.Output().FullName()),
+		Streaming:    strings.ToLower(streamingLabel(m)),
+	}
+}
+
+func (t *Toolkit) handleDescribeMethod(ctx context.Context, _ *mcp.CallToolRequest, in DescribeMethodInput) (*mcp.CallToolResult, any, error) {
+	_, set, _, err := t.lookupConn(ctx, in.Connection)
+	if err != nil {
+		return toolkit.ErrorResult(err.Error()), nil, nil
+	}
+	m, ok := set.lookupMethod(in.Method)
+	if !ok {
+		return toolkit.ErrorResult(fmt.Sprintf("method %q not found (use grpc_list_methods to discover methods)", in.Method)), nil, nil
+	}
+	_, description := splitComment(leadingComments(m))
+	out := DescribeMethodOutput{
+		MethodSummary:  summarize(m),
+		Description:    description,
+		RequestSchema:  messageSchema(m.Input()),
+		ResponseSchema: messageSchema(m.Output()),
+	}
+	return toolkit.JSONResult(out), out, nil
+}
+
+// handleInvoke is the MCP handler for grpc_invoke_method. The route
+// policy runs before any outbound traffic so an unauthorized call never
+// reaches the upstream.
+func (t *Toolkit) handleInvoke(ctx context.Context, _ *mcp.CallToolRequest, in InvokeInput) (*mcp.CallToolResult, any, error) {
+	c, set, policy, err := t.lookupConn(ctx, in.Connection)
+	if err != nil {
+		return toolkit.ErrorResult(err.Error()), nil, nil
+	}
+	if m, ok := set.lookupMethod(in.Method); ok && policy != nil {
+		if allowed, reason := policy.Allow(ctx, in.Connection, routeMethod, fullMethodPath(m)); !allowed {
+			msg := "not authorized for this method on this connection"
+			if reason != "" {
+				msg += ": " + reason
+			}
+			return toolkit.ErrorResult(msg), nil, nil
+		}
+	}
+	out, err := invoke(ctx, invocation{cfg: c.cfg, auth: c.auth, cc: c.client, set: set}, in)
+	if err != nil {
+		return toolkit.ErrorResult(err.Error()), nil, nil
+	}
+	return buildInvokeResult(out), out, nil
+}
+
+// buildInvokeResult wraps an InvokeOutput in a CallToolResult and
+// stamps the audit outcome the same way the api gateway does: the
+// category on every call, a message for anything but success, and
+// IsError only when the call did not complete against the upstream.
+func buildInvokeResult(out InvokeOutput) *mcp.CallToolResult {
+	result := toolkit.JSONResult(out)
+	outcome := ClassifyInvokeOutcome(out)
+	result.Meta = mcp.Meta{observability.MetaAuditOutcome: outcome}
+	if outcome != observability.OutcomeOK {
+		msg := out.Code
+		if out.Message != "" {
+			msg += ": " + out.Message
+		}
+		result.Meta[observability.MetaAuditOutcomeMessage] = msg
+	}
+	if isGatewayFailure(out.code) {
+		result.IsError = true
+	}
+	return result
+}
+
+var listMethodsSchema = json.RawMessage(`{
+  "type": "object",
+  "required": ["connection"],
+  "additionalProperties": false,
+  "properties": {
+    "connection": {
+      "type": "string",
+      "description": "Name of the registered gRPC connection (kind=grpc). Required. Use list_connections to discover available connections."
+    },
+    "service": {
+      "type": "string",
+      "description": "Optional fully qualified service name (e.g. \"acme.orders.v1.OrderService\") to list only that service's methods."
+    },
+    "query": {
+      "type": "string",
+      "description": "Optional case-insensitive substring matched against the method name and summary."
+    },
+    "limit": {
+      "type": "integer",
+      "minimum": 1,
+      "description": "Maximum methods to return. Defaults to 50."
+    }
+  }
+}`)
+
+var describeMethodSchema = json.RawMessage(`{
+  "type": "object",
+  "required": ["connection", "method"],
+  "additionalProperties": false,
+  "properties": {
+    "connection": {
+      "type": "string",
+      "description": "Name of the registered gRPC connection (kind=grpc)."
+    },
+    "method": {
+      "type": "string",
+      "description": "Method name as returned by grpc_list_methods, e.g. \"acme.orders.v1.OrderService/GetOrder\"."
+    }
+  }
+}`)
+
+var invokeMethodSchema = json.RawMessage(`{
+  "type": "object",
+  "required": ["connection", "method"],
+  "additionalProperties": false,
+  "properties": {
+    "connection": {
+      "type": "string",
+      "description": "Name of the registered gRPC connection (kind=grpc)."
+    },
+    "method": {
+      "type": "string",
+      "description": "Method name as returned by grpc_list_methods, e.g. \"acme.orders.v1.OrderService/GetOrder\". Must be a unary method."
+    },
+    "request": {
+      "type": "object",
+      "description": "Request message in protojson form, matching request_schema from grpc_describe_method. Omit to send a message with every field at its default. Unknown fields are rejected."
+    },
+    "metadata": {
+      "type": "object",
+      "additionalProperties": {"type": "string"},
+      "description": "Optional extra request metadata (lowercase ASCII names). authorization, grpc-* names, and anything the connection already sets are refused."
+    },
+    "timeout_seconds": {
+      "type": "integer",
+      "minimum": 1,
+      "description": "Optional deadline for this call. Can shorten, never extend, the connection's call_timeout."
+    }
+  }
+}`)
@@ -0,0 +1,233 @@
+package grpcgateway
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const echoService = "test.echo.v1.EchoService"
+
+// echoFile builds the test proto by hand:
+//
+//	package test.echo.v1;
+//	import "google/protobuf/timestamp.proto";
+//	enum Color { COLOR_UNSPECIFIED = 0; RED = 1; }
+//	message Node { string name = 1; repeated Node children = 2; }
+//	message EchoRequest {
+//	  string text = 1; int64 count = 2; Color color = 3;
+//	  google.protobuf.Timestamp at = 4; repeated string tags = 5;
+//	  map<string, int32> attrs = 6; Node node = 7;
+//	}
+//	message EchoResponse { string text = 1; string auth = 2; string tenant = 3; }
+//	// Echo service for tests.
+//	service EchoService {
+//	  // Echo returns the request text.
+//	  //
+//	  // Also reports the caller's auth metadata.
+//	  rpc Echo(EchoRequest) returns (EchoResponse);
+//	  rpc Fail(EchoRequest) returns (EchoResponse);
+//	  rpc Watch(EchoRequest) returns (stream EchoResponse);
+//	}
+func echoFile() *descriptorpb.FileDescriptorProto {
+	opt := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	rep := descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	field := func(name string, num int32, label *descriptorpb.FieldDescriptorProto_Label,
+		typ descriptorpb.FieldDescriptorProto_Type, typeName string,
+	) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name: proto.String(name), Number: proto.Int32(num), Label: label,
+			Type: typ.Enum(), JsonName: proto.String(protoJSONName(name)),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING
+	msg := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	return &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("test/echo/v1/echo.proto"),
+		Package:    proto.String("test.echo.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/timestamp.proto"},
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Color"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("COLOR_UNSPECIFIED"), Number: proto.Int32(0)},
+				{Name: proto.String("RED"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Node"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("name", 1, opt, str, ""),
+					field("children", 2, rep, msg, ".test.echo.v1.Node"),
+				},
+			},
+			{
+				Name: proto.String("EchoRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("text", 1, opt, str, ""),
+					field("count", 2, opt, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+					field("color", 3, opt, descriptorpb.FieldDescriptorProto_TYPE_ENUM, ".test.echo.v1.Color"),
+					field("at", 4, opt, msg, ".google.protobuf.Timestamp"),
+					field("tags", 5, rep, str, ""),
+					field("attrs", 6, rep, msg, ".test.echo.v1.EchoRequest.AttrsEntry"),
+					field("node", 7, opt, msg, ".test.echo.v1.Node"),
+				},
+				NestedType: []*descriptorpb.DescriptorProto{{
+					Name: proto.String("AttrsEntry"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						field("key", 1, opt, str, ""),
+						field("value", 2, opt, descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+					},
+					Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+				}},
+			},
+			{
+				Name: proto.String("EchoResponse"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("text", 1, opt, str, ""),
+					field("auth", 2, opt, str, ""),
+					field("tenant", 3, opt, str, ""),
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("EchoService"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				{Name: proto.String("Echo"), InputType: proto.String(".test.echo.v1.EchoRequest"), OutputType: proto.String(".test.echo.v1.EchoResponse")},
+				{Name: proto.String("Fail"), InputType: proto.String(".test.echo.v1.EchoRequest"), OutputType: proto.String(".test.echo.v1.EchoResponse")},
+				{Name: proto.String("Watch"), InputType: proto.String(".test.echo.v1.EchoRequest"), OutputType: proto.String(".test.echo.v1.EchoResponse"), ServerStreaming: proto.Bool(true)},
+			},
+		}},
+		SourceCodeInfo: &descriptorpb.SourceCodeInfo{Location: []*descriptorpb.SourceCodeInfo_Location{
+			{Path: []int32{6, 0}, Span: []int32{0, 0, 0}, LeadingComments: proto.String(" Echo service for tests.\n")},
+			{Path: []int32{6, 0, 2, 0}, Span: []int32{0, 0, 0}, LeadingComments: proto.String(" Echo returns the request text.\n\n Also reports the caller's auth metadata.\n")},
+		}},
+	}
+}
+
+// protoJSONName mirrors protoc's lowerCamelCase json_name derivation.
+func protoJSONName(name string) string {
+	out := make([]byte, 0, len(name))
+	upper := false
+	for i := range len(name) {
+		if name[i] == '_' {
+			upper = true
+			continue
+		}
+		c := name[i]
+		if upper && c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		upper = false
+		out = append(out, c)
+	}
+	return string(out)
+}
+
+// echoDescriptorSet returns the base64 descriptor_set config value. The
+// timestamp import is deliberately left out so the global-registry
+// fallback is exercised.
+func echoDescriptorSet(t *testing.T) string {
+	t.Helper()
+	raw, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{echoFile()}})
+	if err != nil {
+		t.Fatalf("marshal descriptor set: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+// echoFiles links the test file into a registry for the server side.
+func echoFiles(t *testing.T) *protoregistry.Files {
+	t.Helper()
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: withGlobalDeps([]*descriptorpb.FileDescriptorProto{echoFile()})})
+	if err != nil {
+		t.Fatalf("linking test descriptors: %v", err)
+	}
+	return files
+}
+
+// startEchoServer runs the echo service (and server reflection) on a
+// loopback port and returns its address.
+func startEchoServer(t *testing.T) string {
+	t.Helper()
+	files := echoFiles(t)
+	d, err := files.FindDescriptorByName(echoService)
+	if err != nil {
+		t.Fatalf("finding service: %v", err)
+	}
+	svc := d.(protoreflect.ServiceDescriptor)
+	in, out := svc.Methods().Get(0).Input(), svc.Methods().Get(0).Output()
+
+	unary := func(fail bool) grpc.MethodHandler {
+		return func(_ any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+			req := dynamicpb.NewMessage(in)
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			if fail {
+				return nil, status.Error(codes.NotFound, "no such echo")
+			}
+			md, _ := metadata.FromIncomingContext(ctx)
+			resp := dynamicpb.NewMessage(out)
+			set := func(name, v string) {
+				resp.Set(out.Fields().ByName(protoreflect.Name(name)), protoreflect.ValueOfString(v))
+			}
+			set("text", req.Get(in.Fields().ByName("text")).String())
+			set("auth", first(md.Get("authorization")))
+			set("tenant", first(md.Get("x-tenant")))
+			return resp, nil
+		}
+	}
+	srv := grpc.NewServer()
+	srv.RegisterService(&grpc.ServiceDesc{
+		ServiceName: echoService,
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{
+			{MethodName: "Echo", Handler: unary(false)},
+			{MethodName: "Fail", Handler: unary(true)},
+		},
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "Watch",
+			ServerStreams: true,
+			Handler:       func(any, grpc.ServerStream) error { return status.Error(codes.Unimplemented, "") },
+		}},
+	}, struct{}{})
+	reflectionpb.RegisterServerReflectionServer(srv, reflection.NewServerV1(reflection.ServerOptions{
+		Services:           srv,
+		DescriptorResolver: files,
+	}))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+func first(vs []string) string {
+	if len(vs) == 0 {
+		return ""
+	}
+	return vs[0]
+}
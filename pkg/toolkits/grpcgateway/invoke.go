@@ -0,0 +1,247 @@
+package grpcgateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/txn2/mcp-data-platform/pkg/observability"
+	apigatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway"
+)
+
+// truncatedPreviewBytes is how much of an over-budget response is kept
+// as a text preview so the model can see its shape.
+const truncatedPreviewBytes = 4096
+
+// InvokeInput is the parsed argument shape for grpc_invoke_method.
+type InvokeInput struct {
+	Connection string `json:"connection"`
+	// Method is the canonical "pkg.Service/Method" name from
+	// grpc_list_methods.
+	Method string `json:"method"`
+	// Request is the request message in protojson form. Empty sends the
+	// message with every field at its default.
+	Request json.RawMessage `json:"request,omitempty"`
+	// Metadata is extra request metadata. Names are lowercased; reserved
+	// names (authorization, grpc-*, the connection's own credential and
+	// static headers) are refused.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// TimeoutSeconds shortens the connection's call_timeout for this
+	// call. It cannot lengthen it.
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+}
+
+// InvokeOutput is the structured result of one unary call. Code is the
+// gRPC status code name ("OK", "NOT_FOUND", ...). A non-OK code is the
+// upstream's answer, not a gateway failure, except for UNAVAILABLE and
+// DEADLINE_EXCEEDED, which mean the call never completed.
+type InvokeOutput struct {
+	Method  string `json:"method"`
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+	// Response is the protojson response message, present when the call
+	// returned OK and the encoding fit max_response_bytes.
+	Response json.RawMessage `json:"response,omitempty"`
+	// ResponseTruncated is set when the encoded response exceeded
+	// max_response_bytes; ResponsePreview then holds its first bytes.
+	ResponseTruncated bool   `json:"response_truncated,omitempty"`
+	ResponsePreview   string `json:"response_preview,omitempty"`
+	ResponseBytes     int    `json:"response_bytes,omitempty"`
+	DurationMs        int64  `json:"duration_ms"`
+	Error             string `json:"error,omitempty"`
+
+	code codes.Code
+}
+
+// invocation bundles what one call needs from its connection.
+type invocation struct {
+	cfg  Config
+	auth apigatewaykit.Authenticator
+	cc   grpc.ClientConnInterface
+	set  *serviceSet
+}
+
+// invoke runs one unary call. Argument errors (unknown method,
+// streaming method, malformed request JSON, reserved metadata) are
+// returned as errors; everything the upstream answers, including
+// transport failures, is reported in the InvokeOutput.
+func invoke(ctx context.Context, inv invocation, in InvokeInput) (InvokeOutput, error) {
+	md, ok := inv.set.lookupMethod(in.Method)
+	if !ok {
+		return InvokeOutput{}, fmt.Errorf("method %q not found (use grpc_list_methods to discover methods)", in.Method)
+	}
+	if label := streamingLabel(md); label != "" {
+		return InvokeOutput{}, fmt.Errorf("%s is a %s streaming method; only unary methods can be invoked", methodName(md), strings.ToLower(label))
+	}
+	req, err := decodeRequest(inv.set, md, in.Request)
+	if err != nil {
+		return InvokeOutput{}, err
+	}
+	callCtx, cancel := context.WithTimeout(ctx, callTimeout(inv.cfg, in.TimeoutSeconds))
+	defer cancel()
+	outMD, err := outgoingMetadata(callCtx, inv, md, in.Metadata)
+	if err != nil {
+		return InvokeOutput{}, err
+	}
+	callCtx = metadata.NewOutgoingContext(callCtx, outMD)
+
+	resp := dynamicpb.NewMessage(md.Output())
+	start := time.Now()
+	callErr := inv.cc.Invoke(callCtx, fullMethodPath(md), req, resp)
+	out := InvokeOutput{Method: methodName(md), DurationMs: time.Since(start).Milliseconds()}
+	st := status.Convert(callErr)
+	out.code = st.Code()
+	out.Code = codeName(st.Code())
+	if callErr != nil {
+		out.Message = st.Message()
+		if isGatewayFailure(st.Code()) {
+			out.Error = st.Message()
+		}
+		return out, nil
+	}
+	encodeResponse(&out, inv.set, resp, inv.cfg.MaxResponseBytes)
+	return out, nil
+}
+
+// decodeRequest transcodes the model's JSON into the method's input
+// message. Unknown fields are refused so a typo surfaces as an error
+// instead of a silently defaulted field.
+func decodeRequest(set *serviceSet, md protoreflect.MethodDescriptor, raw json.RawMessage) (*dynamicpb.Message, error) {
+	req := dynamicpb.NewMessage(md.Input())
+	if len(raw) == 0 || string(raw) == "null" {
+		return req, nil
+	}
+	opts := protojson.UnmarshalOptions{Resolver: dynamicpb.NewTypes(set.files)}
+	if err := opts.Unmarshal(raw, req); err != nil {
+		return nil, fmt.Errorf("request does not match %s: %w", md.Input().FullName(), err)
+	}
+	return req, nil
+}
+
+// encodeResponse renders the response message as protojson, replacing
+// it with a preview when it exceeds the connection's budget.
+func encodeResponse(out *InvokeOutput, set *serviceSet, resp *dynamicpb.Message, limit int64) {
+	b, err := protojson.MarshalOptions{Resolver: dynamicpb.NewTypes(set.files)}.Marshal(resp)
+	if err != nil {
+		out.Error = "encoding response: " + err.Error()
+		return
+	}
+	out.ResponseBytes = len(b)
+	if int64(len(b)) > limit {
+		out.ResponseTruncated = true
+		out.ResponsePreview = string(b[:min(len(b), truncatedPreviewBytes)])
+		return
+	}
+	out.Response = b
+}
+
+// callTimeout returns the effective deadline for one call: the
+// connection's call_timeout, shortened by a positive per-call value.
+func callTimeout(cfg Config, seconds int) time.Duration {
+	d := cfg.CallTimeout
+	if seconds > 0 {
+		if req := time.Duration(seconds) * time.Second; req < d {
+			d = req
+		}
+	}
+	return d
+}
+
+// metadataKeyPattern is the gRPC header-name alphabet. Binary ("-bin")
+// keys are refused because the tool carries string values only.
+var metadataKeyPattern = regexp.MustCompile(`^[0-9a-z_.-]+$`)
+
+// outgoingMetadata assembles the call's request metadata: the
+// connection's static headers, the credential its Authenticator would
+// set on an HTTP request, then the caller's extra metadata. The caller
+// cannot set or shadow anything the connection contributes.
+func outgoingMetadata(ctx context.Context, inv invocation, md protoreflect.MethodDescriptor, extra map[string]string) (metadata.MD, error) {
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, inv.cfg.Auth.BaseURL+fullMethodPath(md), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("building auth request: %w", err)
+	}
+	for k, v := range inv.cfg.Auth.StaticHeaders {
+		hreq.Header.Set(k, v)
+	}
+	if err := inv.auth.Apply(hreq); err != nil {
+		return nil, fmt.Errorf("applying connection auth: %w", err)
+	}
+	out := metadata.MD{}
+	for k, vs := range hreq.Header {
+		out.Append(strings.ToLower(k), vs...)
+	}
+	for k, v := range extra {
+		key := strings.ToLower(strings.TrimSpace(k))
+		switch {
+		case !metadataKeyPattern.MatchString(key), strings.HasSuffix(key, "-bin"):
+			return nil, fmt.Errorf("metadata key %q is not a valid ASCII gRPC header name", k)
+		case key == "authorization", strings.HasPrefix(key, "grpc-"), key == "te", key == "content-type", key == "user-agent":
+			return nil, fmt.Errorf("metadata key %q is reserved", k)
+		case len(out.Get(key)) > 0:
+			return nil, fmt.Errorf("metadata key %q is set by the connection and cannot be overridden", k)
+		}
+		out.Set(key, v)
+	}
+	return out, nil
+}
+
+// codeName renders a status code in the canonical upper snake case the
+// gRPC spec and every grpc tool use ("NOT_FOUND", not "NotFound").
+func codeName(c codes.Code) string {
+	var b strings.Builder
+	prevLower := false
+	for _, r := range c.String() {
+		if prevLower && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		prevLower = r >= 'a' && r <= 'z'
+		b.WriteRune(r)
+	}
+	return strings.ToUpper(b.String())
+}
+
+// isGatewayFailure reports whether a status means the call did not
+// complete against the upstream, as opposed to the upstream answering
+// with an error.
+func isGatewayFailure(c codes.Code) bool {
+	return c == codes.Unavailable || c == codes.DeadlineExceeded || c == codes.Canceled
+}
+
+// ClassifyInvokeOutcome maps a call's status code to the audit outcome
+// categories the api gateway uses, so audit_logs.error_category reads
+// the same for both kinds. Client-fault codes map to upstream_4xx and
+// server-fault codes to upstream_5xx, following the HTTP mapping in
+// the gRPC status code documentation.
+func ClassifyInvokeOutcome(out InvokeOutput) string {
+	switch out.code {
+	case codes.OK:
+		return observability.OutcomeOK
+	case codes.DeadlineExceeded:
+		return observability.OutcomeUpstreamTimeout
+	case codes.Unavailable, codes.Canceled:
+		return observability.OutcomeTransportErr
+	case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.PermissionDenied,
+		codes.Unauthenticated, codes.FailedPrecondition, codes.OutOfRange,
+		codes.ResourceExhausted, codes.Aborted:
+		return observability.OutcomeUpstream4xx
+	default:
+		return observability.OutcomeUpstream5xx
+	}
+}
+
+// errNoDescriptors is reported when a reflection connection has not
+// loaded its descriptors yet and the retry also failed.
+var errNoDescriptors = errors.New("grpcgateway: connection descriptors are not loaded")
@@ -0,0 +1,151 @@
+package grpcgateway
+
+import (
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// maxSchemaDepth caps message nesting in a rendered schema. Recursive
+// messages (a tree node holding its children) are cut off with an
+// open object once a type repeats on the current path; the depth cap
+// is the backstop for very deep but non-recursive graphs.
+const maxSchemaDepth = 12
+
+// messageSchema renders a message as the JSON Schema of its protojson
+// encoding, the form the model writes requests in and reads responses
+// from. Field names are the lowerCamelCase JSON names protojson emits;
+// protojson also accepts the original snake_case names on input.
+func messageSchema(md protoreflect.MessageDescriptor) map[string]any {
+	return newSchemaRenderer().message(md, 0)
+}
+
+type schemaRenderer struct {
+	onPath map[protoreflect.FullName]bool
+}
+
+func newSchemaRenderer() *schemaRenderer {
+	return &schemaRenderer{onPath: make(map[protoreflect.FullName]bool)}
+}
+
+func (r *schemaRenderer) message(md protoreflect.MessageDescriptor, depth int) map[string]any {
+	if wkt := wellKnownSchema(md); wkt != nil {
+		return wkt
+	}
+	if r.onPath[md.FullName()] || depth >= maxSchemaDepth {
+		return map[string]any{
+			"type":        "object",
+			"description": "Recursive reference to " + string(md.FullName()) + ".",
+		}
+	}
+	r.onPath[md.FullName()] = true
+	defer delete(r.onPath, md.FullName())
+
+	props := make(map[string]any, md.Fields().Len())
+	for i := range md.Fields().Len() {
+		fd := md.Fields().Get(i)
+		s := r.field(fd, depth)
+		if c := leadingComments(fd); c != "" {
+			s["description"] = c
+		}
+		props[fd.JSONName()] = s
+	}
+	out := map[string]any{"type": "object", "properties": props}
+	if c := leadingComments(md); c != "" {
+		out["description"] = c
+	}
+	return out
+}
+
+// field renders one field, wrapping list and map cardinality around
+// the element schema.
+func (r *schemaRenderer) field(fd protoreflect.FieldDescriptor, depth int) map[string]any {
+	switch {
+	case fd.IsMap():
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": r.singular(fd.MapValue(), depth),
+		}
+	case fd.IsList():
+		return map[string]any{"type": "array", "items": r.singular(fd, depth)}
+	default:
+		return r.singular(fd, depth)
+	}
+}
+
+// singular renders the element type of a field per the protojson
+// mapping: 64-bit integers are decimal strings (JSON numbers lose
+// precision past 2^53), bytes are base64 strings, and enums are their
+// value names.
+func (r *schemaRenderer) singular(fd protoreflect.FieldDescriptor, depth int) map[string]any {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]any{"type": "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]any{"type": "integer"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return map[string]any{"type": "string", "format": "int64"}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return map[string]any{"type": "number"}
+	case protoreflect.StringKind:
+		return map[string]any{"type": "string"}
+	case protoreflect.BytesKind:
+		return map[string]any{"type": "string", "format": "byte"}
+	case protoreflect.EnumKind:
+		return enumSchema(fd.Enum())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return r.message(fd.Message(), depth+1)
+	default:
+		return map[string]any{}
+	}
+}
+
+func enumSchema(ed protoreflect.EnumDescriptor) map[string]any {
+	names := make([]any, 0, ed.Values().Len())
+	for i := range ed.Values().Len() {
+		names = append(names, string(ed.Values().Get(i).Name()))
+	}
+	out := map[string]any{"type": "string", "enum": names}
+	if c := leadingComments(ed); c != "" {
+		out["description"] = c
+	}
+	return out
+}
+
+// wellKnownSchema returns the protojson special-case rendering for the
+// google.protobuf well-known types, or nil for any other message.
+func wellKnownSchema(md protoreflect.MessageDescriptor) map[string]any {
+	switch md.FullName() {
+	case "google.protobuf.Timestamp":
+		return map[string]any{"type": "string", "format": "date-time"}
+	case "google.protobuf.Duration":
+		return map[string]any{"type": "string", "description": "Duration in seconds with an \"s\" suffix, e.g. \"1.5s\"."}
+	case "google.protobuf.FieldMask":
+		return map[string]any{"type": "string", "description": "Comma-separated lowerCamelCase field paths."}
+	case "google.protobuf.Struct":
+		return map[string]any{"type": "object"}
+	case "google.protobuf.ListValue":
+		return map[string]any{"type": "array"}
+	case "google.protobuf.Value":
+		return map[string]any{}
+	case "google.protobuf.Empty":
+		return map[string]any{"type": "object", "properties": map[string]any{}}
+	case "google.protobuf.Any":
+		return map[string]any{
+			"type":        "object",
+			"description": "Any message, with an \"@type\" URL naming its type.",
+			"properties":  map[string]any{"@type": map[string]any{"type": "string"}},
+		}
+	case "google.protobuf.StringValue", "google.protobuf.BytesValue":
+		return map[string]any{"type": "string"}
+	case "google.protobuf.Int64Value", "google.protobuf.UInt64Value":
+		return map[string]any{"type": "string", "format": "int64"}
+	case "google.protobuf.Int32Value", "google.protobuf.UInt32Value":
+		return map[string]any{"type": "integer"}
+	case "google.protobuf.FloatValue", "google.protobuf.DoubleValue":
+		return map[string]any{"type": "number"}
+	case "google.protobuf.BoolValue":
+		return map[string]any{"type": "boolean"}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package grpcgateway
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	apigatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway"
+	"github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway/catalog"
+)
+
+func loadEchoSet(t *testing.T) *serviceSet {
+	t.Helper()
+	raw, err := base64.StdEncoding.DecodeString(echoDescriptorSet(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := loadDescriptorSet(raw)
+	if err != nil {
+		t.Fatalf("loadDescriptorSet: %v", err)
+	}
+	return set
+}
+
+func TestMessageSchema(t *testing.T) {
+	set := loadEchoSet(t)
+	m, ok := set.lookupMethod("test.echo.v1.EchoService.Echo")
+	if !ok {
+		t.Fatal("dotted method name did not resolve")
+	}
+	props := messageSchema(m.Input())["properties"].(map[string]any)
+
+	prop := func(name string) map[string]any { return props[name].(map[string]any) }
+	if got := prop("count")["type"]; got != "string" {
+		t.Errorf("int64 type = %v, want string", got)
+	}
+	if got := prop("color")["enum"].([]any); len(got) != 2 || got[1] != "RED" {
+		t.Errorf("enum = %v", got)
+	}
+	if got := prop("at")["format"]; got != "date-time" {
+		t.Errorf("timestamp format = %v", got)
+	}
+	if got := prop("tags")["type"]; got != "array" {
+		t.Errorf("repeated type = %v", got)
+	}
+	attrs := prop("attrs")
+	if attrs["type"] != "object" || attrs["additionalProperties"].(map[string]any)["type"] != "integer" {
+		t.Errorf("map schema = %v", attrs)
+	}
+	children := prop("node")["properties"].(map[string]any)["children"].(map[string]any)
+	item := children["items"].(map[string]any)
+	if _, recursed := item["properties"]; recursed {
+		t.Errorf("recursive message was expanded: %v", item)
+	}
+}
+
+func TestServiceSpecIsValidOpenAPI(t *testing.T) {
+	set := loadEchoSet(t)
+	content, ops, err := serviceSpec(set.services[0])
+	if err != nil {
+		t.Fatalf("serviceSpec: %v", err)
+	}
+	if ops != 3 {
+		t.Errorf("ops = %d, want 3", ops)
+	}
+	if _, err := catalog.ParseSpec(content); err != nil {
+		t.Fatalf("synthesized spec does not validate: %v", err)
+	}
+	items, err := apigatewaykit.BuildOperationItems(content, "echo")
+	if err != nil || len(items) != 3 {
+		t.Fatalf("BuildOperationItems = %d items, %v", len(items), err)
+	}
+	if name := serviceSpecName(set.services[0]); name != "test-echo-v1-echoservice" {
+		t.Errorf("spec name = %q", name)
+	}
+}
+
+func TestSyncCatalog(t *testing.T) {
+	ctx := context.Background()
+	store := catalog.NewMemoryStore()
+	set := loadEchoSet(t)
+	if err := store.CreateCatalog(ctx, catalog.Catalog{ID: "echo", Name: "echo", Version: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpsertSpec(ctx, "echo", catalog.SpecEntry{SpecName: "stale", Content: "{}", SourceKind: catalog.SourceGRPC}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpsertSpec(ctx, "echo", catalog.SpecEntry{SpecName: "manual", Content: "{}", SourceKind: catalog.SourceInline}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := syncCatalog(ctx, store, "echo", set); err != nil {
+		t.Fatalf("syncCatalog: %v", err)
+	}
+	specs, err := store.ListSpecs(ctx, "echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]catalog.SpecEntry{}
+	for _, s := range specs {
+		got[s.SpecName] = s
+	}
+	if _, ok := got["stale"]; ok {
+		t.Error("stale grpc spec was not pruned")
+	}
+	if _, ok := got["manual"]; !ok {
+		t.Error("operator spec was removed")
+	}
+	spec, ok := got["test-echo-v1-echoservice"]
+	if !ok || spec.SourceKind != catalog.SourceGRPC || spec.OperationCount != 3 {
+		t.Errorf("service spec = %+v", spec)
+	}
+	if spec.Description != "Echo service for tests." {
+		t.Errorf("description = %q", spec.Description)
+	}
+
+	if err := syncCatalog(ctx, store, "created", set); err != nil {
+		t.Fatalf("syncCatalog into new catalog: %v", err)
+	}
+	if _, err := store.GetCatalog(ctx, "created"); err != nil {
+		t.Errorf("catalog not created: %v", err)
+	}
+}
@@ -0,0 +1,452 @@
+package grpcgateway
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/txn2/mcp-data-platform/internal/logsan"
+	"github.com/txn2/mcp-data-platform/pkg/query"
+	"github.com/txn2/mcp-data-platform/pkg/semantic"
+	"github.com/txn2/mcp-data-platform/pkg/toolkit"
+	apigatewaykit "github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway"
+	"github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway/catalog"
+)
+
+// ErrConnectionExists is returned when AddConnection is called with a
+// name already registered in the toolkit.
+var ErrConnectionExists = errors.New("grpcgateway: connection already exists")
+
+// ErrConnectionNotFound is returned when an operation is requested
+// against a connection that has not been registered.
+var ErrConnectionNotFound = errors.New("grpcgateway: connection not found")
+
+const (
+	// ToolListMethods names the discovery tool: services and methods
+	// with their one-line proto comments.
+	ToolListMethods = "grpc_list_methods"
+	// ToolDescribeMethod names the tool returning one method's request
+	// and response JSON Schemas and full comments.
+	ToolDescribeMethod = "grpc_describe_method"
+	// ToolInvokeMethod names the unary invoke tool. Exported so audit
+	// code and tests reference the same literal as the registration
+	// site.
+	ToolInvokeMethod = "grpc_invoke_method"
+
+	// routeMethod is the HTTP method a gRPC call presents to the route
+	// policy. Every gRPC call is an HTTP/2 POST on its method path, so
+	// persona api_routes rules written as "POST /pkg.Service/*" read the
+	// way the wire does.
+	routeMethod = "POST"
+
+	logKeyConnection = "connection"
+	logKeyError      = "error"
+)
+
+// Toolkit is the gRPC gateway toolkit. A single Toolkit manages
+// multiple named connections, each addressing a different upstream
+// gRPC server. Connections are added at startup from the merged
+// YAML+DB config or at runtime via AddConnection when an operator
+// saves one through the portal.
+type Toolkit struct {
+	name        string
+	defaultName string
+
+	mu          sync.RWMutex
+	connections map[string]*conn
+	routePolicy apigatewaykit.RoutePolicy
+
+	// catalogStore receives one synthesized spec per service for
+	// connections with a catalog_id. nil skips catalog indexing; the
+	// grpc_* tools read descriptors directly and work either way.
+	catalogStore catalog.Store
+
+	semanticProvider semantic.Provider
+	queryProvider    query.Provider
+}
+
+// conn is one registered upstream. set is nil while a reflection
+// connection's descriptors have not loaded; loading is closed when the
+// reflection in flight finishes, so concurrent first calls reflect
+// once. mu guards both and is never held across the reflection call or
+// while taking the toolkit's mu.
+type conn struct {
+	cfg    Config
+	auth   apigatewaykit.Authenticator
+	client *grpc.ClientConn
+
+	mu      sync.Mutex
+	set     *serviceSet
+	loading chan struct{}
+}
+
+// loadedSet returns the connection's service set, nil when it has not
+// loaded.
+func (c *conn) loadedSet() *serviceSet {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.set
+}
+
+// New builds an empty toolkit. Connections are added later via
+// AddConnection.
+func New(name string) *Toolkit {
+	if name == "" {
+		name = Kind
+	}
+	return &Toolkit{
+		name:        name,
+		connections: make(map[string]*conn),
+	}
+}
+
+// NewMulti builds a Toolkit and pre-loads the given parsed connection
+// configs. Per-connection failures are logged and skipped so a single
+// bad connection does not block platform startup.
+func NewMulti(cfg MultiConfig) *Toolkit {
+	t := New(cfg.DefaultName)
+	t.defaultName = cfg.DefaultName
+	for instanceName, c := range cfg.Instances {
+		if c.ConnectionName == "" {
+			c.ConnectionName = instanceName
+		}
+		if err := t.addParsedConnection(instanceName, c); err != nil {
+			slog.Warn("grpcgateway: initial connection failed",
+				logKeyConnection, instanceName, logKeyError, err)
+		}
+	}
+	return t
+}
+
+// Kind returns the toolkit kind discriminator.
+func (*Toolkit) Kind() string { return Kind }
+
+// Name returns the toolkit instance name.
+func (t *Toolkit) Name() string { return t.name }
+
+// Connection returns the default connection name for audit
+// attribution when a tool call does not carry one.
+func (t *Toolkit) Connection() string { return t.defaultName }
+
+// SetSemanticProvider stores the semantic provider. Not consumed: gRPC
+// services carry no warehouse semantics.
+func (t *Toolkit) SetSemanticProvider(provider semantic.Provider) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.semanticProvider = provider
+}
+
+// SetQueryProvider stores the query provider. Not consumed.
+func (t *Toolkit) SetQueryProvider(provider query.Provider) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.queryProvider = provider
+}
+
+// SetRoutePolicy installs the per-route authorization gate shared with
+// the api gateway. A call presents as POST on its gRPC method path
+// ("/pkg.Service/Method"). nil disables per-route gating.
+func (t *Toolkit) SetRoutePolicy(p apigatewaykit.RoutePolicy) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.routePolicy = p
+}
+
+// SetCatalogStore wires the catalog store and indexes every connection
+// whose descriptors are already loaded, so connections registered
+// before the store became available appear in their catalog without
+// waiting for an admin save. Safe to call more than once.
+func (t *Toolkit) SetCatalogStore(s catalog.Store) {
+	t.mu.Lock()
+	t.catalogStore = s
+	conns := make(map[string]*conn, len(t.connections))
+	for name, c := range t.connections {
+		conns[name] = c
+	}
+	t.mu.Unlock()
+	for name, c := range conns {
+		if set := c.loadedSet(); set != nil {
+			t.indexCatalog(name, c.cfg, set)
+		}
+	}
+}
+
+// AddConnection parses a raw config map, dials the upstream, loads its
+// descriptors, and registers the connection. Used both at startup (via
+// NewMulti) and by the admin hot-add path.
+func (t *Toolkit) AddConnection(name string, config map[string]any) error {
+	cfg, err := ParseConfig(config)
+	if err != nil {
+		return err
+	}
+	if cfg.ConnectionName == "" {
+		cfg.ConnectionName = name
+	}
+	return t.addParsedConnection(name, cfg)
+}
+
+// addParsedConnection assumes the Config is already validated. A
+// descriptor set that does not link is a hard error (it will never
+// get better); a reflection failure is not, because the upstream may
+// simply not be up yet. That connection registers without methods and
+// reflection is retried on first use.
+func (t *Toolkit) addParsedConnection(name string, cfg Config) error {
+	auth, err := apigatewaykit.NewAuthenticator(cfg.Auth)
+	if err != nil {
+		return fmt.Errorf("grpcgateway: %s: %w", name, err)
+	}
+	var set *serviceSet
+	if len(cfg.DescriptorSet) > 0 {
+		if set, err = loadDescriptorSet(cfg.DescriptorSet); err != nil {
+			return fmt.Errorf("grpcgateway: %s: %w", name, err)
+		}
+	}
+	client, err := newClient(cfg)
+	if err != nil {
+		return fmt.Errorf("grpcgateway: %s: %w", name, err)
+	}
+	c := &conn{cfg: cfg, auth: auth, client: client, set: set}
+
+	t.mu.Lock()
+	if _, exists := t.connections[name]; exists {
+		t.mu.Unlock()
+		_ = client.Close()
+		return fmt.Errorf("grpcgateway: %s: %w", name, ErrConnectionExists)
+	}
+	t.connections[name] = c
+	t.mu.Unlock()
+
+	if set == nil {
+		if _, lerr := t.descriptors(context.Background(), name, c); lerr != nil {
+			slog.Warn("grpcgateway: reflection failed; will retry on first use",
+				logKeyConnection, logsan.SanitizeForLog(name), logKeyError, lerr)
+		}
+		return nil
+	}
+	t.indexCatalog(name, cfg, set)
+	return nil
+}
+
+// newClient builds the connection's client. grpc.NewClient does not
+// dial; the first RPC does, so an unreachable upstream never fails
+// registration.
+func newClient(cfg Config) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if !cfg.Plaintext {
+		tlsCfg, err := apigatewaykit.TLSClientConfig(cfg.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("building tls config: %w", err)
+		}
+		if tlsCfg == nil {
+			tlsCfg = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		creds = credentials.NewTLS(tlsCfg)
+	}
+	cc, err := grpc.NewClient(cfg.Target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("creating client: %w", err)
+	}
+	return cc, nil
+}
+
+// descriptors returns the connection's loaded service set, running
+// server reflection first when it has not succeeded yet. A caller that
+// finds a reflection already in flight waits for it rather than
+// starting another, and tries again itself if it failed. A successful
+// late load also indexes the catalog.
+func (t *Toolkit) descriptors(ctx context.Context, name string, c *conn) (*serviceSet, error) {
+	for {
+		c.mu.Lock()
+		set, wait := c.set, c.loading
+		if set == nil && c.cfg.Reflection && wait == nil {
+			done := make(chan struct{})
+			c.loading = done
+			c.mu.Unlock()
+			return t.reflect(ctx, name, c, done)
+		}
+		c.mu.Unlock()
+		switch {
+		case set != nil:
+			return set, nil
+		case wait == nil:
+			return nil, errNoDescriptors
+		}
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// reflect loads the connection's descriptors by server reflection,
+// records them, and closes done to release the callers waiting on it.
+// No lock is held during the network call or the catalog write.
+func (t *Toolkit) reflect(ctx context.Context, name string, c *conn, done chan struct{}) (*serviceSet, error) {
+	rctx, cancel := context.WithTimeout(ctx, c.cfg.ConnectTimeout)
+	defer cancel()
+	set, err := loadReflection(rctx, c.client)
+	c.mu.Lock()
+	if err == nil {
+		c.set = set
+	}
+	c.loading = nil
+	c.mu.Unlock()
+	close(done)
+	if err != nil {
+		return nil, err
+	}
+	t.indexCatalog(name, c.cfg, set)
+	return set, nil
+}
+
+// indexCatalog writes the connection's services into its catalog.
+// Failures are logged, never returned: the catalog is a discovery aid
+// and the tools work without it.
+func (t *Toolkit) indexCatalog(name string, cfg Config, set *serviceSet) {
+	if cfg.CatalogID == "" {
+		return
+	}
+	t.mu.RLock()
+	store := t.catalogStore
+	t.mu.RUnlock()
+	if store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout)
+	defer cancel()
+	if err := syncCatalog(ctx, store, cfg.CatalogID, set); err != nil {
+		slog.Warn("grpcgateway: catalog indexing failed",
+			logKeyConnection, logsan.SanitizeForLog(name), logKeyError, err)
+	}
+}
+
+// RemoveConnection drops a registered connection and closes its
+// client.
+func (t *Toolkit) RemoveConnection(name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c, exists := t.connections[name]
+	if !exists {
+		return fmt.Errorf("grpcgateway: %s: %w", name, ErrConnectionNotFound)
+	}
+	_ = c.client.Close()
+	delete(t.connections, name)
+	return nil
+}
+
+// HasConnection reports whether a connection with the given name is
+// registered.
+func (t *Toolkit) HasConnection(name string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	_, ok := t.connections[name]
+	return ok
+}
+
+// ListConnections returns details for every registered connection in
+// name-sorted order. Implements toolkit.ConnectionLister so the
+// platform's list_connections tool surfaces grpc connections. The
+// operation count is the number of methods loaded so far (zero for a
+// reflection connection that has not reached its upstream yet).
+func (t *Toolkit) ListConnections() []toolkit.ConnectionDetail {
+	t.mu.RLock()
+	conns := make(map[string]*conn, len(t.connections))
+	names := make([]string, 0, len(t.connections))
+	for name, c := range t.connections {
+		conns[name] = c
+		names = append(names, name)
+	}
+	t.mu.RUnlock()
+	sort.Strings(names)
+	out := make([]toolkit.ConnectionDetail, 0, len(names))
+	for _, name := range names {
+		c := conns[name]
+		desc := c.cfg.Description
+		if desc == "" {
+			desc = c.cfg.Target
+		}
+		ops := 0
+		if set := c.loadedSet(); set != nil {
+			ops = len(set.methods)
+		}
+		out = append(out, toolkit.ConnectionDetail{
+			Name:           name,
+			Description:    desc,
+			IsDefault:      name == t.defaultName,
+			CatalogID:      c.cfg.CatalogID,
+			OperationCount: ops,
+		})
+	}
+	return out
+}
+
+// Close closes every connection's client.
+func (t *Toolkit) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, c := range t.connections {
+		_ = c.client.Close()
+	}
+	return nil
+}
+
+// RegisterTools registers the gRPC gateway's MCP tools.
+func (t *Toolkit) RegisterTools(s *mcp.Server) {
+	mcp.AddTool(s, &mcp.Tool{
+		Name:  ToolListMethods,
+		Title: "List gRPC Methods",
+		Description: "List the services and methods a registered gRPC connection (kind=grpc) " +
+			"exposes, with the one-line summary from each method's proto comment. Use this " +
+			"BEFORE grpc_describe_method and grpc_invoke_method. Optional `service` restricts " +
+			"to one service; optional `query` does a case-insensitive substring match on the " +
+			"method name and summary. Streaming methods are listed but flagged; only unary " +
+			"methods can be invoked. Use list_connections to discover kind=grpc connections.",
+		InputSchema: listMethodsSchema,
+	}, t.handleListMethods)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:  ToolDescribeMethod,
+		Title: "Describe gRPC Method",
+		Description: "Return one gRPC method's full proto comment and the JSON Schema of its " +
+			"request and response messages in the protojson mapping grpc_invoke_method uses: " +
+			"lowerCamelCase field names, 64-bit integers as strings, enums as value names, " +
+			"Timestamp as RFC 3339. Pass the method name from grpc_list_methods.",
+		InputSchema: describeMethodSchema,
+	}, t.handleDescribeMethod)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:  ToolInvokeMethod,
+		Title: "Invoke gRPC Method",
+		Description: "Call a unary method on a registered gRPC connection. The request is a JSON " +
+			"object in the protojson form grpc_describe_method documents; the platform " +
+			"transcodes it to protobuf, applies the connection's auth (none/bearer/api_key/" +
+			"oauth/mtls) as request metadata, and returns the response transcoded back to JSON " +
+			"with its gRPC status code. Non-OK codes such as NOT_FOUND are the upstream's " +
+			"answer; UNAVAILABLE and DEADLINE_EXCEEDED mean the call did not complete. " +
+			"Responses above the connection's max_response_bytes are replaced by a preview " +
+			"and flagged.",
+		InputSchema: invokeMethodSchema,
+	}, t.handleInvoke)
+}
+
+// Tools returns the list of tool names this toolkit registers.
+func (*Toolkit) Tools() []string {
+	return []string{ToolListMethods, ToolDescribeMethod, ToolInvokeMethod}
+}
+
+// Compile-time checks for the optional toolkit interfaces the
+// platform's list_connections and admin hot-add paths consume.
+var (
+	_ toolkit.ConnectionLister  = (*Toolkit)(nil)
+	_ toolkit.ConnectionManager = (*Toolkit)(nil)
+)
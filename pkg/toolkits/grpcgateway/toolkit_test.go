@@ -0,0 +1,252 @@
+package grpcgateway
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/grpc/codes"
+
+	"github.com/txn2/mcp-data-platform/pkg/observability"
+	"github.com/txn2/mcp-data-platform/pkg/toolkits/apigateway/catalog"
+)
+
+type denyPolicy struct{ path string }
+
+func (d denyPolicy) Allow(_ context.Context, _, method, path string) (allowed bool, reason string) {
+	if method == routeMethod && path == d.path {
+		return false, "denied by test"
+	}
+	return true, ""
+}
+
+func newEchoToolkit(t *testing.T, extra map[string]any) *Toolkit {
+	t.Helper()
+	cfg := map[string]any{
+		"target":         startEchoServer(t),
+		"plaintext":      true,
+		"descriptor_set": echoDescriptorSet(t),
+	}
+	for k, v := range extra {
+		cfg[k] = v
+	}
+	tk := New("")
+	if err := tk.AddConnection("echo", cfg); err != nil {
+		t.Fatalf("AddConnection: %v", err)
+	}
+	t.Cleanup(func() { _ = tk.Close() })
+	return tk
+}
+
+func invokeEcho(t *testing.T, tk *Toolkit, in InvokeInput) (*mcp.CallToolResult, InvokeOutput) {
+	t.Helper()
+	in.Connection = "echo"
+	res, out, err := tk.handleInvoke(context.Background(), nil, in)
+	if err != nil {
+		t.Fatalf("handleInvoke: %v", err)
+	}
+	o, _ := out.(InvokeOutput)
+	return res, o
+}
+
+func TestInvokeUnary(t *testing.T) {
+	tk := newEchoToolkit(t, map[string]any{
+		"auth_mode":      "bearer",
+		"credential":     "secret-token",
+		"static_headers": map[string]any{"X-Tenant": "acme"},
+	})
+
+	res, out := invokeEcho(t, tk, InvokeInput{
+		Method:  "test.echo.v1.EchoService/Echo",
+		Request: json.RawMessage(`{"text":"hi","count":"3","color":"RED","at":"2026-01-02T03:04:05Z","attrs":{"a":1}}`),
+	})
+	if res.IsError || out.Code != "OK" {
+		t.Fatalf("result = %+v", out)
+	}
+	var resp map[string]string
+	if err := json.Unmarshal(out.Response, &resp); err != nil {
+		t.Fatalf("response: %v", err)
+	}
+	if resp["text"] != "hi" || resp["auth"] != "Bearer secret-token" || resp["tenant"] != "acme" {
+		t.Errorf("response = %v", resp)
+	}
+	if res.Meta[observability.MetaAuditOutcome] != observability.OutcomeOK {
+		t.Errorf("outcome = %v", res.Meta)
+	}
+}
+
+func TestInvokeUpstreamError(t *testing.T) {
+	tk := newEchoToolkit(t, nil)
+	res, out := invokeEcho(t, tk, InvokeInput{Method: "/test.echo.v1.EchoService/Fail"})
+	if out.Code != "NOT_FOUND" || out.Message != "no such echo" {
+		t.Fatalf("out = %+v", out)
+	}
+	if res.IsError {
+		t.Error("an upstream status must not be a gateway error")
+	}
+	if res.Meta[observability.MetaAuditOutcome] != observability.OutcomeUpstream4xx {
+		t.Errorf("outcome = %v", res.Meta)
+	}
+}
+
+func TestInvokeRefusals(t *testing.T) {
+	tk := newEchoToolkit(t, map[string]any{"static_headers": map[string]any{"x-tenant": "acme"}})
+	tk.SetRoutePolicy(denyPolicy{path: "/test.echo.v1.EchoService/Fail"})
+	cases := []struct {
+		name string
+		in   InvokeInput
+		want string
+	}{
+		{"unknown method", InvokeInput{Method: "test.echo.v1.EchoService/Nope"}, "not found"},
+		{"streaming", InvokeInput{Method: "test.echo.v1.EchoService/Watch"}, "streaming"},
+		{"unknown field", InvokeInput{Method: "test.echo.v1.EchoService/Echo", Request: json.RawMessage(`{"bogus":1}`)}, "does not match"},
+		{"reserved metadata", InvokeInput{Method: "test.echo.v1.EchoService/Echo", Metadata: map[string]string{"Authorization": "x"}}, "reserved"},
+		{"shadowed metadata", InvokeInput{Method: "test.echo.v1.EchoService/Echo", Metadata: map[string]string{"x-tenant": "evil"}}, "cannot be overridden"},
+		{"route policy", InvokeInput{Method: "test.echo.v1.EchoService/Fail"}, "denied by test"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, _ := invokeEcho(t, tk, tc.in)
+			text := res.Content[0].(*mcp.TextContent).Text
+			if !res.IsError || !strings.Contains(text, tc.want) {
+				t.Errorf("result = %s, want error containing %q", text, tc.want)
+			}
+		})
+	}
+}
+
+func TestReflectionListAndDescribe(t *testing.T) {
+	tk := New("")
+	if err := tk.AddConnection("echo", map[string]any{
+		"target":     startEchoServer(t),
+		"plaintext":  true,
+		"reflection": true,
+	}); err != nil {
+		t.Fatalf("AddConnection: %v", err)
+	}
+	t.Cleanup(func() { _ = tk.Close() })
+	tk.SetRoutePolicy(denyPolicy{path: "/test.echo.v1.EchoService/Fail"})
+
+	_, raw, err := tk.handleListMethods(context.Background(), nil, ListMethodsInput{Connection: "echo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := raw.(ListMethodsOutput)
+	if list.Total != 2 {
+		t.Fatalf("methods = %+v, want Echo and Watch (Fail hidden by policy)", list.Methods)
+	}
+	if list.Methods[0].Method != "test.echo.v1.EchoService/Echo" || list.Methods[0].Summary != "Echo returns the request text." {
+		t.Errorf("first method = %+v", list.Methods[0])
+	}
+	if list.Methods[1].Streaming != "server" {
+		t.Errorf("streaming flag = %q", list.Methods[1].Streaming)
+	}
+
+	_, raw, err = tk.handleDescribeMethod(context.Background(), nil, DescribeMethodInput{Connection: "echo", Method: "test.echo.v1.EchoService/Echo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc := raw.(DescribeMethodOutput)
+	if desc.Description != "Also reports the caller's auth metadata." {
+		t.Errorf("description = %q", desc.Description)
+	}
+	if _, ok := desc.RequestSchema["properties"].(map[string]any)["count"]; !ok {
+		t.Errorf("request schema = %v", desc.RequestSchema)
+	}
+
+	conns := tk.ListConnections()
+	if len(conns) != 1 || conns[0].OperationCount != 3 {
+		t.Errorf("connections = %+v", conns)
+	}
+}
+
+func TestClassifyInvokeOutcome(t *testing.T) {
+	cases := map[string]string{
+		"OK":                observability.OutcomeOK,
+		"DEADLINE_EXCEEDED": observability.OutcomeUpstreamTimeout,
+		"UNAVAILABLE":       observability.OutcomeTransportErr,
+		"PERMISSION_DENIED": observability.OutcomeUpstream4xx,
+		"INTERNAL":          observability.OutcomeUpstream5xx,
+	}
+	for c := range 17 {
+		out := InvokeOutput{}
+		out.code = codes.Code(c)
+		if want, ok := cases[codeName(out.code)]; ok {
+			if got := ClassifyInvokeOutcome(out); got != want {
+				t.Errorf("%s -> %s, want %s", codeName(out.code), got, want)
+			}
+			delete(cases, codeName(out.code))
+		}
+	}
+	if len(cases) != 0 {
+		t.Errorf("codes never produced: %v", cases)
+	}
+}
+
+func TestResolveMethodPath(t *testing.T) {
+	tk := newEchoToolkit(t, nil)
+	for _, name := range []string{
+		"test.echo.v1.EchoService/Echo",
+		"/test.echo.v1.EchoService/Echo",
+		"test.echo.v1.EchoService.Echo",
+		"  test.echo.v1.EchoService.Echo ",
+	} {
+		path, ok := tk.ResolveMethodPath(context.Background(), "echo", name)
+		if !ok || path != "/test.echo.v1.EchoService/Echo" {
+			t.Errorf("ResolveMethodPath(%q) = %q, %v", name, path, ok)
+		}
+	}
+	if _, ok := tk.ResolveMethodPath(context.Background(), "echo", "test.echo.v1.EchoService/Nope"); ok {
+		t.Error("an undeclared method must not resolve")
+	}
+	if _, ok := tk.ResolveMethodPath(context.Background(), "other", "test.echo.v1.EchoService/Echo"); ok {
+		t.Error("an unknown connection must not resolve")
+	}
+}
+
+func TestConcurrentReflectionAndListing(t *testing.T) {
+	tk := New("")
+	if err := tk.AddConnection("echo", map[string]any{
+		"target":     startEchoServer(t),
+		"plaintext":  true,
+		"reflection": true,
+		"catalog_id": "echo",
+	}); err != nil {
+		t.Fatalf("AddConnection: %v", err)
+	}
+	t.Cleanup(func() { _ = tk.Close() })
+	tk.SetCatalogStore(catalog.NewMemoryStore())
+
+	// Forget the descriptors so the callers below race to reflect them
+	// while others list connections and rewire the catalog store.
+	c := tk.connections["echo"]
+	c.mu.Lock()
+	c.set = nil
+	c.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			if _, err := tk.descriptors(context.Background(), "echo", c); err != nil {
+				t.Errorf("descriptors: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_ = tk.ListConnections()
+		}()
+		go func() {
+			defer wg.Done()
+			tk.SetCatalogStore(catalog.NewMemoryStore())
+		}()
+	}
+	wg.Wait()
+	if conns := tk.ListConnections(); conns[0].OperationCount != 3 {
+		t.Errorf("connections = %+v", conns)
+	}
+}
@@ -152,7 +152,7 @@ var manageAssetSchemaBase = json.RawMessage(`{
     },
     "change_summary": {
       "type": "string",
-      "description": "Human-readable summary of the change, recorded as the new version's change summary (update and patch actions). Defaults to a generated summary for a patch."
+      "description": "Human-readable summary of the change, recorded as the new version's change summary (update, patch and revert actions). Defaults to a generated summary for a patch and \"Reverted from vN\" for a revert."
     },
     "sources": {
       "type": "array",
@@ -178,7 +178,7 @@ var manageAssetSchemaBase = json.RawMessage(`{
     },
     "query": {
       "type": "string",
-      "description": "Free-text relevance query for the 'search' action. Ranks your saved assets by semantic + keyword similarity within your own assets."
+      "description": "Free-text relevance query for the 'search' action. Ranks your saved assets by semantic + keyword similarity within your own assets, matching their indexed content as well as their metadata; a content match returns the passage and a deep link to it."
     },
     "offset": {
       "type": "integer",
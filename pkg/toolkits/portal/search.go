@@ -27,7 +27,9 @@ const (
 // cannot search (it would otherwise scope to the shared "anonymous" bucket).
 // Ranking is hybrid (semantic + lexical) when an embedding provider is
 // configured and lexical-only otherwise, reported as the "ranking" field so the
-// caller knows which path produced the results.
+// caller knows which path produced the results. A hit found in an asset's
+// indexed content carries the matching passage, its link made absolute when
+// the portal's base URL is known.
 func (t *Toolkit) handleSearch(ctx context.Context, input manageAssetInput) (*mcp.CallToolResult, any, error) {
 	searcher, ok := t.assetStore.(portal.AssetSearcher)
 	if !ok {
@@ -61,6 +63,7 @@ func (t *Toolkit) handleSearch(ctx context.Context, input manageAssetInput) (*mc
 		QueryText: query,
 		OwnerID:   ownerID,
 		Limit:     input.Limit,
+		Passages:  true,
 	})
 	if err != nil {
 		return toolkit.ErrorResult("failed to search assets: " + err.Error()), nil, nil
@@ -68,6 +71,13 @@ func (t *Toolkit) handleSearch(ctx context.Context, input manageAssetInput) (*mc
 	if scored == nil {
 		scored = []portal.ScoredAsset{}
 	}
+	if t.baseURL != "" {
+		for i := range scored {
+			if p := scored[i].Passage; p != nil {
+				p.Link = t.baseURL + p.Link
+			}
+		}
+	}
 
 	return toolkit.JSONResultTyped(map[string]any{
 		"assets":     scored,
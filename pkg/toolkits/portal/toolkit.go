@@ -3,6 +3,7 @@
 package portal
 
 import (
+	"cmp"
 	"context"
 	"crypto/rand"
 	"encoding/hex"
@@ -999,7 +1000,7 @@ func (t *Toolkit) handleRevert(ctx context.Context, input manageAssetInput) (*mc
 		ContentType:   targetVer.ContentType,
 		SizeBytes:     int64(len(data)),
 		CreatedBy:     resolveOwnerEmail(ctx),
-		ChangeSummary: fmt.Sprintf("Reverted from v%d", input.Version),
+		ChangeSummary: cmp.Or(input.ChangeSummary, fmt.Sprintf("Reverted from v%d", input.Version)),
 	}
 	assignedVersion, err := t.versionStore.CreateVersion(ctx, av)
 	if err != nil {
@@ -1455,6 +1455,17 @@ func TestHandleRevert(t *testing.T) {
 	require.True(t, ok)
 	require.NoError(t, json.Unmarshal([]byte(tc.Text), &parsed))
 	assert.Equal(t, float64(2), parsed["version"])
+
+	result, _, err = tk.handleManageAsset(ctx, nil, manageAssetInput{
+		Action: "revert", AssetID: "a1", Version: 1, ChangeSummary: "Restore the Q2 figures",
+	})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	versions, _, err := vs.ListByAsset(ctx, "a1", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	assert.Equal(t, "Reverted from v1", versions[1].ChangeSummary)
+	assert.Equal(t, "Restore the Q2 figures", versions[2].ChangeSummary)
 }
 
 // TestHandleRevertAdminAnyOwner is the #1042 asset-side regression for the
@@ -0,0 +1,243 @@
+package trino
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	trinoclient "github.com/txn2/mcp-trino/pkg/client"
+)
+
+const (
+	// StatsProcessedRows and StatsProcessedBytes are the keys a trino_query
+	// result's stats carry what Trino read under: the rows and bytes its
+	// scans took in before any filter, which is what the engine's own client
+	// protocol reports as processedRows and processedBytes.
+	StatsProcessedRows  = "processed_rows"
+	StatsProcessedBytes = "processed_bytes"
+
+	// scanStatsTimeout bounds the lookup. It runs after the query has
+	// answered, so a slow coordinator delays the result rather than failing it.
+	scanStatsTimeout = 5 * time.Second
+
+	methodToolsCall = "tools/call"
+	methodToolsList = "tools/list"
+)
+
+// queryIDPattern is the shape of a Trino query id (20260118_101500_00042_abcde).
+// The id is spliced into SQL, so anything else is refused rather than quoted.
+var queryIDPattern = regexp.MustCompile(`^[0-9a-z_]+$`)
+
+// processedInputSQL sums what the query's leaf stages read. system.runtime.tasks
+// is where the coordinator keeps the per-task counters, and a finished query's
+// tasks stay listed there for as long as the query itself does. Every stage's
+// raw input counts the pages it took in, so a stage fed by an exchange counts
+// rows another stage already read; only the stages that read from a connector,
+// which are the ones reporting physical input, are summed.
+const processedInputSQL = `SELECT coalesce(sum(raw_input_rows), 0) AS processed_rows, ` +
+	`coalesce(sum(raw_input_bytes), 0) AS processed_bytes ` +
+	`FROM system.runtime.tasks WHERE query_id = '%[1]s' AND stage_id IN (` +
+	`SELECT stage_id FROM system.runtime.tasks WHERE query_id = '%[1]s' ` +
+	`GROUP BY stage_id HAVING sum(physical_input_bytes) > 0)`
+
+// ProcessedInputFunc reports the rows and bytes the query with the given id
+// read on a connection.
+type ProcessedInputFunc func(ctx context.Context, connection, queryID string) (rows, bytes int64, err error)
+
+// ChargedFunc reports whether a call is one whose scan is charged to someone,
+// and so worth the lookup.
+type ChargedFunc func(ctx context.Context) bool
+
+// queryRunner is the part of *trinoclient.Client the lookup uses.
+type queryRunner interface {
+	Query(ctx context.Context, sql string, opts trinoclient.QueryOptions) (*trinoclient.QueryResult, error)
+}
+
+var _ queryRunner = (*trinoclient.Client)(nil)
+
+// ProcessedInput is the toolkit's ProcessedInputFunc: it asks the connection
+// the query ran on.
+func (t *Toolkit) ProcessedInput(ctx context.Context, connection, queryID string) (rows, bytes int64, err error) {
+	client, err := t.execClient(connection)
+	if err != nil {
+		return 0, 0, err
+	}
+	return processedInput(ctx, client, queryID)
+}
+
+// processedInput sums the query's leaf-stage task counters.
+func processedInput(ctx context.Context, client queryRunner, queryID string) (rows, bytes int64, err error) {
+	if !queryIDPattern.MatchString(queryID) {
+		return 0, 0, fmt.Errorf("%q is not a trino query id", queryID)
+	}
+	res, err := client.Query(ctx, fmt.Sprintf(processedInputSQL, queryID), trinoclient.QueryOptions{Limit: 1})
+	if err != nil {
+		return 0, 0, fmt.Errorf("reading the query's task stats: %w", err)
+	}
+	if len(res.Rows) == 0 {
+		return 0, 0, nil
+	}
+	return asInt64(res.Rows[0][StatsProcessedRows]), asInt64(res.Rows[0][StatsProcessedBytes]), nil
+}
+
+// ScanStatsMiddleware adds what Trino read, as lookup reports it, to the stats
+// of every successful trino_query call charged reports true for, and declares
+// the two keys in the tool's advertised output schema.
+//
+// mcp-trino's stats say how many rows came back, which is not what a query
+// cost: a COUNT(*) over a year of events returns one row. A managed script's
+// rows_scanned quota needs the other number, and only the engine has it. The
+// lookup is a second query to the coordinator, so only the calls charged for
+// a scan pay for it.
+//
+// It is a receiving middleware rather than a trinotools.ToolMiddleware because
+// the SDK sets a typed handler's structured output after that handler, and
+// the toolkit's middleware, have returned. A result whose query id is missing,
+// or whose tasks cannot be read, is returned without the keys, and the script
+// runtime refuses to go on under a scan quota it cannot charge.
+func ScanStatsMiddleware(lookup ProcessedInputFunc, charged ChargedFunc) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			result, err := next(ctx, method, req)
+			if err != nil {
+				return result, err
+			}
+			switch method {
+			case methodToolsCall:
+				if charged(ctx) {
+					addScanStats(ctx, lookup, req, result)
+				}
+			case methodToolsList:
+				declareScanStats(result)
+			}
+			return result, nil
+		}
+	}
+}
+
+// addScanStats looks up and merges the processed counts into one trino_query
+// result.
+func addScanStats(ctx context.Context, lookup ProcessedInputFunc, req mcp.Request, result mcp.Result) {
+	params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+	if !ok || params.Name != toolQuery {
+		return
+	}
+	call, ok := result.(*mcp.CallToolResult)
+	if !ok || call == nil || call.IsError {
+		return
+	}
+	out, stats := structuredStats(call.StructuredContent)
+	queryID, _ := stats["query_id"].(string)
+	if queryID == "" {
+		return
+	}
+	var args struct {
+		Connection string `json:"connection"`
+	}
+	_ = json.Unmarshal(params.Arguments, &args)
+
+	lookupCtx, cancel := context.WithTimeout(ctx, scanStatsTimeout)
+	defer cancel()
+	rows, bytes, err := lookup(lookupCtx, args.Connection, queryID)
+	if err != nil {
+		slog.Warn("trino: processed stats unavailable", "query_id", queryID, logKeyError, err)
+		return
+	}
+	stats[StatsProcessedRows] = rows
+	stats[StatsProcessedBytes] = bytes
+	call.StructuredContent = out
+}
+
+// structuredStats returns a result's structured content as a map, and the
+// stats object inside it, or nils when there is none to add to.
+func structuredStats(sc any) (out, stats map[string]any) {
+	if sc == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(sc)
+	if err != nil {
+		return nil, nil
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, nil
+	}
+	stats, _ = out["stats"].(map[string]any)
+	return out, stats
+}
+
+// declareScanStats adds the processed counts to the stats object of
+// trino_query's advertised output schema. mcp-trino closes that object, so a
+// client validating structuredContent would otherwise reject the keys.
+func declareScanStats(result mcp.Result) {
+	list, ok := result.(*mcp.ListToolsResult)
+	if !ok || list == nil {
+		return
+	}
+	for i, tool := range list.Tools {
+		if tool == nil || tool.Name != toolQuery || tool.OutputSchema == nil {
+			continue
+		}
+		schema, ok := withScanStatsSchema(tool.OutputSchema)
+		if !ok {
+			return
+		}
+		cp := *tool
+		cp.OutputSchema = schema
+		list.Tools[i] = &cp
+	}
+}
+
+// withScanStatsSchema returns a copy of schema, normalized through JSON, whose
+// stats property declares the processed counts. It reports false when schema
+// has no stats object to extend.
+func withScanStatsSchema(schema any) (map[string]any, bool) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, false
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, false
+	}
+	props, _ := obj["properties"].(map[string]any)
+	stats, _ := props["stats"].(map[string]any)
+	if stats == nil {
+		return nil, false
+	}
+	statsProps, _ := stats["properties"].(map[string]any)
+	if statsProps == nil {
+		statsProps = map[string]any{}
+	}
+	statsProps[StatsProcessedRows] = map[string]any{
+		"type": "integer", "description": "rows Trino read to answer the query, before any filter",
+	}
+	statsProps[StatsProcessedBytes] = map[string]any{
+		"type": "integer", "description": "bytes Trino read to answer the query",
+	}
+	stats["properties"] = statsProps
+	return obj, true
+}
+
+// asInt64 reads a count the way the client may hand it back.
+func asInt64(v any) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case int:
+		return int64(n)
+	case float64:
+		return int64(n)
+	case json.Number:
+		i, _ := n.Int64()
+		return i
+	case string:
+		i, _ := strconv.ParseInt(n, 10, 64)
+		return i
+	}
+	return 0
+}
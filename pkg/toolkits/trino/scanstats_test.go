@@ -0,0 +1,167 @@
+package trino
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	trinoclient "github.com/txn2/mcp-trino/pkg/client"
+)
+
+// taskStatsRunner answers the task-stats lookup and records the SQL it ran.
+type taskStatsRunner struct {
+	sql  string
+	rows []map[string]any
+	err  error
+}
+
+func (r *taskStatsRunner) Query(_ context.Context, sql string, _ trinoclient.QueryOptions) (*trinoclient.QueryResult, error) {
+	r.sql = sql
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &trinoclient.QueryResult{Rows: r.rows}, nil
+}
+
+func TestProcessedInput(t *testing.T) {
+	t.Run("sums the query's tasks", func(t *testing.T) {
+		runner := &taskStatsRunner{rows: []map[string]any{{"processed_rows": int64(48000), "processed_bytes": int64(3100000)}}}
+		rows, bytes, err := processedInput(context.Background(), runner, "20260118_101500_00042_abcde")
+		require.NoError(t, err)
+		assert.Equal(t, int64(48000), rows)
+		assert.Equal(t, int64(3100000), bytes)
+		assert.Contains(t, runner.sql, "system.runtime.tasks")
+		assert.Contains(t, runner.sql, "query_id = '20260118_101500_00042_abcde'")
+		assert.Contains(t, runner.sql, "HAVING sum(physical_input_bytes) > 0", "only the stages that read from a connector count")
+	})
+
+	t.Run("refuses an id that is not one", func(t *testing.T) {
+		runner := &taskStatsRunner{}
+		_, _, err := processedInput(context.Background(), runner, "x' OR '1'='1")
+		require.Error(t, err)
+		assert.Empty(t, runner.sql, "nothing is sent for a malformed id")
+	})
+
+	t.Run("reports a failed lookup", func(t *testing.T) {
+		_, _, err := processedInput(context.Background(), &taskStatsRunner{err: errors.New("access denied")}, "20260118_101500_00042_abcde")
+		assert.ErrorContains(t, err, "access denied")
+	})
+}
+
+// callQuery runs one tools/call through the middleware over a handler that
+// returns result.
+func callQuery(t *testing.T, lookup ProcessedInputFunc, tool string, result *mcp.CallToolResult) *mcp.CallToolResult {
+	t.Helper()
+	handler := ScanStatsMiddleware(lookup, func(context.Context) bool { return true })(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		return result, nil
+	})
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: tool, Arguments: json.RawMessage(`{"sql":"SELECT 1","connection":"warehouse"}`)}}
+	out, err := handler(context.Background(), methodToolsCall, req)
+	require.NoError(t, err)
+	call, ok := out.(*mcp.CallToolResult)
+	require.True(t, ok)
+	return call
+}
+
+func queryResult() *mcp.CallToolResult {
+	return &mcp.CallToolResult{StructuredContent: map[string]any{
+		"rows":  []any{map[string]any{"n": 1}},
+		"stats": map[string]any{"row_count": 1, "truncated": false, "query_id": "20260118_101500_00042_abcde"},
+	}}
+}
+
+func TestScanStatsMiddleware_AddsProcessedCounts(t *testing.T) {
+	var connection string
+	lookup := func(_ context.Context, conn, _ string) (rows, bytes int64, err error) {
+		connection = conn
+		return 48000, 3100000, nil
+	}
+	call := callQuery(t, lookup, toolQuery, queryResult())
+
+	out, ok := call.StructuredContent.(map[string]any)
+	require.True(t, ok)
+	stats, ok := out["stats"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, int64(48000), stats[StatsProcessedRows])
+	assert.Equal(t, int64(3100000), stats[StatsProcessedBytes])
+	assert.Equal(t, "warehouse", connection, "the lookup asks the connection the query ran on")
+}
+
+func TestScanStatsMiddleware_LeavesOtherResultsAlone(t *testing.T) {
+	lookup := func(context.Context, string, string) (rows, bytes int64, err error) {
+		return 0, 0, errors.New("the lookup should not run")
+	}
+	failing := func(context.Context, string, string) (rows, bytes int64, err error) {
+		return 0, 0, errors.New("tasks expired")
+	}
+	found := func(context.Context, string, string) (rows, bytes int64, err error) { return 1, 1, nil }
+
+	for name, tc := range map[string]struct {
+		lookup ProcessedInputFunc
+		tool   string
+		result *mcp.CallToolResult
+	}{
+		"another tool":    {lookup, toolExplain, queryResult()},
+		"a failed call":   {lookup, toolQuery, &mcp.CallToolResult{IsError: true}},
+		"no query id":     {found, toolQuery, &mcp.CallToolResult{StructuredContent: map[string]any{"stats": map[string]any{}}}},
+		"a failed lookup": {failing, toolQuery, queryResult()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			call := callQuery(t, tc.lookup, tc.tool, tc.result)
+			out, _ := call.StructuredContent.(map[string]any)
+			stats, _ := out["stats"].(map[string]any)
+			assert.NotContains(t, stats, StatsProcessedRows)
+		})
+	}
+}
+
+func TestScanStatsMiddleware_LooksUpOnlyForChargedCalls(t *testing.T) {
+	lookup := func(context.Context, string, string) (rows, bytes int64, err error) {
+		t.Error("the lookup ran for a call nobody is charged for")
+		return 1, 1, nil
+	}
+	result := queryResult()
+	handler := ScanStatsMiddleware(lookup, func(context.Context) bool { return false })(
+		func(context.Context, string, mcp.Request) (mcp.Result, error) { return result, nil })
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: toolQuery, Arguments: json.RawMessage(`{"sql":"SELECT 1"}`)}}
+	_, err := handler(context.Background(), methodToolsCall, req)
+	require.NoError(t, err)
+	stats, _ := result.StructuredContent.(map[string]any)["stats"].(map[string]any)
+	assert.NotContains(t, stats, StatsProcessedRows)
+}
+
+func TestScanStatsMiddleware_DeclaresTheKeysInTheSchema(t *testing.T) {
+	closed := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"stats": map[string]any{
+				"type":                 "object",
+				"additionalProperties": false,
+				"properties":           map[string]any{"row_count": map[string]any{"type": "integer"}},
+			},
+		},
+	}
+	list := &mcp.ListToolsResult{Tools: []*mcp.Tool{
+		{Name: toolQuery, OutputSchema: closed},
+		{Name: toolExplain, OutputSchema: closed},
+	}}
+	handler := ScanStatsMiddleware(nil, nil)(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		return list, nil
+	})
+	_, err := handler(context.Background(), methodToolsList, &mcp.ListToolsRequest{})
+	require.NoError(t, err)
+
+	schema, ok := list.Tools[0].OutputSchema.(map[string]any)
+	require.True(t, ok)
+	statsProps := schema["properties"].(map[string]any)["stats"].(map[string]any)["properties"].(map[string]any)
+	assert.Contains(t, statsProps, StatsProcessedRows)
+	assert.Contains(t, statsProps, StatsProcessedBytes)
+	assert.Contains(t, statsProps, "row_count")
+
+	untouched := closed["properties"].(map[string]any)["stats"].(map[string]any)["properties"].(map[string]any)
+	assert.NotContains(t, untouched, StatsProcessedRows, "the registered schema is copied, not changed")
+}
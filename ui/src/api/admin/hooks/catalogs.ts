@@ -24,9 +24,11 @@ export interface APICatalogSpec {
   // "embedded" specs are bundled from a connection's own toolkit and
   // re-seeded at every startup (see pkg/toolkits/apigateway/catalog
   // SourceEmbedded and seedAdminSelfConnection), so portal edits/deletes
-  // do not persist; the portal treats them as read-only. Keep this union
-  // in sync with the backend's source-kind constants.
-  source_kind: "inline" | "upload" | "url" | "embedded";
+  // do not persist; the portal treats them as read-only. "grpc" specs are
+  // synthesized from a grpc connection's protobuf descriptors and are
+  // read-only for the same reason. Keep this union in sync with the
+  // backend's source-kind constants.
+  source_kind: "inline" | "upload" | "url" | "embedded" | "grpc";
   source_url?: string;
   etag?: string;
   // Operator-set per-spec URL prefix applied at api_list_endpoints
@@ -249,10 +249,22 @@ export function useAdminVersionContent(assetId: string | null, version: number)
 export function useAdminRevertVersion() {
   const queryClient = useQueryClient();
   return useMutation({
-    mutationFn: ({ assetId, version }: { assetId: string; version: number }) =>
-      apiFetch(`/assets/${assetId}/versions/${version}/revert`, {
+    mutationFn: ({
+      assetId,
+      version,
+      changeSummary,
+    }: {
+      assetId: string;
+      version: number;
+      changeSummary?: string;
+    }) => {
+      const headers: Record<string, string> = {};
+      if (changeSummary) headers["X-Change-Summary"] = changeSummary;
+      return apiFetch(`/assets/${assetId}/versions/${version}/revert`, {
         method: "POST",
-      }),
+        headers,
+      });
+    },
     onSuccess: (_data, { assetId }) => {
       queryClient.invalidateQueries({ queryKey: ["admin", "asset", assetId] });
       queryClient.invalidateQueries({ queryKey: ["admin", "asset-content", assetId] });
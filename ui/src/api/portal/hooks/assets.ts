@@ -21,6 +21,7 @@ import type {
   ShareResponse,
   ScoredAsset,
   CreateShareBody,
+  VersionDiff,
 } from "../types";
 
 // Re-exported so existing importers (assets.test.ts and page components) keep
@@ -366,13 +367,38 @@ export function useVersionContent(assetId: string, version: number) {
   });
 }
 
+// useVersionDiff compares two versions; from defaults server-side to the
+// version before to.
+export function useVersionDiff(assetId: string, from: number, to: number) {
+  return useQuery({
+    queryKey: ["version-diff", assetId, from, to],
+    queryFn: () =>
+      apiFetch<VersionDiff>(
+        `/assets/${assetId}/versions/diff?from=${from}&to=${to}`,
+      ),
+    enabled: !!assetId && from > 0 && to > from,
+  });
+}
+
 export function useRevertVersion() {
   const qc = useQueryClient();
   return useMutation({
-    mutationFn: ({ assetId, version }: { assetId: string; version: number }) =>
-      apiFetch(`/assets/${assetId}/versions/${version}/revert`, {
+    mutationFn: ({
+      assetId,
+      version,
+      changeSummary,
+    }: {
+      assetId: string;
+      version: number;
+      changeSummary?: string;
+    }) => {
+      const headers: Record<string, string> = {};
+      if (changeSummary) headers["X-Change-Summary"] = changeSummary;
+      return apiFetch(`/assets/${assetId}/versions/${version}/revert`, {
         method: "POST",
-      }),
+        headers,
+      });
+    },
     onSuccess: (_data, { assetId }) => {
       void qc.invalidateQueries({ queryKey: ["asset", assetId] });
       void qc.invalidateQueries({ queryKey: ["asset-content", assetId] });
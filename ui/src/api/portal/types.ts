@@ -47,6 +47,53 @@ export interface AssetVersion {
   created_at: string;
 }
 
+// Difference between two versions of an asset (Go versionDiffResponse). format
+// names the populated field: blocks for HTML, cells for CSV/TSV, unified for
+// every other text type.
+export interface VersionDiff {
+  asset_id: string;
+  from_version: number;
+  to_version: number;
+  format: "structure" | "cells" | "lines";
+  identical: boolean;
+  blocks?: BlockChange[];
+  cells?: CellDiff;
+  unified?: string;
+}
+
+export type VersionChangeOp = "added" | "removed" | "changed";
+
+// One element-level HTML change, read as the viewer renders it (Go
+// textpatch.BlockChange).
+export interface BlockChange {
+  op: VersionChangeOp;
+  element: string;
+  section?: string;
+  old?: string;
+  new?: string;
+}
+
+export interface CellDiff {
+  added_columns?: string[];
+  removed_columns?: string[];
+  rows?: RowChange[];
+  truncated?: boolean;
+}
+
+// Row numbers count data rows from 1, in the version the row comes from.
+export interface RowChange {
+  op: VersionChangeOp;
+  old_row?: number;
+  new_row?: number;
+  cells: CellChange[];
+}
+
+export interface CellChange {
+  column: string;
+  old?: string;
+  new?: string;
+}
+
 export type {
   Provenance,
   ProvenanceCapture,
@@ -313,6 +360,15 @@ export type ScoredInsight = Insight & { score: number };
 export interface ScoredAsset {
   asset: Asset;
   score: number;
+  // Set when the asset's indexed content matched: the best passage and a
+  // link that lands on it (Go AssetPassage).
+  passage?: AssetPassage;
+}
+export interface AssetPassage {
+  section?: string;
+  fragment?: string;
+  excerpt: string;
+  link: string;
 }
 export interface ScoredCollection {
   collection: Collection;
@@ -360,17 +416,28 @@ export type ValidationState = "none" | "pending" | "validated" | "disputed";
 
 // A W3C-style text-quote anchor (markdown/plaintext) or a collection section
 // anchor. null means the thread is object-level (the whole target).
-export interface TextQuoteAnchor {
+/** Where a document anchor points and whether it still resolves, filled in by the server. */
+export interface DocumentAnchorState {
+  selector?: string;
+  section?: string;
+  state?: "active" | "orphaned";
+  version?: number;
+  orphaned_at_version?: number;
+}
+export interface TextQuoteAnchor extends DocumentAnchorState {
   type: "text_quote";
   exact: string;
   prefix?: string;
   suffix?: string;
 }
+export interface RegionAnchor extends DocumentAnchorState {
+  type: "region";
+}
 export interface SectionAnchor {
   type: "section";
   section_id: string;
 }
-export type ThreadAnchor = TextQuoteAnchor | SectionAnchor;
+export type ThreadAnchor = TextQuoteAnchor | RegionAnchor | SectionAnchor;
 
 export interface Thread {
   id: string;
@@ -8,7 +8,7 @@ import { LoadingIndicator } from "@/components/LoadingIndicator";
 import { useIdleGate } from "@/lib/idle";
 import { isThumbnailSupported, THUMBNAIL_SOURCE_LIMIT } from "@/lib/thumbnailSupport";
 import { isEditableContent } from "@/components/renderers/registry";
-import { type AssetViewerProps, type ViewMode } from "./assetviewer/types";
+import { type AssetViewerProps, type VersionView, type ViewMode } from "./assetviewer/types";
 import { ThumbnailGeneratorWithInvalidation } from "./assetviewer/ThumbnailGeneratorWithInvalidation";
 import { AssetViewerToolbar } from "./assetviewer/AssetViewerToolbar";
 import { AssetContentView } from "./assetviewer/AssetContentView";
@@ -46,6 +46,8 @@ export function AssetViewer({
   onSelectVersion,
   versionContent,
   versionContentLoading,
+  versionDiff,
+  versionDiffLoading,
 }: AssetViewerProps) {
   const [shareOpen, setShareOpen] = useState(false);
   const [sidebarOpen, setSidebarOpen] = useState(false);
@@ -60,6 +62,8 @@ export function AssetViewer({
   const [changeSummaryOpen, setChangeSummaryOpen] = useState(false);
   const [changeSummary, setChangeSummary] = useState("");
   const [revertModalOpen, setRevertModalOpen] = useState(false);
+  const [revertSummary, setRevertSummary] = useState("");
+  const [versionView, setVersionView] = useState<VersionView>("version");
 
   const [viewMode, setViewMode] = useState<ViewMode>("preview");
   const [editedContent, setEditedContent] = useState<string>("");
@@ -226,9 +230,11 @@ export function AssetViewer({
 
   function handleConfirmRevert() {
     if (revertMutation && asset && selectedVersion != null) {
-      revertMutation.mutate({ assetId: asset.id, version: selectedVersion }, {
+      const changeSummary = revertSummary.trim() || undefined;
+      revertMutation.mutate({ assetId: asset.id, version: selectedVersion, changeSummary }, {
         onSuccess: () => {
           setRevertModalOpen(false);
+          setRevertSummary("");
           onSelectVersion?.(null);
         },
       });
@@ -277,6 +283,10 @@ export function AssetViewer({
           saveStatus={saveStatus}
           versionContentLoading={versionContentLoading}
           versionContent={versionContent}
+          versionView={versionView}
+          onSetVersionView={setVersionView}
+          versionDiff={versionDiff}
+          versionDiffLoading={versionDiffLoading}
           editedContent={editedContent}
           onSourceChange={(v) => { setEditedContent(v); setDirty(true); }}
         />
@@ -342,6 +352,8 @@ export function AssetViewer({
         revertModalOpen={revertModalOpen}
         selectedVersion={selectedVersion}
         onRevertClose={() => setRevertModalOpen(false)}
+        revertSummary={revertSummary}
+        onRevertSummaryChange={setRevertSummary}
         onConfirmRevert={handleConfirmRevert}
         revertMutation={revertMutation}
       />
@@ -1,11 +1,12 @@
 import { lazy, Suspense } from "react";
-import type { Asset, AssetVersion, SharePermission } from "@/api/portal/types";
+import type { Asset, AssetVersion, SharePermission, VersionDiff } from "@/api/portal/types";
 import { ContentRenderer } from "@/components/renderers/ContentRenderer";
 import { LoadingIndicator } from "@/components/LoadingIndicator";
 import { exceedsInlineLimit, rendersFromURL } from "@/components/renderers/registry";
 import { useContentUrl } from "@/lib/useContentUrl";
-import { SaveControls, TooLarge, VersionControls, ViewModeToggle } from "./contentControls";
-import type { MutationLike, ViewMode } from "./types";
+import { SaveControls, TooLarge, VersionControls, VersionViewToggle, ViewModeToggle } from "./contentControls";
+import type { MutationLike, RevertVars, VersionView, ViewMode } from "./types";
+import { VersionDiffView } from "./VersionDiffView";
 
 const SourceEditor = lazy(() =>
   import("@/components/SourceEditor").then((m) => ({ default: m.SourceEditor })),
@@ -24,7 +25,7 @@ interface AssetContentViewProps {
   selectedVersion?: number | null;
   isOwner: boolean;
   sharePermission?: SharePermission;
-  revertMutation?: MutationLike<{ assetId: string; version: number }>;
+  revertMutation?: MutationLike<RevertVars>;
   onRevert: () => void;
   onSaveContent: () => void;
   hasChanges: boolean;
@@ -32,6 +33,10 @@ interface AssetContentViewProps {
   saveStatus: "idle" | "saved" | "error";
   versionContentLoading?: boolean;
   versionContent?: string;
+  versionView: VersionView;
+  onSetVersionView: (view: VersionView) => void;
+  versionDiff?: VersionDiff;
+  versionDiffLoading?: boolean;
   editedContent: string;
   onSourceChange: (v: string) => void;
 }
@@ -57,9 +62,15 @@ export function AssetContentView({
   saveStatus,
   versionContentLoading,
   versionContent,
+  versionView,
+  onSetVersionView,
+  versionDiff,
+  versionDiffLoading,
   editedContent,
   onSourceChange,
 }: AssetContentViewProps) {
+  const canCompare = viewingOldVersion && (versionDiff !== undefined || !!versionDiffLoading);
+  const showingChanges = canCompare && versionView === "changes";
   return (
     <>
       <div className="flex items-center gap-2">
@@ -68,6 +79,11 @@ export function AssetContentView({
           viewMode={viewMode}
           onSetViewMode={onSetViewMode}
         />
+        <VersionViewToggle
+          show={canCompare}
+          versionView={versionView}
+          onSetVersionView={onSetVersionView}
+        />
         <VersionControls
           asset={asset}
           versions={versions}
@@ -90,7 +106,9 @@ export function AssetContentView({
       </div>
 
       {/* Content display */}
-      {viewingOldVersion ? (
+      {showingChanges ? (
+        versionDiff ? <VersionDiffView diff={versionDiff} /> : <LoadingIndicator />
+      ) : viewingOldVersion ? (
         versionContentLoading ? (
           <LoadingIndicator />
         ) : (
@@ -1,7 +1,7 @@
 import type { Asset } from "@/api/portal/types";
 import { ConfirmDialog } from "@/components/ConfirmDialog";
 import { ChangeSummaryDialog } from "./ChangeSummaryDialog";
-import type { MutationLike } from "./types";
+import type { MutationLike, RevertVars } from "./types";
 
 interface AssetViewerModalsProps {
   asset: Asset;
@@ -25,8 +25,10 @@ interface AssetViewerModalsProps {
   revertModalOpen: boolean;
   selectedVersion?: number | null;
   onRevertClose: () => void;
+  revertSummary: string;
+  onRevertSummaryChange: (v: string) => void;
   onConfirmRevert: () => void;
-  revertMutation?: MutationLike<{ assetId: string; version: number }>;
+  revertMutation?: MutationLike<RevertVars>;
 }
 
 /** The four questions the asset viewer stops to ask before it acts. */
@@ -48,6 +50,8 @@ export function AssetViewerModals({
   revertModalOpen,
   selectedVersion,
   onRevertClose,
+  revertSummary,
+  onRevertSummaryChange,
   onConfirmRevert,
   revertMutation,
 }: AssetViewerModalsProps) {
@@ -97,16 +101,21 @@ export function AssetViewerModals({
         saving={!!contentUpdateMutation?.isPending}
       />
 
-      <ConfirmDialog
+      <ChangeSummaryDialog
         open={revertModalOpen && selectedVersion != null}
         onOpenChange={(open) => {
           if (!open) onRevertClose();
         }}
+        nextVersion={nextVersion}
+        value={revertSummary}
+        onChange={onRevertSummaryChange}
+        onSave={onConfirmRevert}
+        saving={!!revertMutation?.isPending}
         title={`Revert to v${selectedVersion}?`}
         description={`A new version (v${nextVersion}) will be created from the content of v${selectedVersion}.`}
-        confirmLabel="Revert"
-        loading={revertMutation?.isPending}
-        onConfirm={onConfirmRevert}
+        placeholder={`Reverted from v${selectedVersion}`}
+        saveLabel="Revert"
+        savingLabel="Reverting..."
       />
     </>
   );
@@ -8,7 +8,8 @@ import { Textarea } from "@/components/ui/textarea";
  *
  * The summary lives with the editor state rather than in this dialog, so a
  * failed save can reopen it with what the author already wrote; the dialog only
- * shows the field and reports the two ways out.
+ * shows the field and reports the two ways out. Restoring an older version
+ * asks the same question in its own words, through the optional copy props.
  */
 export function ChangeSummaryDialog({
   open,
@@ -18,6 +19,11 @@ export function ChangeSummaryDialog({
   onChange,
   onSave,
   saving,
+  title = "What changed?",
+  description,
+  placeholder = "Describe your changes (optional)",
+  saveLabel = "Save",
+  savingLabel = "Saving...",
 }: {
   open: boolean;
   onOpenChange: (open: boolean) => void;
@@ -26,6 +32,11 @@ export function ChangeSummaryDialog({
   onChange: (v: string) => void;
   onSave: () => void;
   saving: boolean;
+  title?: string;
+  description?: string;
+  placeholder?: string;
+  saveLabel?: string;
+  savingLabel?: string;
 }) {
   return (
     <Dialog.Root open={open} onOpenChange={onOpenChange}>
@@ -45,17 +56,17 @@ export function ChangeSummaryDialog({
                 if (saving) e.preventDefault();
               }}
             >
-              <Dialog.Title className="text-base font-semibold">What changed?</Dialog.Title>
+              <Dialog.Title className="text-base font-semibold">{title}</Dialog.Title>
               <Dialog.Description
                 id="change-summary-description"
                 className="text-xs text-muted-foreground"
               >
-                Saving will create a new version v{nextVersion}.
+                {description ?? `Saving will create a new version v${nextVersion}.`}
               </Dialog.Description>
               <Textarea
                 value={value}
                 onChange={(e) => onChange(e.target.value)}
-                placeholder="Describe your changes (optional)"
+                placeholder={placeholder}
                 aria-label="Change summary"
                 rows={3}
                 // ui/textarea sizes to its content unless asked for a height.
@@ -67,7 +78,7 @@ export function ChangeSummaryDialog({
                   Cancel
                 </Button>
                 <Button size="sm" onClick={onSave} disabled={saving}>
-                  {saving ? "Saving..." : "Save"}
+                  {saving ? savingLabel : saveLabel}
                 </Button>
               </div>
             </Dialog.Content>
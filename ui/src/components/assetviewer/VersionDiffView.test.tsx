@@ -0,0 +1,56 @@
+import { describe, it, expect, afterEach } from "vitest";
+import { render, screen, cleanup } from "@testing-library/react";
+import type { VersionDiff } from "@/api/portal/types";
+import { VersionDiffView } from "./VersionDiffView";
+
+// The Changes view draws whichever shape the server chose for the content
+// type; each test hands it one.
+
+const base = { asset_id: "asset-1", from_version: 2, to_version: 3, identical: false };
+
+describe("VersionDiffView", () => {
+  afterEach(cleanup);
+
+  it("says so when the versions read the same", () => {
+    render(<VersionDiffView diff={{ ...base, format: "structure", identical: true }} />);
+    expect(screen.getByText(/v2 and v3 read the same/)).toBeTruthy();
+  });
+
+  it("lists HTML block changes with their section", () => {
+    const diff: VersionDiff = {
+      ...base,
+      format: "structure",
+      blocks: [{ op: "changed", element: "p", section: "Revenue", old: "Up 3%.", new: "Up 4%." }],
+    };
+    render(<VersionDiffView diff={diff} />);
+    expect(screen.getByText("changed")).toBeTruthy();
+    expect(screen.getByText("Revenue")).toBeTruthy();
+    expect(screen.getByText("Up 3%.")).toBeTruthy();
+    expect(screen.getByText("Up 4%.")).toBeTruthy();
+  });
+
+  it("lists changed cells by column and notes a truncated diff", () => {
+    const diff: VersionDiff = {
+      ...base,
+      format: "cells",
+      cells: {
+        added_columns: ["margin"],
+        rows: [{ op: "changed", old_row: 4, new_row: 5, cells: [{ column: "revenue", old: "1200", new: "1350" }] }],
+        truncated: true,
+      },
+    };
+    render(<VersionDiffView diff={diff} />);
+    expect(screen.getByText(/margin/)).toBeTruthy();
+    expect(screen.getByText("row 4 → 5")).toBeTruthy();
+    expect(screen.getByText("revenue")).toBeTruthy();
+    expect(screen.getByText("1350")).toBeTruthy();
+    expect(screen.getByText(/first 1 changed rows/)).toBeTruthy();
+  });
+
+  it("renders a unified line diff for other text", () => {
+    const diff: VersionDiff = { ...base, format: "lines", unified: "@@ -1 +1 @@\n-old line\n+new line\n" };
+    render(<VersionDiffView diff={diff} />);
+    expect(screen.getByText("-old line")).toBeTruthy();
+    expect(screen.getByText("+new line")).toBeTruthy();
+  });
+});
@@ -0,0 +1,128 @@
+import { GitCompare } from "lucide-react";
+import type {
+  BlockChange,
+  CellDiff,
+  RowChange,
+  VersionChangeOp,
+  VersionDiff,
+} from "@/api/portal/types";
+import { EmptyState } from "@/components/patterns/EmptyState";
+import { Badge } from "@/components/ui/badge";
+
+const OP_VARIANT: Record<VersionChangeOp, "success" | "danger" | "info"> = {
+  added: "success",
+  removed: "danger",
+  changed: "info",
+};
+
+/**
+ * What changed between an older version and the current one, in the shape the
+ * server chose for the content type: HTML as rendered blocks, CSV and TSV as
+ * cells, everything else as a unified line diff.
+ */
+export function VersionDiffView({ diff }: { diff: VersionDiff }) {
+  if (diff.identical) {
+    return (
+      <EmptyState icon={GitCompare}>
+        v{diff.from_version} and v{diff.to_version} read the same.
+      </EmptyState>
+    );
+  }
+  switch (diff.format) {
+    case "structure":
+      return <BlockChanges blocks={diff.blocks ?? []} />;
+    case "cells":
+      return diff.cells ? <CellChanges cells={diff.cells} /> : null;
+    default:
+      return (
+        <pre className="overflow-x-auto rounded-md border bg-muted/30 p-3 font-mono text-xs">
+          {(diff.unified ?? "").split("\n").map((line, i) => (
+            <div key={i} className={unifiedLineClass(line)}>
+              {line || " "}
+            </div>
+          ))}
+        </pre>
+      );
+  }
+}
+
+function unifiedLineClass(line: string): string | undefined {
+  if (line.startsWith("+++") || line.startsWith("---")) return "text-muted-foreground";
+  if (line.startsWith("@@")) return "text-blue-700 dark:text-blue-300";
+  if (line.startsWith("+")) return "bg-emerald-500/10 text-emerald-800 dark:text-emerald-300";
+  if (line.startsWith("-")) return "bg-red-500/10 text-red-800 dark:text-red-300";
+  return undefined;
+}
+
+function OpBadge({ op }: { op: VersionChangeOp }) {
+  return <Badge variant={OP_VARIANT[op]}>{op}</Badge>;
+}
+
+function BlockChanges({ blocks }: { blocks: BlockChange[] }) {
+  return (
+    <ul className="space-y-2">
+      {blocks.map((b, i) => (
+        <li key={i} className="space-y-1 rounded-md border p-3 text-sm">
+          <div className="flex items-center gap-2 text-xs text-muted-foreground">
+            <OpBadge op={b.op} />
+            <span className="font-mono">{b.element}</span>
+            {b.section && <span className="truncate">{b.section}</span>}
+          </div>
+          {b.old && <p className="text-red-800 line-through dark:text-red-300">{b.old}</p>}
+          {b.new && <p className="text-emerald-800 dark:text-emerald-300">{b.new}</p>}
+        </li>
+      ))}
+    </ul>
+  );
+}
+
+function CellChanges({ cells }: { cells: CellDiff }) {
+  const rows = cells.rows ?? [];
+  return (
+    <div className="space-y-3 text-sm">
+      {(cells.added_columns?.length ?? 0) > 0 && (
+        <p>
+          <OpBadge op="added" /> columns: {cells.added_columns?.join(", ")}
+        </p>
+      )}
+      {(cells.removed_columns?.length ?? 0) > 0 && (
+        <p>
+          <OpBadge op="removed" /> columns: {cells.removed_columns?.join(", ")}
+        </p>
+      )}
+      <ul className="space-y-2">
+        {rows.map((r, i) => (
+          <li key={i} className="space-y-1 rounded-md border p-3">
+            <div className="flex items-center gap-2 text-xs text-muted-foreground">
+              <OpBadge op={r.op} />
+              <span>{rowLabel(r)}</span>
+            </div>
+            <dl className="grid grid-cols-[max-content_1fr] gap-x-3 gap-y-0.5 text-xs">
+              {r.cells.map((c) => (
+                <div key={c.column} className="contents">
+                  <dt className="font-medium">{c.column}</dt>
+                  <dd>
+                    {c.old && <span className="text-red-800 line-through dark:text-red-300">{c.old}</span>}
+                    {c.old && c.new && " → "}
+                    {c.new && <span className="text-emerald-800 dark:text-emerald-300">{c.new}</span>}
+                  </dd>
+                </div>
+              ))}
+            </dl>
+          </li>
+        ))}
+      </ul>
+      {cells.truncated && (
+        <p className="text-xs text-muted-foreground">
+          Showing the first {rows.length} changed rows.
+        </p>
+      )}
+    </div>
+  );
+}
+
+/** Names a row by where it sits in each version it belongs to. */
+function rowLabel(r: RowChange): string {
+  if (r.old_row && r.new_row && r.old_row !== r.new_row) return `row ${r.old_row} → ${r.new_row}`;
+  return `row ${r.new_row ?? r.old_row}`;
+}
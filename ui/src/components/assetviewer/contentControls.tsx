@@ -1,4 +1,4 @@
-import { Code, Download, Eye, FileWarning, RotateCcw, Save } from "lucide-react";
+import { Code, Download, Eye, FileText, FileWarning, GitCompare, RotateCcw, Save } from "lucide-react";
 import type { Asset, AssetVersion } from "@/api/portal/types";
 import { EmptyState } from "@/components/patterns/EmptyState";
 import { SegmentedControl } from "@/components/patterns/SegmentedControl";
@@ -12,7 +12,7 @@ import {
   SelectValue,
 } from "@/components/ui/select";
 import { formatBytes } from "@/lib/format";
-import type { ViewMode } from "./types";
+import type { VersionView, ViewMode } from "./types";
 
 const VIEW_OPTIONS = [
   { value: "preview" as const, label: "Preview", icon: Eye, text: "Preview" },
@@ -40,6 +40,32 @@ export function ViewModeToggle({
   );
 }
 
+const VERSION_VIEW_OPTIONS = [
+  { value: "version" as const, label: "Version", icon: FileText, text: "Version" },
+  { value: "changes" as const, label: "Changes since this version", icon: GitCompare, text: "Changes" },
+];
+
+/** Version/Changes switch, shown for an older version the surface can compare. */
+export function VersionViewToggle({
+  show,
+  versionView,
+  onSetVersionView,
+}: {
+  show: boolean;
+  versionView: VersionView;
+  onSetVersionView: (view: VersionView) => void;
+}) {
+  if (!show) return null;
+  return (
+    <SegmentedControl
+      label="Older version view"
+      value={versionView}
+      onChange={onSetVersionView}
+      options={VERSION_VIEW_OPTIONS}
+    />
+  );
+}
+
 /** How a version names itself, in the trigger and in the list alike. */
 function versionLabel(version: number, currentVersion: number): string {
   return `v${version}${version === currentVersion ? " (current)" : ""}`;
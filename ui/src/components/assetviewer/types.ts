@@ -1,5 +1,5 @@
 import type { ReactNode } from "react";
-import type { Asset, AssetVersion, SharePermission } from "@/api/portal/types";
+import type { Asset, AssetVersion, SharePermission, VersionDiff } from "@/api/portal/types";
 
 export interface MutationLike<TVariables> {
   mutate: (vars: TVariables, options?: { onSuccess?: () => void; onError?: () => void }) => void;
@@ -8,6 +8,15 @@ export interface MutationLike<TVariables> {
 
 export type ViewMode = "preview" | "source";
 
+/** How an older version is shown: as it was, or as what changed since. */
+export type VersionView = "version" | "changes";
+
+export interface RevertVars {
+  assetId: string;
+  version: number;
+  changeSummary?: string;
+}
+
 export interface AssetViewerProps {
   asset: Asset | undefined;
   content: string | ArrayBuffer | undefined;
@@ -41,9 +50,15 @@ export interface AssetViewerProps {
   sessionPath?: (sessionId: string) => string;
   versions?: AssetVersion[];
   versionsLoading?: boolean;
-  revertMutation?: MutationLike<{ assetId: string; version: number }>;
+  revertMutation?: MutationLike<RevertVars>;
   selectedVersion?: number | null;
   onSelectVersion?: (v: number | null) => void;
   versionContent?: string;
   versionContentLoading?: boolean;
+  /**
+   * The selected version compared with the current one. A surface with no
+   * diff endpoint leaves it out, and the viewer offers no Changes view.
+   */
+  versionDiff?: VersionDiff;
+  versionDiffLoading?: boolean;
 }
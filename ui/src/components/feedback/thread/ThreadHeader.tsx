@@ -44,7 +44,8 @@ export function ThreadHeader({
 }
 
 // ThreadSubject is what the thread is about: its title, who opened it and when,
-// and the passage it was anchored to if it was raised against one.
+// and the passage it was anchored to if it was raised against one, noting when
+// a later version removed that passage.
 export function ThreadSubject({ thread }: { thread: Thread }) {
   return (
     <div className="border-b p-3">
@@ -60,6 +61,11 @@ export function ThreadSubject({ thread }: { thread: Thread }) {
           <span className="min-w-0 truncate">{thread.anchor.exact}</span>
         </p>
       )}
+      {thread.anchor?.type !== "section" && thread.anchor?.state === "orphaned" && (
+        <p className="mt-1 text-xs text-muted-foreground">
+          The anchored passage was removed in v{thread.anchor.orphaned_at_version}.
+        </p>
+      )}
     </div>
   );
 }
@@ -10,10 +10,12 @@ export function HtmlRenderer({ content }: { content: string }) {
     return () => URL.revokeObjectURL(blobUrl);
   }, [blobUrl]);
 
+  // A page opened from a search passage link (#section) carries the fragment
+  // through to the document, so the frame lands on the matching heading.
   return (
     <iframe
       sandbox="allow-scripts"
-      src={blobUrl}
+      src={blobUrl + window.location.hash}
       className="w-full border border-border rounded-lg"
       style={{ height: "80vh" }}
       title="HTML Preview"
@@ -141,9 +141,9 @@ export function SpecList({
         <TableBody>
           {specs.map((s) => {
             const status = statusByName[s.spec_name];
-            // Embedded specs are re-seeded from their toolkit at startup, so
+            // Embedded and grpc specs are re-derived by their toolkit, so
             // edits/deletes here do not persist; present them as read-only.
-            const specReadOnly = s.source_kind === "embedded";
+            const specReadOnly = s.source_kind === "embedded" || s.source_kind === "grpc";
             return (
               <TableRow key={s.spec_name}>
                 <TableCell className="font-mono">{s.spec_name}</TableCell>
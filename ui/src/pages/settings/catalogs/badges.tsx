@@ -168,11 +168,11 @@ export function CatalogEmbeddingHealthBanner({
   );
 }
 
-// SourceBadge names where a component spec's content came from. The four
-// source kinds are categories, not states, so each rides a distinct badge
+// SourceBadge names where a component spec's content came from. The source
+// kinds are categories, not states, so each rides a distinct badge
 // variant: operator-pasted (muted), uploaded (info), fetched from a URL
-// (success), and platform-embedded (outline — the read-only kind that is
-// re-seeded from its toolkit at startup).
+// (success), and the two read-only kinds re-derived by their toolkit,
+// platform-embedded and grpc descriptors (outline).
 const SOURCE_BADGES: Record<
   string,
   {
@@ -185,6 +185,7 @@ const SOURCE_BADGES: Record<
   upload: { icon: Upload, label: "upload", variant: "info" },
   url: { icon: LinkIcon, label: "URL", variant: "success" },
   embedded: { icon: Package, label: "embedded", variant: "outline" },
+  grpc: { icon: Package, label: "gRPC", variant: "outline" },
 };
 
 export function SourceBadge({ kind, url }: { kind: APICatalogSpec["source_kind"]; url?: string }) {
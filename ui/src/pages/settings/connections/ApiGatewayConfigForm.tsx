@@ -19,11 +19,18 @@ const TRUST_LEVELS = [
   { value: "trusted", label: "Trusted" },
 ];
 
+const CONTRACT_MODES = [
+  { value: "off", label: "Off (default)" },
+  { value: "sampled", label: "Sampled" },
+  { value: "always", label: "Always" },
+];
+
 // ApiGatewayConfigForm renders the editor for kind=api connections —
 // the HTTP API gateway. Field shape matches the apigateway toolkit
 // config (see pkg/toolkits/apigateway/config.go): base_url, the auth
 // block (ApiGatewayAuthFields), TLS material, static headers, the
-// catalog reference, timeouts, and max_response_bytes.
+// catalog reference, timeouts, max_response_bytes, and contract
+// validation.
 export function ApiGatewayConfigForm({
   config,
   onChange,
@@ -115,6 +122,26 @@ export function ApiGatewayConfigForm({
         placeholder="10485760"
       />
 
+      <div className="grid grid-cols-2 gap-3">
+        <ConfigSelect
+          label="Contract validation"
+          value={String(config.contract_validation ?? "off")}
+          onChange={(v) => onChange(update(config, "contract_validation", v === "off" ? undefined : v))}
+          options={CONTRACT_MODES}
+          help="Compare responses with the catalog spec and record drift on the catalog. Responses are never changed."
+        />
+        {config.contract_validation === "sampled" && (
+          <ConfigField
+            label="Sample rate"
+            help="Fraction of calls checked, between 0 and 1. Default 0.1."
+            type="number"
+            value={String(config.contract_sample_rate ?? "")}
+            onChange={(v) => onChange(update(config, "contract_sample_rate", v ? Number(v) : undefined))}
+            placeholder="0.1"
+          />
+        )}
+      </div>
+
       <ConfigSelect
         label="Trust level"
         value={String(config.trust_level ?? "untrusted")}
@@ -82,6 +82,8 @@ export const CONFIG_LABELS: Record<string, Record<string, string>> = {
     call_timeout: "Call Timeout",
     trust_level: "Trust Level",
     max_response_bytes: "Max Response Bytes",
+    contract_validation: "Contract Validation",
+    contract_sample_rate: "Contract Sample Rate",
     catalog_id: "OpenAPI Catalog",
     connection_name: "Connection Name",
     oauth2_token_url: "OAuth2 Token URL",
@@ -1,7 +1,8 @@
 import { useState } from "react";
-import { useAsset, useAssetContent, useUpdateAsset, useDeleteAsset, useUpdateAssetContent, useCopyAsset, useAssetVersions, useRevertVersion, useVersionContent } from "@/api/portal/hooks";
+import { useAsset, useAssetContent, useUpdateAsset, useDeleteAsset, useUpdateAssetContent, useCopyAsset, useAssetVersions, useRevertVersion, useVersionContent, useVersionDiff } from "@/api/portal/hooks";
 import { AssetViewer } from "@/components/AssetViewer";
 import { FeedbackButton } from "@/components/feedback/FeedbackButton";
+import { isTextualType } from "@/lib/contentType";
 import { mySessionPath } from "@/pages/activity/routes";
 
 interface Props {
@@ -26,6 +27,18 @@ export function AssetViewerPage({ assetId, onNavigate, onBack }: Props) {
     assetId,
     needsVersionContent ? selectedVersion : 0,
   );
+  // Only text compares; an image or a PDF is offered the version view alone.
+  const oldVersionType = versionsData?.data.find((v) => v.version === selectedVersion)?.content_type;
+  const canCompare =
+    needsVersionContent &&
+    !!asset &&
+    isTextualType(asset.content_type) &&
+    isTextualType(oldVersionType ?? asset.content_type);
+  const { data: versionDiff, isLoading: versionDiffLoading } = useVersionDiff(
+    assetId,
+    canCompare ? (selectedVersion ?? 0) : 0,
+    canCompare ? (asset?.current_version ?? 0) : 0,
+  );
 
   const isOwner = asset?.is_owner ?? true;
   const sharePermission = asset?.share_permission;
@@ -55,6 +68,8 @@ export function AssetViewerPage({ assetId, onNavigate, onBack }: Props) {
       onSelectVersion={setSelectedVersion}
       versionContent={needsVersionContent ? versionContent : undefined}
       versionContentLoading={needsVersionContent ? versionContentLoading : false}
+      versionDiff={canCompare ? versionDiff : undefined}
+      versionDiffLoading={canCompare ? versionDiffLoading : false}
       toolbarExtra={
         <FeedbackButton
           target={{ type: "asset", id: assetId, version: asset?.current_version }}